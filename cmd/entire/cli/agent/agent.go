@@ -107,6 +107,17 @@ type HookSupport interface {
 	AreHooksInstalled() bool
 }
 
+// HookVerifier is implemented by agents that can check each installed hook
+// against the command line InstallHooks writes. Agents that don't implement
+// it are only checked with AreHooksInstalled.
+type HookVerifier interface {
+	HookSupport
+
+	// MissingHooks returns the hook commands InstallHooks would add, i.e.
+	// those that are missing or have a different command line.
+	MissingHooks(localDev bool) ([]string, error)
+}
+
 // FileWatcher is implemented by agents that use file-based detection.
 // Agents like Aider that don't support hooks can use file watching
 // to detect session activity.
@@ -184,3 +195,16 @@ type SubagentAwareExtractor interface {
 	// The subagentsDir parameter specifies where subagent transcripts are stored.
 	CalculateTotalTokenUsage(sessionRef string, fromOffset int, subagentsDir string) (*TokenUsage, error)
 }
+
+// ResumeDetector recognizes sessions that resume an earlier conversation.
+// Agents that issue a new session ID on resume (like Claude Code's --resume
+// and --continue) implement this so the new session can be linked to the
+// one it continues.
+type ResumeDetector interface {
+	Agent
+
+	// ResumedSessionID returns the ID of the session that sessionID resumes,
+	// read from the transcript at sessionRef. Returns "" when the session
+	// did not resume another one.
+	ResumedSessionID(sessionRef, sessionID string) (string, error)
+}
@@ -0,0 +1,203 @@
+// Package aider implements the Agent interface for Aider.
+package aider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameAider, NewAiderAgent)
+}
+
+// Files Aider keeps in the repository root.
+const (
+	// ChatHistoryFileName is Aider's default chat history file.
+	ChatHistoryFileName = ".aider.chat.history.md"
+	// InputHistoryFileName is Aider's prompt input history file.
+	InputHistoryFileName = ".aider.input.history"
+	// ConfigFileName is Aider's per-repository config file.
+	ConfigFileName = ".aider.conf.yml"
+)
+
+// AiderAgent implements the Agent interface for Aider.
+//
+//nolint:revive // AiderAgent is clearer than Agent in this context
+type AiderAgent struct{}
+
+func NewAiderAgent() agent.Agent {
+	return &AiderAgent{}
+}
+
+// Name returns the agent registry key.
+func (a *AiderAgent) Name() agent.AgentName {
+	return agent.AgentNameAider
+}
+
+// Type returns the agent type identifier.
+func (a *AiderAgent) Type() agent.AgentType {
+	return agent.AgentTypeAider
+}
+
+// Description returns a human-readable description.
+func (a *AiderAgent) Description() string {
+	return "Aider - AI pair programming in your terminal"
+}
+
+func (a *AiderAgent) IsPreview() bool { return true }
+
+// DetectPresence checks if Aider is configured or has been used in the repository.
+func (a *AiderAgent) DetectPresence() (bool, error) {
+	// Get worktree root to check for Aider files
+	// This is needed because the CLI may be run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	for _, name := range []string{ConfigFileName, ChatHistoryFileName} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (a *AiderAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns the Aider history files, which rewind must not touch
+// since the chat history is the session transcript.
+func (a *AiderAgent) ProtectedDirs() []string {
+	return []string{ChatHistoryFileName, InputHistoryFileName}
+}
+
+// GetSessionDir returns the directory holding Aider's chat history, which is
+// the repository root.
+func (a *AiderAgent) GetSessionDir(repoPath string) (string, error) {
+	return repoPath, nil
+}
+
+// ResolveSessionFile returns the path to the chat history file. All Aider
+// sessions share it; each one is a chat within the file.
+func (a *AiderAgent) ResolveSessionFile(sessionDir, _ string) string {
+	return chatHistoryPath(sessionDir)
+}
+
+// ReadSession reads the current chat from Aider's history file.
+// NativeData holds the chat's markdown, from its header on.
+func (a *AiderAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (chat history path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     a.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    CurrentSession(data),
+		ModifiedFiles: ExtractModifiedFiles(data, 0),
+	}, nil
+}
+
+// WriteSession writes a chat back to Aider's history file. The chat replaces
+// the one with the same header, or is appended if there is none, so the other
+// chats in the file are kept.
+func (a *AiderAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Aider
+	if session.AgentName != "" && session.AgentName != a.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, a.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (chat history path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	existing, err := os.ReadFile(session.SessionRef)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	if err := os.WriteFile(session.SessionRef, replaceChat(existing, session.NativeData), 0o600); err != nil {
+		return fmt.Errorf("failed to write chat history: %w", err)
+	}
+	return nil
+}
+
+// replaceChat returns history with chat in place of the chat that has the
+// same header line, or with chat appended.
+func replaceChat(history, chat []byte) []byte {
+	header, _, _ := bytes.Cut(chat, []byte("\n"))
+	start := -1
+	switch {
+	case len(header) == 0:
+	case bytes.HasPrefix(history, header):
+		start = 0
+	default:
+		if idx := bytes.Index(history, append([]byte("\n"), header...)); idx >= 0 {
+			start = idx + 1
+		}
+	}
+	if start < 0 {
+		if len(history) > 0 && !bytes.HasSuffix(history, []byte("\n")) {
+			history = append(history, '\n')
+		}
+		return append(history, chat...)
+	}
+
+	result := append([]byte{}, history[:start]...)
+	result = append(result, chat...)
+	rest := history[start+len(header):]
+	if next := bytes.Index(rest, []byte("\n"+sessionHeaderPrefix)); next >= 0 {
+		if !bytes.HasSuffix(result, []byte("\n")) {
+			result = append(result, '\n')
+		}
+		result = append(result, rest[next+1:]...)
+	}
+	return result
+}
+
+// FormatResumeCommand returns the command to resume an Aider session.
+// Aider has no session IDs; it reloads the chat history on request.
+func (a *AiderAgent) FormatResumeCommand(_ string) string {
+	return "aider --restore-chat-history"
+}
+
+// ChunkTranscript splits the markdown history at line boundaries.
+func (a *AiderAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk chat history: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates chunks with newlines.
+func (a *AiderAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
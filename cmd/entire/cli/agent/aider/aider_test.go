@@ -0,0 +1,86 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestAiderAgent_Registered(t *testing.T) {
+	t.Parallel()
+
+	ag, err := agent.Get(agent.AgentNameAider)
+	if err != nil {
+		t.Fatalf("agent.Get(aider) error = %v", err)
+	}
+	if ag.Type() != agent.AgentTypeAider {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeAider)
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	ag := &AiderAgent{}
+
+	if present, err := ag.DetectPresence(); err != nil || present {
+		t.Errorf("DetectPresence() = %v, %v in an empty dir, want false", present, err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ChatHistoryFileName), []byte(testHistory), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	if present, err := ag.DetectPresence(); err != nil || !present {
+		t.Errorf("DetectPresence() = %v, %v with chat history, want true", present, err)
+	}
+}
+
+func TestWriteSession_ReplacesOnlyItsChat(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ChatHistoryFileName)
+	earlier := "# aider chat started at 2026-03-01 09:00:00\n\n#### first\n\nOk.\n\n"
+	current := "# aider chat started at 2026-03-02 14:30:05\n\n#### second\n\nDone, and more.\n"
+	if err := os.WriteFile(path, []byte(earlier+current), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	ag := &AiderAgent{}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(session.NativeData) != current {
+		t.Errorf("ReadSession() NativeData = %q, want the current chat", session.NativeData)
+	}
+
+	// Rewind restores an earlier version of the current chat
+	restored := "# aider chat started at 2026-03-02 14:30:05\n\n#### second\n"
+	session.NativeData = []byte(restored)
+	if err := ag.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	if string(data) != earlier+restored {
+		t.Errorf("history = %q, want %q", data, earlier+restored)
+	}
+
+	// A chat not in the file is appended
+	other := "# aider chat started at 2026-03-03 08:00:00\n"
+	session.NativeData = []byte(other)
+	if err := ag.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read history: %v", err)
+	}
+	if !strings.HasSuffix(string(data), restored+other) {
+		t.Errorf("history = %q, want the new chat appended", data)
+	}
+}
@@ -0,0 +1,191 @@
+package aider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Ensure AiderAgent implements HookSupport
+var _ agent.HookSupport = (*AiderAgent)(nil)
+
+// HookNameTurnEnd is the only Aider hook - it becomes `entire hooks aider turn-end`.
+// Aider has no session or prompt hooks; its notifications command runs each
+// time Aider finishes responding and waits for input.
+const HookNameTurnEnd = "turn-end"
+
+// Aider config keys Entire reads or sets in .aider.conf.yml.
+const (
+	configKeyNotifications        = "notifications"
+	configKeyNotificationsCommand = "notifications-command"
+	configKeyChatHistoryFile      = "chat-history-file"
+)
+
+// entireConfigComment marks the config lines Entire added.
+const entireConfigComment = "# Entire: record a checkpoint each time Aider finishes responding"
+
+// entireHookPrefixes are command prefixes that identify Entire hooks
+var entireHookPrefixes = []string{
+	"entire ",
+	"go run ./cmd/entire/main.go ",
+}
+
+// InstallHooks sets Entire as Aider's notifications command in .aider.conf.yml.
+// The file is edited line by line so the user's settings and comments are kept.
+// If force is true, reinstalls even when the same command is already set.
+// Returns the number of hooks installed.
+func (a *AiderAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	// Use repo root instead of CWD to find .aider.conf.yml
+	// This ensures hooks are installed correctly when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Fallback to CWD if not in a git repo (e.g., during tests)
+		repoRoot, err = os.Getwd() //nolint:forbidigo // Intentional fallback when WorktreeRoot() fails (tests run outside git repos)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	configPath := filepath.Join(repoRoot, ConfigFileName)
+	lines, err := agent.ReadConfigLines(configPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var command string
+	if localDev {
+		command = "go run ./cmd/entire/main.go hooks aider " + HookNameTurnEnd
+	} else {
+		command = "entire hooks aider " + HookNameTurnEnd
+	}
+
+	existing, _ := configValue(lines, configKeyNotificationsCommand)
+	if existing != "" && !isEntireHook(existing) {
+		return 0, fmt.Errorf("%s already sets %s to %q; Entire needs this hook, remove it and try again", ConfigFileName, configKeyNotificationsCommand, existing)
+	}
+	// Check for idempotency - if the same command is already set, nothing to do
+	if !force && existing == command {
+		return 0, nil
+	}
+
+	lines = removeEntireConfig(lines)
+
+	// Notifications must be on for Aider to run the command
+	if _, idx := configValue(lines, configKeyNotifications); idx >= 0 {
+		lines[idx] = configKeyNotifications + ": true"
+		lines = append(lines, entireConfigComment)
+	} else {
+		lines = append(lines, entireConfigComment, configKeyNotifications+": true")
+	}
+	lines = append(lines, configKeyNotificationsCommand+": "+command)
+
+	if err := agent.WriteConfigLines(configPath, lines); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// UninstallHooks removes Entire's notifications command from .aider.conf.yml.
+func (a *AiderAgent) UninstallHooks() error {
+	// Use repo root to find .aider.conf.yml when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	configPath := filepath.Join(repoRoot, ConfigFileName)
+	if _, err := os.Stat(configPath); err != nil {
+		return nil //nolint:nilerr // No config file means nothing to uninstall
+	}
+
+	lines, err := agent.ReadConfigLines(configPath)
+	if err != nil {
+		return err
+	}
+	return agent.WriteConfigLines(configPath, removeEntireConfig(lines))
+}
+
+// AreHooksInstalled checks if Entire is Aider's notifications command.
+func (a *AiderAgent) AreHooksInstalled() bool {
+	// Use repo root to find .aider.conf.yml when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	lines, err := agent.ReadConfigLines(filepath.Join(repoRoot, ConfigFileName))
+	if err != nil {
+		return false
+	}
+	command, _ := configValue(lines, configKeyNotificationsCommand)
+	return isEntireHook(command)
+}
+
+// chatHistoryPath returns the chat history file for the repository at
+// repoRoot, honoring chat-history-file in .aider.conf.yml.
+func chatHistoryPath(repoRoot string) string {
+	lines, err := agent.ReadConfigLines(filepath.Join(repoRoot, ConfigFileName))
+	if err == nil {
+		if name, _ := configValue(lines, configKeyChatHistoryFile); name != "" {
+			if filepath.IsAbs(name) {
+				return name
+			}
+			return filepath.Join(repoRoot, name)
+		}
+	}
+	return filepath.Join(repoRoot, ChatHistoryFileName)
+}
+
+// configValue returns the value of a top-level key and the index of its line,
+// or "" and -1 if the key is not set. Quoted values are unquoted.
+func configValue(lines []string, key string) (string, int) {
+	for i, line := range lines {
+		rest, ok := strings.CutPrefix(line, key+":")
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(rest)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+		return value, i
+	}
+	return "", -1
+}
+
+// removeEntireConfig removes the lines Entire added: its comment, the
+// notifications setting right after it, and the Entire notifications command.
+func removeEntireConfig(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	afterComment := false
+	for _, line := range lines {
+		switch {
+		case line == entireConfigComment:
+			afterComment = true
+			continue
+		case afterComment && line == configKeyNotifications+": true":
+			continue
+		}
+		afterComment = false
+		if value, idx := configValue([]string{line}, configKeyNotificationsCommand); idx == 0 && isEntireHook(value) {
+			continue
+		}
+		result = append(result, line)
+	}
+	return result
+}
+
+// isEntireHook checks if a command is an Entire hook
+func isEntireHook(command string) bool {
+	for _, prefix := range entireHookPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallHooks_FreshInstall(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &AiderAgent{}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("InstallHooks() count = %d, want 1", count)
+	}
+
+	config := readConfig(t, tempDir)
+	want := entireConfigComment + "\nnotifications: true\nnotifications-command: entire hooks aider turn-end\n"
+	if config != want {
+		t.Errorf("config = %q, want %q", config, want)
+	}
+	if !agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestInstallHooks_Idempotent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &AiderAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("first InstallHooks() error = %v", err)
+	}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+
+	if n := strings.Count(readConfig(t, tempDir), "notifications-command:"); n != 1 {
+		t.Errorf("notifications-command set %d times, want 1", n)
+	}
+}
+
+func TestInstallHooks_KeepsUserConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	writeConfig(t, tempDir, "# my settings\nmodel: sonnet\nnotifications: false\n")
+
+	agent := &AiderAgent{}
+	if _, err := agent.InstallHooks(true, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	config := readConfig(t, tempDir)
+	for _, want := range []string{"# my settings\n", "model: sonnet\n", "notifications: true\n", "notifications-command: go run ./cmd/entire/main.go hooks aider turn-end\n"} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config missing %q:\n%s", want, config)
+		}
+	}
+	if strings.Contains(config, "notifications: false") {
+		t.Errorf("notifications should be enabled in place:\n%s", config)
+	}
+}
+
+func TestInstallHooks_UserNotificationsCommand(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	writeConfig(t, tempDir, "notifications-command: 'say done'\n")
+
+	if _, err := (&AiderAgent{}).InstallHooks(false, false); err == nil {
+		t.Error("InstallHooks() should refuse to replace the user's notifications command")
+	}
+	if config := readConfig(t, tempDir); config != "notifications-command: 'say done'\n" {
+		t.Errorf("config changed to %q", config)
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	writeConfig(t, tempDir, "model: sonnet\n")
+
+	agent := &AiderAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if err := agent.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+
+	if config := readConfig(t, tempDir); config != "model: sonnet\n" {
+		t.Errorf("config after uninstall = %q, want the original", config)
+	}
+	if agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+}
+
+func TestChatHistoryPath(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	if got := chatHistoryPath(tempDir); got != filepath.Join(tempDir, ChatHistoryFileName) {
+		t.Errorf("chatHistoryPath() = %q, want the default history file", got)
+	}
+
+	writeConfig(t, tempDir, "chat-history-file: \"logs/aider.md\"\n")
+	if got := chatHistoryPath(tempDir); got != filepath.Join(tempDir, "logs", "aider.md") {
+		t.Errorf("chatHistoryPath() = %q, want the configured history file", got)
+	}
+}
+
+func readConfig(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", ConfigFileName, err)
+	}
+	return string(data)
+}
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", ConfigFileName, err)
+	}
+}
@@ -0,0 +1,127 @@
+package aider
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Compile-time interface assertions for new interfaces.
+var (
+	_ agent.TranscriptAnalyzer = (*AiderAgent)(nil)
+	_ agent.TokenCalculator    = (*AiderAgent)(nil)
+)
+
+// HookNames returns the hook verbs Aider supports.
+// These become subcommands: entire hooks aider <verb>
+func (a *AiderAgent) HookNames() []string {
+	return []string{HookNameTurnEnd}
+}
+
+// ParseHookEvent translates an Aider hook into a normalized lifecycle Event.
+// Aider passes nothing to its notifications command (stdin may even be the
+// user's terminal), so stdin is not read: the session and transcript come
+// from the chat history file in the repository.
+// Returns nil if there is no chat history yet.
+func (a *AiderAgent) ParseHookEvent(hookName string, _ io.Reader) (*agent.Event, error) {
+	if hookName != HookNameTurnEnd {
+		return nil, nil //nolint:nilnil // Unknown hooks have no lifecycle action
+	}
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	historyPath := chatHistoryPath(repoRoot)
+	data, err := os.ReadFile(historyPath) //nolint:gosec // Path is the repo's Aider chat history
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // nil event = no lifecycle action
+		}
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	return &agent.Event{
+		Type:       agent.TurnEnd,
+		SessionID:  SessionIDFromHistory(data),
+		SessionRef: historyPath,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// ReadTranscript returns the current chat from the chat history file.
+func (a *AiderAgent) ReadTranscript(sessionRef string) ([]byte, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return CurrentSession(data), nil
+}
+
+// ExtractPrompts extracts user prompts from the current chat starting at the given line offset.
+func (a *AiderAgent) ExtractPrompts(sessionRef string, fromOffset int) ([]string, error) {
+	data, err := a.ReadTranscript(sessionRef)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractUserPrompts(data, fromOffset), nil
+}
+
+// ExtractSummary extracts the model's last reply as a session summary.
+func (a *AiderAgent) ExtractSummary(sessionRef string) (string, error) {
+	data, err := a.ReadTranscript(sessionRef)
+	if err != nil {
+		return "", err
+	}
+	return ExtractLastAssistantMessage(data), nil
+}
+
+// CalculateTokenUsage sums Aider's token reports starting at the given line offset.
+func (a *AiderAgent) CalculateTokenUsage(sessionRef string, fromOffset int) (*agent.TokenUsage, error) {
+	data, err := a.ReadTranscript(sessionRef)
+	if err != nil {
+		return nil, err
+	}
+	return CalculateTokenUsage(data, fromOffset), nil
+}
+
+// GetTranscriptPosition returns the current line count of the current chat.
+// Returns 0 if the history file doesn't exist or is empty.
+func (a *AiderAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	return LineCount(data), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files Aider edited since a given line number.
+// Returns:
+//   - files: files named in Aider's "Applied edit to" reports
+//   - currentPosition: total number of lines in the current chat
+//   - error: any error encountered during reading
+func (a *AiderAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	data, readErr := os.ReadFile(path) //nolint:gosec // Path comes from agent hook input
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read chat history: %w", readErr)
+	}
+	return ExtractModifiedFiles(data, startOffset), LineCount(data), nil
+}
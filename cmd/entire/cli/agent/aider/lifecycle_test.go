@@ -0,0 +1,89 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestParseHookEvent_TurnEnd(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	if err := os.WriteFile(filepath.Join(tempDir, ChatHistoryFileName), []byte(testHistory), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	// stdin is never read - Aider may leave it attached to the terminal
+	event, err := (&AiderAgent{}).ParseHookEvent(HookNameTurnEnd, strings.NewReader("not json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event == nil {
+		t.Fatal("expected event, got nil")
+	}
+	if event.Type != agent.TurnEnd {
+		t.Errorf("Type = %v, want %v", event.Type, agent.TurnEnd)
+	}
+	if event.SessionID != "aider-2026-03-02-143005" {
+		t.Errorf("SessionID = %q, want aider-2026-03-02-143005", event.SessionID)
+	}
+	if filepath.Base(event.SessionRef) != ChatHistoryFileName {
+		t.Errorf("SessionRef = %q, want the chat history file", event.SessionRef)
+	}
+}
+
+func TestParseHookEvent_NoHistory(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	event, err := (&AiderAgent{}).ParseHookEvent(HookNameTurnEnd, strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event != nil {
+		t.Errorf("expected nil event without chat history, got %+v", event)
+	}
+}
+
+func TestTranscriptAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ChatHistoryFileName)
+	if err := os.WriteFile(path, []byte(testHistory), 0o600); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+	ag := &AiderAgent{}
+
+	transcript, err := ag.ReadTranscript(path)
+	if err != nil {
+		t.Fatalf("ReadTranscript() error = %v", err)
+	}
+	if !strings.HasPrefix(string(transcript), "# aider chat started at 2026-03-02 14:30:05") {
+		t.Errorf("ReadTranscript() should return only the current chat, got %q", transcript[:40])
+	}
+
+	position, err := ag.GetTranscriptPosition(path)
+	if err != nil || position != LineCount([]byte(testHistory)) {
+		t.Errorf("GetTranscriptPosition() = %d, %v, want %d", position, err, LineCount([]byte(testHistory)))
+	}
+
+	files, currentPosition, err := ag.ExtractModifiedFilesFromOffset(path, 23)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if len(files) != 0 || currentPosition != position {
+		t.Errorf("ExtractModifiedFilesFromOffset(23) = %v, %d, want no files and %d", files, currentPosition, position)
+	}
+
+	summary, err := ag.ExtractSummary(path)
+	if err != nil || summary != "You're welcome!" {
+		t.Errorf("ExtractSummary() = %q, %v", summary, err)
+	}
+
+	usage, err := ag.CalculateTokenUsage(path, 23)
+	if err != nil || usage.APICallCount != 1 {
+		t.Errorf("CalculateTokenUsage(23) = %+v, %v, want one request", usage, err)
+	}
+}
@@ -0,0 +1,244 @@
+package aider
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Transcript parsing - Aider appends every chat to a markdown history file
+// (.aider.chat.history.md by default). Each run starts with a
+// "# aider chat started at <time>" header. User prompts are lines prefixed
+// with "####", Aider's own output (edits applied, token reports) is quoted
+// with ">", and everything else is the model's reply.
+//
+// The transcript of a session is the last chat in the file, from its header
+// on. Transcript positions are line numbers within that chat.
+
+const (
+	sessionHeaderPrefix = "# aider chat started at "
+	sessionTimeLayout   = "2006-01-02 15:04:05"
+	promptPrefix        = "####"
+	outputPrefix        = ">"
+	codeFence           = "```"
+)
+
+// Message role constants for parsed history messages.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleOutput    = "output" // Aider's own status output
+)
+
+// Message is a run of consecutive history lines with the same role.
+type Message struct {
+	Role string
+	Text string
+}
+
+// appliedEditRegex matches Aider's report of a file it edited.
+var appliedEditRegex = regexp.MustCompile(`^Applied edit to (.+)$`)
+
+// tokenCountRegex matches one count in Aider's token report, e.g. the
+// "2.1k sent" in "Tokens: 2.1k sent, 1.5k cache hit, 120 received."
+var tokenCountRegex = regexp.MustCompile(`([\d.,]+)([kKmM]?) (sent|cache write|cache hit|received)`)
+
+// CurrentSession returns the last chat in history data, from its header on.
+// History without a chat header is returned whole.
+func CurrentSession(data []byte) []byte {
+	if idx := bytes.LastIndex(data, []byte("\n"+sessionHeaderPrefix)); idx >= 0 {
+		return data[idx+1:]
+	}
+	return data
+}
+
+// SessionIDFromHistory returns the ID of the last chat in history data,
+// derived from the time it started. Returns "" if the history has no chat
+// header.
+func SessionIDFromHistory(data []byte) string {
+	section := CurrentSession(data)
+	if !bytes.HasPrefix(section, []byte(sessionHeaderPrefix)) {
+		return ""
+	}
+	header, _, _ := bytes.Cut(section, []byte("\n"))
+	started := strings.TrimSpace(strings.TrimPrefix(string(header), sessionHeaderPrefix))
+	startedAt, err := time.Parse(sessionTimeLayout, started)
+	if err != nil {
+		return ""
+	}
+	return "aider-" + startedAt.Format("2006-01-02-150405")
+}
+
+// splitLines returns the lines of the current chat without trailing blank
+// lines, matching how line-based transcripts are counted elsewhere.
+func splitLines(data []byte) []string {
+	section := CurrentSession(data)
+	if len(section) == 0 {
+		return nil
+	}
+	lines := strings.Split(string(section), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// LineCount returns the transcript position at the end of history data.
+func LineCount(data []byte) int {
+	return len(splitLines(data))
+}
+
+// ParseMessages groups the lines of the current chat from line startLine on
+// into messages. Lines inside fenced code blocks always belong to the
+// model's reply, so quoted or "####" lines in code are not misread.
+func ParseMessages(data []byte, startLine int) []Message {
+	lines := splitLines(data)
+	var messages []Message
+	var current *Message
+	inFence := false
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.TrimSpace(current.Text)
+			if current.Text != "" {
+				messages = append(messages, *current)
+			}
+			current = nil
+		}
+	}
+	add := func(role, text string) {
+		if current != nil && current.Role != role {
+			flush()
+		}
+		if current == nil {
+			current = &Message{Role: role}
+		} else {
+			current.Text += "\n"
+		}
+		current.Text += text
+	}
+
+	for i := max(startLine, 0); i < len(lines); i++ {
+		// Aider ends lines with two spaces for markdown line breaks
+		line := strings.TrimRight(lines[i], " \r")
+		switch {
+		case inFence:
+			if strings.HasPrefix(line, codeFence) {
+				inFence = false
+			}
+			add(RoleAssistant, line)
+		case strings.HasPrefix(line, sessionHeaderPrefix):
+			flush()
+		case strings.HasPrefix(line, promptPrefix):
+			add(RoleUser, strings.TrimPrefix(strings.TrimPrefix(line, promptPrefix), " "))
+		case strings.HasPrefix(line, outputPrefix):
+			add(RoleOutput, strings.TrimSpace(strings.TrimPrefix(line, outputPrefix)))
+		case strings.TrimSpace(line) == "":
+			// Blank lines end prompts and output, but are part of replies
+			if current != nil && current.Role == RoleAssistant {
+				add(RoleAssistant, "")
+			} else {
+				flush()
+			}
+		default:
+			if strings.HasPrefix(line, codeFence) {
+				inFence = true
+			}
+			add(RoleAssistant, line)
+		}
+	}
+	flush()
+	return messages
+}
+
+// ExtractUserPrompts returns the user prompts in the current chat from line
+// startLine on.
+func ExtractUserPrompts(data []byte, startLine int) []string {
+	var prompts []string
+	for _, msg := range ParseMessages(data, startLine) {
+		if msg.Role == RoleUser {
+			prompts = append(prompts, msg.Text)
+		}
+	}
+	return prompts
+}
+
+// ExtractLastAssistantMessage returns the model's last reply in the current chat.
+func ExtractLastAssistantMessage(data []byte) string {
+	messages := ParseMessages(data, 0)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == RoleAssistant {
+			return messages[i].Text
+		}
+	}
+	return ""
+}
+
+// ExtractModifiedFiles returns the files Aider reported editing in the
+// current chat from line startLine on.
+func ExtractModifiedFiles(data []byte, startLine int) []string {
+	var files []string
+	for _, msg := range ParseMessages(data, startLine) {
+		if msg.Role != RoleOutput {
+			continue
+		}
+		for _, line := range strings.Split(msg.Text, "\n") {
+			if match := appliedEditRegex.FindStringSubmatch(line); match != nil && !slices.Contains(files, match[1]) {
+				files = append(files, match[1])
+			}
+		}
+	}
+	return files
+}
+
+// CalculateTokenUsage sums Aider's token reports in the current chat from
+// line startLine on. Each report covers one model request.
+func CalculateTokenUsage(data []byte, startLine int) *agent.TokenUsage {
+	usage := &agent.TokenUsage{}
+	for _, msg := range ParseMessages(data, startLine) {
+		if msg.Role != RoleOutput {
+			continue
+		}
+		for _, line := range strings.Split(msg.Text, "\n") {
+			report, ok := strings.CutPrefix(line, "Tokens: ")
+			if !ok {
+				continue
+			}
+			usage.APICallCount++
+			for _, match := range tokenCountRegex.FindAllStringSubmatch(report, -1) {
+				count := parseTokenCount(match[1], match[2])
+				switch match[3] {
+				case "sent":
+					usage.InputTokens += count
+				case "cache write":
+					usage.CacheCreationTokens += count
+				case "cache hit":
+					usage.CacheReadTokens += count
+				case "received":
+					usage.OutputTokens += count
+				}
+			}
+		}
+	}
+	return usage
+}
+
+// parseTokenCount parses a count as Aider formats it: "950", "2.1k", "12k", "1.2M".
+func parseTokenCount(number, suffix string) int {
+	value, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	switch strings.ToLower(suffix) {
+	case "k":
+		value *= 1_000
+	case "m":
+		value *= 1_000_000
+	}
+	return int(value + 0.5)
+}
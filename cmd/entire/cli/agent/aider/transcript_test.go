@@ -0,0 +1,140 @@
+package aider
+
+import (
+	"slices"
+	"testing"
+)
+
+const testHistory = `
+# aider chat started at 2026-03-01 09:00:00
+
+> aider --model sonnet
+> Aider v0.80.0
+
+#### fix the typo in the README
+
+Fixed it.
+
+> Tokens: 900 sent, 45 received. Cost: $0.0034 message, $0.0034 session.  
+> Applied edit to README.md  
+
+# aider chat started at 2026-03-02 14:30:05
+
+> aider --model sonnet  
+> Aider v0.80.0  
+
+#### add a hello function  
+#### with a docstring
+
+Here is the function:
+
+main.py
+` + "```" + `python
+#### not a prompt
+> not output
+def hello():
+    """Say hello."""
+` + "```" + `
+
+> Tokens: 2.1k sent, 1.5k cache write, 12k cache hit, 120 received. Cost: $0.01 message, $0.01 session.  
+> Applied edit to main.py  
+> Applied edit to tests/test_main.py  
+> Commit 1a2b3c4 feat: Add hello function  
+
+#### thanks
+
+You're welcome!
+
+> Tokens: 1.2M sent, 1,050 received.  
+
+`
+
+func TestSessionIDFromHistory(t *testing.T) {
+	t.Parallel()
+
+	if got := SessionIDFromHistory([]byte(testHistory)); got != "aider-2026-03-02-143005" {
+		t.Errorf("SessionIDFromHistory() = %q, want aider-2026-03-02-143005", got)
+	}
+	if got := SessionIDFromHistory([]byte("#### no header\n")); got != "" {
+		t.Errorf("SessionIDFromHistory(no header) = %q, want empty", got)
+	}
+}
+
+func TestCurrentSession(t *testing.T) {
+	t.Parallel()
+
+	lines := splitLines([]byte(testHistory))
+	if lines[0] != "# aider chat started at 2026-03-02 14:30:05" {
+		t.Errorf("first line = %q, want the last chat's header", lines[0])
+	}
+	if LineCount([]byte(testHistory)) != len(lines) {
+		t.Errorf("LineCount() = %d, want %d", LineCount([]byte(testHistory)), len(lines))
+	}
+	if got := lines[len(lines)-1]; got != "> Tokens: 1.2M sent, 1,050 received.  " {
+		t.Errorf("last line = %q, trailing blank lines should be dropped", got)
+	}
+}
+
+func TestExtractUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	prompts := ExtractUserPrompts([]byte(testHistory), 0)
+	want := []string{"add a hello function\nwith a docstring", "thanks"}
+	if !slices.Equal(prompts, want) {
+		t.Errorf("ExtractUserPrompts() = %q, want %q", prompts, want)
+	}
+
+	// Line 23 is the "#### thanks" prompt
+	prompts = ExtractUserPrompts([]byte(testHistory), 23)
+	if !slices.Equal(prompts, []string{"thanks"}) {
+		t.Errorf("ExtractUserPrompts(23) = %q, want [thanks]", prompts)
+	}
+}
+
+func TestParseMessages_CodeFence(t *testing.T) {
+	t.Parallel()
+
+	for _, msg := range ParseMessages([]byte(testHistory), 0) {
+		if msg.Role == RoleUser && msg.Text == "not a prompt" {
+			t.Error("lines inside a code fence were parsed as a prompt")
+		}
+		if msg.Role == RoleOutput && msg.Text == "not output" {
+			t.Error("lines inside a code fence were parsed as Aider output")
+		}
+	}
+}
+
+func TestExtractLastAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	if got := ExtractLastAssistantMessage([]byte(testHistory)); got != "You're welcome!" {
+		t.Errorf("ExtractLastAssistantMessage() = %q, want %q", got, "You're welcome!")
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	files := ExtractModifiedFiles([]byte(testHistory), 0)
+	if want := []string{"main.py", "tests/test_main.py"}; !slices.Equal(files, want) {
+		t.Errorf("ExtractModifiedFiles() = %q, want %q (earlier chats excluded)", files, want)
+	}
+}
+
+func TestCalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testHistory), 0)
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 2_100+1_200_000 {
+		t.Errorf("InputTokens = %d, want %d", usage.InputTokens, 2_100+1_200_000)
+	}
+	if usage.CacheCreationTokens != 1_500 || usage.CacheReadTokens != 12_000 {
+		t.Errorf("cache tokens = %d write, %d read, want 1500 and 12000", usage.CacheCreationTokens, usage.CacheReadTokens)
+	}
+	if usage.OutputTokens != 120+1_050 {
+		t.Errorf("OutputTokens = %d, want %d", usage.OutputTokens, 120+1_050)
+	}
+}
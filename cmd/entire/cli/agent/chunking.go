@@ -1,8 +1,10 @@
 package agent
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/bits"
 	"sort"
 	"strings"
 )
@@ -14,6 +16,17 @@ const (
 
 	// ChunkSuffix is the format for chunk file suffixes (e.g., ".001", ".002")
 	ChunkSuffix = ".%03d"
+
+	// CDCMinChunkSize is the smallest content-defined chunk. Transcripts up to
+	// this size are stored as a single file.
+	CDCMinChunkSize = 32 * 1024 // 32KB
+
+	// CDCAvgChunkSize is the average distance between content-defined cut
+	// points after CDCMinChunkSize. Must be a power of two.
+	CDCAvgChunkSize = 128 * 1024 // 128KB
+
+	// maxChunkFiles is the number of chunk files ChunkSuffix can name.
+	maxChunkFiles = 999
 )
 
 // ChunkTranscript splits a transcript into chunks using the appropriate agent.
@@ -39,6 +52,37 @@ func ChunkTranscript(content []byte, agentType AgentType) ([][]byte, error) {
 	return ChunkJSONL(content, MaxChunkSize)
 }
 
+// ChunkTranscriptContentDefined splits a transcript for storage on the metadata branch.
+// JSONL transcripts are cut at content-defined line boundaries (see ChunkJSONLContentDefined),
+// so a transcript that only had lines appended shares all but its last chunks with the
+// earlier version and git stores those chunks once. Other formats, and transcripts too
+// large to name every chunk, fall back to ChunkTranscript.
+// The chunks reassemble with ReassembleTranscript like any other chunked transcript.
+func ChunkTranscriptContentDefined(content []byte, agentType AgentType) ([][]byte, error) {
+	if len(content) <= CDCMinChunkSize || !usesJSONLChunks(agentType) {
+		return ChunkTranscript(content, agentType)
+	}
+	chunks, err := ChunkJSONLContentDefined(content, CDCMinChunkSize, CDCAvgChunkSize, MaxChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) > maxChunkFiles {
+		return ChunkTranscript(content, agentType)
+	}
+	return chunks, nil
+}
+
+// usesJSONLChunks reports whether transcripts of agentType are chunked and
+// reassembled as JSONL.
+func usesJSONLChunks(agentType AgentType) bool {
+	if agentType == "" || agentType == AgentTypeClaudeCode {
+		return true
+	}
+	// Unknown agents fall back to JSONL in ChunkTranscript and ReassembleTranscript
+	_, err := GetByAgentType(agentType)
+	return err != nil
+}
+
 // ReassembleTranscript combines chunks back into a single transcript.
 // If agentType is empty or the agent is not found, falls back to JSONL (line-based) reassembly.
 func ReassembleTranscript(chunks [][]byte, agentType AgentType) ([]byte, error) {
@@ -102,6 +146,84 @@ func ChunkJSONL(content []byte, maxSize int) ([][]byte, error) {
 	return chunks, nil
 }
 
+// ChunkJSONLContentDefined splits JSONL content at line boundaries chosen by the content
+// itself. A gear rolling hash runs over the bytes of each chunk; once the chunk holds at
+// least minSize bytes and the hash hits a cut point (on average every avgSize bytes), the
+// chunk ends with the current line. Because cut points depend only on the bytes since the
+// previous cut, appending lines never moves earlier boundaries.
+// Chunks never exceed maxSize. Like ChunkJSONL, chunks omit the newline they were cut at,
+// so ReassembleJSONL restores the original content.
+func ChunkJSONLContentDefined(content []byte, minSize, avgSize, maxSize int) ([][]byte, error) {
+	if len(content) == 0 {
+		return [][]byte{}, nil
+	}
+
+	cutMask := gearCutMask(avgSize)
+	var chunks [][]byte
+	var hash uint64
+	start, pos, lineNum := 0, 0, 0
+	cut := false
+	for pos < len(content) {
+		lineNum++
+		lineEnd := len(content)
+		if i := bytes.IndexByte(content[pos:], '\n'); i >= 0 {
+			lineEnd = pos + i + 1
+		}
+		if lineEnd-pos > maxSize {
+			return nil, fmt.Errorf("JSONL line %d exceeds maximum chunk size (%d bytes > %d bytes); cannot split a single JSON object", lineNum, lineEnd-pos, maxSize)
+		}
+		// Close the chunk before a line that would push it over maxSize
+		if lineEnd-start > maxSize && pos > start {
+			chunks = append(chunks, content[start:pos-1])
+			start, hash, cut = pos, 0, false
+		}
+
+		for i := pos; i < lineEnd; i++ {
+			hash = (hash << 1) + gearTable[content[i]]
+			if !cut && i+1-start >= minSize && hash&cutMask == 0 {
+				cut = true
+			}
+		}
+		pos = lineEnd
+
+		if cut && pos < len(content) {
+			chunks = append(chunks, content[start:pos-1])
+			start, hash, cut = pos, 0, false
+		}
+	}
+	if start < len(content) {
+		chunks = append(chunks, content[start:])
+	}
+	return chunks, nil
+}
+
+// gearCutMask returns a mask over the high bits of the gear hash that is zero
+// on average once every avgSize bytes.
+func gearCutMask(avgSize int) uint64 {
+	n := bits.Len64(uint64(avgSize)) - 1 //nolint:gosec // sizes are positive
+	if n <= 0 {
+		return 0
+	}
+	return ^uint64(0) << (64 - n)
+}
+
+// gearTable maps each byte to a pseudo-random value for the gear hash. It is
+// generated from a fixed seed: changing it would move every cut point and stop
+// new chunks from matching previously stored ones.
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x656e74697265) // "entire"
+	for i := range table {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
 // ReassembleJSONL concatenates JSONL chunks with newlines.
 func ReassembleJSONL(chunks [][]byte) []byte {
 	var result strings.Builder
@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"bytes"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -230,3 +232,97 @@ func TestDetectAgentTypeFromContent(t *testing.T) {
 		})
 	}
 }
+
+// cdcTestTranscript builds a JSONL transcript of n varied lines.
+func cdcTestTranscript(first, n int) []byte {
+	var buf bytes.Buffer
+	for i := first; i < first+n; i++ {
+		fmt.Fprintf(&buf, `{"type":"assistant","uuid":"msg-%d","message":"%s"}`+"\n", i, strings.Repeat(fmt.Sprintf("step %d ", i*7919%1000), 40))
+	}
+	return buf.Bytes()
+}
+
+func TestChunkJSONLContentDefined_RoundTrip(t *testing.T) {
+	t.Parallel()
+	content := cdcTestTranscript(0, 3000) // ~1.4MB
+
+	for _, c := range [][]byte{content, bytes.TrimSuffix(content, []byte("\n"))} {
+		chunks, err := ChunkJSONLContentDefined(c, CDCMinChunkSize, CDCAvgChunkSize, MaxChunkSize)
+		if err != nil {
+			t.Fatalf("ChunkJSONLContentDefined error: %v", err)
+		}
+		if len(chunks) < 3 {
+			t.Errorf("Expected several chunks, got %d", len(chunks))
+		}
+		for i, chunk := range chunks[:len(chunks)-1] {
+			if len(chunk) < CDCMinChunkSize {
+				t.Errorf("Chunk %d is smaller than CDCMinChunkSize: %d", i, len(chunk))
+			}
+		}
+		if !bytes.Equal(ReassembleJSONL(chunks), c) {
+			t.Errorf("Reassembled content doesn't match original")
+		}
+	}
+}
+
+func TestChunkJSONLContentDefined_AppendKeepsEarlierChunks(t *testing.T) {
+	t.Parallel()
+	before := cdcTestTranscript(0, 2000)
+	after := append(append([]byte{}, before...), cdcTestTranscript(2000, 200)...)
+
+	chunksBefore, err := ChunkJSONLContentDefined(before, CDCMinChunkSize, CDCAvgChunkSize, MaxChunkSize)
+	if err != nil {
+		t.Fatalf("ChunkJSONLContentDefined error: %v", err)
+	}
+	chunksAfter, err := ChunkJSONLContentDefined(after, CDCMinChunkSize, CDCAvgChunkSize, MaxChunkSize)
+	if err != nil {
+		t.Fatalf("ChunkJSONLContentDefined error: %v", err)
+	}
+
+	// Every chunk but the last one (which the appended lines extend) is unchanged
+	if len(chunksAfter) < len(chunksBefore) {
+		t.Fatalf("Expected at least %d chunks after append, got %d", len(chunksBefore), len(chunksAfter))
+	}
+	for i := range len(chunksBefore) - 1 {
+		if !bytes.Equal(chunksBefore[i], chunksAfter[i]) {
+			t.Errorf("Chunk %d changed after appending lines", i)
+		}
+	}
+}
+
+func TestChunkJSONLContentDefined_MaxSize(t *testing.T) {
+	t.Parallel()
+	content := cdcTestTranscript(0, 200)
+
+	// A cut mask that never matches leaves only the max size to end chunks
+	chunks, err := ChunkJSONLContentDefined(content, 1024, 1<<62, 8*1024)
+	if err != nil {
+		t.Fatalf("ChunkJSONLContentDefined error: %v", err)
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > 8*1024 {
+			t.Errorf("Chunk %d exceeds max size: %d", i, len(chunk))
+		}
+	}
+	if !bytes.Equal(ReassembleJSONL(chunks), content) {
+		t.Errorf("Reassembled content doesn't match original")
+	}
+
+	_, err = ChunkJSONLContentDefined([]byte(strings.Repeat("x", 100)), 10, 16, 50)
+	if err == nil {
+		t.Error("Expected error for a line larger than the max size")
+	}
+}
+
+func TestChunkTranscriptContentDefined_SmallContent(t *testing.T) {
+	t.Parallel()
+	content := []byte(`{"type":"human","message":"hello"}` + "\n")
+
+	chunks, err := ChunkTranscriptContentDefined(content, AgentTypeClaudeCode)
+	if err != nil {
+		t.Fatalf("ChunkTranscriptContentDefined error: %v", err)
+	}
+	if len(chunks) != 1 || !bytes.Equal(chunks[0], content) {
+		t.Errorf("Expected small transcript as a single unchanged chunk, got %d chunks", len(chunks))
+	}
+}
@@ -385,3 +385,8 @@ func (c *ClaudeCodeAgent) ExtractAllModifiedFiles(sessionRef string, fromOffset
 func (c *ClaudeCodeAgent) CalculateTotalTokenUsage(sessionRef string, fromOffset int, subagentsDir string) (*agent.TokenUsage, error) {
 	return CalculateTotalTokenUsage(sessionRef, fromOffset, subagentsDir)
 }
+
+// ResumedSessionID returns the session a resumed Claude Code session continues.
+func (c *ClaudeCodeAgent) ResumedSessionID(sessionRef, sessionID string) (string, error) {
+	return ResumedSessionID(sessionRef, sessionID)
+}
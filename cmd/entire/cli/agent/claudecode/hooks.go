@@ -13,8 +13,9 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 )
 
-// Ensure ClaudeCodeAgent implements HookSupport
+// Ensure ClaudeCodeAgent implements HookSupport and HookVerifier
 var _ agent.HookSupport = (*ClaudeCodeAgent)(nil)
+var _ agent.HookVerifier = (*ClaudeCodeAgent)(nil)
 
 // Claude Code hook names - these become subcommands under `entire hooks claude-code`
 const (
@@ -40,6 +41,30 @@ var entireHookPrefixes = []string{
 	"go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go ",
 }
 
+// entireHooks are the hooks InstallHooks adds: the Claude Code hook type, the
+// tool matcher ("" for any), and the `entire hooks claude-code` verb it runs.
+var entireHooks = []struct {
+	hookType string
+	matcher  string
+	verb     string
+}{
+	{"SessionStart", "", "session-start"},
+	{"SessionEnd", "", "session-end"},
+	{"Stop", "", "stop"},
+	{"UserPromptSubmit", "", "user-prompt-submit"},
+	{"PreToolUse", "Task", "pre-task"},
+	{"PostToolUse", "Task", "post-task"},
+	{"PostToolUse", "TodoWrite", "post-todo"},
+}
+
+// hookCommand returns the command line of the hook that runs verb.
+func hookCommand(localDev bool, verb string) string {
+	if localDev {
+		return "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code " + verb
+	}
+	return "entire hooks claude-code " + verb
+}
+
 // InstallHooks installs Claude Code hooks in .claude/settings.json.
 // If force is true, removes existing Entire hooks before installing.
 // Returns the number of hooks installed.
@@ -112,24 +137,13 @@ func (c *ClaudeCodeAgent) InstallHooks(localDev bool, force bool) (int, error) {
 	}
 
 	// Define hook commands
-	var sessionStartCmd, sessionEndCmd, stopCmd, userPromptSubmitCmd, preTaskCmd, postTaskCmd, postTodoCmd string
-	if localDev {
-		sessionStartCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code session-start"
-		sessionEndCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code session-end"
-		stopCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code stop"
-		userPromptSubmitCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code user-prompt-submit"
-		preTaskCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code pre-task"
-		postTaskCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code post-task"
-		postTodoCmd = "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code post-todo"
-	} else {
-		sessionStartCmd = "entire hooks claude-code session-start"
-		sessionEndCmd = "entire hooks claude-code session-end"
-		stopCmd = "entire hooks claude-code stop"
-		userPromptSubmitCmd = "entire hooks claude-code user-prompt-submit"
-		preTaskCmd = "entire hooks claude-code pre-task"
-		postTaskCmd = "entire hooks claude-code post-task"
-		postTodoCmd = "entire hooks claude-code post-todo"
-	}
+	sessionStartCmd := hookCommand(localDev, "session-start")
+	sessionEndCmd := hookCommand(localDev, "session-end")
+	stopCmd := hookCommand(localDev, "stop")
+	userPromptSubmitCmd := hookCommand(localDev, "user-prompt-submit")
+	preTaskCmd := hookCommand(localDev, "pre-task")
+	postTaskCmd := hookCommand(localDev, "post-task")
+	postTodoCmd := hookCommand(localDev, "post-todo")
 
 	count := 0
 
@@ -394,6 +408,46 @@ func (c *ClaudeCodeAgent) AreHooksInstalled() bool {
 		hookCommandExists(settings.Hooks.Stop, "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go rewind claude-hook --stop")
 }
 
+// MissingHooks returns the hook commands InstallHooks would add to
+// .claude/settings.json.
+func (c *ClaudeCodeAgent) MissingHooks(localDev bool) ([]string, error) {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	settingsPath := filepath.Join(repoRoot, ".claude", ClaudeSettingsFileName)
+	data, err := os.ReadFile(settingsPath) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read settings.json: %w", err)
+	}
+
+	var rawHooks map[string]json.RawMessage
+	if len(data) > 0 {
+		var rawSettings map[string]json.RawMessage
+		if err := json.Unmarshal(data, &rawSettings); err != nil {
+			return nil, fmt.Errorf("failed to parse settings.json: %w", err)
+		}
+		if hooksRaw, ok := rawSettings["hooks"]; ok {
+			if err := json.Unmarshal(hooksRaw, &rawHooks); err != nil {
+				return nil, fmt.Errorf("failed to parse hooks in settings.json: %w", err)
+			}
+		}
+	}
+
+	var missing []string
+	for _, hook := range entireHooks {
+		var matchers []ClaudeHookMatcher
+		parseHookType(rawHooks, hook.hookType, &matchers)
+		command := hookCommand(localDev, hook.verb)
+		if hook.matcher == "" && hookCommandExists(matchers, command) ||
+			hook.matcher != "" && hookCommandExistsWithMatcher(matchers, hook.matcher, command) {
+			continue
+		}
+		missing = append(missing, command)
+	}
+	return missing, nil
+}
+
 // Helper functions for hook management
 
 func hookCommandExists(matchers []ClaudeHookMatcher, command string) bool {
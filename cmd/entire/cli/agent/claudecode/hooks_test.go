@@ -655,3 +655,37 @@ func TestUninstallHooks_PreservesUnknownHookTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestMissingHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &ClaudeCodeAgent{}
+	missing, err := agent.MissingHooks(false)
+	if err != nil {
+		t.Fatalf("MissingHooks() error = %v", err)
+	}
+	if len(missing) != len(entireHooks) {
+		t.Errorf("MissingHooks() before install = %v, want all %d hooks", missing, len(entireHooks))
+	}
+
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	missing, err = agent.MissingHooks(false)
+	if err != nil {
+		t.Fatalf("MissingHooks() error = %v", err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("MissingHooks() after install = %v, want none", missing)
+	}
+
+	// Hooks installed for local development don't match the release command lines
+	missing, err = agent.MissingHooks(true)
+	if err != nil {
+		t.Fatalf("MissingHooks() error = %v", err)
+	}
+	if !slices.Contains(missing, "go run ${CLAUDE_PROJECT_DIR}/cmd/entire/main.go hooks claude-code stop") {
+		t.Errorf("MissingHooks(localDev) = %v, want the local-dev stop hook", missing)
+	}
+}
@@ -22,6 +22,7 @@ var (
 	_ agent.TranscriptPreparer     = (*ClaudeCodeAgent)(nil)
 	_ agent.TokenCalculator        = (*ClaudeCodeAgent)(nil)
 	_ agent.SubagentAwareExtractor = (*ClaudeCodeAgent)(nil)
+	_ agent.ResumeDetector         = (*ClaudeCodeAgent)(nil)
 )
 
 // HookNames returns the hook verbs Claude Code supports.
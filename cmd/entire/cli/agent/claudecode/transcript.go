@@ -1,9 +1,13 @@
 package claudecode
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -183,6 +187,7 @@ func FindCheckpointUUID(lines []TranscriptLine, toolUseID string) (string, bool)
 func CalculateTokenUsage(transcript []TranscriptLine) *agent.TokenUsage {
 	// Map from message.id to the usage with highest output_tokens
 	usageByMessageID := make(map[string]messageUsage)
+	modelByMessageID := make(map[string]string)
 
 	for _, line := range transcript {
 		if line.Type != "assistant" {
@@ -203,6 +208,9 @@ func CalculateTokenUsage(transcript []TranscriptLine) *agent.TokenUsage {
 		if !exists || msg.Usage.OutputTokens > existing.OutputTokens {
 			usageByMessageID[msg.ID] = msg.Usage
 		}
+		if msg.Model != "" {
+			modelByMessageID[msg.ID] = msg.Model
+		}
 	}
 
 	// Sum up all unique messages
@@ -216,6 +224,12 @@ func CalculateTokenUsage(transcript []TranscriptLine) *agent.TokenUsage {
 		usage.OutputTokens += u.OutputTokens
 	}
 
+	callsByModel := make(map[string]int)
+	for _, model := range modelByMessageID {
+		callsByModel[model]++
+	}
+	usage.Model = agent.MostUsedModel(callsByModel)
+
 	return usage
 }
 
@@ -360,6 +374,9 @@ func CalculateTotalTokenUsage(transcriptPath string, startLine int, subagentsDir
 			subagentUsage.CacheReadTokens += agentUsage.CacheReadTokens
 			subagentUsage.OutputTokens += agentUsage.OutputTokens
 			subagentUsage.APICallCount += agentUsage.APICallCount
+			if subagentUsage.Model == "" {
+				subagentUsage.Model = agentUsage.Model
+			}
 		}
 		if subagentUsage.APICallCount > 0 {
 			mainUsage.SubagentTokens = subagentUsage
@@ -414,3 +431,39 @@ func ExtractAllModifiedFiles(transcriptPath string, startLine int, subagentsDir
 
 	return files, nil
 }
+
+// ResumedSessionID returns the ID of the session that the transcript at
+// transcriptPath resumes, or "" if it started fresh. When Claude Code resumes
+// a conversation (--resume, --continue) it issues a new session ID and copies
+// the earlier messages into the new transcript with their original sessionId,
+// so the resumed session is the last other ID seen before the first line of
+// sessionID itself. Messages from sessions further back come before it.
+func ResumedSessionID(transcriptPath, sessionID string) (string, error) {
+	f, err := os.Open(transcriptPath) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return "", fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+
+	var resumed string
+	reader := bufio.NewReader(f)
+	for {
+		lineBytes, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return "", fmt.Errorf("failed to read transcript: %w", readErr)
+		}
+		var line struct {
+			SessionID string `json:"sessionId"`
+		}
+		if len(bytes.TrimSpace(lineBytes)) > 0 && json.Unmarshal(lineBytes, &line) == nil && line.SessionID != "" {
+			if line.SessionID == sessionID {
+				break
+			}
+			resumed = line.SessionID
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+	}
+	return resumed, nil
+}
@@ -287,6 +287,31 @@ func TestCalculateTokenUsage_BasicMessages(t *testing.T) {
 	}
 }
 
+func TestCalculateTokenUsage_Model(t *testing.T) {
+	t.Parallel()
+
+	line := func(id, model string) TranscriptLine {
+		return TranscriptLine{
+			Type: "assistant",
+			Message: mustMarshal(t, map[string]interface{}{
+				"id":    id,
+				"model": model,
+				"usage": map[string]int{"input_tokens": 1, "output_tokens": 1},
+			}),
+		}
+	}
+	transcript := []TranscriptLine{
+		line("msg_001", "claude-opus-4-1-20250805"),
+		line("msg_002", "claude-sonnet-4-5-20250929"),
+		line("msg_002", "claude-sonnet-4-5-20250929"),
+		line("msg_003", "claude-sonnet-4-5-20250929"),
+	}
+
+	if got := CalculateTokenUsage(transcript).Model; got != "claude-sonnet-4-5-20250929" {
+		t.Errorf("Model = %q, want the model that served the most messages", got)
+	}
+}
+
 func TestCalculateTokenUsage_StreamingDeduplication(t *testing.T) {
 	// Simulate streaming: multiple rows with same message ID, increasing output_tokens
 	transcript := []TranscriptLine{
@@ -873,3 +898,59 @@ func TestExtractAllModifiedFiles_SubagentOnlyChanges(t *testing.T) {
 		t.Errorf("missing expected file %q", f)
 	}
 }
+
+func TestResumedSessionID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		transcript string
+		want       string
+	}{
+		{
+			name: "fresh session",
+			transcript: `{"type":"user","uuid":"u1","sessionId":"current","message":{"content":"hello"}}
+`,
+			want: "",
+		},
+		{
+			name: "resumed session",
+			transcript: `{"type":"summary","summary":"Earlier work"}
+{"type":"user","uuid":"u1","sessionId":"parent","message":{"content":"hello"}}
+{"type":"assistant","uuid":"a1","sessionId":"parent","message":{"content":[]}}
+{"type":"user","uuid":"u2","sessionId":"current","message":{"content":"go on"}}
+`,
+			want: "parent",
+		},
+		{
+			name: "resumed twice",
+			transcript: `{"type":"user","uuid":"u1","sessionId":"grandparent","message":{"content":"hello"}}
+{"type":"user","uuid":"u2","sessionId":"parent","message":{"content":"more"}}
+{"type":"user","uuid":"u3","sessionId":"current","message":{"content":"go on"}}
+`,
+			want: "parent",
+		},
+		{
+			name:       "resumed before the first new message",
+			transcript: `{"type":"user","uuid":"u1","sessionId":"parent","message":{"content":"hello"}}`,
+			want:       "parent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := t.TempDir() + "/transcript.jsonl"
+			if err := os.WriteFile(path, []byte(tt.transcript), 0o600); err != nil {
+				t.Fatalf("failed to write transcript: %v", err)
+			}
+			got, err := ResumedSessionID(path, "current")
+			if err != nil {
+				t.Fatalf("ResumedSessionID() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResumedSessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
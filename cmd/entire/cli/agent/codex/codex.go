@@ -0,0 +1,207 @@
+// Package codex implements the Agent interface for the OpenAI Codex CLI.
+package codex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCodex, NewCodexAgent)
+}
+
+// CodexAgent implements the Agent interface for the Codex CLI.
+//
+//nolint:revive // CodexAgent is clearer than Agent in this context
+type CodexAgent struct{}
+
+func NewCodexAgent() agent.Agent {
+	return &CodexAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CodexAgent) Name() agent.AgentName {
+	return agent.AgentNameCodex
+}
+
+// Type returns the agent type identifier.
+func (c *CodexAgent) Type() agent.AgentType {
+	return agent.AgentTypeCodex
+}
+
+// Description returns a human-readable description.
+func (c *CodexAgent) Description() string {
+	return "Codex CLI - OpenAI's terminal coding agent"
+}
+
+func (c *CodexAgent) IsPreview() bool { return true }
+
+// DetectPresence checks if Codex is configured in the repository.
+func (c *CodexAgent) DetectPresence() (bool, error) {
+	// Get worktree root to check for .codex directory
+	// This is needed because the CLI may be run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	if _, err := os.Stat(filepath.Join(repoRoot, ".codex")); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CodexAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns directories that Codex uses for config/state.
+func (c *CodexAgent) ProtectedDirs() []string { return []string{".codex"} }
+
+// GetSessionDir returns the directory where Codex stores rollout files.
+// Codex keeps them under $CODEX_HOME/sessions (~/.codex/sessions by default)
+// for all projects, in YYYY/MM/DD subdirectories.
+func (c *CodexAgent) GetSessionDir(_ string) (string, error) {
+	// Check for test environment override
+	if override := os.Getenv("ENTIRE_TEST_CODEX_SESSION_DIR"); override != "" {
+		return override, nil
+	}
+
+	if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+		return filepath.Join(codexHome, "sessions"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".codex", "sessions"), nil
+}
+
+// ResolveSessionFile returns the path to a Codex rollout file. Rollouts are
+// named rollout-<time>-<session-id>.jsonl; if none exists for the session,
+// a path in today's directory is returned so Codex can find it on resume.
+func (c *CodexAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	if path := findRolloutByID(sessionDir, agentSessionID); path != "" {
+		return path
+	}
+	now := time.Now()
+	return filepath.Join(sessionDir, now.Format("2006"), now.Format("01"), now.Format("02"),
+		"rollout-"+now.Format("2006-01-02T15-04-05")+"-"+agentSessionID+".jsonl")
+}
+
+// findRolloutByID returns the rollout file for a session, or "" if there is none.
+func findRolloutByID(sessionDir, sessionID string) string {
+	if sessionID == "" {
+		return ""
+	}
+	matches, err := filepath.Glob(filepath.Join(sessionDir, "*", "*", "*", "rollout-*-"+sessionID+".jsonl"))
+	if err != nil || len(matches) == 0 {
+		return ""
+	}
+	slices.Sort(matches)
+	return matches[len(matches)-1]
+}
+
+// findLatestRollout returns the newest rollout file whose session ran in cwd,
+// or "" if there is none. Used when Codex doesn't report the session ID.
+func findLatestRollout(sessionDir, cwd string) string {
+	matches, err := filepath.Glob(filepath.Join(sessionDir, "*", "*", "*", "rollout-*.jsonl"))
+	if err != nil {
+		return ""
+	}
+	// Date directories and file names sort chronologically
+	slices.Sort(matches)
+	for i := len(matches) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(matches[i]) //nolint:gosec // Path comes from Codex session directory
+		if err != nil {
+			continue
+		}
+		if _, sessionCwd := SessionMeta(data); sessionCwd != "" && filepath.Clean(sessionCwd) == filepath.Clean(cwd) {
+			return matches[i]
+		}
+	}
+	return ""
+}
+
+// ReadSession reads a session from a Codex rollout file.
+// The session data is stored in NativeData as raw JSONL bytes.
+func (c *CodexAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (rollout path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(ParseRollout(data)),
+	}, nil
+}
+
+// WriteSession writes a session to a Codex rollout file.
+// Uses the NativeData field which contains raw JSONL bytes.
+func (c *CodexAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Codex
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (rollout path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(session.SessionRef), 0o750); err != nil {
+		return fmt.Errorf("failed to create rollout directory: %w", err)
+	}
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write rollout: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume a Codex session.
+func (c *CodexAgent) FormatResumeCommand(sessionID string) string {
+	return "codex resume " + sessionID
+}
+
+// ChunkTranscript splits a JSONL rollout at line boundaries.
+func (c *CodexAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk JSONL transcript: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates JSONL chunks with newlines.
+func (c *CodexAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
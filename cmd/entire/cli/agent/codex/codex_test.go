@@ -0,0 +1,105 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestCodexAgent_Registered(t *testing.T) {
+	t.Parallel()
+
+	ag, err := agent.Get(agent.AgentNameCodex)
+	if err != nil {
+		t.Fatalf("agent.Get(codex) error = %v", err)
+	}
+	if ag.Type() != agent.AgentTypeCodex {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeCodex)
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	if present, err := ag.DetectPresence(); err != nil || present {
+		t.Errorf("DetectPresence() without .codex = %v, %v, want false", present, err)
+	}
+	if err := os.Mkdir(filepath.Join(tempDir, ".codex"), 0o750); err != nil {
+		t.Fatalf("failed to create .codex: %v", err)
+	}
+	if present, err := ag.DetectPresence(); err != nil || !present {
+		t.Errorf("DetectPresence() with .codex = %v, %v, want true", present, err)
+	}
+}
+
+func TestGetSessionDir_CodexHome(t *testing.T) {
+	t.Setenv("ENTIRE_TEST_CODEX_SESSION_DIR", "")
+	t.Setenv("CODEX_HOME", "/opt/codex")
+
+	dir, err := (&CodexAgent{}).GetSessionDir("/repo")
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	if want := filepath.Join("/opt/codex", "sessions"); dir != want {
+		t.Errorf("GetSessionDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestResolveSessionFile(t *testing.T) {
+	t.Parallel()
+
+	sessionDir := t.TempDir()
+	ag := &CodexAgent{}
+
+	// No rollout yet: a path in today's directory, named after the session
+	path := ag.ResolveSessionFile(sessionDir, testSessionID)
+	if !strings.HasPrefix(path, sessionDir) || !strings.HasSuffix(path, "-"+testSessionID+".jsonl") {
+		t.Errorf("ResolveSessionFile() = %q", path)
+	}
+
+	existing := filepath.Join(sessionDir, "2025", "09", "20", "rollout-2025-09-20T10-00-00-"+testSessionID+".jsonl")
+	if err := os.MkdirAll(filepath.Dir(existing), 0o750); err != nil {
+		t.Fatalf("failed to create rollout directory: %v", err)
+	}
+	if err := os.WriteFile(existing, []byte(testRollout), 0o600); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+	if got := ag.ResolveSessionFile(sessionDir, testSessionID); got != existing {
+		t.Errorf("ResolveSessionFile() = %q, want %q", got, existing)
+	}
+}
+
+func TestWriteSession_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "2025", "09", "20", "rollout.jsonl")
+	ag := &CodexAgent{}
+	if err := ag.WriteSession(&agent.AgentSession{
+		SessionID:  testSessionID,
+		AgentName:  agent.AgentNameCodex,
+		SessionRef: path,
+		NativeData: []byte(testRollout),
+	}); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: testSessionID, SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(session.NativeData) != testRollout {
+		t.Error("ReadSession() NativeData differs from written rollout")
+	}
+	if len(session.ModifiedFiles) != 4 {
+		t.Errorf("ModifiedFiles = %q, want 4 files", session.ModifiedFiles)
+	}
+
+	if err := ag.WriteSession(&agent.AgentSession{AgentName: agent.AgentNameCursor, SessionRef: path, NativeData: []byte("{}")}); err == nil {
+		t.Error("WriteSession() should reject another agent's session")
+	}
+}
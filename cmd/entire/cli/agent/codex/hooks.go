@@ -0,0 +1,203 @@
+package codex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Ensure CodexAgent implements HookSupport
+var _ agent.HookSupport = (*CodexAgent)(nil)
+
+// Codex hook names - these become subcommands under `entire hooks codex`.
+// Codex has a single hook, its notify program, which runs after each turn;
+// Entire's notify command calls both verbs with the notification on stdin.
+const (
+	HookNameSessionStart = "session-start"
+	HookNameTurnEnd      = "turn-end"
+)
+
+// ConfigFileName is the project config file Codex reads from .codex/.
+const ConfigFileName = "config.toml"
+
+// configKeyNotify is the top-level config key naming Codex's notify program.
+const configKeyNotify = "notify"
+
+// entireConfigComment marks the config lines Entire added.
+const entireConfigComment = "# Entire: record a checkpoint each time Codex finishes a turn"
+
+// entireHookPrefixes are command prefixes that identify Entire hooks. Codex
+// passes the notification as an argument, so Entire's commands are wrapped
+// in a shell script that pipes it to them.
+var entireHookPrefixes = []string{
+	"entire hooks codex ",
+	"go run ./cmd/entire/main.go hooks codex ",
+}
+
+// configPath returns the path of .codex/config.toml in the repository.
+func configPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".codex", ConfigFileName)
+}
+
+// notifyLine returns the config line that makes Codex run Entire's hooks.
+// The notification is the last argument, $1 of the script.
+func notifyLine(localDev bool) string {
+	cmdPrefix := "entire hooks codex "
+	if localDev {
+		cmdPrefix = "go run ./cmd/entire/main.go hooks codex "
+	}
+	script := fmt.Sprintf(`printf '%%s' "$1" | %s%s; printf '%%s' "$1" | %s%s`,
+		cmdPrefix, HookNameSessionStart, cmdPrefix, HookNameTurnEnd)
+
+	args := []string{"sh", "-c", script, "entire"}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		// Go's quoting of ASCII strings is a valid TOML basic string
+		quoted[i] = strconv.Quote(arg)
+	}
+	return configKeyNotify + " = [" + strings.Join(quoted, ", ") + "]"
+}
+
+// InstallHooks sets Entire as Codex's notify program in .codex/config.toml.
+// The file is edited line by line so the user's settings and comments are kept.
+// If force is true, reinstalls even when the same command is already set.
+// Returns the number of hooks installed.
+func (c *CodexAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	// Use repo root instead of CWD to find .codex directory
+	// This ensures hooks are installed correctly when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Fallback to CWD if not in a git repo (e.g., during tests)
+		repoRoot, err = os.Getwd() //nolint:forbidigo // Intentional fallback when WorktreeRoot() fails (tests run outside git repos)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	path := configPath(repoRoot)
+	lines, err := agent.ReadConfigLines(path)
+	if err != nil {
+		return 0, err
+	}
+
+	line := notifyLine(localDev)
+	if idx := notifyIndex(lines); idx >= 0 {
+		if !isEntireHook(lines[idx]) {
+			return 0, fmt.Errorf(".codex/%s already sets %s; Entire needs this hook, remove it and try again", ConfigFileName, configKeyNotify)
+		}
+		// Check for idempotency - if the same command is already set, nothing to do
+		if !force && lines[idx] == line {
+			return 0, nil
+		}
+	}
+
+	lines = removeEntireConfig(lines)
+
+	// Top-level keys must come before the first table
+	insertAt := len(lines)
+	for i, l := range lines {
+		if strings.HasPrefix(strings.TrimSpace(l), "[") {
+			insertAt = i
+			break
+		}
+	}
+	entireLines := []string{entireConfigComment, line}
+	if insertAt < len(lines) {
+		entireLines = append(entireLines, "")
+	}
+	lines = append(lines[:insertAt], append(entireLines, lines[insertAt:]...)...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create .codex directory: %w", err)
+	}
+	if err := agent.WriteConfigLines(path, lines); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// UninstallHooks removes Entire's notify program from .codex/config.toml.
+func (c *CodexAgent) UninstallHooks() error {
+	// Use repo root to find .codex directory when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	path := configPath(repoRoot)
+	if _, err := os.Stat(path); err != nil {
+		return nil //nolint:nilerr // No config file means nothing to uninstall
+	}
+
+	lines, err := agent.ReadConfigLines(path)
+	if err != nil {
+		return err
+	}
+	return agent.WriteConfigLines(path, removeEntireConfig(lines))
+}
+
+// AreHooksInstalled checks if Entire is Codex's notify program.
+func (c *CodexAgent) AreHooksInstalled() bool {
+	// Use repo root to find .codex directory when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	lines, err := agent.ReadConfigLines(configPath(repoRoot))
+	if err != nil {
+		return false
+	}
+	idx := notifyIndex(lines)
+	return idx >= 0 && isEntireHook(lines[idx])
+}
+
+// notifyIndex returns the index of the line setting the top-level notify
+// key, or -1 if it is not set. Keys after the first table belong to it.
+func notifyIndex(lines []string) int {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			return -1
+		}
+		if rest, ok := strings.CutPrefix(trimmed, configKeyNotify); ok && strings.HasPrefix(strings.TrimSpace(rest), "=") {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeEntireConfig removes the lines Entire added: its comment, the notify
+// line, and the blank line separating them from a following table.
+func removeEntireConfig(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	removed := false
+	for i, line := range lines {
+		switch {
+		case line == entireConfigComment:
+			continue
+		case i == notifyIndex(lines) && isEntireHook(line):
+			removed = true
+			continue
+		case removed && line == "" && i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "["):
+			removed = false
+			continue
+		}
+		removed = false
+		result = append(result, line)
+	}
+	return result
+}
+
+// isEntireHook checks if a notify config line runs an Entire hook
+func isEntireHook(line string) bool {
+	for _, prefix := range entireHookPrefixes {
+		if strings.Contains(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallHooks_FreshInstall(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CodexAgent{}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("InstallHooks() count = %d, want 1", count)
+	}
+
+	config := readConfig(t, tempDir)
+	want := entireConfigComment + "\n" +
+		`notify = ["sh", "-c", "printf '%s' \"$1\" | entire hooks codex session-start; printf '%s' \"$1\" | entire hooks codex turn-end", "entire"]` + "\n"
+	if config != want {
+		t.Errorf("config = %q, want %q", config, want)
+	}
+	if !agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestInstallHooks_Idempotent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CodexAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("first InstallHooks() error = %v", err)
+	}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+
+	if n := strings.Count(readConfig(t, tempDir), "notify ="); n != 1 {
+		t.Errorf("notify set %d times, want 1", n)
+	}
+}
+
+func TestInstallHooks_BeforeFirstTable(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	writeConfig(t, tempDir, "# my settings\nmodel = \"gpt-5-codex\"\n\n[mcp_servers.docs]\ncommand = \"docs-server\"\n")
+
+	agent := &CodexAgent{}
+	if _, err := agent.InstallHooks(true, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	config := readConfig(t, tempDir)
+	notifyAt := strings.Index(config, "notify = ")
+	tableAt := strings.Index(config, "[mcp_servers.docs]")
+	if notifyAt < 0 || notifyAt > tableAt {
+		t.Errorf("notify should be set before the first table:\n%s", config)
+	}
+	if !strings.Contains(config, "go run ./cmd/entire/main.go hooks codex turn-end") {
+		t.Errorf("config missing local dev command:\n%s", config)
+	}
+
+	if err := agent.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if got, want := readConfig(t, tempDir), "# my settings\nmodel = \"gpt-5-codex\"\n\n[mcp_servers.docs]\ncommand = \"docs-server\"\n"; got != want {
+		t.Errorf("config after uninstall = %q, want %q", got, want)
+	}
+}
+
+func TestInstallHooks_UserNotify(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	writeConfig(t, tempDir, "notify = [\"notify-send\", \"Codex\"]\n")
+
+	if _, err := (&CodexAgent{}).InstallHooks(false, false); err == nil {
+		t.Fatal("InstallHooks() should fail when the user has their own notify program")
+	}
+	if got := readConfig(t, tempDir); got != "notify = [\"notify-send\", \"Codex\"]\n" {
+		t.Errorf("config was modified: %q", got)
+	}
+}
+
+func TestInstallHooks_TableNotifyIgnored(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+	// notify under a table is not Codex's notify program
+	writeConfig(t, tempDir, "[profiles.ci]\nnotify = [\"true\"]\n")
+
+	agent := &CodexAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if !agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestUninstallHooks_NoConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CodexAgent{}
+	if err := agent.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true without config")
+	}
+}
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, ".codex"), 0o750); err != nil {
+		t.Fatalf("failed to create .codex: %v", err)
+	}
+	if err := os.WriteFile(configPath(dir), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func readConfig(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(configPath(dir))
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	return string(data)
+}
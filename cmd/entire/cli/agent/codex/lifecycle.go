@@ -0,0 +1,164 @@
+package codex
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Compile-time interface assertions for new interfaces.
+var (
+	_ agent.TranscriptAnalyzer = (*CodexAgent)(nil)
+	_ agent.TokenCalculator    = (*CodexAgent)(nil)
+)
+
+// HookNames returns the hook verbs Codex supports.
+// These become subcommands: entire hooks codex <verb>
+func (c *CodexAgent) HookNames() []string {
+	return []string{HookNameSessionStart, HookNameTurnEnd}
+}
+
+// ParseHookEvent translates a Codex notification into a normalized lifecycle
+// Event. Both verbs read the agent-turn-complete notification: turn-end ends
+// the turn, and session-start starts the session on its first turn only,
+// since Codex has no hook that runs when a session starts.
+// Returns nil if the hook has no lifecycle significance or the session's
+// rollout file can't be found.
+func (c *CodexAgent) ParseHookEvent(hookName string, stdin io.Reader) (*agent.Event, error) {
+	var eventType agent.EventType
+	switch hookName {
+	case HookNameSessionStart:
+		eventType = agent.SessionStart
+	case HookNameTurnEnd:
+		eventType = agent.TurnEnd
+	default:
+		return nil, nil //nolint:nilnil // Unknown hooks have no lifecycle action
+	}
+
+	raw, err := agent.ReadAndParseHookInput[notifyPayload](stdin)
+	if err != nil {
+		return nil, err
+	}
+	if raw.Type != notifyTypeTurnComplete {
+		return nil, nil //nolint:nilnil // Other notifications have no lifecycle action
+	}
+
+	sessionDir, err := c.GetSessionDir("")
+	if err != nil {
+		return nil, err
+	}
+	rolloutPath := findRolloutByID(sessionDir, raw.ThreadID)
+	if rolloutPath == "" && raw.ThreadID == "" {
+		cwd := raw.Cwd
+		if cwd == "" {
+			if cwd, err = paths.WorktreeRoot(); err != nil {
+				cwd = "." // Fallback to CWD if not in a git repo
+			}
+		}
+		rolloutPath = findLatestRollout(sessionDir, cwd)
+	}
+	if rolloutPath == "" {
+		return nil, nil //nolint:nilnil // nil event = no lifecycle action
+	}
+
+	data, err := os.ReadFile(rolloutPath) //nolint:gosec // Path comes from Codex session directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	sessionID, _ := SessionMeta(data)
+	if sessionID == "" {
+		sessionID = raw.ThreadID
+	}
+	if eventType == agent.SessionStart && len(ExtractAllUserPrompts(data)) > 1 {
+		return nil, nil //nolint:nilnil // Session already started on an earlier turn
+	}
+
+	return &agent.Event{
+		Type:       eventType,
+		SessionID:  sessionID,
+		SessionRef: rolloutPath,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// ReadTranscript reads the raw JSONL rollout bytes for a session.
+func (c *CodexAgent) ReadTranscript(sessionRef string) ([]byte, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+	return data, nil
+}
+
+// ExtractPrompts extracts user prompts from the rollout starting at the given line offset.
+func (c *CodexAgent) ExtractPrompts(sessionRef string, fromOffset int) ([]string, error) {
+	data, err := c.ReadTranscript(sessionRef)
+	if err != nil {
+		return nil, err
+	}
+	lines, _ := ParseRolloutFromLine(data, fromOffset)
+	return ExtractUserPrompts(lines), nil
+}
+
+// ExtractSummary extracts the last message Codex sent as a session summary.
+func (c *CodexAgent) ExtractSummary(sessionRef string) (string, error) {
+	data, err := c.ReadTranscript(sessionRef)
+	if err != nil {
+		return "", err
+	}
+	return ExtractLastAssistantMessage(ParseRollout(data)), nil
+}
+
+// CalculateTokenUsage sums Codex's token counts starting at the given line offset.
+func (c *CodexAgent) CalculateTokenUsage(sessionRef string, fromOffset int) (*agent.TokenUsage, error) {
+	data, err := c.ReadTranscript(sessionRef)
+	if err != nil {
+		return nil, err
+	}
+	lines, _ := ParseRolloutFromLine(data, fromOffset)
+	return CalculateTokenUsage(lines), nil
+}
+
+// GetTranscriptPosition returns the current line count of a Codex rollout.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CodexAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Codex session directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read rollout: %w", err)
+	}
+
+	return len(splitLines(data)), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given line number.
+// Returns:
+//   - files: list of file paths named in apply_patch calls
+//   - currentPosition: total number of lines in the rollout
+//   - error: any error encountered during reading
+func (c *CodexAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	data, readErr := os.ReadFile(path) //nolint:gosec // Path comes from Codex session directory
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read rollout: %w", readErr)
+	}
+
+	lines, position := ParseRolloutFromLine(data, startOffset)
+	return ExtractModifiedFiles(lines), position, nil
+}
@@ -0,0 +1,158 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+const testSessionID = "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b"
+
+// writeRollout writes a rollout for testSessionID into a session directory
+// set as the test override, and returns its path.
+func writeRollout(t *testing.T, content string) string {
+	t.Helper()
+	sessionDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CODEX_SESSION_DIR", sessionDir)
+
+	path := filepath.Join(sessionDir, "2025", "09", "20", "rollout-2025-09-20T10-00-00-"+testSessionID+".jsonl")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatalf("failed to create rollout directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+	return path
+}
+
+// firstTurn is the part of testRollout up to the second prompt.
+func firstTurn() string {
+	lines := strings.SplitAfter(testRollout, "\n")
+	return strings.Join(lines[:secondTurnLine], "")
+}
+
+func TestParseHookEvent_TurnEnd(t *testing.T) {
+	path := writeRollout(t, testRollout)
+
+	input := `{"type":"agent-turn-complete","thread-id":"` + testSessionID + `","turn-id":"1","cwd":"/repo","input-messages":["Delete the old notes"],"last-assistant-message":"Deleted NOTES.md."}`
+	event, err := (&CodexAgent{}).ParseHookEvent(HookNameTurnEnd, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event == nil {
+		t.Fatal("ParseHookEvent() returned nil event")
+	}
+	if event.Type != agent.TurnEnd {
+		t.Errorf("Type = %v, want TurnEnd", event.Type)
+	}
+	if event.SessionID != testSessionID {
+		t.Errorf("SessionID = %q, want %q", event.SessionID, testSessionID)
+	}
+	if event.SessionRef != path {
+		t.Errorf("SessionRef = %q, want %q", event.SessionRef, path)
+	}
+}
+
+func TestParseHookEvent_SessionStartOnFirstTurnOnly(t *testing.T) {
+	input := `{"type":"agent-turn-complete","thread-id":"` + testSessionID + `","cwd":"/repo"}`
+
+	writeRollout(t, firstTurn())
+	event, err := (&CodexAgent{}).ParseHookEvent(HookNameSessionStart, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event == nil || event.Type != agent.SessionStart || event.SessionID != testSessionID {
+		t.Errorf("ParseHookEvent() on first turn = %+v, want SessionStart for %s", event, testSessionID)
+	}
+
+	writeRollout(t, testRollout)
+	event, err = (&CodexAgent{}).ParseHookEvent(HookNameSessionStart, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event != nil {
+		t.Errorf("ParseHookEvent() on second turn = %+v, want nil", event)
+	}
+}
+
+func TestParseHookEvent_WithoutThreadID(t *testing.T) {
+	path := writeRollout(t, testRollout)
+
+	// Older Codex versions don't send the thread ID; the newest rollout for cwd is used
+	event, err := (&CodexAgent{}).ParseHookEvent(HookNameTurnEnd, strings.NewReader(`{"type":"agent-turn-complete","turn-id":"1","cwd":"/repo"}`))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event == nil || event.SessionRef != path || event.SessionID != testSessionID {
+		t.Errorf("ParseHookEvent() = %+v, want rollout %s", event, path)
+	}
+
+	event, err = (&CodexAgent{}).ParseHookEvent(HookNameTurnEnd, strings.NewReader(`{"type":"agent-turn-complete","turn-id":"1","cwd":"/elsewhere"}`))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event != nil {
+		t.Errorf("ParseHookEvent() for other cwd = %+v, want nil", event)
+	}
+}
+
+func TestParseHookEvent_NoLifecycleAction(t *testing.T) {
+	writeRollout(t, testRollout)
+
+	tests := []struct {
+		name     string
+		hookName string
+		input    string
+	}{
+		{"unknown hook", "unknown", `{"type":"agent-turn-complete","thread-id":"` + testSessionID + `"}`},
+		{"other notification", HookNameTurnEnd, `{"type":"approval-requested","thread-id":"` + testSessionID + `"}`},
+		{"unknown session", HookNameTurnEnd, `{"type":"agent-turn-complete","thread-id":"0199ffff-0000-7000-8000-000000000000"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := (&CodexAgent{}).ParseHookEvent(tt.hookName, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseHookEvent() error = %v", err)
+			}
+			if event != nil {
+				t.Errorf("ParseHookEvent() = %+v, want nil", event)
+			}
+		})
+	}
+}
+
+func TestTranscriptAnalyzer(t *testing.T) {
+	path := writeRollout(t, testRollout)
+	ag := &CodexAgent{}
+
+	position, err := ag.GetTranscriptPosition(path)
+	if err != nil || position != 14 {
+		t.Errorf("GetTranscriptPosition() = %d, %v, want 14", position, err)
+	}
+
+	files, position, err := ag.ExtractModifiedFilesFromOffset(path, secondTurnLine)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if !slices.Equal(files, []string{"NOTES.md"}) || position != 14 {
+		t.Errorf("ExtractModifiedFilesFromOffset() = %q, %d", files, position)
+	}
+
+	prompts, err := ag.ExtractPrompts(path, 0)
+	if err != nil || !slices.Equal(prompts, []string{"Add a README", "Delete the old notes"}) {
+		t.Errorf("ExtractPrompts() = %q, %v", prompts, err)
+	}
+
+	summary, err := ag.ExtractSummary(path)
+	if err != nil || summary != "Deleted NOTES.md." {
+		t.Errorf("ExtractSummary() = %q, %v", summary, err)
+	}
+
+	if position, err := ag.GetTranscriptPosition(filepath.Join(t.TempDir(), "missing.jsonl")); err != nil || position != 0 {
+		t.Errorf("GetTranscriptPosition() for missing file = %d, %v, want 0", position, err)
+	}
+}
@@ -0,0 +1,268 @@
+package codex
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Transcript parsing - Codex records each session as a JSONL rollout file:
+// {"timestamp": ..., "type": "session_meta"|"response_item"|"event_msg"|"turn_context", "payload": {...}}.
+// Based on the rollout location: ~/.codex/sessions/YYYY/MM/DD/rollout-<time>-<session-id>.jsonl
+//
+// Prompts are read from user_message events rather than user response items,
+// which also carry the environment context Codex sends the model.
+
+// Patch header prefixes naming the files an apply_patch call touches.
+var patchFilePrefixes = []string{
+	"*** Add File: ",
+	"*** Update File: ",
+	"*** Delete File: ",
+	"*** Move to: ",
+}
+
+// ParseRollout parses Codex rollout content. Malformed lines are skipped.
+func ParseRollout(data []byte) []RolloutLine {
+	lines, _ := ParseRolloutFromLine(data, 0)
+	return lines
+}
+
+// ParseRolloutFromLine parses the rollout lines from line number startLine
+// on, and returns them with the total number of lines, which is the
+// transcript position. Malformed lines are skipped but still counted.
+func ParseRolloutFromLine(data []byte, startLine int) ([]RolloutLine, int) {
+	rawLines := splitLines(data)
+	var lines []RolloutLine
+	for i := max(startLine, 0); i < len(rawLines); i++ {
+		if len(bytes.TrimSpace(rawLines[i])) == 0 {
+			continue
+		}
+		var line RolloutLine
+		if err := json.Unmarshal(rawLines[i], &line); err != nil {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, len(rawLines)
+}
+
+// splitLines splits JSONL content into lines, without the empty line after
+// the final newline.
+func splitLines(data []byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+	rawLines := bytes.Split(data, []byte("\n"))
+	if len(rawLines[len(rawLines)-1]) == 0 {
+		rawLines = rawLines[:len(rawLines)-1]
+	}
+	return rawLines
+}
+
+// payload decodes a line's payload. Returns the zero payload if it doesn't parse.
+func payload(line RolloutLine) rolloutPayload {
+	var p rolloutPayload
+	//nolint:errcheck,gosec // Unknown payloads are ignored - leave p empty
+	json.Unmarshal(line.Payload, &p)
+	return p
+}
+
+// SessionMeta returns the session ID and working directory recorded in the
+// rollout's session_meta line. Returns empty strings if there is none.
+func SessionMeta(data []byte) (sessionID, cwd string) {
+	for _, line := range ParseRollout(data) {
+		if line.Type == LineTypeSessionMeta {
+			p := payload(line)
+			return p.ID, p.Cwd
+		}
+	}
+	return "", ""
+}
+
+// ExtractUserPrompts returns the prompts the user sent, in order.
+func ExtractUserPrompts(lines []RolloutLine) []string {
+	var prompts []string
+	for _, line := range lines {
+		if line.Type != LineTypeEventMsg {
+			continue
+		}
+		if p := payload(line); p.Type == PayloadTypeUserMessage {
+			if prompt := strings.TrimSpace(p.Message); prompt != "" {
+				prompts = append(prompts, prompt)
+			}
+		}
+	}
+	return prompts
+}
+
+// ExtractAllUserPrompts extracts all user prompts from raw rollout bytes.
+// This is a package-level function used by the condensation path.
+func ExtractAllUserPrompts(data []byte) []string {
+	return ExtractUserPrompts(ParseRollout(data))
+}
+
+// messageText joins the text blocks of a response item message.
+func messageText(p rolloutPayload) string {
+	var texts []string
+	for _, block := range p.Content {
+		if block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// Message is a prompt, reply, or tool call in a rollout.
+type Message struct {
+	Role string // RoleUser, RoleAssistant, or RoleTool
+
+	// Text is the prompt or reply, for user and assistant messages
+	Text string
+
+	// ToolName and ToolDetail describe a tool call: the files a patch
+	// touches or the command a shell call runs
+	ToolName   string
+	ToolDetail string
+}
+
+// ParseMessages returns the prompts, replies, and tool calls in the given
+// lines. Replies are read from assistant response items, which Codex also
+// reports as agent_message events.
+func ParseMessages(lines []RolloutLine) []Message {
+	var messages []Message
+	for _, line := range lines {
+		p := payload(line)
+		switch {
+		case line.Type == LineTypeEventMsg && p.Type == PayloadTypeUserMessage:
+			if text := strings.TrimSpace(p.Message); text != "" {
+				messages = append(messages, Message{Role: RoleUser, Text: text})
+			}
+		case line.Type != LineTypeResponseItem:
+		case p.Type == PayloadTypeMessage && p.Role == RoleAssistant:
+			if text := strings.TrimSpace(messageText(p)); text != "" {
+				messages = append(messages, Message{Role: RoleAssistant, Text: text})
+			}
+		case p.Type == PayloadTypeFunctionCall || p.Type == PayloadTypeCustomToolCall:
+			messages = append(messages, Message{Role: RoleTool, ToolName: p.Name, ToolDetail: toolDetail(p)})
+		}
+	}
+	return messages
+}
+
+// toolDetail returns the files a patch touches, or the command a shell call runs.
+func toolDetail(p rolloutPayload) string {
+	if patch := patchText(p); patch != "" {
+		return strings.Join(PatchFiles(patch), ", ")
+	}
+	if p.Type != PayloadTypeFunctionCall {
+		return ""
+	}
+	var args functionCallArgs
+	if err := json.Unmarshal([]byte(p.Arguments), &args); err != nil {
+		return ""
+	}
+	return strings.Join(args.Command, " ")
+}
+
+// ExtractLastAssistantMessage returns the last message Codex sent the user,
+// from either an agent_message event or an assistant response item.
+func ExtractLastAssistantMessage(lines []RolloutLine) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		p := payload(lines[i])
+		var text string
+		switch {
+		case lines[i].Type == LineTypeEventMsg && p.Type == PayloadTypeAgentMessage:
+			text = p.Message
+		case lines[i].Type == LineTypeResponseItem && p.Type == PayloadTypeMessage && p.Role == RoleAssistant:
+			text = messageText(p)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// ExtractModifiedFiles returns the files touched by apply_patch calls, as
+// written in the patches.
+func ExtractModifiedFiles(lines []RolloutLine) []string {
+	var files []string
+	for _, line := range lines {
+		if line.Type != LineTypeResponseItem {
+			continue
+		}
+		for _, file := range PatchFiles(patchText(payload(line))) {
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// patchText returns the patch a tool call applies, or "" if it applies none.
+// Codex applies patches through the apply_patch custom tool, an apply_patch
+// function call, or a shell call whose command is apply_patch.
+func patchText(p rolloutPayload) string {
+	switch p.Type {
+	case PayloadTypeCustomToolCall:
+		if p.Name == ToolApplyPatch {
+			return p.Input
+		}
+	case PayloadTypeFunctionCall:
+		if p.Name != ToolApplyPatch && p.Name != ToolShell {
+			return ""
+		}
+		var args functionCallArgs
+		if err := json.Unmarshal([]byte(p.Arguments), &args); err != nil {
+			return ""
+		}
+		if p.Name == ToolApplyPatch {
+			return args.Input
+		}
+		if len(args.Command) == 2 && args.Command[0] == ToolApplyPatch {
+			return args.Command[1]
+		}
+	}
+	return ""
+}
+
+// PatchFiles returns the files named in an apply_patch patch's file headers,
+// including the destination of moves.
+func PatchFiles(patch string) []string {
+	var files []string
+	for _, line := range strings.Split(patch, "\n") {
+		for _, prefix := range patchFilePrefixes {
+			if file, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), prefix); ok {
+				if file = strings.TrimSpace(file); file != "" && !slices.Contains(files, file) {
+					files = append(files, file)
+				}
+			}
+		}
+	}
+	return files
+}
+
+// CalculateTokenUsage sums the token_count events in the given lines. Each
+// event with usage info reports one model request.
+func CalculateTokenUsage(lines []RolloutLine) *agent.TokenUsage {
+	usage := &agent.TokenUsage{}
+	for _, line := range lines {
+		if line.Type != LineTypeEventMsg {
+			continue
+		}
+		p := payload(line)
+		if p.Type != PayloadTypeTokenCount || p.Info == nil || p.Info.LastTokenUsage == nil {
+			continue
+		}
+		last := p.Info.LastTokenUsage
+		usage.APICallCount++
+		usage.InputTokens += last.InputTokens - last.CachedInputTokens
+		usage.CacheReadTokens += last.CachedInputTokens
+		usage.OutputTokens += last.OutputTokens
+	}
+	return usage
+}
@@ -0,0 +1,149 @@
+package codex
+
+import (
+	"slices"
+	"testing"
+)
+
+// testRollout is a two-turn Codex rollout: the first turn edits files with
+// the apply_patch custom tool, the second through a shell call.
+const testRollout = `{"timestamp":"2025-09-20T10:00:00.000Z","type":"session_meta","payload":{"id":"0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b","cwd":"/repo","originator":"codex_cli_rs","cli_version":"0.39.0"}}
+{"timestamp":"2025-09-20T10:00:01.000Z","type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>\n  <cwd>/repo</cwd>\n</environment_context>"}]}}
+{"timestamp":"2025-09-20T10:00:01.000Z","type":"event_msg","payload":{"type":"user_message","message":"Add a README","kind":"plain"}}
+{"timestamp":"2025-09-20T10:00:01.000Z","type":"turn_context","payload":{"cwd":"/repo","model":"gpt-5-codex"}}
+{"timestamp":"2025-09-20T10:00:05.000Z","type":"response_item","payload":{"type":"custom_tool_call","status":"completed","call_id":"call_1","name":"apply_patch","input":"*** Begin Patch\n*** Add File: README.md\n+# Project\n*** Update File: main.go\n*** Move to: cmd/main.go\n@@\n-old\n+new\n*** End Patch"}}
+{"timestamp":"2025-09-20T10:00:06.000Z","type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":50,"total_tokens":1050},"last_token_usage":{"input_tokens":1000,"cached_input_tokens":400,"output_tokens":50,"total_tokens":1050}}}}
+{"timestamp":"2025-09-20T10:00:07.000Z","type":"event_msg","payload":{"type":"agent_message","message":"Added a README."}}
+{"timestamp":"2025-09-20T10:00:07.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Added a README."}]}}
+{"timestamp":"2025-09-20T10:01:00.000Z","type":"event_msg","payload":{"type":"user_message","message":"Delete the old notes","kind":"plain"}}
+{"timestamp":"2025-09-20T10:01:02.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"apply_patch\",\"*** Begin Patch\\n*** Delete File: NOTES.md\\n*** End Patch\"]}","call_id":"call_2"}}
+{"timestamp":"2025-09-20T10:01:03.000Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"bash\",\"-lc\",\"git status\"]}","call_id":"call_3"}}
+{"timestamp":"2025-09-20T10:01:04.000Z","type":"event_msg","payload":{"type":"token_count","info":{"last_token_usage":{"input_tokens":1200,"cached_input_tokens":1000,"output_tokens":30,"total_tokens":1230}}}}
+{"timestamp":"2025-09-20T10:01:04.000Z","type":"event_msg","payload":{"type":"token_count","info":null}}
+{"timestamp":"2025-09-20T10:01:05.000Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Deleted NOTES.md."}]}}
+`
+
+// secondTurnLine is the line the second turn starts at in testRollout.
+const secondTurnLine = 8
+
+func TestParseRolloutFromLine(t *testing.T) {
+	t.Parallel()
+
+	lines, position := ParseRolloutFromLine([]byte(testRollout+"not json\n"), 0)
+	if position != 15 {
+		t.Errorf("position = %d, want 15", position)
+	}
+	if len(lines) != 14 {
+		t.Errorf("parsed %d lines, want 14 (malformed line skipped)", len(lines))
+	}
+
+	lines, _ = ParseRolloutFromLine([]byte(testRollout), secondTurnLine)
+	if len(lines) != 6 {
+		t.Errorf("parsed %d lines from offset, want 6", len(lines))
+	}
+}
+
+func TestSessionMeta(t *testing.T) {
+	t.Parallel()
+
+	id, cwd := SessionMeta([]byte(testRollout))
+	if id != "0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b" || cwd != "/repo" {
+		t.Errorf("SessionMeta() = %q, %q", id, cwd)
+	}
+	if id, cwd := SessionMeta([]byte(`{"type":"event_msg","payload":{}}`)); id != "" || cwd != "" {
+		t.Errorf("SessionMeta() without session_meta = %q, %q, want empty", id, cwd)
+	}
+}
+
+func TestExtractUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	got := ExtractAllUserPrompts([]byte(testRollout))
+	want := []string{"Add a README", "Delete the old notes"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExtractAllUserPrompts() = %q, want %q", got, want)
+	}
+
+	lines, _ := ParseRolloutFromLine([]byte(testRollout), secondTurnLine)
+	if got := ExtractUserPrompts(lines); !slices.Equal(got, want[1:]) {
+		t.Errorf("ExtractUserPrompts() from offset = %q, want %q", got, want[1:])
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	got := ExtractModifiedFiles(ParseRollout([]byte(testRollout)))
+	want := []string{"README.md", "main.go", "cmd/main.go", "NOTES.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExtractModifiedFiles() = %q, want %q", got, want)
+	}
+
+	lines, _ := ParseRolloutFromLine([]byte(testRollout), secondTurnLine)
+	if got := ExtractModifiedFiles(lines); !slices.Equal(got, []string{"NOTES.md"}) {
+		t.Errorf("ExtractModifiedFiles() from offset = %q, want [NOTES.md]", got)
+	}
+}
+
+func TestExtractModifiedFiles_ApplyPatchFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	rollout := `{"type":"response_item","payload":{"type":"function_call","name":"apply_patch","arguments":"{\"input\":\"*** Begin Patch\\n*** Update File: src/app.go\\n*** End Patch\"}"}}`
+	got := ExtractModifiedFiles(ParseRollout([]byte(rollout)))
+	if !slices.Equal(got, []string{"src/app.go"}) {
+		t.Errorf("ExtractModifiedFiles() = %q, want [src/app.go]", got)
+	}
+}
+
+func TestExtractLastAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	if got := ExtractLastAssistantMessage(ParseRollout([]byte(testRollout))); got != "Deleted NOTES.md." {
+		t.Errorf("ExtractLastAssistantMessage() = %q", got)
+	}
+	agentMessageOnly := `{"type":"event_msg","payload":{"type":"agent_message","message":"Done."}}`
+	if got := ExtractLastAssistantMessage(ParseRollout([]byte(agentMessageOnly))); got != "Done." {
+		t.Errorf("ExtractLastAssistantMessage() from agent_message = %q", got)
+	}
+}
+
+func TestParseMessages(t *testing.T) {
+	t.Parallel()
+
+	messages := ParseMessages(ParseRollout([]byte(testRollout)))
+	want := []Message{
+		{Role: RoleUser, Text: "Add a README"},
+		{Role: RoleTool, ToolName: "apply_patch", ToolDetail: "README.md, main.go, cmd/main.go"},
+		{Role: RoleAssistant, Text: "Added a README."},
+		{Role: RoleUser, Text: "Delete the old notes"},
+		{Role: RoleTool, ToolName: "shell", ToolDetail: "NOTES.md"},
+		{Role: RoleTool, ToolName: "shell", ToolDetail: "bash -lc git status"},
+		{Role: RoleAssistant, Text: "Deleted NOTES.md."},
+	}
+	if !slices.Equal(messages, want) {
+		t.Errorf("ParseMessages() = %+v\nwant %+v", messages, want)
+	}
+}
+
+func TestCalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage(ParseRollout([]byte(testRollout)))
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 800 {
+		t.Errorf("InputTokens = %d, want 800", usage.InputTokens)
+	}
+	if usage.CacheReadTokens != 1400 {
+		t.Errorf("CacheReadTokens = %d, want 1400", usage.CacheReadTokens)
+	}
+	if usage.OutputTokens != 80 {
+		t.Errorf("OutputTokens = %d, want 80", usage.OutputTokens)
+	}
+
+	lines, _ := ParseRolloutFromLine([]byte(testRollout), secondTurnLine)
+	if usage := CalculateTokenUsage(lines); usage.APICallCount != 1 || usage.OutputTokens != 30 {
+		t.Errorf("CalculateTokenUsage() from offset = %+v, want 1 call, 30 output tokens", usage)
+	}
+}
@@ -0,0 +1,106 @@
+package codex
+
+import "encoding/json"
+
+// notifyPayload is the JSON Codex passes to its notify program after each
+// turn. Older Codex versions send no thread-id.
+type notifyPayload struct {
+	Type                 string   `json:"type"`
+	ThreadID             string   `json:"thread-id,omitempty"`
+	TurnID               string   `json:"turn-id,omitempty"`
+	Cwd                  string   `json:"cwd,omitempty"`
+	InputMessages        []string `json:"input-messages,omitempty"`
+	LastAssistantMessage string   `json:"last-assistant-message,omitempty"`
+}
+
+// notifyTypeTurnComplete is the only notification type Codex sends.
+const notifyTypeTurnComplete = "agent-turn-complete"
+
+// Rollout line types.
+const (
+	LineTypeSessionMeta  = "session_meta"
+	LineTypeResponseItem = "response_item"
+	LineTypeEventMsg     = "event_msg"
+	LineTypeTurnContext  = "turn_context"
+)
+
+// Rollout payload types Entire reads.
+const (
+	PayloadTypeMessage        = "message"          // response_item
+	PayloadTypeFunctionCall   = "function_call"    // response_item
+	PayloadTypeCustomToolCall = "custom_tool_call" // response_item
+	PayloadTypeUserMessage    = "user_message"     // event_msg
+	PayloadTypeAgentMessage   = "agent_message"    // event_msg
+	PayloadTypeTokenCount     = "token_count"      // event_msg
+)
+
+// Message role constants for Codex response items.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool" // Tool calls, in parsed messages only
+)
+
+// RolloutLine is one line of a Codex rollout file (JSONL).
+type RolloutLine struct {
+	Timestamp string          `json:"timestamp,omitempty"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// rolloutPayload holds the payload fields Entire reads across line types.
+type rolloutPayload struct {
+	Type string `json:"type"`
+
+	// session_meta
+	ID  string `json:"id,omitempty"`
+	Cwd string `json:"cwd,omitempty"`
+
+	// response_item message
+	Role    string         `json:"role,omitempty"`
+	Content []contentBlock `json:"content,omitempty"`
+
+	// response_item function_call and custom_tool_call
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments,omitempty"` // JSON-encoded arguments
+	Input     string `json:"input,omitempty"`
+
+	// event_msg user_message and agent_message
+	Message string `json:"message,omitempty"`
+
+	// event_msg token_count
+	Info *tokenCountInfo `json:"info,omitempty"`
+}
+
+// contentBlock is one block of a response item message.
+type contentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// tokenCountInfo is the info of a token_count event. last_token_usage covers
+// the model request that just finished.
+type tokenCountInfo struct {
+	LastTokenUsage *tokenUsage `json:"last_token_usage,omitempty"`
+}
+
+// tokenUsage is Codex's usage report for a model request. Input tokens
+// include the cached ones.
+type tokenUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+// functionCallArgs holds the arguments of function calls that can apply a
+// patch: apply_patch itself, or a shell call running apply_patch.
+type functionCallArgs struct {
+	Input   string   `json:"input,omitempty"`
+	Command []string `json:"command,omitempty"`
+}
+
+// Tool names that edit files in Codex
+const (
+	ToolApplyPatch = "apply_patch"
+	ToolShell      = "shell"
+)
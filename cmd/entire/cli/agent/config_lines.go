@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadConfigLines reads an agent config file as lines. A missing file has no lines.
+// Agents whose config formats Entire edits line by line (to keep the user's
+// settings and comments) share these helpers.
+func ReadConfigLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	content := strings.TrimRight(string(data), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// WriteConfigLines writes lines to an agent config file with a trailing newline.
+func WriteConfigLines(path string, lines []string) error {
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
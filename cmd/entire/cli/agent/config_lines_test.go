@@ -0,0 +1,48 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestConfigLines_RoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	lines, err := ReadConfigLines(path)
+	if err != nil {
+		t.Fatalf("ReadConfigLines() on missing file error = %v", err)
+	}
+	if lines != nil {
+		t.Errorf("ReadConfigLines() on missing file = %q, want nil", lines)
+	}
+
+	want := []string{"# comment", `notify = ["entire"]`}
+	if err := WriteConfigLines(path, want); err != nil {
+		t.Fatalf("WriteConfigLines() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if string(data) != "# comment\nnotify = [\"entire\"]\n" {
+		t.Errorf("config content = %q, want a trailing newline", data)
+	}
+
+	got, err := ReadConfigLines(path)
+	if err != nil {
+		t.Fatalf("ReadConfigLines() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ReadConfigLines() = %q, want %q", got, want)
+	}
+
+	if err := WriteConfigLines(path, nil); err != nil {
+		t.Fatalf("WriteConfigLines(nil) error = %v", err)
+	}
+	if got, _ := ReadConfigLines(path); got != nil {
+		t.Errorf("ReadConfigLines() after empty write = %q, want nil", got)
+	}
+}
@@ -0,0 +1,219 @@
+// Package cursor implements the Agent interface for Cursor.
+package cursor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCursor, NewCursorAgent)
+}
+
+// CursorAgent implements the Agent interface for Cursor.
+//
+//nolint:revive // CursorAgent is clearer than Agent in this context
+type CursorAgent struct{}
+
+func NewCursorAgent() agent.Agent {
+	return &CursorAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CursorAgent) Name() agent.AgentName {
+	return agent.AgentNameCursor
+}
+
+// Type returns the agent type identifier.
+func (c *CursorAgent) Type() agent.AgentType {
+	return agent.AgentTypeCursor
+}
+
+// Description returns a human-readable description.
+func (c *CursorAgent) Description() string {
+	return "Cursor - AI code editor"
+}
+
+func (c *CursorAgent) IsPreview() bool { return true }
+
+// DetectPresence checks if Cursor is configured in the repository.
+func (c *CursorAgent) DetectPresence() (bool, error) {
+	// Get worktree root to check for .cursor directory
+	// This is needed because the CLI may be run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	// .cursor holds hooks.json, rules, and MCP config
+	if _, err := os.Stat(filepath.Join(repoRoot, ".cursor")); err == nil {
+		return true, nil
+	}
+	// Legacy project rules file
+	if _, err := os.Stat(filepath.Join(repoRoot, ".cursorrules")); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CursorAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns directories that Cursor uses for config/state.
+func (c *CursorAgent) ProtectedDirs() []string { return []string{".cursor"} }
+
+// ResolveSessionFile returns the path to a Cursor agent transcript.
+// Cursor names transcripts after the conversation ID.
+func (c *CursorAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	return filepath.Join(sessionDir, agentSessionID+".jsonl")
+}
+
+// GetSessionDir returns the directory where Cursor stores agent transcripts.
+// Cursor stores them in ~/.cursor/projects/<project>/agent-transcripts/, where
+// <project> is the repo path with separators replaced by dashes.
+func (c *CursorAgent) GetSessionDir(repoPath string) (string, error) {
+	// Check for test environment override
+	if override := os.Getenv("ENTIRE_TEST_CURSOR_PROJECT_DIR"); override != "" {
+		return override, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, ".cursor", "projects", ProjectDirName(repoPath), "agent-transcripts"), nil
+}
+
+// ProjectDirName returns the directory name Cursor uses for a project under
+// ~/.cursor/projects: the path with non-alphanumeric characters replaced by
+// dashes and no leading dash.
+func ProjectDirName(repoPath string) string {
+	return strings.TrimLeft(paths.SanitizePathForClaude(repoPath), "-")
+}
+
+// ReadSession reads a session from Cursor's storage (JSONL transcript file).
+// The session data is stored in NativeData as raw JSONL bytes.
+func (c *CursorAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (transcript path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(ParseTranscript(data)),
+	}, nil
+}
+
+// WriteSession writes a session to Cursor's storage (JSONL transcript file).
+// Uses the NativeData field which contains raw JSONL bytes.
+func (c *CursorAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Cursor
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (transcript path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(session.SessionRef), 0o750); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume a Cursor session.
+// Conversations started in the editor are resumed from its chat history;
+// the Cursor CLI resumes them by ID.
+func (c *CursorAgent) FormatResumeCommand(sessionID string) string {
+	return "cursor-agent --resume " + sessionID
+}
+
+// ChunkTranscript splits a JSONL transcript at line boundaries.
+func (c *CursorAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk JSONL transcript: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates JSONL chunks with newlines.
+func (c *CursorAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the current line count of a Cursor transcript.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CursorAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Path comes from Cursor transcript location
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return len(splitLines(data)), nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given line number.
+// Returns:
+//   - files: list of file paths modified by Cursor (from file editing tools)
+//   - currentPosition: total number of lines in the transcript
+//   - error: any error encountered during reading
+func (c *CursorAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	data, readErr := os.ReadFile(path) //nolint:gosec // Path comes from Cursor transcript location
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read transcript: %w", readErr)
+	}
+
+	lines, position := ParseTranscriptFromLine(data, startOffset)
+	return ExtractModifiedFiles(lines), position, nil
+}
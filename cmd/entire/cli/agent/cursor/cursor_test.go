@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestCursorAgent_Registered(t *testing.T) {
+	t.Parallel()
+
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		t.Fatalf("agent.Get(cursor) error = %v", err)
+	}
+	if ag.Type() != agent.AgentTypeCursor {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeCursor)
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	tests := []struct {
+		name   string
+		create string
+		isDir  bool
+		want   bool
+	}{
+		{"cursor directory", ".cursor", true, true},
+		{"cursorrules file", ".cursorrules", false, true},
+		{"nothing", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			t.Chdir(tempDir)
+			if tt.create != "" {
+				path := filepath.Join(tempDir, tt.create)
+				var err error
+				if tt.isDir {
+					err = os.Mkdir(path, 0o750)
+				} else {
+					err = os.WriteFile(path, []byte("rules"), 0o600)
+				}
+				if err != nil {
+					t.Fatalf("failed to create %s: %v", tt.create, err)
+				}
+			}
+
+			got, err := (&CursorAgent{}).DetectPresence()
+			if err != nil {
+				t.Fatalf("DetectPresence() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectPresence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSessionDir(t *testing.T) {
+	t.Setenv("ENTIRE_TEST_CURSOR_PROJECT_DIR", "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir, err := (&CursorAgent{}).GetSessionDir("/Users/me/src/my-app")
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	want := filepath.Join(home, ".cursor", "projects", "Users-me-src-my-app", "agent-transcripts")
+	if dir != want {
+		t.Errorf("GetSessionDir() = %q, want %q", dir, want)
+	}
+
+	t.Setenv("ENTIRE_TEST_CURSOR_PROJECT_DIR", "/tmp/override")
+	dir, err = (&CursorAgent{}).GetSessionDir("/Users/me/src/my-app")
+	if err != nil || dir != "/tmp/override" {
+		t.Errorf("GetSessionDir() with override = %q, %v, want /tmp/override", dir, err)
+	}
+}
+
+func TestReadWriteSession(t *testing.T) {
+	t.Parallel()
+
+	ag := &CursorAgent{}
+	path := filepath.Join(t.TempDir(), "transcripts", "conv-1.jsonl")
+	err := ag.WriteSession(&agent.AgentSession{
+		SessionID:  "conv-1",
+		AgentName:  agent.AgentNameCursor,
+		SessionRef: path,
+		NativeData: []byte(testTranscript),
+	})
+	if err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: "conv-1", SessionRef: path})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(session.NativeData) != testTranscript {
+		t.Error("ReadSession() NativeData does not match what was written")
+	}
+	if len(session.ModifiedFiles) != 2 {
+		t.Errorf("ModifiedFiles = %v, want 2 files", session.ModifiedFiles)
+	}
+
+	err = ag.WriteSession(&agent.AgentSession{AgentName: agent.AgentNameGemini, SessionRef: path, NativeData: []byte("x")})
+	if err == nil {
+		t.Error("WriteSession() should reject sessions from other agents")
+	}
+}
@@ -0,0 +1,288 @@
+package cursor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Ensure CursorAgent implements HookSupport
+var _ agent.HookSupport = (*CursorAgent)(nil)
+
+// Cursor hook names - these become subcommands under `entire hooks cursor`
+const (
+	HookNameSessionStart       = "session-start"
+	HookNameSessionEnd         = "session-end"
+	HookNameBeforeSubmitPrompt = "before-submit-prompt"
+	HookNameStop               = "stop"
+)
+
+// CursorHooksFileName is the hooks config file used by Cursor.
+const CursorHooksFileName = "hooks.json"
+
+// cursorHooksFileVersion is the hooks.json schema version Cursor expects.
+const cursorHooksFileVersion = 1
+
+// entireHookPrefixes are command prefixes that identify Entire hooks
+var entireHookPrefixes = []string{
+	"entire ",
+	"go run ${CURSOR_PROJECT_DIR}/cmd/entire/main.go ",
+}
+
+// hooksPath returns the path of .cursor/hooks.json in the repository.
+func hooksPath(repoRoot string) string {
+	return filepath.Join(repoRoot, ".cursor", CursorHooksFileName)
+}
+
+// InstallHooks installs Cursor hooks in .cursor/hooks.json.
+// If force is true, removes existing Entire hooks before installing.
+// Returns the number of hooks installed.
+func (c *CursorAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	// Use repo root instead of CWD to find .cursor directory
+	// This ensures hooks are installed correctly when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		// Fallback to CWD if not in a git repo (e.g., during tests)
+		repoRoot, err = os.Getwd() //nolint:forbidigo // Intentional fallback when WorktreeRoot() fails (tests run outside git repos)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	hooksFile := hooksPath(repoRoot)
+
+	// rawFile preserves unknown top-level keys, rawHooks unknown hook types
+	var rawFile map[string]json.RawMessage
+	var rawHooks map[string]json.RawMessage
+
+	existingData, readErr := os.ReadFile(hooksFile) //nolint:gosec // path is constructed from repo root + fixed path
+	if readErr == nil {
+		if err := json.Unmarshal(existingData, &rawFile); err != nil {
+			return 0, fmt.Errorf("failed to parse existing hooks.json: %w", err)
+		}
+		if hooksRaw, ok := rawFile["hooks"]; ok {
+			if err := json.Unmarshal(hooksRaw, &rawHooks); err != nil {
+				return 0, fmt.Errorf("failed to parse hooks in hooks.json: %w", err)
+			}
+		}
+	}
+	if rawFile == nil {
+		rawFile = make(map[string]json.RawMessage)
+	}
+	if rawHooks == nil {
+		rawHooks = make(map[string]json.RawMessage)
+	}
+
+	// Define hook commands based on localDev mode
+	var cmdPrefix string
+	if localDev {
+		cmdPrefix = "go run ${CURSOR_PROJECT_DIR}/cmd/entire/main.go hooks cursor "
+	} else {
+		cmdPrefix = "entire hooks cursor "
+	}
+
+	// Parse only the hook types we need to modify
+	var sessionStart, sessionEnd, beforeSubmitPrompt, stop []CursorHookEntry
+	parseCursorHookType(rawHooks, "sessionStart", &sessionStart)
+	parseCursorHookType(rawHooks, "sessionEnd", &sessionEnd)
+	parseCursorHookType(rawHooks, "beforeSubmitPrompt", &beforeSubmitPrompt)
+	parseCursorHookType(rawHooks, "stop", &stop)
+
+	// Check for idempotency BEFORE removing hooks
+	// If the exact same hook command already exists, return 0 (no changes needed)
+	if !force {
+		if getFirstEntireHookCommand(sessionStart) == cmdPrefix+HookNameSessionStart {
+			return 0, nil // Already installed with same mode
+		}
+	}
+
+	// Remove existing Entire hooks first (for clean installs and mode switching)
+	sessionStart = removeEntireHooks(sessionStart)
+	sessionEnd = removeEntireHooks(sessionEnd)
+	beforeSubmitPrompt = removeEntireHooks(beforeSubmitPrompt)
+	stop = removeEntireHooks(stop)
+
+	sessionStart = append(sessionStart, CursorHookEntry{Command: cmdPrefix + HookNameSessionStart})
+	sessionEnd = append(sessionEnd, CursorHookEntry{Command: cmdPrefix + HookNameSessionEnd})
+	beforeSubmitPrompt = append(beforeSubmitPrompt, CursorHookEntry{Command: cmdPrefix + HookNameBeforeSubmitPrompt})
+	stop = append(stop, CursorHookEntry{Command: cmdPrefix + HookNameStop})
+	count := 4
+
+	// Marshal modified hook types back to rawHooks
+	marshalCursorHookType(rawHooks, "sessionStart", sessionStart)
+	marshalCursorHookType(rawHooks, "sessionEnd", sessionEnd)
+	marshalCursorHookType(rawHooks, "beforeSubmitPrompt", beforeSubmitPrompt)
+	marshalCursorHookType(rawHooks, "stop", stop)
+
+	hooksJSON, err := json.Marshal(rawHooks)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal hooks: %w", err)
+	}
+	rawFile["hooks"] = hooksJSON
+	if _, ok := rawFile["version"]; !ok {
+		versionJSON, err := json.Marshal(cursorHooksFileVersion)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal version: %w", err)
+		}
+		rawFile["version"] = versionJSON
+	}
+
+	// Write back to file
+	if err := os.MkdirAll(filepath.Dir(hooksFile), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create .cursor directory: %w", err)
+	}
+
+	output, err := json.MarshalIndent(rawFile, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal hooks.json: %w", err)
+	}
+
+	if err := os.WriteFile(hooksFile, output, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write hooks.json: %w", err)
+	}
+
+	return count, nil
+}
+
+// parseCursorHookType parses a specific hook type from rawHooks into the target slice.
+// Silently ignores parse errors (leaves target unchanged).
+func parseCursorHookType(rawHooks map[string]json.RawMessage, hookType string, target *[]CursorHookEntry) {
+	if data, ok := rawHooks[hookType]; ok {
+		//nolint:errcheck,gosec // Intentionally ignoring parse errors - leave target as nil/empty
+		json.Unmarshal(data, target)
+	}
+}
+
+// marshalCursorHookType marshals a hook type back to rawHooks.
+// If the slice is empty, removes the key from rawHooks.
+func marshalCursorHookType(rawHooks map[string]json.RawMessage, hookType string, entries []CursorHookEntry) {
+	if len(entries) == 0 {
+		delete(rawHooks, hookType)
+		return
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return // Silently ignore marshal errors (shouldn't happen)
+	}
+	rawHooks[hookType] = data
+}
+
+// UninstallHooks removes Entire hooks from Cursor's hooks.json.
+func (c *CursorAgent) UninstallHooks() error {
+	// Use repo root to find .cursor directory when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	hooksFile := hooksPath(repoRoot)
+	data, err := os.ReadFile(hooksFile) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		return nil //nolint:nilerr // No hooks file means nothing to uninstall
+	}
+
+	var rawFile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rawFile); err != nil {
+		return fmt.Errorf("failed to parse hooks.json: %w", err)
+	}
+
+	// rawHooks preserves unknown hook types
+	var rawHooks map[string]json.RawMessage
+	if hooksRaw, ok := rawFile["hooks"]; ok {
+		if err := json.Unmarshal(hooksRaw, &rawHooks); err != nil {
+			return fmt.Errorf("failed to parse hooks: %w", err)
+		}
+	}
+	if rawHooks == nil {
+		rawHooks = make(map[string]json.RawMessage)
+	}
+
+	for _, hookType := range []string{"sessionStart", "sessionEnd", "beforeSubmitPrompt", "stop"} {
+		var entries []CursorHookEntry
+		parseCursorHookType(rawHooks, hookType, &entries)
+		marshalCursorHookType(rawHooks, hookType, removeEntireHooks(entries))
+	}
+
+	// Marshal hooks back (preserving unknown hook types)
+	if len(rawHooks) > 0 {
+		hooksJSON, err := json.Marshal(rawHooks)
+		if err != nil {
+			return fmt.Errorf("failed to marshal hooks: %w", err)
+		}
+		rawFile["hooks"] = hooksJSON
+	} else {
+		delete(rawFile, "hooks")
+	}
+
+	// Write back
+	output, err := json.MarshalIndent(rawFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal hooks.json: %w", err)
+	}
+
+	if err := os.WriteFile(hooksFile, output, 0o600); err != nil {
+		return fmt.Errorf("failed to write hooks.json: %w", err)
+	}
+	return nil
+}
+
+// AreHooksInstalled checks if Entire hooks are installed.
+func (c *CursorAgent) AreHooksInstalled() bool {
+	// Use repo root to find .cursor directory when run from a subdirectory
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		repoRoot = "." // Fallback to CWD if not in a git repo
+	}
+	data, err := os.ReadFile(hooksPath(repoRoot)) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		return false
+	}
+
+	var hooksFile CursorHooksFile
+	if err := json.Unmarshal(data, &hooksFile); err != nil {
+		return false
+	}
+
+	// Check for at least one of our hooks using isEntireHook (works for both localDev and production)
+	return getFirstEntireHookCommand(hooksFile.Hooks.SessionStart) != "" ||
+		getFirstEntireHookCommand(hooksFile.Hooks.SessionEnd) != "" ||
+		getFirstEntireHookCommand(hooksFile.Hooks.BeforeSubmitPrompt) != "" ||
+		getFirstEntireHookCommand(hooksFile.Hooks.Stop) != ""
+}
+
+// Helper functions for hook management
+
+// isEntireHook checks if a command is an Entire hook
+func isEntireHook(command string) bool {
+	for _, prefix := range entireHookPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFirstEntireHookCommand returns the command of the first Entire hook found, or empty string
+func getFirstEntireHookCommand(entries []CursorHookEntry) string {
+	for _, entry := range entries {
+		if isEntireHook(entry.Command) {
+			return entry.Command
+		}
+	}
+	return ""
+}
+
+// removeEntireHooks removes all Entire hooks from a list of hook entries
+func removeEntireHooks(entries []CursorHookEntry) []CursorHookEntry {
+	result := make([]CursorHookEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !isEntireHook(entry.Command) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package cursor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallHooks_FreshInstall(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CursorAgent{}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("InstallHooks() count = %d, want 4", count)
+	}
+
+	hooksFile := readCursorHooksFile(t, tempDir)
+	if hooksFile.Version != 1 {
+		t.Errorf("version = %d, want 1", hooksFile.Version)
+	}
+	verifyHookCommand(t, hooksFile.Hooks.SessionStart, "entire hooks cursor session-start")
+	verifyHookCommand(t, hooksFile.Hooks.SessionEnd, "entire hooks cursor session-end")
+	verifyHookCommand(t, hooksFile.Hooks.BeforeSubmitPrompt, "entire hooks cursor before-submit-prompt")
+	verifyHookCommand(t, hooksFile.Hooks.Stop, "entire hooks cursor stop")
+
+	if !agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestInstallHooks_Idempotent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CursorAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("first InstallHooks() error = %v", err)
+	}
+	count, err := agent.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+
+	hooksFile := readCursorHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 1 {
+		t.Errorf("Stop hooks = %d, want 1", len(hooksFile.Hooks.Stop))
+	}
+}
+
+func TestInstallHooks_LocalDevSwitchesMode(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CursorAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	count, err := agent.InstallHooks(true, false)
+	if err != nil {
+		t.Fatalf("InstallHooks(localDev) error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("InstallHooks(localDev) count = %d, want 4", count)
+	}
+
+	hooksFile := readCursorHooksFile(t, tempDir)
+	verifyHookCommand(t, hooksFile.Hooks.SessionStart, "go run ${CURSOR_PROJECT_DIR}/cmd/entire/main.go hooks cursor session-start")
+}
+
+func TestInstallHooks_PreservesUserHooksAndUnknownKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeCursorHooksFile(t, tempDir, `{
+  "version": 1,
+  "hooks": {
+    "stop": [{"command": "./scripts/notify.sh"}],
+    "afterFileEdit": [{"command": "./scripts/format.sh"}]
+  }
+}`)
+
+	agent := &CursorAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	rawHooks := readRawHooks(t, tempDir)
+	if _, ok := rawHooks["afterFileEdit"]; !ok {
+		t.Error("afterFileEdit hook type was not preserved")
+	}
+
+	hooksFile := readCursorHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 2 {
+		t.Fatalf("Stop hooks = %d, want 2", len(hooksFile.Hooks.Stop))
+	}
+	if hooksFile.Hooks.Stop[0].Command != "./scripts/notify.sh" {
+		t.Errorf("Stop[0] = %q, want user hook first", hooksFile.Hooks.Stop[0].Command)
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeCursorHooksFile(t, tempDir, `{
+  "version": 1,
+  "hooks": {
+    "stop": [{"command": "./scripts/notify.sh"}]
+  }
+}`)
+
+	agent := &CursorAgent{}
+	if _, err := agent.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if err := agent.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+
+	if agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+	rawHooks := readRawHooks(t, tempDir)
+	if _, ok := rawHooks["sessionStart"]; ok {
+		t.Error("sessionStart should be removed when it only had Entire hooks")
+	}
+	hooksFile := readCursorHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 1 || hooksFile.Hooks.Stop[0].Command != "./scripts/notify.sh" {
+		t.Errorf("Stop hooks = %+v, want only the user hook", hooksFile.Hooks.Stop)
+	}
+}
+
+func TestUninstallHooks_NoFile(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	agent := &CursorAgent{}
+	if err := agent.UninstallHooks(); err != nil {
+		t.Errorf("UninstallHooks() error = %v, want nil", err)
+	}
+	if agent.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true without hooks.json")
+	}
+}
+
+func readCursorHooksFile(t *testing.T, tempDir string) CursorHooksFile {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(tempDir, ".cursor", CursorHooksFileName))
+	if err != nil {
+		t.Fatalf("failed to read hooks.json: %v", err)
+	}
+	var hooksFile CursorHooksFile
+	if err := json.Unmarshal(data, &hooksFile); err != nil {
+		t.Fatalf("failed to parse hooks.json: %v", err)
+	}
+	return hooksFile
+}
+
+func readRawHooks(t *testing.T, tempDir string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(tempDir, ".cursor", CursorHooksFileName))
+	if err != nil {
+		t.Fatalf("failed to read hooks.json: %v", err)
+	}
+	var rawFile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rawFile); err != nil {
+		t.Fatalf("failed to parse hooks.json: %v", err)
+	}
+	var rawHooks map[string]json.RawMessage
+	if err := json.Unmarshal(rawFile["hooks"], &rawHooks); err != nil {
+		t.Fatalf("failed to parse hooks: %v", err)
+	}
+	return rawHooks
+}
+
+func writeCursorHooksFile(t *testing.T, tempDir, content string) {
+	t.Helper()
+	dir := filepath.Join(tempDir, ".cursor")
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatalf("failed to create .cursor: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, CursorHooksFileName), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write hooks.json: %v", err)
+	}
+}
+
+func verifyHookCommand(t *testing.T, entries []CursorHookEntry, expectedCommand string) {
+	t.Helper()
+	for _, entry := range entries {
+		if entry.Command == expectedCommand {
+			return
+		}
+	}
+	t.Errorf("hook command %q not found in %+v", expectedCommand, entries)
+}
@@ -0,0 +1,99 @@
+package cursor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Compile-time interface assertions for new interfaces.
+var _ agent.TranscriptAnalyzer = (*CursorAgent)(nil)
+
+// HookNames returns the hook verbs Cursor supports.
+// These become subcommands: entire hooks cursor <verb>
+func (c *CursorAgent) HookNames() []string {
+	return []string{
+		HookNameSessionStart,
+		HookNameSessionEnd,
+		HookNameBeforeSubmitPrompt,
+		HookNameStop,
+	}
+}
+
+// ParseHookEvent translates a Cursor hook into a normalized lifecycle Event.
+// Returns nil if the hook has no lifecycle significance.
+func (c *CursorAgent) ParseHookEvent(hookName string, stdin io.Reader) (*agent.Event, error) {
+	var eventType agent.EventType
+	switch hookName {
+	case HookNameSessionStart:
+		eventType = agent.SessionStart
+	case HookNameBeforeSubmitPrompt:
+		eventType = agent.TurnStart
+	case HookNameStop:
+		eventType = agent.TurnEnd
+	case HookNameSessionEnd:
+		eventType = agent.SessionEnd
+	default:
+		return nil, nil //nolint:nilnil // Unknown hooks have no lifecycle action
+	}
+
+	raw, err := agent.ReadAndParseHookInput[hookInputRaw](stdin)
+	if err != nil {
+		return nil, err
+	}
+	event := &agent.Event{
+		Type:       eventType,
+		SessionID:  raw.sessionID(),
+		SessionRef: raw.TranscriptPath,
+		Timestamp:  time.Now(),
+	}
+	switch eventType {
+	case agent.TurnStart:
+		event.Prompt = raw.Prompt
+	case agent.SessionStart:
+		if raw.IsBackgroundAgent {
+			event.Metadata = map[string]string{"is_background_agent": strconv.FormatBool(true)}
+		}
+	}
+	return event, nil
+}
+
+// ReadTranscript reads the raw JSONL transcript bytes for a session.
+func (c *CursorAgent) ReadTranscript(sessionRef string) ([]byte, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return data, nil
+}
+
+// ExtractPrompts extracts user prompts from the transcript starting at the given line offset.
+func (c *CursorAgent) ExtractPrompts(sessionRef string, fromOffset int) ([]string, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	lines, _ := ParseTranscriptFromLine(data, fromOffset)
+
+	var prompts []string
+	for _, line := range lines {
+		if prompt := ExtractUserPrompt(line); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts, nil
+}
+
+// ExtractSummary extracts the last assistant message as a session summary.
+func (c *CursorAgent) ExtractSummary(sessionRef string) (string, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from agent hook input
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return ExtractLastAssistantMessage(ParseTranscript(data)), nil
+}
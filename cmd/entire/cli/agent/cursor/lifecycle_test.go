@@ -0,0 +1,134 @@
+package cursor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestParseHookEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		hookName   string
+		input      string
+		wantType   agent.EventType
+		wantID     string
+		wantPrompt string
+	}{
+		{
+			name:     "session start",
+			hookName: HookNameSessionStart,
+			input:    `{"conversation_id": "conv-1", "session_id": "conv-1", "hook_event_name": "sessionStart", "transcript_path": "/tmp/conv-1.jsonl"}`,
+			wantType: agent.SessionStart,
+			wantID:   "conv-1",
+		},
+		{
+			name:       "before submit prompt",
+			hookName:   HookNameBeforeSubmitPrompt,
+			input:      `{"conversation_id": "conv-1", "generation_id": "gen-1", "hook_event_name": "beforeSubmitPrompt", "transcript_path": "/tmp/conv-1.jsonl", "prompt": "Fix the bug"}`,
+			wantType:   agent.TurnStart,
+			wantID:     "conv-1",
+			wantPrompt: "Fix the bug",
+		},
+		{
+			name:     "stop",
+			hookName: HookNameStop,
+			input:    `{"conversation_id": "conv-1", "hook_event_name": "stop", "status": "completed", "transcript_path": "/tmp/conv-1.jsonl"}`,
+			wantType: agent.TurnEnd,
+			wantID:   "conv-1",
+		},
+		{
+			name:     "session end falls back to session_id",
+			hookName: HookNameSessionEnd,
+			input:    `{"session_id": "conv-2", "hook_event_name": "sessionEnd", "transcript_path": "/tmp/conv-1.jsonl"}`,
+			wantType: agent.SessionEnd,
+			wantID:   "conv-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := (&CursorAgent{}).ParseHookEvent(tt.hookName, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if event == nil {
+				t.Fatal("expected event, got nil")
+			}
+			if event.Type != tt.wantType {
+				t.Errorf("Type = %v, want %v", event.Type, tt.wantType)
+			}
+			if event.SessionID != tt.wantID {
+				t.Errorf("SessionID = %q, want %q", event.SessionID, tt.wantID)
+			}
+			if event.SessionRef != "/tmp/conv-1.jsonl" {
+				t.Errorf("SessionRef = %q, want /tmp/conv-1.jsonl", event.SessionRef)
+			}
+			if event.Prompt != tt.wantPrompt {
+				t.Errorf("Prompt = %q, want %q", event.Prompt, tt.wantPrompt)
+			}
+		})
+	}
+}
+
+func TestParseHookEvent_UnknownHook(t *testing.T) {
+	t.Parallel()
+
+	event, err := (&CursorAgent{}).ParseHookEvent("after-file-edit", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event != nil {
+		t.Errorf("expected nil event for unknown hook, got %+v", event)
+	}
+}
+
+func TestParseHookEvent_EmptyInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&CursorAgent{}).ParseHookEvent(HookNameStop, strings.NewReader("")); err == nil {
+		t.Error("expected error for empty input")
+	}
+}
+
+func TestExtractPromptsAndSummary(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "conv-1.jsonl")
+	if err := os.WriteFile(path, []byte(testTranscript), 0o600); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	ag := &CursorAgent{}
+
+	prompts, err := ag.ExtractPrompts(path, 0)
+	if err != nil {
+		t.Fatalf("ExtractPrompts() error = %v", err)
+	}
+	if want := []string{"Add a README", "Now add a license"}; !slices.Equal(prompts, want) {
+		t.Errorf("ExtractPrompts() = %q, want %q", prompts, want)
+	}
+
+	prompts, err = ag.ExtractPrompts(path, 2)
+	if err != nil {
+		t.Fatalf("ExtractPrompts(2) error = %v", err)
+	}
+	if want := []string{"Now add a license"}; !slices.Equal(prompts, want) {
+		t.Errorf("ExtractPrompts(2) = %q, want %q", prompts, want)
+	}
+
+	summary, err := ag.ExtractSummary(path)
+	if err != nil {
+		t.Fatalf("ExtractSummary() error = %v", err)
+	}
+	if summary != "Added the MIT license." {
+		t.Errorf("ExtractSummary() = %q, want %q", summary, "Added the MIT license.")
+	}
+}
@@ -0,0 +1,176 @@
+package cursor
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Transcript parsing - Cursor stores agent transcripts as JSONL, one message
+// per line: {"role": "user"|"assistant", "message": {"content": [...]}}.
+// Based on transcript_path format: ~/.cursor/projects/<project>/agent-transcripts/<conversation-id>.jsonl
+
+// userQueryRegex matches the <user_query> tag Cursor wraps typed prompts in.
+var userQueryRegex = regexp.MustCompile(`(?s)<user_query>\s*(.*?)\s*</user_query>`)
+
+// ParseTranscript parses Cursor JSONL transcript content. Malformed lines are skipped.
+func ParseTranscript(data []byte) []TranscriptLine {
+	lines, _ := ParseTranscriptFromLine(data, 0)
+	return lines
+}
+
+// ParseTranscriptFromLine parses the transcript lines from line number
+// startLine on, and returns them with the total number of lines, which is
+// the transcript position. Malformed lines are skipped but still counted.
+func ParseTranscriptFromLine(data []byte, startLine int) ([]TranscriptLine, int) {
+	rawLines := splitLines(data)
+	var lines []TranscriptLine
+	for i := max(startLine, 0); i < len(rawLines); i++ {
+		if len(bytes.TrimSpace(rawLines[i])) == 0 {
+			continue
+		}
+		var line TranscriptLine
+		if err := json.Unmarshal(rawLines[i], &line); err != nil {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, len(rawLines)
+}
+
+// splitLines splits JSONL content into lines, without the empty line after
+// the final newline.
+func splitLines(data []byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+	rawLines := bytes.Split(data, []byte("\n"))
+	if len(rawLines[len(rawLines)-1]) == 0 {
+		rawLines = rawLines[:len(rawLines)-1]
+	}
+	return rawLines
+}
+
+// contentBlocks returns the content of a line's message as blocks.
+// String content becomes a single text block.
+func contentBlocks(line TranscriptLine) []contentBlock {
+	var msg transcriptMessage
+	if err := json.Unmarshal(line.Message, &msg); err != nil || len(msg.Content) == 0 {
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(msg.Content, &text); err == nil {
+		return []contentBlock{{Type: "text", Text: text}}
+	}
+	var blocks []contentBlock
+	if err := json.Unmarshal(msg.Content, &blocks); err != nil {
+		return nil
+	}
+	return blocks
+}
+
+// MessageText joins the text blocks of a line's message.
+func MessageText(line TranscriptLine) string {
+	var texts []string
+	for _, block := range contentBlocks(line) {
+		if block.Type == "text" && block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// ExtractUserPrompt returns the prompt the user typed in a user line, without
+// the <user_query> wrapper and the context Cursor attaches around it.
+func ExtractUserPrompt(line TranscriptLine) string {
+	if line.Role != RoleUser {
+		return ""
+	}
+	text := MessageText(line)
+	if match := userQueryRegex.FindStringSubmatch(text); match != nil {
+		return match[1]
+	}
+	return strings.TrimSpace(text)
+}
+
+// ExtractAllUserPrompts extracts all user prompts from raw JSONL transcript bytes.
+// This is a package-level function used by the condensation path.
+func ExtractAllUserPrompts(data []byte) []string {
+	var prompts []string
+	for _, line := range ParseTranscript(data) {
+		if prompt := ExtractUserPrompt(line); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// ToolCall is a tool invocation in an assistant message.
+type ToolCall struct {
+	Name  string
+	Input map[string]interface{}
+}
+
+// ExtractToolCalls returns the tool calls in an assistant line.
+func ExtractToolCalls(line TranscriptLine) []ToolCall {
+	if line.Role != RoleAssistant {
+		return nil
+	}
+	var calls []ToolCall
+	for _, block := range contentBlocks(line) {
+		if block.Type != "tool_use" {
+			continue
+		}
+		call := ToolCall{Name: block.Name}
+		//nolint:errcheck,gosec // Tool input is optional - leave Input nil if it doesn't parse
+		json.Unmarshal(block.Input, &call.Input)
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// ExtractModifiedFiles extracts files modified by tool calls from transcript lines.
+func ExtractModifiedFiles(lines []TranscriptLine) []string {
+	var files []string
+	for _, line := range lines {
+		if line.Role != RoleAssistant {
+			continue
+		}
+		for _, block := range contentBlocks(line) {
+			if block.Type != "tool_use" || !slices.Contains(FileModificationTools, block.Name) {
+				continue
+			}
+			var input toolInput
+			if err := json.Unmarshal(block.Input, &input); err != nil {
+				continue
+			}
+			file := input.Path
+			if file == "" {
+				file = input.FilePath
+			}
+			if file == "" {
+				file = input.TargetFile
+			}
+			if file != "" && !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+	return files
+}
+
+// ExtractLastAssistantMessage returns the text of the last assistant message
+// that has any.
+func ExtractLastAssistantMessage(lines []TranscriptLine) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Role != RoleAssistant {
+			continue
+		}
+		if text := MessageText(lines[i]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package cursor
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testTranscript = `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nAdd a README\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Creating it."},{"type":"tool_use","name":"Write","input":{"path":"README.md","contents":"# Demo"}}]}}
+{"role":"user","message":{"content":[{"type":"text","text":"<user_query>Now add a license</user_query>"}]}}
+not json
+{"role":"assistant","message":{"content":[{"type":"tool_use","name":"StrReplace","input":{"path":"LICENSE"}},{"type":"tool_use","name":"Read","input":{"path":"go.mod"}},{"type":"text","text":"Added the MIT license."}]}}
+`
+
+func TestParseTranscriptFromLine(t *testing.T) {
+	t.Parallel()
+
+	lines, position := ParseTranscriptFromLine([]byte(testTranscript), 0)
+	if position != 5 {
+		t.Errorf("position = %d, want 5 (malformed lines still count)", position)
+	}
+	if len(lines) != 4 {
+		t.Errorf("len(lines) = %d, want 4", len(lines))
+	}
+
+	lines, position = ParseTranscriptFromLine([]byte(testTranscript), 3)
+	if position != 5 || len(lines) != 1 {
+		t.Errorf("from line 3: len(lines) = %d, position = %d, want 1 and 5", len(lines), position)
+	}
+}
+
+func TestExtractUserPrompt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"user query tag", `{"role":"user","message":{"content":[{"type":"text","text":"<attached_files>a.go</attached_files>\n<user_query>\nFix it\n</user_query>"}]}}`, "Fix it"},
+		{"plain string content", `{"role":"user","message":{"content":"  Fix it  "}}`, "Fix it"},
+		{"assistant line", `{"role":"assistant","message":{"content":"Fix it"}}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lines := ParseTranscript([]byte(tt.line))
+			if len(lines) != 1 {
+				t.Fatalf("ParseTranscript() returned %d lines, want 1", len(lines))
+			}
+			if got := ExtractUserPrompt(lines[0]); got != tt.want {
+				t.Errorf("ExtractUserPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	files := ExtractModifiedFiles(ParseTranscript([]byte(testTranscript)))
+	if want := []string{"README.md", "LICENSE"}; !slices.Equal(files, want) {
+		t.Errorf("ExtractModifiedFiles() = %q, want %q", files, want)
+	}
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "conv-1.jsonl")
+	if err := os.WriteFile(path, []byte(testTranscript), 0o600); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	ag := &CursorAgent{}
+
+	files, position, err := ag.ExtractModifiedFilesFromOffset(path, 2)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if position != 5 {
+		t.Errorf("position = %d, want 5", position)
+	}
+	if want := []string{"LICENSE"}; !slices.Equal(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+
+	gotPosition, err := ag.GetTranscriptPosition(path)
+	if err != nil || gotPosition != 5 {
+		t.Errorf("GetTranscriptPosition() = %d, %v, want 5", gotPosition, err)
+	}
+	gotPosition, err = ag.GetTranscriptPosition(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || gotPosition != 0 {
+		t.Errorf("GetTranscriptPosition(missing) = %d, %v, want 0", gotPosition, err)
+	}
+}
+
+func TestExtractToolCalls(t *testing.T) {
+	t.Parallel()
+
+	lines := ParseTranscript([]byte(testTranscript))
+	calls := ExtractToolCalls(lines[3])
+	if len(calls) != 2 {
+		t.Fatalf("len(calls) = %d, want 2", len(calls))
+	}
+	if calls[1].Name != "Read" || calls[1].Input["path"] != "go.mod" {
+		t.Errorf("calls[1] = %+v, want Read of go.mod", calls[1])
+	}
+	if ExtractToolCalls(lines[0]) != nil {
+		t.Error("user lines should have no tool calls")
+	}
+}
@@ -0,0 +1,96 @@
+package cursor
+
+import "encoding/json"
+
+// CursorHooksFile represents the .cursor/hooks.json structure.
+type CursorHooksFile struct {
+	Version int         `json:"version"`
+	Hooks   CursorHooks `json:"hooks"`
+}
+
+// CursorHooks contains the hook types Entire installs.
+type CursorHooks struct {
+	SessionStart       []CursorHookEntry `json:"sessionStart,omitempty"`
+	SessionEnd         []CursorHookEntry `json:"sessionEnd,omitempty"`
+	BeforeSubmitPrompt []CursorHookEntry `json:"beforeSubmitPrompt,omitempty"`
+	Stop               []CursorHookEntry `json:"stop,omitempty"`
+}
+
+// CursorHookEntry represents a single hook command.
+// Unlike Claude Code and Gemini CLI, Cursor hooks have no matchers.
+type CursorHookEntry struct {
+	Command string `json:"command"`
+}
+
+// hookInputRaw holds the fields Cursor sends to every hook, plus the
+// event-specific ones Entire reads. Cursor calls the session a conversation;
+// session hooks also send it as session_id.
+type hookInputRaw struct {
+	ConversationID    string   `json:"conversation_id"`
+	SessionID         string   `json:"session_id,omitempty"`
+	GenerationID      string   `json:"generation_id,omitempty"`
+	HookEventName     string   `json:"hook_event_name"`
+	TranscriptPath    string   `json:"transcript_path,omitempty"`
+	WorkspaceRoots    []string `json:"workspace_roots,omitempty"`
+	Prompt            string   `json:"prompt,omitempty"`              // beforeSubmitPrompt only
+	Status            string   `json:"status,omitempty"`              // stop only: completed, aborted, error
+	IsBackgroundAgent bool     `json:"is_background_agent,omitempty"` // sessionStart only
+}
+
+// sessionID returns the conversation ID, falling back to session_id.
+func (r *hookInputRaw) sessionID() string {
+	if r.ConversationID != "" {
+		return r.ConversationID
+	}
+	return r.SessionID
+}
+
+// Message role constants for Cursor transcripts.
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// TranscriptLine is one line of a Cursor agent transcript (JSONL).
+type TranscriptLine struct {
+	Role    string          `json:"role"`
+	Message json.RawMessage `json:"message"`
+}
+
+// transcriptMessage is the message of a transcript line.
+type transcriptMessage struct {
+	Content json.RawMessage `json:"content"`
+}
+
+// contentBlock is one block of a message's content array.
+type contentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// toolInput holds the file path fields of Cursor's file editing tools.
+type toolInput struct {
+	Path       string `json:"path,omitempty"`
+	FilePath   string `json:"file_path,omitempty"`
+	TargetFile string `json:"target_file,omitempty"`
+}
+
+// Tool names used in Cursor transcripts that modify files
+const (
+	ToolWrite        = "Write"
+	ToolStrReplace   = "StrReplace"
+	ToolMultiEdit    = "MultiEdit"
+	ToolDelete       = "Delete"
+	ToolEditNotebook = "EditNotebook"
+)
+
+// FileModificationTools lists tools that create, modify, or delete files in Cursor
+var FileModificationTools = []string{
+	ToolWrite,
+	ToolStrReplace,
+	ToolMultiEdit,
+	ToolDelete,
+	ToolEditNotebook,
+}
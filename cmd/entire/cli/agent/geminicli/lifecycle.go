@@ -1,9 +1,14 @@
 package geminicli
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
@@ -47,8 +52,11 @@ func (g *GeminiCLIAgent) ParseHookEvent(hookName string, stdin io.Reader) (*agen
 		return g.parseSessionEnd(stdin)
 	case HookNamePreCompress:
 		return g.parseCompaction(stdin)
-	case HookNameBeforeTool, HookNameAfterTool, HookNameBeforeModel,
-		HookNameAfterModel, HookNameBeforeToolSelection, HookNameNotification:
+	case HookNameBeforeTool:
+		return g.parseSubagentTool(stdin, agent.SubagentStart)
+	case HookNameAfterTool:
+		return g.parseSubagentTool(stdin, agent.SubagentEnd)
+	case HookNameBeforeModel, HookNameAfterModel, HookNameBeforeToolSelection, HookNameNotification:
 		// Acknowledged hooks with no lifecycle action
 		return nil, nil //nolint:nilnil // nil event = no lifecycle action
 	default:
@@ -168,3 +176,60 @@ func (g *GeminiCLIAgent) parseCompaction(stdin io.Reader) (*agent.Event, error)
 		Timestamp:  time.Now(),
 	}, nil
 }
+
+// parseSubagentTool turns the BeforeTool/AfterTool hooks of a subagent tool
+// into SubagentStart/SubagentEnd events. Hooks for other tools have no
+// lifecycle action.
+func (g *GeminiCLIAgent) parseSubagentTool(stdin io.Reader, eventType agent.EventType) (*agent.Event, error) {
+	raw, err := agent.ReadAndParseHookInput[toolHookInputRaw](stdin)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(SubagentTools, raw.ToolName) {
+		return nil, nil //nolint:nilnil // nil event = no lifecycle action
+	}
+	return &agent.Event{
+		Type:       eventType,
+		SessionID:  raw.SessionID,
+		SessionRef: raw.TranscriptPath,
+		ToolUseID:  subagentToolUseID(raw),
+		ToolInput:  normalizeSubagentToolInput(raw),
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// subagentToolUseID derives a stable ID for a subagent tool call from its
+// session, tool name, and input, so BeforeTool and AfterTool of the same
+// call agree on where the pre-task state is kept.
+func subagentToolUseID(raw *toolHookInputRaw) string {
+	var input bytes.Buffer
+	if err := json.Compact(&input, raw.ToolInput); err != nil {
+		input.Reset()
+		input.Write(raw.ToolInput)
+	}
+	h := sha256.New()
+	h.Write([]byte(raw.SessionID + "\x00" + raw.ToolName + "\x00"))
+	h.Write(input.Bytes())
+	return "gemini-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// normalizeSubagentToolInput rewrites a subagent tool's input into the
+// subagent_type/description shape task checkpoints read their commit
+// message from.
+func normalizeSubagentToolInput(raw *toolHookInputRaw) json.RawMessage {
+	var input subagentToolInput
+	_ = json.Unmarshal(raw.ToolInput, &input) //nolint:errcheck // missing fields just leave the message generic
+	subagentType := raw.ToolName
+	if input.AgentName != "" {
+		subagentType = input.AgentName
+	}
+	description := input.Objective
+	if description == "" {
+		description = input.Description
+	}
+	data, err := json.Marshal(map[string]string{"subagent_type": subagentType, "description": description})
+	if err != nil {
+		return nil
+	}
+	return data
+}
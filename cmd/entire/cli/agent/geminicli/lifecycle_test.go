@@ -437,3 +437,55 @@ func TestReadAndParse_AgentHookInput(t *testing.T) {
 		t.Errorf("expected hook_event_name 'before-agent', got %q", result.HookEventName)
 	}
 }
+
+func TestParseHookEvent_SubagentTools(t *testing.T) {
+	t.Parallel()
+
+	ag := &GeminiCLIAgent{}
+	before := `{"session_id": "sub-1", "transcript_path": "/t", "tool_name": "delegate_to_agent", "tool_input": {"agent_name": "codebase_investigator", "objective": "Find the auth flow"}}`
+	after := `{"session_id": "sub-1", "transcript_path": "/t", "tool_name": "delegate_to_agent", "tool_input": {"agent_name":"codebase_investigator","objective":"Find the auth flow"}, "tool_response": {"llmContent": "done"}}`
+
+	start, err := ag.ParseHookEvent(HookNameBeforeTool, strings.NewReader(before))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	end, err := ag.ParseHookEvent(HookNameAfterTool, strings.NewReader(after))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if start == nil || start.Type != agent.SubagentStart {
+		t.Fatalf("expected SubagentStart event, got %+v", start)
+	}
+	if end == nil || end.Type != agent.SubagentEnd {
+		t.Fatalf("expected SubagentEnd event, got %+v", end)
+	}
+	if start.ToolUseID == "" || start.ToolUseID != end.ToolUseID {
+		t.Errorf("expected matching tool use IDs, got %q and %q", start.ToolUseID, end.ToolUseID)
+	}
+	if start.SessionID != "sub-1" || start.SessionRef != "/t" {
+		t.Errorf("unexpected session fields: %+v", start)
+	}
+	if got := string(end.ToolInput); got != `{"description":"Find the auth flow","subagent_type":"codebase_investigator"}` {
+		t.Errorf("unexpected normalized tool input: %s", got)
+	}
+
+	// A second call with different input is a different subagent run
+	other, err := ag.ParseHookEvent(HookNameBeforeTool, strings.NewReader(
+		`{"session_id": "sub-1", "transcript_path": "/t", "tool_name": "codebase_investigator", "tool_input": {"objective": "Map the API"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other == nil || other.ToolUseID == start.ToolUseID {
+		t.Errorf("expected a distinct subagent event, got %+v", other)
+	}
+
+	// Other tools have no lifecycle action
+	event, err := ag.ParseHookEvent(HookNameBeforeTool, strings.NewReader(
+		`{"session_id": "sub-1", "transcript_path": "/t", "tool_name": "write_file", "tool_input": {"file_path": "a.go"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event != nil {
+		t.Errorf("expected nil event for write_file, got %+v", event)
+	}
+}
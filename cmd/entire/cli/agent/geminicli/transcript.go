@@ -306,6 +306,7 @@ func CalculateTokenUsage(data []byte, startMessageIndex int) *agent.TokenUsage {
 	}
 
 	usage := &agent.TokenUsage{}
+	callsByModel := make(map[string]int)
 
 	for i, msg := range transcript.Messages {
 		// Skip messages before startMessageIndex
@@ -326,7 +327,9 @@ func CalculateTokenUsage(data []byte, startMessageIndex int) *agent.TokenUsage {
 		usage.InputTokens += msg.Tokens.Input
 		usage.OutputTokens += msg.Tokens.Output
 		usage.CacheReadTokens += msg.Tokens.Cached
+		callsByModel[msg.Model]++
 	}
+	usage.Model = agent.MostUsedModel(callsByModel)
 
 	return usage
 }
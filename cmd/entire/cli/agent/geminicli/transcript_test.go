@@ -612,12 +612,16 @@ func TestCalculateTokenUsage_BasicMessages(t *testing.T) {
     {"id": "1", "type": "user", "content": "hello"},
     {"id": "2", "type": "gemini", "content": "hi there", "tokens": {"input": 10, "output": 20, "cached": 5, "thoughts": 0, "tool": 0, "total": 35}},
     {"id": "3", "type": "user", "content": "how are you?"},
-    {"id": "4", "type": "gemini", "content": "I'm doing well", "tokens": {"input": 15, "output": 25, "cached": 3, "thoughts": 0, "tool": 0, "total": 43}}
+    {"id": "4", "type": "gemini", "content": "I'm doing well", "model": "gemini-2.5-flash", "tokens": {"input": 15, "output": 25, "cached": 3, "thoughts": 0, "tool": 0, "total": 43}}
   ]
 }`)
 
 	usage := CalculateTokenUsage(data, 0)
 
+	if usage.Model != "gemini-2.5-flash" {
+		t.Errorf("Model = %q, want %q", usage.Model, "gemini-2.5-flash")
+	}
+
 	// Should have 2 API calls (2 gemini messages)
 	if usage.APICallCount != 2 {
 		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
@@ -1,5 +1,7 @@
 package geminicli
 
+import "encoding/json"
+
 // GeminiSettings represents the .gemini/settings.json structure
 type GeminiSettings struct {
 	HooksConfig GeminiHooksConfig `json:"hooksConfig,omitempty"`
@@ -63,6 +65,41 @@ type agentHookInputRaw struct {
 	Prompt         string `json:"prompt,omitempty"` // User's prompt (BeforeAgent only)
 }
 
+// toolHookInputRaw is the JSON structure from BeforeTool/AfterTool hooks.
+// Gemini CLI does not identify the tool call, so the call is recognized by
+// its tool name and input, which are the same in both hooks.
+type toolHookInputRaw struct {
+	SessionID      string          `json:"session_id"`
+	TranscriptPath string          `json:"transcript_path"`
+	Cwd            string          `json:"cwd"`
+	HookEventName  string          `json:"hook_event_name"`
+	Timestamp      string          `json:"timestamp"`
+	ToolName       string          `json:"tool_name"`
+	ToolInput      json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// subagentToolInput holds the tool_input fields of subagent tools that name
+// the subagent and describe its task.
+type subagentToolInput struct {
+	AgentName   string `json:"agent_name"`
+	Objective   string `json:"objective"`
+	Description string `json:"description"`
+}
+
+// Tool names used in Gemini CLI that run a subagent.
+// Newer Gemini CLI versions delegate through delegate_to_agent (naming the
+// subagent in agent_name); earlier ones expose each subagent as its own tool.
+const (
+	ToolDelegateToAgent      = "delegate_to_agent"
+	ToolCodebaseInvestigator = "codebase_investigator"
+)
+
+// SubagentTools lists tools whose BeforeTool/AfterTool hooks start and end a subagent
+var SubagentTools = []string{
+	ToolDelegateToAgent,
+	ToolCodebaseInvestigator,
+}
+
 // Tool names used in Gemini CLI that modify files
 // Note: Gemini CLI uses different names in different contexts:
 // - Internal/transcript names: write_file, replace
@@ -98,5 +135,6 @@ type geminiMessageTokens struct {
 type geminiMessageWithTokens struct {
 	ID     string               `json:"id"`
 	Type   string               `json:"type"`
+	Model  string               `json:"model,omitempty"`
 	Tokens *geminiMessageTokens `json:"tokens,omitempty"`
 }
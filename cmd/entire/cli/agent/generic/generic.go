@@ -0,0 +1,146 @@
+// Package generic implements an Agent that external tools drive directly with
+// normalized lifecycle events, for agents Entire has no dedicated support for.
+package generic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameGeneric, NewGenericAgent)
+}
+
+// GenericAgent implements the Agent interface for tools that send
+// `entire hooks generic <event>` themselves. Entire knows nothing about their
+// transcripts beyond the path each event names, so they are stored as-is and
+// modified files come from git status.
+//
+//nolint:revive // GenericAgent is clearer than Agent in this context
+type GenericAgent struct{}
+
+func NewGenericAgent() agent.Agent {
+	return &GenericAgent{}
+}
+
+// Name returns the agent registry key.
+func (g *GenericAgent) Name() agent.AgentName {
+	return agent.AgentNameGeneric
+}
+
+// Type returns the agent type identifier.
+func (g *GenericAgent) Type() agent.AgentType {
+	return agent.AgentTypeGeneric
+}
+
+// Description returns a human-readable description.
+func (g *GenericAgent) Description() string {
+	return "Generic - any tool sending lifecycle events to entire hooks generic"
+}
+
+func (g *GenericAgent) IsPreview() bool { return true }
+
+// DetectPresence always returns false: there is no configuration to detect.
+func (g *GenericAgent) DetectPresence() (bool, error) {
+	return false, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (g *GenericAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns nil: the generic agent keeps no state in the repository.
+func (g *GenericAgent) ProtectedDirs() []string { return nil }
+
+// GetSessionDir returns an error: transcripts live wherever the sending tool
+// keeps them, so there is no directory to restore them to.
+func (g *GenericAgent) GetSessionDir(_ string) (string, error) {
+	return "", errors.New("generic agent sessions have no known session directory")
+}
+
+// ResolveSessionFile returns the transcript path for a session. Only reached
+// with a session directory the caller chose.
+func (g *GenericAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	return filepath.Join(sessionDir, agentSessionID)
+}
+
+// ReadSession reads a session's transcript. NativeData holds the raw bytes.
+func (g *GenericAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (transcript path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:  input.SessionID,
+		AgentName:  g.Name(),
+		SessionRef: input.SessionRef,
+		StartTime:  time.Now(),
+		NativeData: data,
+	}, nil
+}
+
+// WriteSession writes a session's transcript back to its path.
+func (g *GenericAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to the generic agent
+	if session.AgentName != "" && session.AgentName != g.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, g.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (transcript path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// FormatResumeCommand describes how to resume a session. Entire doesn't know
+// the tool that started it.
+func (g *GenericAgent) FormatResumeCommand(sessionID string) string {
+	return "Resume session " + sessionID + " in the tool that started it."
+}
+
+// ReadTranscript reads the raw transcript bytes for a session.
+func (g *GenericAgent) ReadTranscript(sessionRef string) ([]byte, error) {
+	data, err := os.ReadFile(sessionRef) //nolint:gosec // Path comes from the event envelope
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return data, nil
+}
+
+// ChunkTranscript splits a transcript at line boundaries.
+func (g *GenericAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk transcript: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates chunks with newlines.
+func (g *GenericAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
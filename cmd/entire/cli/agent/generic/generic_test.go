@@ -0,0 +1,147 @@
+package generic
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+)
+
+func TestGenericAgent_Registered(t *testing.T) {
+	t.Parallel()
+
+	ag, err := agent.Get(agent.AgentNameGeneric)
+	if err != nil {
+		t.Fatalf("agent.Get(generic) error = %v", err)
+	}
+	if ag.Type() != agent.AgentTypeGeneric {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeGeneric)
+	}
+	if present, err := ag.DetectPresence(); err != nil || present {
+		t.Errorf("DetectPresence() = %v, %v, want false", present, err)
+	}
+}
+
+func TestHookNames(t *testing.T) {
+	t.Parallel()
+
+	want := []string{"session-start", "turn-start", "turn-end", "compaction", "session-end"}
+	if got := (&GenericAgent{}).HookNames(); !slices.Equal(got, want) {
+		t.Errorf("HookNames() = %q, want %q", got, want)
+	}
+}
+
+func TestParseHookEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		hookName   string
+		input      string
+		wantType   agent.EventType
+		wantRef    string
+		wantPrompt string
+	}{
+		{
+			name:     "session start",
+			hookName: validation.GenericEventSessionStart,
+			input:    `{"session_id": "run-42"}`,
+			wantType: agent.SessionStart,
+		},
+		{
+			name:       "turn start",
+			hookName:   validation.GenericEventTurnStart,
+			input:      `{"session_id": "run-42", "transcript_path": "/tmp/run-42.log", "prompt": "Fix the bug"}`,
+			wantType:   agent.TurnStart,
+			wantRef:    "/tmp/run-42.log",
+			wantPrompt: "Fix the bug",
+		},
+		{
+			name:     "turn end",
+			hookName: validation.GenericEventTurnEnd,
+			input:    `{"session_id": "run-42", "transcript_path": "/tmp/run-42.log"}`,
+			wantType: agent.TurnEnd,
+			wantRef:  "/tmp/run-42.log",
+		},
+		{
+			name:     "compaction",
+			hookName: validation.GenericEventCompaction,
+			input:    `{"session_id": "run-42", "transcript_path": "/tmp/run-42.log"}`,
+			wantType: agent.Compaction,
+			wantRef:  "/tmp/run-42.log",
+		},
+		{
+			name:     "session end",
+			hookName: validation.GenericEventSessionEnd,
+			input:    `{"session_id": "run-42"}`,
+			wantType: agent.SessionEnd,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			event, err := (&GenericAgent{}).ParseHookEvent(tt.hookName, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseHookEvent() error = %v", err)
+			}
+			if event == nil {
+				t.Fatal("ParseHookEvent() returned nil event")
+			}
+			if event.Type != tt.wantType {
+				t.Errorf("Type = %v, want %v", event.Type, tt.wantType)
+			}
+			if event.SessionID != "run-42" {
+				t.Errorf("SessionID = %q, want run-42", event.SessionID)
+			}
+			if event.SessionRef != tt.wantRef {
+				t.Errorf("SessionRef = %q, want %q", event.SessionRef, tt.wantRef)
+			}
+			if event.Prompt != tt.wantPrompt {
+				t.Errorf("Prompt = %q, want %q", event.Prompt, tt.wantPrompt)
+			}
+			if event.Timestamp.IsZero() {
+				t.Error("Timestamp is zero")
+			}
+		})
+	}
+}
+
+func TestParseHookEvent_EnvelopeFields(t *testing.T) {
+	t.Parallel()
+
+	input := `{"session_id": "run-42", "previous_session_id": "run-41", "timestamp": "2026-03-02T14:30:05Z", "metadata": {"model": "m1"}}`
+	event, err := (&GenericAgent{}).ParseHookEvent(validation.GenericEventSessionStart, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookEvent() error = %v", err)
+	}
+	if event.PreviousSessionID != "run-41" {
+		t.Errorf("PreviousSessionID = %q, want run-41", event.PreviousSessionID)
+	}
+	if want := time.Date(2026, 3, 2, 14, 30, 5, 0, time.UTC); !event.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", event.Timestamp, want)
+	}
+	if event.Metadata["model"] != "m1" {
+		t.Errorf("Metadata = %v, want model m1", event.Metadata)
+	}
+}
+
+func TestParseHookEvent_Invalid(t *testing.T) {
+	t.Parallel()
+
+	ag := &GenericAgent{}
+	if _, err := ag.ParseHookEvent(validation.GenericEventTurnEnd, strings.NewReader(`{"session_id": "run-42"}`)); err == nil {
+		t.Error("ParseHookEvent() should reject turn-end without transcript_path")
+	}
+	if _, err := ag.ParseHookEvent(validation.GenericEventSessionStart, strings.NewReader(`{"session": "run-42"}`)); err == nil {
+		t.Error("ParseHookEvent() should reject unknown fields")
+	}
+	event, err := ag.ParseHookEvent("unknown", strings.NewReader(`{"session_id": "run-42"}`))
+	if err != nil || event != nil {
+		t.Errorf("ParseHookEvent() for unknown hook = %+v, %v, want nil, nil", event, err)
+	}
+}
@@ -0,0 +1,77 @@
+package generic
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+)
+
+// Ensure GenericAgent implements HookSupport
+var _ agent.HookSupport = (*GenericAgent)(nil)
+
+// HookNames returns the generic lifecycle events.
+// These become subcommands: entire hooks generic <event>
+func (g *GenericAgent) HookNames() []string {
+	return validation.GenericEventNames()
+}
+
+// ParseHookEvent validates the event envelope on stdin and translates it into
+// a lifecycle Event. See validation.GenericEvent for the envelope.
+func (g *GenericAgent) ParseHookEvent(hookName string, stdin io.Reader) (*agent.Event, error) {
+	var eventType agent.EventType
+	switch hookName {
+	case validation.GenericEventSessionStart:
+		eventType = agent.SessionStart
+	case validation.GenericEventTurnStart:
+		eventType = agent.TurnStart
+	case validation.GenericEventTurnEnd:
+		eventType = agent.TurnEnd
+	case validation.GenericEventCompaction:
+		eventType = agent.Compaction
+	case validation.GenericEventSessionEnd:
+		eventType = agent.SessionEnd
+	default:
+		return nil, nil //nolint:nilnil // Unknown hooks have no lifecycle action
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook input: %w", err)
+	}
+	envelope, err := validation.ParseGenericEvent(hookName, data)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Validation errors describe the envelope problem
+	}
+
+	event := &agent.Event{
+		Type:              eventType,
+		SessionID:         envelope.SessionID,
+		PreviousSessionID: envelope.PreviousSessionID,
+		SessionRef:        envelope.TranscriptPath,
+		Prompt:            envelope.Prompt,
+		Timestamp:         time.Now(),
+		Metadata:          envelope.Metadata,
+	}
+	if envelope.Timestamp != nil {
+		event.Timestamp = *envelope.Timestamp
+	}
+	return event, nil
+}
+
+// InstallHooks installs nothing: tools call `entire hooks generic` themselves.
+func (g *GenericAgent) InstallHooks(_ bool, _ bool) (int, error) {
+	return 0, nil
+}
+
+// UninstallHooks removes nothing: there are no generic hooks to remove.
+func (g *GenericAgent) UninstallHooks() error {
+	return nil
+}
+
+// AreHooksInstalled returns false: there are no generic hooks to install.
+func (g *GenericAgent) AreHooksInstalled() bool {
+	return false
+}
@@ -91,15 +91,23 @@ type AgentType string
 
 // Agent name constants (registry keys)
 const (
+	AgentNameAider      AgentName = "aider"
 	AgentNameClaudeCode AgentName = "claude-code"
+	AgentNameCodex      AgentName = "codex"
+	AgentNameCursor     AgentName = "cursor"
 	AgentNameGemini     AgentName = "gemini"
+	AgentNameGeneric    AgentName = "generic"
 	AgentNameOpenCode   AgentName = "opencode"
 )
 
 // Agent type constants (type identifiers stored in metadata/trailers)
 const (
+	AgentTypeAider      AgentType = "Aider"
 	AgentTypeClaudeCode AgentType = "Claude Code"
+	AgentTypeCodex      AgentType = "Codex"
+	AgentTypeCursor     AgentType = "Cursor"
 	AgentTypeGemini     AgentType = "Gemini CLI"
+	AgentTypeGeneric    AgentType = "Generic"
 	AgentTypeOpenCode   AgentType = "OpenCode"
 	AgentTypeUnknown    AgentType = "Agent" // Fallback for backwards compatibility
 )
@@ -56,6 +56,23 @@ type TokenUsage struct {
 	OutputTokens int `json:"output_tokens"`
 	// APICallCount is the number of API calls made
 	APICallCount int `json:"api_call_count"`
+	// Model is the model that served the most API calls, when the transcript names it
+	Model string `json:"model,omitempty"`
 	// SubagentTokens contains token usage from spawned subagents (if any)
 	SubagentTokens *TokenUsage `json:"subagent_tokens,omitempty"`
 }
+
+// MostUsedModel returns the model with the most API calls in counts, breaking
+// ties by name. Returns "" if counts is empty.
+func MostUsedModel(counts map[string]int) string {
+	best := ""
+	for model, n := range counts {
+		if model == "" {
+			continue
+		}
+		if best == "" || n > counts[best] || (n == counts[best] && model < best) {
+			best = model
+		}
+	}
+	return best
+}
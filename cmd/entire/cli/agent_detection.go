@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// Agent detection cache.
+//
+// agent.DetectAll stats several directories in the repository root on every
+// call, which adds up on network mounts. Results are cached in the git directory,
+// keyed by worktree root. Agent presence markers (.claude/, .gemini/, ...) live
+// directly in the worktree root, so creating or removing one changes the root's
+// mtime and invalidates the cache. Entries also expire after a short TTL.
+
+// agentDetectionCacheFileName is the cache file name inside the git directory.
+const agentDetectionCacheFileName = "entire-agent-detection.json"
+
+// agentDetectionCacheTTL is how long a cached detection result stays valid.
+const agentDetectionCacheTTL = 5 * time.Minute
+
+// agentDetectionCache is the on-disk format of the detection cache.
+type agentDetectionCache struct {
+	RepoRoot    string            `json:"repo_root"`
+	RootModTime time.Time         `json:"root_mod_time"`
+	DetectedAt  time.Time         `json:"detected_at"`
+	Agents      []agent.AgentName `json:"agents"`
+}
+
+// agentDetectionResult holds detected agents and where the result came from.
+type agentDetectionResult struct {
+	Agents     []agent.Agent
+	Cached     bool
+	DetectedAt time.Time
+}
+
+// detectAgents returns the agents present in the current repository, using the
+// detection cache when it is still valid. refresh forces re-detection.
+// Falls back to uncached detection outside a git repository.
+func detectAgents(refresh bool) agentDetectionResult {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return agentDetectionResult{Agents: agent.DetectAll(), DetectedAt: time.Now()}
+	}
+	gitDir, err := strategy.GetGitDir()
+	if err != nil {
+		return agentDetectionResult{Agents: agent.DetectAll(), DetectedAt: time.Now()}
+	}
+	return detectAgentsWithCache(repoRoot, filepath.Join(gitDir, agentDetectionCacheFileName), time.Now(), refresh)
+}
+
+// detectAgentsWithCache implements detectAgents against an explicit cache path and clock.
+func detectAgentsWithCache(repoRoot, cachePath string, now time.Time, refresh bool) agentDetectionResult {
+	ctx := logging.WithComponent(context.Background(), "agent-detection")
+
+	info, err := os.Stat(repoRoot)
+	if err != nil {
+		return agentDetectionResult{Agents: agent.DetectAll(), DetectedAt: now}
+	}
+	rootModTime := info.ModTime()
+
+	if !refresh {
+		if cache, ok := loadAgentDetectionCache(cachePath); ok && cache.isValid(repoRoot, rootModTime, now) {
+			var agents []agent.Agent
+			for _, name := range cache.Agents {
+				if ag, getErr := agent.Get(name); getErr == nil {
+					agents = append(agents, ag)
+				}
+			}
+			logging.Debug(ctx, "using cached agent detection",
+				slog.Int("agents", len(agents)),
+				slog.Time("detected_at", cache.DetectedAt),
+			)
+			return agentDetectionResult{Agents: agents, Cached: true, DetectedAt: cache.DetectedAt}
+		}
+	}
+
+	detected := agent.DetectAll()
+	cache := agentDetectionCache{
+		RepoRoot:    repoRoot,
+		RootModTime: rootModTime,
+		DetectedAt:  now,
+	}
+	for _, ag := range detected {
+		cache.Agents = append(cache.Agents, ag.Name())
+	}
+	if err := writeAgentDetectionCache(cachePath, cache); err != nil {
+		logging.Debug(ctx, "failed to write agent detection cache", slog.String("error", err.Error()))
+	}
+	return agentDetectionResult{Agents: detected, DetectedAt: now}
+}
+
+// isValid reports whether the cache entry still describes repoRoot.
+func (c *agentDetectionCache) isValid(repoRoot string, rootModTime, now time.Time) bool {
+	if c.RepoRoot != repoRoot || !c.RootModTime.Equal(rootModTime) {
+		return false
+	}
+	age := now.Sub(c.DetectedAt)
+	return age >= 0 && age < agentDetectionCacheTTL
+}
+
+// loadAgentDetectionCache reads the cache file. Returns false if it is missing or unreadable.
+func loadAgentDetectionCache(cachePath string) (*agentDetectionCache, bool) {
+	data, err := os.ReadFile(cachePath) //nolint:gosec // path is built from the git directory
+	if err != nil {
+		return nil, false
+	}
+	var cache agentDetectionCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false
+	}
+	return &cache, true
+}
+
+// writeAgentDetectionCache writes the cache file.
+func writeAgentDetectionCache(cachePath string, cache agentDetectionCache) error {
+	data, err := jsonutil.MarshalIndentWithNewline(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent detection cache: %w", err)
+	}
+	if err := os.WriteFile(cachePath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write agent detection cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupAgentDetectionRepo creates a repo containing a .claude directory and
+// returns the worktree root and a cache path inside the git directory.
+func setupAgentDetectionRepo(t *testing.T) (string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".claude"), 0o755))
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	repoRoot, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	return repoRoot, filepath.Join(repoRoot, ".git", agentDetectionCacheFileName)
+}
+
+func agentNames(agents []agent.Agent) []agent.AgentName {
+	names := make([]agent.AgentName, 0, len(agents))
+	for _, ag := range agents {
+		names = append(names, ag.Name())
+	}
+	return names
+}
+
+func TestDetectAgentsWithCache_UsesCache(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot, cachePath := setupAgentDetectionRepo(t)
+	now := time.Now()
+
+	first := detectAgentsWithCache(repoRoot, cachePath, now, false)
+	assert.False(t, first.Cached)
+	assert.Equal(t, []agent.AgentName{agent.AgentNameClaudeCode}, agentNames(first.Agents))
+
+	second := detectAgentsWithCache(repoRoot, cachePath, now.Add(time.Minute), false)
+	assert.True(t, second.Cached)
+	assert.True(t, now.Equal(second.DetectedAt))
+	assert.Equal(t, []agent.AgentName{agent.AgentNameClaudeCode}, agentNames(second.Agents))
+}
+
+func TestDetectAgentsWithCache_InvalidatedByRootChange(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot, cachePath := setupAgentDetectionRepo(t)
+	now := time.Now()
+
+	detectAgentsWithCache(repoRoot, cachePath, now, false)
+
+	// Adding an agent marker changes the root directory's mtime
+	require.NoError(t, os.MkdirAll(filepath.Join(repoRoot, ".gemini"), 0o755))
+	future := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(repoRoot, future, future))
+
+	result := detectAgentsWithCache(repoRoot, cachePath, now.Add(time.Minute), false)
+	assert.False(t, result.Cached)
+	assert.Equal(t, []agent.AgentName{agent.AgentNameClaudeCode, agent.AgentNameGemini}, agentNames(result.Agents))
+}
+
+func TestDetectAgentsWithCache_ExpiresAfterTTL(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot, cachePath := setupAgentDetectionRepo(t)
+	now := time.Now()
+
+	detectAgentsWithCache(repoRoot, cachePath, now, false)
+
+	result := detectAgentsWithCache(repoRoot, cachePath, now.Add(agentDetectionCacheTTL), false)
+	assert.False(t, result.Cached)
+}
+
+func TestDetectAgentsWithCache_Refresh(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot, cachePath := setupAgentDetectionRepo(t)
+	now := time.Now()
+
+	detectAgentsWithCache(repoRoot, cachePath, now, false)
+
+	result := detectAgentsWithCache(repoRoot, cachePath, now.Add(time.Minute), true)
+	assert.False(t, result.Cached)
+	assert.Equal(t, []agent.AgentName{agent.AgentNameClaudeCode}, agentNames(result.Agents))
+
+	// Refresh rewrites the cache with the new detection time
+	cache, ok := loadAgentDetectionCache(cachePath)
+	require.True(t, ok)
+	assert.True(t, now.Add(time.Minute).Equal(cache.DetectedAt))
+}
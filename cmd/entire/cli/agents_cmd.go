@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newAgentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agents",
+		Short: "Inspect supported AI agents",
+	}
+
+	cmd.AddCommand(newAgentsDetectCmd())
+
+	return cmd
+}
+
+func newAgentsDetectCmd() *cobra.Command {
+	var refresh bool
+
+	cmd := &cobra.Command{
+		Use:   "detect",
+		Short: "Show which agents are detected in this repository",
+		Long: `Show which agents are detected in this repository.
+
+Detection results are cached per repository for a few minutes and invalidated
+when files are added to or removed from the repository root. Use --refresh to
+force re-detection.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			result := detectAgents(refresh)
+			w := cmd.OutOrStdout()
+
+			if len(result.Agents) == 0 {
+				fmt.Fprintln(w, "No agents detected.")
+			} else {
+				fmt.Fprintln(w, "Detected agents:")
+				for _, ag := range result.Agents {
+					fmt.Fprintf(w, "  %s (%s)\n", ag.Name(), ag.Type())
+				}
+			}
+
+			if result.Cached {
+				age := time.Since(result.DetectedAt).Round(time.Second)
+				fmt.Fprintf(w, "\nCached result from %s ago. Use --refresh to re-detect.\n", age)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Ignore cached results and re-detect agents")
+
+	return cmd
+}
@@ -0,0 +1,247 @@
+// Package audit records every git ref, session state file, and worktree file
+// that Entire writes, so users can see exactly what the CLI changed in their
+// repository.
+//
+// The log is always on. It is a JSON Lines file in the git common directory
+// (shared by all worktrees and never committed), rotated once it grows past
+// maxLogSize. Recording is best-effort: a failure to write the audit log is
+// logged but never fails the operation being audited.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// LogFileName is the audit log file name within the git common directory.
+const LogFileName = "entire-audit.jsonl"
+
+// maxLogSize is the size at which the log is rotated to LogFileName + ".1".
+const maxLogSize = 5 << 20 // 5 MiB
+
+// Action identifies the kind of mutation recorded.
+type Action string
+
+const (
+	ActionRefUpdate   Action = "ref_update"
+	ActionRefDelete   Action = "ref_delete"
+	ActionStateWrite  Action = "state_write"
+	ActionStateDelete Action = "state_delete"
+	ActionFileWrite   Action = "file_write"
+	ActionFileDelete  Action = "file_delete"
+	// ActionSessionErase records a session erased from local state and the
+	// metadata branch history. Old and New are the branch tips.
+	ActionSessionErase Action = "session_erase"
+)
+
+// Event is a single audit log record.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Trigger is the Entire command or hook that caused the mutation,
+	// e.g. "hooks git post-commit" or "rewind".
+	Trigger string `json:"trigger"`
+	Action  Action `json:"action"`
+	// Target is the ref name, session ID, or repo-relative file path.
+	Target string `json:"target"`
+	// Old and New are object hashes for ref updates, when known.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+var writeMu sync.Mutex
+
+// Record appends an event to the audit log in gitDir. The timestamp and
+// trigger are filled in when empty. gitDir may be a linked worktree's git
+// directory; events are always written to the common directory.
+func Record(gitDir string, ev Event) {
+	if gitDir == "" {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	if ev.Trigger == "" {
+		ev.Trigger = Trigger()
+	}
+
+	if err := appendLine(LogPath(gitDir), ev); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "audit"), "failed to write audit log",
+			slog.String("action", string(ev.Action)),
+			slog.String("target", ev.Target),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// RecordRepo appends an event to the audit log of repo.
+func RecordRepo(repo *git.Repository, ev Event) {
+	Record(GitDir(repo), ev)
+}
+
+// GitDir returns the git directory backing repo, or "" if it is not on disk.
+func GitDir(repo *git.Repository) string {
+	if repo == nil {
+		return ""
+	}
+	fsStorage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return ""
+	}
+	return fsStorage.Filesystem().Root()
+}
+
+// LogPath returns the audit log path for gitDir, resolving linked worktree
+// git directories to the common directory via their "commondir" file.
+func LogPath(gitDir string) string {
+	return filepath.Join(commonDir(gitDir), LogFileName)
+}
+
+// commonDir resolves a linked worktree's git directory to the common
+// directory via its "commondir" file. Other directories are returned as-is.
+func commonDir(gitDir string) string {
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil { //nolint:gosec // path is within the git directory
+		dir := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitDir, dir)
+		}
+		return filepath.Clean(dir)
+	}
+	return gitDir
+}
+
+// Trigger describes the running Entire command from its arguments, e.g.
+// "hooks claude-code stop". Flags and their values are omitted.
+func Trigger() string {
+	var parts []string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		parts = append(parts, arg)
+	}
+	if len(parts) == 0 {
+		return "entire"
+	}
+	return strings.Join(parts, " ")
+}
+
+// appendLine appends v to the JSON Lines log at path, rotating the log to
+// path + ".1" first if the line would grow it past maxLogSize.
+func appendLine(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling audit event: %w", err)
+	}
+	data = append(data, '\n')
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(data)) > maxLogSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return fmt.Errorf("rotating audit log: %w", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // path is within the git directory
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close() // best-effort cleanup, write error takes precedence
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+	return nil
+}
+
+// Read returns audit events recorded at or after since, oldest first,
+// including events in the rotated log. Malformed lines are skipped.
+func Read(gitDir string, since time.Time) ([]Event, error) {
+	return readLines(LogPath(gitDir), func(ev Event) bool { return !ev.Time.Before(since) })
+}
+
+// readLines returns the records of the JSON Lines log at path that keep
+// accepts, oldest first, including those in the rotated log. Malformed lines
+// are skipped.
+func readLines[T any](path string, keep func(T) bool) ([]T, error) {
+	var records []T
+	for _, p := range []string{path + ".1", path} {
+		data, err := os.ReadFile(p) //nolint:gosec // path is within the git directory
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for scanner.Scan() {
+			var record T
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				continue
+			}
+			if keep(record) {
+				records = append(records, record)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading audit log: %w", err)
+		}
+	}
+	return records, nil
+}
+
+// SetReference updates a ref in repo and records the update, including the
+// previous target when the ref already existed.
+func SetReference(repo *git.Repository, ref *plumbing.Reference) error {
+	ev := Event{Action: ActionRefUpdate, Target: ref.Name().String(), New: ref.Hash().String()}
+	if old, err := repo.Storer.Reference(ref.Name()); err == nil {
+		ev.Old = old.Hash().String()
+	}
+	if err := faultinject.Check(faultinject.RefUpdate); err != nil {
+		return err //nolint:wrapcheck // callers add context, matching direct SetReference use
+	}
+	if err := repo.Storer.SetReference(ref); err != nil {
+		return err //nolint:wrapcheck // callers add context, matching direct SetReference use
+	}
+	RecordRepo(repo, ev)
+	return nil
+}
+
+// CheckAndSetReference updates a ref in repo only if it still points where
+// old does, and records the update. Returns storage.ErrReferenceHasChanged
+// when another writer moved the ref first.
+func CheckAndSetReference(repo *git.Repository, ref, old *plumbing.Reference) error {
+	ev := Event{Action: ActionRefUpdate, Target: ref.Name().String(), New: ref.Hash().String()}
+	if old != nil {
+		ev.Old = old.Hash().String()
+	}
+	if err := faultinject.Check(faultinject.RefUpdate); err != nil {
+		return err //nolint:wrapcheck // callers add context, matching direct SetReference use
+	}
+	if err := repo.Storer.CheckAndSetReference(ref, old); err != nil {
+		return err //nolint:wrapcheck // callers add context, matching direct SetReference use
+	}
+	RecordRepo(repo, ev)
+	return nil
+}
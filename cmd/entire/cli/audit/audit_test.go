@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	Record(gitDir, Event{Time: base, Trigger: "hooks git post-commit", Action: ActionRefUpdate, Target: "refs/heads/entire/checkpoints/v1", New: "abc"})
+	Record(gitDir, Event{Time: base.Add(time.Hour), Trigger: "rewind", Action: ActionFileWrite, Target: "main.go"})
+
+	events, err := Read(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Read() returned %d events, want 2", len(events))
+	}
+	if events[0].Action != ActionRefUpdate || events[0].New != "abc" {
+		t.Errorf("events[0] = %+v, want ref update to abc", events[0])
+	}
+	if events[1].Trigger != "rewind" || events[1].Target != "main.go" {
+		t.Errorf("events[1] = %+v, want rewind file write of main.go", events[1])
+	}
+
+	events, err = Read(gitDir, base.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("Read(since) error = %v", err)
+	}
+	if len(events) != 1 || events[0].Action != ActionFileWrite {
+		t.Errorf("Read(since) = %+v, want only the file write", events)
+	}
+}
+
+func TestRecord_FillsTimeAndTrigger(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+
+	Record(gitDir, Event{Action: ActionStateWrite, Target: "session-1"})
+
+	events, err := Read(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Read() returned %d events, want 1", len(events))
+	}
+	if events[0].Time.IsZero() {
+		t.Error("expected time to be filled in")
+	}
+	if events[0].Trigger == "" {
+		t.Error("expected trigger to be filled in")
+	}
+}
+
+func TestRead_MissingLog(t *testing.T) {
+	t.Parallel()
+
+	events, err := Read(t.TempDir(), time.Time{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Read() = %+v, want no events", events)
+	}
+}
+
+func TestRead_SkipsMalformedLines(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+
+	Record(gitDir, Event{Action: ActionRefDelete, Target: "refs/heads/entire/abc1234"})
+	f, err := os.OpenFile(LogPath(gitDir), os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("not json\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	events, err := Read(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Read() returned %d events, want 1", len(events))
+	}
+}
+
+func TestAppendEvent_Rotates(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+	path := LogPath(gitDir)
+
+	// Pre-fill the log to just under the rotation size.
+	if err := os.WriteFile(path, make([]byte, maxLogSize-1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	Record(gitDir, Event{Action: ActionStateDelete, Target: "session-1"})
+
+	rotated, err := os.Stat(path + ".1")
+	if err != nil {
+		t.Fatalf("expected rotated log: %v", err)
+	}
+	if rotated.Size() != maxLogSize-1 {
+		t.Errorf("rotated log size = %d, want %d", rotated.Size(), maxLogSize-1)
+	}
+	current, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("expected new log: %v", err)
+	}
+	if current.Size() >= maxLogSize {
+		t.Errorf("new log size = %d, want a fresh log", current.Size())
+	}
+}
+
+func TestLogPath_LinkedWorktree(t *testing.T) {
+	t.Parallel()
+	commonDir := t.TempDir()
+	worktreeGitDir := filepath.Join(commonDir, "worktrees", "feature")
+	if err := os.MkdirAll(worktreeGitDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreeGitDir, "commondir"), []byte("../..\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(commonDir, LogFileName)
+	if got := LogPath(worktreeGitDir); got != want {
+		t.Errorf("LogPath() = %q, want %q", got, want)
+	}
+	if got := LogPath(commonDir); got != want {
+		t.Errorf("LogPath(common) = %q, want %q", got, want)
+	}
+}
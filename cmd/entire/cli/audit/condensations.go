@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+)
+
+// CondensationLogFileName is the condensation log file name within the git
+// common directory.
+const CondensationLogFileName = "entire-condensations.jsonl"
+
+// Decision is what the post-commit hook did with a session.
+type Decision string
+
+const (
+	// DecisionCondensed means the session was condensed into the commit's
+	// checkpoint.
+	DecisionCondensed Decision = "condensed"
+	// DecisionSkipped means the session was left out of the commit.
+	DecisionSkipped Decision = "skipped"
+	// DecisionCarryForward means files with uncommitted agent changes were
+	// carried forward to the next commit after condensing.
+	DecisionCarryForward Decision = "carry-forward"
+)
+
+// Condensation records one post-commit decision about one session. It
+// answers "why wasn't my session condensed into that commit?".
+type Condensation struct {
+	Time    time.Time `json:"time"`
+	Trigger string    `json:"trigger"`
+	// SessionID is empty for decisions about a commit rather than a
+	// session, e.g. a trailer with no sessions to condense.
+	SessionID    string   `json:"session_id,omitempty"`
+	Commit       string   `json:"commit"`
+	CheckpointID string   `json:"checkpoint_id,omitempty"`
+	Phase        string   `json:"phase,omitempty"`
+	Decision     Decision `json:"decision"`
+	Reason       string   `json:"reason,omitempty"`
+	// Files are the files condensed or carried forward.
+	Files []string `json:"files,omitempty"`
+}
+
+// RecordCondensation appends a decision to the condensation log in gitDir.
+// The timestamp and trigger are filled in when empty. Like Record, it never
+// fails the hook: write errors are only logged.
+func RecordCondensation(gitDir string, c Condensation) {
+	if gitDir == "" {
+		return
+	}
+	if c.Time.IsZero() {
+		c.Time = time.Now().UTC()
+	}
+	if c.Trigger == "" {
+		c.Trigger = Trigger()
+	}
+
+	if err := appendLine(CondensationLogPath(gitDir), c); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "audit"), "failed to write condensation log",
+			slog.String("session_id", c.SessionID),
+			slog.String("commit", c.Commit),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// CondensationLogPath returns the condensation log path for gitDir.
+func CondensationLogPath(gitDir string) string {
+	return filepath.Join(commonDir(gitDir), CondensationLogFileName)
+}
+
+// ReadCondensations returns decisions recorded at or after since, oldest
+// first, including those in the rotated log. Malformed lines are skipped.
+func ReadCondensations(gitDir string, since time.Time) ([]Condensation, error) {
+	return readLines(CondensationLogPath(gitDir), func(c Condensation) bool { return !c.Time.Before(since) })
+}
@@ -0,0 +1,47 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordAndReadCondensations(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	RecordCondensation(gitDir, Condensation{Time: base, SessionID: "session-1", Commit: "abc", Decision: DecisionCondensed})
+	RecordCondensation(gitDir, Condensation{Time: base.Add(time.Hour), SessionID: "session-2", Commit: "abc", Decision: DecisionSkipped, Reason: "no new content"})
+	// Condensations are not audit events, and the other way around.
+	Record(gitDir, Event{Time: base, Action: ActionRefUpdate, Target: "refs/heads/main"})
+
+	decisions, err := ReadCondensations(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadCondensations() error = %v", err)
+	}
+	if len(decisions) != 2 {
+		t.Fatalf("ReadCondensations() returned %d decisions, want 2", len(decisions))
+	}
+	if decisions[1].Decision != DecisionSkipped || decisions[1].Reason != "no new content" {
+		t.Errorf("decisions[1] = %+v, want skipped with reason", decisions[1])
+	}
+	if decisions[0].Trigger == "" {
+		t.Error("expected trigger to be filled in")
+	}
+
+	decisions, err = ReadCondensations(gitDir, base.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("ReadCondensations(since) error = %v", err)
+	}
+	if len(decisions) != 1 || decisions[0].SessionID != "session-2" {
+		t.Errorf("ReadCondensations(since) = %+v, want only session-2", decisions)
+	}
+
+	events, err := Read(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Read() returned %d events, want 1", len(events))
+	}
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+)
+
+// HookRunLogFileName is the hook run log file name within the git common
+// directory.
+const HookRunLogFileName = "entire-hook-runs.jsonl"
+
+// HookRun records one run of an Entire hook. Hooks run in short-lived
+// processes, so this log is what `entire serve` reads to report hook
+// durations and failures. Runs are only recorded while the hook_metrics
+// feature flag is enabled.
+type HookRun struct {
+	Time time.Time `json:"time"`
+	// Hook is the hook that ran, e.g. "git/post-commit" or "claude-code/stop".
+	Hook       string `json:"hook"`
+	DurationMs int64  `json:"duration_ms"`
+	// Error is the error the hook returned, empty when it succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// RecordHookRun appends a hook run to the hook run log in gitDir. The
+// timestamp is filled in when empty. Like Record, it never fails the hook:
+// write errors are only logged.
+func RecordHookRun(gitDir string, run HookRun) {
+	if gitDir == "" {
+		return
+	}
+	if run.Time.IsZero() {
+		run.Time = time.Now().UTC()
+	}
+
+	if err := appendLine(HookRunLogPath(gitDir), run); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "audit"), "failed to write hook run log",
+			slog.String("hook", run.Hook),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// HookRunLogPath returns the hook run log path for gitDir.
+func HookRunLogPath(gitDir string) string {
+	return filepath.Join(commonDir(gitDir), HookRunLogFileName)
+}
+
+// ReadHookRuns returns hook runs recorded at or after since, oldest first,
+// including those in the rotated log. Malformed lines are skipped.
+func ReadHookRuns(gitDir string, since time.Time) ([]HookRun, error) {
+	return readLines(HookRunLogPath(gitDir), func(r HookRun) bool { return !r.Time.Before(since) })
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordAndReadHookRuns(t *testing.T) {
+	t.Parallel()
+	gitDir := t.TempDir()
+
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	RecordHookRun(gitDir, HookRun{Time: base, Hook: "git/post-commit", DurationMs: 120})
+	RecordHookRun(gitDir, HookRun{Time: base.Add(time.Hour), Hook: "claude-code/stop", DurationMs: 40, Error: "boom"})
+	RecordHookRun(gitDir, HookRun{Hook: "git/pre-push"})
+
+	runs, err := ReadHookRuns(gitDir, time.Time{})
+	if err != nil {
+		t.Fatalf("ReadHookRuns() error = %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("ReadHookRuns() returned %d runs, want 3", len(runs))
+	}
+	if runs[1].Error != "boom" || runs[1].DurationMs != 40 {
+		t.Errorf("runs[1] = %+v, want failed run of 40ms", runs[1])
+	}
+	if runs[2].Time.IsZero() {
+		t.Error("expected time to be filled in")
+	}
+
+	runs, err = ReadHookRuns(gitDir, base.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("ReadHookRuns(since) error = %v", err)
+	}
+	if len(runs) != 2 || runs[0].Hook != "claude-code/stop" {
+		t.Errorf("ReadHookRuns(since) = %+v, want claude-code/stop first", runs)
+	}
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect changes Entire made to this repository",
+	}
+
+	cmd.AddCommand(newAuditLogCmd())
+
+	return cmd
+}
+
+func newAuditLogCmd() *cobra.Command {
+	var sinceFlag string
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show every ref, session state, and worktree file Entire has written",
+		Long: `Show the audit log of git mutations made by Entire in this repository.
+
+Every ref update or deletion (shadow branches, the checkpoints branch), session
+state write, and worktree file written during rewind is recorded with a
+timestamp and the command or hook that triggered it. The log is always on and
+is stored in the git directory as ` + audit.LogFileName + `.
+
+--since accepts a duration (90m, 24h, 7d), a date (2006-01-02), or an
+RFC 3339 timestamp.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			since, err := parseSince(sinceFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			return runAuditLog(cmd, since, jsonFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only show events after this time (duration, date, or timestamp)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output events as JSON lines")
+
+	return cmd
+}
+
+func runAuditLog(cmd *cobra.Command, since time.Time, asJSON bool) error {
+	commonDir, err := strategy.GetGitCommonDir()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	events, err := audit.Read(commonDir, since)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if asJSON {
+		return writeAuditJSON(w, events)
+	}
+	if len(events) == 0 {
+		fmt.Fprintln(w, "No audit events recorded.")
+		return nil
+	}
+	for _, ev := range events {
+		fmt.Fprintln(w, formatAuditEvent(ev))
+	}
+	return nil
+}
+
+func writeAuditJSON(w io.Writer, events []audit.Event) error {
+	enc := json.NewEncoder(w)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("failed to encode audit event: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatAuditEvent renders an event as a single human-readable line.
+func formatAuditEvent(ev audit.Event) string {
+	line := fmt.Sprintf("%s  %-12s  %s  [%s]",
+		ev.Time.Local().Format("2006-01-02 15:04:05"), ev.Action, ev.Target, ev.Trigger)
+	switch {
+	case ev.Old != "" && ev.New != "":
+		line += fmt.Sprintf("  %s..%s", strategy.TruncateHash(ev.Old), strategy.TruncateHash(ev.New))
+	case ev.New != "":
+		line += "  " + strategy.TruncateHash(ev.New)
+	}
+	return line
+}
+
+// parseSince parses a --since value relative to now. Accepts Go durations,
+// whole days ("7d"), dates ("2006-01-02"), and RFC 3339 timestamps. An empty
+// value means no lower bound.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	return parseTimeFlag("--since", value, now)
+}
+
+// parseTimeFlag parses the value of the named time flag like parseSince.
+func parseTimeFlag(flag, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s value %q: use a duration (24h, 7d), date (2006-01-02), or RFC 3339 timestamp", flag, value)
+}
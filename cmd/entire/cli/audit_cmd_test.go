@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-03-01T08:00:00Z", time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		require.NoError(t, err, tt.value)
+		assert.True(t, tt.want.Equal(got), "parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+	}
+
+	for _, bad := range []string{"yesterday", "-1h", "xd"} {
+		_, err := parseSince(bad, now)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFormatAuditEvent(t *testing.T) {
+	t.Parallel()
+
+	line := formatAuditEvent(audit.Event{
+		Time:    time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC),
+		Trigger: "hooks git post-commit",
+		Action:  audit.ActionRefUpdate,
+		Target:  "refs/heads/entire/checkpoints/v1",
+		Old:     "1111111111111111111111111111111111111111",
+		New:     "2222222222222222222222222222222222222222",
+	})
+	assert.Contains(t, line, "ref_update")
+	assert.Contains(t, line, "refs/heads/entire/checkpoints/v1")
+	assert.Contains(t, line, "[hooks git post-commit]")
+	assert.Contains(t, line, "1111111..2222222")
+
+	line = formatAuditEvent(audit.Event{Action: audit.ActionFileDelete, Target: "tmp.txt", Trigger: "rewind"})
+	assert.False(t, strings.Contains(line, ".."), "file events have no hashes: %q", line)
+}
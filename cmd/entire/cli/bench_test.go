@@ -77,7 +77,7 @@ func benchStatus(sessionCount int, detailed, useGitCommonDirCache bool) func(*te
 				session.ClearGitCommonDirCache()
 			}
 
-			if err := runStatus(io.Discard, detailed); err != nil {
+			if err := runStatus(io.Discard, detailed, false); err != nil {
 				b.Fatalf("runStatus: %v", err)
 			}
 		}
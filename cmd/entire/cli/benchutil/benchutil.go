@@ -575,6 +575,17 @@ func (br *BenchRepo) PackRefs(b *testing.B) {
 	}
 }
 
+// WriteCommitGraph runs `git commit-graph write --reachable`, as `git gc` and
+// `git maintenance` do in real repositories.
+func (br *BenchRepo) WriteCommitGraph(b *testing.B) {
+	b.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", "commit-graph", "write", "--reachable")
+	cmd.Dir = br.Dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git commit-graph write: %v\n%s", err, output)
+	}
+}
+
 // SeedGitObjects creates loose git objects to bloat .git/objects/.
 // Each call creates N blob objects via `git hash-object -w`.
 // After seeding, runs `git gc` to pack them into a packfile (realistic).
@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// bisectLogLine matches the verdict comments git writes to the bisect log,
+// e.g. "# bad: [<hash>] subject" or "# first bad commit: [<hash>] subject".
+var bisectLogLine = regexp.MustCompile(`^# ([a-z][a-z ]*): \[([0-9a-f]+)\] ?(.*)$`)
+
+// bisectEntry is a commit with the verdict it was given during a bisect.
+type bisectEntry struct {
+	Verdict string
+	Hash    string
+	Subject string
+}
+
+// bisectLog is the parsed content of `git bisect log`.
+type bisectLog struct {
+	// Tested lists each commit once, in the order it was first marked, with
+	// its latest verdict.
+	Tested []bisectEntry
+	// Culprit is the first bad commit, if the bisect has finished.
+	Culprit *bisectEntry
+	// Candidates are the possible first bad commits when skipped commits
+	// prevented git from narrowing it down to one.
+	Candidates []bisectEntry
+}
+
+// bisectCheckpoint is the session context recorded for a checkpoint.
+type bisectCheckpoint struct {
+	CheckpointID id.CheckpointID
+	Found        bool
+	SessionIDs   []string
+	Agent        string
+	Intent       string
+	Prompts      []string
+}
+
+func newBisectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bisect",
+		Short: "Add session context to git bisect",
+	}
+
+	cmd.AddCommand(newBisectAnnotateCmd())
+
+	return cmd
+}
+
+func newBisectAnnotateCmd() *cobra.Command {
+	var logFlag string
+
+	cmd := &cobra.Command{
+		Use:   "annotate",
+		Short: "Show which sessions and prompts produced each commit tested by git bisect",
+		Long: `Read the current git bisect log and show, for every commit marked so far,
+the checkpoints and prompts from its Entire-Checkpoint trailers. Once bisect
+has found the first bad commit, a final report lists its sessions, prompts,
+and the command to view the full transcript.
+
+Run it during a bisect, or after ` + "`git bisect run`" + ` finishes. To annotate an
+earlier bisect, save its log first (git bisect log > bisect.log) and pass it
+with --log.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runBisectAnnotate(cmd, logFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&logFlag, "log", "", "Read a saved bisect log instead of the current bisect")
+
+	return cmd
+}
+
+func runBisectAnnotate(cmd *cobra.Command, logFile string) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	var data []byte
+	if logFile != "" {
+		data, err = os.ReadFile(logFile) //nolint:gosec // path is provided by the user
+		if err != nil {
+			return fmt.Errorf("failed to read bisect log: %w", err)
+		}
+	} else {
+		data, err = exec.CommandContext(ctx, "git", "-C", repoRoot, "bisect", "log").Output()
+		if err != nil {
+			cmd.SilenceUsage = true
+			err = errors.New("no bisect in progress (use --log to annotate a saved bisect log)")
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+	}
+
+	log := parseBisectLog(string(data))
+	if len(log.Tested) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No commits have been marked yet.")
+		return nil
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+	lookup := func(hash string) []bisectCheckpoint {
+		return bisectCommitCheckpoints(ctx, repo, store, hash)
+	}
+
+	writeBisectAnnotation(cmd.OutOrStdout(), log, lookup)
+	return nil
+}
+
+// parseBisectLog extracts the marked commits and the outcome from the output
+// of `git bisect log`.
+func parseBisectLog(data string) bisectLog {
+	var log bisectLog
+	index := make(map[string]int)
+	for _, line := range strings.Split(data, "\n") {
+		m := bisectLogLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		entry := bisectEntry{Verdict: m[1], Hash: m[2], Subject: m[3]}
+		switch {
+		case strings.HasPrefix(entry.Verdict, "possible first "):
+			log.Candidates = append(log.Candidates, entry)
+		case strings.HasPrefix(entry.Verdict, "first "):
+			log.Culprit = &entry
+		default:
+			if i, ok := index[entry.Hash]; ok {
+				log.Tested[i].Verdict = entry.Verdict
+				continue
+			}
+			index[entry.Hash] = len(log.Tested)
+			log.Tested = append(log.Tested, entry)
+		}
+	}
+	return log
+}
+
+// bisectCommitCheckpoints returns the session context for each checkpoint
+// referenced by the commit's trailers.
+func bisectCommitCheckpoints(ctx context.Context, repo *git.Repository, store *checkpoint.GitStore, hash string) []bisectCheckpoint {
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil
+	}
+
+	var result []bisectCheckpoint
+	for _, cpID := range trailers.ParseAllCheckpoints(commit.Message) {
+		result = append(result, readBisectCheckpoint(ctx, store, cpID))
+	}
+	return result
+}
+
+// readBisectCheckpoint reads the sessions, agent, intent, and prompts of a
+// committed checkpoint. Found is false if it isn't on the metadata branch.
+func readBisectCheckpoint(ctx context.Context, store *checkpoint.GitStore, cpID id.CheckpointID) bisectCheckpoint {
+	cp := bisectCheckpoint{CheckpointID: cpID}
+	summary, err := store.ReadCommitted(ctx, cpID)
+	if err != nil || summary == nil {
+		return cp
+	}
+	cp.Found = true
+	for i := range summary.Sessions {
+		content, contentErr := store.ReadSessionContent(ctx, cpID, i)
+		if contentErr != nil {
+			continue
+		}
+		cp.SessionIDs = append(cp.SessionIDs, content.Metadata.SessionID)
+		if content.Metadata.Agent != "" {
+			cp.Agent = string(content.Metadata.Agent)
+		}
+		if content.Metadata.Summary != nil && content.Metadata.Summary.Intent != "" {
+			cp.Intent = content.Metadata.Summary.Intent
+		}
+		cp.Prompts = append(cp.Prompts, splitPrompts(content.Prompts)...)
+	}
+	return cp
+}
+
+// splitPrompts splits prompt.txt content into its non-empty prompts.
+func splitPrompts(content string) []string {
+	var prompts []string
+	for _, p := range strings.Split(content, "\n\n---\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			prompts = append(prompts, p)
+		}
+	}
+	return prompts
+}
+
+func writeBisectAnnotation(w io.Writer, log bisectLog, lookup func(hash string) []bisectCheckpoint) {
+	fmt.Fprintf(w, "Bisect: %d commit(s) marked\n\n", len(log.Tested))
+	for _, entry := range log.Tested {
+		fmt.Fprintf(w, "%-5s %s  %s\n", entry.Verdict, strategy.TruncateHash(entry.Hash), entry.Subject)
+		checkpoints := lookup(entry.Hash)
+		if len(checkpoints) == 0 {
+			fmt.Fprintln(w, "      no Entire checkpoint")
+			continue
+		}
+		for _, cp := range checkpoints {
+			fmt.Fprintf(w, "      %s\n", formatBisectCheckpointLine(cp))
+		}
+	}
+
+	fmt.Fprintln(w)
+	switch {
+	case log.Culprit != nil:
+		fmt.Fprintf(w, "First bad commit: %s %s\n", log.Culprit.Hash, log.Culprit.Subject)
+		writeBisectCulprit(w, lookup(log.Culprit.Hash))
+	case len(log.Candidates) > 0:
+		fmt.Fprintln(w, "Skipped commits left more than one possible first bad commit:")
+		for _, entry := range log.Candidates {
+			fmt.Fprintf(w, "\n%s %s\n", entry.Hash, entry.Subject)
+			writeBisectCulprit(w, lookup(entry.Hash))
+		}
+	default:
+		fmt.Fprintln(w, "Bisect has not found the first bad commit yet.")
+	}
+}
+
+// formatBisectCheckpointLine renders a checkpoint as a single line with its
+// agent and first prompt.
+func formatBisectCheckpointLine(cp bisectCheckpoint) string {
+	if !cp.Found {
+		return cp.CheckpointID.String() + "  (not on " + paths.MetadataBranchName() + ")"
+	}
+	line := cp.CheckpointID.String()
+	if cp.Agent != "" {
+		line += "  " + cp.Agent
+	}
+	if len(cp.Prompts) > 0 {
+		prompt := stringutil.TruncateRunes(stringutil.CollapseWhitespace(cp.Prompts[0]), strategy.MaxDescriptionLength, "...")
+		line += fmt.Sprintf("  %q", prompt)
+	}
+	return line
+}
+
+func writeBisectCulprit(w io.Writer, checkpoints []bisectCheckpoint) {
+	if len(checkpoints) == 0 {
+		fmt.Fprintln(w, "  No Entire checkpoint: this commit was not linked to an agent session.")
+		return
+	}
+	for _, cp := range checkpoints {
+		fmt.Fprintf(w, "  Checkpoint %s\n", cp.CheckpointID)
+		if !cp.Found {
+			fmt.Fprintf(w, "    Not found on %s (try fetching it)\n", paths.MetadataBranchName())
+			continue
+		}
+		fmt.Fprintf(w, "    Sessions:   %s\n", strings.Join(cp.SessionIDs, ", "))
+		if cp.Agent != "" {
+			fmt.Fprintf(w, "    Agent:      %s\n", cp.Agent)
+		}
+		if cp.Intent != "" {
+			fmt.Fprintf(w, "    Intent:     %s\n", cp.Intent)
+		}
+		if len(cp.Prompts) > 0 {
+			fmt.Fprintln(w, "    Prompts:")
+			for _, p := range cp.Prompts {
+				fmt.Fprintf(w, "      - %s\n", stringutil.TruncateRunes(stringutil.CollapseWhitespace(p), 120, "..."))
+			}
+		}
+		fmt.Fprintf(w, "    Transcript: entire explain --checkpoint %s --full\n", cp.CheckpointID)
+	}
+}
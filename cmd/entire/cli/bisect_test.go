@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleBisectLog = `# bad: [42472c0c0c779d0eecdb8d7eae54f0c9017ee7ca] c5
+# good: [89aad5f7bcde6dc8ac4531aafd960f460838ad13] c1
+git bisect start 'HEAD' 'HEAD~4'
+# good: [71c7a27ff4741483df859b796275ec8f74607177] c3
+git bisect good 71c7a27ff4741483df859b796275ec8f74607177
+# bad: [83cb02d737342bfd5bdc7adf0c9b93c3701badbe] c4
+git bisect bad 83cb02d737342bfd5bdc7adf0c9b93c3701badbe
+# first bad commit: [83cb02d737342bfd5bdc7adf0c9b93c3701badbe] c4
+`
+
+func TestParseBisectLog(t *testing.T) {
+	t.Parallel()
+
+	log := parseBisectLog(sampleBisectLog)
+	assert.Equal(t, []bisectEntry{
+		{Verdict: "bad", Hash: "42472c0c0c779d0eecdb8d7eae54f0c9017ee7ca", Subject: "c5"},
+		{Verdict: "good", Hash: "89aad5f7bcde6dc8ac4531aafd960f460838ad13", Subject: "c1"},
+		{Verdict: "good", Hash: "71c7a27ff4741483df859b796275ec8f74607177", Subject: "c3"},
+		{Verdict: "bad", Hash: "83cb02d737342bfd5bdc7adf0c9b93c3701badbe", Subject: "c4"},
+	}, log.Tested)
+	require.NotNil(t, log.Culprit)
+	assert.Equal(t, "83cb02d737342bfd5bdc7adf0c9b93c3701badbe", log.Culprit.Hash)
+	assert.Empty(t, log.Candidates)
+}
+
+func TestParseBisectLog_RemarkedAndSkipped(t *testing.T) {
+	t.Parallel()
+
+	log := parseBisectLog(`# good: [1111111111111111111111111111111111111111] a
+# skip: [2222222222222222222222222222222222222222] b
+# good: [2222222222222222222222222222222222222222] b
+# only skipped commits left to test
+# possible first bad commit: [3333333333333333333333333333333333333333] c
+# possible first bad commit: [4444444444444444444444444444444444444444] d
+`)
+	require.Len(t, log.Tested, 2)
+	assert.Equal(t, "good", log.Tested[1].Verdict, "latest verdict wins")
+	assert.Nil(t, log.Culprit)
+	require.Len(t, log.Candidates, 2)
+	assert.Equal(t, "d", log.Candidates[1].Subject)
+}
+
+func TestWriteBisectAnnotation(t *testing.T) {
+	t.Parallel()
+
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+	lookup := func(hash string) []bisectCheckpoint {
+		if hash != "83cb02d737342bfd5bdc7adf0c9b93c3701badbe" {
+			return nil
+		}
+		return []bisectCheckpoint{{
+			CheckpointID: cpID,
+			Found:        true,
+			SessionIDs:   []string{"2026-04-01-alpha"},
+			Agent:        "Claude Code",
+			Intent:       "Speed up the parser",
+			Prompts:      []string{"Make the parser faster", "Remove the cache"},
+		}}
+	}
+
+	var out bytes.Buffer
+	writeBisectAnnotation(&out, parseBisectLog(sampleBisectLog), lookup)
+	output := out.String()
+
+	assert.Contains(t, output, "Bisect: 4 commit(s) marked")
+	assert.Contains(t, output, "good  71c7a27  c3\n      no Entire checkpoint")
+	assert.Contains(t, output, "bad   83cb02d  c4\n      a1b2c3d4e5f6  Claude Code  \"Make the parser faster\"")
+	assert.Contains(t, output, "First bad commit: 83cb02d737342bfd5bdc7adf0c9b93c3701badbe c4")
+	assert.Contains(t, output, "Intent:     Speed up the parser")
+	assert.Contains(t, output, "      - Remove the cache")
+	assert.Contains(t, output, "Transcript: entire explain --checkpoint a1b2c3d4e5f6 --full")
+}
+
+func TestBisectAnnotate_CurrentBisect(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "f.txt", "1\n")
+	testutil.GitAdd(t, tmpDir, "f.txt")
+	testutil.GitCommit(t, tmpDir, "Good commit")
+	good := testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "f.txt", "2\n")
+	testutil.GitAdd(t, tmpDir, "f.txt")
+	testutil.GitCommit(t, tmpDir, "Break it\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	bad := testutil.GetHeadHash(t, tmpDir)
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-04-01-alpha",
+		Strategy:     "manual-commit",
+		Prompts:      []string{"Change f.txt"},
+		FilesTouched: []string{"f.txt"},
+	}))
+
+	cmd := newBisectCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"annotate"})
+	require.Error(t, cmd.Execute(), "expected an error without a bisect in progress")
+
+	bisect := exec.CommandContext(context.Background(), "git", "bisect", "start", bad, good)
+	bisect.Dir = tmpDir
+	output, err := bisect.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	out.Reset()
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "First bad commit: "+bad+" Break it")
+	assert.Contains(t, out.String(), "Sessions:   2026-04-01-alpha")
+	assert.Contains(t, out.String(), "      - Change f.txt")
+}
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// uncommittedBlameHash is the hash git blame reports for lines that are not
+// committed yet.
+const uncommittedBlameHash = "0000000000000000000000000000000000000000"
+
+// blameCommit is a commit as described by `git blame --porcelain`.
+type blameCommit struct {
+	Author  string
+	Time    time.Time
+	Summary string
+}
+
+// blameLine is one line of the blamed file.
+type blameLine struct {
+	Commit  string
+	Number  int
+	Content string
+}
+
+// blameHunk is a run of consecutive lines last changed by the same commit.
+type blameHunk struct {
+	Commit      string
+	Lines       []blameLine
+	Checkpoints []bisectCheckpoint
+}
+
+func newBlameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "blame <path>",
+		Short: "Show which session and prompt produced each part of a file",
+		Long: `Run git blame over a file and group its lines into hunks last changed by
+the same commit. Each hunk shows the commit and, when the commit is linked to
+a checkpoint by an Entire-Checkpoint trailer or 'entire stamp', the
+checkpoint's agent and the prompt behind it.
+
+Use 'entire explain <commit>' for the full context of a hunk's commit.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBlame(cmd, args[0])
+		},
+	}
+	return cmd
+}
+
+func runBlame(cmd *cobra.Command, pathArg string) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	absPath, err := filepath.Abs(pathArg)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	relPath := paths.RepoRelative(repoRoot, absPath)
+	if relPath == "" || relPath == "." {
+		cmd.SilenceUsage = true
+		err = fmt.Errorf("%s is not a file in this repository", pathArg)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	blameCmd := exec.CommandContext(ctx, "git", "blame", "--porcelain", "--", relPath)
+	blameCmd.Dir = repoRoot
+	output, err := blameCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			cmd.SilenceUsage = true
+			err = fmt.Errorf("git blame failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+		return fmt.Errorf("git blame failed: %w", err)
+	}
+	lines, commits := parseBlamePorcelain(string(output))
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	hunks := groupBlameHunks(lines)
+	lookup := newBlameCheckpointLookup(ctx, repo)
+	for i := range hunks {
+		hunks[i].Checkpoints = lookup(hunks[i].Commit)
+	}
+
+	writeBlame(cmd.OutOrStdout(), relPath, hunks, commits)
+	return nil
+}
+
+// parseBlamePorcelain parses `git blame --porcelain` output into the file's
+// lines and the commits they were last changed by, keyed by hash.
+func parseBlamePorcelain(output string) ([]blameLine, map[string]blameCommit) {
+	var lines []blameLine
+	commits := make(map[string]blameCommit)
+
+	var current blameLine
+	for _, line := range strings.Split(output, "\n") {
+		if content, ok := strings.CutPrefix(line, "\t"); ok {
+			current.Content = content
+			lines = append(lines, current)
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			if n, err := strconv.Atoi(fields[2]); err == nil {
+				current = blameLine{Commit: fields[0], Number: n}
+				continue
+			}
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		info := commits[current.Commit]
+		switch key {
+		case "author":
+			info.Author = value
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.Time = time.Unix(secs, 0)
+			}
+		case "summary":
+			info.Summary = value
+		default:
+			continue
+		}
+		commits[current.Commit] = info
+	}
+	return lines, commits
+}
+
+// groupBlameHunks groups consecutive lines last changed by the same commit.
+func groupBlameHunks(lines []blameLine) []blameHunk {
+	var hunks []blameHunk
+	for _, line := range lines {
+		if n := len(hunks); n > 0 && hunks[n-1].Commit == line.Commit {
+			hunks[n-1].Lines = append(hunks[n-1].Lines, line)
+			continue
+		}
+		hunks = append(hunks, blameHunk{Commit: line.Commit, Lines: []blameLine{line}})
+	}
+	return hunks
+}
+
+// newBlameCheckpointLookup returns a function that resolves a commit hash to
+// the checkpoints linked to it by trailers or `entire stamp`. Commits and
+// checkpoints are read once however many hunks they cover.
+func newBlameCheckpointLookup(ctx context.Context, repo *git.Repository) func(hash string) []bisectCheckpoint {
+	store := checkpoint.NewGitStore(repo)
+	stamps := stampIndex{}
+	if committed, err := store.ListCommitted(ctx); err == nil {
+		stamps = newStampIndex(committed)
+	}
+
+	byCommit := make(map[string][]bisectCheckpoint)
+	byID := make(map[id.CheckpointID]bisectCheckpoint)
+	return func(hash string) []bisectCheckpoint {
+		if hash == uncommittedBlameHash {
+			return nil
+		}
+		if cps, ok := byCommit[hash]; ok {
+			return cps
+		}
+		var cps []bisectCheckpoint
+		if commit, err := repo.CommitObject(plumbing.NewHash(hash)); err == nil {
+			for _, cpID := range stamps.checkpointsFor(commit) {
+				cp, ok := byID[cpID]
+				if !ok {
+					cp = readBisectCheckpoint(ctx, store, cpID)
+					byID[cpID] = cp
+				}
+				cps = append(cps, cp)
+			}
+		}
+		byCommit[hash] = cps
+		return cps
+	}
+}
+
+func writeBlame(w io.Writer, relPath string, hunks []blameHunk, commits map[string]blameCommit) {
+	if len(hunks) == 0 {
+		fmt.Fprintf(w, "%s is empty.\n", relPath)
+		return
+	}
+
+	width := len(strconv.Itoa(hunks[len(hunks)-1].Lines[len(hunks[len(hunks)-1].Lines)-1].Number))
+	for i, hunk := range hunks {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if hunk.Commit == uncommittedBlameHash {
+			fmt.Fprintf(w, "%s  Not committed yet\n", strategy.TruncateHash(hunk.Commit))
+		} else {
+			info := commits[hunk.Commit]
+			fmt.Fprintf(w, "%s  %s  %s  %s\n",
+				strategy.TruncateHash(hunk.Commit), info.Time.Local().Format("2006-01-02"), sanitizeForTerminal(info.Author), sanitizeForTerminal(info.Summary))
+		}
+		for _, cp := range hunk.Checkpoints {
+			fmt.Fprintf(w, "         %s\n", formatBisectCheckpointLine(cp))
+		}
+		for _, line := range hunk.Lines {
+			fmt.Fprintf(w, "  %*d  %s\n", width, line.Number, sanitizeForTerminal(line.Content))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runBlameForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newBlameCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestBlame_AnnotatesCheckpoints(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	_, queued, unrelated := setupStampRepo(t)
+	_, err := runStampForTest(t, "--commit", queued, "--checkpoint", "a1b2c3d4e5f6")
+	require.NoError(t, err)
+
+	output, err := runBlameForTest(t, "a.go")
+	require.NoError(t, err)
+	assert.Contains(t, output, strategy.TruncateHash(queued))
+	assert.Contains(t, output, "Add a (#12)")
+	assert.Contains(t, output, "a1b2c3d4e5f6")
+	assert.Contains(t, output, "1  package a")
+
+	output, err = runBlameForTest(t, "b.go")
+	require.NoError(t, err)
+	assert.Contains(t, output, strategy.TruncateHash(unrelated))
+	assert.NotContains(t, output, "a1b2c3d4e5f6")
+}
+
+func TestBlame_UncommittedLines(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupStampRepo(t)
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	testutil.WriteFile(t, cwd, "b.go", "package b\n\nfunc B() {}\n")
+
+	output, err := runBlameForTest(t, filepath.Join(cwd, "b.go"))
+	require.NoError(t, err)
+	assert.Contains(t, output, "Not committed yet")
+	assert.Contains(t, output, "3  func B() {}")
+}
+
+func TestBlame_FileNotInRepository(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupStampRepo(t)
+
+	_, err := runBlameForTest(t, "missing.go")
+	require.Error(t, err)
+}
@@ -0,0 +1,348 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// defaultMaxMetadataBytes is the largest checkpoint `entire check` accepts
+// unless configured otherwise. Matches the size at which GitHub starts
+// warning about large files.
+const defaultMaxMetadataBytes = 50 * 1024 * 1024
+
+// checkPolicy is the set of rules `entire check` enforces on each commit.
+type checkPolicy struct {
+	RequireCheckpoint bool
+	RequiredTrailers  []string
+	MaxMetadataBytes  int64
+}
+
+// commitCheck is the outcome of checking one commit.
+type commitCheck struct {
+	Hash     string
+	Subject  string
+	Problems []string
+	// Findings are compliance scan findings recorded on the commit's
+	// checkpoints. They are reported but never fail the check.
+	Findings []compliance.Finding
+	// Tickets are the commit's Entire-Ticket references.
+	Tickets []string
+}
+
+func newCheckCmd() *cobra.Command {
+	var rangeFlag string
+	var requireCheckpoint bool
+	var requiredTrailers []string
+	var maxMetadataBytes int64
+	var annotateFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Validate Entire provenance for every commit in a range",
+		Long: `Check every commit in a range before it is merged, for use in CI on pull
+requests. For each commit, it verifies that:
+
+  - Entire-Checkpoint and Entire-Session trailers are well-formed
+  - Referenced checkpoints exist on ` + paths.MetadataBranchName() + ` (local or origin)
+  - Referenced checkpoints are not larger than the metadata size limit
+  - Required trailers are present (non-merge commits only)
+
+Ticket references (Entire-Ticket trailers) and compliance scan findings
+recorded on the checkpoints (see compliance_scan in settings) are listed
+under each commit. With --annotate they are also
+printed as GitHub Actions annotations, so they show up on the pull request.
+Findings never fail the check.
+
+Rules come from the "check" section of .entire/settings.json and can be
+tightened with flags. The command exits non-zero with a report of every
+problem found.
+
+In CI, fetch the metadata branch first:
+
+  git fetch origin ` + paths.MetadataBranchName() + `:` + paths.MetadataBranchName() + `
+  entire check --range origin/main..HEAD`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			policy := loadCheckPolicy()
+			if requireCheckpoint {
+				policy.RequireCheckpoint = true
+			}
+			policy.RequiredTrailers = append(policy.RequiredTrailers, requiredTrailers...)
+			if maxMetadataBytes > 0 {
+				policy.MaxMetadataBytes = maxMetadataBytes
+			}
+			return runCheck(cmd, rangeFlag, policy, annotateFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&rangeFlag, "range", "", "Commit range to check, e.g. origin/main..HEAD (default: <default branch>..HEAD)")
+	cmd.Flags().BoolVar(&requireCheckpoint, "require-checkpoint", false, "Fail commits without an Entire-Checkpoint trailer")
+	cmd.Flags().StringArrayVar(&requiredTrailers, "require-trailer", nil, "Trailer key every commit must have (repeatable)")
+	cmd.Flags().Int64Var(&maxMetadataBytes, "max-metadata-bytes", 0, "Largest allowed checkpoint metadata size in bytes (default 50 MiB)")
+	cmd.Flags().BoolVar(&annotateFlag, "annotate", false, "Print compliance findings as GitHub Actions annotations")
+
+	return cmd
+}
+
+// loadCheckPolicy returns the policy from settings, with defaults applied.
+func loadCheckPolicy() checkPolicy {
+	policy := checkPolicy{MaxMetadataBytes: defaultMaxMetadataBytes}
+	s, err := LoadEntireSettings()
+	if err != nil || s.Check == nil {
+		return policy
+	}
+	policy.RequireCheckpoint = s.Check.RequireCheckpoint
+	policy.RequiredTrailers = append(policy.RequiredTrailers, s.Check.RequiredTrailers...)
+	if s.Check.MaxMetadataBytes > 0 {
+		policy.MaxMetadataBytes = s.Check.MaxMetadataBytes
+	}
+	return policy
+}
+
+func runCheck(cmd *cobra.Command, commitRange string, policy checkPolicy, annotate bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	if commitRange == "" {
+		if commitRange, err = defaultCommitRange(repo); err != nil {
+			cmd.SilenceUsage = true
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+	}
+
+	hashes, err := listRangeCommits(ctx, repoRoot, commitRange)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	var stamps stampIndex
+	if policy.RequireCheckpoint {
+		committed, listErr := store.ListCommitted(ctx)
+		if listErr != nil {
+			return fmt.Errorf("failed to list checkpoints: %w", listErr)
+		}
+		stamps = newStampIndex(committed)
+	}
+
+	results := make([]commitCheck, 0, len(hashes))
+	for _, hash := range hashes {
+		commit, commitErr := repo.CommitObject(plumbing.NewHash(hash))
+		if commitErr != nil {
+			return fmt.Errorf("failed to read commit %s: %w", hash, commitErr)
+		}
+		results = append(results, checkCommit(ctx, store, commit, stamps, policy))
+	}
+
+	if annotate {
+		writeCheckAnnotations(w, results)
+	}
+	if failed := writeCheckReport(w, commitRange, results); failed > 0 {
+		cmd.SilenceUsage = true
+		return NewSilentError(fmt.Errorf("%d commit(s) failed checks", failed))
+	}
+	return nil
+}
+
+// defaultCommitRange returns "<default branch>..HEAD", preferring the
+// branch on origin when it exists.
+func defaultCommitRange(repo *git.Repository) (string, error) {
+	branch := strategy.GetDefaultBranchName(repo)
+	if branch == "" {
+		return "", errors.New("could not determine the default branch; pass --range")
+	}
+	base := "origin/" + branch
+	if _, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true); err != nil {
+		base = branch
+	}
+	return base + "..HEAD", nil
+}
+
+// listRangeCommits returns the commits in a git revision range, oldest first.
+func listRangeCommits(ctx context.Context, repoRoot, commitRange string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "rev-list", "--reverse", commitRange, "--").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("invalid range %q: %s", commitRange, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("invalid range %q: %w", commitRange, err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// checkCommit applies the policy to a single commit. A commit linked to a
+// checkpoint by `entire stamp` satisfies RequireCheckpoint without a trailer.
+func checkCommit(ctx context.Context, store *checkpoint.GitStore, commit *object.Commit, stamps stampIndex, policy checkPolicy) commitCheck {
+	result := commitCheck{
+		Hash:    commit.Hash.String(),
+		Subject: strings.SplitN(commit.Message, "\n", 2)[0],
+		Tickets: trailers.ParseAllTickets(commit.Message),
+	}
+	isMerge := commit.NumParents() > 1
+
+	if _, _, err := trailers.ParseCheckpointID(commit.Message); err != nil {
+		result.Problems = append(result.Problems, err.Error())
+	}
+	for _, sessionID := range trailers.ParseAllSessions(commit.Message) {
+		if err := validation.ValidateSessionID(sessionID); err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("malformed %s trailer: %v", trailers.SessionTrailerKey, err))
+		}
+	}
+
+	checkpointIDs := trailers.ParseAllCheckpoints(commit.Message)
+	if len(checkpointIDs) == 0 && len(stamps[result.Hash]) == 0 && policy.RequireCheckpoint && !isMerge {
+		result.Problems = append(result.Problems, "missing "+trailers.CheckpointTrailerKey()+" trailer")
+	}
+	for _, cpID := range checkpointIDs {
+		size, found, err := store.CommittedSize(ctx, cpID)
+		switch {
+		case err != nil:
+			result.Problems = append(result.Problems, fmt.Sprintf("checkpoint %s could not be read: %v", cpID, err))
+		case !found:
+			result.Problems = append(result.Problems, fmt.Sprintf("checkpoint %s not found on %s (push it, or fetch it before checking)", cpID, paths.MetadataBranchName()))
+		case policy.MaxMetadataBytes > 0 && size > policy.MaxMetadataBytes:
+			result.Problems = append(result.Problems, fmt.Sprintf("checkpoint %s metadata is %s, over the %s limit", cpID, formatBytes(size), formatBytes(policy.MaxMetadataBytes)))
+		}
+		if found {
+			result.Findings = appendComplianceFindings(ctx, store, cpID, result.Findings)
+		}
+	}
+
+	if !isMerge {
+		for _, key := range policy.RequiredTrailers {
+			if key = strings.TrimSpace(key); key != "" && !hasTrailer(commit.Message, key) {
+				result.Problems = append(result.Problems, "missing required "+key+" trailer")
+			}
+		}
+	}
+	return result
+}
+
+// appendComplianceFindings adds the compliance findings recorded on a
+// checkpoint's sessions to findings, skipping duplicates.
+func appendComplianceFindings(ctx context.Context, store *checkpoint.GitStore, cpID id.CheckpointID, findings []compliance.Finding) []compliance.Finding {
+	metadata, err := store.ReadCommittedMetadata(ctx, cpID)
+	if err != nil {
+		return findings
+	}
+	for _, m := range metadata {
+		if m.ComplianceScan == nil {
+			continue
+		}
+		for _, f := range m.ComplianceScan.Findings {
+			if !slices.Contains(findings, f) {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// hasTrailer reports whether the message has a "Key: value" trailer line.
+// Trailer keys are matched case-insensitively, as git does.
+func hasTrailer(message, key string) bool {
+	re := regexp.MustCompile(`(?mi)^` + regexp.QuoteMeta(key) + `:[ \t]*\S`)
+	return re.MatchString(message)
+}
+
+// writeCheckReport prints every commit with its problems and returns the
+// number of commits that failed.
+func writeCheckReport(w io.Writer, commitRange string, results []commitCheck) int {
+	if len(results) == 0 {
+		fmt.Fprintf(w, "No commits in %s.\n", commitRange)
+		return 0
+	}
+
+	failed := 0
+	fmt.Fprintf(w, "Checking %d commit(s) in %s\n\n", len(results), commitRange)
+	for _, r := range results {
+		mark := "✓"
+		if len(r.Problems) > 0 {
+			mark = "✕"
+			failed++
+		}
+		fmt.Fprintf(w, "%s %s  %s\n", mark, strategy.TruncateHash(r.Hash), r.Subject)
+		if len(r.Tickets) > 0 {
+			fmt.Fprintf(w, "    tickets: %s\n", strings.Join(r.Tickets, ", "))
+		}
+		for _, problem := range r.Problems {
+			fmt.Fprintf(w, "    %s\n", problem)
+		}
+		for _, finding := range r.Findings {
+			fmt.Fprintf(w, "    compliance: %s\n", finding)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if failed > 0 {
+		fmt.Fprintf(w, "%d of %d commit(s) failed checks.\n", failed, len(results))
+	} else {
+		fmt.Fprintf(w, "All %d commit(s) passed.\n", len(results))
+	}
+	return failed
+}
+
+// writeCheckAnnotations prints each compliance finding as a GitHub Actions
+// warning annotation, attached to the finding's file when it has one.
+func writeCheckAnnotations(w io.Writer, results []commitCheck) {
+	for _, r := range results {
+		for _, f := range r.Findings {
+			var props []string
+			if f.File != "" {
+				props = append(props, "file="+escapeAnnotationProperty(f.File))
+				if f.Line > 0 {
+					props = append(props, fmt.Sprintf("line=%d", f.Line))
+				}
+			}
+			props = append(props, "title="+escapeAnnotationProperty("Compliance: "+f.Kind))
+			message := fmt.Sprintf("%s (commit %s)", f, strategy.TruncateHash(r.Hash))
+			fmt.Fprintf(w, "::warning %s::%s\n", strings.Join(props, ","), escapeAnnotationData(message))
+		}
+	}
+}
+
+// escapeAnnotationData escapes a workflow command message.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasTrailer(t *testing.T) {
+	t.Parallel()
+
+	msg := "Fix bug\n\nSigned-off-by: A <a@example.com>\nReviewed-by:\n"
+	assert.True(t, hasTrailer(msg, "Signed-off-by"))
+	assert.True(t, hasTrailer(msg, "signed-off-by"), "keys match case-insensitively")
+	assert.False(t, hasTrailer(msg, "Reviewed-by"), "empty trailers don't count")
+	assert.False(t, hasTrailer(msg, "Acked-by"))
+}
+
+func TestCheck_Range(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "f.txt", "0\n")
+	testutil.GitAdd(t, tmpDir, "f.txt")
+	testutil.GitCommit(t, tmpDir, "Base")
+	base := testutil.GetHeadHash(t, tmpDir)
+
+	commits := []string{
+		"Good\n\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Ticket: ABC-123\nSigned-off-by: A <a@example.com>\n",
+		"Missing checkpoint\n\nEntire-Checkpoint: 0a0b0c0d0e0f\nSigned-off-by: A <a@example.com>\n",
+		"Malformed\n\nEntire-Checkpoint: not-an-id\n",
+	}
+	for i, msg := range commits {
+		testutil.WriteFile(t, tmpDir, "f.txt", strings.Repeat("x", i+1)+"\n")
+		testutil.GitAdd(t, tmpDir, "f.txt")
+		testutil.GitCommit(t, tmpDir, msg)
+	}
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-04-01-alpha",
+		Strategy:     "manual-commit",
+		Transcript:   []byte(`{"type":"user","message":{"content":"hi"}}` + "\n"),
+		Prompts:      []string{"Change f.txt"},
+		FilesTouched: []string{"f.txt"},
+		ComplianceScan: &compliance.Report{
+			Command:  "license-scan",
+			Findings: []compliance.Finding{{Kind: compliance.KindLicenseHeader, File: "f.txt", Line: 1, Message: "missing license header"}},
+		},
+	}))
+
+	run := func(args ...string) (string, error) {
+		cmd := newCheckCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--range", base+"..HEAD", "--require-trailer", "Signed-off-by")
+	require.Error(t, err)
+	assert.Contains(t, out, "Checking 3 commit(s)")
+	assert.Contains(t, out, "✓ ")
+	assert.Contains(t, out, "checkpoint 0a0b0c0d0e0f not found on "+paths.MetadataBranchName())
+	assert.Contains(t, out, "malformed Entire-Checkpoint trailer")
+	assert.Contains(t, out, "missing required Signed-off-by trailer")
+	assert.Contains(t, out, "2 of 3 commit(s) failed checks.")
+
+	out, err = run("--range", base+"..HEAD~2", "--max-metadata-bytes", "10")
+	require.Error(t, err)
+	assert.Contains(t, out, "over the 10 B limit")
+
+	out, err = run("--range", base+"..HEAD~2", "--require-checkpoint")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "All 1 commit(s) passed.", "compliance findings don't fail the check")
+	assert.Contains(t, out, "    compliance: license_header in f.txt:1: missing license header\n")
+	assert.Contains(t, out, "    tickets: ABC-123\n")
+	assert.NotContains(t, out, "::warning")
+
+	out, err = run("--range", base+"..HEAD~2", "--annotate")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "::warning file=f.txt,line=1,title=Compliance%3A license_header::license_header in f.txt:1: missing license header (commit ")
+
+	_, err = run("--range", "nope..HEAD")
+	require.Error(t, err)
+}
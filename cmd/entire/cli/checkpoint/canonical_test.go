@@ -0,0 +1,145 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/")
+
+// goldenCheckpointOptions returns a committed checkpoint that sets most
+// metadata fields, with inputs in the unsorted order callers may pass them.
+func goldenCheckpointOptions() WriteCommittedOptions {
+	return WriteCommittedOptions{
+		CheckpointID:              id.MustCheckpointID("c0ffee123456"),
+		SessionID:                 "2026-01-02-golden",
+		Strategy:                  "manual-commit",
+		Branch:                    "feature/golden",
+		Transcript:                []byte(`{"type":"user","message":{"content":"Add <b>bold</b> & more"}}` + "\n"),
+		Prompts:                   []string{"Add <b>bold</b> & more"},
+		Context:                   []byte("# Context\n"),
+		FilesTouched:              []string{"z.go", "a.go", "m/b.go"},
+		CheckpointsCount:          2,
+		AuthorName:                "Test Author",
+		AuthorEmail:               "test@example.com",
+		Agent:                     agent.AgentTypeClaudeCode,
+		TurnID:                    "turn-1",
+		CheckpointTranscriptStart: 3,
+		TokenUsage: &agent.TokenUsage{
+			InputTokens:  1200,
+			OutputTokens: 340,
+			APICallCount: 2,
+		},
+		InitialAttribution: &InitialAttribution{
+			CalculatedAt:    time.Date(2026, 1, 2, 4, 4, 5, 500, time.FixedZone("CET", 3600)),
+			AgentLines:      2,
+			HumanAdded:      1,
+			TotalCommitted:  3,
+			AgentPercentage: 200.0 / 3,
+		},
+		Summary: &Summary{
+			Intent:  "Make text bold",
+			Outcome: "Done",
+		},
+		TornSnapshotFiles: []string{"z.go", "a.go"},
+		OmittedFiles:      []OmittedFile{{Path: "shot.png", Hash: "0123456789abcdef0123456789abcdef01234567", Size: 52311, Reason: OmittedBinary}},
+		Tickets:           []string{"ENG-2", "ENG-1"},
+	}
+}
+
+// writeGoldenCheckpoint writes the golden checkpoint into a fresh repository
+// and returns the store and the metadata branch tree hash.
+func writeGoldenCheckpoint(t *testing.T) (*GitStore, plumbing.Hash) {
+	t.Helper()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetClock(clock.NewFake(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	if err := store.WriteCommitted(context.Background(), goldenCheckpointOptions()); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	if err != nil {
+		t.Fatalf("failed to get metadata branch reference: %v", err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("failed to get commit object: %v", err)
+	}
+	return store, commit.TreeHash
+}
+
+// TestWriteCommitted_GoldenMetadata verifies that checkpoint metadata files
+// serialize byte for byte as recorded in testdata/. Run with -update after an
+// intentional format change.
+func TestWriteCommitted_GoldenMetadata(t *testing.T) {
+	t.Parallel()
+
+	store, _ := writeGoldenCheckpoint(t)
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("failed to read metadata branch tree: %v", err)
+	}
+	cpPath := goldenCheckpointOptions().CheckpointID.Path()
+
+	for _, tc := range []struct {
+		path   string
+		golden string
+	}{
+		{cpPath + "/" + paths.MetadataFileName, "checkpoint_summary.golden.json"},
+		{cpPath + "/0/" + paths.MetadataFileName, "session_metadata.golden.json"},
+	} {
+		file, err := tree.File(tc.path)
+		if err != nil {
+			t.Fatalf("failed to find %s: %v", tc.path, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", tc.path, err)
+		}
+		// The CLI version depends on build flags, so it is masked
+		got := bytes.ReplaceAll([]byte(content), []byte(strconv.Quote(buildinfo.Version)), []byte(`"<cli-version>"`))
+
+		goldenPath := filepath.Join("testdata", tc.golden)
+		if *updateGolden {
+			if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+				t.Fatalf("failed to update %s: %v", goldenPath, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", goldenPath, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s does not match %s (run with -update if the change is intended)\ngot:\n%s\nwant:\n%s", tc.path, goldenPath, got, want)
+		}
+	}
+}
+
+// TestWriteCommitted_StableTree verifies that writing the same checkpoint in
+// two repositories produces the same metadata tree.
+func TestWriteCommitted_StableTree(t *testing.T) {
+	t.Parallel()
+
+	_, first := writeGoldenCheckpoint(t)
+	_, second := writeGoldenCheckpoint(t)
+	if first != second {
+		t.Errorf("metadata tree hashes differ: %s vs %s", first, second)
+	}
+}
@@ -9,10 +9,14 @@ package checkpoint
 import (
 	"context"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/qualitygate"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
 
 	"github.com/go-git/go-git/v5/plumbing"
 )
@@ -117,6 +121,68 @@ type WriteTemporaryResult struct {
 	// Skipped is true if the checkpoint was skipped due to no changes
 	// (tree hash matched the previous checkpoint)
 	Skipped bool
+
+	// Snapshots is the verification status of each file written from disk,
+	// keyed by repo-relative path
+	Snapshots map[string]SnapshotStatus
+
+	// Omitted are the files whose content was not stored because of the
+	// MaxFileBytes and SkipBinaryFiles limits
+	Omitted []OmittedFile
+}
+
+// OmittedFile is a file a shadow checkpoint recorded by path, blob hash, and
+// size only, leaving its content out of the checkpoint tree.
+type OmittedFile struct {
+	// Path is the repo-relative path
+	Path string `json:"path"`
+
+	// Hash is the git blob hash of the content that was left out
+	Hash string `json:"hash"`
+
+	// Size is the file size in bytes
+	Size int64 `json:"size"`
+
+	// Reason is OmittedTooLarge or OmittedBinary
+	Reason string `json:"reason"`
+}
+
+const (
+	// OmittedTooLarge means the file was over the MaxFileBytes limit.
+	OmittedTooLarge = "too_large"
+
+	// OmittedBinary means the file looked binary and SkipBinaryFiles was set.
+	OmittedBinary = "binary"
+)
+
+// SnapshotStatus is the result of checking a file's snapshot in a shadow
+// checkpoint against the file on disk after the blob was written.
+type SnapshotStatus string
+
+const (
+	// SnapshotVerified means the stored blob matched the file on disk.
+	SnapshotVerified SnapshotStatus = "verified"
+
+	// SnapshotRetried means the file changed while it was being stored and
+	// the second attempt matched.
+	SnapshotRetried SnapshotStatus = "retried"
+
+	// SnapshotTorn means the file still changed during the retry, so the
+	// stored blob may not match any version the agent finished writing.
+	SnapshotTorn SnapshotStatus = "torn"
+)
+
+// TornSnapshots returns the paths whose snapshot could not be verified,
+// sorted.
+func (r WriteTemporaryResult) TornSnapshots() []string {
+	var torn []string
+	for file, status := range r.Snapshots {
+		if status == SnapshotTorn {
+			torn = append(torn, file)
+		}
+	}
+	sort.Strings(torn)
+	return torn
 }
 
 // WriteTemporaryOptions contains options for writing a temporary checkpoint.
@@ -158,6 +224,17 @@ type WriteTemporaryOptions struct {
 	// IsFirstCheckpoint indicates if this is the first checkpoint of the session
 	// When true, all working directory files are captured (not just modified)
 	IsFirstCheckpoint bool
+
+	// WorktreeRoot is the directory the file paths are relative to (e.g., a
+	// submodule's checkout). Empty means the current worktree's root.
+	WorktreeRoot string
+
+	// MaxFileBytes is the largest file whose content is stored; larger ones
+	// are recorded in Omitted instead. 0 = no limit.
+	MaxFileBytes int64
+
+	// SkipBinaryFiles records binary files in Omitted instead of storing them
+	SkipBinaryFiles bool
 }
 
 // ReadTemporaryResult contains the result of reading a temporary checkpoint.
@@ -285,6 +362,54 @@ type WriteCommittedOptions struct {
 	//   - the transcript was empty or too short to summarize
 	//   - the checkpoint predates the summarization feature
 	Summary *Summary
+
+	// Review is the output of the user's configured review command for this
+	// session's diff (review.md). Empty when no review command is configured
+	// or the command failed.
+	Review []byte
+
+	// QualityGate is the session's last quality gate result. nil when no
+	// gate is configured or it has not run yet.
+	QualityGate *qualitygate.Result
+
+	// ComplianceScan is the session's last compliance scan report. nil when
+	// no scan is configured or it has not run yet.
+	ComplianceScan *compliance.Report
+
+	// TranscriptFilter records what transcript filtering removed from
+	// Transcript. nil when the transcript is stored as recorded.
+	TranscriptFilter *transcript.FilterStats
+
+	// CompressTranscript stores the transcript chunks gzip-compressed.
+	// Readers decompress them transparently.
+	CompressTranscript bool
+
+	// ContentDefinedChunks splits JSONL transcripts over agent.CDCMinChunkSize
+	// at content-defined line boundaries instead of only past
+	// agent.MaxChunkSize. Readers reassemble either layout.
+	ContentDefinedChunks bool
+
+	// TornSnapshotFiles are files whose shadow snapshot did not match the
+	// file on disk in one of the session's steps
+	TornSnapshotFiles []string
+
+	// OmittedFiles are files the session's steps recorded by hash only,
+	// because of the snapshot limits
+	OmittedFiles []OmittedFile
+
+	// Tickets are the issue or ticket IDs the session is linked to
+	Tickets []string
+
+	// ParentSessionID is the session this session resumes, if any
+	ParentSessionID string
+
+	// Imported describes where an imported checkpoint's attribution came
+	// from. nil for checkpoints recorded by Entire's hooks.
+	Imported *ImportInfo
+
+	// CreatedAt overrides the checkpoint's creation time (e.g. the commit
+	// time of an imported commit). Zero = now.
+	CreatedAt time.Time
 }
 
 // UpdateCommittedOptions contains options for updating an existing committed checkpoint.
@@ -309,6 +434,19 @@ type UpdateCommittedOptions struct {
 
 	// Agent identifies the agent type (needed for transcript chunking)
 	Agent agent.AgentType
+
+	// TranscriptFilter records what transcript filtering removed from
+	// Transcript. nil when the transcript is stored as recorded.
+	TranscriptFilter *transcript.FilterStats
+
+	// CompressTranscript stores the transcript chunks gzip-compressed.
+	// Readers decompress them transparently.
+	CompressTranscript bool
+
+	// ContentDefinedChunks splits JSONL transcripts over agent.CDCMinChunkSize
+	// at content-defined line boundaries instead of only past
+	// agent.MaxChunkSize. Readers reassemble either layout.
+	ContentDefinedChunks bool
 }
 
 // CommittedInfo contains summary information about a committed checkpoint.
@@ -340,6 +478,12 @@ type CommittedInfo struct {
 	// Multi-session support
 	SessionCount int      // Number of sessions (1 if single session)
 	SessionIDs   []string // All session IDs that contributed
+
+	// StampedCommits are commits linked to this checkpoint by `entire stamp`
+	StampedCommits []string
+
+	// Imported is set when the latest session was synthesized by `entire import foreign`
+	Imported bool
 }
 
 // SessionContent contains the actual content for a session.
@@ -357,6 +501,43 @@ type SessionContent struct {
 
 	// Context is the context.md content
 	Context string
+
+	// Review is the machine review (review.md) content, if any
+	Review string
+
+	// Revision is the latest append-only revision applied to this content.
+	// 0 means the content is exactly as originally written.
+	Revision int
+}
+
+// SessionRevision records an append-only correction to a committed session.
+// Revisions live under <session>/revisions/<n>/ next to the files they
+// supersede; the original files are never modified. Revision 0 is the
+// original write and has no revision.json of its own.
+type SessionRevision struct {
+	// Revision is the 1-based revision number (0 for the original write)
+	Revision int `json:"revision"`
+
+	// Supersedes is the revision this one corrects
+	Supersedes int `json:"supersedes"`
+
+	// Reason describes why the revision was recorded (e.g. "finalize", "summary")
+	Reason string `json:"reason"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// Files lists the session files replaced by this revision
+	Files []string `json:"files,omitempty"`
+
+	// PromptsCount replaces the session's prompts count when prompts were revised
+	PromptsCount int `json:"prompts_count,omitempty"`
+
+	// Summary replaces the session's AI summary when set
+	Summary *Summary `json:"summary,omitempty"`
+
+	// TranscriptFilter replaces the session's transcript filter stats when
+	// the revised transcript was filtered
+	TranscriptFilter *transcript.FilterStats `json:"transcript_filter,omitempty"`
 }
 
 // CommittedMetadata contains the metadata stored in metadata.json for each checkpoint.
@@ -392,11 +573,72 @@ type CommittedMetadata struct {
 	// Token usage for this checkpoint
 	TokenUsage *agent.TokenUsage `json:"token_usage,omitempty"`
 
+	// PromptsCount is the number of user prompts stored for this session
+	PromptsCount int `json:"prompts_count,omitempty"`
+
 	// AI-generated summary of the checkpoint
 	Summary *Summary `json:"summary,omitempty"`
 
 	// InitialAttribution is line-level attribution calculated at commit time
 	InitialAttribution *InitialAttribution `json:"initial_attribution,omitempty"`
+
+	// QualityGate is the session's last quality gate result before this
+	// checkpoint was written, if a gate is configured
+	QualityGate *qualitygate.Result `json:"quality_gate,omitempty"`
+
+	// ComplianceScan is the session's last compliance scan report before
+	// this checkpoint was written, if a scan is configured
+	ComplianceScan *compliance.Report `json:"compliance_scan,omitempty"`
+
+	// TranscriptFilter records what the transcript.keep_types and
+	// transcript.drop_tool_output_over_bytes settings removed from the stored
+	// transcript, including its original size
+	TranscriptFilter *transcript.FilterStats `json:"transcript_filter,omitempty"`
+
+	// TornSnapshotFiles are files whose snapshot in one of the session's
+	// steps did not match the file on disk, even after a retry. Their
+	// content in this checkpoint's steps may be a mix of two edits.
+	TornSnapshotFiles []string `json:"torn_snapshot_files,omitempty"`
+
+	// OmittedFiles are files whose content the session's steps did not store
+	// because of the snapshot.max_file_bytes and snapshot.skip_binary
+	// settings. Only their path, blob hash, and size are recorded.
+	OmittedFiles []OmittedFile `json:"omitted_files,omitempty"`
+
+	// Tickets are the issue or ticket IDs the session was linked to, set with
+	// `entire session set-ticket` or found in the branch name
+	Tickets []string `json:"tickets,omitempty"`
+
+	// ParentSessionID is the session this session resumes. Following it
+	// through the checkpoints of earlier sessions gives the session lineage.
+	ParentSessionID string `json:"parent_session_id,omitempty"`
+
+	// ErasedAt is set when the session's transcript, prompts, context, and
+	// summary were removed with `entire privacy erase`
+	ErasedAt *time.Time `json:"erased_at,omitempty"`
+
+	// Imported is set on checkpoints synthesized by `entire import foreign`
+	// from another tool's attribution. They have no transcript or prompts.
+	Imported *ImportInfo `json:"imported,omitempty"`
+}
+
+// ImportInfo describes the foreign attribution an imported checkpoint was
+// synthesized from.
+type ImportInfo struct {
+	// Format is the import format that recognized the attribution (e.g. "aider")
+	Format string `json:"format"`
+
+	// Commit is the commit the attribution was found on
+	Commit string `json:"commit"`
+
+	// Tool is the tool the commit is attributed to, as written by that tool
+	Tool string `json:"tool,omitempty"`
+
+	// Model is the model named in the attribution, if any
+	Model string `json:"model,omitempty"`
+
+	// Evidence is the line of the commit that carried the attribution
+	Evidence string `json:"evidence,omitempty"`
 }
 
 // GetTranscriptStart returns the transcript line offset at which this checkpoint's data begins.
@@ -418,6 +660,7 @@ type SessionFilePaths struct {
 	Context     string `json:"context"`
 	ContentHash string `json:"content_hash"`
 	Prompt      string `json:"prompt"`
+	Review      string `json:"review,omitempty"`
 }
 
 // CheckpointSummary is the root-level metadata.json for a checkpoint.
@@ -448,6 +691,26 @@ type CheckpointSummary struct {
 	FilesTouched     []string           `json:"files_touched"`
 	Sessions         []SessionFilePaths `json:"sessions"`
 	TokenUsage       *agent.TokenUsage  `json:"token_usage,omitempty"`
+
+	// Contributions breaks the aggregated statistics down per session, in the
+	// same order as Sessions. Absent for checkpoints written by older CLI versions.
+	Contributions []SessionContribution `json:"contributions,omitempty"`
+
+	// StampedCommits are commits linked to this checkpoint by `entire stamp`
+	// because they were created without hooks (e.g. by a merge queue) and
+	// carry no Entire-Checkpoint trailer.
+	StampedCommits []string `json:"stamped_commits,omitempty"`
+}
+
+// SessionContribution records what a single session contributed to a checkpoint.
+// When several sessions (possibly from different agents) condense into the same
+// commit, these records keep the commit attributable to each of them.
+type SessionContribution struct {
+	SessionID    string            `json:"session_id"`
+	Agent        agent.AgentType   `json:"agent,omitempty"`
+	PromptsCount int               `json:"prompts_count"`
+	FilesTouched []string          `json:"files_touched"`
+	TokenUsage   *agent.TokenUsage `json:"token_usage,omitempty"`
 }
 
 // Summary contains AI-generated summary of a checkpoint.
@@ -575,6 +838,12 @@ type WriteTemporaryTaskOptions struct {
 
 	// IncrementalData is the tool_input payload for this checkpoint
 	IncrementalData []byte
+
+	// MaxFileBytes and SkipBinaryFiles work as in WriteTemporaryOptions.
+	// Task checkpoints have no metadata directory, so omitted files are
+	// only logged.
+	MaxFileBytes    int64
+	SkipBinaryFiles bool
 }
 
 // TemporaryCheckpointInfo contains information about a single commit on a shadow branch.
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -16,6 +17,8 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
@@ -149,7 +152,7 @@ func TestWriteCommitted_AgentField(t *testing.T) {
 	}
 
 	// Verify root metadata.json contains agents in the Agents array
-	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get metadata branch reference: %v", err)
 	}
@@ -223,7 +226,7 @@ func TestWriteCommitted_AgentField(t *testing.T) {
 func readLatestSessionMetadata(t *testing.T, repo *git.Repository, checkpointID id.CheckpointID) CommittedMetadata {
 	t.Helper()
 
-	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get metadata branch reference: %v", err)
 	}
@@ -362,6 +365,20 @@ func TestWriteTemporary_Deduplication(t *testing.T) {
 	if result1.CommitHash == plumbing.ZeroHash {
 		t.Error("first checkpoint should have a commit hash")
 	}
+	if got := result1.Snapshots["test.go"]; got != SnapshotVerified {
+		t.Errorf("test.go snapshot status = %q, want %q", got, SnapshotVerified)
+	}
+	commit1, err := repo.CommitObject(result1.CommitHash)
+	if err != nil {
+		t.Fatalf("failed to read checkpoint commit: %v", err)
+	}
+	tree1, err := commit1.Tree()
+	if err != nil {
+		t.Fatalf("failed to read checkpoint tree: %v", err)
+	}
+	if _, err := tree1.File(".entire/metadata/test-session/" + paths.SnapshotFileName); err != nil {
+		t.Errorf("checkpoint should record snapshot status: %v", err)
+	}
 
 	// Second checkpoint with identical content should be skipped
 	result2, err := store.WriteTemporary(context.Background(), WriteTemporaryOptions{
@@ -413,6 +430,116 @@ func TestWriteTemporary_Deduplication(t *testing.T) {
 	}
 }
 
+// TestWriteTemporary_SnapshotLimits verifies that files over the size limit
+// or that look binary are recorded by hash only, and stay listed in
+// omitted.json until a later step stores them.
+func TestWriteTemporary_SnapshotLimits(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "README.md"), []byte("# Test"), 0o644); err != nil {
+		t.Fatalf("failed to write README: %v", err)
+	}
+	if _, err := worktree.Add("README.md"); err != nil {
+		t.Fatalf("failed to add README: %v", err)
+	}
+	initialCommit, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	t.Chdir(tempDir)
+
+	large := []byte(strings.Repeat("x", 100))
+	binary := []byte("PNG\x00\x01\x02")
+	for name, content := range map[string][]byte{"small.go": []byte("package main\n"), "large.txt": large, "image.png": binary} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), content, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	metadataDir := filepath.Join(tempDir, ".entire", "metadata", "test-session")
+	if err := os.MkdirAll(metadataDir, 0o755); err != nil {
+		t.Fatalf("failed to create metadata dir: %v", err)
+	}
+
+	store := NewGitStore(repo)
+	write := func(message string, files []string, maxFileBytes int64) (WriteTemporaryResult, *object.Tree) {
+		t.Helper()
+		result, err := store.WriteTemporary(context.Background(), WriteTemporaryOptions{
+			SessionID:       "test-session",
+			BaseCommit:      initialCommit.String(),
+			NewFiles:        files,
+			MetadataDir:     ".entire/metadata/test-session",
+			MetadataDirAbs:  metadataDir,
+			CommitMessage:   message,
+			AuthorName:      "Test",
+			AuthorEmail:     "test@test.com",
+			MaxFileBytes:    maxFileBytes,
+			SkipBinaryFiles: true,
+		})
+		if err != nil {
+			t.Fatalf("WriteTemporary() error = %v", err)
+		}
+		commit, err := repo.CommitObject(result.CommitHash)
+		if err != nil {
+			t.Fatalf("failed to read checkpoint commit: %v", err)
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			t.Fatalf("failed to read checkpoint tree: %v", err)
+		}
+		return result, tree
+	}
+
+	result, tree := write("Checkpoint 1", []string{"small.go", "large.txt", "image.png"}, 50)
+	want := []OmittedFile{
+		{Path: "large.txt", Hash: plumbing.ComputeHash(plumbing.BlobObject, large).String(), Size: 100, Reason: OmittedTooLarge},
+		{Path: "image.png", Hash: plumbing.ComputeHash(plumbing.BlobObject, binary).String(), Size: int64(len(binary)), Reason: OmittedBinary},
+	}
+	if !reflect.DeepEqual(result.Omitted, want) {
+		t.Errorf("Omitted = %+v, want %+v", result.Omitted, want)
+	}
+	if _, err := tree.File("small.go"); err != nil {
+		t.Errorf("small.go should be stored: %v", err)
+	}
+	for _, name := range []string{"large.txt", "image.png"} {
+		if _, err := tree.File(name); err == nil {
+			t.Errorf("%s content should not be stored", name)
+		}
+	}
+	if got := ReadOmittedFiles(tree, ".entire/metadata/test-session"); len(got) != 2 || got[0].Path != "image.png" || got[1].Path != "large.txt" {
+		t.Errorf("ReadOmittedFiles() = %+v, want image.png and large.txt", got)
+	}
+
+	// A later step that doesn't touch the files keeps them listed
+	if err := os.WriteFile(filepath.Join(tempDir, "small.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("failed to modify small.go: %v", err)
+	}
+	result, tree = write("Checkpoint 2", []string{"small.go"}, 50)
+	if len(result.Omitted) != 0 {
+		t.Errorf("Omitted = %+v, want none", result.Omitted)
+	}
+	if got := ReadOmittedFiles(tree, ".entire/metadata/test-session"); len(got) != 2 {
+		t.Errorf("ReadOmittedFiles() = %+v, want both files still listed", got)
+	}
+
+	// Raising the limit stores large.txt and drops it from the list
+	_, tree = write("Checkpoint 3", []string{"large.txt"}, 0)
+	if _, err := tree.File("large.txt"); err != nil {
+		t.Errorf("large.txt should be stored once under the limit: %v", err)
+	}
+	if got := ReadOmittedFiles(tree, ".entire/metadata/test-session"); len(got) != 1 || got[0].Path != "image.png" {
+		t.Errorf("ReadOmittedFiles() = %+v, want only image.png", got)
+	}
+}
+
 // setupBranchTestRepo creates a test repository with an initial commit.
 func setupBranchTestRepo(t *testing.T) (*git.Repository, plumbing.Hash) {
 	t.Helper()
@@ -449,7 +576,7 @@ func setupBranchTestRepo(t *testing.T) (*git.Repository, plumbing.Hash) {
 func verifyBranchInMetadata(t *testing.T, repo *git.Repository, checkpointID id.CheckpointID, expectedBranch string, shouldOmit bool) {
 	t.Helper()
 
-	metadataRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	metadataRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get metadata branch reference: %v", err)
 	}
@@ -676,6 +803,77 @@ func TestUpdateSummary_NotFound(t *testing.T) {
 	}
 }
 
+// TestStampCommit verifies that stamped commits are recorded once, listed by
+// ListCommitted and StampedCheckpoints, and kept when another session is
+// written to the checkpoint.
+func TestStampCommit(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	ctx := context.Background()
+	checkpointID := id.MustCheckpointID("5a5b5c5d5e5f")
+	commitHash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+
+	write := func(sessionID string) {
+		t.Helper()
+		err := store.WriteCommitted(ctx, WriteCommittedOptions{
+			CheckpointID: checkpointID,
+			SessionID:    sessionID,
+			Strategy:     "manual-commit",
+			Transcript:   []byte("transcript"),
+			AuthorName:   "Test Author",
+			AuthorEmail:  "test@example.com",
+		})
+		if err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+	write("session-one")
+
+	stamped, err := store.StampCommit(ctx, checkpointID, commitHash)
+	if err != nil {
+		t.Fatalf("StampCommit() error = %v", err)
+	}
+	if !stamped {
+		t.Error("StampCommit() = false, want true on first stamp")
+	}
+	stamped, err = store.StampCommit(ctx, checkpointID, commitHash)
+	if err != nil {
+		t.Fatalf("StampCommit() second call error = %v", err)
+	}
+	if stamped {
+		t.Error("StampCommit() = true, want false when already stamped")
+	}
+
+	write("session-two")
+
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		t.Fatalf("ListCommitted() error = %v", err)
+	}
+	if len(committed) != 1 {
+		t.Fatalf("ListCommitted() returned %d checkpoints, want 1", len(committed))
+	}
+	want := []string{commitHash.String()}
+	if !reflect.DeepEqual(committed[0].StampedCommits, want) {
+		t.Errorf("StampedCommits = %v, want %v", committed[0].StampedCommits, want)
+	}
+
+	other := plumbing.NewHash("89abcdef0123456789abcdef0123456789abcdef")
+	byCommit, err := store.StampedCheckpoints(ctx, []plumbing.Hash{commitHash, other})
+	if err != nil {
+		t.Fatalf("StampedCheckpoints() error = %v", err)
+	}
+	wantByCommit := map[plumbing.Hash][]id.CheckpointID{commitHash: {checkpointID}}
+	if !reflect.DeepEqual(byCommit, wantByCommit) {
+		t.Errorf("StampedCheckpoints() = %v, want %v", byCommit, wantByCommit)
+	}
+
+	_, err = store.StampCommit(ctx, id.MustCheckpointID("000000000000"), commitHash)
+	if !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("StampCommit() error = %v, want ErrCheckpointNotFound", err)
+	}
+}
+
 // TestListCommitted_FallsBackToRemote verifies that ListCommitted can find
 // checkpoints when only origin/entire/checkpoints/v1 exists (simulating post-clone state).
 func TestListCommitted_FallsBackToRemote(t *testing.T) {
@@ -730,7 +928,7 @@ func TestListCommitted_FallsBackToRemote(t *testing.T) {
 
 	// Fetch the entire/checkpoints/v1 branch to origin/entire/checkpoints/v1
 	// (but don't create local branch - simulating post-clone state)
-	refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", paths.MetadataBranchName, paths.MetadataBranchName)
+	refSpec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", paths.MetadataBranchName(), paths.MetadataBranchName())
 	err = localRepo.Fetch(&git.FetchOptions{
 		RemoteName: "origin",
 		RefSpecs:   []config.RefSpec{config.RefSpec(refSpec)},
@@ -740,13 +938,13 @@ func TestListCommitted_FallsBackToRemote(t *testing.T) {
 	}
 
 	// Verify local branch doesn't exist
-	_, err = localRepo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = localRepo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err == nil {
 		t.Fatal("local entire/checkpoints/v1 branch should not exist")
 	}
 
 	// Verify remote-tracking branch exists
-	_, err = localRepo.Reference(plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName), true)
+	_, err = localRepo.Reference(plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("origin/entire/checkpoints/v1 should exist: %v", err)
 	}
@@ -1085,6 +1283,73 @@ func TestReadCommitted_ReturnsCheckpointSummary(t *testing.T) {
 	}
 }
 
+// TestWriteCommitted_SessionContributions verifies that the CheckpointSummary
+// records a per-session breakdown when multiple sessions share a checkpoint.
+func TestWriteCommitted_SessionContributions(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("c7c8c9cacbcc")
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "session-claude",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(`{"message": "first"}`),
+		Prompts:      []string{"Add login", "Fix tests"},
+		FilesTouched: []string{"auth.go", "auth_test.go"},
+		TokenUsage:   &agent.TokenUsage{InputTokens: 100, OutputTokens: 50},
+		AuthorName:   "Test Author",
+		AuthorEmail:  "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() first session error = %v", err)
+	}
+
+	err = store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "session-gemini",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeGemini,
+		Transcript:   []byte(`{"message": "second"}`),
+		Prompts:      []string{"Update docs"},
+		FilesTouched: []string{"README.md"},
+		AuthorName:   "Test Author",
+		AuthorEmail:  "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() second session error = %v", err)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if summary == nil {
+		t.Fatal("ReadCommitted() returned nil summary")
+		return
+	}
+
+	want := []SessionContribution{
+		{
+			SessionID:    "session-claude",
+			Agent:        agent.AgentTypeClaudeCode,
+			PromptsCount: 2,
+			FilesTouched: []string{"auth.go", "auth_test.go"},
+			TokenUsage:   &agent.TokenUsage{InputTokens: 100, OutputTokens: 50},
+		},
+		{
+			SessionID:    "session-gemini",
+			Agent:        agent.AgentTypeGemini,
+			PromptsCount: 1,
+			FilesTouched: []string{"README.md"},
+		},
+	}
+	if !reflect.DeepEqual(summary.Contributions, want) {
+		t.Errorf("summary.Contributions = %+v, want %+v", summary.Contributions, want)
+	}
+}
+
 // TestReadSessionContent_ByIndex verifies that ReadSessionContent can read
 // specific sessions by their 0-based index within a checkpoint.
 func TestReadSessionContent_ByIndex(t *testing.T) {
@@ -1428,6 +1693,75 @@ func TestWriteCommitted_SessionWithSummary(t *testing.T) {
 	}
 }
 
+// TestWriteCommitted_SessionWithReview verifies that a machine review is
+// stored as review.md and read back with the session content.
+func TestWriteCommitted_SessionWithReview(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("a0a1a2a3a4a5")
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "review-session",
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"test": true}`),
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+		Review:           []byte("Looks good; consider a test for the empty case."),
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if want := "/" + checkpointID.Path() + "/0/" + paths.ReviewFileName; summary.Sessions[0].Review != want {
+		t.Errorf("Sessions[0].Review = %q, want %q", summary.Sessions[0].Review, want)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Review != "Looks good; consider a test for the empty case." {
+		t.Errorf("Review = %q, want the stored review", content.Review)
+	}
+}
+
+// TestWriteCommitted_UsesClock verifies that committed metadata timestamps
+// come from the store's clock.
+func TestWriteCommitted_UsesClock(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	fixed := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	store.SetClock(clock.NewFake(fixed))
+	checkpointID := id.MustCheckpointID("b0b1b2b3b4b5")
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "clock-session",
+		Strategy:         "manual-commit",
+		Transcript:       []byte(`{"test": true}`),
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if !content.Metadata.CreatedAt.Equal(fixed) {
+		t.Errorf("CreatedAt = %v, want %v", content.Metadata.CreatedAt, fixed)
+	}
+}
+
 // TestWriteCommitted_SessionWithNoContext verifies that a session can be
 // written without context and still be read correctly.
 func TestWriteCommitted_SessionWithNoContext(t *testing.T) {
@@ -2759,6 +3093,57 @@ func TestWriteCommitted_RedactsPromptSecrets(t *testing.T) {
 	}
 }
 
+func TestReadCommittedMetadata_ComplianceScan(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("aabbccddeef5")
+
+	for _, sessionID := range []string{"scan-session-1", "scan-session-2"} {
+		opts := WriteCommittedOptions{
+			CheckpointID:     checkpointID,
+			SessionID:        sessionID,
+			Strategy:         "manual-commit",
+			Transcript:       []byte(`{"msg":"safe"}`),
+			CheckpointsCount: 1,
+			AuthorName:       "Test Author",
+			AuthorEmail:      "test@example.com",
+		}
+		if sessionID == "scan-session-2" {
+			opts.ComplianceScan = &compliance.Report{
+				Command: "license-scan",
+				Findings: []compliance.Finding{
+					{Kind: compliance.KindLicenseHeader, File: "main.go", Message: "token " + highEntropySecret},
+				},
+			}
+		}
+		if err := store.WriteCommitted(context.Background(), opts); err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+
+	metadata, err := store.ReadCommittedMetadata(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommittedMetadata() error = %v", err)
+	}
+	if len(metadata) != 2 {
+		t.Fatalf("len(metadata) = %d, want 2", len(metadata))
+	}
+	if metadata[0].ComplianceScan != nil {
+		t.Errorf("session without a scan has ComplianceScan = %+v", metadata[0].ComplianceScan)
+	}
+	scan := metadata[1].ComplianceScan
+	if scan == nil || len(scan.Findings) != 1 {
+		t.Fatalf("ComplianceScan = %+v, want one finding", scan)
+	}
+	if msg := scan.Findings[0].Message; strings.Contains(msg, highEntropySecret) || !strings.Contains(msg, "REDACTED") {
+		t.Errorf("finding message should be redacted, got %q", msg)
+	}
+
+	if _, err := store.ReadCommittedMetadata(context.Background(), id.MustCheckpointID("aabbccddeef6")); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("ReadCommittedMetadata() for a missing checkpoint error = %v, want ErrCheckpointNotFound", err)
+	}
+}
+
 func TestWriteCommitted_RedactsContextSecrets(t *testing.T) {
 	repo, _ := setupBranchTestRepo(t)
 	store := NewGitStore(repo)
@@ -2906,7 +3291,7 @@ func TestWriteCommitted_CLIVersionField(t *testing.T) {
 	}
 
 	// Read the metadata branch
-	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get metadata branch reference: %v", err)
 	}
@@ -3251,7 +3636,7 @@ func TestWriteCommitted_SubagentTranscript_JSONLFallback(t *testing.T) {
 	}
 
 	// Read back the subagent transcript from the tree
-	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get branch ref: %v", err)
 	}
@@ -3522,3 +3907,92 @@ func TestAddDirectoryToEntries_SkipsSymlinkedDirectories(t *testing.T) {
 		t.Errorf("expected 1 entry (regular.txt only), got %d: %v", len(entries), entries)
 	}
 }
+
+func TestWriteCommitted_TranscriptChunksReusedAcrossCheckpoints(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	var transcript strings.Builder
+	writeLines := func(first, n int) {
+		for i := first; i < first+n; i++ {
+			fmt.Fprintf(&transcript, `{"type":"assistant","uuid":"msg-%d","message":"%s"}`+"\n", i, strings.Repeat(fmt.Sprintf("step %d ", i*7919%1000), 40))
+		}
+	}
+	writeLines(0, 2000)
+	first := transcript.String()
+	writeLines(2000, 100)
+	second := transcript.String()
+
+	chunkHashes := func(checkpointID id.CheckpointID, want string) map[plumbing.Hash]bool {
+		t.Helper()
+		content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+		if err != nil {
+			t.Fatalf("ReadSessionContent() error = %v", err)
+		}
+		if string(content.Transcript) != want {
+			t.Fatalf("transcript mismatch for %s: got %d bytes, want %d", checkpointID, len(content.Transcript), len(want))
+		}
+
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+		if err != nil {
+			t.Fatalf("failed to get metadata branch: %v", err)
+		}
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			t.Fatalf("failed to get commit: %v", err)
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			t.Fatalf("failed to get tree: %v", err)
+		}
+		sessionTree, err := tree.Tree(checkpointID.Path() + "/0")
+		if err != nil {
+			t.Fatalf("failed to get session tree: %v", err)
+		}
+		hashes := make(map[plumbing.Hash]bool)
+		for _, entry := range sessionTree.Entries {
+			if agent.ParseChunkIndex(entry.Name, paths.TranscriptFileName) >= 0 {
+				hashes[entry.Hash] = true
+			}
+		}
+		return hashes
+	}
+
+	for i, tc := range []struct {
+		checkpointID id.CheckpointID
+		transcript   string
+	}{
+		{id.MustCheckpointID("c0c0c0c0c0c1"), first},
+		{id.MustCheckpointID("c0c0c0c0c0c2"), second},
+	} {
+		err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+			CheckpointID:         tc.checkpointID,
+			SessionID:            "chunked-session",
+			Strategy:             "manual-commit",
+			Agent:                agent.AgentTypeClaudeCode,
+			Transcript:           []byte(tc.transcript),
+			ContentDefinedChunks: true,
+			CheckpointsCount:     i + 1,
+			AuthorName:           "Test Author",
+			AuthorEmail:          "test@example.com",
+		})
+		if err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+
+	firstChunks := chunkHashes(id.MustCheckpointID("c0c0c0c0c0c1"), first)
+	secondChunks := chunkHashes(id.MustCheckpointID("c0c0c0c0c0c2"), second)
+	if len(firstChunks) < 3 {
+		t.Fatalf("expected the transcript to be stored in several chunks, got %d", len(firstChunks))
+	}
+	shared := 0
+	for hash := range firstChunks {
+		if secondChunks[hash] {
+			shared++
+		}
+	}
+	if shared < len(firstChunks)-1 {
+		t.Errorf("expected all but the last chunk to be reused, %d of %d shared", shared, len(firstChunks))
+	}
+}
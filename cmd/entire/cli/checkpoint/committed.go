@@ -16,12 +16,17 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/qualitygate"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
 	"github.com/entireio/cli/cmd/entire/cli/validation"
 	"github.com/entireio/cli/redact"
 
@@ -30,6 +35,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
 	"github.com/go-git/go-git/v5/utils/binary"
 )
 
@@ -45,6 +51,13 @@ var errStopIteration = errors.New("stop iteration")
 func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOptions) error {
 	_ = ctx // Reserved for future use
 
+	return s.retryMetadataUpdate(func() error {
+		return s.writeCommitted(opts)
+	})
+}
+
+// writeCommitted applies one WriteCommitted attempt on the current branch tip.
+func (s *GitStore) writeCommitted(opts WriteCommittedOptions) error {
 	// Validate identifiers to prevent path traversal and malformed data
 	if opts.CheckpointID.IsEmpty() {
 		return errors.New("invalid checkpoint options: checkpoint ID is required")
@@ -101,20 +114,17 @@ func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOption
 		return err
 	}
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	newRef := plumbing.NewHashReference(refName, newCommitHash)
-	if err := s.repo.Storer.SetReference(newRef); err != nil {
-		return fmt.Errorf("failed to set branch reference: %w", err)
-	}
-
-	return nil
+	return s.setMetadataRef(ref, newCommitHash)
 }
 
 // getSessionsBranchEntries returns the sessions branch reference and flattened tree entries.
 func (s *GitStore) getSessionsBranchEntries() (*plumbing.Reference, map[string]object.TreeEntry, error) {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := s.repo.Reference(refName, true)
 	if err != nil {
+		if s.refBeingWritten(refName) {
+			return nil, nil, fmt.Errorf("sessions branch is being updated: %w", storage.ErrReferenceHasChanged)
+		}
 		return nil, nil, fmt.Errorf("failed to get sessions branch reference: %w", err)
 	}
 
@@ -155,10 +165,10 @@ func (s *GitStore) writeIncrementalTaskCheckpoint(opts WriteCommittedOptions, ta
 	checkpoint := incrementalCheckpointData{
 		Type:      opts.IncrementalType,
 		ToolUseID: opts.ToolUseID,
-		Timestamp: time.Now().UTC(),
+		Timestamp: s.now().UTC(),
 		Data:      incData,
 	}
-	cpData, err := jsonutil.MarshalIndentWithNewline(checkpoint, "", "  ")
+	cpData, err := jsonutil.MarshalCanonical(checkpoint)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal incremental checkpoint: %w", err)
 	}
@@ -185,7 +195,7 @@ func (s *GitStore) writeFinalTaskCheckpoint(opts WriteCommittedOptions, taskPath
 		CheckpointUUID: opts.CheckpointUUID,
 		AgentID:        opts.AgentID,
 	}
-	checkpointData, err := jsonutil.MarshalIndentWithNewline(checkpoint, "", "  ")
+	checkpointData, err := jsonutil.MarshalCanonical(checkpoint)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal task checkpoint: %w", err)
 	}
@@ -308,6 +318,9 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		return filePaths, err
 	}
 	filePaths.Transcript = "/" + sessionPath + paths.TranscriptFileName
+	if opts.CompressTranscript {
+		filePaths.Transcript += compressedChunkSuffix
+	}
 	filePaths.ContentHash = "/" + sessionPath + paths.ContentHashFileName
 
 	// Write prompts
@@ -339,15 +352,34 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		filePaths.Context = "/" + sessionPath + paths.ContextFileName
 	}
 
+	// Write machine review
+	if len(opts.Review) > 0 {
+		blobHash, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Review))
+		if err != nil {
+			return filePaths, err
+		}
+		entries[sessionPath+paths.ReviewFileName] = object.TreeEntry{
+			Name: sessionPath + paths.ReviewFileName,
+			Mode: filemode.Regular,
+			Hash: blobHash,
+		}
+		filePaths.Review = "/" + sessionPath + paths.ReviewFileName
+	}
+
+	createdAt := s.now().UTC()
+	if !opts.CreatedAt.IsZero() {
+		createdAt = opts.CreatedAt.UTC()
+	}
+
 	// Write session-level metadata.json (CommittedMetadata with all fields including initial_attribution)
 	sessionMetadata := CommittedMetadata{
 		CheckpointID:                opts.CheckpointID,
 		SessionID:                   opts.SessionID,
 		Strategy:                    opts.Strategy,
-		CreatedAt:                   time.Now().UTC(),
+		CreatedAt:                   createdAt,
 		Branch:                      opts.Branch,
 		CheckpointsCount:            opts.CheckpointsCount,
-		FilesTouched:                opts.FilesTouched,
+		FilesTouched:                sortedCopy(opts.FilesTouched),
 		Agent:                       opts.Agent,
 		TurnID:                      opts.TurnID,
 		IsTask:                      opts.IsTask,
@@ -356,12 +388,21 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		CheckpointTranscriptStart:   opts.CheckpointTranscriptStart,
 		TranscriptLinesAtStart:      opts.CheckpointTranscriptStart, // Deprecated: kept for backward compat
 		TokenUsage:                  opts.TokenUsage,
-		InitialAttribution:          opts.InitialAttribution,
+		PromptsCount:                len(opts.Prompts),
+		InitialAttribution:          utcAttribution(opts.InitialAttribution),
 		Summary:                     redactSummary(opts.Summary),
+		QualityGate:                 redactQualityGate(opts.QualityGate),
+		ComplianceScan:              redactComplianceScan(opts.ComplianceScan),
+		TranscriptFilter:            opts.TranscriptFilter,
+		TornSnapshotFiles:           sortedCopy(opts.TornSnapshotFiles),
+		OmittedFiles:                opts.OmittedFiles,
+		Tickets:                     opts.Tickets,
+		ParentSessionID:             opts.ParentSessionID,
+		Imported:                    opts.Imported,
 		CLIVersion:                  buildinfo.Version,
 	}
 
-	metadataJSON, err := jsonutil.MarshalIndentWithNewline(sessionMetadata, "", "  ")
+	metadataJSON, err := jsonutil.MarshalCanonical(sessionMetadata)
 	if err != nil {
 		return filePaths, fmt.Errorf("failed to marshal session metadata: %w", err)
 	}
@@ -382,8 +423,7 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 // writeCheckpointSummary writes the root-level CheckpointSummary with aggregated statistics.
 // sessions is the complete sessions array (already built by the caller).
 func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry, sessions []SessionFilePaths) error {
-	checkpointsCount, filesTouched, tokenUsage, err :=
-		s.reaggregateFromEntries(basePath, len(sessions), entries)
+	agg, err := s.reaggregateFromEntries(basePath, len(sessions), entries)
 	if err != nil {
 		return fmt.Errorf("failed to aggregate session stats: %w", err)
 	}
@@ -393,13 +433,15 @@ func (s *GitStore) writeCheckpointSummary(opts WriteCommittedOptions, basePath s
 		CLIVersion:       buildinfo.Version,
 		Strategy:         opts.Strategy,
 		Branch:           opts.Branch,
-		CheckpointsCount: checkpointsCount,
-		FilesTouched:     filesTouched,
+		CheckpointsCount: agg.checkpointsCount,
+		FilesTouched:     agg.filesTouched,
 		Sessions:         sessions,
-		TokenUsage:       tokenUsage,
+		TokenUsage:       agg.tokenUsage,
+		Contributions:    agg.contributions,
+		StampedCommits:   s.stampedCommitsAt(basePath+paths.MetadataFileName, entries),
 	}
 
-	metadataJSON, err := jsonutil.MarshalIndentWithNewline(summary, "", "  ")
+	metadataJSON, err := jsonutil.MarshalCanonical(summary)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkpoint summary: %w", err)
 	}
@@ -441,29 +483,43 @@ func (s *GitStore) findSessionIndex(basePath string, existingSummary *Checkpoint
 	return len(existingSummary.Sessions)
 }
 
+// sessionAggregate holds statistics aggregated across all sessions of a checkpoint.
+type sessionAggregate struct {
+	checkpointsCount int
+	filesTouched     []string
+	tokenUsage       *agent.TokenUsage
+	contributions    []SessionContribution
+}
+
 // reaggregateFromEntries reads all session metadata from the entries map and
-// reaggregates CheckpointsCount, FilesTouched, and TokenUsage.
-func (s *GitStore) reaggregateFromEntries(basePath string, sessionCount int, entries map[string]object.TreeEntry) (int, []string, *agent.TokenUsage, error) {
-	var totalCount int
-	var allFiles []string
-	var totalTokens *agent.TokenUsage
+// reaggregates CheckpointsCount, FilesTouched, and TokenUsage, along with the
+// per-session contribution records.
+func (s *GitStore) reaggregateFromEntries(basePath string, sessionCount int, entries map[string]object.TreeEntry) (sessionAggregate, error) {
+	var agg sessionAggregate
 
 	for i := range sessionCount {
 		path := fmt.Sprintf("%s%d/%s", basePath, i, paths.MetadataFileName)
 		entry, exists := entries[path]
 		if !exists {
-			return 0, nil, nil, fmt.Errorf("session %d metadata not found at %s", i, path)
+			return sessionAggregate{}, fmt.Errorf("session %d metadata not found at %s", i, path)
 		}
 		meta, err := s.readMetadataFromBlob(entry.Hash)
 		if err != nil {
-			return 0, nil, nil, fmt.Errorf("failed to read session %d metadata: %w", i, err)
+			return sessionAggregate{}, fmt.Errorf("failed to read session %d metadata: %w", i, err)
 		}
-		totalCount += meta.CheckpointsCount
-		allFiles = mergeFilesTouched(allFiles, meta.FilesTouched)
-		totalTokens = aggregateTokenUsage(totalTokens, meta.TokenUsage)
-	}
-
-	return totalCount, allFiles, totalTokens, nil
+		agg.checkpointsCount += meta.CheckpointsCount
+		agg.filesTouched = mergeFilesTouched(agg.filesTouched, meta.FilesTouched)
+		agg.tokenUsage = aggregateTokenUsage(agg.tokenUsage, meta.TokenUsage)
+		agg.contributions = append(agg.contributions, SessionContribution{
+			SessionID:    meta.SessionID,
+			Agent:        meta.Agent,
+			PromptsCount: meta.PromptsCount,
+			FilesTouched: meta.FilesTouched,
+			TokenUsage:   meta.TokenUsage,
+		})
+	}
+
+	return agg, nil
 }
 
 // readJSONFromBlob reads JSON from a blob hash and decodes it to the given type.
@@ -492,8 +548,8 @@ func (s *GitStore) readSummaryFromBlob(hash plumbing.Hash) (*CheckpointSummary,
 	return readJSONFromBlob[CheckpointSummary](s.repo, hash)
 }
 
-// aggregateTokenUsage sums two TokenUsage structs.
-// Returns nil if both inputs are nil.
+// aggregateTokenUsage sums two TokenUsage structs. The model is taken from b
+// when it names one. Returns nil if both inputs are nil.
 func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 	if a == nil && b == nil {
 		return nil
@@ -505,6 +561,7 @@ func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 		result.CacheReadTokens = a.CacheReadTokens
 		result.OutputTokens = a.OutputTokens
 		result.APICallCount = a.APICallCount
+		result.Model = a.Model
 	}
 	if b != nil {
 		result.InputTokens += b.InputTokens
@@ -512,17 +569,26 @@ func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 		result.CacheReadTokens += b.CacheReadTokens
 		result.OutputTokens += b.OutputTokens
 		result.APICallCount += b.APICallCount
+		if b.Model != "" {
+			result.Model = b.Model
+		}
 	}
 	return result
 }
 
 // writeTranscript writes the transcript file from in-memory content or file path.
-// If the transcript exceeds MaxChunkSize, it's split into multiple chunk files.
+// Transcripts larger than MaxChunkSize are split into multiple chunk files; with
+// opts.ContentDefinedChunks, those larger than CDCMinChunkSize are split into
+// content-defined chunk files, so chunks unchanged since an earlier checkpoint of
+// the session reuse the same blobs.
 func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) error {
 	transcript := opts.Transcript
 	if len(transcript) == 0 && opts.TranscriptPath != "" {
 		var readErr error
 		transcript, readErr = os.ReadFile(opts.TranscriptPath)
+		if readErr == nil {
+			readErr = faultinject.Check(faultinject.TranscriptRead)
+		}
 		if readErr != nil {
 			// Non-fatal: transcript may not exist yet
 			transcript = nil
@@ -538,24 +604,9 @@ func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string,
 		return fmt.Errorf("failed to redact transcript secrets: %w", err)
 	}
 
-	// Chunk the transcript if it's too large
-	chunks, err := agent.ChunkTranscript(transcript, opts.Agent)
-	if err != nil {
-		return fmt.Errorf("failed to chunk transcript: %w", err)
-	}
-
-	// Write chunk files
-	for i, chunk := range chunks {
-		chunkPath := basePath + agent.ChunkFileName(paths.TranscriptFileName, i)
-		blobHash, err := CreateBlobFromContent(s.repo, chunk)
-		if err != nil {
-			return err
-		}
-		entries[chunkPath] = object.TreeEntry{
-			Name: chunkPath,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
+	// Chunk the transcript so appended content only adds new chunk blobs
+	if err := s.writeTranscriptChunks(transcript, opts.Agent, basePath, opts.CompressTranscript, opts.ContentDefinedChunks, entries); err != nil {
+		return err
 	}
 
 	// Content hash for deduplication (hash of full transcript)
@@ -594,6 +645,55 @@ func mergeFilesTouched(existing, additional []string) []string {
 	return result
 }
 
+// sortedCopy returns a sorted copy of ss, so file lists serialize the same
+// regardless of the order the caller collected them in.
+func sortedCopy(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	out := append([]string(nil), ss...)
+	sort.Strings(out)
+	return out
+}
+
+// utcAttribution returns a copy of the attribution with CalculatedAt in UTC,
+// since time.Time serializes with its location's offset.
+func utcAttribution(a *InitialAttribution) *InitialAttribution {
+	if a == nil {
+		return nil
+	}
+	utc := *a
+	utc.CalculatedAt = a.CalculatedAt.UTC()
+	return &utc
+}
+
+// redactQualityGate returns a copy of the result with secrets removed from
+// the command output.
+func redactQualityGate(r *qualitygate.Result) *qualitygate.Result {
+	if r == nil {
+		return nil
+	}
+	redacted := *r
+	redacted.Output = redact.String(r.Output)
+	return &redacted
+}
+
+// redactComplianceScan returns a copy of the report with secrets removed from
+// the scanner's messages.
+func redactComplianceScan(r *compliance.Report) *compliance.Report {
+	if r == nil {
+		return nil
+	}
+	redacted := *r
+	redacted.Error = redact.String(r.Error)
+	redacted.Findings = make([]compliance.Finding, len(r.Findings))
+	for i, f := range r.Findings {
+		f.Message = redact.String(f.Message)
+		redacted.Findings[i] = f
+	}
+	return &redacted
+}
+
 // redactSummary returns a copy of the summary with text fields redacted.
 // Structural fields (Path, Line, EndLine) are preserved.
 // NOTE: When adding new text fields to Summary, LearningsSummary, or CodeLearning,
@@ -742,6 +842,67 @@ func (s *GitStore) ReadCommitted(ctx context.Context, checkpointID id.Checkpoint
 	return &summary, nil
 }
 
+// CommittedSize returns the total size in bytes of every file stored for a
+// committed checkpoint. Returns (0, false, nil) if the checkpoint doesn't exist.
+func (s *GitStore) CommittedSize(ctx context.Context, checkpointID id.CheckpointID) (int64, bool, error) {
+	_ = ctx // Reserved for future use
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return 0, false, nil //nolint:nilerr // No sessions branch means no checkpoint exists
+	}
+	checkpointTree, err := tree.Tree(checkpointID.Path())
+	if err != nil {
+		return 0, false, nil //nolint:nilerr // Checkpoint directory not found
+	}
+
+	var total int64
+	if err := checkpointTree.Files().ForEach(func(f *object.File) error {
+		total += f.Size
+		return nil
+	}); err != nil {
+		return 0, false, fmt.Errorf("failed to read checkpoint files: %w", err)
+	}
+	return total, true, nil
+}
+
+// ReadCommittedMetadata returns the metadata of every session in a committed
+// checkpoint, without reading transcripts. Like ReadSessionContent, it
+// includes the metadata changes of the sessions' append-only revisions.
+// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+func (s *GitStore) ReadCommittedMetadata(ctx context.Context, checkpointID id.CheckpointID) ([]CommittedMetadata, error) {
+	summary, err := s.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		return nil, ErrCheckpointNotFound
+	}
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+	checkpointTree, err := tree.Tree(checkpointID.Path())
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+
+	metadata := make([]CommittedMetadata, 0, len(summary.Sessions))
+	for i := range summary.Sessions {
+		m, ok := readCommittedSessionMetadata(checkpointTree, i)
+		if !ok {
+			continue
+		}
+		if sessionTree, err := checkpointTree.Tree(strconv.Itoa(i)); err == nil {
+			for _, rev := range readRevisions(sessionTree) {
+				applyRevisionMetadata(rev.SessionRevision, &m)
+			}
+		}
+		metadata = append(metadata, m)
+	}
+	return metadata, nil
+}
+
 // ReadSessionContent reads the actual content for a specific session within a checkpoint.
 // sessionIndex is 0-based (0 for first session, 1 for second, etc.).
 // Returns the session's metadata, transcript, prompts, and context.
@@ -798,6 +959,16 @@ func (s *GitStore) ReadSessionContent(ctx context.Context, checkpointID id.Check
 		}
 	}
 
+	// Read machine review
+	if file, fileErr := sessionTree.File(paths.ReviewFileName); fileErr == nil {
+		if content, contentErr := file.Contents(); contentErr == nil {
+			result.Review = content
+		}
+	}
+
+	// Resolve append-only revisions to the latest content
+	applyRevisions(sessionTree, agentType, result)
+
 	return result, nil
 }
 
@@ -906,22 +1077,21 @@ func (s *GitStore) ListCommitted(ctx context.Context) ([]CommittedInfo, error) {
 						info.CheckpointsCount = summary.CheckpointsCount
 						info.FilesTouched = summary.FilesTouched
 						info.SessionCount = len(summary.Sessions)
-
-						// Read session metadata from latest session to get Agent, SessionID, CreatedAt
-						if len(summary.Sessions) > 0 {
-							latestIndex := len(summary.Sessions) - 1
-							latestDir := strconv.Itoa(latestIndex)
-							if sessionTree, treeErr := checkpointTree.Tree(latestDir); treeErr == nil {
-								if sessionMetadataFile, smErr := sessionTree.File(paths.MetadataFileName); smErr == nil {
-									if sessionContent, scErr := sessionMetadataFile.Contents(); scErr == nil {
-										var sessionMetadata CommittedMetadata
-										if json.Unmarshal([]byte(sessionContent), &sessionMetadata) == nil {
-											info.Agent = sessionMetadata.Agent
-											info.SessionID = sessionMetadata.SessionID
-											info.CreatedAt = sessionMetadata.CreatedAt
-										}
-									}
-								}
+						info.StampedCommits = summary.StampedCommits
+
+						// Read each session's metadata for SessionIDs; the latest session
+						// also supplies Agent, SessionID, and CreatedAt
+						for i := range summary.Sessions {
+							sessionMetadata, ok := readCommittedSessionMetadata(checkpointTree, i)
+							if !ok {
+								continue
+							}
+							info.SessionIDs = append(info.SessionIDs, sessionMetadata.SessionID)
+							if i == len(summary.Sessions)-1 {
+								info.Agent = sessionMetadata.Agent
+								info.SessionID = sessionMetadata.SessionID
+								info.CreatedAt = sessionMetadata.CreatedAt
+								info.Imported = sessionMetadata.Imported != nil
 							}
 						}
 					}
@@ -940,6 +1110,28 @@ func (s *GitStore) ListCommitted(ctx context.Context) ([]CommittedInfo, error) {
 	return checkpoints, nil
 }
 
+// readCommittedSessionMetadata reads the metadata.json of the session stored
+// at sessionIndex within a checkpoint tree.
+func readCommittedSessionMetadata(checkpointTree *object.Tree, sessionIndex int) (CommittedMetadata, bool) {
+	var metadata CommittedMetadata
+	sessionTree, err := checkpointTree.Tree(strconv.Itoa(sessionIndex))
+	if err != nil {
+		return metadata, false
+	}
+	file, err := sessionTree.File(paths.MetadataFileName)
+	if err != nil {
+		return metadata, false
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return metadata, false
+	}
+	if json.Unmarshal([]byte(content), &metadata) != nil {
+		return metadata, false
+	}
+	return metadata, true
+}
+
 // GetTranscript retrieves the transcript for a specific checkpoint ID.
 // Returns the latest session's transcript.
 func (s *GitStore) GetTranscript(ctx context.Context, checkpointID id.CheckpointID) ([]byte, error) {
@@ -986,10 +1178,18 @@ func LookupSessionLog(cpID id.CheckpointID) ([]byte, string, error) {
 }
 
 // UpdateSummary updates the summary field in the latest session's metadata.
+// In append-only mode the summary is recorded as a new session revision instead.
 // Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
 func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary) error {
 	_ = ctx // Reserved for future use
 
+	return s.retryMetadataUpdate(func() error {
+		return s.updateSummary(checkpointID, summary)
+	})
+}
+
+// updateSummary applies one UpdateSummary attempt on the current branch tip.
+func (s *GitStore) updateSummary(checkpointID id.CheckpointID, summary *Summary) error {
 	// Ensure sessions branch exists
 	if err := s.ensureSessionsBranch(); err != nil {
 		return fmt.Errorf("failed to ensure sessions branch: %w", err)
@@ -1028,22 +1228,38 @@ func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.Checkpoint
 		return fmt.Errorf("failed to read session metadata: %w", err)
 	}
 
-	// Update the summary
-	existingMetadata.Summary = redactSummary(summary)
+	if s.appendOnly {
+		// Record the summary as a new revision, leaving metadata.json untouched
+		sessionPath := fmt.Sprintf("%s%d/", basePath, latestIndex)
+		n := nextRevision(sessionPath, entries)
+		revision := SessionRevision{
+			Revision:   n,
+			Supersedes: n - 1,
+			Reason:     RevisionReasonSummary,
+			CreatedAt:  s.now().UTC(),
+			Summary:    redactSummary(summary),
+		}
+		if err := s.writeJSONEntry(revisionPath(sessionPath, n)+paths.RevisionFileName, revision, entries); err != nil {
+			return err
+		}
+	} else {
+		// Update the summary
+		existingMetadata.Summary = redactSummary(summary)
 
-	// Write updated session metadata
-	metadataJSON, err := jsonutil.MarshalIndentWithNewline(existingMetadata, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-	metadataHash, err := CreateBlobFromContent(s.repo, metadataJSON)
-	if err != nil {
-		return fmt.Errorf("failed to create metadata blob: %w", err)
-	}
-	entries[sessionMetadataPath] = object.TreeEntry{
-		Name: sessionMetadataPath,
-		Mode: filemode.Regular,
-		Hash: metadataHash,
+		// Write updated session metadata
+		metadataJSON, err := jsonutil.MarshalCanonical(existingMetadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		metadataHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+		if err != nil {
+			return fmt.Errorf("failed to create metadata blob: %w", err)
+		}
+		entries[sessionMetadataPath] = object.TreeEntry{
+			Name: sessionMetadataPath,
+			Mode: filemode.Regular,
+			Hash: metadataHash,
+		}
 	}
 
 	// Build and commit
@@ -1059,13 +1275,7 @@ func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.Checkpoint
 		return err
 	}
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	newRef := plumbing.NewHashReference(refName, newCommitHash)
-	if err := s.repo.Storer.SetReference(newRef); err != nil {
-		return fmt.Errorf("failed to set branch reference: %w", err)
-	}
-
-	return nil
+	return s.setMetadataRef(ref, newCommitHash)
 }
 
 // UpdateCommitted replaces the transcript, prompts, and context for an existing
@@ -1075,8 +1285,18 @@ func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.Checkpoint
 // This is called at stop time to finalize all checkpoints from the current turn
 // with the complete session transcript (from prompt to stop event).
 //
+// In append-only mode the replacements are written as a new session revision
+// and the originally written files are left unchanged.
+//
 // Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
 func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOptions) error {
+	return s.retryMetadataUpdate(func() error {
+		return s.updateCommitted(ctx, opts)
+	})
+}
+
+// updateCommitted applies one UpdateCommitted attempt on the current branch tip.
+func (s *GitStore) updateCommitted(ctx context.Context, opts UpdateCommittedOptions) error {
 	if opts.CheckpointID.IsEmpty() {
 		return errors.New("invalid update options: checkpoint ID is required")
 	}
@@ -1132,6 +1352,21 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 
 	sessionPath := fmt.Sprintf("%s%d/", basePath, sessionIndex)
 
+	// In append-only mode, write replacements into a new revision directory
+	// instead of over the original files.
+	targetPath := sessionPath
+	var revision *SessionRevision
+	if s.appendOnly {
+		n := nextRevision(sessionPath, entries)
+		targetPath = revisionPath(sessionPath, n)
+		revision = &SessionRevision{
+			Revision:   n,
+			Supersedes: n - 1,
+			Reason:     RevisionReasonFinalize,
+			CreatedAt:  s.now().UTC(),
+		}
+	}
+
 	// Replace transcript (full replace, not append)
 	// Apply redaction as safety net (caller should redact, but we ensure it here)
 	if len(opts.Transcript) > 0 {
@@ -1139,9 +1374,15 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 		if err != nil {
 			return fmt.Errorf("failed to redact transcript secrets: %w", err)
 		}
-		if err := s.replaceTranscript(transcript, opts.Agent, sessionPath, entries); err != nil {
+		if err := s.replaceTranscript(transcript, opts.Agent, targetPath, opts.CompressTranscript, opts.ContentDefinedChunks, entries); err != nil {
 			return fmt.Errorf("failed to replace transcript: %w", err)
 		}
+		if revision != nil {
+			revision.Files = append(revision.Files, paths.TranscriptFileName)
+			revision.TranscriptFilter = opts.TranscriptFilter
+		} else if err := s.updateTranscriptFilter(sessionPath, opts.TranscriptFilter, entries); err != nil {
+			return fmt.Errorf("failed to update transcript filter stats: %w", err)
+		}
 	}
 
 	// Replace prompts (apply redaction as safety net)
@@ -1151,11 +1392,17 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 		if err != nil {
 			return fmt.Errorf("failed to create prompt blob: %w", err)
 		}
-		entries[sessionPath+paths.PromptFileName] = object.TreeEntry{
-			Name: sessionPath + paths.PromptFileName,
+		entries[targetPath+paths.PromptFileName] = object.TreeEntry{
+			Name: targetPath + paths.PromptFileName,
 			Mode: filemode.Regular,
 			Hash: blobHash,
 		}
+		if revision != nil {
+			revision.Files = append(revision.Files, paths.PromptFileName)
+			revision.PromptsCount = len(opts.Prompts)
+		} else if err := s.updatePromptsCount(basePath, sessionIndex, len(opts.Prompts), checkpointSummary, entries); err != nil {
+			return fmt.Errorf("failed to update prompts count: %w", err)
+		}
 	}
 
 	// Replace context (apply redaction as safety net)
@@ -1164,11 +1411,25 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 		if err != nil {
 			return fmt.Errorf("failed to create context blob: %w", err)
 		}
-		entries[sessionPath+paths.ContextFileName] = object.TreeEntry{
-			Name: sessionPath + paths.ContextFileName,
+		entries[targetPath+paths.ContextFileName] = object.TreeEntry{
+			Name: targetPath + paths.ContextFileName,
 			Mode: filemode.Regular,
 			Hash: contextBlob,
 		}
+		if revision != nil {
+			revision.Files = append(revision.Files, paths.ContextFileName)
+		}
+	}
+
+	commitMsg := fmt.Sprintf("Finalize transcript for Checkpoint: %s", opts.CheckpointID)
+	if revision != nil {
+		if len(revision.Files) == 0 {
+			return nil // Nothing to record
+		}
+		if err := s.writeJSONEntry(targetPath+paths.RevisionFileName, revision, entries); err != nil {
+			return err
+		}
+		commitMsg = fmt.Sprintf("Record revision %d for Checkpoint: %s", revision.Revision, opts.CheckpointID)
 	}
 
 	// Build and commit
@@ -1178,24 +1439,84 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 	}
 
 	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
-	commitMsg := fmt.Sprintf("Finalize transcript for Checkpoint: %s", opts.CheckpointID)
 	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
 	if err != nil {
 		return err
 	}
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	newRef := plumbing.NewHashReference(refName, newCommitHash)
-	if err := s.repo.Storer.SetReference(newRef); err != nil {
-		return fmt.Errorf("failed to set branch reference: %w", err)
+	return s.setMetadataRef(ref, newCommitHash)
+}
+
+// updatePromptsCount records a new prompts count for a session in both the
+// session metadata and the root summary's contribution record, so the two stay
+// consistent when prompts are replaced after the initial write.
+func (s *GitStore) updatePromptsCount(basePath string, sessionIndex, count int, checkpointSummary *CheckpointSummary, entries map[string]object.TreeEntry) error {
+	sessionMetadataPath := fmt.Sprintf("%s%d/%s", basePath, sessionIndex, paths.MetadataFileName)
+	sessionEntry, exists := entries[sessionMetadataPath]
+	if !exists {
+		return fmt.Errorf("session metadata not found at %s", sessionMetadataPath)
+	}
+	meta, err := s.readMetadataFromBlob(sessionEntry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read session metadata: %w", err)
+	}
+	if meta.PromptsCount == count {
+		return nil
+	}
+	meta.PromptsCount = count
+	if err := s.writeJSONEntry(sessionMetadataPath, meta, entries); err != nil {
+		return err
 	}
 
+	// Older checkpoints have no contribution records; leave them untouched.
+	if sessionIndex >= len(checkpointSummary.Contributions) {
+		return nil
+	}
+	checkpointSummary.Contributions[sessionIndex].PromptsCount = count
+	return s.writeJSONEntry(basePath+paths.MetadataFileName, checkpointSummary, entries)
+}
+
+// updateTranscriptFilter records the filter stats of a replaced transcript in
+// the session's metadata.json. A nil stats clears stale stats from an earlier
+// filtered write.
+func (s *GitStore) updateTranscriptFilter(sessionPath string, stats *transcript.FilterStats, entries map[string]object.TreeEntry) error {
+	sessionMetadataPath := sessionPath + paths.MetadataFileName
+	sessionEntry, exists := entries[sessionMetadataPath]
+	if !exists {
+		return fmt.Errorf("session metadata not found at %s", sessionMetadataPath)
+	}
+	meta, err := s.readMetadataFromBlob(sessionEntry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read session metadata: %w", err)
+	}
+	if meta.TranscriptFilter == nil && stats == nil {
+		return nil
+	}
+	meta.TranscriptFilter = stats
+	return s.writeJSONEntry(sessionMetadataPath, meta, entries)
+}
+
+// writeJSONEntry marshals v as indented JSON and stores it at path in entries.
+func (s *GitStore) writeJSONEntry(path string, v any, entries map[string]object.TreeEntry) error {
+	data, err := jsonutil.MarshalCanonical(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	hash, err := CreateBlobFromContent(s.repo, data)
+	if err != nil {
+		return fmt.Errorf("failed to create blob for %s: %w", path, err)
+	}
+	entries[path] = object.TreeEntry{
+		Name: path,
+		Mode: filemode.Regular,
+		Hash: hash,
+	}
 	return nil
 }
 
 // replaceTranscript writes the full transcript content, replacing any existing transcript.
 // Also removes any chunk files from a previous write and updates the content hash.
-func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentType, sessionPath string, entries map[string]object.TreeEntry) error {
+func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentType, sessionPath string, compress, contentDefined bool, entries map[string]object.TreeEntry) error {
 	// Remove existing transcript files (base + any chunks)
 	transcriptBase := sessionPath + paths.TranscriptFileName
 	for key := range entries {
@@ -1205,23 +1526,8 @@ func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentTyp
 	}
 
 	// Chunk the transcript (matches writeTranscript behavior)
-	chunks, err := agent.ChunkTranscript(transcript, agentType)
-	if err != nil {
-		return fmt.Errorf("failed to chunk transcript: %w", err)
-	}
-
-	// Write chunk files
-	for i, chunk := range chunks {
-		chunkPath := sessionPath + agent.ChunkFileName(paths.TranscriptFileName, i)
-		blobHash, err := CreateBlobFromContent(s.repo, chunk)
-		if err != nil {
-			return fmt.Errorf("failed to create transcript blob: %w", err)
-		}
-		entries[chunkPath] = object.TreeEntry{
-			Name: chunkPath,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
+	if err := s.writeTranscriptChunks(transcript, agentType, sessionPath, compress, contentDefined, entries); err != nil {
+		return err
 	}
 
 	// Update content hash
@@ -1242,11 +1548,15 @@ func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentTyp
 
 // ensureSessionsBranch ensures the entire/checkpoints/v1 branch exists.
 func (s *GitStore) ensureSessionsBranch() error {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	_, err := s.repo.Reference(refName, true)
 	if err == nil {
 		return nil // Branch exists
 	}
+	if s.refBeingWritten(refName) {
+		// Another process is rewriting the branch; creating it would discard its history.
+		return fmt.Errorf("sessions branch is being updated: %w", storage.ErrReferenceHasChanged)
+	}
 
 	// Create orphan branch with empty tree
 	emptyTreeHash, err := BuildTreeFromEntries(s.repo, make(map[string]object.TreeEntry))
@@ -1261,7 +1571,7 @@ func (s *GitStore) ensureSessionsBranch() error {
 	}
 
 	newRef := plumbing.NewHashReference(refName, commitHash)
-	if err := s.repo.Storer.SetReference(newRef); err != nil {
+	if err := audit.SetReference(s.repo, newRef); err != nil {
 		return fmt.Errorf("failed to set branch reference: %w", err)
 	}
 	return nil
@@ -1270,11 +1580,11 @@ func (s *GitStore) ensureSessionsBranch() error {
 // getSessionsBranchTree returns the tree object for the entire/checkpoints/v1 branch.
 // Falls back to origin/entire/checkpoints/v1 if the local branch doesn't exist.
 func (s *GitStore) getSessionsBranchTree() (*object.Tree, error) {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := s.repo.Reference(refName, true)
 	if err != nil {
 		// Local branch doesn't exist, try remote-tracking branch
-		remoteRefName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
+		remoteRefName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName())
 		ref, err = s.repo.Reference(remoteRefName, true)
 		if err != nil {
 			return nil, fmt.Errorf("sessions branch not found: %w", err)
@@ -1466,73 +1776,59 @@ func GetGitAuthorFromRepo(repo *git.Repository) (name, email string) {
 }
 
 // readTranscriptFromTree reads a transcript from a git tree, handling both chunked and non-chunked formats.
-// It checks for chunk files first (.001, .002, etc.), then falls back to the base file.
+// It collects the base file (full.jsonl) as chunk 0 and numbered chunk files (.001, .002, etc.),
+// decompressing any stored gzipped (.gz), then falls back to the legacy full.log.
 // The agentType is used for reassembling chunks in the correct format.
 func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byte, error) {
-	// Collect all transcript-related files
-	var chunkFiles []string
-	var hasBaseFile bool
-
+	type chunkFile struct {
+		name       string
+		index      int
+		compressed bool
+	}
+	var chunkFiles []chunkFile
 	for _, entry := range tree.Entries {
-		if entry.Name == paths.TranscriptFileName || entry.Name == paths.TranscriptFileNameLegacy {
-			hasBaseFile = true
-		}
-		// Check for chunk files (full.jsonl.001, full.jsonl.002, etc.)
-		if strings.HasPrefix(entry.Name, paths.TranscriptFileName+".") {
-			idx := agent.ParseChunkIndex(entry.Name, paths.TranscriptFileName)
-			if idx > 0 {
-				chunkFiles = append(chunkFiles, entry.Name)
-			}
+		if idx, compressed := transcriptChunkIndex(entry.Name); idx >= 0 {
+			chunkFiles = append(chunkFiles, chunkFile{name: entry.Name, index: idx, compressed: compressed})
 		}
 	}
+	sort.Slice(chunkFiles, func(i, j int) bool { return chunkFiles[i].index < chunkFiles[j].index })
 
-	// If we have chunk files, read and reassemble them
-	if len(chunkFiles) > 0 {
-		// Sort chunk files by index
-		chunkFiles = agent.SortChunkFiles(chunkFiles, paths.TranscriptFileName)
-
-		// Check if base file should be included as chunk 0.
-		// NOTE: This assumes the chunking convention where the unsuffixed file
-		// (full.jsonl) is chunk 0, and numbered files (.001, .002) are chunks 1+.
-		if hasBaseFile {
-			chunkFiles = append([]string{paths.TranscriptFileName}, chunkFiles...)
+	var chunks [][]byte
+	for _, chunkFile := range chunkFiles {
+		file, err := tree.File(chunkFile.name)
+		if err != nil {
+			logging.Warn(context.Background(), "failed to read transcript chunk file from tree",
+				slog.String("chunk_file", chunkFile.name),
+				slog.String("error", err.Error()),
+			)
+			continue
 		}
-
-		var chunks [][]byte
-		for _, chunkFile := range chunkFiles {
-			file, err := tree.File(chunkFile)
-			if err != nil {
-				logging.Warn(context.Background(), "failed to read transcript chunk file from tree",
-					slog.String("chunk_file", chunkFile),
-					slog.String("error", err.Error()),
-				)
-				continue
-			}
-			content, err := file.Contents()
-			if err != nil {
-				logging.Warn(context.Background(), "failed to read transcript chunk contents",
-					slog.String("chunk_file", chunkFile),
-					slog.String("error", err.Error()),
-				)
-				continue
-			}
-			chunks = append(chunks, []byte(content))
+		content, err := file.Contents()
+		if err != nil {
+			logging.Warn(context.Background(), "failed to read transcript chunk contents",
+				slog.String("chunk_file", chunkFile.name),
+				slog.String("error", err.Error()),
+			)
+			continue
 		}
-
-		if len(chunks) > 0 {
-			result, err := agent.ReassembleTranscript(chunks, agentType)
-			if err != nil {
-				return nil, fmt.Errorf("failed to reassemble transcript: %w", err)
+		chunk := []byte(content)
+		if chunkFile.compressed {
+			if chunk, err = gunzipBytes(chunk); err != nil {
+				return nil, fmt.Errorf("failed to decompress transcript chunk %s: %w", chunkFile.name, err)
 			}
-			return result, nil
 		}
+		chunks = append(chunks, chunk)
 	}
 
-	// Fall back to reading base file (non-chunked or backwards compatibility)
-	if file, err := tree.File(paths.TranscriptFileName); err == nil {
-		if content, err := file.Contents(); err == nil {
-			return []byte(content), nil
+	switch {
+	case len(chunks) == 1:
+		return chunks[0], nil
+	case len(chunks) > 1:
+		result, err := agent.ReassembleTranscript(chunks, agentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reassemble transcript: %w", err)
 		}
+		return result, nil
 	}
 
 	// Try legacy filename
@@ -1557,7 +1853,7 @@ type Author struct {
 func (s *GitStore) GetCheckpointAuthor(ctx context.Context, checkpointID id.CheckpointID) (Author, error) {
 	_ = ctx // Reserved for future use
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := s.repo.Reference(refName, true)
 	if err != nil {
 		return Author{}, nil
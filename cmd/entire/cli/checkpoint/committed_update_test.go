@@ -9,6 +9,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
@@ -114,6 +115,80 @@ func TestUpdateCommitted_ReplacesPrompts(t *testing.T) {
 	}
 }
 
+func TestUpdateCommitted_UpdatesPromptsCount(t *testing.T) {
+	t.Parallel()
+	_, store, cpID := setupRepoForUpdate(t)
+
+	err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Prompts:      []string{"prompt 1", "prompt 2", "prompt 3"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.PromptsCount != 3 {
+		t.Errorf("session PromptsCount = %d, want 3", content.Metadata.PromptsCount)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), cpID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if len(summary.Contributions) != 1 {
+		t.Fatalf("len(summary.Contributions) = %d, want 1", len(summary.Contributions))
+	}
+	if summary.Contributions[0].PromptsCount != 3 {
+		t.Errorf("contribution PromptsCount = %d, want 3", summary.Contributions[0].PromptsCount)
+	}
+}
+
+func TestUpdateCommitted_RecordsTranscriptFilter(t *testing.T) {
+	t.Parallel()
+	_, store, cpID := setupRepoForUpdate(t)
+
+	stats := &transcript.FilterStats{OriginalBytes: 4096, OriginalLines: 12, DroppedLines: 5}
+	err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        "session-001",
+		Transcript:       []byte("filtered transcript\n"),
+		TranscriptFilter: stats,
+	})
+	if err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if got := content.Metadata.TranscriptFilter; got == nil || *got != *stats {
+		t.Errorf("TranscriptFilter = %+v, want %+v", got, stats)
+	}
+
+	// An unfiltered replacement clears the stale stats.
+	err = store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("unfiltered transcript\n"),
+	})
+	if err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	content, err = store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Metadata.TranscriptFilter != nil {
+		t.Errorf("TranscriptFilter = %+v, want nil", content.Metadata.TranscriptFilter)
+	}
+}
+
 func TestUpdateCommitted_ReplacesContext(t *testing.T) {
 	t.Parallel()
 	_, store, cpID := setupRepoForUpdate(t)
@@ -279,7 +354,7 @@ func TestUpdateCommitted_UpdatesContentHash(t *testing.T) {
 	}
 
 	// Verify content_hash.txt was updated
-	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get ref: %v", err)
 	}
@@ -536,7 +611,7 @@ func TestUpdateCommitted_UsesCorrectAuthor(t *testing.T) {
 			}
 
 			// Read the latest commit on entire/checkpoints/v1 and verify author
-			ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+			ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 			if err != nil {
 				t.Fatalf("failed to get sessions branch ref: %v", err)
 			}
@@ -0,0 +1,276 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+// EraseSessionResult describes what EraseSession changed.
+type EraseSessionResult struct {
+	// Checkpoints are the checkpoints that stored the session, sorted.
+	Checkpoints []id.CheckpointID
+	// RewrittenCommits is the number of metadata branch commits rewritten.
+	RewrittenCommits int
+	// OldTip and NewTip are the metadata branch tips before and after. Both
+	// are zero when the session was not found.
+	OldTip plumbing.Hash
+	NewTip plumbing.Hash
+}
+
+// EraseSession removes a session's transcript, prompts, context, and summary
+// from every commit on the metadata branch, rewriting the branch history.
+// Each of the session's directories is replaced by a metadata.json without
+// the summary and with ErasedAt set, so checkpoint summaries that list the
+// session stay readable. Commits that never stored the session keep their
+// hashes.
+//
+// The erased content stays reachable from any other ref into the old
+// history, such as origin/entire/checkpoints/v1, until that ref is updated.
+func (s *GitStore) EraseSession(ctx context.Context, sessionID string) (EraseSessionResult, error) {
+	_ = ctx // Reserved for future use
+
+	var result EraseSessionResult
+	err := s.retryMetadataUpdate(func() error {
+		var err error
+		result, err = s.eraseSession(sessionID)
+		return err
+	})
+	return result, err
+}
+
+// eraseSession applies one EraseSession attempt on the current branch tip.
+func (s *GitStore) eraseSession(sessionID string) (EraseSessionResult, error) {
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
+	ref, err := s.repo.Reference(refName, true)
+	if err != nil {
+		if s.refBeingWritten(refName) {
+			return EraseSessionResult{}, fmt.Errorf("sessions branch is being updated: %w", storage.ErrReferenceHasChanged)
+		}
+		return EraseSessionResult{}, nil // No metadata branch, nothing stored
+	}
+
+	e := &sessionEraser{
+		store:       s,
+		sessionID:   sessionID,
+		erasedAt:    s.now().UTC(),
+		trees:       make(map[plumbing.Hash]plumbing.Hash),
+		commits:     make(map[plumbing.Hash]plumbing.Hash),
+		checkpoints: make(map[id.CheckpointID]bool),
+	}
+	newTip, err := e.rewriteHistory(ref.Hash())
+	if err != nil {
+		return EraseSessionResult{}, err
+	}
+	if newTip == ref.Hash() {
+		return EraseSessionResult{}, nil
+	}
+
+	if err := s.setMetadataRef(ref, newTip); err != nil {
+		return EraseSessionResult{}, err
+	}
+
+	result := EraseSessionResult{
+		RewrittenCommits: e.rewritten,
+		OldTip:           ref.Hash(),
+		NewTip:           newTip,
+	}
+	for cpID := range e.checkpoints {
+		result.Checkpoints = append(result.Checkpoints, cpID)
+	}
+	slices.Sort(result.Checkpoints)
+	return result, nil
+}
+
+// sessionEraser rewrites metadata branch commits without one session's
+// content. Rewritten trees and commits are memoized by their original hash,
+// so content shared between commits is only processed once.
+type sessionEraser struct {
+	store       *GitStore
+	sessionID   string
+	erasedAt    time.Time
+	trees       map[plumbing.Hash]plumbing.Hash
+	commits     map[plumbing.Hash]plumbing.Hash
+	checkpoints map[id.CheckpointID]bool
+	rewritten   int
+}
+
+// rewriteHistory rewrites every commit reachable from tip, parents before
+// children, and returns the rewritten tip.
+func (e *sessionEraser) rewriteHistory(tip plumbing.Hash) (plumbing.Hash, error) {
+	stack := []plumbing.Hash{tip}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		if _, done := e.commits[hash]; done {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		commit, err := e.store.repo.CommitObject(hash)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		pending := false
+		for _, parent := range commit.ParentHashes {
+			if _, done := e.commits[parent]; !done {
+				stack = append(stack, parent)
+				pending = true
+			}
+		}
+		if pending {
+			continue
+		}
+		stack = stack[:len(stack)-1]
+
+		newHash, err := e.rewriteCommit(commit)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		e.commits[hash] = newHash
+	}
+	return e.commits[tip], nil
+}
+
+// rewriteCommit returns commit's hash if neither its tree nor its parents
+// changed, and otherwise stores a copy with the rewritten tree and parents.
+func (e *sessionEraser) rewriteCommit(commit *object.Commit) (plumbing.Hash, error) {
+	treeHash, err := e.rewriteTree(commit.TreeHash)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	changed := treeHash != commit.TreeHash
+	parents := make([]plumbing.Hash, len(commit.ParentHashes))
+	for i, parent := range commit.ParentHashes {
+		parents[i] = e.commits[parent]
+		changed = changed || parents[i] != parent
+	}
+	if !changed {
+		return commit.Hash, nil
+	}
+
+	rewritten := &object.Commit{
+		Author:       commit.Author,
+		Committer:    commit.Committer,
+		Message:      commit.Message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+	}
+	obj := e.store.repo.Storer.NewEncodedObject()
+	if err := rewritten.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	hash, err := e.store.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+	}
+	e.rewritten++
+	return hash, nil
+}
+
+// rewriteTree returns the hash of the tree with the session's directories
+// erased, or hash itself if the tree never contained the session.
+func (e *sessionEraser) rewriteTree(hash plumbing.Hash) (plumbing.Hash, error) {
+	if rewritten, ok := e.trees[hash]; ok {
+		return rewritten, nil
+	}
+	tree, err := e.store.repo.TreeObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree %s: %w", hash, err)
+	}
+
+	var rewritten plumbing.Hash
+	if metadata, ok := e.sessionMetadata(tree); ok {
+		rewritten, err = e.erasedSessionTree(metadata)
+	} else {
+		rewritten, err = e.rewriteSubtrees(tree)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	e.trees[hash] = rewritten
+	return rewritten, nil
+}
+
+// rewriteSubtrees rewrites the directories in tree, storing a new tree only
+// if one of them changed.
+func (e *sessionEraser) rewriteSubtrees(tree *object.Tree) (plumbing.Hash, error) {
+	entries := slices.Clone(tree.Entries)
+	changed := false
+	for i, entry := range entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+		subHash, err := e.rewriteTree(entry.Hash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if subHash != entry.Hash {
+			entries[i].Hash = subHash
+			changed = true
+		}
+	}
+	if !changed {
+		return tree.Hash, nil
+	}
+
+	// Names are unchanged, so the entries are still in git's sort order
+	obj := e.store.repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+	hash, err := e.store.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store tree: %w", err)
+	}
+	return hash, nil
+}
+
+// sessionMetadata returns the session metadata of tree if tree is a session
+// directory of the session being erased.
+func (e *sessionEraser) sessionMetadata(tree *object.Tree) (*CommittedMetadata, bool) {
+	entry, err := tree.FindEntry(paths.MetadataFileName)
+	if err != nil || !entry.Mode.IsFile() {
+		return nil, false
+	}
+	metadata, err := e.store.readMetadataFromBlob(entry.Hash)
+	if err != nil || metadata.SessionID != e.sessionID {
+		return nil, false
+	}
+	return metadata, true
+}
+
+// erasedSessionTree stores a session directory holding only metadata.json,
+// with the summary and compliance scan removed and ErasedAt set. An already
+// erased session keeps its original ErasedAt.
+func (e *sessionEraser) erasedSessionTree(metadata *CommittedMetadata) (plumbing.Hash, error) {
+	e.checkpoints[metadata.CheckpointID] = true
+
+	metadata.Summary = nil
+	metadata.ComplianceScan = nil
+	metadata.TranscriptIdentifierAtStart = ""
+	if metadata.ErasedAt == nil {
+		erasedAt := e.erasedAt
+		metadata.ErasedAt = &erasedAt
+	}
+
+	metadataJSON, err := jsonutil.MarshalCanonical(metadata)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	metadataHash, err := CreateBlobFromContent(e.store.repo, metadataJSON)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return BuildTreeFromEntries(e.store.repo, map[string]object.TreeEntry{
+		paths.MetadataFileName: {Name: paths.MetadataFileName, Mode: filemode.Regular, Hash: metadataHash},
+	})
+}
@@ -0,0 +1,146 @@
+package checkpoint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func metadataBranchTip(t *testing.T, repo *git.Repository) plumbing.Hash {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	if err != nil {
+		t.Fatalf("metadata branch not found: %v", err)
+	}
+	return ref.Hash()
+}
+
+func TestEraseSession_RemovesContentFromHistory(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	ctx := context.Background()
+
+	// A later commit replaces the transcript, so the original one only
+	// survives in history.
+	if err := store.UpdateCommitted(ctx, UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("final transcript line 1\n"),
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	if err := writeTestCheckpoint(t, store, cpID, "session-002"); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	otherID := id.MustCheckpointID("b1b2c3d4e5f6")
+	if err := writeTestCheckpoint(t, store, otherID, "session-002"); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	oldTip := metadataBranchTip(t, repo)
+
+	result, err := store.EraseSession(ctx, "session-001")
+	if err != nil {
+		t.Fatalf("EraseSession() error = %v", err)
+	}
+	if len(result.Checkpoints) != 1 || result.Checkpoints[0] != cpID {
+		t.Errorf("Checkpoints = %v, want [%s]", result.Checkpoints, cpID)
+	}
+	if result.OldTip != oldTip || result.NewTip != metadataBranchTip(t, repo) {
+		t.Errorf("tips = %s -> %s, want %s -> branch tip", result.OldTip, result.NewTip, oldTip)
+	}
+	if result.RewrittenCommits != 4 {
+		t.Errorf("RewrittenCommits = %d, want 4", result.RewrittenCommits)
+	}
+
+	erased, err := store.ReadSessionContent(ctx, cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if erased.Transcript != nil || erased.Prompts != "" || erased.Context != "" {
+		t.Errorf("erased session still has content: %+v", erased)
+	}
+	if erased.Metadata.SessionID != "session-001" || erased.Metadata.ErasedAt == nil {
+		t.Errorf("erased metadata = %+v, want session-001 with ErasedAt", erased.Metadata)
+	}
+
+	kept, err := store.ReadSessionContent(ctx, cpID, 1)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(kept.Transcript) != "session-002 transcript\n" {
+		t.Errorf("other session transcript = %q", kept.Transcript)
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: result.NewTip})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	commits := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		commits++
+		tree, treeErr := c.Tree()
+		if treeErr != nil {
+			return treeErr
+		}
+		return tree.Files().ForEach(func(f *object.File) error {
+			content, contentErr := f.Contents()
+			if contentErr != nil {
+				return contentErr
+			}
+			for _, erasedText := range []string{"provisional transcript", "final transcript", "initial prompt", "initial context"} {
+				if strings.Contains(content, erasedText) {
+					t.Errorf("commit %s still stores %q in %s", c.Hash, erasedText, f.Name)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("walking history: %v", err)
+	}
+	if commits != 5 {
+		t.Errorf("history has %d commits, want 5", commits)
+	}
+}
+
+func TestEraseSession_UnknownSessionLeavesBranchUntouched(t *testing.T) {
+	t.Parallel()
+	repo, store, _ := setupRepoForUpdate(t)
+	oldTip := metadataBranchTip(t, repo)
+
+	result, err := store.EraseSession(context.Background(), "session-unknown")
+	if err != nil {
+		t.Fatalf("EraseSession() error = %v", err)
+	}
+	if len(result.Checkpoints) != 0 || !result.NewTip.IsZero() {
+		t.Errorf("result = %+v, want nothing erased", result)
+	}
+	if tip := metadataBranchTip(t, repo); tip != oldTip {
+		t.Errorf("branch moved from %s to %s", oldTip, tip)
+	}
+}
+
+func TestEraseSession_IsIdempotent(t *testing.T) {
+	t.Parallel()
+	repo, store, _ := setupRepoForUpdate(t)
+
+	if _, err := store.EraseSession(context.Background(), "session-001"); err != nil {
+		t.Fatalf("EraseSession() error = %v", err)
+	}
+	tip := metadataBranchTip(t, repo)
+
+	result, err := store.EraseSession(context.Background(), "session-001")
+	if err != nil {
+		t.Fatalf("second EraseSession() error = %v", err)
+	}
+	if len(result.Checkpoints) != 0 || metadataBranchTip(t, repo) != tip {
+		t.Errorf("second erase rewrote history: %+v", result)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sync"
 )
 
 // CheckpointID is a 12-character hex identifier for checkpoints.
@@ -40,7 +41,9 @@ func NewCheckpointID(s string) (CheckpointID, error) {
 }
 
 // MustCheckpointID creates a CheckpointID from a string, panicking if invalid.
-// Use only when the ID is known to be valid (e.g., from trusted sources).
+// Use only in tests and for compile-time constants. IDs read from commit
+// messages, metadata, or user input must go through NewCheckpointID, since
+// malformed values (e.g. hand-edited trailers) would otherwise crash a hook.
 func MustCheckpointID(s string) CheckpointID {
 	id, err := NewCheckpointID(s)
 	if err != nil {
@@ -58,6 +61,43 @@ func Generate() (CheckpointID, error) {
 	return CheckpointID(hex.EncodeToString(bytes)), nil
 }
 
+// Generator creates checkpoint IDs. Strategies take a Generator instead of
+// calling Generate directly, so tests and replay tooling can make IDs
+// reproducible.
+type Generator interface {
+	Generate() (CheckpointID, error)
+}
+
+// Random is the Generator backed by Generate.
+var Random Generator = randomGenerator{}
+
+type randomGenerator struct{}
+
+func (randomGenerator) Generate() (CheckpointID, error) { return Generate() }
+
+// OrRandom returns g, or Random if g is nil.
+func OrRandom(g Generator) Generator {
+	if g == nil {
+		return Random
+	}
+	return g
+}
+
+// SequenceGenerator yields 000000000001, 000000000002, ... in order.
+// It is safe for concurrent use.
+type SequenceGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// Generate returns the next ID in the sequence.
+func (g *SequenceGenerator) Generate() (CheckpointID, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.next++
+	return CheckpointID(fmt.Sprintf("%012x", g.next)), nil
+}
+
 // Validate checks if a string is a valid checkpoint ID format.
 // Returns an error if invalid, nil if valid.
 func Validate(s string) error {
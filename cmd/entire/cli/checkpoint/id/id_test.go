@@ -120,3 +120,33 @@ func TestCheckpointID_Path(t *testing.T) {
 		})
 	}
 }
+
+func TestSequenceGenerator(t *testing.T) {
+	t.Parallel()
+
+	var g SequenceGenerator
+	for _, want := range []CheckpointID{"000000000001", "000000000002", "000000000003"} {
+		got, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Generate() = %q, want %q", got, want)
+		}
+		if err := Validate(got.String()); err != nil {
+			t.Errorf("Generate() produced invalid ID: %v", err)
+		}
+	}
+}
+
+func TestOrRandom(t *testing.T) {
+	t.Parallel()
+
+	if OrRandom(nil) != Random {
+		t.Error("OrRandom(nil) should return Random")
+	}
+	g := &SequenceGenerator{}
+	if OrRandom(g) != g {
+		t.Error("OrRandom(g) should return g")
+	}
+}
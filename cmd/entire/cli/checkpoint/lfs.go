@@ -0,0 +1,268 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Git LFS support. Paths with the "lfs" filter attribute are committed as
+// small pointer files while their content lives in the LFS object store, so
+// shadow checkpoints store the pointer too. Content is written to the local
+// object store the way `git lfs clean` would, so a rewind can smudge it back.
+
+const (
+	// lfsPointerVersion is the first line of every Git LFS pointer file.
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+	// lfsMaxPointerSize is the largest file git-lfs parses as a pointer.
+	lfsMaxPointerSize = 1024
+)
+
+// LFSPointer identifies a Git LFS object.
+type LFSPointer struct {
+	// OID is the hex SHA-256 of the content
+	OID string
+
+	// Size is the content size in bytes
+	Size int64
+}
+
+// Bytes returns the pointer file content.
+func (p LFSPointer) Bytes() []byte {
+	return []byte(fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", lfsPointerVersion, p.OID, p.Size))
+}
+
+// ParseLFSPointer reports whether data is a Git LFS pointer file and returns
+// the object it points to.
+func ParseLFSPointer(data []byte) (LFSPointer, bool) {
+	if len(data) > lfsMaxPointerSize || !bytes.HasPrefix(data, []byte(lfsPointerVersion+"\n")) {
+		return LFSPointer{}, false
+	}
+	var p LFSPointer
+	hasSize := false
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n")[1:] {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || len(oid) != sha256.Size*2 || oid != strings.ToLower(oid) {
+				return LFSPointer{}, false
+			}
+			if _, err := hex.DecodeString(oid); err != nil {
+				return LFSPointer{}, false
+			}
+			p.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return LFSPointer{}, false
+			}
+			p.Size, hasSize = size, true
+		}
+	}
+	return p, p.OID != "" && hasSize
+}
+
+// LFSTrackedPaths returns the files among files that have the "lfs" filter
+// attribute in the repository at repoRoot. Repositories without LFS, and
+// errors running git, give an empty set. git is only asked when an attributes
+// file that applies to files mentions the lfs filter.
+func LFSTrackedPaths(repoRoot string, files []string) map[string]bool {
+	if len(files) == 0 || !lfsFilterConfigured(repoRoot, files) {
+		return nil
+	}
+	cmd := exec.CommandContext(context.Background(), "git", "check-attr", "--stdin", "-z", "filter")
+	cmd.Dir = repoRoot
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\x00") + "\x00")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	// Output is NUL-separated <path> <attribute> <value> triples
+	fields := strings.Split(string(output), "\x00")
+	tracked := make(map[string]bool)
+	for i := 0; i+2 < len(fields); i += 3 {
+		if fields[i+2] == "lfs" {
+			tracked[fields[i]] = true
+		}
+	}
+	return tracked
+}
+
+// lfsFilterConfigured reports whether any .gitattributes file between
+// repoRoot and the directories of files, or the repository's
+// info/attributes, mentions filter=lfs. Attributes from core.attributesFile
+// are not checked; git lfs track only writes .gitattributes.
+func lfsFilterConfigured(repoRoot string, files []string) bool {
+	candidates := []string{filepath.Join(repoRoot, ".gitattributes")}
+	if commonDir, err := gitCommonDir(repoRoot); err == nil {
+		// Linked worktrees share the main repository's info/attributes
+		candidates = append(candidates, filepath.Join(commonDir, "info", "attributes"))
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		for dir := filepath.Dir(filepath.FromSlash(file)); dir != "." && !seen[dir]; dir = filepath.Dir(dir) {
+			seen[dir] = true
+			candidates = append(candidates, filepath.Join(repoRoot, dir, ".gitattributes"))
+		}
+	}
+	for _, path := range candidates {
+		data, err := os.ReadFile(path) //nolint:gosec // attributes files inside the repository
+		if err == nil && bytes.Contains(data, []byte("filter=lfs")) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanLFSFile returns the pointer for the file at absPath. A file that is
+// already a pointer (content not downloaded) is returned as-is; otherwise its
+// content is copied into the LFS object store of the repository at repoRoot.
+func cleanLFSFile(repoRoot, absPath string) (LFSPointer, error) {
+	f, err := os.Open(absPath) //nolint:gosec // absPath is a repository file reported by the agent
+	if err != nil {
+		return LFSPointer{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	head := make([]byte, lfsMaxPointerSize+1)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return LFSPointer{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	if p, ok := ParseLFSPointer(head[:n]); ok {
+		return p, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return LFSPointer{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	objectsDir, err := lfsObjectsDir(repoRoot)
+	if err != nil {
+		return LFSPointer{}, err
+	}
+	tmpDir := filepath.Join(filepath.Dir(objectsDir), "tmp")
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil { //nolint:gosec // matches the permissions git-lfs uses
+		return LFSPointer{}, fmt.Errorf("failed to create LFS tmp dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(tmpDir, "entire-clean-")
+	if err != nil {
+		return LFSPointer{}, fmt.Errorf("failed to create LFS tmp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), f)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return LFSPointer{}, fmt.Errorf("failed to copy file to LFS store: %w", err)
+	}
+
+	p := LFSPointer{OID: hex.EncodeToString(hasher.Sum(nil)), Size: size}
+	objectPath := lfsObjectPath(objectsDir, p.OID)
+	if _, err := os.Stat(objectPath); err == nil {
+		return p, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0o755); err != nil { //nolint:gosec // matches the permissions git-lfs uses
+		return LFSPointer{}, fmt.Errorf("failed to create LFS object dir: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), objectPath); err != nil {
+		return LFSPointer{}, fmt.Errorf("failed to store LFS object: %w", err)
+	}
+	return p, nil
+}
+
+// SmudgeLFSPointer returns the content data points to when data is a Git LFS
+// pointer whose object is in the local store of the repository at repoRoot.
+// Otherwise it returns data unchanged and false; the pointer can then be
+// smudged later with `git lfs pull`.
+func SmudgeLFSPointer(repoRoot string, data []byte) ([]byte, bool) {
+	p, ok := ParseLFSPointer(data)
+	if !ok {
+		return data, false
+	}
+	objectsDir, err := lfsObjectsDir(repoRoot)
+	if err != nil {
+		return data, false
+	}
+	content, err := os.ReadFile(lfsObjectPath(objectsDir, p.OID)) //nolint:gosec // path is derived from a validated hex OID
+	if err != nil || int64(len(content)) != p.Size {
+		return data, false
+	}
+	return content, true
+}
+
+// lfsObjectsDir returns the LFS object store of the repository at repoRoot,
+// which is shared by all of its worktrees.
+func lfsObjectsDir(repoRoot string) (string, error) {
+	commonDir, err := gitCommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "lfs", "objects"), nil
+}
+
+// gitCommonDir returns the git directory shared by all worktrees of the
+// repository at repoRoot. It reads the worktree's .git file and the
+// "commondir" file it points to, and only asks git when .git is missing.
+func gitCommonDir(repoRoot string) (string, error) {
+	gitPath := filepath.Join(repoRoot, ".git")
+	if info, err := os.Stat(gitPath); err == nil {
+		if info.IsDir() {
+			return gitPath, nil
+		}
+		// A linked worktree's .git file holds "gitdir: <dir>", and <dir>
+		// holds the path to the common directory in its commondir file.
+		if content, err := os.ReadFile(gitPath); err == nil { //nolint:gosec // gitPath is repoRoot + ".git"
+			if gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: "); ok {
+				if !filepath.IsAbs(gitDir) {
+					gitDir = filepath.Join(repoRoot, gitDir)
+				}
+				return resolveCommonDir(gitDir), nil
+			}
+		}
+	}
+
+	cmd := exec.CommandContext(context.Background(), "git", "rev-parse", "--git-common-dir")
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git common dir: %w", err)
+	}
+	commonDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(repoRoot, commonDir)
+	}
+	return commonDir, nil
+}
+
+// resolveCommonDir resolves a git directory to the common directory named by
+// its commondir file. A directory without one is its own common directory.
+func resolveCommonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir")) //nolint:gosec // path is within the git directory
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// lfsObjectPath returns where git-lfs stores the object with the given OID.
+func lfsObjectPath(objectsDir, oid string) string {
+	return filepath.Join(objectsDir, oid[0:2], oid[2:4], oid)
+}
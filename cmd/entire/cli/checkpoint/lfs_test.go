@@ -0,0 +1,186 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestParseLFSPointer(t *testing.T) {
+	t.Parallel()
+	oid := strings.Repeat("ab", 32)
+	valid := LFSPointer{OID: oid, Size: 1234}
+
+	if got, ok := ParseLFSPointer(valid.Bytes()); !ok || got != valid {
+		t.Errorf("ParseLFSPointer(Bytes()) = %+v, %v, want %+v, true", got, ok, valid)
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "plain text", data: "hello\n"},
+		{name: "missing size", data: lfsPointerVersion + "\noid sha256:" + oid + "\n"},
+		{name: "short oid", data: lfsPointerVersion + "\noid sha256:abcd\nsize 1\n"},
+		{name: "non-hex oid", data: lfsPointerVersion + "\noid sha256:" + strings.Repeat("zz", 32) + "\nsize 1\n"},
+		{name: "other hash", data: lfsPointerVersion + "\noid md5:" + oid + "\nsize 1\n"},
+		{name: "too large", data: string(valid.Bytes()) + strings.Repeat("x", lfsMaxPointerSize)},
+	}
+	for _, tt := range tests {
+		if _, ok := ParseLFSPointer([]byte(tt.data)); ok {
+			t.Errorf("ParseLFSPointer(%s) = true, want false", tt.name)
+		}
+	}
+}
+
+// TestWriteTemporary_LFSPointers verifies that LFS-tracked files are stored
+// as pointers, with their content moved into the local LFS object store so
+// it can be smudged back.
+func TestWriteTemporary_LFSPointers(t *testing.T) {
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .gitattributes: %v", err)
+	}
+	if _, err := worktree.Add(".gitattributes"); err != nil {
+		t.Fatalf("failed to add .gitattributes: %v", err)
+	}
+	initialCommit, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	t.Chdir(tempDir)
+
+	content := bytes.Repeat([]byte("model weights\x00"), 100)
+	sum := sha256.Sum256(content)
+	wantPointer := LFSPointer{OID: hex.EncodeToString(sum[:]), Size: int64(len(content))}
+	// A pointer whose object was never downloaded is stored as it is
+	pending := LFSPointer{OID: strings.Repeat("cd", 32), Size: 99}
+	for name, data := range map[string][]byte{"model.bin": content, "pending.bin": pending.Bytes(), "main.go": []byte("package main\n")} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), data, 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := NewGitStore(repo).WriteTemporary(context.Background(), WriteTemporaryOptions{
+		SessionID:     "test-session",
+		BaseCommit:    initialCommit.String(),
+		NewFiles:      []string{"model.bin", "pending.bin", "main.go"},
+		CommitMessage: "Checkpoint 1",
+		AuthorName:    "Test",
+		AuthorEmail:   "test@test.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteTemporary() error = %v", err)
+	}
+	commit, err := repo.CommitObject(result.CommitHash)
+	if err != nil {
+		t.Fatalf("failed to read checkpoint commit: %v", err)
+	}
+	fileContents := func(name string) string {
+		t.Helper()
+		f, err := commit.File(name)
+		if err != nil {
+			t.Fatalf("checkpoint is missing %s: %v", name, err)
+		}
+		data, err := f.Contents()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return data
+	}
+
+	if got := fileContents("model.bin"); got != string(wantPointer.Bytes()) {
+		t.Errorf("model.bin stored as %q, want its LFS pointer", got)
+	}
+	if got := fileContents("pending.bin"); got != string(pending.Bytes()) {
+		t.Errorf("pending.bin stored as %q, want the pointer unchanged", got)
+	}
+	if got := fileContents("main.go"); got != "package main\n" {
+		t.Errorf("main.go stored as %q, want its content", got)
+	}
+
+	smudged, ok := SmudgeLFSPointer(tempDir, wantPointer.Bytes())
+	if !ok || !bytes.Equal(smudged, content) {
+		t.Error("SmudgeLFSPointer() should return the content stored in the LFS object store")
+	}
+	if _, ok := SmudgeLFSPointer(tempDir, pending.Bytes()); ok {
+		t.Error("SmudgeLFSPointer() = true for an object that is not in the store")
+	}
+}
+
+func TestLFSTrackedPaths(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	if _, err := git.PlainInit(tempDir, false); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	files := []string{"a.bin", "assets/b.bin", "assets/c.txt"}
+	if got := LFSTrackedPaths(tempDir, files); len(got) != 0 {
+		t.Errorf("LFSTrackedPaths() without attributes = %v, want empty", got)
+	}
+
+	// LFS configured only in a subdirectory's .gitattributes
+	if err := os.MkdirAll(filepath.Join(tempDir, "assets"), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "assets", ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .gitattributes: %v", err)
+	}
+	got := LFSTrackedPaths(tempDir, files)
+	if len(got) != 1 || !got["assets/b.bin"] {
+		t.Errorf("LFSTrackedPaths() = %v, want only assets/b.bin", got)
+	}
+}
+
+func TestLFSTrackedPaths_LinkedWorktreeInfoAttributes(t *testing.T) {
+	t.Parallel()
+	mainDir := filepath.Join(t.TempDir(), "main")
+	worktreeDir := filepath.Join(t.TempDir(), "linked")
+	runGit := func(dir string, args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(context.Background(), "git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	if err := os.MkdirAll(mainDir, 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	runGit(mainDir, "init", "-q")
+	runGit(mainDir, "-c", "user.name=Test", "-c", "user.email=test@test.com", "-c", "commit.gpgsign=false",
+		"commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(mainDir, "worktree", "add", "-q", worktreeDir)
+
+	// LFS tracking set in the shared info/attributes, not in .gitattributes
+	if err := os.MkdirAll(filepath.Join(mainDir, ".git", "info"), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mainDir, ".git", "info", "attributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatalf("failed to write info/attributes: %v", err)
+	}
+
+	got := LFSTrackedPaths(worktreeDir, []string{"a.bin", "b.txt"})
+	if len(got) != 1 || !got["a.bin"] {
+		t.Errorf("LFSTrackedPaths() = %v, want only a.bin", got)
+	}
+}
@@ -0,0 +1,166 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Revision reasons recorded in revision.json.
+const (
+	RevisionReasonFinalize = "finalize"
+	RevisionReasonSummary  = "summary"
+)
+
+// revisionPath returns the directory holding revision n of the session at sessionPath.
+// sessionPath must end with a slash.
+func revisionPath(sessionPath string, n int) string {
+	return fmt.Sprintf("%s%s/%d/", sessionPath, paths.RevisionsDirName, n)
+}
+
+// nextRevision returns the number the next revision of the session at
+// sessionPath should use, based on the revisions already present in entries.
+func nextRevision(sessionPath string, entries map[string]object.TreeEntry) int {
+	prefix := sessionPath + paths.RevisionsDirName + "/"
+	latest := 0
+	for key := range entries {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		dir, _, _ := strings.Cut(rest, "/")
+		if n, err := strconv.Atoi(dir); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return latest + 1
+}
+
+// readRevisions returns the revisions recorded in a session tree, oldest first.
+// Revisions with a missing or unreadable revision.json are skipped.
+func readRevisions(sessionTree *object.Tree) []revisionEntry {
+	revisionsTree, err := sessionTree.Tree(paths.RevisionsDirName)
+	if err != nil {
+		return nil
+	}
+
+	var revisions []revisionEntry
+	for _, entry := range revisionsTree.Entries {
+		if entry.Mode.IsFile() {
+			continue
+		}
+		if _, err := strconv.Atoi(entry.Name); err != nil {
+			continue
+		}
+		tree, err := revisionsTree.Tree(entry.Name)
+		if err != nil {
+			continue
+		}
+		file, err := tree.File(paths.RevisionFileName)
+		if err != nil {
+			continue
+		}
+		content, err := file.Contents()
+		if err != nil {
+			continue
+		}
+		var rev SessionRevision
+		if err := json.Unmarshal([]byte(content), &rev); err != nil {
+			continue
+		}
+		revisions = append(revisions, revisionEntry{SessionRevision: rev, tree: tree})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	return revisions
+}
+
+// revisionEntry pairs a revision record with the tree holding its files.
+type revisionEntry struct {
+	SessionRevision
+	tree *object.Tree
+}
+
+// applyRevisions overlays every recorded revision onto content in order, so
+// the result reflects the latest revision of each file and field.
+func applyRevisions(sessionTree *object.Tree, agentType agent.AgentType, content *SessionContent) {
+	for _, rev := range readRevisions(sessionTree) {
+		if transcript, err := readTranscriptFromTree(rev.tree, agentType); err == nil && transcript != nil {
+			content.Transcript = transcript
+		}
+		if file, err := rev.tree.File(paths.PromptFileName); err == nil {
+			if prompts, err := file.Contents(); err == nil {
+				content.Prompts = prompts
+			}
+		}
+		if file, err := rev.tree.File(paths.ContextFileName); err == nil {
+			if ctx, err := file.Contents(); err == nil {
+				content.Context = ctx
+			}
+		}
+		applyRevisionMetadata(rev.SessionRevision, &content.Metadata)
+		content.Revision = rev.Revision
+	}
+}
+
+// applyRevisionMetadata overlays the metadata fields a revision records onto
+// metadata.
+func applyRevisionMetadata(rev SessionRevision, metadata *CommittedMetadata) {
+	if rev.PromptsCount > 0 {
+		metadata.PromptsCount = rev.PromptsCount
+	}
+	if rev.Summary != nil {
+		metadata.Summary = rev.Summary
+	}
+	if rev.TranscriptFilter != nil {
+		metadata.TranscriptFilter = rev.TranscriptFilter
+	}
+}
+
+// ReadSessionRevisions returns the full revision history of a session within a
+// checkpoint, starting with the original write as revision 0.
+// sessionIndex is 0-based. Returns ErrCheckpointNotFound if the checkpoint
+// doesn't exist.
+func (s *GitStore) ReadSessionRevisions(ctx context.Context, checkpointID id.CheckpointID, sessionIndex int) ([]SessionRevision, error) {
+	_ = ctx // Reserved for future use
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+	checkpointTree, err := tree.Tree(checkpointID.Path())
+	if err != nil {
+		return nil, ErrCheckpointNotFound
+	}
+	sessionTree, err := checkpointTree.Tree(strconv.Itoa(sessionIndex))
+	if err != nil {
+		return nil, fmt.Errorf("session %d not found: %w", sessionIndex, err)
+	}
+
+	original := SessionRevision{Reason: "original"}
+	if file, err := sessionTree.File(paths.MetadataFileName); err == nil {
+		if content, err := file.Contents(); err == nil {
+			var meta CommittedMetadata
+			if json.Unmarshal([]byte(content), &meta) == nil {
+				original.CreatedAt = meta.CreatedAt
+			}
+		}
+	}
+
+	history := []SessionRevision{original}
+	for _, rev := range readRevisions(sessionTree) {
+		history = append(history, rev.SessionRevision)
+	}
+	return history, nil
+}
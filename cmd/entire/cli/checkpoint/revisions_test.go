@@ -0,0 +1,117 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+func TestUpdateCommitted_AppendOnlyKeepsOriginal(t *testing.T) {
+	t.Parallel()
+	_, store, cpID := setupRepoForUpdate(t)
+	store.SetAppendOnly(true)
+
+	fullTranscript := []byte("full transcript line 1\nfull transcript line 2\n")
+	err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   fullTranscript,
+		Prompts:      []string{"prompt 1", "prompt 2"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	// Readers resolve the latest revision
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != string(fullTranscript) {
+		t.Errorf("transcript = %q, want %q", content.Transcript, fullTranscript)
+	}
+	if content.Metadata.PromptsCount != 2 {
+		t.Errorf("PromptsCount = %d, want 2", content.Metadata.PromptsCount)
+	}
+	if content.Context != "initial context" {
+		t.Errorf("context = %q, want original context to remain current", content.Context)
+	}
+	if content.Revision != 1 {
+		t.Errorf("Revision = %d, want 1", content.Revision)
+	}
+
+	// The originally written files are untouched
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("getSessionsBranchTree() error = %v", err)
+	}
+	file, err := tree.File(cpID.Path() + "/0/" + paths.TranscriptFileName)
+	if err != nil {
+		t.Fatalf("original transcript missing: %v", err)
+	}
+	original, err := file.Contents()
+	if err != nil {
+		t.Fatalf("failed to read original transcript: %v", err)
+	}
+	if original != "provisional transcript line 1\n" {
+		t.Errorf("original transcript was modified: %q", original)
+	}
+}
+
+func TestReadSessionRevisions_ListsHistory(t *testing.T) {
+	t.Parallel()
+	_, store, cpID := setupRepoForUpdate(t)
+	store.SetAppendOnly(true)
+
+	if err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("final transcript\n"),
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	if err := store.UpdateSummary(context.Background(), cpID, &Summary{Intent: "Fix the bug"}); err != nil {
+		t.Fatalf("UpdateSummary() error = %v", err)
+	}
+
+	history, err := store.ReadSessionRevisions(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionRevisions() error = %v", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("expected 3 revisions (original + 2), got %d", len(history))
+	}
+	wantReasons := []string{"original", RevisionReasonFinalize, RevisionReasonSummary}
+	for i, rev := range history {
+		if rev.Revision != i {
+			t.Errorf("history[%d].Revision = %d, want %d", i, rev.Revision, i)
+		}
+		if rev.Reason != wantReasons[i] {
+			t.Errorf("history[%d].Reason = %q, want %q", i, rev.Reason, wantReasons[i])
+		}
+	}
+	if history[2].Supersedes != 1 {
+		t.Errorf("summary revision supersedes %d, want 1", history[2].Supersedes)
+	}
+
+	// Both revisions apply: finalized transcript and the new summary
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != "final transcript\n" {
+		t.Errorf("transcript = %q, want finalized transcript", content.Transcript)
+	}
+	if content.Metadata.Summary == nil || content.Metadata.Summary.Intent != "Fix the bug" {
+		t.Errorf("expected summary from revision, got %+v", content.Metadata.Summary)
+	}
+
+	metadata, err := store.ReadCommittedMetadata(context.Background(), cpID)
+	if err != nil {
+		t.Fatalf("ReadCommittedMetadata() error = %v", err)
+	}
+	if len(metadata) != 1 || metadata[0].Summary == nil || metadata[0].Summary.Intent != "Fix the bug" {
+		t.Errorf("expected ReadCommittedMetadata to include the summary revision, got %+v", metadata)
+	}
+}
@@ -0,0 +1,174 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// StampCommit links commitHash to an existing committed checkpoint without
+// rewriting the commit. It is for commits created where hooks never run,
+// such as merge queues, which therefore have no Entire-Checkpoint trailer.
+// The link is recorded in the checkpoint's root metadata.json.
+//
+// Returns false if the commit was already stamped with this checkpoint.
+// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+func (s *GitStore) StampCommit(ctx context.Context, checkpointID id.CheckpointID, commitHash plumbing.Hash) (bool, error) {
+	_ = ctx // Reserved for future use
+
+	var stamped bool
+	err := s.retryMetadataUpdate(func() error {
+		var err error
+		stamped, err = s.stampCommit(checkpointID, commitHash)
+		return err
+	})
+	return stamped, err
+}
+
+// stampCommit applies one StampCommit attempt on the current branch tip.
+func (s *GitStore) stampCommit(checkpointID id.CheckpointID, commitHash plumbing.Hash) (bool, error) {
+	if err := s.ensureSessionsBranch(); err != nil {
+		return false, fmt.Errorf("failed to ensure sessions branch: %w", err)
+	}
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return false, err
+	}
+
+	rootMetadataPath := checkpointID.Path() + "/" + paths.MetadataFileName
+	entry, exists := entries[rootMetadataPath]
+	if !exists {
+		return false, ErrCheckpointNotFound
+	}
+	summary, err := s.readSummaryFromBlob(entry.Hash)
+	if err != nil {
+		return false, fmt.Errorf("failed to read checkpoint summary: %w", err)
+	}
+
+	commit := commitHash.String()
+	if slices.Contains(summary.StampedCommits, commit) {
+		return false, nil
+	}
+	summary.StampedCommits = append(summary.StampedCommits, commit)
+
+	metadataJSON, err := jsonutil.MarshalCanonical(summary)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal checkpoint summary: %w", err)
+	}
+	metadataHash, err := CreateBlobFromContent(s.repo, metadataJSON)
+	if err != nil {
+		return false, err
+	}
+	entries[rootMetadataPath] = object.TreeEntry{
+		Name: rootMetadataPath,
+		Mode: filemode.Regular,
+		Hash: metadataHash,
+	}
+
+	newTreeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return false, err
+	}
+	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
+	commitMsg := fmt.Sprintf("Stamp commit %s with checkpoint %s", commit[:7], checkpointID)
+	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.setMetadataRef(ref, newCommitHash); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// stampedCommitsAt returns the commits stamped on the checkpoint whose root
+// metadata.json is at path in entries, so rewriting the summary keeps them.
+func (s *GitStore) stampedCommitsAt(path string, entries map[string]object.TreeEntry) []string {
+	entry, exists := entries[path]
+	if !exists {
+		return nil
+	}
+	summary, err := s.readSummaryFromBlob(entry.Hash)
+	if err != nil {
+		return nil
+	}
+	return summary.StampedCommits
+}
+
+// StampedCheckpoints returns the checkpoints stamped onto each of commits.
+// Only the root metadata.json of each checkpoint is read, and only those
+// that mention one of commits are decoded.
+func (s *GitStore) StampedCheckpoints(ctx context.Context, commits []plumbing.Hash) (map[plumbing.Hash][]id.CheckpointID, error) {
+	_ = ctx // Reserved for future use
+
+	stamped := make(map[plumbing.Hash][]id.CheckpointID)
+	if len(commits) == 0 {
+		return stamped, nil
+	}
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return stamped, nil //nolint:nilerr // No sessions branch means nothing is stamped
+	}
+
+	for _, bucketEntry := range tree.Entries {
+		if bucketEntry.Mode != filemode.Dir || len(bucketEntry.Name) != 2 {
+			continue
+		}
+		bucketTree, err := s.repo.TreeObject(bucketEntry.Hash)
+		if err != nil {
+			continue
+		}
+		for _, checkpointEntry := range bucketTree.Entries {
+			if checkpointEntry.Mode != filemode.Dir {
+				continue
+			}
+			checkpointID, err := id.NewCheckpointID(bucketEntry.Name + checkpointEntry.Name)
+			if err != nil {
+				continue
+			}
+			checkpointTree, err := s.repo.TreeObject(checkpointEntry.Hash)
+			if err != nil {
+				continue
+			}
+			metadataFile, err := checkpointTree.File(paths.MetadataFileName)
+			if err != nil {
+				continue
+			}
+			contents, err := metadataFile.Contents()
+			if err != nil {
+				continue
+			}
+			content := []byte(contents)
+			var mentioned []plumbing.Hash
+			for _, commit := range commits {
+				if bytes.Contains(content, []byte(commit.String())) {
+					mentioned = append(mentioned, commit)
+				}
+			}
+			if len(mentioned) == 0 {
+				continue
+			}
+			var summary CheckpointSummary
+			if err := json.Unmarshal(content, &summary); err != nil {
+				continue
+			}
+			for _, commit := range mentioned {
+				if slices.Contains(summary.StampedCommits, commit.String()) {
+					stamped[commit] = append(stamped[commit], checkpointID)
+				}
+			}
+		}
+	}
+	return stamped, nil
+}
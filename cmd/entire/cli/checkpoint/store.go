@@ -1,7 +1,29 @@
 package checkpoint
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// Retry policy for moving the metadata branch when another writer, such as a
+// commit in a second worktree, moved it first.
+const (
+	metadataRefMaxAttempts  = 6
+	metadataRefInitialDelay = 10 * time.Millisecond
+	metadataRefMaxDelay     = 500 * time.Millisecond
 )
 
 // Compile-time check that GitStore implements the Store interface.
@@ -11,6 +33,17 @@ var _ Store = (*GitStore)(nil)
 // It implements the Store interface by wrapping a git repository.
 type GitStore struct {
 	repo *git.Repository
+
+	// appendOnly records updates to committed checkpoints as new revisions
+	// instead of rewriting the original files.
+	appendOnly bool
+
+	// clock timestamps checkpoint commits and metadata. nil = clock.System.
+	clock clock.Clock
+
+	// beforeMetadataRefUpdate, if set, runs just before the metadata branch
+	// is moved. Tests use it to simulate a concurrent writer.
+	beforeMetadataRefUpdate func()
 }
 
 // NewGitStore creates a new checkpoint store backed by the given git repository.
@@ -23,3 +56,68 @@ func NewGitStore(repo *git.Repository) *GitStore {
 func (s *GitStore) Repository() *git.Repository {
 	return s.repo
 }
+
+// SetAppendOnly enables or disables append-only mode. In append-only mode,
+// UpdateCommitted and UpdateSummary record their changes as new session
+// revisions and leave previously written checkpoint content untouched.
+func (s *GitStore) SetAppendOnly(enabled bool) {
+	s.appendOnly = enabled
+}
+
+// SetClock sets the time source for checkpoint commit and metadata timestamps.
+func (s *GitStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+func (s *GitStore) now() time.Time {
+	return clock.OrSystem(s.clock).Now()
+}
+
+// retryMetadataUpdate runs update until it moves the metadata branch without
+// conflict. update must read the branch tip, apply its change on top, and
+// finish with setMetadataRef; when another writer moved the branch in
+// between, update is run again on the new tip after an exponential, jittered
+// backoff. Gives up after metadataRefMaxAttempts attempts.
+func (s *GitStore) retryMetadataUpdate(update func() error) error {
+	delay := metadataRefInitialDelay
+	for attempt := 1; ; attempt++ {
+		err := update()
+		if !errors.Is(err, storage.ErrReferenceHasChanged) {
+			return err
+		}
+		if attempt == metadataRefMaxAttempts {
+			return fmt.Errorf("metadata branch kept changing after %d attempts: %w", attempt, err)
+		}
+		logging.Debug(logging.WithComponent(context.Background(), "checkpoint"), "metadata branch moved concurrently, retrying",
+			slog.Int("attempt", attempt),
+			slog.Duration("delay", delay),
+		)
+		time.Sleep(delay/2 + rand.N(delay/2+1)) //nolint:gosec // jitter needs no cryptographic randomness
+		delay = min(delay*2, metadataRefMaxDelay)
+	}
+}
+
+// setMetadataRef moves the metadata branch from parent to commitHash. Returns
+// storage.ErrReferenceHasChanged if the branch no longer points at parent.
+func (s *GitStore) setMetadataRef(parent *plumbing.Reference, commitHash plumbing.Hash) error {
+	if s.beforeMetadataRefUpdate != nil {
+		s.beforeMetadataRefUpdate()
+	}
+	newRef := plumbing.NewHashReference(parent.Name(), commitHash)
+	if err := audit.CheckAndSetReference(s.repo, newRef, parent); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return nil
+}
+
+// refBeingWritten reports whether the loose file of a ref that could not be
+// read exists. go-git rewrites ref files in place, so a reader racing with
+// another writer can briefly see an empty file and treat the ref as missing.
+func (s *GitStore) refBeingWritten(refName plumbing.ReferenceName) bool {
+	fsStorage, ok := s.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return false
+	}
+	_, err := fsStorage.Filesystem().Stat(refName.String())
+	return err == nil
+}
@@ -0,0 +1,168 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage"
+)
+
+func writeTestCheckpoint(t *testing.T, store *GitStore, cpID id.CheckpointID, sessionID string) error {
+	t.Helper()
+	return store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    sessionID,
+		Strategy:     "manual-commit",
+		Transcript:   []byte(sessionID + " transcript\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@test.com",
+	})
+}
+
+func openSecondStore(t *testing.T, repo *git.Repository) *GitStore {
+	t.Helper()
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	other, err := git.PlainOpen(worktree.Filesystem.Root())
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	return NewGitStore(other)
+}
+
+func TestWriteCommitted_RetriesWhenBranchMovesConcurrently(t *testing.T) {
+	t.Parallel()
+	repo, store, firstID := setupRepoForUpdate(t)
+	other := openSecondStore(t, repo)
+
+	competingID := id.MustCheckpointID("b1b2c3d4e5f6")
+	ourID := id.MustCheckpointID("c1c2c3d4e5f6")
+
+	attempts := 0
+	store.beforeMetadataRefUpdate = func() {
+		attempts++
+		if attempts == 1 {
+			// Another worktree condenses between our read and our ref update.
+			if err := writeTestCheckpoint(t, other, competingID, "session-other"); err != nil {
+				t.Fatalf("competing WriteCommitted() error = %v", err)
+			}
+		}
+	}
+
+	if err := writeTestCheckpoint(t, store, ourID, "session-ours"); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("ref update attempts = %d, want 2", attempts)
+	}
+
+	for _, cpID := range []id.CheckpointID{firstID, competingID, ourID} {
+		summary, err := store.ReadCommitted(context.Background(), cpID)
+		if err != nil || summary == nil {
+			t.Errorf("checkpoint %s missing after concurrent writes (err = %v)", cpID, err)
+		}
+	}
+}
+
+func TestUpdateCommitted_RetriesWhenBranchMovesConcurrently(t *testing.T) {
+	t.Parallel()
+	repo, store, cpID := setupRepoForUpdate(t)
+	other := openSecondStore(t, repo)
+	competingID := id.MustCheckpointID("b1b2c3d4e5f6")
+
+	attempts := 0
+	store.beforeMetadataRefUpdate = func() {
+		attempts++
+		if attempts == 1 {
+			if err := writeTestCheckpoint(t, other, competingID, "session-other"); err != nil {
+				t.Fatalf("competing WriteCommitted() error = %v", err)
+			}
+		}
+	}
+
+	err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-001",
+		Transcript:   []byte("full transcript\n"),
+	})
+	if err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	content, err := store.ReadLatestSessionContent(context.Background(), cpID)
+	if err != nil {
+		t.Fatalf("ReadLatestSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != "full transcript\n" {
+		t.Errorf("transcript = %q, want the updated transcript", content.Transcript)
+	}
+	if summary, err := store.ReadCommitted(context.Background(), competingID); err != nil || summary == nil {
+		t.Errorf("competing checkpoint lost by the update (err = %v)", err)
+	}
+}
+
+func TestWriteCommitted_GivesUpWhenBranchKeepsMoving(t *testing.T) {
+	t.Parallel()
+	repo, store, _ := setupRepoForUpdate(t)
+	other := openSecondStore(t, repo)
+
+	attempts := 0
+	store.beforeMetadataRefUpdate = func() {
+		attempts++
+		cpID := id.MustCheckpointID(fmt.Sprintf("d0d0d0d0d0%02x", attempts))
+		if err := writeTestCheckpoint(t, other, cpID, "session-other"); err != nil {
+			t.Fatalf("competing WriteCommitted() error = %v", err)
+		}
+	}
+
+	err := writeTestCheckpoint(t, store, id.MustCheckpointID("c1c2c3d4e5f6"), "session-ours")
+	if !errors.Is(err, storage.ErrReferenceHasChanged) {
+		t.Fatalf("WriteCommitted() error = %v, want ErrReferenceHasChanged", err)
+	}
+	if attempts != metadataRefMaxAttempts {
+		t.Errorf("ref update attempts = %d, want %d", attempts, metadataRefMaxAttempts)
+	}
+}
+
+func TestWriteCommitted_ConcurrentWriters(t *testing.T) {
+	t.Parallel()
+	repo, store, _ := setupRepoForUpdate(t)
+
+	const writers = 4
+	ids := make([]id.CheckpointID, writers)
+	stores := make([]*GitStore, writers)
+	for i := range writers {
+		ids[i] = id.MustCheckpointID(fmt.Sprintf("e0e0e0e0e0%02x", i))
+		stores[i] = openSecondStore(t, repo)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = writeTestCheckpoint(t, stores[i], ids[i], fmt.Sprintf("session-%d", i))
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("writer %d: WriteCommitted() error = %v", i, err)
+		}
+	}
+	for _, cpID := range ids {
+		if summary, err := store.ReadCommitted(context.Background(), cpID); err != nil || summary == nil {
+			t.Errorf("checkpoint %s missing after concurrent writes (err = %v)", cpID, err)
+		}
+	}
+}
@@ -1,12 +1,14 @@
 package checkpoint
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -16,6 +18,8 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/entireignore"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -29,10 +33,13 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
-const (
-	// ShadowBranchPrefix is the prefix for shadow branches.
-	ShadowBranchPrefix = "entire/"
+// ShadowBranchPrefix returns the prefix for shadow branches: "entire/" unless
+// the "namespace" setting overrides it.
+func ShadowBranchPrefix() string {
+	return paths.ShadowBranchPrefix()
+}
 
+const (
 	// ShadowBranchHashLength is the number of hex characters used in shadow branch names.
 	// Shadow branches are named "entire/<hash>" using the first 7 characters of the commit hash.
 	ShadowBranchHashLength = 7
@@ -106,7 +113,8 @@ func (s *GitStore) WriteTemporary(ctx context.Context, opts WriteTemporaryOption
 	}
 
 	// Build tree with changes
-	treeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, allDeletedFiles, opts.MetadataDir, opts.MetadataDirAbs)
+	limits := snapshotLimits{maxFileBytes: opts.MaxFileBytes, skipBinary: opts.SkipBinaryFiles}
+	treeHash, snapshots, omitted, err := s.buildTreeWithChanges(baseTreeHash, allFiles, allDeletedFiles, opts.MetadataDir, opts.MetadataDirAbs, opts.WorktreeRoot, limits)
 	if err != nil {
 		return WriteTemporaryResult{}, fmt.Errorf("failed to build tree: %w", err)
 	}
@@ -116,6 +124,8 @@ func (s *GitStore) WriteTemporary(ctx context.Context, opts WriteTemporaryOption
 		return WriteTemporaryResult{
 			CommitHash: parentHash,
 			Skipped:    true,
+			Snapshots:  snapshots,
+			Omitted:    omitted,
 		}, nil
 	}
 
@@ -130,13 +140,15 @@ func (s *GitStore) WriteTemporary(ctx context.Context, opts WriteTemporaryOption
 	// Update branch reference
 	refName := plumbing.NewBranchReferenceName(shadowBranchName)
 	newRef := plumbing.NewHashReference(refName, commitHash)
-	if err := s.repo.Storer.SetReference(newRef); err != nil {
+	if err := audit.SetReference(s.repo, newRef); err != nil {
 		return WriteTemporaryResult{}, fmt.Errorf("failed to update branch reference: %w", err)
 	}
 
 	return WriteTemporaryResult{
 		CommitHash: commitHash,
 		Skipped:    false,
+		Snapshots:  snapshots,
+		Omitted:    omitted,
 	}, nil
 }
 
@@ -184,12 +196,12 @@ func (s *GitStore) ListTemporary(ctx context.Context) ([]TemporaryInfo, error) {
 	var results []TemporaryInfo
 	err = iter.ForEach(func(ref *plumbing.Reference) error {
 		branchName := ref.Name().Short()
-		if !strings.HasPrefix(branchName, ShadowBranchPrefix) {
+		if !strings.HasPrefix(branchName, ShadowBranchPrefix()) {
 			return nil
 		}
 
 		// Skip the sessions branch
-		if branchName == paths.MetadataBranchName {
+		if branchName == paths.MetadataBranchName() {
 			return nil
 		}
 
@@ -259,7 +271,8 @@ func (s *GitStore) WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTa
 	allFiles = append(allFiles, opts.NewFiles...)
 
 	// Build new tree with code changes (no metadata dir yet)
-	newTreeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, opts.DeletedFiles, "", "")
+	limits := snapshotLimits{maxFileBytes: opts.MaxFileBytes, skipBinary: opts.SkipBinaryFiles}
+	newTreeHash, _, _, err := s.buildTreeWithChanges(baseTreeHash, allFiles, opts.DeletedFiles, "", "", "", limits)
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
 	}
@@ -279,7 +292,7 @@ func (s *GitStore) WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTa
 	// Update shadow branch reference
 	refName := plumbing.NewBranchReferenceName(shadowBranchName)
 	ref := plumbing.NewHashReference(refName, commitHash)
-	if err := s.repo.Storer.SetReference(ref); err != nil {
+	if err := audit.SetReference(s.repo, ref); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to update shadow branch reference: %w", err)
 	}
 
@@ -322,10 +335,10 @@ func (s *GitStore) addTaskMetadataToTree(baseTreeHash plumbing.Hash, opts WriteT
 		}{
 			Type:      opts.IncrementalType,
 			ToolUseID: opts.ToolUseID,
-			Timestamp: time.Now().UTC(),
+			Timestamp: s.now().UTC(),
 			Data:      incData,
 		}
-		cpData, err := jsonutil.MarshalIndentWithNewline(incrementalCheckpoint, "", "  ")
+		cpData, err := jsonutil.MarshalCanonical(incrementalCheckpoint)
 		if err != nil {
 			return plumbing.ZeroHash, fmt.Errorf("failed to marshal incremental checkpoint: %w", err)
 		}
@@ -628,6 +641,7 @@ func (s *GitStore) DeleteShadowBranch(baseCommit, worktreeID string) error {
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to delete shadow branch %s: %s: %w", shadowBranchName, strings.TrimSpace(string(output)), err)
 	}
+	audit.RecordRepo(s.repo, audit.Event{Action: audit.ActionRefDelete, Target: "refs/heads/" + shadowBranchName})
 	return nil
 }
 
@@ -641,17 +655,17 @@ func ShadowBranchNameForCommit(baseCommit, worktreeID string) string {
 		commitPart = baseCommit[:ShadowBranchHashLength]
 	}
 	worktreeHash := HashWorktreeID(worktreeID)
-	return ShadowBranchPrefix + commitPart + "-" + worktreeHash
+	return ShadowBranchPrefix() + commitPart + "-" + worktreeHash
 }
 
 // ParseShadowBranchName extracts the commit prefix and worktree hash from a shadow branch name.
 // Input format: "entire/<commit[:7]>-<worktreeHash[:6]>"
 // Returns (commitPrefix, worktreeHash, ok). Returns ("", "", false) if not a valid shadow branch.
 func ParseShadowBranchName(branchName string) (commitPrefix, worktreeHash string, ok bool) {
-	if !strings.HasPrefix(branchName, ShadowBranchPrefix) {
+	if !strings.HasPrefix(branchName, ShadowBranchPrefix()) {
 		return "", "", false
 	}
-	suffix := strings.TrimPrefix(branchName, ShadowBranchPrefix)
+	suffix := strings.TrimPrefix(branchName, ShadowBranchPrefix())
 
 	// Find the last dash - everything before is commit prefix, after is worktree hash
 	lastDash := strings.LastIndex(suffix, "-")
@@ -696,51 +710,105 @@ func (s *GitStore) getOrCreateShadowBranch(branchName string) (plumbing.Hash, pl
 // buildTreeWithChanges builds a git tree with the given changes.
 // metadataDir is the relative path for git tree entries, metadataDirAbs is the absolute path
 // for filesystem operations (needed when CLI is run from a subdirectory).
+// repoRoot is the directory the files are relative to; empty means the current
+// worktree's root. Each file read from disk is verified after its blob is written (see snapshotFile);
+// the statuses are returned and, when metadataDir is set, stored in the tree as
+// metadataDir/snapshot.json. Files over the limits are left out of the tree
+// (a tracked file keeps its previous entry); the ones this step left out are
+// returned, and every file still left out is listed in metadataDir/omitted.json.
 func (s *GitStore) buildTreeWithChanges(
 	baseTreeHash plumbing.Hash,
 	modifiedFiles, deletedFiles []string,
-	metadataDir, metadataDirAbs string,
-) (plumbing.Hash, error) {
+	metadataDir, metadataDirAbs, repoRoot string,
+	limits snapshotLimits,
+) (plumbing.Hash, map[string]SnapshotStatus, []OmittedFile, error) {
 	// Get worktree root for resolving file paths
 	// This is critical because fileExists() and createBlobFromFile() use os.Stat()
 	// which resolves relative to CWD. The modifiedFiles are repo-relative paths,
 	// so we must resolve them against repo root, not CWD.
-	repoRoot, err := paths.WorktreeRoot()
-	if err != nil {
-		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree root: %w", err)
+	if repoRoot == "" {
+		var err error
+		if repoRoot, err = paths.WorktreeRoot(); err != nil {
+			return plumbing.ZeroHash, nil, nil, fmt.Errorf("failed to get worktree root: %w", err)
+		}
 	}
 
 	// Get the base tree
 	baseTree, err := s.repo.TreeObject(baseTreeHash)
 	if err != nil {
-		return plumbing.ZeroHash, fmt.Errorf("failed to get base tree: %w", err)
+		return plumbing.ZeroHash, nil, nil, fmt.Errorf("failed to get base tree: %w", err)
 	}
 
 	// Flatten existing tree
 	entries := make(map[string]object.TreeEntry)
 	if err := FlattenTree(s.repo, baseTree, "", entries); err != nil {
-		return plumbing.ZeroHash, fmt.Errorf("failed to flatten base tree: %w", err)
+		return plumbing.ZeroHash, nil, nil, fmt.Errorf("failed to flatten base tree: %w", err)
+	}
+
+	// Files left out by earlier steps stay listed until they are stored or deleted
+	stillOmitted := make(map[string]OmittedFile)
+	if metadataDir != "" {
+		for _, f := range s.readOmittedFromEntries(metadataDir, entries) {
+			stillOmitted[f.Path] = f
+		}
 	}
 
 	// Remove deleted files
 	for _, file := range deletedFiles {
 		delete(entries, file)
+		delete(stillOmitted, file)
 	}
 
 	// Add/update modified files
+	snapshots := make(map[string]SnapshotStatus)
+	var omitted []OmittedFile
+	lfsPaths := LFSTrackedPaths(repoRoot, modifiedFiles)
 	for _, file := range modifiedFiles {
 		// Resolve path relative to repo root for filesystem operations
 		absPath := filepath.Join(repoRoot, file)
 		if !fileExists(absPath) {
 			delete(entries, file)
+			delete(stillOmitted, file)
+			continue
+		}
+
+		// LFS paths store their pointer, as a commit would
+		if lfsPaths[file] {
+			blobHash, mode, err := snapshotLFSFile(s.repo, repoRoot, absPath)
+			if err != nil {
+				logging.Warn(context.Background(), "failed to store LFS pointer for checkpoint",
+					slog.String("file", file),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			delete(stillOmitted, file)
+			entries[file] = object.TreeEntry{
+				Name: file,
+				Mode: mode,
+				Hash: blobHash,
+			}
 			continue
 		}
 
-		blobHash, mode, err := createBlobFromFile(s.repo, absPath)
+		if o, ok := limits.omit(file, absPath); ok {
+			logging.Warn(context.Background(), "file content left out of checkpoint",
+				slog.String("file", file),
+				slog.String("reason", o.Reason),
+				slog.Int64("size", o.Size),
+			)
+			omitted = append(omitted, o)
+			stillOmitted[file] = o
+			continue
+		}
+		delete(stillOmitted, file)
+
+		blobHash, mode, status, err := snapshotFile(s.repo, absPath)
 		if err != nil {
 			// Skip files that can't be staged (may have been deleted since detection)
 			continue
 		}
+		snapshots[file] = status
 
 		entries[file] = object.TreeEntry{
 			Name: file,
@@ -752,17 +820,195 @@ func (s *GitStore) buildTreeWithChanges(
 	// Add metadata directory files
 	if metadataDir != "" && metadataDirAbs != "" {
 		if err := addDirectoryToEntriesWithAbsPath(s.repo, metadataDirAbs, metadataDir, entries); err != nil {
-			return plumbing.ZeroHash, fmt.Errorf("failed to add metadata directory: %w", err)
+			return plumbing.ZeroHash, nil, nil, fmt.Errorf("failed to add metadata directory: %w", err)
+		}
+		if err := s.addSnapshotStatusToEntries(metadataDir, snapshots, entries); err != nil {
+			return plumbing.ZeroHash, nil, nil, err
+		}
+		if err := s.addOmittedToEntries(metadataDir, stillOmitted, entries); err != nil {
+			return plumbing.ZeroHash, nil, nil, err
 		}
 	}
 
 	// Build tree
-	return BuildTreeFromEntries(s.repo, entries)
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return plumbing.ZeroHash, nil, nil, err
+	}
+	return treeHash, snapshots, omitted, nil
+}
+
+// addSnapshotStatusToEntries stores the step's snapshot statuses at
+// metadataDir/snapshot.json, replacing the previous step's. Steps that wrote
+// no files from disk drop the file so stale statuses are not carried over.
+func (s *GitStore) addSnapshotStatusToEntries(metadataDir string, snapshots map[string]SnapshotStatus, entries map[string]object.TreeEntry) error {
+	snapshotPath := metadataDir + "/" + paths.SnapshotFileName
+	if len(snapshots) == 0 {
+		delete(entries, snapshotPath)
+		return nil
+	}
+	data, err := jsonutil.MarshalCanonical(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot status: %w", err)
+	}
+	blobHash, err := CreateBlobFromContent(s.repo, data)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot status blob: %w", err)
+	}
+	entries[snapshotPath] = object.TreeEntry{
+		Name: snapshotPath,
+		Mode: filemode.Regular,
+		Hash: blobHash,
+	}
+	return nil
+}
+
+// addOmittedToEntries lists the files still left out of the checkpoint at
+// metadataDir/omitted.json, or drops the file when there are none.
+func (s *GitStore) addOmittedToEntries(metadataDir string, omitted map[string]OmittedFile, entries map[string]object.TreeEntry) error {
+	omittedPath := metadataDir + "/" + paths.OmittedFileName
+	if len(omitted) == 0 {
+		delete(entries, omittedPath)
+		return nil
+	}
+	files := make([]OmittedFile, 0, len(omitted))
+	for _, f := range omitted {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	data, err := jsonutil.MarshalCanonical(files)
+	if err != nil {
+		return fmt.Errorf("failed to marshal omitted files: %w", err)
+	}
+	blobHash, err := CreateBlobFromContent(s.repo, data)
+	if err != nil {
+		return fmt.Errorf("failed to create omitted files blob: %w", err)
+	}
+	entries[omittedPath] = object.TreeEntry{
+		Name: omittedPath,
+		Mode: filemode.Regular,
+		Hash: blobHash,
+	}
+	return nil
+}
+
+// readOmittedFromEntries returns the files listed in metadataDir/omitted.json
+// of the tree being built. A missing or unreadable list is treated as empty.
+func (s *GitStore) readOmittedFromEntries(metadataDir string, entries map[string]object.TreeEntry) []OmittedFile {
+	entry, ok := entries[metadataDir+"/"+paths.OmittedFileName]
+	if !ok {
+		return nil
+	}
+	blob, err := s.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+	var files []OmittedFile
+	if err := json.NewDecoder(reader).Decode(&files); err != nil {
+		return nil
+	}
+	return files
+}
+
+// ReadOmittedFiles returns the files a checkpoint tree lists as left out
+// under metadataDir, or nil when it lists none.
+func ReadOmittedFiles(tree *object.Tree, metadataDir string) []OmittedFile {
+	file, err := tree.File(metadataDir + "/" + paths.OmittedFileName)
+	if err != nil {
+		return nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil
+	}
+	var files []OmittedFile
+	if err := json.Unmarshal([]byte(content), &files); err != nil {
+		return nil
+	}
+	return files
+}
+
+// snapshotLimits decides which files a checkpoint leaves out instead of
+// storing their content. The zero value stores everything.
+type snapshotLimits struct {
+	maxFileBytes int64
+	skipBinary   bool
+}
+
+// binarySniffLen is how much of a file is checked for NUL bytes, the same
+// amount git looks at when deciding whether a file is binary.
+const binarySniffLen = 8000
+
+// omit reports whether the file at absPath is over the limits, and if so
+// returns its record. Symlinks and files that can't be read are stored as usual.
+func (l snapshotLimits) omit(file, absPath string) (OmittedFile, bool) {
+	if l.maxFileBytes <= 0 && !l.skipBinary {
+		return OmittedFile{}, false
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil || !info.Mode().IsRegular() {
+		return OmittedFile{}, false
+	}
+
+	reason := ""
+	switch {
+	case l.maxFileBytes > 0 && info.Size() > l.maxFileBytes:
+		reason = OmittedTooLarge
+	case l.skipBinary && isBinaryFile(absPath):
+		reason = OmittedBinary
+	default:
+		return OmittedFile{}, false
+	}
+
+	hash, size, err := hashFileAsBlob(absPath)
+	if err != nil {
+		return OmittedFile{}, false
+	}
+	return OmittedFile{Path: file, Hash: hash.String(), Size: size, Reason: reason}, true
+}
+
+// isBinaryFile reports whether the file has a NUL byte near its start.
+func isBinaryFile(path string) bool {
+	f, err := os.Open(path) //nolint:gosec // path is a repository file reported by the agent
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	buf := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false
+	}
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
+
+// hashFileAsBlob computes the git blob hash of a file without reading it
+// into memory or storing it.
+func hashFileAsBlob(path string) (plumbing.Hash, int64, error) {
+	f, err := os.Open(path) //nolint:gosec // path is a repository file reported by the agent
+	if err != nil {
+		return plumbing.ZeroHash, 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return plumbing.ZeroHash, 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	hasher := plumbing.NewHasher(plumbing.BlobObject, info.Size())
+	if _, err := io.Copy(hasher, f); err != nil {
+		return plumbing.ZeroHash, 0, fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hasher.Sum(), info.Size(), nil
 }
 
 // createCommit creates a commit object.
 func (s *GitStore) createCommit(treeHash, parentHash plumbing.Hash, message, authorName, authorEmail string) (plumbing.Hash, error) {
-	now := time.Now()
+	now := s.now()
 	sig := object.Signature{
 		Name:  authorName,
 		Email: authorEmail,
@@ -880,6 +1126,58 @@ func createBlobFromFile(repo *git.Repository, filePath string) (plumbing.Hash, f
 	return hash, mode, nil
 }
 
+// snapshotFile stores a file from the working directory as a blob and checks
+// that the blob still matches the file afterwards. An agent editing the file
+// while it is read can leave a blob that matches neither the old nor the new
+// content, so on a mismatch the file is stored once more and checked again.
+func snapshotFile(repo *git.Repository, filePath string) (plumbing.Hash, filemode.FileMode, SnapshotStatus, error) {
+	status := SnapshotVerified
+	for attempt := 0; ; attempt++ {
+		blobHash, mode, err := createBlobFromFile(repo, filePath)
+		if err != nil {
+			return plumbing.ZeroHash, 0, "", err
+		}
+		if fileMatchesBlob(filePath, blobHash) {
+			return blobHash, mode, status, nil
+		}
+		if attempt == 1 {
+			return blobHash, mode, SnapshotTorn, nil
+		}
+		status = SnapshotRetried
+	}
+}
+
+// snapshotLFSFile stores the Git LFS pointer for a file instead of its
+// content, moving the content into the LFS object store (see cleanLFSFile).
+func snapshotLFSFile(repo *git.Repository, repoRoot, filePath string) (plumbing.Hash, filemode.FileMode, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return plumbing.ZeroHash, 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	mode := filemode.Regular
+	if info.Mode()&0o111 != 0 {
+		mode = filemode.Executable
+	}
+	pointer, err := cleanLFSFile(repoRoot, filePath)
+	if err != nil {
+		return plumbing.ZeroHash, 0, err
+	}
+	blobHash, err := CreateBlobFromContent(repo, pointer.Bytes())
+	if err != nil {
+		return plumbing.ZeroHash, 0, fmt.Errorf("failed to create LFS pointer blob: %w", err)
+	}
+	return blobHash, mode, nil
+}
+
+// fileMatchesBlob reports whether the file's current content hashes to blobHash.
+func fileMatchesBlob(filePath string, blobHash plumbing.Hash) bool {
+	content, err := os.ReadFile(filePath) //nolint:gosec // filePath is a repository file already read by createBlobFromFile
+	if err != nil {
+		return false
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content) == blobHash
+}
+
 // addDirectoryToEntriesWithAbsPath recursively adds all files in a directory to the entries map.
 func addDirectoryToEntriesWithAbsPath(repo *git.Repository, dirPathAbs, dirPathRel string, entries map[string]object.TreeEntry) error {
 	err := filepath.Walk(dirPathAbs, func(path string, info os.FileInfo, err error) error {
@@ -1158,15 +1456,24 @@ func collectChangedFiles(ctx context.Context, repo *git.Repository) (changedFile
 		}
 	}
 
+	// Files matching .entireignore are left out like gitignored ones
+	ignore, err := entireignore.Load(repoRoot)
+	if err != nil {
+		ignore = nil // Fail open: snapshot everything git reports
+	}
 	changed := make([]string, 0, len(changedSeen))
 	for file := range changedSeen {
-		changed = append(changed, file)
+		if !ignore.Match(file) {
+			changed = append(changed, file)
+		}
 	}
 
 	deleted := make([]string, 0, len(deletedSeen))
 	for file := range deletedSeen {
 		deleted = append(deleted, file)
 	}
+	sort.Strings(changed)
+	sort.Strings(deleted)
 
 	return changedFilesResult{Changed: changed, Deleted: deleted}, nil
 }
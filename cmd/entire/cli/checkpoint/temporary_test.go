@@ -1,9 +1,15 @@
 package checkpoint
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 func TestHashWorktreeID(t *testing.T) {
@@ -134,7 +140,7 @@ func TestParseShadowBranchName(t *testing.T) {
 		},
 		{
 			name:         "entire/checkpoints/v1 is not a shadow branch",
-			branchName:   paths.MetadataBranchName,
+			branchName:   paths.MetadataBranchName(),
 			wantCommit:   "checkpoints/v1",
 			wantWorktree: "",
 			wantOK:       true, // Parser doesn't validate content, just extracts
@@ -200,3 +206,70 @@ func TestParseShadowBranchName_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSnapshotFile_Verified(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	filePath := filepath.Join(tempDir, "main.go")
+	content := []byte("package main\n")
+	if err := os.WriteFile(filePath, content, 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	blobHash, _, status, err := snapshotFile(repo, filePath)
+	if err != nil {
+		t.Fatalf("snapshotFile() error = %v", err)
+	}
+	if status != SnapshotVerified {
+		t.Errorf("snapshotFile() status = %q, want %q", status, SnapshotVerified)
+	}
+	if want := plumbing.ComputeHash(plumbing.BlobObject, content); blobHash != want {
+		t.Errorf("snapshotFile() hash = %s, want %s", blobHash, want)
+	}
+}
+
+func TestFileMatchesBlob(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	blobHash := plumbing.ComputeHash(plumbing.BlobObject, []byte("package main\n"))
+
+	if !fileMatchesBlob(filePath, blobHash) {
+		t.Error("fileMatchesBlob() = false for unchanged file")
+	}
+
+	// An edit after the blob was written is a torn snapshot.
+	if err := os.WriteFile(filePath, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatalf("failed to modify file: %v", err)
+	}
+	if fileMatchesBlob(filePath, blobHash) {
+		t.Error("fileMatchesBlob() = true after the file changed")
+	}
+	if fileMatchesBlob(filepath.Join(t.TempDir(), "missing.go"), blobHash) {
+		t.Error("fileMatchesBlob() = true for a missing file")
+	}
+}
+
+func TestWriteTemporaryResult_TornSnapshots(t *testing.T) {
+	t.Parallel()
+	result := WriteTemporaryResult{Snapshots: map[string]SnapshotStatus{
+		"b.go": SnapshotTorn,
+		"c.go": SnapshotVerified,
+		"a.go": SnapshotTorn,
+		"d.go": SnapshotRetried,
+	}}
+
+	if got := result.TornSnapshots(); !slices.Equal(got, []string{"a.go", "b.go"}) {
+		t.Errorf("TornSnapshots() = %v, want [a.go b.go]", got)
+	}
+	if got := (WriteTemporaryResult{}).TornSnapshots(); got != nil {
+		t.Errorf("TornSnapshots() on empty result = %v, want nil", got)
+	}
+}
@@ -0,0 +1,83 @@
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// compressedChunkSuffix marks transcript chunk files stored gzip-compressed,
+// e.g. full.jsonl.gz and full.jsonl.001.gz.
+const compressedChunkSuffix = ".gz"
+
+// writeTranscriptChunks splits transcript into chunks, content-defined ones
+// with contentDefined, and adds a blob for each under sessionPath. With
+// compress, each chunk is gzipped; gzip output is deterministic, so unchanged
+// chunks still reuse their blobs.
+func (s *GitStore) writeTranscriptChunks(transcript []byte, agentType agent.AgentType, sessionPath string, compress, contentDefined bool, entries map[string]object.TreeEntry) error {
+	chunk := agent.ChunkTranscript
+	if contentDefined {
+		chunk = agent.ChunkTranscriptContentDefined
+	}
+	chunks, err := chunk(transcript, agentType)
+	if err != nil {
+		return fmt.Errorf("failed to chunk transcript: %w", err)
+	}
+
+	for i, chunk := range chunks {
+		chunkPath := sessionPath + agent.ChunkFileName(paths.TranscriptFileName, i)
+		if compress {
+			chunkPath += compressedChunkSuffix
+			if chunk, err = gzipBytes(chunk); err != nil {
+				return fmt.Errorf("failed to compress transcript chunk: %w", err)
+			}
+		}
+		blobHash, err := CreateBlobFromContent(s.repo, chunk)
+		if err != nil {
+			return fmt.Errorf("failed to create transcript blob: %w", err)
+		}
+		entries[chunkPath] = object.TreeEntry{
+			Name: chunkPath,
+			Mode: filemode.Regular,
+			Hash: blobHash,
+		}
+	}
+	return nil
+}
+
+// transcriptChunkIndex returns the chunk index of a transcript file name
+// (0 for full.jsonl) and whether the chunk is compressed. The index is -1 for
+// other files.
+func transcriptChunkIndex(name string) (int, bool) {
+	base, compressed := strings.CutSuffix(name, compressedChunkSuffix)
+	return agent.ParseChunkIndex(base, paths.TranscriptFileName), compressed
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the caller
+	}
+	if err := w.Close(); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the caller
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipBytes(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the caller
+	}
+	defer r.Close()
+	return io.ReadAll(r) //nolint:wrapcheck // wrapped by the caller
+}
@@ -0,0 +1,141 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestWriteCommitted_CompressedTranscript(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+
+	var transcript strings.Builder
+	writeLines := func(first, n int) {
+		for i := first; i < first+n; i++ {
+			fmt.Fprintf(&transcript, `{"type":"assistant","uuid":"msg-%d","message":"%s"}`+"\n", i, strings.Repeat(fmt.Sprintf("step %d ", i*7919%1000), 40))
+		}
+	}
+	writeLines(0, 2000)
+	first := transcript.String()
+	writeLines(2000, 100)
+	second := transcript.String()
+
+	sessionTree := func(checkpointID id.CheckpointID) *object.Tree {
+		t.Helper()
+		ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+		if err != nil {
+			t.Fatalf("failed to get metadata branch: %v", err)
+		}
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			t.Fatalf("failed to get commit: %v", err)
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			t.Fatalf("failed to get tree: %v", err)
+		}
+		sessionTree, err := tree.Tree(checkpointID.Path() + "/0")
+		if err != nil {
+			t.Fatalf("failed to get session tree: %v", err)
+		}
+		return sessionTree
+	}
+
+	chunks := make([]map[plumbing.Hash]bool, 2)
+	for i, tc := range []struct {
+		checkpointID id.CheckpointID
+		transcript   string
+	}{
+		{id.MustCheckpointID("d0d0d0d0d0d1"), first},
+		{id.MustCheckpointID("d0d0d0d0d0d2"), second},
+	} {
+		err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+			CheckpointID:         tc.checkpointID,
+			SessionID:            "compressed-session",
+			Strategy:             "manual-commit",
+			Agent:                agent.AgentTypeClaudeCode,
+			Transcript:           []byte(tc.transcript),
+			CheckpointsCount:     i + 1,
+			AuthorName:           "Test Author",
+			AuthorEmail:          "test@example.com",
+			CompressTranscript:   true,
+			ContentDefinedChunks: true,
+		})
+		if err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+
+		content, err := store.ReadSessionContent(context.Background(), tc.checkpointID, 0)
+		if err != nil {
+			t.Fatalf("ReadSessionContent() error = %v", err)
+		}
+		if string(content.Transcript) != tc.transcript {
+			t.Fatalf("transcript mismatch for %s: got %d bytes, want %d", tc.checkpointID, len(content.Transcript), len(tc.transcript))
+		}
+
+		chunks[i] = make(map[plumbing.Hash]bool)
+		stored := 0
+		for _, entry := range sessionTree(tc.checkpointID).Entries {
+			idx, compressed := transcriptChunkIndex(entry.Name)
+			if idx < 0 {
+				continue
+			}
+			if !compressed {
+				t.Errorf("chunk %s stored uncompressed", entry.Name)
+			}
+			chunks[i][entry.Hash] = true
+			blob, err := repo.BlobObject(entry.Hash)
+			if err != nil {
+				t.Fatalf("failed to read blob: %v", err)
+			}
+			stored += int(blob.Size)
+		}
+		if len(chunks[i]) < 3 {
+			t.Fatalf("expected the transcript to be stored in several chunks, got %d", len(chunks[i]))
+		}
+		if stored >= len(tc.transcript)/2 {
+			t.Errorf("compressed chunks total %d bytes, want well under %d", stored, len(tc.transcript))
+		}
+	}
+
+	// Compression is deterministic, so unchanged chunks reuse their blobs
+	shared := 0
+	for hash := range chunks[0] {
+		if chunks[1][hash] {
+			shared++
+		}
+	}
+	if shared < len(chunks[0])-1 {
+		t.Errorf("expected all but the last chunk to be reused, %d of %d shared", shared, len(chunks[0]))
+	}
+}
+
+func TestTranscriptChunkIndex(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]struct {
+		index      int
+		compressed bool
+	}{
+		"full.jsonl":        {0, false},
+		"full.jsonl.gz":     {0, true},
+		"full.jsonl.002":    {2, false},
+		"full.jsonl.002.gz": {2, true},
+		"metadata.json":     {-1, false},
+		"full.log":          {-1, false},
+	} {
+		index, compressed := transcriptChunkIndex(name)
+		if index != want.index || compressed != want.compressed {
+			t.Errorf("transcriptChunkIndex(%q) = %d, %v; want %d, %v", name, index, compressed, want.index, want.compressed)
+		}
+	}
+}
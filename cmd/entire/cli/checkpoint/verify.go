@@ -0,0 +1,96 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// VerifyCommitted checks the layout of the local entire/checkpoints/v1 branch
+// and returns one description per problem found: entries outside the sharded
+// <id[:2]>/<id[2:]>/ layout, and checkpoints whose metadata is missing or
+// doesn't parse. ListCommitted and ReadCommitted skip such entries silently.
+// A missing branch is not a problem: no checkpoint has been condensed yet.
+func (s *GitStore) VerifyCommitted(ctx context.Context) ([]string, error) {
+	_ = ctx // Reserved for future use
+
+	ref, err := s.repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", paths.MetadataBranchName(), err)
+	}
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return []string{fmt.Sprintf("tip commit %s cannot be read: %v", ref.Hash().String()[:7], err)}, nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return []string{fmt.Sprintf("tree of tip commit %s cannot be read: %v", ref.Hash().String()[:7], err)}, nil
+	}
+
+	var problems []string
+	for _, bucketEntry := range tree.Entries {
+		if bucketEntry.Mode != filemode.Dir || len(bucketEntry.Name) != 2 {
+			problems = append(problems, fmt.Sprintf("unexpected entry %s at the branch root", bucketEntry.Name))
+			continue
+		}
+		bucketTree, err := s.repo.TreeObject(bucketEntry.Hash)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s/ cannot be read: %v", bucketEntry.Name, err))
+			continue
+		}
+		for _, checkpointEntry := range bucketTree.Entries {
+			path := bucketEntry.Name + "/" + checkpointEntry.Name
+			if checkpointEntry.Mode != filemode.Dir {
+				problems = append(problems, "unexpected file "+path)
+				continue
+			}
+			if _, err := id.NewCheckpointID(bucketEntry.Name + checkpointEntry.Name); err != nil {
+				problems = append(problems, fmt.Sprintf("%s is not a valid checkpoint ID", bucketEntry.Name+checkpointEntry.Name))
+				continue
+			}
+			checkpointTree, err := s.repo.TreeObject(checkpointEntry.Hash)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s/ cannot be read: %v", path, err))
+				continue
+			}
+			problems = append(problems, verifyCheckpointTree(path, checkpointTree)...)
+		}
+	}
+	return problems, nil
+}
+
+// verifyCheckpointTree checks a checkpoint's summary and the metadata of each
+// session it lists.
+func verifyCheckpointTree(path string, checkpointTree *object.Tree) []string {
+	file, err := checkpointTree.File(paths.MetadataFileName)
+	if err != nil {
+		return []string{fmt.Sprintf("%s/%s is missing", path, paths.MetadataFileName)}
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return []string{fmt.Sprintf("%s/%s cannot be read: %v", path, paths.MetadataFileName, err)}
+	}
+	var summary CheckpointSummary
+	if err := json.Unmarshal([]byte(content), &summary); err != nil {
+		return []string{fmt.Sprintf("%s/%s does not parse: %v", path, paths.MetadataFileName, err)}
+	}
+
+	var problems []string
+	for i := range summary.Sessions {
+		if _, ok := readCommittedSessionMetadata(checkpointTree, i); !ok {
+			problems = append(problems, fmt.Sprintf("%s/%d/%s is missing or does not parse", path, i, paths.MetadataFileName))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,94 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestVerifyCommitted(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	ctx := context.Background()
+
+	problems, err := store.VerifyCommitted(ctx)
+	if err != nil {
+		t.Fatalf("VerifyCommitted() without a branch error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("VerifyCommitted() without a branch = %v, want none", problems)
+	}
+
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "verify-session",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(`{"type":"user"}` + "\n"),
+		AuthorName:   "Test Author",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	problems, err = store.VerifyCommitted(ctx)
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("VerifyCommitted() = %v, want none", problems)
+	}
+
+	// Add a stray file to the branch root
+	tip := metadataBranchTip(t, repo)
+	commit, err := repo.CommitObject(tip)
+	if err != nil {
+		t.Fatalf("failed to read tip: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatalf("failed to read tree: %v", err)
+	}
+	blob, err := CreateBlobFromContent(repo, []byte("stray"))
+	if err != nil {
+		t.Fatalf("failed to create blob: %v", err)
+	}
+	strayTree := &object.Tree{Entries: append(tree.Entries, object.TreeEntry{Name: "stray.txt", Mode: filemode.Regular, Hash: blob})}
+	treeObj := repo.Storer.NewEncodedObject()
+	if err := strayTree.Encode(treeObj); err != nil {
+		t.Fatalf("failed to encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(treeObj)
+	if err != nil {
+		t.Fatalf("failed to store tree: %v", err)
+	}
+	sig := object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()}
+	strayCommit := &object.Commit{Author: sig, Committer: sig, Message: "stray", TreeHash: treeHash, ParentHashes: []plumbing.Hash{tip}}
+	commitObj := repo.Storer.NewEncodedObject()
+	if err := strayCommit.Encode(commitObj); err != nil {
+		t.Fatalf("failed to encode commit: %v", err)
+	}
+	commitHash, err := repo.Storer.SetEncodedObject(commitObj)
+	if err != nil {
+		t.Fatalf("failed to store commit: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), commitHash)); err != nil {
+		t.Fatalf("failed to update branch: %v", err)
+	}
+
+	problems, err = store.VerifyCommitted(ctx)
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if len(problems) != 1 || problems[0] != "unexpected entry stray.txt at the branch root" {
+		t.Errorf("VerifyCommitted() = %v, want the stray file reported", problems)
+	}
+}
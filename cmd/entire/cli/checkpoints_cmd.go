@@ -0,0 +1,424 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// defaultTranscriptPreviewLines is how much of the transcript
+// `entire checkpoints show` prints unless --lines is given.
+const defaultTranscriptPreviewLines = 20
+
+// checkpointCommit is a commit on a local branch linked to a checkpoint.
+type checkpointCommit struct {
+	Commit  string `json:"commit"`
+	Subject string `json:"subject"`
+	// Stamped is set when the link comes from `entire stamp` rather than a
+	// trailer on the commit.
+	Stamped bool `json:"stamped,omitempty"`
+}
+
+// checkpointEntry is a committed checkpoint as listed by `entire checkpoints`.
+type checkpointEntry struct {
+	CheckpointID string             `json:"checkpoint_id"`
+	Path         string             `json:"path"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Agent        agent.AgentType    `json:"agent,omitempty"`
+	SessionIDs   []string           `json:"session_ids"`
+	FilesTouched []string           `json:"files_touched"`
+	Commits      []checkpointCommit `json:"commits"`
+	// Imported is set for checkpoints created by `entire import foreign`.
+	Imported bool `json:"imported,omitempty"`
+}
+
+// checkpointShowEntry extends checkpointEntry with what `entire checkpoints show` prints.
+type checkpointShowEntry struct {
+	checkpointEntry
+
+	Prompts           []string `json:"prompts,omitempty"`
+	TranscriptPreview []string `json:"transcript_preview,omitempty"`
+	// Lineage is the chain of sessions the latest session resumed, oldest
+	// first. Empty unless the latest session resumed an earlier one.
+	Lineage []sessionLineageEntry `json:"lineage,omitempty"`
+	// Import describes the foreign attribution an imported checkpoint was
+	// created from.
+	Import *checkpoint.ImportInfo `json:"import,omitempty"`
+}
+
+func newCheckpointsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "checkpoints",
+		Aliases: []string{"checkpoint"},
+		Short:   "Browse checkpoints stored on the " + paths.MetadataBranchName() + " branch",
+	}
+
+	cmd.AddCommand(newCheckpointsListCmd())
+	cmd.AddCommand(newCheckpointsShowCmd())
+
+	return cmd
+}
+
+func newCheckpointsListCmd() *cobra.Command {
+	var limitFlag int
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List committed checkpoints with their sessions, files, and commits",
+		Long: `List the checkpoints stored on the ` + paths.MetadataBranchName() + ` branch, newest first.
+Each row shows when the checkpoint was created, the sessions that contributed
+to it, how many files they touched, and the commits on local branches that
+reference it through an Entire-Checkpoint trailer or 'entire stamp'.
+
+Use --json for output meant for scripts: one JSON object per checkpoint.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if limitFlag < 0 {
+				return errors.New("--limit must not be negative")
+			}
+			return runCheckpointsList(cmd, limitFlag, jsonFlag)
+		},
+	}
+
+	cmd.Flags().IntVar(&limitFlag, "limit", 0, "Show at most this many checkpoints (0 = all)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output one JSON object per checkpoint")
+
+	return cmd
+}
+
+func newCheckpointsShowCmd() *cobra.Command {
+	var linesFlag int
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "show <checkpoint-id>",
+		Short: "Show a committed checkpoint with its commits and a transcript preview",
+		Long: `Show a checkpoint stored on the ` + paths.MetadataBranchName() + ` branch: its sessions,
+agent, files touched, linked commits, prompts, and the start of the latest
+session's transcript. When the latest session resumed an earlier conversation,
+the sessions it continues are listed with their checkpoints. The checkpoint ID
+may be abbreviated to any unique prefix.
+
+Use 'entire explain --checkpoint <id> --full' for the whole transcript.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if linesFlag < 0 {
+				return errors.New("--lines must not be negative")
+			}
+			return runCheckpointsShow(cmd, args[0], linesFlag, jsonFlag)
+		},
+	}
+
+	cmd.Flags().IntVar(&linesFlag, "lines", defaultTranscriptPreviewLines, "Number of transcript lines to preview (0 = none)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the checkpoint as a JSON object")
+
+	return cmd
+}
+
+func runCheckpointsList(cmd *cobra.Command, limit int, asJSON bool) error {
+	repo, committed, err := loadCommittedCheckpoints(cmd)
+	if err != nil {
+		return err
+	}
+	if limit > 0 && len(committed) > limit {
+		committed = committed[:limit]
+	}
+
+	commits, err := linkedCommits(repo, committed)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	entries := make([]checkpointEntry, 0, len(committed))
+	for _, info := range committed {
+		entries = append(entries, newCheckpointEntry(info, commits[info.CheckpointID]))
+	}
+	if asJSON {
+		enc := json.NewEncoder(w)
+		for _, entry := range entries {
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("failed to encode checkpoint: %w", err)
+			}
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No checkpoints on %s.\n", paths.MetadataBranchName())
+		return nil
+	}
+	writeCheckpointList(w, entries)
+	return nil
+}
+
+func runCheckpointsShow(cmd *cobra.Command, prefix string, previewLines int, asJSON bool) error {
+	errW := cmd.ErrOrStderr()
+	repo, committed, err := loadCommittedCheckpoints(cmd)
+	if err != nil {
+		return err
+	}
+
+	info, err := matchCommittedCheckpoint(committed, prefix)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	commits, err := linkedCommits(repo, []checkpoint.CommittedInfo{info})
+	if err != nil {
+		return err
+	}
+	detail := checkpointShowEntry{checkpointEntry: newCheckpointEntry(info, commits[info.CheckpointID])}
+
+	store := checkpoint.NewGitStore(repo)
+	content, err := store.ReadLatestSessionContent(context.Background(), info.CheckpointID)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint content: %w", err)
+	}
+	detail.Prompts = splitPrompts(content.Prompts)
+	detail.Import = content.Metadata.Imported
+	if previewLines > 0 && len(content.Transcript) > 0 {
+		formatted := strings.TrimRight(formatTranscriptBytes(content.Transcript, "", content.Metadata.Agent), "\n")
+		lines := strings.Split(formatted, "\n")
+		detail.TranscriptPreview = lines[:min(previewLines, len(lines))]
+	}
+	if lineage := sessionLineage(context.Background(), store, committed, content.Metadata.SessionID); len(lineage) > 1 {
+		detail.Lineage = lineage
+	}
+
+	w := cmd.OutOrStdout()
+	if asJSON {
+		if err := json.NewEncoder(w).Encode(detail); err != nil {
+			return fmt.Errorf("failed to encode checkpoint: %w", err)
+		}
+		return nil
+	}
+	writeCheckpointDetail(w, detail, len(content.Transcript) > 0)
+	return nil
+}
+
+// loadCommittedCheckpoints opens the repository and lists its committed
+// checkpoints, newest first.
+func loadCommittedCheckpoints(cmd *cobra.Command) (*git.Repository, []checkpoint.CommittedInfo, error) {
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+		return nil, nil, NewSilentError(errors.New("not a git repository"))
+	}
+	committed, err := checkpoint.NewGitStore(repo).ListCommitted(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	return repo, committed, nil
+}
+
+// matchCommittedCheckpoint returns the checkpoint whose ID equals or is
+// uniquely prefixed by prefix.
+func matchCommittedCheckpoint(committed []checkpoint.CommittedInfo, prefix string) (checkpoint.CommittedInfo, error) {
+	var matches []checkpoint.CommittedInfo
+	for _, info := range committed {
+		if info.CheckpointID.String() == prefix {
+			return info, nil
+		}
+		if strings.HasPrefix(info.CheckpointID.String(), prefix) {
+			matches = append(matches, info)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return checkpoint.CommittedInfo{}, fmt.Errorf("checkpoint not found: %s", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.CheckpointID.String())
+		}
+		return checkpoint.CommittedInfo{}, fmt.Errorf("checkpoint prefix %q is ambiguous, matches: %s", prefix, strings.Join(ids, ", "))
+	}
+}
+
+// linkedCommits returns, for each of the given checkpoints, the commits on
+// local branches that reference it by trailer or stamp, newest first.
+func linkedCommits(repo *git.Repository, committed []checkpoint.CommittedInfo) (map[id.CheckpointID][]checkpointCommit, error) {
+	wanted := make(map[id.CheckpointID]struct{}, len(committed))
+	for _, info := range committed {
+		wanted[info.CheckpointID] = struct{}{}
+	}
+	stamps := newStampIndex(committed)
+
+	found := make(map[id.CheckpointID][]*object.Commit)
+	err := forEachLocalBranchCommit(repo, func(c *object.Commit) {
+		for _, cpID := range stamps.checkpointsFor(c) {
+			if _, ok := wanted[cpID]; ok {
+				found[cpID] = append(found[cpID], c)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[id.CheckpointID][]checkpointCommit, len(found))
+	for cpID, commits := range found {
+		slices.SortStableFunc(commits, func(a, b *object.Commit) int {
+			return b.Committer.When.Compare(a.Committer.When)
+		})
+		for _, c := range commits {
+			result[cpID] = append(result[cpID], checkpointCommit{
+				Commit:  c.Hash.String(),
+				Subject: strings.Split(c.Message, "\n")[0],
+				Stamped: !slices.Contains(trailers.ParseAllCheckpoints(c.Message), cpID),
+			})
+		}
+	}
+	return result, nil
+}
+
+func newCheckpointEntry(info checkpoint.CommittedInfo, commits []checkpointCommit) checkpointEntry {
+	entry := checkpointEntry{
+		CheckpointID: info.CheckpointID.String(),
+		Path:         paths.MetadataBranchName() + ":" + info.CheckpointID.Path(),
+		CreatedAt:    info.CreatedAt,
+		Agent:        info.Agent,
+		SessionIDs:   info.SessionIDs,
+		FilesTouched: info.FilesTouched,
+		Commits:      commits,
+		Imported:     info.Imported,
+	}
+	if entry.SessionIDs == nil {
+		entry.SessionIDs = []string{}
+	}
+	if entry.FilesTouched == nil {
+		entry.FilesTouched = []string{}
+	}
+	if entry.Commits == nil {
+		entry.Commits = []checkpointCommit{}
+	}
+	return entry
+}
+
+// writeCheckpointList writes one aligned row per checkpoint.
+func writeCheckpointList(w io.Writer, entries []checkpointEntry) {
+	sessionWidth := len("SESSIONS")
+	for _, e := range entries {
+		sessionWidth = max(sessionWidth, len(checkpointSessionsLabel(e.SessionIDs)))
+	}
+
+	fmt.Fprintf(w, "%-12s  %-16s  %-*s  %5s  %s\n", "CHECKPOINT", "CREATED", sessionWidth, "SESSIONS", "FILES", "COMMITS")
+	for _, e := range entries {
+		commits := make([]string, 0, len(e.Commits))
+		for _, c := range e.Commits {
+			commits = append(commits, strategy.TruncateHash(c.Commit))
+		}
+		commitLabel := strings.Join(commits, ", ")
+		if commitLabel == "" {
+			commitLabel = "-"
+		}
+		fmt.Fprintf(w, "%-12s  %-16s  %-*s  %5d  %s\n",
+			e.CheckpointID,
+			checkpointCreatedLabel(e.CreatedAt),
+			sessionWidth, checkpointSessionsLabel(e.SessionIDs),
+			len(e.FilesTouched),
+			commitLabel)
+	}
+}
+
+// writeCheckpointDetail writes the human-readable form of `entire checkpoints show`.
+func writeCheckpointDetail(w io.Writer, d checkpointShowEntry, hasTranscript bool) {
+	fmt.Fprintf(w, "Checkpoint: %s\n", d.CheckpointID)
+	fmt.Fprintf(w, "Path:       %s\n", d.Path)
+	fmt.Fprintf(w, "Created:    %s\n", checkpointCreatedLabel(d.CreatedAt))
+	if d.Agent != "" {
+		fmt.Fprintf(w, "Agent:      %s\n", d.Agent)
+	}
+	fmt.Fprintf(w, "Sessions:   %s\n", strings.Join(d.SessionIDs, ", "))
+	if d.Import != nil {
+		fmt.Fprintf(w, "Imported:   %s\n", formatImportInfo(d.Import))
+		if d.Import.Evidence != "" {
+			fmt.Fprintf(w, "            %s\n", d.Import.Evidence)
+		}
+	}
+
+	fmt.Fprintf(w, "\nCommits (%d):\n", len(d.Commits))
+	if len(d.Commits) == 0 {
+		fmt.Fprintln(w, "  (none on local branches)")
+	}
+	for _, c := range d.Commits {
+		line := fmt.Sprintf("  %s  %s", strategy.TruncateHash(c.Commit), c.Subject)
+		if c.Stamped {
+			line += "  (stamped)"
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "\nFiles touched (%d):\n", len(d.FilesTouched))
+	for _, f := range d.FilesTouched {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+
+	if len(d.Lineage) > 0 {
+		fmt.Fprintln(w, "\nSession lineage (oldest first):")
+		for _, l := range d.Lineage {
+			checkpoints := strings.Join(l.Checkpoints, ", ")
+			if checkpoints == "" {
+				checkpoints = "no checkpoints"
+			}
+			fmt.Fprintf(w, "  %s  (%s)\n", l.SessionID, checkpoints)
+		}
+	}
+
+	if len(d.Prompts) > 0 {
+		fmt.Fprintf(w, "\nPrompts (%d):\n", len(d.Prompts))
+		for _, p := range d.Prompts {
+			fmt.Fprintf(w, "  - %s\n", strings.ReplaceAll(p, "\n", "\n    "))
+		}
+	}
+
+	if len(d.TranscriptPreview) > 0 {
+		fmt.Fprintln(w, "\nTranscript preview (latest session):")
+		for _, line := range d.TranscriptPreview {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	} else if !hasTranscript {
+		fmt.Fprintln(w, "\nTranscript: (none)")
+	}
+}
+
+func checkpointSessionsLabel(sessionIDs []string) string {
+	switch len(sessionIDs) {
+	case 0:
+		return "-"
+	case 1:
+		return sessionIDs[0]
+	default:
+		return fmt.Sprintf("%s +%d", sessionIDs[len(sessionIDs)-1], len(sessionIDs)-1)
+	}
+}
+
+func checkpointCreatedLabel(t time.Time) string {
+	if t.IsZero() {
+		return unknownPlaceholder
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCheckpointsCmdForTest(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCheckpointsList(t *testing.T) {
+	first, second := setupResolveRepo(t)
+
+	out, err := runCheckpointsCmdForTest(t, newCheckpointsListCmd())
+	require.NoError(t, err, out)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3, out)
+	assert.Contains(t, lines[0], "CHECKPOINT")
+	assert.Contains(t, out, "a1b2c3d4e5f6")
+	assert.Contains(t, out, "2026-04-01-beta +1")
+	assert.Contains(t, out, strategy.TruncateHash(first))
+	assert.Contains(t, out, strategy.TruncateHash(second))
+
+	out, err = runCheckpointsCmdForTest(t, newCheckpointsListCmd(), "--json", "--limit", "1")
+	require.NoError(t, err, out)
+	lines = strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 1, out)
+	var entry checkpointEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.NotEmpty(t, entry.CheckpointID)
+	require.Len(t, entry.Commits, 1)
+	assert.Equal(t, []string{"a.go"}, entry.FilesTouched)
+}
+
+func TestCheckpointsShow(t *testing.T) {
+	first, _ := setupResolveRepo(t)
+
+	out, err := runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "a1b2")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "Checkpoint: a1b2c3d4e5f6\n")
+	assert.Contains(t, out, "Sessions:   2026-04-01-alpha, 2026-04-01-beta\n")
+	assert.Contains(t, out, "  "+strategy.TruncateHash(first)+"  Add a\n")
+	assert.Contains(t, out, "Files touched (1):\n  a.go\n")
+
+	out, err = runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "a1b2c3d4e5f6", "--json")
+	require.NoError(t, err, out)
+	var detail checkpointShowEntry
+	require.NoError(t, json.Unmarshal([]byte(out), &detail))
+	assert.Equal(t, "a1b2c3d4e5f6", detail.CheckpointID)
+	require.Len(t, detail.Commits, 1)
+	assert.Equal(t, first, detail.Commits[0].Commit)
+	assert.False(t, detail.Commits[0].Stamped)
+
+	out, err = runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "ffff")
+	require.Error(t, err)
+	assert.Contains(t, out, "checkpoint not found: ffff")
+}
+
+func TestCheckpointsShow_SessionLineage(t *testing.T) {
+	setupResolveRepo(t)
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	repo, err := git.PlainOpen(root)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:    id.MustCheckpointID("fedcba987654"),
+		SessionID:       "2026-04-02-gamma",
+		Strategy:        "manual-commit",
+		FilesTouched:    []string{"a.go"},
+		ParentSessionID: "2026-04-01-alpha",
+	}))
+
+	out, err := runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "fedcba", "--json")
+	require.NoError(t, err, out)
+	var detail checkpointShowEntry
+	require.NoError(t, json.Unmarshal([]byte(out), &detail))
+	require.Len(t, detail.Lineage, 2)
+	assert.Equal(t, "2026-04-01-alpha", detail.Lineage[0].SessionID)
+	assert.ElementsMatch(t, []string{"a1b2c3d4e5f6", "0123456789ab"}, detail.Lineage[0].Checkpoints)
+	assert.Equal(t, "2026-04-02-gamma", detail.Lineage[1].SessionID)
+	assert.Equal(t, []string{"fedcba987654"}, detail.Lineage[1].Checkpoints)
+
+	out, err = runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "fedcba")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "Session lineage (oldest first):\n  2026-04-01-alpha  (")
+	assert.Contains(t, out, "  2026-04-02-gamma  (fedcba987654)\n")
+
+	out, err = runCheckpointsCmdForTest(t, newCheckpointsShowCmd(), "a1b2")
+	require.NoError(t, err, out)
+	assert.NotContains(t, out, "Session lineage")
+}
+
+func TestWriteCheckpointDetail(t *testing.T) {
+	t.Parallel()
+
+	detail := checkpointShowEntry{
+		checkpointEntry:   checkpointEntry{CheckpointID: "a1b2c3d4e5f6", SessionIDs: []string{"s1"}},
+		Prompts:           []string{"Fix the bug"},
+		TranscriptPreview: []string{"[User] Fix the bug", "[Assistant] Done."},
+	}
+	var buf bytes.Buffer
+	writeCheckpointDetail(&buf, detail, true)
+	out := buf.String()
+	assert.Contains(t, out, "Prompts (1):\n  - Fix the bug\n")
+	assert.Contains(t, out, "Transcript preview (latest session):\n  [User] Fix the bug\n  [Assistant] Done.\n")
+	assert.Contains(t, out, "Commits (0):\n  (none on local branches)\n")
+}
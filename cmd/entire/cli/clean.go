@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,12 +10,15 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/spf13/cobra"
 )
 
 func newCleanCmd() *cobra.Command {
 	var forceFlag bool
+	var allUsersFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "clean",
@@ -42,27 +46,45 @@ This command finds and removes orphaned data from any strategy:
 Default: shows a preview of items that would be deleted.
 With --force, actually deletes the orphaned items.
 
+When state.per_user is enabled, only the current user's session states are
+checked and shadow branches used by other users' sessions are kept. Use
+--all-users to clean up every user's data.
+
 The entire/checkpoints/v1 branch itself is never deleted.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if allUsersFlag {
+				return runCleanAllUsers(cmd.OutOrStdout(), forceFlag)
+			}
 			return runClean(cmd.OutOrStdout(), forceFlag)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Actually delete items (default: dry run)")
+	cmd.Flags().BoolVar(&allUsersFlag, "all-users", false, "Include every user's data when state.per_user is enabled")
 
 	return cmd
 }
 
 func runClean(w io.Writer, force bool) error {
+	return runCleanWithLister(w, force, strategy.ListAllCleanupItems)
+}
+
+// runCleanAllUsers is runClean across every user's session state.
+func runCleanAllUsers(w io.Writer, force bool) error {
+	return runCleanWithLister(w, force, strategy.ListAllCleanupItemsAllUsers)
+}
+
+func runCleanWithLister(w io.Writer, force bool, listItems func() ([]strategy.CleanupItem, error)) error {
 	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
 	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
 	logging.SetLogLevelGetter(GetLogLevel)
+	logging.SetUserNamespaceGetter(settings.StateUserNamespace)
 	if err := logging.Init(""); err == nil {
 		defer logging.Close()
 	}
 
 	// List all cleanup items
-	items, err := strategy.ListAllCleanupItems()
+	items, err := listItems()
 	if err != nil {
 		return fmt.Errorf("failed to list orphaned items: %w", err)
 	}
@@ -93,11 +115,14 @@ func listTempFiles() ([]string, error) {
 		return nil, fmt.Errorf("failed to read temp dir: %w", err)
 	}
 
-	// Build set of active session IDs to protect their temp files
+	// Build set of active session IDs to protect their temp files.
+	// .entire/tmp is shared, so every user's sessions are protected.
 	activeSessionIDs := make(map[string]bool)
-	if states, listErr := strategy.ListSessionStates(); listErr == nil {
-		for _, state := range states {
-			activeSessionIDs[state.SessionID] = true
+	if stores, storesErr := session.NewAllUsersStateStores(); storesErr == nil {
+		if states, listErr := session.ListAllUsers(context.Background(), stores, session.Filter{}); listErr == nil {
+			for _, state := range states {
+				activeSessionIDs[state.SessionID] = true
+			}
 		}
 	}
 
@@ -2,11 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -93,9 +98,9 @@ func TestRunClean_PreviewMode(t *testing.T) {
 	}
 
 	// Also create entire/checkpoints/v1 (should NOT be listed)
-	sessionsRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), commitHash)
+	sessionsRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), commitHash)
 	if err := repo.Storer.SetReference(sessionsRef); err != nil {
-		t.Fatalf("failed to create %s: %v", paths.MetadataBranchName, err)
+		t.Fatalf("failed to create %s: %v", paths.MetadataBranchName(), err)
 	}
 
 	var stdout bytes.Buffer
@@ -120,8 +125,8 @@ func TestRunClean_PreviewMode(t *testing.T) {
 	}
 
 	// Should NOT list entire/checkpoints/v1
-	if strings.Contains(output, paths.MetadataBranchName) {
-		t.Errorf("Should not list '%s', got: %s", paths.MetadataBranchName, output)
+	if strings.Contains(output, paths.MetadataBranchName()) {
+		t.Errorf("Should not list '%s', got: %s", paths.MetadataBranchName(), output)
 	}
 
 	// Should prompt to use --force
@@ -181,7 +186,7 @@ func TestRunClean_SessionsBranchPreserved(t *testing.T) {
 		t.Fatalf("failed to create shadow branch: %v", err)
 	}
 
-	sessionsRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), commitHash)
+	sessionsRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), commitHash)
 	if err := repo.Storer.SetReference(sessionsRef); err != nil {
 		t.Fatalf("failed to create entire/checkpoints/v1: %v", err)
 	}
@@ -199,7 +204,7 @@ func TestRunClean_SessionsBranchPreserved(t *testing.T) {
 	}
 
 	// Sessions branch should still exist
-	sessionsRefName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	sessionsRefName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	if _, err := repo.Reference(sessionsRefName, true); err != nil {
 		t.Error("entire/checkpoints/v1 branch should be preserved")
 	}
@@ -383,3 +388,46 @@ func TestRunCleanWithItems_MixedTypes_Preview(t *testing.T) {
 		t.Errorf("Expected 'Found 3 items to clean', got: %s", output)
 	}
 }
+
+func TestRunClean_PerUserKeepsOtherUsersBranches(t *testing.T) {
+	repo, commitHash := setupCleanTestRepo(t)
+
+	if err := os.MkdirAll(paths.EntireDir, 0o750); err != nil {
+		t.Fatalf("failed to create .entire: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(paths.EntireDir, paths.SettingsFileName),
+		[]byte(`{"enabled": true, "state": {"per_user": true}}`), 0o600); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+
+	// Another user has an active session checkpointing to this shadow branch.
+	otherStore := session.NewStateStoreWithDir(filepath.Join(".git", session.SessionStateDirName, session.UsersDirName, "other-user"))
+	if err := otherStore.Save(context.Background(), &session.State{
+		SessionID:  "other-user-session",
+		BaseCommit: commitHash.String(),
+		StartedAt:  time.Now(),
+		Phase:      session.PhaseActive,
+	}); err != nil {
+		t.Fatalf("failed to save other user's session: %v", err)
+	}
+	branch := checkpoint.ShadowBranchNameForCommit(commitHash.String(), "")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), commitHash)); err != nil {
+		t.Fatalf("failed to create branch %s: %v", branch, err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runClean(&stdout, false); err != nil {
+		t.Fatalf("runClean() error = %v", err)
+	}
+	if strings.Contains(stdout.String(), branch) {
+		t.Errorf("runClean() should keep another user's shadow branch, got: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := runCleanAllUsers(&stdout, false); err != nil {
+		t.Fatalf("runCleanAllUsers() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), branch) {
+		t.Errorf("runCleanAllUsers() should list %s, got: %s", branch, stdout.String())
+	}
+}
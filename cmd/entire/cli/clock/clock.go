@@ -0,0 +1,62 @@
+// Package clock provides an injectable time source. Strategies and stores take
+// a Clock instead of calling time.Now directly, so tests and replay tooling can
+// make timestamps reproducible.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock reports the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// System is the Clock backed by time.Now.
+var System Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// OrSystem returns c, or System if c is nil. Types with an optional Clock
+// field use it so their zero value reads the real time.
+func OrSystem(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
+
+// Fake is a Clock that only moves when told to. It is safe for concurrent use.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a Fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake clock's current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the fake clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+// Set moves the fake clock to now.
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
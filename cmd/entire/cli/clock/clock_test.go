@@ -0,0 +1,39 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := NewFake(start)
+	if got := f.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+
+	f.Advance(90 * time.Second)
+	if got, want := f.Now(), start.Add(90*time.Second); !got.Equal(want) {
+		t.Errorf("Now() after Advance = %v, want %v", got, want)
+	}
+
+	later := start.AddDate(0, 1, 0)
+	f.Set(later)
+	if got := f.Now(); !got.Equal(later) {
+		t.Errorf("Now() after Set = %v, want %v", got, later)
+	}
+}
+
+func TestOrSystem(t *testing.T) {
+	t.Parallel()
+
+	if OrSystem(nil) != System {
+		t.Error("OrSystem(nil) should return System")
+	}
+	f := NewFake(time.Time{})
+	if OrSystem(f) != f {
+		t.Error("OrSystem(f) should return f")
+	}
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+func newCommitCmd() *cobra.Command {
+	var sessionFlag string
+	var allFlag bool
+	var messageFlag string
+	var patchFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "commit",
+		Short: "Pick files from a session's checkpoints and commit them",
+		Long: `Commit the files an agent session has checkpointed, linked to the session.
+
+The files that differ between the session's base commit and its latest
+checkpoint are listed for you to pick from; all are selected to start with.
+The picked files are staged as 'entire stage' would, and committed with a
+message generated from the session's last prompt (or
+strategy_options.commit_message_template), which you can edit before it is
+used. The commit gets an Entire-Checkpoint trailer, so the session is
+condensed under it as with any linked commit.
+
+Files that are already staged but not picked would be committed too, so the
+command stops if there are any.
+
+By default the command applies to the session running in this worktree; pass
+--session when there are several. With --all, every checkpointed file is
+committed without asking; without a terminal, the generated message is then
+used as is.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runCommit(cmd, sessionFlag, allFlag, messageFlag, patchFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "Session ID or unique prefix (default: the session in this worktree)")
+	cmd.Flags().BoolVarP(&allFlag, "all", "a", false, "Commit every checkpointed file without asking")
+	cmd.Flags().StringVarP(&messageFlag, "message", "m", "", "Commit message to use instead of the generated one")
+	cmd.Flags().BoolVarP(&patchFlag, "patch", "p", false, "Stage only the session's hunks, using its latest checkpoint as the reference")
+
+	return cmd
+}
+
+func runCommit(cmd *cobra.Command, sessionPrefix string, all bool, message string, patch bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	fail := func(err error) error {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return fail(errors.New("not a git repository"))
+	}
+
+	var state *session.State
+	if sessionPrefix != "" {
+		state, err = findSessionState(ctx, sessionPrefix)
+	} else {
+		state, err = worktreeSession(ctx)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	strat, ok := GetStrategy().(*strategy.ManualCommitStrategy)
+	if !ok {
+		return fail(errors.New("entire commit needs shadow-branch checkpoints, which the patch-file strategy does not create"))
+	}
+
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+	if _, err := gitOutput(ctx, repoRoot, nil, "rev-parse", "--verify", "--quiet", "refs/heads/"+shadowBranch); err != nil {
+		fmt.Fprintf(w, "Session %s has no uncommitted checkpoints since %s.\n",
+			state.SessionID, strategy.TruncateHash(state.BaseCommit))
+		return nil
+	}
+	files, err := checkpointedSessionFiles(ctx, repoRoot, state, shadowBranch)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(w, "Session %s has no checkpointed changes to commit.\n", state.SessionID)
+		return nil
+	}
+
+	interactive := canPromptInteractively()
+	if !all && !interactive {
+		return fail(errors.New("cannot ask which files to commit without a terminal; pass --all"))
+	}
+
+	selected := files
+	if !all {
+		if selected, err = selectCommitFiles(files); err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			fmt.Fprintln(w, "No files selected.")
+			return nil
+		}
+	}
+
+	staged, err := gitOutput(ctx, repoRoot, nil, "diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return err
+	}
+	var others []string
+	for _, f := range splitNUL(staged) {
+		if !slices.Contains(selected, f) {
+			others = append(others, f)
+		}
+	}
+	if len(others) > 0 {
+		return fail(fmt.Errorf("other changes are already staged (%s); unstage them or commit them first", strings.Join(others, ", ")))
+	}
+
+	if message == "" {
+		lastPrompt, err := strat.SessionLastPrompt(state.SessionID)
+		if err != nil {
+			return fmt.Errorf("failed to read session prompts: %w", err)
+		}
+		message = templatedCommitMessage(commitMessageData{
+			Subject:      generateCommitMessage(lastPrompt),
+			Prompt:       lastPrompt,
+			SessionID:    state.SessionID,
+			FilesTouched: selected,
+		})
+		if interactive {
+			if message, err = editCommitMessage(message); err != nil {
+				return err
+			}
+		}
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return fail(errors.New("aborting commit due to empty commit message"))
+	}
+
+	if patch {
+		err = stageSessionHunks(ctx, repoRoot, state.BaseCommit, shadowBranch, selected)
+	} else {
+		err = stageSessionFiles(ctx, repoRoot, selected)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	staged, err = gitOutput(ctx, repoRoot, nil, append([]string{"diff", "--cached", "--name-only", "-z", "--"}, selected...)...)
+	if err != nil {
+		return err
+	}
+	if len(splitNUL(staged)) == 0 {
+		fmt.Fprintf(w, "No changes from session %s to commit.\n", state.SessionID)
+		return nil
+	}
+
+	message, err = strat.AddSessionCommitTrailers(state.SessionID, message)
+	if err != nil {
+		return err
+	}
+	// Run git directly so its hooks and output reach the user
+	commitCmd := exec.CommandContext(ctx, "git", "commit", "-F", "-")
+	commitCmd.Dir = repoRoot
+	commitCmd.Stdin = strings.NewReader(message)
+	commitCmd.Stdout = w
+	commitCmd.Stderr = errW
+	if err := commitCmd.Run(); err != nil {
+		return fail(fmt.Errorf("git commit failed: %w", err))
+	}
+	return nil
+}
+
+// checkpointedSessionFiles returns the session's files that differ between
+// its base commit and the tip of its shadow branch, i.e. as of its latest
+// checkpoint.
+func checkpointedSessionFiles(ctx context.Context, repoRoot string, state *session.State, shadowBranch string) ([]string, error) {
+	files := sessionStageFiles(state)
+	if len(files) == 0 {
+		return nil, nil
+	}
+	args := append([]string{"diff", "--name-only", "--no-renames", "-z", state.BaseCommit, "refs/heads/" + shadowBranch, "--"}, files...)
+	out, err := gitOutput(ctx, repoRoot, nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// selectCommitFiles asks which of files to commit, with all selected to start.
+func selectCommitFiles(files []string) ([]string, error) {
+	options := make([]huh.Option[string], 0, len(files))
+	for _, f := range files {
+		options = append(options, huh.NewOption(f, f).Selected(true))
+	}
+
+	var selected []string
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title("Select the files to commit").
+				Description("Files the session has checkpointed since its base commit").
+				Options(options...).
+				Value(&selected),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return nil, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return selected, nil
+}
+
+// editCommitMessage lets the user edit the generated commit message.
+func editCommitMessage(message string) (string, error) {
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewText().
+				Title("Commit message").
+				Description("Trailers linking the commit to the session are added when committing").
+				Value(&message),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", fmt.Errorf("commit cancelled: %w", err)
+	}
+	return message, nil
+}
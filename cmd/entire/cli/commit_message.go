@@ -1,15 +1,24 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"text/template"
 
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/stringutil"
 )
 
+// maxCommitSubjectWidth is the default display width of generated commit subjects.
+const maxCommitSubjectWidth = 72
+
 // generateCommitMessage creates a commit message from the user's original prompt
 func generateCommitMessage(originalPrompt string) string {
 	if originalPrompt != "" {
-		cleaned := cleanPromptForCommit(originalPrompt)
+		cleaned := cleanPromptForCommit(originalPrompt, settings.PromptSummaryOptions(maxCommitSubjectWidth))
 		if cleaned != "" {
 			return cleaned
 		}
@@ -18,9 +27,68 @@ func generateCommitMessage(originalPrompt string) string {
 	return "Claude Code session updates"
 }
 
+// commitMessageData holds the fields available to
+// strategy_options.commit_message_template.
+type commitMessageData struct {
+	// Subject is the message generated without a template.
+	Subject      string
+	Prompt       string
+	Summary      string
+	SessionID    string
+	FilesTouched []string
+}
+
+// templatedCommitMessage renders the commit_message_template setting with
+// data. It falls back to data.Subject when no template is set or the template
+// fails, so a broken template never blocks a checkpoint.
+func templatedCommitMessage(data commitMessageData) string {
+	s, err := settings.Load()
+	if err != nil {
+		return data.Subject
+	}
+	tmpl := s.CommitMessageTemplate()
+	if tmpl == "" {
+		return data.Subject
+	}
+	message, err := renderCommitMessage(tmpl, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: commit_message_template: %v; using the default message\n", err)
+		return data.Subject
+	}
+	return message
+}
+
+// renderCommitMessage executes tmpl, a text/template, with data. Templates can
+// use join to list files, e.g. {{join .FilesTouched ", "}}.
+func renderCommitMessage(tmpl string, data commitMessageData) (string, error) {
+	t, err := template.New("commit_message_template").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing template: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("executing template: %w", err)
+	}
+	message := strings.TrimSpace(sb.String())
+	if message == "" {
+		return "", errors.New("template produced an empty message")
+	}
+	return message, nil
+}
+
+// touchedFiles merges file lists into one sorted list without duplicates.
+func touchedFiles(lists ...[]string) []string {
+	files := slices.Concat(lists...)
+	slices.Sort(files)
+	return slices.Compact(files)
+}
+
 // cleanPromptForCommit cleans up a user prompt to make it suitable as a commit message
-// Uses a loop to remove all matching prefixes until none remain
-func cleanPromptForCommit(prompt string) string {
+// Uses a loop to remove all matching prefixes until none remain, then shortens
+// the result according to opts.
+func cleanPromptForCommit(prompt string, opts stringutil.SummaryOptions) string {
 	cleaned := prompt
 
 	prefixes := []string{
@@ -54,11 +122,10 @@ func cleanPromptForCommit(prompt string) string {
 		}
 	}
 
-	cleaned = strings.TrimSuffix(cleaned, "?")
-	cleaned = strings.TrimSpace(cleaned)
-
-	// Truncate to 72 characters (rune-safe for multi-byte UTF-8)
-	cleaned = stringutil.TruncateRunes(cleaned, 72, "")
+	// Shorten to the first sentence and configured width without splitting
+	// words or multi-byte characters, then drop the sentence terminator.
+	cleaned = stringutil.Summarize(cleaned, opts)
+	cleaned = strings.TrimRight(cleaned, "?!.。？！")
 	cleaned = strings.TrimSpace(cleaned)
 
 	// Capitalize first letter (rune-safe for multi-byte UTF-8)
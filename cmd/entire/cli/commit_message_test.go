@@ -2,6 +2,8 @@ package cli
 
 import (
 	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
 )
 
 func TestCleanPromptForCommit(t *testing.T) {
@@ -171,11 +173,38 @@ func TestCleanPromptForCommit(t *testing.T) {
 			input:    "Can you ?",
 			expected: "",
 		},
+		// First sentence extraction
+		{
+			name:     "keeps only the first sentence",
+			input:    "Can you fix the login bug? It crashes when the password is empty.",
+			expected: "Fix the login bug",
+		},
+		{
+			name:     "uses the first line of a multi-line prompt",
+			input:    "Add retries to the uploader\n\nThe S3 client times out under load.",
+			expected: "Add retries to the uploader",
+		},
+		{
+			name:     "does not split on periods inside file names",
+			input:    "Please update main.go to use the new API",
+			expected: "Update main.go to use the new API",
+		},
+		// CJK text
+		{
+			name:     "truncates CJK text by display width",
+			input:    "ユーザー認証のバグを修正して、ログイン画面でパスワードが空のときにクラッシュしないようにしてください",
+			expected: "ユーザー認証のバグを修正して、ログイン画面でパスワードが空のときにクラッ",
+		},
+		{
+			name:     "stops at a full-width sentence terminator",
+			input:    "修复登录错误。密码为空时会崩溃。",
+			expected: "修复登录错误",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := cleanPromptForCommit(tt.input)
+			result := cleanPromptForCommit(tt.input, stringutil.SummaryOptions{MaxWidth: maxCommitSubjectWidth, FirstSentence: true})
 			if result != tt.expected {
 				t.Errorf("cleanPromptForCommit(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
@@ -230,3 +259,87 @@ func TestGenerateCommitMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderCommitMessage(t *testing.T) {
+	t.Parallel()
+
+	data := commitMessageData{
+		Subject:      "Fix the login bug",
+		Prompt:       "Can you fix the login bug?",
+		Summary:      "Fixed token refresh",
+		SessionID:    "2026-01-01-abc",
+		FilesTouched: []string{"auth.go", "auth_test.go"},
+	}
+
+	tests := []struct {
+		name     string
+		tmpl     string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "conventional commit",
+			tmpl:     "fix: {{.Subject}}\n\n{{.Summary}}\n\nSession: {{.SessionID}}\n",
+			expected: "fix: Fix the login bug\n\nFixed token refresh\n\nSession: 2026-01-01-abc",
+		},
+		{
+			name:     "joins files",
+			tmpl:     "chore: update {{join .FilesTouched \", \"}}",
+			expected: "chore: update auth.go, auth_test.go",
+		},
+		{
+			name:    "unknown field",
+			tmpl:    "{{.Ticket}}",
+			wantErr: true,
+		},
+		{
+			name:    "parse error",
+			tmpl:    "{{.Subject",
+			wantErr: true,
+		},
+		{
+			name:    "empty result",
+			tmpl:    "{{if false}}x{{end}}  ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := renderCommitMessage(tt.tmpl, data)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("renderCommitMessage(%q) = %q, want error", tt.tmpl, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderCommitMessage(%q) error: %v", tt.tmpl, err)
+			}
+			if result != tt.expected {
+				t.Errorf("renderCommitMessage(%q) = %q, want %q", tt.tmpl, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTemplatedCommitMessage_FallsBack(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupCleanTestRepo(t)
+
+	data := commitMessageData{Subject: "Fix the login bug", SessionID: "2026-01-01-abc"}
+	if got := templatedCommitMessage(data); got != data.Subject {
+		t.Errorf("without a template = %q, want %q", got, data.Subject)
+	}
+
+	writeSettings(t, `{"strategy_options": {"commit_message_template": "feat: {{.Subject}} ({{.SessionID}})"}}`)
+	if got, want := templatedCommitMessage(data), "feat: Fix the login bug (2026-01-01-abc)"; got != want {
+		t.Errorf("with a template = %q, want %q", got, want)
+	}
+
+	writeSettings(t, `{"strategy_options": {"commit_message_template": "{{.Nope}}"}}`)
+	if got := templatedCommitMessage(data); got != data.Subject {
+		t.Errorf("with a broken template = %q, want %q", got, data.Subject)
+	}
+}
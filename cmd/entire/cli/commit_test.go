@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCommitForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newCommitCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCommit(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	t.Setenv("ENTIRE_TEST_TTY", "0")
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.WriteFile(t, tmpDir, "notes.md", "notes\n")
+	testutil.GitAdd(t, tmpDir, "main.go", "notes.md")
+	testutil.GitCommit(t, tmpDir, "initial")
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	const sessionID = "2026-03-01-commit-session"
+	metadataDir := ".entire/metadata/" + sessionID
+	testutil.WriteFile(t, tmpDir, metadataDir+"/"+paths.TranscriptFileName, "{}\n")
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() { run() }\n")
+	testutil.WriteFile(t, tmpDir, "run.go", "package main\n\nfunc run() {}\n")
+	require.NoError(t, strategy.NewManualCommitStrategy().SaveStep(strategy.StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  []string{"main.go"},
+		NewFiles:       []string{"run.go"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: filepath.Join(tmpDir, metadataDir),
+		CommitMessage:  "Add run",
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}))
+	// The user's own edit, which is not part of the session
+	testutil.WriteFile(t, tmpDir, "notes.md", "more notes\n")
+
+	out, err := runCommitForTest(t, "--session", sessionID)
+	require.Error(t, err)
+	assert.Contains(t, out, "pass --all")
+
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		c := exec.CommandContext(t.Context(), "git", args...)
+		c.Dir = tmpDir
+		output, err := c.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return string(output)
+	}
+
+	gitCmd("add", "notes.md")
+	out, err = runCommitForTest(t, "--session", sessionID, "--all", "-m", "Add run helper")
+	require.Error(t, err)
+	assert.Contains(t, out, "other changes are already staged (notes.md)")
+	gitCmd("reset", "-q", "notes.md")
+
+	out, err = runCommitForTest(t, "--session", sessionID, "--all", "-m", "Add run helper")
+	require.NoError(t, err, out)
+
+	message := gitCmd("log", "-1", "--format=%B")
+	assert.True(t, strings.HasPrefix(message, "Add run helper\n\n"), message)
+	_, found := trailers.ParseCheckpoint(message)
+	assert.True(t, found, "the commit is linked to the session")
+	assert.Equal(t, "main.go\nrun.go\n", gitCmd("show", "--name-only", "--format=", "HEAD"))
+	assert.Equal(t, " M notes.md\n", gitCmd("status", "--porcelain", "--", "notes.md"), "files outside the session stay uncommitted")
+}
@@ -0,0 +1,227 @@
+// Package compliance runs a user-configured license scanner over the files an
+// agent changed, at the end of each turn. Its findings, such as newly added
+// dependencies or files without a license header, are kept in the session
+// state and recorded on the checkpoint, so compliance review of AI-written
+// code can start from the checkpoint instead of from scratch.
+package compliance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/shellcmd"
+)
+
+const (
+	// DefaultTimeout bounds a scan when no timeout is configured.
+	DefaultTimeout = 5 * time.Minute
+	// MaxFindings caps the findings kept from one scan.
+	MaxFindings = 200
+	// maxErrorSize caps the stored stderr of a failed scan, keeping its tail.
+	maxErrorSize = 4 * 1024
+)
+
+// Well-known finding kinds. Scanners may report others.
+const (
+	KindDependency    = "dependency"
+	KindLicenseHeader = "license_header"
+)
+
+// Finding is one issue reported by the scanner.
+type Finding struct {
+	// Kind classifies the finding, e.g. "dependency" or "license_header".
+	Kind string `json:"kind"`
+	// File is the repository-relative file the finding is about.
+	File string `json:"file,omitempty"`
+	// Line is the 1-based line in File, if known.
+	Line int `json:"line,omitempty"`
+	// Package is the dependency a "dependency" finding is about.
+	Package string `json:"package,omitempty"`
+	// License is the detected license, e.g. an SPDX identifier.
+	License string `json:"license,omitempty"`
+	// Message explains the finding.
+	Message string `json:"message,omitempty"`
+}
+
+// String describes the finding in one line.
+func (f Finding) String() string {
+	var sb strings.Builder
+	sb.WriteString(f.Kind)
+	if f.Package != "" {
+		sb.WriteString(" " + f.Package)
+	}
+	if f.License != "" {
+		sb.WriteString(" (" + f.License + ")")
+	}
+	if f.File != "" {
+		sb.WriteString(" in " + f.File)
+		if f.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", f.Line)
+		}
+	}
+	if f.Message != "" {
+		sb.WriteString(": " + f.Message)
+	}
+	return sb.String()
+}
+
+// Report is the outcome of one scan.
+type Report struct {
+	// Command is the scanner command that was run.
+	Command string `json:"command"`
+	// Files are the files the scanner was run against.
+	Files []string `json:"files,omitempty"`
+	// Findings are the scanner's findings, at most MaxFindings.
+	Findings []Finding `json:"findings,omitempty"`
+	// Truncated is set when the scanner reported more than MaxFindings.
+	Truncated bool `json:"truncated,omitempty"`
+	// Error is set when the scanner failed, timed out, or printed output
+	// that is not findings. Findings are empty then.
+	Error string `json:"error,omitempty"`
+	// RanAt is when the scan started.
+	RanAt time.Time `json:"ran_at"`
+}
+
+// Summary describes the report in one line, e.g.
+// "3 findings (2 dependency, 1 license_header)".
+func (r *Report) Summary() string {
+	switch {
+	case r.Error != "":
+		return "scan failed"
+	case len(r.Findings) == 0:
+		return "no findings"
+	}
+
+	counts := r.CountByKind()
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+	}
+
+	total := fmt.Sprintf("%d finding", len(r.Findings))
+	if len(r.Findings) != 1 {
+		total += "s"
+	}
+	if r.Truncated {
+		total = "more than " + total
+	}
+	return total + " (" + strings.Join(parts, ", ") + ")"
+}
+
+// CountByKind returns the number of findings of each kind.
+func (r *Report) CountByKind() map[string]int {
+	counts := make(map[string]int)
+	for _, f := range r.Findings {
+		counts[f.Kind]++
+	}
+	return counts
+}
+
+// Runner runs a license scanner.
+type Runner struct {
+	// Command is run with `sh -c`. The files are passed as positional
+	// parameters ("$@") and, newline-separated, in ENTIRE_FILES_TOUCHED.
+	// It prints findings to stdout as a JSON array or as one JSON object
+	// per line, and exits 0 whether or not it found anything.
+	Command string
+	// Dir is the working directory, normally the repository root.
+	Dir string
+	// Timeout bounds the command. If zero, DefaultTimeout is used.
+	Timeout time.Duration
+}
+
+// Run scans files. A scanner that fails or prints something other than
+// findings produces a report with Error set; an error means the command
+// could not be run at all.
+func (r *Runner) Run(ctx context.Context, files []string) (*Report, error) {
+	if strings.TrimSpace(r.Command) == "" {
+		return nil, errors.New("no compliance scan command configured")
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runner := &shellcmd.Runner{Command: r.Command, Name: "entire-compliance-scan", Dir: r.Dir, Timeout: timeout}
+	report := &Report{
+		Command: r.Command,
+		Files:   files,
+		RanAt:   time.Now(),
+	}
+	run, err := runner.Run(ctx, files)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already describes the command
+	}
+	if run.ExitCode != 0 {
+		if run.TimedOut {
+			report.Error = "timed out after " + timeout.String()
+		} else {
+			report.Error = fmt.Sprintf("exit %d", run.ExitCode)
+		}
+		if msg := strings.TrimSpace(string(run.Stderr)); msg != "" {
+			report.Error += ": " + shellcmd.Tail(msg, maxErrorSize)
+		}
+		return report, nil
+	}
+
+	findings, err := ParseFindings(run.Stdout)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	if len(findings) > MaxFindings {
+		findings = findings[:MaxFindings]
+		report.Truncated = true
+	}
+	report.Findings = findings
+	return report, nil
+}
+
+// ParseFindings decodes scanner output: a JSON array of findings, or one
+// finding object per line. Empty output means no findings. Findings without
+// a kind are reported as "other".
+func ParseFindings(data []byte) ([]Finding, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var findings []Finding
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &findings); err != nil {
+			return nil, fmt.Errorf("invalid scanner output: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var f Finding
+			err := dec.Decode(&f)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid scanner output: %w", err)
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	for i := range findings {
+		if findings[i].Kind == "" {
+			findings[i].Kind = "other"
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,94 @@
+package compliance
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{
+		Command: `for f in "$@"; do echo "{\"kind\": \"license_header\", \"file\": \"$f\", \"message\": \"missing header\"}"; done
+echo '{"kind": "dependency", "package": "left-pad", "license": "WTFPL", "file": "package.json"}'`,
+		Dir: t.TempDir(),
+	}
+	report, err := r.Run(context.Background(), []string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Error != "" {
+		t.Fatalf("Run() Error = %q", report.Error)
+	}
+	if len(report.Findings) != 3 {
+		t.Fatalf("got %d findings, want 3: %+v", len(report.Findings), report.Findings)
+	}
+	if got := report.Findings[0].String(); got != "license_header in a.go: missing header" {
+		t.Errorf("Findings[0].String() = %q", got)
+	}
+	if got := report.Findings[2].String(); got != "dependency left-pad (WTFPL) in package.json" {
+		t.Errorf("Findings[2].String() = %q", got)
+	}
+	if got := report.Summary(); got != "3 findings (1 dependency, 2 license_header)" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestRunner_RunFails(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `echo "scanner crashed" >&2; exit 2`, Dir: t.TempDir()}
+	report, err := r.Run(context.Background(), []string{"a.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if report.Error != "exit 2: scanner crashed" {
+		t.Errorf("Error = %q", report.Error)
+	}
+	if report.Summary() != "scan failed" {
+		t.Errorf("Summary() = %q", report.Summary())
+	}
+
+	r = &Runner{Command: `echo "not json"`, Dir: t.TempDir()}
+	report, err = r.Run(context.Background(), []string{"a.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.HasPrefix(report.Error, "invalid scanner output") {
+		t.Errorf("Error = %q, want invalid scanner output", report.Error)
+	}
+}
+
+func TestRunner_RunTruncates(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `i=0; while [ $i -lt 250 ]; do echo '{"kind": "dependency"}'; i=$((i+1)); done`, Dir: t.TempDir()}
+	report, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Findings) != MaxFindings || !report.Truncated {
+		t.Errorf("got %d findings (truncated %v), want %d truncated", len(report.Findings), report.Truncated, MaxFindings)
+	}
+	if got := report.Summary(); got != "more than 200 findings (200 dependency)" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestParseFindings(t *testing.T) {
+	t.Parallel()
+
+	findings, err := ParseFindings([]byte(`[{"kind": "dependency", "package": "x"}, {"file": "a.go"}]`))
+	if err != nil {
+		t.Fatalf("ParseFindings() error = %v", err)
+	}
+	if len(findings) != 2 || findings[1].Kind != "other" {
+		t.Errorf("ParseFindings() = %+v, want 2 findings with the second of kind other", findings)
+	}
+
+	findings, err = ParseFindings([]byte("  \n"))
+	if err != nil || findings != nil {
+		t.Errorf("ParseFindings(empty) = %v, %v; want no findings", findings, err)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/redact"
 
 	// Import claudecode to register the agent
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
@@ -59,11 +61,33 @@ func IsEnabled() (bool, error) {
 	return s.Enabled, nil
 }
 
-// GetStrategy returns the configured strategy instance.
-// Falls back to default if the configured strategy is not found.
-//
+// IsEnabledOnCurrentBranch returns true if Entire is enabled and the
+// enabled_branches / disabled_branches patterns allow the current branch.
+// Hooks use this to stay inert on branches such as main or release/*.
+func IsEnabledOnCurrentBranch(s *EntireSettings) bool {
+	return s.IsEnabledOnBranch(currentBranchForSettings())
+}
+
+// currentBranchForSettings returns the branch that branch patterns are
+// matched against, or empty when HEAD is detached or cannot be read.
+func currentBranchForSettings() string {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return ""
+	}
+	branch := resolveWorktreeBranch(repoRoot)
+	if branch == detachedHEADDisplay {
+		return ""
+	}
+	return branch
+}
 
+// GetStrategy returns the configured strategy instance.
+// Falls back to manual-commit unless the settings select "patch-file".
 func GetStrategy() strategy.Strategy {
+	if s, err := settings.Load(); err == nil && s.UsesPatchFileStrategy() {
+		return strategy.NewPatchFileStrategy()
+	}
 	return strategy.NewManualCommitStrategy()
 }
 
@@ -78,6 +102,36 @@ func GetLogLevel() string {
 	return s.LogLevel
 }
 
+// GetLocale returns the locale from settings for user-facing messages.
+// Returns empty string if not configured or settings cannot be loaded.
+func GetLocale() string {
+	s, err := settings.Load()
+	if err != nil {
+		return ""
+	}
+	return s.Locale
+}
+
+// GetNamespace returns the "namespace" setting, or the defaults when settings
+// can't be loaded.
+func GetNamespace() paths.Namespace {
+	s, err := settings.Load()
+	if err != nil {
+		return paths.Namespace{}
+	}
+	return s.RefNamespace()
+}
+
+// GetRedactionRules returns the "redaction" setting, or no rules when
+// settings can't be loaded.
+func GetRedactionRules() redact.Rules {
+	s, err := settings.Load()
+	if err != nil {
+		return redact.Rules{}
+	}
+	return s.RedactionRules()
+}
+
 // GetAgentsWithHooksInstalled returns names of agents that have hooks installed.
 func GetAgentsWithHooksInstalled() []agent.AgentName {
 	var installed []agent.AgentName
@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/hookdaemon"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+	"github.com/spf13/cobra"
+)
+
+// HookDaemonEnvVar set to "0" makes hooks run directly even when a hook
+// daemon is serving the working tree.
+const HookDaemonEnvVar = "ENTIRE_HOOK_DAEMON"
+
+func newDaemonCmd() *cobra.Command {
+	var serveHooksFlag bool
+	var idleTimeoutFlag time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run hooks in a long-lived process to speed them up",
+		Long: `Keep a warm 'entire' process running for this working tree. Hooks started
+by git and by agents hand their work to it over a unix socket instead of
+setting everything up again on every call.
+
+  entire daemon --serve-hooks
+
+The daemon runs in the foreground until interrupted, or until it has been
+idle for --idle-timeout. Hooks run one at a time. When no daemon is running,
+or it cannot take a request, hooks run directly as usual. Set
+` + HookDaemonEnvVar + `=0 to always run them directly.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if !serveHooksFlag {
+				return cmd.Help()
+			}
+			return runHookDaemon(cmd, idleTimeoutFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&serveHooksFlag, "serve-hooks", false, "Serve hook invocations for this working tree")
+	cmd.Flags().DurationVar(&idleTimeoutFlag, "idle-timeout", 0, "Exit after this long without hook invocations (default: never)")
+
+	return cmd
+}
+
+func runHookDaemon(cmd *cobra.Command, idleTimeout time.Duration) error {
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	socketPath := hookdaemon.SocketPath(root)
+	listener, err := hookdaemon.Listen(socketPath)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	fmt.Fprintf(w, "Serving hooks for %s on %s\n", root, socketPath)
+	server := &hookdaemon.Server{
+		Handler:     runHookRequest,
+		Version:     hookDaemonVersion(),
+		IdleTimeout: idleTimeout,
+	}
+	if err := server.Serve(cmd.Context(), listener); err != nil {
+		return fmt.Errorf("hook daemon stopped: %w", err)
+	}
+	fmt.Fprintln(w, "Hook daemon stopped.")
+	return nil
+}
+
+// hookDaemonVersion identifies the binary, so a daemon started before an
+// upgrade is not used by the new version's hooks.
+func hookDaemonVersion() string {
+	return buildinfo.Version + "+" + buildinfo.Commit
+}
+
+// ForwardHookToDaemon runs an `entire hooks ...` invocation in the hook
+// daemon serving the current working tree, if there is one. It returns the
+// exit code and true when the daemon ran the hook, or false when the caller
+// should run it directly. It is called before the command tree is built, so
+// it does as little as possible when no daemon is running.
+func ForwardHookToDaemon(args []string) (int, bool) {
+	if !forwardableHook(args) || os.Getenv(HookDaemonEnvVar) == "0" {
+		return 0, false
+	}
+	// Only a direct run can prompt the user in their terminal.
+	if isGitHook(args, "prepare-commit-msg") && canPromptInteractively() {
+		return 0, false
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return 0, false
+	}
+	root, ok := hookdaemon.FindWorktreeRoot(dir)
+	if !ok {
+		return 0, false
+	}
+	socketPath := hookdaemon.SocketPath(root)
+	if !hookdaemon.Available(socketPath) {
+		return 0, false
+	}
+
+	req := &hookdaemon.Request{
+		Version: hookDaemonVersion(),
+		Args:    args,
+		Dir:     dir,
+		Env:     os.Environ(),
+	}
+	// Git hooks take their input as arguments, except post-rewrite, which
+	// reads the rewritten commits; agent hooks read a payload.
+	if args[1] != "git" || isGitHook(args, "post-rewrite") {
+		stdin, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return 0, false
+		}
+		req.Stdin = stdin
+	}
+
+	code, ok := hookdaemon.Forward(socketPath, req, os.Stdout, os.Stderr)
+	if !ok && req.Stdin != nil {
+		// Hand the payload that was already read to the direct run.
+		restoreStdin(req.Stdin)
+	}
+	return code, ok
+}
+
+// forwardableHook reports whether args invoke a git or agent hook.
+// Maintenance subcommands of `entire hooks` always run directly.
+func forwardableHook(args []string) bool {
+	if len(args) < 3 || args[0] != "hooks" {
+		return false
+	}
+	switch args[1] {
+	case "replay", "refresh":
+		return false
+	}
+	return !strings.HasPrefix(args[2], "-")
+}
+
+func isGitHook(args []string, hook string) bool {
+	return len(args) >= 3 && args[0] == "hooks" && args[1] == "git" && args[2] == hook
+}
+
+// restoreStdin replaces os.Stdin with a pipe that replays data.
+func restoreStdin(data []byte) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	go func() {
+		_, _ = w.Write(data) //nolint:errcheck // the reader sees a short payload, as with a broken pipe
+		_ = w.Close()
+	}()
+	os.Stdin = r
+}
+
+// runHookRequest runs a forwarded hook in the daemon process. The process
+// takes on the client's working directory, environment and standard streams
+// for the duration of the request, and gets its own back afterwards.
+func runHookRequest(ctx context.Context, req *hookdaemon.Request, stdout, stderr io.Writer) (code int) {
+	restore, err := enterHookRequest(req, stdout, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "[entire] Warning: hook daemon could not run the hook: %v\n", err)
+		return 1
+	}
+	defer restore()
+
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "[entire] hook panicked: %v\n", r)
+			code = 1
+		}
+	}()
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs(req.Args)
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		var silent *SilentError
+		if !errors.As(err, &silent) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// enterHookRequest switches the process to the request's working directory,
+// environment and streams, and returns a function that switches back.
+func enterHookRequest(req *hookdaemon.Request, stdout, stderr io.Writer) (func(), error) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if err := os.Chdir(req.Dir); err != nil {
+		return nil, fmt.Errorf("failed to enter %s: %w", req.Dir, err)
+	}
+
+	origEnv := os.Environ()
+	setEnviron(req.Env)
+	// The client has no terminal the daemon could prompt on.
+	if os.Getenv("ENTIRE_TEST_TTY") == "" {
+		_ = os.Setenv("ENTIRE_TEST_TTY", "0") //nolint:errcheck // setting a valid name cannot fail
+	}
+	// Requests can come from any repository or worktree
+	clearRepoCaches()
+
+	origStdin, origStdout, origStderr := os.Stdin, os.Stdout, os.Stderr
+	var copies sync.WaitGroup
+	stdinR := redirectStdin(req.Stdin)
+	stdoutW := redirectOutput(stdout, &copies)
+	stderrW := redirectOutput(stderr, &copies)
+	os.Stdin, os.Stdout, os.Stderr = stdinR, stdoutW, stderrW
+	// The default logger, used when hook logging is not set up, holds its own
+	// reference to stderr.
+	log.SetOutput(stderrW)
+
+	return func() {
+		logging.Close()
+		log.SetOutput(origStderr)
+		os.Stdin, os.Stdout, os.Stderr = origStdin, origStdout, origStderr
+		_ = stdoutW.Close()
+		_ = stderrW.Close()
+		copies.Wait()
+		_ = stdinR.Close()
+
+		setEnviron(origEnv)
+		clearRepoCaches()
+		_ = os.Chdir(origDir) //nolint:errcheck // the next request changes directory again anyway
+	}, nil
+}
+
+// redirectStdin returns a file that reads data, or the null device.
+func redirectStdin(data []byte) *os.File {
+	r, w, err := os.Pipe()
+	if err != nil {
+		devNull, _ := os.Open(os.DevNull) //nolint:errcheck // a nil stdin reads nothing
+		return devNull
+	}
+	go func() {
+		_, _ = w.Write(data) //nolint:errcheck // the hook sees a short payload, as with a broken pipe
+		_ = w.Close()
+	}()
+	return r
+}
+
+// redirectOutput returns a file whose writes are copied to w until it is
+// closed. Code that writes to os.Stdout and os.Stderr directly needs a real
+// file, not an io.Writer.
+func redirectOutput(w io.Writer, copies *sync.WaitGroup) *os.File {
+	r, pw, err := os.Pipe()
+	if err != nil {
+		devNull, _ := os.OpenFile(os.DevNull, os.O_WRONLY, 0) //nolint:errcheck // output is lost either way
+		return devNull
+	}
+	copies.Add(1)
+	go func() {
+		defer copies.Done()
+		_, _ = io.Copy(w, r) //nolint:errcheck // the client may have gone away
+		_ = r.Close()
+	}()
+	return pw
+}
+
+func setEnviron(env []string) {
+	os.Clearenv()
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok && k != "" {
+			_ = os.Setenv(k, v) //nolint:errcheck // names come from a valid environment
+		}
+	}
+}
+
+// clearRepoCaches forgets everything cached from a repository. Every package
+// that caches repository state registers its clearer with repocache.
+func clearRepoCaches() {
+	repocache.Clear()
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/hookdaemon"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardableHook(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"hooks", "git", "post-commit"}, true},
+		{[]string{"hooks", "claude-code", "stop"}, true},
+		{[]string{"hooks", "replay", "payload.json"}, false},
+		{[]string{"hooks", "refresh", "x"}, false},
+		{[]string{"hooks", "git", "--help"}, false},
+		{[]string{"hooks", "git"}, false},
+		{[]string{"status", "a", "b"}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, forwardableHook(tt.args), tt.args)
+	}
+}
+
+func TestRunHookRequest(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := t.TempDir()
+	testutil.InitRepo(t, repoDir)
+	t.Chdir(t.TempDir())
+	t.Setenv("ENTIRE_DAEMON_TEST_VAR", "daemon")
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	req := &hookdaemon.Request{
+		Args: []string{"version"},
+		Dir:  repoDir,
+		Env:  []string{"ENTIRE_DAEMON_TEST_VAR=client"},
+	}
+	code := runHookRequest(context.Background(), req, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), "Entire CLI")
+
+	// The daemon's own directory, environment and streams are restored.
+	dir, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, origDir, dir)
+	assert.Equal(t, "daemon", os.Getenv("ENTIRE_DAEMON_TEST_VAR"))
+	assert.Empty(t, os.Getenv("ENTIRE_TEST_TTY"))
+
+	req.Args = []string{"no-such-command"}
+	code = runHookRequest(context.Background(), req, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr.String(), "unknown command")
+}
+
+func TestClearRepoCaches_ReloadsSettings(t *testing.T) {
+	// Cannot use t.Parallel() because we replace the package-level namespace getter
+	loads := 0
+	paths.SetNamespaceGetter(func() paths.Namespace {
+		loads++
+		return paths.Namespace{}
+	})
+	t.Cleanup(func() { paths.SetNamespaceGetter(GetNamespace) })
+
+	paths.CurrentNamespace()
+	paths.CurrentNamespace()
+	assert.Equal(t, 1, loads)
+
+	// A request from another repository must not see this one's settings
+	clearRepoCaches()
+	paths.CurrentNamespace()
+	assert.Equal(t, 2, loads)
+}
+
+func TestForwardHookToDaemon_NoDaemon(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := t.TempDir()
+	testutil.InitRepo(t, repoDir)
+	t.Chdir(repoDir)
+	t.Setenv("TMPDIR", t.TempDir())
+
+	_, ok := ForwardHookToDaemon([]string{"hooks", "git", "post-commit"})
+	assert.False(t, ok)
+	_, ok = ForwardHookToDaemon([]string{"status"})
+	assert.False(t, ok)
+}
@@ -0,0 +1,477 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+// devtoolScenario is a canonical repository state used to reproduce
+// condensation bugs.
+type devtoolScenario struct {
+	name        string
+	description string
+	build       func(b *scenarioBuilder) error
+}
+
+// devtoolScenarios lists the scenarios `entire devtool scenario` can build.
+var devtoolScenarios = []devtoolScenario{
+	{
+		name:        "partial-commit",
+		description: "A session changed two files and the user committed only one; the other is carried forward",
+		build:       buildPartialCommitScenario,
+	},
+	{
+		name:        "concurrent-sessions",
+		description: "Two idle sessions share a base commit and shadow branch, each with its own files",
+		build:       buildConcurrentSessionsScenario,
+	},
+	{
+		name:        "stale-active",
+		description: "A session stuck in ACTIVE with a checkpoint and no interaction for two hours",
+		build:       buildStaleActiveScenario,
+	},
+	{
+		name:        "rebase-in-progress",
+		description: "A condensed session with a newer checkpoint while a conflicting rebase is stopped",
+		build:       buildRebaseInProgressScenario,
+	},
+}
+
+func newDevtoolCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "devtool",
+		Short:  "Tools for developing and debugging Entire",
+		Hidden: true,
+	}
+
+	cmd.AddCommand(newDevtoolScenarioCmd())
+
+	return cmd
+}
+
+func newDevtoolScenarioCmd() *cobra.Command {
+	var dirFlag string
+
+	cmd := &cobra.Command{
+		Use:   "scenario [name]",
+		Short: "Build a repository in a canonical session state for reproducing bugs",
+		Long: `Build a throwaway repository whose sessions, shadow branches, and commits are
+in a known state, using the same strategy code the hooks run. The repository
+has Entire enabled and git hooks installed, so it can be used to reproduce
+user-reported condensation bugs or as a starting point for tests.
+
+The repository is created in <dir>/repo and agent transcripts in
+<dir>/transcripts. Without --dir, a new temporary directory is used.
+
+Run without a name to list the available scenarios.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := cmd.OutOrStdout()
+			if len(args) == 0 {
+				listDevtoolScenarios(w)
+				return nil
+			}
+			scenario, ok := findDevtoolScenario(args[0])
+			if !ok {
+				cmd.SilenceUsage = true
+				err := fmt.Errorf("unknown scenario %q", args[0])
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+				listDevtoolScenarios(cmd.ErrOrStderr())
+				return NewSilentError(err)
+			}
+
+			dir := dirFlag
+			if dir == "" {
+				var err error
+				dir, err = os.MkdirTemp("", "entire-scenario-"+scenario.name+"-")
+				if err != nil {
+					return fmt.Errorf("failed to create temp directory: %w", err)
+				}
+			}
+			repoDir, err := buildDevtoolScenario(scenario, dir)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(w, "Built scenario %s in %s\n", scenario.name, repoDir)
+			fmt.Fprintf(w, "  %s\n\n", scenario.description)
+			fmt.Fprintf(w, "  cd %s && entire status\n", repoDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dirFlag, "dir", "", "Directory to build the scenario in (must be empty or not exist)")
+
+	return cmd
+}
+
+func listDevtoolScenarios(w io.Writer) {
+	fmt.Fprintln(w, "Available scenarios:")
+	for _, s := range devtoolScenarios {
+		fmt.Fprintf(w, "  %-20s %s\n", s.name, s.description)
+	}
+}
+
+func findDevtoolScenario(name string) (devtoolScenario, bool) {
+	for _, s := range devtoolScenarios {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return devtoolScenario{}, false
+}
+
+// buildDevtoolScenario creates the scenario's repository under dir and
+// returns its path. The working directory is changed to the repository while
+// the scenario is built and restored afterwards.
+func buildDevtoolScenario(scenario devtoolScenario, dir string) (string, error) {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return "", fmt.Errorf("directory %s is not empty", dir)
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	b := &scenarioBuilder{
+		ctx:           context.Background(),
+		repoDir:       filepath.Join(dir, "repo"),
+		transcriptDir: filepath.Join(dir, "transcripts"),
+		strat:         GetStrategy(),
+	}
+	for _, d := range []string{b.repoDir, b.transcriptDir} {
+		if err := os.MkdirAll(d, 0o750); err != nil {
+			return "", fmt.Errorf("failed to create %s: %w", d, err)
+		}
+	}
+
+	prevDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if err := os.Chdir(b.repoDir); err != nil {
+		return "", fmt.Errorf("failed to enter repository: %w", err)
+	}
+	paths.ClearWorktreeRootCache()
+	defer func() {
+		_ = os.Chdir(prevDir) //nolint:errcheck // best-effort restore
+		paths.ClearWorktreeRootCache()
+	}()
+
+	if err := b.init(); err != nil {
+		return "", err
+	}
+	if err := scenario.build(b); err != nil {
+		return "", fmt.Errorf("failed to build scenario %s: %w", scenario.name, err)
+	}
+	// Installed last so the builder's own git commands don't run the hooks.
+	if _, err := strategy.InstallGitHook(true, false); err != nil {
+		return "", fmt.Errorf("failed to install git hooks: %w", err)
+	}
+	return b.repoDir, nil
+}
+
+func buildPartialCommitScenario(b *scenarioBuilder) error {
+	const sessionID = "scenario-partial-commit"
+	if err := b.startSession(sessionID, "Add a greeting and a farewell"); err != nil {
+		return err
+	}
+	if err := b.step(sessionID, "Added hello.txt and goodbye.txt.", map[string]string{
+		"hello.txt":   "hello\n",
+		"goodbye.txt": "goodbye\n",
+	}); err != nil {
+		return err
+	}
+	b.endTurn(sessionID)
+	return b.commit("Add greeting", "hello.txt")
+}
+
+func buildConcurrentSessionsScenario(b *scenarioBuilder) error {
+	for _, s := range []struct{ id, prompt, file string }{
+		{"scenario-concurrent-a", "Add the alpha module", "alpha.txt"},
+		{"scenario-concurrent-b", "Add the beta module", "beta.txt"},
+	} {
+		if err := b.startSession(s.id, s.prompt); err != nil {
+			return err
+		}
+		if err := b.step(s.id, "Added "+s.file+".", map[string]string{s.file: s.file + "\n"}); err != nil {
+			return err
+		}
+		b.endTurn(s.id)
+	}
+	return nil
+}
+
+func buildStaleActiveScenario(b *scenarioBuilder) error {
+	const sessionID = "scenario-stale-active"
+	if err := b.startSession(sessionID, "Refactor the parser"); err != nil {
+		return err
+	}
+	if err := b.step(sessionID, "Started refactoring parser.txt.", map[string]string{
+		"parser.txt": "parser v2\n",
+	}); err != nil {
+		return err
+	}
+	// No turn end: the agent died mid-turn and the session is still ACTIVE.
+	state, err := strategy.LoadSessionState(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return fmt.Errorf("session %s has no state", sessionID)
+	}
+	lastInteraction := time.Now().Add(-2 * time.Hour)
+	state.LastInteractionTime = &lastInteraction
+	if err := strategy.SaveSessionState(state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+	return nil
+}
+
+func buildRebaseInProgressScenario(b *scenarioBuilder) error {
+	const sessionID = "scenario-rebase"
+	if _, err := b.git("checkout", "-q", "-b", "upstream"); err != nil {
+		return err
+	}
+	if err := b.writeFiles(map[string]string{"shared.txt": "upstream version\n"}); err != nil {
+		return err
+	}
+	if _, err := b.git("add", "shared.txt"); err != nil {
+		return err
+	}
+	if _, err := b.git("commit", "-q", "-m", "Change shared.txt upstream"); err != nil {
+		return err
+	}
+	if _, err := b.git("checkout", "-q", "-"); err != nil {
+		return err
+	}
+
+	if err := b.startSession(sessionID, "Update shared.txt"); err != nil {
+		return err
+	}
+	if err := b.step(sessionID, "Updated shared.txt.", map[string]string{"shared.txt": "agent version\n"}); err != nil {
+		return err
+	}
+	b.endTurn(sessionID)
+	if err := b.commit("Update shared.txt", "shared.txt"); err != nil {
+		return err
+	}
+
+	if err := b.startSession(sessionID, "Add notes"); err != nil {
+		return err
+	}
+	if err := b.step(sessionID, "Added notes.txt.", map[string]string{"notes.txt": "notes\n"}); err != nil {
+		return err
+	}
+	b.endTurn(sessionID)
+
+	// The rebase is expected to stop on the conflict in shared.txt.
+	if _, err := b.git("rebase", "upstream"); err == nil {
+		return errors.New("rebase unexpectedly succeeded")
+	}
+	return nil
+}
+
+// scenarioBuilder drives the strategy the way agent and git hooks do.
+type scenarioBuilder struct {
+	ctx           context.Context
+	repoDir       string
+	transcriptDir string
+	strat         strategy.Strategy
+}
+
+// init creates the repository with an initial commit and enables Entire.
+func (b *scenarioBuilder) init() error {
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Entire Scenario"},
+		{"config", "user.email", "scenario@entire.local"},
+		{"config", "commit.gpgsign", "false"},
+	} {
+		if _, err := b.git(args...); err != nil {
+			return err
+		}
+	}
+
+	settingsData, err := jsonutil.MarshalIndentWithNewline(map[string]any{"enabled": true}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := b.writeFiles(map[string]string{
+		"README.md":  "# Scenario\n",
+		"shared.txt": "original version\n",
+		paths.EntireDir + "/" + paths.SettingsFileName: string(settingsData),
+	}); err != nil {
+		return err
+	}
+	if err := strategy.EnsureEntireGitignore(); err != nil {
+		return fmt.Errorf("failed to write .entire/.gitignore: %w", err)
+	}
+	if _, err := b.git("add", "-A"); err != nil {
+		return err
+	}
+	if _, err := b.git("commit", "-q", "-m", "Initial commit"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *scenarioBuilder) transcriptPath(sessionID string) string {
+	return filepath.Join(b.transcriptDir, sessionID+".jsonl")
+}
+
+// startSession begins a turn with prompt, as the agent's prompt-submit hook does.
+func (b *scenarioBuilder) startSession(sessionID, prompt string) error {
+	if err := b.appendTranscript(sessionID, "user", prompt); err != nil {
+		return err
+	}
+	if err := b.strat.InitializeSession(sessionID, agent.AgentTypeClaudeCode, b.transcriptPath(sessionID), prompt); err != nil {
+		return fmt.Errorf("failed to initialize session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// step writes files as the agent and saves a checkpoint, as the stop hook does.
+func (b *scenarioBuilder) step(sessionID, response string, files map[string]string) error {
+	var modified, added []string
+	for name := range files {
+		if fileExists(filepath.Join(b.repoDir, name)) {
+			modified = append(modified, name)
+		} else {
+			added = append(added, name)
+		}
+	}
+	if err := b.writeFiles(files); err != nil {
+		return err
+	}
+	if err := b.appendTranscript(sessionID, "assistant", response); err != nil {
+		return err
+	}
+
+	metadataDir := paths.SessionMetadataDirFromSessionID(sessionID)
+	metadataDirAbs := filepath.Join(b.repoDir, metadataDir)
+	if err := os.MkdirAll(metadataDirAbs, 0o750); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	transcript, err := os.ReadFile(b.transcriptPath(sessionID))
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(metadataDirAbs, paths.TranscriptFileName), transcript, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	err = b.strat.SaveStep(strategy.StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  modified,
+		NewFiles:       added,
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: metadataDirAbs,
+		CommitMessage:  response,
+		TranscriptPath: b.transcriptPath(sessionID),
+		AuthorName:     "Entire Scenario",
+		AuthorEmail:    "scenario@entire.local",
+		AgentType:      agent.AgentTypeClaudeCode,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save step for %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// endTurn moves the session to IDLE, as the stop hook does after saving.
+func (b *scenarioBuilder) endTurn(sessionID string) {
+	transitionSessionTurnEnd(sessionID)
+}
+
+// commit stages files and commits them through the prepare-commit-msg,
+// commit-msg, and post-commit hooks, as a user committing from an editor.
+func (b *scenarioBuilder) commit(message string, files ...string) error {
+	if _, err := b.git(append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	gitDir, err := b.git("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	msgFile := filepath.Join(strings.TrimSpace(string(gitDir)), "COMMIT_EDITMSG")
+	if err := os.WriteFile(msgFile, []byte(message+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write commit message: %w", err)
+	}
+	if err := b.strat.PrepareCommitMsg(msgFile, ""); err != nil {
+		return fmt.Errorf("prepare-commit-msg failed: %w", err)
+	}
+	if err := b.strat.CommitMsg(msgFile); err != nil {
+		return fmt.Errorf("commit-msg failed: %w", err)
+	}
+	if _, err := b.git("commit", "-q", "--cleanup=strip", "-F", msgFile); err != nil {
+		return err
+	}
+	if err := b.strat.PostCommit(); err != nil {
+		return fmt.Errorf("post-commit failed: %w", err)
+	}
+	return nil
+}
+
+func (b *scenarioBuilder) writeFiles(files map[string]string) error {
+	for name, content := range files {
+		path := filepath.Join(b.repoDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// appendTranscript appends a Claude Code transcript line for role.
+func (b *scenarioBuilder) appendTranscript(sessionID, role, text string) error {
+	path := b.transcriptPath(sessionID)
+	lines := 0
+	if data, err := os.ReadFile(path); err == nil {
+		lines = strings.Count(string(data), "\n")
+	}
+
+	var content any = text
+	if role == "assistant" {
+		content = []map[string]string{{"type": "text", "text": text}}
+	}
+	line, err := json.Marshal(map[string]any{
+		"type":      role,
+		"uuid":      fmt.Sprintf("%s-%d", sessionID, lines+1),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"message":   map[string]any{"role": role, "content": content},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript line: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) //nolint:gosec // path is under the scenario directory
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+func (b *scenarioBuilder) git(args ...string) ([]byte, error) {
+	return gitOutput(b.ctx, b.repoDir, nil, args...)
+}
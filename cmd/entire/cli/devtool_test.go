@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildScenarioForTest builds the named scenario in a temp directory and
+// changes into its repository.
+func buildScenarioForTest(t *testing.T, name string) string {
+	t.Helper()
+	scenario, ok := findDevtoolScenario(name)
+	require.True(t, ok, "unknown scenario %s", name)
+
+	repoDir, err := buildDevtoolScenario(scenario, t.TempDir())
+	require.NoError(t, err)
+	t.Chdir(repoDir)
+	paths.ClearWorktreeRootCache()
+	return repoDir
+}
+
+func scenarioGit(t *testing.T, repoDir string, args ...string) string {
+	t.Helper()
+	out, err := gitOutput(context.Background(), repoDir, nil, args...)
+	require.NoError(t, err)
+	return string(bytes.TrimSpace(out))
+}
+
+func TestDevtoolScenario_PartialCommit(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := buildScenarioForTest(t, "partial-commit")
+
+	state, err := strategy.LoadSessionState("scenario-partial-commit")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, session.PhaseIdle, state.Phase)
+	assert.Equal(t, scenarioGit(t, repoDir, "rev-parse", "HEAD"), state.BaseCommit)
+	assert.Equal(t, []string{"goodbye.txt"}, state.FilesTouched)
+
+	_, found := trailers.ParseCheckpoint(scenarioGit(t, repoDir, "log", "-1", "--format=%B"))
+	assert.True(t, found, "expected HEAD to have a checkpoint trailer")
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+	scenarioGit(t, repoDir, "rev-parse", "--verify", "refs/heads/"+shadowBranch)
+	assert.Equal(t, "?? goodbye.txt", scenarioGit(t, repoDir, "status", "--porcelain"))
+	assert.True(t, strategy.IsGitHookInstalledInDir(repoDir))
+}
+
+func TestDevtoolScenario_ConcurrentSessions(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	buildScenarioForTest(t, "concurrent-sessions")
+
+	states, err := strategy.ListSessionStates()
+	require.NoError(t, err)
+	require.Len(t, states, 2)
+	assert.Equal(t, states[0].BaseCommit, states[1].BaseCommit)
+	for _, state := range states {
+		assert.Equal(t, session.PhaseIdle, state.Phase)
+		assert.Equal(t, 1, state.StepCount)
+		assert.Len(t, state.FilesTouched, 1)
+	}
+}
+
+func TestDevtoolScenario_StaleActive(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	buildScenarioForTest(t, "stale-active")
+
+	state, err := strategy.LoadSessionState("scenario-stale-active")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, session.PhaseActive, state.Phase)
+	require.NotNil(t, state.LastInteractionTime)
+	assert.Greater(t, time.Since(*state.LastInteractionTime), time.Hour)
+}
+
+func TestDevtoolScenario_RebaseInProgress(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := buildScenarioForTest(t, "rebase-in-progress")
+
+	gitDir := scenarioGit(t, repoDir, "rev-parse", "--absolute-git-dir")
+	_, err := os.Stat(filepath.Join(gitDir, "rebase-merge"))
+	require.NoError(t, err, "expected a stopped rebase")
+
+	state, err := strategy.LoadSessionState("scenario-rebase")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, session.PhaseIdle, state.Phase)
+	assert.Equal(t, 1, state.StepCount)
+	assert.Equal(t, []string{"notes.txt"}, state.FilesTouched)
+}
+
+func TestDevtoolScenario_RejectsNonEmptyDir(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing"), nil, 0o600))
+
+	scenario, ok := findDevtoolScenario("partial-commit")
+	require.True(t, ok)
+	_, err := buildDevtoolScenario(scenario, dir)
+	require.Error(t, err)
+}
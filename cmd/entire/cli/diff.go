@@ -0,0 +1,540 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitdiff "github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/spf13/cobra"
+)
+
+// checkpointDiffMode selects how `entire diff` renders its output.
+type checkpointDiffMode int
+
+const (
+	checkpointDiffPatch checkpointDiffMode = iota
+	checkpointDiffStat
+	checkpointDiffNameOnly
+)
+
+// binaryProbeSize is how much of a file is checked for NUL bytes to decide
+// whether it is binary, matching git.
+const binaryProbeSize = 8000
+
+func newDiffCmd() *cobra.Command {
+	var statFlag bool
+	var nameOnlyFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <checkpoint> [<checkpoint>]",
+		Short: "Show the changes between two checkpoints",
+		Long: `Show a unified diff of the files between two checkpoints, or between a
+checkpoint and the working tree when only one is given. Use it to review
+exactly what an agent turn changed before committing.
+
+Each side may be a committed checkpoint ID, the commit hash of a temporary
+checkpoint (as listed by 'entire explain'), or any git revision such as HEAD.
+IDs and hashes may be abbreviated to a unique prefix. A committed checkpoint
+stands for the newest commit that references it. Entire's own metadata under
+.entire/ is never shown.
+
+Output modes:
+  (default)     Full patch
+  --stat        Diffstat summary
+  --name-only   Names of changed files only`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if statFlag && nameOnlyFlag {
+				return errors.New("--stat and --name-only cannot be used together")
+			}
+			mode := checkpointDiffPatch
+			switch {
+			case statFlag:
+				mode = checkpointDiffStat
+			case nameOnlyFlag:
+				mode = checkpointDiffNameOnly
+			}
+			return runCheckpointDiff(cmd, args, mode)
+		},
+	}
+
+	cmd.Flags().BoolVar(&statFlag, "stat", false, "Show a diffstat instead of the full patch")
+	cmd.Flags().BoolVar(&nameOnlyFlag, "name-only", false, "List changed file names only")
+
+	return cmd
+}
+
+func runCheckpointDiff(cmd *cobra.Command, args []string, mode checkpointDiffMode) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	fail := func(err error) error {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fail(errors.New("not a git repository"))
+	}
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return fail(errors.New("not a git repository"))
+	}
+
+	from, err := resolveDiffEndpoint(ctx, repo, args[0])
+	if err != nil {
+		return fail(err)
+	}
+	var files []diffFilePatch
+	toLabel := "the working tree"
+	if len(args) == 2 {
+		to, err := resolveDiffEndpoint(ctx, repo, args[1])
+		if err != nil {
+			return fail(err)
+		}
+		toLabel = to.label
+		files, err = diffTrees(from.tree, to.tree)
+		if err != nil {
+			return err
+		}
+	} else {
+		files, err = diffTreeToWorktree(ctx, repo, repoRoot, from.tree)
+		if err != nil {
+			return err
+		}
+	}
+
+	w := cmd.OutOrStdout()
+	if len(files) == 0 {
+		fmt.Fprintf(w, "No changes between %s and %s.\n", from.label, toLabel)
+		return nil
+	}
+	return writeCheckpointDiff(w, files, mode)
+}
+
+// diffEndpoint is one side of `entire diff`: the tree of a checkpoint or commit.
+type diffEndpoint struct {
+	label string
+	tree  *object.Tree
+}
+
+// resolveDiffEndpoint resolves arg as a committed checkpoint ID prefix, then a
+// temporary checkpoint hash prefix, then a git revision.
+func resolveDiffEndpoint(ctx context.Context, repo *git.Repository, arg string) (diffEndpoint, error) {
+	store := checkpoint.NewGitStore(repo)
+
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return diffEndpoint{}, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	if hasCommittedCheckpointPrefix(committed, arg) {
+		info, err := matchCommittedCheckpoint(committed, arg)
+		if err != nil {
+			return diffEndpoint{}, err
+		}
+		linked, err := linkedCommits(repo, []checkpoint.CommittedInfo{info})
+		if err != nil {
+			return diffEndpoint{}, fmt.Errorf("failed to find commits for checkpoint %s: %w", info.CheckpointID, err)
+		}
+		commits := linked[info.CheckpointID]
+		if len(commits) == 0 {
+			return diffEndpoint{}, fmt.Errorf("checkpoint %s is not referenced by any commit on a local branch", info.CheckpointID)
+		}
+		return commitDiffEndpoint(repo, "checkpoint "+info.CheckpointID.String(), plumbing.NewHash(commits[0].Commit))
+	}
+
+	temporary, err := store.ListAllTemporaryCheckpoints(ctx, "", branchCheckpointsLimit)
+	if err != nil {
+		return diffEndpoint{}, fmt.Errorf("failed to list temporary checkpoints: %w", err)
+	}
+	var matches []plumbing.Hash
+	for _, tc := range temporary {
+		if strings.HasPrefix(tc.CommitHash.String(), arg) {
+			matches = append(matches, tc.CommitHash)
+		}
+	}
+	switch len(matches) {
+	case 0:
+	case 1:
+		return commitDiffEndpoint(repo, "checkpoint "+strategy.TruncateHash(matches[0].String()), matches[0])
+	default:
+		return diffEndpoint{}, fmt.Errorf("checkpoint prefix %q is ambiguous, matches %d temporary checkpoints", arg, len(matches))
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(arg))
+	if err != nil {
+		return diffEndpoint{}, fmt.Errorf("no checkpoint or revision matches %q", arg)
+	}
+	return commitDiffEndpoint(repo, arg, *hash)
+}
+
+// hasCommittedCheckpointPrefix reports whether any committed checkpoint ID
+// starts with prefix.
+func hasCommittedCheckpointPrefix(committed []checkpoint.CommittedInfo, prefix string) bool {
+	for _, info := range committed {
+		if strings.HasPrefix(info.CheckpointID.String(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func commitDiffEndpoint(repo *git.Repository, label string, hash plumbing.Hash) (diffEndpoint, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return diffEndpoint{}, fmt.Errorf("failed to get commit %s: %w", strategy.TruncateHash(hash.String()), err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return diffEndpoint{}, fmt.Errorf("failed to get tree of %s: %w", strategy.TruncateHash(hash.String()), err)
+	}
+	return diffEndpoint{label: label, tree: tree}, nil
+}
+
+// diffTrees returns the patches for the files that differ between two trees.
+func diffTrees(from, to *object.Tree) ([]diffFilePatch, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+	candidates := make(map[string]struct{}, len(changes))
+	addChangedPaths(candidates, changes)
+	return diffCandidates(candidates, func(path string) (*diffBlob, error) { return treeBlob(from, path) },
+		func(path string) (*diffBlob, error) { return treeBlob(to, path) })
+}
+
+// diffTreeToWorktree returns the patches for the files that differ between a
+// tree and the working tree. A file can only differ if it differs between
+// the tree and HEAD, or between HEAD and the working tree, so only those
+// files are read from disk.
+func diffTreeToWorktree(ctx context.Context, repo *git.Repository, repoRoot string, from *object.Tree) ([]diffFilePatch, error) {
+	candidates := make(map[string]struct{})
+	var headTree *object.Tree
+	if head, err := repo.Head(); err == nil {
+		endpoint, err := commitDiffEndpoint(repo, "HEAD", head.Hash())
+		if err != nil {
+			return nil, err
+		}
+		headTree = endpoint.tree
+	}
+	changes, err := object.DiffTree(from, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff trees: %w", err)
+	}
+	addChangedPaths(candidates, changes)
+	status, err := worktreeStatusPaths(ctx, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range status {
+		candidates[path] = struct{}{}
+	}
+	return diffCandidates(candidates, func(path string) (*diffBlob, error) { return treeBlob(from, path) },
+		func(path string) (*diffBlob, error) { return worktreeBlob(repoRoot, path) })
+}
+
+// addChangedPaths adds the old and new path of each change to candidates.
+func addChangedPaths(candidates map[string]struct{}, changes object.Changes) {
+	for _, c := range changes {
+		if c.From.Name != "" {
+			candidates[c.From.Name] = struct{}{}
+		}
+		if c.To.Name != "" {
+			candidates[c.To.Name] = struct{}{}
+		}
+	}
+}
+
+// worktreeStatusPaths returns every path git status reports as changed,
+// including untracked files and both names of a rename.
+func worktreeStatusPaths(ctx context.Context, repoRoot string) ([]string, error) {
+	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "-uall")
+	statusCmd.Dir = repoRoot
+	output, err := statusCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+
+	// Format: XY filename\0, with renames and copies followed by the old name
+	var result []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 3 {
+			continue
+		}
+		result = append(result, entry[3:])
+		if (entry[0] == 'R' || entry[0] == 'C') && i+1 < len(entries) {
+			i++
+			result = append(result, entries[i])
+		}
+	}
+	return result, nil
+}
+
+// diffCandidates reads each candidate path from both sides and returns a
+// patch for each one whose content or mode differs, sorted by path.
+func diffCandidates(candidates map[string]struct{}, readFrom, readTo func(string) (*diffBlob, error)) ([]diffFilePatch, error) {
+	sorted := make([]string, 0, len(candidates))
+	for path := range candidates {
+		if !paths.IsInfrastructurePath(path) {
+			sorted = append(sorted, path)
+		}
+	}
+	sort.Strings(sorted)
+
+	var result []diffFilePatch
+	for _, path := range sorted {
+		from, err := readFrom(path)
+		if err != nil {
+			return nil, err
+		}
+		to, err := readTo(path)
+		if err != nil {
+			return nil, err
+		}
+		if from == nil && to == nil {
+			continue
+		}
+		if from != nil && to != nil && from.hash == to.hash && from.mode == to.mode {
+			continue
+		}
+		result = append(result, newDiffFilePatch(from, to))
+	}
+	return result, nil
+}
+
+// treeBlob reads path from tree, or returns nil if the tree has no such file.
+func treeBlob(tree *object.Tree, path string) (*diffBlob, error) {
+	if tree == nil {
+		return nil, nil
+	}
+	f, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &diffBlob{path: path, hash: f.Hash, mode: f.Mode, content: []byte(contents)}, nil
+}
+
+// worktreeBlob reads path from the working tree, or returns nil if it does
+// not exist there.
+func worktreeBlob(repoRoot, path string) (*diffBlob, error) {
+	absPath := filepath.Join(repoRoot, filepath.FromSlash(path))
+	info, err := os.Lstat(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	var content []byte
+	mode := filemode.Regular
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read link %s: %w", path, err)
+		}
+		content = []byte(target)
+		mode = filemode.Symlink
+	case info.IsDir():
+		return nil, nil
+	default:
+		content, err = os.ReadFile(absPath) //nolint:gosec // path comes from git
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if info.Mode()&0o111 != 0 {
+			mode = filemode.Executable
+		}
+	}
+	return &diffBlob{path: path, hash: plumbing.ComputeHash(plumbing.BlobObject, content), mode: mode, content: content}, nil
+}
+
+// diffBlob is one version of a file, implementing fdiff.File.
+type diffBlob struct {
+	path    string
+	hash    plumbing.Hash
+	mode    filemode.FileMode
+	content []byte
+}
+
+func (b *diffBlob) Hash() plumbing.Hash     { return b.hash }
+func (b *diffBlob) Mode() filemode.FileMode { return b.mode }
+func (b *diffBlob) Path() string            { return b.path }
+
+func (b *diffBlob) isBinary() bool {
+	probe := b.content
+	if len(probe) > binaryProbeSize {
+		probe = probe[:binaryProbeSize]
+	}
+	return bytes.IndexByte(probe, 0) >= 0
+}
+
+// diffChunk implements fdiff.Chunk.
+type diffChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c diffChunk) Content() string       { return c.content }
+func (c diffChunk) Type() fdiff.Operation { return c.op }
+
+// diffFilePatch is the change to one file, implementing fdiff.FilePatch.
+// Either side is nil when the file is added or deleted.
+type diffFilePatch struct {
+	from, to *diffBlob
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func newDiffFilePatch(from, to *diffBlob) diffFilePatch {
+	fp := diffFilePatch{from: from, to: to}
+	var fromContent, toContent string
+	if from != nil {
+		fp.binary = from.isBinary()
+		fromContent = string(from.content)
+	}
+	if to != nil {
+		fp.binary = fp.binary || to.isBinary()
+		toContent = string(to.content)
+	}
+	if fp.binary {
+		return fp
+	}
+	for _, d := range gitdiff.Do(fromContent, toContent) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffEqual:
+		}
+		fp.chunks = append(fp.chunks, diffChunk{content: d.Text, op: op})
+	}
+	return fp
+}
+
+func (fp diffFilePatch) IsBinary() bool        { return fp.binary }
+func (fp diffFilePatch) Chunks() []fdiff.Chunk { return fp.chunks }
+
+func (fp diffFilePatch) Files() (fdiff.File, fdiff.File) {
+	// Return untyped nils so the encoder sees added and deleted files
+	var from, to fdiff.File
+	if fp.from != nil {
+		from = fp.from
+	}
+	if fp.to != nil {
+		to = fp.to
+	}
+	return from, to
+}
+
+func (fp diffFilePatch) path() string {
+	if fp.to != nil {
+		return fp.to.path
+	}
+	return fp.from.path
+}
+
+// diffPatch implements fdiff.Patch.
+type diffPatch []diffFilePatch
+
+func (p diffPatch) Message() string { return "" }
+
+func (p diffPatch) FilePatches() []fdiff.FilePatch {
+	result := make([]fdiff.FilePatch, len(p))
+	for i, fp := range p {
+		result[i] = fp
+	}
+	return result
+}
+
+// writeCheckpointDiff writes files as a unified diff, a diffstat, or a list
+// of names.
+func writeCheckpointDiff(w io.Writer, files []diffFilePatch, mode checkpointDiffMode) error {
+	switch mode {
+	case checkpointDiffNameOnly:
+		for _, fp := range files {
+			fmt.Fprintln(w, fp.path())
+		}
+	case checkpointDiffStat:
+		writeCheckpointDiffStat(w, files)
+	case checkpointDiffPatch:
+		if err := fdiff.NewUnifiedEncoder(w, fdiff.DefaultContextLines).Encode(diffPatch(files)); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeCheckpointDiffStat writes a diffstat like `git diff --stat`.
+func writeCheckpointDiffStat(w io.Writer, files []diffFilePatch) {
+	stats := make(object.FileStats, 0, len(files))
+	var additions, deletions int
+	for _, fp := range files {
+		stat := object.FileStat{Name: fp.path()}
+		for _, c := range fp.chunks {
+			s := c.Content()
+			if s == "" {
+				continue
+			}
+			lines := strings.Count(s, "\n")
+			if !strings.HasSuffix(s, "\n") {
+				lines++
+			}
+			switch c.Type() {
+			case fdiff.Add:
+				stat.Addition += lines
+			case fdiff.Delete:
+				stat.Deletion += lines
+			case fdiff.Equal:
+			}
+		}
+		additions += stat.Addition
+		deletions += stat.Deletion
+		stats = append(stats, stat)
+	}
+	fmt.Fprint(w, stats.String())
+	fmt.Fprintf(w, " %d %s changed, %d %s(+), %d %s(-)\n",
+		len(files), pluralize(len(files), "file", "files"),
+		additions, pluralize(additions, "insertion", "insertions"),
+		deletions, pluralize(deletions, "deletion", "deletions"))
+}
+
+// pluralize returns singular when n is 1 and plural otherwise.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runDiffForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCheckpointDiff(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "initial")
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	const sessionID = "2026-03-01-diff-session"
+	s := strategy.NewManualCommitStrategy()
+	metadataDir := ".entire/metadata/" + sessionID
+	testutil.WriteFile(t, tmpDir, metadataDir+"/"+paths.TranscriptFileName, "{}\n")
+	step := func(message string, modified, added []string) {
+		t.Helper()
+		require.NoError(t, s.SaveStep(strategy.StepContext{
+			SessionID:      sessionID,
+			ModifiedFiles:  modified,
+			NewFiles:       added,
+			MetadataDir:    metadataDir,
+			MetadataDirAbs: filepath.Join(tmpDir, metadataDir),
+			CommitMessage:  message,
+			AuthorName:     "Test",
+			AuthorEmail:    "test@test.com",
+		}))
+	}
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+	step("Add main", []string{"main.go"}, nil)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() { run() }\n")
+	testutil.WriteFile(t, tmpDir, "run.go", "package main\n\nfunc run() {}\n")
+	step("Add run", []string{"main.go"}, []string{"run.go"})
+
+	points, err := s.(*strategy.ManualCommitStrategy).SessionRewindPoints(sessionID)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	first, second := points[0].ID[:10], points[1].ID[:10]
+
+	out, err := runDiffForTest(t, first, second)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "diff --git a/main.go b/main.go\n")
+	assert.Contains(t, out, "-func main() {}\n+func main() { run() }\n")
+	assert.Contains(t, out, "new file mode 100644\n")
+	assert.Contains(t, out, "+func run() {}\n")
+	assert.NotContains(t, out, ".entire/", "metadata is not shown")
+
+	out, err = runDiffForTest(t, "--stat", first, second)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, " main.go | 2 +-\n")
+	assert.Contains(t, out, " run.go  | 3 +++\n")
+	assert.Contains(t, out, " 2 files changed, 4 insertions(+), 1 deletion(-)\n")
+
+	out, err = runDiffForTest(t, "--name-only", "HEAD", second)
+	require.NoError(t, err, out)
+	assert.Equal(t, "main.go\nrun.go\n", out)
+
+	// Against the working tree, including a file that is not yet tracked
+	testutil.WriteFile(t, tmpDir, "notes.md", "todo\n")
+	out, err = runDiffForTest(t, "--name-only", second)
+	require.NoError(t, err, out)
+	assert.Equal(t, "notes.md\n", out)
+
+	out, err = runDiffForTest(t, second, second)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "No changes between checkpoint")
+
+	out, err = runDiffForTest(t, "no-such-ref")
+	require.Error(t, err)
+	assert.Contains(t, out, `no checkpoint or revision matches "no-such-ref"`)
+}
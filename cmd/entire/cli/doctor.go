@@ -8,6 +8,7 @@ import (
 
 	"github.com/charmbracelet/huh"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
@@ -22,12 +23,18 @@ const stalenessThreshold = 1 * time.Hour
 
 func newDoctorCmd() *cobra.Command {
 	var forceFlag bool
+	var repairFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "doctor",
-		Short: "Fix stuck sessions",
+		Short: "Diagnose and fix stuck sessions and damaged data",
 		Long: `Scan for stuck or problematic sessions and offer to fix them.
 
+Sessions recorded under a worktree path that no longer exists are first
+re-bound to the worktree's current location, e.g. after the repository was
+moved or renamed. Each session's state is then checked against its shadow
+branch and transcript, and any drift between them is reported.
+
 A session is considered stuck if:
   - It is in ACTIVE phase with no interaction for over 1 hour
   - It is in ENDED phase with uncondensed checkpoint data on a shadow branch
@@ -38,17 +45,126 @@ For each stuck session, you can choose to:
   - Skip: Leave the session as-is
 
 Use --force to condense all fixable sessions without prompting.  Sessions that can't
-be condensed will be discarded.`,
+be condensed will be discarded.
+
+With --repair, doctor first checks for damaged data that makes condensation
+fail on every commit, and offers a fix for each problem found:
+  - Shadow branches whose commits or trees can't be read
+  - Refs and session base commits that point at the zero hash
+  - Session metadata directories no session state refers to
+  - Step counts that disagree with the session's base commit or shadow branch
+  - Session state files that can't be read
+
+With --repair --force, all fixes are applied without prompting.
+
+Run 'entire doctor check' for read-only health checks of hooks, settings,
+and Entire's branches and session state.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if repairFlag {
+				if err := runRepair(cmd, forceFlag); err != nil {
+					return err
+				}
+			}
 			return runSessionsFix(cmd, forceFlag)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Fix all stuck sessions without prompting (condense if possible, otherwise discard)")
+	cmd.Flags().BoolVar(&repairFlag, "repair", false, "Also detect and fix corrupted shadow branches, zero-hash references, orphaned metadata, and inconsistent session state")
+
+	cmd.AddCommand(newDoctorCheckCmd())
 
 	return cmd
 }
 
+// reportStateDrift prints conformance violations for each session. Drift is
+// only reported; fixing it is left to condensing or discarding the session.
+func reportStateDrift(w, errW io.Writer, states []*strategy.SessionState) {
+	var violations []strategy.ConformanceViolation
+	for _, state := range states {
+		v, err := strategy.CheckSessionConformance(state)
+		if err != nil {
+			fmt.Fprintf(errW, "Warning: failed to check session %s: %v\n", state.SessionID, err)
+			continue
+		}
+		violations = append(violations, v...)
+	}
+	if len(violations) == 0 {
+		return
+	}
+	fmt.Fprintln(w, i18n.Tf("Session state drift detected (%d):", len(violations)))
+	for _, v := range violations {
+		fmt.Fprintf(w, "  %s %s\n", v.SessionID, v)
+	}
+	fmt.Fprintln(w)
+}
+
+// runRepair reports damaged Entire data and applies the fixes the user accepts,
+// or all of them with force.
+func runRepair(cmd *cobra.Command, force bool) error {
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	issues, err := strategy.FindRepairIssues()
+	if err != nil {
+		return fmt.Errorf("failed to check for damaged data: %w", err)
+	}
+	if len(issues) == 0 {
+		fmt.Fprintln(w, i18n.T("No damaged data found."))
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s\n\n", i18n.Tf("Found %d problem(s):", len(issues)))
+	for _, issue := range issues {
+		fmt.Fprintf(w, "  %s\n", issue)
+		if !issue.Fixable() {
+			fmt.Fprintf(w, "  -> %s\n\n", i18n.T("No automated fix"))
+			continue
+		}
+		fmt.Fprintf(w, "  %s\n", i18n.Tf("Fix: %s", issue.Fix))
+
+		if !force {
+			apply, err := promptRepair(issue)
+			if err != nil {
+				if errors.Is(err, huh.ErrUserAborted) {
+					return nil
+				}
+				return fmt.Errorf("failed to get action: %w", err)
+			}
+			if !apply {
+				fmt.Fprintf(w, "  -> %s\n\n", i18n.T("Skipped"))
+				continue
+			}
+		}
+
+		if err := strategy.ApplyRepair(issue); err != nil {
+			fmt.Fprintf(errW, "Warning: failed to repair %s: %v\n", issue.Target, err)
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, "  -> %s\n\n", i18n.T("Repaired"))
+	}
+	return nil
+}
+
+// promptRepair asks the user whether to apply an issue's fix.
+func promptRepair(issue strategy.RepairIssue) (bool, error) {
+	apply := true
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(i18n.Tf("Repair %s?", issue.Target)).
+				Description(issue.Fix).
+				Value(&apply),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("repair prompt failed: %w", err)
+	}
+	return apply, nil
+}
+
 // stuckSession holds a session state along with diagnostic info.
 type stuckSession struct {
 	State             *strategy.SessionState
@@ -63,6 +179,18 @@ func runSessionsFix(cmd *cobra.Command, force bool) error {
 	w := cmd.OutOrStdout()
 	defer func() { settings.WriteDeprecatedStrategyWarnings(w) }()
 
+	// Re-bind sessions left behind by a repository or worktree move
+	rebound, err := strategy.RebindMovedSessions()
+	if err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to re-bind moved sessions: %v\n", err)
+	}
+	for _, rb := range rebound {
+		fmt.Fprintln(w, i18n.Tf("Re-bound session %s: %s -> %s", rb.SessionID, rb.OldPath, rb.NewPath))
+	}
+	if len(rebound) > 0 {
+		fmt.Fprintln(w)
+	}
+
 	// Load all session states
 	states, err := strategy.ListSessionStates()
 	if err != nil {
@@ -70,10 +198,12 @@ func runSessionsFix(cmd *cobra.Command, force bool) error {
 	}
 
 	if len(states) == 0 {
-		fmt.Fprintln(cmd.OutOrStdout(), "No stuck sessions found.")
+		fmt.Fprintln(cmd.OutOrStdout(), i18n.T("No stuck sessions found."))
 		return nil
 	}
 
+	reportStateDrift(w, cmd.ErrOrStderr(), states)
+
 	// Open repository to check shadow branches (uses worktree-aware helper)
 	repo, err := openRepository()
 	if err != nil {
@@ -92,14 +222,14 @@ func runSessionsFix(cmd *cobra.Command, force bool) error {
 	}
 
 	if len(stuck) == 0 {
-		fmt.Fprintln(cmd.OutOrStdout(), "No stuck sessions found.")
+		fmt.Fprintln(cmd.OutOrStdout(), i18n.T("No stuck sessions found."))
 		return nil
 	}
 
 	// Get the current strategy for condense operations
 	strat := GetStrategy()
 
-	fmt.Fprintf(cmd.OutOrStdout(), "Found %d stuck session(s):\n\n", len(stuck))
+	fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n", i18n.Tf("Found %d stuck session(s):", len(stuck)))
 
 	for _, ss := range stuck {
 		displayStuckSession(cmd, ss)
@@ -109,14 +239,14 @@ func runSessionsFix(cmd *cobra.Command, force bool) error {
 				if err := strat.CondenseSessionByID(ss.State.SessionID); err != nil {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to condense session %s: %v\n", ss.State.SessionID, err)
 				} else {
-					fmt.Fprintf(cmd.OutOrStdout(), "  -> Condensed session %s\n\n", ss.State.SessionID)
+					fmt.Fprintf(cmd.OutOrStdout(), "  -> %s\n\n", i18n.Tf("Condensed session %s", ss.State.SessionID))
 				}
 			} else {
 				// Discard if we can't condense
 				if err := discardSession(ss, repo, cmd.ErrOrStderr()); err != nil {
 					fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to discard session %s: %v\n", ss.State.SessionID, err)
 				} else {
-					fmt.Fprintf(cmd.OutOrStdout(), "  -> Discarded session %s\n\n", ss.State.SessionID)
+					fmt.Fprintf(cmd.OutOrStdout(), "  -> %s\n\n", i18n.Tf("Discarded session %s", ss.State.SessionID))
 				}
 			}
 			continue
@@ -136,16 +266,16 @@ func runSessionsFix(cmd *cobra.Command, force bool) error {
 			if err := strat.CondenseSessionByID(ss.State.SessionID); err != nil {
 				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to condense session %s: %v\n", ss.State.SessionID, err)
 			} else {
-				fmt.Fprintf(cmd.OutOrStdout(), "  -> Condensed session %s\n\n", ss.State.SessionID)
+				fmt.Fprintf(cmd.OutOrStdout(), "  -> %s\n\n", i18n.Tf("Condensed session %s", ss.State.SessionID))
 			}
 		case "discard":
 			if err := discardSession(ss, repo, cmd.ErrOrStderr()); err != nil {
 				fmt.Fprintf(cmd.ErrOrStderr(), "Warning: failed to discard session %s: %v\n", ss.State.SessionID, err)
 			} else {
-				fmt.Fprintf(cmd.OutOrStdout(), "  -> Discarded session %s\n\n", ss.State.SessionID)
+				fmt.Fprintf(cmd.OutOrStdout(), "  -> %s\n\n", i18n.Tf("Discarded session %s", ss.State.SessionID))
 			}
 		case "skip":
-			fmt.Fprintf(cmd.OutOrStdout(), "  -> Skipped\n\n")
+			fmt.Fprintf(cmd.OutOrStdout(), "  -> %s\n\n", i18n.T("Skipped"))
 		}
 	}
 
@@ -171,9 +301,9 @@ func classifySession(state *strategy.SessionState, repo *git.Repository, now tim
 
 		var reason string
 		if state.LastInteractionTime != nil {
-			reason = fmt.Sprintf("active, last interaction %s ago", now.Sub(*state.LastInteractionTime).Truncate(time.Minute))
+			reason = i18n.Tf("active, last interaction %s ago", now.Sub(*state.LastInteractionTime).Truncate(time.Minute))
 		} else {
-			reason = "active, no recorded interaction time"
+			reason = i18n.T("active, no recorded interaction time")
 		}
 
 		return &stuckSession{
@@ -193,7 +323,7 @@ func classifySession(state *strategy.SessionState, repo *git.Repository, now tim
 
 		return &stuckSession{
 			State:             state,
-			Reason:            "ended with uncondensed checkpoint data",
+			Reason:            i18n.T("ended with uncondensed checkpoint data"),
 			ShadowBranch:      shadowBranch,
 			HasShadowBranch:   hasShadowBranch,
 			CheckpointCount:   state.StepCount,
@@ -209,24 +339,24 @@ func classifySession(state *strategy.SessionState, repo *git.Repository, now tim
 func displayStuckSession(cmd *cobra.Command, ss stuckSession) {
 	w := cmd.OutOrStdout()
 
-	fmt.Fprintf(w, "  Session: %s\n", ss.State.SessionID)
-	fmt.Fprintf(w, "  Phase:   %s\n", ss.State.Phase)
-	fmt.Fprintf(w, "  Reason:  %s\n", ss.Reason)
+	fmt.Fprintf(w, "  %s %s\n", i18n.T("Session:"), ss.State.SessionID)
+	fmt.Fprintf(w, "  %s %s\n", i18n.T("Phase:"), ss.State.Phase)
+	fmt.Fprintf(w, "  %s %s\n", i18n.T("Reason:"), ss.Reason)
 
 	if ss.State.AgentType != "" {
-		fmt.Fprintf(w, "  Agent:   %s\n", ss.State.AgentType)
+		fmt.Fprintf(w, "  %s %s\n", i18n.T("Agent:"), ss.State.AgentType)
 	}
 
 	if ss.State.LastInteractionTime != nil {
-		fmt.Fprintf(w, "  Last interaction: %s\n", ss.State.LastInteractionTime.Format(time.RFC3339))
+		fmt.Fprintf(w, "  %s %s\n", i18n.T("Last interaction:"), ss.State.LastInteractionTime.Format(time.RFC3339))
 	}
 
-	shadowStatus := "not found"
+	shadowStatus := i18n.T("not found")
 	if ss.HasShadowBranch {
-		shadowStatus = fmt.Sprintf("exists (%s)", ss.ShadowBranch)
+		shadowStatus = i18n.Tf("exists (%s)", ss.ShadowBranch)
 	}
-	fmt.Fprintf(w, "  Shadow branch: %s\n", shadowStatus)
-	fmt.Fprintf(w, "  Checkpoints: %d, Files touched: %d\n", ss.CheckpointCount, ss.FilesTouchedCount)
+	fmt.Fprintf(w, "  %s %s\n", i18n.T("Shadow branch:"), shadowStatus)
+	fmt.Fprintf(w, "  %s\n", i18n.Tf("Checkpoints: %d, Files touched: %d", ss.CheckpointCount, ss.FilesTouchedCount))
 }
 
 // promptSessionAction asks the user what to do with a stuck session.
@@ -235,17 +365,17 @@ func promptSessionAction(ss stuckSession) (string, error) {
 
 	options := make([]huh.Option[string], 0, 3)
 	if ss.HasShadowBranch && ss.CheckpointCount > 0 {
-		options = append(options, huh.NewOption("Condense (save to permanent storage)", "condense"))
+		options = append(options, huh.NewOption(i18n.T("Condense (save to permanent storage)"), "condense"))
 	}
 	options = append(options,
-		huh.NewOption("Discard (remove session data)", "discard"),
-		huh.NewOption("Skip (leave as-is)", "skip"),
+		huh.NewOption(i18n.T("Discard (remove session data)"), "discard"),
+		huh.NewOption(i18n.T("Skip (leave as-is)"), "skip"),
 	)
 
 	form := NewAccessibleForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
-				Title(fmt.Sprintf("Fix session %s?", ss.State.SessionID)).
+				Title(i18n.Tf("Fix session %s?", ss.State.SessionID)).
 				Options(options...).
 				Value(&action),
 		),
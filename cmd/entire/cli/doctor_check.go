@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+// healthCheck is the result of one `entire doctor check` check.
+type healthCheck struct {
+	name     string
+	problems []string
+	// remedy tells the user how to fix the problems.
+	remedy string
+}
+
+func newDoctorCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Check that Entire is set up correctly in this repository",
+		Long: `Run read-only health checks and print how to fix each failure:
+  - Settings files parse
+  - Git hooks are installed, executable, and run the expected commands
+  - Agent hooks run the expected commands
+  - The entire/checkpoints/v1 branch has the expected layout
+  - Shadow branches resolve to readable commits
+  - Session states can be read and agree with their shadow branches
+
+Exits with a non-zero status when any check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			repo, err := openRepository()
+			if err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), i18n.T("Not a git repository."))
+				return NewSilentError(errors.New("not a git repository"))
+			}
+
+			checks := runHealthChecks(checkpoint.NewGitStore(repo))
+			if failed := writeHealthChecks(cmd.OutOrStdout(), checks); failed > 0 {
+				cmd.SilenceUsage = true
+				return NewSilentError(fmt.Errorf("%d health check(s) failed", failed))
+			}
+			return nil
+		},
+	}
+}
+
+func runHealthChecks(store *checkpoint.GitStore) []healthCheck {
+	localDev := false
+	if s, err := settings.Load(); err == nil {
+		localDev = s.LocalDev
+	}
+
+	checks := []healthCheck{
+		checkSettingsFiles(),
+		checkGitHooks(localDev),
+		checkAgentHooks(localDev),
+		checkMetadataBranch(store),
+	}
+
+	issues, err := strategy.FindRepairIssues()
+	shadow := healthCheck{name: i18n.T("Shadow branches"), remedy: i18n.T("Run 'entire doctor --repair' to delete branches that can't be read.")}
+	sessions := healthCheck{name: i18n.T("Session states"), remedy: i18n.T("Run 'entire doctor --repair' to fix them.")}
+	if err != nil {
+		shadow.problems = []string{"check failed: " + err.Error()}
+		sessions.problems = shadow.problems
+	}
+	for _, issue := range issues {
+		switch issue.Kind {
+		case strategy.RepairCorruptedRef, strategy.RepairZeroHash, strategy.RepairInconsistentState, strategy.RepairUnreadableState:
+		default:
+			continue // orphaned metadata directories are harmless
+		}
+		switch {
+		case issue.Target == "refs/heads/"+paths.MetadataBranchName():
+			// reported by the metadata branch check
+		case strings.HasPrefix(issue.Target, "refs/"):
+			shadow.problems = append(shadow.problems, issue.String())
+		default:
+			sessions.problems = append(sessions.problems, "session "+issue.String())
+		}
+	}
+	return append(checks, shadow, sessions)
+}
+
+func checkSettingsFiles() healthCheck {
+	check := healthCheck{
+		name:   i18n.T("Settings files"),
+		remedy: i18n.T("Fix or remove the invalid settings; 'entire schema dump' prints the settings schema."),
+	}
+	for _, file := range []string{settings.EntireSettingsFile, settings.EntireSettingsLocalFile} {
+		path, err := paths.AbsPath(file)
+		if err != nil {
+			path = file
+		}
+		if _, err := settings.LoadFromFile(path); err != nil {
+			check.problems = append(check.problems, fmt.Sprintf("%s: %v", file, err))
+		}
+	}
+	if s, err := settings.Load(); err == nil {
+		if err := s.RefNamespace().Validate(); err != nil {
+			check.problems = append(check.problems, "namespace (defaults are used instead): "+err.Error())
+		}
+		if err := s.RedactionRules().Validate(); err != nil {
+			check.problems = append(check.problems, "redaction (built-in rules are used instead): "+err.Error())
+		}
+	}
+	return check
+}
+
+func checkGitHooks(localDev bool) healthCheck {
+	check := healthCheck{
+		name:   i18n.T("Git hooks"),
+		remedy: i18n.T("Run 'entire enable' to reinstall the hooks, and 'chmod +x' any hook that is not executable."),
+	}
+	if !settings.IsSetUp() {
+		check.problems = []string{i18n.T("Entire is not set up in this repository")}
+		return check
+	}
+	problems, err := strategy.GitHookProblems(localDev)
+	if err != nil {
+		problems = []string{"check failed: " + err.Error()}
+	}
+	check.problems = problems
+	return check
+}
+
+func checkAgentHooks(localDev bool) healthCheck {
+	check := healthCheck{
+		name:   i18n.T("Agent hooks"),
+		remedy: i18n.T("Run 'entire enable --agent <agent> --force' to reinstall an agent's hooks."),
+	}
+	installed := GetAgentsWithHooksInstalled()
+	if len(installed) == 0 {
+		check.problems = []string{i18n.T("no agent has Entire hooks installed")}
+		return check
+	}
+	for _, name := range installed {
+		ag, err := agent.Get(name)
+		if err != nil {
+			continue
+		}
+		verifier, ok := ag.(agent.HookVerifier)
+		if !ok {
+			continue // presence is all that can be checked
+		}
+		missing, err := verifier.MissingHooks(localDev)
+		if err != nil {
+			check.problems = append(check.problems, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		for _, command := range missing {
+			check.problems = append(check.problems, fmt.Sprintf("%s: missing hook %q", name, command))
+		}
+	}
+	return check
+}
+
+func checkMetadataBranch(store *checkpoint.GitStore) healthCheck {
+	check := healthCheck{
+		name:   i18n.T("Metadata branch"),
+		remedy: i18n.Tf("Restore %s from the remote or a backup; 'entire doctor --repair' fixes a branch that points at the zero hash.", paths.MetadataBranchName()),
+	}
+	problems, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		problems = []string{"check failed: " + err.Error()}
+	}
+	check.problems = problems
+	return check
+}
+
+// maxHealthProblems is how many problems are listed per failed check.
+const maxHealthProblems = 10
+
+// writeHealthChecks prints one line per check, with the problems and remedy
+// of each failed check, and returns how many checks failed.
+func writeHealthChecks(w io.Writer, checks []healthCheck) int {
+	failed := 0
+	for _, check := range checks {
+		if len(check.problems) == 0 {
+			fmt.Fprintf(w, "✓ %s\n", check.name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "✗ %s\n", check.name)
+		for i, problem := range check.problems {
+			if i == maxHealthProblems {
+				fmt.Fprintf(w, "    %s\n", i18n.Tf("... and %d more", len(check.problems)-maxHealthProblems))
+				break
+			}
+			fmt.Fprintf(w, "    %s\n", problem)
+		}
+		fmt.Fprintf(w, "  → %s\n", check.remedy)
+	}
+
+	fmt.Fprintln(w)
+	if failed == 0 {
+		fmt.Fprintln(w, i18n.T("All checks passed."))
+	} else {
+		fmt.Fprintln(w, i18n.Tf("%d of %d checks failed.", failed, len(checks)))
+	}
+	return failed
+}
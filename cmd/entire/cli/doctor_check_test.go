@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runDoctorCheckForTest(t *testing.T) (string, error) {
+	t.Helper()
+	cmd := newDoctorCheckCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestDoctorCheck(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupCleanTestRepo(t)
+	strategy.ClearHooksDirCache()
+
+	output, err := runDoctorCheckForTest(t)
+	require.Error(t, err)
+	assert.Contains(t, output, "✗ Git hooks\n    Entire is not set up in this repository\n  → Run 'entire enable'")
+	assert.Contains(t, output, "✗ Agent hooks\n    no agent has Entire hooks installed\n")
+	assert.Contains(t, output, "✓ Settings files\n")
+	assert.Contains(t, output, "2 of 6 checks failed.")
+
+	writeSettings(t, `{"enabled": true}`)
+	_, err = strategy.InstallGitHook(true, false)
+	require.NoError(t, err)
+	_, err = (&claudecode.ClaudeCodeAgent{}).InstallHooks(false, false)
+	require.NoError(t, err)
+
+	output, err = runDoctorCheckForTest(t)
+	require.NoError(t, err, output)
+	assert.Contains(t, output, "All checks passed.")
+
+	hooksDir, err := strategy.GetHooksDir()
+	require.NoError(t, err)
+	require.NoError(t, os.Chmod(filepath.Join(hooksDir, "post-commit"), 0o644))
+	require.NoError(t, os.WriteFile(".entire/settings.local.json", []byte(`{"enabled": tru`), 0o644))
+
+	output, err = runDoctorCheckForTest(t)
+	require.Error(t, err)
+	assert.Contains(t, output, "✗ Git hooks\n    post-commit is not executable\n")
+	assert.Contains(t, output, "✗ Settings files\n    .entire/settings.local.json: parsing settings file")
+	assert.Contains(t, output, "2 of 6 checks failed.")
+}
+
+func TestDoctorCheck_Translated(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir() and t.Setenv()
+	setupCleanTestRepo(t)
+	strategy.ClearHooksDirCache()
+	t.Setenv(i18n.LocaleEnvVar, "es")
+
+	output, err := runDoctorCheckForTest(t)
+	require.Error(t, err)
+	assert.Contains(t, output, "✗ Hooks de git\n    Entire no está configurado en este repositorio\n  → Ejecuta 'entire enable'")
+	assert.Contains(t, output, "Fallaron 2 de 6 comprobaciones.")
+}
@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
@@ -319,3 +322,47 @@ func TestRunSessionsFix_DeprecatedStrategyWarning(t *testing.T) {
 		t.Errorf("Expected warning after main output, got: %s", output)
 	}
 }
+
+func TestReportStateDrift(t *testing.T) {
+	dir := setupGitRepoForPhaseTest(t)
+	t.Chdir(dir)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	// The fixture commit has no session trailer, so it is not one of the session's steps.
+	createShadowBranchRef(t, repo, testBaseCommit, "")
+	states := []*strategy.SessionState{
+		{SessionID: "test-drifted", BaseCommit: testBaseCommit, StepCount: 2},
+		{SessionID: "test-healthy", BaseCommit: testBaseCommit},
+	}
+
+	var stdout, stderr bytes.Buffer
+	reportStateDrift(&stdout, &stderr, states)
+
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, "Session state drift detected (1):\n"+
+		"  test-drifted step_count: state records 2 step(s) but the shadow branch has 0\n\n", stdout.String())
+}
+
+func TestRunRepair_Force(t *testing.T) {
+	dir := setupGitRepoForPhaseTest(t)
+	t.Chdir(dir)
+
+	orphan := filepath.Join(dir, paths.EntireMetadataDir, "2026-02-01-orphan")
+	require.NoError(t, os.MkdirAll(orphan, 0o755))
+
+	var stdout bytes.Buffer
+	cmd := &cobra.Command{Use: "doctor"}
+	cmd.SetOut(&stdout)
+	require.NoError(t, runRepair(cmd, true))
+
+	assert.Equal(t, "Found 1 problem(s):\n\n"+
+		"  .entire/metadata/2026-02-01-orphan: no session state refers to it\n"+
+		"  Fix: remove the directory\n"+
+		"  -> Repaired\n\n", stdout.String())
+	assert.NoDirExists(t, orphan)
+
+	stdout.Reset()
+	require.NoError(t, runRepair(cmd, true))
+	assert.Equal(t, "No damaged data found.\n\n", stdout.String())
+}
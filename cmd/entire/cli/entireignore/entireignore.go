@@ -0,0 +1,84 @@
+// Package entireignore reads .entireignore, which lists files Entire leaves
+// out of checkpoints, such as build artifacts an agent creates that are not
+// covered by .gitignore.
+//
+// Each line is a glob pattern in path.Match syntax, where "*" does not match
+// "/". A pattern matches a file or any directory containing it, and, as in
+// .gitignore, a pattern without a "/" matches a name at any depth. Blank
+// lines and lines starting with "#" are skipped, and a pattern starting with
+// "!" re-includes files an earlier pattern excluded. The last matching
+// pattern wins.
+package entireignore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/pathmatch"
+)
+
+// FileName is the name of the ignore file, read from the worktree root.
+const FileName = ".entireignore"
+
+type rule struct {
+	pattern string
+	negate  bool
+}
+
+// Matcher matches paths against the patterns of an .entireignore file. A nil
+// Matcher matches nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// Load reads the .entireignore file in worktreeRoot. Returns nil, and no
+// error, when there is no such file.
+func Load(worktreeRoot string) (*Matcher, error) {
+	data, err := os.ReadFile(filepath.Join(worktreeRoot, FileName)) //nolint:gosec // path is within the worktree
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return Parse(data), nil
+}
+
+// Parse parses the contents of an .entireignore file.
+func Parse(data []byte) *Matcher {
+	m := &Matcher{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		negate := false
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			line, negate = rest, true
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			m.rules = append(m.rules, rule{pattern: line, negate: negate})
+		}
+	}
+	return m
+}
+
+// Match reports whether file, a slash-separated path relative to the
+// worktree root, is ignored.
+func (m *Matcher) Match(file string) bool {
+	if m == nil {
+		return false
+	}
+	ignored := false
+	for _, r := range m.rules {
+		if pathmatch.Match(r.pattern, file) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,66 @@
+package entireignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	t.Parallel()
+
+	m := Parse([]byte(`# transient output
+node_modules
+coverage/
+*.log
+build/*.o
+!important.log
+
+`))
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "node_modules/left-pad/index.js", want: true},
+		{file: "web/node_modules/x.js", want: true}, // no "/": any depth
+		{file: "coverage/lcov.info", want: true},
+		{file: "src/coverage.go"},
+		{file: "debug.log", want: true},
+		{file: "logs/server.log", want: true},
+		{file: "important.log"}, // re-included by "!"
+		{file: "build/main.o", want: true},
+		{file: "cmd/build/main.o"}, // has "/": anchored to the root
+		{file: "main.go"},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.file); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+
+	var none *Matcher
+	if none.Match("anything") {
+		t.Error("a nil Matcher should match nothing")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m, err := Load(dir)
+	if err != nil || m != nil {
+		t.Fatalf("Load() without a file = %v, %v, want nil, nil", m, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("dist\n"), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", FileName, err)
+	}
+	m, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !m.Match("dist/app.js") {
+		t.Error("Match(dist/app.js) = false, want true")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/entireio/cli/cmd/entire/cli/summarize"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
@@ -72,9 +74,10 @@ func newExplainCmd() *cobra.Command {
 	var generateFlag bool
 	var forceFlag bool
 	var searchAllFlag bool
+	var historyFlag bool
 
 	cmd := &cobra.Command{
-		Use:   "explain",
+		Use:   "explain [commit]",
 		Short: "Explain a session, commit, or checkpoint",
 		Long: `Explain provides human-readable context about sessions, commits, and checkpoints.
 
@@ -88,6 +91,8 @@ Filtering the list view:
   --session      Filter checkpoints by session ID (or prefix)
 
 Viewing specific items:
+  <commit>       Explain a commit: the prompts, summary, files touched, and
+                 token usage of the checkpoints behind it (same as --commit)
   --commit       Explain a specific commit (shows its associated checkpoint)
   --checkpoint   Explain a specific checkpoint by ID
 
@@ -101,6 +106,11 @@ Summary generation (for --checkpoint):
   --generate    Generate an AI summary for the checkpoint
   --force       Regenerate even if a summary already exists (requires --generate)
 
+Revision history (for --checkpoint):
+  --history     List all recorded revisions of each session in the checkpoint.
+                Readers show the latest revision by default; revisions are
+                recorded when strategy_options.append_only_checkpoints is enabled.
+
 Performance options:
   --search-all  Remove branch/depth limits when searching for commits (may be slow)
 
@@ -109,14 +119,24 @@ Checkpoint detail view shows:
   - Associated git commits that reference the checkpoint
   - Prompts and responses from the session
 
+A commit is linked to checkpoints by its Entire-Checkpoint trailers or by
+'entire stamp'.
+
 Note: --session filters the list view; --commit and --checkpoint are mutually exclusive.`,
 		Args: func(_ *cobra.Command, args []string) error {
-			if len(args) > 0 {
-				return fmt.Errorf("unexpected argument %q\nHint: use --checkpoint, --session, or --commit to specify what to explain", args[0])
+			if len(args) > 1 {
+				return fmt.Errorf("unexpected argument %q\nHint: pass a single commit, or use --checkpoint, --session, or --commit to specify what to explain", args[1])
 			}
 			return nil
 		},
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if commitFlag != "" || checkpointFlag != "" || sessionFlag != "" {
+					return fmt.Errorf("unexpected argument %q\nHint: a commit argument can't be combined with --checkpoint, --session, or --commit", args[0])
+				}
+				commitFlag = args[0]
+			}
+
 			// Check if Entire is disabled
 			if checkDisabledGuard(cmd.OutOrStdout()) {
 				return nil
@@ -132,6 +152,12 @@ Note: --session filters the list view; --commit and --checkpoint are mutually ex
 			if rawTranscriptFlag && checkpointFlag == "" {
 				return errors.New("--raw-transcript requires --checkpoint/-c flag")
 			}
+			if historyFlag {
+				if checkpointFlag == "" {
+					return errors.New("--history requires --checkpoint/-c flag")
+				}
+				return runExplainCheckpointHistory(cmd.OutOrStdout(), checkpointFlag)
+			}
 
 			// Convert short flag to verbose (verbose = !short)
 			verbose := !shortFlag
@@ -149,11 +175,13 @@ Note: --session filters the list view; --commit and --checkpoint are mutually ex
 	cmd.Flags().BoolVar(&generateFlag, "generate", false, "Generate an AI summary for the checkpoint")
 	cmd.Flags().BoolVar(&forceFlag, "force", false, "Regenerate summary even if one already exists (requires --generate)")
 	cmd.Flags().BoolVar(&searchAllFlag, "search-all", false, "Search all commits (no branch/depth limit, may be slow)")
+	cmd.Flags().BoolVar(&historyFlag, "history", false, "Show all revisions of a checkpoint (requires --checkpoint)")
 
 	// Make --short, --full, and --raw-transcript mutually exclusive
 	cmd.MarkFlagsMutuallyExclusive("short", "full", "raw-transcript")
 	// --generate and --raw-transcript are incompatible (summary would be generated but not shown)
 	cmd.MarkFlagsMutuallyExclusive("generate", "raw-transcript")
+	cmd.MarkFlagsMutuallyExclusive("history", "generate", "raw-transcript")
 
 	return cmd
 }
@@ -203,6 +231,9 @@ func runExplainCheckpoint(w, errW io.Writer, checkpointIDPrefix string, noPager,
 	}
 
 	store := checkpoint.NewGitStore(repo)
+	if s, loadErr := settings.Load(); loadErr == nil {
+		store.SetAppendOnly(s.IsAppendOnlyCheckpointsEnabled())
+	}
 
 	// First, try to find in committed checkpoints by checkpoint ID prefix
 	committed, err := store.ListCommitted(context.Background())
@@ -288,8 +319,12 @@ func runExplainCheckpoint(w, errW io.Writer, checkpointIDPrefix string, noPager,
 	// Look up the author for this checkpoint (best-effort, ignore errors)
 	author, _ := store.GetCheckpointAuthor(context.Background(), fullCheckpointID) //nolint:errcheck // Author is optional
 
-	// Find associated commits (git commits with matching Entire-Checkpoint trailer)
+	// Find associated commits (git commits with matching Entire-Checkpoint trailer),
+	// plus commits linked by `entire stamp` or `entire import foreign`
 	associatedCommits, _ := getAssociatedCommits(repo, fullCheckpointID, searchAll) //nolint:errcheck // Best-effort
+	if summary != nil {
+		associatedCommits = appendStampedCommits(repo, associatedCommits, summary.StampedCommits)
+	}
 
 	// Format and output
 	output := formatCheckpointOutput(summary, content, fullCheckpointID, associatedCommits, author, verbose, full)
@@ -335,6 +370,77 @@ func generateCheckpointSummary(w, _ io.Writer, store *checkpoint.GitStore, check
 	return nil
 }
 
+// runExplainCheckpointHistory lists every recorded revision of each session in
+// a committed checkpoint, oldest first.
+func runExplainCheckpointHistory(w io.Writer, checkpointIDPrefix string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+	ctx := context.Background()
+
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var matches []id.CheckpointID
+	for _, info := range committed {
+		if strings.HasPrefix(info.CheckpointID.String(), checkpointIDPrefix) {
+			matches = append(matches, info.CheckpointID)
+		}
+	}
+	switch {
+	case len(matches) == 0:
+		return fmt.Errorf("checkpoint not found: %s", checkpointIDPrefix)
+	case len(matches) > 1:
+		return fmt.Errorf("ambiguous checkpoint prefix %q matches %d checkpoints", checkpointIDPrefix, len(matches))
+	}
+	checkpointID := matches[0]
+
+	summary, err := store.ReadCommitted(ctx, checkpointID)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if summary == nil {
+		return fmt.Errorf("checkpoint not found: %s", checkpointID)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Checkpoint: %s\n", checkpointID)
+	for i := range len(summary.Sessions) {
+		history, err := store.ReadSessionRevisions(ctx, checkpointID, i)
+		if err != nil {
+			return fmt.Errorf("failed to read revisions: %w", err)
+		}
+		sessionID := ""
+		if content, err := store.ReadSessionContent(ctx, checkpointID, i); err == nil {
+			sessionID = content.Metadata.SessionID
+		}
+		fmt.Fprintf(&sb, "\nSession: %s\n", sessionID)
+		formatRevisionHistory(&sb, history)
+	}
+	fmt.Fprint(w, sb.String())
+	return nil
+}
+
+// formatRevisionHistory writes one line per revision, marking the latest one.
+func formatRevisionHistory(sb *strings.Builder, history []checkpoint.SessionRevision) {
+	for i, rev := range history {
+		line := fmt.Sprintf("  r%d  %s  %s", rev.Revision, rev.CreatedAt.Format("2006-01-02 15:04:05"), rev.Reason)
+		if len(rev.Files) > 0 {
+			line += " (" + strings.Join(rev.Files, ", ") + ")"
+		}
+		if rev.Summary != nil {
+			line += " (summary)"
+		}
+		if i == len(history)-1 {
+			line += "  [latest]"
+		}
+		sb.WriteString(line + "\n")
+	}
+}
+
 // explainTemporaryCheckpoint finds and formats a temporary checkpoint by shadow commit hash prefix.
 // Returns the formatted output and whether the checkpoint was found.
 // Searches ALL shadow branches, not just the one for current HEAD, to find checkpoints
@@ -529,6 +635,28 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 	return commits, nil
 }
 
+// appendStampedCommits adds the commits stamped onto a checkpoint that are not
+// already in commits. Stamped commits that no longer exist are skipped.
+func appendStampedCommits(repo *git.Repository, commits []associatedCommit, stamped []string) []associatedCommit {
+	for _, hash := range stamped {
+		if slices.ContainsFunc(commits, func(c associatedCommit) bool { return c.SHA == hash }) {
+			continue
+		}
+		c, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			continue
+		}
+		commits = append(commits, associatedCommit{
+			SHA:      hash,
+			ShortSHA: strategy.TruncateHash(hash),
+			Message:  strings.Split(c.Message, "\n")[0],
+			Author:   c.Author.Name,
+			Date:     c.Author.When,
+		})
+	}
+	return commits
+}
+
 // scopeTranscriptForCheckpoint slices a transcript to include only the portion
 // relevant to a specific checkpoint, starting from the given offset.
 // For Claude Code (JSONL), the offset is a line number and we slice by line.
@@ -547,7 +675,7 @@ func scopeTranscriptForCheckpoint(fullTranscript []byte, startOffset int, agentT
 			return nil
 		}
 		return scoped
-	case agent.AgentTypeClaudeCode, agent.AgentTypeUnknown:
+	case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeAider, agent.AgentTypeCodex, agent.AgentTypeUnknown:
 		return transcript.SliceFromLine(fullTranscript, startOffset)
 	}
 	return transcript.SliceFromLine(fullTranscript, startOffset)
@@ -606,6 +734,12 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 	if author.Name != "" {
 		fmt.Fprintf(&sb, "Author: %s <%s>\n", author.Name, author.Email)
 	}
+	if len(meta.Tickets) > 0 {
+		fmt.Fprintf(&sb, "Tickets: %s\n", strings.Join(meta.Tickets, ", "))
+	}
+	if imp := meta.Imported; imp != nil {
+		fmt.Fprintf(&sb, "Imported: %s\n", formatImportInfo(imp))
+	}
 
 	// Token usage - prefer content metadata, fall back to summary
 	tokenUsage := meta.TokenUsage
@@ -615,7 +749,30 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 	if tokenUsage != nil {
 		totalTokens := tokenUsage.InputTokens + tokenUsage.CacheCreationTokens +
 			tokenUsage.CacheReadTokens + tokenUsage.OutputTokens
-		fmt.Fprintf(&sb, "Tokens: %d\n", totalTokens)
+		if est, ok := loadCostEstimator().Estimate(meta.Agent, tokenUsage); ok {
+			fmt.Fprintf(&sb, "Tokens: %d (cost %s estimated)\n", totalTokens, est)
+		} else {
+			fmt.Fprintf(&sb, "Tokens: %d\n", totalTokens)
+		}
+	}
+	if gate := meta.QualityGate; gate != nil {
+		fmt.Fprintf(&sb, "Quality gate: %s (%s)\n", gate.Summary(), gate.Command)
+	}
+	if scan := meta.ComplianceScan; scan != nil {
+		fmt.Fprintf(&sb, "Compliance: %s (%s)\n", scan.Summary(), scan.Command)
+		if verbose || full {
+			if scan.Error != "" {
+				fmt.Fprintf(&sb, "  %s\n", scan.Error)
+			}
+			for _, f := range scan.Findings {
+				fmt.Fprintf(&sb, "  - %s\n", f)
+			}
+		}
+	}
+
+	// Per-session breakdown when several sessions condensed into this checkpoint
+	if summary != nil && len(summary.Contributions) > 1 {
+		formatSessionContributions(&sb, summary.Contributions, meta.SessionID, verbose || full)
 	}
 
 	// Associated commits section
@@ -653,6 +810,11 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 		sb.WriteString("Outcome: (not generated)\n")
 	}
 
+	// Machine review from the configured review command
+	if content.Review != "" {
+		formatMachineReview(&sb, content.Review)
+	}
+
 	// Verbose: add learnings, friction, files, and scoped transcript
 	if verbose || full {
 		// AI Summary details (learnings, friction, open items)
@@ -679,6 +841,31 @@ func formatCheckpointOutput(summary *checkpoint.CheckpointSummary, content *chec
 	return sb.String()
 }
 
+// formatSessionContributions appends the per-session breakdown of a checkpoint that
+// several sessions contributed to. The session whose content is displayed below is
+// marked with "*". Files are listed per session when verbose is set.
+func formatSessionContributions(sb *strings.Builder, contributions []checkpoint.SessionContribution, displayedSessionID string, verbose bool) {
+	sb.WriteString("\n")
+	fmt.Fprintf(sb, "Sessions: (%d)\n", len(contributions))
+	for _, c := range contributions {
+		marker := " "
+		if c.SessionID == displayedSessionID {
+			marker = "*"
+		}
+		agentLabel := string(c.Agent)
+		if agentLabel == "" {
+			agentLabel = "unknown agent"
+		}
+		fmt.Fprintf(sb, "%s %s (%s): %d prompts, %d files, %s tokens\n",
+			marker, c.SessionID, agentLabel, c.PromptsCount, len(c.FilesTouched), formatTokenCount(totalTokens(c.TokenUsage)))
+		if verbose {
+			for _, file := range c.FilesTouched {
+				fmt.Fprintf(sb, "    - %s\n", file)
+			}
+		}
+	}
+}
+
 // appendTranscriptSection appends the appropriate transcript section to the builder
 // based on verbosity level. Full mode shows the entire session, verbose shows checkpoint scope.
 // fullTranscript is the entire session transcript, scopedContent is either scoped transcript bytes
@@ -720,6 +907,18 @@ func formatTranscriptBytes(transcriptBytes []byte, fallback string, agentType ag
 	return summarize.FormatCondensedTranscript(input)
 }
 
+// formatMachineReview appends the output of the review command, indented.
+func formatMachineReview(sb *strings.Builder, review string) {
+	sb.WriteString("\nMachine review:\n")
+	for _, line := range strings.Split(strings.TrimRight(review, "\n"), "\n") {
+		if line == "" {
+			sb.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(sb, "  %s\n", line)
+	}
+}
+
 // formatSummaryDetails formats the detailed sections of an AI summary.
 func formatSummaryDetails(sb *strings.Builder, summary *checkpoint.Summary) {
 	// Learnings section
@@ -917,10 +1116,17 @@ func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoi
 
 	var points []strategy.RewindPoint
 
+	stamps := newStampIndex(committedInfos)
+
 	collectCheckpoint := func(c *object.Commit) {
 		cpID, found := trailers.ParseCheckpoint(c.Message)
 		if !found {
-			return
+			// Commits linked by `entire stamp` or `entire import foreign`
+			stamped := stamps[c.Hash.String()]
+			if len(stamped) == 0 {
+				return
+			}
+			cpID = stamped[0]
 		}
 		cpInfo, found := committedByID[cpID]
 		if !found {
@@ -1180,18 +1386,30 @@ func runExplainCommit(w io.Writer, commitRef string, noPager, verbose, full, sea
 		return fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	// Extract Entire-Checkpoint trailer
-	checkpointID, hasCheckpoint := trailers.ParseCheckpoint(commit.Message)
-	if !hasCheckpoint {
+	// Checkpoints from Entire-Checkpoint trailers, then those linked by `entire stamp`
+	checkpointIDs := trailers.ParseAllCheckpoints(commit.Message)
+	if committed, listErr := checkpoint.NewGitStore(repo).ListCommitted(context.Background()); listErr == nil {
+		checkpointIDs = newStampIndex(committed).checkpointsFor(commit)
+	}
+	if len(checkpointIDs) == 0 {
 		fmt.Fprintln(w, "No associated Entire checkpoint")
-		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer.\n", hash.String()[:7])
+		fmt.Fprintf(w, "\nCommit %s does not have an %s trailer.\n", hash.String()[:7], trailers.CheckpointTrailerKey())
 		fmt.Fprintln(w, "This commit was not created during an Entire session, or the trailer was removed.")
 		return nil
 	}
 
-	// Delegate to checkpoint detail view
+	// Delegate to checkpoint detail view, one checkpoint after another if the
+	// commit has several (e.g. a squash of checkpointed commits)
 	// Note: errW is only used for generate mode, but we pass w for safety
-	return runExplainCheckpoint(w, w, checkpointID.String(), noPager, verbose, full, false, false, false, searchAll)
+	for i, checkpointID := range checkpointIDs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if err := runExplainCheckpoint(w, w, checkpointID.String(), noPager || len(checkpointIDs) > 1, verbose, full, false, false, false, searchAll); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // formatSessionInfo formats session information for display.
@@ -1547,7 +1765,7 @@ func transcriptOffset(transcriptBytes []byte, agentType agent.AgentType) int {
 			return 0
 		}
 		return len(t.Messages)
-	case agent.AgentTypeClaudeCode, agent.AgentTypeOpenCode, agent.AgentTypeUnknown:
+	case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeAider, agent.AgentTypeCodex, agent.AgentTypeOpenCode, agent.AgentTypeUnknown:
 		return countLines(transcriptBytes)
 	}
 	return countLines(transcriptBytes)
@@ -13,6 +13,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
@@ -25,8 +26,8 @@ import (
 func TestNewExplainCmd(t *testing.T) {
 	cmd := newExplainCmd()
 
-	if cmd.Use != "explain" {
-		t.Errorf("expected Use to be 'explain', got %s", cmd.Use)
+	if cmd.Use != "explain [commit]" {
+		t.Errorf("expected Use to be 'explain [commit]', got %s", cmd.Use)
 	}
 
 	// Verify flags exist
@@ -70,9 +71,10 @@ func TestExplainCmd_RejectsPositionalArgs(t *testing.T) {
 		name string
 		args []string
 	}{
-		{"positional arg without flags", []string{"abc123"}},
+		{"two positional args", []string{"abc123", "def456"}},
 		{"positional arg with checkpoint flag", []string{"abc123", "--checkpoint", "def456"}},
 		{"positional arg after flags", []string{"--checkpoint", "def456", "abc123"}},
+		{"positional arg with commit flag", []string{"abc123", "--commit", "def456"}},
 	}
 
 	for _, tt := range tests {
@@ -863,6 +865,31 @@ func TestRunExplainCheckpoint_NotFound(t *testing.T) {
 	}
 }
 
+func TestFormatCheckpointOutput_CostEstimate(t *testing.T) {
+	usage := &agent.TokenUsage{InputTokens: 1_000_000, OutputTokens: 100_000}
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "2026-01-21-test-session",
+			CreatedAt:    time.Date(2026, 1, 21, 10, 30, 0, 0, time.UTC),
+			Agent:        agent.AgentTypeClaudeCode,
+			TokenUsage:   usage,
+		},
+	}
+
+	output := formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if !strings.Contains(output, "Tokens: 1100000 (cost ~$4.50 estimated)") {
+		t.Errorf("expected labeled cost estimate in output, got:\n%s", output)
+	}
+
+	// Agents without known pricing show tokens only
+	content.Metadata.Agent = agent.AgentTypeOpenCode
+	output = formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if strings.Contains(output, "estimated") {
+		t.Errorf("expected no cost estimate for unpriced agent, got:\n%s", output)
+	}
+}
+
 func TestFormatCheckpointOutput_Short(t *testing.T) {
 	summary := &checkpoint.CheckpointSummary{
 		CheckpointID:     id.MustCheckpointID("abc123def456"),
@@ -917,6 +944,166 @@ func TestFormatCheckpointOutput_Short(t *testing.T) {
 	}
 }
 
+func TestFormatCheckpointOutput_SessionContributions(t *testing.T) {
+	summary := &checkpoint.CheckpointSummary{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		FilesTouched: []string{"auth.go", "README.md"},
+		Contributions: []checkpoint.SessionContribution{
+			{
+				SessionID:    "session-claude",
+				Agent:        agent.AgentTypeClaudeCode,
+				PromptsCount: 2,
+				FilesTouched: []string{"auth.go"},
+				TokenUsage:   &agent.TokenUsage{InputTokens: 1000, OutputTokens: 200},
+			},
+			{
+				SessionID:    "session-gemini",
+				Agent:        agent.AgentTypeGemini,
+				PromptsCount: 1,
+				FilesTouched: []string{"README.md"},
+			},
+		},
+	}
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "session-gemini",
+			CreatedAt:    time.Date(2026, 1, 21, 10, 30, 0, 0, time.UTC),
+			FilesTouched: []string{"README.md"},
+		},
+	}
+
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+
+	if !strings.Contains(output, "Sessions: (2)") {
+		t.Errorf("expected sessions section, got:\n%s", output)
+	}
+	if !strings.Contains(output, "  session-claude (Claude Code): 2 prompts, 1 files, 1.2k tokens") {
+		t.Errorf("expected claude contribution line, got:\n%s", output)
+	}
+	if !strings.Contains(output, "* session-gemini (Gemini CLI): 1 prompts, 1 files, 0 tokens") {
+		t.Errorf("expected displayed session to be marked, got:\n%s", output)
+	}
+	if strings.Contains(output, "- auth.go") {
+		t.Error("default output should not list per-session files")
+	}
+
+	verboseOutput := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	if !strings.Contains(verboseOutput, "    - auth.go") {
+		t.Errorf("verbose output should list per-session files, got:\n%s", verboseOutput)
+	}
+}
+
+func TestFormatCheckpointOutput_SingleSessionOmitsContributions(t *testing.T) {
+	summary := &checkpoint.CheckpointSummary{
+		CheckpointID: id.MustCheckpointID("abc123def456"),
+		Contributions: []checkpoint.SessionContribution{
+			{SessionID: "only-session", Agent: agent.AgentTypeClaudeCode, PromptsCount: 1},
+		},
+	}
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "only-session",
+		},
+	}
+
+	output := formatCheckpointOutput(summary, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if strings.Contains(output, "Sessions:") {
+		t.Errorf("single-session checkpoint should not show sessions section, got:\n%s", output)
+	}
+}
+
+func TestFormatCheckpointOutput_MachineReview(t *testing.T) {
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "review-session",
+		},
+		Review: "Looks correct.\n\n- Missing test for empty input\n",
+	}
+
+	output := formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if !strings.Contains(output, "Machine review:\n  Looks correct.\n\n  - Missing test for empty input\n") {
+		t.Errorf("expected indented machine review, got:\n%s", output)
+	}
+
+	content.Review = ""
+	output = formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if strings.Contains(output, "Machine review:") {
+		t.Errorf("checkpoint without a review should not show the section, got:\n%s", output)
+	}
+}
+
+func TestFormatCheckpointOutput_Tickets(t *testing.T) {
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "ticket-session",
+			Tickets:      []string{"ABC-123", "#42"},
+		},
+	}
+
+	output := formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if !strings.Contains(output, "Tickets: ABC-123, #42\n") {
+		t.Errorf("expected tickets line, got:\n%s", output)
+	}
+
+	content.Metadata.Tickets = nil
+	output = formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if strings.Contains(output, "Tickets:") {
+		t.Errorf("checkpoint without tickets should not show the line, got:\n%s", output)
+	}
+}
+
+func TestFormatCheckpointOutput_Imported(t *testing.T) {
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "imported-1a2b3c4d5e6f",
+			Imported: &checkpoint.ImportInfo{
+				Format: "aider",
+				Commit: "1a2b3c4d5e6f7a8b9c0d1a2b3c4d5e6f7a8b9c0d",
+				Tool:   "aider",
+				Model:  "openai/gpt-4o",
+			},
+		},
+	}
+
+	output := formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if !strings.Contains(output, "Imported: aider (openai/gpt-4o) via aider format on 1a2b3c4\n") {
+		t.Errorf("expected imported line, got:\n%s", output)
+	}
+}
+
+func TestFormatCheckpointOutput_ComplianceScan(t *testing.T) {
+	content := &checkpoint.SessionContent{
+		Metadata: checkpoint.CommittedMetadata{
+			CheckpointID: "abc123def456",
+			SessionID:    "scan-session",
+			ComplianceScan: &compliance.Report{
+				Command: "license-scan",
+				Findings: []compliance.Finding{
+					{Kind: compliance.KindDependency, Package: "left-pad", License: "WTFPL", File: "package.json"},
+				},
+			},
+		},
+	}
+
+	output := formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, false, false)
+	if !strings.Contains(output, "Compliance: 1 finding (1 dependency) (license-scan)\n") {
+		t.Errorf("expected compliance summary, got:\n%s", output)
+	}
+	if strings.Contains(output, "left-pad") {
+		t.Errorf("findings should only be listed in verbose output, got:\n%s", output)
+	}
+
+	output = formatCheckpointOutput(nil, content, id.MustCheckpointID("abc123def456"), nil, checkpoint.Author{}, true, false)
+	if !strings.Contains(output, "  - dependency left-pad (WTFPL) in package.json\n") {
+		t.Errorf("expected compliance findings in verbose output, got:\n%s", output)
+	}
+}
+
 func TestFormatCheckpointOutput_Verbose(t *testing.T) {
 	// Transcript with user prompts that match what we expect to see
 	transcriptContent := []byte(`{"type":"user","uuid":"u1","message":{"content":"Add a new feature"}}
@@ -3587,3 +3774,60 @@ func createCommitWithTree(t *testing.T, repo *git.Repository, treeHash plumbing.
 	}
 	return hash
 }
+
+func TestFormatRevisionHistory(t *testing.T) {
+	t.Parallel()
+
+	history := []checkpoint.SessionRevision{
+		{Revision: 0, Reason: "original", CreatedAt: time.Date(2026, 1, 21, 10, 30, 0, 0, time.UTC)},
+		{Revision: 1, Supersedes: 0, Reason: checkpoint.RevisionReasonFinalize, CreatedAt: time.Date(2026, 1, 21, 10, 45, 0, 0, time.UTC), Files: []string{"full.jsonl", "prompt.txt"}},
+	}
+
+	var sb strings.Builder
+	formatRevisionHistory(&sb, history)
+
+	want := "  r0  2026-01-21 10:30:00  original\n" +
+		"  r1  2026-01-21 10:45:00  finalize (full.jsonl, prompt.txt)  [latest]\n"
+	if sb.String() != want {
+		t.Errorf("formatRevisionHistory() =\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestExplainCmd_CommitArgument(t *testing.T) {
+	_, queued, unrelated := setupStampRepo(t)
+
+	cmd := newExplainCmd()
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stdout)
+	cmd.SetArgs([]string{unrelated[:7], "--no-pager"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No associated Entire checkpoint") {
+		t.Errorf("expected no checkpoint for unrelated commit, got: %s", stdout.String())
+	}
+
+	// A commit without a trailer is explained through its `entire stamp` link
+	repo, err := git.PlainOpen(".")
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	if _, err := checkpoint.NewGitStore(repo).StampCommit(context.Background(), id.MustCheckpointID("a1b2c3d4e5f6"), plumbing.NewHash(queued)); err != nil {
+		t.Fatalf("StampCommit() error = %v", err)
+	}
+
+	cmd = newExplainCmd()
+	stdout.Reset()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stdout)
+	cmd.SetArgs([]string{queued, "--no-pager"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, want := range []string{"Checkpoint: a1b2c3d4e5f6", "Session: 2026-04-01-alpha"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output, got: %s", want, stdout.String())
+		}
+	}
+}
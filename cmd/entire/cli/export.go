@@ -0,0 +1,441 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/redact"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// Privacy modes for `entire export prompts`. Secrets are redacted in every
+// mode; the modes control which fields are exported at all.
+const (
+	exportPrivacyFull        = "full"
+	exportPrivacyNoDiffs     = "no-diffs"
+	exportPrivacyPromptsOnly = "prompts-only"
+)
+
+const (
+	exportFormatJSONL    = "jsonl"
+	exportDataFileName   = "prompts.jsonl"
+	exportManifestName   = "manifest.json"
+	exportRedactionNotes = "secrets redacted with gitleaks rules and high-entropy string detection"
+)
+
+// exportTurn is one user prompt and the agent's text response to it.
+type exportTurn struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response,omitempty"`
+}
+
+// exportRecord is one session's contribution to one checkpoint.
+type exportRecord struct {
+	CheckpointID string       `json:"checkpoint_id"`
+	SessionID    string       `json:"session_id"`
+	Agent        string       `json:"agent,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Commit       string       `json:"commit,omitempty"`
+	Tickets      []string     `json:"tickets,omitempty"`
+	Turns        []exportTurn `json:"turns"`
+	Files        []string     `json:"files"`
+	Diff         string       `json:"diff,omitempty"`
+}
+
+// exportStats summarizes an exported dataset.
+type exportStats struct {
+	Records        int            `json:"records"`
+	Checkpoints    int            `json:"checkpoints"`
+	Sessions       int            `json:"sessions"`
+	Prompts        int            `json:"prompts"`
+	Diffs          int            `json:"diffs"`
+	RedactedFields int            `json:"redacted_fields"`
+	Skipped        int            `json:"skipped"`
+	Agents         map[string]int `json:"agents"`
+}
+
+// exportManifest describes where an exported dataset came from and how it was
+// filtered, so it can be audited after it leaves the repository.
+type exportManifest struct {
+	Dataset        string      `json:"dataset"`
+	Format         string      `json:"format"`
+	GeneratedAt    time.Time   `json:"generated_at"`
+	CLIVersion     string      `json:"cli_version"`
+	Repository     string      `json:"repository"`
+	MetadataBranch string      `json:"metadata_branch"`
+	MetadataCommit string      `json:"metadata_commit"`
+	Since          *time.Time  `json:"since,omitempty"`
+	Privacy        string      `json:"privacy"`
+	Redaction      string      `json:"redaction"`
+	Stats          exportStats `json:"stats"`
+	CheckpointIDs  []string    `json:"checkpoint_ids"`
+}
+
+// exportOptions are the flags of `entire export prompts`.
+type exportOptions struct {
+	format  string
+	since   time.Time
+	privacy string
+	output  string
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export data recorded by Entire",
+	}
+
+	cmd.AddCommand(newExportPromptsCmd())
+
+	return cmd
+}
+
+func newExportPromptsCmd() *cobra.Command {
+	var formatFlag string
+	var sinceFlag string
+	var privacyFlag string
+	var outputFlag string
+
+	cmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Export prompts, responses, and diffs from condensed checkpoints",
+		Long: `Export a dataset of prompts, agent responses, and resulting file diffs from
+the checkpoints on ` + paths.MetadataBranchName() + `, e.g. to build internal
+fine-tuning or evaluation sets from your own sessions.
+
+Each record covers one session's part of one checkpoint: the prompts and
+responses from that checkpoint's portion of the transcript, the files the
+session touched, and the diff of those files in the commit that references
+the checkpoint.
+
+Secrets are always redacted. --privacy limits what is exported:
+  full          Prompts, responses, and diffs (default)
+  no-diffs      Prompts and responses only
+  prompts-only  Prompts only
+
+Records are written to stdout unless --output names a directory, in which
+case it receives ` + exportDataFileName + ` and a ` + exportManifestName + ` describing the
+dataset's provenance. Statistics are printed to stderr.
+
+--since accepts a duration (90m, 24h, 7d), a date (2006-01-02), or an
+RFC 3339 timestamp.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if formatFlag != exportFormatJSONL {
+				return fmt.Errorf("unsupported format %q (supported: %s)", formatFlag, exportFormatJSONL)
+			}
+			switch privacyFlag {
+			case exportPrivacyFull, exportPrivacyNoDiffs, exportPrivacyPromptsOnly:
+			default:
+				return fmt.Errorf("unknown privacy mode %q (use %s, %s, or %s)",
+					privacyFlag, exportPrivacyFull, exportPrivacyNoDiffs, exportPrivacyPromptsOnly)
+			}
+			since, err := parseSince(sinceFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			return runExportPrompts(cmd, exportOptions{
+				format:  formatFlag,
+				since:   since,
+				privacy: privacyFlag,
+				output:  outputFlag,
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", exportFormatJSONL, "Output format (jsonl)")
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only export checkpoints created after this time (duration, date, or timestamp)")
+	cmd.Flags().StringVar(&privacyFlag, "privacy", exportPrivacyFull, "What to export: full, no-diffs, or prompts-only")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Directory to write the dataset and manifest to")
+
+	return cmd
+}
+
+func runExportPrompts(cmd *cobra.Command, opts exportOptions) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	records, stats, err := buildExportRecords(ctx, repo, repoRoot, opts)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if opts.output != "" {
+		if err := os.MkdirAll(opts.output, 0o750); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		f, err := os.Create(filepath.Join(opts.output, exportDataFileName))
+		if err != nil {
+			return fmt.Errorf("failed to create dataset file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+	}
+
+	if opts.output != "" {
+		manifest := newExportManifest(repo, repoRoot, opts, stats, records)
+		if err := writeExportManifest(filepath.Join(opts.output, exportManifestName), manifest); err != nil {
+			return err
+		}
+	}
+
+	writeExportStats(errW, stats)
+	return nil
+}
+
+// buildExportRecords reads every committed checkpoint created after
+// opts.since and returns its records, oldest first.
+func buildExportRecords(ctx context.Context, repo *git.Repository, repoRoot string, opts exportOptions) ([]exportRecord, exportStats, error) {
+	stats := exportStats{Agents: map[string]int{}}
+
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var commits map[id.CheckpointID]*object.Commit
+	if opts.privacy == exportPrivacyFull {
+		commits, err = checkpointCommits(repo)
+		if err != nil {
+			return nil, stats, err
+		}
+	}
+
+	var records []exportRecord
+	sessions := make(map[string]struct{})
+	for i := len(committed) - 1; i >= 0; i-- {
+		info := committed[i]
+		if !opts.since.IsZero() && info.CreatedAt.Before(opts.since) {
+			continue
+		}
+		checkpointExported := false
+		for idx := range max(info.SessionCount, 1) {
+			content, readErr := store.ReadSessionContent(ctx, info.CheckpointID, idx)
+			if readErr != nil || len(content.Transcript) == 0 {
+				stats.Skipped++
+				continue
+			}
+			rec := exportRecordFromContent(content, opts.privacy, &stats)
+			if len(rec.Turns) == 0 {
+				stats.Skipped++
+				continue
+			}
+			if commit, ok := commits[info.CheckpointID]; ok {
+				rec.Commit = commit.Hash.String()
+				diff, diffErr := exportCommitDiff(ctx, repoRoot, commit, rec.Files)
+				if diffErr != nil {
+					return nil, stats, diffErr
+				}
+				rec.Diff = redactExportField(diff, &stats)
+				if rec.Diff != "" {
+					stats.Diffs++
+				}
+			}
+
+			records = append(records, rec)
+			checkpointExported = true
+			sessions[rec.SessionID] = struct{}{}
+			stats.Records++
+			stats.Prompts += len(rec.Turns)
+			stats.Agents[rec.Agent]++
+		}
+		if checkpointExported {
+			stats.Checkpoints++
+		}
+	}
+	stats.Sessions = len(sessions)
+
+	return records, stats, nil
+}
+
+// exportRecordFromContent builds a record from a session's checkpoint content,
+// scoped to the part of the transcript the checkpoint added. Text fields are
+// redacted and filtered by privacy mode.
+func exportRecordFromContent(content *checkpoint.SessionContent, privacy string, stats *exportStats) exportRecord {
+	meta := content.Metadata
+	rec := exportRecord{
+		CheckpointID: meta.CheckpointID.String(),
+		SessionID:    meta.SessionID,
+		Agent:        string(meta.Agent),
+		CreatedAt:    meta.CreatedAt,
+		Tickets:      meta.Tickets,
+		Turns:        []exportTurn{},
+		Files:        withoutEntireMetadata(meta.FilesTouched),
+	}
+
+	scoped := scopeTranscriptForCheckpoint(content.Transcript, meta.CheckpointTranscriptStart, meta.Agent)
+	entries, err := summarize.BuildCondensedTranscriptFromBytes(scoped, meta.Agent)
+	if err != nil {
+		return rec
+	}
+
+	var responses []string
+	flush := func() {
+		if len(rec.Turns) == 0 {
+			return
+		}
+		if privacy != exportPrivacyPromptsOnly {
+			rec.Turns[len(rec.Turns)-1].Response = redactExportField(strings.Join(responses, "\n\n"), stats)
+		}
+		responses = nil
+	}
+	for _, entry := range entries {
+		switch entry.Type {
+		case summarize.EntryTypeUser:
+			if entry.Content == "" {
+				continue
+			}
+			flush()
+			rec.Turns = append(rec.Turns, exportTurn{Prompt: redactExportField(entry.Content, stats)})
+		case summarize.EntryTypeAssistant:
+			if entry.Content != "" {
+				responses = append(responses, entry.Content)
+			}
+		case summarize.EntryTypeTool:
+		}
+	}
+	flush()
+
+	return rec
+}
+
+// redactExportField redacts secrets in s, counting fields that changed.
+func redactExportField(s string, stats *exportStats) string {
+	redacted := redact.String(s)
+	if redacted != s {
+		stats.RedactedFields++
+	}
+	return redacted
+}
+
+// checkpointCommits maps each checkpoint ID to the newest commit on a local
+// branch whose trailers reference it. Entire's own branches are skipped.
+func checkpointCommits(repo *git.Repository) (map[id.CheckpointID]*object.Commit, error) {
+	commits := make(map[id.CheckpointID]*object.Commit)
+	err := forEachLocalBranchCommit(repo, func(c *object.Commit) {
+		for _, cpID := range trailers.ParseAllCheckpoints(c.Message) {
+			if existing, ok := commits[cpID]; !ok || c.Committer.When.After(existing.Committer.When) {
+				commits[cpID] = c
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// exportCommitDiff returns the commit's diff restricted to files.
+func exportCommitDiff(ctx context.Context, repoRoot string, commit *object.Commit, files []string) (string, error) {
+	if len(files) == 0 {
+		return "", nil
+	}
+	args := append([]string{"show", "--format=", "--no-color", "--no-ext-diff", "--first-parent", commit.Hash.String(), "--"}, files...)
+	out, err := gitOutput(ctx, repoRoot, nil, args...)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func newExportManifest(repo *git.Repository, repoRoot string, opts exportOptions, stats exportStats, records []exportRecord) exportManifest {
+	manifest := exportManifest{
+		Dataset:        "prompts",
+		Format:         opts.format,
+		GeneratedAt:    time.Now().UTC(),
+		CLIVersion:     buildinfo.Version,
+		Repository:     filepath.Base(repoRoot),
+		MetadataBranch: paths.MetadataBranchName(),
+		Privacy:        opts.privacy,
+		Redaction:      exportRedactionNotes,
+		Stats:          stats,
+		CheckpointIDs:  []string{},
+	}
+	if !opts.since.IsZero() {
+		since := opts.since
+		manifest.Since = &since
+	}
+	if ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true); err == nil {
+		manifest.MetadataCommit = ref.Hash().String()
+	}
+	seen := make(map[string]struct{})
+	for _, rec := range records {
+		if _, ok := seen[rec.CheckpointID]; !ok {
+			seen[rec.CheckpointID] = struct{}{}
+			manifest.CheckpointIDs = append(manifest.CheckpointIDs, rec.CheckpointID)
+		}
+	}
+	return manifest
+}
+
+func writeExportManifest(path string, manifest exportManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+func writeExportStats(w io.Writer, stats exportStats) {
+	fmt.Fprintf(w, "Exported %d record(s) from %d checkpoint(s) across %d session(s).\n",
+		stats.Records, stats.Checkpoints, stats.Sessions)
+	fmt.Fprintf(w, "  Prompts:         %d\n", stats.Prompts)
+	fmt.Fprintf(w, "  Diffs:           %d\n", stats.Diffs)
+	fmt.Fprintf(w, "  Redacted fields: %d\n", stats.RedactedFields)
+	if stats.Skipped > 0 {
+		fmt.Fprintf(w, "  Skipped:         %d (no transcript or prompts)\n", stats.Skipped)
+	}
+	agents := make([]string, 0, len(stats.Agents))
+	for name := range stats.Agents {
+		agents = append(agents, name)
+	}
+	sort.Strings(agents)
+	for _, name := range agents {
+		label := name
+		if label == "" {
+			label = "unknown"
+		}
+		fmt.Fprintf(w, "  Agent %s: %d\n", label, stats.Agents[name])
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exportTestSecret = "sk-ant-REDACTED"
+
+// setupExportRepo creates a commit referencing a checkpoint whose transcript
+// has two prompts. The committed file contains a secret.
+func setupExportRepo(t *testing.T) (commit string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "README.md", "# test\n")
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "Initial commit")
+
+	testutil.WriteFile(t, tmpDir, "config.go", "package config\n\nconst key = \""+exportTestSecret+"\"\n")
+	testutil.GitAdd(t, tmpDir, "config.go")
+	testutil.GitCommit(t, tmpDir, "Add config\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	commit = testutil.GetHeadHash(t, tmpDir)
+
+	transcript := strings.Join([]string{
+		`{"type":"user","uuid":"u1","message":{"role":"user","content":"Add a config file"}}`,
+		`{"type":"assistant","uuid":"a1","message":{"role":"assistant","content":[{"type":"text","text":"Created config.go."}]}}`,
+		`{"type":"user","uuid":"u2","message":{"role":"user","content":"Thanks"}}`,
+		`{"type":"assistant","uuid":"a2","message":{"role":"assistant","content":[{"type":"text","text":"You're welcome."}]}}`,
+	}, "\n") + "\n"
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-04-01-alpha",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(transcript),
+		FilesTouched: []string{"config.go", ".entire/settings.json"},
+	}))
+	return commit
+}
+
+func runExportForTest(t *testing.T, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	cmd := newExportCmd()
+	var out, errOut bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs(append([]string{"prompts"}, args...))
+	err = cmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestExportPrompts_Full(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	commit := setupExportRepo(t)
+
+	stdout, stderr, err := runExportForTest(t)
+	require.NoError(t, err)
+
+	var rec exportRecord
+	require.NoError(t, json.Unmarshal([]byte(stdout), &rec))
+	assert.Equal(t, "a1b2c3d4e5f6", rec.CheckpointID)
+	assert.Equal(t, "2026-04-01-alpha", rec.SessionID)
+	assert.Equal(t, commit, rec.Commit)
+	assert.Equal(t, []string{"config.go"}, rec.Files)
+	assert.Equal(t, []exportTurn{
+		{Prompt: "Add a config file", Response: "Created config.go."},
+		{Prompt: "Thanks", Response: "You're welcome."},
+	}, rec.Turns)
+	assert.Contains(t, rec.Diff, "+++ b/config.go")
+	assert.NotContains(t, rec.Diff, exportTestSecret)
+
+	assert.Contains(t, stderr, "Exported 1 record(s) from 1 checkpoint(s) across 1 session(s).")
+	assert.Contains(t, stderr, "Redacted fields: 1")
+}
+
+func TestExportPrompts_PrivacyModes(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupExportRepo(t)
+
+	stdout, _, err := runExportForTest(t, "--privacy", "no-diffs")
+	require.NoError(t, err)
+	var rec exportRecord
+	require.NoError(t, json.Unmarshal([]byte(stdout), &rec))
+	assert.Empty(t, rec.Diff)
+	assert.Empty(t, rec.Commit)
+	assert.Equal(t, "Created config.go.", rec.Turns[0].Response)
+
+	stdout, _, err = runExportForTest(t, "--privacy", "prompts-only")
+	require.NoError(t, err)
+	rec = exportRecord{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &rec))
+	assert.Empty(t, rec.Diff)
+	assert.Equal(t, []exportTurn{{Prompt: "Add a config file"}, {Prompt: "Thanks"}}, rec.Turns)
+
+	_, _, err = runExportForTest(t, "--privacy", "everything")
+	require.Error(t, err)
+	_, _, err = runExportForTest(t, "--format", "csv")
+	require.Error(t, err)
+}
+
+func TestExportPrompts_Since(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupExportRepo(t)
+
+	stdout, stderr, err := runExportForTest(t, "--since", "2999-01-01")
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "Exported 0 record(s)")
+}
+
+func TestExportPrompts_OutputManifest(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupExportRepo(t)
+	outDir := filepath.Join(t.TempDir(), "dataset")
+
+	stdout, _, err := runExportForTest(t, "--output", outDir)
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+
+	data, err := os.ReadFile(filepath.Join(outDir, exportDataFileName))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "\n"))
+
+	manifestData, err := os.ReadFile(filepath.Join(outDir, exportManifestName))
+	require.NoError(t, err)
+	var manifest exportManifest
+	require.NoError(t, json.Unmarshal(manifestData, &manifest))
+	assert.Equal(t, "prompts", manifest.Dataset)
+	assert.Equal(t, exportFormatJSONL, manifest.Format)
+	assert.Equal(t, exportPrivacyFull, manifest.Privacy)
+	assert.Equal(t, paths.MetadataBranchName(), manifest.MetadataBranch)
+	assert.NotEmpty(t, manifest.MetadataCommit)
+	assert.Nil(t, manifest.Since)
+	assert.Equal(t, []string{"a1b2c3d4e5f6"}, manifest.CheckpointIDs)
+	assert.Equal(t, 2, manifest.Stats.Prompts)
+	assert.Equal(t, map[string]int{string(agent.AgentTypeClaudeCode): 1}, manifest.Stats.Agents)
+}
@@ -0,0 +1,114 @@
+// Package faultinject makes internal operations fail on purpose, so we and
+// integrators can check that hooks never break git operations and that
+// session state stays recoverable after partial failures.
+//
+// It is off unless ENTIRE_FAULT_INJECT is set to a comma-separated list of
+// operations, each optionally followed by "=<probability>" (default 1):
+//
+//	ENTIRE_FAULT_INJECT=state-save=0.5,ref-update
+//	ENTIRE_FAULT_INJECT=all=0.1
+//
+// Set ENTIRE_FAULT_INJECT_SEED to an integer to make a run reproducible.
+// This is a testing facility and is intentionally not shown in help output.
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EnvVar selects the operations to fail and how often.
+const EnvVar = "ENTIRE_FAULT_INJECT"
+
+// SeedEnvVar seeds the random source that decides which calls fail.
+const SeedEnvVar = "ENTIRE_FAULT_INJECT_SEED"
+
+// Op names an operation that can be made to fail.
+type Op string
+
+const (
+	// StateSave fails writing session state to .git/entire-sessions/.
+	StateSave Op = "state-save"
+	// RefUpdate fails updating a git ref (shadow and metadata branches).
+	RefUpdate Op = "ref-update"
+	// TranscriptRead fails reading an agent transcript.
+	TranscriptRead Op = "transcript-read"
+
+	// all matches every operation in ENTIRE_FAULT_INJECT.
+	all = "all"
+)
+
+// ErrInjected is wrapped by every error returned by Check.
+var ErrInjected = errors.New("injected fault")
+
+var (
+	mu      sync.Mutex
+	rng     *rand.Rand
+	rngSeed string
+)
+
+// Check returns an error wrapping ErrInjected if op should fail this time,
+// and nil otherwise. It is a no-op unless ENTIRE_FAULT_INJECT is set.
+func Check(op Op) error {
+	spec := os.Getenv(EnvVar)
+	if spec == "" {
+		return nil
+	}
+	probabilities := parseSpec(spec)
+	probability, ok := probabilities[string(op)]
+	if !ok {
+		probability, ok = probabilities[all]
+	}
+	if !ok || probability <= 0 {
+		return nil
+	}
+	if probability < 1 && randomFloat() >= probability {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInjected, op)
+}
+
+// parseSpec parses ENTIRE_FAULT_INJECT into probabilities keyed by operation.
+// Malformed probabilities disable that entry rather than failing everything.
+func parseSpec(spec string) map[string]float64 {
+	probabilities := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		name, value, hasValue := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		probability := 1.0
+		if hasValue {
+			p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			probability = p
+		}
+		probabilities[name] = probability
+	}
+	return probabilities
+}
+
+// randomFloat returns a number in [0, 1), from a source seeded by
+// ENTIRE_FAULT_INJECT_SEED when it is set.
+func randomFloat() float64 {
+	mu.Lock()
+	defer mu.Unlock()
+	seed := os.Getenv(SeedEnvVar)
+	if rng == nil || seed != rngSeed {
+		rngSeed = seed
+		if n, err := strconv.ParseUint(seed, 10, 64); err == nil {
+			rng = rand.New(rand.NewPCG(n, n)) //nolint:gosec // fault injection needs reproducibility, not unpredictability
+		} else {
+			rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())) //nolint:gosec // see above
+		}
+	}
+	return rng.Float64()
+}
@@ -0,0 +1,59 @@
+package faultinject
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck_Disabled(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(EnvVar, "")
+	require.NoError(t, Check(StateSave))
+}
+
+func TestCheck_Always(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(EnvVar, "state-save, ref-update=1")
+
+	err := Check(StateSave)
+	require.ErrorIs(t, err, ErrInjected)
+	assert.Contains(t, err.Error(), "state-save")
+	require.ErrorIs(t, Check(RefUpdate), ErrInjected)
+	require.NoError(t, Check(TranscriptRead), "operations not listed should not fail")
+}
+
+func TestCheck_All(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(EnvVar, "all,ref-update=0")
+
+	require.ErrorIs(t, Check(TranscriptRead), ErrInjected)
+	require.NoError(t, Check(RefUpdate), "a specific entry overrides all")
+}
+
+func TestCheck_MalformedProbabilityIgnored(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(EnvVar, "state-save=often")
+	require.NoError(t, Check(StateSave))
+}
+
+func TestCheck_SeedIsReproducible(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(EnvVar, "state-save=0.5")
+
+	run := func(seed string) []bool {
+		t.Setenv(SeedEnvVar, seed)
+		rng = nil
+		var failed []bool
+		for range 32 {
+			failed = append(failed, Check(StateSave) != nil)
+		}
+		return failed
+	}
+
+	first := run("42")
+	assert.Equal(t, first, run("42"))
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
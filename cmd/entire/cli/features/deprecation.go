@@ -0,0 +1,94 @@
+package features
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// sunsetLayout is the format of sunset dates.
+const sunsetLayout = "2006-01-02"
+
+// Deprecation is a behavior or setting that is still honored but will be
+// removed. The CLI reports each active deprecation at most once a day.
+type Deprecation struct {
+	// ID identifies the deprecation, e.g. "setting.strategy".
+	ID string
+	// Message says what is deprecated and what to do instead.
+	Message string
+	// Sunset is the date ("YYYY-MM-DD") after which support is removed.
+	// Empty means no date has been set.
+	Sunset string
+}
+
+// Warning returns the user-facing warning, including the sunset date.
+func (d Deprecation) Warning(now time.Time) string {
+	if d.Sunset == "" {
+		return "Deprecated: " + d.Message
+	}
+	if sunset, err := time.Parse(sunsetLayout, d.Sunset); err == nil && !now.Before(sunset) {
+		return fmt.Sprintf("Deprecated: %s Support ended on %s and may be removed in any release.", d.Message, d.Sunset)
+	}
+	return fmt.Sprintf("Deprecated: %s Support ends on %s.", d.Message, d.Sunset)
+}
+
+// settingDeprecations are settings fields that are ignored or replaced.
+var settingDeprecations = []struct {
+	Deprecation
+	inUse func(*settings.EntireSettings) bool
+}{
+	{
+		Deprecation: Deprecation{
+			ID:      "setting.strategy",
+			Message: `"strategy" in .entire/settings.json is ignored unless it is "patch-file"; 'manual-commit' is the default. Remove the field.`,
+			Sunset:  "2027-01-01",
+		},
+		inUse: func(s *settings.EntireSettings) bool { return s.HasDeprecatedStrategy() },
+	},
+}
+
+// Active returns the deprecations that apply to the current repository:
+// deprecated settings that are set, deprecated flags that are enabled, and
+// flags in settings that this version does not know about.
+func Active() []Deprecation {
+	s, err := settings.Load()
+	if err != nil {
+		return nil
+	}
+	return activeFor(s)
+}
+
+func activeFor(s *settings.EntireSettings) []Deprecation {
+	var active []Deprecation
+	for _, d := range settingDeprecations {
+		if d.inUse(s) {
+			active = append(active, d.Deprecation)
+		}
+	}
+	for _, f := range registry {
+		if f.Stage == Deprecated && f.state(s.Features) {
+			active = append(active, Deprecation{
+				ID:      "feature." + f.Name,
+				Message: fmt.Sprintf("feature %q is deprecated: %s.", f.Name, f.Description),
+				Sunset:  f.Sunset,
+			})
+		}
+	}
+
+	var unknown []string
+	for name := range s.Features {
+		if _, ok := Lookup(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		active = append(active, Deprecation{
+			ID:      "feature." + name,
+			Message: fmt.Sprintf("feature %q is not known to this version and has no effect. Remove it from \"features\" in settings.", name),
+		})
+	}
+	return active
+}
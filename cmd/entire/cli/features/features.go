@@ -0,0 +1,135 @@
+// Package features gates experimental behavior behind flags and tracks
+// deprecated behavior with sunset dates, so large changes to trailers,
+// refs, and metadata formats can ship and be retired predictably.
+//
+// Flags are toggled in the "features" section of .entire/settings.json (or
+// settings.local.json):
+//
+//	{"features": {"v2_trailers": true}}
+package features
+
+import (
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// Stage describes how far along a flagged behavior is.
+type Stage string
+
+const (
+	// Experimental behavior is off by default and may change or be removed.
+	Experimental Stage = "experimental"
+	// Stable behavior is on by default; the flag exists so it can be turned off.
+	Stable Stage = "stable"
+	// Deprecated behavior will be removed after the flag's sunset date.
+	Deprecated Stage = "deprecated"
+)
+
+// Flag names. Use these constants with Enabled rather than string literals.
+const (
+	// ContentDefinedChunks stores committed JSONL transcripts as
+	// content-defined chunks, so checkpoints of a growing session share blobs.
+	ContentDefinedChunks = "content_defined_chunks"
+	// HookMetrics records every hook run for the /metrics endpoint of
+	// `entire serve`.
+	HookMetrics = "hook_metrics"
+	// V2Trailers writes the v2 commit trailer format.
+	V2Trailers = "v2_trailers"
+	// RefNamespaceV2 moves shadow and metadata branches out of refs/heads/.
+	RefNamespaceV2 = "ref_namespace_v2"
+)
+
+// Flag describes a feature flag.
+type Flag struct {
+	Name        string
+	Description string
+	Stage       Stage
+	// Default is the state when settings do not mention the flag.
+	Default bool
+	// Sunset is the date ("YYYY-MM-DD") after which a deprecated flag is removed.
+	Sunset string
+}
+
+// registry lists every known flag, sorted by name.
+var registry = []Flag{
+	{
+		Name:        ContentDefinedChunks,
+		Description: "Split committed transcripts over 32KB into content-defined chunks that later checkpoints reuse",
+		Stage:       Experimental,
+	},
+	{
+		Name:        HookMetrics,
+		Description: "Record hook durations and failures for the /metrics endpoint of entire serve",
+		Stage:       Experimental,
+	},
+	{
+		Name:        RefNamespaceV2,
+		Description: "Store shadow and metadata branches under refs/entire/ instead of refs/heads/",
+		Stage:       Experimental,
+	},
+	{
+		Name:        V2Trailers,
+		Description: "Write the v2 Entire-* commit trailer format",
+		Stage:       Experimental,
+	},
+}
+
+// All returns every known flag, sorted by name.
+func All() []Flag {
+	return slices.Clone(registry)
+}
+
+// Lookup returns the flag called name.
+func Lookup(name string) (Flag, bool) {
+	for _, f := range registry {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flag{}, false
+}
+
+// Enabled reports whether the named flag is on: the settings value if
+// present, otherwise the flag's default. Unknown flags are always off.
+func Enabled(name string) bool {
+	f, ok := Lookup(name)
+	if !ok {
+		return false
+	}
+	s, err := settings.Load()
+	if err != nil {
+		return f.Default
+	}
+	return f.state(s.Features)
+}
+
+// state returns the flag's value given the "features" settings.
+func (f Flag) state(configured map[string]bool) bool {
+	if v, ok := configured[f.Name]; ok {
+		return v
+	}
+	return f.Default
+}
+
+// State is a flag with its current value, as shown by `entire features`.
+type State struct {
+	Flag
+	Enabled bool
+	// Configured is true when settings set the flag explicitly.
+	Configured bool
+}
+
+// States returns every known flag with its current value.
+func States() []State {
+	var configured map[string]bool
+	if s, err := settings.Load(); err == nil {
+		configured = s.Features
+	}
+	states := make([]State, 0, len(registry))
+	for _, f := range registry {
+		_, set := configured[f.Name]
+		states = append(states, State{Flag: f, Enabled: f.state(configured), Configured: set})
+	}
+	return states
+}
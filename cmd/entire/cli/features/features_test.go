@@ -0,0 +1,69 @@
+package features
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_SortedAndValid(t *testing.T) {
+	t.Parallel()
+
+	names := make([]string, 0, len(registry))
+	for _, f := range registry {
+		names = append(names, f.Name)
+		assert.NotEmpty(t, f.Description, "flag %s needs a description", f.Name)
+		assert.Contains(t, []Stage{Experimental, Stable, Deprecated}, f.Stage, "flag %s", f.Name)
+		if f.Stage == Deprecated {
+			_, err := time.Parse(sunsetLayout, f.Sunset)
+			assert.NoError(t, err, "deprecated flag %s needs a YYYY-MM-DD sunset", f.Name)
+		}
+	}
+	assert.True(t, slices.IsSorted(names), "registry should be sorted by name: %v", names)
+}
+
+func TestFlagState(t *testing.T) {
+	t.Parallel()
+
+	off := Flag{Name: "off_by_default"}
+	on := Flag{Name: "on_by_default", Default: true}
+
+	assert.False(t, off.state(nil))
+	assert.True(t, on.state(nil))
+	assert.True(t, off.state(map[string]bool{"off_by_default": true}))
+	assert.False(t, on.state(map[string]bool{"on_by_default": false}))
+	assert.False(t, off.state(map[string]bool{"something_else": true}))
+}
+
+func TestActiveFor(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, activeFor(&settings.EntireSettings{}))
+
+	active := activeFor(&settings.EntireSettings{
+		Strategy: "auto-commit",
+		Features: map[string]bool{V2Trailers: true, "zz_unknown": true, "aa_unknown": false},
+	})
+	ids := make([]string, 0, len(active))
+	for _, d := range active {
+		ids = append(ids, d.ID)
+	}
+	assert.Equal(t, []string{"setting.strategy", "feature.aa_unknown", "feature.zz_unknown"}, ids)
+}
+
+func TestDeprecationWarning(t *testing.T) {
+	t.Parallel()
+
+	d := Deprecation{ID: "x", Message: "Old thing is going away.", Sunset: "2027-01-01"}
+	before := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "Deprecated: Old thing is going away. Support ends on 2027-01-01.", d.Warning(before))
+	assert.True(t, strings.HasPrefix(d.Warning(after), "Deprecated: Old thing is going away. Support ended on 2027-01-01"))
+	assert.Equal(t, "Deprecated: No date.", Deprecation{Message: "No date."}.Warning(before))
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/features"
+	"github.com/entireio/cli/cmd/entire/cli/kvstore"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/spf13/cobra"
+)
+
+func newFeaturesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "features",
+		Short: "List feature flags and deprecations",
+		Long: `List every feature flag, its stage, and whether it is enabled here, followed
+by any deprecated settings or flags in use.
+
+Toggle flags in the "features" section of .entire/settings.json or
+.entire/settings.local.json:
+
+  {"features": {"v2_trailers": true}}
+
+Experimental flags are off by default and may change or be removed.
+Deprecated flags and settings keep working until their sunset date.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			writeFeatures(cmd.OutOrStdout(), features.States(), features.Active(), time.Now())
+			return nil
+		},
+	}
+}
+
+// writeFeatures prints the flag table and active deprecations.
+func writeFeatures(w io.Writer, states []features.State, deprecations []features.Deprecation, now time.Time) {
+	nameWidth := 0
+	for _, st := range states {
+		nameWidth = max(nameWidth, len(st.Name))
+	}
+
+	fmt.Fprintln(w, "Feature flags:")
+	for _, st := range states {
+		state := "off"
+		if st.Enabled {
+			state = "on"
+		}
+		source := "default"
+		if st.Configured {
+			source = "settings"
+		}
+		stage := string(st.Stage)
+		if st.Stage == features.Deprecated && st.Sunset != "" {
+			stage += ", sunset " + st.Sunset
+		}
+		fmt.Fprintf(w, "  %-*s  %-3s  (%s, %s)\n", nameWidth, st.Name, state, stage, source)
+		fmt.Fprintf(w, "  %-*s  %s\n", nameWidth, "", st.Description)
+	}
+
+	if len(deprecations) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Deprecations in use:")
+		for _, d := range deprecations {
+			fmt.Fprintf(w, "  %s\n", d.Warning(now))
+		}
+	}
+}
+
+// deprecationWarningBucket records which deprecations were reported in the
+// last day. The hook state store's TTL expires entries after 24 hours, so
+// each warning is shown again at most once a day.
+func deprecationWarningBucket() kvstore.Bucket[time.Time] {
+	return kvstore.NewBucket[time.Time](hookStateStore(), "deprecation-warnings")
+}
+
+// warnDeprecationsOncePerDay writes a warning for each active deprecation
+// that has not been reported in the last day.
+func warnDeprecationsOncePerDay(w io.Writer, bucket kvstore.Bucket[time.Time], deprecations []features.Deprecation, now time.Time) {
+	logCtx := logging.WithComponent(context.Background(), "features")
+	for _, d := range deprecations {
+		// Get, not Update: rewriting the entry would reset its TTL and
+		// silence the warning for as long as commands keep running daily.
+		key := deprecationKey(d.ID)
+		if last, err := bucket.Get(key); err == nil && last != nil {
+			continue
+		}
+		if err := bucket.Put(key, now); err != nil {
+			logging.Debug(logCtx, "failed to record deprecation warning",
+				slog.String("deprecation", d.ID),
+				slog.String("error", err.Error()),
+			)
+		}
+		fmt.Fprintln(w, d.Warning(now))
+	}
+}
+
+// deprecationKey turns a deprecation ID into a file-safe store key. IDs for
+// unknown flags include names from settings, so anything but letters,
+// digits, '.', '-' and '_' is replaced.
+func deprecationKey(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, id)
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/features"
+	"github.com/entireio/cli/cmd/entire/cli/kvstore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFeatures(t *testing.T) {
+	t.Parallel()
+
+	states := []features.State{
+		{Flag: features.Flag{Name: "beta", Description: "Try the beta", Stage: features.Experimental}},
+		{Flag: features.Flag{Name: "old_way", Description: "Keep the old way", Stage: features.Deprecated, Sunset: "2027-01-01"}, Enabled: true, Configured: true},
+	}
+	deprecations := []features.Deprecation{{ID: "feature.old_way", Message: "Stop using old_way.", Sunset: "2027-01-01"}}
+
+	var buf bytes.Buffer
+	writeFeatures(&buf, states, deprecations, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	out := buf.String()
+
+	assert.Contains(t, out, "beta     off  (experimental, default)")
+	assert.Contains(t, out, "old_way  on   (deprecated, sunset 2027-01-01, settings)")
+	assert.Contains(t, out, "Try the beta")
+	assert.Contains(t, out, "Deprecations in use:\n  Deprecated: Stop using old_way. Support ends on 2027-01-01.")
+}
+
+func TestWarnDeprecationsOncePerDay(t *testing.T) {
+	t.Parallel()
+
+	bucket := kvstore.NewBucket[time.Time](kvstore.Open(t.TempDir(), kvstore.Options{TTL: time.Hour}), "deprecation-warnings")
+	deprecations := []features.Deprecation{
+		{ID: "setting.strategy", Message: "Remove strategy."},
+		{ID: "feature.../escape", Message: "Unknown flag."},
+	}
+	now := time.Now()
+
+	var first bytes.Buffer
+	warnDeprecationsOncePerDay(&first, bucket, deprecations, now)
+	assert.Equal(t, 2, strings.Count(first.String(), "Deprecated:"))
+
+	var second bytes.Buffer
+	warnDeprecationsOncePerDay(&second, bucket, deprecations, now)
+	assert.Empty(t, second.String(), "warnings should not repeat within the TTL")
+}
+
+func TestDeprecationKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "setting.strategy", deprecationKey("setting.strategy"))
+	assert.Equal(t, "feature..._escape", deprecationKey("feature.../escape"))
+	assert.Equal(t, "feature.a_b_c", deprecationKey(`feature.a\b:c`))
+}
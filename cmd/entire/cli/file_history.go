@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// fileHistoryEntry is one change to a file: a commit, or an uncommitted step
+// on a session's shadow branch.
+type fileHistoryEntry struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+	// Change is "added", "modified" or "deleted".
+	Change string
+	// SessionID is set for uncommitted steps.
+	SessionID string
+	// Checkpoints are the checkpoints linked to a commit by its trailers.
+	Checkpoints []bisectCheckpoint
+}
+
+// Uncommitted reports whether the entry is a shadow branch step.
+func (e fileHistoryEntry) Uncommitted() bool {
+	return e.SessionID != ""
+}
+
+func newFileHistoryCmd() *cobra.Command {
+	var atFlag string
+	var limitFlag int
+
+	cmd := &cobra.Command{
+		Use:   "file-history <path>",
+		Short: "Show every commit and session step that changed a file",
+		Long: `List the changes to a file, newest first: the uncommitted steps agent
+sessions have recorded on their shadow branches, then the commits on the
+current branch with the checkpoints, agents, and prompts behind them.
+
+Pass a step or commit hash from the list with --at to print the file as it
+was after that change.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFileHistory(cmd, args[0], atFlag, limitFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&atFlag, "at", "", "Print the file as of this step or commit")
+	cmd.Flags().IntVar(&limitFlag, "limit", 20, "Maximum number of commits to list")
+
+	return cmd
+}
+
+func runFileHistory(cmd *cobra.Command, pathArg, at string, limit int) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	absPath, err := filepath.Abs(pathArg)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	relPath := paths.RepoRelative(repoRoot, absPath)
+	if relPath == "" || relPath == "." {
+		cmd.SilenceUsage = true
+		err = fmt.Errorf("%s is not a file in this repository", pathArg)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	if at != "" {
+		return writeFileAt(ctx, cmd.OutOrStdout(), repoRoot, relPath, at)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	steps := uncommittedFileSteps(ctx, repo, repoRoot, relPath)
+	commits, err := committedFileChanges(ctx, repoRoot, relPath, limit)
+	if err != nil {
+		return err
+	}
+	store := checkpoint.NewGitStore(repo)
+	for i := range commits {
+		commits[i].Checkpoints = bisectCommitCheckpoints(ctx, repo, store, commits[i].Hash)
+	}
+
+	writeFileHistory(cmd.OutOrStdout(), relPath, append(steps, commits...))
+	return nil
+}
+
+// writeFileAt prints the file's content as of a commit or shadow step.
+func writeFileAt(ctx context.Context, w io.Writer, repoRoot, relPath, rev string) error {
+	show := exec.CommandContext(ctx, "git", "show", rev+":"+relPath)
+	show.Dir = repoRoot
+	output, err := show.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("%s does not exist at %s: %s", relPath, rev, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("git show failed: %w", err)
+	}
+	if _, err := w.Write(output); err != nil {
+		return fmt.Errorf("failed to write file content: %w", err)
+	}
+	return nil
+}
+
+// committedFileChanges returns the commits on HEAD that changed the file,
+// newest first.
+func committedFileChanges(ctx context.Context, repoRoot, relPath string, limit int) ([]fileHistoryEntry, error) {
+	args := []string{"log", "--format=%x1e%H%x1f%aI%x1f%s", "--name-status", "--no-renames"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+	args = append(args, "--", relPath)
+
+	logCmd := exec.CommandContext(ctx, "git", args...)
+	logCmd.Dir = repoRoot
+	output, err := logCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return parseFileLog(string(output)), nil
+}
+
+// parseFileLog parses `git log --name-status` output in the format written by
+// committedFileChanges.
+func parseFileLog(output string) []fileHistoryEntry {
+	var entries []fileHistoryEntry
+	for _, record := range strings.Split(output, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.SplitN(lines[0], "\x1f", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		entry := fileHistoryEntry{Hash: fields[0], Subject: fields[2], Change: "modified"}
+		if t, err := time.Parse(time.RFC3339, fields[1]); err == nil {
+			entry.Time = t
+		}
+		for _, line := range lines[1:] {
+			switch {
+			case strings.HasPrefix(line, "A\t"):
+				entry.Change = "added"
+			case strings.HasPrefix(line, "D\t"):
+				entry.Change = "deleted"
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// uncommittedFileSteps returns the shadow branch steps of this worktree's
+// sessions that changed the file, newest first. Each step is compared with
+// the commit before it on the shadow branch.
+func uncommittedFileSteps(ctx context.Context, repo *git.Repository, repoRoot, relPath string) []fileHistoryEntry {
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil
+	}
+	states, err := store.List(ctx)
+	if err != nil {
+		return nil
+	}
+
+	gitStore := checkpoint.NewGitStore(repo)
+	seenBranches := make(map[string]bool)
+	var entries []fileHistoryEntry
+	for _, st := range states {
+		if st.StepCount == 0 || st.BaseCommit == "" {
+			continue
+		}
+		if st.WorktreePath != "" && paths.RepoRelative(repoRoot, st.WorktreePath) != "." {
+			continue
+		}
+		branch := checkpoint.ShadowBranchNameForCommit(st.BaseCommit, st.WorktreeID)
+		if seenBranches[branch] {
+			continue
+		}
+		seenBranches[branch] = true
+
+		// All sessions sharing the shadow branch are listed at once.
+		infos, listErr := gitStore.ListCheckpointsForBranch(ctx, branch, "", 100)
+		if listErr != nil {
+			continue
+		}
+		for _, info := range infos {
+			change := shadowStepChange(repo, info.CommitHash, relPath)
+			if change == "" {
+				continue
+			}
+			entries = append(entries, fileHistoryEntry{
+				Hash:      info.CommitHash.String(),
+				Time:      info.Timestamp,
+				Subject:   info.Message,
+				Change:    change,
+				SessionID: info.SessionID,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries
+}
+
+// shadowStepChange reports how a shadow commit changed the file compared with
+// its parent: "added", "modified", "deleted", or "" if it did not.
+func shadowStepChange(repo *git.Repository, hash plumbing.Hash, relPath string) string {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return ""
+	}
+	after, inAfter := fileBlobHash(commit, relPath)
+
+	var before plumbing.Hash
+	var inBefore bool
+	if commit.NumParents() > 0 {
+		if parent, parentErr := commit.Parent(0); parentErr == nil {
+			before, inBefore = fileBlobHash(parent, relPath)
+		}
+	}
+
+	switch {
+	case inAfter && !inBefore:
+		return "added"
+	case !inAfter && inBefore:
+		return "deleted"
+	case inAfter && after != before:
+		return "modified"
+	}
+	return ""
+}
+
+// fileBlobHash returns the blob hash of the file in the commit's tree.
+func fileBlobHash(commit *object.Commit, relPath string) (plumbing.Hash, bool) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, false
+	}
+	entry, err := tree.FindEntry(relPath)
+	if err != nil {
+		return plumbing.ZeroHash, false
+	}
+	return entry.Hash, true
+}
+
+func writeFileHistory(w io.Writer, relPath string, entries []fileHistoryEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintf(w, "No commits or session steps have changed %s.\n", relPath)
+		return
+	}
+
+	fmt.Fprintf(w, "History of %s\n\n", relPath)
+	for _, e := range entries {
+		kind := "commit"
+		if e.Uncommitted() {
+			kind = "step"
+		}
+		fmt.Fprintf(w, "%-6s %s  %s  %-8s  %s\n",
+			kind, strategy.TruncateHash(e.Hash), e.Time.Local().Format("2006-01-02 15:04"), e.Change, sanitizeForTerminal(e.Subject))
+		if e.Uncommitted() {
+			fmt.Fprintf(w, "       uncommitted, session %s\n", e.SessionID)
+			continue
+		}
+		for _, cp := range e.Checkpoints {
+			fmt.Fprintf(w, "       %s\n", formatBisectCheckpointLine(cp))
+		}
+	}
+	fmt.Fprintf(w, "\nShow the file at a change: entire file-history %s --at <hash>\n", relPath)
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runFileHistoryForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newFileHistoryCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestFileHistory(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.WriteFile(t, tmpDir, "other.go", "package other\n")
+	testutil.GitAdd(t, tmpDir, "main.go", "other.go")
+	testutil.GitCommit(t, tmpDir, "Initial commit")
+
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "Add main\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	baseCommit := testutil.GetHeadHash(t, tmpDir)
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-05-01-alpha",
+		Strategy:     "manual-commit",
+		Agent:        "Claude Code",
+		Prompts:      []string{"Write a main function"},
+		FilesTouched: []string{"main.go"},
+	}))
+
+	// Two uncommitted steps; only the first changes main.go.
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(baseCommit, "")
+	testutil.GitCheckoutNewBranch(t, tmpDir, shadowBranch)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() { run() }\n")
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "Call run\n\nEntire-Session: 2026-05-02-beta\n")
+	step := testutil.GetHeadHash(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "other.go", "package other\n\n// changed\n")
+	testutil.GitAdd(t, tmpDir, "other.go")
+	testutil.GitCommit(t, tmpDir, "Touch other\n\nEntire-Session: 2026-05-02-beta\n")
+
+	checkout := exec.CommandContext(context.Background(), "git", "checkout", "-q", baseCommit)
+	checkout.Dir = tmpDir
+	out, err := checkout.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Save(context.Background(), &session.State{
+		SessionID:  "2026-05-02-beta",
+		BaseCommit: baseCommit,
+		StartedAt:  time.Now(),
+		StepCount:  2,
+	}))
+
+	output, err := runFileHistoryForTest(t, "main.go")
+	require.NoError(t, err)
+	assert.Contains(t, output, "History of main.go")
+	assert.Contains(t, output, "step   "+step[:7])
+	assert.Contains(t, output, "uncommitted, session 2026-05-02-beta")
+	assert.NotContains(t, output, "Touch other", "steps that don't change the file are left out")
+	assert.Contains(t, output, "commit "+baseCommit[:7])
+	assert.Contains(t, output, `a1b2c3d4e5f6  Claude Code  "Write a main function"`)
+	assert.Contains(t, output, "added     Initial commit")
+	assert.Less(t, bytes.Index([]byte(output), []byte("Call run")), bytes.Index([]byte(output), []byte("Add main")),
+		"uncommitted steps should come first")
+
+	content, err := runFileHistoryForTest(t, "main.go", "--at", step[:7])
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() { run() }\n", content)
+
+	_, err = runFileHistoryForTest(t, "../outside.go")
+	require.Error(t, err)
+}
+
+func TestParseFileLog(t *testing.T) {
+	t.Parallel()
+
+	output := "\x1eaaa\x1f2026-05-01T10:00:00Z\x1fDelete it\n\nD\tf.go\n" +
+		"\x1ebbb\x1f2026-04-01T10:00:00Z\x1fCreate it\n\nA\tf.go\n"
+	entries := parseFileLog(output)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "aaa", entries[0].Hash)
+	assert.Equal(t, "deleted", entries[0].Change)
+	assert.Equal(t, "Create it", entries[1].Subject)
+	assert.Equal(t, "added", entries[1].Change)
+	assert.Equal(t, 2026, entries[1].Time.Year())
+}
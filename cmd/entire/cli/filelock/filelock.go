@@ -0,0 +1,78 @@
+// Package filelock provides exclusive locks between processes.
+//
+// A lock is an OS file lock (flock, or LockFileEx on Windows) on a lock file,
+// so it is released when its holder exits, however long the holder keeps it.
+// Nothing is ever taken over because it looks stale. The holder removes the
+// lock file before releasing it; anyone who opened the removed file finds it
+// is no longer at the lock path and tries again with a new one.
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxBackoff caps the delay between attempts to take a lock.
+const maxBackoff = 100 * time.Millisecond
+
+// ErrLocked is returned when another process holds the lock for longer than
+// the caller is willing to wait.
+var ErrLocked = errors.New("locked by another process")
+
+// Lock is a held lock.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire locks the file at path, creating it if needed, and waits up to wait
+// for another holder to release it. A wait of zero tries once. The directory
+// holding path must exist.
+func Acquire(path string, wait time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(wait)
+	backoff := 5 * time.Millisecond
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) //nolint:gosec // callers pass paths they own
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file: %w", err)
+		}
+		locked, err := lockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			// The previous holder may have removed the file after we opened
+			// it; a lock on a removed file excludes no one.
+			if isLockPath(f, path) {
+				return &Lock{f: f}, nil
+			}
+			_ = f.Close()
+			continue
+		}
+		_ = f.Close()
+		if !time.Now().Before(deadline) {
+			return nil, ErrLocked
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// Release removes the lock file while still holding it, so no one else can
+// have taken it over, then releases the lock.
+func (l *Lock) Release() {
+	_ = os.Remove(l.f.Name())
+	_ = l.f.Close() // Closing the file releases its lock
+}
+
+// isLockPath reports whether the open file f is still the file at path.
+func isLockPath(f *os.File, path string) bool {
+	held, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(held, current)
+}
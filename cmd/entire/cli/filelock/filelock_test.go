@@ -0,0 +1,60 @@
+package filelock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquire_ExcludesAndReleases(t *testing.T) {
+	t.Parallel()
+	lockPath := filepath.Join(t.TempDir(), "a.lock")
+
+	lock, err := Acquire(lockPath, 0)
+	require.NoError(t, err)
+	_, err = Acquire(lockPath, 50*time.Millisecond)
+	require.ErrorIs(t, err, ErrLocked)
+
+	lock.Release()
+	_, err = os.Stat(lockPath)
+	require.ErrorIs(t, err, os.ErrNotExist, "release removes the lock file")
+	lock, err = Acquire(lockPath, 0)
+	require.NoError(t, err)
+	lock.Release()
+}
+
+func TestAcquire_LeftoverLockFileIsFree(t *testing.T) {
+	t.Parallel()
+	lockPath := filepath.Join(t.TempDir(), "a.lock")
+
+	// A lock file left behind by a crashed process is not locked by anyone
+	require.NoError(t, os.WriteFile(lockPath, nil, 0o600))
+	lock, err := Acquire(lockPath, 0)
+	require.NoError(t, err)
+	lock.Release()
+}
+
+// TestAcquire_SurvivesRemovedLockFile verifies that a process that opened a
+// lock file just before its holder removed it does not take a lock that
+// excludes no one.
+func TestAcquire_SurvivesRemovedLockFile(t *testing.T) {
+	t.Parallel()
+	lockPath := filepath.Join(t.TempDir(), "a.lock")
+
+	stale, err := Acquire(lockPath, 0)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(lockPath))
+	current, err := Acquire(lockPath, 0)
+	require.NoError(t, err, "the removed file's lock does not block the lock path")
+	assert.True(t, isLockPath(current.f, lockPath))
+	assert.False(t, isLockPath(stale.f, lockPath))
+
+	_, err = Acquire(lockPath, 0)
+	require.ErrorIs(t, err, ErrLocked)
+	current.Release()
+	_ = stale.f.Close()
+}
@@ -0,0 +1,221 @@
+// Package foreign recognizes AI attribution that other tools leave on commits,
+// so `entire import foreign` can synthesize checkpoints for history recorded
+// before Entire was enabled.
+package foreign
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Import formats.
+const (
+	// FormatAider recognizes the attribution Aider adds to its commits: the
+	// " (aider)" suffix on the author or committer name, the "aider: " subject
+	// prefix, and its Co-authored-by trailer.
+	FormatAider = "aider"
+
+	// FormatGenericTrailer recognizes AI attribution trailers such as
+	// Generated-by, Assisted-by, and Co-authored-by lines naming an AI tool.
+	FormatGenericTrailer = "generic-trailer"
+)
+
+// Formats lists the supported import formats.
+func Formats() []string {
+	return []string{FormatAider, FormatGenericTrailer}
+}
+
+// Commit is the part of a commit the parsers look at.
+type Commit struct {
+	Message       string
+	AuthorName    string
+	CommitterName string
+}
+
+// Attribution is AI attribution found on a commit.
+type Attribution struct {
+	// Tool is the tool the commit is attributed to, as written on the commit
+	Tool string
+
+	// Model is the model named in the attribution, if any
+	Model string
+
+	// Agent is the Entire agent type matching Tool, or empty if Entire has no
+	// matching agent
+	Agent agent.AgentType
+
+	// Evidence is the line that carried the attribution
+	Evidence string
+}
+
+// ValidateFormat returns an error if format is not a supported import format.
+func ValidateFormat(format string) error {
+	if !slices.Contains(Formats(), format) {
+		return fmt.Errorf("unknown import format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return nil
+}
+
+// Parse looks for attribution in the given format on a commit. ok is false
+// when the commit carries none.
+func Parse(format string, c Commit) (Attribution, bool, error) {
+	switch format {
+	case FormatAider:
+		a, ok := parseAider(c)
+		return a, ok, nil
+	case FormatGenericTrailer:
+		a, ok := parseGenericTrailer(c)
+		return a, ok, nil
+	default:
+		return Attribution{}, false, ValidateFormat(format)
+	}
+}
+
+const (
+	aiderNameSuffix    = " (aider)"
+	aiderSubjectPrefix = "aider: "
+	aiderTool          = "aider"
+)
+
+// aiderCoAuthorRegex matches Aider's trailer value, e.g.
+// "aider (openai/gpt-4o) <noreply@aider.chat>".
+var aiderCoAuthorRegex = regexp.MustCompile(`^aider \(([^)]+)\) <[^>]*>$`)
+
+// parseAider recognizes Aider's attribution. The Co-authored-by trailer is
+// preferred because it names the model.
+func parseAider(c Commit) (Attribution, bool) {
+	a := Attribution{Tool: aiderTool, Agent: agent.AgentTypeAider}
+	for _, t := range parseTrailers(c.Message) {
+		if !strings.EqualFold(t.Key, "Co-authored-by") {
+			continue
+		}
+		if m := aiderCoAuthorRegex.FindStringSubmatch(t.Value); m != nil {
+			a.Model = m[1]
+			a.Evidence = t.Key + ": " + t.Value
+			return a, true
+		}
+	}
+	switch subject := strings.SplitN(c.Message, "\n", 2)[0]; {
+	case strings.HasSuffix(c.AuthorName, aiderNameSuffix):
+		a.Evidence = "Author: " + c.AuthorName
+	case strings.HasSuffix(c.CommitterName, aiderNameSuffix):
+		a.Evidence = "Committer: " + c.CommitterName
+	case strings.HasPrefix(subject, aiderSubjectPrefix):
+		a.Evidence = subject
+	default:
+		return Attribution{}, false
+	}
+	return a, true
+}
+
+// toolTrailerKeys are trailers whose value always names an AI tool.
+var toolTrailerKeys = []string{"Generated-by", "Assisted-by", "AI-Assisted-by", "AI-Agent", "AI-Tool"}
+
+// modelTrailerKey names the model when a commit carries it separately.
+const modelTrailerKey = "AI-Model"
+
+// knownTools identifies Co-authored-by trailers that credit an AI tool rather
+// than a person, matched case-insensitively against the name and email.
+var knownTools = []string{"aider", "claude", "codex", "copilot", "cursor", "devin", "gemini", "opencode", "windsurf"}
+
+// parseGenericTrailer recognizes AI attribution trailers. A value of the form
+// "Tool (model) <email>" gives both the tool and the model.
+func parseGenericTrailer(c Commit) (Attribution, bool) {
+	var a Attribution
+	var model string
+	for _, t := range parseTrailers(c.Message) {
+		if strings.EqualFold(t.Key, modelTrailerKey) {
+			model = t.Value
+			continue
+		}
+		if a.Evidence != "" {
+			continue
+		}
+		isTool := slices.ContainsFunc(toolTrailerKeys, func(key string) bool { return strings.EqualFold(t.Key, key) })
+		if !isTool && (!strings.EqualFold(t.Key, "Co-authored-by") || knownTool(t.Value) == "") {
+			continue
+		}
+		a.Tool, a.Model = splitToolValue(t.Value)
+		a.Agent = agentTypeFor(a.Tool)
+		a.Evidence = t.Key + ": " + t.Value
+	}
+	if a.Evidence == "" {
+		return Attribution{}, false
+	}
+	if a.Model == "" {
+		a.Model = model
+	}
+	return a, true
+}
+
+// splitToolValue splits a trailer value such as "GitHub Copilot (gpt-4o) <bot@example.com>"
+// into the tool name and the parenthesized model.
+func splitToolValue(value string) (tool, model string) {
+	if i := strings.Index(value, "<"); i >= 0 {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+	if open := strings.LastIndex(value, "("); open > 0 && strings.HasSuffix(value, ")") {
+		return strings.TrimSpace(value[:open]), strings.TrimSpace(value[open+1 : len(value)-1])
+	}
+	return value, ""
+}
+
+// knownTool returns the known AI tool a trailer value mentions, or empty.
+func knownTool(value string) string {
+	lower := strings.ToLower(value)
+	for _, tool := range knownTools {
+		if strings.Contains(lower, tool) {
+			return tool
+		}
+	}
+	return ""
+}
+
+// agentTypeFor maps a tool name to the matching Entire agent type.
+func agentTypeFor(tool string) agent.AgentType {
+	switch knownTool(tool) {
+	case "aider":
+		return agent.AgentTypeAider
+	case "claude":
+		return agent.AgentTypeClaudeCode
+	case "codex":
+		return agent.AgentTypeCodex
+	case "cursor":
+		return agent.AgentTypeCursor
+	case "gemini":
+		return agent.AgentTypeGemini
+	case "opencode":
+		return agent.AgentTypeOpenCode
+	default:
+		return ""
+	}
+}
+
+// trailer is a "Key: value" line from a commit's trailer block.
+type trailer struct {
+	Key   string
+	Value string
+}
+
+var trailerLineRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.+?)\s*$`)
+
+// parseTrailers returns the trailers in the last paragraph of a commit
+// message. The subject line is never a trailer block.
+func parseTrailers(message string) []trailer {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var trailers []trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if m := trailerLineRegex.FindStringSubmatch(line); m != nil {
+			trailers = append(trailers, trailer{Key: m[1], Value: m[2]})
+		}
+	}
+	return trailers
+}
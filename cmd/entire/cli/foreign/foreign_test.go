@@ -0,0 +1,148 @@
+package foreign
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestParse_Aider(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		commit       Commit
+		wantOK       bool
+		wantModel    string
+		wantEvidence string
+	}{
+		{
+			name: "co-authored-by trailer names the model",
+			commit: Commit{
+				Message:    "feat: Add hello function\n\nCo-authored-by: aider (openai/gpt-4o) <noreply@aider.chat>\n",
+				AuthorName: "Jane Doe (aider)",
+			},
+			wantOK:       true,
+			wantModel:    "openai/gpt-4o",
+			wantEvidence: "Co-authored-by: aider (openai/gpt-4o) <noreply@aider.chat>",
+		},
+		{
+			name:         "author suffix",
+			commit:       Commit{Message: "fix: Handle empty input\n", AuthorName: "Jane Doe (aider)", CommitterName: "Jane Doe"},
+			wantOK:       true,
+			wantEvidence: "Author: Jane Doe (aider)",
+		},
+		{
+			name:         "committer suffix",
+			commit:       Commit{Message: "fix: Handle empty input\n", AuthorName: "Jane Doe", CommitterName: "Jane Doe (aider)"},
+			wantOK:       true,
+			wantEvidence: "Committer: Jane Doe (aider)",
+		},
+		{
+			name:         "subject prefix",
+			commit:       Commit{Message: "aider: Refactor parser\n\nMore details.\n", AuthorName: "Jane Doe"},
+			wantOK:       true,
+			wantEvidence: "aider: Refactor parser",
+		},
+		{
+			name:   "human commit",
+			commit: Commit{Message: "Mention aider in the README\n\nCo-authored-by: John Roe <john@example.com>\n", AuthorName: "Jane Doe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a, ok, err := Parse(FormatAider, tt.commit)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Parse() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if a.Tool != "aider" || a.Agent != agent.AgentTypeAider {
+				t.Errorf("Parse() tool = %q, agent = %q, want aider", a.Tool, a.Agent)
+			}
+			if a.Model != tt.wantModel {
+				t.Errorf("Parse() model = %q, want %q", a.Model, tt.wantModel)
+			}
+			if a.Evidence != tt.wantEvidence {
+				t.Errorf("Parse() evidence = %q, want %q", a.Evidence, tt.wantEvidence)
+			}
+		})
+	}
+}
+
+func TestParse_GenericTrailer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		message   string
+		wantOK    bool
+		wantTool  string
+		wantModel string
+		wantAgent agent.AgentType
+	}{
+		{
+			name:      "generated-by with model",
+			message:   "Add login form\n\nGenerated-by: Cursor (gpt-4o)\n",
+			wantOK:    true,
+			wantTool:  "Cursor",
+			wantModel: "gpt-4o",
+			wantAgent: agent.AgentTypeCursor,
+		},
+		{
+			name:      "assisted-by with separate model trailer",
+			message:   "Add login form\n\nSigned-off-by: Jane Doe <jane@example.com>\nassisted-by: Gemini CLI <noreply@example.com>\nAI-Model: gemini-2.5-pro\n",
+			wantOK:    true,
+			wantTool:  "Gemini CLI",
+			wantModel: "gemini-2.5-pro",
+			wantAgent: agent.AgentTypeGemini,
+		},
+		{
+			name:     "co-authored-by an AI tool without an Entire agent",
+			message:  "Add login form\n\nCo-authored-by: Copilot <198982749+Copilot@users.noreply.github.com>\n",
+			wantOK:   true,
+			wantTool: "Copilot",
+		},
+		{
+			name:    "co-authored-by a person",
+			message: "Add login form\n\nCo-authored-by: Jane Doe <jane@example.com>\n",
+		},
+		{
+			name:    "trailer-like line outside the trailer block",
+			message: "Generated-by: a script\n\nThe body mentions nothing else.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a, ok, err := Parse(FormatGenericTrailer, Commit{Message: tt.message})
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Parse() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if a.Tool != tt.wantTool || a.Model != tt.wantModel || a.Agent != tt.wantAgent {
+				t.Errorf("Parse() = %+v, want tool %q, model %q, agent %q", a, tt.wantTool, tt.wantModel, tt.wantAgent)
+			}
+		})
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := Parse("copilot-workspace", Commit{Message: "x"}); err == nil {
+		t.Error("Parse() with an unknown format should fail")
+	}
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/kvstore"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+// defaultGCRetention is how long ended session states are kept by default.
+const defaultGCRetention = "30d"
+
+func newGCCmd() *cobra.Command {
+	var olderThanFlag string
+	var forceFlag bool
+	var allUsersFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove old session state and unreachable shadow branches",
+		Long: `Garbage-collect Entire data that is no longer useful:
+
+  Ended session states (.git/entire-sessions/)
+    Sessions that ended longer ago than --older-than (default 30d).
+    Sessions that never ended are kept.
+
+  Unreachable shadow branches (entire/<commit-hash>)
+    Shadow branches whose base commit is no longer reachable from any
+    branch, tag, or HEAD, e.g. after an amend or rebase. Branches used by a
+    session that hasn't ended are kept.
+
+  Stale hook state (.git/entire-state/)
+    Pre-prompt and pre-task state left behind by agents that exited
+    without firing their stop hooks.
+
+--older-than accepts a duration (720h, 30d), date (2006-01-02), or RFC 3339
+timestamp. Checkpoints on entire/checkpoints/v1 are never removed.
+
+Default: shows a preview of items that would be deleted.
+With --force, actually deletes them.
+
+When state.per_user is enabled, only the current user's session and hook
+state is collected. Use --all-users to collect every user's.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cutoff, err := parseTimeFlag("--older-than", olderThanFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			if cutoff.IsZero() {
+				return fmt.Errorf("--older-than must not be empty (default %s)", defaultGCRetention)
+			}
+			return runGC(cmd.OutOrStdout(), cutoff, forceFlag, allUsersFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThanFlag, "older-than", defaultGCRetention, "Remove session states that ended before this (duration, date, or timestamp)")
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Actually delete items (default: dry run)")
+	cmd.Flags().BoolVar(&allUsersFlag, "all-users", false, "Include every user's data when state.per_user is enabled")
+
+	return cmd
+}
+
+// expiringBucket is a hook state bucket, whatever its value type.
+type expiringBucket interface {
+	Expired() ([]kvstore.Entry, error)
+	Prune() ([]kvstore.Entry, error)
+}
+
+// hookStateBucket is a hook state bucket with the name it is shown under.
+type hookStateBucket struct {
+	name   string
+	bucket expiringBucket
+}
+
+// hookStateBuckets returns the hook state buckets gc collects.
+func hookStateBuckets(allUsers bool) []hookStateBucket {
+	stores := []*kvstore.Store{hookStateStore()}
+	if allUsers {
+		stores = allHookStateStores()
+	}
+	var buckets []hookStateBucket
+	for _, store := range stores {
+		buckets = append(buckets,
+			hookStateBucket{name: prePromptBucketName, bucket: kvstore.NewBucket[PrePromptState](store, prePromptBucketName)},
+			hookStateBucket{name: preTaskBucketName, bucket: kvstore.NewBucket[PreTaskState](store, preTaskBucketName)},
+		)
+	}
+	return buckets
+}
+
+func runGC(w io.Writer, cutoff time.Time, force, allUsers bool) error {
+	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
+	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
+	logging.SetLogLevelGetter(GetLogLevel)
+	logging.SetUserNamespaceGetter(settings.StateUserNamespace)
+	if err := logging.Init(""); err == nil {
+		defer logging.Close()
+	}
+
+	states, err := strategy.ListExpiredSessionStates(cutoff, allUsers)
+	if err != nil {
+		return fmt.Errorf("failed to list ended session states: %w", err)
+	}
+	branches, err := strategy.ListUnreachableShadowBranches()
+	if err != nil {
+		return fmt.Errorf("failed to list unreachable shadow branches: %w", err)
+	}
+
+	buckets := hookStateBuckets(allUsers)
+	var hookState []string
+	for _, b := range buckets {
+		expired, err := b.bucket.Expired()
+		if err != nil {
+			// Non-fatal: continue with other items
+			fmt.Fprintf(w, "Warning: failed to list %s state: %v\n", b.name, err)
+			continue
+		}
+		for _, entry := range expired {
+			hookState = append(hookState, b.name+"/"+entry.Key)
+		}
+	}
+
+	if len(states) == 0 && len(branches) == 0 && len(hookState) == 0 {
+		fmt.Fprintln(w, "Nothing to collect.")
+		return nil
+	}
+
+	// Preview mode (default)
+	if !force {
+		fmt.Fprintf(w, "Found %d items to collect:\n\n", len(states)+len(branches)+len(hookState))
+		writeGCSection(w, "Ended session states", cleanupItemIDs(states))
+		writeGCSection(w, "Unreachable shadow branches", cleanupItemIDs(branches))
+		writeGCSection(w, "Stale hook state", hookState)
+		fmt.Fprintln(w, "Run with --force to delete these items.")
+		return nil
+	}
+
+	result, err := strategy.DeleteAllCleanupItems(append(states, branches...))
+	if err != nil {
+		return fmt.Errorf("failed to delete items: %w", err)
+	}
+	var prunedHookState, failedHookState []string
+	for _, b := range buckets {
+		pruned, err := b.bucket.Prune()
+		for _, entry := range pruned {
+			prunedHookState = append(prunedHookState, b.name+"/"+entry.Key)
+		}
+		if err != nil {
+			failedHookState = append(failedHookState, fmt.Sprintf("%s: %v", b.name, err))
+		}
+	}
+
+	totalDeleted := len(result.SessionStates) + len(result.ShadowBranches) + len(prunedHookState)
+	totalFailed := len(result.FailedStates) + len(result.FailedBranches) + len(failedHookState)
+	if totalDeleted > 0 {
+		fmt.Fprintf(w, "Deleted %d items:\n\n", totalDeleted)
+		writeGCSection(w, "Ended session states", result.SessionStates)
+		writeGCSection(w, "Unreachable shadow branches", result.ShadowBranches)
+		writeGCSection(w, "Stale hook state", prunedHookState)
+	}
+	if totalFailed > 0 {
+		fmt.Fprintf(w, "Failed to delete %d items:\n\n", totalFailed)
+		writeGCSection(w, "Session states", result.FailedStates)
+		writeGCSection(w, "Shadow branches", result.FailedBranches)
+		writeGCSection(w, "Hook state", failedHookState)
+		return fmt.Errorf("failed to delete %d items", totalFailed)
+	}
+	return nil
+}
+
+// writeGCSection writes a titled list of items, or nothing if it is empty.
+func writeGCSection(w io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s (%d):\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(w, "  %s\n", item)
+	}
+	fmt.Fprintln(w)
+}
+
+func cleanupItemIDs(items []strategy.CleanupItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
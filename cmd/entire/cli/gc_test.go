@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// setupGCTestRepo creates a repo with an ended session from long ago, a
+// recently ended one, a shadow branch on an unreachable base commit, one on
+// HEAD, and stale pre-prompt state. Returns the two shadow branch names.
+func setupGCTestRepo(t *testing.T) (unreachable, reachable string) {
+	t.Helper()
+	repo, commitHash := setupCleanTestRepo(t)
+
+	store, err := session.NewStateStore()
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+	longAgo := time.Now().Add(-60 * 24 * time.Hour)
+	recently := time.Now().Add(-time.Hour)
+	for _, state := range []*session.State{
+		{SessionID: "ended-long-ago", StartedAt: longAgo, EndedAt: &longAgo},
+		{SessionID: "ended-recently", StartedAt: longAgo, EndedAt: &recently},
+	} {
+		if err := store.Save(context.Background(), state); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	unreachable = checkpoint.ShadowBranchNameForCommit("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef", "")
+	reachable = checkpoint.ShadowBranchNameForCommit(commitHash.String(), "")
+	for _, branch := range []string{unreachable, reachable} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), commitHash)); err != nil {
+			t.Fatalf("failed to create shadow branch: %v", err)
+		}
+	}
+
+	staleFile := filepath.Join(hookStateStore().Dir(), prePromptBucketName, "stale-session.json")
+	if err := os.MkdirAll(filepath.Dir(staleFile), 0o750); err != nil {
+		t.Fatalf("failed to create hook state dir: %v", err)
+	}
+	stale := `{"written_at":"` + longAgo.UTC().Format(time.RFC3339) + `","value":{}}`
+	if err := os.WriteFile(staleFile, []byte(stale), 0o600); err != nil {
+		t.Fatalf("failed to write hook state: %v", err)
+	}
+
+	return unreachable, reachable
+}
+
+func TestRunGC_Preview(t *testing.T) {
+	unreachable, reachable := setupGCTestRepo(t)
+
+	var stdout bytes.Buffer
+	if err := runGC(&stdout, time.Now().Add(-30*24*time.Hour), false, false); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	output := stdout.String()
+
+	for _, want := range []string{"Found 3 items", "ended-long-ago", unreachable, "pre-prompt/stale-session", "--force"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("preview missing %q:\n%s", want, output)
+		}
+	}
+	for _, kept := range []string{"ended-recently", reachable} {
+		if strings.Contains(output, kept) {
+			t.Errorf("preview should not list %q:\n%s", kept, output)
+		}
+	}
+
+	// Preview deletes nothing
+	store, err := session.NewStateStore()
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+	if state, err := store.Load(context.Background(), "ended-long-ago"); err != nil || state == nil {
+		t.Errorf("preview deleted session state: %v", err)
+	}
+}
+
+func TestRunGC_Force(t *testing.T) {
+	unreachable, reachable := setupGCTestRepo(t)
+
+	var stdout bytes.Buffer
+	if err := runGC(&stdout, time.Now().Add(-30*24*time.Hour), true, false); err != nil {
+		t.Fatalf("runGC() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Deleted 3 items") {
+		t.Errorf("expected 3 deleted items, got:\n%s", stdout.String())
+	}
+
+	store, err := session.NewStateStore()
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+	if state, err := store.Load(context.Background(), "ended-long-ago"); err != nil || state != nil {
+		t.Errorf("ended-long-ago should be deleted: %v", err)
+	}
+	if state, err := store.Load(context.Background(), "ended-recently"); err != nil || state == nil {
+		t.Errorf("ended-recently should be kept: %v", err)
+	}
+
+	branches, err := strategy.ListShadowBranches()
+	if err != nil {
+		t.Fatalf("ListShadowBranches() error = %v", err)
+	}
+	if len(branches) != 1 || branches[0] != reachable {
+		t.Errorf("shadow branches after gc = %q, want only %s (not %s)", branches, reachable, unreachable)
+	}
+
+	stdout.Reset()
+	if err := runGC(&stdout, time.Now().Add(-30*24*time.Hour), true, false); err != nil {
+		t.Fatalf("second runGC() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Nothing to collect.") {
+		t.Errorf("second run should find nothing, got:\n%s", stdout.String())
+	}
+}
+
+func TestGCCmd_InvalidOlderThan(t *testing.T) {
+	setupCleanTestRepo(t)
+
+	cmd := newGCCmd()
+	cmd.SetArgs([]string{"--older-than", "soon"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "invalid --older-than value") {
+		t.Errorf("Execute() error = %v, want invalid --older-than value", err)
+	}
+}
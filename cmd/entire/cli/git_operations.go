@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
@@ -25,6 +26,17 @@ func openRepository() (*git.Repository, error) {
 	return repo, nil
 }
 
+// checkRepoSupport returns an error wrapping strategy.ErrUnsupportedRepository
+// if the current repository uses features Entire cannot read. Returns nil
+// outside a git repository.
+func checkRepoSupport() error {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil //nolint:nilerr // Not being in a repository is reported elsewhere
+	}
+	return strategy.CheckRepoSupport(repoRoot) //nolint:wrapcheck // already a descriptive error
+}
+
 // GitAuthor represents the git user configuration
 type GitAuthor struct {
 	Name  string
@@ -82,6 +94,9 @@ func getGitConfigValue(key string) string {
 // Returns (isDefault, branchName, error)
 func IsOnDefaultBranch() (bool, string, error) {
 	repo, err := openRepository()
+	if errors.Is(err, strategy.ErrUnsupportedRepository) {
+		return isOnDefaultBranchGit()
+	}
 	if err != nil {
 		return false, "", fmt.Errorf("failed to open git repository: %w", err)
 	}
@@ -101,17 +116,45 @@ func IsOnDefaultBranch() (bool, string, error) {
 
 	// Try to get default branch from remote origin's HEAD
 	defaultBranch := getDefaultBranchFromRemote(repo)
+	return isDefaultBranch(currentBranch, defaultBranch), currentBranch, nil
+}
 
-	// If we couldn't determine from remote, use common defaults
+// isDefaultBranch reports whether currentBranch is the default branch, which
+// is defaultBranch if known, and otherwise main or master.
+func isDefaultBranch(currentBranch, defaultBranch string) bool {
 	if defaultBranch == "" {
-		// Check if current branch is a common default name
-		if currentBranch == "main" || currentBranch == "master" {
-			return true, currentBranch, nil
-		}
-		return false, currentBranch, nil
+		return currentBranch == "main" || currentBranch == "master"
 	}
+	return currentBranch == defaultBranch
+}
 
-	return currentBranch == defaultBranch, currentBranch, nil
+// isOnDefaultBranchGit is IsOnDefaultBranch using the git CLI, for
+// repositories go-git cannot read (e.g. reftable ref storage).
+func isOnDefaultBranchGit() (bool, string, error) {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	ctx := context.Background()
+	_, currentBranch, err := strategy.HeadCLI(ctx, repoRoot)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if currentBranch == "" {
+		// Detached HEAD - not on any branch
+		return false, "", nil
+	}
+
+	defaultBranch := strings.TrimPrefix(strategy.SymbolicReferenceCLI(ctx, repoRoot, "refs/remotes/origin/HEAD"), "refs/remotes/origin/")
+	if defaultBranch == "" {
+		for _, name := range []string{"main", "master"} {
+			if _, err := strategy.ReferenceCLI(ctx, repoRoot, "refs/remotes/origin/"+name); err == nil {
+				defaultBranch = name
+				break
+			}
+		}
+	}
+	return isDefaultBranch(currentBranch, defaultBranch), currentBranch, nil
 }
 
 // getDefaultBranchFromRemote tries to determine the default branch from the origin remote.
@@ -155,6 +198,9 @@ func ShouldSkipOnDefaultBranch() (bool, string) {
 // Returns an error if in detached HEAD state or if not in a git repository.
 func GetCurrentBranch() (string, error) {
 	repo, err := openRepository()
+	if errors.Is(err, strategy.ErrUnsupportedRepository) {
+		return getCurrentBranchGit()
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to open git repository: %w", err)
 	}
@@ -171,6 +217,23 @@ func GetCurrentBranch() (string, error) {
 	return head.Name().Short(), nil
 }
 
+// getCurrentBranchGit resolves the current branch with the git CLI, for
+// repositories go-git cannot read (e.g. reftable ref storage).
+func getCurrentBranchGit() (string, error) {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	_, branch, err := strategy.HeadCLI(context.Background(), repoRoot)
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if branch == "" {
+		return "", errors.New("not on a branch (detached HEAD)")
+	}
+	return branch, nil
+}
+
 // GetMergeBase finds the common ancestor (merge-base) between two branches.
 // Returns the hash of the merge-base commit.
 func GetMergeBase(branch1, branch2 string) (*plumbing.Hash, error) {
@@ -349,7 +412,7 @@ func FetchAndCheckoutRemoteBranch(branchName string) error {
 
 	// Create local branch pointing to the same commit
 	localRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), remoteRef.Hash())
-	err = repo.Storer.SetReference(localRef)
+	err = audit.SetReference(repo, localRef)
 	if err != nil {
 		return fmt.Errorf("failed to create local branch: %w", err)
 	}
@@ -363,7 +426,7 @@ func FetchAndCheckoutRemoteBranch(branchName string) error {
 // Uses git CLI instead of go-git for fetch because go-git doesn't use credential helpers,
 // which breaks HTTPS URLs that require authentication.
 func FetchMetadataBranch() error {
-	branchName := paths.MetadataBranchName
+	branchName := paths.MetadataBranchName()
 
 	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
@@ -392,7 +455,7 @@ func FetchMetadataBranch() error {
 
 	// Create or update local branch pointing to the same commit
 	localRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), remoteRef.Hash())
-	if err := repo.Storer.SetReference(localRef); err != nil {
+	if err := audit.SetReference(repo, localRef); err != nil {
 		return fmt.Errorf("failed to create local %s branch: %w", branchName, err)
 	}
 
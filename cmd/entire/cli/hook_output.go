@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/features"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+// HookOutputEnvVar selects how hook commands report progress. Set it to
+// "json" when an editor or agent wrapper runs the hooks and wants to render
+// their output itself; anything else keeps the default free-text stderr.
+const HookOutputEnvVar = "ENTIRE_HOOK_OUTPUT"
+
+const hookOutputJSON = "json"
+
+// Hook event types emitted in JSON output mode.
+const (
+	hookEventProgress = "progress"
+	hookEventWarning  = "warning"
+	hookEventMessage  = "message"
+	hookEventResult   = "result"
+)
+
+// hookEvent is one line of the JSON hook output protocol. Every hook run
+// emits progress and warning events as they happen, then any message events,
+// then exactly one result event.
+type hookEvent struct {
+	Type    string    `json:"type"`
+	Hook    string    `json:"hook"`
+	Time    time.Time `json:"time"`
+	Message string    `json:"message,omitempty"`
+	// Result events only.
+	Status     string `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs *int64 `json:"duration_ms,omitempty"`
+}
+
+// hookEventWriter encodes hook events as JSON lines.
+type hookEventWriter struct {
+	mu   sync.Mutex
+	enc  *json.Encoder
+	hook string
+	// messages are held until the hook finishes so they are reported after
+	// all of its stderr output, whose forwarding is asynchronous.
+	messages []string
+}
+
+// addMessage queues a message for the user.
+func (w *hookEventWriter) addMessage(message string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, message)
+}
+
+func (w *hookEventWriter) emit(event hookEvent) {
+	event.Hook = w.hook
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.enc.Encode(event) //nolint:errchkjson // best effort, the hook must not fail because its output is closed
+}
+
+// currentHookOutput is the event writer for the hook being run in JSON output
+// mode, or nil in text mode. Used by outputHookResponse to report messages for
+// the user as events instead of the agent's own response format.
+var currentHookOutput *hookEventWriter
+
+// hookOutputIsJSON reports whether ENTIRE_HOOK_OUTPUT selects JSON output.
+func hookOutputIsJSON() bool {
+	return strings.EqualFold(strings.TrimSpace(os.Getenv(HookOutputEnvVar)), hookOutputJSON)
+}
+
+// runHookWithOutput runs a hook handler. In text mode it just calls run.
+// In JSON mode, every line the handler writes to stderr becomes a progress or
+// warning event on the command's stdout, and a result event reports how the
+// hook finished.
+func runHookWithOutput(cmd *cobra.Command, hook string, run func() error) error {
+	start := time.Now()
+	run = recordHookRun(hook, start, run)
+	if !hookOutputIsJSON() {
+		return run()
+	}
+
+	events := &hookEventWriter{enc: json.NewEncoder(cmd.OutOrStdout()), hook: hook}
+
+	r, w, pipeErr := os.Pipe()
+	if pipeErr != nil {
+		// Without a pipe, stderr cannot be converted; still report the result.
+		err := run()
+		events.emit(hookResultEvent(err, time.Since(start)))
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		forwardHookStderr(r, events)
+	}()
+
+	origStderr := os.Stderr
+	os.Stderr = w
+	currentHookOutput = events
+	defer func() {
+		currentHookOutput = nil
+		os.Stderr = origStderr
+	}()
+
+	err := run()
+
+	os.Stderr = origStderr
+	_ = w.Close()
+	<-done
+	_ = r.Close()
+
+	for _, message := range events.messages {
+		events.emit(hookEvent{Type: hookEventMessage, Message: message})
+	}
+	events.emit(hookResultEvent(err, time.Since(start)))
+	return err
+}
+
+// recordHookRun wraps run so that its duration and outcome are appended to
+// the hook run log, which `entire serve` reports on /metrics. Hooks only pay
+// for this when the hook_metrics feature flag is enabled.
+func recordHookRun(hook string, start time.Time, run func() error) func() error {
+	return func() error {
+		err := run()
+		if !features.Enabled(features.HookMetrics) || !settings.IsSetUpAndEnabled() {
+			return err
+		}
+		commonDir, dirErr := strategy.GetGitCommonDir()
+		if dirErr != nil {
+			return err
+		}
+		hookRun := audit.HookRun{Hook: hook, DurationMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			hookRun.Error = err.Error()
+		}
+		audit.RecordHookRun(commonDir, hookRun)
+		return err
+	}
+}
+
+// forwardHookStderr turns each non-empty line read from r into an event.
+func forwardHookStderr(r io.Reader, events *hookEventWriter) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if event, ok := classifyHookLine(scanner.Text()); ok {
+			events.emit(event)
+		}
+	}
+	// Drain anything left (e.g. an over-long line) so writers never block.
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// classifyHookLine maps a line of free-text hook output to an event.
+// Lines starting with "Warning:" (optionally after the "[entire]" prefix)
+// are warnings; everything else is progress.
+func classifyHookLine(line string) (hookEvent, bool) {
+	msg := strings.TrimSpace(line)
+	msg = strings.TrimSpace(strings.TrimPrefix(msg, "[entire]"))
+	if msg == "" {
+		return hookEvent{}, false
+	}
+	if rest, ok := cutPrefixFold(msg, "warning:"); ok {
+		return hookEvent{Type: hookEventWarning, Message: strings.TrimSpace(rest)}, true
+	}
+	return hookEvent{Type: hookEventProgress, Message: msg}, true
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+func hookResultEvent(err error, elapsed time.Duration) hookEvent {
+	ms := elapsed.Milliseconds()
+	event := hookEvent{Type: hookEventResult, Status: "ok", DurationMs: &ms}
+	if err != nil {
+		event.Status = "error"
+		event.Error = err.Error()
+	}
+	return event
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyHookLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line    string
+		want    hookEvent
+		wantOut bool
+	}{
+		{line: "Captured state before prompt: 2 untracked files", want: hookEvent{Type: hookEventProgress, Message: "Captured state before prompt: 2 untracked files"}, wantOut: true},
+		{line: "Warning: failed to load session state: boom", want: hookEvent{Type: hookEventWarning, Message: "failed to load session state: boom"}, wantOut: true},
+		{line: "[entire] Warning: turn stalled", want: hookEvent{Type: hookEventWarning, Message: "turn stalled"}, wantOut: true},
+		{line: "  Session ID: abc", want: hookEvent{Type: hookEventProgress, Message: "Session ID: abc"}, wantOut: true},
+		{line: "   ", wantOut: false},
+	}
+	for _, tt := range tests {
+		got, ok := classifyHookLine(tt.line)
+		assert.Equal(t, tt.wantOut, ok, "line %q", tt.line)
+		assert.Equal(t, tt.want, got, "line %q", tt.line)
+	}
+}
+
+func decodeHookEvents(t *testing.T, data []byte) []hookEvent {
+	t.Helper()
+	var events []hookEvent
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event hookEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event), scanner.Text())
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestRunHookWithOutput_JSON(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv() and replace os.Stderr
+	t.Setenv(HookOutputEnvVar, "json")
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	err := runHookWithOutput(cmd, "claude-code/stop", func() error {
+		fmt.Fprintln(os.Stderr, "Created shadow branch 'entire/abc1234'")
+		fmt.Fprintln(os.Stderr, "Warning: failed to compute diff")
+		return outputHookResponse("Powered by Entire")
+	})
+	require.NoError(t, err)
+
+	events := decodeHookEvents(t, out.Bytes())
+	require.Len(t, events, 4)
+	assert.Equal(t, hookEventProgress, events[0].Type)
+	assert.Equal(t, "Created shadow branch 'entire/abc1234'", events[0].Message)
+	assert.Equal(t, hookEventWarning, events[1].Type)
+	assert.Equal(t, "failed to compute diff", events[1].Message)
+	assert.Equal(t, hookEventMessage, events[2].Type)
+	assert.Equal(t, "Powered by Entire", events[2].Message)
+	assert.Equal(t, hookEventResult, events[3].Type)
+	assert.Equal(t, "ok", events[3].Status)
+	assert.NotNil(t, events[3].DurationMs)
+	for _, event := range events {
+		assert.Equal(t, "claude-code/stop", event.Hook)
+		assert.False(t, event.Time.IsZero())
+	}
+	assert.Nil(t, currentHookOutput, "event writer should be cleared after the hook")
+}
+
+func TestRunHookWithOutput_JSONError(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv() and replace os.Stderr
+	t.Setenv(HookOutputEnvVar, "JSON")
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	hookErr := errors.New("commit blocked")
+	err := runHookWithOutput(cmd, "git/commit-msg", func() error { return hookErr })
+	require.ErrorIs(t, err, hookErr)
+
+	events := decodeHookEvents(t, out.Bytes())
+	require.Len(t, events, 1)
+	assert.Equal(t, hookEventResult, events[0].Type)
+	assert.Equal(t, "error", events[0].Status)
+	assert.Equal(t, "commit blocked", events[0].Error)
+}
+
+func TestRunHookWithOutput_TextMode(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(HookOutputEnvVar, "")
+
+	cmd := &cobra.Command{}
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+
+	called := false
+	require.NoError(t, runHookWithOutput(cmd, "git/post-commit", func() error {
+		called = true
+		return nil
+	}))
+	assert.True(t, called)
+	assert.Empty(t, out.String(), "text mode should not write events")
+}
+
+func TestRunHookWithOutput_RecordsHookRunsOnlyWithFlag(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	dir := t.TempDir()
+	testutil.InitRepo(t, dir)
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	gitDir := filepath.Join(dir, ".git")
+
+	runHook := func() {
+		t.Helper()
+		err := runHookWithOutput(&cobra.Command{}, "git/post-commit", func() error { return errors.New("boom") })
+		require.EqualError(t, err, "boom")
+	}
+
+	testutil.WriteFile(t, dir, ".entire/settings.json", `{"enabled": true}`)
+	runHook()
+	runs, err := audit.ReadHookRuns(gitDir, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, runs, "hook runs must not be recorded without the hook_metrics flag")
+
+	testutil.WriteFile(t, dir, ".entire/settings.json", `{"enabled": true, "features": {"hook_metrics": true}}`)
+	runHook()
+	runs, err = audit.ReadHookRuns(gitDir, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "git/post-commit", runs[0].Hook)
+	assert.Equal(t, "boom", runs[0].Error)
+}
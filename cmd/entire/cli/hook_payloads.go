@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/spf13/cobra"
+)
+
+// Hook payload capture for replay debugging.
+//
+// When debug.capture_hook_payloads is enabled, every agent hook invocation stores
+// the exact stdin payload the agent delivered under .entire/debug/hook-payloads/.
+// `entire hooks replay <file>` feeds a captured payload back through the same
+// dispatcher, turning "can't reproduce" hook bugs into replayable cases.
+//
+// Payloads contain user prompts and transcript paths. They are written with
+// 0o600 permissions and .entire/debug/ is gitignored.
+
+// hookPayloadCaptureDir is the directory for captured payloads (relative to repo root).
+const hookPayloadCaptureDir = paths.EntireDebugDir + "/hook-payloads"
+
+// maxCapturedHookPayloads is the number of captured payloads kept on disk.
+// Older captures are removed when a new one is written.
+const maxCapturedHookPayloads = 200
+
+// capturedHookPayloadTimeFormat sorts lexically in chronological order.
+const capturedHookPayloadTimeFormat = "20060102T150405.000000000Z"
+
+// CapturedHookPayload is the on-disk format of a captured agent hook invocation.
+type CapturedHookPayload struct {
+	Agent      agent.AgentName `json:"agent"`
+	Hook       string          `json:"hook"`
+	CapturedAt time.Time       `json:"captured_at"`
+
+	// Payload holds the stdin payload when it is valid JSON.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// RawPayload holds the stdin payload verbatim when it is not valid JSON.
+	RawPayload string `json:"raw_payload,omitempty"`
+}
+
+// Stdin returns the payload bytes exactly as the agent delivered them.
+func (p *CapturedHookPayload) Stdin() []byte {
+	if len(p.Payload) > 0 {
+		return p.Payload
+	}
+	return []byte(p.RawPayload)
+}
+
+// captureHookPayload reads the hook's stdin, stores it under .entire/debug/, and
+// returns a reader that yields the same bytes for the dispatcher. Capture failures
+// are reported on stderr but never block the hook.
+func captureHookPayload(agentName agent.AgentName, hookName string, stdin io.Reader) io.Reader {
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read hook payload for capture: %v\n", err)
+		return bytes.NewReader(data)
+	}
+
+	if err := writeCapturedHookPayload(agentName, hookName, data, time.Now().UTC()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to capture hook payload: %v\n", err)
+	}
+	return bytes.NewReader(data)
+}
+
+// writeCapturedHookPayload writes a capture file and rotates old captures.
+func writeCapturedHookPayload(agentName agent.AgentName, hookName string, data []byte, now time.Time) error {
+	dir, err := paths.AbsPath(hookPayloadCaptureDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve capture directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create capture directory: %w", err)
+	}
+
+	captured := CapturedHookPayload{
+		Agent:      agentName,
+		Hook:       hookName,
+		CapturedAt: now,
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && json.Valid(trimmed) {
+		captured.Payload = json.RawMessage(trimmed)
+	} else {
+		captured.RawPayload = string(data)
+	}
+
+	out, err := jsonutil.MarshalIndentWithNewline(captured, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal captured payload: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%s-%s.json", now.Format(capturedHookPayloadTimeFormat), agentName, hookName)
+	if err := os.WriteFile(filepath.Join(dir, name), out, 0o600); err != nil {
+		return fmt.Errorf("failed to write captured payload: %w", err)
+	}
+
+	return rotateCapturedHookPayloads(dir, maxCapturedHookPayloads)
+}
+
+// rotateCapturedHookPayloads removes the oldest capture files so that at most keep remain.
+func rotateCapturedHookPayloads(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read capture directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names[:len(names)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old capture %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// loadCapturedHookPayload reads and validates a capture file.
+func loadCapturedHookPayload(path string) (*CapturedHookPayload, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the user on the command line
+	if err != nil {
+		return nil, fmt.Errorf("failed to read captured payload: %w", err)
+	}
+
+	var captured CapturedHookPayload
+	if err := json.Unmarshal(data, &captured); err != nil {
+		return nil, fmt.Errorf("failed to parse captured payload: %w", err)
+	}
+	if captured.Agent == "" || captured.Hook == "" {
+		return nil, errors.New("captured payload is missing agent or hook name")
+	}
+	return &captured, nil
+}
+
+func newHooksReplayCmd() *cobra.Command {
+	var showOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <file>",
+		Short: "Re-run the hook dispatcher against a captured payload",
+		Long: `Re-run the hook dispatcher against a payload captured with
+debug.capture_hook_payloads enabled. Captures are stored in .entire/debug/hook-payloads/.
+
+Replaying performs the same work as the original hook (including checkpoint
+writes), so run it in a scratch clone when reproducing bugs.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := paths.WorktreeRoot(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository. Run 'entire hooks replay' from within the repository the payload was captured in.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+
+			captured, err := loadCapturedHookPayload(args[0])
+			if err != nil {
+				return err
+			}
+
+			ag, err := agent.Get(captured.Agent)
+			if err != nil {
+				return fmt.Errorf("unknown agent in capture: %w", err)
+			}
+			handler, ok := ag.(agent.HookSupport)
+			if !ok {
+				return fmt.Errorf("agent %q does not support hooks", captured.Agent)
+			}
+			if !slices.Contains(handler.HookNames(), captured.Hook) {
+				return fmt.Errorf("agent %q has no hook named %q", captured.Agent, captured.Hook)
+			}
+
+			if showOnly {
+				fmt.Fprintf(cmd.OutOrStdout(), "Agent:    %s\nHook:     %s\nCaptured: %s\n\n",
+					captured.Agent, captured.Hook, captured.CapturedAt.Format(time.RFC3339))
+				var pretty bytes.Buffer
+				if json.Indent(&pretty, captured.Stdin(), "", "  ") == nil {
+					fmt.Fprintln(cmd.OutOrStdout(), pretty.String())
+				} else {
+					fmt.Fprintln(cmd.OutOrStdout(), string(captured.Stdin()))
+				}
+				return nil
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Replaying %s %s hook captured at %s\n",
+				captured.Agent, captured.Hook, captured.CapturedAt.Format(time.RFC3339))
+
+			cleanup := initHookLogging()
+			defer cleanup()
+
+			return runAgentHook(captured.Agent, captured.Hook, bytes.NewReader(captured.Stdin()))
+		},
+	}
+
+	cmd.Flags().BoolVar(&showOnly, "show", false, "Print the captured payload without dispatching it")
+
+	return cmd
+}
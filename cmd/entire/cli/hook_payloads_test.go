@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCapturedHookPayload_RoundTrip(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	payload := []byte(`{"session_id":"abc","transcript_path":"/tmp/t.jsonl"}` + "\n")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)
+	require.NoError(t, writeCapturedHookPayload(agent.AgentNameClaudeCode, "stop", payload, now))
+
+	entries, err := os.ReadDir(filepath.Join(tmpDir, hookPayloadCaptureDir))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "20260102T030405.000000006Z-claude-code-stop.json", entries[0].Name())
+
+	info, err := entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	captured, err := loadCapturedHookPayload(filepath.Join(tmpDir, hookPayloadCaptureDir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, agent.AgentNameClaudeCode, captured.Agent)
+	assert.Equal(t, "stop", captured.Hook)
+	assert.True(t, now.Equal(captured.CapturedAt))
+	assert.JSONEq(t, string(payload), string(captured.Stdin()))
+	assert.Empty(t, captured.RawPayload)
+}
+
+func TestWriteCapturedHookPayload_NonJSONPayload(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	payload := []byte("not json {")
+	require.NoError(t, writeCapturedHookPayload(agent.AgentNameGemini, "session-start", payload, time.Now().UTC()))
+
+	entries, err := os.ReadDir(filepath.Join(tmpDir, hookPayloadCaptureDir))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	captured, err := loadCapturedHookPayload(filepath.Join(tmpDir, hookPayloadCaptureDir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Empty(t, captured.Payload)
+	assert.Equal(t, payload, captured.Stdin())
+}
+
+func TestRotateCapturedHookPayloads_KeepsNewest(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	names := []string{
+		"20260101T000000.000000000Z-claude-code-stop.json",
+		"20260101T000001.000000000Z-claude-code-stop.json",
+		"20260101T000002.000000000Z-claude-code-stop.json",
+		"20260101T000003.000000000Z-claude-code-stop.json",
+	}
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0o600))
+	}
+
+	require.NoError(t, rotateCapturedHookPayloads(dir, 2))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var remaining []string
+	for _, e := range entries {
+		remaining = append(remaining, e.Name())
+	}
+	assert.Equal(t, names[2:], remaining)
+}
+
+func TestLoadCapturedHookPayload_RejectsIncompleteCapture(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "capture.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"hook":"stop","payload":{}}`), 0o600))
+
+	_, err := loadCapturedHookPayload(path)
+	require.Error(t, err)
+}
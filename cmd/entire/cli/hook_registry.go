@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
@@ -94,72 +95,92 @@ func newAgentHookVerbCmdWithLogging(agentName agent.AgentName, hookName string)
 		Hidden: true,
 		Short:  "Called on " + hookName,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			// Skip silently if not in a git repository - hooks shouldn't prevent the agent from working
-			if _, err := paths.WorktreeRoot(); err != nil {
-				return nil
-			}
+			return runHookWithOutput(cmd, string(agentName)+"/"+hookName, func() error {
+				// Skip silently if not in a git repository - hooks shouldn't prevent the agent from working
+				if _, err := paths.WorktreeRoot(); err != nil {
+					return nil
+				}
+
+				// Skip if Entire is not enabled, or not enabled on this branch
+				s, err := LoadEntireSettings()
+				if err == nil && !IsEnabledOnCurrentBranch(s) {
+					return nil
+				}
+
+				// Skip with a warning if go-git cannot read the repository
+				if supportErr := checkRepoSupport(); supportErr != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "[entire] Skipping: %v\n", supportErr)
+					return nil
+				}
+
+				stdin := cmd.InOrStdin() // Use cmd.InOrStdin() to support testing with cmd.SetIn()
+				if err == nil && s.IsHookPayloadCaptureEnabled() {
+					stdin = captureHookPayload(agentName, hookName, stdin)
+				}
+
+				return runAgentHook(agentName, hookName, stdin)
+			})
+		},
+	}
+}
 
-			// Skip if Entire is not enabled
-			enabled, err := IsEnabled()
-			if err == nil && !enabled {
-				return nil
-			}
+// runAgentHook parses the hook payload from stdin and dispatches it.
+// Shared by agent hook commands and `entire hooks replay`.
+func runAgentHook(agentName agent.AgentName, hookName string, stdin io.Reader) error {
+	start := time.Now()
 
-			start := time.Now()
+	// Initialize logging context with agent name
+	ctx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), agentName)
 
-			// Initialize logging context with agent name
-			ctx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), agentName)
+	// Get strategy name for logging
+	strategyName := GetStrategy().Name()
 
-			// Get strategy name for logging
-			strategyName := GetStrategy().Name()
+	hookType := getHookType(hookName)
 
-			hookType := getHookType(hookName)
+	logging.Debug(ctx, "hook invoked",
+		slog.String("hook", hookName),
+		slog.String("hook_type", hookType),
+		slog.String("strategy", strategyName),
+	)
 
-			logging.Debug(ctx, "hook invoked",
-				slog.String("hook", hookName),
-				slog.String("hook_type", hookType),
-				slog.String("strategy", strategyName),
-			)
+	drainCondensationQueue()
 
-			// Set the current hook agent so handlers can retrieve it
-			currentHookAgentName = agentName
-			defer func() { currentHookAgentName = "" }()
+	// Set the current hook agent so handlers can retrieve it
+	currentHookAgentName = agentName
+	defer func() { currentHookAgentName = "" }()
 
-			// Use the lifecycle dispatcher for all hooks
-			var hookErr error
-			ag, agentErr := agent.Get(agentName)
-			if agentErr != nil {
-				return fmt.Errorf("failed to get agent %q: %w", agentName, agentErr)
-			}
+	// Use the lifecycle dispatcher for all hooks
+	var hookErr error
+	ag, agentErr := agent.Get(agentName)
+	if agentErr != nil {
+		return fmt.Errorf("failed to get agent %q: %w", agentName, agentErr)
+	}
 
-			handler, ok := ag.(agent.HookSupport)
-			if !ok {
-				return fmt.Errorf("agent %q does not support hooks", agentName)
-			}
+	handler, ok := ag.(agent.HookSupport)
+	if !ok {
+		return fmt.Errorf("agent %q does not support hooks", agentName)
+	}
 
-			// Use cmd.InOrStdin() to support testing with cmd.SetIn()
-			event, parseErr := handler.ParseHookEvent(hookName, cmd.InOrStdin())
-			if parseErr != nil {
-				return fmt.Errorf("failed to parse hook event: %w", parseErr)
-			}
+	event, parseErr := handler.ParseHookEvent(hookName, stdin)
+	if parseErr != nil {
+		return fmt.Errorf("failed to parse hook event: %w", parseErr)
+	}
 
-			if event != nil {
-				// Lifecycle event — use the generic dispatcher
-				hookErr = DispatchLifecycleEvent(ag, event)
-			} else if agentName == agent.AgentNameClaudeCode && hookName == claudecode.HookNamePostTodo {
-				// PostTodo is Claude-specific: creates incremental checkpoints during subagent execution
-				hookErr = handleClaudeCodePostTodo()
-			}
-			// Other pass-through hooks (nil event, no special handling) are no-ops
+	if event != nil {
+		// Lifecycle event — use the generic dispatcher
+		hookErr = DispatchLifecycleEvent(ag, event)
+	} else if agentName == agent.AgentNameClaudeCode && hookName == claudecode.HookNamePostTodo {
+		// PostTodo is Claude-specific: creates incremental checkpoints during subagent execution
+		hookErr = handleClaudeCodePostTodo()
+	}
+	// Other pass-through hooks (nil event, no special handling) are no-ops
 
-			logging.LogDuration(ctx, slog.LevelDebug, "hook completed", start,
-				slog.String("hook", hookName),
-				slog.String("hook_type", hookType),
-				slog.String("strategy", strategyName),
-				slog.Bool("success", hookErr == nil),
-			)
+	logging.LogDuration(ctx, slog.LevelDebug, "hook completed", start,
+		slog.String("hook", hookName),
+		slog.String("hook_type", hookType),
+		slog.String("strategy", strategyName),
+		slog.Bool("success", hookErr == nil),
+	)
 
-			return hookErr
-		},
-	}
+	return hookErr
 }
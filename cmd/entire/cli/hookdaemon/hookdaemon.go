@@ -0,0 +1,306 @@
+// Package hookdaemon lets hook commands run in a long-lived process. A
+// daemon listens on a unix socket per working tree; the `entire` process
+// started by a hook forwards its arguments, working directory, environment
+// and stdin, and relays the output and exit code it gets back.
+//
+// Forwarding is always optional: when no daemon is listening, or it does not
+// accept a request, the caller runs the hook itself. A request that was
+// accepted is never run twice.
+package hookdaemon
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+const (
+	// dialTimeout bounds connecting to the daemon. A live daemon accepts
+	// immediately; anything slower is treated as unavailable.
+	dialTimeout = 200 * time.Millisecond
+	// acceptTimeout bounds waiting for the daemon to accept a request.
+	acceptTimeout = 2 * time.Second
+	// readRequestTimeout bounds how long the daemon waits for a request
+	// after a client connects.
+	readRequestTimeout = 5 * time.Second
+)
+
+// ErrAlreadyRunning is returned by Listen when another daemon is serving the
+// socket.
+var ErrAlreadyRunning = errors.New("a hook daemon is already running for this working tree")
+
+// Request is one hook invocation.
+type Request struct {
+	// Version identifies the client binary. The daemon rejects requests
+	// from other versions so an upgrade never runs stale hook logic.
+	Version string `json:"version"`
+	// Args are the command-line arguments, without the program name.
+	Args []string `json:"args"`
+	// Dir is the client's working directory.
+	Dir string `json:"dir"`
+	// Env is the client's environment.
+	Env []string `json:"env"`
+	// Stdin is the client's standard input, for hooks that read it.
+	Stdin []byte `json:"stdin,omitempty"`
+}
+
+// frame is one message from the daemon to the client. The first frame
+// either accepts or rejects the request; an accepted request is followed by
+// output frames and a final frame carrying the exit code.
+type frame struct {
+	Accepted bool   `json:"accepted,omitempty"`
+	Rejected string `json:"rejected,omitempty"`
+	Stdout   []byte `json:"stdout,omitempty"`
+	Stderr   []byte `json:"stderr,omitempty"`
+	Exit     *int   `json:"exit,omitempty"`
+}
+
+// SocketPath returns the daemon socket for the working tree rooted at
+// worktreeRoot. Sockets live in a per-user directory under the system temp
+// directory because unix socket paths are limited to about 100 bytes.
+func SocketPath(worktreeRoot string) string {
+	sum := sha256.Sum256([]byte(paths.ResolveSymlinks(worktreeRoot)))
+	return filepath.Join(socketDir(), hex.EncodeToString(sum[:8])+".sock")
+}
+
+func socketDir() string {
+	return filepath.Join(os.TempDir(), "entire-"+strconv.Itoa(os.Getuid()))
+}
+
+// checkSocketDir makes sure dir is a private directory of the current user,
+// so neither side of the socket can be impersonated by another user.
+func checkSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err //nolint:wrapcheck // callers add context
+	}
+	if !info.IsDir() || info.Mode().Perm() != 0o700 || !ownedByCurrentUser(info) {
+		return fmt.Errorf("%s must be a directory private to the current user", dir)
+	}
+	return nil
+}
+
+// FindWorktreeRoot returns the nearest directory at or above dir that has a
+// .git entry. It only looks at the file system, so the client can find the
+// daemon without starting git.
+func FindWorktreeRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Lstat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// Available reports whether a daemon socket exists at socketPath. It is a
+// cheap check for the client's fast path; Forward still handles a socket
+// nobody is listening on.
+func Available(socketPath string) bool {
+	info, err := os.Lstat(socketPath)
+	return err == nil && info.Mode().Type() == os.ModeSocket
+}
+
+// Forward sends req to the daemon at socketPath and copies the output it
+// streams back to stdout and stderr. ok is false when the daemon did not
+// accept the request; nothing has run and the caller should run the hook
+// itself. Once the request is accepted, ok is true and exitCode is the
+// hook's exit code.
+func Forward(socketPath string, req *Request, stdout, stderr io.Writer) (exitCode int, ok bool) {
+	if checkSocketDir(filepath.Dir(socketPath)) != nil {
+		return 0, false
+	}
+	conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+	if err != nil {
+		return 0, false
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(acceptTimeout)) //nolint:errcheck // best effort, a failed read falls back
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return 0, false
+	}
+	dec := json.NewDecoder(bufio.NewReader(conn))
+	var first frame
+	if err := dec.Decode(&first); err != nil || !first.Accepted {
+		return 0, false
+	}
+	_ = conn.SetDeadline(time.Time{}) //nolint:errcheck // hooks may legitimately run for a long time
+
+	for {
+		var f frame
+		if err := dec.Decode(&f); err != nil {
+			fmt.Fprintf(stderr, "[entire] Warning: lost connection to the hook daemon: %v\n", err)
+			return 1, true
+		}
+		if len(f.Stdout) > 0 {
+			_, _ = stdout.Write(f.Stdout) //nolint:errcheck // the hook's result matters more than its output
+		}
+		if len(f.Stderr) > 0 {
+			_, _ = stderr.Write(f.Stderr) //nolint:errcheck // the hook's result matters more than its output
+		}
+		if f.Exit != nil {
+			return *f.Exit, true
+		}
+	}
+}
+
+// Handler runs one accepted request, writing its output to stdout and
+// stderr, and returns its exit code.
+type Handler func(ctx context.Context, req *Request, stdout, stderr io.Writer) int
+
+// Server serves hook requests. Requests are read and accepted as soon as
+// clients connect, then run one at a time: hook handlers change process-wide
+// state such as the working directory and environment.
+type Server struct {
+	// Handler runs accepted requests.
+	Handler Handler
+	// Version must match Request.Version for a request to be accepted.
+	Version string
+	// IdleTimeout stops the server after this long without requests.
+	// Zero means never.
+	IdleTimeout time.Duration
+
+	mu       sync.Mutex
+	inFlight atomic.Int32
+}
+
+// Listen creates the socket for a daemon at socketPath, replacing a stale
+// socket left by a daemon that exited without cleaning up.
+func Listen(socketPath string) (*net.UnixListener, error) {
+	dir := filepath.Dir(socketPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := checkSocketDir(dir); err != nil {
+		return nil, err
+	}
+	if Available(socketPath) {
+		if conn, err := net.DialTimeout("unix", socketPath, dialTimeout); err == nil {
+			_ = conn.Close()
+			return nil, ErrAlreadyRunning
+		}
+		if err := os.Remove(socketPath); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", socketPath, err)
+		}
+	}
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	return l, nil
+}
+
+// Serve accepts requests on l until ctx is cancelled or the server has been
+// idle for IdleTimeout. It closes l, which removes the socket file.
+func (s *Server) Serve(ctx context.Context, l *net.UnixListener) error {
+	stop := context.AfterFunc(ctx, func() { _ = l.Close() })
+	defer stop()
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		if s.IdleTimeout > 0 {
+			_ = l.SetDeadline(time.Now().Add(s.IdleTimeout)) //nolint:errcheck // without a deadline the server just never idles out
+		}
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if s.inFlight.Load() == 0 {
+					return nil
+				}
+				continue
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+		s.inFlight.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer s.inFlight.Add(-1)
+			s.serveConn(ctx, conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(readRequestTimeout)) //nolint:errcheck // best effort
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{}) //nolint:errcheck // best effort
+
+	out := &frameWriter{enc: json.NewEncoder(conn)}
+	if req.Version != s.Version {
+		out.send(frame{Rejected: fmt.Sprintf("daemon runs version %s, client is %s", s.Version, req.Version)})
+		return
+	}
+	// If the client already gave up, it will run the hook itself.
+	if err := out.send(frame{Accepted: true}); err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	code := s.Handler(ctx, &req, out.stream(false), out.stream(true))
+	out.send(frame{Exit: &code}) //nolint:errcheck // nothing left to do if the client is gone
+}
+
+// frameWriter serializes frames from the handler's stdout and stderr.
+type frameWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (w *frameWriter) send(f frame) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(f) //nolint:wrapcheck // callers only check for failure
+}
+
+func (w *frameWriter) stream(stderr bool) io.Writer {
+	return streamWriter{w: w, stderr: stderr}
+}
+
+// streamWriter sends writes as stdout or stderr frames. Write errors are
+// swallowed so a client that disconnects does not fail the hook.
+type streamWriter struct {
+	w      *frameWriter
+	stderr bool
+}
+
+func (s streamWriter) Write(p []byte) (int, error) {
+	data := append([]byte(nil), p...)
+	if s.stderr {
+		_ = s.w.send(frame{Stderr: data}) //nolint:errcheck // see streamWriter
+	} else {
+		_ = s.w.send(frame{Stdout: data}) //nolint:errcheck // see streamWriter
+	}
+	return len(p), nil
+}
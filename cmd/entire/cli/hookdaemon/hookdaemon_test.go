@@ -0,0 +1,138 @@
+package hookdaemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startServer serves a daemon for root until the test ends.
+func startServer(t *testing.T, root string, server *Server) string {
+	t.Helper()
+	socketPath := SocketPath(root)
+	l, err := Listen(socketPath)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, l) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+	return socketPath
+}
+
+func TestForward(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+
+	var calls atomic.Int32
+	socketPath := startServer(t, root, &Server{
+		Version: "v1",
+		Handler: func(_ context.Context, req *Request, stdout, stderr io.Writer) int {
+			calls.Add(1)
+			fmt.Fprintf(stdout, "args: %s\n", strings.Join(req.Args, " "))
+			fmt.Fprintf(stderr, "stdin: %s\n", req.Stdin)
+			return 3
+		},
+	})
+	assert.True(t, Available(socketPath))
+
+	var stdout, stderr bytes.Buffer
+	req := &Request{Version: "v1", Args: []string{"hooks", "git", "post-commit"}, Dir: root, Stdin: []byte("payload")}
+	code, ok := Forward(socketPath, req, &stdout, &stderr)
+	require.True(t, ok)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "args: hooks git post-commit\n", stdout.String())
+	assert.Equal(t, "stdin: payload\n", stderr.String())
+
+	// Requests from another version are not accepted and not run.
+	req.Version = "v2"
+	_, ok = Forward(socketPath, req, &stdout, &stderr)
+	assert.False(t, ok)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestForward_NoDaemon(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	socketPath := SocketPath(t.TempDir())
+	assert.False(t, Available(socketPath))
+	_, ok := Forward(socketPath, &Request{}, io.Discard, io.Discard)
+	assert.False(t, ok)
+}
+
+func TestListen(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	root := t.TempDir()
+	socketPath := SocketPath(root)
+
+	l, err := Listen(socketPath)
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Dir(socketPath))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	_, err = Listen(socketPath)
+	require.ErrorIs(t, err, ErrAlreadyRunning)
+
+	// A socket left behind by a daemon that died is replaced.
+	l.SetUnlinkOnClose(false)
+	require.NoError(t, l.Close())
+	require.True(t, Available(socketPath))
+	l, err = Listen(socketPath)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+	assert.False(t, Available(socketPath), "closing the listener removes the socket")
+}
+
+func TestListen_SharedDirectory(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	socketPath := SocketPath(t.TempDir())
+	require.NoError(t, os.MkdirAll(filepath.Dir(socketPath), 0o755))
+	require.NoError(t, os.Chmod(filepath.Dir(socketPath), 0o755))
+
+	_, err := Listen(socketPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "private to the current user")
+}
+
+func TestServe_IdleTimeout(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	l, err := Listen(SocketPath(t.TempDir()))
+	require.NoError(t, err)
+
+	server := &Server{IdleTimeout: 50 * time.Millisecond}
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(context.Background(), l) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop after the idle timeout")
+	}
+}
+
+func TestFindWorktreeRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o755))
+	nested := filepath.Join(root, "a", "b")
+	require.NoError(t, os.MkdirAll(nested, 0o755))
+
+	got, ok := FindWorktreeRoot(nested)
+	require.True(t, ok)
+	assert.Equal(t, root, got)
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package hookdaemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByCurrentUser reports whether info belongs to the current user.
+func ownedByCurrentUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
@@ -238,8 +238,13 @@ type hookResponse struct {
 	SystemMessage string `json:"systemMessage,omitempty"`
 }
 
-// outputHookResponse outputs a JSON response to stdout
+// outputHookResponse outputs a JSON response to stdout.
+// In JSON hook output mode the reason is emitted as a message event instead.
 func outputHookResponse(reason string) error {
+	if currentHookOutput != nil {
+		currentHookOutput.addMessage(reason)
+		return nil
+	}
 	resp := hookResponse{
 		SystemMessage: reason,
 	}
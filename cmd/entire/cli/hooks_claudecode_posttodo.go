@@ -62,7 +62,7 @@ func handleClaudeCodePostTodoFromReader(reader io.Reader) error {
 	}
 
 	// Detect file changes since last checkpoint
-	changes, err := DetectFileChanges(nil)
+	changes, err := DetectFileChanges(nil, nil)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to detect changed files: %v\n", err)
 		return nil
@@ -3,8 +3,12 @@ package cli
 import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	// Import agents to ensure they are registered before we iterate
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/generic"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/opencode"
 
 	"github.com/spf13/cobra"
@@ -21,6 +25,12 @@ func newHooksCmd() *cobra.Command {
 	// Git hooks are strategy-level (not agent-specific)
 	cmd.AddCommand(newHooksGitCmd())
 
+	// Replay captured agent hook payloads (debug.capture_hook_payloads)
+	cmd.AddCommand(newHooksReplayCmd())
+
+	// Reinstall hooks after `entire upgrade`
+	cmd.AddCommand(newHooksRefreshCmd())
+
 	// Dynamically add agent hook subcommands
 	// Each agent that implements HookSupport gets its own subcommand tree
 	for _, agentName := range agent.List() {
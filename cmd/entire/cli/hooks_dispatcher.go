@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// DispatchedGitHookName returns the git hook the binary was started as, when
+// git runs it through a link installed by the hooks dispatcher, or "" when it
+// was started as entire.
+func DispatchedGitHookName(argv0 string) string {
+	name := filepath.Base(argv0)
+	if strategy.IsGitHookName(name) {
+		return name
+	}
+	return ""
+}
+
+// RunDispatchedGitHook handles a git hook started through the hooks
+// dispatcher and returns the exit code for git. Hooks Entire manages run
+// first, with the same arguments and failure handling as the hook scripts;
+// then the hook of the same name from the previous hooks directory runs, and
+// its exit code is returned.
+func RunDispatchedGitHook(hookName string, args []string) int {
+	if strategy.IsManagedGitHook(hookName) {
+		// Both hooks read post-rewrite's list of rewritten commits
+		var stdin []byte
+		if hookName == "post-rewrite" {
+			stdin, _ = io.ReadAll(os.Stdin) //nolint:errcheck // a short read leaves fewer commits to remap
+			restoreStdin(stdin)
+		}
+		code := runEntireGitHook(hookName, args)
+		if stdin != nil {
+			restoreStdin(stdin)
+		}
+		if code != 0 {
+			return code
+		}
+	}
+	return runChainedGitHook(hookName, args)
+}
+
+// runEntireGitHook runs `entire hooks git <hookName>`. As in the hook
+// scripts, only commit-msg can fail the git operation, and prepare-commit-msg
+// and post-commit run with stderr discarded.
+func runEntireGitHook(hookName string, args []string) int {
+	var maxArgs int
+	quiet, canFail := false, false
+	switch hookName {
+	case "prepare-commit-msg":
+		maxArgs, quiet = 2, true
+	case "commit-msg":
+		maxArgs, canFail = 1, true
+	case "post-commit":
+		maxArgs, quiet = 0, true
+	case "pre-push":
+		maxArgs = 1
+	case "post-rewrite":
+		maxArgs, quiet = 1, true
+	}
+	hookArgs := append([]string{"hooks", "git", hookName}, args[:min(len(args), maxArgs)]...)
+
+	if quiet {
+		if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+			stderr := os.Stderr
+			os.Stderr = devNull
+			defer func() {
+				os.Stderr = stderr
+				_ = devNull.Close()
+			}()
+		}
+	}
+
+	if code, ok := ForwardHookToDaemon(hookArgs); ok {
+		if canFail {
+			return code
+		}
+		return 0
+	}
+
+	rootCmd := NewRootCmd()
+	rootCmd.SetArgs(hookArgs)
+	err := rootCmd.ExecuteContext(context.Background())
+	if err == nil || !canFail {
+		return 0
+	}
+	var silent *SilentError
+	if !errors.As(err, &silent) {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return 1
+}
+
+// runChainedGitHook runs the named hook from the hooks directory that was
+// active before the dispatcher was installed, if it has one, passing on the
+// arguments and stdin git gave the dispatcher. Entire's own hook scripts are
+// skipped so a hook never runs twice.
+func runChainedGitHook(hookName string, args []string) int {
+	chainDir := strategy.HookChainDir()
+	if chainDir == "" {
+		return 0
+	}
+	hookPath := filepath.Join(chainDir, hookName)
+	info, err := os.Stat(hookPath)
+	if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+		return 0
+	}
+	if strategy.IsEntireHookScript(hookPath) {
+		return 0
+	}
+
+	cmd := exec.CommandContext(context.Background(), hookPath, args...) //nolint:gosec // runs the user's own hook, as git would
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to run %s: %v\n", hookPath, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatchedGitHookName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "commit-msg", DispatchedGitHookName("/repo/.git/entire/hooks/commit-msg"))
+	assert.Equal(t, "pre-commit", DispatchedGitHookName("pre-commit"))
+	assert.Empty(t, DispatchedGitHookName("/usr/local/bin/entire"))
+}
+
+func TestRunChainedGitHook(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	ctx := context.Background()
+	require.NoError(t, exec.CommandContext(ctx, "git", "init").Run())
+	paths.ClearWorktreeRootCache()
+
+	// Not installed: nothing to chain to
+	assert.Equal(t, 0, runChainedGitHook("pre-commit", nil))
+
+	chainDir := filepath.Join(tmpDir, "previous-hooks")
+	require.NoError(t, os.MkdirAll(chainDir, 0o755))
+	require.NoError(t, exec.CommandContext(ctx, "git", "config", "entire.hooksChainDir", chainDir).Run())
+
+	argsFile := filepath.Join(tmpDir, "args")
+	hook := "#!/bin/sh\necho \"$@\" > " + argsFile + "\nexit 3\n"
+	require.NoError(t, os.WriteFile(filepath.Join(chainDir, "pre-commit"), []byte(hook), 0o755))
+
+	assert.Equal(t, 3, runChainedGitHook("pre-commit", []string{"a", "b"}), "the hook's exit code is passed on")
+	data, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, "a b\n", string(data))
+
+	// Entire's own hook scripts are never run again
+	entireHook := "#!/bin/sh\n# Entire CLI hooks\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(chainDir, "post-commit"), []byte(entireHook), 0o755))
+	assert.Equal(t, 0, runChainedGitHook("post-commit", nil))
+
+	// Hooks that are not executable are skipped, as git skips them
+	require.NoError(t, os.WriteFile(filepath.Join(chainDir, "pre-push"), []byte(hook), 0o644))
+	assert.Equal(t, 0, runChainedGitHook("pre-push", nil))
+}
+
+func TestRunDispatchedGitHook_PostRewriteStdin(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir() and replace os.Stdin
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	ctx := context.Background()
+	require.NoError(t, exec.CommandContext(ctx, "git", "init").Run())
+	paths.ClearWorktreeRootCache()
+	// Entire is enabled, so its own post-rewrite handler reads stdin first
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".entire", "settings.json"), []byte(`{"enabled": true}`), 0o644))
+	t.Setenv(HookDaemonEnvVar, "0")
+
+	chainDir := filepath.Join(tmpDir, "previous-hooks")
+	require.NoError(t, os.MkdirAll(chainDir, 0o755))
+	require.NoError(t, exec.CommandContext(ctx, "git", "config", "entire.hooksChainDir", chainDir).Run())
+	stdinFile := filepath.Join(tmpDir, "stdin")
+	require.NoError(t, os.WriteFile(filepath.Join(chainDir, "post-rewrite"), []byte("#!/bin/sh\ncat > "+stdinFile+"\n"), 0o755))
+
+	origStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = origStdin })
+	rewritten := "1111111111111111111111111111111111111111 2222222222222222222222222222222222222222\n"
+	restoreStdin([]byte(rewritten))
+
+	assert.Equal(t, 0, RunDispatchedGitHook("post-rewrite", []string{"rebase"}))
+	data, err := os.ReadFile(stdinFile)
+	require.NoError(t, err)
+	assert.Equal(t, rewritten, string(data), "the chained hook still gets the rewritten commits")
+}
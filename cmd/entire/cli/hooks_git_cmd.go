@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -18,6 +19,10 @@ const unknownStrategyName = "unknown"
 // When true, all git hook commands return early without doing any work.
 var gitHooksDisabled bool
 
+// gitHooksSkipReason explains why git hooks are disabled when the user should
+// be told (e.g. an unsupported repository). Nil when skipping silently.
+var gitHooksSkipReason error
+
 // gitHookContext holds common state for git hook logging.
 type gitHookContext struct {
 	hookName     string
@@ -74,6 +79,7 @@ func initHookLogging() func() {
 
 	// Set up log level getter so logging can read from settings
 	logging.SetLogLevelGetter(GetLogLevel)
+	logging.SetUserNamespaceGetter(settings.StateUserNamespace)
 
 	// Read session ID for the slog attribute (empty string is fine - log file is fixed)
 	sessionID := strategy.FindMostRecentSession()
@@ -94,14 +100,26 @@ func newHooksGitCmd() *cobra.Command {
 		Short:  "Git hook handlers",
 		Long:   "Commands called by git hooks. These delegate to the current strategy.",
 		Hidden: true, // Internal command, not for direct user use
-		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			// Check if Entire is set up and enabled before doing any work.
 			// This prevents global git hooks from doing anything in repos where
 			// Entire was never enabled or has been disabled.
+			gitHooksDisabled, gitHooksSkipReason = false, nil
 			if !settings.IsSetUpAndEnabled() {
 				gitHooksDisabled = true
 				return nil
 			}
+			// Stay inert on branches excluded by enabled_branches / disabled_branches.
+			if s, err := LoadEntireSettings(); err == nil && !IsEnabledOnCurrentBranch(s) {
+				gitHooksDisabled = true
+				return nil
+			}
+			// Never block a commit or push because Entire cannot read the repository.
+			if err := checkRepoSupport(); err != nil {
+				gitHooksDisabled = true
+				gitHooksSkipReason = err
+				return nil
+			}
 			hookLogCleanup = initHookLogging()
 			return nil
 		},
@@ -117,33 +135,48 @@ func newHooksGitCmd() *cobra.Command {
 	cmd.AddCommand(newHooksGitCommitMsgCmd())
 	cmd.AddCommand(newHooksGitPostCommitCmd())
 	cmd.AddCommand(newHooksGitPrePushCmd())
+	cmd.AddCommand(newHooksGitPostRewriteCmd())
 
 	return cmd
 }
 
+// runGitHook runs a git hook handler with the hook output mode applied,
+// skipping it when git hooks are disabled for this repository.
+func runGitHook(cmd *cobra.Command, hookName string, run func() error) error {
+	return runHookWithOutput(cmd, "git/"+hookName, func() error {
+		if gitHooksDisabled {
+			if gitHooksSkipReason != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "[entire] Skipping: %v\n", gitHooksSkipReason)
+			}
+			return nil
+		}
+		return run()
+	})
+}
+
 func newHooksGitPrepareCommitMsgCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "prepare-commit-msg <commit-msg-file> [source]",
 		Short: "Handle prepare-commit-msg git hook",
 		Args:  cobra.RangeArgs(1, 2),
-		RunE: func(_ *cobra.Command, args []string) error {
-			if gitHooksDisabled {
-				return nil
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGitHook(cmd, "prepare-commit-msg", func() error {
+				commitMsgFile := args[0]
+				var source string
+				if len(args) > 1 {
+					source = args[1]
+				}
 
-			commitMsgFile := args[0]
-			var source string
-			if len(args) > 1 {
-				source = args[1]
-			}
+				g := newGitHookContext("prepare-commit-msg")
+				g.logInvoked(slog.String("source", source))
 
-			g := newGitHookContext("prepare-commit-msg")
-			g.logInvoked(slog.String("source", source))
+				drainCondensationQueue()
 
-			hookErr := g.strategy.PrepareCommitMsg(commitMsgFile, source)
-			g.logCompleted(hookErr, slog.String("source", source))
+				hookErr := g.strategy.PrepareCommitMsg(commitMsgFile, source)
+				g.logCompleted(hookErr, slog.String("source", source))
 
-			return nil
+				return nil
+			})
 		},
 	}
 }
@@ -153,19 +186,17 @@ func newHooksGitCommitMsgCmd() *cobra.Command {
 		Use:   "commit-msg <commit-msg-file>",
 		Short: "Handle commit-msg git hook",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			if gitHooksDisabled {
-				return nil
-			}
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGitHook(cmd, "commit-msg", func() error {
+				commitMsgFile := args[0]
 
-			commitMsgFile := args[0]
+				g := newGitHookContext("commit-msg")
+				g.logInvoked()
 
-			g := newGitHookContext("commit-msg")
-			g.logInvoked()
-
-			hookErr := g.strategy.CommitMsg(commitMsgFile)
-			g.logCompleted(hookErr)
-			return hookErr //nolint:wrapcheck // Thin delegation layer - wrapping adds no value
+				hookErr := g.strategy.CommitMsg(commitMsgFile)
+				g.logCompleted(hookErr)
+				return hookErr //nolint:wrapcheck // Thin delegation layer - wrapping adds no value
+			})
 		},
 	}
 }
@@ -175,18 +206,16 @@ func newHooksGitPostCommitCmd() *cobra.Command {
 		Use:   "post-commit",
 		Short: "Handle post-commit git hook",
 		Args:  cobra.NoArgs,
-		RunE: func(_ *cobra.Command, _ []string) error {
-			if gitHooksDisabled {
-				return nil
-			}
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runGitHook(cmd, "post-commit", func() error {
+				g := newGitHookContext("post-commit")
+				g.logInvoked()
 
-			g := newGitHookContext("post-commit")
-			g.logInvoked()
+				hookErr := g.strategy.PostCommit()
+				g.logCompleted(hookErr)
 
-			hookErr := g.strategy.PostCommit()
-			g.logCompleted(hookErr)
-
-			return nil
+				return nil
+			})
 		},
 	}
 }
@@ -196,20 +225,46 @@ func newHooksGitPrePushCmd() *cobra.Command {
 		Use:   "pre-push <remote>",
 		Short: "Handle pre-push git hook",
 		Args:  cobra.ExactArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
-			if gitHooksDisabled {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGitHook(cmd, "pre-push", func() error {
+				remote := args[0]
+
+				g := newGitHookContext("pre-push")
+				g.logInvoked(slog.String("remote", remote))
+
+				// Condense queued commits so their checkpoints are pushed too
+				drainCondensationQueue()
+
+				hookErr := g.strategy.PrePush(remote)
+				g.logCompleted(hookErr, slog.String("remote", remote))
+
 				return nil
-			}
+			})
+		},
+	}
+}
 
-			remote := args[0]
+func newHooksGitPostRewriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-rewrite <rebase|amend>",
+		Short: "Handle post-rewrite git hook",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGitHook(cmd, "post-rewrite", func() error {
+				rewriteType := args[0]
 
-			g := newGitHookContext("pre-push")
-			g.logInvoked(slog.String("remote", remote))
+				g := newGitHookContext("post-rewrite")
+				g.logInvoked(slog.String("type", rewriteType))
 
-			hookErr := g.strategy.PrePush(remote)
-			g.logCompleted(hookErr, slog.String("remote", remote))
+				// Git lists the rewritten commits on stdin as "<old> <new>" lines
+				rewritten, hookErr := strategy.ParseRewrittenCommits(cmd.InOrStdin())
+				if hookErr == nil {
+					hookErr = g.strategy.PostRewrite(rewriteType, rewritten)
+				}
+				g.logCompleted(hookErr, slog.String("type", rewriteType), slog.Int("commits", len(rewritten)))
 
-			return nil
+				return nil
+			})
 		},
 	}
 }
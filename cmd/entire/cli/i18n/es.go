@@ -0,0 +1,120 @@
+package i18n
+
+// spanish holds the Spanish translations, keyed by English text.
+var spanish = map[string]string{
+	// Agent session banner
+	"Powered by Entire:": "Con la tecnología de Entire:",
+	"This conversation will be linked to your next commit.":                    "Esta conversación se vinculará a tu próximo commit.",
+	"%d other active conversation(s) in this workspace will also be included.": "También se incluirán %d conversación(es) activa(s) más de este espacio de trabajo.",
+	"Use 'entire status' for more information.":                                "Usa 'entire status' para más información.",
+
+	// entire status
+	"not a git repository":                            "no es un repositorio git",
+	"not set up (run `entire enable` to get started)": "sin configurar (ejecuta `entire enable` para empezar)",
+	"Enabled":         "Activado",
+	"Disabled":        "Desactivado",
+	"enabled":         "activado",
+	"disabled":        "desactivado",
+	"branch %s":       "rama %s",
+	"Project":         "Proyecto",
+	"Local":           "Local",
+	"Active Sessions": "Sesiones activas",
+	"started %s":      "iniciada %s",
+	"active now":      "activa ahora",
+	"active %s":       "activa %s",
+	"resumed from %s": "reanudada de %s",
+	"tokens %s":       "tokens %s",
+	"cost %s est.":    "coste %s est.",
+	"1 session":       "1 sesión",
+	"%d sessions":     "%d sesiones",
+	"just now":        "justo ahora",
+	"%dm ago":         "hace %dm",
+	"%dh ago":         "hace %dh",
+	"%dd ago":         "hace %dd",
+
+	// entire status branch patterns
+	"disabled on this branch by pattern %s":                         "desactivado en esta rama por el patrón %s",
+	"disabled on this branch (no enabled_branches pattern matches)": "desactivado en esta rama (ningún patrón de enabled_branches coincide)",
+
+	// entire status quick actions
+	"Select a session": "Selecciona una sesión",
+	"Session %s":       "Sesión %s",
+	"Show diff":        "Ver diff",
+	"Rewind last step": "Deshacer el último paso",
+	"Open transcript":  "Abrir transcripción",
+	"Condense now":     "Condensar ahora",
+	"End session":      "Terminar sesión",
+	"End session %s?":  "¿Terminar la sesión %s?",
+	"Its checkpoints are kept and are still condensed into your next commit.": "Sus checkpoints se conservan y se condensarán igualmente en tu próximo commit.",
+	"Session %s ended.": "Sesión %s terminada.",
+	"No earlier step to rewind to. Use `entire rewind` to choose a checkpoint.": "No hay un paso anterior al que volver. Usa `entire rewind` para elegir un checkpoint.",
+	"Rewind the last step?": "¿Deshacer el último paso?",
+	"This will reset to: %s\nChanges after this point may be lost!": "Se restaurará a: %s\n¡Los cambios posteriores pueden perderse!",
+	"Condensed session %s into checkpoint %s.":                      "Sesión %s condensada en el checkpoint %s.",
+	"Condensed session %s.":                                         "Sesión %s condensada.",
+
+	// entire doctor
+	"No stuck sessions found.":                "No se encontraron sesiones atascadas.",
+	"Found %d stuck session(s):":              "Se encontraron %d sesión(es) atascada(s):",
+	"Session state drift detected (%d):":      "Se detectaron %d desajuste(s) en el estado de las sesiones:",
+	"Re-bound session %s: %s -> %s":           "Sesión %s reasignada: %s -> %s",
+	"Session:":                                "Sesión:",
+	"Phase:":                                  "Fase:",
+	"Reason:":                                 "Motivo:",
+	"Agent:":                                  "Agente:",
+	"Last interaction:":                       "Última interacción:",
+	"Shadow branch:":                          "Rama sombra:",
+	"not found":                               "no encontrada",
+	"exists (%s)":                             "existe (%s)",
+	"Checkpoints: %d, Files touched: %d":      "Checkpoints: %d, archivos modificados: %d",
+	"active, last interaction %s ago":         "activa, última interacción hace %s",
+	"active, no recorded interaction time":    "activa, sin hora de interacción registrada",
+	"ended with uncondensed checkpoint data":  "terminada con datos de checkpoint sin condensar",
+	"Fix session %s?":                         "¿Reparar la sesión %s?",
+	"Condense (save to permanent storage)":    "Condensar (guardar en almacenamiento permanente)",
+	"Discard (remove session data)":           "Descartar (eliminar los datos de la sesión)",
+	"Skip (leave as-is)":                      "Omitir (dejar como está)",
+	"Condensed session %s":                    "Sesión %s condensada",
+	"Discarded session %s":                    "Sesión %s descartada",
+	"Skipped":                                 "Omitida",
+	"No damaged data found.":                  "No se encontraron datos dañados.",
+	"Found %d problem(s):":                    "Se encontraron %d problema(s):",
+	"Fix: %s":                                 "Solución: %s",
+	"No automated fix":                        "Sin solución automática",
+	"Repair %s?":                              "¿Reparar %s?",
+	"Repaired":                                "Reparado",
+	"Not a git repository.":                   "No es un repositorio git.",
+	"Shadow branches":                         "Ramas sombra",
+	"Session states":                          "Estados de sesión",
+	"Settings files":                          "Archivos de configuración",
+	"Git hooks":                               "Hooks de git",
+	"Agent hooks":                             "Hooks de agentes",
+	"Metadata branch":                         "Rama de metadatos",
+	"Entire is not set up in this repository": "Entire no está configurado en este repositorio",
+	"no agent has Entire hooks installed":     "ningún agente tiene los hooks de Entire instalados",
+	"... and %d more":                         "... y %d más",
+	"All checks passed.":                      "Todas las comprobaciones son correctas.",
+	"%d of %d checks failed.":                 "Fallaron %d de %d comprobaciones.",
+	"Run 'entire doctor --repair' to delete branches that can't be read.":                                           "Ejecuta 'entire doctor --repair' para eliminar las ramas que no se pueden leer.",
+	"Run 'entire doctor --repair' to fix them.":                                                                     "Ejecuta 'entire doctor --repair' para repararlos.",
+	"Fix or remove the invalid settings; 'entire schema dump' prints the settings schema.":                          "Corrige o elimina la configuración no válida; 'entire schema dump' muestra el esquema de configuración.",
+	"Run 'entire enable' to reinstall the hooks, and 'chmod +x' any hook that is not executable.":                   "Ejecuta 'entire enable' para reinstalar los hooks y 'chmod +x' en los hooks que no sean ejecutables.",
+	"Run 'entire enable --agent <agent> --force' to reinstall an agent's hooks.":                                    "Ejecuta 'entire enable --agent <agente> --force' para reinstalar los hooks de un agente.",
+	"Restore %s from the remote or a backup; 'entire doctor --repair' fixes a branch that points at the zero hash.": "Restaura %s desde el remoto o una copia de seguridad; 'entire doctor --repair' repara una rama que apunta al hash cero.",
+
+	// Prompts
+	"Which agents are you using?":                                     "¿Qué agentes usas?",
+	"Use space to select, enter to confirm.":                          "Usa espacio para seleccionar y enter para confirmar.",
+	"Help improve Entire CLI?":                                        "¿Quieres ayudar a mejorar Entire CLI?",
+	"Share anonymous usage data. No code or personal info collected.": "Comparte datos de uso anónimos. No se recopila código ni información personal.",
+	"Yes": "Sí",
+	"No":  "No",
+	"Are you sure you want to uninstall Entire?": "¿Seguro que quieres desinstalar Entire?",
+	"Yes, uninstall":             "Sí, desinstalar",
+	"Cancel":                     "Cancelar",
+	"Reset session data?":        "¿Restablecer los datos de la sesión?",
+	"Reset session %s?":          "¿Restablecer la sesión %s?",
+	"Phase: %s, Checkpoints: %d": "Fase: %s, Checkpoints: %d",
+	"Erase session %s?":          "¿Borrar la sesión %s?",
+	"This rewrites the history of the checkpoints branch and cannot be undone.": "Esto reescribe el historial de la rama de checkpoints y no se puede deshacer.",
+}
@@ -0,0 +1,116 @@
+// Package i18n translates user-facing messages.
+//
+// Messages are looked up by their English text, so call sites stay readable
+// and English needs no catalog: any message without a translation in the
+// active locale is shown in English. Only text meant for people is
+// translated; hook protocol output, log lines, error messages, and
+// "Warning:" prefixes that tooling parses stay in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+)
+
+// LocaleEnvVar is the environment variable that selects the locale.
+// It takes precedence over the "locale" setting.
+const LocaleEnvVar = "ENTIRE_LOCALE"
+
+// DefaultLocale is the locale messages are written in.
+const DefaultLocale = "en"
+
+// catalogs maps a language code to its translations, keyed by English text.
+var catalogs = map[string]map[string]string{
+	"es": spanish,
+}
+
+var (
+	mu sync.Mutex
+
+	// localeGetter is an optional callback to get the locale from settings.
+	// Set by SetLocaleGetter.
+	localeGetter func() string
+
+	// settingsLocale caches the getter's result so settings are read once.
+	settingsLocale       string
+	settingsLocaleLoaded bool
+)
+
+// SetLocaleGetter sets a callback function to get the locale from settings.
+// This allows the i18n package to read settings without a circular dependency.
+// The callback is only used if ENTIRE_LOCALE env var is not set.
+func SetLocaleGetter(getter func() string) {
+	mu.Lock()
+	defer mu.Unlock()
+	localeGetter = getter
+	settingsLocale = ""
+	settingsLocaleLoaded = false
+}
+
+// ClearLocaleCache forgets the cached locale setting, so the next Locale
+// call reads settings again.
+func ClearLocaleCache() {
+	mu.Lock()
+	defer mu.Unlock()
+	settingsLocale = ""
+	settingsLocaleLoaded = false
+}
+
+func init() {
+	repocache.Register(ClearLocaleCache)
+}
+
+// Locale returns the active locale: ENTIRE_LOCALE, then the "locale" setting,
+// reduced to its language code ("es_ES.UTF-8" becomes "es"). Locales without
+// a catalog resolve to DefaultLocale.
+func Locale() string {
+	requested := os.Getenv(LocaleEnvVar)
+	if requested == "" {
+		requested = localeFromSettings()
+	}
+	if lang := normalizeLocale(requested); catalogs[lang] != nil {
+		return lang
+	}
+	return DefaultLocale
+}
+
+// T returns msg translated into the active locale.
+func T(msg string) string {
+	if translated, ok := catalogs[Locale()][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Tf translates format and formats it like fmt.Sprintf.
+// Translations must use the same verbs, in the same order, as the English format.
+func Tf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+func localeFromSettings() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if !settingsLocaleLoaded && localeGetter != nil {
+		settingsLocale = localeGetter()
+		settingsLocaleLoaded = true
+	}
+	return settingsLocale
+}
+
+// normalizeLocale reduces a POSIX or BCP 47 locale name to a lowercase
+// language code: "es_ES.UTF-8", "es-MX" and "ES" all become "es".
+func normalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(locale)
+}
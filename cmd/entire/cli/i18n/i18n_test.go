@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"es":          "es",
+		"ES":          "es",
+		"es_ES.UTF-8": "es",
+		"es-MX":       "es",
+		"de_DE@euro":  "de",
+		" en ":        "en",
+		"":            "",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, normalizeLocale(in), "locale %q", in)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv() and replace the locale getter
+	t.Cleanup(func() { SetLocaleGetter(nil) })
+
+	t.Setenv(LocaleEnvVar, "")
+	SetLocaleGetter(nil)
+	assert.Equal(t, DefaultLocale, Locale())
+
+	SetLocaleGetter(func() string { return "es_ES" })
+	assert.Equal(t, "es", Locale(), "locale setting should be used when the env var is unset")
+
+	t.Setenv(LocaleEnvVar, "en")
+	assert.Equal(t, "en", Locale(), "env var should take precedence over the setting")
+
+	t.Setenv(LocaleEnvVar, "xx")
+	assert.Equal(t, DefaultLocale, Locale(), "unsupported locales fall back to English")
+}
+
+func TestT(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv()
+	t.Setenv(LocaleEnvVar, "es")
+
+	assert.Equal(t, "Sesiones activas", T("Active Sessions"))
+	assert.Equal(t, "3 sesiones", Tf("%d sessions", 3))
+	assert.Equal(t, "not in any catalog", T("not in any catalog"), "untranslated messages stay in English")
+
+	t.Setenv(LocaleEnvVar, "en")
+	assert.Equal(t, "3 sessions", Tf("%d sessions", 3))
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// Translations are passed to fmt.Sprintf with the English format's arguments,
+// so they must use the same verbs in the same order.
+func TestCatalogs_FormatVerbsMatch(t *testing.T) {
+	t.Parallel()
+
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			assert.Equal(t, formatVerb.FindAllString(msg, -1), formatVerb.FindAllString(translated, -1),
+				"%s translation of %q", lang, msg)
+			assert.NotEmpty(t, translated, "%s translation of %q", lang, msg)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/foreign"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// importStrategyName is recorded as the strategy of imported checkpoints.
+const importStrategyName = "import"
+
+// importSessionPrefix prefixes the session ID of imported checkpoints. The
+// rest is the imported commit's abbreviated hash.
+const importSessionPrefix = "imported-"
+
+// foreignImport is a commit with foreign attribution and the checkpoint
+// synthesized for it.
+type foreignImport struct {
+	Commit       string
+	CheckpointID id.CheckpointID
+	Attribution  foreign.Attribution
+}
+
+func newImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import attribution recorded by other tools",
+	}
+
+	cmd.AddCommand(newImportForeignCmd())
+
+	return cmd
+}
+
+func newImportForeignCmd() *cobra.Command {
+	var formatFlag string
+	var rangeFlag string
+	var dryRunFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "foreign",
+		Short: "Create checkpoints for commits attributed by other AI tools",
+		Long: `Find commits that another AI tool attributed to itself and create a checkpoint
+for each, so history views are complete after switching to Entire. Imported
+checkpoints are marked as imported and record the tool, the model if known, and
+the line the attribution came from. They have no transcript or prompts.
+
+Formats:
+
+  aider            Aider's " (aider)" author or committer suffix, "aider: "
+                   subject prefix, or Co-authored-by: aider (<model>) trailer
+  generic-trailer  Generated-by, Assisted-by, AI-Assisted-by, AI-Agent and
+                   AI-Tool trailers, and Co-authored-by trailers naming an AI tool
+
+Commits are linked to their checkpoints as with 'entire stamp', without being
+rewritten. Commits already linked to a checkpoint are skipped, so the import
+can be re-run. Push ` + paths.MetadataBranchName() + ` afterwards to share the checkpoints.
+
+  entire import foreign --format aider
+  entire import foreign --format generic-trailer --range v1.0..main --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if formatFlag == "" {
+				return errors.New("pass --format (" + strings.Join(foreign.Formats(), " or ") + ")")
+			}
+			return runImportForeign(cmd, formatFlag, rangeFlag, dryRunFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Attribution format: "+strings.Join(foreign.Formats(), ", "))
+	cmd.Flags().StringVar(&rangeFlag, "range", "HEAD", "Commits to scan")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be imported without writing anything")
+
+	return cmd
+}
+
+func runImportForeign(cmd *cobra.Command, format, commitRange string, dryRun bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	if err := foreign.ValidateFormat(format); err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	stamps := newStampIndex(committed)
+	author, err := GetGitAuthor()
+	if err != nil {
+		return fmt.Errorf("failed to get git author: %w", err)
+	}
+
+	commits, err := revList(ctx, repoRoot, "--no-merges", "--reverse", commitRange)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	var imports []foreignImport
+	for _, hash := range commits {
+		commit, commitErr := repo.CommitObject(plumbing.NewHash(hash))
+		if commitErr != nil {
+			return fmt.Errorf("failed to read commit %s: %w", hash, commitErr)
+		}
+		if len(stamps.checkpointsFor(commit)) > 0 {
+			continue
+		}
+		attribution, ok, parseErr := foreign.Parse(format, foreign.Commit{
+			Message:       commit.Message,
+			AuthorName:    commit.Author.Name,
+			CommitterName: commit.Committer.Name,
+		})
+		if parseErr != nil {
+			return fmt.Errorf("failed to parse commit %s: %w", strategy.TruncateHash(hash), parseErr)
+		}
+		if !ok {
+			continue
+		}
+
+		imp := foreignImport{Commit: hash, Attribution: attribution}
+		if !dryRun {
+			if imp.CheckpointID, err = id.Generate(); err != nil {
+				return fmt.Errorf("failed to generate checkpoint ID: %w", err)
+			}
+			files, filesErr := commitFiles(ctx, repoRoot, hash)
+			if filesErr != nil {
+				return filesErr
+			}
+			writeErr := store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+				CheckpointID:  imp.CheckpointID,
+				SessionID:     importSessionPrefix + hash[:12],
+				Strategy:      importStrategyName,
+				FilesTouched:  files,
+				AuthorName:    author.Name,
+				AuthorEmail:   author.Email,
+				CommitSubject: fmt.Sprintf("Imported from %s attribution on %s", format, strategy.TruncateHash(hash)),
+				Agent:         attribution.Agent,
+				CreatedAt:     commit.Committer.When,
+				Imported: &checkpoint.ImportInfo{
+					Format:   format,
+					Commit:   hash,
+					Tool:     attribution.Tool,
+					Model:    attribution.Model,
+					Evidence: attribution.Evidence,
+				},
+			})
+			if writeErr != nil {
+				return fmt.Errorf("failed to write checkpoint for %s: %w", strategy.TruncateHash(hash), writeErr)
+			}
+			if _, stampErr := store.StampCommit(ctx, imp.CheckpointID, commit.Hash); stampErr != nil {
+				return fmt.Errorf("failed to stamp commit %s: %w", strategy.TruncateHash(hash), stampErr)
+			}
+		}
+		imports = append(imports, imp)
+	}
+
+	writeForeignImports(w, imports, len(commits), dryRun)
+	return nil
+}
+
+// commitFiles returns the files a commit changed relative to its first parent.
+func commitFiles(ctx context.Context, repoRoot, hash string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "diff-tree", "--no-commit-id", "--name-only", "-r", "-z", "--root", hash).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree failed for %s: %w", strategy.TruncateHash(hash), err)
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func writeForeignImports(w io.Writer, imports []foreignImport, scanned int, dryRun bool) {
+	for _, imp := range imports {
+		tool := imp.Attribution.Tool
+		if imp.Attribution.Model != "" {
+			tool += ", " + imp.Attribution.Model
+		}
+		if dryRun {
+			fmt.Fprintf(w, "Would import %s (%s): %s\n", strategy.TruncateHash(imp.Commit), tool, imp.Attribution.Evidence)
+			continue
+		}
+		fmt.Fprintf(w, "Imported %s as checkpoint %s (%s)\n", strategy.TruncateHash(imp.Commit), imp.CheckpointID, tool)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintln(w)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "%d of %d commit(s) would be imported.\n", len(imports), scanned)
+		return
+	}
+	fmt.Fprintf(w, "%d of %d commit(s) imported.\n", len(imports), scanned)
+}
+
+// formatImportInfo describes where an imported checkpoint's attribution came
+// from, e.g. "aider (openai/gpt-4o) via aider format on 1a2b3c4".
+func formatImportInfo(imp *checkpoint.ImportInfo) string {
+	tool := imp.Tool
+	if imp.Model != "" {
+		tool += " (" + imp.Model + ")"
+	}
+	return fmt.Sprintf("%s via %s format on %s", tool, imp.Format, strategy.TruncateHash(imp.Commit))
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupImportRepo creates a history with two Aider commits between human ones.
+func setupImportRepo(t *testing.T) (tmpDir, trailerCommit, prefixCommit string) {
+	t.Helper()
+	tmpDir = t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "README.md", "# test\n")
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "Initial commit")
+
+	testutil.WriteFile(t, tmpDir, "hello.py", "print('hello')\n")
+	testutil.WriteFile(t, tmpDir, "docs/usage notes.md", "Run it.\n")
+	testutil.GitAdd(t, tmpDir, "hello.py")
+	testutil.GitAdd(t, tmpDir, "docs/usage notes.md")
+	testutil.GitCommit(t, tmpDir, "feat: Add hello\n\nCo-authored-by: aider (openai/gpt-4o) <noreply@aider.chat>\n")
+	trailerCommit = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "hello.py", "print('hello, world')\n")
+	testutil.GitAdd(t, tmpDir, "hello.py")
+	testutil.GitCommit(t, tmpDir, "aider: Greet the world")
+	prefixCommit = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "README.md", "# test\n\nBy hand.\n")
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "Update README")
+	return tmpDir, trailerCommit, prefixCommit
+}
+
+func runImportForeignForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newImportForeignCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestImportForeign_Aider(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, trailerCommit, prefixCommit := setupImportRepo(t)
+
+	output, err := runImportForeignForTest(t, "--format", "aider", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would import "+strategy.TruncateHash(trailerCommit)+" (aider, openai/gpt-4o)")
+	assert.Contains(t, output, "Would import "+strategy.TruncateHash(prefixCommit)+" (aider): aider: Greet the world")
+	assert.Contains(t, output, "2 of 4 commit(s) would be imported.")
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, committed, "dry run should not write checkpoints")
+
+	output, err = runImportForeignForTest(t, "--format", "aider")
+	require.NoError(t, err)
+	assert.Contains(t, output, "2 of 4 commit(s) imported.")
+
+	committed, err = store.ListCommitted(context.Background())
+	require.NoError(t, err)
+	require.Len(t, committed, 2)
+	byCommit := make(map[string]checkpoint.CommittedInfo)
+	for _, info := range committed {
+		assert.True(t, info.Imported)
+		require.Len(t, info.StampedCommits, 1)
+		byCommit[info.StampedCommits[0]] = info
+	}
+
+	info, ok := byCommit[trailerCommit]
+	require.True(t, ok, "trailer commit should be stamped with its checkpoint")
+	assert.Equal(t, agent.AgentTypeAider, info.Agent)
+	assert.Equal(t, []string{"docs/usage notes.md", "hello.py"}, info.FilesTouched)
+
+	content, err := store.ReadLatestSessionContent(context.Background(), info.CheckpointID)
+	require.NoError(t, err)
+	require.NotNil(t, content.Metadata.Imported)
+	assert.Equal(t, checkpoint.ImportInfo{
+		Format:   "aider",
+		Commit:   trailerCommit,
+		Tool:     "aider",
+		Model:    "openai/gpt-4o",
+		Evidence: "Co-authored-by: aider (openai/gpt-4o) <noreply@aider.chat>",
+	}, *content.Metadata.Imported)
+	assert.Empty(t, content.Transcript)
+
+	// Imported commits are linked now, so a second run finds nothing new
+	output, err = runImportForeignForTest(t, "--format", "aider")
+	require.NoError(t, err)
+	assert.Contains(t, output, "0 of 4 commit(s) imported.")
+}
+
+func TestImportForeign_UnknownFormat(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupImportRepo(t)
+
+	output, err := runImportForeignForTest(t, "--format", "copilot-workspace")
+	require.Error(t, err)
+	assert.Contains(t, output, `unknown import format "copilot-workspace"`)
+}
@@ -136,7 +136,7 @@ func TestManualCommit_Attribution(t *testing.T) {
 	t.Log("Verifying attribution in metadata")
 
 	// Read metadata from entire/checkpoints/v1 branch
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get entire/checkpoints/v1 branch: %v", err)
 	}
@@ -279,7 +279,7 @@ func TestManualCommit_AttributionDeletionOnly(t *testing.T) {
 	// ========================================
 	t.Log("Verifying attribution for deletion-only commit")
 
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get entire/checkpoints/v1 branch: %v", err)
 	}
@@ -521,7 +521,7 @@ func TestManualCommit_AttributionNoDoubleCount(t *testing.T) {
 func getAttributionFromMetadata(t *testing.T, repo *git.Repository, checkpointID id.CheckpointID) *checkpoint.InitialAttribution {
 	t.Helper()
 
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get entire/checkpoints/v1 branch: %v", err)
 	}
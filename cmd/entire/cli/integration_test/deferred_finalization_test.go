@@ -126,7 +126,7 @@ func TestShadow_DeferredTranscriptFinalization(t *testing.T) {
 	t.Logf("All branches after commit: %v", branches)
 
 	// Verify checkpoint exists on metadata branch (provisional)
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Fatal("entire/checkpoints/v1 branch should exist")
 	}
 
@@ -139,7 +139,7 @@ func TestShadow_DeferredTranscriptFinalization(t *testing.T) {
 		t.Errorf("Unexpected path structure: got %s, expected prefix %s", transcriptPath, expectedPrefix)
 	}
 
-	provisionalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	provisionalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		t.Fatalf("Provisional transcript should exist at %s", transcriptPath)
 	}
@@ -175,7 +175,7 @@ func TestShadow_DeferredTranscriptFinalization(t *testing.T) {
 	}
 
 	// Read the finalized transcript
-	finalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	finalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		t.Fatalf("Finalized transcript should exist at %s", transcriptPath)
 	}
@@ -365,7 +365,7 @@ func TestShadow_CarryForward_ActiveSession(t *testing.T) {
 	// indicate a regression in carry-forward cleanup.
 	branchesAfterAll := env.ListBranchesWithPrefix("entire/")
 	for _, b := range branchesAfterAll {
-		if b != paths.MetadataBranchName {
+		if b != paths.MetadataBranchName() {
 			t.Errorf("Unexpected shadow branch after all files committed: %s", b)
 		}
 	}
@@ -576,7 +576,7 @@ func TestShadow_AgentCommitsMidTurn_UserCommitsRemainder(t *testing.T) {
 	// No shadow branches should remain after all files are committed
 	branchesAfter := env.ListBranchesWithPrefix("entire/")
 	for _, b := range branchesAfter {
-		if b != paths.MetadataBranchName {
+		if b != paths.MetadataBranchName() {
 			t.Errorf("Unexpected shadow branch after all files committed: %s", b)
 		}
 	}
@@ -1180,7 +1180,7 @@ func TestShadow_EndedSession_UserCommitsRemainingFiles(t *testing.T) {
 	// No shadow branches should remain
 	branchesAfter := env.ListBranchesWithPrefix("entire/")
 	for _, b := range branchesAfter {
-		if b != paths.MetadataBranchName {
+		if b != paths.MetadataBranchName() {
 			t.Errorf("Unexpected shadow branch after all files committed: %s", b)
 		}
 	}
@@ -1274,7 +1274,7 @@ func TestShadow_DeletedFiles_CheckpointAndCarryForward(t *testing.T) {
 	// doesn't produce full metadata (known limitation).
 	branchesAfter := env.ListBranchesWithPrefix("entire/")
 	for _, b := range branchesAfter {
-		if b != paths.MetadataBranchName {
+		if b != paths.MetadataBranchName() {
 			t.Logf("Shadow branch remaining after commits (may be expected for deleted files): %s", b)
 		}
 	}
@@ -1368,7 +1368,7 @@ func TestShadow_CarryForward_ModifiedExistingFiles(t *testing.T) {
 	// No shadow branches should remain
 	branchesAfter := env.ListBranchesWithPrefix("entire/")
 	for _, b := range branchesAfter {
-		if b != paths.MetadataBranchName {
+		if b != paths.MetadataBranchName() {
 			t.Errorf("Unexpected shadow branch after all files committed: %s", b)
 		}
 	}
@@ -22,10 +22,9 @@ func TestHookRunner_SimulateUserPromptSubmit(t *testing.T) {
 		t.Fatalf("SimulateUserPromptSubmit failed: %v", err)
 	}
 
-	// Verify pre-prompt state was captured (uses entire session ID with date prefix)
-	statePath := filepath.Join(env.RepoDir, ".entire", "tmp", "pre-prompt-"+modelSessionID+".json")
-	if _, err := os.Stat(statePath); os.IsNotExist(err) {
-		t.Error("pre-prompt state file should exist")
+	// Verify pre-prompt state was captured
+	if !env.HookStateExists("pre-prompt", modelSessionID) {
+		t.Error("pre-prompt state should exist")
 	}
 }
 
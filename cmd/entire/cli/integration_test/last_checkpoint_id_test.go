@@ -60,9 +60,9 @@ func TestShadowStrategy_OneCheckpointPerCommit(t *testing.T) {
 
 	// Verify checkpoint exists on entire/checkpoints/v1
 	checkpointPath := paths.CheckpointPath(id.MustCheckpointID(firstCheckpointID))
-	if !env.FileExistsInBranch(paths.MetadataBranchName, checkpointPath+"/"+paths.MetadataFileName) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), checkpointPath+"/"+paths.MetadataFileName) {
 		t.Errorf("Checkpoint metadata should exist at %s on %s branch",
-			checkpointPath, paths.MetadataBranchName)
+			checkpointPath, paths.MetadataBranchName())
 	}
 
 	// Second commit: file B (IDLE session, no carry-forward → no trailer)
@@ -306,7 +306,7 @@ func TestShadowStrategy_ShadowBranchCleanedUpAfterCondensation(t *testing.T) {
 	// Verify data exists on entire/checkpoints/v1
 	checkpointID := env.GetLatestCheckpointID()
 	checkpointPath := paths.CheckpointPath(id.MustCheckpointID(checkpointID))
-	if !env.FileExistsInBranch(paths.MetadataBranchName, checkpointPath+"/"+paths.MetadataFileName) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), checkpointPath+"/"+paths.MetadataFileName) {
 		t.Error("Checkpoint metadata should exist on entire/checkpoints/v1 branch")
 	}
 }
@@ -245,14 +245,14 @@ func TestShadow_FullWorkflow(t *testing.T) {
 	t.Logf("Checkpoint 1 ID: %s", checkpoint1ID)
 
 	// Verify entire/checkpoints/v1 branch exists with checkpoint folder
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Error("entire/checkpoints/v1 branch should exist after condensation")
 	}
 
 	// Verify checkpoint folder contents (check via git show)
 	// Uses sharded path: <id[:2]>/<id[2:]>/metadata.json
 	checkpointPath := ShardedCheckpointPath(checkpoint1ID) + "/metadata.json"
-	if !env.FileExistsInBranch(paths.MetadataBranchName, checkpointPath) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), checkpointPath) {
 		t.Errorf("Checkpoint folder should contain metadata.json at %s", checkpointPath)
 	}
 
@@ -329,7 +329,7 @@ func TestShadow_FullWorkflow(t *testing.T) {
 
 	// Verify second checkpoint folder exists (uses sharded path)
 	checkpoint2Path := ShardedCheckpointPath(checkpoint2ID) + "/metadata.json"
-	if !env.FileExistsInBranch(paths.MetadataBranchName, checkpoint2Path) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), checkpoint2Path) {
 		t.Errorf("Second checkpoint folder should exist at %s", checkpoint2Path)
 	}
 
@@ -669,7 +669,7 @@ func TestShadow_TranscriptCondensation(t *testing.T) {
 	t.Logf("Checkpoint ID: %s", checkpointID)
 
 	// Verify entire/checkpoints/v1 branch exists
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Fatal("entire/checkpoints/v1 branch should exist after condensation")
 	}
 
@@ -688,7 +688,7 @@ func TestShadow_TranscriptCondensation(t *testing.T) {
 
 	// Additionally verify agent field in session metadata
 	sessionMetadataPath := SessionFilePath(checkpointID, paths.MetadataFileName)
-	sessionMetadataContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, sessionMetadataPath)
+	sessionMetadataContent, found := env.ReadFileFromBranch(paths.MetadataBranchName(), sessionMetadataPath)
 	if !found {
 		t.Fatal("session metadata.json should be readable")
 	}
@@ -774,7 +774,7 @@ func TestShadow_FullTranscriptContext(t *testing.T) {
 
 	// Verify first checkpoint has both prompts (uses session file path in numbered subdirectory)
 	promptPath1 := SessionFilePath(checkpoint1ID, "prompt.txt")
-	prompt1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, promptPath1)
+	prompt1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), promptPath1)
 	if !found {
 		t.Errorf("prompt.txt should exist at %s", promptPath1)
 	} else {
@@ -789,7 +789,7 @@ func TestShadow_FullTranscriptContext(t *testing.T) {
 	}
 
 	contextPath1 := SessionFilePath(checkpoint1ID, "context.md")
-	context1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, contextPath1)
+	context1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), contextPath1)
 	if !found {
 		t.Errorf("context.md should exist at %s", contextPath1)
 	} else {
@@ -845,7 +845,7 @@ func TestShadow_FullTranscriptContext(t *testing.T) {
 	// Verify second checkpoint has the FULL transcript (all three prompts)
 	// Session files are now in numbered subdirectories (e.g., 0/prompt.txt)
 	promptPath2 := SessionFilePath(checkpoint2ID, "prompt.txt")
-	prompt2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, promptPath2)
+	prompt2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), promptPath2)
 	if !found {
 		t.Errorf("prompt.txt should exist at %s", promptPath2)
 	} else {
@@ -864,7 +864,7 @@ func TestShadow_FullTranscriptContext(t *testing.T) {
 	}
 
 	contextPath2 := SessionFilePath(checkpoint2ID, "context.md")
-	context2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, contextPath2)
+	context2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), contextPath2)
 	if !found {
 		t.Errorf("context.md should exist at %s", contextPath2)
 	} else {
@@ -994,7 +994,7 @@ func TestShadow_RewindAndCondensation(t *testing.T) {
 
 	// Check prompt.txt (uses session file path in numbered subdirectory)
 	promptPath := SessionFilePath(checkpointID, "prompt.txt")
-	promptContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, promptPath)
+	promptContent, found := env.ReadFileFromBranch(paths.MetadataBranchName(), promptPath)
 	if !found {
 		t.Errorf("prompt.txt should exist at %s", promptPath)
 	} else {
@@ -1013,7 +1013,7 @@ func TestShadow_RewindAndCondensation(t *testing.T) {
 
 	// Check context.md
 	contextPath := SessionFilePath(checkpointID, "context.md")
-	contextContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, contextPath)
+	contextContent, found := env.ReadFileFromBranch(paths.MetadataBranchName(), contextPath)
 	if !found {
 		t.Errorf("context.md should exist at %s", contextPath)
 	} else {
@@ -1274,7 +1274,7 @@ func TestShadow_IntermediateCommitsWithoutPrompts(t *testing.T) {
 	for _, cpID := range []string{checkpoint1ID, checkpoint3ID} {
 		shardedPath := ShardedCheckpointPath(cpID)
 		metadataPath := shardedPath + "/metadata.json"
-		if !env.FileExistsInBranch(paths.MetadataBranchName, metadataPath) {
+		if !env.FileExistsInBranch(paths.MetadataBranchName(), metadataPath) {
 			t.Errorf("Checkpoint %s should have metadata.json at %s", cpID, metadataPath)
 		}
 	}
@@ -1343,7 +1343,7 @@ func TestShadow_FullTranscriptCondensationWithIntermediateCommits(t *testing.T)
 	t.Logf("First commit: %s, checkpoint: %s", commit1Hash[:7], checkpoint1ID)
 
 	// Verify first checkpoint has prompts A and B (session files in numbered subdirectory)
-	prompt1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, SessionFilePath(checkpoint1ID, "prompt.txt"))
+	prompt1Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), SessionFilePath(checkpoint1ID, "prompt.txt"))
 	if !found {
 		t.Fatal("First checkpoint should have prompt.txt")
 	}
@@ -1387,7 +1387,7 @@ func TestShadow_FullTranscriptCondensationWithIntermediateCommits(t *testing.T)
 	t.Log("Phase 5: Verify second checkpoint has full transcript (A, B, and C)")
 
 	// Session files are now in numbered subdirectory (e.g., 0/prompt.txt)
-	prompt2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName, SessionFilePath(checkpoint2ID, "prompt.txt"))
+	prompt2Content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), SessionFilePath(checkpoint2ID, "prompt.txt"))
 	if !found {
 		t.Fatal("Second checkpoint should have prompt.txt")
 	}
@@ -1652,14 +1652,14 @@ func TestShadow_TrailerRemovalSkipsCondensation(t *testing.T) {
 	}
 
 	// Verify condensation happened for second commit
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Fatal("entire/checkpoints/v1 branch should exist after second commit with trailer")
 	}
 
 	// Verify checkpoint exists
 	shardedPath := ShardedCheckpointPath(checkpointID)
 	metadataPath := shardedPath + "/metadata.json"
-	if !env.FileExistsInBranch(paths.MetadataBranchName, metadataPath) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), metadataPath) {
 		t.Errorf("Checkpoint should exist at %s", metadataPath)
 	} else {
 		t.Log("✓ Condensation happened for commit with trailer")
@@ -1701,7 +1701,7 @@ func TestShadow_SessionsBranchCommitTrailers(t *testing.T) {
 	env.GitCommitWithShadowHooks("Add main.go", "main.go")
 
 	// Get the commit message on entire/checkpoints/v1 branch
-	sessionsCommitMsg := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName)
+	sessionsCommitMsg := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName())
 	t.Logf("entire/checkpoints/v1 commit message:\n%s", sessionsCommitMsg)
 
 	// Verify required trailers are present
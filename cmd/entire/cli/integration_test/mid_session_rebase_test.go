@@ -326,8 +326,8 @@ func TestShadow_CommitThenRebaseMidSession(t *testing.T) {
 	}
 
 	// Verify data was condensed to metadata branch
-	if !env.BranchExists(paths.MetadataBranchName) {
-		t.Fatalf("%s branch should exist after condensation", paths.MetadataBranchName)
+	if !env.BranchExists(paths.MetadataBranchName()) {
+		t.Fatalf("%s branch should exist after condensation", paths.MetadataBranchName())
 	}
 
 	// ========================================
@@ -66,7 +66,7 @@ func TestOpenCodeHookFlow(t *testing.T) {
 
 	// 10. Verify condensed data
 	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	_, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	_, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		t.Error("condensed transcript should exist on metadata branch")
 	}
@@ -321,7 +321,7 @@ func TestOpenCodeMidTurnCommit(t *testing.T) {
 
 	// 8. CRITICAL: Verify checkpoint data was written to entire/checkpoints/v1
 	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	_, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	_, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		t.Error("checkpoint transcript should exist on metadata branch after mid-turn commit")
 	}
@@ -173,13 +173,13 @@ func TestShadow_CommitBeforeStop(t *testing.T) {
 	// Immediate condensation should have fired during PostCommit (ACTIVE + GitCommit).
 	// Verify metadata was persisted to entire/checkpoints/v1.
 
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Fatal("entire/checkpoints/v1 branch should exist after TurnEnd condensation")
 	}
 	latestCheckpointID := env.TryGetLatestCheckpointID()
 	if latestCheckpointID != "" {
 		summaryPath := CheckpointSummaryPath(latestCheckpointID)
-		if !env.FileExistsInBranch(paths.MetadataBranchName, summaryPath) {
+		if !env.FileExistsInBranch(paths.MetadataBranchName(), summaryPath) {
 			t.Errorf("Checkpoint metadata should exist at %s", summaryPath)
 		} else {
 			t.Logf("Condensed data exists at checkpoint %s", latestCheckpointID)
@@ -234,12 +234,12 @@ func TestShadow_AmendPreservesTrailer(t *testing.T) {
 	t.Logf("Original commit %s has checkpoint ID: %s", originalCommitHash[:7], originalCheckpointID)
 
 	// Verify condensation happened
-	if !env.BranchExists(paths.MetadataBranchName) {
+	if !env.BranchExists(paths.MetadataBranchName()) {
 		t.Fatal("entire/checkpoints/v1 branch should exist after condensation")
 	}
 
 	// Record the sessions branch state for later comparison
-	sessionsCommitBefore := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName)
+	sessionsCommitBefore := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName())
 	t.Logf("Sessions branch commit before amend:\n%s", sessionsCommitBefore)
 
 	// ========================================
@@ -281,7 +281,7 @@ func TestShadow_AmendPreservesTrailer(t *testing.T) {
 	t.Log("Phase 4: Verify no duplicate condensation")
 
 	// The sessions branch commit should not have changed (no new condensation)
-	sessionsCommitAfter := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName)
+	sessionsCommitAfter := env.GetLatestCommitMessageOnBranch(paths.MetadataBranchName())
 	if sessionsCommitBefore != sessionsCommitAfter {
 		t.Logf("Sessions branch commit changed after amend:\nBefore:\n%s\nAfter:\n%s",
 			sessionsCommitBefore, sessionsCommitAfter)
@@ -294,12 +294,12 @@ func TestShadow_AmendPreservesTrailer(t *testing.T) {
 
 	// Verify the checkpoint data still exists and is accessible
 	summaryPath := CheckpointSummaryPath(originalCheckpointID)
-	if !env.FileExistsInBranch(paths.MetadataBranchName, summaryPath) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), summaryPath) {
 		t.Errorf("Checkpoint metadata should still exist at %s after amend", summaryPath)
 	}
 
 	transcriptPath := SessionFilePath(originalCheckpointID, paths.TranscriptFileName)
-	if !env.FileExistsInBranch(paths.MetadataBranchName, transcriptPath) {
+	if !env.FileExistsInBranch(paths.MetadataBranchName(), transcriptPath) {
 		t.Errorf("Transcript should still exist at %s after amend", transcriptPath)
 	}
 
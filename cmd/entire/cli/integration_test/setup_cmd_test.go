@@ -131,10 +131,9 @@ func TestHooksSilentWhenDisabled(t *testing.T) {
 		t.Fatalf("Hook should exit silently when disabled, got error: %v", err)
 	}
 
-	// Verify no state file was created (hook exited early)
-	statePath := filepath.Join(env.RepoDir, ".entire", "tmp", "pre-prompt-test-session-disabled.json")
-	if _, err := os.Stat(statePath); err == nil {
-		t.Error("pre-prompt state file should NOT exist when disabled")
+	// Verify no state was captured (hook exited early)
+	if env.HookStateExists("pre-prompt", "test-session-disabled") {
+		t.Error("pre-prompt state should NOT exist when disabled")
 	}
 }
 
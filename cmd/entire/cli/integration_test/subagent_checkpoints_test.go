@@ -4,8 +4,6 @@ package integration
 
 import (
 	"encoding/json"
-	"os"
-	"path/filepath"
 	"strings"
 	"testing"
 
@@ -50,10 +48,9 @@ func TestSubagentCheckpoints_FullFlow(t *testing.T) {
 		t.Fatalf("SimulatePreTask failed: %v", err)
 	}
 
-	// Verify pre-task file was created
-	preTaskFile := filepath.Join(env.RepoDir, ".entire", "tmp", "pre-task-"+taskToolUseID+".json")
-	if _, err := os.Stat(preTaskFile); os.IsNotExist(err) {
-		t.Error("pre-task file should exist after SimulatePreTask")
+	// Verify pre-task state was captured
+	if !env.HookStateExists("pre-task", taskToolUseID) {
+		t.Error("pre-task state should exist after SimulatePreTask")
 	}
 
 	// Step 2: PostTodo - simulate TodoWrite calls with file changes between them
@@ -118,9 +115,9 @@ func TestSubagentCheckpoints_FullFlow(t *testing.T) {
 		t.Fatalf("SimulatePostTask failed: %v", err)
 	}
 
-	// Verify pre-task file is cleaned up
-	if _, err := os.Stat(preTaskFile); !os.IsNotExist(err) {
-		t.Error("Pre-task file should be removed after PostTask")
+	// Verify pre-task state is cleaned up
+	if env.HookStateExists("pre-task", taskToolUseID) {
+		t.Error("Pre-task state should be removed after PostTask")
 	}
 
 	// Verify checkpoints are stored in final location (strategy-specific)
@@ -199,10 +196,9 @@ func TestSubagentCheckpoints_PostTaskNoFileChanges(t *testing.T) {
 		t.Fatalf("SimulatePreTask failed: %v", err)
 	}
 
-	// Verify pre-task file was created
-	preTaskFile := filepath.Join(env.RepoDir, ".entire", "tmp", "pre-task-"+taskToolUseID+".json")
-	if _, err := os.Stat(preTaskFile); os.IsNotExist(err) {
-		t.Fatal("pre-task file should exist after SimulatePreTask")
+	// Verify pre-task state was captured
+	if !env.HookStateExists("pre-task", taskToolUseID) {
+		t.Fatal("pre-task state should exist after SimulatePreTask")
 	}
 
 	// Get git log before PostTask
@@ -227,9 +223,9 @@ func TestSubagentCheckpoints_PostTaskNoFileChanges(t *testing.T) {
 		t.Errorf("Expected no new commits when no file changes, before=%d after=%d", len(beforeCommits), len(afterCommits))
 	}
 
-	// Verify pre-task file is cleaned up even though no checkpoint was created
-	if _, err := os.Stat(preTaskFile); !os.IsNotExist(err) {
-		t.Error("Pre-task file should be removed after PostTask even with no file changes")
+	// Verify pre-task state is cleaned up even though no checkpoint was created
+	if env.HookStateExists("pre-task", taskToolUseID) {
+		t.Error("Pre-task state should be removed after PostTask even with no file changes")
 	}
 }
 
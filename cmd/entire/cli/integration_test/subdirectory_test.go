@@ -62,16 +62,9 @@ func TestSubdirectory_EntireDirCreatedAtRepoRoot(t *testing.T) {
 		t.Errorf(".entire/tmp should exist at repo root, but it doesn't")
 	}
 
-	// Verify the pre-prompt state file was created at repo root
-	stateFile := filepath.Join(env.RepoDir, ".entire", "tmp", "pre-prompt-"+sessionID+".json")
-	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
-		t.Errorf("pre-prompt state file should exist at %s, but it doesn't", stateFile)
-	}
-
-	// Also verify the state file was NOT created in the subdirectory
-	subdirStateFile := filepath.Join(subdirPath, ".entire", "tmp", "pre-prompt-"+sessionID+".json")
-	if _, err := os.Stat(subdirStateFile); !os.IsNotExist(err) {
-		t.Errorf("pre-prompt state file should NOT exist in subdirectory at %s", subdirStateFile)
+	// Verify the pre-prompt state was stored in the repository's git dir
+	if !env.HookStateExists("pre-prompt", sessionID) {
+		t.Errorf("pre-prompt state should exist in the repository's hook state store")
 	}
 }
 
@@ -19,6 +19,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/kvstore"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
@@ -1196,10 +1197,10 @@ func (env *TestEnv) GetLatestCheckpointID() string {
 	}
 
 	// Get the entire/checkpoints/v1 branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
-		env.T.Fatalf("failed to get %s branch: %v", paths.MetadataBranchName, err)
+		env.T.Fatalf("failed to get %s branch: %v", paths.MetadataBranchName(), err)
 	}
 
 	commit, err := repo.CommitObject(ref.Hash())
@@ -1217,7 +1218,7 @@ func (env *TestEnv) GetLatestCheckpointID() string {
 	}
 
 	env.T.Fatalf("could not find checkpoint ID in %s branch commit message:\n%s",
-		paths.MetadataBranchName, commit.Message)
+		paths.MetadataBranchName(), commit.Message)
 	return ""
 }
 
@@ -1233,7 +1234,7 @@ func (env *TestEnv) TryGetLatestCheckpointID() string {
 	}
 
 	// Get the entire/checkpoints/v1 branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return ""
@@ -1394,7 +1395,7 @@ func (env *TestEnv) validateCheckpointSummary(v CheckpointValidation) {
 	env.T.Helper()
 
 	summaryPath := CheckpointSummaryPath(v.CheckpointID)
-	content, found := env.ReadFileFromBranch(paths.MetadataBranchName, summaryPath)
+	content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), summaryPath)
 	if !found {
 		env.T.Fatalf("CheckpointSummary not found at %s", summaryPath)
 	}
@@ -1443,7 +1444,7 @@ func (env *TestEnv) validateSessionMetadata(v CheckpointValidation) {
 	env.T.Helper()
 
 	metadataPath := SessionMetadataPath(v.CheckpointID)
-	content, found := env.ReadFileFromBranch(paths.MetadataBranchName, metadataPath)
+	content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), metadataPath)
 	if !found {
 		env.T.Fatalf("Session metadata not found at %s", metadataPath)
 	}
@@ -1500,7 +1501,7 @@ func (env *TestEnv) validateTranscriptJSONL(checkpointID string, expectedContent
 	env.T.Helper()
 
 	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	content, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		env.T.Fatalf("Transcript not found at %s", transcriptPath)
 	}
@@ -1544,14 +1545,14 @@ func (env *TestEnv) validateContentHash(checkpointID string) {
 
 	// Read transcript
 	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	transcript, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	transcript, found := env.ReadFileFromBranch(paths.MetadataBranchName(), transcriptPath)
 	if !found {
 		env.T.Fatalf("Transcript not found at %s", transcriptPath)
 	}
 
 	// Read content hash
 	hashPath := SessionFilePath(checkpointID, "content_hash.txt")
-	storedHash, found := env.ReadFileFromBranch(paths.MetadataBranchName, hashPath)
+	storedHash, found := env.ReadFileFromBranch(paths.MetadataBranchName(), hashPath)
 	if !found {
 		env.T.Fatalf("Content hash not found at %s", hashPath)
 	}
@@ -1571,7 +1572,7 @@ func (env *TestEnv) validatePromptContent(checkpointID string, expectedPrompts [
 	env.T.Helper()
 
 	promptPath := SessionFilePath(checkpointID, paths.PromptFileName)
-	content, found := env.ReadFileFromBranch(paths.MetadataBranchName, promptPath)
+	content, found := env.ReadFileFromBranch(paths.MetadataBranchName(), promptPath)
 	if !found {
 		env.T.Fatalf("Prompt file not found at %s", promptPath)
 	}
@@ -1602,3 +1603,16 @@ func findModuleRoot() string {
 		dir = parent
 	}
 }
+
+// HookStateExists reports whether the hook state store in the repository's
+// git dir holds an entry for key in bucket ("pre-prompt", keyed by session ID,
+// or "pre-task", keyed by tool use ID).
+func (env *TestEnv) HookStateExists(bucket, key string) bool {
+	env.T.Helper()
+	store := kvstore.Open(filepath.Join(env.RepoDir, ".git", "entire-state"), kvstore.Options{})
+	value, err := kvstore.NewBucket[json.RawMessage](store, bucket).Get(key)
+	if err != nil {
+		env.T.Fatalf("failed to read %s state for %s: %v", bucket, key, err)
+	}
+	return value != nil
+}
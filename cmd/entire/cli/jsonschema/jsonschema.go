@@ -0,0 +1,240 @@
+// Package jsonschema generates JSON Schemas (draft 2020-12) from Go types by
+// reflection, following encoding/json's rules for field names, omitempty,
+// embedded structs and "-". Schemas generated this way cannot drift from the
+// structs Entire actually reads and writes.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords the generator emits are modeled.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// never makes the schema match nothing. It is encoded as false.
+	never bool
+}
+
+// False is the schema that matches nothing, used as
+// "additionalProperties": false for closed objects.
+var False = &Schema{never: true}
+
+// MarshalJSON encodes the schema, writing False as false.
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s)) //nolint:wrapcheck // plain re-encoding of the same value
+}
+
+// Types is the "type" keyword: one JSON type, or several when a value may
+// also be null.
+type Types []string
+
+// MarshalJSON writes a single type as a string and several as an array.
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0]) //nolint:wrapcheck // encoding a string cannot fail
+	}
+	return json.Marshal([]string(t)) //nolint:wrapcheck // encoding strings cannot fail
+}
+
+// Options control schema generation.
+type Options struct {
+	// Title and Description annotate the root schema.
+	Title       string
+	Description string
+	// Closed rejects properties not declared by the structs, for files that
+	// are decoded with unknown fields disallowed.
+	Closed bool
+	// AllOptional marks no property as required, for files that are read
+	// with defaults for anything missing.
+	AllOptional bool
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// For returns the schema for T. Named struct types other than T itself are
+// placed in $defs and referenced, which also handles recursive types.
+func For[T any](opts Options) *Schema {
+	return Generate(reflect.TypeFor[T](), opts)
+}
+
+// Generate returns the schema for t. See For.
+func Generate(t reflect.Type, opts Options) *Schema {
+	g := &generator{closed: opts.Closed, allOptional: opts.AllOptional, defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	root := g.schemaFor(t, true)
+	root.Schema = Draft
+	root.Title = opts.Title
+	root.Description = opts.Description
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+type generator struct {
+	closed      bool
+	allOptional bool
+	defs        map[string]*Schema
+	names       map[reflect.Type]string
+}
+
+func (g *generator) schemaFor(t reflect.Type, root bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.String && (t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType)):
+		// Custom encodings have no reflectable shape.
+		return &Schema{}
+	case t.Kind() != reflect.String && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: Types{"string"}, Format: "byte"} // encoding/json base64-encodes []byte
+		}
+		return &Schema{Type: Types{"array"}, Items: g.schemaFor(t.Elem(), false)}
+	case reflect.Map:
+		return &Schema{Type: Types{"object"}, AdditionalProperties: g.schemaFor(t.Elem(), false)}
+	case reflect.Struct:
+		if root || t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	default:
+		// Interfaces and anything else: any JSON value.
+		return &Schema{}
+	}
+}
+
+// ref returns a reference to the named struct t, adding it to $defs on first use.
+func (g *generator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.defName(t)
+		g.names[t] = name
+		g.defs[name] = &Schema{} // placeholder, so recursive references terminate
+		g.defs[name] = g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// defName names t in $defs, qualifying it with its package when two types
+// share a name.
+func (g *generator) defName(t reflect.Type) string {
+	if _, taken := g.defs[t.Name()]; !taken {
+		return t.Name()
+	}
+	return strings.ReplaceAll(t.String(), ".", "_")
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	if g.closed {
+		s.AdditionalProperties = False
+	}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds t's JSON fields to s, promoting the fields of embedded
+// structs as encoding/json does.
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			g.addFields(s, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		var prop *Schema
+		if hasOption(opts, "string") {
+			prop = &Schema{Type: Types{"string"}}
+		} else {
+			prop = g.schemaFor(field.Type, false)
+		}
+		s.Properties[name] = prop
+
+		if hasOption(opts, "omitempty") || hasOption(opts, "omitzero") || field.Type.Kind() == reflect.Pointer {
+			continue
+		}
+		if kind := field.Type.Kind(); kind == reflect.Slice || kind == reflect.Map {
+			prop.Type = append(prop.Type, "null") // encoding/json writes nil as null
+		}
+		if !g.allOptional {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	Name     string  `json:"name"`
+	Children []*node `json:"children,omitempty"`
+}
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type sample struct {
+	base
+	Count    int               `json:"count"`
+	Ratio    float64           `json:"ratio,omitempty"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	When     time.Time         `json:"when"`
+	Raw      json.RawMessage   `json:"raw,omitempty"`
+	Data     []byte            `json:"data,omitempty"`
+	Tree     *node             `json:"tree,omitempty"`
+	Size     int64             `json:"size,string"`
+	Skipped  string            `json:"-"`
+	Untagged bool
+}
+
+func TestFor(t *testing.T) {
+	t.Parallel()
+
+	s := For[sample](Options{Title: "Sample"})
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+
+	assert.Equal(t, Draft, got["$schema"])
+	assert.Equal(t, "Sample", got["title"])
+	assert.Equal(t, "object", got["type"])
+	assert.NotContains(t, got, "additionalProperties")
+
+	props, ok := got["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.Len(t, props, 11, "embedded fields are promoted and '-' fields are skipped")
+	assert.Equal(t, map[string]any{"type": "string"}, props["id"])
+	assert.Equal(t, map[string]any{"type": "integer"}, props["count"])
+	assert.Equal(t, map[string]any{"type": "number"}, props["ratio"])
+	assert.Equal(t, map[string]any{"type": []any{"array", "null"}, "items": map[string]any{"type": "string"}}, props["tags"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, props["labels"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["when"])
+	assert.Equal(t, map[string]any{}, props["raw"])
+	assert.Equal(t, map[string]any{"type": "string", "format": "byte"}, props["data"])
+	assert.Equal(t, map[string]any{"$ref": "#/$defs/node"}, props["tree"])
+	assert.Equal(t, map[string]any{"type": "string"}, props["size"])
+	assert.Equal(t, map[string]any{"type": "boolean"}, props["Untagged"])
+
+	assert.ElementsMatch(t, []any{"id", "count", "tags", "when", "size", "Untagged"}, got["required"])
+
+	defs, ok := got["$defs"].(map[string]any)
+	require.True(t, ok)
+	nodeDef, ok := defs["node"].(map[string]any)
+	require.True(t, ok)
+	nodeProps, ok := nodeDef["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/node"}}, nodeProps["children"],
+		"recursive types refer back to their definition")
+}
+
+func TestFor_ClosedAllOptional(t *testing.T) {
+	t.Parallel()
+
+	s := For[sample](Options{Closed: true, AllOptional: true})
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, false, got["additionalProperties"])
+	assert.NotContains(t, got, "required")
+
+	defs, ok := got["$defs"].(map[string]any)
+	require.True(t, ok)
+	nodeDef, ok := defs["node"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, false, nodeDef["additionalProperties"], "nested objects are closed too")
+}
@@ -18,3 +18,21 @@ func MarshalIndentWithNewline(v any, prefix, indent string) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// MarshalCanonical is like MarshalIndentWithNewline with two-space indentation,
+// but sorts object keys at every level, struct fields included. Numbers keep
+// encoding/json's shortest round-trip form, so equal values always serialize
+// to identical bytes.
+func MarshalCanonical(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding JSON: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("decoding JSON: %w", err)
+	}
+	return MarshalIndentWithNewline(generic, "", "  ")
+}
@@ -0,0 +1,341 @@
+// Package kvstore is a small key-value store for short-lived hook state.
+//
+// A store is a directory with one subdirectory per bucket and one JSON file
+// per key. Writes go to a temporary file that is renamed into place, so
+// readers never see a partial value, and every mutation holds the store's
+// lock, so concurrent hooks (for example, parallel subagents) cannot clobber
+// each other. Update runs a read-modify-write under the same lock. The lock
+// is a filelock, released when its holder exits, so a crashed hook never
+// leaves the store locked and a live one is never taken over.
+//
+// Entries older than the store's TTL are treated as missing and are removed
+// the next time their bucket is written.
+package kvstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/filelock"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+)
+
+const (
+	lockFileName = ".lock"
+	fileSuffix   = ".json"
+
+	defaultLockWait = 2 * time.Second
+)
+
+// ErrLocked is returned when the store's lock cannot be taken in time.
+var ErrLocked = errors.New("kvstore: timed out waiting for lock")
+
+// Options configure a Store. Zero values select the defaults.
+type Options struct {
+	// TTL is how long an entry stays valid after it was written.
+	// Zero means entries never expire.
+	TTL time.Duration
+	// LockWait is how long a mutation waits for the lock (default 2s).
+	LockWait time.Duration
+	// Clock is the time source for TTLs. nil = clock.System.
+	Clock clock.Clock
+}
+
+// Store is a directory-backed key-value store. The directory is created on
+// first write.
+type Store struct {
+	dir  string
+	opts Options
+}
+
+// Open returns a store rooted at dir. It does not touch the filesystem.
+func Open(dir string, opts Options) *Store {
+	if opts.LockWait == 0 {
+		opts.LockWait = defaultLockWait
+	}
+	return &Store{dir: dir, opts: opts}
+}
+
+// Dir returns the store's root directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Entry describes a stored key.
+type Entry struct {
+	Key       string
+	WrittenAt time.Time
+}
+
+// record is the on-disk form of a value.
+type record struct {
+	WrittenAt time.Time       `json:"written_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Bucket is a typed view of one bucket in a store.
+type Bucket[T any] struct {
+	store *Store
+	name  string
+}
+
+// NewBucket returns the bucket called name in store, holding values of type T.
+func NewBucket[T any](store *Store, name string) Bucket[T] {
+	return Bucket[T]{store: store, name: name}
+}
+
+// Get returns the value stored under key, or nil if there is none or it has
+// expired.
+func (b Bucket[T]) Get(key string) (*T, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	rec, err := b.read(key)
+	if err != nil || rec == nil {
+		return nil, err
+	}
+	var value T
+	if err := json.Unmarshal(rec.Value, &value); err != nil {
+		return nil, fmt.Errorf("kvstore: failed to decode %s/%s: %w", b.name, key, err)
+	}
+	return &value, nil
+}
+
+// Put stores value under key, replacing any previous value.
+func (b Bucket[T]) Put(key string, value T) error {
+	return b.Update(key, func(*T) (*T, error) { return &value, nil })
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (b Bucket[T]) Delete(key string) error {
+	return b.Update(key, func(*T) (*T, error) { return nil, nil })
+}
+
+// Update reads the current value of key (nil if missing or expired), calls fn,
+// and stores what fn returns, all while holding the store's lock. Returning
+// nil deletes the key; returning an error leaves it unchanged.
+func (b Bucket[T]) Update(key string, fn func(current *T) (*T, error)) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	unlock, err := b.store.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current, err := b.Get(key)
+	if err != nil {
+		return err
+	}
+	next, err := fn(current)
+	if err != nil {
+		return err
+	}
+	if next == nil {
+		if err := os.Remove(b.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("kvstore: failed to delete %s/%s: %w", b.name, key, err)
+		}
+		return nil
+	}
+	if err := b.write(key, *next); err != nil {
+		return err
+	}
+	b.pruneLocked()
+	return nil
+}
+
+// Keys returns the bucket's unexpired entries, most recently written first.
+func (b Bucket[T]) Keys() ([]Entry, error) {
+	dirEntries, err := os.ReadDir(b.dir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kvstore: failed to list %s: %w", b.name, err)
+	}
+	var entries []Entry
+	for _, de := range dirEntries {
+		key, ok := strings.CutSuffix(de.Name(), fileSuffix)
+		if de.IsDir() || !ok || validateKey(key) != nil {
+			continue
+		}
+		rec, readErr := b.read(key)
+		if readErr != nil || rec == nil {
+			continue
+		}
+		entries = append(entries, Entry{Key: key, WrittenAt: rec.WrittenAt})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].WrittenAt.After(entries[j].WrittenAt)
+	})
+	return entries, nil
+}
+
+func (b Bucket[T]) dir() string {
+	return filepath.Join(b.store.dir, b.name)
+}
+
+func (b Bucket[T]) path(key string) string {
+	return filepath.Join(b.dir(), key+fileSuffix)
+}
+
+// read returns the record for key, or nil if it is missing or expired.
+func (b Bucket[T]) read(key string) (*record, error) {
+	data, err := os.ReadFile(b.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil // missing key is not an error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kvstore: failed to read %s/%s: %w", b.name, key, err)
+	}
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("kvstore: failed to decode %s/%s: %w", b.name, key, err)
+	}
+	if b.store.expired(rec.WrittenAt) {
+		return nil, nil //nolint:nilnil // expired key reads as missing
+	}
+	return &rec, nil
+}
+
+// write stores value atomically. Must be called with the lock held.
+func (b Bucket[T]) write(key string, value T) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("kvstore: failed to encode %s/%s: %w", b.name, key, err)
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(record{
+		WrittenAt: clock.OrSystem(b.store.opts.Clock).Now().UTC(),
+		Value:     raw,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("kvstore: failed to encode %s/%s: %w", b.name, key, err)
+	}
+	if err := os.MkdirAll(b.dir(), 0o750); err != nil {
+		return fmt.Errorf("kvstore: failed to create bucket %s: %w", b.name, err)
+	}
+	tmp, err := os.CreateTemp(b.dir(), "."+key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("kvstore: failed to write %s/%s: %w", b.name, key, err)
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmp.Name(), b.path(key))
+	}
+	if writeErr != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("kvstore: failed to write %s/%s: %w", b.name, key, writeErr)
+	}
+	return nil
+}
+
+// Expired returns the bucket's expired entries, oldest first. They read as
+// missing and are removed on the bucket's next write, or by Prune.
+func (b Bucket[T]) Expired() ([]Entry, error) {
+	if b.store.opts.TTL == 0 {
+		return nil, nil
+	}
+	dirEntries, err := os.ReadDir(b.dir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kvstore: failed to list %s: %w", b.name, err)
+	}
+	var entries []Entry
+	for _, de := range dirEntries {
+		key, ok := strings.CutSuffix(de.Name(), fileSuffix)
+		if de.IsDir() || !ok || validateKey(key) != nil {
+			continue
+		}
+		data, readErr := os.ReadFile(b.path(key))
+		if readErr != nil {
+			continue
+		}
+		var rec record
+		if json.Unmarshal(data, &rec) == nil && b.store.expired(rec.WrittenAt) {
+			entries = append(entries, Entry{Key: key, WrittenAt: rec.WrittenAt})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].WrittenAt.Before(entries[j].WrittenAt)
+	})
+	return entries, nil
+}
+
+// Prune removes the bucket's expired entries and returns them. The store is
+// not touched if nothing has expired.
+func (b Bucket[T]) Prune() ([]Entry, error) {
+	if expired, err := b.Expired(); err != nil || len(expired) == 0 {
+		return nil, err
+	}
+	unlock, err := b.store.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	expired, err := b.Expired()
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]Entry, 0, len(expired))
+	for _, entry := range expired {
+		if err := os.Remove(b.path(entry.Key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, fmt.Errorf("kvstore: failed to delete %s/%s: %w", b.name, entry.Key, err)
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// pruneLocked removes the bucket's expired entries. Must be called with the
+// lock held. Failures are ignored; expired entries already read as missing.
+func (b Bucket[T]) pruneLocked() {
+	expired, err := b.Expired()
+	if err != nil {
+		return
+	}
+	for _, entry := range expired {
+		_ = os.Remove(b.path(entry.Key))
+	}
+}
+
+func (s *Store) expired(writtenAt time.Time) bool {
+	return s.opts.TTL > 0 && clock.OrSystem(s.opts.Clock).Now().Sub(writtenAt) > s.opts.TTL
+}
+
+// lock takes the store's lock, waiting up to LockWait.
+func (s *Store) lock() (unlock func(), err error) {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return nil, fmt.Errorf("kvstore: failed to create store: %w", err)
+	}
+	lock, err := filelock.Acquire(filepath.Join(s.dir, lockFileName), s.opts.LockWait)
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("kvstore: failed to take lock: %w", err)
+	}
+	return lock.Release, nil
+}
+
+// validateKey rejects keys that cannot be used as a file name.
+func validateKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return fmt.Errorf("kvstore: invalid key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package kvstore
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValue struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestBucket_PutGetDelete(t *testing.T) {
+	t.Parallel()
+	store := Open(filepath.Join(t.TempDir(), "state"), Options{})
+	bucket := NewBucket[testValue](store, "things")
+
+	got, err := bucket.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, got, "missing key should read as nil")
+
+	require.NoError(t, bucket.Put("a", testValue{Name: "first", Count: 1}))
+	got, err = bucket.Get("a")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, testValue{Name: "first", Count: 1}, *got)
+
+	require.NoError(t, bucket.Delete("a"))
+	got, err = bucket.Get("a")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	require.NoError(t, bucket.Delete("a"), "deleting a missing key is not an error")
+
+	_, err = os.Stat(filepath.Join(store.Dir(), lockFileName))
+	assert.True(t, os.IsNotExist(err), "lock should be released")
+}
+
+func TestBucket_TTL(t *testing.T) {
+	t.Parallel()
+	fake := clock.NewFake(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	store := Open(t.TempDir(), Options{TTL: time.Hour, Clock: fake})
+	bucket := NewBucket[testValue](store, "things")
+
+	require.NoError(t, bucket.Put("old", testValue{Name: "old"}))
+	fake.Advance(2 * time.Hour)
+
+	got, err := bucket.Get("old")
+	require.NoError(t, err)
+	assert.Nil(t, got, "expired key should read as missing")
+
+	require.NoError(t, bucket.Put("new", testValue{Name: "new"}))
+	_, err = os.Stat(filepath.Join(store.Dir(), "things", "old"+fileSuffix))
+	assert.True(t, os.IsNotExist(err), "expired entry should be pruned on write")
+
+	keys, err := bucket.Keys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "new", keys[0].Key)
+}
+
+func TestBucket_ExpiredAndPrune(t *testing.T) {
+	t.Parallel()
+	fake := clock.NewFake(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	store := Open(t.TempDir(), Options{TTL: time.Hour, Clock: fake})
+	bucket := NewBucket[testValue](store, "things")
+
+	require.NoError(t, bucket.Put("old", testValue{Name: "old"}))
+	fake.Advance(2 * time.Hour)
+	// Written directly so the put doesn't prune "old" first
+	require.NoError(t, bucket.write("new", testValue{Name: "new"}))
+
+	expired, err := bucket.Expired()
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "old", expired[0].Key)
+
+	removed, err := bucket.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, expired, removed)
+	_, err = os.Stat(filepath.Join(store.Dir(), "things", "old"+fileSuffix))
+	assert.True(t, os.IsNotExist(err), "expired entry should be removed")
+
+	keys, err := bucket.Keys()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "new", keys[0].Key)
+
+	removed, err = NewBucket[testValue](Open(t.TempDir(), Options{TTL: time.Hour}), "missing").Prune()
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestBucket_KeysNewestFirst(t *testing.T) {
+	t.Parallel()
+	fake := clock.NewFake(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC))
+	bucket := NewBucket[testValue](Open(t.TempDir(), Options{Clock: fake}), "things")
+
+	keys, err := bucket.Keys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	for _, key := range []string{"b", "c", "a"} {
+		require.NoError(t, bucket.Put(key, testValue{Name: key}))
+		fake.Advance(time.Minute)
+	}
+
+	keys, err = bucket.Keys()
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	assert.Equal(t, []string{"a", "c", "b"}, []string{keys[0].Key, keys[1].Key, keys[2].Key})
+}
+
+func TestBucket_InvalidKeys(t *testing.T) {
+	t.Parallel()
+	bucket := NewBucket[testValue](Open(t.TempDir(), Options{}), "things")
+
+	for _, key := range []string{"", ".", "..", ".hidden", "a/b", `a\b`, "../escape"} {
+		require.Error(t, bucket.Put(key, testValue{}), "key %q", key)
+		_, err := bucket.Get(key)
+		require.Error(t, err, "key %q", key)
+	}
+}
+
+func TestBucket_ConcurrentUpdate(t *testing.T) {
+	t.Parallel()
+	bucket := NewBucket[testValue](Open(t.TempDir(), Options{LockWait: 10 * time.Second}), "things")
+
+	const workers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- bucket.Update("counter", func(current *testValue) (*testValue, error) {
+				next := testValue{Name: "counter"}
+				if current != nil {
+					next.Count = current.Count
+				}
+				next.Count++
+				return &next, nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	got, err := bucket.Get("counter")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, workers, got.Count, "no update should be lost")
+}
+
+func TestStore_LockTimeoutAndLeftoverLockFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	store := Open(dir, Options{LockWait: 50 * time.Millisecond})
+	bucket := NewBucket[testValue](store, "things")
+
+	unlock, err := store.lock()
+	require.NoError(t, err)
+	require.ErrorIs(t, bucket.Put("a", testValue{}), ErrLocked, "a held lock is never taken over")
+	unlock()
+
+	// A lock file left behind by a crashed hook is not locked by anyone
+	require.NoError(t, os.WriteFile(filepath.Join(dir, lockFileName), nil, 0o600))
+	require.NoError(t, bucket.Put("a", testValue{Name: "a"}))
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Thresholds above which `entire enable` treats a repository as large.
+// Shadow snapshots and `git status` in hooks scale with the size of the
+// working tree, so repositories past these sizes benefit from tuning.
+const (
+	largeRepoFileThreshold  = 100_000
+	largeRepoBytesThreshold = 2 << 30 // 2 GiB
+)
+
+// repoSize describes the size of the tree at HEAD.
+type repoSize struct {
+	Files int
+	Bytes int64
+}
+
+// isLarge reports whether the repository exceeds either large-repo threshold.
+func (r repoSize) isLarge() bool {
+	return r.Files >= largeRepoFileThreshold || r.Bytes >= largeRepoBytesThreshold
+}
+
+// gitConfigRecommendation is a git setting that speeds up hooks in large repositories.
+type gitConfigRecommendation struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+// measureRepoSize counts the files and total blob size of the tree at HEAD.
+// Returns a zero size for repositories without commits.
+func measureRepoSize(ctx context.Context, repoRoot string) (repoSize, error) {
+	cmd := exec.CommandContext(ctx, "git", "ls-tree", "-r", "-l", "-z", "HEAD")
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return repoSize{}, nil //nolint:nilerr // no HEAD yet means nothing to measure
+	}
+
+	var size repoSize
+	for _, record := range bytes.Split(output, []byte{0}) {
+		// Format: <mode> SP <type> SP <object> SP+ <size> TAB <path>
+		meta, _, ok := bytes.Cut(record, []byte{'\t'})
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(string(meta))
+		if len(fields) != 4 || fields[1] != "blob" {
+			continue
+		}
+		size.Files++
+		if n, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+			size.Bytes += n
+		}
+	}
+	return size, nil
+}
+
+// largeRepoRecommendations returns the recommended git settings that are not
+// already applied. current maps config keys to their current values.
+func largeRepoRecommendations(current map[string]string, goos string) []gitConfigRecommendation {
+	candidates := []gitConfigRecommendation{
+		{Key: "feature.manyFiles", Value: "true", Reason: "faster index and untracked-file scans"},
+		{Key: "core.untrackedCache", Value: "true", Reason: "caches untracked-file scans used by every checkpoint"},
+	}
+	// The built-in filesystem monitor is only available on macOS and Windows.
+	if goos == "darwin" || goos == "windows" {
+		candidates = append(candidates, gitConfigRecommendation{
+			Key: "core.fsmonitor", Value: "true", Reason: "avoids rescanning the worktree on every hook",
+		})
+	}
+
+	var missing []gitConfigRecommendation
+	for _, rec := range candidates {
+		if current[rec.Key] != rec.Value {
+			missing = append(missing, rec)
+		}
+	}
+	return missing
+}
+
+// readGitConfig returns the current value of each key, or "" if unset.
+func readGitConfig(ctx context.Context, repoRoot string, keys []string) map[string]string {
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		cmd := exec.CommandContext(ctx, "git", "config", "--get", key)
+		cmd.Dir = repoRoot
+		if output, err := cmd.Output(); err == nil {
+			values[key] = strings.TrimSpace(string(output))
+		}
+	}
+	return values
+}
+
+// checkLargeRepository warns when the repository is large enough that
+// checkpoints and hooks may be slow, and offers to apply recommended git
+// settings. When interactive is false the recommendations are only printed.
+// Failures are never fatal: this is advice, not a prerequisite.
+func checkLargeRepository(w io.Writer, interactive bool) {
+	ctx := context.Background()
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return
+	}
+
+	size, err := measureRepoSize(ctx, repoRoot)
+	if err != nil || !size.isLarge() {
+		return
+	}
+
+	fmt.Fprintf(w, "Note: This is a large repository (%d files, %s at HEAD).\n", size.Files, formatBytes(size.Bytes))
+	fmt.Fprintln(w, "Checkpoints snapshot changed and untracked files, so keep build outputs and")
+	fmt.Fprintln(w, "other large generated files in .gitignore to keep hooks fast.")
+
+	keys := []string{"feature.manyFiles", "core.untrackedCache", "core.fsmonitor"}
+	recs := largeRepoRecommendations(readGitConfig(ctx, repoRoot, keys), runtime.GOOS)
+	if len(recs) == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintln(w, "Recommended git settings for this repository:")
+	for _, rec := range recs {
+		fmt.Fprintf(w, "  git config %s %s   # %s\n", rec.Key, rec.Value, rec.Reason)
+	}
+
+	if !interactive {
+		fmt.Fprintln(w)
+		return
+	}
+
+	apply := true
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Apply the recommended git settings?").
+				Affirmative("Yes").
+				Negative("No").
+				Value(&apply),
+		),
+	)
+	if err := form.Run(); err != nil || !apply {
+		fmt.Fprintln(w)
+		return
+	}
+
+	for _, rec := range recs {
+		cmd := exec.CommandContext(ctx, "git", "config", "--local", rec.Key, rec.Value) //nolint:gosec // keys and values are constants
+		cmd.Dir = repoRoot
+		if output, err := cmd.CombinedOutput(); err != nil {
+			fmt.Fprintf(w, "Warning: failed to set %s: %s\n", rec.Key, strings.TrimSpace(string(output)))
+			continue
+		}
+		fmt.Fprintf(w, "✓ Set %s=%s\n", rec.Key, rec.Value)
+	}
+	fmt.Fprintln(w)
+}
+
+// formatBytes formats a byte count using binary units, e.g. "1.5 GiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureRepoSize(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+
+	// No commits yet: nothing to measure
+	size, err := measureRepoSize(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, repoSize{}, size)
+
+	testutil.WriteFile(t, tmpDir, "a.txt", "hello")
+	testutil.WriteFile(t, tmpDir, "dir/b.txt", "world!")
+	testutil.GitAdd(t, tmpDir, "a.txt", "dir/b.txt")
+	testutil.GitCommit(t, tmpDir, "initial")
+
+	size, err = measureRepoSize(context.Background(), tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, repoSize{Files: 2, Bytes: 11}, size)
+	assert.False(t, size.isLarge())
+}
+
+func TestRepoSize_IsLarge(t *testing.T) {
+	t.Parallel()
+	assert.True(t, repoSize{Files: largeRepoFileThreshold}.isLarge())
+	assert.True(t, repoSize{Files: 10, Bytes: largeRepoBytesThreshold}.isLarge())
+	assert.False(t, repoSize{Files: 10, Bytes: 1024}.isLarge())
+}
+
+func TestLargeRepoRecommendations(t *testing.T) {
+	t.Parallel()
+
+	recs := largeRepoRecommendations(map[string]string{"feature.manyFiles": "true"}, "linux")
+	keys := make([]string, 0, len(recs))
+	for _, rec := range recs {
+		keys = append(keys, rec.Key)
+	}
+	assert.Equal(t, []string{"core.untrackedCache"}, keys, "applied settings and unsupported fsmonitor should be omitted")
+
+	recs = largeRepoRecommendations(map[string]string{}, "darwin")
+	assert.Len(t, recs, 3)
+}
+
+func TestFormatBytes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "2.0 GiB", formatBytes(2<<30))
+}
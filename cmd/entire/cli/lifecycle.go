@@ -18,11 +18,14 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
+	"github.com/entireio/cli/cmd/entire/cli/transcriptcache"
 	"github.com/entireio/cli/cmd/entire/cli/validation"
 )
 
@@ -74,12 +77,16 @@ func handleLifecycleSessionStart(ag agent.Agent, event *agent.Event) error {
 	}
 
 	// Build informational message
-	message := "\n\nPowered by Entire:\n  This conversation will be linked to your next commit."
+	message := "\n\n" + i18n.T("Powered by Entire:") + "\n  " + i18n.T("This conversation will be linked to your next commit.")
+
+	// End sessions left ACTIVE by killed agents so they aren't counted below
+	strategy.ExpireIdleSessions(time.Now())
 
 	// Check for concurrent sessions and append count if any
 	strat := GetStrategy()
 	if count, err := strat.CountOtherActiveSessionsWithCheckpoints(event.SessionID); err == nil && count > 0 {
-		message += fmt.Sprintf("\n  %d other active conversation(s) in this workspace will also be included.\n  Use 'entire status' for more information.", count)
+		message += "\n  " + i18n.Tf("%d other active conversation(s) in this workspace will also be included.", count) +
+			"\n  " + i18n.T("Use 'entire status' for more information.")
 	}
 
 	// Output informational message
@@ -123,6 +130,11 @@ func handleLifecycleTurnStart(ag agent.Agent, event *agent.Event) error {
 		return fmt.Errorf("invalid %s event: %w", event.Type, err)
 	}
 
+	// Start copying the transcript into the local cache so TurnEnd only has
+	// to read what this turn appends.
+	waitForPrefetch := prefetchTranscript(logCtx, sessionID, event.SessionRef)
+	defer waitForPrefetch()
+
 	// Capture pre-prompt state (including transcript position via TranscriptAnalyzer)
 	if err := CapturePrePromptState(ag, sessionID, event.SessionRef); err != nil {
 		return err
@@ -137,10 +149,45 @@ func handleLifecycleTurnStart(ag agent.Agent, event *agent.Event) error {
 	if err := strat.InitializeSession(sessionID, ag.Type(), event.SessionRef, event.Prompt); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
 	}
+	recordResumedSession(logCtx, ag, sessionID, event.SessionRef)
 
 	return nil
 }
 
+// recordResumedSession sets ParentSessionID when the session resumes an
+// earlier one under a new session ID, so its checkpoints can be linked into
+// the earlier session's lineage. Failures are logged and otherwise ignored.
+func recordResumedSession(logCtx context.Context, ag agent.Agent, sessionID, sessionRef string) {
+	detector, ok := ag.(agent.ResumeDetector)
+	if !ok || sessionRef == "" {
+		return
+	}
+	state, err := strategy.LoadSessionState(sessionID)
+	if err != nil || state == nil || state.ParentSessionID != "" {
+		return
+	}
+	parentID, err := detector.ResumedSessionID(sessionRef, sessionID)
+	if err != nil {
+		logging.Debug(logCtx, "turn-start: failed to detect resumed session",
+			slog.String("session_id", sessionID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if parentID == "" || parentID == sessionID || validation.ValidateSessionID(parentID) != nil {
+		return
+	}
+	state.ParentSessionID = parentID
+	if err := strategy.SaveSessionState(state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record resumed session: %v\n", err)
+		return
+	}
+	logging.Info(logCtx, "turn-start: session resumes an earlier session",
+		slog.String("session_id", sessionID),
+		slog.String("parent_session_id", parentID),
+	)
+}
+
 // handleLifecycleTurnEnd handles turn end: validates transcript, extracts metadata,
 // detects file changes, saves step + checkpoint, transitions phase.
 //
@@ -162,8 +209,18 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 	if transcriptRef == "" {
 		return errors.New("transcript file not specified")
 	}
-	if !fileExists(transcriptRef) {
-		return fmt.Errorf("transcript file not found: %s", transcriptRef)
+	sourceAvailable := fileExists(transcriptRef)
+	// Subagent transcripts live next to the original transcript, so resolve
+	// their directory before switching to a local copy.
+	subagentsDir := filepath.Join(filepath.Dir(transcriptRef), event.SessionID, "subagents")
+	if !sourceAvailable && !hasCachedTranscript(sessionID, transcriptRef) {
+		// Fall back to the session's metadata copy, restored from the metadata
+		// branch if .entire was deleted, so the turn can still be finalized.
+		restored, restoreErr := strategy.RestoreSessionTranscript(logCtx, sessionID)
+		if restoreErr != nil {
+			return fmt.Errorf("transcript file not found: %s", transcriptRef)
+		}
+		transcriptRef = restored
 	}
 
 	// Early check: bail out quickly if the repo has no commits yet.
@@ -183,14 +240,19 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 	}
 
 	// If agent implements TranscriptPreparer, wait for transcript to be ready
-	if preparer, ok := ag.(agent.TranscriptPreparer); ok {
+	if preparer, ok := ag.(agent.TranscriptPreparer); ok && sourceAvailable {
 		if err := preparer.PrepareTranscript(transcriptRef); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to prepare transcript: %v\n", err)
 		}
 	}
 
+	transcriptRef = cachedTranscriptPath(logCtx, sessionID, transcriptRef)
+
 	// Copy transcript to session directory
 	transcriptData, err := ag.ReadTranscript(transcriptRef)
+	if err == nil {
+		err = faultinject.Check(faultinject.TranscriptRead)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read transcript: %w", err)
 	}
@@ -214,10 +276,6 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 	var summary string
 	var modifiedFiles []string
 
-	// Compute subagents directory for agents that support subagent extraction.
-	// Subagent transcripts live in <transcriptDir>/<modelSessionID>/subagents/
-	subagentsDir := filepath.Join(filepath.Dir(transcriptRef), event.SessionID, "subagents")
-
 	if analyzer, ok := ag.(agent.TranscriptAnalyzer); ok {
 		// Extract prompts
 		if prompts, promptErr := analyzer.ExtractPrompts(transcriptRef, transcriptOffset); promptErr != nil {
@@ -265,14 +323,6 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 	}
 	fmt.Fprintf(os.Stderr, "Extracted summary to: %s\n", sessionDir+"/"+paths.SummaryFileName)
 
-	// Generate commit message from last prompt
-	lastPrompt := ""
-	if len(allPrompts) > 0 {
-		lastPrompt = allPrompts[len(allPrompts)-1]
-	}
-	commitMessage := generateCommitMessage(lastPrompt)
-	fmt.Fprintf(os.Stderr, "Using commit message: %s\n", commitMessage)
-
 	// Get worktree root for path normalization
 	repoRoot, err := paths.WorktreeRoot()
 	if err != nil {
@@ -285,19 +335,8 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 		preUntrackedFiles = preState.PreUntrackedFiles()
 	}
 
-	// Detect file changes via git status
-	changes, err := DetectFileChanges(preUntrackedFiles)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
-	}
-
-	// Filter and normalize all paths
-	relModifiedFiles := FilterAndNormalizePaths(modifiedFiles, repoRoot)
-	var relNewFiles, relDeletedFiles []string
-	if changes != nil {
-		relNewFiles = FilterAndNormalizePaths(changes.New, repoRoot)
-		relDeletedFiles = FilterAndNormalizePaths(changes.Deleted, repoRoot)
-	}
+	// Detect file changes via git status, with filtered, normalized paths
+	relModifiedFiles, relNewFiles, relDeletedFiles := detectStepChanges(preUntrackedFiles, modifiedFiles, repoRoot)
 
 	// Filter transcript-extracted files to exclude files already committed to HEAD.
 	// When an agent commits files mid-turn, those files are condensed by PostCommit
@@ -320,6 +359,20 @@ func handleLifecycleTurnEnd(ag agent.Agent, event *agent.Event) error {
 	// Log file changes
 	logFileChanges(relModifiedFiles, relNewFiles, relDeletedFiles)
 
+	// Generate commit message from last prompt
+	lastPrompt := ""
+	if len(allPrompts) > 0 {
+		lastPrompt = allPrompts[len(allPrompts)-1]
+	}
+	commitMessage := templatedCommitMessage(commitMessageData{
+		Subject:      generateCommitMessage(lastPrompt),
+		Prompt:       lastPrompt,
+		Summary:      summary,
+		SessionID:    sessionID,
+		FilesTouched: touchedFiles(relModifiedFiles, relNewFiles, relDeletedFiles),
+	})
+	fmt.Fprintf(os.Stderr, "Using commit message: %s\n", commitMessage)
+
 	// Create context file
 	contextFile := filepath.Join(sessionDirAbs, paths.ContextFileName)
 	if err := createContextFile(contextFile, commitMessage, sessionID, allPrompts, summary); err != nil {
@@ -531,23 +584,13 @@ func handleLifecycleSubagentEnd(ag agent.Agent, event *agent.Event) error {
 	if preState != nil {
 		preUntrackedFiles = preState.PreUntrackedFiles()
 	}
-	changes, err := DetectFileChanges(preUntrackedFiles)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
-	}
 
 	// Get worktree root and normalize paths
 	repoRoot, err := paths.WorktreeRoot()
 	if err != nil {
 		return fmt.Errorf("failed to get worktree root: %w", err)
 	}
-
-	relModifiedFiles := FilterAndNormalizePaths(modifiedFiles, repoRoot)
-	var relNewFiles, relDeletedFiles []string
-	if changes != nil {
-		relNewFiles = FilterAndNormalizePaths(changes.New, repoRoot)
-		relDeletedFiles = FilterAndNormalizePaths(changes.Deleted, repoRoot)
-	}
+	relModifiedFiles, relNewFiles, relDeletedFiles := detectStepChanges(preUntrackedFiles, modifiedFiles, repoRoot)
 
 	// If no changes, skip
 	if len(relModifiedFiles) == 0 && len(relNewFiles) == 0 && len(relDeletedFiles) == 0 {
@@ -661,53 +704,54 @@ func parseTranscriptForCheckpointUUID(transcriptPath string) ([]transcriptLine,
 	return lines, total, nil
 }
 
-// transitionSessionTurnEnd transitions the session phase to IDLE and dispatches turn-end actions.
-func transitionSessionTurnEnd(sessionID string) {
-	turnState, loadErr := strategy.LoadSessionState(sessionID)
-	if loadErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to load session state for turn end: %v\n", loadErr)
-		return
-	}
-	if turnState == nil {
-		return
-	}
-	if err := strategy.TransitionAndLog(turnState, session.EventTurnEnd, session.TransitionContext{}, session.NoOpActionHandler{}); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: turn-end transition failed: %v\n", err)
+// detectStepChanges returns the files an agent step modified, created, and
+// deleted, relative to repoRoot. modifiedFiles are the files the agent
+// reported; the rest come from git status, ignoring preUntrackedFiles.
+// Paths outside the sparse-checkout cone and ignored paths are dropped.
+func detectStepChanges(preUntrackedFiles, modifiedFiles []string, repoRoot string) (modified, created, deleted []string) {
+	reported := FilterAndNormalizePaths(modifiedFiles, repoRoot)
+	changes, err := DetectFileChanges(preUntrackedFiles, reported)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
+		return filterIgnoredPaths(filterToSparseCone(reported)), nil, nil
 	}
+	return changes.Reported, FilterAndNormalizePaths(changes.New, repoRoot), FilterAndNormalizePaths(changes.Deleted, repoRoot)
+}
 
-	// Always dispatch to strategy for turn-end handling. The strategy reads
-	// work items from state (e.g. TurnCheckpointIDs), not the action list.
-	strat := GetStrategy()
-	if err := strat.HandleTurnEnd(turnState); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: turn-end action dispatch failed: %v\n", err)
-	}
+// transitionSessionTurnEnd transitions the session phase to IDLE and dispatches turn-end actions.
+// The state is updated under the session's lock, so a post-commit hook running
+// at the same time cannot overwrite the transition, or have its changes
+// overwritten by it.
+func transitionSessionTurnEnd(sessionID string) {
+	err := strategy.UpdateSessionState(sessionID, func(turnState *strategy.SessionState) error {
+		if err := strategy.TransitionAndLog(turnState, session.EventTurnEnd, session.TransitionContext{}, session.NoOpActionHandler{}); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: turn-end transition failed: %v\n", err)
+		}
 
-	if updateErr := strategy.SaveSessionState(turnState); updateErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to update session phase on turn end: %v\n", updateErr)
+		// Always dispatch to strategy for turn-end handling. The strategy reads
+		// work items from state (e.g. TurnCheckpointIDs), not the action list.
+		strat := GetStrategy()
+		if err := strat.HandleTurnEnd(turnState); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: turn-end action dispatch failed: %v\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update session phase on turn end: %v\n", err)
 	}
 }
 
 // markSessionEnded transitions the session to ENDED phase via the state machine.
 func markSessionEnded(sessionID string) error {
-	state, err := strategy.LoadSessionState(sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to load session state: %w", err)
-	}
-	if state == nil {
-		return nil // No state file, nothing to update
-	}
-
-	if transErr := strategy.TransitionAndLog(state, session.EventSessionStop, session.TransitionContext{}, session.NoOpActionHandler{}); transErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: session stop transition failed: %v\n", transErr)
-	}
-
-	now := time.Now()
-	state.EndedAt = &now
+	return strategy.UpdateSessionState(sessionID, func(state *strategy.SessionState) error {
+		if transErr := strategy.TransitionAndLog(state, session.EventSessionStop, session.TransitionContext{}, session.NoOpActionHandler{}); transErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: session stop transition failed: %v\n", transErr)
+		}
 
-	if err := strategy.SaveSessionState(state); err != nil {
-		return fmt.Errorf("failed to save session state: %w", err)
-	}
-	return nil
+		now := time.Now()
+		state.EndedAt = &now
+		return nil
+	})
 }
 
 // logFileChanges logs the files modified, created, and deleted during a session.
@@ -729,3 +773,58 @@ func logFileChanges(modified, newFiles, deleted []string) {
 		}
 	}
 }
+
+// transcriptPrefetchTimeout bounds how long TurnStart waits for the transcript
+// cache to be refreshed before the hook exits.
+const transcriptPrefetchTimeout = 5 * time.Second
+
+// cachedTranscriptPath refreshes the local copy of the transcript at ref and
+// returns the path to read it from. Returns ref unchanged if the transcript is
+// not cached or the cache cannot be refreshed.
+func cachedTranscriptPath(ctx context.Context, sessionID, ref string) string {
+	cache, ok := transcriptcache.ForTranscript(ref)
+	if !ok {
+		return ref
+	}
+	path, err := cache.Sync(ctx, sessionID, ref)
+	if err != nil {
+		logging.Warn(ctx, "failed to cache transcript, reading source directly",
+			slog.String("transcript", ref),
+			slog.String("error", err.Error()),
+		)
+		return ref
+	}
+	return path
+}
+
+// hasCachedTranscript reports whether a verified local copy of the transcript
+// at ref exists for sessionID.
+func hasCachedTranscript(sessionID, ref string) bool {
+	cache, ok := transcriptcache.ForTranscript(ref)
+	return ok && cache.Has(sessionID, ref)
+}
+
+// prefetchTranscript refreshes the transcript cache in the background and
+// returns a function that waits for it, up to transcriptPrefetchTimeout.
+// Missing transcripts (e.g. on the first prompt of a session) are ignored.
+func prefetchTranscript(ctx context.Context, sessionID, ref string) (wait func()) {
+	cache, ok := transcriptcache.ForTranscript(ref)
+	if !ok || sessionID == "" {
+		return func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, transcriptPrefetchTimeout)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := cache.Sync(ctx, sessionID, ref); err != nil && !errors.Is(err, os.ErrNotExist) {
+			logging.Debug(ctx, "transcript prefetch failed",
+				slog.String("transcript", ref),
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+	return func() {
+		defer cancel()
+		<-done
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/transcriptcache"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -212,6 +214,45 @@ func TestHandleLifecycleTurnEnd_NonexistentTranscript(t *testing.T) {
 	}
 }
 
+func TestCachedTranscriptPath(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := t.TempDir()
+	t.Chdir(repoDir)
+	paths.ClearWorktreeRootCache()
+	setupGitRepoWithCommit(t, repoDir)
+	paths.ClearWorktreeRootCache()
+
+	ctx := context.Background()
+
+	// Transcripts outside the repository are read from the local cache.
+	homeTranscript := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(homeTranscript, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got := cachedTranscriptPath(ctx, "test-session", homeTranscript)
+	want := filepath.Join(repoDir, paths.EntireCacheDir, transcriptcache.DirName, "test-session.jsonl")
+	if paths.ResolveSymlinks(got) != paths.ResolveSymlinks(want) {
+		t.Errorf("cachedTranscriptPath() = %q, want %q", got, want)
+	}
+
+	// The cached copy keeps TurnEnd working when the source disappears.
+	if err := os.Remove(homeTranscript); err != nil {
+		t.Fatal(err)
+	}
+	if !hasCachedTranscript("test-session", homeTranscript) {
+		t.Error("hasCachedTranscript() = false after source was removed")
+	}
+
+	// Transcripts inside the repository are read directly.
+	repoTranscript := filepath.Join(repoDir, "transcript.jsonl")
+	if err := os.WriteFile(repoTranscript, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := cachedTranscriptPath(ctx, "test-session", repoTranscript); got != repoTranscript {
+		t.Errorf("cachedTranscriptPath() = %q, want source path for in-repo transcript", got)
+	}
+}
+
 func TestHandleLifecycleTurnEnd_EmptyRepository(t *testing.T) {
 	// Cannot use t.Parallel() because we use t.Chdir()
 	tmpDir := t.TempDir()
@@ -317,6 +358,54 @@ func TestHandleLifecycleCompaction_ResetsTranscriptOffset(t *testing.T) {
 	}
 }
 
+// mockResumingAgent is a mock agent whose sessions resume resumedFrom.
+type mockResumingAgent struct {
+	mockLifecycleAgent
+
+	resumedFrom string
+}
+
+var _ agent.ResumeDetector = (*mockResumingAgent)(nil)
+
+func (m *mockResumingAgent) ResumedSessionID(_, _ string) (string, error) {
+	return m.resumedFrom, nil
+}
+
+func TestRecordResumedSession(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	setupGitRepoWithCommit(t, tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	sessionID := "resumed-session"
+	if err := strategy.SaveSessionState(&strategy.SessionState{SessionID: sessionID}); err != nil {
+		t.Fatalf("Failed to save session state: %v", err)
+	}
+
+	ag := &mockResumingAgent{mockLifecycleAgent: *newMockAgent(), resumedFrom: "earlier-session"}
+	recordResumedSession(context.Background(), ag, sessionID, filepath.Join(tmpDir, "transcript.jsonl"))
+
+	state, err := strategy.LoadSessionState(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to load session state: %v", err)
+	}
+	if state.ParentSessionID != "earlier-session" {
+		t.Errorf("ParentSessionID = %q, want %q", state.ParentSessionID, "earlier-session")
+	}
+
+	// A recorded parent is kept even if detection later finds another one.
+	ag.resumedFrom = "other-session"
+	recordResumedSession(context.Background(), ag, sessionID, filepath.Join(tmpDir, "transcript.jsonl"))
+	state, err = strategy.LoadSessionState(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to load session state: %v", err)
+	}
+	if state.ParentSessionID != "earlier-session" {
+		t.Errorf("ParentSessionID = %q, want it unchanged", state.ParentSessionID)
+	}
+}
+
 // --- handleLifecycleSessionEnd tests ---
 
 func TestHandleLifecycleSessionEnd_EmptySessionID(t *testing.T) {
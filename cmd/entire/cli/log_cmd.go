@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+func newLogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show what Entire decided and why",
+	}
+
+	cmd.AddCommand(newLogCondensationsCmd())
+
+	return cmd
+}
+
+// condensationFilter selects which condensation decisions to show.
+type condensationFilter struct {
+	since   time.Time
+	session string // session ID prefix
+	commit  string // commit hash prefix
+}
+
+func newLogCondensationsCmd() *cobra.Command {
+	var sinceFlag string
+	var sessionFlag string
+	var commitFlag string
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "condensations",
+		Short: "Show why sessions were or weren't condensed into each commit",
+		Long: `Show the decisions the post-commit hook made about each session: whether it
+was condensed into the commit's checkpoint, skipped, or had files carried
+forward to the next commit, and why.
+
+Typical reasons a session is skipped: the commit has no Entire-Checkpoint
+trailer, a rebase was in progress, the session had no new content since its
+last checkpoint, or none of the session's changes are in the commit.
+
+The log is always on and is stored in the git directory as
+` + audit.CondensationLogFileName + `.
+
+--since accepts a duration (90m, 24h, 7d), a date (2006-01-02), or an
+RFC 3339 timestamp. --session accepts a prefix; --commit accepts a revision
+or a hash prefix.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			since, err := parseSince(sinceFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			filter := condensationFilter{since: since, session: sessionFlag, commit: commitFlag}
+			return runLogCondensations(cmd, filter, jsonFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only show decisions after this time (duration, date, or timestamp)")
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "Only show decisions about this session")
+	cmd.Flags().StringVar(&commitFlag, "commit", "", "Only show decisions made for this commit (revision or hash prefix)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output decisions as JSON lines")
+
+	return cmd
+}
+
+func runLogCondensations(cmd *cobra.Command, filter condensationFilter, asJSON bool) error {
+	commonDir, err := strategy.GetGitCommonDir()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	if filter.commit != "" {
+		filter.commit = resolveCommitFilter(filter.commit)
+	}
+
+	decisions, err := audit.ReadCondensations(commonDir, filter.since)
+	if err != nil {
+		return fmt.Errorf("failed to read condensation log: %w", err)
+	}
+	matching := decisions[:0]
+	for _, c := range decisions {
+		if strings.HasPrefix(c.SessionID, filter.session) && strings.HasPrefix(c.Commit, filter.commit) {
+			matching = append(matching, c)
+		}
+	}
+
+	w := cmd.OutOrStdout()
+	if asJSON {
+		enc := json.NewEncoder(w)
+		for _, c := range matching {
+			if err := enc.Encode(c); err != nil {
+				return fmt.Errorf("failed to encode condensation: %w", err)
+			}
+		}
+		return nil
+	}
+	if len(matching) == 0 {
+		fmt.Fprintln(w, "No condensation decisions recorded.")
+		return nil
+	}
+	writeCondensations(w, matching)
+	return nil
+}
+
+// resolveCommitFilter resolves a revision such as HEAD~1 to its full hash.
+// Anything that doesn't resolve, such as a commit lost to an amend, is kept
+// as a hash prefix.
+func resolveCommitFilter(rev string) string {
+	repo, err := openRepository()
+	if err != nil {
+		return rev
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return rev
+	}
+	return hash.String()
+}
+
+// writeCondensations writes one aligned line per decision.
+func writeCondensations(w io.Writer, decisions []audit.Condensation) {
+	sessionWidth := 1
+	for _, c := range decisions {
+		sessionWidth = max(sessionWidth, len(c.SessionID))
+	}
+	for _, c := range decisions {
+		sessionID := c.SessionID
+		if sessionID == "" {
+			sessionID = "-"
+		}
+		fmt.Fprintf(w, "%s  %s  %-13s  %-*s  %s\n",
+			c.Time.Local().Format("2006-01-02 15:04:05"),
+			strategy.TruncateHash(c.Commit),
+			c.Decision,
+			sessionWidth, sessionID,
+			condensationDetail(c))
+	}
+}
+
+// condensationDetail explains a decision: the checkpoint a session was
+// condensed into, the files carried forward, or why it was skipped.
+func condensationDetail(c audit.Condensation) string {
+	switch c.Decision {
+	case audit.DecisionCondensed:
+		return fmt.Sprintf("into %s (%d files)", c.CheckpointID, len(c.Files))
+	case audit.DecisionCarryForward:
+		return strings.Join(c.Files, ", ")
+	default:
+		return c.Reason
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runLogCondensationsForTest(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := newLogCondensationsCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	return out.String()
+}
+
+func TestLogCondensations(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	_, head := setupCleanTestRepo(t)
+
+	output := runLogCondensationsForTest(t)
+	assert.Equal(t, "No condensation decisions recorded.\n", output)
+
+	gitDir, err := filepath.Abs(".git")
+	require.NoError(t, err)
+	base := time.Now().Add(-time.Hour)
+	for _, c := range []audit.Condensation{
+		{Time: base, SessionID: "2026-04-01-alpha", Commit: "1111111111111111111111111111111111111111", CheckpointID: "a1b2c3d4e5f6", Decision: audit.DecisionCondensed, Files: []string{"a.go", "b.go"}},
+		{Time: base, SessionID: "2026-04-01-alpha", Commit: "1111111111111111111111111111111111111111", CheckpointID: "a1b2c3d4e5f6", Decision: audit.DecisionCarryForward, Files: []string{"c.go"}},
+		{Time: base, SessionID: "2026-04-01-beta", Commit: "1111111111111111111111111111111111111111", Decision: audit.DecisionSkipped, Reason: "no new content since the last checkpoint"},
+		{Time: base.Add(30 * time.Minute), SessionID: "2026-04-01-beta", Commit: head.String(), Decision: audit.DecisionSkipped, Reason: "commit has no Entire-Checkpoint trailer"},
+	} {
+		audit.RecordCondensation(gitDir, c)
+	}
+
+	output = runLogCondensationsForTest(t)
+	assert.Contains(t, output, "1111111  condensed")
+	assert.Contains(t, output, "into a1b2c3d4e5f6 (2 files)")
+	assert.Contains(t, output, "carry-forward  2026-04-01-alpha  c.go")
+	assert.Contains(t, output, "no new content since the last checkpoint")
+
+	output = runLogCondensationsForTest(t, "--session", "2026-04-01-b", "--commit", "1111")
+	assert.Contains(t, output, "no new content since the last checkpoint")
+	assert.NotContains(t, output, "alpha")
+	assert.NotContains(t, output, head.String()[:7])
+
+	output = runLogCondensationsForTest(t, "--commit", "HEAD")
+	assert.Contains(t, output, "commit has no Entire-Checkpoint trailer")
+	assert.NotContains(t, output, "1111111")
+
+	output = runLogCondensationsForTest(t, "--since", "45m", "--json")
+	assert.Contains(t, output, `"reason":"commit has no Entire-Checkpoint trailer"`)
+	assert.NotContains(t, output, "1111111")
+}
@@ -60,6 +60,11 @@ var (
 	// logLevelGetter is an optional callback to get log level from settings.
 	// Set by SetLogLevelGetter before Init is called.
 	logLevelGetter func() string
+
+	// userNamespaceGetter is an optional callback returning the current user's
+	// log subdirectory, or "" to log to LogsDir directly.
+	// Set by SetUserNamespaceGetter before Init is called.
+	userNamespaceGetter func() string
 )
 
 // SetLogLevelGetter sets a callback function to get the log level from settings.
@@ -71,8 +76,17 @@ func SetLogLevelGetter(getter func() string) {
 	logLevelGetter = getter
 }
 
+// SetUserNamespaceGetter sets a callback function returning the subdirectory of
+// LogsDir to log to, so users sharing a clone (state.per_user) get separate logs.
+// An empty result logs to LogsDir itself.
+func SetUserNamespaceGetter(getter func() string) {
+	mu.Lock()
+	defer mu.Unlock()
+	userNamespaceGetter = getter
+}
+
 // Init initializes the logger for a session, writing JSON logs to
-// .entire/logs/entire.log.
+// .entire/logs/entire.log (.entire/logs/<user>/entire.log with a user namespace).
 //
 // If sessionID is non-empty, it is stored as an slog attribute on every log line for filtering.
 // If the log file cannot be created, falls back to stderr.
@@ -118,6 +132,11 @@ func Init(sessionID string) error {
 	}
 
 	logsPath := filepath.Join(repoRoot, LogsDir)
+	if userNamespaceGetter != nil {
+		if ns := userNamespaceGetter(); ns != "" {
+			logsPath = filepath.Join(logsPath, ns)
+		}
+	}
 	if err := os.MkdirAll(logsPath, 0o750); err != nil {
 		// Fall back to stderr
 		logger = createLogger(os.Stderr, level)
@@ -32,7 +32,7 @@ func WriteTaskCheckpoint(taskMetadataDir string, checkpoint *TaskCheckpoint) err
 		return fmt.Errorf("failed to create task metadata directory: %w", err)
 	}
 
-	data, err := jsonutil.MarshalIndentWithNewline(checkpoint, "", "  ")
+	data, err := jsonutil.MarshalCanonical(checkpoint)
 	if err != nil {
 		return fmt.Errorf("failed to marshal checkpoint: %w", err)
 	}
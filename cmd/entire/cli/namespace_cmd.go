@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newNamespaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "namespace",
+		Short: "Show or migrate the checkpoint trailer key and branch names",
+		Long: `Show the trailer key and branch names Entire uses in this repository.
+
+They default to Entire-Checkpoint, entire/checkpoints/v1, and entire/, and can
+be changed with the "namespace" setting when they collide with other tooling:
+
+  "namespace": {
+    "trailer_key": "Acme-Checkpoint",
+    "metadata_branch": "acme/entire/checkpoints/v1",
+    "shadow_branch_prefix": "acme/entire/"
+  }
+
+After changing the branch names, run 'entire namespace migrate' to move
+existing branches to the new names.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			writeNamespace(cmd.OutOrStdout(), paths.CurrentNamespace())
+			return nil
+		},
+	}
+
+	cmd.AddCommand(newNamespaceMigrateCmd())
+
+	return cmd
+}
+
+func newNamespaceMigrateCmd() *cobra.Command {
+	var forceFlag bool
+	var from paths.Namespace
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Move the metadata and shadow branches to the configured names",
+		Long: `Rename the local metadata branch and shadow branches from their previous
+names (the defaults, unless --from-metadata-branch or --from-shadow-prefix say
+otherwise) to the names in the "namespace" setting.
+
+Commits are not rewritten: their trailers keep the old key, and
+Entire-Checkpoint trailers are still recognized after the key changes.
+Branches on the remote keep their old names until the new metadata branch is
+pushed; delete the old one there with 'git push origin --delete <branch>'.
+
+Default: shows a preview of the branches that would be renamed.
+With --force, actually renames them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := openRepository(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			if s, err := settings.Load(); err == nil {
+				if err := s.RefNamespace().Validate(); err != nil {
+					return fmt.Errorf("invalid namespace setting: %w", err)
+				}
+			}
+			return runNamespaceMigrate(cmd.OutOrStdout(), from, forceFlag)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Actually rename the branches (default: preview only)")
+	cmd.Flags().StringVar(&from.MetadataBranch, "from-metadata-branch", paths.DefaultMetadataBranchName, "Previous metadata branch name")
+	cmd.Flags().StringVar(&from.ShadowBranchPrefix, "from-shadow-prefix", paths.DefaultShadowBranchPrefix, "Previous shadow branch prefix")
+
+	return cmd
+}
+
+func writeNamespace(w io.Writer, ns paths.Namespace) {
+	fmt.Fprintf(w, "Trailer key:          %s\n", ns.TrailerKey)
+	fmt.Fprintf(w, "Metadata branch:      %s\n", ns.MetadataBranch)
+	fmt.Fprintf(w, "Shadow branch prefix: %s\n", ns.ShadowBranchPrefix)
+}
+
+func runNamespaceMigrate(w io.Writer, from paths.Namespace, force bool) error {
+	renames, err := strategy.NamespaceRenames(from)
+	if err != nil {
+		return fmt.Errorf("failed to find branches to migrate: %w", err)
+	}
+	if len(renames) == 0 {
+		fmt.Fprintln(w, "Nothing to migrate.")
+		return nil
+	}
+
+	if !force {
+		fmt.Fprintf(w, "Would rename %d branch(es):\n", len(renames))
+		for _, rename := range renames {
+			fmt.Fprintf(w, "  %s → %s\n", rename.From, rename.To)
+		}
+		fmt.Fprintln(w, "\nRun with --force to rename them.")
+		return nil
+	}
+
+	moved, err := strategy.MigrateNamespace(renames)
+	for _, rename := range moved {
+		fmt.Fprintf(w, "✓ %s → %s\n", rename.From, rename.To)
+	}
+	if err != nil {
+		return fmt.Errorf("some branches were not renamed: %w", err)
+	}
+	return nil
+}
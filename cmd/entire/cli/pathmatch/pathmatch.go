@@ -0,0 +1,28 @@
+// Package pathmatch matches repository paths against the glob patterns used
+// by .entireignore and the track_paths and ignore_paths settings.
+package pathmatch
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether pattern matches file, a slash-separated path relative
+// to the repository root, or one of the directories containing it. Patterns
+// use path.Match syntax and, as in .gitignore, a pattern without a "/"
+// matches a file or directory name at any depth. Invalid patterns match
+// nothing.
+func Match(pattern, file string) bool {
+	pattern = strings.TrimSuffix(strings.TrimPrefix(pattern, "./"), "/")
+	anyDepth := !strings.Contains(pattern, "/")
+	for dir := file; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+		name := dir
+		if anyDepth {
+			name = path.Base(dir)
+		}
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package pathmatch
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{pattern: "services/*", file: "services/api/main.go", want: true},
+		{pattern: "./services/", file: "services/api/main.go", want: true},
+		{pattern: "*.lock", file: "web/yarn.lock", want: true}, // no "/": any depth
+		{pattern: "build/*.o", file: "cmd/build/main.o"},       // has "/": anchored to the root
+		{pattern: "docs", file: "docs.go"},
+		{pattern: "[", file: "["}, // invalid patterns match nothing
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.file); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.file, got, tt.want)
+		}
+	}
+}
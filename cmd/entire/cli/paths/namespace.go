@@ -0,0 +1,177 @@
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Default namespace values. Existing repositories use these unless the
+// "namespace" setting overrides them.
+const (
+	DefaultCheckpointTrailerKey = "Entire-Checkpoint"
+	DefaultMetadataBranchName   = "entire/checkpoints/v1"
+	DefaultShadowBranchPrefix   = "entire/"
+)
+
+// Namespace holds the names Entire uses in commit messages and refs.
+// Organizations that already use a colliding trailer or ref namespace can
+// override them with the "namespace" setting. Empty fields use the defaults.
+type Namespace struct {
+	// TrailerKey links commits to their checkpoint metadata.
+	TrailerKey string
+	// MetadataBranch is the orphan branch that stores committed checkpoints.
+	MetadataBranch string
+	// ShadowBranchPrefix prefixes the branches that store temporary checkpoints.
+	ShadowBranchPrefix string
+}
+
+// DefaultNamespace returns the namespace used when nothing is configured.
+func DefaultNamespace() Namespace {
+	return Namespace{
+		TrailerKey:         DefaultCheckpointTrailerKey,
+		MetadataBranch:     DefaultMetadataBranchName,
+		ShadowBranchPrefix: DefaultShadowBranchPrefix,
+	}
+}
+
+var trailerKeyRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*$`)
+
+// Validate reports the first field that can't be used. Empty fields are valid
+// and mean the default.
+func (n Namespace) Validate() error {
+	if n.TrailerKey != "" && !trailerKeyRegex.MatchString(n.TrailerKey) {
+		return fmt.Errorf("trailer_key %q must start with a letter and contain only letters, digits, and dashes", n.TrailerKey)
+	}
+	if n.MetadataBranch != "" {
+		if err := validateBranchName(n.MetadataBranch); err != nil {
+			return fmt.Errorf("metadata_branch: %w", err)
+		}
+	}
+	if n.ShadowBranchPrefix != "" {
+		if !strings.HasSuffix(n.ShadowBranchPrefix, "/") {
+			return fmt.Errorf("shadow_branch_prefix %q must end with '/'", n.ShadowBranchPrefix)
+		}
+		if err := validateBranchName(n.ShadowBranchPrefix + "0000000"); err != nil {
+			return fmt.Errorf("shadow_branch_prefix: %w", err)
+		}
+	}
+	metadata, prefix := n.withDefaults().MetadataBranch, n.withDefaults().ShadowBranchPrefix
+	if isShadowLike(metadata, prefix) {
+		return fmt.Errorf("metadata_branch %q would be mistaken for a shadow branch under %q", metadata, prefix)
+	}
+	return nil
+}
+
+func validateBranchName(name string) error {
+	if !plumbing.NewBranchReferenceName(name).IsBranch() || strings.ContainsAny(name, " ~^:?*[\\") ||
+		strings.Contains(name, "..") || strings.Contains(name, "//") ||
+		strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".lock") {
+		return errors.New("invalid branch name " + name)
+	}
+	return nil
+}
+
+// isShadowLike reports whether branch looks like "<prefix><hex>", the form of
+// a shadow branch name.
+func isShadowLike(branch, prefix string) bool {
+	rest, ok := strings.CutPrefix(branch, prefix)
+	if !ok || len(rest) < 7 {
+		return false
+	}
+	for _, c := range rest {
+		if !strings.ContainsRune("0123456789abcdefABCDEF-", c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n Namespace) withDefaults() Namespace {
+	defaults := DefaultNamespace()
+	if n.TrailerKey == "" {
+		n.TrailerKey = defaults.TrailerKey
+	}
+	if n.MetadataBranch == "" {
+		n.MetadataBranch = defaults.MetadataBranch
+	}
+	if n.ShadowBranchPrefix == "" {
+		n.ShadowBranchPrefix = defaults.ShadowBranchPrefix
+	}
+	return n
+}
+
+var (
+	namespaceMu sync.Mutex
+	// Set by SetNamespaceGetter.
+	namespaceGetter func() Namespace
+
+	// namespace caches the getter's result so settings are read once.
+	namespace       Namespace
+	namespaceLoaded bool
+)
+
+// SetNamespaceGetter sets a callback function to get the namespace from
+// settings. This allows the paths package to read settings without a
+// circular dependency. The callback is called at most once.
+func SetNamespaceGetter(getter func() Namespace) {
+	namespaceMu.Lock()
+	defer namespaceMu.Unlock()
+	namespaceGetter = getter
+	namespace = Namespace{}
+	namespaceLoaded = false
+}
+
+// ClearNamespaceCache forgets the cached namespace, so the next
+// CurrentNamespace call reads settings again.
+func ClearNamespaceCache() {
+	namespaceMu.Lock()
+	defer namespaceMu.Unlock()
+	namespace = Namespace{}
+	namespaceLoaded = false
+}
+
+func init() {
+	repocache.Register(ClearNamespaceCache)
+}
+
+// CurrentNamespace returns the configured namespace with defaults filled in.
+// An invalid configuration falls back to the defaults entirely, so refs are
+// never written under a half-applied namespace.
+func CurrentNamespace() Namespace {
+	namespaceMu.Lock()
+	defer namespaceMu.Unlock()
+	if !namespaceLoaded {
+		namespace = DefaultNamespace()
+		if namespaceGetter != nil {
+			if configured := namespaceGetter(); configured.Validate() == nil {
+				namespace = configured.withDefaults()
+			}
+		}
+		namespaceLoaded = true
+	}
+	return namespace
+}
+
+// MetadataBranchName returns the orphan branch used by manual-commit strategy
+// to store metadata (entire/checkpoints/v1 by default).
+func MetadataBranchName() string {
+	return CurrentNamespace().MetadataBranch
+}
+
+// ShadowBranchPrefix returns the prefix of shadow branches (entire/ by default).
+func ShadowBranchPrefix() string {
+	return CurrentNamespace().ShadowBranchPrefix
+}
+
+// CheckpointTrailerKey returns the commit trailer key that links commits to
+// their checkpoint metadata (Entire-Checkpoint by default).
+func CheckpointTrailerKey() string {
+	return CurrentNamespace().TrailerKey
+}
@@ -0,0 +1,51 @@
+package paths
+
+import "testing"
+
+func TestNamespaceValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		ns      Namespace
+		wantErr bool
+	}{
+		{"empty", Namespace{}, false},
+		{"custom", Namespace{TrailerKey: "Acme-Checkpoint", MetadataBranch: "acme/checkpoints", ShadowBranchPrefix: "acme/shadow/"}, false},
+		{"trailer key with colon", Namespace{TrailerKey: "Acme:Checkpoint"}, true},
+		{"trailer key with space", Namespace{TrailerKey: "Acme Checkpoint"}, true},
+		{"metadata branch with dots", Namespace{MetadataBranch: "acme/../v1"}, true},
+		{"prefix without slash", Namespace{ShadowBranchPrefix: "acme"}, true},
+		{"metadata branch looks like a shadow branch", Namespace{MetadataBranch: "entire/abcdef0"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.ns.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCurrentNamespace(t *testing.T) {
+	t.Cleanup(func() { SetNamespaceGetter(nil) })
+
+	SetNamespaceGetter(nil)
+	if got := CurrentNamespace(); got != DefaultNamespace() {
+		t.Errorf("CurrentNamespace() without getter = %+v, want defaults", got)
+	}
+
+	SetNamespaceGetter(func() Namespace { return Namespace{MetadataBranch: "acme/checkpoints"} })
+	if got := MetadataBranchName(); got != "acme/checkpoints" {
+		t.Errorf("MetadataBranchName() = %q, want acme/checkpoints", got)
+	}
+	if got := CheckpointTrailerKey(); got != DefaultCheckpointTrailerKey {
+		t.Errorf("CheckpointTrailerKey() = %q, want the default for an unset field", got)
+	}
+
+	SetNamespaceGetter(func() Namespace { return Namespace{TrailerKey: "Acme-Checkpoint", ShadowBranchPrefix: "acme"} })
+	if got := CurrentNamespace(); got != DefaultNamespace() {
+		t.Errorf("CurrentNamespace() with an invalid setting = %+v, want defaults", got)
+	}
+}
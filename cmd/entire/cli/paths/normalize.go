@@ -0,0 +1,228 @@
+package paths
+
+import (
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Canonical path form
+//
+// Paths stored in session state and checkpoints are repository-relative,
+// slash-separated and cleaned ("src/main.go", never "./src//main.go" or
+// "src\main.go"). Agents report files in whatever form they like: absolute,
+// through a symlinked directory (macOS /tmp is /private/tmp), or with a
+// different letter case on case-insensitive file systems. RepoRelative turns
+// all of them into the canonical form, and PathKey gives the form to compare
+// canonical paths by.
+
+// normalizer implements path normalization for one platform. The exported
+// functions use the native one; tests construct others to cover every
+// platform from any host.
+type normalizer struct {
+	// windows paths use backslashes (slashes are accepted too) and may start
+	// with a drive ("C:") or UNC volume ("\\server\share").
+	windows bool
+	// caseInsensitive file systems treat "Main.go" and "main.go" as the same
+	// file. The default file systems of macOS and Windows are.
+	caseInsensitive bool
+	// evalSymlinks resolves symlinks in an existing native path.
+	evalSymlinks func(string) (string, error)
+}
+
+var native = normalizer{
+	windows:         runtime.GOOS == "windows",
+	caseInsensitive: runtime.GOOS == "darwin" || runtime.GOOS == "windows",
+	evalSymlinks:    filepath.EvalSymlinks,
+}
+
+// Canonical returns p cleaned and slash-separated, without a leading "./".
+// It does not touch the file system.
+func Canonical(p string) string {
+	return native.canonical(p)
+}
+
+// RepoRelative returns p relative to repoRoot in canonical form, or "" if p
+// is outside repoRoot. Relative paths are taken to be relative to repoRoot
+// already. Absolute paths match repoRoot even when one of them goes through
+// a symlink or, on case-insensitive file systems, differs in case.
+// repoRoot itself is ".".
+func RepoRelative(repoRoot, p string) string {
+	return native.repoRelative(repoRoot, p)
+}
+
+// PathKey returns the form canonical paths are compared by: the path itself,
+// case-folded on case-insensitive file systems. Use it for map keys and
+// equality; store the canonical path.
+func PathKey(p string) string {
+	return native.key(native.canonical(p))
+}
+
+// SamePath reports whether two paths name the same file by their canonical
+// form. It does not resolve symlinks; use RepoRelative first for paths that
+// may be absolute.
+func SamePath(a, b string) bool {
+	return PathKey(a) == PathKey(b)
+}
+
+// ResolveSymlinks returns p with symlinks resolved in its longest existing
+// prefix, so paths to files that don't exist yet (or any more) resolve
+// consistently with their directory. Returns p cleaned if nothing resolves.
+func ResolveSymlinks(p string) string {
+	return filepath.FromSlash(native.resolve(native.canonical(p)))
+}
+
+// canonical cleans p and converts it to slashes. The volume of a Windows
+// path is kept as written, with slashes.
+func (n normalizer) canonical(p string) string {
+	if p == "" {
+		return ""
+	}
+	if n.windows {
+		p = strings.ReplaceAll(p, `\`, "/")
+	}
+	volume, rest := n.splitVolume(p)
+	if volume != "" {
+		if rest == "" {
+			return volume
+		}
+		// Drive-relative paths ("C:foo") stay relative to the drive.
+		return volume + path.Clean(rest)
+	}
+	return path.Clean(rest)
+}
+
+// splitVolume splits a slash-separated path into its Windows volume ("C:" or
+// "//server/share") and the rest. Non-Windows paths have no volume.
+func (n normalizer) splitVolume(p string) (string, string) {
+	if !n.windows {
+		return "", p
+	}
+	if len(p) >= 2 && p[1] == ':' && isASCIILetter(p[0]) {
+		return p[:2], p[2:]
+	}
+	if strings.HasPrefix(p, "//") && !strings.HasPrefix(p, "///") {
+		// UNC: //server/share/rest
+		parts := strings.SplitN(p[2:], "/", 3)
+		if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
+			volume := "//" + parts[0] + "/" + parts[1]
+			return volume, strings.TrimPrefix(p, volume)
+		}
+	}
+	return "", p
+}
+
+// isAbs reports whether the canonical path c is absolute.
+func (n normalizer) isAbs(c string) bool {
+	volume, rest := n.splitVolume(c)
+	if n.windows {
+		return volume != "" && (strings.HasPrefix(volume, "//") || strings.HasPrefix(rest, "/"))
+	}
+	return strings.HasPrefix(rest, "/")
+}
+
+// key returns the comparison form of the canonical path c. Windows volumes
+// are case-insensitive even on case-sensitive file systems.
+func (n normalizer) key(c string) string {
+	if n.caseInsensitive {
+		return strings.ToLower(c)
+	}
+	if volume, rest := n.splitVolume(c); volume != "" {
+		return strings.ToLower(volume) + rest
+	}
+	return c
+}
+
+// relTo returns the canonical path c relative to the canonical absolute
+// directory root, keeping c's own spelling, or false if c is outside root.
+func (n normalizer) relTo(root, c string) (string, bool) {
+	rootKey, cKey := n.key(root), n.key(c)
+	if cKey == rootKey {
+		return ".", true
+	}
+	prefix := rootKey
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if !strings.HasPrefix(cKey, prefix) {
+		return "", false
+	}
+	// Case folding never changes the length of ASCII; for other text fall
+	// back to the key, which is still a valid path on a case-insensitive
+	// file system.
+	if len(cKey) == len(c) {
+		return c[len(prefix):], true
+	}
+	return cKey[len(prefix):], true
+}
+
+func (n normalizer) repoRelative(repoRoot, p string) string {
+	c := n.canonical(p)
+	if c == "" {
+		return ""
+	}
+	if !n.isAbs(c) {
+		if c == ".." || strings.HasPrefix(c, "../") {
+			return ""
+		}
+		return c
+	}
+
+	root := n.canonical(repoRoot)
+	if rel, ok := n.relTo(root, c); ok {
+		return rel
+	}
+	// Retry with symlinks resolved on both sides, e.g. /tmp/repo/a.go
+	// against a root of /private/tmp/repo.
+	if rel, ok := n.relTo(n.resolve(root), n.resolve(c)); ok {
+		return rel
+	}
+	return ""
+}
+
+// resolve resolves symlinks in the longest existing prefix of the canonical
+// absolute path c and returns the result in canonical form.
+func (n normalizer) resolve(c string) string {
+	if n.evalSymlinks == nil {
+		return c
+	}
+	dir, suffix := c, ""
+	for {
+		if resolved, err := n.evalSymlinks(n.toNative(dir)); err == nil {
+			r := n.canonical(resolved)
+			if suffix != "" {
+				r = strings.TrimSuffix(r, "/") + "/" + suffix
+			}
+			return r
+		}
+		parent := path.Dir(dir)
+		if volume, rest := n.splitVolume(dir); volume != "" {
+			parent = volume + path.Dir(rest)
+			if rest == "" || rest == "/" {
+				return c
+			}
+		}
+		if parent == dir {
+			return c
+		}
+		base := path.Base(dir)
+		if suffix == "" {
+			suffix = base
+		} else {
+			suffix = base + "/" + suffix
+		}
+		dir = parent
+	}
+}
+
+func (n normalizer) toNative(c string) string {
+	if n.windows {
+		return strings.ReplaceAll(c, "/", `\`)
+	}
+	return c
+}
+
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
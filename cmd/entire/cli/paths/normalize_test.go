@@ -0,0 +1,190 @@
+package paths
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSymlinks resolves paths through a map of symlinked directories, the
+// way filepath.EvalSymlinks would on a host that has them.
+func fakeSymlinks(links map[string]string, exists func(string) bool) func(string) (string, error) {
+	return func(p string) (string, error) {
+		for from, to := range links {
+			if p == from {
+				p = to
+				break
+			}
+			if len(p) > len(from) && p[:len(from)] == from && (p[len(from)] == '/' || p[len(from)] == '\\') {
+				p = to + p[len(from):]
+				break
+			}
+		}
+		if !exists(p) {
+			return "", errors.New("no such file or directory")
+		}
+		return p, nil
+	}
+}
+
+func TestNormalizer_Canonical(t *testing.T) {
+	t.Parallel()
+
+	unix := normalizer{}
+	windows := normalizer{windows: true, caseInsensitive: true}
+
+	tests := []struct {
+		name string
+		n    normalizer
+		in   string
+		want string
+	}{
+		{"unix empty", unix, "", ""},
+		{"unix relative", unix, "src/main.go", "src/main.go"},
+		{"unix dot prefix", unix, "./src//main.go", "src/main.go"},
+		{"unix dot dot", unix, "src/../main.go", "main.go"},
+		{"unix absolute", unix, "/repo/src/", "/repo/src"},
+		{"unix backslash is a name", unix, `src\main.go`, `src\main.go`},
+		{"windows backslashes", windows, `src\main.go`, "src/main.go"},
+		{"windows drive", windows, `C:\Repo\src\..\main.go`, "C:/Repo/main.go"},
+		{"windows drive root", windows, `C:\`, "C:/"},
+		{"windows drive relative", windows, `C:src\main.go`, "C:src/main.go"},
+		{"windows bare drive", windows, "C:", "C:"},
+		{"windows UNC", windows, `\\server\share\repo\.\src`, "//server/share/repo/src"},
+		{"windows UNC share", windows, `\\server\share`, "//server/share"},
+		{"windows mixed separators", windows, `C:/Repo\src/main.go`, "C:/Repo/src/main.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.n.canonical(tt.in); got != tt.want {
+				t.Errorf("canonical(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizer_RepoRelative(t *testing.T) {
+	t.Parallel()
+
+	unix := normalizer{}
+	darwin := normalizer{
+		caseInsensitive: true,
+		evalSymlinks: fakeSymlinks(map[string]string{"/tmp": "/private/tmp"}, func(p string) bool {
+			return p == "/private/tmp" || p == "/private/tmp/repo" || p == "/private/tmp/repo/src"
+		}),
+	}
+	windows := normalizer{
+		windows:         true,
+		caseInsensitive: true,
+		evalSymlinks: fakeSymlinks(map[string]string{`S:`: `\\server\share`}, func(p string) bool {
+			return p == `\\server\share\repo` || p == `C:\Repo`
+		}),
+	}
+
+	tests := []struct {
+		name string
+		n    normalizer
+		root string
+		in   string
+		want string
+	}{
+		{"unix relative", unix, "/repo", "./src/main.go", "src/main.go"},
+		{"unix relative escaping root", unix, "/repo", "../other/main.go", ""},
+		{"unix absolute inside", unix, "/repo", "/repo/src/main.go", "src/main.go"},
+		{"unix root itself", unix, "/repo", "/repo/", "."},
+		{"unix absolute outside", unix, "/repo", "/other/main.go", ""},
+		{"unix sibling with common prefix", unix, "/repo", "/repo-other/main.go", ""},
+		{"unix case matters", unix, "/Repo", "/repo/main.go", ""},
+		{"unix filesystem root", unix, "/", "/etc/hosts", "etc/hosts"},
+
+		{"darwin case folds", darwin, "/Users/me/Repo", "/users/me/repo/Src/Main.go", "Src/Main.go"},
+		{"darwin symlinked file", darwin, "/private/tmp/repo", "/tmp/repo/src/main.go", "src/main.go"},
+		{"darwin symlinked root", darwin, "/tmp/repo", "/private/tmp/repo/new.go", "new.go"},
+		{"darwin deleted dir under symlink", darwin, "/private/tmp/repo", "/tmp/repo/gone/a.go", "gone/a.go"},
+		{"darwin symlinked outside", darwin, "/private/tmp/repo", "/tmp/other/a.go", ""},
+
+		{"windows drive", windows, `C:\Repo`, `C:\Repo\src\main.go`, "src/main.go"},
+		{"windows drive case", windows, `C:\Repo`, `c:\repo\Src\main.go`, "Src/main.go"},
+		{"windows slashes", windows, `C:\Repo`, "C:/Repo/src/main.go", "src/main.go"},
+		{"windows other drive", windows, `C:\Repo`, `D:\Repo\main.go`, ""},
+		{"windows relative", windows, `C:\Repo`, `src\main.go`, "src/main.go"},
+		{"windows UNC", windows, `\\server\share\repo`, `\\SERVER\share\repo\main.go`, "main.go"},
+		{"windows mapped drive", windows, `\\server\share\repo`, `S:\repo\main.go`, "main.go"},
+		{"windows UNC outside share", windows, `\\server\share\repo`, `\\server\other\repo\main.go`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.n.repoRelative(tt.root, tt.in); got != tt.want {
+				t.Errorf("repoRelative(%q, %q) = %q, want %q", tt.root, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizer_Key(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		n    normalizer
+		a, b string
+		same bool
+	}{
+		{"unix same", normalizer{}, "src/main.go", "./src/main.go", true},
+		{"unix case differs", normalizer{}, "src/Main.go", "src/main.go", false},
+		{"darwin case differs", normalizer{caseInsensitive: true}, "src/Main.go", "SRC/main.go", true},
+		{"windows separators", normalizer{windows: true, caseInsensitive: true}, `src\main.go`, "src/main.go", true},
+		{"windows drive letter case", normalizer{windows: true}, `c:\Repo`, `C:\Repo`, true},
+		{"windows name case on case-sensitive volume", normalizer{windows: true}, `C:\repo`, `C:\Repo`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a, b := tt.n.key(tt.n.canonical(tt.a)), tt.n.key(tt.n.canonical(tt.b))
+			if (a == b) != tt.same {
+				t.Errorf("key(%q) = %q, key(%q) = %q, want same = %v", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestResolveSymlinks(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	realDir := filepath.Join(dir, "real")
+	if err := os.MkdirAll(filepath.Join(realDir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(realDir, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	resolvedReal, err := filepath.EvalSymlinks(realDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{link, resolvedReal},
+		{filepath.Join(link, "sub"), filepath.Join(resolvedReal, "sub")},
+		// Paths that don't exist yet resolve through their existing parent.
+		{filepath.Join(link, "sub", "new.go"), filepath.Join(resolvedReal, "sub", "new.go")},
+		{filepath.Join(link, "missing", "new.go"), filepath.Join(resolvedReal, "missing", "new.go")},
+	}
+	for _, tt := range tests {
+		if got := ResolveSymlinks(tt.in); got != tt.want {
+			t.Errorf("ResolveSymlinks(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := RepoRelative(link, filepath.Join(resolvedReal, "sub", "a.go")); got != "sub/a.go" {
+		t.Errorf("RepoRelative() through symlinked root = %q, want %q", got, "sub/a.go")
+	}
+}
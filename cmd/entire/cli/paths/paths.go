@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
 )
 
 // Directory constants
@@ -18,12 +19,16 @@ const (
 	EntireDir         = ".entire"
 	EntireTmpDir      = ".entire/tmp"
 	EntireMetadataDir = ".entire/metadata"
+	EntireDebugDir    = ".entire/debug"
+	EntireCacheDir    = ".entire/cache"
+	EntirePatchesDir  = ".entire/patches"
 )
 
 // Metadata file names
 const (
 	ContextFileName          = "context.md"
 	PromptFileName           = "prompt.txt"
+	ReviewFileName           = "review.md"
 	SummaryFileName          = "summary.txt"
 	TranscriptFileName       = "full.jsonl"
 	TranscriptFileNameLegacy = "full.log"
@@ -31,11 +36,12 @@ const (
 	CheckpointFileName       = "checkpoint.json"
 	ContentHashFileName      = "content_hash.txt"
 	SettingsFileName         = "settings.json"
+	RevisionFileName         = "revision.json"
+	RevisionsDirName         = "revisions"
+	SnapshotFileName         = "snapshot.json"
+	OmittedFileName          = "omitted.json"
 )
 
-// MetadataBranchName is the orphan branch used by manual-commit strategy to store metadata
-const MetadataBranchName = "entire/checkpoints/v1"
-
 // CheckpointPath returns the sharded storage path for a checkpoint ID.
 // Uses first 2 characters as shard (256 buckets), remaining as folder name.
 // Example: "a3b2c4d5e6f7" -> "a3/b2c4d5e6f7"
@@ -101,6 +107,10 @@ func ClearWorktreeRootCache() {
 	worktreeRootMu.Unlock()
 }
 
+func init() {
+	repocache.Register(ClearWorktreeRootCache)
+}
+
 // AbsPath returns the absolute path for a relative path within the repository.
 // If the path is already absolute, it is returned as-is.
 // Uses WorktreeRoot() to resolve paths relative to the worktree root.
@@ -123,17 +133,11 @@ func IsInfrastructurePath(path string) bool {
 	return strings.HasPrefix(path, EntireDir+"/") || path == EntireDir
 }
 
-// ToRelativePath converts an absolute path to relative.
+// ToRelativePath converts a path to canonical form relative to cwd.
 // Returns empty string if the path is outside the working directory.
+// See RepoRelative.
 func ToRelativePath(absPath, cwd string) string {
-	if !filepath.IsAbs(absPath) {
-		return absPath
-	}
-	relPath, err := filepath.Rel(cwd, absPath)
-	if err != nil || strings.HasPrefix(relPath, "..") {
-		return ""
-	}
-	return relPath
+	return RepoRelative(cwd, absPath)
 }
 
 // nonAlphanumericRegex matches any non-alphanumeric character
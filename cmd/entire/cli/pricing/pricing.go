@@ -0,0 +1,163 @@
+// Package pricing turns agent token usage into approximate dollar costs.
+//
+// Prices are list prices in USD per million tokens. Usage is priced at the
+// model its transcript names when that model has a price, and otherwise at the
+// rates of the agent's usual default model. The results are estimates and must
+// always be labeled as such when shown to users.
+package pricing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write,omitempty"`
+	CacheRead  float64 `json:"cache_read,omitempty"`
+}
+
+// Currency converts USD estimates into another currency for display.
+type Currency struct {
+	// Code is the ISO 4217 currency code, e.g. "EUR".
+	Code string `json:"code"`
+	// PerUSD is how many units of this currency one US dollar buys.
+	PerUSD float64 `json:"per_usd"`
+}
+
+// USD is the currency prices are defined in.
+var USD = Currency{Code: "USD", PerUSD: 1}
+
+// DefaultPrices are the bundled list prices, keyed by model name.
+// Users can override or extend them with the "pricing" settings key.
+var DefaultPrices = map[string]ModelPrice{
+	"claude-opus-4":    {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+	"claude-sonnet-4":  {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-haiku-4":   {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10, CacheRead: 0.31},
+	"gemini-2.5-flash": {Input: 0.30, Output: 2.50, CacheRead: 0.075},
+}
+
+// defaultModels maps each agent to the model its usage is priced at.
+// Agents without an entry (e.g. OpenCode, which is model-agnostic) are not priced.
+var defaultModels = map[agent.AgentType]string{
+	agent.AgentTypeClaudeCode: "claude-sonnet-4",
+	agent.AgentTypeGemini:     "gemini-2.5-pro",
+}
+
+// currencySymbols are prefixes used instead of the currency code for common currencies.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+}
+
+// Estimator computes cost estimates from token usage.
+type Estimator struct {
+	prices   map[string]ModelPrice
+	currency Currency
+}
+
+// NewEstimator returns an estimator using the bundled prices with overrides
+// applied on top. Overrides replace the whole entry for a model. A zero or
+// empty currency means USD.
+func NewEstimator(overrides map[string]ModelPrice, currency Currency) *Estimator {
+	prices := make(map[string]ModelPrice, len(DefaultPrices)+len(overrides))
+	for model, price := range DefaultPrices {
+		prices[model] = price
+	}
+	for model, price := range overrides {
+		prices[model] = price
+	}
+	if currency.Code == "" || currency.PerUSD <= 0 {
+		currency = USD
+	}
+	return &Estimator{prices: prices, currency: currency}
+}
+
+// Estimate is an approximate cost in the estimator's currency.
+type Estimate struct {
+	Amount   float64
+	Currency Currency
+	// Model is the model whose prices were used.
+	Model string
+}
+
+// Estimate returns the approximate cost of usage by the given agent, including
+// subagent usage. Returns false if neither the usage's model nor the agent's
+// default model has a known price, or there is no usage to price.
+func (e *Estimator) Estimate(agentType agent.AgentType, usage *agent.TokenUsage) (Estimate, bool) {
+	if usage == nil {
+		return Estimate{}, false
+	}
+	model, ok := e.lookup(usage.Model)
+	if !ok {
+		if model, ok = defaultModels[agentType]; !ok {
+			return Estimate{}, false
+		}
+		if _, ok = e.prices[model]; !ok {
+			return Estimate{}, false
+		}
+	}
+	usd := e.costUSD(e.prices[model], usage)
+	return Estimate{Amount: usd * e.currency.PerUSD, Currency: e.currency, Model: model}, true
+}
+
+// lookup returns the priced model for a model name from a transcript: an exact
+// match, or else the longest priced name it starts with, so dated releases
+// such as "claude-sonnet-4-5-20250929" use the "claude-sonnet-4" price.
+func (e *Estimator) lookup(model string) (string, bool) {
+	if model == "" {
+		return "", false
+	}
+	if _, ok := e.prices[model]; ok {
+		return model, true
+	}
+	best := ""
+	for name := range e.prices {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	return best, best != ""
+}
+
+// costUSD prices usage at price and its subagent usage recursively, at the
+// subagent's own model when it has a price.
+func (e *Estimator) costUSD(price ModelPrice, usage *agent.TokenUsage) float64 {
+	if usage == nil {
+		return 0
+	}
+	const perMillion = 1_000_000
+	cost := float64(usage.InputTokens)*price.Input +
+		float64(usage.OutputTokens)*price.Output +
+		float64(usage.CacheCreationTokens)*price.CacheWrite +
+		float64(usage.CacheReadTokens)*price.CacheRead
+	if sub := usage.SubagentTokens; sub != nil {
+		if model, ok := e.lookup(sub.Model); ok {
+			price = e.prices[model]
+		}
+	}
+	return cost/perMillion + e.costUSD(price, usage.SubagentTokens)
+}
+
+// String formats the estimate for display, e.g. "~$1.23" or "~12.50 CHF".
+// Amounts below one cent are shown as "<$0.01".
+func (e Estimate) String() string {
+	code := strings.ToUpper(e.Currency.Code)
+	amount := fmt.Sprintf("%.2f", e.Amount)
+	prefix := "~"
+	if e.Amount > 0 && e.Amount < 0.01 {
+		amount = "0.01"
+		prefix = "<"
+	}
+	if symbol, ok := currencySymbols[code]; ok {
+		return prefix + symbol + amount
+	}
+	return prefix + amount + " " + code
+}
@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestEstimate_UsesDefaultModelPrices(t *testing.T) {
+	t.Parallel()
+
+	usage := &agent.TokenUsage{
+		InputTokens:         1_000_000,
+		OutputTokens:        100_000,
+		CacheCreationTokens: 200_000,
+		CacheReadTokens:     1_000_000,
+		SubagentTokens:      &agent.TokenUsage{OutputTokens: 100_000},
+	}
+
+	est, ok := NewEstimator(nil, USD).Estimate(agent.AgentTypeClaudeCode, usage)
+	if !ok {
+		t.Fatal("expected an estimate for Claude Code")
+	}
+	// 3 input + 1.5 output + 0.75 cache write + 0.30 cache read + 1.5 subagent output
+	if want := 7.05; math.Abs(est.Amount-want) > 1e-9 {
+		t.Errorf("Amount = %v, want %v", est.Amount, want)
+	}
+	if est.Model != "claude-sonnet-4" {
+		t.Errorf("Model = %q, want %q", est.Model, "claude-sonnet-4")
+	}
+}
+
+func TestEstimate_UsesTranscriptModel(t *testing.T) {
+	t.Parallel()
+
+	e := NewEstimator(map[string]ModelPrice{"claude-opus-4-1": {Input: 20, Output: 100}}, USD)
+	usage := &agent.TokenUsage{
+		InputTokens:    1_000_000,
+		Model:          "claude-opus-4-20250514",
+		SubagentTokens: &agent.TokenUsage{InputTokens: 1_000_000, Model: "claude-haiku-4-5-20251001"},
+	}
+	est, ok := e.Estimate(agent.AgentTypeClaudeCode, usage)
+	if !ok {
+		t.Fatal("expected an estimate")
+	}
+	// 15 opus input + 1 haiku subagent input
+	if want := 16.0; math.Abs(est.Amount-want) > 1e-9 {
+		t.Errorf("Amount = %v, want %v", est.Amount, want)
+	}
+	if est.Model != "claude-opus-4" {
+		t.Errorf("Model = %q, want %q", est.Model, "claude-opus-4")
+	}
+
+	// The longest configured prefix wins
+	usage = &agent.TokenUsage{InputTokens: 1_000_000, Model: "claude-opus-4-1-20250805"}
+	if est, _ = e.Estimate(agent.AgentTypeClaudeCode, usage); est.Model != "claude-opus-4-1" || est.Amount != 20 {
+		t.Errorf("Estimate = %+v, want the claude-opus-4-1 price", est)
+	}
+
+	// Unknown models fall back to the agent's default model
+	usage = &agent.TokenUsage{InputTokens: 1_000_000, Model: "claude-3-5-sonnet-20241022"}
+	if est, _ = e.Estimate(agent.AgentTypeClaudeCode, usage); est.Model != "claude-sonnet-4" {
+		t.Errorf("Model = %q, want %q", est.Model, "claude-sonnet-4")
+	}
+	if est, ok = e.Estimate(agent.AgentTypeOpenCode, &agent.TokenUsage{InputTokens: 1_000_000, Model: "gemini-2.5-flash"}); !ok || est.Model != "gemini-2.5-flash" {
+		t.Errorf("Estimate = %+v, %v; want a gemini-2.5-flash estimate for a model-agnostic agent", est, ok)
+	}
+}
+
+func TestEstimate_OverridesAndCurrency(t *testing.T) {
+	t.Parallel()
+
+	overrides := map[string]ModelPrice{"gemini-2.5-pro": {Input: 10, Output: 10}}
+	est, ok := NewEstimator(overrides, Currency{Code: "EUR", PerUSD: 0.5}).
+		Estimate(agent.AgentTypeGemini, &agent.TokenUsage{InputTokens: 500_000, OutputTokens: 500_000})
+	if !ok {
+		t.Fatal("expected an estimate for Gemini CLI")
+	}
+	if want := 5.0; math.Abs(est.Amount-want) > 1e-9 {
+		t.Errorf("Amount = %v, want %v", est.Amount, want)
+	}
+	if got := est.String(); got != "~€5.00" {
+		t.Errorf("String() = %q, want %q", got, "~€5.00")
+	}
+}
+
+func TestEstimate_UnpricedAgent(t *testing.T) {
+	t.Parallel()
+
+	e := NewEstimator(nil, USD)
+	if _, ok := e.Estimate(agent.AgentTypeOpenCode, &agent.TokenUsage{InputTokens: 100}); ok {
+		t.Error("expected no estimate for an agent without a default model")
+	}
+	if _, ok := e.Estimate(agent.AgentTypeClaudeCode, nil); ok {
+		t.Error("expected no estimate without usage")
+	}
+}
+
+func TestEstimateString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		est  Estimate
+		want string
+	}{
+		{Estimate{Amount: 1.234, Currency: USD}, "~$1.23"},
+		{Estimate{Amount: 0.001, Currency: USD}, "<$0.01"},
+		{Estimate{Amount: 0, Currency: USD}, "~$0.00"},
+		{Estimate{Amount: 12.5, Currency: Currency{Code: "chf", PerUSD: 0.9}}, "~12.50 CHF"},
+	}
+	for _, tt := range tests {
+		if got := tt.est.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// privacyExport is everything Entire stores in this repository about one
+// author, as written by `entire privacy export`.
+type privacyExport struct {
+	Author      string              `json:"author"`
+	GeneratedAt time.Time           `json:"generated_at"`
+	CLIVersion  string              `json:"cli_version"`
+	Sessions    []*session.State    `json:"sessions"`
+	Checkpoints []privacyCheckpoint `json:"checkpoints"`
+}
+
+// privacyCheckpoint is one session's stored content in one checkpoint.
+type privacyCheckpoint struct {
+	CheckpointID string                       `json:"checkpoint_id"`
+	SessionID    string                       `json:"session_id"`
+	AuthorName   string                       `json:"author_name"`
+	AuthorEmail  string                       `json:"author_email"`
+	Metadata     checkpoint.CommittedMetadata `json:"metadata"`
+	Prompts      string                       `json:"prompts,omitempty"`
+	Context      string                       `json:"context,omitempty"`
+	Transcript   string                       `json:"transcript,omitempty"`
+}
+
+// authoredSession is a session whose content an author wrote to a checkpoint.
+type authoredSession struct {
+	checkpointID id.CheckpointID
+	sessionID    string
+	author       object.Signature
+}
+
+func newPrivacyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "privacy",
+		Short: "Export or erase the data Entire stores about a person or session",
+		Long: `Answer data subject access and erasure requests for the data Entire stores
+in this repository: session state, prompts, transcripts, context, and
+summaries on ` + paths.MetadataBranchName() + `.`,
+	}
+
+	cmd.AddCommand(newPrivacyExportCmd())
+	cmd.AddCommand(newPrivacyEraseCmd())
+
+	return cmd
+}
+
+func newPrivacyExportCmd() *cobra.Command {
+	var authorFlag string
+	var outputFlag string
+
+	cmd := &cobra.Command{
+		Use:   "export --author <email>",
+		Short: "Export all stored data related to an author",
+		Long: `Export everything Entire stores about an author as a single JSON document.
+
+Checkpoint content (metadata, prompts, context, and transcript) is included
+for every session whose checkpoint commit on ` + paths.MetadataBranchName() + ` was
+authored with the given email, matched case-insensitively. Local session
+states are included for those sessions, and all of them when the email is
+the repository's configured user.email.
+
+The document is written to stdout unless --output names a file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if strings.TrimSpace(authorFlag) == "" {
+				return errors.New("--author is required")
+			}
+			return runPrivacyExport(cmd, strings.TrimSpace(authorFlag), outputFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&authorFlag, "author", "", "Email address of the author whose data to export")
+	cmd.Flags().StringVarP(&outputFlag, "output", "o", "", "File to write the export to")
+
+	return cmd
+}
+
+func newPrivacyEraseCmd() *cobra.Command {
+	var sessionFlag string
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "erase --session <id>",
+		Short: "Erase a session's stored data from local state and history",
+		Long: `Erase a session's prompts, transcripts, context, and summaries.
+
+The session's state file and shadow branch are deleted, and the history of
+` + paths.MetadataBranchName() + ` is rewritten so that no commit still stores the
+session's content. Each checkpoint keeps a metadata.json for the session,
+without its summary and marked with erased_at, so checkpoint statistics
+stay intact. The erasure is recorded in the audit log (see 'entire audit').
+
+Copies pushed to a remote are not changed: force-push the rewritten branch
+to erase them there too.
+
+Without --force, prompts for confirmation and refuses active sessions.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if sessionFlag == "" {
+				return errors.New("--session is required")
+			}
+			return runPrivacyErase(cmd, sessionFlag, forceFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "ID of the session to erase")
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Skip confirmation prompt and erase active sessions")
+
+	return cmd
+}
+
+func runPrivacyExport(cmd *cobra.Command, author, output string) error {
+	ctx := context.Background()
+
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+		return NewSilentError(err)
+	}
+
+	export, err := buildPrivacyExport(ctx, repo, author)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output) //nolint:gosec // output path is provided by the user
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writePrivacyExport(w, export); err != nil {
+		return err
+	}
+	if output != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d sessions and %d checkpoint entries for %s to %s\n",
+			len(export.Sessions), len(export.Checkpoints), author, output)
+	}
+	return nil
+}
+
+// buildPrivacyExport collects the checkpoint content and session states
+// stored for author, oldest checkpoint first.
+func buildPrivacyExport(ctx context.Context, repo *git.Repository, author string) (*privacyExport, error) {
+	export := &privacyExport{
+		Author:      author,
+		GeneratedAt: time.Now().UTC(),
+		CLIVersion:  buildinfo.Version,
+		Sessions:    []*session.State{},
+		Checkpoints: []privacyCheckpoint{},
+	}
+
+	authored, err := authoredSessions(repo, author)
+	if err != nil {
+		return nil, err
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	sessionIDs := make(map[string]bool)
+	for _, as := range authored {
+		sessionIDs[as.sessionID] = true
+		content, readErr := store.ReadSessionContentByID(ctx, as.checkpointID, as.sessionID)
+		if readErr != nil || content == nil {
+			continue // Checkpoint removed since, e.g. by entire clean
+		}
+		export.Checkpoints = append(export.Checkpoints, privacyCheckpoint{
+			CheckpointID: as.checkpointID.String(),
+			SessionID:    as.sessionID,
+			AuthorName:   as.author.Name,
+			AuthorEmail:  as.author.Email,
+			Metadata:     content.Metadata,
+			Prompts:      content.Prompts,
+			Context:      content.Context,
+			Transcript:   string(content.Transcript),
+		})
+	}
+
+	_, localEmail := checkpoint.GetGitAuthorFromRepo(repo)
+	isLocalUser := strings.EqualFold(localEmail, author)
+	states, err := strategy.ListSessionStates()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session states: %w", err)
+	}
+	for _, st := range states {
+		if isLocalUser || sessionIDs[st.SessionID] {
+			export.Sessions = append(export.Sessions, st)
+		}
+	}
+	slices.SortFunc(export.Sessions, func(a, b *session.State) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+
+	return export, nil
+}
+
+// authoredSessions returns the sessions whose checkpoint commits on the
+// metadata branch were authored with the given email, oldest first. Each
+// checkpoint commit has the subject "Checkpoint: <id>" and names its session
+// in the Entire-Session trailer.
+func authoredSessions(repo *git.Repository, email string) ([]authoredSession, error) {
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	if err != nil {
+		return nil, nil //nolint:nilerr // No metadata branch, nothing stored
+	}
+	iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s history: %w", paths.MetadataBranchName(), err)
+	}
+	defer iter.Close()
+
+	var authored []authoredSession
+	seen := make(map[string]bool)
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !strings.EqualFold(c.Author.Email, email) {
+			return nil
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		cpID, err := id.NewCheckpointID(strings.TrimSpace(strings.TrimPrefix(subject, "Checkpoint: ")))
+		if err != nil || !strings.HasPrefix(subject, "Checkpoint: ") {
+			return nil //nolint:nilerr // Not a checkpoint commit
+		}
+		sessionID, ok := trailers.ParseSession(c.Message)
+		if !ok {
+			return nil
+		}
+		key := cpID.String() + "/" + sessionID
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+		authored = append(authored, authoredSession{checkpointID: cpID, sessionID: sessionID, author: c.Author})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s history: %w", paths.MetadataBranchName(), err)
+	}
+	slices.Reverse(authored)
+	return authored, nil
+}
+
+func writePrivacyExport(w io.Writer, export *privacyExport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	return nil
+}
+
+func runPrivacyErase(cmd *cobra.Command, sessionID string, force bool) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(err)
+	}
+
+	state, err := strategy.LoadSessionState(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	if state != nil && state.Phase.IsActive() && !force {
+		cmd.SilenceUsage = true
+		err := fmt.Errorf("session %s is still active; end it first or use --force", sessionID)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	if !force {
+		var confirmed bool
+		form := NewAccessibleForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(i18n.Tf("Erase session %s?", sessionID)).
+					Description(i18n.T("This rewrites the history of the checkpoints branch and cannot be undone.")).
+					Value(&confirmed),
+			),
+		)
+		if err := form.Run(); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return nil
+			}
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if state != nil {
+		if err := GetStrategy().ResetSession(sessionID); err != nil {
+			return fmt.Errorf("failed to remove session state: %w", err)
+		}
+	}
+
+	result, err := checkpoint.NewGitStore(repo).EraseSession(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to erase session from %s: %w", paths.MetadataBranchName(), err)
+	}
+
+	if state == nil && len(result.Checkpoints) == 0 {
+		cmd.SilenceUsage = true
+		err := fmt.Errorf("no stored data found for session %s", sessionID)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	ev := audit.Event{Action: audit.ActionSessionErase, Target: sessionID}
+	if !result.OldTip.IsZero() {
+		ev.Old = result.OldTip.String()
+		ev.New = result.NewTip.String()
+	}
+	audit.RecordRepo(repo, ev)
+
+	writePrivacyEraseResult(cmd.OutOrStdout(), sessionID, state != nil, result, hasRemoteMetadataBranch(repo))
+	return nil
+}
+
+// hasRemoteMetadataBranch reports whether origin has a copy of the metadata branch.
+func hasRemoteMetadataBranch(repo *git.Repository) bool {
+	_, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName()), true)
+	return err == nil
+}
+
+func writePrivacyEraseResult(w io.Writer, sessionID string, hadState bool, result checkpoint.EraseSessionResult, hasRemote bool) {
+	fmt.Fprintf(w, "Erased session %s\n", sessionID)
+	if hadState {
+		fmt.Fprintln(w, "  local state:  removed")
+	} else {
+		fmt.Fprintln(w, "  local state:  none")
+	}
+	if len(result.Checkpoints) == 0 {
+		fmt.Fprintln(w, "  checkpoints:  none")
+		return
+	}
+	ids := make([]string, 0, len(result.Checkpoints))
+	for _, cpID := range result.Checkpoints {
+		ids = append(ids, cpID.String())
+	}
+	fmt.Fprintf(w, "  checkpoints:  %s\n", strings.Join(ids, ", "))
+	fmt.Fprintf(w, "  history:      %d commits on %s rewritten\n", result.RewrittenCommits, paths.MetadataBranchName())
+	if hasRemote {
+		fmt.Fprintf(w, "\nThe remote copy still contains the session. To erase it there, run:\n")
+		fmt.Fprintf(w, "  git push --force origin %s\n", paths.MetadataBranchName())
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPrivacyRepo writes checkpoint a1b2c3d4e5f6 with sessions from two
+// authors, alice (2026-05-01-alpha) and bob (2026-05-01-beta), and saves a
+// local state for each session.
+func setupPrivacyRepo(t *testing.T) *git.Repository {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	head := testutil.GetHeadHash(t, tmpDir)
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	for _, sess := range []struct{ id, name, email string }{
+		{"2026-05-01-alpha", "Alice", "alice@example.com"},
+		{"2026-05-01-beta", "Bob", "bob@example.com"},
+	} {
+		require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+			CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+			SessionID:    sess.id,
+			Strategy:     "manual-commit",
+			Transcript:   []byte(`{"type":"user","message":"` + sess.id + ` secret"}` + "\n"),
+			Prompts:      []string{sess.id + " prompt"},
+			FilesTouched: []string{"a.go"},
+			AuthorName:   sess.name,
+			AuthorEmail:  sess.email,
+		}))
+		require.NoError(t, strategy.SaveSessionState(&session.State{
+			SessionID:  sess.id,
+			BaseCommit: head,
+			StartedAt:  time.Now().Add(-time.Hour),
+			Phase:      session.PhaseEnded,
+		}))
+	}
+	return repo
+}
+
+func runPrivacyForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newPrivacyCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPrivacyExport_Author(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupPrivacyRepo(t)
+
+	output, err := runPrivacyForTest(t, "export", "--author", "ALICE@example.com")
+	require.NoError(t, err)
+
+	var export privacyExport
+	require.NoError(t, json.Unmarshal([]byte(output), &export))
+	require.Len(t, export.Checkpoints, 1)
+	cp := export.Checkpoints[0]
+	assert.Equal(t, "a1b2c3d4e5f6", cp.CheckpointID)
+	assert.Equal(t, "2026-05-01-alpha", cp.SessionID)
+	assert.Equal(t, "alice@example.com", cp.AuthorEmail)
+	assert.Contains(t, cp.Transcript, "2026-05-01-alpha secret")
+	assert.Contains(t, cp.Prompts, "2026-05-01-alpha prompt")
+	require.Len(t, export.Sessions, 1)
+	assert.Equal(t, "2026-05-01-alpha", export.Sessions[0].SessionID)
+
+	output, err = runPrivacyForTest(t, "export", "--author", "nobody@example.com")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), &export))
+	assert.Empty(t, export.Checkpoints)
+	assert.Empty(t, export.Sessions)
+}
+
+func TestPrivacyErase_Session(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repo := setupPrivacyRepo(t)
+
+	output, err := runPrivacyForTest(t, "erase", "--session", "2026-05-01-alpha", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Erased session 2026-05-01-alpha")
+	assert.Contains(t, output, "local state:  removed")
+	assert.Contains(t, output, "checkpoints:  a1b2c3d4e5f6")
+
+	state, err := strategy.LoadSessionState("2026-05-01-alpha")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	store := checkpoint.NewGitStore(repo)
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+	erased, err := store.ReadSessionContentByID(context.Background(), cpID, "2026-05-01-alpha")
+	require.NoError(t, err)
+	assert.Empty(t, erased.Transcript)
+	assert.Empty(t, erased.Prompts)
+	assert.NotNil(t, erased.Metadata.ErasedAt)
+	kept, err := store.ReadSessionContentByID(context.Background(), cpID, "2026-05-01-beta")
+	require.NoError(t, err)
+	assert.Contains(t, string(kept.Transcript), "2026-05-01-beta secret")
+
+	events, err := audit.Read(audit.GitDir(repo), time.Time{})
+	require.NoError(t, err)
+	var erasures []audit.Event
+	for _, ev := range events {
+		if ev.Action == audit.ActionSessionErase {
+			erasures = append(erasures, ev)
+		}
+	}
+	require.Len(t, erasures, 1)
+	assert.Equal(t, "2026-05-01-alpha", erasures[0].Target)
+	assert.NotEmpty(t, erasures[0].New)
+
+	_, err = runPrivacyForTest(t, "erase", "--session", "2026-05-01-alpha", "--force")
+	require.Error(t, err, "erasing twice finds nothing left to erase")
+}
+
+func TestPrivacyErase_RefusesActiveSession(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupPrivacyRepo(t)
+
+	state, err := strategy.LoadSessionState("2026-05-01-beta")
+	require.NoError(t, err)
+	state.Phase = session.PhaseActive
+	require.NoError(t, strategy.SaveSessionState(state))
+
+	output, err := runPrivacyForTest(t, "erase", "--session", "2026-05-01-beta")
+	require.Error(t, err)
+	assert.Contains(t, output, "still active")
+
+	state, err = strategy.LoadSessionState("2026-05-01-beta")
+	require.NoError(t, err)
+	assert.NotNil(t, state)
+}
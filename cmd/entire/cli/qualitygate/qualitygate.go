@@ -0,0 +1,97 @@
+// Package qualitygate runs a user-configured test command against the files an
+// agent changed, at the end of each turn. The pass/fail result is kept in the
+// session state and recorded on the checkpoint, so commits of changes that
+// are known to be broken can be flagged before they happen.
+package qualitygate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/shellcmd"
+)
+
+const (
+	// DefaultTimeout bounds a quality gate command when no timeout is configured.
+	DefaultTimeout = 5 * time.Minute
+	// MaxOutputSize caps the stored output of a failed run. Longer output
+	// keeps its tail, where test runners print their failures.
+	MaxOutputSize = 16 * 1024
+)
+
+// Result is the outcome of one quality gate run.
+type Result struct {
+	// Passed is true when the command exited with status 0.
+	Passed bool `json:"passed"`
+	// ExitCode is the command's exit status, or -1 if it timed out.
+	ExitCode int `json:"exit_code"`
+	// Command is the command that was run.
+	Command string `json:"command"`
+	// Files are the files the command was run against.
+	Files []string `json:"files,omitempty"`
+	// Output is the tail of the command's combined output. Only kept when
+	// the gate failed.
+	Output string `json:"output,omitempty"`
+	// TimedOut is set when the command was killed after the timeout.
+	TimedOut bool `json:"timed_out,omitempty"`
+	// RanAt is when the command started.
+	RanAt time.Time `json:"ran_at"`
+}
+
+// Runner runs a quality gate command.
+type Runner struct {
+	// Command is run with `sh -c`. The files are passed as positional
+	// parameters ("$@") and, newline-separated, in ENTIRE_FILES_TOUCHED.
+	Command string
+	// Dir is the working directory, normally the repository root.
+	Dir string
+	// Timeout bounds the command. If zero, DefaultTimeout is used.
+	Timeout time.Duration
+}
+
+// Run executes the command against files. A command that exits non-zero or
+// times out is a failed gate, not an error; an error means the command could
+// not be run at all.
+func (r *Runner) Run(ctx context.Context, files []string) (*Result, error) {
+	if strings.TrimSpace(r.Command) == "" {
+		return nil, errors.New("no quality gate command configured")
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runner := &shellcmd.Runner{Command: r.Command, Name: "entire-quality-gate", Dir: r.Dir, Timeout: timeout, CombinedOutput: true}
+	result := &Result{
+		Passed:  true,
+		Command: r.Command,
+		Files:   files,
+		RanAt:   time.Now(),
+	}
+	run, err := runner.Run(ctx, files)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // already describes the command
+	}
+	if run.ExitCode != 0 {
+		result.Passed = false
+		result.ExitCode = run.ExitCode
+		result.TimedOut = run.TimedOut
+		result.Output = shellcmd.Tail(strings.TrimSpace(string(run.Stdout)), MaxOutputSize)
+	}
+	return result, nil
+}
+
+// Summary describes the result in one line, e.g. "failed (exit 1)".
+func (r *Result) Summary() string {
+	switch {
+	case r.Passed:
+		return "passed"
+	case r.TimedOut:
+		return "timed out"
+	default:
+		return fmt.Sprintf("failed (exit %d)", r.ExitCode)
+	}
+}
@@ -0,0 +1,77 @@
+package qualitygate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_RunPasses(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `test "$#" -eq 2 && test "$1" = a.go && test "$ENTIRE_FILES_TOUCHED" = "$(printf 'a.go\nb.go')"`, Dir: t.TempDir()}
+	result, err := r.Run(context.Background(), []string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.Passed {
+		t.Fatalf("Run() failed: %s\n%s", result.Summary(), result.Output)
+	}
+	if result.Output != "" {
+		t.Errorf("Output = %q, want empty for a passing gate", result.Output)
+	}
+}
+
+func TestRunner_RunFails(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `echo "FAIL: TestThing" >&2; exit 3`, Dir: t.TempDir()}
+	result, err := r.Run(context.Background(), []string{"a.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Passed || result.ExitCode != 3 {
+		t.Errorf("Run() = passed %v, exit %d; want failed, exit 3", result.Passed, result.ExitCode)
+	}
+	if result.Output != "FAIL: TestThing" {
+		t.Errorf("Output = %q, want %q", result.Output, "FAIL: TestThing")
+	}
+	if got := result.Summary(); got != "failed (exit 3)" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
+
+func TestRunner_RunTimesOut(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: "sleep 5", Dir: t.TempDir(), Timeout: 50 * time.Millisecond}
+	result, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Passed || !result.TimedOut {
+		t.Errorf("Run() = %+v, want a timed-out failure", result)
+	}
+}
+
+func TestRunner_RunKeepsOutputTail(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `head -c 20000 /dev/zero | tr '\0' x; echo; echo LAST; exit 1`, Dir: t.TempDir()}
+	result, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !strings.HasPrefix(result.Output, "[output truncated]") || !strings.HasSuffix(result.Output, "LAST") {
+		t.Errorf("Output was not cut to its tail: %q...", result.Output[:40])
+	}
+}
+
+func TestRunner_RunWithoutCommand(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (&Runner{}).Run(context.Background(), nil); err == nil {
+		t.Error("Run() with no command should fail")
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/entireio/cli/redact"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runRedactForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newRedactCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestRedactAudit_FindsSecretsMatchingNewRules(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a")
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-05-01-alpha",
+		Strategy:     "manual-commit",
+		Transcript: []byte(`{"type":"user","message":"hello"}
+{"type":"assistant","message":"use acme_abcdefgh to log in"}
+`),
+		Prompts:      []string{"log in"},
+		FilesTouched: []string{"a.go"},
+	}))
+
+	output, err := runRedactForTest(t, "--audit")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Scanned 1 checkpoint(s): no secrets found.")
+
+	// A rule added after the checkpoint was written finds the stored token
+	redact.SetRulesGetter(func() redact.Rules {
+		return redact.Rules{Patterns: []redact.Rule{{Name: "acme-token", Pattern: `acme_[a-z]{8}`}}}
+	})
+	t.Cleanup(func() { redact.SetRulesGetter(nil) })
+
+	output, err = runRedactForTest(t, "--audit")
+	var silent *SilentError
+	require.ErrorAs(t, err, &silent)
+	assert.Contains(t, output, "a1b2c3d4e5f6  2026-05-01-alpha  full.jsonl:2  acme-token")
+	assert.Contains(t, output, "Found 1 possible secret(s) in 1 of 1 checkpoint(s).")
+	assert.NotContains(t, output, "acme_abcdefgh")
+
+	_, err = runRedactForTest(t)
+	require.Error(t, err)
+}
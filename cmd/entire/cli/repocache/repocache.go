@@ -0,0 +1,31 @@
+// Package repocache tracks the process-wide caches of state read from a
+// repository, such as its root, git dir, or settings. Packages that keep such
+// a cache register a function that clears it from init, and long-lived
+// processes like the hook daemon call Clear before each request, so a cache
+// added later cannot be missed by a hand-maintained list.
+package repocache
+
+import "sync"
+
+var (
+	mu       sync.Mutex
+	clearers []func()
+)
+
+// Register adds fn to the functions Clear calls. Call it from init.
+func Register(fn func()) {
+	mu.Lock()
+	defer mu.Unlock()
+	clearers = append(clearers, fn)
+}
+
+// Clear calls every registered function, forgetting everything cached from
+// the current repository.
+func Clear() {
+	mu.Lock()
+	fns := clearers
+	mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
@@ -0,0 +1,21 @@
+package repocache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClear_CallsEveryRegisteredFunction(t *testing.T) {
+	// Cannot use t.Parallel() because we change the package-level registry
+	saved := clearers
+	clearers = nil
+	t.Cleanup(func() { clearers = saved })
+
+	var calls []string
+	Register(func() { calls = append(calls, "a") })
+	Register(func() { calls = append(calls, "b") })
+
+	Clear()
+	assert.Equal(t, []string{"a", "b"}, calls)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
@@ -74,7 +75,7 @@ Without --force, prompts for confirmation before deleting.`,
 				form := NewAccessibleForm(
 					huh.NewGroup(
 						huh.NewConfirm().
-							Title("Reset session data?").
+							Title(i18n.T("Reset session data?")).
 							Value(&confirmed),
 					),
 				)
@@ -120,8 +121,8 @@ func runResetSession(cmd *cobra.Command, strat strategy.Strategy, sessionID stri
 	if !force {
 		var confirmed bool
 
-		title := fmt.Sprintf("Reset session %s?", sessionID)
-		description := fmt.Sprintf("Phase: %s, Checkpoints: %d", state.Phase, state.StepCount)
+		title := i18n.Tf("Reset session %s?", sessionID)
+		description := i18n.Tf("Phase: %s, Checkpoints: %d", state.Phase, state.StepCount)
 
 		form := NewAccessibleForm(
 			huh.NewGroup(
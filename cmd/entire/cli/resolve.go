@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// resolvedCheckpoint is a checkpoint referenced by a commit trailer or
+// linked to the commit by `entire stamp`.
+type resolvedCheckpoint struct {
+	CheckpointID string   `json:"checkpoint_id"`
+	Path         string   `json:"path"`
+	SessionIDs   []string `json:"session_ids"`
+	// Missing is set when the checkpoint is not on the local metadata branch,
+	// e.g. because it has not been fetched.
+	Missing bool `json:"missing,omitempty"`
+	// Stamped is set when the link comes from `entire stamp` rather than a
+	// trailer on the commit.
+	Stamped bool `json:"stamped,omitempty"`
+}
+
+// resolvedCommit is a commit together with its checkpoints.
+type resolvedCommit struct {
+	Commit      string               `json:"commit"`
+	Subject     string               `json:"subject"`
+	Checkpoints []resolvedCheckpoint `json:"checkpoints"`
+}
+
+func newResolveCmd() *cobra.Command {
+	var reverseFlag bool
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "resolve <commit-ish>",
+		Short: "Map a commit to its checkpoints and sessions, or a session to its commits",
+		Long: `Print the checkpoint IDs in a commit's Entire-Checkpoint trailers (or
+linked to it by 'entire stamp'), the sessions that contributed to each checkpoint, and where each checkpoint is
+stored on the entire/checkpoints/v1 branch.
+
+With --reverse, the argument is a session ID (or unique prefix) and every
+commit on a local branch whose checkpoints include that session is listed.
+
+Use --json for output meant for scripts: one JSON object per commit.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runResolve(cmd, args[0], reverseFlag, jsonFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&reverseFlag, "reverse", false, "Treat the argument as a session ID and list its commits")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output one JSON object per commit")
+
+	return cmd
+}
+
+func runResolve(cmd *cobra.Command, arg string, reverse, asJSON bool) error {
+	errW := cmd.ErrOrStderr()
+
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	sessionsByCheckpoint := make(map[id.CheckpointID][]string, len(committed))
+	for _, info := range committed {
+		sessionsByCheckpoint[info.CheckpointID] = info.SessionIDs
+	}
+	stamps := newStampIndex(committed)
+
+	var results []resolvedCommit
+	var sessionID string
+	if reverse {
+		sessionID, err = matchCommittedSession(committed, arg)
+		if err != nil {
+			cmd.SilenceUsage = true
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+		results, err = commitsForSession(repo, sessionID, sessionsByCheckpoint, stamps)
+		if err != nil {
+			return err
+		}
+	} else {
+		hash, resolveErr := repo.ResolveRevision(plumbing.Revision(arg))
+		if resolveErr != nil {
+			cmd.SilenceUsage = true
+			err = fmt.Errorf("commit not found: %s", arg)
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+		commit, commitErr := repo.CommitObject(*hash)
+		if commitErr != nil {
+			return fmt.Errorf("failed to get commit: %w", commitErr)
+		}
+		results = []resolvedCommit{resolveCommit(commit, sessionsByCheckpoint, stamps)}
+	}
+
+	w := cmd.OutOrStdout()
+	if asJSON {
+		return writeResolveJSON(w, results)
+	}
+	if reverse {
+		writeResolvedSession(w, sessionID, results)
+	} else {
+		writeResolvedCommit(w, results[0])
+	}
+	return nil
+}
+
+// resolveCommit collects the checkpoints named by the commit's trailers,
+// followed by any stamped onto it.
+func resolveCommit(commit *object.Commit, sessionsByCheckpoint map[id.CheckpointID][]string, stamps stampIndex) resolvedCommit {
+	rc := resolvedCommit{
+		Commit:      commit.Hash.String(),
+		Subject:     strings.Split(commit.Message, "\n")[0],
+		Checkpoints: []resolvedCheckpoint{},
+	}
+	fromTrailers := trailers.ParseAllCheckpoints(commit.Message)
+	for _, cpID := range stamps.checkpointsFor(commit) {
+		sessionIDs, found := sessionsByCheckpoint[cpID]
+		if sessionIDs == nil {
+			sessionIDs = []string{}
+		}
+		rc.Checkpoints = append(rc.Checkpoints, resolvedCheckpoint{
+			CheckpointID: cpID.String(),
+			Path:         paths.MetadataBranchName() + ":" + cpID.Path(),
+			SessionIDs:   sessionIDs,
+			Missing:      !found,
+			Stamped:      !slices.Contains(fromTrailers, cpID),
+		})
+	}
+	return rc
+}
+
+// matchCommittedSession returns the committed session ID equal to or uniquely
+// prefixed by prefix.
+func matchCommittedSession(committed []checkpoint.CommittedInfo, prefix string) (string, error) {
+	matches := make(map[string]struct{})
+	for _, info := range committed {
+		for _, sid := range info.SessionIDs {
+			if sid == prefix {
+				return sid, nil
+			}
+			if strings.HasPrefix(sid, prefix) {
+				matches[sid] = struct{}{}
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no checkpoints found for session %q", prefix)
+	case 1:
+		for sid := range matches {
+			return sid, nil
+		}
+	}
+	ids := make([]string, 0, len(matches))
+	for sid := range matches {
+		ids = append(ids, sid)
+	}
+	sort.Strings(ids)
+	return "", fmt.Errorf("session prefix %q is ambiguous, matches: %s", prefix, strings.Join(ids, ", "))
+}
+
+// commitsForSession walks every local branch except Entire's own and returns
+// the commits whose checkpoints include sessionID, newest first.
+func commitsForSession(repo *git.Repository, sessionID string, sessionsByCheckpoint map[id.CheckpointID][]string, stamps stampIndex) ([]resolvedCommit, error) {
+	var found []*object.Commit
+	err := forEachLocalBranchCommit(repo, func(c *object.Commit) {
+		for _, cpID := range stamps.checkpointsFor(c) {
+			if slices.Contains(sessionsByCheckpoint[cpID], sessionID) {
+				found = append(found, c)
+				return
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Committer.When.After(found[j].Committer.When)
+	})
+	results := make([]resolvedCommit, 0, len(found))
+	for _, c := range found {
+		results = append(results, resolveCommit(c, sessionsByCheckpoint, stamps))
+	}
+	return results, nil
+}
+
+// forEachLocalBranchCommit calls fn once for every commit reachable from a
+// local branch, skipping Entire's own entire/* branches.
+func forEachLocalBranchCommit(repo *git.Repository, fn func(*object.Commit)) error {
+	branches, err := repo.Branches()
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	var heads []plumbing.Hash
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		if name := ref.Name().Short(); !strings.HasPrefix(name, checkpoint.ShadowBranchPrefix()) && name != paths.MetadataBranchName() {
+			heads = append(heads, ref.Hash())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	seen := make(map[plumbing.Hash]struct{})
+	for _, head := range heads {
+		iter, logErr := repo.Log(&git.LogOptions{From: head})
+		if logErr != nil {
+			return fmt.Errorf("failed to get commit log: %w", logErr)
+		}
+		err = iter.ForEach(func(c *object.Commit) error {
+			if _, ok := seen[c.Hash]; ok {
+				return nil
+			}
+			seen[c.Hash] = struct{}{}
+			fn(c)
+			return nil
+		})
+		iter.Close()
+		if err != nil {
+			return fmt.Errorf("error iterating commits: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeResolveJSON(w io.Writer, results []resolvedCommit) error {
+	enc := json.NewEncoder(w)
+	for _, rc := range results {
+		if err := enc.Encode(rc); err != nil {
+			return fmt.Errorf("failed to encode commit: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeResolvedCommit(w io.Writer, rc resolvedCommit) {
+	if len(rc.Checkpoints) == 0 {
+		fmt.Fprintf(w, "Commit %s has no %s trailer.\n", strategy.TruncateHash(rc.Commit), trailers.CheckpointTrailerKey())
+		return
+	}
+	fmt.Fprintf(w, "Commit %s\n", rc.Commit)
+	for _, cp := range rc.Checkpoints {
+		fmt.Fprintf(w, "\nCheckpoint: %s\n", cp.CheckpointID)
+		if cp.Missing {
+			fmt.Fprintf(w, "  Not found on %s (try fetching it)\n", paths.MetadataBranchName())
+			continue
+		}
+		fmt.Fprintf(w, "  Path:     %s\n", cp.Path)
+		fmt.Fprintf(w, "  Sessions: %s\n", strings.Join(cp.SessionIDs, ", "))
+		if cp.Stamped {
+			fmt.Fprintln(w, "  Linked:   by 'entire stamp' (no trailer on the commit)")
+		}
+	}
+}
+
+func writeResolvedSession(w io.Writer, sessionID string, results []resolvedCommit) {
+	if len(results) == 0 {
+		fmt.Fprintf(w, "No commits on local branches reference session %s.\n", sessionID)
+		return
+	}
+	fmt.Fprintf(w, "Commits for session %s:\n", sessionID)
+	for _, rc := range results {
+		cpIDs := make([]string, 0, len(rc.Checkpoints))
+		for _, cp := range rc.Checkpoints {
+			cpIDs = append(cpIDs, cp.CheckpointID)
+		}
+		fmt.Fprintf(w, "  %s  %s  %s\n", strategy.TruncateHash(rc.Commit), strings.Join(cpIDs, ","), rc.Subject)
+	}
+}
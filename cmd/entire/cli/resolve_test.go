@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupResolveRepo creates three commits: the first references a checkpoint
+// shared by two sessions, the second a checkpoint from one of them, and the
+// third has no trailer.
+func setupResolveRepo(t *testing.T) (first, second string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	first = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "b.go", "package b\n")
+	testutil.GitAdd(t, tmpDir, "b.go")
+	testutil.GitCommit(t, tmpDir, "Add b\n\nEntire-Checkpoint: 0123456789ab\n")
+	second = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "c.go", "package c\n")
+	testutil.GitAdd(t, tmpDir, "c.go")
+	testutil.GitCommit(t, tmpDir, "Add c")
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	for _, cp := range []struct{ id, session string }{
+		{"a1b2c3d4e5f6", "2026-04-01-alpha"},
+		{"a1b2c3d4e5f6", "2026-04-01-beta"},
+		{"0123456789ab", "2026-04-01-alpha"},
+	} {
+		require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+			CheckpointID: id.MustCheckpointID(cp.id),
+			SessionID:    cp.session,
+			Strategy:     "manual-commit",
+			FilesTouched: []string{"a.go"},
+		}))
+	}
+	return first, second
+}
+
+func runResolveForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newResolveCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestResolve_Commit(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	first, _ := setupResolveRepo(t)
+
+	output, err := runResolveForTest(t, "HEAD~2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Commit "+first)
+	assert.Contains(t, output, "Checkpoint: a1b2c3d4e5f6")
+	assert.Contains(t, output, "Path:     entire/checkpoints/v1:a1/b2c3d4e5f6")
+	assert.Contains(t, output, "Sessions: 2026-04-01-alpha, 2026-04-01-beta")
+
+	output, err = runResolveForTest(t, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, output, "has no Entire-Checkpoint trailer")
+
+	_, err = runResolveForTest(t, "no-such-ref")
+	require.Error(t, err)
+}
+
+func TestResolve_Reverse(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	first, second := setupResolveRepo(t)
+
+	output, err := runResolveForTest(t, "--reverse", "--json", "2026-04-01-al")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 2)
+
+	var got []resolvedCommit
+	for _, line := range lines {
+		var rc resolvedCommit
+		require.NoError(t, json.Unmarshal([]byte(line), &rc))
+		got = append(got, rc)
+	}
+	assert.ElementsMatch(t, []string{first, second}, []string{got[0].Commit, got[1].Commit})
+
+	output, err = runResolveForTest(t, "--reverse", "2026-04-01-beta")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Commits for session 2026-04-01-beta:")
+	assert.Contains(t, output, "a1b2c3d4e5f6  Add a")
+	assert.NotContains(t, output, "Add b")
+
+	output, err = runResolveForTest(t, "--reverse", "2026-04-01")
+	require.Error(t, err)
+	assert.Contains(t, output, "is ambiguous")
+}
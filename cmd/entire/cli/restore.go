@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// restoreAction is what `entire restore` does to one file.
+type restoreAction string
+
+const (
+	restoreWrite     restoreAction = "restore"
+	restoreDelete    restoreAction = "delete"
+	restoreUnchanged restoreAction = "unchanged"
+)
+
+// restoreFile is one file touched by the checkpoint being restored.
+type restoreFile struct {
+	Path   string
+	Action restoreAction
+	// Conflict is set when the worktree copy has uncommitted changes that
+	// restoring would discard.
+	Conflict bool
+
+	content []byte
+	mode    filemode.FileMode
+}
+
+func newRestoreCmd() *cobra.Command {
+	var dryRunFlag bool
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <checkpoint-id>",
+		Short: "Restore the files a checkpoint touched into the worktree",
+		Long: `Restore every file a committed checkpoint touched to its content in the
+commit that references the checkpoint. Files the commit deleted are deleted.
+Other files in the worktree are left alone, and nothing is staged.
+
+The checkpoint ID may be abbreviated to any unique prefix. When several
+commits reference the checkpoint, the newest one is used.
+
+Files with uncommitted changes that differ from the checkpoint are
+conflicts: restore refuses to run until they are committed or stashed, or
+--force is given. --dry-run prints what would change without touching
+the worktree.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRestore(cmd, args[0], dryRunFlag, forceFlag)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be restored without changing files")
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Overwrite files with uncommitted changes")
+
+	return cmd
+}
+
+func runRestore(cmd *cobra.Command, prefix string, dryRun, force bool) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	repo, committed, err := loadCommittedCheckpoints(cmd)
+	if err != nil {
+		return err
+	}
+	info, err := matchCommittedCheckpoint(committed, prefix)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	commits, err := linkedCommits(repo, []checkpoint.CommittedInfo{info})
+	if err != nil {
+		return err
+	}
+	linked := commits[info.CheckpointID]
+	if len(linked) == 0 {
+		cmd.SilenceUsage = true
+		err := fmt.Errorf("checkpoint %s is not referenced by any commit on a local branch, so its files cannot be restored", info.CheckpointID)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+	source := linked[0]
+
+	files, err := planRestore(ctx, repo, repoRoot, plumbing.NewHash(source.Commit), info.FilesTouched)
+	if err != nil {
+		return err
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Checkpoint %s from commit %s (%s)\n", info.CheckpointID, strategy.TruncateHash(source.Commit), source.Subject)
+	writeRestorePlan(w, files)
+
+	var conflicts []string
+	for _, f := range files {
+		if f.Conflict {
+			conflicts = append(conflicts, f.Path)
+		}
+	}
+	if dryRun {
+		fmt.Fprintln(w, "\nDry run: no files changed.")
+		return nil
+	}
+	if len(conflicts) > 0 && !force {
+		cmd.SilenceUsage = true
+		fmt.Fprintf(errW, "\nNot restoring: %d file(s) have uncommitted changes that differ from the checkpoint:\n", len(conflicts))
+		for _, path := range conflicts {
+			fmt.Fprintf(errW, "  %s\n", path)
+		}
+		fmt.Fprintln(errW, "Commit or stash them, or use --force to overwrite.")
+		return NewSilentError(errors.New("restore conflicts with uncommitted changes"))
+	}
+
+	if err := applyRestore(repo, repoRoot, files); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "\nRestored. Review the changes with 'git diff'.")
+	return nil
+}
+
+// planRestore compares each touched file in the source commit with the
+// worktree and decides what to do with it. Paths outside the worktree are
+// skipped.
+func planRestore(ctx context.Context, repo *git.Repository, repoRoot string, source plumbing.Hash, filesTouched []string) ([]restoreFile, error) {
+	commit, err := repo.CommitObject(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", strategy.TruncateHash(source.String()), err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit tree: %w", err)
+	}
+
+	var touched []string
+	for _, path := range filesTouched {
+		if filepath.IsLocal(filepath.FromSlash(path)) {
+			touched = append(touched, path)
+		}
+	}
+	dirty, err := dirtyFiles(ctx, repoRoot, touched)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]restoreFile, 0, len(touched))
+	for _, path := range touched {
+		current, readErr := os.ReadFile(filepath.Join(repoRoot, filepath.FromSlash(path))) //nolint:gosec // path is checked to be within the worktree
+		exists := readErr == nil
+
+		f := restoreFile{Path: path}
+		entry, fileErr := tree.File(path)
+		switch {
+		case errors.Is(fileErr, object.ErrFileNotFound):
+			f.Action = restoreDelete
+			if !exists {
+				f.Action = restoreUnchanged
+			}
+		case fileErr != nil:
+			return nil, fmt.Errorf("failed to read %s from commit: %w", path, fileErr)
+		default:
+			contents, contentErr := entry.Contents()
+			if contentErr != nil {
+				return nil, fmt.Errorf("failed to read %s from commit: %w", path, contentErr)
+			}
+			f.content = []byte(contents)
+			f.mode = entry.Mode
+			f.Action = restoreWrite
+			if exists && bytes.Equal(current, f.content) {
+				f.Action = restoreUnchanged
+			}
+		}
+		f.Conflict = f.Action != restoreUnchanged && dirty[path]
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// dirtyFiles returns which of files have staged, unstaged, or untracked
+// changes, according to git status.
+func dirtyFiles(ctx context.Context, repoRoot string, files []string) (map[string]bool, error) {
+	dirty := make(map[string]bool)
+	if len(files) == 0 {
+		return dirty, nil
+	}
+	args := append([]string{"status", "--porcelain", "-z", "-uall", "--"}, files...)
+	statusCmd := exec.CommandContext(ctx, "git", args...)
+	statusCmd.Dir = repoRoot
+	output, err := statusCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git status: %w", err)
+	}
+
+	// Format: XY filename\0, with renames and copies followed by the old name
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 3 {
+			continue
+		}
+		dirty[entry[3:]] = true
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return dirty, nil
+}
+
+// applyRestore writes or deletes each file as planned, recording every
+// change in the audit log.
+func applyRestore(repo *git.Repository, repoRoot string, files []restoreFile) error {
+	for _, f := range files {
+		absPath := filepath.Join(repoRoot, filepath.FromSlash(f.Path))
+		switch f.Action {
+		case restoreWrite:
+			//nolint:gosec // G301: Need 0o755 for user directories during restore
+			if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+			}
+			var perm os.FileMode = 0o644
+			if f.mode == filemode.Executable {
+				perm = 0o755
+			}
+			if err := os.WriteFile(absPath, f.content, perm); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", f.Path, err)
+			}
+			audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileWrite, Target: f.Path})
+		case restoreDelete:
+			if err := os.Remove(absPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to delete file %s: %w", f.Path, err)
+			}
+			audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileDelete, Target: f.Path})
+		case restoreUnchanged:
+		}
+	}
+	return nil
+}
+
+func writeRestorePlan(w io.Writer, files []restoreFile) {
+	if len(files) == 0 {
+		fmt.Fprintln(w, "  (the checkpoint touched no files)")
+		return
+	}
+	for _, f := range files {
+		line := fmt.Sprintf("  %-9s  %s", f.Action, f.Path)
+		if f.Conflict {
+			line += "  (conflict: uncommitted changes)"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runRestoreForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newRestoreCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func readWorktreeFile(t *testing.T, name string) string {
+	t.Helper()
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(root, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRestore_WritesCheckpointFiles(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupResolveRepo(t)
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	testutil.WriteFile(t, root, "a.go", "package a // v2\n")
+	testutil.GitAdd(t, root, "a.go")
+	testutil.GitCommit(t, root, "Update a")
+
+	output, err := runRestoreForTest(t, "a1b2", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Checkpoint a1b2c3d4e5f6 from commit")
+	assert.Contains(t, output, "restore    a.go")
+	assert.Contains(t, output, "Dry run: no files changed.")
+	assert.Equal(t, "package a // v2\n", readWorktreeFile(t, "a.go"))
+
+	output, err = runRestoreForTest(t, "a1b2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Restored.")
+	assert.Equal(t, "package a\n", readWorktreeFile(t, "a.go"))
+
+	output, err = runRestoreForTest(t, "a1b2")
+	require.NoError(t, err)
+	assert.Contains(t, output, "unchanged  a.go")
+}
+
+func TestRestore_DirtyFileConflicts(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupResolveRepo(t)
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	testutil.WriteFile(t, root, "a.go", "package a // local edit\n")
+
+	output, err := runRestoreForTest(t, "a1b2c3d4e5f6", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "a.go  (conflict: uncommitted changes)")
+
+	output, err = runRestoreForTest(t, "a1b2c3d4e5f6")
+	require.Error(t, err)
+	assert.Contains(t, output, "Not restoring: 1 file(s) have uncommitted changes")
+	assert.Equal(t, "package a // local edit\n", readWorktreeFile(t, "a.go"))
+
+	_, err = runRestoreForTest(t, "a1b2c3d4e5f6", "--force")
+	require.NoError(t, err)
+	assert.Equal(t, "package a\n", readWorktreeFile(t, "a.go"))
+}
+
+func TestRestore_UnlinkedCheckpoint(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupResolveRepo(t)
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	repo, err := git.PlainOpen(root)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("fedcba987654"),
+		SessionID:    "2026-04-01-gamma",
+		Strategy:     "manual-commit",
+		FilesTouched: []string{"a.go"},
+	}))
+
+	output, err := runRestoreForTest(t, "fedcba")
+	require.Error(t, err)
+	assert.Contains(t, output, "not referenced by any commit")
+
+	_, err = runRestoreForTest(t, "999999")
+	require.Error(t, err)
+}
@@ -278,7 +278,7 @@ func createCheckpointOnMetadataBranch(t *testing.T, repo *git.Repository, sessio
 		t.Fatalf("Failed to ensure metadata branch: %v", err)
 	}
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		t.Fatalf("Failed to get metadata branch ref: %v", err)
@@ -442,12 +442,12 @@ func TestCheckRemoteMetadata_MetadataExistsOnRemote(t *testing.T) {
 	checkpointID := createCheckpointOnMetadataBranch(t, repo, sessionID)
 
 	// Copy the local entire/checkpoints/v1 to origin/entire/checkpoints/v1 (simulate remote)
-	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get local metadata branch: %v", err)
 	}
 	remoteRef := plumbing.NewHashReference(
-		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName),
+		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName()),
 		localRef.Hash(),
 	)
 	if err := repo.Storer.SetReference(remoteRef); err != nil {
@@ -455,7 +455,7 @@ func TestCheckRemoteMetadata_MetadataExistsOnRemote(t *testing.T) {
 	}
 
 	// Delete local entire/checkpoints/v1 branch to simulate "not fetched yet"
-	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName)); err != nil {
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName())); err != nil {
 		t.Fatalf("Failed to remove local metadata branch: %v", err)
 	}
 
@@ -480,7 +480,7 @@ func TestCheckRemoteMetadata_NoRemoteMetadataBranch(t *testing.T) {
 	repo, _, _ := setupResumeTestRepo(t, tmpDir, false)
 
 	// Delete local entire/checkpoints/v1 branch
-	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName)); err != nil {
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName())); err != nil {
 		t.Fatalf("Failed to remove local metadata branch: %v", err)
 	}
 
@@ -504,12 +504,12 @@ func TestCheckRemoteMetadata_CheckpointNotOnRemote(t *testing.T) {
 	_ = createCheckpointOnMetadataBranch(t, repo, sessionID)
 
 	// Copy the local entire/checkpoints/v1 to origin/entire/checkpoints/v1 (simulate remote)
-	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get local metadata branch: %v", err)
 	}
 	remoteRef := plumbing.NewHashReference(
-		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName),
+		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName()),
 		localRef.Hash(),
 	)
 	if err := repo.Storer.SetReference(remoteRef); err != nil {
@@ -517,7 +517,7 @@ func TestCheckRemoteMetadata_CheckpointNotOnRemote(t *testing.T) {
 	}
 
 	// Delete local entire/checkpoints/v1 branch
-	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName)); err != nil {
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName())); err != nil {
 		t.Fatalf("Failed to remove local metadata branch: %v", err)
 	}
 
@@ -543,12 +543,12 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 	checkpointID := createCheckpointOnMetadataBranch(t, repo, sessionID)
 
 	// Copy the local entire/checkpoints/v1 to origin/entire/checkpoints/v1 (simulate remote)
-	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("Failed to get local metadata branch: %v", err)
 	}
 	remoteRef := plumbing.NewHashReference(
-		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName),
+		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName()),
 		localRef.Hash(),
 	)
 	if err := repo.Storer.SetReference(remoteRef); err != nil {
@@ -556,7 +556,7 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 	}
 
 	// Delete local entire/checkpoints/v1 branch to simulate "not fetched yet"
-	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName)); err != nil {
+	if err := repo.Storer.RemoveReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName())); err != nil {
 		t.Fatalf("Failed to remove local metadata branch: %v", err)
 	}
 
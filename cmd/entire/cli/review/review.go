@@ -0,0 +1,100 @@
+// Package review runs a user-configured command that reviews the diff of a
+// condensed checkpoint. Entire makes no network calls itself: the command
+// (typically a script that calls an LLM) decides what to do with the input,
+// and whatever it prints is stored on the checkpoint as a machine review.
+package review
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/shellcmd"
+)
+
+const (
+	// DefaultTimeout bounds a review command when no timeout is configured.
+	DefaultTimeout = 2 * time.Minute
+
+	// MaxOutputSize caps the stored review. Longer output is truncated.
+	MaxOutputSize = 64 * 1024
+
+	// MaxDiffSize caps the diff passed to the command. Longer diffs are
+	// truncated and Input.DiffTruncated is set.
+	MaxDiffSize = 1024 * 1024
+)
+
+// Input is written to the review command's stdin as JSON.
+type Input struct {
+	CheckpointID  string   `json:"checkpoint_id"`
+	SessionID     string   `json:"session_id"`
+	Commit        string   `json:"commit,omitempty"`
+	FilesTouched  []string `json:"files_touched,omitempty"`
+	Prompts       []string `json:"prompts,omitempty"`
+	Diff          string   `json:"diff"`
+	DiffTruncated bool     `json:"diff_truncated,omitempty"`
+}
+
+// Runner runs a review command.
+type Runner struct {
+	// Command is run with `sh -c`.
+	Command string
+
+	// Dir is the working directory, normally the repository root.
+	Dir string
+
+	// Timeout bounds the command. If zero, DefaultTimeout is used.
+	Timeout time.Duration
+}
+
+// Run executes the review command with input on stdin and returns its trimmed
+// stdout. An empty review (the command printed nothing) is not an error.
+func (r *Runner) Run(ctx context.Context, input Input) (string, error) {
+	if strings.TrimSpace(r.Command) == "" {
+		return "", errors.New("no review command configured")
+	}
+	if len(input.Diff) > MaxDiffSize {
+		input.Diff = input.Diff[:MaxDiffSize]
+		input.DiffTruncated = true
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode review input: %w", err)
+	}
+
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runner := &shellcmd.Runner{
+		Command: r.Command,
+		Name:    "entire-review",
+		Dir:     r.Dir,
+		Timeout: timeout,
+		Env: []string{
+			"ENTIRE_CHECKPOINT_ID=" + input.CheckpointID,
+			"ENTIRE_SESSION_ID=" + input.SessionID,
+		},
+		Stdin: bytes.NewReader(payload),
+	}
+	result, err := runner.Run(ctx, input.FilesTouched)
+	if err != nil {
+		return "", err //nolint:wrapcheck // shellcmd names the command in its error
+	}
+	if result.TimedOut {
+		return "", fmt.Errorf("review command timed out after %s", timeout)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("review command failed (exit %d): %s", result.ExitCode, strings.TrimSpace(string(result.Stderr)))
+	}
+
+	out := string(result.Stdout)
+	if len(out) > MaxOutputSize {
+		out = out[:MaxOutputSize] + "\n\n[review truncated]"
+	}
+	return strings.TrimSpace(out), nil
+}
@@ -0,0 +1,73 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `cat > input.json; echo "reviewed $ENTIRE_CHECKPOINT_ID"`, Dir: t.TempDir()}
+	out, err := r.Run(context.Background(), Input{CheckpointID: "a1b2c3d4e5f6", SessionID: "s1", Diff: "diff --git a/x b/x"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out != "reviewed a1b2c3d4e5f6" {
+		t.Errorf("Run() = %q, want %q", out, "reviewed a1b2c3d4e5f6")
+	}
+}
+
+func TestRunner_RunPassesInputAsJSON(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: "cat", Dir: t.TempDir()}
+	out, err := r.Run(context.Background(), Input{CheckpointID: "a1b2c3d4e5f6", Prompts: []string{"fix the bug"}, Diff: "+line"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	var got Input
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("stdin was not JSON: %v\n%s", err, out)
+	}
+	if got.Diff != "+line" || len(got.Prompts) != 1 || got.Prompts[0] != "fix the bug" {
+		t.Errorf("input = %+v", got)
+	}
+}
+
+func TestRunner_RunTruncatesLargeDiff(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: `grep -o '"diff_truncated":true'`, Dir: t.TempDir()}
+	out, err := r.Run(context.Background(), Input{Diff: strings.Repeat("x", MaxDiffSize+10)})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if out != `"diff_truncated":true` {
+		t.Error("expected diff_truncated to be set")
+	}
+}
+
+func TestRunner_RunErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		runner  Runner
+		wantErr string
+	}{
+		{name: "empty command", runner: Runner{Command: "  "}, wantErr: "no review command"},
+		{name: "exit status", runner: Runner{Command: "echo boom >&2; exit 3"}, wantErr: "exit 3): boom"},
+		{name: "timeout", runner: Runner{Command: "sleep 5", Timeout: 50 * time.Millisecond}, wantErr: "timed out"},
+	}
+	for _, tt := range tests {
+		tt.runner.Dir = t.TempDir()
+		_, err := tt.runner.Run(context.Background(), Input{})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Run() error = %v, want containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
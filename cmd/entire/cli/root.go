@@ -3,10 +3,16 @@ package cli
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/features"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
 	"github.com/entireio/cli/cmd/entire/cli/telemetry"
 	"github.com/entireio/cli/cmd/entire/cli/versioncheck"
+	"github.com/entireio/cli/redact"
 	"github.com/spf13/cobra"
 )
 
@@ -26,7 +32,19 @@ Environment Variables:
                 TUI elements, which works better with screen readers.
 `
 
+func init() {
+	// redact does not depend on the CLI packages, so its cache is registered here.
+	repocache.Register(redact.ClearRulesCache)
+}
+
 func NewRootCmd() *cobra.Command {
+	// User-facing messages follow ENTIRE_LOCALE or the "locale" setting.
+	i18n.SetLocaleGetter(GetLocale)
+	// Trailer key and branch names follow the "namespace" setting.
+	paths.SetNamespaceGetter(GetNamespace)
+	// Checkpoint content is redacted with the "redaction" setting's rules.
+	redact.SetRulesGetter(GetRedactionRules)
+
 	cmd := &cobra.Command{
 		Use:   "entire",
 		Short: "Entire CLI",
@@ -61,6 +79,11 @@ func NewRootCmd() *cobra.Command {
 				telemetry.TrackCommandDetached(cmd, agentStr, settings.Enabled, buildinfo.Version)
 			}
 
+			// Deprecated settings and flags in use, at most once a day each
+			if deprecations := features.Active(); len(deprecations) > 0 {
+				warnDeprecationsOncePerDay(cmd.ErrOrStderr(), deprecationWarningBucket(), deprecations, time.Now())
+			}
+
 			// Version check and notification (synchronous with 2s timeout)
 			// Runs AFTER command completes to avoid interfering with interactive modes
 			versioncheck.CheckAndNotify(cmd.OutOrStdout(), buildinfo.Version)
@@ -72,8 +95,12 @@ func NewRootCmd() *cobra.Command {
 
 	// Add subcommands here
 	cmd.AddCommand(newRewindCmd())
+	cmd.AddCommand(newRestoreCmd())
 	cmd.AddCommand(newResumeCmd())
 	cmd.AddCommand(newCleanCmd())
+	cmd.AddCommand(newGCCmd())
+	cmd.AddCommand(newSyncCmd())
+	cmd.AddCommand(newServeCmd())
 	cmd.AddCommand(newResetCmd())
 	cmd.AddCommand(newEnableCmd())
 	cmd.AddCommand(newDisableCmd())
@@ -81,7 +108,34 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newHooksCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newCheckpointsCmd())
+	cmd.AddCommand(newDiffCmd())
 	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newLogCmd())
+	cmd.AddCommand(newAgentsCmd())
+	cmd.AddCommand(newUpgradeCmd())
+	cmd.AddCommand(newSessionCmd())
+	cmd.AddCommand(newWorktreeCmd())
+	cmd.AddCommand(newNamespaceCmd())
+	cmd.AddCommand(newFileHistoryCmd())
+	cmd.AddCommand(newResolveCmd())
+	cmd.AddCommand(newStageCmd())
+	cmd.AddCommand(newCommitCmd())
+	cmd.AddCommand(newBisectCmd())
+	cmd.AddCommand(newBlameCmd())
+	cmd.AddCommand(newCheckCmd())
+	cmd.AddCommand(newStampCmd())
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newPrivacyCmd())
+	cmd.AddCommand(newRedactCmd())
+	cmd.AddCommand(newUsageCmd())
+	cmd.AddCommand(newWorkerCmd())
+	cmd.AddCommand(newFeaturesCmd())
+	cmd.AddCommand(newSchemaCmd())
+	cmd.AddCommand(newDaemonCmd())
+	cmd.AddCommand(newDevtoolCmd())
 	cmd.AddCommand(newSendAnalyticsCmd())
 	cmd.AddCommand(newCurlBashPostInstallCmd())
 
@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonschema"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/spf13/cobra"
+)
+
+// schemaDoc is a JSON file format Entire reads or writes, with the Go type
+// its schema is generated from.
+type schemaDoc struct {
+	Name   string
+	Schema func() *jsonschema.Schema
+}
+
+// schemaDocs lists every documented format, in output order.
+var schemaDocs = []schemaDoc{
+	{
+		Name: "settings",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[settings.EntireSettings](jsonschema.Options{
+				Title:       "Entire settings",
+				Description: "Project and local settings: " + settings.EntireSettingsFile + " and " + settings.EntireSettingsLocalFile + ".",
+				Closed:      true, // unknown keys are rejected when settings are loaded
+				AllOptional: true,
+			})
+		},
+	},
+	{
+		Name: "session-state",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[session.State](jsonschema.Options{
+				Title:       "Entire session state",
+				Description: "Per-session state kept in .git/entire-sessions/<session-id>.json.",
+			})
+		},
+	},
+	{
+		Name: "checkpoint-summary",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[checkpoint.CheckpointSummary](jsonschema.Options{
+				Title:       "Entire checkpoint summary",
+				Description: "Root metadata.json of a checkpoint on " + paths.MetadataBranchName() + ".",
+			})
+		},
+	},
+	{
+		Name: "checkpoint-metadata",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[checkpoint.CommittedMetadata](jsonschema.Options{
+				Title:       "Entire checkpoint session metadata",
+				Description: "Per-session metadata.json inside a checkpoint on " + paths.MetadataBranchName() + ".",
+			})
+		},
+	},
+	{
+		Name: "hook-event",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[hookEvent](jsonschema.Options{
+				Title:       "Entire hook output event",
+				Description: "One line of hook output when " + HookOutputEnvVar + "=json.",
+			})
+		},
+	},
+	{
+		Name: "hook-payload",
+		Schema: func() *jsonschema.Schema {
+			return jsonschema.For[CapturedHookPayload](jsonschema.Options{
+				Title:       "Entire captured hook payload",
+				Description: "An agent hook payload recorded under .entire/debug/ by debug.capture_hook_payloads.",
+			})
+		},
+	},
+}
+
+func newSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print JSON schemas for Entire's file formats",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(newSchemaDumpCmd())
+	return cmd
+}
+
+func newSchemaDumpCmd() *cobra.Command {
+	var dirFlag string
+
+	names := make([]string, 0, len(schemaDocs))
+	for _, doc := range schemaDocs {
+		names = append(names, doc.Name)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "dump [name]",
+		Short: "Print the JSON schema of one or all file formats",
+		Long: `Print JSON schemas (draft 2020-12) generated from the Go types Entire uses to
+read and write its files, so they always match this version of the CLI.
+
+Available schemas: ` + strings.Join(names, ", ") + `
+
+With no name, all schemas are printed as one JSON object keyed by name.
+With --dir, each schema is written to <dir>/<name>.schema.json instead.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: names,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docs := schemaDocs
+			if len(args) == 1 {
+				doc, ok := findSchemaDoc(args[0])
+				if !ok {
+					cmd.SilenceUsage = true
+					err := fmt.Errorf("unknown schema %q (available: %s)", args[0], strings.Join(names, ", "))
+					fmt.Fprintln(cmd.ErrOrStderr(), err)
+					return NewSilentError(err)
+				}
+				docs = []schemaDoc{doc}
+			}
+			if dirFlag != "" {
+				return writeSchemaFiles(cmd.OutOrStdout(), dirFlag, docs)
+			}
+			return writeSchemas(cmd.OutOrStdout(), docs, len(args) == 1)
+		},
+	}
+
+	cmd.Flags().StringVar(&dirFlag, "dir", "", "Write each schema to <dir>/<name>.schema.json")
+
+	return cmd
+}
+
+func findSchemaDoc(name string) (schemaDoc, bool) {
+	for _, doc := range schemaDocs {
+		if doc.Name == name {
+			return doc, true
+		}
+	}
+	return schemaDoc{}, false
+}
+
+// writeSchemas prints a single schema, or all of them as an object keyed by name.
+func writeSchemas(w io.Writer, docs []schemaDoc, single bool) error {
+	var v any
+	if single {
+		v = docs[0].Schema()
+	} else {
+		all := make(map[string]*jsonschema.Schema, len(docs))
+		for _, doc := range docs {
+			all[doc.Name] = doc.Schema()
+		}
+		v = all
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return nil
+}
+
+func writeSchemaFiles(w io.Writer, dir string, docs []schemaDoc) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, doc := range docs {
+		data, err := json.MarshalIndent(doc.Schema(), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode %s schema: %w", doc.Name, err)
+		}
+		path := filepath.Join(dir, doc.Name+".schema.json")
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // schemas are meant to be shared
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(w, "Wrote %s\n", path)
+	}
+	return nil
+}
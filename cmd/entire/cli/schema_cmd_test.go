@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/jsonschema"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSchemaDumpForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newSchemaDumpCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSchemaDump_All(t *testing.T) {
+	t.Parallel()
+
+	output, err := runSchemaDumpForTest(t)
+	require.NoError(t, err)
+
+	var all map[string]map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &all))
+	require.Len(t, all, len(schemaDocs))
+	for _, doc := range schemaDocs {
+		schema, ok := all[doc.Name]
+		require.True(t, ok, "missing schema %s", doc.Name)
+		assert.Equal(t, jsonschema.Draft, schema["$schema"], doc.Name)
+		assert.Equal(t, "object", schema["type"], doc.Name)
+		assert.NotEmpty(t, schema["properties"], doc.Name)
+	}
+}
+
+func TestSchemaDump_Settings(t *testing.T) {
+	t.Parallel()
+
+	output, err := runSchemaDumpForTest(t, "settings")
+	require.NoError(t, err)
+
+	var schema struct {
+		Properties           map[string]any `json:"properties"`
+		Required             []string       `json:"required"`
+		AdditionalProperties *bool          `json:"additionalProperties"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &schema))
+	assert.Contains(t, schema.Properties, "enabled")
+	assert.Contains(t, schema.Properties, "quality_gate")
+	assert.Empty(t, schema.Required, "every setting is optional")
+	require.NotNil(t, schema.AdditionalProperties)
+	assert.False(t, *schema.AdditionalProperties, "unknown settings keys are rejected")
+
+	output, err = runSchemaDumpForTest(t, "nope")
+	require.Error(t, err)
+	assert.Contains(t, output, `unknown schema "nope"`)
+}
+
+func TestSchemaDump_Dir(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "schemas")
+	output, err := runSchemaDumpForTest(t, "--dir", dir)
+	require.NoError(t, err)
+
+	for _, doc := range schemaDocs {
+		path := filepath.Join(dir, doc.Name+".schema.json")
+		assert.Contains(t, output, "Wrote "+path)
+		data, readErr := os.ReadFile(path)
+		require.NoError(t, readErr)
+		assert.True(t, json.Valid(data), "%s is not valid JSON", path)
+	}
+}
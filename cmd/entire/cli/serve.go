@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/server"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+// defaultServePort is the port `entire serve` listens on unless --port is given.
+const defaultServePort = 7373
+
+func newServeCmd() *cobra.Command {
+	var portFlag int
+	var metricsAddrFlag string
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Browse sessions, checkpoints, and transcripts in a local web UI",
+		Long: `Serve a web UI for browsing this repository's sessions, checkpoints, and
+transcripts, and the commits they are linked to. The server only reads:
+nothing is changed while you browse.
+
+The server listens on localhost only and runs in the foreground until
+interrupted. Use --port 0 to pick a free port.
+
+Condensations, sessions, and token usage are served for Prometheus on
+/metrics. Hook durations and failures are included once hooks record them,
+which they do with {"features": {"hook_metrics": true}} in settings. To let a Prometheus server on another
+machine scrape them, use --metrics-addr to also serve /metrics, and nothing
+else, on that address, e.g. --metrics-addr :9373.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if portFlag < 0 || portFlag > 65535 {
+				return errors.New("--port must be between 0 and 65535")
+			}
+			return runServe(cmd, portFlag, metricsAddrFlag)
+		},
+	}
+
+	cmd.Flags().IntVar(&portFlag, "port", defaultServePort, "Port to listen on (0 = any free port)")
+	cmd.Flags().StringVar(&metricsAddrFlag, "metrics-addr", "", "Also serve /metrics alone on this address, e.g. :9373")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, port int, metricsAddr string) error {
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	sessions, err := session.NewStateStore()
+	if err != nil {
+		return fmt.Errorf("failed to open session state: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	opts := serverOptions(repo, sessions)
+	if metricsAddr != "" {
+		metricsListener, err := net.Listen("tcp", metricsAddr)
+		if err != nil {
+			_ = listener.Close()
+			cmd.SilenceUsage = true
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", server.MetricsHandler(opts))
+		go func() {
+			if err := server.Serve(cmd.Context(), metricsListener, mux); err != nil {
+				fmt.Fprintf(errW, "Metrics server stopped: %v\n", err)
+			}
+		}()
+		fmt.Fprintf(w, "Serving metrics at http://%s/metrics\n", metricsListener.Addr())
+	}
+
+	handler := server.New(opts)
+	fmt.Fprintf(w, "Serving the Entire web UI at http://localhost:%d/\n", listener.Addr().(*net.TCPAddr).Port)
+	fmt.Fprintln(w, "Press Ctrl+C to stop.")
+	if err := server.Serve(cmd.Context(), listener, handler); err != nil {
+		return fmt.Errorf("web UI server stopped: %w", err)
+	}
+	fmt.Fprintln(w, "Web UI server stopped.")
+	return nil
+}
+
+// serverOptions wires the web UI server to the repository's checkpoints and
+// session state, linking commits and formatting transcripts as the
+// `entire checkpoints` and `entire explain` commands do.
+func serverOptions(repo *git.Repository, sessions *session.StateStore) server.Options {
+	return server.Options{
+		Store:    checkpoint.NewGitStore(repo),
+		Sessions: sessions,
+		LinkedCommits: func(committed []checkpoint.CommittedInfo) (map[id.CheckpointID][]server.Commit, error) {
+			linked, err := linkedCommits(repo, committed)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[id.CheckpointID][]server.Commit, len(linked))
+			for cpID, commits := range linked {
+				for _, c := range commits {
+					result[cpID] = append(result[cpID], server.Commit{Hash: c.Commit, Subject: c.Subject, Stamped: c.Stamped})
+				}
+			}
+			return result, nil
+		},
+		FormatTranscript: func(transcript []byte, agentType agent.AgentType) string {
+			return formatTranscriptBytes(transcript, "", agentType)
+		},
+	}
+}
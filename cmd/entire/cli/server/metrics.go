@@ -0,0 +1,191 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+)
+
+// hookDurationBuckets are the upper bounds, in seconds, of the hook duration
+// histogram buckets.
+var hookDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// hookStats aggregates the logged runs of one hook.
+type hookStats struct {
+	runs     int
+	failures int
+	sum      float64
+	buckets  []int // cumulative counts per hookDurationBuckets entry
+}
+
+// MetricsHandler returns the handler serving the repository's metrics in the
+// Prometheus text exposition format. Hook runs and condensations are counted
+// from the logs in the git common directory, so the counters cover every hook
+// process, not just this server; they reset when those logs are rotated.
+// Sessions and token usage are read from the session state store.
+func MetricsHandler(opts Options) http.Handler {
+	s := &server{opts: opts}
+	return http.HandlerFunc(s.handleMetrics)
+}
+
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	gitDir := audit.GitDir(s.opts.Store.Repository())
+
+	runs, err := audit.ReadHookRuns(gitDir, time.Time{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	condensations, err := audit.ReadCondensations(gitDir, time.Time{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var states []*session.State
+	if s.opts.Sessions != nil {
+		if states, err = s.opts.Sessions.List(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeHookMetrics(w, runs)
+	writeCondensationMetrics(w, condensations)
+	writeSessionMetrics(w, states)
+}
+
+func writeHookMetrics(w io.Writer, runs []audit.HookRun) {
+	stats := map[string]*hookStats{}
+	for _, run := range runs {
+		st, ok := stats[run.Hook]
+		if !ok {
+			st = &hookStats{buckets: make([]int, len(hookDurationBuckets))}
+			stats[run.Hook] = st
+		}
+		seconds := float64(run.DurationMs) / 1000
+		st.runs++
+		st.sum += seconds
+		if run.Error != "" {
+			st.failures++
+		}
+		for i, le := range hookDurationBuckets {
+			if seconds <= le {
+				st.buckets[i]++
+			}
+		}
+	}
+	hooks := sortedKeys(stats)
+
+	writeHeader(w, "entire_hook_runs_total", "counter", "Hook runs recorded in the hook run log.")
+	for _, hook := range hooks {
+		writeSample(w, "entire_hook_runs_total", labels("hook", hook), strconv.Itoa(stats[hook].runs))
+	}
+	writeHeader(w, "entire_hook_failures_total", "counter", "Hook runs that returned an error.")
+	for _, hook := range hooks {
+		writeSample(w, "entire_hook_failures_total", labels("hook", hook), strconv.Itoa(stats[hook].failures))
+	}
+	writeHeader(w, "entire_hook_duration_seconds", "histogram", "Duration of hook runs.")
+	for _, hook := range hooks {
+		st := stats[hook]
+		for i, le := range hookDurationBuckets {
+			writeSample(w, "entire_hook_duration_seconds_bucket", labels("hook", hook, "le", formatFloat(le)), strconv.Itoa(st.buckets[i]))
+		}
+		writeSample(w, "entire_hook_duration_seconds_bucket", labels("hook", hook, "le", "+Inf"), strconv.Itoa(st.runs))
+		writeSample(w, "entire_hook_duration_seconds_sum", labels("hook", hook), formatFloat(st.sum))
+		writeSample(w, "entire_hook_duration_seconds_count", labels("hook", hook), strconv.Itoa(st.runs))
+	}
+}
+
+func writeCondensationMetrics(w io.Writer, condensations []audit.Condensation) {
+	counts := map[string]int{}
+	for _, c := range condensations {
+		counts[string(c.Decision)]++
+	}
+
+	writeHeader(w, "entire_condensations_total", "counter", "Post-commit decisions about sessions, by decision.")
+	for _, decision := range sortedKeys(counts) {
+		writeSample(w, "entire_condensations_total", labels("decision", decision), strconv.Itoa(counts[decision]))
+	}
+}
+
+func writeSessionMetrics(w io.Writer, states []*session.State) {
+	phases := map[string]int{
+		string(session.PhaseActive): 0,
+		string(session.PhaseIdle):   0,
+		string(session.PhaseEnded):  0,
+	}
+	var tokens agent.TokenUsage
+	for _, st := range states {
+		phases[string(session.PhaseFromString(string(st.Phase)))]++
+		addTokens(&tokens, st.TokenUsage)
+	}
+
+	writeHeader(w, "entire_sessions", "gauge", "Sessions in the session state store, by phase.")
+	for _, phase := range sortedKeys(phases) {
+		writeSample(w, "entire_sessions", labels("phase", phase), strconv.Itoa(phases[phase]))
+	}
+	writeHeader(w, "entire_session_tokens", "gauge", "Tokens used by the sessions in the session state store, including subagents.")
+	for _, t := range []struct {
+		kind  string
+		count int
+	}{
+		{"input", tokens.InputTokens},
+		{"cache_creation", tokens.CacheCreationTokens},
+		{"cache_read", tokens.CacheReadTokens},
+		{"output", tokens.OutputTokens},
+	} {
+		writeSample(w, "entire_session_tokens", labels("type", t.kind), strconv.Itoa(t.count))
+	}
+}
+
+// addTokens adds usage, including its subagents' usage, to total.
+func addTokens(total *agent.TokenUsage, usage *agent.TokenUsage) {
+	for ; usage != nil; usage = usage.SubagentTokens {
+		total.InputTokens += usage.InputTokens
+		total.CacheCreationTokens += usage.CacheCreationTokens
+		total.CacheReadTokens += usage.CacheReadTokens
+		total.OutputTokens += usage.OutputTokens
+	}
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w io.Writer, name, labels, value string) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, value)
+}
+
+// labelEscaper escapes label values as the text exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats alternating label names and values as a Prometheus label set.
+func labels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, pairs[i]+`="`+labelEscaper.Replace(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
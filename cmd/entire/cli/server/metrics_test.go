@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	testutil.InitRepo(t, dir)
+	gitDir := filepath.Join(dir, ".git")
+
+	audit.RecordHookRun(gitDir, audit.HookRun{Hook: "git/post-commit", DurationMs: 80})
+	audit.RecordHookRun(gitDir, audit.HookRun{Hook: "git/post-commit", DurationMs: 3000, Error: "boom"})
+	audit.RecordHookRun(gitDir, audit.HookRun{Hook: "claude-code/stop", DurationMs: 200})
+	audit.RecordCondensation(gitDir, audit.Condensation{SessionID: "s1", Commit: "abc", Decision: audit.DecisionCondensed})
+	audit.RecordCondensation(gitDir, audit.Condensation{SessionID: "s2", Commit: "abc", Decision: audit.DecisionSkipped})
+
+	sessions := session.NewStateStoreWithDir(t.TempDir())
+	require.NoError(t, sessions.Save(context.Background(), &session.State{
+		SessionID:  "2026-04-01-alpha",
+		AgentType:  agent.AgentTypeClaudeCode,
+		BaseCommit: "abc",
+		StartedAt:  time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+		Phase:      session.PhaseActive,
+		TokenUsage: &agent.TokenUsage{
+			InputTokens:    100,
+			OutputTokens:   20,
+			SubagentTokens: &agent.TokenUsage{InputTokens: 5, OutputTokens: 1},
+		},
+	}))
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	srv := httptest.NewServer(New(Options{Store: checkpoint.NewGitStore(repo), Sessions: sessions}))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/metrics") //nolint:noctx // test request
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/plain")
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	for _, line := range []string{
+		"# TYPE entire_hook_runs_total counter",
+		`entire_hook_runs_total{hook="git/post-commit"} 2`,
+		`entire_hook_failures_total{hook="git/post-commit"} 1`,
+		`entire_hook_failures_total{hook="claude-code/stop"} 0`,
+		`entire_hook_duration_seconds_bucket{hook="git/post-commit",le="0.1"} 1`,
+		`entire_hook_duration_seconds_bucket{hook="git/post-commit",le="+Inf"} 2`,
+		`entire_hook_duration_seconds_sum{hook="git/post-commit"} 3.08`,
+		`entire_condensations_total{decision="condensed"} 1`,
+		`entire_condensations_total{decision="skipped"} 1`,
+		`entire_sessions{phase="active"} 1`,
+		`entire_sessions{phase="ended"} 0`,
+		`entire_session_tokens{type="input"} 105`,
+		`entire_session_tokens{type="output"} 21`,
+	} {
+		assert.Contains(t, string(body), line+"\n")
+	}
+}
+
+func TestLabels_Escapes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, `{hook="a\"b\\c\nd",le="1"}`, labels("hook", "a\"b\\c\nd", "le", "1"))
+}
@@ -0,0 +1,415 @@
+// Package server serves a local web UI for browsing sessions, checkpoints,
+// transcripts, and the commits they are linked to. The UI is a static bundle
+// embedded in the binary; it reads everything through a JSON API under /api/.
+// Repository health metrics are served in the Prometheus text format on
+// /metrics.
+//
+// The server is read-only: it only answers GET requests, and it only reads
+// from the checkpoint store and the session state store.
+package server
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// promptSeparator separates the prompts stored in a session's prompt.txt.
+const promptSeparator = "\n\n---\n\n"
+
+// Commit is a commit linked to a checkpoint.
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	// Stamped is set when the link comes from `entire stamp` rather than a
+	// trailer on the commit.
+	Stamped bool `json:"stamped,omitempty"`
+}
+
+// Options configures the server.
+type Options struct {
+	// Store reads committed checkpoints.
+	Store *checkpoint.GitStore
+	// Sessions reads session state. Nil serves no sessions.
+	Sessions *session.StateStore
+	// LinkedCommits returns, for each of the given checkpoints, the commits
+	// on local branches that reference it. Nil serves no linked commits.
+	LinkedCommits func(committed []checkpoint.CommittedInfo) (map[id.CheckpointID][]Commit, error)
+	// FormatTranscript renders a session transcript as readable text. Nil
+	// serves the raw transcript.
+	FormatTranscript func(transcript []byte, agentType agent.AgentType) string
+}
+
+type server struct {
+	opts Options
+}
+
+// New returns the handler serving the web UI and its API.
+func New(opts Options) http.Handler {
+	s := &server{opts: opts}
+
+	static, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		panic(err) // the embedded bundle always has a static directory
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/checkpoints", s.handleCheckpoints)
+	mux.HandleFunc("GET /api/checkpoints/{id}", s.handleCheckpoint)
+	mux.HandleFunc("GET /api/checkpoints/{id}/sessions/{index}/transcript", s.handleTranscript)
+	mux.HandleFunc("GET /api/sessions", s.handleSessions)
+	mux.HandleFunc("GET /api/commits/{hash}", s.handleCommit)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.Handle("GET /", http.FileServerFS(static))
+	return localOnly(mux)
+}
+
+// localOnly rejects requests whose Host header does not name the loopback
+// interface, so a web page cannot reach the server through DNS rebinding.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkpointView is a committed checkpoint as listed by the API.
+type checkpointView struct {
+	CheckpointID   id.CheckpointID `json:"checkpoint_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Agent          agent.AgentType `json:"agent,omitempty"`
+	SessionIDs     []string        `json:"session_ids"`
+	FilesTouched   []string        `json:"files_touched"`
+	StepsCount     int             `json:"steps_count"`
+	IsTask         bool            `json:"is_task,omitempty"`
+	Imported       bool            `json:"imported,omitempty"`
+	Commits        []Commit        `json:"commits"`
+	StampedCommits []string        `json:"stamped_commits,omitempty"`
+}
+
+// checkpointSessionView is one session of a checkpoint.
+type checkpointSessionView struct {
+	Index        int                 `json:"index"`
+	SessionID    string              `json:"session_id"`
+	Agent        agent.AgentType     `json:"agent,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Branch       string              `json:"branch,omitempty"`
+	FilesTouched []string            `json:"files_touched"`
+	Prompts      []string            `json:"prompts"`
+	Summary      *checkpoint.Summary `json:"summary,omitempty"`
+	TokenUsage   *agent.TokenUsage   `json:"token_usage,omitempty"`
+}
+
+// checkpointDetailView is a checkpoint with its sessions.
+type checkpointDetailView struct {
+	checkpointView
+
+	Sessions []checkpointSessionView `json:"sessions"`
+}
+
+// transcriptView is the transcript of one session of a checkpoint.
+type transcriptView struct {
+	CheckpointID id.CheckpointID `json:"checkpoint_id"`
+	SessionID    string          `json:"session_id"`
+	Agent        agent.AgentType `json:"agent,omitempty"`
+	Text         string          `json:"text"`
+}
+
+// sessionView is a session known to the session state store.
+type sessionView struct {
+	SessionID           string          `json:"session_id"`
+	Agent               agent.AgentType `json:"agent,omitempty"`
+	Phase               string          `json:"phase"`
+	StartedAt           time.Time       `json:"started_at"`
+	EndedAt             *time.Time      `json:"ended_at,omitempty"`
+	LastInteractionTime *time.Time      `json:"last_interaction_time,omitempty"`
+	BaseCommit          string          `json:"base_commit"`
+	WorktreePath        string          `json:"worktree_path,omitempty"`
+	StepCount           int             `json:"step_count"`
+	FilesTouched        []string        `json:"files_touched"`
+	LastCheckpointID    id.CheckpointID `json:"last_checkpoint_id,omitempty"`
+}
+
+// commitView is a commit with the checkpoints linked to it.
+type commitView struct {
+	Hash        string           `json:"hash"`
+	Subject     string           `json:"subject"`
+	Author      string           `json:"author"`
+	Date        time.Time        `json:"date"`
+	Checkpoints []checkpointView `json:"checkpoints"`
+}
+
+func (s *server) handleCheckpoints(w http.ResponseWriter, r *http.Request) {
+	committed, err := s.opts.Store.ListCommitted(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	commits, err := s.linkedCommits(committed)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	views := make([]checkpointView, 0, len(committed))
+	for _, info := range committed {
+		views = append(views, newCheckpointView(info, commits[info.CheckpointID]))
+	}
+	writeJSON(w, views)
+}
+
+func (s *server) handleCheckpoint(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.findCheckpoint(w, r)
+	if !ok {
+		return
+	}
+	commits, err := s.linkedCommits([]checkpoint.CommittedInfo{info})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	detail := checkpointDetailView{
+		checkpointView: newCheckpointView(info, commits[info.CheckpointID]),
+		Sessions:       []checkpointSessionView{},
+	}
+	for i := range max(info.SessionCount, 1) {
+		content, err := s.opts.Store.ReadSessionContent(r.Context(), info.CheckpointID, i)
+		if err != nil {
+			continue
+		}
+		meta := content.Metadata
+		detail.Sessions = append(detail.Sessions, checkpointSessionView{
+			Index:        i,
+			SessionID:    meta.SessionID,
+			Agent:        meta.Agent,
+			CreatedAt:    meta.CreatedAt,
+			Branch:       meta.Branch,
+			FilesTouched: nonNil(meta.FilesTouched),
+			Prompts:      splitPrompts(content.Prompts),
+			Summary:      meta.Summary,
+			TokenUsage:   meta.TokenUsage,
+		})
+	}
+	writeJSON(w, detail)
+}
+
+func (s *server) handleTranscript(w http.ResponseWriter, r *http.Request) {
+	info, ok := s.findCheckpoint(w, r)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid session index"))
+		return
+	}
+
+	content, err := s.opts.Store.ReadSessionContent(r.Context(), info.CheckpointID, index)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	text := string(content.Transcript)
+	if s.opts.FormatTranscript != nil {
+		text = s.opts.FormatTranscript(content.Transcript, content.Metadata.Agent)
+	}
+	writeJSON(w, transcriptView{
+		CheckpointID: info.CheckpointID,
+		SessionID:    content.Metadata.SessionID,
+		Agent:        content.Metadata.Agent,
+		Text:         text,
+	})
+}
+
+func (s *server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	views := []sessionView{}
+	if s.opts.Sessions != nil {
+		states, err := s.opts.Sessions.List(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, st := range states {
+			views = append(views, sessionView{
+				SessionID:           st.SessionID,
+				Agent:               st.AgentType,
+				Phase:               string(session.PhaseFromString(string(st.Phase))),
+				StartedAt:           st.StartedAt,
+				EndedAt:             st.EndedAt,
+				LastInteractionTime: st.LastInteractionTime,
+				BaseCommit:          st.BaseCommit,
+				WorktreePath:        st.WorktreePath,
+				StepCount:           st.StepCount,
+				FilesTouched:        nonNil(st.FilesTouched),
+				LastCheckpointID:    st.LastCheckpointID,
+			})
+		}
+	}
+	slices.SortStableFunc(views, func(a, b sessionView) int {
+		return b.StartedAt.Compare(a.StartedAt)
+	})
+	writeJSON(w, views)
+}
+
+func (s *server) handleCommit(w http.ResponseWriter, r *http.Request) {
+	repo := s.opts.Store.Repository()
+	hash, err := repo.ResolveRevision(plumbing.Revision(r.PathValue("hash")))
+	if err != nil {
+		writeError(w, http.StatusNotFound, errors.New("commit not found"))
+		return
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		writeError(w, http.StatusNotFound, errors.New("commit not found"))
+		return
+	}
+	committed, err := s.opts.Store.ListCommitted(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	view := commitView{
+		Hash:        commit.Hash.String(),
+		Subject:     strings.Split(commit.Message, "\n")[0],
+		Author:      commit.Author.Name,
+		Date:        commit.Author.When,
+		Checkpoints: []checkpointView{},
+	}
+	trailerIDs := trailers.ParseAllCheckpoints(commit.Message)
+	for _, info := range committed {
+		stamped := slices.Contains(info.StampedCommits, view.Hash)
+		if !stamped && !slices.Contains(trailerIDs, info.CheckpointID) {
+			continue
+		}
+		link := Commit{Hash: view.Hash, Subject: view.Subject, Stamped: !slices.Contains(trailerIDs, info.CheckpointID)}
+		view.Checkpoints = append(view.Checkpoints, newCheckpointView(info, []Commit{link}))
+	}
+	writeJSON(w, view)
+}
+
+// findCheckpoint looks up the checkpoint named by the request path, writing
+// an error response when there is none.
+func (s *server) findCheckpoint(w http.ResponseWriter, r *http.Request) (checkpoint.CommittedInfo, bool) {
+	cpID, err := id.NewCheckpointID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return checkpoint.CommittedInfo{}, false
+	}
+	committed, err := s.opts.Store.ListCommitted(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return checkpoint.CommittedInfo{}, false
+	}
+	for _, info := range committed {
+		if info.CheckpointID == cpID {
+			return info, true
+		}
+	}
+	writeError(w, http.StatusNotFound, errors.New("checkpoint not found"))
+	return checkpoint.CommittedInfo{}, false
+}
+
+func (s *server) linkedCommits(committed []checkpoint.CommittedInfo) (map[id.CheckpointID][]Commit, error) {
+	if s.opts.LinkedCommits == nil {
+		return nil, nil
+	}
+	return s.opts.LinkedCommits(committed)
+}
+
+func newCheckpointView(info checkpoint.CommittedInfo, commits []Commit) checkpointView {
+	if commits == nil {
+		commits = []Commit{}
+	}
+	return checkpointView{
+		CheckpointID:   info.CheckpointID,
+		CreatedAt:      info.CreatedAt,
+		Agent:          info.Agent,
+		SessionIDs:     nonNil(info.SessionIDs),
+		FilesTouched:   nonNil(info.FilesTouched),
+		StepsCount:     info.CheckpointsCount,
+		IsTask:         info.IsTask,
+		Imported:       info.Imported,
+		Commits:        commits,
+		StampedCommits: info.StampedCommits,
+	}
+}
+
+func splitPrompts(content string) []string {
+	prompts := []string{}
+	for _, p := range strings.Split(content, promptSeparator) {
+		if p = strings.TrimSpace(p); p != "" {
+			prompts = append(prompts, p)
+		}
+	}
+	return prompts
+}
+
+// nonNil returns s, or an empty slice if s is nil, so it encodes as [].
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v) //nolint:errchkjson // the client may have gone away
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}) //nolint:errchkjson // the client may have gone away
+}
+
+// Serve serves handler on listener until ctx is cancelled, then shuts the
+// server down, letting requests in flight finish.
+func Serve(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- srv.Shutdown(shutdownCtx) //nolint:contextcheck // ctx is already cancelled
+	}()
+
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return <-done
+}
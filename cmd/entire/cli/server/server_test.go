@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCheckpointID = "a1b2c3d4e5f6"
+
+// newTestServer serves a repository with one checkpoint, linked to HEAD by
+// a trailer, and one session.
+func newTestServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	testutil.InitRepo(t, dir)
+	testutil.WriteFile(t, dir, "a.go", "package a\n")
+	testutil.GitAdd(t, dir, "a.go")
+	testutil.GitCommit(t, dir, "Add a\n\nEntire-Checkpoint: "+testCheckpointID+"\n")
+	head := testutil.GetHeadHash(t, dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID(testCheckpointID),
+		SessionID:    "2026-04-01-alpha",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		FilesTouched: []string{"a.go"},
+		Transcript:   []byte(`{"type":"user"}` + "\n"),
+		Prompts:      []string{"Write package a", "Add a comment"},
+	}))
+
+	sessions := session.NewStateStoreWithDir(t.TempDir())
+	require.NoError(t, sessions.Save(context.Background(), &session.State{
+		SessionID:        "2026-04-01-alpha",
+		AgentType:        agent.AgentTypeClaudeCode,
+		BaseCommit:       head,
+		StartedAt:        time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC),
+		Phase:            session.PhaseIdle,
+		LastCheckpointID: id.MustCheckpointID(testCheckpointID),
+	}))
+
+	srv := httptest.NewServer(New(Options{
+		Store:    store,
+		Sessions: sessions,
+		LinkedCommits: func(committed []checkpoint.CommittedInfo) (map[id.CheckpointID][]Commit, error) {
+			return map[id.CheckpointID][]Commit{
+				id.MustCheckpointID(testCheckpointID): {{Hash: head, Subject: "Add a"}},
+			}, nil
+		},
+	}))
+	t.Cleanup(srv.Close)
+	return srv, head
+}
+
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url) //nolint:noctx // test request
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestServer_Checkpoints(t *testing.T) {
+	t.Parallel()
+	srv, head := newTestServer(t)
+
+	var list []checkpointView
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/api/checkpoints", &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, testCheckpointID, list[0].CheckpointID.String())
+	assert.Equal(t, []string{"a.go"}, list[0].FilesTouched)
+	assert.Equal(t, []Commit{{Hash: head, Subject: "Add a"}}, list[0].Commits)
+
+	var detail checkpointDetailView
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/api/checkpoints/"+testCheckpointID, &detail))
+	require.Len(t, detail.Sessions, 1)
+	assert.Equal(t, "2026-04-01-alpha", detail.Sessions[0].SessionID)
+	assert.Equal(t, []string{"Write package a", "Add a comment"}, detail.Sessions[0].Prompts)
+
+	var transcript transcriptView
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/api/checkpoints/"+testCheckpointID+"/sessions/0/transcript", &transcript))
+	assert.Contains(t, transcript.Text, `"type":"user"`)
+
+	var apiErr map[string]string
+	assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/api/checkpoints/0a0b0c0d0e0f", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, srv.URL+"/api/checkpoints/nope", &apiErr))
+}
+
+func TestServer_SessionsAndCommits(t *testing.T) {
+	t.Parallel()
+	srv, head := newTestServer(t)
+
+	var sessions []sessionView
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/api/sessions", &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "2026-04-01-alpha", sessions[0].SessionID)
+	assert.Equal(t, testCheckpointID, sessions[0].LastCheckpointID.String())
+
+	var commit commitView
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/api/commits/"+head[:7], &commit))
+	assert.Equal(t, head, commit.Hash)
+	require.Len(t, commit.Checkpoints, 1)
+	assert.Equal(t, testCheckpointID, commit.Checkpoints[0].CheckpointID.String())
+	assert.False(t, commit.Checkpoints[0].Commits[0].Stamped)
+}
+
+func TestServer_ReadOnlyAndLocalOnly(t *testing.T) {
+	t.Parallel()
+	srv, _ := newTestServer(t)
+
+	resp, err := http.Post(srv.URL+"/api/checkpoints", "application/json", strings.NewReader("{}")) //nolint:noctx // test request
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/api/checkpoints", nil)
+	require.NoError(t, err)
+	req.Host = "attacker.example:7373"
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "/") //nolint:noctx // test request
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+}
@@ -0,0 +1,230 @@
+// Entire web UI. Routes live in the URL hash:
+//
+//   #/checkpoints                          checkpoint list
+//   #/checkpoints/<id>                     checkpoint detail
+//   #/checkpoints/<id>/sessions/<index>    session transcript
+//   #/sessions                             session list
+//   #/commits/<hash>                       checkpoints linked to a commit
+//
+// All text from the API is inserted with textContent, never as HTML.
+"use strict";
+
+const app = document.getElementById("app");
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    node.setAttribute(key, value);
+  }
+  for (const child of children) {
+    if (child === null || child === undefined) {
+      continue;
+    }
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function link(href, text, className) {
+  return el("a", className ? { href, class: className } : { href }, text);
+}
+
+function short(hash) {
+  return hash ? hash.slice(0, 7) : "";
+}
+
+function formatTime(value) {
+  if (!value || value.startsWith("0001-")) {
+    return "unknown";
+  }
+  return new Date(value).toLocaleString();
+}
+
+function table(headers, rows) {
+  return el("table", {},
+    el("thead", {}, el("tr", {}, ...headers.map((h) => el("th", {}, h)))),
+    el("tbody", {}, ...rows.map((cells) => el("tr", {}, ...cells.map((c) => el("td", {}, c))))));
+}
+
+function details(pairs) {
+  const list = el("dl");
+  for (const [term, value] of pairs) {
+    if (value === null || value === undefined || value === "") {
+      continue;
+    }
+    list.append(el("dt", {}, term), el("dd", {}, value));
+  }
+  return list;
+}
+
+function commitLinks(commits) {
+  if (!commits || commits.length === 0) {
+    return el("span", { class: "muted" }, "-");
+  }
+  const span = el("span");
+  commits.forEach((c, i) => {
+    if (i > 0) {
+      span.append(", ");
+    }
+    span.append(link("#/commits/" + c.hash, short(c.hash), "mono"));
+  });
+  return span;
+}
+
+async function api(path) {
+  const response = await fetch("api/" + path);
+  const body = await response.json();
+  if (!response.ok) {
+    throw new Error(body.error || response.statusText);
+  }
+  return body;
+}
+
+async function showCheckpoints() {
+  const checkpoints = await api("checkpoints");
+  if (checkpoints.length === 0) {
+    return el("p", { class: "muted" }, "No checkpoints yet.");
+  }
+  return el("div", {},
+    el("h2", {}, "Checkpoints (" + checkpoints.length + ")"),
+    table(["Checkpoint", "Created", "Agent", "Sessions", "Files", "Commits"], checkpoints.map((cp) => [
+      link("#/checkpoints/" + cp.checkpoint_id, cp.checkpoint_id, "mono"),
+      formatTime(cp.created_at),
+      cp.agent || "-",
+      cp.session_ids.join(", ") || "-",
+      String(cp.files_touched.length),
+      commitLinks(cp.commits),
+    ])));
+}
+
+async function showCheckpoint(id) {
+  const cp = await api("checkpoints/" + encodeURIComponent(id));
+  const page = el("div", {},
+    el("h2", {}, "Checkpoint ", el("span", { class: "mono" }, cp.checkpoint_id)),
+    details([
+      ["Created", formatTime(cp.created_at)],
+      ["Agent", cp.agent],
+      ["Steps", String(cp.steps_count)],
+      ["Commits", commitLinks(cp.commits)],
+    ]));
+
+  if (cp.files_touched.length > 0) {
+    page.append(el("section", {},
+      el("h3", {}, "Files touched (" + cp.files_touched.length + ")"),
+      el("ul", {}, ...cp.files_touched.map((f) => el("li", { class: "mono" }, f)))));
+  }
+
+  for (const s of cp.sessions) {
+    const section = el("section", {},
+      el("h3", {}, "Session ", el("span", { class: "mono" }, s.session_id)),
+      details([
+        ["Agent", s.agent],
+        ["Created", formatTime(s.created_at)],
+        ["Branch", s.branch],
+        ["Transcript", link("#/checkpoints/" + cp.checkpoint_id + "/sessions/" + s.index, "View transcript")],
+      ]));
+    if (s.summary && s.summary.intent) {
+      section.append(el("p", {}, s.summary.intent));
+    }
+    if (s.prompts.length > 0) {
+      section.append(el("h4", {}, "Prompts (" + s.prompts.length + ")"),
+        ...s.prompts.map((p) => el("pre", {}, p)));
+    }
+    page.append(section);
+  }
+  return page;
+}
+
+async function showTranscript(id, index) {
+  const transcript = await api("checkpoints/" + encodeURIComponent(id) + "/sessions/" + encodeURIComponent(index) + "/transcript");
+  return el("div", {},
+    el("h2", {}, "Transcript"),
+    details([
+      ["Checkpoint", link("#/checkpoints/" + transcript.checkpoint_id, transcript.checkpoint_id, "mono")],
+      ["Session", transcript.session_id],
+      ["Agent", transcript.agent],
+    ]),
+    el("pre", {}, transcript.text || "(empty)"));
+}
+
+async function showSessions() {
+  const sessions = await api("sessions");
+  if (sessions.length === 0) {
+    return el("p", { class: "muted" }, "No sessions.");
+  }
+  return el("div", {},
+    el("h2", {}, "Sessions (" + sessions.length + ")"),
+    table(["Session", "Agent", "Phase", "Started", "Last activity", "Steps", "Last checkpoint"], sessions.map((s) => [
+      el("span", { class: "mono" }, s.session_id),
+      s.agent || "-",
+      s.phase,
+      formatTime(s.started_at),
+      formatTime(s.last_interaction_time),
+      String(s.step_count),
+      s.last_checkpoint_id ? link("#/checkpoints/" + s.last_checkpoint_id, s.last_checkpoint_id, "mono") : "-",
+    ])));
+}
+
+async function showCommit(hash) {
+  const commit = await api("commits/" + encodeURIComponent(hash));
+  const page = el("div", {},
+    el("h2", {}, "Commit ", el("span", { class: "mono" }, short(commit.hash))),
+    details([
+      ["Hash", el("span", { class: "mono" }, commit.hash)],
+      ["Subject", commit.subject],
+      ["Author", commit.author],
+      ["Date", formatTime(commit.date)],
+    ]));
+  if (commit.checkpoints.length === 0) {
+    page.append(el("p", { class: "muted" }, "No checkpoint is linked to this commit."));
+    return page;
+  }
+  page.append(el("section", {},
+    el("h3", {}, "Checkpoints"),
+    table(["Checkpoint", "Agent", "Sessions", "Link"], commit.checkpoints.map((cp) => [
+      link("#/checkpoints/" + cp.checkpoint_id, cp.checkpoint_id, "mono"),
+      cp.agent || "-",
+      cp.session_ids.join(", ") || "-",
+      cp.commits.some((c) => c.stamped) ? "stamped" : "trailer",
+    ]))));
+  return page;
+}
+
+function route() {
+  const parts = location.hash.replace(/^#\/?/, "").split("/").map(decodeURIComponent);
+  switch (parts[0]) {
+    case "checkpoints":
+      if (parts.length >= 4 && parts[2] === "sessions") {
+        return showTranscript(parts[1], parts[3]);
+      }
+      return parts[1] ? showCheckpoint(parts[1]) : showCheckpoints();
+    case "sessions":
+      return showSessions();
+    case "commits":
+      return showCommit(parts[1] || "HEAD");
+    default:
+      return showCheckpoints();
+  }
+}
+
+async function render() {
+  app.replaceChildren(el("p", { class: "muted" }, "Loading..."));
+  try {
+    app.replaceChildren(await route());
+  } catch (err) {
+    app.replaceChildren(el("p", { class: "error" }, err.message));
+  }
+}
+
+document.getElementById("commit-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const input = document.getElementById("commit-input");
+  const hash = input.value.trim();
+  if (hash) {
+    location.hash = "#/commits/" + encodeURIComponent(hash);
+    input.value = "";
+  }
+});
+
+window.addEventListener("hashchange", render);
+render();
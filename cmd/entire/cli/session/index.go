@@ -0,0 +1,333 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/filelock"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+)
+
+// The session index summarizes every state file in one small JSON file, so
+// filtered iteration (by phase, worktree, or age) can skip state files that
+// cannot match without opening them. It lives next to the state directory
+// rather than inside it, so writing the index does not change the
+// directory's modification time.
+//
+// The index is a hint, never the source of truth. It records the directory's
+// modification time and file count when it was last written; if either has
+// changed since (for example, an older CLI version wrote a state file), the
+// index is ignored and rebuilt from a full scan. Index updates are serialized
+// with a filelock; if the lock cannot be taken, the index is left to go
+// stale and is rebuilt on the next read.
+
+const (
+	indexFileSuffix = ".index.json"
+	lockFileSuffix  = ".index.lock"
+
+	indexLockWait = 500 * time.Millisecond
+)
+
+// Filter restricts which session states StateStore.Iter yields.
+// Zero-valued fields match everything.
+type Filter struct {
+	// Phases matches sessions in any of the given phases.
+	Phases []Phase
+	// WorktreePath matches sessions in the given worktree.
+	WorktreePath string
+	// ExcludeEnded skips sessions that were explicitly closed.
+	ExcludeEnded bool
+	// StartedBefore matches sessions started strictly before this time.
+	StartedBefore time.Time
+	// EndedBefore matches sessions that were closed strictly before this time.
+	EndedBefore time.Time
+	// ActiveSince matches sessions whose last interaction (or start, if no
+	// interaction was recorded) is at or after this time.
+	ActiveSince time.Time
+	// IDPrefix matches sessions whose ID starts with this prefix.
+	IDPrefix string
+}
+
+// indexEntry holds the fields of a State that filters can match on.
+type indexEntry struct {
+	Phase               Phase      `json:"phase,omitempty"`
+	WorktreePath        string     `json:"worktree_path,omitempty"`
+	StartedAt           time.Time  `json:"started_at"`
+	LastInteractionTime *time.Time `json:"last_interaction_time,omitempty"`
+	EndedAt             *time.Time `json:"ended_at,omitempty"`
+}
+
+// sessionIndex is the on-disk index format.
+type sessionIndex struct {
+	DirModTime time.Time             `json:"dir_mod_time"`
+	FileCount  int                   `json:"file_count"`
+	Sessions   map[string]indexEntry `json:"sessions"`
+}
+
+func newIndexEntry(state *State) indexEntry {
+	return indexEntry{
+		Phase:               state.Phase,
+		WorktreePath:        state.WorktreePath,
+		StartedAt:           state.StartedAt,
+		LastInteractionTime: state.LastInteractionTime,
+		EndedAt:             state.EndedAt,
+	}
+}
+
+// stale mirrors State.IsStaleAt.
+func (e indexEntry) stale(now time.Time) bool {
+	return e.LastInteractionTime != nil && now.Sub(*e.LastInteractionTime) > StaleSessionThreshold
+}
+
+// matches reports whether a session with this entry and ID passes the filter.
+func (f Filter) matches(sessionID string, e indexEntry) bool {
+	if len(f.Phases) > 0 {
+		found := false
+		for _, p := range f.Phases {
+			if PhaseFromString(string(e.Phase)) == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.WorktreePath != "" && e.WorktreePath != f.WorktreePath {
+		return false
+	}
+	if f.ExcludeEnded && e.EndedAt != nil {
+		return false
+	}
+	if !f.StartedBefore.IsZero() && !e.StartedAt.Before(f.StartedBefore) {
+		return false
+	}
+	if !f.EndedBefore.IsZero() && (e.EndedAt == nil || !e.EndedAt.Before(f.EndedBefore)) {
+		return false
+	}
+	if !f.ActiveSince.IsZero() {
+		last := e.StartedAt
+		if e.LastInteractionTime != nil {
+			last = *e.LastInteractionTime
+		}
+		if last.Before(f.ActiveSince) {
+			return false
+		}
+	}
+	return f.IDPrefix == "" || strings.HasPrefix(sessionID, f.IDPrefix)
+}
+
+// Iter returns an iterator over the session states that match filter, in
+// session ID order. States are loaded lazily, one at a time, so callers that
+// stop early never read the remaining files. When the index is current,
+// states that cannot match are skipped without being read. Corrupted state
+// files are skipped, and stale sessions are cleaned up as by Load.
+func (s *StateStore) Iter(ctx context.Context, filter Filter) (iter.Seq[*State], error) {
+	ids, err := s.sessionIDs()
+	if err != nil {
+		return nil, err
+	}
+	idx, fresh := s.readIndex(len(ids))
+	now := clock.OrSystem(s.clock).Now()
+
+	return func(yield func(*State) bool) {
+		var rebuilt map[string]indexEntry
+		if !fresh {
+			rebuilt = make(map[string]indexEntry, len(ids))
+		}
+
+		for _, sessionID := range ids {
+			if fresh {
+				if e, ok := idx.Sessions[sessionID]; ok && !e.stale(now) && !filter.matches(sessionID, e) {
+					continue
+				}
+			}
+
+			state, loadErr := s.Load(ctx, sessionID)
+			if loadErr != nil || state == nil {
+				continue // Corrupted, or stale and cleaned up by Load
+			}
+			entry := newIndexEntry(state)
+			if rebuilt != nil {
+				rebuilt[sessionID] = entry
+			}
+			if !filter.matches(sessionID, entry) {
+				continue
+			}
+			if !yield(state) {
+				return // Partial scan: don't write an incomplete index
+			}
+		}
+
+		if rebuilt != nil {
+			s.rebuildIndex(rebuilt)
+		}
+	}, nil
+}
+
+// Unreadable returns the state files that fail to load, keyed by session ID.
+// Iter and List skip them without a trace.
+func (s *StateStore) Unreadable(ctx context.Context) (map[string]error, error) {
+	ids, err := s.sessionIDs()
+	if err != nil {
+		return nil, err
+	}
+	unreadable := make(map[string]error)
+	for _, sessionID := range ids {
+		if _, loadErr := s.Load(ctx, sessionID); loadErr != nil {
+			unreadable[sessionID] = loadErr
+		}
+	}
+	return unreadable, nil
+}
+
+// WorktreePaths returns the distinct worktree paths recorded across all
+// session states, sorted. Uses the index when it is current, so state files
+// are only read after the directory has changed.
+func (s *StateStore) WorktreePaths(ctx context.Context) ([]string, error) {
+	ids, err := s.sessionIDs()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	if idx, fresh := s.readIndex(len(ids)); fresh {
+		for _, e := range idx.Sessions {
+			seen[e.WorktreePath] = true
+		}
+	} else {
+		states, err := s.Iter(ctx, Filter{})
+		if err != nil {
+			return nil, err
+		}
+		for st := range states {
+			seen[st.WorktreePath] = true
+		}
+	}
+	delete(seen, "")
+	return slices.Sorted(maps.Keys(seen)), nil
+}
+
+// sessionIDs lists session IDs from state file names, sorted.
+func (s *StateStore) sessionIDs() ([]string, error) {
+	entries, err := os.ReadDir(s.stateDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session state directory: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return ids, nil
+}
+
+func (s *StateStore) indexPath() string {
+	return s.stateDir + indexFileSuffix
+}
+
+// readIndex returns the index and whether it still describes the state
+// directory, which currently holds fileCount state files.
+func (s *StateStore) readIndex(fileCount int) (*sessionIndex, bool) {
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return nil, false
+	}
+	var idx sessionIndex
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Sessions == nil {
+		return nil, false
+	}
+	info, err := os.Stat(s.stateDir)
+	if err != nil || !info.ModTime().Equal(idx.DirModTime) || idx.FileCount != fileCount {
+		return nil, false
+	}
+	return &idx, true
+}
+
+// writeIndex records sessions along with the state directory's current
+// modification time and file count. Must be called with the index lock held.
+func (s *StateStore) writeIndex(sessions map[string]indexEntry) {
+	info, err := os.Stat(s.stateDir)
+	if err != nil {
+		return
+	}
+	ids, err := s.sessionIDs()
+	if err != nil {
+		return
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(sessionIndex{
+		DirModTime: info.ModTime(),
+		FileCount:  len(ids),
+		Sessions:   sessions,
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := s.indexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, s.indexPath()); err != nil {
+		_ = os.Remove(tmp) //nolint:errcheck // best-effort cleanup
+	}
+}
+
+// rebuildIndex replaces the index with entries gathered by a full scan.
+func (s *StateStore) rebuildIndex(sessions map[string]indexEntry) {
+	unlock, ok := s.lockIndex()
+	if !ok {
+		return
+	}
+	defer unlock()
+	s.writeIndex(sessions)
+}
+
+// withIndex runs mutate, which changes a state file, and then applies update
+// to the index. The index is only updated if it was current before mutate
+// ran; otherwise it is left stale for the next Iter to rebuild.
+func (s *StateStore) withIndex(mutate func() error, update func(map[string]indexEntry)) error {
+	unlock, ok := s.lockIndex()
+	if !ok {
+		// Can't coordinate with other writers; changing the directory without
+		// updating the index makes it stale, which is safe.
+		return mutate()
+	}
+	defer unlock()
+
+	ids, err := s.sessionIDs()
+	var idx *sessionIndex
+	fresh := false
+	if err == nil {
+		idx, fresh = s.readIndex(len(ids))
+	}
+
+	if err := mutate(); err != nil {
+		return err
+	}
+	if fresh {
+		update(idx.Sessions)
+		s.writeIndex(idx.Sessions)
+	}
+	return nil
+}
+
+// lockIndex takes the index lock, waiting up to indexLockWait.
+func (s *StateStore) lockIndex() (unlock func(), ok bool) {
+	lock, err := filelock.Acquire(s.stateDir+lockFileSuffix, indexLockWait)
+	if err != nil {
+		return nil, false
+	}
+	return lock.Release, true
+}
@@ -0,0 +1,176 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func saveStates(t *testing.T, store *StateStore, states ...*State) {
+	t.Helper()
+	for _, st := range states {
+		require.NoError(t, store.Save(context.Background(), st))
+	}
+}
+
+func sessionIDsOf(states []*State) []string {
+	ids := make([]string, 0, len(states))
+	for _, st := range states {
+		ids = append(ids, st.SessionID)
+	}
+	return ids
+}
+
+func TestStateStore_ListMatching(t *testing.T) {
+	t.Parallel()
+	store := NewStateStoreWithDir(filepath.Join(t.TempDir(), SessionStateDirName))
+	ctx := context.Background()
+
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+	ended := now.Add(-time.Hour)
+	saveStates(t, store,
+		&State{SessionID: "a-active", Phase: PhaseActive, WorktreePath: "/repo", StartedAt: now},
+		&State{SessionID: "b-idle", Phase: PhaseIdle, WorktreePath: "/repo", StartedAt: old, LastInteractionTime: &old},
+		&State{SessionID: "c-ended", Phase: PhaseEnded, WorktreePath: "/other", StartedAt: old, EndedAt: &ended},
+	)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "all", filter: Filter{}, want: []string{"a-active", "b-idle", "c-ended"}},
+		{name: "phase", filter: Filter{Phases: []Phase{PhaseActive, PhaseIdle}}, want: []string{"a-active", "b-idle"}},
+		{name: "worktree", filter: Filter{WorktreePath: "/other"}, want: []string{"c-ended"}},
+		{name: "exclude ended", filter: Filter{ExcludeEnded: true}, want: []string{"a-active", "b-idle"}},
+		{name: "started before", filter: Filter{StartedBefore: now.Add(-time.Minute)}, want: []string{"b-idle", "c-ended"}},
+		{name: "ended before", filter: Filter{EndedBefore: now.Add(-time.Minute)}, want: []string{"c-ended"}},
+		{name: "ended before excludes recent", filter: Filter{EndedBefore: now.Add(-2 * time.Hour)}, want: []string{}},
+		{name: "active since", filter: Filter{ActiveSince: now.Add(-time.Minute)}, want: []string{"a-active"}},
+		{name: "id prefix", filter: Filter{IDPrefix: "b-"}, want: []string{"b-idle"}},
+	}
+	for _, tt := range tests {
+		got, err := store.ListMatching(ctx, tt.filter)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, sessionIDsOf(got), tt.name)
+	}
+}
+
+func TestStateStore_IterStopsEarly(t *testing.T) {
+	t.Parallel()
+	store := NewStateStoreWithDir(filepath.Join(t.TempDir(), SessionStateDirName))
+	saveStates(t, store,
+		&State{SessionID: "s1", StartedAt: time.Now()},
+		&State{SessionID: "s2", StartedAt: time.Now()},
+		&State{SessionID: "s3", StartedAt: time.Now()},
+	)
+
+	states, err := store.Iter(context.Background(), Filter{})
+	require.NoError(t, err)
+	var seen []string
+	for st := range states {
+		seen = append(seen, st.SessionID)
+		if len(seen) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"s1", "s2"}, seen)
+}
+
+func TestStateStore_IndexTracksSaveAndClear(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	saveStates(t, store, &State{SessionID: "s1", Phase: PhaseIdle, StartedAt: time.Now()})
+	// The first listing builds the index from a full scan.
+	_, err := store.List(ctx)
+	require.NoError(t, err)
+
+	saveStates(t, store, &State{SessionID: "s2", Phase: PhaseActive, StartedAt: time.Now()})
+	ids, err := store.sessionIDs()
+	require.NoError(t, err)
+	idx, fresh := store.readIndex(len(ids))
+	require.True(t, fresh, "index should stay current after Save")
+	assert.Equal(t, PhaseActive, idx.Sessions["s2"].Phase)
+
+	require.NoError(t, store.Clear(ctx, "s1"))
+	ids, err = store.sessionIDs()
+	require.NoError(t, err)
+	idx, fresh = store.readIndex(len(ids))
+	require.True(t, fresh, "index should stay current after Clear")
+	assert.NotContains(t, idx.Sessions, "s1")
+}
+
+func TestStateStore_IndexRebuiltAfterExternalWrite(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	saveStates(t, store, &State{SessionID: "s1", Phase: PhaseIdle, StartedAt: time.Now()})
+	_, err := store.List(ctx)
+	require.NoError(t, err)
+
+	// Simulate an older CLI version writing a state file without updating the index.
+	data, err := json.Marshal(&State{SessionID: "s2", Phase: PhaseActive, StartedAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "s2.json"), data, 0o600))
+
+	got, err := store.ListMatching(ctx, Filter{Phases: []Phase{PhaseActive}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s2"}, sessionIDsOf(got))
+
+	ids, err := store.sessionIDs()
+	require.NoError(t, err)
+	idx, fresh := store.readIndex(len(ids))
+	require.True(t, fresh, "a full scan should rewrite the index")
+	assert.Contains(t, idx.Sessions, "s2")
+}
+
+func TestStateStore_RemoveAllRemovesIndex(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+
+	saveStates(t, store, &State{SessionID: "s1", StartedAt: time.Now()})
+	states, err := store.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+
+	require.NoError(t, store.RemoveAll())
+	_, err = os.Stat(store.indexPath())
+	assert.True(t, os.IsNotExist(err), "index should be removed")
+
+	states, err = store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, states)
+}
+
+func TestStateStore_WorktreePaths(t *testing.T) {
+	t.Parallel()
+	store := NewStateStoreWithDir(filepath.Join(t.TempDir(), SessionStateDirName))
+	ctx := context.Background()
+
+	saveStates(t, store,
+		&State{SessionID: "s1", WorktreePath: "/repo", StartedAt: time.Now()},
+		&State{SessionID: "s2", WorktreePath: "/repo", StartedAt: time.Now()},
+		&State{SessionID: "s3", WorktreePath: "/repo-feature", StartedAt: time.Now()},
+		&State{SessionID: "s4", StartedAt: time.Now()},
+	)
+
+	// First call scans state files; the second is served from the index.
+	for range 2 {
+		got, err := store.WorktreePaths(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/repo", "/repo-feature"}, got)
+	}
+}
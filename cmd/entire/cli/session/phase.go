@@ -7,8 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
-	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/clock"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 )
 
@@ -58,10 +58,11 @@ const (
 	EventSessionStart              // Session process started (SessionStart hook)
 	EventSessionStop               // Session process ended (SessionStop hook)
 	EventCompaction                // Agent compacted context mid-turn (PreCompress hook)
+	EventIdleTimeout               // No interaction for longer than state.session_timeout_minutes
 )
 
 // allEvents is the canonical list of events for enumeration.
-var allEvents = []Event{EventTurnStart, EventTurnEnd, EventGitCommit, EventSessionStart, EventSessionStop, EventCompaction}
+var allEvents = []Event{EventTurnStart, EventTurnEnd, EventGitCommit, EventSessionStart, EventSessionStop, EventCompaction, EventIdleTimeout}
 
 // String returns a human-readable name for the event.
 func (e Event) String() string {
@@ -78,6 +79,8 @@ func (e Event) String() string {
 		return "SessionStop"
 	case EventCompaction:
 		return "Compaction"
+	case EventIdleTimeout:
+		return "IdleTimeout"
 	default:
 		return fmt.Sprintf("Event(%d)", int(e))
 	}
@@ -119,8 +122,9 @@ func (a Action) String() string {
 // TransitionContext provides read-only context for transitions that need
 // to inspect session state without mutating it.
 type TransitionContext struct {
-	HasFilesTouched    bool // len(FilesTouched) > 0
-	IsRebaseInProgress bool // .git/rebase-merge/ or .git/rebase-apply/ exists
+	HasFilesTouched    bool        // len(FilesTouched) > 0
+	IsRebaseInProgress bool        // .git/rebase-merge/ or .git/rebase-apply/ exists
+	Clock              clock.Clock // time source for applied actions; nil = clock.System
 }
 
 // TransitionResult holds the outcome of a state machine transition.
@@ -184,6 +188,9 @@ func transitionFromIdle(event Event, ctx TransitionContext) TransitionResult {
 			NewPhase: PhaseIdle,
 			Actions:  []Action{ActionCondenseIfFilesTouched, ActionUpdateLastInteraction},
 		}
+	case EventIdleTimeout:
+		// Only a turn left open by a killed agent times out.
+		return TransitionResult{NewPhase: PhaseIdle}
 	default:
 		return TransitionResult{NewPhase: PhaseIdle}
 	}
@@ -227,6 +234,10 @@ func transitionFromActive(event Event, ctx TransitionContext) TransitionResult {
 			NewPhase: PhaseActive,
 			Actions:  []Action{ActionCondenseIfFilesTouched, ActionUpdateLastInteraction},
 		}
+	case EventIdleTimeout:
+		// Agent was killed mid-turn without its stop hooks firing. The last
+		// interaction time is kept so it still shows when the agent went away.
+		return TransitionResult{NewPhase: PhaseEnded}
 	default:
 		return TransitionResult{NewPhase: PhaseActive}
 	}
@@ -267,6 +278,9 @@ func transitionFromEnded(event Event, ctx TransitionContext) TransitionResult {
 	case EventCompaction:
 		// Compaction while ended shouldn't happen, no-op.
 		return TransitionResult{NewPhase: PhaseEnded}
+	case EventIdleTimeout:
+		// Already ended, no-op.
+		return TransitionResult{NewPhase: PhaseEnded}
 	default:
 		return TransitionResult{NewPhase: PhaseEnded}
 	}
@@ -296,8 +310,8 @@ func (NoOpActionHandler) HandleWarnStaleSession(_ *State) error       { return n
 // errors so that bookkeeping fields stay consistent with the new phase.
 // Strategy-specific handler actions stop on the first error; subsequent
 // handler actions are skipped but common actions continue. Returns the
-// first handler error, or nil.
-func ApplyTransition(state *State, result TransitionResult, handler ActionHandler) error {
+// first handler error, or nil. Timestamps come from clk (nil = clock.System).
+func ApplyTransition(state *State, result TransitionResult, handler ActionHandler, clk clock.Clock) error {
 	logCtx := logging.WithComponent(context.Background(), "session")
 
 	actionStrs := make([]string, len(result.Actions))
@@ -318,7 +332,7 @@ func ApplyTransition(state *State, result TransitionResult, handler ActionHandle
 		switch action {
 		// Common actions: always applied, even after a handler error.
 		case ActionUpdateLastInteraction:
-			now := time.Now()
+			now := clock.OrSystem(clk).Now()
 			state.LastInteractionTime = &now
 		case ActionClearEndedAt:
 			state.EndedAt = nil
@@ -68,6 +68,7 @@ func TestEvent_String(t *testing.T) {
 		{EventGitCommit, "GitCommit"},
 		{EventSessionStart, "SessionStart"},
 		{EventSessionStop, "SessionStop"},
+		{EventIdleTimeout, "IdleTimeout"},
 	}
 
 	for _, tt := range tests {
@@ -170,6 +171,13 @@ func TestTransitionFromIdle(t *testing.T) {
 			wantPhase:   PhaseIdle,
 			wantActions: nil,
 		},
+		{
+			name:        "IdleTimeout_while_IDLE_is_noop",
+			current:     PhaseIdle,
+			event:       EventIdleTimeout,
+			wantPhase:   PhaseIdle,
+			wantActions: nil,
+		},
 	})
 }
 
@@ -219,6 +227,13 @@ func TestTransitionFromActive(t *testing.T) {
 			wantPhase:   PhaseActive,
 			wantActions: []Action{ActionWarnStaleSession},
 		},
+		{
+			name:        "IdleTimeout_transitions_to_ENDED_keeping_last_interaction",
+			current:     PhaseActive,
+			event:       EventIdleTimeout,
+			wantPhase:   PhaseEnded,
+			wantActions: nil,
+		},
 	})
 }
 
@@ -276,6 +291,13 @@ func TestTransitionFromEnded(t *testing.T) {
 			wantPhase:   PhaseEnded,
 			wantActions: nil,
 		},
+		{
+			name:        "IdleTimeout_while_ENDED_is_noop",
+			current:     PhaseEnded,
+			event:       EventIdleTimeout,
+			wantPhase:   PhaseEnded,
+			wantActions: nil,
+		},
 	})
 }
 
@@ -425,7 +447,7 @@ func TestApplyTransition_SetsPhaseAndHandlesCommonActions(t *testing.T) {
 		Actions:  []Action{ActionUpdateLastInteraction},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.Equal(t, PhaseActive, state.Phase)
@@ -443,7 +465,7 @@ func TestApplyTransition_CallsHandlerForCondense(t *testing.T) {
 		Actions:  []Action{ActionCondense, ActionUpdateLastInteraction},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.True(t, handler.condenseCalled)
@@ -461,7 +483,7 @@ func TestApplyTransition_CallsHandlerForCondenseIfFilesTouched(t *testing.T) {
 		Actions:  []Action{ActionCondenseIfFilesTouched, ActionUpdateLastInteraction},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.True(t, handler.condenseIfFilesTouchedCalled)
@@ -477,7 +499,7 @@ func TestApplyTransition_CallsHandlerForDiscardIfNoFiles(t *testing.T) {
 		Actions:  []Action{ActionDiscardIfNoFiles, ActionUpdateLastInteraction},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.True(t, handler.discardIfNoFilesCalled)
@@ -493,7 +515,7 @@ func TestApplyTransition_CallsHandlerForWarnStaleSession(t *testing.T) {
 		Actions:  []Action{ActionWarnStaleSession},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.True(t, handler.warnStaleSessionCalled)
@@ -510,7 +532,7 @@ func TestApplyTransition_ClearsEndedAt(t *testing.T) {
 		Actions:  []Action{ActionClearEndedAt},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.NoError(t, err)
 	assert.Nil(t, state.EndedAt)
@@ -527,7 +549,7 @@ func TestApplyTransition_ReturnsHandlerError_ButRunsCommonActions(t *testing.T)
 		Actions:  []Action{ActionCondense, ActionUpdateLastInteraction},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "condense failed")
@@ -547,7 +569,7 @@ func TestApplyTransition_StopsOnFirstHandlerError(t *testing.T) {
 		Actions:  []Action{ActionCondense, ActionWarnStaleSession},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.Error(t, err)
 	assert.True(t, handler.condenseCalled)
@@ -568,7 +590,7 @@ func TestApplyTransition_ClearEndedAtRunsDespiteHandlerError(t *testing.T) {
 		Actions:  []Action{ActionCondenseIfFilesTouched, ActionClearEndedAt},
 	}
 
-	err := ApplyTransition(state, result, handler)
+	err := ApplyTransition(state, result, handler, nil)
 
 	require.Error(t, err)
 	assert.Nil(t, state.EndedAt, "ClearEndedAt must run despite earlier handler error")
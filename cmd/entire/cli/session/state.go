@@ -8,14 +8,23 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/qualitygate"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/validation"
 )
 
@@ -23,6 +32,10 @@ const (
 	// SessionStateDirName is the directory name for session state files within git common dir.
 	SessionStateDirName = "entire-sessions"
 
+	// UsersDirName is the subdirectory of SessionStateDirName holding one state
+	// directory per user when state.per_user is enabled.
+	UsersDirName = "users"
+
 	// StaleSessionThreshold is the duration after which an ended session is considered stale
 	// and will be automatically deleted during load/list operations.
 	StaleSessionThreshold = 7 * 24 * time.Hour
@@ -55,6 +68,11 @@ type State struct {
 	// Derived from .git/worktrees/<name>/, stable across git worktree move
 	WorktreeID string `json:"worktree_id,omitempty"`
 
+	// RepoID is the repository's ID from .entire/repo-id when the session
+	// started. It lets a moved repository claim its sessions: WorktreePath is
+	// re-bound to the worktree's new location only when the IDs match.
+	RepoID string `json:"repo_id,omitempty"`
+
 	// StartedAt is when the session was started
 	StartedAt time.Time `json:"started_at"`
 
@@ -116,9 +134,22 @@ type State struct {
 	// AgentType identifies the agent that created this session (e.g., "Claude Code", "Gemini CLI", "Cursor")
 	AgentType agent.AgentType `json:"agent_type,omitempty"`
 
+	// ParentSessionID is the session this one resumes, when the agent issued a
+	// new session ID on resume (e.g., Claude Code's --resume and --continue).
+	// Detected from the transcript at turn start.
+	ParentSessionID string `json:"parent_session_id,omitempty"`
+
 	// Token usage tracking (accumulated across all checkpoints in this session)
 	TokenUsage *agent.TokenUsage `json:"token_usage,omitempty"`
 
+	// LastQualityGate is the result of the most recent quality_gate command,
+	// run against FilesTouched at turn end. Recorded on the next checkpoint.
+	LastQualityGate *qualitygate.Result `json:"last_quality_gate,omitempty"`
+
+	// LastComplianceScan is the report of the most recent compliance_scan
+	// command, run against FilesTouched at turn end. Recorded on the next checkpoint.
+	LastComplianceScan *compliance.Report `json:"last_compliance_scan,omitempty"`
+
 	// Deprecated: TranscriptLinesAtStart is replaced by CheckpointTranscriptStart.
 	// Kept for backward compatibility with existing state files.
 	TranscriptLinesAtStart int `json:"transcript_lines_at_start,omitempty"`
@@ -133,6 +164,10 @@ type State struct {
 	// FirstPrompt is the first user prompt that started this session (truncated for display)
 	FirstPrompt string `json:"first_prompt,omitempty"`
 
+	// Tickets are the issue or ticket IDs attached with `entire session set-ticket`.
+	// Tickets found in the branch name (tickets.branch_patterns) are added at commit time.
+	Tickets []string `json:"tickets,omitempty"`
+
 	// PromptAttributions tracks user and agent line changes at each prompt start.
 	// This enables accurate attribution by capturing user edits between checkpoints.
 	PromptAttributions []PromptAttribution `json:"prompt_attributions,omitempty"`
@@ -140,6 +175,34 @@ type State struct {
 	// PendingPromptAttribution holds attribution calculated at prompt start (before agent runs).
 	// This is moved to PromptAttributions when SaveStep is called.
 	PendingPromptAttribution *PromptAttribution `json:"pending_prompt_attribution,omitempty"`
+
+	// TornSnapshotFiles are files whose shadow snapshot did not match the file
+	// on disk after a retry in one of the steps since the last condensation.
+	// Recorded on the next checkpoint.
+	TornSnapshotFiles []string `json:"torn_snapshot_files,omitempty"`
+
+	// OmittedFiles are files whose content the steps since the last
+	// condensation left out of the shadow branch because of the snapshot
+	// limits. Recorded on the next checkpoint.
+	OmittedFiles []checkpoint.OmittedFile `json:"omitted_files,omitempty"`
+
+	// Submodules tracks the session's checkpoints in git submodules, keyed by
+	// the submodule's path relative to the worktree root. Files the agent
+	// changes inside a submodule are checkpointed in the submodule's own
+	// repository rather than the superproject's.
+	Submodules map[string]*SubmoduleState `json:"submodules,omitempty"`
+}
+
+// SubmoduleState is a session's checkpoint state in one git submodule.
+type SubmoduleState struct {
+	// BaseCommit is the submodule commit its shadow branch is based on
+	BaseCommit string `json:"base_commit"`
+
+	// StepCount is the number of checkpoints on the submodule's shadow branch
+	StepCount int `json:"checkpoint_count"`
+
+	// FilesTouched are the files changed in the submodule, relative to its root
+	FilesTouched []string `json:"files_touched,omitempty"`
 }
 
 // PromptAttribution captures line-level attribution data at the start of each prompt.
@@ -214,7 +277,12 @@ func (s *State) NormalizeAfterLoad() {
 // If LastInteractionTime isn't set, we don't consider a session stale to avoid aggressively
 // deleting things.
 func (s *State) IsStale() bool {
-	return s.LastInteractionTime != nil && time.Since(*s.LastInteractionTime) > StaleSessionThreshold
+	return s.IsStaleAt(time.Now())
+}
+
+// IsStaleAt is IsStale evaluated at now.
+func (s *State) IsStaleAt(now time.Time) bool {
+	return s.LastInteractionTime != nil && now.Sub(*s.LastInteractionTime) > StaleSessionThreshold
 }
 
 // StateStore provides low-level operations for managing session state files.
@@ -228,26 +296,94 @@ func (s *State) IsStale() bool {
 type StateStore struct {
 	// stateDir is the directory where session state files are stored
 	stateDir string
+
+	// auditDir is the git dir that receives audit events. Empty = parent of stateDir.
+	auditDir string
+
+	// clock decides which sessions are stale. nil = clock.System.
+	clock clock.Clock
 }
 
 // NewStateStore creates a new state store.
 // Uses the git common dir to store session state (shared across worktrees).
+// When state.per_user is enabled, the store only sees the current user's sessions.
 func NewStateStore() (*StateStore, error) {
 	commonDir, err := getGitCommonDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get git common dir: %w", err)
 	}
 	return &StateStore{
-		stateDir: filepath.Join(commonDir, SessionStateDirName),
+		stateDir: StateDir(commonDir),
+		auditDir: commonDir,
 	}, nil
 }
 
+// StateDir returns the current user's session state directory within commonDir:
+// .git/entire-sessions, or .git/entire-sessions/users/<user> when state.per_user
+// is enabled.
+func StateDir(commonDir string) string {
+	dir := filepath.Join(commonDir, SessionStateDirName)
+	if ns := settings.StateUserNamespace(); ns != "" {
+		return filepath.Join(dir, UsersDirName, ns)
+	}
+	return dir
+}
+
+// NewAllUsersStateStores returns a store for the shared session state directory
+// followed by one for each user's directory, so admins can inspect or clean up
+// every user's sessions on a shared clone. The shared store comes first.
+func NewAllUsersStateStores() ([]*StateStore, error) {
+	commonDir, err := getGitCommonDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git common dir: %w", err)
+	}
+	sharedDir := filepath.Join(commonDir, SessionStateDirName)
+	stores := []*StateStore{{stateDir: sharedDir, auditDir: commonDir}}
+
+	entries, err := os.ReadDir(filepath.Join(sharedDir, UsersDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list user state directories: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && !isStoreSidecarDir(entry.Name()) {
+			stores = append(stores, &StateStore{
+				stateDir: filepath.Join(sharedDir, UsersDirName, entry.Name()),
+				auditDir: commonDir,
+			})
+		}
+	}
+	return stores, nil
+}
+
+// ListAllUsers returns the session states matching filter from every store.
+func ListAllUsers(ctx context.Context, stores []*StateStore, filter Filter) ([]*State, error) {
+	var states []*State
+	for _, store := range stores {
+		storeStates, err := store.ListMatching(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, storeStates...)
+	}
+	return states, nil
+}
+
 // NewStateStoreWithDir creates a new state store with a custom directory.
 // This is useful for testing.
 func NewStateStoreWithDir(stateDir string) *StateStore {
 	return &StateStore{stateDir: stateDir}
 }
 
+// Dir returns the directory the store keeps session state files in.
+func (s *StateStore) Dir() string {
+	return s.stateDir
+}
+
+// SetClock sets the time source used to decide which sessions are stale.
+func (s *StateStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
 // Load loads the session state for the given session ID.
 // Returns (nil, nil) when session file doesn't exist or session is stale (not an error condition).
 // Stale sessions (ended longer than StaleSessionThreshold ago) are automatically deleted.
@@ -257,9 +393,27 @@ func (s *StateStore) Load(ctx context.Context, sessionID string) (*State, error)
 		return nil, fmt.Errorf("invalid session ID: %w", err)
 	}
 
-	stateFile := s.stateFilePath(sessionID)
+	state, err := s.readState(ctx, sessionID)
+	if err != nil || state == nil {
+		return state, err
+	}
+
+	if state.IsStaleAt(clock.OrSystem(s.clock).Now()) {
+		logCtx := logging.WithComponent(ctx, "session")
+		logging.Debug(logCtx, "deleting stale session state",
+			slog.String("session_id", sessionID),
+		)
+		_ = s.Clear(ctx, sessionID) //nolint:errcheck // best-effort cleanup of stale session
+		return nil, nil             //nolint:nilnil // stale session treated as not found
+	}
+
+	return state, nil
+}
 
-	data, err := os.ReadFile(stateFile) //nolint:gosec // stateFile is derived from sessionID
+// readState reads the session's state file, recovering the backup if the
+// file is partially written. Returns (nil, nil) when there is no state file.
+func (s *StateStore) readState(ctx context.Context, sessionID string) (*State, error) {
+	data, err := os.ReadFile(s.stateFilePath(sessionID)) //nolint:gosec // path is derived from sessionID
 	if os.IsNotExist(err) {
 		return nil, nil //nolint:nilnil // nil,nil indicates session not found (expected case)
 	}
@@ -269,19 +423,19 @@ func (s *StateStore) Load(ctx context.Context, sessionID string) (*State, error)
 
 	var state State
 	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal session state: %w", err)
-	}
-	state.NormalizeAfterLoad()
-
-	if state.IsStale() {
-		logCtx := logging.WithComponent(ctx, "session")
-		logging.Debug(logCtx, "deleting stale session state",
+		// A crash mid-write can leave a partial file; fall back to the last
+		// good state, which the next Save writes back.
+		backup, ok := s.loadBackup(sessionID)
+		if !ok {
+			return nil, fmt.Errorf("failed to unmarshal session state: %w", err)
+		}
+		logging.Warn(logging.WithComponent(ctx, "session"), "recovered session state from backup",
 			slog.String("session_id", sessionID),
+			slog.String("error", err.Error()),
 		)
-		_ = s.Clear(ctx, sessionID) //nolint:errcheck // best-effort cleanup of stale session
-		return nil, nil             //nolint:nilnil // stale session treated as not found
+		state = *backup
 	}
-
+	state.NormalizeAfterLoad()
 	return &state, nil
 }
 
@@ -294,25 +448,72 @@ func (s *StateStore) Save(ctx context.Context, state *State) error {
 		return fmt.Errorf("invalid session ID: %w", err)
 	}
 
-	if err := os.MkdirAll(s.stateDir, 0o750); err != nil {
-		return fmt.Errorf("failed to create session state directory: %w", err)
+	unlock, err := s.lockSessions(state.SessionID)
+	if err != nil {
+		return err
 	}
+	defer unlock()
+	return s.writeState(state)
+}
 
+// Update loads the session's state, passes it to fn, and saves it unless fn
+// returns an error, holding the session's lock throughout. Unlike Load
+// followed by Save, a change another process saves in between cannot be
+// lost. fn is not called when the session has no state.
+func (s *StateStore) Update(ctx context.Context, sessionID string, fn func(*State) error) error {
+	// Validate session ID to prevent path traversal
+	if err := validation.ValidateSessionID(sessionID); err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	unlock, err := s.lockSessions(sessionID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.readState(ctx, sessionID)
+	if err != nil || state == nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return s.writeState(state)
+}
+
+// writeState writes state to its state file. Must be called with the
+// session's lock held.
+func (s *StateStore) writeState(state *State) error {
 	data, err := jsonutil.MarshalIndentWithNewline(state, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal session state: %w", err)
 	}
 
 	stateFile := s.stateFilePath(state.SessionID)
+	s.backupStateFile(state.SessionID)
 
-	// Atomic write: write to temp file, then rename
-	tmpFile := stateFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write session state: %w", err)
-	}
-	if err := os.Rename(tmpFile, stateFile); err != nil {
-		return fmt.Errorf("failed to rename session state file: %w", err)
+	// Atomic write: write to a synced temp file, then rename
+	err = s.withIndex(func() error {
+		tmpFile, err := s.writeStateTemp(state.SessionID, data)
+		if err != nil {
+			return err
+		}
+		if err := faultinject.Check(faultinject.StateSave); err != nil {
+			_ = os.Remove(tmpFile)
+			return fmt.Errorf("failed to rename session state file: %w", err)
+		}
+		if err := os.Rename(tmpFile, stateFile); err != nil {
+			return fmt.Errorf("failed to rename session state file: %w", err)
+		}
+		return nil
+	}, func(sessions map[string]indexEntry) {
+		sessions[state.SessionID] = newIndexEntry(state)
+	})
+	if err != nil {
+		return err
 	}
+	audit.Record(s.gitDir(), audit.Event{Action: audit.ActionStateWrite, Target: state.SessionID})
 	return nil
 }
 
@@ -325,14 +526,32 @@ func (s *StateStore) Clear(ctx context.Context, sessionID string) error {
 		return fmt.Errorf("invalid session ID: %w", err)
 	}
 
-	stateFile := s.stateFilePath(sessionID)
+	unlock, err := s.lockSessions(sessionID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	if err := os.Remove(stateFile); err != nil {
-		if os.IsNotExist(err) {
-			return nil // Already gone, not an error
+	stateFile := s.stateFilePath(sessionID)
+	_ = os.Remove(s.backupFilePath(sessionID))
+
+	removed := false
+	err = s.withIndex(func() error {
+		if err := os.Remove(stateFile); err != nil {
+			if os.IsNotExist(err) {
+				return nil // Already gone, not an error
+			}
+			return fmt.Errorf("failed to remove session state file: %w", err)
 		}
-		return fmt.Errorf("failed to remove session state file: %w", err)
+		removed = true
+		return nil
+	}, func(sessions map[string]indexEntry) {
+		delete(sessions, sessionID)
+	})
+	if err != nil || !removed {
+		return err
 	}
+	audit.Record(s.gitDir(), audit.Event{Action: audit.ActionStateDelete, Target: sessionID})
 	return nil
 }
 
@@ -342,40 +561,37 @@ func (s *StateStore) RemoveAll() error {
 	if err := os.RemoveAll(s.stateDir); err != nil {
 		return fmt.Errorf("failed to remove session state directory: %w", err)
 	}
+	if err := os.Remove(s.indexPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove session index: %w", err)
+	}
+	if err := os.RemoveAll(s.stateDir + backupDirSuffix); err != nil {
+		return fmt.Errorf("failed to remove session state backups: %w", err)
+	}
+	audit.Record(s.gitDir(), audit.Event{Action: audit.ActionStateDelete, Target: "*"})
 	return nil
 }
 
-// List returns all session states.
+// List returns all session states. Use Iter to filter or stream states
+// without loading them all into memory.
 func (s *StateStore) List(ctx context.Context) ([]*State, error) {
-	entries, err := os.ReadDir(s.stateDir)
-	if os.IsNotExist(err) {
-		return nil, nil
-	}
+	return s.ListMatching(ctx, Filter{})
+}
+
+// ListMatching returns the session states that match filter.
+func (s *StateStore) ListMatching(ctx context.Context, filter Filter) ([]*State, error) {
+	states, err := s.Iter(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read session state directory: %w", err)
+		return nil, err
 	}
+	return slices.Collect(states), nil
+}
 
-	var states []*State
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-		if strings.HasSuffix(entry.Name(), ".tmp") {
-			continue // Skip temp files
-		}
-
-		sessionID := strings.TrimSuffix(entry.Name(), ".json")
-		state, err := s.Load(ctx, sessionID)
-		if err != nil {
-			continue // Skip corrupted state files
-		}
-		if state == nil {
-			continue // Not found or stale (Load handles cleanup)
-		}
-
-		states = append(states, state)
+// gitDir returns the git dir that receives the store's audit events.
+func (s *StateStore) gitDir() string {
+	if s.auditDir != "" {
+		return s.auditDir
 	}
-	return states, nil
+	return filepath.Dir(s.stateDir)
 }
 
 // stateFilePath returns the path to a session state file.
@@ -400,6 +616,10 @@ func ClearGitCommonDirCache() {
 	gitCommonDirMu.Unlock()
 }
 
+func init() {
+	repocache.Register(ClearGitCommonDirCache)
+}
+
 // getGitCommonDir returns the path to the shared git directory.
 // In a regular checkout, this is .git/
 // In a worktree, this is the main repo's .git/ (not .git/worktrees/<name>/)
@@ -0,0 +1,165 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/filelock"
+)
+
+// State files are written crash-safely. A writer holds the session's lock,
+// so hooks firing at the same time (an agent's Stop hook while the user
+// commits) cannot interleave writes to the same temporary file. Update and
+// StateTx hold the lock from loading a state until saving it, so neither
+// hook overwrites the other's change with a stale copy. The new state
+// is written to a temporary file and synced before being renamed into place,
+// and the previous state file, if it was valid, is kept as a backup. If a
+// state file is still found partially written, Load recovers the backup.
+//
+// A session's lock is a filelock on a lock file, so it is released when its
+// holder exits, however long the holder keeps it.
+//
+// Locks and backups live in directories next to the state directory, like
+// the session index, so the state directory only ever holds state files.
+
+const (
+	locksDirSuffix  = ".locks"
+	backupDirSuffix = ".backup"
+)
+
+// sessionLockWait is how long a writer waits for another to release a
+// session's lock. A variable so tests can shorten it.
+var sessionLockWait = 30 * time.Second
+
+// ErrStateLocked is returned when another process holds a session's lock for
+// longer than the writer is willing to wait.
+var ErrStateLocked = errors.New("session state is locked by another process")
+
+// lockSessions takes the locks of the given sessions, in order, retrying with
+// backoff. Callers pass sorted IDs so that concurrent writers cannot deadlock.
+func (s *StateStore) lockSessions(sessionIDs ...string) (unlock func(), err error) {
+	return s.takeSessionLocks(sessionLockWait, sessionIDs)
+}
+
+// tryLockSessions is lockSessions without waiting: it fails with
+// ErrStateLocked if any of the sessions is locked by someone else.
+func (s *StateStore) tryLockSessions(sessionIDs ...string) (unlock func(), err error) {
+	return s.takeSessionLocks(0, sessionIDs)
+}
+
+// takeSessionLocks takes the locks of the given sessions, in order, waiting
+// up to wait for each.
+func (s *StateStore) takeSessionLocks(wait time.Duration, sessionIDs []string) (unlock func(), err error) {
+	var held []*filelock.Lock
+	unlock = func() {
+		for _, lock := range held {
+			lock.Release()
+		}
+	}
+	if len(sessionIDs) > 0 {
+		if err := os.MkdirAll(s.locksDir(), 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create session lock directory: %w", err)
+		}
+	}
+	for _, sessionID := range sessionIDs {
+		lock, err := filelock.Acquire(filepath.Join(s.locksDir(), sessionID+".lock"), wait)
+		if errors.Is(err, filelock.ErrLocked) {
+			err = ErrStateLocked
+		}
+		if err != nil {
+			unlock()
+			return nil, fmt.Errorf("session %s: %w", sessionID, err)
+		}
+		held = append(held, lock)
+	}
+	return unlock, nil
+}
+
+// isStoreSidecarDir reports whether name is a lock or backup directory kept
+// next to a state directory rather than a user's state directory.
+func isStoreSidecarDir(name string) bool {
+	return strings.HasSuffix(name, locksDirSuffix) || strings.HasSuffix(name, backupDirSuffix)
+}
+
+// locksDir returns the directory holding the store's session lock files.
+func (s *StateStore) locksDir() string {
+	return s.stateDir + locksDirSuffix
+}
+
+// backupStateFile keeps the session's current state file as its backup, if
+// the file is valid. A partially written file never replaces a good backup.
+// Must be called with the session's lock held.
+func (s *StateStore) backupStateFile(sessionID string) {
+	stateFile := s.stateFilePath(sessionID)
+	data, err := os.ReadFile(stateFile) //nolint:gosec // stateFile is derived from sessionID
+	if err != nil || !json.Valid(data) {
+		return
+	}
+	if err := os.MkdirAll(s.stateDir+backupDirSuffix, 0o750); err != nil {
+		return
+	}
+	backupFile := s.backupFilePath(sessionID)
+	_ = os.Remove(backupFile)
+	// The state file is replaced by rename, so a hard link keeps its current
+	// contents without copying them.
+	if os.Link(stateFile, backupFile) == nil {
+		return
+	}
+	_ = writeSynced(backupFile+".tmp", data)     //nolint:errcheck // the backup is best-effort
+	_ = os.Rename(backupFile+".tmp", backupFile) //nolint:errcheck // the backup is best-effort
+}
+
+// writeStateTemp writes data to the session's temporary state file and syncs
+// it, so the rename that follows never exposes a partially written file.
+// Must be called with the session's lock held.
+func (s *StateStore) writeStateTemp(sessionID string, data []byte) (string, error) {
+	if err := os.MkdirAll(s.stateDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create session state directory: %w", err)
+	}
+	tmpFile := s.stateFilePath(sessionID) + ".tmp"
+	if err := writeSynced(tmpFile, data); err != nil {
+		_ = os.Remove(tmpFile)
+		return "", fmt.Errorf("failed to write session state: %w", err)
+	}
+	return tmpFile, nil
+}
+
+// writeSynced writes data to path and syncs it to disk.
+func writeSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec // path is within the git directory
+	if err != nil {
+		return err //nolint:wrapcheck // wrapped by callers
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err //nolint:wrapcheck // wrapped by callers
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err //nolint:wrapcheck // wrapped by callers
+	}
+	return f.Close() //nolint:wrapcheck // wrapped by callers
+}
+
+// backupFilePath returns the path to a session's backup state file.
+func (s *StateStore) backupFilePath(sessionID string) string {
+	return filepath.Join(s.stateDir+backupDirSuffix, sessionID+".json")
+}
+
+// loadBackup returns the session's backup state, if it has a valid one.
+func (s *StateStore) loadBackup(sessionID string) (*State, bool) {
+	data, err := os.ReadFile(s.backupFilePath(sessionID))
+	if err != nil {
+		return nil, false
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, false
+	}
+	return &state, true
+}
@@ -0,0 +1,200 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateStore_LoadRecoversFromBackup(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	saveStates(t, store, &State{SessionID: "s1", BaseCommit: "first", StartedAt: time.Now()})
+	saveStates(t, store, &State{SessionID: "s1", BaseCommit: "second", StartedAt: time.Now()})
+
+	// Simulate a crash that left the state file partially written
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "s1.json"), []byte(`{"session_id": "s1", "base_co`), 0o600))
+
+	state, err := store.Load(ctx, "s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "first", state.BaseCommit, "the last good state is recovered")
+
+	// Saving over the torn file must not replace the good backup with it
+	state.BaseCommit = "third"
+	saveStates(t, store, state)
+	backup, ok := store.loadBackup("s1")
+	require.True(t, ok)
+	assert.Equal(t, "first", backup.BaseCommit)
+
+	require.NoError(t, store.Clear(ctx, "s1"))
+	_, ok = store.loadBackup("s1")
+	assert.False(t, ok, "Clear removes the backup")
+}
+
+func TestStateStore_LoadCorruptWithoutBackup(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+
+	require.NoError(t, os.MkdirAll(stateDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(stateDir, "s1.json"), []byte("{"), 0o600))
+
+	_, err := store.Load(context.Background(), "s1")
+	require.Error(t, err)
+}
+
+func TestStateStore_SaveWaitsForLock(t *testing.T) {
+	// Cannot use t.Parallel() because we change sessionLockWait
+	wait := sessionLockWait
+	sessionLockWait = 100 * time.Millisecond
+	t.Cleanup(func() { sessionLockWait = wait })
+
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	unlock, err := store.lockSessions("s1")
+	require.NoError(t, err)
+	err = store.Save(ctx, &State{SessionID: "s1", StartedAt: time.Now()})
+	require.ErrorIs(t, err, ErrStateLocked)
+	unlock()
+	saveStates(t, store, &State{SessionID: "s1", StartedAt: time.Now()})
+
+	// A lock file left behind by a crashed process is not locked by anyone
+	require.NoError(t, os.WriteFile(filepath.Join(store.locksDir(), "s1.lock"), nil, 0o600))
+	saveStates(t, store, &State{SessionID: "s1", StartedAt: time.Now()})
+}
+
+// TestStateStore_StateDirHoldsOnlyStateFiles verifies that locks and backups
+// are kept out of the state directory, which other tools list.
+func TestStateStore_StateDirHoldsOnlyStateFiles(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+
+	saveStates(t, store, &State{SessionID: "s1", StartedAt: time.Now()})
+	saveStates(t, store, &State{SessionID: "s1", StartedAt: time.Now()})
+	_, ok := store.loadBackup("s1")
+	require.True(t, ok)
+
+	entries, err := os.ReadDir(stateDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "s1.json", entries[0].Name())
+}
+
+func TestStateStore_ConcurrentSaves(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.Save(ctx, &State{SessionID: "s1", BaseCommit: fmt.Sprintf("commit-%d", i), StartedAt: time.Now()})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	state, err := store.Load(ctx, "s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Contains(t, state.BaseCommit, "commit-")
+	locks, err := os.ReadDir(store.locksDir())
+	require.NoError(t, err)
+	assert.Empty(t, locks, "every lock is released")
+}
+
+// TestStateStore_ConcurrentUpdates verifies that read-modify-write cycles
+// from different processes (separate stores) never lose an update.
+func TestStateStore_ConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	ctx := context.Background()
+	saveStates(t, NewStateStoreWithDir(stateDir), &State{SessionID: "s1", StartedAt: time.Now()})
+
+	const workers, increments = 4, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*increments)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store := NewStateStoreWithDir(stateDir)
+			for range increments {
+				errs <- store.Update(ctx, "s1", func(state *State) error {
+					state.StepCount++
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	state, err := NewStateStoreWithDir(stateDir).Load(ctx, "s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, workers*increments, state.StepCount, "no increment is lost")
+}
+
+// TestStateTx_HoldsLocksUntilCommit verifies that an update made while a
+// transaction is open, like an agent's Stop hook during post-commit, waits
+// for the transaction and keeps both changes.
+func TestStateTx_HoldsLocksUntilCommit(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	ctx := context.Background()
+	saveStates(t, NewStateStoreWithDir(stateDir), &State{SessionID: "s1", StartedAt: time.Now(), Phase: PhaseActive})
+
+	tx, err := NewStateStoreWithDir(stateDir).Begin(ctx, Filter{})
+	require.NoError(t, err)
+
+	updated := make(chan error, 1)
+	go func() {
+		updated <- NewStateStoreWithDir(stateDir).Update(ctx, "s1", func(state *State) error {
+			state.Phase = PhaseIdle
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-updated:
+		t.Fatalf("Update finished while the transaction held the lock: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	state := tx.Get("s1")
+	require.NotNil(t, state)
+	state.BaseCommit = "condensed"
+	tx.Save(state)
+	require.NoError(t, tx.Commit(ctx))
+	require.NoError(t, <-updated)
+
+	state, err = NewStateStoreWithDir(stateDir).Load(ctx, "s1")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, "condensed", state.BaseCommit, "the transaction's change is kept")
+	assert.Equal(t, PhaseIdle, state.Phase, "the concurrent update is kept")
+}
@@ -8,6 +8,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/clock"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -204,6 +208,29 @@ func TestStateStore_Load_DeletesStaleSession(t *testing.T) {
 	assert.Equal(t, "active-session", loaded.SessionID)
 }
 
+func TestStateStore_Load_UsesClock(t *testing.T) {
+	t.Parallel()
+
+	store := NewStateStoreWithDir(filepath.Join(t.TempDir(), "entire-sessions"))
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	store.SetClock(fake)
+
+	interacted := start
+	require.NoError(t, store.Save(ctx, &State{SessionID: "s1", StartedAt: start, LastInteractionTime: &interacted}))
+
+	loaded, err := store.Load(ctx, "s1")
+	require.NoError(t, err)
+	require.NotNil(t, loaded, "session should not be stale at the fake time it was saved")
+
+	fake.Advance(StaleSessionThreshold + time.Hour)
+	loaded, err = store.Load(ctx, "s1")
+	require.NoError(t, err)
+	assert.Nil(t, loaded, "session should be stale once the fake clock passes the threshold")
+}
+
 func TestStateStore_List_DeletesStaleSession(t *testing.T) {
 	t.Parallel()
 
@@ -251,9 +278,7 @@ func initTestRepo(t *testing.T) string {
 	t.Helper()
 	dir := t.TempDir()
 	// Resolve symlinks (macOS /var -> /private/var)
-	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
-		dir = resolved
-	}
+	dir = paths.ResolveSymlinks(dir)
 	_, err := git.PlainInit(dir, false)
 	require.NoError(t, err)
 	t.Chdir(dir)
@@ -316,16 +341,12 @@ func TestGetGitCommonDir_ClearCache(t *testing.T) {
 func TestGetGitCommonDir_InvalidatesOnCwdChange(t *testing.T) {
 	// Create two separate repos
 	dir1 := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir1); err == nil {
-		dir1 = resolved
-	}
+	dir1 = paths.ResolveSymlinks(dir1)
 	_, err := git.PlainInit(dir1, false)
 	require.NoError(t, err)
 
 	dir2 := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir2); err == nil {
-		dir2 = resolved
-	}
+	dir2 = paths.ResolveSymlinks(dir2)
 	_, err = git.PlainInit(dir2, false)
 	require.NoError(t, err)
 
@@ -358,3 +379,42 @@ func TestGetGitCommonDir_ErrorOutsideRepo(t *testing.T) {
 	_, err := getGitCommonDir()
 	assert.Error(t, err)
 }
+
+func TestNewStateStore_PerUser(t *testing.T) {
+	dir := initTestRepo(t)
+	ctx := context.Background()
+
+	// A session saved while state is shared stays in the shared directory.
+	shared, err := NewStateStore()
+	require.NoError(t, err)
+	require.NoError(t, shared.Save(ctx, &State{SessionID: "shared-session", BaseCommit: "abc123", StartedAt: time.Now()}))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "state": {"per_user": true}}`), 0o600))
+
+	store, err := NewStateStore()
+	require.NoError(t, err)
+	commonDir, err := getGitCommonDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(commonDir, SessionStateDirName, UsersDirName, settings.CurrentUserNamespace()), store.Dir())
+	require.NoError(t, store.Save(ctx, &State{SessionID: "user-session", BaseCommit: "abc123", StartedAt: time.Now()}))
+
+	states, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, states, 1, "per-user store should not see the shared session")
+	assert.Equal(t, "user-session", states[0].SessionID)
+
+	stores, err := NewAllUsersStateStores()
+	require.NoError(t, err)
+	require.Len(t, stores, 2)
+	assert.Equal(t, filepath.Join(commonDir, SessionStateDirName), stores[0].Dir(), "shared store comes first")
+
+	all, err := ListAllUsers(ctx, stores, Filter{})
+	require.NoError(t, err)
+	ids := make([]string, 0, len(all))
+	for _, state := range all {
+		ids = append(ids, state.SessionID)
+	}
+	assert.ElementsMatch(t, []string{"shared-session", "user-session"}, ids)
+}
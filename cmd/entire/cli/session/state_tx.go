@@ -0,0 +1,227 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+)
+
+// StateTx batches session state changes for callers, such as the post-commit
+// hook, that would otherwise load and save the same states several times.
+// Begin loads the states matching a filter once; Get and List then return the
+// same *State values, so changes made through one are seen by the other. Save
+// and Clear only record the change. Commit writes every changed state at the
+// end, under a single index lock.
+//
+// The transaction holds the lock of every session it loaded from Begin until
+// Commit, so another process updating one of them, such as an agent's Stop
+// hook, waits for the transaction instead of having its change overwritten.
+// Sessions Begin did not load are only locked by Commit, and only if no one
+// else holds them. Every Begin must be followed by Commit, which releases the
+// locks.
+type StateTx struct {
+	store   *StateStore
+	states  map[string]*State
+	dirty   map[string]bool
+	cleared map[string]bool
+	locked  map[string]bool
+	unlock  func()
+}
+
+// Begin locks the sessions that match filter and loads their states into a
+// new transaction. Hooks pass their worktree, so they never wait on sessions
+// in other worktrees. Corrupted and stale state files are handled as by Iter.
+func (s *StateStore) Begin(ctx context.Context, filter Filter) (*StateTx, error) {
+	states, err := s.ListMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(states))
+	for _, state := range states {
+		ids = append(ids, state.SessionID)
+	}
+	slices.Sort(ids)
+	unlock, err := s.lockSessions(ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &StateTx{
+		store:   s,
+		states:  make(map[string]*State, len(states)),
+		dirty:   make(map[string]bool),
+		cleared: make(map[string]bool),
+		locked:  make(map[string]bool, len(ids)),
+		unlock:  unlock,
+	}
+	for _, state := range states {
+		tx.locked[state.SessionID] = true
+		// Reload under the lock: the state may have changed since List read it
+		current, err := s.readState(ctx, state.SessionID)
+		if err != nil {
+			current = state
+		}
+		if current != nil {
+			tx.states[state.SessionID] = current
+		}
+	}
+	return tx, nil
+}
+
+// Get returns the state for sessionID, or nil if there is none.
+func (tx *StateTx) Get(sessionID string) *State {
+	return tx.states[sessionID]
+}
+
+// List returns the states that match filter, in session ID order.
+func (tx *StateTx) List(filter Filter) []*State {
+	var states []*State
+	for _, id := range slices.Sorted(maps.Keys(tx.states)) {
+		state := tx.states[id]
+		if filter.matches(id, newIndexEntry(state)) {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// Save records state to be written on Commit.
+func (tx *StateTx) Save(state *State) {
+	tx.states[state.SessionID] = state
+	tx.dirty[state.SessionID] = true
+	delete(tx.cleared, state.SessionID)
+}
+
+// Clear records the session's state file to be removed on Commit.
+func (tx *StateTx) Clear(sessionID string) {
+	delete(tx.states, sessionID)
+	delete(tx.dirty, sessionID)
+	tx.cleared[sessionID] = true
+}
+
+// Commit writes the states saved in the transaction and removes the cleared
+// ones, holding the lock of every session it changes, then releases the
+// locks taken by Begin. Sessions Begin did not load are locked without
+// waiting, because waiting while holding Begin's locks could deadlock; if
+// another process holds one, Commit fails with ErrStateLocked. Every state is first written to a temporary file, and
+// only once all of them are written are they renamed into place, so a failed
+// write leaves every state file as it was. A transaction with no changes
+// touches nothing.
+func (tx *StateTx) Commit(ctx context.Context) error {
+	_ = ctx // Reserved for future use
+
+	if tx.unlock != nil {
+		defer func() {
+			tx.unlock()
+			tx.unlock = nil
+			clear(tx.locked)
+		}()
+	}
+	if len(tx.dirty) == 0 && len(tx.cleared) == 0 {
+		return nil
+	}
+	s := tx.store
+
+	saved := slices.Sorted(maps.Keys(tx.dirty))
+	for _, id := range saved {
+		if err := validation.ValidateSessionID(id); err != nil {
+			return fmt.Errorf("invalid session ID: %w", err)
+		}
+	}
+	cleared := slices.Sorted(maps.Keys(tx.cleared))
+	for _, id := range cleared {
+		if err := validation.ValidateSessionID(id); err != nil {
+			return fmt.Errorf("invalid session ID: %w", err)
+		}
+	}
+
+	// Lock the sessions Begin did not load. Saved and cleared sessions are
+	// disjoint.
+	var unlocked []string
+	for _, id := range append(slices.Clone(saved), cleared...) {
+		if !tx.locked[id] {
+			unlocked = append(unlocked, id)
+		}
+	}
+	slices.Sort(unlocked)
+	unlock, err := s.tryLockSessions(unlocked...)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var tmpFiles []string
+	removeTmpFiles := func() {
+		for _, tmp := range tmpFiles {
+			_ = os.Remove(tmp) //nolint:errcheck // best-effort cleanup
+		}
+	}
+	for _, id := range saved {
+		data, err := jsonutil.MarshalIndentWithNewline(tx.states[id], "", "  ")
+		if err != nil {
+			removeTmpFiles()
+			return fmt.Errorf("failed to marshal session state: %w", err)
+		}
+		s.backupStateFile(id)
+		tmpFile, err := s.writeStateTemp(id, data)
+		if err != nil {
+			removeTmpFiles()
+			return err
+		}
+		tmpFiles = append(tmpFiles, tmpFile)
+	}
+
+	var renamed, removed []string
+	err = s.withIndex(func() error {
+		if err := faultinject.Check(faultinject.StateSave); err != nil {
+			removeTmpFiles()
+			return fmt.Errorf("failed to rename session state file: %w", err)
+		}
+		for _, id := range saved {
+			if err := os.Rename(s.stateFilePath(id)+".tmp", s.stateFilePath(id)); err != nil {
+				removeTmpFiles()
+				return fmt.Errorf("failed to rename session state file: %w", err)
+			}
+			renamed = append(renamed, id)
+		}
+		for _, id := range cleared {
+			_ = os.Remove(s.backupFilePath(id))
+			if err := os.Remove(s.stateFilePath(id)); err != nil {
+				if os.IsNotExist(err) {
+					continue // Already gone, not an error
+				}
+				return fmt.Errorf("failed to remove session state file: %w", err)
+			}
+			removed = append(removed, id)
+		}
+		return nil
+	}, func(sessions map[string]indexEntry) {
+		for _, id := range saved {
+			sessions[id] = newIndexEntry(tx.states[id])
+		}
+		for _, id := range cleared {
+			delete(sessions, id)
+		}
+	})
+
+	for _, id := range renamed {
+		audit.Record(s.gitDir(), audit.Event{Action: audit.ActionStateWrite, Target: id})
+	}
+	for _, id := range removed {
+		audit.Record(s.gitDir(), audit.Event{Action: audit.ActionStateDelete, Target: id})
+	}
+	if err != nil {
+		return err
+	}
+
+	clear(tx.dirty)
+	clear(tx.cleared)
+	return nil
+}
@@ -0,0 +1,112 @@
+package session
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateTx_CommitWritesChangesOnce(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	saveStates(t, store,
+		&State{SessionID: "s1", Phase: PhaseActive, WorktreePath: "/repo", StartedAt: time.Now()},
+		&State{SessionID: "s2", Phase: PhaseIdle, WorktreePath: "/repo", StartedAt: time.Now()},
+		&State{SessionID: "s3", Phase: PhaseIdle, WorktreePath: "/other", StartedAt: time.Now()},
+	)
+	// Build the index so Commit keeps it current
+	_, err := store.List(ctx)
+	require.NoError(t, err)
+
+	tx, err := store.Begin(ctx, Filter{WorktreePath: "/repo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s2"}, sessionIDsOf(tx.List(Filter{})))
+	assert.Nil(t, tx.Get("s3"), "sessions in other worktrees are not loaded")
+
+	// Changes are seen through the transaction but not written yet
+	s1 := tx.Get("s1")
+	require.NotNil(t, s1)
+	s1.BaseCommit = "abc123"
+	s1.Phase = PhaseIdle
+	tx.Save(s1)
+	tx.Save(&State{SessionID: "s4", Phase: PhaseActive, StartedAt: time.Now()})
+	tx.Clear("s3")
+	assert.Same(t, s1, tx.Get("s1"))
+	assert.Nil(t, tx.Get("s3"))
+	assert.Equal(t, []string{"s1", "s2", "s4"}, sessionIDsOf(tx.List(Filter{})))
+
+	onDisk, err := store.Load(ctx, "s1")
+	require.NoError(t, err)
+	assert.Empty(t, onDisk.BaseCommit)
+	_, err = os.Stat(filepath.Join(stateDir, "s3.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, tx.Commit(ctx))
+
+	onDisk, err = store.Load(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", onDisk.BaseCommit)
+	got, err := store.ListMatching(ctx, Filter{Phases: []Phase{PhaseActive}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s4"}, sessionIDsOf(got))
+	_, err = os.Stat(filepath.Join(stateDir, "s3.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	ids, err := store.sessionIDs()
+	require.NoError(t, err)
+	idx, fresh := store.readIndex(len(ids))
+	require.True(t, fresh, "index should stay current after Commit")
+	assert.Equal(t, PhaseIdle, idx.Sessions["s1"].Phase)
+	assert.NotContains(t, idx.Sessions, "s3")
+
+	// Nothing left to write
+	require.NoError(t, tx.Commit(ctx))
+}
+
+func TestStateTx_FailedCommitLeavesStateFiles(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv
+	t.Setenv(faultinject.EnvVar, string(faultinject.StateSave))
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+
+	tx, err := store.Begin(ctx, Filter{})
+	require.NoError(t, err)
+	tx.Save(&State{SessionID: "s1", Phase: PhaseActive, StartedAt: time.Now()})
+	tx.Save(&State{SessionID: "s2", Phase: PhaseActive, StartedAt: time.Now()})
+
+	require.ErrorIs(t, tx.Commit(ctx), faultinject.ErrInjected)
+
+	files, err := filepath.Glob(filepath.Join(stateDir, "*.json*"))
+	require.NoError(t, err)
+	assert.Empty(t, files, "no state or temporary file should be left behind")
+}
+
+func TestStateTx_CommitDoesNotWaitForUnloadedSessions(t *testing.T) {
+	t.Parallel()
+	stateDir := filepath.Join(t.TempDir(), SessionStateDirName)
+	store := NewStateStoreWithDir(stateDir)
+	ctx := context.Background()
+	saveStates(t, store, &State{SessionID: "s1", WorktreePath: "/other", StartedAt: time.Now()})
+
+	tx, err := store.Begin(ctx, Filter{WorktreePath: "/repo"})
+	require.NoError(t, err)
+	unlock, err := NewStateStoreWithDir(stateDir).lockSessions("s1")
+	require.NoError(t, err)
+	defer unlock()
+
+	tx.Clear("s1")
+	require.ErrorIs(t, tx.Commit(ctx), ErrStateLocked)
+	_, err = os.Stat(filepath.Join(stateDir, "s1.json"))
+	require.NoError(t, err, "the locked session is left alone")
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+func newSessionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "session",
+		Aliases: []string{"sessions"},
+		Short:   "Inspect and annotate agent sessions",
+	}
+
+	cmd.AddCommand(newSessionListCmd())
+	cmd.AddCommand(newSessionDiffCmd())
+	cmd.AddCommand(newSessionRewindCmd())
+	cmd.AddCommand(newSessionSetTicketCmd())
+
+	return cmd
+}
+
+// sessionDiffMode selects how `entire session diff` renders its output.
+type sessionDiffMode int
+
+const (
+	sessionDiffPatch sessionDiffMode = iota
+	sessionDiffStat
+	sessionDiffFiles
+)
+
+func newSessionDiffCmd() *cobra.Command {
+	var statFlag bool
+	var filesFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "diff [<session-id>] [-- <path>...]",
+		Short: "Show everything a session has changed since its base commit",
+		Long: `Show a single combined diff of all checkpoints a session has recorded so far,
+against the commit the session started from. Unlike rewinding through
+individual checkpoints, this gives a one-shot review of the agent's work.
+
+The session ID may be abbreviated to any unique prefix. Without one, pick
+from the sessions in this worktree that have checkpoints, and the diff is
+shown in the pager. Paths after "--" restrict the diff; by default it covers
+the files the session has touched.
+
+Output modes:
+  (default)  Full patch
+  --stat     Diffstat summary
+  --files    Names of changed files only`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if statFlag && filesFlag {
+				return errors.New("--stat and --files cannot be used together")
+			}
+			mode := sessionDiffPatch
+			switch {
+			case statFlag:
+				mode = sessionDiffStat
+			case filesFlag:
+				mode = sessionDiffFiles
+			}
+			sessionPrefix, pathFilters := "", args
+			if len(args) > 0 && cmd.ArgsLenAtDash() != 0 {
+				sessionPrefix, pathFilters = args[0], args[1:]
+			}
+			return runSessionDiff(cmd, sessionPrefix, pathFilters, mode)
+		},
+	}
+
+	cmd.Flags().BoolVar(&statFlag, "stat", false, "Show a diffstat instead of the full patch")
+	cmd.Flags().BoolVar(&filesFlag, "files", false, "List changed file names only")
+
+	return cmd
+}
+
+func runSessionDiff(cmd *cobra.Command, sessionPrefix string, pathFilters []string, mode sessionDiffMode) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	if sessionPrefix == "" {
+		return pickSessionDiff(cmd, repoRoot, pathFilters, mode)
+	}
+
+	state, err := findSessionState(ctx, sessionPrefix)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	return sessionDiff(ctx, cmd.OutOrStdout(), repoRoot, state, pathFilters, mode)
+}
+
+// pickSessionDiff lets the user pick one of the current worktree's sessions
+// that have checkpoints and shows its diff through the pager.
+func pickSessionDiff(cmd *cobra.Command, repoRoot string, pathFilters []string, mode sessionDiffMode) error {
+	ctx := context.Background()
+	errW := cmd.ErrOrStderr()
+	if !canPromptInteractively() {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "A session ID is required when not running interactively.")
+		return NewSilentError(errors.New("session ID required"))
+	}
+
+	store, err := session.NewStateStore()
+	if err != nil {
+		return fmt.Errorf("failed to open session store: %w", err)
+	}
+	states, err := store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	options := make([]huh.Option[string], 0, len(states)+1)
+	byID := make(map[string]*session.State)
+	for _, st := range states {
+		if st.StepCount == 0 || st.WorktreePath == "" || paths.RepoRelative(repoRoot, st.WorktreePath) != "." {
+			continue
+		}
+		options = append(options, huh.NewOption(sessionOptionLabel(st), st.SessionID))
+		byID[st.SessionID] = st
+	}
+	if len(byID) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No sessions with checkpoints in this worktree.")
+		return nil
+	}
+	options = append(options, huh.NewOption(i18n.T("Cancel"), ""))
+
+	var selectedID string
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title(i18n.T("Select a session")).
+				Options(options...).
+				Value(&selectedID),
+		),
+	)
+	if err := form.Run(); err != nil || selectedID == "" {
+		return nil //nolint:nilerr // Leaving the menu is not an error
+	}
+
+	var buf bytes.Buffer
+	if err := sessionDiff(ctx, &buf, repoRoot, byID[selectedID], pathFilters, mode); err != nil {
+		return err
+	}
+	outputWithPager(cmd.OutOrStdout(), buf.String())
+	return nil
+}
+
+// sessionDiff writes the session's combined diff, or a note when the session
+// has no uncommitted checkpoints to diff.
+func sessionDiff(ctx context.Context, w io.Writer, repoRoot string, state *session.State, pathFilters []string, mode sessionDiffMode) error {
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+	verify := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "refs/heads/"+shadowBranch) //nolint:gosec // branch name is derived from session state
+	verify.Dir = repoRoot
+	if err := verify.Run(); err != nil {
+		fmt.Fprintf(w, "Session %s has no uncommitted checkpoints since %s.\n",
+			state.SessionID, strategy.TruncateHash(state.BaseCommit))
+		return nil
+	}
+
+	return writeSessionDiff(ctx, w, repoRoot, state, shadowBranch, pathFilters, mode)
+}
+
+// writeSessionDiff writes the combined diff between a session's base commit and
+// the tip of its shadow branch. The shadow branch tip holds the cumulative
+// worktree state after the session's latest step, so a single two-tree diff
+// covers every step. Entire's own metadata under .entire/ is always excluded.
+func writeSessionDiff(ctx context.Context, w io.Writer, repoRoot string, state *session.State, shadowBranch string, pathFilters []string, mode sessionDiffMode) error {
+	args := []string{"diff", "--no-color"}
+	switch mode {
+	case sessionDiffStat:
+		args = append(args, "--stat")
+	case sessionDiffFiles:
+		args = append(args, "--name-only")
+	case sessionDiffPatch:
+	}
+	args = append(args, state.BaseCommit, "refs/heads/"+shadowBranch, "--")
+
+	// Other sessions can share the shadow branch, so default to this session's
+	// files. They are names, not patterns, so glob characters must not expand.
+	pathspecs := pathFilters
+	if len(pathspecs) == 0 {
+		for _, file := range state.FilesTouched {
+			pathspecs = append(pathspecs, ":(literal)"+file)
+		}
+	}
+	if len(pathspecs) == 0 {
+		pathspecs = []string{"."}
+	}
+	args = append(args, pathspecs...)
+	args = append(args, ":(exclude)"+paths.EntireDir)
+
+	diffCmd := exec.CommandContext(ctx, "git", args...)
+	diffCmd.Dir = repoRoot
+	output, err := diffCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("git diff failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("git diff failed: %w", err)
+	}
+
+	if len(output) == 0 {
+		fmt.Fprintf(w, "No changes in session %s since %s.\n", state.SessionID, strategy.TruncateHash(state.BaseCommit))
+		return nil
+	}
+	_, err = w.Write(output)
+	if err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	return nil
+}
+
+// findSessionState returns the session whose ID equals or starts with prefix.
+// Returns an error if no session or more than one session matches.
+func findSessionState(ctx context.Context, prefix string) (*session.State, error) {
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session store: %w", err)
+	}
+	states, err := store.ListMatching(ctx, session.Filter{IDPrefix: prefix})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var matches []*session.State
+	for _, st := range states {
+		if st.SessionID == prefix {
+			return st, nil
+		}
+		if strings.HasPrefix(st.SessionID, prefix) {
+			matches = append(matches, st)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no session found matching %q", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.SessionID)
+		}
+		return nil, fmt.Errorf("session prefix %q is ambiguous, matches: %s", prefix, strings.Join(ids, ", "))
+	}
+}
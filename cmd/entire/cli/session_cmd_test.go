@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSessionDiffRepo creates a repo with a base commit and a shadow branch
+// holding two steps of agent work, and saves a session state for it.
+func setupSessionDiffRepo(t *testing.T) *session.State {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.WriteFile(t, tmpDir, "other.go", "package other\n")
+	testutil.GitAdd(t, tmpDir, "main.go", "other.go")
+	testutil.GitCommit(t, tmpDir, "initial")
+	baseCommit := testutil.GetHeadHash(t, tmpDir)
+
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(baseCommit, "")
+	testutil.GitCheckoutNewBranch(t, tmpDir, shadowBranch)
+
+	// Step 1: edit main.go
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+	testutil.WriteFile(t, tmpDir, ".entire/metadata/test-session/full.jsonl", "{}\n")
+	testutil.GitAdd(t, tmpDir, "main.go", ".entire/metadata/test-session/full.jsonl")
+	testutil.GitCommit(t, tmpDir, "step 1")
+
+	// Step 2: add a new file and edit other.go (touched by another session)
+	testutil.WriteFile(t, tmpDir, "util.go", "package main\n\nfunc helper() {}\n")
+	testutil.WriteFile(t, tmpDir, "other.go", "package other\n\n// changed\n")
+	testutil.GitAdd(t, tmpDir, "util.go", "other.go")
+	testutil.GitCommit(t, tmpDir, "step 2")
+
+	checkout := exec.CommandContext(context.Background(), "git", "checkout", "-q", baseCommit)
+	checkout.Dir = tmpDir
+	out, err := checkout.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	state := &session.State{
+		SessionID:    "2026-02-01-diff-session",
+		BaseCommit:   baseCommit,
+		StartedAt:    time.Now(),
+		StepCount:    2,
+		FilesTouched: []string{"main.go", "util.go"},
+	}
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Save(context.Background(), state))
+	return state
+}
+
+func runSessionDiffForTest(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := newSessionDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute())
+	return out.String()
+}
+
+func TestSessionDiff_CombinesAllSteps(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupSessionDiffRepo(t)
+
+	output := runSessionDiffForTest(t, "2026-02-01-diff")
+
+	assert.Contains(t, output, "+func main() {}")
+	assert.Contains(t, output, "+func helper() {}")
+	assert.NotContains(t, output, "other.go", "files not touched by the session should be excluded")
+	assert.NotContains(t, output, ".entire/metadata", "metadata should be excluded")
+}
+
+func TestSessionDiff_StatAndFiles(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupSessionDiffRepo(t)
+
+	stat := runSessionDiffForTest(t, "2026-02-01-diff-session", "--stat")
+	assert.Contains(t, stat, "2 files changed")
+
+	files := runSessionDiffForTest(t, "2026-02-01-diff-session", "--files")
+	assert.Equal(t, "main.go\nutil.go\n", files)
+
+	filtered := runSessionDiffForTest(t, "2026-02-01-diff-session", "--files", "--", "util.go")
+	assert.Equal(t, "util.go\n", filtered)
+}
+
+func TestSessionDiff_UnknownSession(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupSessionDiffRepo(t)
+
+	cmd := newSessionDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"does-not-exist"})
+	require.Error(t, cmd.Execute())
+	assert.Contains(t, out.String(), `no session found matching "does-not-exist"`)
+}
+
+func TestSessionDiff_FilesTouchedAreLiteral(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	state := setupSessionDiffRepo(t)
+	state.FilesTouched = []string{"*.go"}
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Save(context.Background(), state))
+
+	output := runSessionDiffForTest(t, state.SessionID, "--files")
+	assert.Contains(t, output, "No changes in session", "*.go names a file, not a pattern")
+}
+
+func TestSessionDiff_NoSessionIDNonInteractive(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	setupSessionDiffRepo(t)
+	t.Setenv("ENTIRE_TEST_TTY", "0")
+
+	cmd := newSessionDiffCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--", "main.go"})
+	require.Error(t, cmd.Execute())
+	assert.Contains(t, out.String(), "A session ID is required")
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"context"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// maxSessionLineageDepth bounds how many resumes a lineage follows back, in
+// case metadata written by hand links sessions into a cycle.
+const maxSessionLineageDepth = 50
+
+// sessionLineageEntry is one session in a chain of resumed sessions.
+type sessionLineageEntry struct {
+	SessionID string `json:"session_id"`
+	// Checkpoints are the committed checkpoints the session contributed to,
+	// newest first.
+	Checkpoints []string `json:"checkpoints"`
+}
+
+// sessionLineage follows ParentSessionID links back from sessionID and
+// returns the chain oldest first, ending with sessionID itself. A session's
+// parent is read from its local state when it still has one, and otherwise
+// from the metadata of its newest committed checkpoint. committed must be
+// sorted newest first, as ListCommitted returns it.
+func sessionLineage(ctx context.Context, store *checkpoint.GitStore, committed []checkpoint.CommittedInfo, sessionID string) []sessionLineageEntry {
+	var lineage []sessionLineageEntry
+	seen := make(map[string]bool)
+	for current := sessionID; current != "" && !seen[current] && len(lineage) < maxSessionLineageDepth; {
+		seen[current] = true
+		entry := sessionLineageEntry{SessionID: current, Checkpoints: []string{}}
+		for _, info := range committed {
+			if slices.Contains(info.SessionIDs, current) {
+				entry.Checkpoints = append(entry.Checkpoints, info.CheckpointID.String())
+			}
+		}
+		lineage = append(lineage, entry)
+		current = parentSessionID(ctx, store, committed, current)
+	}
+	slices.Reverse(lineage)
+	return lineage
+}
+
+// parentSessionID returns the session that sessionID resumes, or "" if it
+// resumes none or nothing is known about it.
+func parentSessionID(ctx context.Context, store *checkpoint.GitStore, committed []checkpoint.CommittedInfo, sessionID string) string {
+	if state, err := strategy.LoadSessionState(sessionID); err == nil && state != nil {
+		return state.ParentSessionID
+	}
+	for _, info := range committed {
+		if !slices.Contains(info.SessionIDs, sessionID) {
+			continue
+		}
+		metadata, err := store.ReadCommittedMetadata(ctx, info.CheckpointID)
+		if err != nil {
+			continue
+		}
+		for _, m := range metadata {
+			if m.SessionID == sessionID {
+				return m.ParentSessionID
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/spf13/cobra"
+)
+
+func newSessionListCmd() *cobra.Command {
+	var phaseFlag string
+	var agentFlag string
+	var sinceFlag string
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all sessions with phase, agent, files, tokens, and age",
+		Long: `List every session Entire is tracking in this repository, across all
+worktrees, newest first. Each row shows the session's phase, agent, number of
+files touched, token usage, and when it was last active.
+
+--phase is one of active, idle, or ended. --agent accepts an agent name
+(claude-code, gemini, ...) or the agent type shown in the list. --since accepts
+a duration (90m, 24h, 7d), a date (2006-01-02), or an RFC 3339 timestamp and
+keeps sessions active since then. --json prints one session state per line.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter := session.Filter{}
+			if phaseFlag != "" {
+				phase := session.Phase(strings.ToLower(phaseFlag))
+				if phase != session.PhaseActive && phase != session.PhaseIdle && phase != session.PhaseEnded {
+					return fmt.Errorf("invalid --phase %q: use active, idle, or ended", phaseFlag)
+				}
+				filter.Phases = []session.Phase{phase}
+			}
+			since, err := parseSince(sinceFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			filter.ActiveSince = since
+			return runSessionList(cmd, filter, agentFlag, jsonFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&phaseFlag, "phase", "", "Only list sessions in this phase (active, idle, ended)")
+	cmd.Flags().StringVar(&agentFlag, "agent", "", "Only list sessions of this agent")
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only list sessions active after this time (duration, date, or timestamp)")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output session states as JSON lines")
+
+	return cmd
+}
+
+func runSessionList(cmd *cobra.Command, filter session.Filter, agentName string, asJSON bool) error {
+	store, err := session.NewStateStore()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+		return NewSilentError(err)
+	}
+	states, err := store.ListMatching(context.Background(), filter)
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	if agentName != "" {
+		agentType := resolveAgentType(agentName)
+		states = slices.DeleteFunc(states, func(st *session.State) bool {
+			return !strings.EqualFold(string(st.AgentType), string(agentType))
+		})
+	}
+	slices.SortFunc(states, func(a, b *session.State) int {
+		return lastActive(b).Compare(lastActive(a))
+	})
+
+	w := cmd.OutOrStdout()
+	if asJSON {
+		return writeSessionListJSON(w, states)
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(w, "No sessions found.")
+		return nil
+	}
+	writeSessionList(w, states)
+	return nil
+}
+
+// resolveAgentType maps an --agent value to an agent type, accepting
+// registry names ("claude-code") as well as types ("Claude Code").
+func resolveAgentType(name string) agent.AgentType {
+	if ag, err := agent.Get(agent.AgentName(strings.ToLower(name))); err == nil {
+		return ag.Type()
+	}
+	return agent.AgentType(name)
+}
+
+// lastActive returns when a session last interacted, or its start time.
+func lastActive(st *session.State) time.Time {
+	if st.LastInteractionTime != nil {
+		return *st.LastInteractionTime
+	}
+	return st.StartedAt
+}
+
+func writeSessionListJSON(w io.Writer, states []*session.State) error {
+	enc := json.NewEncoder(w)
+	for _, st := range states {
+		if err := enc.Encode(st); err != nil {
+			return fmt.Errorf("failed to encode session: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeSessionList writes one aligned row per session.
+func writeSessionList(w io.Writer, states []*session.State) {
+	idWidth := len("SESSION")
+	agentWidth := len("AGENT")
+	for _, st := range states {
+		idWidth = max(idWidth, len(st.SessionID))
+		agentWidth = max(agentWidth, len(sessionAgentLabel(st)))
+	}
+
+	fmt.Fprintf(w, "%-*s  %-6s  %-*s  %5s  %7s  %s\n", idWidth, "SESSION", "PHASE", agentWidth, "AGENT", "FILES", "TOKENS", "LAST ACTIVE")
+	for _, st := range states {
+		fmt.Fprintf(w, "%-*s  %-6s  %-*s  %5d  %7s  %s\n",
+			idWidth, st.SessionID,
+			st.Phase,
+			agentWidth, sessionAgentLabel(st),
+			len(st.FilesTouched),
+			formatTokenCount(totalTokens(st.TokenUsage)),
+			timeAgo(lastActive(st)))
+	}
+}
+
+func sessionAgentLabel(st *session.State) string {
+	if st.AgentType == "" {
+		return unknownPlaceholder
+	}
+	return string(st.AgentType)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionListStates(t *testing.T) {
+	t.Helper()
+	setupTestRepo(t)
+
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	now := time.Now()
+	recent := now.Add(-5 * time.Minute)
+	old := now.Add(-72 * time.Hour)
+	for _, st := range []*session.State{
+		{
+			SessionID:           "2026-05-01-active",
+			Phase:               session.PhaseActive,
+			AgentType:           agent.AgentTypeClaudeCode,
+			StartedAt:           recent,
+			LastInteractionTime: &recent,
+			FilesTouched:        []string{"a.go", "b.go"},
+			TokenUsage:          &agent.TokenUsage{InputTokens: 1000, OutputTokens: 200},
+		},
+		{
+			SessionID: "2026-04-28-ended",
+			Phase:     session.PhaseEnded,
+			AgentType: agent.AgentTypeGemini,
+			StartedAt: old,
+			EndedAt:   &old,
+		},
+	} {
+		require.NoError(t, store.Save(context.Background(), st))
+	}
+}
+
+func runSessionListForTest(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := newSessionListCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute(), out.String())
+	return out.String()
+}
+
+func TestSessionList(t *testing.T) {
+	setupSessionListStates(t)
+
+	out := runSessionListForTest(t)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3, out)
+	assert.Contains(t, lines[0], "SESSION")
+	assert.Contains(t, lines[1], "2026-05-01-active")
+	assert.Contains(t, lines[1], "active")
+	assert.Contains(t, lines[1], string(agent.AgentTypeClaudeCode))
+	assert.Contains(t, lines[1], "1.2k")
+	assert.Contains(t, lines[1], "5m ago")
+	assert.Contains(t, lines[2], "2026-04-28-ended")
+	assert.Contains(t, lines[2], "3d ago")
+}
+
+func TestSessionList_Filters(t *testing.T) {
+	setupSessionListStates(t)
+
+	out := runSessionListForTest(t, "--phase", "ended")
+	assert.Contains(t, out, "2026-04-28-ended")
+	assert.NotContains(t, out, "2026-05-01-active")
+
+	out = runSessionListForTest(t, "--agent", "claude-code")
+	assert.Contains(t, out, "2026-05-01-active")
+	assert.NotContains(t, out, "2026-04-28-ended")
+
+	out = runSessionListForTest(t, "--since", "24h")
+	assert.Contains(t, out, "2026-05-01-active")
+	assert.NotContains(t, out, "2026-04-28-ended")
+
+	out = runSessionListForTest(t, "--phase", "idle")
+	assert.Equal(t, "No sessions found.\n", out)
+
+	cmd := newSessionListCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--phase", "sleeping"})
+	require.Error(t, cmd.Execute())
+}
+
+func TestSessionList_JSON(t *testing.T) {
+	setupSessionListStates(t)
+
+	out := runSessionListForTest(t, "--json")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2, out)
+
+	var st session.State
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &st))
+	assert.Equal(t, "2026-05-01-active", st.SessionID)
+	assert.Equal(t, []string{"a.go", "b.go"}, st.FilesTouched)
+	require.NotNil(t, st.TokenUsage)
+	assert.Equal(t, 1000, st.TokenUsage.InputTokens)
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+)
+
+func newSessionRewindCmd() *cobra.Command {
+	var sessionFlag string
+	var toFlag int
+	var listFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "rewind",
+		Short: "Undo a session's changes since one of its checkpoints",
+		Long: `Undo everything an agent session changed after one of its checkpoints.
+
+The session's uncommitted checkpoints are numbered from 1, oldest first. Pick
+one interactively, or pass its number with --to. Files the session changed in
+later checkpoints are restored to their content at the chosen checkpoint, and
+files it created since are deleted. Every other file, including your own local
+changes and other sessions' work, is left as it is. The agent's transcript is
+not changed; use 'entire rewind' to restore the agent's context as well.
+
+By default the command applies to the session running in this worktree; pass
+--session when there are several.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			if listFlag && toFlag != 0 {
+				return errors.New("--list and --to cannot be used together")
+			}
+			return runSessionRewind(cmd, sessionFlag, toFlag, listFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "Session ID or unique prefix (default: the session in this worktree)")
+	cmd.Flags().IntVar(&toFlag, "to", 0, "Number of the checkpoint to rewind to (non-interactive)")
+	cmd.Flags().BoolVar(&listFlag, "list", false, "List the session's checkpoints")
+
+	return cmd
+}
+
+func runSessionRewind(cmd *cobra.Command, sessionPrefix string, to int, list bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	fail := func(err error) error {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	var state *session.State
+	var err error
+	if sessionPrefix != "" {
+		state, err = findSessionState(ctx, sessionPrefix)
+	} else {
+		state, err = worktreeSession(ctx)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	strat, ok := GetStrategy().(*strategy.ManualCommitStrategy)
+	if !ok {
+		return fail(errors.New("session rewind needs shadow-branch checkpoints, which the patch-file strategy does not create"))
+	}
+	points, err := strat.SessionRewindPoints(state.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	if len(points) == 0 {
+		fmt.Fprintf(w, "Session %s has no uncommitted checkpoints since %s.\n",
+			state.SessionID, strategy.TruncateHash(state.BaseCommit))
+		return nil
+	}
+
+	if list {
+		writeSessionRewindPoints(w, points)
+		return nil
+	}
+
+	interactive := to == 0
+	if interactive {
+		if to, err = selectSessionRewindPoint(points); err != nil {
+			return err
+		}
+		if to == 0 {
+			fmt.Fprintln(w, "Rewind cancelled.")
+			return nil
+		}
+	}
+	if to < 1 || to > len(points) {
+		return fail(fmt.Errorf("checkpoint %d does not exist; session %s has checkpoints 1 to %d", to, state.SessionID, len(points)))
+	}
+	target := to - 1
+
+	preview, err := strat.PreviewSessionRewind(points, target)
+	if err != nil {
+		return fmt.Errorf("failed to preview rewind: %w", err)
+	}
+	if len(preview.FilesToRestore) == 0 && len(preview.FilesToDelete) == 0 {
+		fmt.Fprintf(w, "Session %s changed no files after checkpoint %d.\n", state.SessionID, to)
+		return nil
+	}
+	writeSessionRewindPreview(w, preview)
+
+	if interactive {
+		var confirm bool
+		form := NewAccessibleForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(fmt.Sprintf("Undo the session's changes since checkpoint %d?", to)).
+					Description("Changes the session made after this checkpoint will be lost.").
+					Value(&confirm),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return fmt.Errorf("confirmation cancelled: %w", err)
+		}
+		if !confirm {
+			fmt.Fprintln(w, "Rewind cancelled.")
+			return nil
+		}
+	}
+
+	if _, err := strat.RewindSession(points, target); err != nil {
+		return fmt.Errorf("failed to rewind session: %w", err)
+	}
+	fmt.Fprintf(w, "Rewound session %s to checkpoint %d (%s).\n", state.SessionID, to, strategy.TruncateHash(points[target].ID))
+	return nil
+}
+
+// selectSessionRewindPoint asks which checkpoint to rewind to, newest first,
+// and returns its number, or 0 if cancelled.
+func selectSessionRewindPoint(points []strategy.RewindPoint) (int, error) {
+	options := make([]huh.Option[int], 0, len(points)+1)
+	for i := len(points) - 1; i >= 0; i-- {
+		options = append(options, huh.NewOption(sessionRewindPointLabel(points, i), i+1))
+	}
+	options = append(options, huh.NewOption("Cancel", 0))
+
+	var selected int
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Select a checkpoint to rewind to").
+				Description("Files the session changed after it are restored; other files are left alone").
+				Options(options...).
+				Value(&selected),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return 0, fmt.Errorf("selection cancelled: %w", err)
+	}
+	return selected, nil
+}
+
+// writeSessionRewindPoints lists the session's checkpoints, newest first.
+func writeSessionRewindPoints(w io.Writer, points []strategy.RewindPoint) {
+	for i := len(points) - 1; i >= 0; i-- {
+		fmt.Fprintln(w, sessionRewindPointLabel(points, i))
+	}
+}
+
+// sessionRewindPointLabel describes the checkpoint at index i of points.
+func sessionRewindPointLabel(points []strategy.RewindPoint, i int) string {
+	p := points[i]
+	label := fmt.Sprintf("%3d  %s  (%s) %s", i+1, strategy.TruncateHash(p.ID), p.Date.Format("2006-01-02 15:04"), sanitizeForTerminal(p.Message))
+	if p.IsTaskCheckpoint {
+		label += " [Task]"
+	}
+	if i == len(points)-1 {
+		label += " (latest)"
+	}
+	return label
+}
+
+// writeSessionRewindPreview lists the files a session rewind changes.
+func writeSessionRewindPreview(w io.Writer, preview *strategy.RewindPreview) {
+	if len(preview.FilesToRestore) > 0 {
+		fmt.Fprintln(w, "Files to restore:")
+		for _, f := range preview.FilesToRestore {
+			fmt.Fprintf(w, "  %s\n", f)
+		}
+	}
+	if len(preview.FilesToDelete) > 0 {
+		fmt.Fprintln(w, "Files to delete (created by the session since):")
+		for _, f := range preview.FilesToDelete {
+			fmt.Fprintf(w, "  %s\n", f)
+		}
+	}
+	fmt.Fprintln(w)
+}
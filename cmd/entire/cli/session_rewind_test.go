@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSessionRewindForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newSessionRewindCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSessionRewind(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n")
+	testutil.GitAdd(t, tmpDir, "main.go")
+	testutil.GitCommit(t, tmpDir, "initial")
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	const sessionID = "2026-03-01-rewind-session"
+	s := strategy.NewManualCommitStrategy()
+	metadataDir := ".entire/metadata/" + sessionID
+	testutil.WriteFile(t, tmpDir, metadataDir+"/"+paths.TranscriptFileName, "{}\n")
+	step := func(message string, modified, added []string) {
+		t.Helper()
+		require.NoError(t, s.SaveStep(strategy.StepContext{
+			SessionID:      sessionID,
+			ModifiedFiles:  modified,
+			NewFiles:       added,
+			MetadataDir:    metadataDir,
+			MetadataDirAbs: filepath.Join(tmpDir, metadataDir),
+			CommitMessage:  message,
+			AuthorName:     "Test",
+			AuthorEmail:    "test@test.com",
+		}))
+	}
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() {}\n")
+	step("Add main", []string{"main.go"}, nil)
+	testutil.WriteFile(t, tmpDir, "main.go", "package main\n\nfunc main() { run() }\n")
+	testutil.WriteFile(t, tmpDir, "run.go", "package main\n\nfunc run() {}\n")
+	step("Add run", []string{"main.go"}, []string{"run.go"})
+	testutil.WriteFile(t, tmpDir, "README.md", "my notes\n")
+
+	out, err := runSessionRewindForTest(t, "--session", "2026-03-01-rew", "--list")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "  2  ")
+	assert.Contains(t, out, "Add run (latest)")
+	assert.Contains(t, out, "Add main")
+
+	out, err = runSessionRewindForTest(t, "--session", sessionID, "--to", "3")
+	require.Error(t, err)
+	assert.Contains(t, out, "checkpoint 3 does not exist")
+
+	out, err = runSessionRewindForTest(t, "--session", sessionID, "--to", "1")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "Files to restore:\n  main.go\n")
+	assert.Contains(t, out, "Files to delete (created by the session since):\n  run.go\n")
+	assert.Contains(t, out, "Rewound session "+sessionID+" to checkpoint 1")
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc main() {}\n", string(data))
+	assert.NoFileExists(t, filepath.Join(tmpDir, "run.go"))
+	assert.FileExists(t, filepath.Join(tmpDir, "README.md"), "files outside the session are kept")
+}
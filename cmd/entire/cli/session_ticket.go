@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+
+	"github.com/spf13/cobra"
+)
+
+func newSessionSetTicketCmd() *cobra.Command {
+	var sessionFlag string
+	var clearFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "set-ticket <ticket>...",
+		Short: "Link a session to issue or ticket IDs",
+		Long: `Attach issue or ticket IDs (for example ABC-123 or #42) to an agent
+session. They are recorded in the metadata of the session's checkpoints and
+added to commits as Entire-Ticket trailers, next to Entire-Checkpoint.
+
+The tickets replace any set before; --clear removes them. By default the
+command applies to the session running in this worktree; pass --session when
+there are several. Tickets can also be found in branch names automatically
+with the tickets.branch_patterns setting.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearFlag && len(args) > 0 {
+				return errors.New("--clear cannot be used with ticket IDs")
+			}
+			if !clearFlag && len(args) == 0 {
+				return errors.New("pass at least one ticket ID, or --clear")
+			}
+			return runSessionSetTicket(cmd, sessionFlag, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "Session ID or unique prefix (default: the session in this worktree)")
+	cmd.Flags().BoolVar(&clearFlag, "clear", false, "Remove the session's tickets")
+
+	return cmd
+}
+
+func runSessionSetTicket(cmd *cobra.Command, sessionPrefix string, tickets []string) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	fail := func(err error) error {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	var unique []string
+	for _, ticket := range tickets {
+		ticket = strings.TrimSpace(ticket)
+		if err := validation.ValidateTicketID(ticket); err != nil {
+			return fail(err)
+		}
+		if !slices.Contains(unique, ticket) {
+			unique = append(unique, ticket)
+		}
+	}
+
+	var state *session.State
+	var err error
+	if sessionPrefix != "" {
+		state, err = findSessionState(ctx, sessionPrefix)
+	} else {
+		state, err = worktreeSession(ctx)
+	}
+	if err != nil {
+		return fail(err)
+	}
+
+	state.Tickets = unique
+	if err := strategy.SaveSessionState(state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+
+	if len(unique) == 0 {
+		fmt.Fprintf(w, "Removed the tickets of session %s.\n", state.SessionID)
+		return nil
+	}
+	fmt.Fprintf(w, "Session %s linked to %s.\n", state.SessionID, strings.Join(unique, ", "))
+	return nil
+}
+
+// worktreeSession returns the one session in the current worktree that has
+// not ended. Returns an error when there is none or more than one.
+func worktreeSession(ctx context.Context) (*session.State, error) {
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil, errors.New("not a git repository")
+	}
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session store: %w", err)
+	}
+	states, err := store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var matches []*session.State
+	for _, st := range states {
+		if st.EndedAt == nil && st.WorktreePath != "" && paths.RepoRelative(root, st.WorktreePath) == "." {
+			matches = append(matches, st)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("no session is running in this worktree; pass --session")
+	case 1:
+		return matches[0], nil
+	default:
+		ids := make([]string, 0, len(matches))
+		for _, m := range matches {
+			ids = append(ids, m.SessionID)
+		}
+		return nil, fmt.Errorf("several sessions are running in this worktree (%s); pick one with --session", strings.Join(ids, ", "))
+	}
+}
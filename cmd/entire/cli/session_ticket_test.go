@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runSessionSetTicketForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newSessionSetTicketCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestSessionSetTicket(t *testing.T) {
+	setupTestRepo(t)
+
+	root, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	ended := time.Now()
+	for _, st := range []*session.State{
+		{SessionID: "2026-05-01-running", WorktreePath: root, StartedAt: time.Now()},
+		{SessionID: "2026-05-01-ended", WorktreePath: root, StartedAt: time.Now(), EndedAt: &ended},
+	} {
+		require.NoError(t, store.Save(context.Background(), st))
+	}
+
+	out, err := runSessionSetTicketForTest(t, "ABC-123", "#42", "ABC-123")
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "Session 2026-05-01-running linked to ABC-123, #42.")
+	state, err := strategy.LoadSessionState("2026-05-01-running")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ABC-123", "#42"}, state.Tickets)
+
+	out, err = runSessionSetTicketForTest(t, "--session", "2026-05-01-e", "ENG-7")
+	require.NoError(t, err, out)
+	state, err = strategy.LoadSessionState("2026-05-01-ended")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ENG-7"}, state.Tickets)
+
+	out, err = runSessionSetTicketForTest(t, "--clear")
+	require.NoError(t, err, out)
+	state, err = strategy.LoadSessionState("2026-05-01-running")
+	require.NoError(t, err)
+	assert.Empty(t, state.Tickets)
+
+	out, err = runSessionSetTicketForTest(t, "not a ticket")
+	require.Error(t, err)
+	assert.Contains(t, out, "invalid ticket ID")
+
+	_, err = runSessionSetTicketForTest(t)
+	require.Error(t, err)
+}
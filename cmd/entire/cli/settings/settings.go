@@ -9,10 +9,20 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/pathmatch"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/pricing"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+	"github.com/entireio/cli/redact"
 )
 
 const (
@@ -29,6 +39,26 @@ type EntireSettings struct {
 	// show a disabled message and hooks exit silently. Defaults to true.
 	Enabled bool `json:"enabled"`
 
+	// EnabledBranches, if set, limits Entire to branches matching one of
+	// these glob patterns (e.g. "feature/*"). Hooks on other branches exit
+	// silently. Detached HEAD is never restricted.
+	EnabledBranches []string `json:"enabled_branches,omitempty"`
+
+	// DisabledBranches makes Entire inert on branches matching one of these
+	// glob patterns (e.g. "main", "release/*"). Takes precedence over
+	// EnabledBranches.
+	DisabledBranches []string `json:"disabled_branches,omitempty"`
+
+	// TrackPaths, if set, limits the files Entire checkpoints and condenses
+	// to those matching one of these glob patterns (e.g. "services/payments"),
+	// for scoping sessions to part of a monorepo. A pattern matches a file or
+	// any directory containing it.
+	TrackPaths []string `json:"track_paths,omitempty"`
+
+	// IgnorePaths excludes files matching one of these glob patterns from
+	// checkpoints and condensation. Takes precedence over TrackPaths.
+	IgnorePaths []string `json:"ignore_paths,omitempty"`
+
 	// LocalDev indicates whether to use "go run" instead of the "entire" binary
 	// This is used for development when the binary is not installed
 	LocalDev bool `json:"local_dev,omitempty"`
@@ -38,6 +68,11 @@ type EntireSettings struct {
 	// Defaults to "info".
 	LogLevel string `json:"log_level,omitempty"`
 
+	// Locale selects the language for user-facing messages (e.g. "es").
+	// Can be overridden by ENTIRE_LOCALE environment variable.
+	// Unsupported locales fall back to English.
+	Locale string `json:"locale,omitempty"`
+
 	// StrategyOptions contains strategy-specific configuration
 	StrategyOptions map[string]any `json:"strategy_options,omitempty"`
 
@@ -45,11 +80,245 @@ type EntireSettings struct {
 	// nil = not asked yet (show prompt), true = opted in, false = opted out
 	Telemetry *bool `json:"telemetry,omitempty"`
 
-	// Deprecated: no longer used. Exists to tolerate old settings files
-	// that still contain "strategy": "auto-commit" or similar.
+	// Debug contains troubleshooting options. These are intended for
+	// settings.local.json and should not be committed.
+	Debug *DebugSettings `json:"debug,omitempty"`
+
+	// Pricing overrides the bundled model prices used for cost estimates,
+	// keyed by model name (e.g. "claude-sonnet-4"). Prices are USD per million tokens.
+	Pricing map[string]pricing.ModelPrice `json:"pricing,omitempty"`
+
+	// Currency displays cost estimates in another currency using a fixed
+	// exchange rate. nil = USD.
+	Currency *pricing.Currency `json:"currency,omitempty"`
+
+	// Review configures an optional command that reviews each condensed
+	// checkpoint's diff. nil = no review.
+	Review *ReviewSettings `json:"review,omitempty"`
+
+	// State configures where local session state is kept. nil = shared.
+	State *StateSettings `json:"state,omitempty"`
+
+	// Check configures the provenance rules `entire check` enforces on a
+	// commit range. nil = only built-in checks.
+	Check *CheckSettings `json:"check,omitempty"`
+
+	// QualityGate configures an optional test command run against the
+	// files an agent changed at the end of each turn. nil = no gate.
+	QualityGate *QualityGateSettings `json:"quality_gate,omitempty"`
+
+	// ComplianceScan configures an optional license scanner run against the
+	// files an agent changed at the end of each turn. nil = no scan.
+	ComplianceScan *ComplianceScanSettings `json:"compliance_scan,omitempty"`
+
+	// Transcript configures filtering applied to transcripts before they
+	// are stored in a checkpoint. nil = store transcripts as recorded.
+	Transcript *TranscriptSettings `json:"transcript,omitempty"`
+
+	// Snapshot configures which files shadow checkpoints store the content
+	// of. nil = every file the agent changed is stored.
+	Snapshot *SnapshotSettings `json:"snapshot,omitempty"`
+
+	// Tickets configures how ticket references are found for sessions.
+	// nil = only tickets set with `entire session set-ticket`.
+	Tickets *TicketSettings `json:"tickets,omitempty"`
+
+	// Output configures what hooks print to the terminal.
+	// nil = defaults (a short receipt after each condensation).
+	Output *OutputSettings `json:"output,omitempty"`
+
+	// Namespace renames the checkpoint trailer and Entire's branches, for
+	// repositories where the defaults collide with other tooling.
+	// nil = Entire-Checkpoint, entire/checkpoints/v1, and entire/.
+	Namespace *NamespaceSettings `json:"namespace,omitempty"`
+
+	// Redaction adds secret patterns to the built-in redaction applied to
+	// checkpoint content. nil = built-in detection only.
+	Redaction *RedactionSettings `json:"redaction,omitempty"`
+
+	// Features toggles feature flags by name (see the features package).
+	// Flags not listed use their default.
+	Features map[string]bool `json:"features,omitempty"`
+
+	// Strategy selects the patch-file strategy when set to PatchFileStrategy.
+	// Any other value is deprecated and ignored; it exists to tolerate old
+	// settings files that still contain "strategy": "auto-commit" or similar.
 	Strategy string `json:"strategy,omitempty"`
 }
 
+// DebugSettings contains troubleshooting options.
+type DebugSettings struct {
+	// CaptureHookPayloads stores the raw stdin payload of every agent hook
+	// under .entire/debug/ so it can be re-run with `entire hooks replay`.
+	CaptureHookPayloads bool `json:"capture_hook_payloads,omitempty"`
+}
+
+// ReviewSettings configures machine review of condensed checkpoints.
+type ReviewSettings struct {
+	// Command is run with `sh -c` in the repository root. It receives the
+	// checkpoint's diff and prompts as JSON on stdin, and its stdout is stored
+	// as the checkpoint's machine review.
+	CommandSettings
+}
+
+// CommandSettings configures a user-supplied command Entire runs.
+type CommandSettings struct {
+	// Command is run with `sh -c` in the repository root.
+	Command string `json:"command,omitempty"`
+
+	// TimeoutSeconds bounds how long the command may run. 0 = default.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Timeout returns TimeoutSeconds as a duration. 0 means the caller's default.
+func (c CommandSettings) Timeout() time.Duration {
+	return time.Duration(max(c.TimeoutSeconds, 0)) * time.Second
+}
+
+// StateSettings configures local session state.
+type StateSettings struct {
+	// PerUser keeps session state, pre-prompt state and logs in a
+	// subdirectory per OS user, for clones shared by several users.
+	PerUser bool `json:"per_user,omitempty"`
+
+	// SessionTimeoutMinutes ends ACTIVE sessions that have seen no
+	// interaction for this long, e.g. after the agent was killed without
+	// firing its stop hooks. 0 = sessions never time out.
+	SessionTimeoutMinutes int `json:"session_timeout_minutes,omitempty"`
+}
+
+// CheckSettings configures `entire check`.
+type CheckSettings struct {
+	// RequireCheckpoint fails non-merge commits without an Entire-Checkpoint trailer.
+	RequireCheckpoint bool `json:"require_checkpoint,omitempty"`
+
+	// RequiredTrailers lists trailer keys (e.g. "Signed-off-by") every
+	// non-merge commit must carry.
+	RequiredTrailers []string `json:"required_trailers,omitempty"`
+
+	// MaxMetadataBytes is the largest total size allowed for a referenced
+	// checkpoint's metadata. 0 = default.
+	MaxMetadataBytes int64 `json:"max_metadata_bytes,omitempty"`
+}
+
+// QualityGateSettings configures the turn-end quality gate.
+type QualityGateSettings struct {
+	// Command is run with the session's touched files as arguments. Exit
+	// status 0 means the gate passed.
+	CommandSettings
+
+	// WarnOnCommit makes the prepare-commit-msg hook warn when a commit
+	// includes files from a session whose last gate failed.
+	WarnOnCommit bool `json:"warn_on_commit,omitempty"`
+}
+
+// ComplianceScanSettings configures the turn-end license scan.
+type ComplianceScanSettings struct {
+	// Command is run with the session's touched files as arguments. It
+	// prints its findings as JSON.
+	CommandSettings
+}
+
+// SnapshotSettings limits the files whose content is stored in shadow
+// checkpoints. Files over the limits are recorded by path, blob hash, and
+// size only, so large generated files (screenshots, model weights) do not
+// bloat the shadow branches.
+type SnapshotSettings struct {
+	// MaxFileBytes is the largest file stored. 0 = no limit.
+	MaxFileBytes int64 `json:"max_file_bytes,omitempty"`
+
+	// SkipBinary leaves out files that look binary (contain a NUL byte
+	// near the start, as git detects them).
+	SkipBinary bool `json:"skip_binary,omitempty"`
+}
+
+// TranscriptSettings configures transcript filtering at condensation.
+// Filtering only applies to JSONL transcripts (Claude Code and agents that
+// use its format).
+type TranscriptSettings struct {
+	// KeepTypes, if set, keeps only transcript lines of these types
+	// (e.g. "user", "assistant"), dropping system and progress noise.
+	KeepTypes []string `json:"keep_types,omitempty"`
+
+	// DropToolOutputOverBytes, if positive, truncates tool outputs larger
+	// than this many bytes, recording their original size.
+	DropToolOutputOverBytes int `json:"drop_tool_output_over_bytes,omitempty"`
+
+	// MaxBytes, if positive, is the size above which a stored transcript is
+	// handled as set by Oversize.
+	MaxBytes int `json:"max_bytes,omitempty"`
+
+	// Oversize is what happens to transcripts larger than MaxBytes:
+	// "truncate" (default) keeps the head and tail, "gzip" stores the whole
+	// transcript compressed.
+	Oversize string `json:"oversize,omitempty"`
+}
+
+// Modes for transcript.oversize.
+const (
+	TranscriptOversizeTruncate = "truncate"
+	TranscriptOversizeGzip     = "gzip"
+)
+
+// TicketSettings configures automatic ticket references.
+type TicketSettings struct {
+	// BranchPatterns are regular expressions matched against the current
+	// branch name. Each match (or its first capture group) is a ticket ID,
+	// e.g. "[A-Z][A-Z0-9]+-[0-9]+" finds ABC-123 in "feature/ABC-123-login".
+	BranchPatterns []string `json:"branch_patterns,omitempty"`
+}
+
+// Receipt modes for output.receipt.
+const (
+	ReceiptFull  = "full"
+	ReceiptShort = "short"
+	ReceiptOff   = "off"
+)
+
+// OutputSettings configures hook output.
+type OutputSettings struct {
+	// Receipt controls the receipt printed after post-commit condensation:
+	// "full", "short" (default), or "off".
+	Receipt string `json:"receipt,omitempty"`
+}
+
+// NamespaceSettings configures the names Entire uses in commit messages and
+// refs. Empty fields use the defaults. Run `entire namespace migrate` after
+// changing the branch names so existing checkpoints move with them.
+type NamespaceSettings struct {
+	// TrailerKey replaces the Entire-Checkpoint trailer key. Commits made with
+	// the default key are still recognized.
+	TrailerKey string `json:"trailer_key,omitempty"`
+
+	// MetadataBranch replaces the entire/checkpoints/v1 branch.
+	MetadataBranch string `json:"metadata_branch,omitempty"`
+
+	// ShadowBranchPrefix replaces the entire/ prefix of shadow branches.
+	// Must end with '/'.
+	ShadowBranchPrefix string `json:"shadow_branch_prefix,omitempty"`
+}
+
+// RedactionSettings configures the secret detection applied to transcripts,
+// prompts, and other content before it is stored in a checkpoint.
+type RedactionSettings struct {
+	// EntropyThreshold replaces the Shannon entropy above which a token is
+	// treated as a secret. 0 = default (4.5).
+	EntropyThreshold float64 `json:"entropy_threshold,omitempty"`
+
+	// Rules are additional secret patterns.
+	Rules []RedactionRule `json:"rules,omitempty"`
+
+	// Allow lists regular expressions for values that are never redacted.
+	Allow []string `json:"allow,omitempty"`
+}
+
+// RedactionRule is a named regular expression for a secret. When the
+// pattern has a capture group, only the group is redacted.
+type RedactionRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
 // Load loads the Entire settings from .entire/settings.json,
 // then applies any overrides from .entire/settings.local.json if it exists.
 // Returns default settings if neither file exists.
@@ -144,6 +413,38 @@ func mergeJSON(settings *EntireSettings, data []byte) error {
 		settings.Enabled = e
 	}
 
+	// Override branch patterns if present
+	if branchesRaw, ok := raw["enabled_branches"]; ok {
+		var b []string
+		if err := json.Unmarshal(branchesRaw, &b); err != nil {
+			return fmt.Errorf("parsing enabled_branches field: %w", err)
+		}
+		settings.EnabledBranches = b
+	}
+	if branchesRaw, ok := raw["disabled_branches"]; ok {
+		var b []string
+		if err := json.Unmarshal(branchesRaw, &b); err != nil {
+			return fmt.Errorf("parsing disabled_branches field: %w", err)
+		}
+		settings.DisabledBranches = b
+	}
+
+	// Override path patterns if present
+	if pathsRaw, ok := raw["track_paths"]; ok {
+		var p []string
+		if err := json.Unmarshal(pathsRaw, &p); err != nil {
+			return fmt.Errorf("parsing track_paths field: %w", err)
+		}
+		settings.TrackPaths = p
+	}
+	if pathsRaw, ok := raw["ignore_paths"]; ok {
+		var p []string
+		if err := json.Unmarshal(pathsRaw, &p); err != nil {
+			return fmt.Errorf("parsing ignore_paths field: %w", err)
+		}
+		settings.IgnorePaths = p
+	}
+
 	// Override local_dev if present
 	if localDevRaw, ok := raw["local_dev"]; ok {
 		var ld bool
@@ -164,6 +465,17 @@ func mergeJSON(settings *EntireSettings, data []byte) error {
 		}
 	}
 
+	// Override locale if present and non-empty
+	if localeRaw, ok := raw["locale"]; ok {
+		var locale string
+		if err := json.Unmarshal(localeRaw, &locale); err != nil {
+			return fmt.Errorf("parsing locale field: %w", err)
+		}
+		if locale != "" {
+			settings.Locale = locale
+		}
+	}
+
 	// Merge strategy_options if present
 	if optionsRaw, ok := raw["strategy_options"]; ok {
 		var opts map[string]any
@@ -188,6 +500,153 @@ func mergeJSON(settings *EntireSettings, data []byte) error {
 		settings.Telemetry = &t
 	}
 
+	// Override debug if present
+	if debugRaw, ok := raw["debug"]; ok {
+		var d DebugSettings
+		if err := json.Unmarshal(debugRaw, &d); err != nil {
+			return fmt.Errorf("parsing debug field: %w", err)
+		}
+		settings.Debug = &d
+	}
+
+	// Merge pricing if present (per model, local entries win)
+	if pricingRaw, ok := raw["pricing"]; ok {
+		var p map[string]pricing.ModelPrice
+		if err := json.Unmarshal(pricingRaw, &p); err != nil {
+			return fmt.Errorf("parsing pricing field: %w", err)
+		}
+		if settings.Pricing == nil {
+			settings.Pricing = p
+		} else {
+			for model, price := range p {
+				settings.Pricing[model] = price
+			}
+		}
+	}
+
+	// Override currency if present
+	if currencyRaw, ok := raw["currency"]; ok {
+		var c pricing.Currency
+		if err := json.Unmarshal(currencyRaw, &c); err != nil {
+			return fmt.Errorf("parsing currency field: %w", err)
+		}
+		settings.Currency = &c
+	}
+
+	// Override review if present
+	if reviewRaw, ok := raw["review"]; ok {
+		var r ReviewSettings
+		if err := json.Unmarshal(reviewRaw, &r); err != nil {
+			return fmt.Errorf("parsing review field: %w", err)
+		}
+		settings.Review = &r
+	}
+
+	// Override state if present
+	if stateRaw, ok := raw["state"]; ok {
+		var st StateSettings
+		if err := json.Unmarshal(stateRaw, &st); err != nil {
+			return fmt.Errorf("parsing state field: %w", err)
+		}
+		settings.State = &st
+	}
+
+	// Override check if present
+	if checkRaw, ok := raw["check"]; ok {
+		var c CheckSettings
+		if err := json.Unmarshal(checkRaw, &c); err != nil {
+			return fmt.Errorf("parsing check field: %w", err)
+		}
+		settings.Check = &c
+	}
+
+	// Override quality gate if present
+	if gateRaw, ok := raw["quality_gate"]; ok {
+		var g QualityGateSettings
+		if err := json.Unmarshal(gateRaw, &g); err != nil {
+			return fmt.Errorf("parsing quality_gate field: %w", err)
+		}
+		settings.QualityGate = &g
+	}
+
+	// Override compliance scan if present
+	if scanRaw, ok := raw["compliance_scan"]; ok {
+		var c ComplianceScanSettings
+		if err := json.Unmarshal(scanRaw, &c); err != nil {
+			return fmt.Errorf("parsing compliance_scan field: %w", err)
+		}
+		settings.ComplianceScan = &c
+	}
+
+	// Override transcript filtering if present
+	if transcriptRaw, ok := raw["transcript"]; ok {
+		var tr TranscriptSettings
+		if err := json.Unmarshal(transcriptRaw, &tr); err != nil {
+			return fmt.Errorf("parsing transcript field: %w", err)
+		}
+		settings.Transcript = &tr
+	}
+
+	// Override snapshot if present
+	if snapshotRaw, ok := raw["snapshot"]; ok {
+		var sn SnapshotSettings
+		if err := json.Unmarshal(snapshotRaw, &sn); err != nil {
+			return fmt.Errorf("parsing snapshot field: %w", err)
+		}
+		settings.Snapshot = &sn
+	}
+
+	// Override tickets if present
+	if ticketsRaw, ok := raw["tickets"]; ok {
+		var tk TicketSettings
+		if err := json.Unmarshal(ticketsRaw, &tk); err != nil {
+			return fmt.Errorf("parsing tickets field: %w", err)
+		}
+		settings.Tickets = &tk
+	}
+
+	// Override output if present
+	if outputRaw, ok := raw["output"]; ok {
+		var o OutputSettings
+		if err := json.Unmarshal(outputRaw, &o); err != nil {
+			return fmt.Errorf("parsing output field: %w", err)
+		}
+		settings.Output = &o
+	}
+
+	// Override namespace if present
+	if namespaceRaw, ok := raw["namespace"]; ok {
+		var n NamespaceSettings
+		if err := json.Unmarshal(namespaceRaw, &n); err != nil {
+			return fmt.Errorf("parsing namespace field: %w", err)
+		}
+		settings.Namespace = &n
+	}
+
+	// Override redaction if present
+	if redactionRaw, ok := raw["redaction"]; ok {
+		var r RedactionSettings
+		if err := json.Unmarshal(redactionRaw, &r); err != nil {
+			return fmt.Errorf("parsing redaction field: %w", err)
+		}
+		settings.Redaction = &r
+	}
+
+	// Merge features if present (local overrides individual flags)
+	if featuresRaw, ok := raw["features"]; ok {
+		var f map[string]bool
+		if err := json.Unmarshal(featuresRaw, &f); err != nil {
+			return fmt.Errorf("parsing features field: %w", err)
+		}
+		if settings.Features == nil {
+			settings.Features = f
+		} else {
+			for name, enabled := range f {
+				settings.Features[name] = enabled
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -217,6 +676,69 @@ func IsSetUpAndEnabled() bool {
 	return s.Enabled
 }
 
+// BranchDisabledBy reports whether the enabled_branches and disabled_branches
+// patterns make Entire inert on a branch. pattern is the disabled_branches
+// pattern that matched, or empty when the branch matches no enabled_branches
+// pattern. Patterns use path.Match syntax, so "*" does not match "/"; invalid
+// patterns are skipped. An empty branch (detached HEAD) is never disabled.
+func (s *EntireSettings) BranchDisabledBy(branch string) (pattern string, disabled bool) {
+	if branch == "" {
+		return "", false
+	}
+	for _, p := range s.DisabledBranches {
+		if matched, err := path.Match(p, branch); err == nil && matched {
+			return p, true
+		}
+	}
+	if len(s.EnabledBranches) == 0 {
+		return "", false
+	}
+	for _, p := range s.EnabledBranches {
+		if matched, err := path.Match(p, branch); err == nil && matched {
+			return "", false
+		}
+	}
+	return "", true
+}
+
+// TracksPath reports whether the track_paths and ignore_paths patterns let
+// Entire checkpoint file, a path relative to the repository root. Patterns
+// use path.Match syntax and match the file or any directory containing it, so
+// "services/*" covers every file under services/; patterns without a "/", like
+// "*.lock", match names at any depth (see pathmatch.Match). Every
+// file is tracked when neither setting is set.
+func (s *EntireSettings) TracksPath(file string) bool {
+	for _, p := range s.IgnorePaths {
+		if pathmatch.Match(p, file) {
+			return false
+		}
+	}
+	if len(s.TrackPaths) == 0 {
+		return true
+	}
+	for _, p := range s.TrackPaths {
+		if pathmatch.Match(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPathScope reports whether track_paths or ignore_paths is set.
+func (s *EntireSettings) HasPathScope() bool {
+	return len(s.TrackPaths) > 0 || len(s.IgnorePaths) > 0
+}
+
+// IsEnabledOnBranch returns true if Entire is enabled and not made inert on
+// the branch by the branch patterns.
+func (s *EntireSettings) IsEnabledOnBranch(branch string) bool {
+	if !s.Enabled {
+		return false
+	}
+	_, disabled := s.BranchDisabledBy(branch)
+	return !disabled
+}
+
 // IsSummarizeEnabled checks if auto-summarize is enabled in settings.
 // Returns false by default if settings cannot be loaded or the key is missing.
 func IsSummarizeEnabled() bool {
@@ -243,6 +765,50 @@ func (s *EntireSettings) IsSummarizeEnabled() bool {
 	return enabled
 }
 
+// PromptSummaryOptions returns how prompts are shortened for commit messages,
+// status previews, and checkpoint descriptions, falling back to defaultWidth
+// and first-sentence extraction if settings cannot be loaded.
+func PromptSummaryOptions(defaultWidth int) stringutil.SummaryOptions {
+	settings, err := Load()
+	if err != nil {
+		return stringutil.SummaryOptions{MaxWidth: defaultWidth, FirstSentence: true}
+	}
+	return settings.PromptSummaryOptions(defaultWidth)
+}
+
+// PromptSummaryOptions reads strategy_options.prompt_summary:
+//
+//	"prompt_summary": {"max_length": 50, "style": "truncate"}
+//
+// max_length (display cells) overrides defaultWidth everywhere prompts are
+// shortened. style is "sentence" (default: keep the first sentence, then
+// truncate) or "truncate" (truncate the whole prompt).
+func (s *EntireSettings) PromptSummaryOptions(defaultWidth int) stringutil.SummaryOptions {
+	opts := stringutil.SummaryOptions{MaxWidth: defaultWidth, FirstSentence: true}
+	summaryOpts, ok := s.StrategyOptions["prompt_summary"].(map[string]any)
+	if !ok {
+		return opts
+	}
+	if maxLength, ok := summaryOpts["max_length"].(float64); ok && maxLength >= 10 {
+		opts.MaxWidth = int(maxLength)
+	}
+	if style, ok := summaryOpts["style"].(string); ok && style == "truncate" {
+		opts.FirstSentence = false
+	}
+	return opts
+}
+
+// CommitMessageTemplate returns strategy_options.commit_message_template, a
+// Go text/template for the commit messages generated for checkpoints.
+// Empty means the default message.
+func (s *EntireSettings) CommitMessageTemplate() string {
+	tmpl, ok := s.StrategyOptions["commit_message_template"].(string)
+	if !ok {
+		return ""
+	}
+	return tmpl
+}
+
 // IsPushSessionsDisabled checks if push_sessions is disabled in settings.
 // Returns true if push_sessions is explicitly set to false.
 func (s *EntireSettings) IsPushSessionsDisabled() bool {
@@ -259,8 +825,220 @@ func (s *EntireSettings) IsPushSessionsDisabled() bool {
 	return false
 }
 
+// IsPushOnCondenseEnabled checks if strategy_options.push_on_condense is
+// enabled. When enabled, entire/checkpoints/v1 is pushed to origin right
+// after each condensation instead of waiting for the next git push.
+func (s *EntireSettings) IsPushOnCondenseEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["push_on_condense"].(bool)
+	return ok && enabled
+}
+
+// IsAsyncCondensationEnabled checks if strategy_options.async_condensation is
+// enabled. When enabled, the post-commit hook only queues the commit and a
+// later hook or `entire worker run-once` condenses it.
+func (s *EntireSettings) IsAsyncCondensationEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["async_condensation"].(bool)
+	return ok && enabled
+}
+
+// IsHooksDispatcherEnabled checks if strategy_options.hooks_dispatcher is
+// enabled. When enabled, core.hooksPath points at a directory of links to the
+// entire binary instead of shell scripts being written into the hooks
+// directory; hooks that were there before still run after Entire's.
+func (s *EntireSettings) IsHooksDispatcherEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["hooks_dispatcher"].(bool)
+	return ok && enabled
+}
+
+// IsAppendOnlyCheckpointsEnabled checks if strategy_options.append_only_checkpoints
+// is enabled. When enabled, committed checkpoints are never rewritten; corrections
+// and finalizations are recorded as new revisions.
+func (s *EntireSettings) IsAppendOnlyCheckpointsEnabled() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["append_only_checkpoints"].(bool)
+	return ok && enabled
+}
+
+// IsHookPayloadCaptureEnabled checks if debug.capture_hook_payloads is enabled.
+func (s *EntireSettings) IsHookPayloadCaptureEnabled() bool {
+	return s.Debug != nil && s.Debug.CaptureHookPayloads
+}
+
+// IsPerUserStateEnabled checks if state.per_user is enabled.
+func (s *EntireSettings) IsPerUserStateEnabled() bool {
+	return s.State != nil && s.State.PerUser
+}
+
+// SessionTimeout returns how long an ACTIVE session may go without
+// interaction before it is ended, or 0 if sessions never time out.
+func (s *EntireSettings) SessionTimeout() time.Duration {
+	if s.State == nil || s.State.SessionTimeoutMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(s.State.SessionTimeoutMinutes) * time.Minute
+}
+
+// ReviewCommand returns the configured review command, or "" if machine
+// review is not configured.
+func (s *EntireSettings) ReviewCommand() string {
+	if s.Review == nil {
+		return ""
+	}
+	return strings.TrimSpace(s.Review.Command)
+}
+
+// QualityGateCommand returns the configured quality gate command, or "" if
+// no gate is configured.
+func (s *EntireSettings) QualityGateCommand() string {
+	if s.QualityGate == nil {
+		return ""
+	}
+	return strings.TrimSpace(s.QualityGate.Command)
+}
+
+// ComplianceScanCommand returns the configured compliance scan command, or ""
+// if no scan is configured.
+func (s *EntireSettings) ComplianceScanCommand() string {
+	if s.ComplianceScan == nil {
+		return ""
+	}
+	return strings.TrimSpace(s.ComplianceScan.Command)
+}
+
+// TranscriptFilter returns the configured transcript filter rules, or nil if
+// transcripts are stored unfiltered.
+func (s *EntireSettings) TranscriptFilter() *TranscriptSettings {
+	if s.Transcript == nil || (len(s.Transcript.KeepTypes) == 0 && s.Transcript.DropToolOutputOverBytes <= 0 && s.Transcript.MaxBytes <= 0) {
+		return nil
+	}
+	return s.Transcript
+}
+
+// SnapshotLimits returns the snapshot.max_file_bytes limit (0 = none) and
+// whether snapshot.skip_binary is set.
+func (s *EntireSettings) SnapshotLimits() (maxFileBytes int64, skipBinary bool) {
+	if s.Snapshot == nil {
+		return 0, false
+	}
+	return max(s.Snapshot.MaxFileBytes, 0), s.Snapshot.SkipBinary
+}
+
+// TranscriptOversize returns the transcript.oversize mode, defaulting to
+// TranscriptOversizeTruncate. Unknown values mean the default.
+func (s *EntireSettings) TranscriptOversize() string {
+	if s.Transcript != nil && strings.TrimSpace(s.Transcript.Oversize) == TranscriptOversizeGzip {
+		return TranscriptOversizeGzip
+	}
+	return TranscriptOversizeTruncate
+}
+
+// TicketsFromBranch returns the ticket IDs the configured branch patterns
+// find in a branch name, in order and without duplicates. Invalid patterns
+// and matches that are not valid ticket IDs are skipped.
+func (s *EntireSettings) TicketsFromBranch(branch string) []string {
+	if s.Tickets == nil || branch == "" {
+		return nil
+	}
+	var tickets []string
+	for _, pattern := range s.Tickets.BranchPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		for _, match := range re.FindAllStringSubmatch(branch, -1) {
+			ticket := match[0]
+			if len(match) > 1 && match[1] != "" {
+				ticket = match[1]
+			}
+			if validation.ValidateTicketID(ticket) == nil && !slices.Contains(tickets, ticket) {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets
+}
+
+// ReceiptMode returns the configured output.receipt mode. Unset or
+// unrecognized values fall back to ReceiptShort.
+func (s *EntireSettings) ReceiptMode() string {
+	if s.Output == nil {
+		return ReceiptShort
+	}
+	switch mode := strings.ToLower(strings.TrimSpace(s.Output.Receipt)); mode {
+	case ReceiptFull, ReceiptOff:
+		return mode
+	default:
+		return ReceiptShort
+	}
+}
+
+// RefNamespace returns the configured namespace. Empty fields mean the
+// defaults; see paths.CurrentNamespace.
+func (s *EntireSettings) RefNamespace() paths.Namespace {
+	if s.Namespace == nil {
+		return paths.Namespace{}
+	}
+	return paths.Namespace{
+		TrailerKey:         strings.TrimSpace(s.Namespace.TrailerKey),
+		MetadataBranch:     strings.TrimSpace(s.Namespace.MetadataBranch),
+		ShadowBranchPrefix: strings.TrimSpace(s.Namespace.ShadowBranchPrefix),
+	}
+}
+
+// RedactionRules returns the configured redaction rules. No rules mean the
+// built-in detection only; see redact.SetRulesGetter.
+func (s *EntireSettings) RedactionRules() redact.Rules {
+	if s.Redaction == nil {
+		return redact.Rules{}
+	}
+	rules := redact.Rules{
+		EntropyThreshold: s.Redaction.EntropyThreshold,
+		Allow:            s.Redaction.Allow,
+	}
+	for _, r := range s.Redaction.Rules {
+		rules.Patterns = append(rules.Patterns, redact.Rule{Name: r.Name, Pattern: r.Pattern})
+	}
+	return rules
+}
+
+// CostEstimator returns an estimator using the bundled model prices with the
+// pricing and currency settings applied.
+func (s *EntireSettings) CostEstimator() *pricing.Estimator {
+	currency := pricing.USD
+	if s.Currency != nil {
+		currency = *s.Currency
+	}
+	return pricing.NewEstimator(s.Pricing, currency)
+}
+
+// PatchFileStrategy is the "strategy" value that writes checkpoints as patch
+// files under .entire/patches/ instead of creating refs.
+const PatchFileStrategy = "patch-file"
+
+// UsesPatchFileStrategy reports whether "strategy" selects the patch-file strategy.
+func (s *EntireSettings) UsesPatchFileStrategy() bool {
+	return strings.TrimSpace(s.Strategy) == PatchFileStrategy
+}
+
+// HasDeprecatedStrategy reports whether "strategy" is set to a value other
+// than PatchFileStrategy. Such values are ignored.
+func (s *EntireSettings) HasDeprecatedStrategy() bool {
+	return s.Strategy != "" && !s.UsesPatchFileStrategy()
+}
+
 // FilesWithDeprecatedStrategy returns the relative paths of settings files
-// that still contain the deprecated "strategy" field.
+// that still contain a deprecated "strategy" value.
 func FilesWithDeprecatedStrategy() []string {
 	var files []string
 	for _, rel := range []string{EntireSettingsFile, EntireSettingsLocalFile} {
@@ -269,7 +1047,7 @@ func FilesWithDeprecatedStrategy() []string {
 			abs = rel // Fallback to relative
 		}
 		s, err := LoadFromFile(abs)
-		if err != nil || s.Strategy == "" {
+		if err != nil || !s.HasDeprecatedStrategy() {
 			continue
 		}
 		files = append(files, rel)
@@ -278,12 +1056,12 @@ func FilesWithDeprecatedStrategy() []string {
 }
 
 // WriteDeprecatedStrategyWarnings writes user-friendly deprecation warnings
-// for each settings file that still contains the "strategy" field.
+// for each settings file that still contains a deprecated "strategy" value.
 // Returns true if any warnings were written.
 func WriteDeprecatedStrategyWarnings(w io.Writer) bool {
 	files := FilesWithDeprecatedStrategy()
 	for _, f := range files {
-		fmt.Fprintf(w, "Note: \"%s\" in %s is no longer needed and can be removed. 'manual-commit' is now the only supported strategy.\n", "strategy", f)
+		fmt.Fprintf(w, "Note: \"%s\" in %s is no longer needed and can be removed. 'manual-commit' is the default strategy and 'patch-file' the only alternative.\n", "strategy", f)
 	}
 	return len(files) > 0
 }
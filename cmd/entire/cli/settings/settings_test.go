@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoad_RejectsUnknownKeys(t *testing.T) {
@@ -131,6 +132,41 @@ func TestLoad_LocalSettingsRejectsUnknownKeys(t *testing.T) {
 	}
 }
 
+func TestLoad_MergesLocalPricing(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsContent := `{"enabled": true, "pricing": {"claude-sonnet-4": {"input": 2, "output": 10}, "custom-model": {"input": 1, "output": 1}}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(settingsContent), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	localContent := `{"pricing": {"claude-sonnet-4": {"input": 4, "output": 20}}, "currency": {"code": "EUR", "per_usd": 0.5}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(localContent), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.Pricing["claude-sonnet-4"].Input; got != 4 {
+		t.Errorf("expected local pricing override input 4, got %v", got)
+	}
+	if _, ok := settings.Pricing["custom-model"]; !ok {
+		t.Error("expected project pricing entry to be preserved")
+	}
+	if settings.Currency == nil || settings.Currency.Code != "EUR" || settings.Currency.PerUSD != 0.5 {
+		t.Errorf("expected EUR currency from local settings, got %+v", settings.Currency)
+	}
+}
+
 func TestLoad_AcceptsDeprecatedStrategyField(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -200,8 +236,492 @@ func TestFilesWithDeprecatedStrategy(t *testing.T) {
 	}
 }
 
+func TestPromptSummaryOptions(t *testing.T) {
+	t.Parallel()
+
+	defaults := (&EntireSettings{}).PromptSummaryOptions(72)
+	if defaults.MaxWidth != 72 || !defaults.FirstSentence {
+		t.Errorf("defaults = %+v, want width 72 with first sentence", defaults)
+	}
+
+	s := &EntireSettings{StrategyOptions: map[string]any{
+		"prompt_summary": map[string]any{"max_length": float64(50), "style": "truncate"},
+	}}
+	opts := s.PromptSummaryOptions(72)
+	if opts.MaxWidth != 50 || opts.FirstSentence {
+		t.Errorf("configured = %+v, want width 50 without first sentence", opts)
+	}
+
+	s = &EntireSettings{StrategyOptions: map[string]any{
+		"prompt_summary": map[string]any{"max_length": float64(3)},
+	}}
+	if opts := s.PromptSummaryOptions(72); opts.MaxWidth != 72 {
+		t.Errorf("unreasonably small max_length should be ignored, got %+v", opts)
+	}
+}
+
+func TestLoad_Review(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(`{"enabled": true}`), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	localContent := `{"review": {"command": " ./scripts/review.sh ", "timeout_seconds": 30}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(localContent), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.ReviewCommand(); got != "./scripts/review.sh" {
+		t.Errorf("ReviewCommand() = %q, want %q", got, "./scripts/review.sh")
+	}
+	if settings.Review.TimeoutSeconds != 30 {
+		t.Errorf("TimeoutSeconds = %d, want 30", settings.Review.TimeoutSeconds)
+	}
+	if got := (&EntireSettings{}).ReviewCommand(); got != "" {
+		t.Errorf("ReviewCommand() without review settings = %q, want empty", got)
+	}
+}
+
+func TestLoad_QualityGate(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "quality_gate": {"command": " go test ./... ", "warn_on_commit": true}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	localContent := `{"quality_gate": {"command": "make test", "timeout_seconds": 60}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(localContent), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.QualityGateCommand(); got != "make test" {
+		t.Errorf("QualityGateCommand() = %q, want %q", got, "make test")
+	}
+	if settings.QualityGate.Timeout() != time.Minute {
+		t.Errorf("Timeout() = %v, want 1m", settings.QualityGate.Timeout())
+	}
+	if settings.QualityGate.WarnOnCommit {
+		t.Error("local quality_gate should replace the project one, including warn_on_commit")
+	}
+	if got := (&EntireSettings{}).QualityGateCommand(); got != "" {
+		t.Errorf("QualityGateCommand() without quality_gate settings = %q, want empty", got)
+	}
+}
+
+func TestLoad_ComplianceScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "compliance_scan": {"command": " ./scripts/license-scan.sh ", "timeout_seconds": 30}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.ComplianceScanCommand(); got != "./scripts/license-scan.sh" {
+		t.Errorf("ComplianceScanCommand() = %q, want %q", got, "./scripts/license-scan.sh")
+	}
+	if settings.ComplianceScan.TimeoutSeconds != 30 {
+		t.Errorf("TimeoutSeconds = %d, want 30", settings.ComplianceScan.TimeoutSeconds)
+	}
+	if got := (&EntireSettings{}).ComplianceScanCommand(); got != "" {
+		t.Errorf("ComplianceScanCommand() without compliance_scan settings = %q, want empty", got)
+	}
+}
+
+func TestLoad_Transcript(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "transcript": {"keep_types": ["user", "assistant"], "drop_tool_output_over_bytes": 4096}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter := settings.TranscriptFilter()
+	if filter == nil {
+		t.Fatal("TranscriptFilter() = nil, want filter rules")
+	}
+	if len(filter.KeepTypes) != 2 || filter.KeepTypes[1] != "assistant" {
+		t.Errorf("KeepTypes = %v, want [user assistant]", filter.KeepTypes)
+	}
+	if filter.DropToolOutputOverBytes != 4096 {
+		t.Errorf("DropToolOutputOverBytes = %d, want 4096", filter.DropToolOutputOverBytes)
+	}
+	if got := (&EntireSettings{Transcript: &TranscriptSettings{}}).TranscriptFilter(); got != nil {
+		t.Errorf("TranscriptFilter() with empty transcript settings = %+v, want nil", got)
+	}
+	if got := settings.TranscriptOversize(); got != TranscriptOversizeTruncate {
+		t.Errorf("TranscriptOversize() = %q, want %q", got, TranscriptOversizeTruncate)
+	}
+	gzipped := &EntireSettings{Transcript: &TranscriptSettings{MaxBytes: 1 << 20, Oversize: "gzip"}}
+	if gzipped.TranscriptFilter() == nil {
+		t.Error("TranscriptFilter() with max_bytes = nil, want filter rules")
+	}
+	if got := gzipped.TranscriptOversize(); got != TranscriptOversizeGzip {
+		t.Errorf("TranscriptOversize() = %q, want %q", got, TranscriptOversizeGzip)
+	}
+}
+
+func TestLoad_Tickets(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "tickets": {"branch_patterns": ["[A-Z][A-Z0-9]+-[0-9]+", "issue-([0-9]+)", "("]}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := settings.TicketsFromBranch("feature/ABC-123-issue-42-ABC-123")
+	want := []string{"ABC-123", "42"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("TicketsFromBranch() = %v, want %v", got, want)
+	}
+	if got := settings.TicketsFromBranch("main"); got != nil {
+		t.Errorf("TicketsFromBranch(main) = %v, want nil", got)
+	}
+	if got := (&EntireSettings{}).TicketsFromBranch("feature/ABC-1"); got != nil {
+		t.Errorf("TicketsFromBranch() without settings = %v, want nil", got)
+	}
+}
+
+func TestLoad_Redaction(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "redaction": {"rules": [{"name": "team", "pattern": "team_[a-z]+"}]}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	local := `{"redaction": {"entropy_threshold": 5, "rules": [{"name": "mine", "pattern": "mine_(\\w+)"}], "allow": ["^EXAMPLE"]}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(local), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := settings.RedactionRules()
+	if rules.EntropyThreshold != 5 {
+		t.Errorf("EntropyThreshold = %v, want 5", rules.EntropyThreshold)
+	}
+	if len(rules.Patterns) != 1 || rules.Patterns[0].Name != "mine" || rules.Patterns[0].Pattern != `mine_(\w+)` {
+		t.Errorf("Patterns = %v, want the local rule only", rules.Patterns)
+	}
+	if len(rules.Allow) != 1 || rules.Allow[0] != "^EXAMPLE" {
+		t.Errorf("Allow = %v, want [^EXAMPLE]", rules.Allow)
+	}
+	if err := rules.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	if got := (&EntireSettings{}).RedactionRules(); got.Patterns != nil || got.EntropyThreshold != 0 {
+		t.Errorf("RedactionRules() without settings = %+v, want zero", got)
+	}
+}
+
+func TestLoad_BranchPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "enabled_branches": ["feature/*"], "disabled_branches": ["main"]}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	local := `{"disabled_branches": ["main", "release/*", "feature/wip-*"]}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(local), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		branch      string
+		wantPattern string
+		wantOff     bool
+	}{
+		{branch: "feature/login"},
+		{branch: "feature/wip-login", wantPattern: "feature/wip-*", wantOff: true},
+		{branch: "release/1.2", wantPattern: "release/*", wantOff: true},
+		{branch: "main", wantPattern: "main", wantOff: true},
+		{branch: "fix/typo", wantOff: true},    // matches no enabled_branches pattern
+		{branch: "feature/a/b", wantOff: true}, // "*" does not match "/"
+		{branch: ""},                           // detached HEAD is never restricted
+	}
+	for _, tt := range tests {
+		pattern, off := settings.BranchDisabledBy(tt.branch)
+		if pattern != tt.wantPattern || off != tt.wantOff {
+			t.Errorf("BranchDisabledBy(%q) = %q, %v, want %q, %v", tt.branch, pattern, off, tt.wantPattern, tt.wantOff)
+		}
+		if settings.IsEnabledOnBranch(tt.branch) == tt.wantOff {
+			t.Errorf("IsEnabledOnBranch(%q) = %v, want %v", tt.branch, !tt.wantOff, tt.wantOff)
+		}
+	}
+
+	settings.Enabled = false
+	if settings.IsEnabledOnBranch("feature/login") {
+		t.Error("IsEnabledOnBranch() should be false when Entire is disabled")
+	}
+	if _, off := (&EntireSettings{Enabled: true}).BranchDisabledBy("main"); off {
+		t.Error("BranchDisabledBy() without patterns should not disable any branch")
+	}
+}
+
+func TestLoad_PathPatterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	content := `{"enabled": true, "track_paths": ["services/payments"], "ignore_paths": ["*.lock"]}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	local := `{"track_paths": ["services/payments/", "libs/*"]}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(local), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !settings.HasPathScope() {
+		t.Error("HasPathScope() should be true with track_paths set")
+	}
+
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "services/payments/main.go", want: true},
+		{file: "services/payments/api/handler.go", want: true},
+		{file: "services/payments-old/main.go"},
+		{file: "services/billing/main.go"},
+		{file: "libs/money/round.go", want: true}, // "libs/*" matches the directory
+		{file: "libs/money/go.lock"},              // ignore_paths takes precedence
+		{file: "README.md"},
+	}
+	for _, tt := range tests {
+		if got := settings.TracksPath(tt.file); got != tt.want {
+			t.Errorf("TracksPath(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+
+	unscoped := &EntireSettings{Enabled: true}
+	if !unscoped.TracksPath("anything/at/all.go") || unscoped.HasPathScope() {
+		t.Error("settings without path patterns should track every file")
+	}
+}
+
+func TestLoad_SnapshotLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxBytes, skipBinary := settings.SnapshotLimits(); maxBytes != 0 || skipBinary {
+		t.Errorf("SnapshotLimits() = (%d, %v), want no limits by default", maxBytes, skipBinary)
+	}
+
+	content := `{"enabled": true, "snapshot": {"max_file_bytes": 1048576, "skip_binary": true}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	settings, err = Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if maxBytes, skipBinary := settings.SnapshotLimits(); maxBytes != 1048576 || !skipBinary {
+		t.Errorf("SnapshotLimits() = (%d, %v), want (1048576, true)", maxBytes, skipBinary)
+	}
+}
+
+func TestLoad_OutputReceipt(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(`{"enabled": true, "output": {"receipt": "short"}}`), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(`{"output": {"receipt": "Full"}}`), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := settings.ReceiptMode(); got != ReceiptFull {
+		t.Errorf("ReceiptMode() = %q, want %q", got, ReceiptFull)
+	}
+	if got := (&EntireSettings{}).ReceiptMode(); got != ReceiptShort {
+		t.Errorf("ReceiptMode() without settings = %q, want %q", got, ReceiptShort)
+	}
+	if got := (&EntireSettings{Output: &OutputSettings{Receipt: "loud"}}).ReceiptMode(); got != ReceiptShort {
+		t.Errorf("ReceiptMode() for unknown mode = %q, want %q", got, ReceiptShort)
+	}
+}
+
 // containsUnknownField checks if the error message indicates an unknown field
 func containsUnknownField(msg string) bool {
 	// Go's json package reports unknown fields with this message format
 	return strings.Contains(msg, "unknown field")
 }
+
+func TestLoad_PerUserState(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(`{"enabled": true}`), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	if got := StateUserNamespace(); got != "" {
+		t.Errorf("StateUserNamespace() without state.per_user = %q, want empty", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(`{"state": {"per_user": true}}`), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !settings.IsPerUserStateEnabled() {
+		t.Error("IsPerUserStateEnabled() = false, want true from local settings")
+	}
+	if got, want := StateUserNamespace(), CurrentUserNamespace(); got != want {
+		t.Errorf("StateUserNamespace() = %q, want %q", got, want)
+	}
+}
+
+func TestSessionTimeout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		settings EntireSettings
+		want     time.Duration
+	}{
+		{"not configured", EntireSettings{}, 0},
+		{"per_user only", EntireSettings{State: &StateSettings{PerUser: true}}, 0},
+		{"negative", EntireSettings{State: &StateSettings{SessionTimeoutMinutes: -5}}, 0},
+		{"configured", EntireSettings{State: &StateSettings{SessionTimeoutMinutes: 90}}, 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := tt.settings.SessionTimeout(); got != tt.want {
+			t.Errorf("%s: SessionTimeout() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeUserName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "alice", want: "alice"},
+		{name: "bob.smith-2", want: "bob.smith-2"},
+		{name: `CORP\carol`, want: "carol"},
+		{name: "dan o'neil", want: "dan_o_neil"},
+		{name: "..", want: ""},
+		{name: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := sanitizeUserName(tt.name); got != tt.want {
+			t.Errorf("sanitizeUserName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
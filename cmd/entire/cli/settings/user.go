@@ -0,0 +1,51 @@
+package settings
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// StateUserNamespace returns the directory name that isolates the current
+// user's local state when state.per_user is enabled, or "" when state is
+// shared. Returns "" if settings cannot be loaded.
+func StateUserNamespace() string {
+	s, err := Load()
+	if err != nil || !s.IsPerUserStateEnabled() {
+		return ""
+	}
+	return CurrentUserNamespace()
+}
+
+// CurrentUserNamespace returns a directory name for the current OS user:
+// the username with anything but letters, digits, '.', '_' and '-' replaced,
+// or "uid-<n>" if the username is unavailable.
+func CurrentUserNamespace() string {
+	if u, err := user.Current(); err == nil {
+		if name := sanitizeUserName(u.Username); name != "" {
+			return name
+		}
+	}
+	return "uid-" + strconv.Itoa(os.Getuid())
+}
+
+// sanitizeUserName makes a username safe to use as a single path element.
+// Windows usernames include the domain ("DOMAIN\user"), which is dropped.
+func sanitizeUserName(name string) string {
+	if i := strings.LastIndexAny(name, `\/`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if strings.Trim(name, ".") == "" {
+		return ""
+	}
+	return name
+}
@@ -7,9 +7,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
@@ -52,6 +54,12 @@ modifying your active branch.`,
 				return NewSilentError(errors.New("not a git repository"))
 			}
 
+			if err := checkRepoSupport(); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+				fmt.Fprintln(cmd.ErrOrStderr(), "Entire cannot be enabled in this repository.")
+				return NewSilentError(err)
+			}
+
 			if err := validateSetupFlags(useLocalSettings, useProjectSettings); err != nil {
 				return err
 			}
@@ -63,6 +71,9 @@ modifying your active branch.`,
 				fmt.Fprintln(cmd.OutOrStdout())
 			}
 
+			// Warn about very large repositories before installing hooks
+			checkLargeRepository(cmd.OutOrStdout(), agentName == "" && canPromptInteractively())
+
 			// Non-interactive mode if --agent flag is provided
 			if cmd.Flags().Changed("agent") && agentName == "" {
 				printMissingAgentError(cmd.ErrOrStderr())
@@ -96,7 +107,7 @@ modifying your active branch.`,
 	cmd.Flags().MarkHidden("ignore-untracked") //nolint:errcheck,gosec // flag is defined above
 	cmd.Flags().BoolVar(&useLocalSettings, "local", false, "Write settings to .entire/settings.local.json instead of .entire/settings.json")
 	cmd.Flags().BoolVar(&useProjectSettings, "project", false, "Write settings to .entire/settings.json even if it already exists")
-	cmd.Flags().StringVar(&agentName, "agent", "", "Agent to set up hooks for (e.g., claude-code, gemini, opencode). Enables non-interactive mode.")
+	cmd.Flags().StringVar(&agentName, "agent", "", "Agent to set up hooks for (e.g., aider, claude-code, codex, cursor, gemini, opencode). Enables non-interactive mode.")
 	cmd.Flags().BoolVarP(&forceHooks, "force", "f", false, "Force reinstall hooks (removes existing Entire hooks first)")
 	cmd.Flags().BoolVar(&skipPushSessions, "skip-push-sessions", false, "Disable automatic pushing of session logs on git push")
 	cmd.Flags().BoolVar(&telemetry, "telemetry", true, "Enable anonymous usage analytics")
@@ -376,8 +387,8 @@ func detectOrSelectAgent(w io.Writer, selectFn func(available []string) ([]strin
 	installedAgentNames := GetAgentsWithHooksInstalled()
 	hasInstalledHooks := len(installedAgentNames) > 0
 
-	// Try auto-detection
-	detected := agent.DetectAll()
+	// Try auto-detection (cached per repository)
+	detected := detectAgents(false).Agents
 
 	// First run: use existing auto-detect shortcuts
 	if !hasInstalledHooks {
@@ -422,7 +433,7 @@ func detectOrSelectAgent(w io.Writer, selectFn func(available []string) ([]strin
 	}
 
 	if !hasInstalledHooks && len(detected) == 0 {
-		fmt.Fprintln(w, "No agent configuration detected (e.g., .claude, .gemini, or .opencode directory).")
+		fmt.Fprintln(w, "No agent configuration detected (e.g., .claude, .codex, .cursor, .gemini, or .opencode directory, or .aider.conf.yml).")
 		fmt.Fprintln(w, "This is normal - some agents don't require a config directory.")
 		fmt.Fprintln(w)
 	}
@@ -449,8 +460,9 @@ func detectOrSelectAgent(w io.Writer, selectFn func(available []string) ([]strin
 		if err != nil {
 			continue
 		}
-		// Only show agents that support hooks
-		if _, ok := ag.(agent.HookSupport); !ok {
+		// Only show agents that support hooks. The generic agent has no hooks to
+		// install; tools call `entire hooks generic` themselves.
+		if _, ok := ag.(agent.HookSupport); !ok || name == agent.AgentNameGeneric {
 			continue
 		}
 		opt := huh.NewOption(string(ag.Type()), string(name))
@@ -484,8 +496,8 @@ func detectOrSelectAgent(w io.Writer, selectFn func(available []string) ([]strin
 		form := NewAccessibleForm(
 			huh.NewGroup(
 				huh.NewMultiSelect[string]().
-					Title("Which agents are you using?").
-					Description("Use space to select, enter to confirm.").
+					Title(i18n.T("Which agents are you using?")).
+					Description(i18n.T("Use space to select, enter to confirm.")).
 					Options(options...).
 					Validate(func(selected []string) error {
 						if len(selected) == 0 {
@@ -892,10 +904,10 @@ func promptTelemetryConsent(settings *EntireSettings, telemetryFlag bool) error
 	form := NewAccessibleForm(
 		huh.NewGroup(
 			huh.NewConfirm().
-				Title("Help improve Entire CLI?").
-				Description("Share anonymous usage data. No code or personal info collected.").
-				Affirmative("Yes").
-				Negative("No").
+				Title(i18n.T("Help improve Entire CLI?")).
+				Description(i18n.T("Share anonymous usage data. No code or personal info collected.")).
+				Affirmative(i18n.T("Yes")).
+				Negative(i18n.T("No")).
 				Value(&consent),
 		),
 	)
@@ -960,9 +972,9 @@ func runUninstall(w, errW io.Writer, force bool) error {
 		form := NewAccessibleForm(
 			huh.NewGroup(
 				huh.NewConfirm().
-					Title("Are you sure you want to uninstall Entire?").
-					Affirmative("Yes, uninstall").
-					Negative("Cancel").
+					Title(i18n.T("Are you sure you want to uninstall Entire?")).
+					Affirmative(i18n.T("Yes, uninstall")).
+					Negative(i18n.T("Cancel")).
 					Value(&confirmed),
 			),
 		)
@@ -1019,17 +1031,22 @@ func runUninstall(w, errW io.Writer, force bool) error {
 	return nil
 }
 
-// countSessionStates returns the number of active session state files.
+// countSessionStates returns the number of active session state files,
+// including every user's when state.per_user is enabled.
 func countSessionStates() int {
-	store, err := session.NewStateStore()
+	stores, err := session.NewAllUsersStateStores()
 	if err != nil {
 		return 0
 	}
-	states, err := store.List(context.Background())
-	if err != nil {
-		return 0
+	count := 0
+	for _, store := range stores {
+		states, err := store.List(context.Background())
+		if err != nil {
+			continue
+		}
+		count += len(states)
 	}
-	return len(states)
+	return count
 }
 
 // countShadowBranches returns the number of shadow branches.
@@ -1075,21 +1092,26 @@ func removeAgentHooks(w io.Writer) error {
 
 // removeAllSessionStates removes all session state files and the directory.
 func removeAllSessionStates() (int, error) {
-	store, err := session.NewStateStore()
+	stores, err := session.NewAllUsersStateStores()
 	if err != nil {
 		return 0, fmt.Errorf("failed to create state store: %w", err)
 	}
 
 	// Count states before removing
-	states, err := store.List(context.Background())
-	if err != nil {
-		return 0, fmt.Errorf("failed to list session states: %w", err)
+	count := 0
+	for _, store := range stores {
+		states, err := store.List(context.Background())
+		if err != nil {
+			return 0, fmt.Errorf("failed to list session states: %w", err)
+		}
+		count += len(states)
 	}
-	count := len(states)
 
-	// Remove the entire directory
-	if err := store.RemoveAll(); err != nil {
-		return 0, fmt.Errorf("failed to remove session states: %w", err)
+	// Remove each user's directory, then the shared directory that holds them
+	for _, store := range slices.Backward(stores) {
+		if err := store.RemoveAll(); err != nil {
+			return 0, fmt.Errorf("failed to remove session states: %w", err)
+		}
 	}
 
 	return count, nil
@@ -0,0 +1,109 @@
+// Package shellcmd runs the user-configured commands Entire runs over the
+// files an agent changed, such as the quality gate, the compliance scan, and
+// the review command.
+package shellcmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Runner runs a command with `sh -c`. The files are passed as positional
+// parameters ("$@") and, newline-separated, in ENTIRE_FILES_TOUCHED.
+//
+// GIT_* variables are removed from the command's environment. Commands run
+// from git hooks, where variables such as GIT_INDEX_FILE point at git's
+// temporary state; git commands in the user's command must operate on the
+// repository normally instead.
+type Runner struct {
+	// Command is the shell command to run.
+	Command string
+	// Name is the command's $0, e.g. "entire-quality-gate".
+	Name string
+	// Dir is the working directory, normally the repository root.
+	Dir string
+	// Timeout bounds the command. Required.
+	Timeout time.Duration
+	// CombinedOutput sends stderr to Result.Stdout as well.
+	CombinedOutput bool
+	// Env holds extra environment variables, as "KEY=value".
+	Env []string
+	// Stdin is the command's standard input. nil means no input.
+	Stdin io.Reader
+}
+
+// Result is the outcome of a command that ran.
+type Result struct {
+	// Stdout is the command's standard output.
+	Stdout []byte
+	// Stderr is the command's standard error, unless CombinedOutput is set.
+	Stderr []byte
+	// ExitCode is the command's exit status, or -1 if it timed out.
+	ExitCode int
+	// TimedOut is set when the command was killed after the timeout.
+	TimedOut bool
+}
+
+// Run executes the command against files. A command that exits non-zero or
+// times out gives a Result; an error means the command could not be run at
+// all.
+func (r *Runner) Run(ctx context.Context, files []string) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	args := append([]string{"-c", r.Command, r.Name}, files...)
+	cmd := exec.CommandContext(ctx, "sh", args...) //nolint:gosec // command comes from the user's own settings
+	cmd.Dir = r.Dir
+	cmd.Env = append(stripGitEnv(cmd.Environ()), "ENTIRE_FILES_TOUCHED="+strings.Join(files, "\n"))
+	cmd.Env = append(cmd.Env, r.Env...)
+	cmd.Stdin = r.Stdin
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if r.CombinedOutput {
+		cmd.Stderr = &stdout
+	}
+
+	result := &Result{}
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			result.TimedOut = true
+			result.ExitCode = -1
+		case errors.As(err, &exitErr):
+			result.ExitCode = exitErr.ExitCode()
+		default:
+			return nil, fmt.Errorf("failed to run %s: %w", r.Name, err)
+		}
+	}
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+	return result, nil
+}
+
+// stripGitEnv returns env without its GIT_* variables.
+func stripGitEnv(env []string) []string {
+	filtered := make([]string, 0, len(env))
+	for _, e := range env {
+		if !strings.HasPrefix(e, "GIT_") {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// Tail returns the last maxLen bytes of s, marking the cut.
+func Tail(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return "[output truncated]\n" + s[len(s)-maxLen:]
+}
@@ -0,0 +1,81 @@
+package shellcmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunner_Run(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{
+		Command: `test "$0" = entire-test && test "$#" -eq 2 && test "$ENTIRE_FILES_TOUCHED" = "$(printf 'a.go\nb.go')" && echo out && echo err >&2`,
+		Name:    "entire-test",
+		Dir:     t.TempDir(),
+		Timeout: time.Minute,
+	}
+	result, err := r.Run(context.Background(), []string{"a.go", "b.go"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 0 || string(result.Stdout) != "out\n" || string(result.Stderr) != "err\n" {
+		t.Errorf("Run() = %+v, want exit 0 with separate stdout and stderr", result)
+	}
+
+	r.Command = "echo out; echo err >&2; exit 3"
+	r.CombinedOutput = true
+	result, err = r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 3 || string(result.Stdout) != "out\nerr\n" {
+		t.Errorf("Run() = %+v, want exit 3 with combined output", result)
+	}
+}
+
+func TestRunner_RunStripsGitEnv(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Setenv
+	t.Setenv("GIT_INDEX_FILE", "/tmp/hook-index")
+
+	r := &Runner{
+		Command: `test -z "$GIT_INDEX_FILE" && test "$EXTRA" = set && cat`,
+		Name:    "entire-test",
+		Dir:     t.TempDir(),
+		Timeout: time.Minute,
+		Env:     []string{"EXTRA=set"},
+		Stdin:   strings.NewReader("input"),
+	}
+	result, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.ExitCode != 0 || string(result.Stdout) != "input" {
+		t.Errorf("Run() = %+v, want exit 0 echoing stdin without GIT_INDEX_FILE", result)
+	}
+}
+
+func TestRunner_RunTimesOut(t *testing.T) {
+	t.Parallel()
+
+	r := &Runner{Command: "sleep 5", Name: "entire-test", Dir: t.TempDir(), Timeout: 50 * time.Millisecond}
+	result, err := r.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !result.TimedOut || result.ExitCode != -1 {
+		t.Errorf("Run() = %+v, want timed out", result)
+	}
+}
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+
+	if got := Tail("short", 10); got != "short" {
+		t.Errorf("Tail() = %q, want unchanged", got)
+	}
+	if got := Tail(strings.Repeat("a", 5)+"tail", 4); got != "[output truncated]\ntail" {
+		t.Errorf("Tail() = %q", got)
+	}
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newStageCmd() *cobra.Command {
+	var sessionFlag string
+	var patchFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "stage --session <id>",
+		Short: "Stage the changes a session made, leaving other edits unstaged",
+		Long: `Stage the files a session has touched so you can commit exactly what the
+agent changed. Files the session did not touch are left alone.
+
+By default whole files are staged, including any edits you made to them
+yourself. With --patch, only the session's own hunks are staged: the diff
+between the session's base commit and its latest checkpoint is applied to
+the index, so edits made after that checkpoint stay unstaged.
+
+The session ID may be abbreviated to any unique prefix.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if sessionFlag == "" {
+				return errors.New("--session is required")
+			}
+			return runStage(cmd, sessionFlag, patchFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&sessionFlag, "session", "", "Session whose changes to stage (ID or unique prefix)")
+	cmd.Flags().BoolVarP(&patchFlag, "patch", "p", false, "Stage only the session's hunks, using its latest checkpoint as the reference")
+
+	return cmd
+}
+
+func runStage(cmd *cobra.Command, sessionPrefix string, patch bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+
+	state, err := findSessionState(ctx, sessionPrefix)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	files := sessionStageFiles(state)
+	if len(files) == 0 {
+		fmt.Fprintf(w, "Session %s has not changed any files.\n", state.SessionID)
+		return nil
+	}
+
+	if patch {
+		shadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+		verify := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "refs/heads/"+shadowBranch) //nolint:gosec // branch name is derived from session state
+		verify.Dir = repoRoot
+		if err := verify.Run(); err != nil {
+			fmt.Fprintf(w, "Session %s has no uncommitted checkpoints since %s.\n",
+				state.SessionID, strategy.TruncateHash(state.BaseCommit))
+			return nil
+		}
+		err = stageSessionHunks(ctx, repoRoot, state.BaseCommit, shadowBranch, files)
+	} else {
+		err = stageSessionFiles(ctx, repoRoot, files)
+	}
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	staged, err := gitOutput(ctx, repoRoot, nil, append([]string{"diff", "--cached", "--name-only", "-z", "--"}, files...)...)
+	if err != nil {
+		return err
+	}
+	stagedFiles := splitNUL(staged)
+	if len(stagedFiles) == 0 {
+		fmt.Fprintf(w, "No changes from session %s to stage.\n", state.SessionID)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Staged %d file(s) from session %s:\n", len(stagedFiles), state.SessionID)
+	for _, f := range stagedFiles {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+	fmt.Fprintln(w, "\nReview with: git diff --cached")
+	return nil
+}
+
+// sessionStageFiles returns the session's touched files, excluding Entire's
+// own metadata under .entire/.
+func sessionStageFiles(state *session.State) []string {
+	return withoutEntireMetadata(state.FilesTouched)
+}
+
+// withoutEntireMetadata returns files with anything under .entire/ removed.
+func withoutEntireMetadata(filePaths []string) []string {
+	files := make([]string, 0, len(filePaths))
+	for _, f := range filePaths {
+		if f == paths.EntireDir || strings.HasPrefix(f, paths.EntireDir+"/") {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// stageSessionFiles stages the current worktree content of files, including
+// deletions. Files that neither exist nor are tracked (created and then
+// removed by the agent) are skipped, since git add rejects them.
+func stageSessionFiles(ctx context.Context, repoRoot string, files []string) error {
+	tracked, err := gitOutput(ctx, repoRoot, nil, append([]string{"ls-files", "-z", "--"}, files...)...)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]struct{})
+	for _, f := range splitNUL(tracked) {
+		known[f] = struct{}{}
+	}
+
+	var stageable []string
+	for _, f := range files {
+		if _, ok := known[f]; ok || fileExists(filepath.Join(repoRoot, f)) {
+			stageable = append(stageable, f)
+		}
+	}
+	if len(stageable) == 0 {
+		return nil
+	}
+
+	_, err = gitOutput(ctx, repoRoot, nil, append([]string{"add", "-A", "--"}, stageable...)...)
+	return err
+}
+
+// stageSessionHunks applies the diff between the session's base commit and
+// its shadow branch tip to the index. The shadow branch holds the worktree as
+// of the session's latest checkpoint, so anything changed after that point is
+// not part of the patch and stays unstaged.
+func stageSessionHunks(ctx context.Context, repoRoot, baseCommit, shadowBranch string, files []string) error {
+	args := append([]string{"diff", "--binary", "--no-color", "--no-ext-diff", baseCommit, "refs/heads/" + shadowBranch, "--"}, files...)
+	patch, err := gitOutput(ctx, repoRoot, nil, args...)
+	if err != nil {
+		return err
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	if _, err := gitOutput(ctx, repoRoot, patch, "apply", "--cached", "--whitespace=nowarn"); err != nil {
+		return fmt.Errorf("%w (unstage these files or run without --patch)", err)
+	}
+	return nil
+}
+
+// splitNUL splits NUL-terminated git output into its non-empty fields.
+func splitNUL(out []byte) []string {
+	var fields []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// gitOutput runs a git command in dir, optionally feeding stdin, and returns
+// its stdout. Errors include git's stderr.
+func gitOutput(ctx context.Context, dir string, stdin []byte, args ...string) ([]byte, error) {
+	gitCmd := exec.CommandContext(ctx, "git", args...)
+	gitCmd.Dir = dir
+	if stdin != nil {
+		gitCmd.Stdin = bytes.NewReader(stdin)
+	}
+	out, err := gitCmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupStageRepo extends the session diff fixture with a worktree that holds
+// the session's latest checkpoint plus edits the user made afterwards.
+func setupStageRepo(t *testing.T) string {
+	t.Helper()
+	setupSessionDiffRepo(t)
+	dir, err := exec.CommandContext(context.Background(), "git", "rev-parse", "--show-toplevel").Output()
+	require.NoError(t, err)
+	repoRoot := strings.TrimSpace(string(dir))
+
+	testutil.WriteFile(t, repoRoot, "main.go", "package main\n\nfunc main() {}\n\n// human edit\n")
+	testutil.WriteFile(t, repoRoot, "util.go", "package main\n\nfunc helper() {}\n")
+	testutil.WriteFile(t, repoRoot, "other.go", "package other\n\n// human only\n")
+	return repoRoot
+}
+
+func runStageForTest(t *testing.T, args ...string) string {
+	t.Helper()
+	cmd := newStageCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.Execute(), out.String())
+	return out.String()
+}
+
+func indexContent(t *testing.T, repoRoot, file string) string {
+	t.Helper()
+	show := exec.CommandContext(context.Background(), "git", "show", ":"+file)
+	show.Dir = repoRoot
+	out, err := show.Output()
+	require.NoError(t, err)
+	return string(out)
+}
+
+func stagedNames(t *testing.T, repoRoot string) string {
+	t.Helper()
+	diff := exec.CommandContext(context.Background(), "git", "diff", "--cached", "--name-only")
+	diff.Dir = repoRoot
+	out, err := diff.Output()
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestStage_WholeFiles(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot := setupStageRepo(t)
+
+	output := runStageForTest(t, "--session", "2026-02-01-diff")
+
+	assert.Contains(t, output, "Staged 2 file(s) from session 2026-02-01-diff-session")
+	assert.Equal(t, "main.go\nutil.go\n", stagedNames(t, repoRoot), "files the session did not touch stay unstaged")
+	assert.Contains(t, indexContent(t, repoRoot, "main.go"), "// human edit")
+}
+
+func TestStage_Patch(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoRoot := setupStageRepo(t)
+
+	output := runStageForTest(t, "--session", "2026-02-01-diff-session", "--patch")
+
+	assert.Contains(t, output, "Staged 2 file(s)")
+	assert.Equal(t, "main.go\nutil.go\n", stagedNames(t, repoRoot))
+	assert.Equal(t, "package main\n\nfunc main() {}\n", indexContent(t, repoRoot, "main.go"),
+		"edits made after the latest checkpoint should stay unstaged")
+	assert.Equal(t, "package main\n\nfunc helper() {}\n", indexContent(t, repoRoot, "util.go"))
+}
+
+func TestStage_RequiresSession(t *testing.T) {
+	t.Parallel()
+
+	cmd := newStageCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--session is required")
+}
@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/spf13/cobra"
+)
+
+// reconcileSourceLimit caps how many checkpointed commits on local branches
+// are considered when matching by patch-id.
+const reconcileSourceLimit = 1000
+
+// stampIndex maps commit hashes to the checkpoints stamped onto them.
+type stampIndex map[string][]id.CheckpointID
+
+// newStampIndex builds the stamp index from committed checkpoints.
+func newStampIndex(committed []checkpoint.CommittedInfo) stampIndex {
+	idx := make(stampIndex)
+	for _, info := range committed {
+		for _, commit := range info.StampedCommits {
+			idx[commit] = append(idx[commit], info.CheckpointID)
+		}
+	}
+	return idx
+}
+
+// checkpointsFor returns the checkpoints in the commit's trailers followed by
+// those stamped onto it, without duplicates.
+func (idx stampIndex) checkpointsFor(commit *object.Commit) []id.CheckpointID {
+	cpIDs := trailers.ParseAllCheckpoints(commit.Message)
+	for _, cpID := range idx[commit.Hash.String()] {
+		if !slices.Contains(cpIDs, cpID) {
+			cpIDs = append(cpIDs, cpID)
+		}
+	}
+	return cpIDs
+}
+
+// stampMatch is a commit without a trailer and the checkpointed commit with
+// the same patch-id.
+type stampMatch struct {
+	Commit       string
+	Source       string
+	CheckpointID id.CheckpointID
+}
+
+func newStampCmd() *cobra.Command {
+	var commitFlag string
+	var checkpointFlag string
+	var reconcileFlag bool
+	var rangeFlag string
+	var dryRunFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "stamp",
+		Short: "Link commits created without hooks to their checkpoints",
+		Long: `Link a commit to a checkpoint without rewriting it. Use this for commits
+created where Entire's hooks never run, such as merge queues and bots, which
+end up without an Entire-Checkpoint trailer. The link is recorded on
+` + paths.MetadataBranchName() + ` and honored by 'entire resolve' and 'entire check'.
+
+Stamp a single commit:
+
+  entire stamp --commit <sha> --checkpoint <id>
+
+Or match every unlinked commit in a range back to a checkpointed commit on
+a local branch with the same patch-id (the same diff), and stamp it with
+that commit's checkpoints:
+
+  entire stamp --reconcile --range origin/main~20..origin/main
+
+Squashes of several commits have a different patch-id from each of them
+and are not matched. Push ` + paths.MetadataBranchName() + ` afterwards to share the links.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if reconcileFlag {
+				return runStampReconcile(cmd, rangeFlag, dryRunFlag)
+			}
+			if commitFlag == "" || checkpointFlag == "" {
+				return errors.New("pass --commit and --checkpoint, or --reconcile")
+			}
+			return runStamp(cmd, commitFlag, checkpointFlag, dryRunFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&commitFlag, "commit", "", "Commit to stamp")
+	cmd.Flags().StringVar(&checkpointFlag, "checkpoint", "", "Checkpoint ID to link the commit to")
+	cmd.Flags().BoolVar(&reconcileFlag, "reconcile", false, "Match unlinked commits to checkpointed commits by patch-id")
+	cmd.Flags().StringVar(&rangeFlag, "range", "", "Commit range to reconcile (default: <default branch>..HEAD)")
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Show what would be stamped without writing anything")
+	cmd.MarkFlagsMutuallyExclusive("commit", "reconcile")
+	cmd.MarkFlagsMutuallyExclusive("checkpoint", "reconcile")
+
+	return cmd
+}
+
+func runStamp(cmd *cobra.Command, commitArg, checkpointArg string, dryRun bool) error {
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	cpID, err := id.NewCheckpointID(checkpointArg)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	hash, err := repo.ResolveRevision(plumbing.Revision(commitArg))
+	if err != nil {
+		cmd.SilenceUsage = true
+		err = fmt.Errorf("commit not found: %s", commitArg)
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return fmt.Errorf("failed to get commit: %w", err)
+	}
+	if slices.Contains(trailers.ParseAllCheckpoints(commit.Message), cpID) {
+		fmt.Fprintf(w, "Commit %s already has an %s trailer for %s.\n", strategy.TruncateHash(hash.String()), trailers.CheckpointTrailerKey(), cpID)
+		return nil
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	if dryRun {
+		_, found, sizeErr := store.CommittedSize(context.Background(), cpID)
+		if sizeErr != nil {
+			return fmt.Errorf("failed to read checkpoint: %w", sizeErr)
+		}
+		if !found {
+			return stampNotFound(cmd, cpID)
+		}
+		fmt.Fprintf(w, "Would stamp %s with checkpoint %s.\n", strategy.TruncateHash(hash.String()), cpID)
+		return nil
+	}
+
+	stamped, err := store.StampCommit(context.Background(), cpID, *hash)
+	if errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+		return stampNotFound(cmd, cpID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stamp commit: %w", err)
+	}
+	if !stamped {
+		fmt.Fprintf(w, "Commit %s is already stamped with checkpoint %s.\n", strategy.TruncateHash(hash.String()), cpID)
+		return nil
+	}
+	fmt.Fprintf(w, "Stamped %s with checkpoint %s.\n", strategy.TruncateHash(hash.String()), cpID)
+	return nil
+}
+
+func stampNotFound(cmd *cobra.Command, cpID id.CheckpointID) error {
+	cmd.SilenceUsage = true
+	err := fmt.Errorf("checkpoint %s not found on %s", cpID, paths.MetadataBranchName())
+	fmt.Fprintln(cmd.ErrOrStderr(), err)
+	return NewSilentError(err)
+}
+
+func runStampReconcile(cmd *cobra.Command, commitRange string, dryRun bool) error {
+	ctx := context.Background()
+	w := cmd.OutOrStdout()
+	errW := cmd.ErrOrStderr()
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, "Not a git repository.")
+		return NewSilentError(errors.New("not a git repository"))
+	}
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	if commitRange == "" {
+		if commitRange, err = defaultCommitRange(repo); err != nil {
+			cmd.SilenceUsage = true
+			fmt.Fprintln(errW, err)
+			return NewSilentError(err)
+		}
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	stamps := newStampIndex(committed)
+	known := make(map[id.CheckpointID]struct{}, len(committed))
+	for _, info := range committed {
+		known[info.CheckpointID] = struct{}{}
+	}
+
+	// Targets: commits in the range with no checkpoint at all.
+	rangeCommits, err := revList(ctx, repoRoot, "--no-merges", commitRange)
+	if err != nil {
+		cmd.SilenceUsage = true
+		fmt.Fprintln(errW, err)
+		return NewSilentError(err)
+	}
+	var targets []string
+	for _, hash := range rangeCommits {
+		commit, commitErr := repo.CommitObject(plumbing.NewHash(hash))
+		if commitErr != nil {
+			return fmt.Errorf("failed to read commit %s: %w", hash, commitErr)
+		}
+		if len(stamps.checkpointsFor(commit)) == 0 {
+			targets = append(targets, hash)
+		}
+	}
+	if len(targets) == 0 {
+		fmt.Fprintf(w, "Every commit in %s is already linked to a checkpoint.\n", commitRange)
+		return nil
+	}
+
+	// Sources: checkpointed commits on local branches other than Entire's own.
+	sourceCommits, err := revList(ctx, repoRoot, "--no-merges",
+		"--max-count="+strconv.Itoa(reconcileSourceLimit),
+		"--grep=^"+trailers.CheckpointTrailerKey()+":", "--grep=^"+paths.DefaultCheckpointTrailerKey+":",
+		"--exclude="+checkpoint.ShadowBranchPrefix()+"*", "--exclude="+paths.MetadataBranchName(), "--branches")
+	if err != nil {
+		return err
+	}
+	sources := make(map[string][]id.CheckpointID)
+	for _, hash := range sourceCommits {
+		commit, commitErr := repo.CommitObject(plumbing.NewHash(hash))
+		if commitErr != nil {
+			return fmt.Errorf("failed to read commit %s: %w", hash, commitErr)
+		}
+		for _, cpID := range trailers.ParseAllCheckpoints(commit.Message) {
+			if _, ok := known[cpID]; ok {
+				sources[hash] = append(sources[hash], cpID)
+			}
+		}
+	}
+
+	matches, err := matchByPatchID(ctx, repoRoot, targets, sources)
+	if err != nil {
+		return err
+	}
+
+	stamped := make(map[string]struct{})
+	for _, m := range matches {
+		if !dryRun {
+			if _, stampErr := store.StampCommit(ctx, m.CheckpointID, plumbing.NewHash(m.Commit)); stampErr != nil {
+				return fmt.Errorf("failed to stamp commit %s: %w", strategy.TruncateHash(m.Commit), stampErr)
+			}
+		}
+		stamped[m.Commit] = struct{}{}
+	}
+	writeStampMatches(w, matches, len(stamped), len(targets), dryRun)
+	return nil
+}
+
+// revList runs git rev-list with args and returns the listed commits.
+func revList(ctx context.Context, repoRoot string, args ...string) ([]string, error) {
+	cmdArgs := append([]string{"-C", repoRoot, "rev-list"}, args...)
+	out, err := exec.CommandContext(ctx, "git", append(cmdArgs, "--")...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("git rev-list failed: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git rev-list failed: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// matchByPatchID pairs each target commit with the source commits that have
+// the same patch-id, returning one match per (target, checkpoint).
+func matchByPatchID(ctx context.Context, repoRoot string, targets []string, sources map[string][]id.CheckpointID) ([]stampMatch, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+	commits := slices.Clone(targets)
+	for hash := range sources {
+		commits = append(commits, hash)
+	}
+	patchIDs, err := patchIDs(ctx, repoRoot, commits)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesByPatch := make(map[string][]string)
+	for hash := range sources {
+		if pid, ok := patchIDs[hash]; ok {
+			sourcesByPatch[pid] = append(sourcesByPatch[pid], hash)
+		}
+	}
+
+	var matches []stampMatch
+	for _, target := range targets {
+		pid, ok := patchIDs[target]
+		if !ok {
+			continue
+		}
+		candidates := sourcesByPatch[pid]
+		slices.Sort(candidates)
+		var seen []id.CheckpointID
+		for _, source := range candidates {
+			for _, cpID := range sources[source] {
+				if slices.Contains(seen, cpID) {
+					continue
+				}
+				seen = append(seen, cpID)
+				matches = append(matches, stampMatch{Commit: target, Source: source, CheckpointID: cpID})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// patchIDs returns the stable patch-id of each commit. Commits with an empty
+// diff have no patch-id and are left out.
+func patchIDs(ctx context.Context, repoRoot string, commits []string) (map[string]string, error) {
+	diffCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "diff-tree", "--stdin", "-p", "--root")
+	diffCmd.Stdin = strings.NewReader(strings.Join(commits, "\n") + "\n")
+	diff, err := diffCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree failed: %w", err)
+	}
+
+	patchCmd := exec.CommandContext(ctx, "git", "-C", repoRoot, "patch-id", "--stable")
+	patchCmd.Stdin = bytes.NewReader(diff)
+	out, err := patchCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git patch-id failed: %w", err)
+	}
+
+	ids := make(map[string]string, len(commits))
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			ids[fields[1]] = fields[0]
+		}
+	}
+	return ids, nil
+}
+
+func writeStampMatches(w io.Writer, matches []stampMatch, stamped, unlinked int, dryRun bool) {
+	verb := "Stamped"
+	if dryRun {
+		verb = "Would stamp"
+	}
+	for _, m := range matches {
+		fmt.Fprintf(w, "%s %s with checkpoint %s (same patch as %s)\n", verb, strategy.TruncateHash(m.Commit), m.CheckpointID, strategy.TruncateHash(m.Source))
+	}
+	if len(matches) > 0 {
+		fmt.Fprintln(w)
+	}
+	if dryRun {
+		fmt.Fprintf(w, "%d of %d unlinked commit(s) would be stamped.\n", stamped, unlinked)
+		return
+	}
+	fmt.Fprintf(w, "%d of %d unlinked commit(s) stamped.\n", stamped, unlinked)
+}
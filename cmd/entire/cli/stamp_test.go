@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupStampRepo creates a feature branch commit with a checkpoint trailer and
+// a "queue" branch where the same change was recommitted without the trailer,
+// followed by an unrelated commit, as a merge queue would.
+func setupStampRepo(t *testing.T) (base, queued, unrelated string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	testutil.WriteFile(t, tmpDir, "README.md", "# test\n")
+	testutil.GitAdd(t, tmpDir, "README.md")
+	testutil.GitCommit(t, tmpDir, "Initial commit")
+	base = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.GitCheckoutNewBranch(t, tmpDir, "feature")
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+
+	gitCheckout(t, tmpDir, base)
+	testutil.GitCheckoutNewBranch(t, tmpDir, "queue")
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a (#12)")
+	queued = testutil.GetHeadHash(t, tmpDir)
+
+	testutil.WriteFile(t, tmpDir, "b.go", "package b\n")
+	testutil.GitAdd(t, tmpDir, "b.go")
+	testutil.GitCommit(t, tmpDir, "Add b")
+	unrelated = testutil.GetHeadHash(t, tmpDir)
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-04-01-alpha",
+		Strategy:     "manual-commit",
+		FilesTouched: []string{"a.go"},
+	}))
+	return base, queued, unrelated
+}
+
+func runStampForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newStampCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestStamp_Commit(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	_, _, unrelated := setupStampRepo(t)
+
+	output, err := runStampForTest(t, "--commit", "HEAD", "--checkpoint", "a1b2c3d4e5f6", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would stamp "+strategy.TruncateHash(unrelated))
+
+	output, err = runResolveForTest(t, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, output, "has no Entire-Checkpoint trailer", "dry run must not write")
+
+	output, err = runStampForTest(t, "--commit", "HEAD", "--checkpoint", "a1b2c3d4e5f6")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Stamped "+strategy.TruncateHash(unrelated)+" with checkpoint a1b2c3d4e5f6.")
+
+	output, err = runStampForTest(t, "--commit", "HEAD", "--checkpoint", "a1b2c3d4e5f6")
+	require.NoError(t, err)
+	assert.Contains(t, output, "already stamped")
+
+	output, err = runResolveForTest(t, "HEAD")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Checkpoint: a1b2c3d4e5f6")
+	assert.Contains(t, output, "Linked:   by 'entire stamp'")
+
+	output, err = runStampForTest(t, "--commit", "HEAD", "--checkpoint", "0a0b0c0d0e0f")
+	require.Error(t, err)
+	assert.Contains(t, output, "checkpoint 0a0b0c0d0e0f not found")
+
+	_, err = runStampForTest(t, "--commit", "HEAD")
+	require.Error(t, err)
+}
+
+func TestStamp_Reconcile(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	base, queued, unrelated := setupStampRepo(t)
+	commitRange := base + "..queue"
+
+	output, err := runStampForTest(t, "--reconcile", "--range", commitRange, "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, output, "Would stamp "+strategy.TruncateHash(queued)+" with checkpoint a1b2c3d4e5f6")
+	assert.NotContains(t, output, strategy.TruncateHash(unrelated))
+	assert.Contains(t, output, "1 of 2 unlinked commit(s) would be stamped.")
+
+	output, err = runStampForTest(t, "--reconcile", "--range", commitRange)
+	require.NoError(t, err)
+	assert.Contains(t, output, "1 of 2 unlinked commit(s) stamped.")
+
+	// The queued commit is now linked, so only the unrelated one is left.
+	output, err = runStampForTest(t, "--reconcile", "--range", commitRange)
+	require.NoError(t, err)
+	assert.Contains(t, output, "0 of 1 unlinked commit(s) stamped.")
+
+	output, err = runResolveForTest(t, "--reverse", "2026-04-01-alpha")
+	require.NoError(t, err)
+	assert.Contains(t, output, strategy.TruncateHash(queued)+"  a1b2c3d4e5f6  Add a (#12)")
+
+	cmd := newCheckCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--range", base + "..queue~1", "--require-checkpoint"})
+	require.NoError(t, cmd.Execute(), out.String())
+}
@@ -10,11 +10,14 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
-	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/kvstore"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 // PrePromptState stores the state captured before a user prompt
@@ -94,17 +97,6 @@ func CapturePrePromptState(ag agent.Agent, sessionID, sessionRef string) error {
 		sessionID = unknownSessionID
 	}
 
-	// Get absolute path for tmp directory
-	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
-	if err != nil {
-		tmpDirAbs = paths.EntireTmpDir // Fallback to relative
-	}
-
-	// Create tmp directory if it doesn't exist
-	if err := os.MkdirAll(tmpDirAbs, 0o750); err != nil {
-		return fmt.Errorf("failed to create tmp directory: %w", err)
-	}
-
 	// Get list of untracked files (excluding .entire directory itself)
 	untrackedFiles, err := getUntrackedFilesForState()
 	if err != nil {
@@ -122,22 +114,14 @@ func CapturePrePromptState(ag agent.Agent, sessionID, sessionRef string) error {
 		}
 	}
 
-	// Create state file
-	stateFile := prePromptStateFile(sessionID)
 	state := PrePromptState{
 		SessionID:        sessionID,
 		Timestamp:        time.Now().UTC().Format(time.RFC3339),
 		UntrackedFiles:   untrackedFiles,
 		TranscriptOffset: transcriptOffset,
 	}
-
-	data, err := jsonutil.MarshalIndentWithNewline(state, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
-	}
-
-	if err := os.WriteFile(stateFile, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+	if err := prePromptBucket().Put(sessionID, state); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "Captured state before prompt: %d untracked files, transcript offset: %d\n",
@@ -146,31 +130,30 @@ func CapturePrePromptState(ag agent.Agent, sessionID, sessionRef string) error {
 }
 
 // LoadPrePromptState loads previously captured state.
-// Returns nil if no state file exists.
+// Returns nil if no state was captured. State written by older versions to
+// .entire/tmp/ is still read.
 func LoadPrePromptState(sessionID string) (*PrePromptState, error) {
-	stateFile := prePromptStateFile(sessionID)
-
-	if !fileExists(stateFile) {
-		return nil, nil //nolint:nilnil // already present in codebase
-	}
-
-	data, err := os.ReadFile(stateFile) //nolint:gosec // Reading from controlled git metadata path
+	state, err := prePromptBucket().Get(sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
+		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
-
-	var state PrePromptState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	if state == nil {
+		state, err = loadLegacyHookState[PrePromptState](prePromptStateFile(sessionID))
+		if err != nil || state == nil {
+			return nil, err
+		}
 	}
 
 	state.normalizePrePromptState()
 
-	return &state, nil
+	return state, nil
 }
 
-// CleanupPrePromptState removes the state file after use
+// CleanupPrePromptState removes the captured state after use
 func CleanupPrePromptState(sessionID string) error {
+	if err := prePromptBucket().Delete(sessionID); err != nil {
+		return fmt.Errorf("failed to remove state: %w", err)
+	}
 	stateFile := prePromptStateFile(sessionID)
 	if fileExists(stateFile) {
 		return os.Remove(stateFile) //nolint:wrapcheck // already present in codebase
@@ -183,6 +166,7 @@ type FileChanges struct {
 	Modified []string // Modified or staged files
 	New      []string // Untracked files (filtered if previouslyUntracked provided)
 	Deleted  []string // Deleted files (staged or unstaged)
+	Reported []string // The reported files passed to DetectFileChanges, filtered
 }
 
 // DetectFileChanges returns categorized file changes from the current git status.
@@ -193,8 +177,11 @@ type FileChanges struct {
 //
 // Modified includes both worktree and staging modified/added files.
 // Deleted includes both staged and unstaged deletions.
-// All results exclude .entire/ directory.
-func DetectFileChanges(previouslyUntracked []string) (*FileChanges, error) {
+// Reported holds reported, the repository-relative files an agent says it
+// changed (such as those extracted from its transcript), minus files outside
+// the sparse-checkout cone. All results exclude .entire/ directory, untracked
+// files git ignores, and files matching .entireignore, checked in one pass.
+func DetectFileChanges(previouslyUntracked, reported []string) (*FileChanges, error) {
 	repo, err := openRepository()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open repository: %w", err)
@@ -242,9 +229,42 @@ func DetectFileChanges(previouslyUntracked []string) (*FileChanges, error) {
 		}
 	}
 
+	// go-git already skips index entries marked skip-worktree, but new files
+	// created outside a sparse-checkout cone still show up as untracked.
+	// Reported files can lie anywhere, including paths the user has not
+	// checked out.
+	changes.New = strategy.FilterToSparseCone(repo, changes.New)
+	changes.Reported = strategy.FilterToSparseCone(repo, reported)
+
+	// Ignored files never enter FilesTouched or the shadow branch
+	lists := strategy.FilterIgnoredPathLists(repo, changes.Modified, changes.New, changes.Deleted, changes.Reported)
+	changes.Modified, changes.New, changes.Deleted, changes.Reported = lists[0], lists[1], lists[2], lists[3]
+
 	return &changes, nil
 }
 
+// filterIgnoredPaths removes paths git ignores or .entireignore matches.
+// Files agents report from their transcripts are not filtered by git status,
+// so they are checked here. Fails open.
+func filterIgnoredPaths(files []string) []string {
+	repo, err := openRepository()
+	if err != nil {
+		return files
+	}
+	return strategy.FilterIgnoredPaths(repo, files)
+}
+
+// filterToSparseCone removes paths outside the sparse-checkout cone.
+// Agents can report edits to files the user has not checked out; tracking them
+// would record paths that are absent from the worktree. Fails open.
+func filterToSparseCone(files []string) []string {
+	repo, err := openRepository()
+	if err != nil {
+		return files
+	}
+	return strategy.FilterToSparseCone(repo, files)
+}
+
 // filterToUncommittedFiles removes files from the list that are already committed to HEAD
 // with matching content. This prevents re-adding files that an agent committed mid-turn
 // (already condensed by PostCommit) back to FilesTouched via SaveStep. Files not in
@@ -277,8 +297,10 @@ func filterToUncommittedFiles(files []string, repoRoot string) []string {
 
 	var result []string
 	for _, relPath := range files {
-		headFile, err := headTree.File(relPath)
-		if err != nil {
+		// Use the tree entry hash rather than headTree.File so the comparison
+		// does not need the HEAD blob, which may be missing in a partial clone.
+		headEntry, err := headTree.FindEntry(relPath)
+		if err != nil || !headEntry.Mode.IsFile() {
 			// File not in HEAD — it's uncommitted
 			result = append(result, relPath)
 			continue
@@ -293,13 +315,7 @@ func filterToUncommittedFiles(files []string, repoRoot string) []string {
 			continue
 		}
 
-		headContent, err := headFile.Contents()
-		if err != nil {
-			result = append(result, relPath)
-			continue
-		}
-
-		if string(workingContent) != headContent {
+		if plumbing.ComputeHash(plumbing.BlobObject, workingContent) != headEntry.Hash {
 			// Working tree differs from HEAD — uncommitted changes
 			result = append(result, relPath)
 		}
@@ -326,7 +342,88 @@ func FilterAndNormalizePaths(files []string, cwd string) []string {
 	return result
 }
 
-// prePromptStateFile returns the absolute path to the pre-prompt state file for a session.
+// hookStateDir is the directory under the git dir that holds the hook state store.
+const hookStateDir = "entire-state"
+
+// hookStateTTL bounds how long captured pre-prompt and pre-task state is kept.
+// State is normally removed when the prompt or task ends; the TTL cleans up
+// after agents that exit without firing their stop hooks.
+const hookStateTTL = 24 * time.Hour
+
+// hookStateStore opens the store for state captured between hooks.
+// It lives in the git dir so it is never picked up as an untracked file,
+// in a subdirectory per user when state.per_user is enabled.
+func hookStateStore() *kvstore.Store {
+	dir := hookStateRoot()
+	if ns := settings.StateUserNamespace(); ns != "" {
+		dir = filepath.Join(dir, session.UsersDirName, ns)
+	}
+	return kvstore.Open(dir, kvstore.Options{TTL: hookStateTTL})
+}
+
+// allHookStateStores returns the shared hook state store and every user's.
+func allHookStateStores() []*kvstore.Store {
+	root := hookStateRoot()
+	stores := []*kvstore.Store{kvstore.Open(root, kvstore.Options{TTL: hookStateTTL})}
+	entries, err := os.ReadDir(filepath.Join(root, session.UsersDirName))
+	if err != nil {
+		return stores
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dir := filepath.Join(root, session.UsersDirName, entry.Name())
+			stores = append(stores, kvstore.Open(dir, kvstore.Options{TTL: hookStateTTL}))
+		}
+	}
+	return stores
+}
+
+// hookStateRoot returns the hook state directory under the git dir.
+func hookStateRoot() string {
+	gitDir, err := strategy.GetGitDir()
+	if err != nil {
+		gitDir, err = paths.AbsPath(".git")
+		if err != nil {
+			gitDir = ".git" // Fallback to relative
+		}
+	}
+	return filepath.Join(gitDir, hookStateDir)
+}
+
+// Buckets of the hook state store.
+const (
+	prePromptBucketName = "pre-prompt"
+	preTaskBucketName   = "pre-task"
+)
+
+func prePromptBucket() kvstore.Bucket[PrePromptState] {
+	return kvstore.NewBucket[PrePromptState](hookStateStore(), prePromptBucketName)
+}
+
+func preTaskBucket() kvstore.Bucket[PreTaskState] {
+	return kvstore.NewBucket[PreTaskState](hookStateStore(), preTaskBucketName)
+}
+
+// loadLegacyHookState reads state written to .entire/tmp/ by older versions.
+// Returns nil if the file does not exist.
+func loadLegacyHookState[T any](stateFile string) (*T, error) {
+	if !fileExists(stateFile) {
+		return nil, nil //nolint:nilnil // already present in codebase
+	}
+
+	data, err := os.ReadFile(stateFile) //nolint:gosec // Reading from controlled git metadata path
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var state T
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	}
+	return &state, nil
+}
+
+// prePromptStateFile returns the absolute path to the legacy pre-prompt state file for a session.
 // Works correctly from any subdirectory within the repository.
 func prePromptStateFile(sessionID string) string {
 	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
@@ -394,38 +491,19 @@ func CapturePreTaskState(toolUseID string) error {
 		return errors.New("tool_use_id is required")
 	}
 
-	// Get absolute path for tmp directory
-	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
-	if err != nil {
-		tmpDirAbs = paths.EntireTmpDir // Fallback to relative
-	}
-
-	// Create tmp directory if it doesn't exist
-	if err := os.MkdirAll(tmpDirAbs, 0o750); err != nil {
-		return fmt.Errorf("failed to create tmp directory: %w", err)
-	}
-
 	// Get list of untracked files (excluding .entire directory itself)
 	untrackedFiles, err := getUntrackedFilesForState()
 	if err != nil {
 		return fmt.Errorf("failed to get untracked files: %w", err)
 	}
 
-	// Create state file
-	stateFile := preTaskStateFile(toolUseID)
 	state := PreTaskState{
 		ToolUseID:      toolUseID,
 		Timestamp:      time.Now().UTC().Format(time.RFC3339),
 		UntrackedFiles: untrackedFiles,
 	}
-
-	data, err := jsonutil.MarshalIndentWithNewline(state, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal state: %w", err)
-	}
-
-	if err := os.WriteFile(stateFile, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
+	if err := preTaskBucket().Put(toolUseID, state); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "Captured state before task: %d untracked files\n", len(untrackedFiles))
@@ -433,29 +511,24 @@ func CapturePreTaskState(toolUseID string) error {
 }
 
 // LoadPreTaskState loads previously captured task state.
-// Returns nil if no state file exists.
+// Returns nil if no state was captured. State written by older versions to
+// .entire/tmp/ is still read.
 func LoadPreTaskState(toolUseID string) (*PreTaskState, error) {
-	stateFile := preTaskStateFile(toolUseID)
-
-	if !fileExists(stateFile) {
-		return nil, nil //nolint:nilnil // already present in codebase
-	}
-
-	data, err := os.ReadFile(stateFile) //nolint:gosec // Reading from controlled git metadata path
+	state, err := preTaskBucket().Get(toolUseID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read state file: %w", err)
+		return nil, fmt.Errorf("failed to load state: %w", err)
 	}
-
-	var state PreTaskState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal state: %w", err)
+	if state != nil {
+		return state, nil
 	}
-
-	return &state, nil
+	return loadLegacyHookState[PreTaskState](preTaskStateFile(toolUseID))
 }
 
-// CleanupPreTaskState removes the task state file after use
+// CleanupPreTaskState removes the captured task state after use
 func CleanupPreTaskState(toolUseID string) error {
+	if err := preTaskBucket().Delete(toolUseID); err != nil {
+		return fmt.Errorf("failed to remove state: %w", err)
+	}
 	stateFile := preTaskStateFile(toolUseID)
 	if fileExists(stateFile) {
 		return os.Remove(stateFile) //nolint:wrapcheck // already present in codebase
@@ -463,7 +536,7 @@ func CleanupPreTaskState(toolUseID string) error {
 	return nil
 }
 
-// preTaskStateFile returns the absolute path to the pre-task state file for a tool use.
+// preTaskStateFile returns the absolute path to the legacy pre-task state file for a tool use.
 // Works correctly from any subdirectory within the repository.
 func preTaskStateFile(toolUseID string) string {
 	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
@@ -476,12 +549,16 @@ func preTaskStateFile(toolUseID string) string {
 // preTaskFilePrefix is the prefix for pre-task state files
 const preTaskFilePrefix = "pre-task-"
 
-// FindActivePreTaskFile finds an active pre-task file in .entire/tmp/ and returns
-// the parent Task's tool_use_id. Returns ("", false) if no pre-task file exists.
-// When multiple pre-task files exist (nested subagents), returns the most recently
-// modified one.
+// FindActivePreTaskFile finds active pre-task state and returns the parent Task's
+// tool_use_id. Returns ("", false) if no pre-task state exists.
+// When multiple tasks are active (nested subagents), returns the most recently
+// captured one. Legacy pre-task files in .entire/tmp/ are checked as a fallback.
 // Works correctly from any subdirectory within the repository.
 func FindActivePreTaskFile() (taskToolUseID string, found bool) {
+	if entries, err := preTaskBucket().Keys(); err == nil && len(entries) > 0 {
+		return entries[0].Key, true
+	}
+
 	tmpDirAbs, err := paths.AbsPath(paths.EntireTmpDir)
 	if err != nil {
 		tmpDirAbs = paths.EntireTmpDir // Fallback to relative
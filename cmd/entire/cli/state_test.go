@@ -7,6 +7,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -289,6 +290,43 @@ func TestFindActivePreTaskFile(t *testing.T) {
 	}
 }
 
+func TestPreTaskState_StoredInGitDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+
+	for _, id := range []string{"toolu_outer", "toolu_inner"} {
+		if err := CapturePreTaskState(id); err != nil {
+			t.Fatalf("CapturePreTaskState(%s) error = %v", id, err)
+		}
+	}
+
+	stored := filepath.Join(tmpDir, ".git", hookStateDir, "pre-task", "toolu_inner.json")
+	if _, err := os.Stat(stored); err != nil {
+		t.Errorf("expected state in %s: %v", stored, err)
+	}
+	if _, err := os.Stat(paths.EntireTmpDir); !os.IsNotExist(err) {
+		t.Errorf("expected no %s directory in the worktree, stat error = %v", paths.EntireTmpDir, err)
+	}
+
+	state, err := LoadPreTaskState("toolu_outer")
+	if err != nil {
+		t.Fatalf("LoadPreTaskState() error = %v", err)
+	}
+	if state == nil || state.ToolUseID != "toolu_outer" {
+		t.Errorf("LoadPreTaskState() = %+v, want tool_use_id toolu_outer", state)
+	}
+
+	if err := CleanupPreTaskState("toolu_inner"); err != nil {
+		t.Fatalf("CleanupPreTaskState() error = %v", err)
+	}
+	taskID, found := FindActivePreTaskFile()
+	if !found || taskID != "toolu_outer" {
+		t.Errorf("FindActivePreTaskFile() = (%q, %v), want (toolu_outer, true)", taskID, found)
+	}
+}
+
 // setupTestRepoWithTranscript sets up a temporary git repo with a transcript file
 // and returns the transcriptPath. Used by PrePromptState transcript tests.
 func setupTestRepoWithTranscript(t *testing.T, transcriptContent string, transcriptName string) (transcriptPath string) {
@@ -471,7 +509,7 @@ func TestDetectFileChanges_DeletedFilesWithNilPreState(t *testing.T) {
 	}
 
 	// Call DetectFileChanges with nil previouslyUntracked
-	changes, err := DetectFileChanges(nil)
+	changes, err := DetectFileChanges(nil, nil)
 	if err != nil {
 		t.Fatalf("DetectFileChanges(nil) error = %v", err)
 	}
@@ -551,7 +589,7 @@ func TestDetectFileChanges_NewAndDeletedFiles(t *testing.T) {
 	}
 
 	// Call DetectFileChanges with pre-existing untracked files
-	changes, err := DetectFileChanges([]string{"pre-existing-untracked.txt"})
+	changes, err := DetectFileChanges([]string{"pre-existing-untracked.txt"}, nil)
 	if err != nil {
 		t.Fatalf("DetectFileChanges() error = %v", err)
 	}
@@ -571,6 +609,65 @@ func TestDetectFileChanges_NewAndDeletedFiles(t *testing.T) {
 	}
 }
 
+func TestDetectFileChanges_IgnoredFiles(t *testing.T) {
+	// Untracked files git ignores and files matching .entireignore, tracked
+	// or not, are left out of the detected changes.
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	testutil.InitRepo(t, tmpDir)
+
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	write(".gitignore", "coverage/\n")
+	write("generated.pb.go", "package x\n")
+	testutil.GitAdd(t, tmpDir, ".gitignore", "generated.pb.go")
+	testutil.GitCommit(t, tmpDir, "Initial commit")
+
+	write(".entireignore", "# build output\n*.pb.go\ndist\n!dist/keep.txt\n")
+	write("generated.pb.go", "package x // regenerated\n")
+	write("coverage/index.html", "<html></html>")
+	write("dist/bundle.js", "bundle")
+	write("dist/keep.txt", "keep")
+	write("main.go", "package main\n")
+
+	changes, err := DetectFileChanges(nil, []string{"coverage/index.html", "src/app.pb.go", "main.go"})
+	if err != nil {
+		t.Fatalf("DetectFileChanges() error = %v", err)
+	}
+	if len(changes.Reported) != 1 || changes.Reported[0] != "main.go" {
+		t.Errorf("Reported = %v, want [main.go]", changes.Reported)
+	}
+	if len(changes.Modified) != 0 {
+		t.Errorf("Modified = %v, want none (generated.pb.go matches .entireignore)", changes.Modified)
+	}
+	got := make(map[string]bool)
+	for _, f := range changes.New {
+		got[f] = true
+	}
+	for _, f := range []string{".entireignore", "main.go", "dist/keep.txt"} {
+		if !got[f] {
+			t.Errorf("New = %v, want it to include %s", changes.New, f)
+		}
+	}
+	for _, f := range []string{"coverage/index.html", "dist/bundle.js"} {
+		if got[f] {
+			t.Errorf("New = %v, should not include ignored %s", changes.New, f)
+		}
+	}
+
+	if got := filterIgnoredPaths([]string{"coverage/index.html", "src/app.pb.go", "main.go"}); len(got) != 1 || got[0] != "main.go" {
+		t.Errorf("filterIgnoredPaths() = %v, want [main.go]", got)
+	}
+}
+
 func TestDetectFileChanges_NoChanges(t *testing.T) {
 	// This test verifies DetectFileChanges returns empty slices
 	// when there are no new, modified, or deleted files.
@@ -609,7 +706,7 @@ func TestDetectFileChanges_NoChanges(t *testing.T) {
 	}
 
 	// Call DetectFileChanges with empty previouslyUntracked - no changes should be detected
-	changes, err := DetectFileChanges([]string{})
+	changes, err := DetectFileChanges([]string{}, nil)
 	if err != nil {
 		t.Fatalf("DetectFileChanges() error = %v", err)
 	}
@@ -674,7 +771,7 @@ func TestDetectFileChanges_NilPreviouslyUntracked_ReturnsModified(t *testing.T)
 	}
 
 	// Call DetectFileChanges with nil (all untracked files should be returned)
-	changes, err := DetectFileChanges(nil)
+	changes, err := DetectFileChanges(nil, nil)
 	if err != nil {
 		t.Fatalf("DetectFileChanges(nil) error = %v", err)
 	}
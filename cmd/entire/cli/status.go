@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
@@ -24,27 +25,46 @@ import (
 
 func newStatusCmd() *cobra.Command {
 	var detailed bool
+	var allUsers bool
+	var jsonFlag bool
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show Entire status",
-		Long:  "Show whether Entire is currently enabled or disabled",
+		Long: `Show whether Entire is currently enabled or disabled, and the active sessions.
+
+When run in a terminal, status then offers quick actions for the active
+sessions in the current worktree: show diff, rewind the last step, open the
+transcript, condense now, or end the session.
+
+--json prints a single JSON object instead, for CI dashboards and editor
+integrations: effective and per-file settings, hook installation per agent,
+git hooks, active sessions with token usage, and shadow branch counts.`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runStatus(cmd.OutOrStdout(), detailed)
+			if jsonFlag {
+				return runStatusJSON(cmd.OutOrStdout(), allUsers)
+			}
+			return runStatus(cmd.OutOrStdout(), detailed, allUsers)
 		},
 	}
 
 	cmd.Flags().BoolVar(&detailed, "detailed", false, "Show detailed status for each settings file")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output status as a JSON object")
+	cmd.Flags().BoolVar(&allUsers, "all-users", false, "Show every user's sessions when state.per_user is enabled")
 
 	return cmd
 }
 
-func runStatus(w io.Writer, detailed bool) error {
+func runStatus(w io.Writer, detailed, allUsers bool) error {
 	// Check if we're in a git repository
 	if _, repoErr := paths.WorktreeRoot(); repoErr != nil {
-		fmt.Fprintln(w, "✕ not a git repository")
+		fmt.Fprintln(w, "✕ "+i18n.T("not a git repository"))
 		return nil //nolint:nilerr // Not being in a git repo is a valid status, not an error
 	}
+	if err := checkRepoSupport(); err != nil {
+		fmt.Fprintf(w, "✕ %v\n", err)
+		return nil //nolint:nilerr // An unsupported repository is a valid status, not an error
+	}
 
 	// Get absolute paths for settings files
 	settingsPath, err := paths.AbsPath(EntireSettingsFile)
@@ -69,14 +89,14 @@ func runStatus(w io.Writer, detailed bool) error {
 	localExists := localErr == nil
 
 	if !projectExists && !localExists {
-		fmt.Fprintln(w, "○ not set up (run `entire enable` to get started)")
+		fmt.Fprintln(w, "○ "+i18n.T("not set up (run `entire enable` to get started)"))
 		return nil
 	}
 
 	sty := newStatusStyles(w)
 
 	if detailed {
-		return runStatusDetailed(w, sty, settingsPath, localSettingsPath, projectExists, localExists)
+		return runStatusDetailed(w, sty, settingsPath, localSettingsPath, projectExists, localExists, allUsers)
 	}
 
 	// Short output: just show the effective/merged state
@@ -90,14 +110,15 @@ func runStatus(w io.Writer, detailed bool) error {
 	fmt.Fprintln(w)
 	fmt.Fprintln(w, formatSettingsStatusShort(s, sty))
 	if s.Enabled {
-		writeActiveSessions(w, sty)
+		active := writeActiveSessions(w, sty, allUsers)
+		return offerSessionQuickActions(w, active, allUsers)
 	}
 
 	return nil
 }
 
 // runStatusDetailed shows the effective status plus detailed status for each settings file.
-func runStatusDetailed(w io.Writer, sty statusStyles, settingsPath, localSettingsPath string, projectExists, localExists bool) error {
+func runStatusDetailed(w io.Writer, sty statusStyles, settingsPath, localSettingsPath string, projectExists, localExists, allUsers bool) error {
 	// First show the effective/merged status
 	effectiveSettings, err := LoadEntireSettings()
 	if err != nil {
@@ -115,7 +136,7 @@ func runStatusDetailed(w io.Writer, sty statusStyles, settingsPath, localSetting
 		if err != nil {
 			return fmt.Errorf("failed to load project settings: %w", err)
 		}
-		fmt.Fprintln(w, formatSettingsStatus("Project", projectSettings, sty))
+		fmt.Fprintln(w, formatSettingsStatus(i18n.T("Project"), projectSettings, sty))
 	}
 
 	// Show local settings if it exists
@@ -124,42 +145,66 @@ func runStatusDetailed(w io.Writer, sty statusStyles, settingsPath, localSetting
 		if err != nil {
 			return fmt.Errorf("failed to load local settings: %w", err)
 		}
-		fmt.Fprintln(w, formatSettingsStatus("Local", localSettings, sty))
+		fmt.Fprintln(w, formatSettingsStatus(i18n.T("Local"), localSettings, sty))
 	}
 
 	if effectiveSettings.Enabled {
-		writeActiveSessions(w, sty)
+		active := writeActiveSessions(w, sty, allUsers)
+		return offerSessionQuickActions(w, active, allUsers)
 	}
 
 	return nil
 }
 
 // formatSettingsStatusShort formats a short settings status line.
-// Output format: "● Enabled · manual-commit · branch main" or "○ Disabled"
+// Output format: "● Enabled · manual-commit · branch main" or "○ Disabled".
+// When branch patterns make Entire inert on the current branch, the line
+// says so: "○ Enabled · manual-commit · branch main · disabled on this branch by pattern main".
 func formatSettingsStatusShort(s *EntireSettings, sty statusStyles) string {
 	displayName := strategy.StrategyNameManualCommit
 
 	var b strings.Builder
 
-	if s.Enabled {
+	// Resolve branch from repo root
+	var branch string
+	if repoRoot, err := paths.WorktreeRoot(); err == nil {
+		branch = resolveWorktreeBranch(repoRoot)
+	}
+	patternBranch := branch
+	if patternBranch == detachedHEADDisplay {
+		patternBranch = ""
+	}
+	disabledByPattern, branchDisabled := s.BranchDisabledBy(patternBranch)
+
+	switch {
+	case s.Enabled && branchDisabled:
+		b.WriteString(sty.render(sty.gray, "○"))
+		b.WriteString(" ")
+		b.WriteString(sty.render(sty.bold, i18n.T("Enabled")))
+	case s.Enabled:
 		b.WriteString(sty.render(sty.green, "●"))
 		b.WriteString(" ")
-		b.WriteString(sty.render(sty.bold, "Enabled"))
-	} else {
+		b.WriteString(sty.render(sty.bold, i18n.T("Enabled")))
+	default:
 		b.WriteString(sty.render(sty.red, "○"))
 		b.WriteString(" ")
-		b.WriteString(sty.render(sty.bold, "Disabled"))
+		b.WriteString(sty.render(sty.bold, i18n.T("Disabled")))
 	}
 
 	b.WriteString(sty.render(sty.dim, " · "))
 	b.WriteString(displayName)
 
-	// Resolve branch from repo root
-	if repoRoot, err := paths.WorktreeRoot(); err == nil {
-		if branch := resolveWorktreeBranch(repoRoot); branch != "" {
-			b.WriteString(sty.render(sty.dim, " · "))
-			b.WriteString("branch ")
-			b.WriteString(sty.render(sty.cyan, branch))
+	if branch != "" {
+		b.WriteString(sty.render(sty.dim, " · "))
+		b.WriteString(i18n.Tf("branch %s", sty.render(sty.cyan, branch)))
+	}
+
+	if s.Enabled && branchDisabled {
+		b.WriteString(sty.render(sty.dim, " · "))
+		if disabledByPattern != "" {
+			b.WriteString(i18n.Tf("disabled on this branch by pattern %s", disabledByPattern))
+		} else {
+			b.WriteString(i18n.T("disabled on this branch (no enabled_branches pattern matches)"))
 		}
 	}
 
@@ -176,9 +221,9 @@ func formatSettingsStatus(prefix string, s *EntireSettings, sty statusStyles) st
 	b.WriteString(sty.render(sty.dim, " · "))
 
 	if s.Enabled {
-		b.WriteString("enabled")
+		b.WriteString(i18n.T("enabled"))
 	} else {
-		b.WriteString("disabled")
+		b.WriteString(i18n.T("disabled"))
 	}
 
 	b.WriteString(sty.render(sty.dim, " · "))
@@ -192,16 +237,16 @@ func timeAgo(t time.Time) string {
 	d := time.Since(t)
 	switch {
 	case d < time.Minute:
-		return "just now"
+		return i18n.T("just now")
 	case d < time.Hour:
 		m := int(d.Minutes())
-		return fmt.Sprintf("%dm ago", m)
+		return i18n.Tf("%dm ago", m)
 	case d < 24*time.Hour:
 		h := int(d.Hours())
-		return fmt.Sprintf("%dh ago", h)
+		return i18n.Tf("%dh ago", h)
 	default:
 		days := int(d.Hours() / 24)
-		return fmt.Sprintf("%dd ago", days)
+		return i18n.Tf("%dd ago", days)
 	}
 }
 
@@ -215,29 +260,19 @@ type worktreeGroup struct {
 const (
 	unknownPlaceholder  = "(unknown)"
 	detachedHEADDisplay = "HEAD"
-)
-
-// writeActiveSessions writes active session information grouped by worktree.
-func writeActiveSessions(w io.Writer, sty statusStyles) {
-	store, err := session.NewStateStore()
-	if err != nil {
-		return
-	}
 
-	states, err := store.List(context.Background())
-	if err != nil || len(states) == 0 {
-		return
-	}
+	// maxStatusPromptWidth is the default display width of first-prompt previews.
+	maxStatusPromptWidth = 60
+)
 
-	// Filter to active sessions only
-	var active []*session.State
-	for _, s := range states {
-		if s.EndedAt == nil {
-			active = append(active, s)
-		}
-	}
+// writeActiveSessions writes active session information grouped by worktree
+// and returns the sessions in the order they were shown.
+// When state.per_user is enabled, only the current user's sessions are shown
+// unless allUsers is set.
+func writeActiveSessions(w io.Writer, sty statusStyles, allUsers bool) []*session.State {
+	active := loadActiveSessions(allUsers)
 	if len(active) == 0 {
-		return
+		return nil
 	}
 
 	// Group by worktree path
@@ -280,18 +315,23 @@ func writeActiveSessions(w io.Writer, sty statusStyles) {
 
 	// Track aggregate totals
 	var totalSessions int
+	shown := make([]*session.State, 0, len(active))
+	estimator := loadCostEstimator()
+	promptSummary := settings.PromptSummaryOptions(maxStatusPromptWidth)
+	promptSummary.Suffix = "..."
 
 	fmt.Fprintln(w)
 	printedHeader := false
 	for _, g := range sortedGroups {
 		if !printedHeader {
-			fmt.Fprintln(w, sty.sectionRule("Active Sessions", sty.width))
+			fmt.Fprintln(w, sty.sectionRule(i18n.T("Active Sessions"), sty.width))
 			fmt.Fprintln(w)
 			printedHeader = true
 		}
 
 		for _, st := range g.sessions {
 			totalSessions++
+			shown = append(shown, st)
 
 			agentLabel := string(st.AgentType)
 			if agentLabel == "" {
@@ -311,19 +351,30 @@ func writeActiveSessions(w io.Writer, sty statusStyles) {
 
 			// Line 2: > "first prompt" (chevron + quoted, truncated)
 			if st.FirstPrompt != "" {
-				prompt := stringutil.TruncateRunes(st.FirstPrompt, 60, "...")
+				prompt := stringutil.Summarize(st.FirstPrompt, promptSummary)
 				fmt.Fprintf(w, "%s \"%s\"\n", sty.render(sty.dim, ">"), prompt)
 			}
 
 			// Line 3: stats line — started Xd ago · active now · files N · tokens X.Xk
 			var stats []string
-			stats = append(stats, "started "+timeAgo(st.StartedAt))
+			stats = append(stats, i18n.Tf("started %s", timeAgo(st.StartedAt)))
 
 			if st.LastInteractionTime != nil && st.LastInteractionTime.Sub(st.StartedAt) > time.Minute {
 				stats = append(stats, activeTimeDisplay(st.LastInteractionTime))
 			}
 
-			stats = append(stats, "tokens "+formatTokenCount(totalTokens(st.TokenUsage)))
+			if st.ParentSessionID != "" {
+				parentID := st.ParentSessionID
+				if len(parentID) > 7 {
+					parentID = parentID[:7]
+				}
+				stats = append(stats, i18n.Tf("resumed from %s", parentID))
+			}
+
+			stats = append(stats, i18n.Tf("tokens %s", formatTokenCount(totalTokens(st.TokenUsage))))
+			if est, ok := estimator.Estimate(st.AgentType, st.TokenUsage); ok {
+				stats = append(stats, i18n.Tf("cost %s est.", est.String()))
+			}
 
 			statsLine := strings.Join(stats, sty.render(sty.dim, " · "))
 			fmt.Fprintln(w, sty.render(sty.dim, statsLine))
@@ -335,12 +386,46 @@ func writeActiveSessions(w io.Writer, sty statusStyles) {
 	fmt.Fprintln(w, sty.horizontalRule(sty.width))
 	var footer string
 	if totalSessions == 1 {
-		footer = "1 session"
+		footer = i18n.T("1 session")
 	} else {
-		footer = fmt.Sprintf("%d sessions", totalSessions)
+		footer = i18n.Tf("%d sessions", totalSessions)
 	}
 	fmt.Fprintln(w, sty.render(sty.dim, footer))
 	fmt.Fprintln(w)
+	return shown
+}
+
+// loadActiveSessions returns the sessions that haven't ended, or nil if
+// session state cannot be read.
+// When state.per_user is enabled, only the current user's sessions are
+// returned unless allUsers is set.
+func loadActiveSessions(allUsers bool) []*session.State {
+	var stores []*session.StateStore
+	if allUsers {
+		all, err := session.NewAllUsersStateStores()
+		if err != nil {
+			return nil
+		}
+		stores = all
+	} else {
+		store, err := session.NewStateStore()
+		if err != nil {
+			return nil
+		}
+		stores = []*session.StateStore{store}
+	}
+
+	// Sessions recorded under a path the repository has since moved away
+	// from are re-bound to the new location before grouping by worktree.
+	_, _ = strategy.RebindMovedSessions() //nolint:errcheck // best-effort; sessions still display under their old path
+
+	// Only sessions that haven't ended; the filter is applied from the
+	// session index so ended sessions are never loaded.
+	active, err := session.ListAllUsers(context.Background(), stores, session.Filter{ExcludeEnded: true})
+	if err != nil {
+		return nil
+	}
+	return active
 }
 
 // resolveWorktreeBranch resolves the current branch for a worktree path
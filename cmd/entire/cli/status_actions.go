@@ -0,0 +1,288 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+)
+
+// sessionAction is a quick action `entire status` offers for a live session.
+type sessionAction string
+
+const (
+	sessionActionDiff       sessionAction = "diff"
+	sessionActionEnd        sessionAction = "end"
+	sessionActionRewind     sessionAction = "rewind"
+	sessionActionTranscript sessionAction = "transcript"
+	sessionActionCondense   sessionAction = "condense"
+	sessionActionCancel     sessionAction = "cancel"
+)
+
+// statusIsInteractive reports whether `entire status` can offer quick actions:
+// its output goes to a terminal and the user can be prompted.
+func statusIsInteractive(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || f != os.Stdout {
+		return false
+	}
+	return shouldUseColor(w) && canPromptInteractively()
+}
+
+// offerSessionQuickActions offers quick actions for the sessions `entire
+// status` just listed, when it runs interactively.
+func offerSessionQuickActions(w io.Writer, active []*session.State, allUsers bool) error {
+	sessions := quickActionSessions(active, allUsers)
+	if len(sessions) == 0 || !statusIsInteractive(w) {
+		return nil
+	}
+	return runSessionQuickActions(w, sessions)
+}
+
+// quickActionSessions returns the sessions quick actions can be applied to:
+// those of the current user in the current worktree, in display order.
+// Actions act on the current worktree, so sessions elsewhere are left out.
+func quickActionSessions(active []*session.State, allUsers bool) []*session.State {
+	if allUsers {
+		return nil
+	}
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil
+	}
+	var result []*session.State
+	for _, st := range active {
+		if st.WorktreePath != "" && paths.RepoRelative(root, st.WorktreePath) == "." {
+			result = append(result, st)
+		}
+	}
+	return result
+}
+
+// sessionQuickActions returns the actions available for a session. Actions
+// that need recorded steps or a transcript are left out when there are none.
+func sessionQuickActions(st *session.State) []huh.Option[sessionAction] {
+	var options []huh.Option[sessionAction]
+	if st.StepCount > 0 {
+		options = append(options, huh.NewOption(i18n.T("Show diff"), sessionActionDiff))
+	}
+	if st.StepCount > 1 {
+		options = append(options, huh.NewOption(i18n.T("Rewind last step"), sessionActionRewind))
+	}
+	if st.TranscriptPath != "" {
+		options = append(options, huh.NewOption(i18n.T("Open transcript"), sessionActionTranscript))
+	}
+	if st.StepCount > 0 {
+		options = append(options, huh.NewOption(i18n.T("Condense now"), sessionActionCondense))
+	}
+	options = append(options,
+		huh.NewOption(i18n.T("End session"), sessionActionEnd),
+		huh.NewOption(i18n.T("Cancel"), sessionActionCancel),
+	)
+	return options
+}
+
+// runSessionQuickActions lets the user pick one of the listed sessions and
+// run a quick action on it.
+func runSessionQuickActions(w io.Writer, sessions []*session.State) error {
+	st := sessions[0]
+	if len(sessions) > 1 {
+		options := make([]huh.Option[string], 0, len(sessions)+1)
+		for _, s := range sessions {
+			options = append(options, huh.NewOption(sessionOptionLabel(s), s.SessionID))
+		}
+		options = append(options, huh.NewOption(i18n.T("Cancel"), ""))
+
+		var selectedID string
+		form := NewAccessibleForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title(i18n.T("Select a session")).
+					Options(options...).
+					Value(&selectedID),
+			),
+		)
+		if err := form.Run(); err != nil {
+			return nil //nolint:nilerr // Leaving the menu is not an error
+		}
+		if selectedID == "" {
+			return nil
+		}
+		for _, s := range sessions {
+			if s.SessionID == selectedID {
+				st = s
+			}
+		}
+	}
+
+	var action sessionAction
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewSelect[sessionAction]().
+				Title(i18n.Tf("Session %s", sessionOptionLabel(st))).
+				Options(sessionQuickActions(st)...).
+				Value(&action),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return nil //nolint:nilerr // Leaving the menu is not an error
+	}
+
+	return runSessionAction(w, st, action)
+}
+
+// runSessionAction runs a quick action on a session.
+func runSessionAction(w io.Writer, st *session.State, action sessionAction) error {
+	switch action {
+	case sessionActionDiff:
+		return showSessionDiff(w, st)
+	case sessionActionEnd:
+		return endSessionAction(w, st)
+	case sessionActionRewind:
+		return rewindLastStep(w, st)
+	case sessionActionTranscript:
+		return openTranscript(st)
+	case sessionActionCondense:
+		return condenseSessionNow(w, st)
+	case sessionActionCancel:
+	}
+	return nil
+}
+
+// sessionOptionLabel describes a session in a menu: its agent, short ID and
+// first prompt.
+func sessionOptionLabel(st *session.State) string {
+	agentLabel := string(st.AgentType)
+	if agentLabel == "" {
+		agentLabel = unknownPlaceholder
+	}
+	label := agentLabel + " · " + strategy.TruncateHash(st.SessionID)
+	if st.FirstPrompt != "" {
+		promptSummary := settings.PromptSummaryOptions(maxStatusPromptWidth)
+		promptSummary.Suffix = "..."
+		prompt := stringutil.Summarize(st.FirstPrompt, promptSummary)
+		label += fmt.Sprintf(" %q", sanitizeForTerminal(prompt))
+	}
+	return label
+}
+
+// showSessionDiff shows everything the session has changed, like
+// `entire session diff`, through the pager when it is long.
+func showSessionDiff(w io.Writer, st *session.State) error {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := sessionDiff(context.Background(), &buf, repoRoot, st, nil, sessionDiffPatch); err != nil {
+		return err
+	}
+	outputWithPager(w, buf.String())
+	return nil
+}
+
+// endSessionAction marks the session ended after confirmation, as if the
+// agent had exited.
+func endSessionAction(w io.Writer, st *session.State) error {
+	if !confirmSessionAction(i18n.Tf("End session %s?", strategy.TruncateHash(st.SessionID)),
+		i18n.T("Its checkpoints are kept and are still condensed into your next commit.")) {
+		return nil
+	}
+	if err := markSessionEnded(st.SessionID); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, i18n.Tf("Session %s ended.", strategy.TruncateHash(st.SessionID)))
+	return nil
+}
+
+// rewindLastStep undoes the session's most recent step by rewinding to the
+// checkpoint before it.
+func rewindLastStep(w io.Writer, st *session.State) error {
+	start := GetStrategy()
+	points, err := start.GetRewindPoints(20)
+	if err != nil {
+		return fmt.Errorf("failed to find rewind points: %w", err)
+	}
+
+	// Points are sorted newest first; the newest is the state after the
+	// last step, so the one after it is the state before.
+	var sessionPoints []strategy.RewindPoint
+	for _, p := range points {
+		if p.SessionID == st.SessionID && !p.IsLogsOnly && !p.IsTaskCheckpoint {
+			sessionPoints = append(sessionPoints, p)
+		}
+	}
+	if len(sessionPoints) < 2 {
+		fmt.Fprintln(w, i18n.T("No earlier step to rewind to. Use `entire rewind` to choose a checkpoint."))
+		return nil
+	}
+	target := sessionPoints[1]
+
+	if !confirmSessionAction(i18n.T("Rewind the last step?"),
+		i18n.Tf("This will reset to: %s\nChanges after this point may be lost!", sanitizeForTerminal(target.Message))) {
+		return nil
+	}
+	return runRewindToWithOptions(target.ID, false, false)
+}
+
+// openTranscript opens the session's live transcript in the pager.
+func openTranscript(st *session.State) error {
+	if _, err := os.Stat(st.TranscriptPath); err != nil {
+		return fmt.Errorf("transcript not available: %w", err)
+	}
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	cmd := exec.CommandContext(context.Background(), pager, st.TranscriptPath) //nolint:gosec // pager from env is expected
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	return nil
+}
+
+// condenseSessionNow condenses the session's checkpoints into a committed
+// checkpoint without waiting for a commit, as `entire doctor` does for stuck
+// sessions.
+func condenseSessionNow(w io.Writer, st *session.State) error {
+	if err := GetStrategy().CondenseSessionByID(st.SessionID); err != nil {
+		return err //nolint:wrapcheck // error already describes the failed step
+	}
+	updated, err := strategy.LoadSessionState(st.SessionID)
+	if err == nil && updated != nil && !updated.LastCheckpointID.IsEmpty() {
+		fmt.Fprintln(w, i18n.Tf("Condensed session %s into checkpoint %s.", strategy.TruncateHash(st.SessionID), updated.LastCheckpointID))
+		return nil
+	}
+	fmt.Fprintln(w, i18n.Tf("Condensed session %s.", strategy.TruncateHash(st.SessionID)))
+	return nil
+}
+
+// confirmSessionAction asks the user to confirm a quick action.
+func confirmSessionAction(title, description string) bool {
+	var confirm bool
+	form := NewAccessibleForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(title).
+				Description(description).
+				Value(&confirm),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return false
+	}
+	return confirm
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+)
+
+func TestSessionQuickActions(t *testing.T) {
+	t.Parallel()
+
+	actions := func(st *session.State) []sessionAction {
+		var got []sessionAction
+		for _, o := range sessionQuickActions(st) {
+			got = append(got, o.Value)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		state *session.State
+		want  []sessionAction
+	}{
+		{
+			name:  "no steps yet",
+			state: &session.State{},
+			want:  []sessionAction{sessionActionEnd, sessionActionCancel},
+		},
+		{
+			name:  "one step with transcript",
+			state: &session.State{StepCount: 1, TranscriptPath: "/tmp/t.jsonl"},
+			want:  []sessionAction{sessionActionDiff, sessionActionTranscript, sessionActionCondense, sessionActionEnd, sessionActionCancel},
+		},
+		{
+			name:  "several steps",
+			state: &session.State{StepCount: 3},
+			want:  []sessionAction{sessionActionDiff, sessionActionRewind, sessionActionCondense, sessionActionEnd, sessionActionCancel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := actions(tt.state)
+			if len(got) != len(tt.want) {
+				t.Fatalf("sessionQuickActions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sessionQuickActions() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestQuickActionSessions(t *testing.T) {
+	setupTestRepo(t)
+
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		t.Fatalf("WorktreeRoot() error = %v", err)
+	}
+	store, err := session.NewStateStore()
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+
+	now := time.Now()
+	for _, st := range []*session.State{
+		{SessionID: "here-older", WorktreePath: root, StartedAt: now.Add(-time.Hour)},
+		{SessionID: "here-newer", WorktreePath: root, StartedAt: now.Add(-time.Minute)},
+		{SessionID: "elsewhere", WorktreePath: "/Users/test/other", StartedAt: now},
+	} {
+		if err := store.Save(context.Background(), st); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	shown := writeActiveSessions(&buf, newStatusStyles(&buf), false)
+	if len(shown) != 3 {
+		t.Fatalf("writeActiveSessions() returned %d sessions, want 3", len(shown))
+	}
+
+	got := quickActionSessions(shown, false)
+	if len(got) != 2 || got[0].SessionID != "here-newer" || got[1].SessionID != "here-older" {
+		ids := make([]string, 0, len(got))
+		for _, st := range got {
+			ids = append(ids, st.SessionID)
+		}
+		t.Errorf("quickActionSessions() = %v, want [here-newer here-older]", ids)
+	}
+	if got := quickActionSessions(shown, true); got != nil {
+		t.Errorf("quickActionSessions(allUsers) = %d sessions, want none", len(got))
+	}
+
+	// Status output to a buffer never prompts.
+	if err := offerSessionQuickActions(&buf, shown, false); err != nil {
+		t.Errorf("offerSessionQuickActions() error = %v", err)
+	}
+}
+
+func TestRewindLastStep_SingleStep(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	repoDir := t.TempDir()
+	testutil.InitRepo(t, repoDir)
+	testutil.WriteFile(t, repoDir, "main.go", "package main\n")
+	testutil.GitAdd(t, repoDir, "main.go")
+	testutil.GitCommit(t, repoDir, "init")
+	t.Chdir(repoDir)
+	paths.ClearWorktreeRootCache()
+
+	state := &session.State{SessionID: "single-step", StepCount: 1}
+	if err := strategy.SaveSessionState(state); err != nil {
+		t.Fatalf("SaveSessionState() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := rewindLastStep(&buf, state); err != nil {
+		t.Fatalf("rewindLastStep() error = %v", err)
+	}
+	if got := buf.String(); got != "No earlier step to rewind to. Use `entire rewind` to choose a checkpoint.\n" {
+		t.Errorf("rewindLastStep() output = %q", got)
+	}
+}
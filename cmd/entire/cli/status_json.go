@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// statusJSON is the document `entire status --json` prints. Fields beyond
+// repository/set_up are only filled in once Entire has been set up.
+type statusJSON struct {
+	Repository  bool   `json:"repository"`
+	Unsupported string `json:"unsupported,omitempty"`
+	SetUp       bool   `json:"set_up"`
+	Enabled     bool   `json:"enabled"`
+	Strategy    string `json:"strategy,omitempty"`
+	Branch      string `json:"branch,omitempty"`
+	// BranchDisabled is set when enabled_branches/disabled_branches make
+	// Entire inert on the current branch; BranchDisabledBy is the matching
+	// disabled_branches pattern, if any.
+	BranchDisabled    bool                      `json:"branch_disabled,omitempty"`
+	BranchDisabledBy  string                    `json:"branch_disabled_by,omitempty"`
+	Settings          *statusSettingsJSON       `json:"settings,omitempty"`
+	Agents            []statusAgentJSON         `json:"agents,omitempty"`
+	GitHooksInstalled bool                      `json:"git_hooks_installed"`
+	Sessions          []statusSessionJSON       `json:"sessions"`
+	ShadowBranches    *statusShadowBranchesJSON `json:"shadow_branches,omitempty"`
+}
+
+// statusSettingsJSON is where the effective settings come from.
+type statusSettingsJSON struct {
+	Project statusSettingsFileJSON `json:"project"`
+	Local   statusSettingsFileJSON `json:"local"`
+}
+
+type statusSettingsFileJSON struct {
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+type statusAgentJSON struct {
+	Name           agent.AgentName `json:"name"`
+	Type           agent.AgentType `json:"type"`
+	HooksInstalled bool            `json:"hooks_installed"`
+}
+
+type statusSessionJSON struct {
+	SessionID           string            `json:"session_id"`
+	AgentType           agent.AgentType   `json:"agent_type,omitempty"`
+	WorktreePath        string            `json:"worktree_path,omitempty"`
+	Branch              string            `json:"branch,omitempty"`
+	Phase               session.Phase     `json:"phase,omitempty"`
+	FirstPrompt         string            `json:"first_prompt,omitempty"`
+	StartedAt           time.Time         `json:"started_at"`
+	LastInteractionTime *time.Time        `json:"last_interaction_time,omitempty"`
+	ParentSessionID     string            `json:"parent_session_id,omitempty"`
+	StepCount           int               `json:"checkpoint_count"`
+	TokenUsage          *agent.TokenUsage `json:"token_usage,omitempty"`
+	TotalTokens         int               `json:"total_tokens"`
+	EstimatedCost       *statusCostJSON   `json:"estimated_cost,omitempty"`
+}
+
+type statusCostJSON struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	Model    string  `json:"model"`
+}
+
+// statusShadowBranchesJSON counts shadow branches; Active are those the
+// active sessions are writing to.
+type statusShadowBranchesJSON struct {
+	Total  int `json:"total"`
+	Active int `json:"active"`
+}
+
+// runStatusJSON writes the status as a single JSON object. Like runStatus,
+// not being in a (supported) repository or not being set up is reported in
+// the document rather than as an error.
+func runStatusJSON(w io.Writer, allUsers bool) error {
+	status, err := collectStatusJSON(allUsers)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		return fmt.Errorf("failed to encode status: %w", err)
+	}
+	return nil
+}
+
+func collectStatusJSON(allUsers bool) (*statusJSON, error) {
+	status := &statusJSON{Sessions: []statusSessionJSON{}}
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return status, nil //nolint:nilerr // Not being in a git repo is a valid status, not an error
+	}
+	status.Repository = true
+	if err := checkRepoSupport(); err != nil {
+		status.Unsupported = err.Error()
+		return status, nil //nolint:nilerr // An unsupported repository is a valid status, not an error
+	}
+
+	project, err := statusSettingsFile(EntireSettingsFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access project settings file: %w", err)
+	}
+	local, err := statusSettingsFile(EntireSettingsLocalFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access local settings file: %w", err)
+	}
+	if !project.Exists && !local.Exists {
+		return status, nil
+	}
+	status.SetUp = true
+	status.Settings = &statusSettingsJSON{Project: project, Local: local}
+
+	s, err := LoadEntireSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	status.Enabled = s.Enabled
+	status.Strategy = strategy.StrategyNameManualCommit
+	if s.UsesPatchFileStrategy() {
+		status.Strategy = strategy.StrategyNamePatchFile
+	}
+	status.Branch = resolveWorktreeBranch(repoRoot)
+	patternBranch := status.Branch
+	if patternBranch == detachedHEADDisplay {
+		patternBranch = ""
+	}
+	status.BranchDisabledBy, status.BranchDisabled = s.BranchDisabledBy(patternBranch)
+
+	for _, name := range agent.List() {
+		ag, err := agent.Get(name)
+		if err != nil {
+			continue
+		}
+		hs, ok := ag.(agent.HookSupport)
+		if !ok || name == agent.AgentNameGeneric {
+			continue
+		}
+		status.Agents = append(status.Agents, statusAgentJSON{
+			Name:           name,
+			Type:           ag.Type(),
+			HooksInstalled: hs.AreHooksInstalled(),
+		})
+	}
+	status.GitHooksInstalled = strategy.IsGitHookInstalled()
+
+	if !s.Enabled {
+		return status, nil
+	}
+
+	active := loadActiveSessions(allUsers)
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].StartedAt.After(active[j].StartedAt)
+	})
+	estimator := s.CostEstimator()
+	branches := make(map[string]string)
+	activeShadow := make(map[string]bool)
+	for _, st := range active {
+		entry := statusSessionJSON{
+			SessionID:           st.SessionID,
+			AgentType:           st.AgentType,
+			WorktreePath:        st.WorktreePath,
+			Phase:               st.Phase,
+			FirstPrompt:         st.FirstPrompt,
+			StartedAt:           st.StartedAt,
+			LastInteractionTime: st.LastInteractionTime,
+			ParentSessionID:     st.ParentSessionID,
+			StepCount:           st.StepCount,
+			TokenUsage:          st.TokenUsage,
+			TotalTokens:         totalTokens(st.TokenUsage),
+		}
+		if st.WorktreePath != "" {
+			branch, ok := branches[st.WorktreePath]
+			if !ok {
+				branch = resolveWorktreeBranch(st.WorktreePath)
+				branches[st.WorktreePath] = branch
+			}
+			entry.Branch = branch
+		}
+		if est, ok := estimator.Estimate(st.AgentType, st.TokenUsage); ok {
+			entry.EstimatedCost = &statusCostJSON{Amount: est.Amount, Currency: est.Currency.Code, Model: est.Model}
+		}
+		status.Sessions = append(status.Sessions, entry)
+		if st.BaseCommit != "" {
+			activeShadow[checkpoint.ShadowBranchNameForCommit(st.BaseCommit, st.WorktreeID)] = true
+		}
+	}
+
+	shadowBranches, err := strategy.ListShadowBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shadow branches: %w", err)
+	}
+	status.ShadowBranches = &statusShadowBranchesJSON{Total: len(shadowBranches)}
+	for _, branch := range shadowBranches {
+		if activeShadow[branch] {
+			status.ShadowBranches.Active++
+		}
+	}
+
+	return status, nil
+}
+
+// statusSettingsFile reports whether a settings file exists and, if so,
+// whether it enables Entire.
+func statusSettingsFile(relPath string) (statusSettingsFileJSON, error) {
+	file := statusSettingsFileJSON{Path: relPath}
+	absPath, err := paths.AbsPath(relPath)
+	if err != nil {
+		absPath = relPath
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return file, nil
+		}
+		return file, err //nolint:wrapcheck // wrapped by the caller
+	}
+	file.Exists = true
+
+	fileSettings, err := settings.LoadFromFile(absPath)
+	if err != nil {
+		return file, fmt.Errorf("failed to load %s: %w", relPath, err)
+	}
+	file.Enabled = &fileSettings.Enabled
+	return file, nil
+}
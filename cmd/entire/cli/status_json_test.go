@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+)
+
+func runStatusJSONForTest(t *testing.T) statusJSON {
+	t.Helper()
+	var stdout bytes.Buffer
+	if err := runStatusJSON(&stdout, false); err != nil {
+		t.Fatalf("runStatusJSON() error = %v", err)
+	}
+	var status statusJSON
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		t.Fatalf("output is not a JSON object: %v\n%s", err, stdout.String())
+	}
+	return status
+}
+
+func TestRunStatusJSON_NotGitRepository(t *testing.T) {
+	setupTestDir(t)
+
+	status := runStatusJSONForTest(t)
+	if status.Repository || status.SetUp || status.Enabled {
+		t.Errorf("status = %+v, want repository, set_up and enabled false", status)
+	}
+}
+
+func TestRunStatusJSON_NotSetUp(t *testing.T) {
+	setupTestRepo(t)
+
+	status := runStatusJSONForTest(t)
+	if !status.Repository {
+		t.Error("repository = false, want true")
+	}
+	if status.SetUp || status.Settings != nil {
+		t.Errorf("status = %+v, want not set up", status)
+	}
+}
+
+func TestRunStatusJSON_SettingsProvenance(t *testing.T) {
+	setupTestRepo(t)
+	writeSettings(t, `{"enabled": true}`)
+	writeLocalSettings(t, `{"enabled": false}`)
+
+	status := runStatusJSONForTest(t)
+	if !status.SetUp || status.Enabled {
+		t.Errorf("set_up = %v, enabled = %v, want set up and disabled by local settings", status.SetUp, status.Enabled)
+	}
+	if status.Strategy != "manual-commit" {
+		t.Errorf("strategy = %q, want manual-commit", status.Strategy)
+	}
+	project, local := status.Settings.Project, status.Settings.Local
+	if !project.Exists || project.Enabled == nil || !*project.Enabled || project.Path != EntireSettingsFile {
+		t.Errorf("project settings = %+v, want existing and enabled", project)
+	}
+	if !local.Exists || local.Enabled == nil || *local.Enabled {
+		t.Errorf("local settings = %+v, want existing and disabled", local)
+	}
+	if len(status.Sessions) != 0 || status.ShadowBranches != nil {
+		t.Errorf("disabled status should not list sessions or shadow branches, got %+v", status)
+	}
+}
+
+func TestRunStatusJSON_ActiveSessions(t *testing.T) {
+	setupTestRepo(t)
+	writeSettings(t, testSettingsEnabled)
+
+	store, err := session.NewStateStore()
+	if err != nil {
+		t.Fatalf("NewStateStore() error = %v", err)
+	}
+	endedAt := time.Now()
+	states := []*session.State{
+		{
+			SessionID:   "older-session",
+			StartedAt:   time.Now().Add(-2 * time.Hour),
+			AgentType:   agent.AgentTypeClaudeCode,
+			FirstPrompt: "Fix auth bug",
+			StepCount:   3,
+			TokenUsage:  &agent.TokenUsage{InputTokens: 800, OutputTokens: 400},
+		},
+		{
+			SessionID: "newer-session",
+			StartedAt: time.Now().Add(-5 * time.Minute),
+		},
+		{
+			SessionID: "ended-session",
+			StartedAt: time.Now().Add(-3 * time.Hour),
+			EndedAt:   &endedAt,
+		},
+	}
+	for _, s := range states {
+		if err := store.Save(context.Background(), s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	status := runStatusJSONForTest(t)
+	if !status.Enabled {
+		t.Fatal("enabled = false, want true")
+	}
+	if len(status.Sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2 (ended session excluded): %+v", len(status.Sessions), status.Sessions)
+	}
+	if status.Sessions[0].SessionID != "newer-session" {
+		t.Errorf("sessions[0] = %q, want newest session first", status.Sessions[0].SessionID)
+	}
+	older := status.Sessions[1]
+	if older.TotalTokens != 1200 || older.TokenUsage == nil || older.TokenUsage.OutputTokens != 400 {
+		t.Errorf("older session token usage = %+v, total %d", older.TokenUsage, older.TotalTokens)
+	}
+	if older.StepCount != 3 || older.FirstPrompt != "Fix auth bug" {
+		t.Errorf("older session = %+v", older)
+	}
+	if older.EstimatedCost == nil || older.EstimatedCost.Currency != "USD" {
+		t.Errorf("older session estimated_cost = %+v, want a USD estimate", older.EstimatedCost)
+	}
+	if status.ShadowBranches == nil || status.ShadowBranches.Total != 0 {
+		t.Errorf("shadow_branches = %+v, want zero counts", status.ShadowBranches)
+	}
+
+	var names []agent.AgentName
+	for _, a := range status.Agents {
+		names = append(names, a.Name)
+		if a.HooksInstalled {
+			t.Errorf("agent %s reports hooks installed in a fresh repo", a.Name)
+		}
+	}
+	if len(names) == 0 {
+		t.Error("agents is empty, want every agent with hook support")
+	}
+	for _, name := range names {
+		if name == agent.AgentNameGeneric {
+			t.Error("agents should not include the generic adapter")
+		}
+	}
+}
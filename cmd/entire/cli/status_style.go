@@ -10,6 +10,9 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/i18n"
+	"github.com/entireio/cli/cmd/entire/cli/pricing"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 
 	"golang.org/x/term"
 )
@@ -117,6 +120,16 @@ func totalTokens(tu *agent.TokenUsage) int {
 	return total
 }
 
+// loadCostEstimator returns a cost estimator configured from settings,
+// falling back to bundled USD prices if settings cannot be loaded.
+func loadCostEstimator() *pricing.Estimator {
+	s, err := settings.Load()
+	if err != nil {
+		return pricing.NewEstimator(nil, pricing.USD)
+	}
+	return s.CostEstimator()
+}
+
 // horizontalRule renders a dimmed horizontal rule of the given width.
 func (s statusStyles) horizontalRule(width int) string {
 	rule := strings.Repeat("─", width)
@@ -149,7 +162,7 @@ func activeTimeDisplay(lastInteraction *time.Time) string {
 	}
 	d := time.Since(*lastInteraction)
 	if d < time.Minute {
-		return "active now"
+		return i18n.T("active now")
 	}
-	return "active " + timeAgo(*lastInteraction)
+	return i18n.Tf("active %s", timeAgo(*lastInteraction))
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 
 	"github.com/go-git/go-git/v5"
@@ -19,9 +20,7 @@ import (
 
 func TestResolveWorktreeBranch_RegularRepo(t *testing.T) {
 	dir := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
-		dir = resolved
-	}
+	dir = paths.ResolveSymlinks(dir)
 
 	_, err := git.PlainInit(dir, false)
 	if err != nil {
@@ -43,9 +42,7 @@ func TestResolveWorktreeBranch_RegularRepo(t *testing.T) {
 
 func TestResolveWorktreeBranch_DetachedHEAD(t *testing.T) {
 	dir := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
-		dir = resolved
-	}
+	dir = paths.ResolveSymlinks(dir)
 
 	repo, err := git.PlainInit(dir, false)
 	if err != nil {
@@ -90,9 +87,7 @@ func TestResolveWorktreeBranch_DetachedHEAD(t *testing.T) {
 func TestResolveWorktreeBranch_WorktreeGitFile(t *testing.T) {
 	// Simulate a worktree where .git is a file pointing to a gitdir
 	dir := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
-		dir = resolved
-	}
+	dir = paths.ResolveSymlinks(dir)
 
 	// Create a fake gitdir with a HEAD file
 	gitdir := filepath.Join(dir, "fake-gitdir")
@@ -123,9 +118,7 @@ func TestResolveWorktreeBranch_WorktreeGitFile(t *testing.T) {
 func TestResolveWorktreeBranch_WorktreeRelativePath(t *testing.T) {
 	// Simulate a worktree where .git file uses a relative gitdir path
 	dir := t.TempDir()
-	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
-		dir = resolved
-	}
+	dir = paths.ResolveSymlinks(dir)
 
 	// Create the main .git dir structure
 	mainGitDir := filepath.Join(dir, "main-repo", ".git", "worktrees", "wt1")
@@ -197,7 +190,7 @@ func TestRunStatus_Enabled(t *testing.T) {
 	writeSettings(t, testSettingsEnabled)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -211,7 +204,7 @@ func TestRunStatus_Disabled(t *testing.T) {
 	writeSettings(t, testSettingsDisabled)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -224,7 +217,7 @@ func TestRunStatus_NotSetUp(t *testing.T) {
 	setupTestRepo(t)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -241,7 +234,7 @@ func TestRunStatus_NotGitRepository(t *testing.T) {
 	setupTestDir(t) // No git init
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -255,7 +248,7 @@ func TestRunStatus_LocalSettingsOnly(t *testing.T) {
 	writeLocalSettings(t, `{"enabled": true}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, true); err != nil {
+	if err := runStatus(&stdout, true, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -282,7 +275,7 @@ func TestRunStatus_BothProjectAndLocal(t *testing.T) {
 	writeLocalSettings(t, `{"enabled": false}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, true); err != nil {
+	if err := runStatus(&stdout, true, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -309,7 +302,7 @@ func TestRunStatus_BothProjectAndLocal_Short(t *testing.T) {
 	writeLocalSettings(t, `{"enabled": false}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -325,7 +318,7 @@ func TestRunStatus_ShowsManualCommitStrategy(t *testing.T) {
 	writeSettings(t, `{"enabled": false}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, true); err != nil {
+	if err := runStatus(&stdout, true, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -345,7 +338,7 @@ func TestRunStatus_DeprecatedStrategyWarning(t *testing.T) {
 	writeSettings(t, `{"enabled": true, "strategy": "auto-commit"}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -363,7 +356,7 @@ func TestRunStatus_DeprecatedStrategyWarning_Detailed(t *testing.T) {
 	writeSettings(t, `{"enabled": true, "strategy": "auto-commit"}`)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, true); err != nil {
+	if err := runStatus(&stdout, true, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -378,7 +371,7 @@ func TestRunStatus_NoWarningWithoutStrategy(t *testing.T) {
 	writeSettings(t, testSettingsEnabled)
 
 	var stdout bytes.Buffer
-	if err := runStatus(&stdout, false); err != nil {
+	if err := runStatus(&stdout, false, false); err != nil {
 		t.Fatalf("runStatus() error = %v", err)
 	}
 
@@ -454,9 +447,10 @@ func TestWriteActiveSessions(t *testing.T) {
 			},
 		},
 		{
-			SessionID:    "ghi-9012-session",
-			WorktreePath: "/Users/test/repo/.worktrees/3",
-			StartedAt:    now.Add(-5 * time.Minute),
+			SessionID:       "ghi-9012-session",
+			WorktreePath:    "/Users/test/repo/.worktrees/3",
+			StartedAt:       now.Add(-5 * time.Minute),
+			ParentSessionID: "jkl-3456-session",
 		},
 	}
 
@@ -468,7 +462,7 @@ func TestWriteActiveSessions(t *testing.T) {
 
 	var buf bytes.Buffer
 	sty := newStatusStyles(&buf)
-	writeActiveSessions(&buf, sty)
+	writeActiveSessions(&buf, sty, false)
 
 	output := buf.String()
 
@@ -523,6 +517,11 @@ func TestWriteActiveSessions(t *testing.T) {
 		}
 	}
 
+	// A resumed session names the session it continues
+	if !strings.Contains(output, "resumed from jkl-345") {
+		t.Errorf("Expected 'resumed from jkl-345' for resumed session, got: %s", output)
+	}
+
 	// Should contain per-session token counts
 	if !strings.Contains(output, "tokens 1.2k") {
 		t.Errorf("Expected per-session 'tokens 1.2k' for first session (800+400), got: %s", output)
@@ -572,7 +571,7 @@ func TestWriteActiveSessions_ActiveTimeOmittedWhenClose(t *testing.T) {
 
 	var buf bytes.Buffer
 	sty := newStatusStyles(&buf)
-	writeActiveSessions(&buf, sty)
+	writeActiveSessions(&buf, sty, false)
 
 	output := buf.String()
 	// Should not show "active Xm ago" when LastInteractionTime is close to StartedAt
@@ -587,7 +586,7 @@ func TestWriteActiveSessions_NoSessions(t *testing.T) {
 
 	var buf bytes.Buffer
 	sty := newStatusStyles(&buf)
-	writeActiveSessions(&buf, sty)
+	writeActiveSessions(&buf, sty, false)
 
 	// Should produce no output when there are no sessions
 	if buf.Len() != 0 {
@@ -617,7 +616,7 @@ func TestWriteActiveSessions_EndedSessionsExcluded(t *testing.T) {
 
 	var buf bytes.Buffer
 	sty := newStatusStyles(&buf)
-	writeActiveSessions(&buf, sty)
+	writeActiveSessions(&buf, sty, false)
 
 	// Should produce no output when all sessions are ended
 	if buf.Len() != 0 {
@@ -1049,6 +1048,43 @@ func TestFormatSettingsStatusShort_Disabled(t *testing.T) {
 	}
 }
 
+func TestFormatSettingsStatusShort_DisabledOnBranch(t *testing.T) {
+	setupTestRepo(t)
+	branch := currentBranchForSettings()
+	if branch == "" {
+		t.Fatal("expected the test repo to be on a branch")
+	}
+
+	sty := statusStyles{colorEnabled: false, width: 60}
+	s := &EntireSettings{
+		Enabled:          true,
+		DisabledBranches: []string{"release/*", branch},
+	}
+
+	result := formatSettingsStatusShort(s, sty)
+	if !strings.Contains(result, "○") {
+		t.Errorf("status on a disabled branch should have open dot, got: %q", result)
+	}
+	if !strings.Contains(result, "disabled on this branch by pattern "+branch) {
+		t.Errorf("expected the matching pattern in output, got: %q", result)
+	}
+	if IsEnabledOnCurrentBranch(s) {
+		t.Error("IsEnabledOnCurrentBranch() = true on a disabled branch")
+	}
+
+	s = &EntireSettings{Enabled: true, EnabledBranches: []string{"feature/*"}}
+	result = formatSettingsStatusShort(s, sty)
+	if !strings.Contains(result, "no enabled_branches pattern matches") {
+		t.Errorf("expected enabled_branches explanation in output, got: %q", result)
+	}
+
+	s = &EntireSettings{Enabled: true, EnabledBranches: []string{branch}}
+	result = formatSettingsStatusShort(s, sty)
+	if !strings.Contains(result, "●") || strings.Contains(result, "disabled on this branch") {
+		t.Errorf("status on an enabled branch should be unchanged, got: %q", result)
+	}
+}
+
 func TestFormatSettingsStatus_Project(t *testing.T) {
 	t.Parallel()
 
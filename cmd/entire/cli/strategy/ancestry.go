@@ -0,0 +1,144 @@
+package strategy
+
+import (
+	"math"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ancestryWalkLimit bounds walks that cannot be pruned by commit-graph
+// generation numbers, so a missing commit-graph never makes a hook slow.
+const ancestryWalkLimit = 1000
+
+// ancestryCacheSize bounds the process-wide cache of ancestry answers.
+const ancestryCacheSize = 4096
+
+// Ancestry answers "is commit A an ancestor of commit B" queries.
+//
+// When the repository has a commit-graph file (written by `git gc`, `git
+// maintenance`, or `git commit-graph write`), parents are read from it
+// instead of decoding commit objects, and generation numbers stop the walk
+// as soon as no remaining commit can reach A. Without one, it falls back to
+// a bounded walk of the object store. Definite answers are cached for the
+// life of the process; commits are immutable, so they never go stale.
+type Ancestry struct {
+	graph commitgraphfmt.Index
+	nodes commitgraph.CommitNodeIndex
+}
+
+type ancestryKey struct {
+	commit, target plumbing.Hash
+}
+
+var (
+	ancestryCacheMu sync.Mutex
+	ancestryCache   = make(map[ancestryKey]bool)
+)
+
+// NewAncestry returns an Ancestry for repo, using its commit-graph when present.
+// Call Close when done to release the commit-graph files.
+func NewAncestry(repo *git.Repository) *Ancestry {
+	a := &Ancestry{}
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		if graph, err := commitgraphfmt.OpenChainOrFileIndex(storage.Filesystem()); err == nil {
+			a.graph = graph
+		}
+	}
+	a.nodes = commitgraph.NewGraphCommitNodeIndex(a.graph, repo.Storer)
+	return a
+}
+
+// HasCommitGraph reports whether queries are backed by a commit-graph file.
+func (a *Ancestry) HasCommitGraph() bool {
+	return a.graph != nil
+}
+
+// Close releases the commit-graph files, if any were opened.
+func (a *Ancestry) Close() {
+	if a.graph != nil {
+		_ = a.graph.Close() //nolint:errcheck // read-only files, nothing to flush
+		a.graph = nil
+	}
+}
+
+// IsAncestor reports whether commit is an ancestor of (or equal to) target.
+// Commits that cannot be read, and walks that hit the limit without a
+// commit-graph, are reported as not ancestors.
+func (a *Ancestry) IsAncestor(commit, target plumbing.Hash) bool {
+	if commit == target {
+		return true
+	}
+	key := ancestryKey{commit: commit, target: target}
+	ancestryCacheMu.Lock()
+	cached, ok := ancestryCache[key]
+	ancestryCacheMu.Unlock()
+	if ok {
+		return cached
+	}
+
+	found, definite := a.walk(commit, target)
+	if definite {
+		ancestryCacheMu.Lock()
+		if len(ancestryCache) >= ancestryCacheSize {
+			clear(ancestryCache)
+		}
+		ancestryCache[key] = found
+		ancestryCacheMu.Unlock()
+	}
+	return found
+}
+
+// walk searches target's history for commit, breadth first. definite is
+// false when the answer may be wrong because the walk was cut short or a
+// parent could not be read (e.g. in a shallow clone).
+func (a *Ancestry) walk(commit, target plumbing.Hash) (found, definite bool) {
+	want, err := a.nodes.Get(commit)
+	if err != nil {
+		return false, false
+	}
+	start, err := a.nodes.Get(target)
+	if err != nil {
+		return false, false
+	}
+
+	// Every ancestor of a commit has a lower generation number, so commits
+	// at or below want's generation cannot reach it. Commits outside the
+	// commit-graph report math.MaxUint64 and are always explored.
+	minGeneration := want.Generation()
+	pruned := minGeneration != 0 && minGeneration != math.MaxUint64
+
+	queue := []commitgraph.CommitNode{start}
+	seen := map[plumbing.Hash]struct{}{target: {}}
+	definite = true
+	for visited := 0; len(queue) > 0; visited++ {
+		if !pruned && visited >= ancestryWalkLimit {
+			return false, false
+		}
+		node := queue[0]
+		queue = queue[1:]
+		if node.ID() == commit {
+			return true, true
+		}
+		if pruned && node.Generation() <= minGeneration {
+			continue
+		}
+		for _, parentHash := range node.ParentHashes() {
+			if _, ok := seen[parentHash]; ok {
+				continue
+			}
+			seen[parentHash] = struct{}{}
+			parent, parentErr := a.nodes.Get(parentHash)
+			if parentErr != nil {
+				definite = false
+				continue
+			}
+			queue = append(queue, parent)
+		}
+	}
+	return false, definite
+}
@@ -0,0 +1,160 @@
+package strategy
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/benchutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// revParse returns the hash rev resolves to in dir.
+func revParse(t *testing.T, dir, rev string) plumbing.Hash {
+	t.Helper()
+	out, err := exec.CommandContext(context.Background(), "git", "-C", dir, "rev-parse", rev).Output()
+	require.NoError(t, err, "git rev-parse %s", rev)
+	return plumbing.NewHash(strings.TrimSpace(string(out)))
+}
+
+// setupAncestryFixture creates this history and returns its path:
+//
+//	A---B-------M---D   main
+//	     \     /
+//	      S1--S2        side
+//	     \
+//	      X             other
+func setupAncestryFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q", "-b", "main")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "A")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "B")
+	runGitInDir(t, dir, "branch", "other")
+	runGitInDir(t, dir, "checkout", "-q", "-b", "side")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "S1")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "S2")
+	runGitInDir(t, dir, "checkout", "-q", "other")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "X")
+	runGitInDir(t, dir, "checkout", "-q", "main")
+	runGitInDir(t, dir, "merge", "-q", "--no-ff", "-m", "M", "side")
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "D")
+	return dir
+}
+
+func TestAncestry_IsAncestor(t *testing.T) {
+	t.Parallel()
+
+	for _, withGraph := range []bool{false, true} {
+		name := "object store"
+		if withGraph {
+			name = "commit-graph"
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := setupAncestryFixture(t)
+			if withGraph {
+				runGitInDir(t, dir, "commit-graph", "write", "--reachable")
+			}
+			repo, err := git.PlainOpen(dir)
+			require.NoError(t, err)
+
+			a := NewAncestry(repo)
+			defer a.Close()
+			assert.Equal(t, withGraph, a.HasCommitGraph())
+
+			rootHash := revParse(t, dir, "main~3")
+			mergeBase := revParse(t, dir, "main~2")
+			sideTip := revParse(t, dir, "side")
+			otherTip := revParse(t, dir, "other")
+			head := revParse(t, dir, "main")
+
+			tests := []struct {
+				name           string
+				commit, target plumbing.Hash
+				want           bool
+			}{
+				{"same commit", head, head, true},
+				{"root of head", rootHash, head, true},
+				{"second parent side", sideTip, head, true},
+				{"descendant is not ancestor", head, rootHash, false},
+				{"unrelated branch", otherTip, head, false},
+				{"sibling branches", sideTip, otherTip, false},
+				{"merge base of branch", mergeBase, otherTip, true},
+				{"unknown commit", plumbing.NewHash("1111111111111111111111111111111111111111"), head, false},
+			}
+			for _, tt := range tests {
+				assert.Equal(t, tt.want, a.IsAncestor(tt.commit, tt.target), tt.name)
+				// A second query may be answered from the cache and must agree.
+				assert.Equal(t, tt.want, a.IsAncestor(tt.commit, tt.target), tt.name+" (cached)")
+			}
+		})
+	}
+}
+
+func TestAncestry_CommitsNewerThanGraph(t *testing.T) {
+	t.Parallel()
+	dir := setupAncestryFixture(t)
+	runGitInDir(t, dir, "commit-graph", "write", "--reachable")
+	// Commits made after the graph was written are read from the object store.
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "E")
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	a := NewAncestry(repo)
+	defer a.Close()
+
+	head := revParse(t, dir, "main")
+	assert.True(t, a.IsAncestor(revParse(t, dir, "side~1"), head))
+	assert.False(t, a.IsAncestor(revParse(t, dir, "other"), head))
+	assert.False(t, a.IsAncestor(head, revParse(t, dir, "main~1")))
+}
+
+// --- Ancestry benchmarks ---
+// Ancestry queries compare session base commits against HEAD in hooks, so
+// they must stay fast on long histories. These call walk directly to bypass
+// the result cache.
+
+func BenchmarkAncestryWalk(b *testing.B) {
+	b.Run("ObjectStore", benchAncestryWalk(false))
+	b.Run("CommitGraph", benchAncestryWalk(true))
+}
+
+func benchAncestryWalk(withGraph bool) func(*testing.B) {
+	return func(b *testing.B) {
+		repo := benchutil.NewBenchRepo(b, benchutil.RepoOpts{CommitCount: 900})
+		if withGraph {
+			repo.WriteCommitGraph(b)
+		}
+		gitRepo, err := git.PlainOpen(repo.Dir)
+		if err != nil {
+			b.Fatalf("open: %v", err)
+		}
+		head := plumbing.NewHash(repo.HeadHash)
+		var root plumbing.Hash
+		iter, err := gitRepo.Log(&git.LogOptions{From: head})
+		if err != nil {
+			b.Fatalf("log: %v", err)
+		}
+		for c, nextErr := iter.Next(); nextErr == nil; c, nextErr = iter.Next() {
+			root = c.Hash
+		}
+
+		a := NewAncestry(gitRepo)
+		defer a.Close()
+		if found, _ := a.walk(root, head); !found {
+			b.Fatal("root commit should be an ancestor of HEAD")
+		}
+
+		b.ResetTimer()
+		for range b.N {
+			a.walk(root, head)
+			a.walk(head, root)
+		}
+	}
+}
@@ -37,7 +37,7 @@ func TestIsShadowBranch(t *testing.T) {
 		{"too short commit (6 chars)", "entire/abc123", false},
 		{"too short commit (1 char)", "entire/a", false},
 		{"non-hex chars in commit", "entire/ghijklm", false},
-		{"sessions branch", paths.MetadataBranchName, false},
+		{"sessions branch", paths.MetadataBranchName(), false},
 		{"no prefix", "abc1234", false},
 		{"wrong prefix", "feature/abc1234", false},
 		{"main branch", "main", false},
@@ -95,7 +95,7 @@ func TestListShadowBranches(t *testing.T) {
 	}{
 		{"entire/abc1234", true},
 		{"entire/def5678", true},
-		{paths.MetadataBranchName, false}, // Should NOT be listed
+		{paths.MetadataBranchName(), false}, // Should NOT be listed
 		{"feature/foo", false},
 		{"main", false},
 	}
@@ -130,8 +130,8 @@ func TestListShadowBranches(t *testing.T) {
 	if !shadowSet["entire/def5678"] {
 		t.Error("ListShadowBranches() missing 'entire/def5678'")
 	}
-	if shadowSet[paths.MetadataBranchName] {
-		t.Errorf("ListShadowBranches() should not include '%s'", paths.MetadataBranchName)
+	if shadowSet[paths.MetadataBranchName()] {
+		t.Errorf("ListShadowBranches() should not include '%s'", paths.MetadataBranchName())
 	}
 }
 
@@ -355,7 +355,7 @@ func TestListOrphanedSessionStates_RecentSessionNotOrphaned(t *testing.T) {
 	}
 
 	// List orphaned session states
-	orphaned, err := ListOrphanedSessionStates()
+	orphaned, err := ListOrphanedSessionStates(time.Now())
 	if err != nil {
 		t.Fatalf("ListOrphanedSessionStates() error = %v", err)
 	}
@@ -444,7 +444,7 @@ func TestListOrphanedSessionStates_ShadowBranchMatching(t *testing.T) {
 	t.Logf("Session WorktreeID: %q", worktreeID)
 
 	// List orphaned session states
-	orphaned, err := ListOrphanedSessionStates()
+	orphaned, err := ListOrphanedSessionStates(time.Now())
 	if err != nil {
 		t.Fatalf("ListOrphanedSessionStates() error = %v", err)
 	}
@@ -6,8 +6,10 @@ import (
 	"log/slog"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
@@ -39,6 +41,9 @@ type CleanupItem struct {
 	Type   CleanupType
 	ID     string // Branch name, session ID, or checkpoint ID
 	Reason string // Why this item is considered orphaned
+	// StateDir is the session state directory of a session-state item listed
+	// across users. Empty = the current user's directory.
+	StateDir string
 }
 
 // CleanupResult contains the results of a cleanup operation.
@@ -57,7 +62,18 @@ type CleanupResult struct {
 //
 // The pattern requires at least 7 hex characters for the commit, optionally followed
 // by a dash and exactly 6 hex characters for the worktree hash.
-var shadowBranchPattern = regexp.MustCompile(`^entire/[0-9a-fA-F]{7,}(-[0-9a-fA-F]{6})?$`)
+// Compiled patterns are cached by prefix.
+func shadowBranchPattern(prefix string) *regexp.Regexp {
+	if cached, ok := shadowBranchPatterns.Load(prefix); ok {
+		return cached.(*regexp.Regexp) //nolint:forcetypeassert // only *regexp.Regexp is stored
+	}
+	re := regexp.MustCompile(`^` + regexp.QuoteMeta(prefix) + `[0-9a-fA-F]{7,}(-[0-9a-fA-F]{6})?$`)
+	shadowBranchPatterns.Store(prefix, re)
+	return re
+}
+
+// shadowBranchPatterns caches shadowBranchPattern results by prefix.
+var shadowBranchPatterns sync.Map
 
 // IsShadowBranch returns true if the branch name matches the shadow branch pattern.
 // Shadow branches have the format "entire/<commit-hash>-<worktree-hash>" where the
@@ -65,10 +81,10 @@ var shadowBranchPattern = regexp.MustCompile(`^entire/[0-9a-fA-F]{7,}(-[0-9a-fA-
 // The "entire/checkpoints/v1" branch is NOT a shadow branch.
 func IsShadowBranch(branchName string) bool {
 	// Explicitly exclude entire/checkpoints/v1
-	if branchName == paths.MetadataBranchName {
+	if branchName == paths.MetadataBranchName() {
 		return false
 	}
-	return shadowBranchPattern.MatchString(branchName)
+	return shadowBranchPattern(checkpoint.ShadowBranchPrefix()).MatchString(branchName)
 }
 
 // ListShadowBranches returns all shadow branches in the repository.
@@ -142,21 +158,48 @@ func DeleteShadowBranches(branches []string) (deleted []string, failed []string,
 //   - No shadow branch exists for the session's base commit
 //
 // This is strategy-agnostic as session states are shared by all strategies.
-func ListOrphanedSessionStates() ([]CleanupItem, error) {
-	repo, err := OpenRepository()
+// When state.per_user is enabled, only the current user's sessions are checked.
+// Sessions that started within sessionGracePeriod of now are never orphaned.
+func ListOrphanedSessionStates(now time.Time) ([]CleanupItem, error) {
+	store, err := session.NewStateStore()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open git repository: %w", err)
+		return nil, fmt.Errorf("failed to create state store: %w", err)
 	}
+	return listOrphanedSessionStates([]*session.StateStore{store}, false, now)
+}
 
-	// Get all session states
-	store, err := session.NewStateStore()
+// ListOrphanedSessionStatesAllUsers is ListOrphanedSessionStates across the
+// shared session state directory and every user's directory.
+func ListOrphanedSessionStatesAllUsers(now time.Time) ([]CleanupItem, error) {
+	stores, err := session.NewAllUsersStateStores()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create state store: %w", err)
+		return nil, fmt.Errorf("failed to create state stores: %w", err)
 	}
+	return listOrphanedSessionStates(stores, true, now)
+}
 
-	states, err := store.List(context.Background())
+func listOrphanedSessionStates(stores []*session.StateStore, recordDir bool, now time.Time) ([]CleanupItem, error) {
+	repo, err := OpenRepository()
 	if err != nil {
-		return nil, fmt.Errorf("failed to list session states: %w", err)
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	// Skip sessions that started recently - they may be actively in use
+	// but haven't created their first checkpoint yet
+	filter := session.Filter{StartedBefore: now.Add(-sessionGracePeriod)}
+	type storedState struct {
+		state *session.State
+		dir   string
+	}
+	var states []storedState
+	for _, store := range stores {
+		storeStates, err := store.ListMatching(context.Background(), filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list session states: %w", err)
+		}
+		for _, state := range storeStates {
+			states = append(states, storedState{state: state, dir: store.Dir()})
+		}
 	}
 
 	if len(states) == 0 {
@@ -182,15 +225,8 @@ func ListOrphanedSessionStates() ([]CleanupItem, error) {
 	}
 
 	var orphaned []CleanupItem
-	now := time.Now()
-
-	for _, state := range states {
-		// Skip sessions that started recently - they may be actively in use
-		// but haven't created their first checkpoint yet
-		if now.Sub(state.StartedAt) < sessionGracePeriod {
-			continue
-		}
-
+	for _, stored := range states {
+		state := stored.state
 		// Check if session has checkpoints on entire/checkpoints/v1
 		hasCheckpoints := sessionsWithCheckpoints[state.SessionID]
 
@@ -200,13 +236,16 @@ func ListOrphanedSessionStates() ([]CleanupItem, error) {
 		hasShadowBranch := shadowBranchSet[expectedBranch]
 
 		// Session is orphaned if it has no checkpoints AND no shadow branch
-		if !hasCheckpoints && !hasShadowBranch {
-			reason := "no checkpoints or shadow branch found"
-			orphaned = append(orphaned, CleanupItem{
+		if !hasCheckpoints && !hasShadowBranch && !hasPatchSteps(state) {
+			item := CleanupItem{
 				Type:   CleanupTypeSessionState,
 				ID:     state.SessionID,
-				Reason: reason,
-			})
+				Reason: "no checkpoints or shadow branch found",
+			}
+			if recordDir {
+				item.StateDir = stored.dir
+			}
+			orphaned = append(orphaned, item)
 		}
 	}
 
@@ -223,7 +262,27 @@ func DeleteOrphanedSessionStates(sessionIDs []string) (deleted []string, failed
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create state store: %w", err)
 	}
+	deleted, failed = clearSessionStates(store, sessionIDs)
+	return deleted, failed, nil
+}
+
+// deleteSessionStatesInDir deletes session states from the given user's state
+// directory, as recorded in CleanupItem.StateDir.
+func deleteSessionStatesInDir(stateDir string, sessionIDs []string) (deleted []string, failed []string, err error) {
+	stores, err := session.NewAllUsersStateStores()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create state stores: %w", err)
+	}
+	for _, store := range stores {
+		if store.Dir() == stateDir {
+			deleted, failed = clearSessionStates(store, sessionIDs)
+			return deleted, failed, nil
+		}
+	}
+	return nil, sessionIDs, nil
+}
 
+func clearSessionStates(store *session.StateStore, sessionIDs []string) (deleted []string, failed []string) {
 	for _, sessionID := range sessionIDs {
 		if err := store.Clear(context.Background(), sessionID); err != nil {
 			failed = append(failed, sessionID)
@@ -231,8 +290,7 @@ func DeleteOrphanedSessionStates(sessionIDs []string) (deleted []string, failed
 			deleted = append(deleted, sessionID)
 		}
 	}
-
-	return deleted, failed, nil
+	return deleted, failed
 }
 
 // DeleteOrphanedCheckpoints removes checkpoint directories from the entire/checkpoints/v1 branch.
@@ -247,7 +305,7 @@ func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed
 	}
 
 	// Get sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return nil, nil, fmt.Errorf("sessions branch not found: %w", err)
@@ -324,7 +382,7 @@ func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed
 
 	// Update branch reference
 	newRef := plumbing.NewHashReference(refName, commitHash)
-	if err := repo.Storer.SetReference(newRef); err != nil {
+	if err := audit.SetReference(repo, newRef); err != nil {
 		return nil, nil, fmt.Errorf("failed to update branch: %w", err)
 	}
 
@@ -336,18 +394,43 @@ func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed
 // It iterates over all registered strategies and calls ListOrphanedItems on those
 // that implement OrphanedItemsLister.
 // Returns an error if the repository cannot be opened.
+// When state.per_user is enabled, only the current user's session states are
+// listed and shadow branches still used by other users' sessions are skipped.
 func ListAllCleanupItems() ([]CleanupItem, error) {
+	return listAllCleanupItems(false)
+}
+
+// ListAllCleanupItemsAllUsers is ListAllCleanupItems for every user's sessions.
+func ListAllCleanupItemsAllUsers() ([]CleanupItem, error) {
+	return listAllCleanupItems(true)
+}
+
+func listAllCleanupItems(allUsers bool) ([]CleanupItem, error) {
 	var items []CleanupItem
 	var firstErr error
 
-	strat := NewManualCommitStrategy()
+	strat := &ManualCommitStrategy{}
 	stratItems, err := strat.ListOrphanedItems()
 	if err != nil {
 		return nil, fmt.Errorf("listing orphaned items: %w", err)
 	}
-	items = append(items, stratItems...)
+	var inUse map[string]bool
+	if !allUsers {
+		inUse = otherUsersShadowBranches()
+	}
+	for _, item := range stratItems {
+		if item.Type == CleanupTypeShadowBranch && inUse[item.ID] {
+			continue
+		}
+		items = append(items, item)
+	}
 	// Orphaned session states (strategy-agnostic)
-	states, err := ListOrphanedSessionStates()
+	var states []CleanupItem
+	if allUsers {
+		states, err = ListOrphanedSessionStatesAllUsers(strat.now())
+	} else {
+		states, err = ListOrphanedSessionStates(strat.now())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -371,11 +454,16 @@ func DeleteAllCleanupItems(items []CleanupItem) (*CleanupResult, error) {
 
 	// Group items by type
 	var branches, states, checkpoints []string
+	otherUserStates := make(map[string][]string) // state dir -> session IDs
 	for _, item := range items {
 		switch item.Type {
 		case CleanupTypeShadowBranch:
 			branches = append(branches, item.ID)
 		case CleanupTypeSessionState:
+			if item.StateDir != "" {
+				otherUserStates[item.StateDir] = append(otherUserStates[item.StateDir], item.ID)
+				continue
+			}
 			states = append(states, item.ID)
 		case CleanupTypeCheckpoint:
 			checkpoints = append(checkpoints, item.ID)
@@ -410,11 +498,19 @@ func DeleteAllCleanupItems(items []CleanupItem) (*CleanupResult, error) {
 	}
 
 	// Delete session states
-	if len(states) > 0 {
+	if len(states) > 0 || len(otherUserStates) > 0 {
 		deleted, failed, err := DeleteOrphanedSessionStates(states)
 		if err != nil {
 			return result, err
 		}
+		for dir, ids := range otherUserStates {
+			dirDeleted, dirFailed, err := deleteSessionStatesInDir(dir, ids)
+			if err != nil {
+				return result, err
+			}
+			deleted = append(deleted, dirDeleted...)
+			failed = append(failed, dirFailed...)
+		}
 		result.SessionStates = deleted
 		result.FailedStates = failed
 
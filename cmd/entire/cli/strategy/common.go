@@ -14,9 +14,12 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5"
@@ -31,6 +34,7 @@ const (
 	branchMaster = "master"
 	// Strategy name constants
 	StrategyNameManualCommit = "manual-commit"
+	StrategyNamePatchFile    = settings.PatchFileStrategy
 )
 
 // errStop is a sentinel error used to break out of git log iteration.
@@ -54,17 +58,20 @@ func EnsureSetup() error {
 		return err
 	}
 
-	// Ensure the entire/checkpoints/v1 orphan branch exists for permanent session storage
-	repo, err := OpenRepository()
-	if err != nil {
-		return fmt.Errorf("failed to open git repository: %w", err)
-	}
-	if err := EnsureMetadataBranch(repo); err != nil {
-		return fmt.Errorf("failed to ensure metadata branch: %w", err)
+	// Ensure the entire/checkpoints/v1 orphan branch exists for permanent session storage.
+	// The patch-file strategy creates no refs, so it doesn't need one.
+	if s, err := settings.Load(); err != nil || !s.UsesPatchFileStrategy() {
+		repo, err := OpenRepository()
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		if err := EnsureMetadataBranch(repo); err != nil {
+			return fmt.Errorf("failed to ensure metadata branch: %w", err)
+		}
 	}
 
 	// Install generic hooks (they delegate to strategy at runtime)
-	if !IsGitHookInstalled() {
+	if !IsGitHookInstalled() || hooksModeMismatch() {
 		if _, err := InstallGitHook(true, isLocalDev()); err != nil {
 			return fmt.Errorf("failed to install git hooks: %w", err)
 		}
@@ -74,33 +81,12 @@ func EnsureSetup() error {
 
 // IsAncestorOf checks if commit is an ancestor of (or equal to) target.
 // Returns true if target can reach commit by following parent links.
-// Limits search to 1000 commits to avoid excessive traversal.
+// Uses the repository's commit-graph when present; otherwise limits the
+// search to 1000 commits to avoid excessive traversal. See Ancestry.
 func IsAncestorOf(repo *git.Repository, commit, target plumbing.Hash) bool {
-	if commit == target {
-		return true
-	}
-
-	iter, err := repo.Log(&git.LogOptions{From: target})
-	if err != nil {
-		return false
-	}
-	defer iter.Close()
-
-	found := false
-	count := 0
-	_ = iter.ForEach(func(c *object.Commit) error { //nolint:errcheck // Best-effort search, errors are non-fatal
-		count++
-		if count > 1000 {
-			return errStop
-		}
-		if c.Hash == commit {
-			found = true
-			return errStop
-		}
-		return nil
-	})
-
-	return found
+	ancestry := NewAncestry(repo)
+	defer ancestry.Close()
+	return ancestry.IsAncestor(commit, target)
 }
 
 // ListCheckpoints returns all checkpoints from the entire/checkpoints/v1 branch.
@@ -111,7 +97,7 @@ func ListCheckpoints() ([]CheckpointInfo, error) {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
 
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		//nolint:nilerr // No sessions branch yet is expected, return empty list
@@ -219,10 +205,9 @@ func ListCheckpoints() ([]CheckpointInfo, error) {
 }
 
 const (
-	entireGitignore    = ".entire/.gitignore"
-	entireDir          = ".entire"
-	gitDir             = ".git"
-	shadowBranchPrefix = "entire/"
+	entireGitignore = ".entire/.gitignore"
+	entireDir       = ".entire"
+	gitDir          = ".git"
 
 	// DefaultAgentType is the generic fallback agent type name
 	DefaultAgentType = agent.AgentTypeUnknown
@@ -284,7 +269,7 @@ func resolveAgentType(ctxAgentType agent.AgentType, state *SessionState) agent.A
 // ensureMetadataBranch creates the orphan entire/checkpoints/v1 branch if it doesn't exist.
 // This branch has no parent and starts with an empty tree.
 func EnsureMetadataBranch(repo *git.Repository) error {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 
 	// Check if branch already exists
 	_, err := repo.Reference(refName, true)
@@ -332,11 +317,11 @@ func EnsureMetadataBranch(repo *git.Repository) error {
 
 	// Create branch reference
 	ref := plumbing.NewHashReference(refName, commitHash)
-	if err := repo.Storer.SetReference(ref); err != nil {
+	if err := audit.SetReference(repo, ref); err != nil {
 		return fmt.Errorf("failed to create metadata branch: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "✓ Created orphan branch '%s' for session metadata\n", paths.MetadataBranchName)
+	fmt.Fprintf(os.Stderr, "✓ Created orphan branch '%s' for session metadata\n", paths.MetadataBranchName())
 	return nil
 }
 
@@ -410,7 +395,7 @@ func ReadCheckpointMetadata(tree *object.Tree, checkpointPath string) (*Checkpoi
 
 // GetMetadataBranchTree returns the tree object for the entire/checkpoints/v1 branch.
 func GetMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metadata branch reference: %w", err)
@@ -428,7 +413,7 @@ func GetMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
 	return tree, nil
 }
 
-// ExtractFirstPrompt extracts and truncates the first meaningful prompt from prompt content.
+// ExtractFirstPrompt extracts and summarizes the first meaningful prompt from prompt content.
 // Prompts are separated by "\n\n---\n\n". Skips empty prompts and separator-only content.
 // Returns empty string if no valid prompt is found.
 func ExtractFirstPrompt(content string) string {
@@ -454,7 +439,9 @@ func ExtractFirstPrompt(content string) string {
 		return ""
 	}
 
-	return TruncateDescription(firstPrompt, MaxDescriptionLength)
+	opts := settings.PromptSummaryOptions(MaxDescriptionLength)
+	opts.Suffix = "..."
+	return stringutil.Summarize(firstPrompt, opts)
 }
 
 // ReadSessionPromptFromTree reads the first meaningful prompt from a checkpoint's prompt.txt file in a git tree.
@@ -476,7 +463,7 @@ func ReadSessionPromptFromTree(tree *object.Tree, checkpointPath string) string
 
 // ReadAgentTypeFromTree reads the agent type from a checkpoint's metadata.json file in a git tree.
 // If metadata.json doesn't exist (shadow branches), it falls back to detecting the agent
-// from the presence of agent-specific config files (.gemini/settings.json or .claude/).
+// from the presence of agent-specific config files (.gemini/settings.json, .claude/, .cursor/, ...).
 // Returns agent.AgentTypeUnknown if the agent type cannot be determined.
 func ReadAgentTypeFromTree(tree *object.Tree, checkpointPath string) agent.AgentType {
 	// First, try to read from metadata.json (present in condensed/committed checkpoints)
@@ -491,13 +478,23 @@ func ReadAgentTypeFromTree(tree *object.Tree, checkpointPath string) agent.Agent
 	}
 
 	// Fall back to detecting agent from config files (shadow branches don't have metadata.json).
-	// Order: Gemini (most specific check), Claude (established default), OpenCode (newest/preview).
+	// Order: Gemini (most specific check), Claude (established default), then the preview
+	// agents Cursor, Aider, Codex, and OpenCode.
 	if _, err := tree.File(".gemini/settings.json"); err == nil {
 		return agent.AgentTypeGemini
 	}
 	if _, err := tree.Tree(".claude"); err == nil {
 		return agent.AgentTypeClaudeCode
 	}
+	if _, err := tree.Tree(".cursor"); err == nil {
+		return agent.AgentTypeCursor
+	}
+	if _, err := tree.File(".aider.conf.yml"); err == nil {
+		return agent.AgentTypeAider
+	}
+	if _, err := tree.File(".codex/config.toml"); err == nil {
+		return agent.AgentTypeCodex
+	}
 	// OpenCode: .opencode directory or opencode.json config
 	if _, err := tree.Tree(".opencode"); err == nil {
 		return agent.AgentTypeOpenCode
@@ -549,7 +546,7 @@ func ReadAllSessionPromptsFromTree(tree *object.Tree, checkpointPath string, ses
 
 // GetRemoteMetadataBranchTree returns the tree object for origin/entire/checkpoints/v1.
 func GetRemoteMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
-	refName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)
+	refName := plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get remote metadata branch reference: %w", err)
@@ -582,6 +579,9 @@ func GetRemoteMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
 //
 // The function first uses 'git rev-parse --show-toplevel' to find the repository
 // root, which works correctly even when called from a subdirectory within the repo.
+//
+// Repositories using extensions go-git cannot read (e.g. reftable ref storage or
+// SHA-256 object format) are rejected with an error wrapping ErrUnsupportedRepository.
 func OpenRepository() (*git.Repository, error) {
 	repoRoot, err := paths.WorktreeRoot()
 	if err != nil {
@@ -590,6 +590,10 @@ func OpenRepository() (*git.Repository, error) {
 		repoRoot = "."
 	}
 
+	if err := CheckRepoSupport(repoRoot); err != nil {
+		return nil, err
+	}
+
 	repo, err := git.PlainOpenWithOptions(repoRoot, &git.PlainOpenOptions{
 		EnableDotGitCommonDir: true,
 	})
@@ -700,6 +704,10 @@ func EnsureEntireGitignore() error {
 		"settings.local.json",
 		"metadata/",
 		"logs/",
+		"debug/",
+		"cache/",
+		"patches/",
+		repoIDFileName,
 	}
 
 	// Track what needs to be added
@@ -1138,6 +1146,9 @@ func DeleteBranchCLI(branchName string) error {
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to delete branch %s: %s: %w", branchName, strings.TrimSpace(string(output)), err)
 	}
+	if commonDir, err := GetGitCommonDir(); err == nil {
+		audit.Record(commonDir, audit.Event{Action: audit.ActionRefDelete, Target: "refs/heads/" + branchName})
+	}
 	return nil
 }
 
@@ -1163,6 +1174,10 @@ func HardResetWithProtection(commitHash plumbing.Hash) (shortID string, err erro
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return "", fmt.Errorf("reset failed: %s: %w", strings.TrimSpace(string(output)), err)
 	}
+	if commonDir, err := GetGitCommonDir(); err == nil {
+		audit.Record(commonDir, audit.Event{Action: audit.ActionRefUpdate, Target: "HEAD", New: hashStr})
+		audit.Record(commonDir, audit.Event{Action: audit.ActionFileWrite, Target: "(worktree reset --hard)", New: hashStr})
+	}
 
 	// Return short commit ID for display
 	shortID = hashStr
@@ -1200,10 +1215,12 @@ func collectUntrackedFiles() ([]string, error) {
 		return nil, nil
 	}
 
+	// Files matching .entireignore are left alone like gitignored ones
+	ignore := loadEntireIgnore()
 	var files []string
 	for _, f := range strings.Split(raw, "\x00") {
 		// Defense-in-depth: filter protected paths even though --exclude-standard should already handle them
-		if f != "" && !isProtectedPath(f) {
+		if f != "" && !isProtectedPath(f) && !ignore.Match(f) {
 			files = append(files, f)
 		}
 	}
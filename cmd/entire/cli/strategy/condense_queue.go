@@ -0,0 +1,250 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/filelock"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// condenseQueueLockFile is held by the worker draining the queue.
+const condenseQueueLockFile = ".lock"
+
+// condenseQueueLockWait is how long `entire worker run-once` waits for another
+// worker to finish. Hooks never wait. A variable so tests can shorten it.
+var condenseQueueLockWait = 30 * time.Second
+
+// ErrCondenseQueueBusy is returned when another worker holds the queue lock
+// for longer than the caller is willing to wait.
+var ErrCondenseQueueBusy = errors.New("another worker is condensing queued commits")
+
+// condenseJob is a commit whose post-commit condensation was deferred by
+// strategy_options.async_condensation.
+type condenseJob struct {
+	Commit string `json:"commit"`
+	// Worktree is the root of the worktree the commit was made in. The queue
+	// is shared by all worktrees, but a commit's sessions can only be found
+	// from its own worktree, so only that worktree condenses it.
+	Worktree string `json:"worktree,omitempty"`
+	// Sequence is set when a rebase, cherry-pick, or revert was in progress
+	// when the commit was made.
+	Sequence   bool      `json:"sequence,omitempty"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// asyncCondensationEnabled reports whether post-commit only queues commits.
+func asyncCondensationEnabled() bool {
+	s, err := settings.Load()
+	return err == nil && s.IsAsyncCondensationEnabled()
+}
+
+// CondenseQueueDir returns the directory queued commits are kept in:
+// .git/entire/queue in the git common directory, shared by all worktrees.
+func CondenseQueueDir() (string, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, "entire", "queue"), nil
+}
+
+// enqueueCondensation queues commit for condensation by a later worker run.
+// Job files are named by enqueue time so workers process commits in order.
+func (s *ManualCommitStrategy) enqueueCondensation(commit plumbing.Hash, sequence bool) error {
+	dir, err := CondenseQueueDir()
+	if err != nil {
+		return fmt.Errorf("failed to locate condensation queue: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create condensation queue: %w", err)
+	}
+
+	worktree, err := paths.WorktreeRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	job := condenseJob{Commit: commit.String(), Worktree: worktree, Sequence: sequence, EnqueuedAt: s.now()}
+	data, err := jsonutil.MarshalIndentWithNewline(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal condensation job: %w", err)
+	}
+
+	jobFile := filepath.Join(dir, fmt.Sprintf("%020d-%s.json", job.EnqueuedAt.UnixNano(), job.Commit))
+	tmpFile := jobFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write condensation job: %w", err)
+	}
+	if err := os.Rename(tmpFile, jobFile); err != nil {
+		return fmt.Errorf("failed to rename condensation job: %w", err)
+	}
+
+	logging.Debug(logging.WithComponent(context.Background(), "checkpoint"), "post-commit: queued condensation",
+		slog.String("commit", TruncateHash(job.Commit)),
+	)
+	return nil
+}
+
+// queuedCondenseJobs returns the job files in dir, oldest first.
+func queuedCondenseJobs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var jobs []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			jobs = append(jobs, entry.Name())
+		}
+	}
+	slices.Sort(jobs)
+	return jobs
+}
+
+// readCondenseJob reads the job in jobFile and reports whether the current
+// worktree, rooted at worktree, should handle it. Jobs queued in another
+// worktree that still exists are left for that worktree; jobs that cannot be
+// read, or whose worktree was removed, are handled here so they are dropped.
+func readCondenseJob(jobFile, worktree string) (condenseJob, bool) {
+	var job condenseJob
+	data, err := os.ReadFile(jobFile) //nolint:gosec // path is within the queue directory
+	if err != nil || json.Unmarshal(data, &job) != nil {
+		return condenseJob{}, true
+	}
+	if job.Worktree == "" || job.Worktree == worktree {
+		return job, true
+	}
+	if _, err := os.Stat(job.Worktree); err == nil {
+		return job, false
+	}
+	return condenseJob{}, true
+}
+
+// PendingCondensations returns the number of commits made in the current
+// worktree that are waiting to be condensed.
+func PendingCondensations() int {
+	dir, err := CondenseQueueDir()
+	if err != nil {
+		return 0
+	}
+	worktree, err := paths.WorktreeRoot()
+	if err != nil {
+		return 0
+	}
+	pending := 0
+	for _, name := range queuedCondenseJobs(dir) {
+		if _, ours := readCondenseJob(filepath.Join(dir, name), worktree); ours {
+			pending++
+		}
+	}
+	return pending
+}
+
+// RunCondensationQueue condenses every commit queued in the current
+// worktree, oldest first, and returns how many were processed. Only one
+// worker runs at a time; others wait up to condenseQueueLockWait and then
+// return ErrCondenseQueueBusy. Jobs are removed once handled, so no commit is
+// condensed twice.
+func RunCondensationQueue() (int, error) {
+	return (&ManualCommitStrategy{}).drainCondensationQueue(condenseQueueLockWait)
+}
+
+// TryCondensationQueue is RunCondensationQueue for hooks: it returns at once,
+// having processed nothing, if another worker is draining the queue, so a
+// hook never waits for someone else's condensation.
+func TryCondensationQueue() (int, error) {
+	n, err := (&ManualCommitStrategy{}).drainCondensationQueue(0)
+	if errors.Is(err, ErrCondenseQueueBusy) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// runCondensationQueue drains the queue before a hook does its own work,
+// logging instead of failing. Like TryCondensationQueue, it skips the queue
+// when another worker holds it.
+func (s *ManualCommitStrategy) runCondensationQueue() {
+	if _, err := s.drainCondensationQueue(0); err != nil && !errors.Is(err, ErrCondenseQueueBusy) {
+		logging.Warn(logging.WithComponent(context.Background(), "checkpoint"), "failed to condense queued commits",
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// drainCondensationQueue condenses the current worktree's queued commits,
+// waiting up to wait for another worker to release the queue.
+func (s *ManualCommitStrategy) drainCondensationQueue(wait time.Duration) (int, error) {
+	dir, err := CondenseQueueDir()
+	if err != nil {
+		return 0, nil //nolint:nilerr // Not in a repository: nothing can be queued
+	}
+	if len(queuedCondenseJobs(dir)) == 0 {
+		return 0, nil
+	}
+
+	lock, err := lockCondenseQueue(dir, wait)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Release()
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := paths.WorktreeRoot()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get worktree root: %w", err)
+	}
+
+	processed := 0
+	// Re-list under the lock: another worker may have drained the queue
+	// while this one waited.
+	for _, name := range queuedCondenseJobs(dir) {
+		jobFile := filepath.Join(dir, name)
+		job, ours := readCondenseJob(jobFile, worktree)
+		if !ours {
+			continue
+		}
+		if job.Commit != "" {
+			hash := plumbing.NewHash(job.Commit)
+			if _, err := repo.CommitObject(hash); err == nil {
+				_ = s.postCommit(repo, plumbing.NewHashReference(plumbing.HEAD, hash), job.Sequence) //nolint:errcheck // Warnings already printed, like the post-commit hook
+			}
+		}
+		// Jobs that cannot be read or whose commit is gone are dropped too,
+		// so one bad file cannot block the queue.
+		if err := os.Remove(jobFile); err != nil {
+			return processed, fmt.Errorf("failed to remove condensation job: %w", err)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// lockCondenseQueue takes the queue lock, waiting up to wait for another
+// worker to release it. The lock is a filelock, so a worker that crashed
+// never leaves the queue locked.
+func lockCondenseQueue(dir string, wait time.Duration) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(filepath.Join(dir, condenseQueueLockFile), wait)
+	if errors.Is(err, filelock.ErrLocked) {
+		return nil, ErrCondenseQueueBusy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock condensation queue: %w", err)
+	}
+	return lock, nil
+}
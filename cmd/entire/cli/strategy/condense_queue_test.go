@@ -0,0 +1,159 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostCommit_AsyncCondensation verifies that with async condensation the
+// post-commit hook only queues the commit, and the worker condenses it once.
+func TestPostCommit_AsyncCondensation(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "strategy_options": {"async_condensation": true}}`), 0o644))
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-async-condensation"
+	setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	state.Phase = session.PhaseIdle
+	state.FilesTouched = []string{"test.txt"}
+	require.NoError(t, s.saveSessionState(state))
+
+	commitWithCheckpointTrailer(t, repo, dir, "c0ffee0c0ffe")
+	require.NoError(t, s.PostCommit())
+
+	metadataRef := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
+	_, err = repo.Reference(metadataRef, true)
+	require.Error(t, err, "post-commit should not condense in async mode")
+	assert.Equal(t, 1, PendingCondensations())
+	assert.Empty(t, readCondensationLog(t, dir))
+
+	n, err := RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, PendingCondensations())
+
+	_, err = repo.Reference(metadataRef, true)
+	require.NoError(t, err, "the worker should condense the queued commit")
+	decisions := readCondensationLog(t, dir)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "c0ffee0c0ffe", decisions[0].CheckpointID)
+
+	// The job is gone, so a second run condenses nothing
+	n, err = RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Len(t, readCondensationLog(t, dir), 1)
+}
+
+func TestRunCondensationQueue_WaitsForLock(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	queueDir, err := CondenseQueueDir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(queueDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(queueDir, "00000000000000000001-bad.json"), []byte("not json"), 0o600))
+
+	wait := condenseQueueLockWait
+	condenseQueueLockWait = 100 * time.Millisecond
+	t.Cleanup(func() { condenseQueueLockWait = wait })
+
+	lock, err := lockCondenseQueue(queueDir, 0)
+	require.NoError(t, err)
+	_, err = RunCondensationQueue()
+	require.ErrorIs(t, err, ErrCondenseQueueBusy)
+
+	// Hooks do not wait for the worker holding the queue
+	start := time.Now()
+	n, err := TryCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.Less(t, time.Since(start), condenseQueueLockWait)
+	assert.Equal(t, 1, PendingCondensations())
+	lock.Release()
+
+	// Unreadable jobs are dropped rather than blocking the queue
+	n, err = RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, PendingCondensations())
+}
+
+// TestRunCondensationQueue_OtherWorktree verifies that a commit queued in one
+// worktree is condensed only from that worktree, where its sessions are, and
+// that jobs from removed worktrees are dropped.
+func TestRunCondensationQueue_OtherWorktree(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "strategy_options": {"async_condensation": true}}`), 0o644))
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-async-worktree"
+	setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	state.Phase = session.PhaseIdle
+	state.FilesTouched = []string{"test.txt"}
+	require.NoError(t, s.saveSessionState(state))
+
+	commitWithCheckpointTrailer(t, repo, dir, "c0ffee0c0ffe")
+	require.NoError(t, s.PostCommit())
+	require.Equal(t, 1, PendingCondensations())
+
+	worktreeDir := filepath.Join(t.TempDir(), "wt")
+	require.NoError(t, createWorktree(dir, worktreeDir, "wt-branch"))
+	t.Cleanup(func() { removeWorktree(dir, worktreeDir) })
+	t.Chdir(worktreeDir)
+	paths.ClearWorktreeRootCache()
+
+	assert.Equal(t, 0, PendingCondensations(), "the job belongs to the main worktree")
+	n, err := RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n, "another worktree must not condense the commit")
+	assert.Empty(t, readCondensationLog(t, dir))
+
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+	n, err = RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	decisions := readCondensationLog(t, dir)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, "c0ffee0c0ffe", decisions[0].CheckpointID)
+
+	// A job from a worktree that no longer exists cannot be condensed anywhere
+	queueDir, err := CondenseQueueDir()
+	require.NoError(t, err)
+	job := `{"commit": "` + plumbing.ZeroHash.String() + `", "worktree": "` + filepath.Join(dir, "gone") + `"}`
+	require.NoError(t, os.WriteFile(filepath.Join(queueDir, "00000000000000000001-gone.json"), []byte(job), 0o600))
+	n, err = RunCondensationQueue()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, PendingCondensations())
+}
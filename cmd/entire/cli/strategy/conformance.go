@@ -0,0 +1,220 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Conformance checks compare a session's state file against what is actually
+// on its shadow branch. Drift between the two has caused most of the
+// condensation and attribution bugs fixed so far, so the checks run after every
+// step in development builds and on demand from `entire doctor`.
+
+// Conformance check names.
+const (
+	ConformanceStepCount    = "step_count"
+	ConformanceFilesTouched = "files_touched"
+	ConformanceTranscript   = "transcript_start"
+)
+
+// ConformanceViolation describes one way a session's state disagrees with its
+// shadow branch or transcript.
+type ConformanceViolation struct {
+	SessionID string
+	Check     string
+	Detail    string
+}
+
+func (v ConformanceViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Check, v.Detail)
+}
+
+// conformanceChecksEnabled reports whether steps should be checked as they are
+// saved. Only development builds pay for the extra reads.
+func conformanceChecksEnabled() bool {
+	return buildinfo.Version == "dev"
+}
+
+// CheckSessionConformance verifies the session's state against its shadow
+// branch and transcript. It returns nil when everything agrees.
+func CheckSessionConformance(state *SessionState) ([]ConformanceViolation, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	return checkSessionConformance(repo, state)
+}
+
+// logConformanceViolations checks the session and logs any drift. Failures to
+// run the checks are logged at debug level; they never affect the caller.
+func logConformanceViolations(repo *git.Repository, state *SessionState) {
+	logCtx := logging.WithComponent(context.Background(), "conformance")
+	violations, err := checkSessionConformance(repo, state)
+	if err != nil {
+		logging.Debug(logCtx, "conformance check failed",
+			slog.String("session_id", state.SessionID),
+			slog.String("error", err.Error()))
+		return
+	}
+	for _, v := range violations {
+		logging.Warn(logCtx, "session state drift",
+			slog.String("session_id", v.SessionID),
+			slog.String("check", v.Check),
+			slog.String("detail", v.Detail))
+	}
+}
+
+func checkSessionConformance(repo *git.Repository, state *SessionState) ([]ConformanceViolation, error) {
+	var violations []ConformanceViolation
+	add := func(check, format string, args ...any) {
+		violations = append(violations, ConformanceViolation{
+			SessionID: state.SessionID,
+			Check:     check,
+			Detail:    fmt.Sprintf(format, args...),
+		})
+	}
+
+	// A condensed session resets StepCount to 0 but may leave its commits on a
+	// shadow branch another session still uses, so only live steps are checked.
+	if state.BaseCommit != "" && state.StepCount > 0 {
+		steps, err := sessionStepCommits(repo, state)
+		if err != nil {
+			return nil, err
+		}
+		if len(steps) != state.StepCount {
+			add(ConformanceStepCount, "state records %d step(s) but the shadow branch has %d", state.StepCount, len(steps))
+		}
+		if len(steps) > 0 {
+			missing, err := filesMissingFromState(steps[0], state.FilesTouched)
+			if err != nil {
+				return nil, err
+			}
+			if len(missing) > 0 {
+				add(ConformanceFilesTouched, "latest step changed files not in files_touched: %s", strings.Join(missing, ", "))
+			}
+		}
+	}
+
+	if length, ok := transcriptLength(state); ok && state.CheckpointTranscriptStart > length {
+		add(ConformanceTranscript, "checkpoint_transcript_start is %d but the transcript has %d", state.CheckpointTranscriptStart, length)
+	}
+
+	return violations, nil
+}
+
+// sessionStepCommits returns the session's step commits on its shadow branch,
+// newest first. Task checkpoints are not steps and are excluded.
+func sessionStepCommits(repo *git.Repository, state *SessionState) ([]*object.Commit, error) {
+	branchName := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
+	if err != nil {
+		return nil, nil //nolint:nilerr // No shadow branch means no steps
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shadow branch log: %w", err)
+	}
+	var steps []*object.Commit
+	err = iter.ForEach(func(c *object.Commit) error {
+		if sessionID, ok := trailers.ParseSession(c.Message); !ok || sessionID != state.SessionID {
+			return nil
+		}
+		if _, isTask := trailers.ParseTaskMetadata(c.Message); isTask {
+			return nil
+		}
+		steps = append(steps, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk shadow branch: %w", err)
+	}
+	return steps, nil
+}
+
+// filesMissingFromState returns the files the step commit changed relative to
+// its parent that are not listed in filesTouched. Entire's own metadata is
+// ignored. A step without a parent is skipped: the first checkpoint also
+// snapshots changes that predate the session, which are never in filesTouched.
+func filesMissingFromState(step *object.Commit, filesTouched []string) ([]string, error) {
+	if step.NumParents() == 0 {
+		return nil, nil
+	}
+	parent, err := step.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent of step %s: %w", step.Hash, err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent tree: %w", err)
+	}
+	stepTree, err := step.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read step tree: %w", err)
+	}
+	changes, err := parentTree.Diff(stepTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff step tree: %w", err)
+	}
+
+	touched := make(map[string]struct{}, len(filesTouched))
+	for _, f := range filesTouched {
+		touched[f] = struct{}{}
+	}
+	var missing []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		if strings.HasPrefix(name, paths.EntireDir+"/") {
+			continue
+		}
+		if _, ok := touched[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// transcriptLength returns the session transcript's current position in the
+// agent's units (lines or messages). ok is false when it cannot be determined,
+// e.g. the transcript no longer exists.
+func transcriptLength(state *SessionState) (int, bool) {
+	if state.TranscriptPath == "" || state.AgentType == "" {
+		return 0, false
+	}
+	if _, err := os.Stat(state.TranscriptPath); errors.Is(err, os.ErrNotExist) {
+		return 0, false
+	}
+	ag, err := agent.GetByAgentType(state.AgentType)
+	if err != nil {
+		return 0, false
+	}
+	analyzer, ok := ag.(agent.TranscriptAnalyzer)
+	if !ok {
+		return 0, false
+	}
+	pos, err := analyzer.GetTranscriptPosition(state.TranscriptPath)
+	if err != nil {
+		return 0, false
+	}
+	return pos, true
+}
@@ -0,0 +1,83 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSessionConformance(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	s := &ManualCommitStrategy{}
+	sessionID := "2026-03-01-conformance"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	transcriptPath := filepath.Join(metadataDirAbs, paths.TranscriptFileName)
+	require.NoError(t, os.WriteFile(transcriptPath, []byte(`{"type":"human","message":{"content":"edit"}}
+{"type":"assistant","message":{"content":"done"}}
+`), 0o644))
+
+	for i, content := range []string{"first edit", "second edit"} {
+		require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), content))
+		require.NoError(t, s.SaveStep(StepContext{
+			SessionID:      sessionID,
+			ModifiedFiles:  []string{"test.txt"},
+			MetadataDir:    metadataDir,
+			MetadataDirAbs: metadataDirAbs,
+			CommitMessage:  "Checkpoint",
+			AuthorName:     "Test",
+			AuthorEmail:    "test@test.com",
+		}), "step %d", i+1)
+	}
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	state.TranscriptPath = transcriptPath
+	state.AgentType = agent.AgentTypeClaudeCode
+
+	violations, err := CheckSessionConformance(state)
+	require.NoError(t, err)
+	assert.Empty(t, violations, "state written by SaveStep should conform")
+
+	state.StepCount = 3
+	state.FilesTouched = nil
+	state.CheckpointTranscriptStart = 10
+
+	violations, err = CheckSessionConformance(state)
+	require.NoError(t, err)
+	checks := make(map[string]string)
+	for _, v := range violations {
+		assert.Equal(t, sessionID, v.SessionID)
+		checks[v.Check] = v.Detail
+	}
+	assert.Equal(t, "state records 3 step(s) but the shadow branch has 2", checks[ConformanceStepCount])
+	assert.Equal(t, "latest step changed files not in files_touched: test.txt", checks[ConformanceFilesTouched])
+	assert.Equal(t, "checkpoint_transcript_start is 10 but the transcript has 2", checks[ConformanceTranscript])
+}
+
+func TestCheckSessionConformance_CondensedSession(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// A condensed session with no live steps and no transcript has nothing to check.
+	state := &SessionState{
+		SessionID:                 "2026-03-01-condensed",
+		BaseCommit:                "0123456789abcdef0123456789abcdef01234567",
+		CheckpointTranscriptStart: 40,
+		TranscriptPath:            filepath.Join(dir, "missing.jsonl"),
+		AgentType:                 agent.AgentTypeClaudeCode,
+	}
+	violations, err := CheckSessionConformance(state)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
@@ -2,10 +2,12 @@ package strategy
 
 import (
 	"context"
-	"io"
 	"log/slog"
+	"maps"
+	"slices"
 
 	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -36,12 +38,6 @@ import (
 func filesOverlapWithContent(repo *git.Repository, shadowBranchName string, headCommit *object.Commit, filesTouched []string) bool {
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 
-	// Build set of filesTouched for quick lookup
-	touchedSet := make(map[string]bool)
-	for _, f := range filesTouched {
-		touchedSet[f] = true
-	}
-
 	// Get HEAD commit tree (the committed content)
 	headTree, err := headCommit.Tree()
 	if err != nil {
@@ -95,14 +91,16 @@ func filesOverlapWithContent(repo *git.Repository, shadowBranchName string, head
 
 	// Check each file in filesTouched
 	for _, filePath := range filesTouched {
-		// Get file from HEAD tree (the committed content)
-		headFile, err := headTree.File(filePath)
-		if err != nil {
+		// Get file from HEAD tree (the committed content).
+		// Only the entry hash is needed, so use treeEntryHash which works even when
+		// the blob is not present locally (partial clones).
+		headHash, inHead := treeEntryHash(headTree, filePath)
+		if !inHead {
 			// File not in HEAD commit. Check if this is a deletion (existed in parent).
 			// Deletions count as overlap because the agent's action (deleting the file)
 			// is being committed - we want the session context linked to this commit.
 			if parentTree != nil {
-				if _, parentErr := parentTree.File(filePath); parentErr == nil {
+				if _, inParent := treeEntryHash(parentTree, filePath); inParent {
 					// File existed in parent but not in HEAD - this is a deletion
 					logging.Debug(logCtx, "filesOverlapWithContent: deleted file counts as overlap",
 						slog.String("file", filePath),
@@ -117,9 +115,7 @@ func filesOverlapWithContent(repo *git.Repository, shadowBranchName string, head
 		// Check if this is a modified file (exists in parent) or new file
 		isModified := false
 		if parentTree != nil {
-			if _, err := parentTree.File(filePath); err == nil {
-				isModified = true
-			}
+			_, isModified = treeEntryHash(parentTree, filePath)
 		}
 
 		// Modified files always count as overlap (user edited session's work)
@@ -131,8 +127,8 @@ func filesOverlapWithContent(repo *git.Repository, shadowBranchName string, head
 		}
 
 		// For new files, check content against shadow branch
-		shadowFile, err := shadowTree.File(filePath)
-		if err != nil {
+		shadowHash, inShadow := treeEntryHash(shadowTree, filePath)
+		if !inShadow {
 			// File not in shadow branch - this shouldn't happen but skip it
 			logging.Debug(logCtx, "filesOverlapWithContent: file in filesTouched but not in shadow branch",
 				slog.String("file", filePath),
@@ -141,18 +137,18 @@ func filesOverlapWithContent(repo *git.Repository, shadowBranchName string, head
 		}
 
 		// Compare by hash (blob hash) - exact content match required for new files
-		if headFile.Hash == shadowFile.Hash {
+		if headHash == shadowHash {
 			logging.Debug(logCtx, "filesOverlapWithContent: new file content match found",
 				slog.String("file", filePath),
-				slog.String("hash", headFile.Hash.String()),
+				slog.String("hash", headHash.String()),
 			)
 			return true
 		}
 
 		logging.Debug(logCtx, "filesOverlapWithContent: new file content mismatch (may be reverted & replaced)",
 			slog.String("file", filePath),
-			slog.String("head_hash", headFile.Hash.String()),
-			slog.String("shadow_hash", shadowFile.Hash.String()),
+			slog.String("head_hash", headHash.String()),
+			slog.String("shadow_hash", shadowHash.String()),
 		)
 	}
 
@@ -174,10 +170,7 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 
 	// Build set of filesTouched for quick lookup
-	touchedSet := make(map[string]bool)
-	for _, f := range filesTouched {
-		touchedSet[f] = true
-	}
+	touchedSet := pathKeySet(filesTouched)
 
 	// Get HEAD tree to determine if files are being modified or newly created
 	head, err := repo.Head()
@@ -219,13 +212,12 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 
 	// Check each staged file
 	for _, stagedPath := range stagedFiles {
-		if !touchedSet[stagedPath] {
+		if !touchedSet[paths.PathKey(stagedPath)] {
 			continue // Not in filesTouched, skip
 		}
 
 		// Check if this is a modified file (exists in HEAD) or new file
-		_, headErr := headTree.File(stagedPath)
-		isModified := headErr == nil
+		_, isModified := treeEntryHash(headTree, stagedPath)
 
 		// Modified files always count as overlap (user edited session's work)
 		// This includes deletions - if file exists in HEAD and is being deleted,
@@ -244,8 +236,8 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 		}
 
 		// Get file from shadow branch tree
-		shadowFile, err := shadowTree.File(stagedPath)
-		if err != nil {
+		shadowHash, inShadow := treeEntryHash(shadowTree, stagedPath)
+		if !inShadow {
 			// File not in shadow branch - can't verify content overlap.
 			// Don't assume overlap just because it's in filesTouched.
 			logging.Debug(logCtx, "stagedFilesOverlapWithContent: file not in shadow tree, skipping",
@@ -255,7 +247,7 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 		}
 
 		// Compare hashes - exact match means file is unchanged
-		if stagedHash == shadowFile.Hash {
+		if stagedHash == shadowHash {
 			logging.Debug(logCtx, "stagedFilesOverlapWithContent: new file content match found",
 				slog.String("file", stagedPath),
 				slog.String("hash", stagedHash.String()),
@@ -266,7 +258,9 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 		// Hashes differ - check if there's significant content overlap.
 		// This distinguishes partial staging (user kept some agent content) from
 		// "reverted and replaced" (user wrote completely different content).
-		shadowContent, shadowErr := shadowFile.Contents()
+		// In partial clones either blob may be missing locally; readBlobContent
+		// fetches it on demand from the promisor remote.
+		shadowContent, shadowErr := readBlobContent(context.Background(), repo, shadowHash)
 		if shadowErr != nil {
 			logging.Debug(logCtx, "stagedFilesOverlapWithContent: failed to read shadow content",
 				slog.String("file", stagedPath),
@@ -276,32 +270,14 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 		}
 
 		// Read staged content from object store
-		stagedBlob, blobErr := repo.BlobObject(stagedHash)
-		if blobErr != nil {
-			logging.Debug(logCtx, "stagedFilesOverlapWithContent: failed to read staged blob",
-				slog.String("file", stagedPath),
-				slog.String("error", blobErr.Error()),
-			)
-			continue
-		}
-		stagedReader, readerErr := stagedBlob.Reader()
-		if readerErr != nil {
-			logging.Debug(logCtx, "stagedFilesOverlapWithContent: failed to get staged reader",
-				slog.String("file", stagedPath),
-				slog.String("error", readerErr.Error()),
-			)
-			continue
-		}
-		stagedBytes, readErr := io.ReadAll(stagedReader)
-		_ = stagedReader.Close() // Best effort close
-		if readErr != nil {
+		stagedContent, stagedErr := readBlobContent(context.Background(), repo, stagedHash)
+		if stagedErr != nil {
 			logging.Debug(logCtx, "stagedFilesOverlapWithContent: failed to read staged content",
 				slog.String("file", stagedPath),
-				slog.String("error", readErr.Error()),
+				slog.String("error", stagedErr.Error()),
 			)
 			continue
 		}
-		stagedContent := string(stagedBytes)
 
 		// Check for significant content overlap
 		if hasSignificantContentOverlap(stagedContent, shadowContent) {
@@ -314,7 +290,7 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 		logging.Debug(logCtx, "stagedFilesOverlapWithContent: new file has no significant overlap (reverted & replaced)",
 			slog.String("file", stagedPath),
 			slog.String("staged_hash", stagedHash.String()),
-			slog.String("shadow_hash", shadowFile.Hash.String()),
+			slog.String("shadow_hash", shadowHash.String()),
 		)
 	}
 
@@ -328,13 +304,10 @@ func stagedFilesOverlapWithContent(repo *git.Repository, shadowTree *object.Tree
 // hasOverlappingFiles checks if any file in stagedFiles appears in filesTouched.
 // This is a fallback when content-aware comparison isn't possible.
 func hasOverlappingFiles(stagedFiles, filesTouched []string) bool {
-	touchedSet := make(map[string]bool)
-	for _, f := range filesTouched {
-		touchedSet[f] = true
-	}
+	touchedSet := pathKeySet(filesTouched)
 
 	for _, staged := range stagedFiles {
-		if touchedSet[staged] {
+		if touchedSet[paths.PathKey(staged)] {
 			return true
 		}
 	}
@@ -396,10 +369,11 @@ func filesWithRemainingAgentChanges(
 	}
 
 	var remaining []string
+	committedSet := pathKeySet(slices.Collect(maps.Keys(committedFiles)))
 
 	for _, filePath := range filesTouched {
 		// If file wasn't committed at all, it definitely has remaining changes
-		if _, wasCommitted := committedFiles[filePath]; !wasCommitted {
+		if !committedSet[paths.PathKey(filePath)] {
 			remaining = append(remaining, filePath)
 			logging.Debug(logCtx, "filesWithRemainingAgentChanges: file not committed, keeping",
 				slog.String("file", filePath),
@@ -408,8 +382,8 @@ func filesWithRemainingAgentChanges(
 		}
 
 		// File was committed - check if committed content matches shadow branch
-		shadowFile, err := shadowTree.File(filePath)
-		if err != nil {
+		shadowHash, inShadow := treeEntryHash(shadowTree, filePath)
+		if !inShadow {
 			// File not in shadow branch - nothing to carry forward for this file
 			logging.Debug(logCtx, "filesWithRemainingAgentChanges: file not in shadow branch, skipping",
 				slog.String("file", filePath),
@@ -417,8 +391,8 @@ func filesWithRemainingAgentChanges(
 			continue
 		}
 
-		commitFile, err := commitTree.File(filePath)
-		if err != nil {
+		commitHash, inCommit := treeEntryHash(commitTree, filePath)
+		if !inCommit {
 			// File not in commit tree (deleted?) - keep it if it's in shadow
 			remaining = append(remaining, filePath)
 			logging.Debug(logCtx, "filesWithRemainingAgentChanges: file not in commit tree but in shadow, keeping",
@@ -428,12 +402,12 @@ func filesWithRemainingAgentChanges(
 		}
 
 		// Compare hashes - if different, there are still uncommitted agent changes
-		if commitFile.Hash != shadowFile.Hash {
+		if commitHash != shadowHash {
 			remaining = append(remaining, filePath)
 			logging.Debug(logCtx, "filesWithRemainingAgentChanges: content mismatch, keeping for carry-forward",
 				slog.String("file", filePath),
-				slog.String("commit_hash", commitFile.Hash.String()[:7]),
-				slog.String("shadow_hash", shadowFile.Hash.String()[:7]),
+				slog.String("commit_hash", commitHash.String()[:7]),
+				slog.String("shadow_hash", shadowHash.String()[:7]),
 			)
 		} else {
 			logging.Debug(logCtx, "filesWithRemainingAgentChanges: content fully committed",
@@ -460,14 +434,25 @@ func subtractFilesByName(filesTouched []string, committedFiles map[string]struct
 		slog.Any("committedFiles", committedFiles),
 	)
 	var remaining []string
+	committedSet := pathKeySet(slices.Collect(maps.Keys(committedFiles)))
 	for _, f := range filesTouched {
-		if _, committed := committedFiles[f]; !committed {
+		if !committedSet[paths.PathKey(f)] {
 			remaining = append(remaining, f)
 		}
 	}
 	return remaining
 }
 
+// pathKeySet returns the set of files keyed by paths.PathKey, so lookups match
+// paths that differ only in spelling, such as case on macOS and Windows.
+func pathKeySet(files []string) map[string]bool {
+	set := make(map[string]bool, len(files))
+	for _, f := range files {
+		set[paths.PathKey(f)] = true
+	}
+	return set
+}
+
 // hasSignificantContentOverlap checks if two file contents share significant lines.
 // This distinguishes partial staging (user kept some agent content) from
 // "reverted and replaced" (user wrote completely different content).
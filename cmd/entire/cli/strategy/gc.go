@@ -0,0 +1,202 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ListExpiredSessionStates returns session states that were ended before
+// cutoff. Sessions that never ended are kept regardless of age.
+// When state.per_user is enabled, only the current user's sessions are
+// listed unless allUsers is set.
+func ListExpiredSessionStates(cutoff time.Time, allUsers bool) ([]CleanupItem, error) {
+	var stores []*session.StateStore
+	if allUsers {
+		all, err := session.NewAllUsersStateStores()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state stores: %w", err)
+		}
+		stores = all
+	} else {
+		store, err := session.NewStateStore()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create state store: %w", err)
+		}
+		stores = []*session.StateStore{store}
+	}
+
+	var items []CleanupItem
+	for _, store := range stores {
+		states, err := store.ListMatching(context.Background(), session.Filter{EndedBefore: cutoff})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list session states: %w", err)
+		}
+		for _, state := range states {
+			item := CleanupItem{
+				Type:   CleanupTypeSessionState,
+				ID:     state.SessionID,
+				Reason: "ended " + state.EndedAt.Format(time.RFC3339),
+			}
+			if allUsers {
+				item.StateDir = store.Dir()
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// ListUnreachableShadowBranches returns shadow branches whose base commit is
+// no longer reachable from any branch, remote-tracking branch, tag, or HEAD,
+// e.g. after the commit was amended or rebased away. Shadow branches that a
+// session of any user that hasn't ended is still using are kept.
+func ListUnreachableShadowBranches() ([]CleanupItem, error) {
+	branches, err := ListShadowBranches()
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return []CleanupItem{}, nil
+	}
+
+	inUse := make(map[string]bool)
+	if stores, storesErr := session.NewAllUsersStateStores(); storesErr == nil {
+		states, listErr := session.ListAllUsers(context.Background(), stores, session.Filter{ExcludeEnded: true})
+		if listErr != nil {
+			return nil, fmt.Errorf("failed to list session states: %w", listErr)
+		}
+		for _, state := range states {
+			inUse[checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)] = true
+		}
+	}
+
+	prefixes := make(map[string]string) // branch -> base commit prefix
+	for _, branch := range branches {
+		if inUse[branch] {
+			continue
+		}
+		if commitPrefix, _, ok := checkpoint.ParseShadowBranchName(branch); ok {
+			prefixes[branch] = commitPrefix
+		}
+	}
+	if len(prefixes) == 0 {
+		return []CleanupItem{}, nil
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	wanted := make(map[string]bool, len(prefixes))
+	for _, commitPrefix := range prefixes {
+		wanted[commitPrefix] = true
+	}
+	reachable, err := reachableCommitPrefixes(repo, wanted)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []CleanupItem{}
+	for _, branch := range branches {
+		commitPrefix, ok := prefixes[branch]
+		if !ok || reachable[commitPrefix] {
+			continue
+		}
+		items = append(items, CleanupItem{
+			Type:   CleanupTypeShadowBranch,
+			ID:     branch,
+			Reason: "base commit " + commitPrefix + " is unreachable",
+		})
+	}
+	return items, nil
+}
+
+// reachableCommitPrefixes walks history from every branch, remote-tracking
+// branch, tag, and HEAD, and returns which of the wanted commit hash prefixes
+// it found. Shadow branches and the metadata branch are not walked: they
+// never contain the commits shadow branches are based on. The walk stops as
+// soon as every prefix has been found.
+func reachableCommitPrefixes(repo *git.Repository, wanted map[string]bool) (map[string]bool, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+	var tips []plumbing.Hash
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		switch {
+		case name.IsBranch():
+			if strings.HasPrefix(name.Short(), checkpoint.ShadowBranchPrefix()) || name.Short() == paths.MetadataBranchName() {
+				return nil
+			}
+		case name.IsRemote(), name.IsTag():
+		default:
+			return nil
+		}
+		if ref.Type() == plumbing.HashReference {
+			tips = append(tips, ref.Hash())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+	if head, headErr := repo.Head(); headErr == nil {
+		tips = append(tips, head.Hash())
+	}
+
+	found := make(map[string]bool)
+	seen := make(map[plumbing.Hash]bool)
+	queue := tips
+	for len(queue) > 0 && len(found) < len(wanted) {
+		hash := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		commit, err := commitOrPeeledTag(repo, hash)
+		if err != nil {
+			continue
+		}
+		if commit.Hash != hash && seen[commit.Hash] {
+			continue
+		}
+		seen[commit.Hash] = true
+		for prefix := range wanted {
+			if strings.HasPrefix(commit.Hash.String(), prefix) {
+				found[prefix] = true
+			}
+		}
+		queue = append(queue, commit.ParentHashes...)
+	}
+	return found, nil
+}
+
+// commitOrPeeledTag returns the commit at hash, following annotated tags.
+func commitOrPeeledTag(repo *git.Repository, hash plumbing.Hash) (*object.Commit, error) {
+	commit, err := repo.CommitObject(hash)
+	if err == nil {
+		return commit, nil
+	}
+	tag, tagErr := repo.TagObject(hash)
+	if tagErr != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	commit, err = tag.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("failed to peel tag %s: %w", hash, err)
+	}
+	return commit, nil
+}
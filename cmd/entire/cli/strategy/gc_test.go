@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestListExpiredSessionStates(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	t.Chdir(dir)
+
+	now := time.Now()
+	longAgo := now.Add(-60 * 24 * time.Hour)
+	recently := now.Add(-time.Hour)
+	for _, state := range []*SessionState{
+		{SessionID: "ended-long-ago", StartedAt: longAgo, EndedAt: &longAgo},
+		{SessionID: "ended-recently", StartedAt: longAgo, EndedAt: &recently},
+		{SessionID: "never-ended", StartedAt: longAgo},
+	} {
+		if err := SaveSessionState(state); err != nil {
+			t.Fatalf("SaveSessionState() error = %v", err)
+		}
+	}
+
+	items, err := ListExpiredSessionStates(now.Add(-30*24*time.Hour), false)
+	if err != nil {
+		t.Fatalf("ListExpiredSessionStates() error = %v", err)
+	}
+	if len(items) != 1 || items[0].ID != "ended-long-ago" || items[0].Type != CleanupTypeSessionState {
+		t.Errorf("ListExpiredSessionStates() = %+v, want only ended-long-ago", items)
+	}
+}
+
+func TestListUnreachableShadowBranches(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	t.Chdir(dir)
+
+	emptyTreeHash := plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+	first, err := createCommit(repo, emptyTreeHash, plumbing.ZeroHash, "initial commit", "test", "test@test.com")
+	if err != nil {
+		t.Fatalf("failed to create initial commit: %v", err)
+	}
+	second, err := createCommit(repo, emptyTreeHash, first, "second commit", "test", "test@test.com")
+	if err != nil {
+		t.Fatalf("failed to create second commit: %v", err)
+	}
+	// Commits no ref points to, like a commit that was amended away
+	amended, err := createCommit(repo, emptyTreeHash, first, "amended away", "test", "test@test.com")
+	if err != nil {
+		t.Fatalf("failed to create amended commit: %v", err)
+	}
+	rebased, err := createCommit(repo, emptyTreeHash, first, "rebased away", "test", "test@test.com")
+	if err != nil {
+		t.Fatalf("failed to create rebased commit: %v", err)
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("master"))); err != nil {
+		t.Fatalf("failed to set HEAD: %v", err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), second)); err != nil {
+		t.Fatalf("failed to set master: %v", err)
+	}
+
+	reachable := checkpoint.ShadowBranchNameForCommit(first.String(), "")
+	unreachable := checkpoint.ShadowBranchNameForCommit(amended.String(), "")
+	inUse := checkpoint.ShadowBranchNameForCommit(rebased.String(), "")
+	for _, branch := range []string{reachable, unreachable, inUse} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), second)); err != nil {
+			t.Fatalf("failed to create shadow branch %s: %v", branch, err)
+		}
+	}
+
+	// A session that hasn't ended keeps its shadow branch even if the base is gone
+	if err := SaveSessionState(&SessionState{SessionID: "active-session", BaseCommit: rebased.String(), StartedAt: time.Now()}); err != nil {
+		t.Fatalf("SaveSessionState() error = %v", err)
+	}
+
+	items, err := ListUnreachableShadowBranches()
+	if err != nil {
+		t.Fatalf("ListUnreachableShadowBranches() error = %v", err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	if !slices.Equal(ids, []string{unreachable}) {
+		t.Errorf("ListUnreachableShadowBranches() = %q, want [%s]", ids, unreachable)
+	}
+}
@@ -0,0 +1,575 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"gopkg.in/yaml.v3"
+)
+
+// When a repository uses Husky, Lefthook, or the pre-commit framework, the
+// hook manager owns the hooks directory and rewrites it when reinstalled. So
+// instead of writing scripts there, Entire adds its hooks to the manager's own
+// configuration, where they survive reinstalls, and removes them again on
+// uninstall.
+
+// managedEntryComment marks the entries Entire adds to a hook manager's
+// configuration.
+const managedEntryComment = "# " + entireHookMarker + " (removed by entire disable --uninstall)"
+
+// managedCommandLine returns the command line of a hook spec for a hook
+// manager's configuration. The configuration is shared with teammates who may
+// not have Entire installed, so the command is skipped when its binary is not
+// on PATH instead of failing the hook.
+func managedCommandLine(spec hookSpec) string {
+	command := extractCommandLine(spec.content)
+	bin, _, _ := strings.Cut(command, " ")
+	return "if command -v " + bin + " >/dev/null 2>&1; then " + command + "; fi"
+}
+
+// lefthookCommandName is the name of the command Entire adds to each Lefthook hook.
+const lefthookCommandName = "entire"
+
+// preCommitHookIDPrefix starts the IDs of the hooks Entire adds to the
+// pre-commit framework's local repo entry.
+const preCommitHookIDPrefix = "entire-"
+
+// hookManagerIntegration adds Entire's hooks to a hook manager's configuration.
+type hookManagerIntegration struct {
+	name string
+	// configPath returns the configuration to edit, relative to the
+	// repository root, or "" if the manager is not used.
+	configPath func(repoRoot string) string
+	// add writes entries for the given hooks and reports whether anything changed.
+	add func(path string, specs []hookSpec) (bool, error)
+	// remove deletes Entire's entries and returns the number removed.
+	remove func(path string) (int, error)
+	// installed returns the managed hooks that have an entry.
+	installed func(path string) []string
+	// installArgs is the command that makes the manager install git hooks for
+	// the hooks Entire added, if it only installs the hooks it is configured for.
+	installArgs []string
+}
+
+// hookManagerIntegrations lists the supported hook managers, in the order
+// they are preferred when a repository configures more than one.
+var hookManagerIntegrations = []hookManagerIntegration{
+	{
+		name: "Husky",
+		configPath: func(repoRoot string) string {
+			if info, err := os.Stat(filepath.Join(repoRoot, ".husky")); err == nil && info.IsDir() {
+				return ".husky"
+			}
+			return ""
+		},
+		add:       addHuskyEntries,
+		remove:    removeHuskyEntries,
+		installed: huskyEntries,
+	},
+	{
+		name: "Lefthook",
+		configPath: func(repoRoot string) string {
+			return firstExisting(repoRoot, "lefthook.yml", "lefthook.yaml", ".lefthook.yml", ".lefthook.yaml")
+		},
+		add:         addLefthookEntries,
+		remove:      removeLefthookEntries,
+		installed:   lefthookEntries,
+		installArgs: []string{"lefthook", "install"},
+	},
+	{
+		name: "pre-commit",
+		configPath: func(repoRoot string) string {
+			return firstExisting(repoRoot, ".pre-commit-config.yaml")
+		},
+		add:       addPreCommitEntries,
+		remove:    removePreCommitEntries,
+		installed: preCommitEntries,
+		installArgs: []string{
+			"pre-commit", "install",
+			"--hook-type", "prepare-commit-msg", "--hook-type", "commit-msg",
+			"--hook-type", "post-commit", "--hook-type", "pre-push",
+			"--hook-type", "post-rewrite",
+		},
+	},
+}
+
+// activeHookManager returns the hook manager Entire's hooks are added to in
+// repoRoot and the absolute path of its configuration, or nil if the
+// repository uses none of the supported managers.
+func activeHookManager(repoRoot string) (*hookManagerIntegration, string) {
+	for i := range hookManagerIntegrations {
+		m := &hookManagerIntegrations[i]
+		if rel := m.configPath(repoRoot); rel != "" {
+			return m, filepath.Join(repoRoot, rel)
+		}
+	}
+	return nil, ""
+}
+
+// hookManagerHooksInstalled reports whether every managed hook has an entry in
+// the configuration of the hook manager used in repoRoot.
+func hookManagerHooksInstalled(repoRoot string) bool {
+	m, path := activeHookManager(repoRoot)
+	if m == nil {
+		return false
+	}
+	installed := m.installed(path)
+	for _, hook := range gitHookNames {
+		if !slices.Contains(installed, hook) {
+			return false
+		}
+	}
+	return true
+}
+
+// installHookManagerEntries adds Entire's hooks to the hook manager's
+// configuration and removes Entire's scripts from hooksDir, so the hooks run
+// once. Returns the number of hooks added or updated.
+func installHookManagerEntries(m *hookManagerIntegration, configPath, hooksDir, cmdPrefix string, silent bool) (int, error) {
+	if _, err := removeScriptHooks(hooksDir); err != nil {
+		return 0, err
+	}
+	changed, err := m.add(configPath, buildHookSpecs(cmdPrefix))
+	if err != nil {
+		return 0, fmt.Errorf("failed to add hooks to %s: %w", configPath, err)
+	}
+	if !changed {
+		return 0, nil
+	}
+
+	if len(m.installArgs) > 0 {
+		cmd := exec.CommandContext(context.Background(), m.installArgs[0], m.installArgs[1:]...) //nolint:gosec // fixed install command
+		cmd.Dir = filepath.Dir(configPath)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Run '%s' so %s installs Entire's git hooks\n", strings.Join(m.installArgs, " "), m.name)
+			if len(output) > 0 && !errors.Is(err, exec.ErrNotFound) {
+				fmt.Fprintf(os.Stderr, "  %s\n", strings.TrimSpace(string(output)))
+			}
+		}
+	}
+
+	if !silent {
+		fmt.Printf("✓ Added git hooks to %s (%s)\n", m.name, configPath)
+	}
+	return len(gitHookNames), nil
+}
+
+// removeHookManagerEntries removes Entire's hooks from the configuration of
+// every supported hook manager used in the repository. Returns the number of
+// entries removed.
+func removeHookManagerEntries() (int, error) {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return 0, nil //nolint:nilerr // not in a worktree, nothing to remove
+	}
+	removed := 0
+	for _, m := range hookManagerIntegrations {
+		rel := m.configPath(repoRoot)
+		if rel == "" {
+			continue
+		}
+		path := filepath.Join(repoRoot, rel)
+		n, err := m.remove(path)
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove hooks from %s: %w", path, err)
+		}
+		removed += n
+	}
+	return removed, nil
+}
+
+// firstExisting returns the first of names that exists in dir, or "".
+func firstExisting(dir string, names ...string) string {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Husky runs each hook file in .husky/ with sh, so Entire appends its command
+// line, preceded by managedEntryComment, to the file.
+
+func addHuskyEntries(dir string, specs []hookSpec) (bool, error) {
+	changed := false
+	for _, spec := range specs {
+		path := filepath.Join(dir, spec.name)
+		data, err := os.ReadFile(path) //nolint:gosec // path is a hook file in .husky
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return changed, err //nolint:wrapcheck // wrapped by caller
+		}
+		content := stripHuskyEntry(string(data))
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += managedEntryComment + "\n" + managedCommandLine(spec) + "\n"
+		if content == string(data) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0o755); err != nil { //nolint:gosec // Git hooks require executable permissions
+			return changed, err //nolint:wrapcheck // wrapped by caller
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+func removeHuskyEntries(dir string) (int, error) {
+	removed := 0
+	for _, hook := range huskyEntries(dir) {
+		path := filepath.Join(dir, hook)
+		data, err := os.ReadFile(path) //nolint:gosec // path is a hook file in .husky
+		if err != nil {
+			return removed, err //nolint:wrapcheck // wrapped by caller
+		}
+		content := stripHuskyEntry(string(data))
+		if strings.TrimSpace(content) == "" {
+			err = os.Remove(path)
+		} else {
+			err = os.WriteFile(path, []byte(content), 0o755) //nolint:gosec // Git hooks require executable permissions
+		}
+		if err != nil {
+			return removed, err //nolint:wrapcheck // wrapped by caller
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func huskyEntries(dir string) []string {
+	var hooks []string
+	for _, hook := range gitHookNames {
+		data, err := os.ReadFile(filepath.Join(dir, hook)) //nolint:gosec // path is a hook file in .husky
+		if err == nil && strings.Contains(string(data), managedEntryComment) {
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+// stripHuskyEntry removes managedEntryComment and the command line after it.
+func stripHuskyEntry(content string) string {
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if strings.TrimRight(line, "\r\n") == managedEntryComment {
+			end := min(i+2, len(lines))
+			return strings.Join(slices.Delete(lines, i, end), "")
+		}
+	}
+	return content
+}
+
+// Lefthook is configured per hook; Entire adds a command named
+// lefthookCommandName to each managed hook. Lefthook substitutes the hook's
+// arguments for {1} and {2}, and passes on git's stdin with use_stdin.
+
+func addLefthookEntries(path string, specs []hookSpec) (bool, error) {
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for _, spec := range specs {
+		run := strings.NewReplacer("$1", "{1}", "$2", "{2}").Replace(managedCommandLine(spec))
+		useStdin := spec.name == "post-rewrite"
+		hook := yamlMappingChild(root, spec.name)
+		commands := yamlMappingChild(hook, "commands")
+		if command := yamlMappingValue(commands, lefthookCommandName); command != nil {
+			current, stdin := yamlMappingValue(command, "run"), yamlMappingValue(command, "use_stdin")
+			if current != nil && current.Value == run && (stdin != nil && stdin.Value == "true") == useStdin {
+				continue
+			}
+		}
+		if useStdin {
+			yamlSetMappingValue(commands, lefthookCommandName, yamlMapping("run", run, "use_stdin", "true"))
+		} else {
+			yamlSetMappingValue(commands, lefthookCommandName, yamlMapping("run", run))
+		}
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, writeYAML(path, root)
+}
+
+func removeLefthookEntries(path string) (int, error) {
+	hooks := lefthookEntries(path)
+	if len(hooks) == 0 {
+		return 0, nil
+	}
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, name := range hooks {
+		hook := yamlMappingValue(root, name)
+		commands := yamlMappingValue(hook, "commands")
+		yamlDeleteMappingKey(commands, lefthookCommandName)
+		if len(commands.Content) == 0 {
+			yamlDeleteMappingKey(hook, "commands")
+		}
+		if len(hook.Content) == 0 {
+			yamlDeleteMappingKey(root, name)
+		}
+	}
+	return len(hooks), writeYAML(path, root)
+}
+
+func lefthookEntries(path string) []string {
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return nil
+	}
+	var hooks []string
+	for _, name := range gitHookNames {
+		command := yamlMappingValue(yamlMappingValue(yamlMappingValue(root, name), "commands"), lefthookCommandName)
+		if run := yamlMappingValue(command, "run"); run != nil && strings.Contains(run.Value, " hooks git "+name) {
+			hooks = append(hooks, name)
+		}
+	}
+	return hooks
+}
+
+// The pre-commit framework runs hooks from repos listed in its config; Entire
+// adds one local repo holding a hook per managed git hook. The framework
+// passes the message file for commit-msg and prepare-commit-msg as the
+// hook's argument, and the rest of the git hook's arguments in environment
+// variables. It does not pass on post-rewrite's stdin, so rebased commits
+// are not linked to their originals' checkpoints there.
+
+func addPreCommitEntries(path string, specs []hookSpec) (bool, error) {
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return false, err
+	}
+	want := preCommitRepo(specs)
+
+	repos := yamlMappingValue(root, "repos")
+	if repos == nil || repos.Kind != yaml.SequenceNode {
+		repos = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		yamlSetMappingValue(root, "repos", repos)
+	}
+	if i := preCommitRepoIndex(repos); i >= 0 {
+		if yamlString(repos.Content[i]) == yamlString(want) {
+			return false, nil
+		}
+		repos.Content[i] = want
+	} else {
+		repos.Content = append(repos.Content, want)
+	}
+	return true, writeYAML(path, root)
+}
+
+func removePreCommitEntries(path string) (int, error) {
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return 0, err
+	}
+	repos := yamlMappingValue(root, "repos")
+	i := preCommitRepoIndex(repos)
+	if i < 0 {
+		return 0, nil
+	}
+	removed := len(preCommitEntries(path))
+	repos.Content = slices.Delete(repos.Content, i, i+1)
+	return removed, writeYAML(path, root)
+}
+
+func preCommitEntries(path string) []string {
+	root, err := readYAMLMapping(path)
+	if err != nil {
+		return nil
+	}
+	repos := yamlMappingValue(root, "repos")
+	i := preCommitRepoIndex(repos)
+	if i < 0 {
+		return nil
+	}
+	var hooks []string
+	for _, hook := range yamlMappingValue(repos.Content[i], "hooks").Content {
+		if id := yamlMappingValue(hook, "id"); id != nil {
+			hooks = append(hooks, strings.TrimPrefix(id.Value, preCommitHookIDPrefix))
+		}
+	}
+	return hooks
+}
+
+// preCommitRepo builds the local repo entry holding Entire's hooks.
+func preCommitRepo(specs []hookSpec) *yaml.Node {
+	hooks := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, spec := range specs {
+		command := managedCommandLine(spec)
+		passFilenames := "true"
+		switch spec.name {
+		case "prepare-commit-msg":
+			command = strings.Replace(command, "$2", "$PRE_COMMIT_COMMIT_MSG_SOURCE", 1)
+		case "pre-push":
+			command = strings.Replace(command, "$1", "$PRE_COMMIT_REMOTE_NAME", 1)
+			passFilenames = "false"
+		case "post-rewrite":
+			command = strings.Replace(command, "$1", "$PRE_COMMIT_REWRITE_COMMAND", 1)
+			passFilenames = "false"
+		case "post-commit":
+			passFilenames = "false"
+		}
+		hook := yamlMapping(
+			"id", preCommitHookIDPrefix+spec.name,
+			"name", "Entire "+spec.name,
+			"entry", "sh -c '"+command+"' --",
+			"language", "system",
+			"always_run", "true",
+			"pass_filenames", passFilenames,
+		)
+		yamlSetMappingValue(hook, "stages", &yaml.Node{
+			Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle,
+			Content: []*yaml.Node{yamlScalar(spec.name)},
+		})
+		hooks.Content = append(hooks.Content, hook)
+	}
+	repo := yamlMapping("repo", "local")
+	repo.HeadComment = managedEntryComment
+	yamlSetMappingValue(repo, "hooks", hooks)
+	return repo
+}
+
+// preCommitRepoIndex returns the index of Entire's local repo entry in repos,
+// or -1.
+func preCommitRepoIndex(repos *yaml.Node) int {
+	if repos == nil || repos.Kind != yaml.SequenceNode {
+		return -1
+	}
+	for i, repo := range repos.Content {
+		if r := yamlMappingValue(repo, "repo"); r == nil || r.Value != "local" {
+			continue
+		}
+		hooks := yamlMappingValue(repo, "hooks")
+		if hooks == nil || len(hooks.Content) == 0 {
+			continue
+		}
+		ours := true
+		for _, hook := range hooks.Content {
+			if id := yamlMappingValue(hook, "id"); id == nil || !strings.HasPrefix(id.Value, preCommitHookIDPrefix) {
+				ours = false
+			}
+		}
+		if ours {
+			return i
+		}
+	}
+	return -1
+}
+
+// readYAMLMapping parses the YAML file at path, whose top level must be a
+// mapping. An empty file yields an empty mapping.
+func readYAMLMapping(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the hook manager's config file
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by caller
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("top level is not a mapping")
+	}
+	return doc.Content[0], nil
+}
+
+// writeYAML writes root to path with two-space indentation.
+func writeYAML(path string, root *yaml.Node) error {
+	if err := os.WriteFile(path, []byte(yamlString(root)), 0o644); err != nil { //nolint:gosec // config file is committed to the repository
+		return err //nolint:wrapcheck // wrapped by caller
+	}
+	return nil
+}
+
+// yamlString encodes node as YAML with two-space indentation.
+func yamlString(node *yaml.Node) string {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	_ = enc.Encode(node) //nolint:errcheck // encoding a node tree cannot fail
+	_ = enc.Close()      //nolint:errcheck // flushes to a buffer
+	return buf.String()
+}
+
+// yamlMappingValue returns the value of key in mapping m, or nil.
+func yamlMappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlMappingChild returns the mapping stored under key in m, replacing a
+// missing or non-mapping value with an empty mapping.
+func yamlMappingChild(m *yaml.Node, key string) *yaml.Node {
+	if child := yamlMappingValue(m, key); child != nil && child.Kind == yaml.MappingNode {
+		return child
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	yamlSetMappingValue(m, key, child)
+	return child
+}
+
+// yamlSetMappingValue sets key in mapping m to value, adding the key if needed.
+func yamlSetMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, yamlScalar(key), value)
+}
+
+// yamlDeleteMappingKey removes key from mapping m.
+func yamlDeleteMappingKey(m *yaml.Node, key string) {
+	if m == nil {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = slices.Delete(m.Content, i, i+2)
+			return
+		}
+	}
+}
+
+// yamlMapping builds a mapping from alternating keys and scalar values.
+// "true" and "false" are written as booleans.
+func yamlMapping(keysAndValues ...string) *yaml.Node {
+	m := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		value := yamlScalar(keysAndValues[i+1])
+		if value.Value == "true" || value.Value == "false" {
+			value.Tag = "!!bool"
+		}
+		m.Content = append(m.Content, yamlScalar(keysAndValues[i]), value)
+	}
+	return m
+}
+
+func yamlScalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,206 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallGitHook_Husky(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, hooksDir := initHooksTestRepo(t)
+	huskyDir := filepath.Join(tmpDir, ".husky")
+	require.NoError(t, os.MkdirAll(huskyDir, 0o755))
+	userHook := "npx --no -- commitlint --edit $1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(huskyDir, "commit-msg"), []byte(userHook), 0o755))
+
+	count, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.Equal(t, len(gitHookNames), count)
+
+	data, err := os.ReadFile(filepath.Join(huskyDir, "commit-msg"))
+	require.NoError(t, err)
+	assert.Equal(t, userHook+managedEntryComment+"\nif command -v entire >/dev/null 2>&1; then entire hooks git commit-msg \"$1\" || exit 1; fi\n", string(data))
+	assert.ElementsMatch(t, gitHookNames, huskyEntries(huskyDir))
+	for _, hook := range gitHookNames {
+		_, err := os.Stat(filepath.Join(hooksDir, hook))
+		assert.True(t, os.IsNotExist(err), "no script is installed for %s", hook)
+	}
+	assert.True(t, IsGitHookInstalled())
+	problems, err := GitHookProblems(false)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	count, err = InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count, "reinstalling changes nothing")
+
+	removed, err := RemoveGitHook()
+	require.NoError(t, err)
+	assert.Equal(t, len(gitHookNames), removed)
+	data, err = os.ReadFile(filepath.Join(huskyDir, "commit-msg"))
+	require.NoError(t, err)
+	assert.Equal(t, userHook, string(data), "the user's hook is left as it was")
+	_, err = os.Stat(filepath.Join(huskyDir, "post-commit"))
+	assert.True(t, os.IsNotExist(err), "hook files Entire created are deleted")
+}
+
+func TestInstallGitHook_HuskyReplacesScripts(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, hooksDir := initHooksTestRepo(t)
+
+	// Scripts from before Husky was set up are removed once its hooks take over
+	_, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(hooksDir, "post-commit"))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".husky"), 0o755))
+	_, err = InstallGitHook(true, false)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(hooksDir, "post-commit"))
+	assert.True(t, os.IsNotExist(err))
+	assert.True(t, IsGitHookInstalledInDir(tmpDir))
+}
+
+func TestInstallGitHook_Lefthook(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, _ := initHooksTestRepo(t)
+	configPath := filepath.Join(tmpDir, "lefthook.yml")
+	original := `# Team hooks
+pre-commit:
+  commands:
+    lint:
+      run: make lint
+commit-msg:
+  commands:
+    check:
+      run: ./scripts/check-msg {1}
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o644))
+
+	_, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, gitHookNames, lefthookEntries(configPath))
+
+	root, err := readYAMLMapping(configPath)
+	require.NoError(t, err)
+	commitMsg := yamlMappingValue(yamlMappingValue(root, "commit-msg"), "commands")
+	assert.NotNil(t, yamlMappingValue(commitMsg, "check"), "existing commands are kept")
+	run := yamlMappingValue(yamlMappingValue(commitMsg, lefthookCommandName), "run")
+	require.NotNil(t, run)
+	assert.Equal(t, `if command -v entire >/dev/null 2>&1; then entire hooks git commit-msg "{1}" || exit 1; fi`, run.Value)
+	postRewrite := yamlMappingValue(yamlMappingValue(yamlMappingValue(root, "post-rewrite"), "commands"), lefthookCommandName)
+	useStdin := yamlMappingValue(postRewrite, "use_stdin")
+	require.NotNil(t, useStdin, "post-rewrite reads the rewritten commits from stdin")
+	assert.Equal(t, "true", useStdin.Value)
+
+	written, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	_, err = InstallGitHook(true, false)
+	require.NoError(t, err)
+	again, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(written), string(again), "reinstalling does not rewrite the file")
+
+	_, err = RemoveGitHook()
+	require.NoError(t, err)
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestInstallGitHook_PreCommit(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, _ := initHooksTestRepo(t)
+	configPath := filepath.Join(tmpDir, ".pre-commit-config.yaml")
+	original := `repos:
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v4.6.0
+    hooks:
+      - id: trailing-whitespace
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0o644))
+
+	_, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, gitHookNames, preCommitEntries(configPath))
+	assert.True(t, IsGitHookInstalled())
+
+	root, err := readYAMLMapping(configPath)
+	require.NoError(t, err)
+	repos := yamlMappingValue(root, "repos")
+	require.Len(t, repos.Content, 2)
+	i := preCommitRepoIndex(repos)
+	require.Equal(t, 1, i)
+	for _, hook := range yamlMappingValue(repos.Content[i], "hooks").Content {
+		if yamlMappingValue(hook, "id").Value == "entire-pre-push" {
+			assert.Equal(t, `sh -c 'if command -v entire >/dev/null 2>&1; then entire hooks git pre-push "$PRE_COMMIT_REMOTE_NAME" || true; fi' --`, yamlMappingValue(hook, "entry").Value)
+		}
+	}
+
+	changed, err := addPreCommitEntries(configPath, buildHookSpecs("entire"))
+	require.NoError(t, err)
+	assert.False(t, changed, "entries already up to date")
+
+	removed, err := RemoveGitHook()
+	require.NoError(t, err)
+	assert.Equal(t, len(gitHookNames), removed)
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestStripHuskyEntry(t *testing.T) {
+	t.Parallel()
+	entry := managedEntryComment + "\nentire hooks git post-commit 2>/dev/null || true\n"
+	assert.Equal(t, "npm test\n", stripHuskyEntry("npm test\n"+entry))
+	assert.Equal(t, "npm test\nnpm run lint\n", stripHuskyEntry("npm test\n"+entry+"npm run lint\n"))
+	assert.Empty(t, stripHuskyEntry(entry))
+}
+
+// TestManagedEntries_EntireNotOnPath verifies that the entries added to a hook
+// manager's configuration, which teammates share, let git continue when
+// entire is not installed, and still fail commit-msg when entire does.
+func TestManagedEntries_EntireNotOnPath(t *testing.T) {
+	t.Parallel()
+	huskyDir := t.TempDir()
+	_, err := addHuskyEntries(huskyDir, buildHookSpecs("entire"))
+	require.NoError(t, err)
+	msgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(msgFile, []byte("msg\n"), 0o644))
+
+	runHooks := func(pathDir string) map[string]error {
+		results := make(map[string]error)
+		for _, hook := range gitHookNames {
+			// Husky runs hook files with sh -e
+			cmd := exec.CommandContext(context.Background(), "/bin/sh", "-e", filepath.Join(huskyDir, hook), msgFile)
+			cmd.Env = []string{"PATH=" + pathDir}
+			results["husky "+hook] = cmd.Run()
+		}
+		for _, hook := range yamlMappingValue(preCommitRepo(buildHookSpecs("entire")), "hooks").Content {
+			entry := yamlMappingValue(hook, "entry").Value
+			script := strings.TrimSuffix(strings.TrimPrefix(entry, "sh -c '"), "' --")
+			cmd := exec.CommandContext(context.Background(), "/bin/sh", "-c", script, "--", msgFile)
+			cmd.Env = []string{"PATH=" + pathDir}
+			results["pre-commit "+yamlMappingValue(hook, "id").Value] = cmd.Run()
+		}
+		return results
+	}
+
+	for name, err := range runHooks(t.TempDir()) {
+		assert.NoError(t, err, "%s must succeed without entire on PATH", name)
+	}
+
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "entire"), []byte("#!/bin/sh\nexit 1\n"), 0o755))
+	results := runHooks(binDir)
+	assert.Error(t, results["husky commit-msg"], "a failing commit-msg still blocks the commit")
+	assert.Error(t, results["pre-commit entire-commit-msg"], "a failing commit-msg still blocks the commit")
+	assert.NoError(t, results["husky post-commit"])
+}
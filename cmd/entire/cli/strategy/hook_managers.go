@@ -15,6 +15,7 @@ type hookManager struct {
 	Name            string // "Husky", "Lefthook", "pre-commit", "Overcommit"
 	ConfigPath      string // relative path that triggered detection (e.g., ".husky/")
 	OverwritesHooks bool   // true if the tool will overwrite Entire's hooks on reinstall
+	Integrated      bool   // true if Entire's hooks are in the tool's configuration
 }
 
 // detectHookManagers checks the repository root for known hook manager config
@@ -23,9 +24,9 @@ func detectHookManagers(repoRoot string) []hookManager {
 	var managers []hookManager
 
 	checks := []hookManager{
-		{"Husky", ".husky/", true},
-		{"pre-commit", ".pre-commit-config.yaml", false},
-		{"Overcommit", ".overcommit.yml", false},
+		{Name: "Husky", ConfigPath: ".husky/", OverwritesHooks: true},
+		{Name: "pre-commit", ConfigPath: ".pre-commit-config.yaml"},
+		{Name: "Overcommit", ConfigPath: ".overcommit.yml"},
 	}
 
 	// Lefthook supports {.,}lefthook{,-local}.{yml,yaml,json,toml}
@@ -33,7 +34,7 @@ func detectHookManagers(repoRoot string) []hookManager {
 		for _, variant := range []string{"", "-local"} {
 			for _, ext := range []string{"yml", "yaml", "json", "toml"} {
 				name := prefix + "lefthook" + variant + "." + ext
-				checks = append(checks, hookManager{"Lefthook", name, false})
+				checks = append(checks, hookManager{Name: "Lefthook", ConfigPath: name})
 			}
 		}
 	}
@@ -65,6 +66,14 @@ func hookManagerWarning(managers []hookManager, cmdPrefix string) string {
 	specs := buildHookSpecs(cmdPrefix)
 
 	for _, m := range managers {
+		if m.Integrated {
+			fmt.Fprintf(&b, "Note: %s detected (%s)\n", m.Name, m.ConfigPath)
+			fmt.Fprintf(&b, "\n")
+			fmt.Fprintf(&b, "  Entire's git hooks were added to %s's configuration, so reinstalling %s keeps them.\n", m.Name, m.Name)
+			fmt.Fprintf(&b, "  Commit the change to share them; 'entire disable --uninstall' removes them.\n")
+			fmt.Fprintf(&b, "\n")
+			continue
+		}
 		if m.OverwritesHooks {
 			fmt.Fprintf(&b, "Warning: %s detected (%s)\n", m.Name, m.ConfigPath)
 			fmt.Fprintf(&b, "\n")
@@ -122,6 +131,11 @@ func CheckAndWarnHookManagers(w io.Writer, localDev bool) {
 	if len(managers) == 0 {
 		return
 	}
+	if active, configPath := activeHookManager(repoRoot); active != nil && len(active.installed(configPath)) > 0 {
+		for i := range managers {
+			managers[i].Integrated = managers[i].Name == active.name
+		}
+	}
 
 	warning := hookManagerWarning(managers, hookCmdPrefix(localDev))
 	if warning != "" {
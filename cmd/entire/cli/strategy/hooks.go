@@ -7,9 +7,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 )
 
@@ -20,7 +23,7 @@ const backupSuffix = ".pre-entire"
 const chainComment = "# Chain: run pre-existing hook"
 
 // gitHookNames are the git hooks managed by Entire CLI
-var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push"}
+var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push", "post-rewrite"}
 
 // ManagedGitHookNames returns the list of git hooks managed by Entire CLI.
 // This is useful for tests that need to manipulate hooks.
@@ -89,6 +92,10 @@ func ClearHooksDirCache() {
 	hooksDirMu.Unlock()
 }
 
+func init() {
+	repocache.Register(ClearHooksDirCache)
+}
+
 // getGitDirInPath returns the git directory for a repository at the given path.
 // It delegates to `git rev-parse --git-dir` to leverage git's own validation.
 func getGitDirInPath(dir string) (string, error) {
@@ -132,13 +139,18 @@ func getHooksDirInPath(dir string) (string, error) {
 	return filepath.Clean(hooksDir), nil
 }
 
-// IsGitHookInstalled checks if all generic Entire CLI hooks are installed.
+// IsGitHookInstalled checks if all generic Entire CLI hooks are installed,
+// either as hooks or in the configuration of the repository's hook manager.
 func IsGitHookInstalled() bool {
 	hooksDir, err := GetHooksDir()
 	if err != nil {
 		return false
 	}
-	return isGitHookInstalledInHooksDir(hooksDir)
+	if isGitHookInstalledInHooksDir(hooksDir) {
+		return true
+	}
+	repoRoot, err := paths.WorktreeRoot()
+	return err == nil && hookManagerHooksInstalled(repoRoot)
 }
 
 // IsGitHookInstalledInDir checks if all Entire CLI hooks are installed in the given repo directory.
@@ -148,13 +160,19 @@ func IsGitHookInstalledInDir(repoDir string) bool {
 	if err != nil {
 		return false
 	}
-	return isGitHookInstalledInHooksDir(hooksDir)
+	return isGitHookInstalledInHooksDir(hooksDir) || hookManagerHooksInstalled(repoDir)
 }
 
 // isGitHookInstalledInHooksDir checks if all hooks are installed in the given hooks directory.
 func isGitHookInstalledInHooksDir(hooksDir string) bool {
 	for _, hook := range gitHookNames {
 		hookPath := filepath.Join(hooksDir, hook)
+		if isDispatcherHooksDir(hooksDir) {
+			if dispatcherHookProblem(hook, hookPath) != "" {
+				return false
+			}
+			continue
+		}
 		data, err := os.ReadFile(hookPath) //nolint:gosec // Path is constructed from constants
 		if err != nil {
 			return false
@@ -166,6 +184,67 @@ func isGitHookInstalledInHooksDir(hooksDir string) bool {
 	return true
 }
 
+// GitHookProblems describes each managed git hook that is missing, was
+// replaced by another tool, is not executable, or runs a different command
+// than Entire installs. Returns nil when all hooks are as installed.
+func GitHookProblems(localDev bool) ([]string, error) {
+	hooksDir, err := GetHooksDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	if dispatcher := isDispatcherHooksDir(hooksDir); dispatcher != hooksDispatcherRequested(localDev) {
+		if dispatcher {
+			problems = append(problems, "git hooks use the dispatcher but strategy_options.hooks_dispatcher is not enabled")
+		} else {
+			problems = append(problems, "git hooks are shell scripts but strategy_options.hooks_dispatcher is enabled")
+		}
+	}
+	if isDispatcherHooksDir(hooksDir) {
+		for _, hook := range gitHookNames {
+			if problem := dispatcherHookProblem(hook, filepath.Join(hooksDir, hook)); problem != "" {
+				problems = append(problems, problem)
+			}
+		}
+		return problems, nil
+	}
+	if repoRoot, err := paths.WorktreeRoot(); err == nil {
+		if m, configPath := activeHookManager(repoRoot); m != nil && len(m.installed(configPath)) > 0 {
+			installed := m.installed(configPath)
+			for _, hook := range gitHookNames {
+				if !slices.Contains(installed, hook) {
+					problems = append(problems, fmt.Sprintf("%s is missing from %s", hook, configPath))
+				}
+			}
+			return problems, nil
+		}
+	}
+	for _, spec := range buildHookSpecs(hookCmdPrefix(localDev)) {
+		hookPath := filepath.Join(hooksDir, spec.name)
+		info, err := os.Stat(hookPath)
+		if err != nil {
+			problems = append(problems, spec.name+" is not installed")
+			continue
+		}
+		data, err := os.ReadFile(hookPath) //nolint:gosec // Path is constructed from constants
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s cannot be read: %v", spec.name, err))
+			continue
+		}
+		content := string(data)
+		switch {
+		case !strings.Contains(content, entireHookMarker):
+			problems = append(problems, spec.name+" was replaced by another hook")
+		case info.Mode().Perm()&0o111 == 0:
+			problems = append(problems, spec.name+" is not executable")
+		case content != spec.content && content != generateChainedContent(spec.content, spec.name):
+			problems = append(problems, spec.name+" runs a different command than this version installs")
+		}
+	}
+	return problems, nil
+}
+
 // buildHookSpecs returns the hook specifications for all managed hooks.
 func buildHookSpecs(cmdPrefix string) []hookSpec {
 	return []hookSpec{
@@ -199,6 +278,15 @@ func buildHookSpecs(cmdPrefix string) []hookSpec {
 # Pre-push hook: push session logs alongside user's push
 # $1 is the remote name (e.g., "origin")
 %s hooks git pre-push "$1" || true
+`, entireHookMarker, cmdPrefix),
+		},
+		{
+			name: "post-rewrite",
+			content: fmt.Sprintf(`#!/bin/sh
+# %s
+# Post-rewrite hook: link rebased commits to their originals' checkpoints
+# $1 is "rebase" or "amend"; the rewritten commits are read from stdin
+%s hooks git post-rewrite "$1" 2>/dev/null || true
 `, entireHookMarker, cmdPrefix),
 		},
 	}
@@ -209,12 +297,34 @@ func buildHookSpecs(cmdPrefix string) []hookSpec {
 // If silent is true, no output is printed (except backup notifications, which always print).
 // localDev controls whether hooks use "go run" (true) or the "entire" binary (false).
 // Returns the number of hooks that were installed (0 if all already up to date).
+// With strategy_options.hooks_dispatcher, the hooks dispatcher is installed
+// instead of scripts (see hooks_dispatcher.go). In a repository using Husky,
+// Lefthook, or pre-commit, the hooks are added to its configuration instead
+// (see hook_manager_integration.go).
 func InstallGitHook(silent bool, localDev bool) (int, error) {
+	if hooksDispatcherRequested(localDev) {
+		// The dispatcher chains to the hook manager, which must not run Entire's hooks again
+		if _, err := removeHookManagerEntries(); err != nil {
+			return 0, err
+		}
+		return installDispatcherHooks(silent)
+	}
+	// Switching back from the dispatcher restores the previous hooks directory
+	if _, err := removeDispatcherHooks(); err != nil {
+		return 0, err
+	}
+
 	hooksDir, err := GetHooksDir()
 	if err != nil {
 		return 0, err
 	}
 
+	if repoRoot, err := paths.WorktreeRoot(); err == nil {
+		if m, configPath := activeHookManager(repoRoot); m != nil {
+			return installHookManagerEntries(m, configPath, hooksDir, hookCmdPrefix(localDev), silent)
+		}
+	}
+
 	if err := os.MkdirAll(hooksDir, 0o755); err != nil { //nolint:gosec // Git hooks require executable permissions
 		return 0, fmt.Errorf("failed to create hooks directory: %w", err)
 	}
@@ -281,14 +391,32 @@ func writeHookFile(path, content string) (bool, error) {
 }
 
 // RemoveGitHook removes all Entire CLI git hooks from the repository.
-// If a .pre-entire backup exists, it is restored.
+// If a .pre-entire backup exists, it is restored. If the hooks dispatcher is
+// installed, the previous core.hooksPath is restored. Entries added to a hook
+// manager's configuration are removed.
 // Returns the number of hooks removed.
 func RemoveGitHook() (int, error) {
+	removed, err := removeDispatcherHooks()
+	if err != nil {
+		return removed, err
+	}
+	entries, err := removeHookManagerEntries()
+	removed += entries
+	if err != nil {
+		return removed, err
+	}
+
 	hooksDir, err := GetHooksDir()
 	if err != nil {
-		return 0, err
+		return removed, err
 	}
+	scripts, err := removeScriptHooks(hooksDir)
+	return removed + scripts, err
+}
 
+// removeScriptHooks removes Entire's hook scripts from hooksDir, restoring
+// .pre-entire backups. Returns the number of hooks removed.
+func removeScriptHooks(hooksDir string) (int, error) {
 	removed := 0
 	var removeErrors []string
 
@@ -298,7 +426,9 @@ func RemoveGitHook() (int, error) {
 
 		// Remove the hook if it contains our marker
 		data, err := os.ReadFile(hookPath) //nolint:gosec // path is controlled
-		hookIsOurs := err == nil && strings.Contains(string(data), entireHookMarker)
+		// A hook manager's hook file holding Entire's entry is the user's, not ours
+		hookIsOurs := err == nil && strings.Contains(string(data), entireHookMarker) &&
+			!strings.Contains(string(data), managedEntryComment)
 		hookExists := err == nil
 
 		if hookIsOurs {
@@ -330,13 +460,22 @@ func RemoveGitHook() (int, error) {
 
 // generateChainedContent appends a chain call to the base hook content,
 // so the pre-existing hook (backed up to .pre-entire) is called after our hook.
+// post-rewrite's stdin, the list of rewritten commits, is read once into a
+// variable and passed to both.
 func generateChainedContent(baseContent, hookName string) string {
+	chainCall := `"$_entire_hook_dir/` + hookName + backupSuffix + `" "$@"`
+	if hookName == "post-rewrite" {
+		replay := `printf '%s\n' "$_entire_stdin" | `
+		cmdLine := extractCommandLine(baseContent)
+		baseContent = strings.Replace(baseContent, cmdLine, `_entire_stdin="$(cat)"`+"\n"+replay+cmdLine, 1)
+		chainCall = replay + chainCall
+	}
 	return baseContent + fmt.Sprintf(`%s
 _entire_hook_dir="$(dirname "$0")"
 if [ -x "$_entire_hook_dir/%s%s" ]; then
-    "$_entire_hook_dir/%s%s" "$@"
+    %s
 fi
-`, chainComment, hookName, backupSuffix, hookName, backupSuffix)
+`, chainComment, hookName, backupSuffix, chainCall)
 }
 
 // hookCmdPrefix returns the command prefix for hook scripts and warning messages.
@@ -0,0 +1,293 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// With strategy_options.hooks_dispatcher, Entire does not write shell scripts
+// into the hooks directory. Instead it points core.hooksPath at a directory it
+// owns, .git/entire/hooks, whose hooks are symlinks to the entire binary. When
+// git runs one, the binary sees the hook's name as its program name and
+// handles the hook in Go (see cli.RunDispatchedGitHook), then runs the hook of
+// the same name from the directory that was active before, so hooks managed
+// by husky, lefthook, or by hand keep working.
+
+const (
+	// hooksChainDirKey is the git config key recording the hooks directory
+	// that was active before the dispatcher was installed. The dispatcher
+	// runs hooks from it after its own.
+	hooksChainDirKey = "entire.hooksChainDir"
+	// hooksPathPreviousKey records the repository's own core.hooksPath
+	// before the dispatcher was installed ("" = unset), to restore it on
+	// uninstall.
+	hooksPathPreviousKey = "entire.hooksPathPrevious"
+)
+
+// allGitHookNames lists the hooks git runs from the hooks directory. The
+// dispatcher links each of them that the previous hooks directory provides,
+// so those hooks still run.
+var allGitHookNames = []string{
+	"applypatch-msg", "pre-applypatch", "post-applypatch",
+	"pre-commit", "pre-merge-commit", "prepare-commit-msg", "commit-msg", "post-commit",
+	"pre-rebase", "post-checkout", "post-merge", "pre-push", "post-rewrite",
+	"pre-auto-gc", "reference-transaction", "post-index-change",
+	"push-to-checkout", "sendemail-validate", "fsmonitor-watchman",
+	"pre-receive", "update", "proc-receive", "post-receive", "post-update",
+}
+
+// IsGitHookName reports whether name is a hook git runs.
+func IsGitHookName(name string) bool {
+	return slices.Contains(allGitHookNames, name)
+}
+
+// IsManagedGitHook reports whether Entire handles the named hook itself,
+// rather than only chaining it.
+func IsManagedGitHook(name string) bool {
+	return slices.Contains(gitHookNames, name)
+}
+
+// DispatcherHooksDir returns the directory core.hooksPath points at when the
+// hooks dispatcher is installed: .git/entire/hooks in the git common dir.
+func DispatcherHooksDir() (string, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	dir, err := filepath.Abs(filepath.Join(commonDir, "entire", "hooks"))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve hooks directory: %w", err)
+	}
+	return dir, nil
+}
+
+// isDispatcherHooksDir reports whether hooksDir is the dispatcher's
+// directory, judged by its location so no git subprocess is needed.
+func isDispatcherHooksDir(hooksDir string) bool {
+	return filepath.Base(hooksDir) == "hooks" && filepath.Base(filepath.Dir(hooksDir)) == "entire"
+}
+
+// hooksDispatcherRequested reports whether settings ask for the dispatcher.
+// It is never used with local_dev, whose hooks run `go run`.
+func hooksDispatcherRequested(localDev bool) bool {
+	if localDev {
+		return false
+	}
+	s, err := settings.Load()
+	return err == nil && s.IsHooksDispatcherEnabled()
+}
+
+// hooksModeMismatch reports whether the installed hooks are scripts while the
+// dispatcher is requested, or the other way around.
+func hooksModeMismatch() bool {
+	hooksDir, err := GetHooksDir()
+	if err != nil {
+		return false
+	}
+	return isDispatcherHooksDir(hooksDir) != hooksDispatcherRequested(isLocalDev())
+}
+
+// HookChainDir returns the hooks directory the dispatcher chains to, or ""
+// if the dispatcher is not installed.
+func HookChainDir() string {
+	return gitConfigGet(context.Background(), ".", hooksChainDirKey)
+}
+
+// dispatcherExecutable returns the path the dispatcher's hooks link to:
+// entire as found on PATH, which survives upgrades that move the binary,
+// or else the running binary.
+func dispatcherExecutable() (string, error) {
+	if path, err := exec.LookPath("entire"); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs, nil
+		}
+	}
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the entire binary: %w", err)
+	}
+	return path, nil
+}
+
+// installDispatcherHooks points core.hooksPath at the dispatcher directory,
+// removing Entire's hook scripts from the previous hooks directory first.
+// Returns the number of hook links created or updated.
+func installDispatcherHooks(silent bool) (int, error) {
+	ctx := context.Background()
+	dispatcherDir, err := DispatcherHooksDir()
+	if err != nil {
+		return 0, err
+	}
+	chainDir := HookChainDir()
+	if chainDir == "" {
+		// First install: remember what to chain to and what to restore
+		previousDir, err := GetHooksDir()
+		if err != nil {
+			return 0, err
+		}
+		previous := gitConfigGetLocal(ctx, "core.hooksPath")
+		if isDispatcherHooksDir(previousDir) {
+			// Left over from an install whose config was lost; never chain to itself
+			previousDir, previous = filepath.Join(filepath.Dir(filepath.Dir(dispatcherDir)), "hooks"), ""
+		}
+		if _, err := removeScriptHooks(previousDir); err != nil {
+			return 0, err
+		}
+		if previousDir, err = filepath.Abs(previousDir); err != nil {
+			return 0, fmt.Errorf("failed to resolve hooks directory: %w", err)
+		}
+		if err := gitConfigSet(ctx, hooksPathPreviousKey, previous); err != nil {
+			return 0, err
+		}
+		if err := gitConfigSet(ctx, hooksChainDirKey, previousDir); err != nil {
+			return 0, err
+		}
+		chainDir = previousDir
+	}
+
+	exe, err := dispatcherExecutable()
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dispatcherDir, 0o755); err != nil { //nolint:gosec // Git hooks require executable permissions
+		return 0, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+
+	installed := 0
+	for _, name := range allGitHookNames {
+		linkPath := filepath.Join(dispatcherDir, name)
+		if !IsManagedGitHook(name) && !isExecutableFile(filepath.Join(chainDir, name)) {
+			// Nothing to run; don't make git start the binary for it
+			if err := os.Remove(linkPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return installed, fmt.Errorf("failed to remove %s hook: %w", name, err)
+			}
+			continue
+		}
+		if target, err := os.Readlink(linkPath); err == nil && target == exe {
+			continue
+		}
+		_ = os.Remove(linkPath)
+		if err := os.Symlink(exe, linkPath); err != nil {
+			return installed, fmt.Errorf("failed to install %s hook: %w", name, err)
+		}
+		installed++
+	}
+
+	if err := gitConfigSet(ctx, "core.hooksPath", dispatcherDir); err != nil {
+		return installed, err
+	}
+	ClearHooksDirCache()
+
+	if !silent {
+		fmt.Println("✓ Installed git hooks dispatcher (core.hooksPath=" + dispatcherDir + ")")
+		fmt.Println("  Hooks run in the entire binary and then chain to " + chainDir)
+	}
+	return installed, nil
+}
+
+// removeDispatcherHooks restores the previous core.hooksPath and deletes the
+// dispatcher directory. Returns the number of Entire hooks removed, or 0 if
+// the dispatcher is not installed.
+func removeDispatcherHooks() (int, error) {
+	ctx := context.Background()
+	if HookChainDir() == "" {
+		return 0, nil
+	}
+	dispatcherDir, err := DispatcherHooksDir()
+	if err != nil {
+		return 0, err
+	}
+
+	previous := gitConfigGetLocal(ctx, hooksPathPreviousKey)
+	if previous == "" {
+		err = gitConfigUnset(ctx, "core.hooksPath")
+	} else {
+		err = gitConfigSet(ctx, "core.hooksPath", previous)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err := gitConfigUnset(ctx, hooksChainDirKey); err != nil {
+		return 0, err
+	}
+	if err := gitConfigUnset(ctx, hooksPathPreviousKey); err != nil {
+		return 0, err
+	}
+	ClearHooksDirCache()
+
+	if err := os.RemoveAll(dispatcherDir); err != nil {
+		return 0, fmt.Errorf("failed to remove hooks directory: %w", err)
+	}
+	return len(gitHookNames), nil
+}
+
+// dispatcherHookProblem describes what is wrong with a dispatcher hook link,
+// or returns "" if it links to an executable.
+func dispatcherHookProblem(name, linkPath string) string {
+	if _, err := os.Readlink(linkPath); err != nil {
+		if _, statErr := os.Lstat(linkPath); statErr != nil {
+			return name + " is not installed"
+		}
+		return name + " was replaced by another hook"
+	}
+	if !isExecutableFile(linkPath) {
+		return name + " links to a missing or non-executable entire binary"
+	}
+	return ""
+}
+
+// IsEntireHookScript reports whether the hook at path is one of Entire's hook
+// scripts.
+func IsEntireHookScript(path string) bool {
+	data, err := os.ReadFile(path) //nolint:gosec // path is a hook in a hooks directory
+	return err == nil && strings.Contains(string(data), entireHookMarker)
+}
+
+// isExecutableFile reports whether path (following links) is an executable
+// regular file.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
+
+// gitConfigGetLocal returns the value of a key in the repository's own
+// config, or "" if unset.
+func gitConfigGetLocal(ctx context.Context, key string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--get", key) //nolint:gosec // key is a constant config key
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// gitConfigSet sets a key in the repository's own config.
+func gitConfigSet(ctx context.Context, key, value string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", key, value) //nolint:gosec // key is a constant config key
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set %s: %s: %w", key, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// gitConfigUnset removes a key from the repository's own config. A key that
+// is already unset is not an error.
+func gitConfigUnset(ctx context.Context, key string) error {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--unset", key) //nolint:gosec // key is a constant config key
+	if output, err := cmd.CombinedOutput(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 5 {
+			return nil // git exits 5 when the key is not set
+		}
+		return fmt.Errorf("failed to unset %s: %s: %w", key, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
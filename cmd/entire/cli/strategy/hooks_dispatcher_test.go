@@ -0,0 +1,136 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHooksDispatcherSetting(t *testing.T, dir string, enabled bool) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	content := `{"enabled": true, "strategy_options": {"hooks_dispatcher": false}}`
+	if enabled {
+		content = `{"enabled": true, "strategy_options": {"hooks_dispatcher": true}}`
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(content), 0o644))
+}
+
+func localHooksPath(t *testing.T, dir string) string {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", "config", "--local", "--get", "core.hooksPath")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+func TestInstallGitHook_Dispatcher(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, hooksDir := initHooksTestRepo(t)
+	t.Cleanup(ClearHooksDirCache)
+
+	// Scripts first, over a hook the user already had
+	require.NoError(t, os.MkdirAll(hooksDir, 0o755))
+	userHook := "#!/bin/sh\necho user hook\n"
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "commit-msg"), []byte(userHook), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(hooksDir, "pre-commit"), []byte(userHook), 0o755))
+	_, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+
+	writeHooksDispatcherSetting(t, tmpDir, true)
+	assert.True(t, hooksModeMismatch())
+	_, err = InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.False(t, hooksModeMismatch())
+
+	dispatcherDir, err := DispatcherHooksDir()
+	require.NoError(t, err)
+	assert.Equal(t, dispatcherDir, localHooksPath(t, tmpDir))
+	assert.Equal(t, evalSymlinks(t, hooksDir), evalSymlinks(t, HookChainDir()))
+	assert.True(t, IsGitHookInstalled())
+	problems, err := GitHookProblems(false)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	// Entire's scripts are gone and the user's hook is back, to be chained to
+	data, err := os.ReadFile(filepath.Join(hooksDir, "commit-msg"))
+	require.NoError(t, err)
+	assert.Equal(t, userHook, string(data))
+	for _, hook := range gitHookNames {
+		assert.False(t, IsEntireHookScript(filepath.Join(hooksDir, hook)), hook)
+	}
+
+	// Managed hooks and the user's other hooks are linked; the rest are not
+	for _, hook := range append([]string{"pre-commit"}, gitHookNames...) {
+		_, err := os.Readlink(filepath.Join(dispatcherDir, hook))
+		require.NoError(t, err, hook)
+	}
+	_, err = os.Lstat(filepath.Join(dispatcherDir, "post-merge"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Reinstalling changes nothing
+	count, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// Switching back restores the hooks directory and installs scripts again
+	writeHooksDispatcherSetting(t, tmpDir, false)
+	assert.True(t, hooksModeMismatch())
+	_, err = InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.Empty(t, localHooksPath(t, tmpDir))
+	assert.Empty(t, HookChainDir())
+	assert.True(t, IsEntireHookScript(filepath.Join(hooksDir, "commit-msg")))
+	_, err = os.Stat(dispatcherDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRemoveGitHook_DispatcherRestoresHooksPath(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir, _ := initHooksTestRepo(t)
+	t.Cleanup(ClearHooksDirCache)
+
+	customHooks := filepath.Join(tmpDir, "custom-hooks")
+	require.NoError(t, os.MkdirAll(customHooks, 0o755))
+	cmd := exec.CommandContext(context.Background(), "git", "config", "core.hooksPath", "custom-hooks")
+	cmd.Dir = tmpDir
+	require.NoError(t, cmd.Run())
+	ClearHooksDirCache()
+
+	writeHooksDispatcherSetting(t, tmpDir, true)
+	_, err := InstallGitHook(true, false)
+	require.NoError(t, err)
+	assert.Equal(t, evalSymlinks(t, customHooks), evalSymlinks(t, HookChainDir()))
+
+	removed, err := RemoveGitHook()
+	require.NoError(t, err)
+	assert.Equal(t, len(gitHookNames), removed)
+	assert.Equal(t, "custom-hooks", localHooksPath(t, tmpDir))
+	assert.Empty(t, HookChainDir())
+	assert.False(t, IsGitHookInstalled())
+}
+
+func TestIsGitHookName(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsGitHookName("pre-commit"))
+	assert.True(t, IsManagedGitHook("commit-msg"))
+	assert.False(t, IsManagedGitHook("pre-commit"))
+	assert.False(t, IsGitHookName("entire"))
+}
+
+// evalSymlinks resolves path so temp directories compare equal on macOS.
+func evalSymlinks(t *testing.T, path string) string {
+	t.Helper()
+	resolved, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	return resolved
+}
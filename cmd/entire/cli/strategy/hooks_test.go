@@ -1119,6 +1119,50 @@ func TestGenerateChainedContent(t *testing.T) {
 	}
 }
 
+// TestInstallGitHook_ChainedPostRewriteGetsStdin verifies that a chained
+// post-rewrite hook still receives the rewritten commits on stdin after
+// Entire's command has read them.
+func TestInstallGitHook_ChainedPostRewriteGetsStdin(t *testing.T) {
+	tmpDir, hooksDir := initHooksTestRepo(t)
+
+	received := filepath.Join(tmpDir, "received")
+	userHook := "#!/bin/sh\ncat > '" + received + "'\n"
+	hookPath := filepath.Join(hooksDir, "post-rewrite")
+	if err := os.WriteFile(hookPath, []byte(userHook), 0o755); err != nil {
+		t.Fatalf("failed to create custom hook: %v", err)
+	}
+	if _, err := InstallGitHook(true, false); err != nil {
+		t.Fatalf("InstallGitHook() error = %v", err)
+	}
+
+	// A stand-in for entire that reads stdin the way the real hook does
+	binDir := t.TempDir()
+	entireRead := filepath.Join(tmpDir, "entire-read")
+	fakeEntire := "#!/bin/sh\ncat > '" + entireRead + "'\n"
+	if err := os.WriteFile(filepath.Join(binDir, "entire"), []byte(fakeEntire), 0o755); err != nil {
+		t.Fatalf("failed to create fake entire: %v", err)
+	}
+
+	input := strings.Repeat("a", 40) + " " + strings.Repeat("b", 40) + "\n" +
+		strings.Repeat("c", 40) + " " + strings.Repeat("d", 40) + "\n"
+	cmd := exec.CommandContext(context.Background(), hookPath, "rebase")
+	cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	cmd.Stdin = strings.NewReader(input)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("post-rewrite hook failed: %v\n%s", err, output)
+	}
+
+	for name, path := range map[string]string{"entire": entireRead, "chained hook": received} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s did not run: %v", name, err)
+		}
+		if string(data) != input {
+			t.Errorf("%s read %q from stdin, want %q", name, string(data), input)
+		}
+	}
+}
+
 func TestInstallGitHook_InstallRemoveReinstall(t *testing.T) {
 	_, hooksDir := initHooksTestRepo(t)
 
@@ -0,0 +1,95 @@
+package strategy
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/entireignore"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// FilterIgnoredPaths removes the paths Entire does not track: untracked files
+// git ignores (.gitignore, .git/info/exclude, core.excludesFile) and files
+// matching the worktree's .entireignore. Agents report the files they wrote
+// whether or not git ignores them, so build artifacts would otherwise end up
+// in FilesTouched and on the shadow branch. Fails open: a check that cannot
+// run is skipped.
+func FilterIgnoredPaths(repo *git.Repository, files []string) []string {
+	return FilterIgnoredPathLists(repo, files)[0]
+}
+
+// FilterIgnoredPathLists is FilterIgnoredPaths for several lists at once. It
+// runs `git check-ignore` once over all of them and returns the filtered
+// lists in the order given.
+func FilterIgnoredPathLists(repo *git.Repository, lists ...[]string) [][]string {
+	var all []string
+	for _, files := range lists {
+		all = append(all, files...)
+	}
+	if len(all) == 0 {
+		return lists
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return lists
+	}
+	repoRoot := wt.Filesystem.Root()
+	gitIgnored := gitIgnoredPaths(repoRoot, all)
+	ignore, err := entireignore.Load(repoRoot)
+	if err != nil {
+		ignore = nil
+	}
+	if len(gitIgnored) == 0 && ignore == nil {
+		return lists
+	}
+
+	filtered := make([][]string, len(lists))
+	for i, files := range lists {
+		kept := make([]string, 0, len(files))
+		for _, file := range files {
+			if _, ok := gitIgnored[file]; ok || ignore.Match(file) {
+				continue
+			}
+			kept = append(kept, file)
+		}
+		filtered[i] = kept
+	}
+	return filtered
+}
+
+// gitIgnoredPaths returns the files among files that git ignores, according
+// to `git check-ignore`, which never reports tracked files.
+func gitIgnoredPaths(repoRoot string, files []string) map[string]struct{} {
+	cmd := exec.CommandContext(context.Background(), "git", "check-ignore", "--stdin", "-z")
+	cmd.Dir = repoRoot
+	cmd.Stdin = strings.NewReader(strings.Join(files, "\x00") + "\x00")
+	// Exit status 1 means no file is ignored; other failures fail open
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	ignored := make(map[string]struct{})
+	for _, file := range strings.Split(string(output), "\x00") {
+		if file != "" {
+			ignored[file] = struct{}{}
+		}
+	}
+	return ignored
+}
+
+// loadEntireIgnore returns the current worktree's .entireignore matcher, or
+// nil if there is none or it cannot be read.
+func loadEntireIgnore() *entireignore.Matcher {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil
+	}
+	ignore, err := entireignore.Load(repoRoot)
+	if err != nil {
+		return nil
+	}
+	return ignore
+}
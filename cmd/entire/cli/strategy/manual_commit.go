@@ -3,9 +3,14 @@ package strategy
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
 	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
 )
 
 // ManualCommitStrategy implements the manual-commit strategy for session management.
@@ -25,6 +30,37 @@ type ManualCommitStrategy struct {
 	checkpointStoreOnce sync.Once
 	// checkpointStoreErr captures any error during initialization
 	checkpointStoreErr error
+
+	// clock timestamps session state and checkpoints. nil = clock.System.
+	clock clock.Clock
+	// ids generates checkpoint and turn IDs. nil = id.Random.
+	ids id.Generator
+}
+
+// SetClock sets the time source for session state and checkpoint timestamps.
+// Call it before the strategy is used; stores already created keep their clock.
+func (s *ManualCommitStrategy) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// SetIDGenerator sets the generator for checkpoint and turn IDs.
+func (s *ManualCommitStrategy) SetIDGenerator(g id.Generator) {
+	s.ids = g
+}
+
+func (s *ManualCommitStrategy) now() time.Time {
+	return clock.OrSystem(s.clock).Now()
+}
+
+func (s *ManualCommitStrategy) generateID() (id.CheckpointID, error) {
+	return id.OrRandom(s.ids).Generate() //nolint:wrapcheck // callers add context
+}
+
+// newGitStore creates a checkpoint store that uses the strategy's clock.
+func (s *ManualCommitStrategy) newGitStore(repo *git.Repository) *checkpoint.GitStore {
+	store := checkpoint.NewGitStore(repo)
+	store.SetClock(s.clock)
+	return store
 }
 
 // getStateStore returns the session state store, initializing it lazily if needed.
@@ -36,6 +72,7 @@ func (s *ManualCommitStrategy) getStateStore() (*session.StateStore, error) {
 			s.stateStoreErr = fmt.Errorf("failed to create state store: %w", err)
 			return
 		}
+		store.SetClock(s.clock)
 		s.stateStore = store
 	})
 	return s.stateStore, s.stateStoreErr
@@ -50,7 +87,7 @@ func (s *ManualCommitStrategy) getCheckpointStore() (*checkpoint.GitStore, error
 			s.checkpointStoreErr = fmt.Errorf("failed to open repository: %w", err)
 			return
 		}
-		s.checkpointStore = checkpoint.NewGitStore(repo)
+		s.checkpointStore = s.newGitStore(repo)
 	})
 	return s.checkpointStore, s.checkpointStoreErr
 }
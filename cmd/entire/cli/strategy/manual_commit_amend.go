@@ -0,0 +1,20 @@
+package strategy
+
+import "strings"
+
+// fixupSubjectPrefixes are the subject prefixes git gives commits made with
+// `git commit --fixup` and `--squash`, which `git rebase --autosquash` folds
+// into an earlier commit.
+var fixupSubjectPrefixes = []string{"fixup! ", "squash! ", "amend! "}
+
+// isFixupCommitMessage reports whether message is that of a fixup or squash
+// commit. Such commits are folded into a commit that already has its own
+// checkpoint, so they are not given one.
+func isFixupCommitMessage(message string) bool {
+	for _, prefix := range fixupSubjectPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
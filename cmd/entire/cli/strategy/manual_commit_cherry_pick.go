@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+)
+
+// revertedCommitRegex matches the line git adds to the message of a commit
+// made by `git revert`.
+var revertedCommitRegex = regexp.MustCompile(`(?m)^This reverts commit [0-9a-f]{40}\.?$`)
+
+// isRevertCommitMessage reports whether message is that of a commit made by
+// `git revert`. A revert undoes a commit rather than adding the session's
+// work, so it is not given a checkpoint.
+func isRevertCommitMessage(message string) bool {
+	return revertedCommitRegex.MatchString(message)
+}
+
+// isCherryPickInProgress reports whether the commit being made is picked by
+// `git cherry-pick`. Git keeps CHERRY_PICK_HEAD while it commits each pick,
+// but a rebase, which may use it too, rewrites commits instead of copying
+// them, so it is excluded.
+func isCherryPickInProgress() bool {
+	gitDir, err := GetGitDir()
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "CHERRY_PICK_HEAD")); err != nil {
+		return false
+	}
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(gitDir, dir)); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// prepareCherryPickCommitMsg prepares the message of a cherry-picked commit.
+//
+// Git copies the picked commit's message, Entire-Checkpoint trailer and all,
+// which would link the copy to a checkpoint condensed for the original. The
+// trailers are turned into Entire-Cherry-Picked-Checkpoint trailers instead,
+// recording where the commit came from without PostCommit condensing into
+// them.
+func prepareCherryPickCommitMsg(logCtx context.Context, commitMsgFile string) {
+	content, err := os.ReadFile(commitMsgFile) //nolint:gosec // commitMsgFile is provided by git hook
+	if err != nil {
+		return
+	}
+	message := renameCheckpointTrailers(string(content), trailers.ParseAllCheckpoints(string(content)), trailers.CherryPickedCheckpointTrailerKey)
+	if message == string(content) {
+		return
+	}
+	if err := os.WriteFile(commitMsgFile, []byte(message), 0o600); err != nil {
+		return
+	}
+	logging.Info(logCtx, "prepare-commit-msg: marked cherry-picked checkpoints",
+		slog.String("strategy", "manual-commit"),
+		slog.Int("checkpoints", len(trailers.ParseCherryPickedCheckpoints(message))),
+	)
+}
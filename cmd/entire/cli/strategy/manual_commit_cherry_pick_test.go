@@ -0,0 +1,43 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrepareCommitMsg_CherryPickMarksCopiedTrailers(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	pickedMsg := "Add a\n\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Ticket: ENT-7\n"
+	prepare := func() string {
+		t.Helper()
+		commitMsgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		require.NoError(t, os.WriteFile(commitMsgFile, []byte(pickedMsg), 0o644))
+		s := &ManualCommitStrategy{}
+		require.NoError(t, s.PrepareCommitMsg(commitMsgFile, "message"))
+		content, err := os.ReadFile(commitMsgFile)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	cherryPickHead := filepath.Join(dir, ".git", "CHERRY_PICK_HEAD")
+	require.NoError(t, os.WriteFile(cherryPickHead, []byte("1111111111111111111111111111111111111111\n"), 0o644))
+
+	message := prepare()
+	_, found := trailers.ParseCheckpoint(message)
+	assert.False(t, found, "the copy must not claim the original's checkpoint")
+	assert.Equal(t, []id.CheckpointID{"a1b2c3d4e5f6"}, trailers.ParseCherryPickedCheckpoints(message))
+	assert.Contains(t, message, "Entire-Ticket: ENT-7\n")
+
+	// A rebase keeps its commits' trailers; post-rewrite remaps them
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git", "rebase-merge"), 0o755))
+	assert.Equal(t, pickedMsg, prepare())
+}
@@ -0,0 +1,64 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/compliance"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// runComplianceScan runs the user's configured license scanner
+// (compliance_scan.command) against the session's touched files and records
+// the report in state.LastComplianceScan. Does nothing when no command is
+// configured or no touched file still exists; failures are recorded in the
+// report and never block the turn end.
+func runComplianceScan(state *SessionState) {
+	s, err := settings.Load()
+	if err != nil || s.ComplianceScanCommand() == "" {
+		return
+	}
+	logCtx := logging.WithComponent(context.Background(), "compliance-scan")
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		logging.Warn(logCtx, "compliance scan skipped: failed to find repository root",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	files := existingTouchedFiles(repoRoot, state)
+	if len(files) == 0 {
+		return
+	}
+
+	runner := &compliance.Runner{
+		Command: s.ComplianceScanCommand(),
+		Dir:     repoRoot,
+		Timeout: s.ComplianceScan.Timeout(),
+	}
+	report, err := runner.Run(logCtx, files)
+	if err != nil {
+		logging.Warn(logCtx, "compliance scan command could not run",
+			slog.String("session_id", state.SessionID),
+			slog.String("error", err.Error()))
+		return
+	}
+	state.LastComplianceScan = report
+
+	logging.Info(logCtx, "compliance scan recorded",
+		slog.String("session_id", state.SessionID),
+		slog.Int("findings", len(report.Findings)),
+		slog.String("error", report.Error),
+		slog.Int("files", len(files)))
+	switch {
+	case report.Error != "":
+		fmt.Fprintf(os.Stderr, "[entire] Warning: compliance scan failed: %s\n", report.Error)
+	case len(report.Findings) > 0:
+		fmt.Fprintf(os.Stderr, "[entire] Compliance scan: %s\n", report.Summary())
+	}
+}
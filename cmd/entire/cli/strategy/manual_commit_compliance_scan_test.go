@@ -0,0 +1,31 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunComplianceScan(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// No scan configured: nothing is recorded.
+	state := &SessionState{SessionID: "s1", FilesTouched: []string{"test.txt", "deleted.txt"}}
+	runComplianceScan(state)
+	assert.Nil(t, state.LastComplianceScan)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	settingsJSON := `{"enabled": true, "compliance_scan": {"command": "for f in \"$@\"; do echo \"{\\\"kind\\\": \\\"license_header\\\", \\\"file\\\": \\\"$f\\\"}\"; done"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settingsJSON), 0o644))
+
+	runComplianceScan(state)
+	require.NotNil(t, state.LastComplianceScan)
+	assert.Empty(t, state.LastComplianceScan.Error)
+	assert.Equal(t, []string{"test.txt"}, state.LastComplianceScan.Files, "deleted files are not scanned")
+	require.Len(t, state.LastComplianceScan.Findings, 1)
+	assert.Equal(t, "test.txt", state.LastComplianceScan.Findings[0].File)
+}
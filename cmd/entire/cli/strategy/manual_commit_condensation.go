@@ -8,13 +8,18 @@ import (
 	"log/slog"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/agent/opencode"
 	cpkg "github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/features"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
@@ -23,6 +28,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/summarize"
 	"github.com/entireio/cli/cmd/entire/cli/textutil"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
+	"github.com/entireio/cli/cmd/entire/cli/transcriptcache"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -183,6 +189,10 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		}
 	}
 
+	// Files outside track_paths or matching ignore_paths, and ignored files,
+	// are not condensed, including ones only found in the transcript or the commit
+	sessionData.FilesTouched = FilterIgnoredPaths(repo, filterTrackedPaths(loadPathScope(), sessionData.FilesTouched))
+
 	// Get checkpoint store
 	store, err := s.getCheckpointStore()
 	if err != nil {
@@ -194,7 +204,7 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 	// Calculate attribution. When no shadow branch exists (agent committed mid-turn
 	// before SaveStep), pass nil ref — the function uses HEAD as the shadow tree
 	// since the agent's commit IS HEAD (no user edits between agent work and commit).
-	attribution := calculateSessionAttributions(repo, ref, sessionData, state)
+	attribution := calculateSessionAttributions(repo, ref, sessionData, state, s.now())
 	// Get current branch name
 	branchName := GetCurrentBranchName(repo)
 
@@ -205,7 +215,7 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		summarizeCtx := logging.WithComponent(logCtx, "summarize")
 
 		// Scope transcript to this checkpoint's portion.
-		// For Claude Code (JSONL), CheckpointTranscriptStart is a line offset.
+		// For Claude Code, Cursor (JSONL), and Aider (markdown), CheckpointTranscriptStart is a line offset.
 		// For Gemini/OpenCode (JSON), CheckpointTranscriptStart is a message index.
 		var scopedTranscript []byte
 		switch state.AgentType {
@@ -225,7 +235,7 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 					slog.String("error", sliceErr.Error()))
 			}
 			scopedTranscript = scoped
-		case agent.AgentTypeClaudeCode, agent.AgentTypeUnknown:
+		case agent.AgentTypeClaudeCode, agent.AgentTypeCursor, agent.AgentTypeAider, agent.AgentTypeCodex, agent.AgentTypeUnknown:
 			scopedTranscript = transcript.SliceFromLine(sessionData.Transcript, state.CheckpointTranscriptStart)
 		}
 		if len(scopedTranscript) > 0 {
@@ -243,13 +253,28 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		}
 	}
 
+	// Run the configured review command, if any (non-blocking)
+	machineReview := reviewCondensedCommit(checkpointID, state.SessionID, sessionData.FilesTouched, committedFiles, sessionData.Prompts)
+
+	if len(state.TornSnapshotFiles) > 0 {
+		logging.Warn(logging.WithComponent(context.Background(), "checkpoint"), "condensing steps with unverified file snapshots",
+			slog.String("session_id", state.SessionID),
+			slog.String("checkpoint_id", checkpointID.String()),
+			slog.Any("files", state.TornSnapshotFiles),
+		)
+	}
+
+	// Filter the stored transcript last, so the summary and token usage above
+	// still see everything the agent recorded.
+	storedTranscript, storedStart, filterStats := filterTranscriptForStorage(state.AgentType, sessionData.Transcript, state.CheckpointTranscriptStart)
+
 	// Write checkpoint metadata using the checkpoint store
 	if err := store.WriteCommitted(context.Background(), cpkg.WriteCommittedOptions{
 		CheckpointID:                checkpointID,
 		SessionID:                   state.SessionID,
 		Strategy:                    StrategyNameManualCommit,
 		Branch:                      branchName,
-		Transcript:                  sessionData.Transcript,
+		Transcript:                  storedTranscript,
 		Prompts:                     sessionData.Prompts,
 		Context:                     sessionData.Context,
 		FilesTouched:                sessionData.FilesTouched,
@@ -260,10 +285,20 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		Agent:                       state.AgentType,
 		TurnID:                      state.TurnID,
 		TranscriptIdentifierAtStart: state.TranscriptIdentifierAtStart,
-		CheckpointTranscriptStart:   state.CheckpointTranscriptStart,
+		CheckpointTranscriptStart:   storedStart,
 		TokenUsage:                  sessionData.TokenUsage,
 		InitialAttribution:          attribution,
 		Summary:                     summary,
+		Review:                      machineReview,
+		QualityGate:                 state.LastQualityGate,
+		ComplianceScan:              state.LastComplianceScan,
+		TranscriptFilter:            filterStats,
+		CompressTranscript:          compressTranscriptForStorage(len(storedTranscript)),
+		ContentDefinedChunks:        features.Enabled(features.ContentDefinedChunks),
+		TornSnapshotFiles:           state.TornSnapshotFiles,
+		OmittedFiles:                state.OmittedFiles,
+		Tickets:                     sessionTickets(state, branchName),
+		ParentSessionID:             state.ParentSessionID,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to write checkpoint metadata: %w", err)
 	}
@@ -274,10 +309,11 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		CheckpointsCount:     state.StepCount,
 		FilesTouched:         sessionData.FilesTouched,
 		TotalTranscriptLines: sessionData.FullTranscriptLines,
+		TokenUsage:           sessionData.TokenUsage,
 	}, nil
 }
 
-func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Reference, sessionData *ExtractedSessionData, state *SessionState) *cpkg.InitialAttribution {
+func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Reference, sessionData *ExtractedSessionData, state *SessionState, now time.Time) *cpkg.InitialAttribution {
 	// Calculate initial attribution using accumulated prompt attribution data.
 	// This uses user edits captured at each prompt start (before agent works),
 	// plus any user edits after the final checkpoint (shadow → head).
@@ -374,6 +410,7 @@ func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Refe
 	)
 
 	if attribution != nil {
+		attribution.CalculatedAt = now.UTC()
 		logging.Info(logCtx, "attribution calculated",
 			slog.Int("agent_lines", attribution.AgentLines),
 			slog.Int("human_added", attribution.HumanAdded),
@@ -422,7 +459,7 @@ func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRe
 		if isActive {
 			prepareTranscriptIfNeeded(agentType, liveTranscriptPath)
 		}
-		if liveData, readErr := os.ReadFile(liveTranscriptPath); readErr == nil && len(liveData) > 0 { //nolint:gosec // path from session state
+		if liveData, readErr := readLiveTranscript(sessionID, liveTranscriptPath); readErr == nil && len(liveData) > 0 {
 			fullTranscript = string(liveData)
 		}
 	}
@@ -458,6 +495,24 @@ func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRe
 	return data, nil
 }
 
+// readLiveTranscript reads the agent's live transcript. Transcripts outside the
+// repository are read through the local transcript cache, which retries slow
+// reads and falls back to the last cached copy.
+func readLiveTranscript(sessionID, path string) ([]byte, error) {
+	if cache, ok := transcriptcache.ForTranscript(path); ok {
+		data, err := cache.Read(context.Background(), sessionID, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path from session state
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return data, nil
+}
+
 // extractSessionDataFromLiveTranscript extracts session data directly from the live transcript file.
 // This is used for mid-session commits where no shadow branch exists yet.
 func (s *ManualCommitStrategy) extractSessionDataFromLiveTranscript(state *SessionState) (*ExtractedSessionData, error) {
@@ -468,7 +523,7 @@ func (s *ManualCommitStrategy) extractSessionDataFromLiveTranscript(state *Sessi
 		return nil, errors.New("no transcript path in session state")
 	}
 
-	liveData, err := os.ReadFile(state.TranscriptPath)
+	liveData, err := readLiveTranscript(state.SessionID, state.TranscriptPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read live transcript: %w", err)
 	}
@@ -562,6 +617,39 @@ func extractUserPrompts(agentType agent.AgentType, content string) []string {
 		return nil
 	}
 
+	// Aider's transcript is its markdown chat history
+	if agentType == agent.AgentTypeAider {
+		var cleaned []string
+		for _, prompt := range aider.ExtractUserPrompts([]byte(content), 0) {
+			if stripped := textutil.StripIDEContextTags(prompt); stripped != "" {
+				cleaned = append(cleaned, stripped)
+			}
+		}
+		return cleaned
+	}
+
+	// Cursor uses JSONL keyed by "role" rather than Claude Code's "type"
+	if agentType == agent.AgentTypeCursor {
+		var cleaned []string
+		for _, prompt := range cursor.ExtractAllUserPrompts([]byte(content)) {
+			if stripped := textutil.StripIDEContextTags(prompt); stripped != "" {
+				cleaned = append(cleaned, stripped)
+			}
+		}
+		return cleaned
+	}
+
+	// Codex rollouts record prompts as user_message events
+	if agentType == agent.AgentTypeCodex {
+		var cleaned []string
+		for _, prompt := range codex.ExtractAllUserPrompts([]byte(content)) {
+			if stripped := textutil.StripIDEContextTags(prompt); stripped != "" {
+				cleaned = append(cleaned, stripped)
+			}
+		}
+		return cleaned
+	}
+
 	// Try Gemini format first if agentType is Gemini, or as fallback if Unknown
 	if agentType == agent.AgentTypeGemini || agentType == agent.AgentTypeUnknown {
 		prompts, err := geminicli.ExtractAllUserPrompts([]byte(content))
@@ -600,6 +688,17 @@ func calculateTokenUsage(agentType agent.AgentType, data []byte, startOffset int
 		return opencode.CalculateTokenUsageFromBytes(data, startOffset)
 	}
 
+	// Aider reports token counts in its markdown chat history
+	if agentType == agent.AgentTypeAider {
+		return aider.CalculateTokenUsage(data, startOffset)
+	}
+
+	// Codex reports token counts in token_count events
+	if agentType == agent.AgentTypeCodex {
+		lines, _ := codex.ParseRolloutFromLine(data, startOffset)
+		return codex.CalculateTokenUsage(lines)
+	}
+
 	// Try Gemini format first if agentType is Gemini, or as fallback if Unknown
 	if agentType == agent.AgentTypeGemini || agentType == agent.AgentTypeUnknown {
 		// Attempt to parse as Gemini JSON
@@ -728,7 +827,7 @@ func (s *ManualCommitStrategy) CondenseSessionByID(sessionID string) error {
 	}
 
 	// Generate a checkpoint ID
-	checkpointID, err := id.Generate()
+	checkpointID, err := s.generateID()
 	if err != nil {
 		return fmt.Errorf("failed to generate checkpoint ID: %w", err)
 	}
@@ -772,6 +871,8 @@ func (s *ManualCommitStrategy) CondenseSessionByID(sessionID string) error {
 	state.AttributionBaseCommit = state.BaseCommit
 	state.PromptAttributions = nil
 	state.PendingPromptAttribution = nil
+	state.TornSnapshotFiles = nil
+	state.OmittedFiles = nil
 
 	if err := s.saveSessionState(state); err != nil {
 		return fmt.Errorf("failed to save session state: %w", err)
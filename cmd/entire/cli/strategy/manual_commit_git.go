@@ -49,6 +49,24 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 		return err
 	}
 
+	// Files outside track_paths or matching ignore_paths are left out
+	scope := loadPathScope()
+	ctx.ModifiedFiles = filterTrackedPaths(scope, ctx.ModifiedFiles)
+	ctx.NewFiles = filterTrackedPaths(scope, ctx.NewFiles)
+	ctx.DeletedFiles = filterTrackedPaths(scope, ctx.DeletedFiles)
+
+	// Files inside submodules are checkpointed in the submodules' own
+	// repositories, after condensing what was committed there since the last step
+	hasSubmodules := false
+	if worktreeRoot, err := paths.WorktreeRoot(); err == nil {
+		logCtx := logging.WithComponent(context.Background(), "checkpoint")
+		s.condenseSubmoduleCommits(logCtx, state, worktreeRoot)
+		if steps := splitSubmoduleFiles(submodulePaths(repo), &ctx); len(steps) > 0 {
+			s.saveSubmoduleSteps(logCtx, state, worktreeRoot, steps, ctx)
+		}
+		hasSubmodules = len(state.Submodules) > 0
+	}
+
 	// Get checkpoint store
 	store, err := s.getCheckpointStore()
 	if err != nil {
@@ -82,6 +100,7 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 
 	// Use WriteTemporary to create the checkpoint
 	isFirstCheckpointOfSession := state.StepCount == 0
+	maxFileBytes, skipBinary := loadSnapshotLimits()
 	result, err := store.WriteTemporary(context.Background(), checkpoint.WriteTemporaryOptions{
 		SessionID:         sessionID,
 		BaseCommit:        state.BaseCommit,
@@ -95,11 +114,18 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 		AuthorName:        ctx.AuthorName,
 		AuthorEmail:       ctx.AuthorEmail,
 		IsFirstCheckpoint: isFirstCheckpointOfSession,
+		MaxFileBytes:      maxFileBytes,
+		SkipBinaryFiles:   skipBinary,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to write temporary checkpoint: %w", err)
 	}
 
+	// Omitted files are recorded even when the tree is unchanged, so the
+	// warning shows up on the step that touched them
+	warnOmittedFiles(result.Omitted)
+	state.OmittedFiles = mergeOmittedFiles(state.OmittedFiles, result.Omitted)
+
 	// If checkpoint was skipped due to deduplication (no changes), return early
 	if result.Skipped {
 		logCtx := logging.WithComponent(context.Background(), "checkpoint")
@@ -110,6 +136,11 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 			slog.String("shadow_branch", shadowBranchName),
 		)
 		fmt.Fprintf(os.Stderr, "Skipped checkpoint (no changes since last checkpoint)\n")
+		if hasSubmodules || len(result.Omitted) > 0 {
+			if err := s.saveSessionState(state); err != nil {
+				return fmt.Errorf("failed to save session state: %w", err)
+			}
+		}
 		return nil
 	}
 
@@ -126,6 +157,15 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 	// Track touched files (modified, new, and deleted)
 	state.FilesTouched = mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
 
+	// Remember files whose snapshot could not be verified so condensation can flag them
+	if torn := result.TornSnapshots(); len(torn) > 0 {
+		state.TornSnapshotFiles = mergeFilesTouched(state.TornSnapshotFiles, torn)
+		logging.Warn(logging.WithComponent(context.Background(), "checkpoint"), "snapshot did not match file on disk after retry",
+			slog.String("session_id", sessionID),
+			slog.Any("files", torn),
+		)
+	}
+
 	// On first checkpoint, record the transcript identifier for this session
 	if state.StepCount == 1 {
 		state.TranscriptIdentifierAtStart = ctx.StepTranscriptIdentifier
@@ -140,6 +180,9 @@ func (s *ManualCommitStrategy) SaveStep(ctx StepContext) error {
 	if err := s.saveSessionState(state); err != nil {
 		return fmt.Errorf("failed to save session state: %w", err)
 	}
+	if conformanceChecksEnabled() {
+		logConformanceViolations(repo, state)
+	}
 
 	if !branchExisted {
 		fmt.Fprintf(os.Stderr, "Created shadow branch '%s' and committed changes\n", shadowBranchName)
@@ -186,6 +229,12 @@ func (s *ManualCommitStrategy) SaveTaskStep(ctx TaskStepContext) error {
 		return err
 	}
 
+	// Files outside track_paths or matching ignore_paths are left out
+	scope := loadPathScope()
+	ctx.ModifiedFiles = filterTrackedPaths(scope, ctx.ModifiedFiles)
+	ctx.NewFiles = filterTrackedPaths(scope, ctx.NewFiles)
+	ctx.DeletedFiles = filterTrackedPaths(scope, ctx.DeletedFiles)
+
 	// Get checkpoint store
 	store, err := s.getCheckpointStore()
 	if err != nil {
@@ -226,6 +275,7 @@ func (s *ManualCommitStrategy) SaveTaskStep(ctx TaskStepContext) error {
 	)
 
 	// Use WriteTemporaryTask to create the checkpoint
+	maxFileBytes, skipBinary := loadSnapshotLimits()
 	_, err = store.WriteTemporaryTask(context.Background(), checkpoint.WriteTemporaryTaskOptions{
 		SessionID:              ctx.SessionID,
 		BaseCommit:             state.BaseCommit,
@@ -245,6 +295,8 @@ func (s *ManualCommitStrategy) SaveTaskStep(ctx TaskStepContext) error {
 		IncrementalSequence:    ctx.IncrementalSequence,
 		IncrementalType:        ctx.IncrementalType,
 		IncrementalData:        ctx.IncrementalData,
+		MaxFileBytes:           maxFileBytes,
+		SkipBinaryFiles:        skipBinary,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to write task checkpoint: %w", err)
@@ -315,6 +367,7 @@ func mergeFilesTouched(existing []string, fileLists ...[]string) []string {
 
 // accumulateTokenUsage adds new token usage to existing accumulated usage.
 // If existing is nil, returns a copy of incoming. If incoming is nil, returns existing unchanged.
+// The most recent model named by incoming replaces the existing one.
 func accumulateTokenUsage(existing, incoming *agent.TokenUsage) *agent.TokenUsage {
 	if incoming == nil {
 		return existing
@@ -327,6 +380,7 @@ func accumulateTokenUsage(existing, incoming *agent.TokenUsage) *agent.TokenUsag
 			CacheReadTokens:     incoming.CacheReadTokens,
 			OutputTokens:        incoming.OutputTokens,
 			APICallCount:        incoming.APICallCount,
+			Model:               incoming.Model,
 			SubagentTokens:      incoming.SubagentTokens,
 		}
 	}
@@ -337,6 +391,9 @@ func accumulateTokenUsage(existing, incoming *agent.TokenUsage) *agent.TokenUsag
 	existing.CacheReadTokens += incoming.CacheReadTokens
 	existing.OutputTokens += incoming.OutputTokens
 	existing.APICallCount += incoming.APICallCount
+	if incoming.Model != "" {
+		existing.Model = incoming.Model
+	}
 
 	// Accumulate subagent tokens if present
 	if incoming.SubagentTokens != nil {
@@ -9,15 +9,19 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
 
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/features"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 	"github.com/entireio/cli/cmd/entire/cli/stringutil"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 	"github.com/entireio/cli/redact"
@@ -148,9 +152,10 @@ func (s *ManualCommitStrategy) CommitMsg(commitMsgFile string) error {
 	return nil
 }
 
-// hasUserContent checks if the message has any content besides comments and our trailer.
+// hasUserContent checks if the message has any content besides comments and our trailers.
 func hasUserContent(message string) bool {
-	trailerPrefix := trailers.CheckpointTrailerKey + ":"
+	trailerPrefix := trailers.CheckpointTrailerKey() + ":"
+	ticketPrefix := trailers.TicketTrailerKey + ":"
 	for _, line := range strings.Split(message, "\n") {
 		trimmed := strings.TrimSpace(line)
 		// Skip empty lines
@@ -161,8 +166,8 @@ func hasUserContent(message string) bool {
 		if strings.HasPrefix(trimmed, "#") {
 			continue
 		}
-		// Skip our trailer line
-		if strings.HasPrefix(trimmed, trailerPrefix) {
+		// Skip our trailer lines
+		if strings.HasPrefix(trimmed, trailerPrefix) || strings.HasPrefix(trimmed, ticketPrefix) {
 			continue
 		}
 		// Found user content
@@ -171,12 +176,15 @@ func hasUserContent(message string) bool {
 	return false
 }
 
-// stripCheckpointTrailer removes the Entire-Checkpoint trailer line from the message.
+// stripCheckpointTrailer removes the Entire-Checkpoint trailer line, and the
+// Entire-Ticket lines added with it, from the message.
 func stripCheckpointTrailer(message string) string {
-	trailerPrefix := trailers.CheckpointTrailerKey + ":"
+	trailerPrefix := trailers.CheckpointTrailerKey() + ":"
+	ticketPrefix := trailers.TicketTrailerKey + ":"
 	var result []string
 	for _, line := range strings.Split(message, "\n") {
-		if !strings.HasPrefix(strings.TrimSpace(line), trailerPrefix) {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, trailerPrefix) && !strings.HasPrefix(trimmed, ticketPrefix) {
 			result = append(result, line)
 		}
 	}
@@ -227,13 +235,25 @@ func isGitSequenceOperation() bool {
 // The source parameter indicates how the commit was initiated:
 //   - "" or "template": normal editor flow - adds trailer with explanatory comment
 //   - "message": using -m or -F flag - prompts user interactively via /dev/tty
-//   - "merge", "squash": skip trailer entirely (auto-generated messages)
-//   - "commit": amend operation - preserves existing trailer or restores from LastCheckpointID
+//   - "merge": skip trailer entirely (auto-generated message)
+//   - "squash", or any source while a `git merge --squash` is pending: see
+//     prepareSquashCommitMsg
+//   - "commit": amend operation - see handleAmendCommitMsg. An amend with a
+//     new message (`--amend -m`) looks like a new commit here; post-rewrite
+//     links the replacement to the amended commit's checkpoints (see PostRewrite)
+//   - any source, for `--fixup`/`--squash` commits and reverts: skip trailer entirely
+//   - any source, while cherry-picking: copied trailers are marked as
+//     cherry-picked (see prepareCherryPickCommitMsg) and no trailer is added
 //
 
 func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source string) error {
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 
+	// Cherry-picked commits must not claim the checkpoints of the commits they copy
+	if isCherryPickInProgress() {
+		prepareCherryPickCommitMsg(logCtx, commitMsgFile)
+	}
+
 	// Skip during rebase, cherry-pick, or revert operations
 	// These are replaying existing commits and should not be linked to agent sessions
 	if isGitSequenceOperation() {
@@ -244,10 +264,9 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 		return nil
 	}
 
-	// Skip for merge and squash sources
+	// Skip for merge sources
 	// These are auto-generated messages - not from Claude sessions
-	switch source {
-	case "merge", "squash":
+	if source == "merge" {
 		logging.Debug(logCtx, "prepare-commit-msg: skipped for source",
 			slog.String("strategy", "manual-commit"),
 			slog.String("source", source),
@@ -255,6 +274,21 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 		return nil
 	}
 
+	// Squash merges fold in commits whose trailers would otherwise be lost
+	if squash := detectSquashMerge(commitMsgFile, source); squash != nil {
+		return s.prepareSquashCommitMsg(logCtx, commitMsgFile, source, squash)
+	}
+
+	// Fixup and squash commits are folded into a commit that has its own
+	// checkpoint, and reverts undo one
+	if content, err := os.ReadFile(commitMsgFile); err == nil && (isFixupCommitMessage(string(content)) || isRevertCommitMessage(string(content))) { //nolint:gosec // commitMsgFile is provided by git hook
+		logging.Debug(logCtx, "prepare-commit-msg: skipped fixup or revert commit",
+			slog.String("strategy", "manual-commit"),
+			slog.String("source", source),
+		)
+		return nil
+	}
+
 	// Handle amend (source="commit") separately: preserve or restore trailer
 	if source == "commit" {
 		return s.handleAmendCommitMsg(logCtx, commitMsgFile)
@@ -283,6 +317,8 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 		return nil //nolint:nilerr // Intentional: hooks must be silent on failure
 	}
 
+	warnFailedQualityGates(os.Stderr, repo, sessions)
+
 	// Fast path: when an agent is committing (ACTIVE session + no TTY), skip
 	// content detection and interactive prompts. The agent can't respond to TTY
 	// prompts and the content detection can miss mid-session work (no shadow
@@ -291,7 +327,7 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 	if !hasTTY() {
 		for _, state := range sessions {
 			if state.Phase.IsActive() {
-				return s.addTrailerForAgentCommit(logCtx, commitMsgFile, state, source)
+				return s.addTrailerForAgentCommit(logCtx, repo, commitMsgFile, state, source)
 			}
 		}
 	}
@@ -329,7 +365,7 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 	}
 
 	// Generate a fresh checkpoint ID
-	checkpointID, err := id.Generate()
+	checkpointID, err := s.generateID()
 	if err != nil {
 		return fmt.Errorf("failed to generate checkpoint ID: %w", err)
 	}
@@ -373,6 +409,7 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 		// Normal editor flow: add trailer with explanatory comment (will be stripped by git)
 		message = addCheckpointTrailerWithComment(message, checkpointID, string(agentType), displayPrompt)
 	}
+	message = addTicketTrailers(message, sessionsTickets(sessionsWithContent, GetCurrentBranchName(repo)))
 
 	logging.Info(logCtx, "prepare-commit-msg: trailer added",
 		slog.String("strategy", "manual-commit"),
@@ -388,8 +425,9 @@ func (s *ManualCommitStrategy) PrepareCommitMsg(commitMsgFile string, source str
 	return nil
 }
 
-// handleAmendCommitMsg handles the prepare-commit-msg hook for amend operations
-// (source="commit"). It preserves existing trailers or restores from LastCheckpointID.
+// handleAmendCommitMsg handles the prepare-commit-msg hook for amend operations.
+// It preserves existing trailers, or restores those of the amended commit, or
+// failing that the checkpoint from LastCheckpointID.
 func (s *ManualCommitStrategy) handleAmendCommitMsg(logCtx context.Context, commitMsgFile string) error {
 	// Read current commit message
 	content, err := os.ReadFile(commitMsgFile) //nolint:gosec // commitMsgFile is provided by git hook
@@ -408,7 +446,34 @@ func (s *ManualCommitStrategy) handleAmendCommitMsg(logCtx context.Context, comm
 		return nil
 	}
 
-	// No trailer in message — check if any session has LastCheckpointID to restore
+	repo, repoErr := OpenRepository()
+	if repoErr != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	head, headErr := repo.Head()
+	if headErr != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	currentHead := head.Hash().String()
+
+	// A new message (`--amend -m`) replaces the one with the trailer, so carry
+	// the amended commit's trailers over instead of linking a new checkpoint
+	if amended, err := repo.CommitObject(head.Hash()); err == nil {
+		if cpID, found := trailers.ParseCheckpoint(amended.Message); found {
+			message = addTicketTrailers(addCheckpointTrailer(message, cpID), trailers.ParseAllTickets(amended.Message))
+			if writeErr := os.WriteFile(commitMsgFile, []byte(message), 0o600); writeErr != nil {
+				return nil //nolint:nilerr // Hook must be silent on failure
+			}
+			logging.Info(logCtx, "prepare-commit-msg: restored trailer on amend",
+				slog.String("strategy", "manual-commit"),
+				slog.String("checkpoint_id", cpID.String()),
+				slog.String("source", "amended commit"),
+			)
+			return nil
+		}
+	}
+
+	// No trailer to carry over — check if any session has LastCheckpointID to restore
 	worktreePath, err := paths.WorktreeRoot()
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
@@ -419,19 +484,10 @@ func (s *ManualCommitStrategy) handleAmendCommitMsg(logCtx context.Context, comm
 		return nil //nolint:nilerr // No sessions - nothing to restore
 	}
 
-	// For amend, HEAD^ is the commit being amended, and HEAD is where we are now.
-	// We need to match sessions whose BaseCommit equals HEAD (the commit being amended
-	// was created from this base). This prevents stale sessions from injecting
-	// unrelated checkpoint IDs.
-	repo, repoErr := OpenRepository()
-	if repoErr != nil {
-		return nil //nolint:nilerr // Hook must be silent on failure
-	}
-	head, headErr := repo.Head()
-	if headErr != nil {
-		return nil //nolint:nilerr // Hook must be silent on failure
-	}
-	currentHead := head.Hash().String()
+	// For amend, HEAD is the commit being amended. We need to match sessions
+	// whose BaseCommit equals HEAD (the commit being amended was created from
+	// this base). This prevents stale sessions from injecting unrelated
+	// checkpoint IDs.
 
 	// Find first matching session with LastCheckpointID to restore.
 	// LastCheckpointID is set after condensation completes.
@@ -493,16 +549,20 @@ type postCommitActionHandler struct {
 	newHead                string
 	shadowBranchName       string
 	shadowBranchesToDelete map[string]struct{}
-	committedFileSet       map[string]struct{}
+	committedFiles         func() map[string]struct{}
 	hasNew                 bool
 	filesTouchedBefore     []string
 
 	// Output: set by handler methods, read by caller after TransitionAndLog.
 	condensed bool
+	result    *CondenseResult
+	// skipReason explains why the session was not condensed, for the
+	// condensation log.
+	skipReason string
 }
 
 func (h *postCommitActionHandler) HandleCondense(state *session.State) error {
-	shouldCondense := h.shouldCondenseWithOverlapCheck(state.Phase.IsActive())
+	shouldCondense, reason := h.shouldCondenseWithOverlapCheck(state.Phase.IsActive())
 
 	logging.Debug(h.logCtx, "post-commit: HandleCondense decision",
 		slog.String("session_id", state.SessionID),
@@ -513,15 +573,19 @@ func (h *postCommitActionHandler) HandleCondense(state *session.State) error {
 	)
 
 	if shouldCondense {
-		h.condensed = h.s.condenseAndUpdateState(h.logCtx, h.repo, h.checkpointID, state, h.head, h.shadowBranchName, h.shadowBranchesToDelete, h.committedFileSet)
+		h.condense(state)
 	} else {
+		h.skipReason = reason
 		h.s.updateBaseCommitIfChanged(h.logCtx, state, h.newHead)
 	}
 	return nil
 }
 
 func (h *postCommitActionHandler) HandleCondenseIfFilesTouched(state *session.State) error {
-	shouldCondense := len(state.FilesTouched) > 0 && h.shouldCondenseWithOverlapCheck(state.Phase.IsActive())
+	shouldCondense, reason := false, "session touched no files"
+	if len(state.FilesTouched) > 0 {
+		shouldCondense, reason = h.shouldCondenseWithOverlapCheck(state.Phase.IsActive())
+	}
 
 	logging.Debug(h.logCtx, "post-commit: HandleCondenseIfFilesTouched decision",
 		slog.String("session_id", state.SessionID),
@@ -533,15 +597,27 @@ func (h *postCommitActionHandler) HandleCondenseIfFilesTouched(state *session.St
 	)
 
 	if shouldCondense {
-		h.condensed = h.s.condenseAndUpdateState(h.logCtx, h.repo, h.checkpointID, state, h.head, h.shadowBranchName, h.shadowBranchesToDelete, h.committedFileSet)
+		h.condense(state)
 	} else {
+		h.skipReason = reason
 		h.s.updateBaseCommitIfChanged(h.logCtx, state, h.newHead)
 	}
 	return nil
 }
 
+// condense condenses the session into the commit's checkpoint.
+func (h *postCommitActionHandler) condense(state *session.State) {
+	result, err := h.s.condenseAndUpdateState(h.logCtx, h.repo, h.checkpointID, state, h.head, h.shadowBranchName, h.shadowBranchesToDelete, h.committedFiles())
+	if err != nil {
+		h.skipReason = "condensation failed: " + err.Error()
+		return
+	}
+	h.result = result
+	h.condensed = true
+}
+
 // shouldCondenseWithOverlapCheck returns true if the session should be condensed
-// into this commit. Requires both that hasNew is true AND that the session's files
+// into this commit, or false and the reason it should not. Requires both that hasNew is true AND that the session's files
 // overlap with the committed files with matching content.
 //
 // This prevents stale sessions (ACTIVE sessions where the agent was killed, or
@@ -557,28 +633,28 @@ func (h *postCommitActionHandler) HandleCondenseIfFilesTouched(state *session.St
 //     mid-turn before any files are saved to state.
 //   - For IDLE/ENDED sessions: return false because there are no files to
 //     overlap with the commit.
-func (h *postCommitActionHandler) shouldCondenseWithOverlapCheck(isActive bool) bool {
+func (h *postCommitActionHandler) shouldCondenseWithOverlapCheck(isActive bool) (bool, string) {
 	if !h.hasNew {
-		return false
+		return false, "no new content since the last checkpoint"
 	}
 	if len(h.filesTouchedBefore) == 0 {
-		return isActive // ACTIVE: fail-open; IDLE/ENDED: no files = no overlap
+		// ACTIVE: fail-open; IDLE/ENDED: no files = no overlap
+		return isActive, "session touched no files"
 	}
 	// Only check files that were actually changed in this commit.
 	// Without this, files that exist in the tree but weren't changed
 	// would pass the "modified file" check in filesOverlapWithContent
 	// (because the file exists in the parent tree), causing stale
-	// sessions to be incorrectly condensed.
-	var committedTouchedFiles []string
-	for _, f := range h.filesTouchedBefore {
-		if _, ok := h.committedFileSet[f]; ok {
-			committedTouchedFiles = append(committedTouchedFiles, f)
-		}
-	}
+	// sessions to be incorrectly condensed. Only the session's paths are
+	// looked up, so sessions that don't overlap never diff the whole commit.
+	committedTouchedFiles := filesChangedAtPaths(h.commit, h.filesTouchedBefore)
 	if len(committedTouchedFiles) == 0 {
-		return false
+		return false, "none of the session's files are in this commit"
+	}
+	if !filesOverlapWithContent(h.repo, h.shadowBranchName, h.commit, committedTouchedFiles) {
+		return false, "committed files don't contain the session's changes"
 	}
-	return filesOverlapWithContent(h.repo, h.shadowBranchName, h.commit, committedTouchedFiles)
+	return true, ""
 }
 
 func (h *postCommitActionHandler) HandleDiscardIfNoFiles(state *session.State) error {
@@ -586,6 +662,7 @@ func (h *postCommitActionHandler) HandleDiscardIfNoFiles(state *session.State) e
 		logging.Debug(h.logCtx, "post-commit: skipping empty ended session (no files to condense)",
 			slog.String("session_id", state.SessionID),
 		)
+		h.skipReason = "session touched no files"
 	}
 	h.s.updateBaseCommitIfChanged(h.logCtx, state, h.newHead)
 	return nil
@@ -600,8 +677,6 @@ func (h *postCommitActionHandler) HandleWarnStaleSession(_ *session.State) error
 //
 
 func (s *ManualCommitStrategy) PostCommit() error {
-	logCtx := logging.WithComponent(context.Background(), "checkpoint")
-
 	repo, err := OpenRepository()
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
@@ -612,40 +687,98 @@ func (s *ManualCommitStrategy) PostCommit() error {
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
 	}
+	isRebase := isGitSequenceOperation()
+
+	// With async condensation, only queue the commit; a later hook or
+	// `entire worker run-once` condenses it. If queueing fails, condense now.
+	if asyncCondensationEnabled() {
+		if err := s.enqueueCondensation(head.Hash(), isRebase); err == nil {
+			return nil
+		}
+	}
+
+	// Commits queued before async condensation was turned off go first, so
+	// sessions are still condensed in commit order.
+	s.runCondensationQueue()
+
+	return s.postCommit(repo, head, isRebase)
+}
+
+// postCommit runs the post-commit handling for head, the commit just made
+// (or a queued one). isRebase reports whether a rebase, cherry-pick, or revert
+// was in progress when it was made.
+func (s *ManualCommitStrategy) postCommit(repo *git.Repository, head *plumbing.Reference, isRebase bool) error {
+	logCtx := logging.WithComponent(context.Background(), "checkpoint")
+
+	worktreePath, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	// This worktree's session states are loaded once and every change is
+	// written together when the hook is done, instead of once per session
+	// and phase.
+	tx, err := s.beginSessionTx(worktreePath)
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	defer commitSessionTx(tx)
+
+	// End sessions left ACTIVE by killed agents first, so they are handled
+	// as ENDED sessions below instead of being carried forward as ACTIVE.
+	expireIdleSessionsInTx(tx, s.now())
+
+	// Work committed inside submodules is condensed into the submodules'
+	// own repositories, whether or not this commit has a trailer.
+	for _, state := range s.findSessionsForWorktreeInTx(tx, repo, worktreePath) {
+		if len(state.Submodules) > 0 && s.condenseSubmoduleCommits(logCtx, state, worktreePath) {
+			tx.Save(state)
+		}
+	}
 
 	commit, err := repo.CommitObject(head.Hash())
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
 	}
 
-	// Check if commit has checkpoint trailer (ParseCheckpoint validates format)
-	checkpointID, found := trailers.ParseCheckpoint(commit.Message)
+	// Check if commit has checkpoint trailer (ParseCheckpointID validates format).
+	// A malformed trailer (e.g. hand-edited in the commit message) is treated
+	// like a missing one so the hook never fails on user input.
+	checkpointID, found, parseErr := trailers.ParseCheckpointID(commit.Message)
+	if parseErr != nil {
+		logging.Warn(logCtx, "post-commit: ignoring malformed checkpoint trailer",
+			slog.String("strategy", "manual-commit"),
+			slog.String("commit", head.Hash().String()),
+			slog.String("error", parseErr.Error()),
+		)
+	}
 	if !found {
 		// No trailer — user removed it or it was never added (mid-turn commit).
 		// Still update BaseCommit for active sessions so future commits can match.
-		s.postCommitUpdateBaseCommitOnly(logCtx, head)
+		s.postCommitUpdateBaseCommitOnly(logCtx, tx, repo, head)
 		return nil
 	}
 
-	worktreePath, err := paths.WorktreeRoot()
-	if err != nil {
-		return nil //nolint:nilerr // Hook must be silent on failure
-	}
-
 	// Find all active sessions for this worktree
-	sessions, err := s.findSessionsForWorktree(worktreePath)
-	if err != nil || len(sessions) == 0 {
+	sessions := s.findSessionsForWorktreeInTx(tx, repo, worktreePath)
+	if len(sessions) == 0 {
 		logging.Warn(logCtx, "post-commit: no active sessions despite trailer",
 			slog.String("strategy", "manual-commit"),
 			slog.String("checkpoint_id", checkpointID.String()),
 		)
-		return nil //nolint:nilerr // Intentional: hooks must be silent on failure
+		audit.RecordCondensation(audit.GitDir(repo), audit.Condensation{
+			Commit:       head.Hash().String(),
+			CheckpointID: checkpointID.String(),
+			Decision:     audit.DecisionSkipped,
+			Reason:       "no sessions in this worktree",
+		})
+		return nil
 	}
 
 	// Build transition context
-	isRebase := isGitSequenceOperation()
 	transitionCtx := session.TransitionContext{
 		IsRebaseInProgress: isRebase,
+		Clock:              s.clock,
 	}
 
 	if isRebase {
@@ -660,7 +793,11 @@ func (s *ManualCommitStrategy) PostCommit() error {
 	uncondensedActiveOnBranch := make(map[string]bool)
 
 	newHead := head.Hash().String()
-	committedFileSet := filesChangedInCommit(commit)
+	// Diffing the whole commit is only needed once a session condenses
+	committedFiles := sync.OnceValue(func() map[string]struct{} {
+		return filesChangedInCommit(repo, commit)
+	})
+	receipt := &condensationReceipt{CheckpointID: checkpointID}
 
 	for _, state := range sessions {
 		shadowBranchName := getShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
@@ -712,6 +849,7 @@ func (s *ManualCommitStrategy) PostCommit() error {
 		)
 
 		// Run the state machine transition with handler for strategy-specific actions.
+		phaseBefore := state.Phase
 		handler := &postCommitActionHandler{
 			s:                      s,
 			logCtx:                 logCtx,
@@ -722,7 +860,7 @@ func (s *ManualCommitStrategy) PostCommit() error {
 			newHead:                newHead,
 			shadowBranchName:       shadowBranchName,
 			shadowBranchesToDelete: shadowBranchesToDelete,
-			committedFileSet:       committedFileSet,
+			committedFiles:         committedFiles,
 			hasNew:                 hasNew,
 			filesTouchedBefore:     filesTouchedBefore,
 		}
@@ -745,26 +883,52 @@ func (s *ManualCommitStrategy) PostCommit() error {
 		// commit across two `git commit` invocations, each gets a 1:1 checkpoint.
 		// Uses content-aware comparison: if user did `git add -p` and committed
 		// partial changes, the file still has remaining agent changes to carry forward.
+		decision := audit.Condensation{
+			SessionID:    state.SessionID,
+			Commit:       newHead,
+			CheckpointID: checkpointID.String(),
+			Phase:        string(phaseBefore),
+			Decision:     audit.DecisionSkipped,
+			Reason:       handler.skipReason,
+		}
+		switch {
+		case handler.condensed:
+			decision.Decision = audit.DecisionCondensed
+			decision.Reason = ""
+			decision.Files = handler.result.FilesTouched
+		case isRebase:
+			decision.Reason = "rebase or other sequence operation in progress"
+		}
+		audit.RecordCondensation(audit.GitDir(repo), decision)
+
 		if handler.condensed {
-			remainingFiles := filesWithRemainingAgentChanges(repo, shadowBranchName, commit, filesTouchedBefore, committedFileSet)
+			remainingFiles := filesWithRemainingAgentChanges(repo, shadowBranchName, commit, filesTouchedBefore, committedFiles())
 			state.FilesTouched = remainingFiles
 			logging.Debug(logCtx, "post-commit: carry-forward decision (content-aware)",
 				slog.String("session_id", state.SessionID),
 				slog.Int("files_touched_before", len(filesTouchedBefore)),
-				slog.Int("committed_files", len(committedFileSet)),
+				slog.Int("committed_files", len(committedFiles())),
 				slog.Int("remaining_files", len(remainingFiles)),
 				slog.Any("remaining", remainingFiles),
-				slog.Any("committed_files", committedFileSet),
+				slog.Any("committed_files", committedFiles()),
 			)
 			if len(remainingFiles) > 0 {
 				s.carryForwardToNewShadowBranch(logCtx, repo, state, remainingFiles)
+				audit.RecordCondensation(audit.GitDir(repo), audit.Condensation{
+					SessionID:    state.SessionID,
+					Commit:       newHead,
+					CheckpointID: checkpointID.String(),
+					Phase:        string(phaseBefore),
+					Decision:     audit.DecisionCarryForward,
+					Reason:       "files still have uncommitted agent changes",
+					Files:        remainingFiles,
+				})
 			}
+			receipt.add(handler.result, remainingFiles)
 		}
 
 		// Save the updated state
-		if err := s.saveSessionState(state); err != nil {
-			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
-		}
+		tx.Save(state)
 
 		// Only preserve shadow branch for active sessions that were NOT condensed.
 		// Condensed sessions already have their data on entire/checkpoints/v1.
@@ -773,8 +937,16 @@ func (s *ManualCommitStrategy) PostCommit() error {
 		}
 	}
 
+	// Write the session states before anything below, such as a push running
+	// the pre-push hook, can read them.
+	commitSessionTx(tx)
+
 	// Clean up shadow branches — only delete when ALL sessions on the branch are non-active
 	// or were condensed during this PostCommit.
+	var otherUsersBranches map[string]bool
+	if len(shadowBranchesToDelete) > 0 {
+		otherUsersBranches = otherUsersShadowBranches()
+	}
 	for shadowBranchName := range shadowBranchesToDelete {
 		if uncondensedActiveOnBranch[shadowBranchName] {
 			logging.Debug(logCtx, "post-commit: preserving shadow branch (active session exists)",
@@ -782,6 +954,12 @@ func (s *ManualCommitStrategy) PostCommit() error {
 			)
 			continue
 		}
+		if otherUsersBranches[shadowBranchName] {
+			logging.Debug(logCtx, "post-commit: preserving shadow branch (another user's session exists)",
+				slog.String("shadow_branch", shadowBranchName),
+			)
+			continue
+		}
 		if err := deleteShadowBranch(repo, shadowBranchName); err != nil {
 			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to clean up %s: %v\n", shadowBranchName, err)
 		} else {
@@ -793,11 +971,16 @@ func (s *ManualCommitStrategy) PostCommit() error {
 		}
 	}
 
+	printCondensationReceipt(os.Stderr, receipt)
+	if len(receipt.Sessions) > 0 {
+		pushAfterCondense()
+	}
+
 	return nil
 }
 
 // condenseAndUpdateState runs condensation for a session and updates state afterward.
-// Returns true if condensation succeeded.
+// Returns the condensation result, or the error condensation failed with.
 func (s *ManualCommitStrategy) condenseAndUpdateState(
 	logCtx context.Context,
 	repo *git.Repository,
@@ -807,16 +990,17 @@ func (s *ManualCommitStrategy) condenseAndUpdateState(
 	shadowBranchName string,
 	shadowBranchesToDelete map[string]struct{},
 	committedFiles map[string]struct{},
-) bool {
+) (*CondenseResult, error) {
 	result, err := s.CondenseSession(repo, checkpointID, state, committedFiles)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "[entire] Warning: condensation failed for session %s: %v\n",
+		fmt.Fprintf(os.Stderr, "[entire] Warning: condensation failed for session %s: %v\n"+
+			"[entire] Run 'entire doctor --repair' if this keeps happening.\n",
 			state.SessionID, err)
 		logging.Warn(logCtx, "post-commit: condensation failed",
 			slog.String("session_id", state.SessionID),
 			slog.String("error", err.Error()),
 		)
-		return false
+		return nil, err
 	}
 
 	// Track this shadow branch for cleanup
@@ -833,17 +1017,12 @@ func (s *ManualCommitStrategy) condenseAndUpdateState(
 	state.PromptAttributions = nil
 	state.PendingPromptAttribution = nil
 	state.FilesTouched = nil
+	state.TornSnapshotFiles = nil
+	state.OmittedFiles = nil
 
 	// Save checkpoint ID so subsequent commits can reuse it (e.g., amend restores trailer)
 	state.LastCheckpointID = checkpointID
 
-	shortID := state.SessionID
-	if len(shortID) > 8 {
-		shortID = shortID[:8]
-	}
-	fmt.Fprintf(os.Stderr, "[entire] Condensed session %s: %s (%d checkpoints)\n",
-		shortID, result.CheckpointID, result.CheckpointsCount)
-
 	logging.Info(logCtx, "session condensed",
 		slog.String("strategy", "manual-commit"),
 		slog.String("checkpoint_id", result.CheckpointID.String()),
@@ -851,7 +1030,7 @@ func (s *ManualCommitStrategy) condenseAndUpdateState(
 		slog.Int("transcript_lines", result.TotalTranscriptLines),
 	)
 
-	return true
+	return result, nil
 }
 
 // updateBaseCommitIfChanged updates BaseCommit to newHead if it changed.
@@ -872,7 +1051,7 @@ func (s *ManualCommitStrategy) updateBaseCommitIfChanged(logCtx context.Context,
 		state.BaseCommit = newHead
 		logging.Debug(logCtx, "post-commit: updated BaseCommit",
 			slog.String("session_id", state.SessionID),
-			slog.String("new_head", truncateHash(newHead)),
+			slog.String("new_head", TruncateHash(newHead)),
 		)
 	}
 }
@@ -884,19 +1063,29 @@ func (s *ManualCommitStrategy) updateBaseCommitIfChanged(logCtx context.Context,
 //
 // Unlike the full PostCommit flow, this does NOT fire EventGitCommit or trigger
 // condensation — it only keeps BaseCommit in sync with HEAD.
-func (s *ManualCommitStrategy) postCommitUpdateBaseCommitOnly(logCtx context.Context, head *plumbing.Reference) {
+func (s *ManualCommitStrategy) postCommitUpdateBaseCommitOnly(logCtx context.Context, tx *session.StateTx, repo *git.Repository, head *plumbing.Reference) {
 	worktreePath, err := paths.WorktreeRoot()
 	if err != nil {
 		return // Silent failure — hooks must be resilient
 	}
 
-	sessions, err := s.findSessionsForWorktree(worktreePath)
-	if err != nil || len(sessions) == 0 {
+	sessions := s.findSessionsForWorktreeInTx(tx, repo, worktreePath)
+	if len(sessions) == 0 {
 		return
 	}
 
 	newHead := head.Hash().String()
 	for _, state := range sessions {
+		if state.Phase.IsActive() || len(state.FilesTouched) > 0 {
+			audit.RecordCondensation(audit.GitDir(repo), audit.Condensation{
+				SessionID: state.SessionID,
+				Commit:    newHead,
+				Phase:     string(state.Phase),
+				Decision:  audit.DecisionSkipped,
+				Reason:    "commit has no " + trailers.CheckpointTrailerKey() + " trailer",
+			})
+		}
+
 		// Only update active sessions. Idle/ended sessions are kept around for
 		// LastCheckpointID reuse and should not be advanced to HEAD.
 		if !state.Phase.IsActive() {
@@ -905,19 +1094,17 @@ func (s *ManualCommitStrategy) postCommitUpdateBaseCommitOnly(logCtx context.Con
 		if state.BaseCommit != newHead {
 			logging.Debug(logCtx, "post-commit (no trailer): updating BaseCommit",
 				slog.String("session_id", state.SessionID),
-				slog.String("old_base", truncateHash(state.BaseCommit)),
-				slog.String("new_head", truncateHash(newHead)),
+				slog.String("old_base", TruncateHash(state.BaseCommit)),
+				slog.String("new_head", TruncateHash(newHead)),
 			)
 			state.BaseCommit = newHead
-			if err := s.saveSessionState(state); err != nil {
-				fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
-			}
+			tx.Save(state)
 		}
 	}
 }
 
-// truncateHash safely truncates a git hash to 7 chars for logging.
-func truncateHash(h string) string {
+// TruncateHash shortens a git hash to 7 chars for logs and command output.
+func TruncateHash(h string) string {
 	if len(h) > 7 {
 		return h[:7]
 	}
@@ -1277,8 +1464,8 @@ func (s *ManualCommitStrategy) extractModifiedFilesFromLiveTranscript(state *Ses
 // addTrailerForAgentCommit handles the fast path when an agent is committing
 // (ACTIVE session + no TTY). Generates a checkpoint ID and adds the trailer
 // directly, bypassing content detection and interactive prompts.
-func (s *ManualCommitStrategy) addTrailerForAgentCommit(logCtx context.Context, commitMsgFile string, state *SessionState, source string) error {
-	cpID, err := id.Generate()
+func (s *ManualCommitStrategy) addTrailerForAgentCommit(logCtx context.Context, repo *git.Repository, commitMsgFile string, state *SessionState, source string) error {
+	cpID, err := s.generateID()
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
 	}
@@ -1296,6 +1483,7 @@ func (s *ManualCommitStrategy) addTrailerForAgentCommit(logCtx context.Context,
 	}
 
 	message = addCheckpointTrailer(message, cpID)
+	message = addTicketTrailers(message, sessionTickets(state, GetCurrentBranchName(repo)))
 
 	logging.Info(logCtx, "prepare-commit-msg: agent commit trailer added",
 		slog.String("strategy", "manual-commit"),
@@ -1313,8 +1501,12 @@ func (s *ManualCommitStrategy) addTrailerForAgentCommit(logCtx context.Context,
 // addCheckpointTrailer adds the Entire-Checkpoint trailer to a commit message.
 // Handles proper trailer formatting (blank line before trailers if needed).
 func addCheckpointTrailer(message string, checkpointID id.CheckpointID) string {
-	trailer := trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	return appendTrailer(message, trailers.CheckpointTrailerKey()+": "+checkpointID.String())
+}
 
+// appendTrailer adds a trailer line to the message's trailer paragraph,
+// starting a new paragraph if the message does not end with one.
+func appendTrailer(message, trailer string) string {
 	// If message already ends with trailers (lines starting with key:), just append
 	// Otherwise, add a blank line first
 	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
@@ -1374,9 +1566,9 @@ func addCheckpointTrailer(message string, checkpointID id.CheckpointID) string {
 // with a comment explaining that the user can remove it if they don't want to link the commit
 // to the agent session. If prompt is non-empty, it's shown as context.
 func addCheckpointTrailerWithComment(message string, checkpointID id.CheckpointID, agentName, prompt string) string {
-	trailer := trailers.CheckpointTrailerKey + ": " + checkpointID.String()
+	trailer := trailers.CheckpointTrailerKey() + ": " + checkpointID.String()
 	commentLines := []string{
-		"# Remove the Entire-Checkpoint trailer above if you don't want to link this commit to " + agentName + " session context.",
+		"# Remove the " + trailers.CheckpointTrailerKey() + " trailer above if you don't want to link this commit to " + agentName + " session context.",
 	}
 	if prompt != "" {
 		commentLines = append(commentLines, "# Last Prompt: "+prompt)
@@ -1441,12 +1633,12 @@ func (s *ManualCommitStrategy) InitializeSession(sessionID string, agentType age
 
 	if state != nil && state.BaseCommit != "" {
 		// Session is fully initialized — apply phase transition for TurnStart.
-		if transErr := TransitionAndLog(state, session.EventTurnStart, session.TransitionContext{}, session.NoOpActionHandler{}); transErr != nil {
+		if transErr := TransitionAndLog(state, session.EventTurnStart, session.TransitionContext{Clock: s.clock}, session.NoOpActionHandler{}); transErr != nil {
 			fmt.Fprintf(os.Stderr, "[entire] Warning: turn start transition failed: %v\n", transErr)
 		}
 
 		// Generate a new TurnID for each turn (correlates carry-forward checkpoints)
-		turnID, err := id.Generate()
+		turnID, err := s.generateID()
 		if err != nil {
 			return fmt.Errorf("failed to generate turn ID: %w", err)
 		}
@@ -1502,7 +1694,7 @@ func (s *ManualCommitStrategy) InitializeSession(sessionID string, agentType age
 	}
 
 	// Apply phase transition: new session starts as ACTIVE.
-	if transErr := TransitionAndLog(state, session.EventTurnStart, session.TransitionContext{}, session.NoOpActionHandler{}); transErr != nil {
+	if transErr := TransitionAndLog(state, session.EventTurnStart, session.TransitionContext{Clock: s.clock}, session.NoOpActionHandler{}); transErr != nil {
 		fmt.Fprintf(os.Stderr, "[entire] Warning: turn start transition failed: %v\n", transErr)
 	}
 
@@ -1683,6 +1875,12 @@ func (s *ManualCommitStrategy) getLastPrompt(repo *git.Repository, state *Sessio
 //
 
 func (s *ManualCommitStrategy) HandleTurnEnd(state *SessionState) error {
+	// Run the configured quality gate and compliance scan (best-effort) so
+	// their results are in state before the next commit's prepare-commit-msg
+	// and condensation read them.
+	runQualityGate(state)
+	runComplianceScan(state)
+
 	// Finalize all checkpoints from this turn with the full transcript.
 	//
 	// IMPORTANT: This is best-effort - errors are logged but don't fail the hook.
@@ -1731,7 +1929,9 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 		return 1 // Count as error - all checkpoints will be skipped
 	}
 
-	fullTranscript, err := os.ReadFile(state.TranscriptPath)
+	// Falls back to the metadata directory copy, restored from the metadata
+	// branch if .entire was deleted mid-session.
+	fullTranscript, err := readTranscriptWithRestore(logCtx, state)
 	if err != nil || len(fullTranscript) == 0 {
 		msg := "finalize: empty transcript, skipping"
 		if err != nil {
@@ -1776,7 +1976,13 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 		state.TurnCheckpointIDs = nil
 		return 1 // Count as error - all checkpoints will be skipped
 	}
-	store := checkpoint.NewGitStore(repo)
+	store := s.newGitStore(repo)
+	if cfg, loadErr := settings.Load(); loadErr == nil {
+		store.SetAppendOnly(cfg.IsAppendOnlyCheckpointsEnabled())
+	}
+
+	storedTranscript, _, filterStats := filterTranscriptForStorage(state.AgentType, fullTranscript, 0)
+	compressTranscript := compressTranscriptForStorage(len(storedTranscript))
 
 	// Update each checkpoint with the full transcript
 	for _, cpIDStr := range state.TurnCheckpointIDs {
@@ -1791,12 +1997,15 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 		}
 
 		updateErr := store.UpdateCommitted(context.Background(), checkpoint.UpdateCommittedOptions{
-			CheckpointID: cpID,
-			SessionID:    state.SessionID,
-			Transcript:   fullTranscript,
-			Prompts:      prompts,
-			Context:      contextBytes,
-			Agent:        state.AgentType,
+			CheckpointID:         cpID,
+			SessionID:            state.SessionID,
+			Transcript:           storedTranscript,
+			Prompts:              prompts,
+			Context:              contextBytes,
+			Agent:                state.AgentType,
+			TranscriptFilter:     filterStats,
+			CompressTranscript:   compressTranscript,
+			ContentDefinedChunks: features.Enabled(features.ContentDefinedChunks),
 		})
 		if updateErr != nil {
 			logging.Warn(logCtx, "finalize: failed to update checkpoint",
@@ -1824,47 +2033,28 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 }
 
 // filesChangedInCommit returns the set of files changed in a commit by diffing against its parent.
-func filesChangedInCommit(commit *object.Commit) map[string]struct{} {
-	result := make(map[string]struct{})
-
+func filesChangedInCommit(repo *git.Repository, commit *object.Commit) map[string]struct{} {
 	commitTree, err := commit.Tree()
 	if err != nil {
-		return result
+		return map[string]struct{}{}
 	}
 
+	// Initial commit: parentTree stays nil and all files are new
 	var parentTree *object.Tree
 	if commit.NumParents() > 0 {
 		parent, err := commit.Parent(0)
 		if err != nil {
-			return result
+			return map[string]struct{}{}
 		}
 		parentTree, err = parent.Tree()
 		if err != nil {
-			return result
+			return map[string]struct{}{}
 		}
 	}
 
-	if parentTree == nil {
-		// Initial commit — all files are new
-		if iterErr := commitTree.Files().ForEach(func(f *object.File) error {
-			result[f.Name] = struct{}{}
-			return nil
-		}); iterErr != nil {
-			return result
-		}
-		return result
-	}
-
-	changes, err := parentTree.Diff(commitTree)
+	result, err := changedFilesBetweenTrees(repo, parentTree, commitTree)
 	if err != nil {
-		return result
-	}
-	for _, change := range changes {
-		name := change.To.Name
-		if name == "" {
-			name = change.From.Name
-		}
-		result[name] = struct{}{}
+		return map[string]struct{}{}
 	}
 	return result
 }
@@ -1889,7 +2079,8 @@ func (s *ManualCommitStrategy) carryForwardToNewShadowBranch(
 	state *SessionState,
 	remainingFiles []string,
 ) {
-	store := checkpoint.NewGitStore(repo)
+	store := s.newGitStore(repo)
+	maxFileBytes, skipBinary := loadSnapshotLimits()
 
 	// Don't include metadata directory in carry-forward. The carry-forward branch
 	// only needs to preserve file content for comparison - not the transcript.
@@ -1904,6 +2095,8 @@ func (s *ManualCommitStrategy) carryForwardToNewShadowBranch(
 		MetadataDirAbs:    "",
 		CommitMessage:     "carry forward: uncommitted session files",
 		IsFirstCheckpoint: false,
+		MaxFileBytes:      maxFileBytes,
+		SkipBinaryFiles:   skipBinary,
 	})
 	if err != nil {
 		logging.Warn(logCtx, "post-commit: carry-forward failed",
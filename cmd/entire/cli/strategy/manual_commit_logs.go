@@ -38,7 +38,7 @@ func (s *ManualCommitStrategy) GetSessionInfo() (*SessionInfo, error) {
 
 	if head.Name().IsBranch() {
 		branchName := head.Name().Short()
-		if strings.HasPrefix(branchName, shadowBranchPrefix) {
+		if strings.HasPrefix(branchName, checkpoint.ShadowBranchPrefix()) {
 			return nil, ErrNoSession
 		}
 	}
@@ -72,7 +72,7 @@ func (s *ManualCommitStrategy) GetMetadataRef(checkpoint Checkpoint) string {
 	if checkpoint.CheckpointID.IsEmpty() {
 		return ""
 	}
-	return paths.MetadataBranchName + ":" + checkpoint.CheckpointID.Path()
+	return paths.MetadataBranchName() + ":" + checkpoint.CheckpointID.Path()
 }
 
 // GetSessionMetadataRef returns a reference to the most recent metadata commit for a session.
@@ -84,7 +84,7 @@ func (s *ManualCommitStrategy) GetSessionMetadataRef(_ string) string {
 	}
 
 	// Get the sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return ""
@@ -92,7 +92,7 @@ func (s *ManualCommitStrategy) GetSessionMetadataRef(_ string) string {
 
 	// The tip of entire/checkpoints/v1 contains all condensed sessions
 	// Return a reference to it (sessionID is not used as all sessions are on the same branch)
-	return trailers.FormatSourceRef(paths.MetadataBranchName, ref.Hash().String())
+	return trailers.FormatSourceRef(paths.MetadataBranchName(), ref.Hash().String())
 }
 
 // GetSessionContext returns the context.md content for a session.
@@ -116,7 +116,7 @@ func (s *ManualCommitStrategy) GetSessionContext(sessionID string) string {
 	}
 
 	// Get the sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref, err := repo.Reference(refName, true)
 	if err != nil {
 		return ""
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 
 	"github.com/go-git/go-git/v5"
@@ -44,36 +45,42 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 		return true, nil
 	}
 
-	oldRefName := plumbing.NewBranchReferenceName(oldShadowBranch)
-	oldRef, err := repo.Reference(oldRefName, true)
+	moved, err := moveShadowBranch(repo, oldShadowBranch, newShadowBranch)
 	if err != nil {
-		// Old shadow branch doesn't exist - just update state.BaseCommit
+		return false, err
+	}
+	if moved {
+		fmt.Fprintf(os.Stderr, "Moved shadow branch from %s to %s (HEAD changed during session)\n",
+			oldShadowBranch, newShadowBranch)
+	} else {
 		// This can happen if this is the first checkpoint after HEAD changed
-		state.BaseCommit = currentHead
 		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", currentHead[:7])
-		return true, nil //nolint:nilerr // err is "reference not found" which is fine - just need to update state
 	}
 
-	// Old shadow branch exists - move it to new base commit
-	newRefName := plumbing.NewBranchReferenceName(newShadowBranch)
+	// Update state with new base commit
+	state.BaseCommit = currentHead
+	return true, nil
+}
+
+// moveShadowBranch renames shadow branch oldBranch to newBranch. Returns
+// false if oldBranch does not exist.
+func moveShadowBranch(repo *git.Repository, oldBranch, newBranch string) (bool, error) {
+	oldRef, err := repo.Reference(plumbing.NewBranchReferenceName(oldBranch), true)
+	if err != nil {
+		return false, nil //nolint:nilerr // err is "reference not found": there is nothing to move
+	}
 
 	// Create new reference pointing to same commit as old shadow branch
-	newRef := plumbing.NewHashReference(newRefName, oldRef.Hash())
-	if err := repo.Storer.SetReference(newRef); err != nil {
-		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newShadowBranch, err)
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(newBranch), oldRef.Hash())
+	if err := audit.SetReference(repo, newRef); err != nil {
+		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newBranch, err)
 	}
 
 	// Delete old reference via CLI (go-git v5's RemoveReference doesn't persist with packed refs/worktrees)
-	if err := DeleteBranchCLI(oldShadowBranch); err != nil {
+	if err := DeleteBranchCLI(oldBranch); err != nil {
 		// Non-fatal: log but continue - the important thing is the new branch exists
-		fmt.Fprintf(os.Stderr, "Warning: failed to remove old shadow branch %s: %v\n", oldShadowBranch, err)
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove old shadow branch %s: %v\n", oldBranch, err)
 	}
-
-	fmt.Fprintf(os.Stderr, "Moved shadow branch from %s to %s (HEAD changed during session)\n",
-		oldShadowBranch, newShadowBranch)
-
-	// Update state with new base commit
-	state.BaseCommit = currentHead
 	return true, nil
 }
 
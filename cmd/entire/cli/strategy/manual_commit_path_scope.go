@@ -0,0 +1,32 @@
+package strategy
+
+import (
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// loadPathScope returns the settings when track_paths or ignore_paths is set,
+// or nil when every file is tracked.
+func loadPathScope() *settings.EntireSettings {
+	s, err := settings.Load()
+	if err != nil || !s.HasPathScope() {
+		return nil
+	}
+	return s
+}
+
+// filterTrackedPaths returns the files among files that scope tracks, in the
+// order given. A nil scope tracks every file. Files outside the scope are
+// neither checkpointed nor condensed, so an agent's edits elsewhere in a
+// monorepo stay out of the session.
+func filterTrackedPaths(scope *settings.EntireSettings, files []string) []string {
+	if scope == nil || len(files) == 0 {
+		return files
+	}
+	tracked := make([]string, 0, len(files))
+	for _, file := range files {
+		if scope.TracksPath(file) {
+			tracked = append(tracked, file)
+		}
+	}
+	return tracked
+}
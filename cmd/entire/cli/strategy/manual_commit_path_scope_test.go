@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveStep_TrackPaths verifies that files outside track_paths, or
+// matching ignore_paths, are neither checkpointed nor tracked by the session.
+func TestSaveStep_TrackPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "track_paths": ["services/payments"], "ignore_paths": ["*.gen.go"]}`), 0o644))
+	for _, file := range []string{"services/payments/pay.go", "services/payments/api.gen.go", "services/billing/bill.go"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(file)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("package x\n"), 0o644))
+	}
+
+	s := &ManualCommitStrategy{}
+	sessionID := "2026-05-02-track-paths"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(metadataDirAbs, "full.jsonl"), []byte("{}\n"), 0o644))
+
+	// The first step captures the whole working tree, so track a second one
+	for i, message := range []string{"Checkpoint 1", "Checkpoint 2"} {
+		if i == 1 {
+			for _, file := range []string{"services/payments/pay.go", "services/payments/api.gen.go", "services/billing/bill.go"} {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte("package x // changed\n"), 0o644))
+			}
+		}
+		require.NoError(t, s.SaveStep(StepContext{
+			SessionID:      sessionID,
+			NewFiles:       []string{"services/payments/pay.go", "services/payments/api.gen.go", "services/billing/bill.go"},
+			MetadataDir:    metadataDir,
+			MetadataDirAbs: metadataDirAbs,
+			CommitMessage:  message,
+			AuthorName:     "Test",
+			AuthorEmail:    "test@test.com",
+		}))
+	}
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"services/payments/pay.go"}, state.FilesTouched)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)), true)
+	require.NoError(t, err)
+	shadowCommit, err := repo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	content := func(file string) string {
+		t.Helper()
+		f, err := shadowCommit.File(file)
+		require.NoError(t, err)
+		data, err := f.Contents()
+		require.NoError(t, err)
+		return data
+	}
+	assert.Equal(t, "package x // changed\n", content("services/payments/pay.go"))
+	assert.Equal(t, "package x\n", content("services/billing/bill.go"), "untracked paths keep their first snapshot")
+}
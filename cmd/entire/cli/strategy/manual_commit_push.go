@@ -1,6 +1,13 @@
 package strategy
 
-import "github.com/entireio/cli/cmd/entire/cli/paths"
+import (
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// condensePushRemote is the remote entire/checkpoints/v1 is pushed to after
+// condensation when strategy_options.push_on_condense is enabled.
+const condensePushRemote = "origin"
 
 // PrePush is called by the git pre-push hook before pushing to a remote.
 // It pushes the entire/checkpoints/v1 branch alongside the user's push.
@@ -9,5 +16,44 @@ import "github.com/entireio/cli/cmd/entire/cli/paths"
 //   - "prompt" (default): ask user with option to enable auto
 //   - "false"/"off"/"no": never push
 func (s *ManualCommitStrategy) PrePush(remote string) error {
-	return pushSessionsBranchCommon(remote, paths.MetadataBranchName)
+	return pushSessionsBranchCommon(remote, paths.MetadataBranchName())
+}
+
+// startCondensePush starts `entire worker push` in the background. A variable
+// so tests can replace it.
+var startCondensePush = startDetachedCondensePush
+
+// pushAfterCondense pushes entire/checkpoints/v1 to origin when
+// strategy_options.push_on_condense is enabled, so checkpoints condensed on
+// this machine reach others without waiting for the next git push. The push
+// runs in a detached `entire worker push`, so the commit does not wait on the
+// network. Does nothing if the repository has no origin remote.
+func pushAfterCondense() {
+	s, err := settings.Load()
+	if err != nil || !s.IsPushOnCondenseEnabled() {
+		return
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return
+	}
+	if _, err := repo.Remote(condensePushRemote); err != nil {
+		return
+	}
+	startCondensePush()
+}
+
+// PushCondensedCheckpoints pushes entire/checkpoints/v1 to origin, retrying
+// network errors with backoff and merging checkpoints another machine pushed
+// first. It is run by `entire worker push`, which post-commit starts in the
+// background. Failures are printed as warnings.
+func PushCondensedCheckpoints() {
+	repo, err := OpenRepository()
+	if err != nil {
+		return
+	}
+	if _, err := repo.Remote(condensePushRemote); err != nil {
+		return
+	}
+	_ = doPushSessionsBranch(condensePushRemote, paths.MetadataBranchName(), backgroundPushPolicy) //nolint:errcheck // Warnings already printed
 }
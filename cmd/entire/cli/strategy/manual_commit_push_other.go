@@ -0,0 +1,11 @@
+//go:build !unix
+
+package strategy
+
+import "github.com/entireio/cli/cmd/entire/cli/paths"
+
+// startDetachedCondensePush pushes in the hook on platforms without
+// detached processes, once and with a short timeout.
+func startDetachedCondensePush() {
+	_ = doPushSessionsBranch(condensePushRemote, paths.MetadataBranchName(), hookPushPolicy) //nolint:errcheck // Warnings already printed; never fails the commit
+}
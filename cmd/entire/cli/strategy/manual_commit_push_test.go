@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushAfterCondense_RunsInBackground(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	remote := t.TempDir()
+	runGitInDir(t, remote, "init", "-q", "--bare")
+	runGitInDir(t, dir, "remote", "add", "origin", remote)
+	runGitInDir(t, dir, "branch", paths.MetadataBranchName())
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "strategy_options": {"push_on_condense": true}}`), 0o644))
+
+	started := 0
+	start := startCondensePush
+	startCondensePush = func() { started++ }
+	t.Cleanup(func() { startCondensePush = start })
+
+	pushAfterCondense()
+	assert.Equal(t, 1, started, "the push is handed to a background worker")
+	err := exec.CommandContext(context.Background(), "git", "-C", remote, "rev-parse", "--verify", "-q", paths.MetadataBranchName()).Run()
+	assert.Error(t, err, "post-commit must not push itself")
+}
+
+func TestDoPushSessionsBranch_HookPolicyDoesNotRetry(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	runGitInDir(t, dir, "remote", "add", "origin", filepath.Join(t.TempDir(), "missing.git"))
+	runGitInDir(t, dir, "branch", paths.MetadataBranchName())
+
+	policy := hookPushPolicy
+	policy.attempts = 5
+	started := time.Now()
+	require.NoError(t, doPushSessionsBranch("origin", paths.MetadataBranchName(), policy))
+	assert.Less(t, time.Since(started), backgroundPushPolicy.retryDelay, "a failed hook push gives up without sleeping")
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package strategy
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// startDetachedCondensePush starts `entire worker push` in its own process
+// group, so it keeps running after the post-commit hook exits.
+func startDetachedCondensePush() {
+	executable, err := os.Executable()
+	if err != nil {
+		return
+	}
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return
+	}
+
+	cmd := exec.CommandContext(context.Background(), executable, "worker", "push")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Dir = repoRoot
+	cmd.Env = os.Environ()
+	cmd.Stdout = io.Discard
+	cmd.Stderr = io.Discard
+	if err := cmd.Start(); err != nil {
+		return
+	}
+	_ = cmd.Process.Release() //nolint:errcheck // Best effort - the push continues regardless
+}
@@ -0,0 +1,112 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/qualitygate"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// runQualityGate runs the user's configured quality gate command
+// (quality_gate.command) against the session's touched files and records the
+// result in state.LastQualityGate. Does nothing when no command is configured
+// or no touched file still exists; failures to run the command are logged and
+// never block the turn end.
+func runQualityGate(state *SessionState) {
+	s, err := settings.Load()
+	if err != nil || s.QualityGateCommand() == "" {
+		return
+	}
+	logCtx := logging.WithComponent(context.Background(), "quality-gate")
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		logging.Warn(logCtx, "quality gate skipped: failed to find repository root",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	// Deleted files can't be tested; passing them would fail most runners.
+	files := existingTouchedFiles(repoRoot, state)
+	if len(files) == 0 {
+		return
+	}
+
+	runner := &qualitygate.Runner{
+		Command: s.QualityGateCommand(),
+		Dir:     repoRoot,
+		Timeout: s.QualityGate.Timeout(),
+	}
+	result, err := runner.Run(logCtx, files)
+	if err != nil {
+		logging.Warn(logCtx, "quality gate command could not run",
+			slog.String("session_id", state.SessionID),
+			slog.String("error", err.Error()))
+		return
+	}
+	state.LastQualityGate = result
+
+	logging.Info(logCtx, "quality gate recorded",
+		slog.String("session_id", state.SessionID),
+		slog.Bool("passed", result.Passed),
+		slog.Int("exit_code", result.ExitCode),
+		slog.Int("files", len(files)))
+	if !result.Passed {
+		fmt.Fprintf(os.Stderr, "[entire] Quality gate %s: %s\n", result.Summary(), result.Command)
+	}
+}
+
+// existingTouchedFiles returns the session's touched files that still exist.
+func existingTouchedFiles(repoRoot string, state *SessionState) []string {
+	var files []string
+	for _, f := range state.FilesTouched {
+		if _, err := os.Stat(filepath.Join(repoRoot, f)); err == nil {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// warnFailedQualityGates writes a warning for each session whose last quality
+// gate failed and whose touched files are staged for this commit. Only runs
+// when quality_gate.warn_on_commit is set.
+func warnFailedQualityGates(w io.Writer, repo *git.Repository, sessions []*SessionState) {
+	s, err := settings.Load()
+	if err != nil || s.QualityGate == nil || !s.QualityGate.WarnOnCommit {
+		return
+	}
+
+	var staged []string
+	for _, state := range sessions {
+		gate := state.LastQualityGate
+		if gate == nil || gate.Passed {
+			continue
+		}
+		if staged == nil {
+			staged = getStagedFiles(repo)
+		}
+		var files []string
+		for _, f := range state.FilesTouched {
+			if slices.Contains(staged, f) {
+				files = append(files, f)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "[entire] Warning: the last quality gate for session %s %s at %s.\n",
+			state.SessionID, gate.Summary(), gate.RanAt.Local().Format("2006-01-02 15:04"))
+		fmt.Fprintf(w, "[entire] This commit includes files it changed: %s\n", strings.Join(files, ", "))
+	}
+}
@@ -0,0 +1,78 @@
+package strategy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeQualityGateSettings(t *testing.T, dir, gate string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	settingsJSON := `{"enabled": true, "quality_gate": ` + gate + `}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settingsJSON), 0o644))
+}
+
+func TestRunQualityGate(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// No gate configured: nothing is recorded.
+	state := &SessionState{SessionID: "s1", FilesTouched: []string{"test.txt"}}
+	runQualityGate(state)
+	assert.Nil(t, state.LastQualityGate)
+
+	// Deleted files are not passed to the command.
+	writeQualityGateSettings(t, dir, `{"command": "echo \"$@\"; test \"$#\" -eq 1 && exit 2"}`)
+	state.FilesTouched = []string{"test.txt", "deleted.txt"}
+	runQualityGate(state)
+	require.NotNil(t, state.LastQualityGate)
+	assert.False(t, state.LastQualityGate.Passed)
+	assert.Equal(t, 2, state.LastQualityGate.ExitCode)
+	assert.Equal(t, []string{"test.txt"}, state.LastQualityGate.Files)
+	assert.Equal(t, "test.txt", state.LastQualityGate.Output)
+
+	// Only deleted files: the gate is skipped and the last result kept.
+	state.FilesTouched = []string{"deleted.txt"}
+	runQualityGate(state)
+	assert.Equal(t, 2, state.LastQualityGate.ExitCode)
+}
+
+func TestWarnFailedQualityGates(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	writeQualityGateSettings(t, dir, `{"command": "exit 1"}`)
+
+	state := &SessionState{SessionID: "s1", FilesTouched: []string{"test.txt"}}
+	runQualityGate(state)
+	require.NotNil(t, state.LastQualityGate)
+
+	require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), "broken"))
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("test.txt")
+	require.NoError(t, err)
+
+	// warn_on_commit is off by default.
+	var out bytes.Buffer
+	warnFailedQualityGates(&out, repo, []*SessionState{state})
+	assert.Empty(t, out.String())
+
+	writeQualityGateSettings(t, dir, `{"command": "exit 1", "warn_on_commit": true}`)
+	warnFailedQualityGates(&out, repo, []*SessionState{state})
+	assert.Contains(t, out.String(), "last quality gate for session s1 failed (exit 1)")
+	assert.Contains(t, out.String(), "includes files it changed: test.txt")
+
+	// Sessions whose files aren't staged are not reported.
+	out.Reset()
+	other := &SessionState{SessionID: "s2", FilesTouched: []string{"other.txt"}, LastQualityGate: state.LastQualityGate}
+	warnFailedQualityGates(&out, repo, []*SessionState{other})
+	assert.Empty(t, out.String())
+}
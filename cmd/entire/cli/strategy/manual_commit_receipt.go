@@ -0,0 +1,123 @@
+package strategy
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// condensationReceipt collects what one PostCommit condensed into a
+// checkpoint, for the receipt printed once all sessions are processed.
+type condensationReceipt struct {
+	CheckpointID id.CheckpointID
+	Sessions     []receiptSession
+	Files        []string // Files attributed to the checkpoint, across sessions
+	Tokens       int
+	CarryForward []string // Uncommitted agent files carried to the next checkpoint
+}
+
+type receiptSession struct {
+	SessionID string
+	Steps     int
+}
+
+// add records a condensed session and the files it carries forward.
+func (r *condensationReceipt) add(result *CondenseResult, remaining []string) {
+	if result == nil {
+		return
+	}
+	r.Sessions = append(r.Sessions, receiptSession{SessionID: result.SessionID, Steps: result.CheckpointsCount})
+	r.Files = mergeFilesTouched(r.Files, result.FilesTouched)
+	r.Tokens += receiptTokens(result.TokenUsage)
+	r.CarryForward = mergeFilesTouched(r.CarryForward, remaining)
+}
+
+// printCondensationReceipt prints the receipt in the output.receipt mode.
+// Nothing is printed when no session was condensed.
+func printCondensationReceipt(w io.Writer, r *condensationReceipt) {
+	mode := settings.ReceiptShort
+	if s, err := settings.Load(); err == nil {
+		mode = s.ReceiptMode()
+	}
+	writeCondensationReceipt(w, r, mode)
+}
+
+// writeCondensationReceipt writes the receipt in the given mode: one line
+// for "short", one line per field for "full", nothing for "off".
+func writeCondensationReceipt(w io.Writer, r *condensationReceipt, mode string) {
+	if r == nil || len(r.Sessions) == 0 || mode == settings.ReceiptOff {
+		return
+	}
+
+	if mode != settings.ReceiptFull {
+		parts := []string{
+			plural(len(r.Sessions), "session", "sessions"),
+			plural(len(r.Files), "file", "files"),
+			formatReceiptTokens(r.Tokens) + " tokens",
+		}
+		if len(r.CarryForward) > 0 {
+			parts = append(parts, plural(len(r.CarryForward), "file", "files")+" carried forward")
+		}
+		fmt.Fprintf(w, "[entire] Checkpoint %s: %s\n", r.CheckpointID, strings.Join(parts, ", "))
+		return
+	}
+
+	sessions := make([]string, 0, len(r.Sessions))
+	for _, sess := range r.Sessions {
+		sessions = append(sessions, fmt.Sprintf("%s (%s)", sess.SessionID, plural(sess.Steps, "step", "steps")))
+	}
+	fmt.Fprintf(w, "[entire] Checkpoint %s recorded\n", r.CheckpointID)
+	fmt.Fprintf(w, "  sessions:       %s\n", strings.Join(sessions, ", "))
+	fmt.Fprintf(w, "  files:          %s\n", receiptFileList(r.Files))
+	fmt.Fprintf(w, "  tokens:         %s\n", formatReceiptTokens(r.Tokens))
+	if len(r.CarryForward) > 0 {
+		fmt.Fprintf(w, "  carry-forward:  %s\n", receiptFileList(r.CarryForward))
+	} else {
+		fmt.Fprintf(w, "  carry-forward:  none\n")
+	}
+}
+
+// receiptFileList returns a count followed by up to three file names.
+func receiptFileList(files []string) string {
+	if len(files) == 0 {
+		return "none"
+	}
+	const maxListed = 3
+	listed := slices.Clone(files)
+	slices.Sort(listed)
+	suffix := ""
+	if len(listed) > maxListed {
+		suffix = fmt.Sprintf(", +%d more", len(listed)-maxListed)
+		listed = listed[:maxListed]
+	}
+	return fmt.Sprintf("%s (%s%s)", plural(len(files), "file", "files"), strings.Join(listed, ", "), suffix)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return strconv.Itoa(n) + " " + many
+}
+
+// receiptTokens sums all token fields, including subagent tokens.
+func receiptTokens(tu *agent.TokenUsage) int {
+	if tu == nil {
+		return 0
+	}
+	return tu.InputTokens + tu.CacheCreationTokens + tu.CacheReadTokens + tu.OutputTokens + receiptTokens(tu.SubagentTokens)
+}
+
+// formatReceiptTokens formats a token count: 500 → "500", 14300 → "14.3k".
+func formatReceiptTokens(n int) string {
+	if n < 1000 {
+		return strconv.Itoa(n)
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000.0), ".0") + "k"
+}
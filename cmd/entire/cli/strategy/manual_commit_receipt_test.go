@@ -0,0 +1,67 @@
+package strategy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+func testReceipt() *condensationReceipt {
+	r := &condensationReceipt{CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6")}
+	r.add(&CondenseResult{
+		SessionID:        "2026-05-01-first",
+		CheckpointsCount: 3,
+		FilesTouched:     []string{"b.go", "a.go"},
+		TokenUsage:       &agent.TokenUsage{InputTokens: 1000, OutputTokens: 200, SubagentTokens: &agent.TokenUsage{OutputTokens: 100}},
+	}, []string{"c.go"})
+	r.add(&CondenseResult{
+		SessionID:        "2026-05-01-second",
+		CheckpointsCount: 1,
+		FilesTouched:     []string{"a.go", "d.go", "e.go"},
+		TokenUsage:       &agent.TokenUsage{InputTokens: 500},
+	}, nil)
+	r.add(nil, []string{"ignored.go"})
+	return r
+}
+
+func TestWriteCondensationReceipt_Short(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	writeCondensationReceipt(&buf, testReceipt(), settings.ReceiptShort)
+
+	want := "[entire] Checkpoint a1b2c3d4e5f6: 2 sessions, 4 files, 1.8k tokens, 1 file carried forward\n"
+	if buf.String() != want {
+		t.Errorf("short receipt = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteCondensationReceipt_Full(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	writeCondensationReceipt(&buf, testReceipt(), settings.ReceiptFull)
+
+	want := "[entire] Checkpoint a1b2c3d4e5f6 recorded\n" +
+		"  sessions:       2026-05-01-first (3 steps), 2026-05-01-second (1 step)\n" +
+		"  files:          4 files (a.go, b.go, d.go, +1 more)\n" +
+		"  tokens:         1.8k\n" +
+		"  carry-forward:  1 file (c.go)\n"
+	if buf.String() != want {
+		t.Errorf("full receipt =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCondensationReceipt_Silent(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	writeCondensationReceipt(&buf, testReceipt(), settings.ReceiptOff)
+	writeCondensationReceipt(&buf, &condensationReceipt{CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6")}, settings.ReceiptFull)
+	if buf.Len() != 0 {
+		t.Errorf("expected no receipt, got %q", buf.String())
+	}
+}
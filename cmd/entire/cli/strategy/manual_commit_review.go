@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/review"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// reviewCondensedCommit runs the user's configured review command (review.command)
+// on the HEAD commit's diff for the session's files. Returns nil when no
+// command is configured or the review fails; failures are logged and never
+// block condensation.
+func reviewCondensedCommit(checkpointID id.CheckpointID, sessionID string, filesTouched []string, committedFiles map[string]struct{}, prompts []string) []byte {
+	s, err := settings.Load()
+	if err != nil || s.ReviewCommand() == "" {
+		return nil
+	}
+	logCtx := logging.WithComponent(context.Background(), "review")
+
+	files := reviewFiles(filesTouched, committedFiles)
+	if len(files) == 0 {
+		return nil
+	}
+
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		logging.Warn(logCtx, "review skipped: failed to find repository root",
+			slog.String("error", err.Error()))
+		return nil
+	}
+
+	diff, commit, err := headDiff(repoRoot, files)
+	if err != nil {
+		logging.Warn(logCtx, "review skipped: failed to compute diff",
+			slog.String("session_id", sessionID),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	if diff == "" {
+		return nil
+	}
+
+	runner := &review.Runner{
+		Command: s.ReviewCommand(),
+		Dir:     repoRoot,
+		Timeout: s.Review.Timeout(),
+	}
+	out, err := runner.Run(logCtx, review.Input{
+		CheckpointID: checkpointID.String(),
+		SessionID:    sessionID,
+		Commit:       commit,
+		FilesTouched: files,
+		Prompts:      prompts,
+		Diff:         diff,
+	})
+	if err != nil {
+		logging.Warn(logCtx, "review command failed",
+			slog.String("session_id", sessionID),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	if out == "" {
+		return nil
+	}
+	logging.Info(logCtx, "review recorded",
+		slog.String("session_id", sessionID),
+		slog.String("checkpoint_id", checkpointID.String()))
+	return []byte(out)
+}
+
+// reviewFiles returns the session's files that were part of the commit, or all
+// of the session's files if the committed set is unknown.
+func reviewFiles(filesTouched []string, committedFiles map[string]struct{}) []string {
+	if len(committedFiles) == 0 {
+		return filesTouched
+	}
+	var files []string
+	for _, f := range filesTouched {
+		if _, ok := committedFiles[f]; ok {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// headDiff returns the HEAD commit's diff limited to files, and HEAD's hash.
+func headDiff(repoRoot string, files []string) (diff, commit string, err error) {
+	ctx := context.Background()
+
+	revCmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	revCmd.Dir = repoRoot
+	rev, err := revCmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	args := append([]string{"show", "--format=", "--no-color", "--no-ext-diff", "HEAD", "--"}, files...)
+	showCmd := exec.CommandContext(ctx, "git", args...)
+	showCmd.Dir = repoRoot
+	out, err := showCmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to diff HEAD: %w", err)
+	}
+	return string(out), strings.TrimSpace(string(rev)), nil
+}
@@ -0,0 +1,51 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReviewFiles(t *testing.T) {
+	t.Parallel()
+
+	touched := []string{"a.go", "b.go", "c.go"}
+	assert.Equal(t, touched, reviewFiles(touched, nil))
+	assert.Equal(t, []string{"a.go", "c.go"}, reviewFiles(touched, map[string]struct{}{"c.go": {}, "a.go": {}, "other.go": {}}))
+	assert.Empty(t, reviewFiles(touched, map[string]struct{}{"other.go": {}}))
+}
+
+func TestReviewCondensedCommit(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// No review configured: the command is never run.
+	checkpointID := id.MustCheckpointID("a1b2c3d4e5f6")
+	assert.Nil(t, reviewCondensedCommit(checkpointID, "s1", []string{"test.txt"}, nil, nil))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	settingsJSON := `{"enabled": true, "review": {"command": "grep -c '+updated' && echo \"checked $ENTIRE_CHECKPOINT_ID\""}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settingsJSON), 0o644))
+
+	require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), "updated content"))
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("test.txt")
+	require.NoError(t, err)
+	_, err = wt.Commit("update", &git.CommitOptions{})
+	require.NoError(t, err)
+
+	got := reviewCondensedCommit(checkpointID, "s1", []string{"test.txt"}, map[string]struct{}{"test.txt": {}}, []string{"update it"})
+	assert.Equal(t, "1\nchecked a1b2c3d4e5f6", string(got))
+
+	// Files outside the commit are not reviewed.
+	assert.Nil(t, reviewCondensedCommit(checkpointID, "s1", []string{"test.txt"}, map[string]struct{}{"other.txt": {}}, nil))
+}
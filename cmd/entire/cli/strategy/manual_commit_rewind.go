@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	cpkg "github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -287,6 +288,15 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 		}
 	}
 
+	// Files the checkpoint recorded by hash only are left as they are on disk:
+	// the checkpoint has no content to restore them from
+	omittedFiles := make(map[string]bool)
+	if metadataDir, ok := trailers.ParseMetadata(commit.Message); ok {
+		for _, f := range cpkg.ReadOmittedFiles(tree, metadataDir) {
+			omittedFiles[f.Path] = true
+		}
+	}
+
 	// Build set of files in the checkpoint tree (excluding metadata)
 	checkpointFiles := make(map[string]bool)
 	err = tree.Files().ForEach(func(f *object.File) error {
@@ -351,19 +361,30 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 			continue
 		}
 
+		// If the checkpoint left the file's content out, keep what is on disk
+		if omittedFiles[relPath] {
+			continue
+		}
+
 		// File is untracked and not in checkpoint - delete it
 		absPath := filepath.Join(repoRoot, relPath)
 		if removeErr := os.Remove(absPath); removeErr == nil {
+			audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileDelete, Target: relPath})
 			fmt.Fprintf(os.Stderr, "  Deleted: %s\n", relPath)
 		}
 	}
 
 	// Restore files from checkpoint
+	var lfsPointers []string
 	err = tree.Files().ForEach(func(f *object.File) error {
 		// Skip metadata directories - these are for checkpoint storage, not working dir
 		if strings.HasPrefix(f.Name, entireDir) {
 			return nil
 		}
+		if omittedFiles[f.Name] {
+			fmt.Fprintf(os.Stderr, "  Kept (not stored in checkpoint): %s\n", f.Name)
+			return nil
+		}
 
 		contents, err := f.Contents()
 		if err != nil {
@@ -387,6 +408,10 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 		if err := os.WriteFile(f.Name, []byte(contents), perm); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", f.Name, err)
 		}
+		audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileWrite, Target: f.Name})
+		if _, ok := cpkg.ParseLFSPointer([]byte(contents)); ok {
+			lfsPointers = append(lfsPointers, f.Name)
+		}
 
 		fmt.Fprintf(os.Stderr, "  Restored: %s\n", f.Name)
 		return nil
@@ -394,6 +419,7 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 	if err != nil {
 		return fmt.Errorf("failed to iterate tree files: %w", err)
 	}
+	smudgeRestoredLFSFiles(repo, repoRoot, lfsPointers)
 
 	fmt.Println()
 	if len(point.ID) >= 7 {
@@ -406,6 +432,40 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 	return nil
 }
 
+// smudgeRestoredLFSFiles replaces the Git LFS pointers rewind wrote for
+// LFS-tracked paths with their content from the local LFS object store, as a
+// checkout would. Pointers whose object is not downloaded are left for
+// `git lfs pull`. GIT_LFS_SKIP_SMUDGE=1 keeps every pointer, as it does for git-lfs.
+func smudgeRestoredLFSFiles(repo *git.Repository, repoRoot string, pointers []string) {
+	if len(pointers) == 0 || os.Getenv("GIT_LFS_SKIP_SMUDGE") == "1" {
+		return
+	}
+	tracked := cpkg.LFSTrackedPaths(repoRoot, pointers)
+	for _, file := range pointers {
+		if !tracked[file] {
+			continue
+		}
+		pointer, err := os.ReadFile(file) //nolint:gosec // file was just restored from the checkpoint tree
+		if err != nil {
+			continue
+		}
+		content, ok := cpkg.SmudgeLFSPointer(repoRoot, pointer)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "  Kept LFS pointer (object not downloaded): %s\n", file)
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		if err := os.WriteFile(file, content, info.Mode().Perm()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to smudge LFS file %s: %v\n", file, err)
+			continue
+		}
+		audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileWrite, Target: file})
+	}
+}
+
 // resetShadowBranchToCheckpoint resets the shadow branch HEAD to the given checkpoint.
 // This ensures that when the user commits after rewinding, the next checkpoint will only
 // include prompts from the rewound point, not prompts from later checkpoints.
@@ -431,7 +491,7 @@ func (s *ManualCommitStrategy) resetShadowBranchToCheckpoint(repo *git.Repositor
 
 	// Update the reference to point to the checkpoint commit
 	ref := plumbing.NewHashReference(refName, commit.Hash)
-	if err := repo.Storer.SetReference(ref); err != nil {
+	if err := audit.SetReference(repo, ref); err != nil {
 		return fmt.Errorf("failed to update shadow branch: %w", err)
 	}
 
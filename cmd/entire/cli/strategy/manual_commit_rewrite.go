@@ -0,0 +1,183 @@
+package strategy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// RewrittenCommit is a commit replaced by a rebase or amend, as reported to
+// the post-rewrite hook. Several commits squashed into one share New.
+type RewrittenCommit struct {
+	Old plumbing.Hash
+	New plumbing.Hash
+}
+
+// ParseRewrittenCommits parses the "<old-sha> <new-sha> [<extra>]" lines git
+// writes to the post-rewrite hook's stdin.
+func ParseRewrittenCommits(r io.Reader) ([]RewrittenCommit, error) {
+	var rewritten []RewrittenCommit
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || !plumbing.IsHash(fields[0]) || !plumbing.IsHash(fields[1]) {
+			return nil, fmt.Errorf("invalid rewritten commit line %q", scanner.Text())
+		}
+		rewritten = append(rewritten, RewrittenCommit{
+			Old: plumbing.NewHash(fields[0]),
+			New: plumbing.NewHash(fields[1]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rewritten commits: %w", err)
+	}
+	return rewritten, nil
+}
+
+// PostRewrite is called by the git post-rewrite hook after a rebase
+// (rewriteType "rebase") or an amend ("amend"). The rewritten commits take
+// over their originals' links here: see remapRewrittenCommits.
+func (s *ManualCommitStrategy) PostRewrite(rewriteType string, rewritten []RewrittenCommit) error {
+	if (rewriteType != "rebase" && rewriteType != "amend") || len(rewritten) == 0 {
+		return nil
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	s.remapRewrittenCommits(repo, rewritten)
+	return nil
+}
+
+// remapRewrittenCommits moves what referred to rewritten commits to their
+// replacements: sessions based on an old commit get the new one as their
+// base, with their shadow branch renamed to match, and each new commit is
+// stamped with the checkpoints its old commit was linked to, by trailer or
+// stamp, that it is not linked to itself (e.g. because a squash or reword
+// dropped the trailer).
+func (s *ManualCommitStrategy) remapRewrittenCommits(repo *git.Repository, rewritten []RewrittenCommit) {
+	logCtx := logging.WithComponent(context.Background(), "checkpoint")
+	s.remapSessionBaseCommits(logCtx, repo, rewritten)
+
+	store := s.newGitStore(repo)
+	oldCommits := make([]plumbing.Hash, 0, len(rewritten))
+	for _, rc := range rewritten {
+		oldCommits = append(oldCommits, rc.Old)
+	}
+	stamped, err := store.StampedCheckpoints(logCtx, oldCommits)
+	if err != nil {
+		logging.Warn(logCtx, "failed to look up checkpoints stamped on rewritten commits",
+			slog.String("error", err.Error()),
+		)
+	}
+
+	for _, rc := range rewritten {
+		newCommit, err := repo.CommitObject(rc.New)
+		if err != nil {
+			continue
+		}
+		linked := trailers.ParseAllCheckpoints(newCommit.Message)
+		var missing []id.CheckpointID
+		if oldCommit, err := repo.CommitObject(rc.Old); err == nil {
+			for _, cpID := range trailers.ParseAllCheckpoints(oldCommit.Message) {
+				if !slices.Contains(linked, cpID) {
+					missing = append(missing, cpID)
+				}
+			}
+		}
+		for _, cpID := range stamped[rc.Old] {
+			if !slices.Contains(linked, cpID) && !slices.Contains(missing, cpID) {
+				missing = append(missing, cpID)
+			}
+		}
+
+		for _, cpID := range missing {
+			if _, err := store.StampCommit(logCtx, cpID, rc.New); err != nil {
+				logging.Warn(logCtx, "failed to stamp rewritten commit",
+					slog.String("checkpoint_id", cpID.String()),
+					slog.String("commit", TruncateHash(rc.New.String())),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			logging.Debug(logCtx, "stamped rewritten commit",
+				slog.String("checkpoint_id", cpID.String()),
+				slog.String("old_commit", TruncateHash(rc.Old.String())),
+				slog.String("new_commit", TruncateHash(rc.New.String())),
+			)
+		}
+	}
+}
+
+// remapSessionBaseCommits moves this worktree's sessions based on a rewritten
+// commit, and their shadow branches, to its replacement. A session is left
+// alone if its new shadow branch name is taken, e.g. when commits that both
+// had one were squashed together.
+func (s *ManualCommitStrategy) remapSessionBaseCommits(logCtx context.Context, repo *git.Repository, rewritten []RewrittenCommit) {
+	worktreePath, err := paths.WorktreeRoot()
+	if err != nil {
+		return
+	}
+	tx, err := s.beginSessionTx(worktreePath)
+	if err != nil {
+		return
+	}
+	defer commitSessionTx(tx)
+
+	newBase := make(map[string]string, len(rewritten))
+	for _, rc := range rewritten {
+		newBase[rc.Old.String()] = rc.New.String()
+	}
+	for _, state := range s.findSessionsForWorktreeInTx(tx, repo, worktreePath) {
+		oldBase := state.BaseCommit
+		base, ok := newBase[oldBase]
+		if !ok {
+			continue
+		}
+		oldBranch := getShadowBranchNameForCommit(oldBase, state.WorktreeID)
+		newBranch := getShadowBranchNameForCommit(base, state.WorktreeID)
+		if oldBranch != newBranch {
+			if _, err := repo.Reference(plumbing.NewBranchReferenceName(newBranch), true); err == nil {
+				if _, err := repo.Reference(plumbing.NewBranchReferenceName(oldBranch), true); err == nil {
+					logging.Warn(logCtx, "session base commit was rewritten, but its new shadow branch already exists",
+						slog.String("session_id", state.SessionID),
+						slog.String("shadow_branch", newBranch),
+					)
+					continue
+				}
+			}
+			if _, err := moveShadowBranch(repo, oldBranch, newBranch); err != nil {
+				logging.Warn(logCtx, "failed to move shadow branch of rewritten commit",
+					slog.String("session_id", state.SessionID),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+		}
+		state.BaseCommit = base
+		if state.AttributionBaseCommit == oldBase {
+			state.AttributionBaseCommit = base
+		}
+		tx.Save(state)
+		logging.Debug(logCtx, "re-pointed session to rewritten commit",
+			slog.String("session_id", state.SessionID),
+			slog.String("old_base", TruncateHash(oldBase)),
+			slog.String("new_base", TruncateHash(base)),
+		)
+	}
+}
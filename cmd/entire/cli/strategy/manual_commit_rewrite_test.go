@@ -0,0 +1,165 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRewrittenCommits(t *testing.T) {
+	t.Parallel()
+
+	old1, new1 := strings.Repeat("a", 40), strings.Repeat("b", 40)
+	old2 := strings.Repeat("c", 40)
+	rewritten, err := ParseRewrittenCommits(strings.NewReader(old1 + " " + new1 + "\n\n" + old2 + " " + new1 + " extra\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []RewrittenCommit{
+		{Old: plumbing.NewHash(old1), New: plumbing.NewHash(new1)},
+		{Old: plumbing.NewHash(old2), New: plumbing.NewHash(new1)},
+	}, rewritten)
+
+	_, err = ParseRewrittenCommits(strings.NewReader(old1 + "\n"))
+	require.Error(t, err)
+}
+
+// TestPostRewrite_RemapsRebasedCommits verifies that after a rebase, rebased
+// commits are linked to the checkpoints of the commits they replace, and
+// sessions based on a replaced commit move to its replacement.
+func TestPostRewrite_RemapsRebasedCommits(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	commit := func(content, message string) plumbing.Hash {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte(content), 0o644))
+		_, err := wt.Add("test.txt")
+		require.NoError(t, err)
+		hash, err := wt.Commit(message, &git.CommitOptions{})
+		require.NoError(t, err)
+		return hash
+	}
+
+	// The originals: one linked by trailer, one by stamp
+	trailered := commit("one", "Change one\n\nEntire-Checkpoint: a1b2c3d4e5f6\n")
+	stamped := commit("two", "Change two\n")
+	store := checkpoint.NewGitStore(repo)
+	for _, cpID := range []id.CheckpointID{"a1b2c3d4e5f6", "0a0b0c0d0e0f"} {
+		require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+			CheckpointID: cpID,
+			SessionID:    "2026-04-01-rewrite",
+			Strategy:     "manual-commit",
+			FilesTouched: []string{"test.txt"},
+		}))
+	}
+	_, err = store.StampCommit(context.Background(), "0a0b0c0d0e0f", stamped)
+	require.NoError(t, err)
+
+	// Their replacements, as if reworded during an interactive rebase
+	rebasedTrailered := commit("one'", "Change one, reworded\n")
+	rebasedStamped := commit("two'", "Change two, reworded\n")
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-session-rewrite"
+	require.NoError(t, s.InitializeSession(sessionID, agent.AgentTypeClaudeCode, "", ""))
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	state.BaseCommit = stamped.String()
+	require.NoError(t, s.saveSessionState(state))
+	oldBranch := getShadowBranchNameForCommit(stamped.String(), state.WorktreeID)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(oldBranch), stamped)))
+
+	rewritten := []RewrittenCommit{
+		{Old: trailered, New: rebasedTrailered},
+		{Old: stamped, New: rebasedStamped},
+	}
+	require.NoError(t, s.PostRewrite("rebase", rewritten))
+
+	committed, err := store.ListCommitted(context.Background())
+	require.NoError(t, err)
+	stamps := make(map[id.CheckpointID][]string)
+	for _, info := range committed {
+		stamps[info.CheckpointID] = info.StampedCommits
+	}
+	assert.Equal(t, []string{rebasedTrailered.String()}, stamps["a1b2c3d4e5f6"])
+	assert.Equal(t, []string{stamped.String(), rebasedStamped.String()}, stamps["0a0b0c0d0e0f"])
+
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, rebasedStamped.String(), state.BaseCommit)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(getShadowBranchNameForCommit(rebasedStamped.String(), state.WorktreeID)), true)
+	require.NoError(t, err, "shadow branch is moved to the rebased commit")
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(oldBranch), true)
+	assert.Error(t, err, "old shadow branch is removed")
+}
+
+// TestPostRewrite_AmendRepointsSession verifies that after `git commit --amend
+// -m`, which drops the amended commit's trailer, its replacement is stamped
+// with the amended commit's checkpoint, and a session based on the amended
+// commit, with its shadow branch, moves to the replacement.
+func TestPostRewrite_AmendRepointsSession(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte("changed"), 0o644))
+	_, err = wt.Add("test.txt")
+	require.NoError(t, err)
+	amended, err := wt.Commit("Change test.txt\n\nEntire-Checkpoint: a1b2c3d4e5f6\n", &git.CommitOptions{})
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: "a1b2c3d4e5f6",
+		SessionID:    "2026-04-01-amend",
+		Strategy:     "manual-commit",
+		FilesTouched: []string{"test.txt"},
+	}))
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-session-amend-repoint"
+	require.NoError(t, s.InitializeSession(sessionID, agent.AgentTypeClaudeCode, "", ""))
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.Equal(t, amended.String(), state.BaseCommit)
+	oldBranch := getShadowBranchNameForCommit(amended.String(), state.WorktreeID)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(oldBranch), amended)))
+
+	cmd := exec.CommandContext(t.Context(), "git", "commit", "--amend", "-m", "Reworded change")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	require.NoError(t, s.PostRewrite("amend", []RewrittenCommit{{Old: amended, New: head.Hash()}}))
+
+	summary, err := store.ReadCommitted(context.Background(), "a1b2c3d4e5f6")
+	require.NoError(t, err)
+	assert.Equal(t, []string{head.Hash().String()}, summary.StampedCommits)
+
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, head.Hash().String(), state.BaseCommit)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(getShadowBranchNameForCommit(head.Hash().String(), state.WorktreeID)), true)
+	require.NoError(t, err, "shadow branch is moved to the new commit")
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(oldBranch), true)
+	assert.Error(t, err, "old shadow branch is removed")
+}
@@ -3,13 +3,15 @@ package strategy
 import (
 	"context"
 	"fmt"
-	"time"
+	"log/slog"
+	"os"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
-	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -58,12 +60,24 @@ func (s *ManualCommitStrategy) clearSessionState(sessionID string) error {
 // listAllSessionStates returns all active session states.
 // It filters out orphaned sessions whose shadow branch no longer exists.
 func (s *ManualCommitStrategy) listAllSessionStates() ([]*SessionState, error) {
+	return s.listSessionStates(session.Filter{})
+}
+
+// listSessionStates returns the active session states that match filter,
+// filtering out orphaned sessions like listAllSessionStates.
+func (s *ManualCommitStrategy) listSessionStates(filter session.Filter) ([]*SessionState, error) {
 	store, err := s.getStateStore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get state store: %w", err)
 	}
 
-	sessionStates, err := store.List(context.Background())
+	// Re-bind sessions from a moved worktree first, so path filters match them.
+	if _, err := rebindMovedSessions(context.Background(), store); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "session"), "failed to re-bind moved sessions",
+			slog.String("error", err.Error()))
+	}
+
+	sessionStates, err := store.ListMatching(context.Background(), filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list session states: %w", err)
 	}
@@ -77,44 +91,68 @@ func (s *ManualCommitStrategy) listAllSessionStates() ([]*SessionState, error) {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
 
+	return dropOrphanedSessions(repo, sessionStates, func(sessionID string) {
+		//nolint:errcheck,gosec // G104: Cleanup is best-effort, shouldn't fail the list operation
+		store.Clear(context.Background(), sessionID)
+	}), nil
+}
+
+// beginSessionTx re-binds sessions from moved worktrees, as listSessionStates
+// does, and opens a transaction over the sessions of worktreePath. Hooks that
+// load and save several sessions use it to read the state files once and
+// write them once at the end. Sessions in other worktrees are left unlocked.
+func (s *ManualCommitStrategy) beginSessionTx(worktreePath string) (*session.StateTx, error) {
+	store, err := s.getStateStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state store: %w", err)
+	}
+	if _, err := rebindMovedSessions(context.Background(), store); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "session"), "failed to re-bind moved sessions",
+			slog.String("error", err.Error()))
+	}
+	tx, err := store.Begin(context.Background(), session.Filter{WorktreePath: worktreePath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session states: %w", err)
+	}
+	return tx, nil
+}
+
+// findSessionsForWorktreeInTx is findSessionsForWorktree within tx. Orphaned
+// sessions are cleared when tx is committed.
+func (s *ManualCommitStrategy) findSessionsForWorktreeInTx(tx *session.StateTx, repo *git.Repository, worktreePath string) []*SessionState {
+	return dropOrphanedSessions(repo, tx.List(session.Filter{WorktreePath: worktreePath}), tx.Clear)
+}
+
+// dropOrphanedSessions returns sessionStates without the orphaned ones,
+// which are passed to drop.
+func dropOrphanedSessions(repo *git.Repository, sessionStates []*SessionState, drop func(sessionID string)) []*SessionState {
 	var states []*SessionState
 	for _, sessionState := range sessionStates {
 		state := sessionState
 
 		// Skip and cleanup orphaned sessions whose shadow branch no longer exists.
 		// Keep active sessions (shadow branch may not be created yet) and sessions
-		// with LastCheckpointID (needed for checkpoint ID reuse on subsequent commits).
+		// with LastCheckpointID (needed for checkpoint ID reuse on subsequent commits),
+		// and sessions of the patch-file strategy, which never has a shadow branch.
 		// Clean up everything else: stale pre-state-machine sessions (empty phase),
 		// IDLE/ENDED sessions that were never condensed, etc.
 		shadowBranch := getShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
 		refName := plumbing.NewBranchReferenceName(shadowBranch)
 		if _, err := repo.Reference(refName, true); err != nil {
-			if !state.Phase.IsActive() && state.LastCheckpointID.IsEmpty() {
-				//nolint:errcheck,gosec // G104: Cleanup is best-effort, shouldn't fail the list operation
-				store.Clear(context.Background(), state.SessionID)
+			if !state.Phase.IsActive() && state.LastCheckpointID.IsEmpty() && !hasPatchSteps(state) {
+				drop(state.SessionID)
 				continue
 			}
 		}
 
 		states = append(states, state)
 	}
-	return states, nil
+	return states
 }
 
 // findSessionsForWorktree finds all sessions for the given worktree path.
 func (s *ManualCommitStrategy) findSessionsForWorktree(worktreePath string) ([]*SessionState, error) {
-	allStates, err := s.listAllSessionStates()
-	if err != nil {
-		return nil, err
-	}
-
-	var matching []*SessionState
-	for _, state := range allStates {
-		if state.WorktreePath == worktreePath {
-			matching = append(matching, state)
-		}
-	}
-	return matching, nil
+	return s.listSessionStates(session.Filter{WorktreePath: worktreePath})
 }
 
 // findSessionsForCommit finds all sessions where base_commit matches the given SHA.
@@ -209,6 +247,14 @@ func (s *ManualCommitStrategy) initializeSession(repo *git.Repository, sessionID
 		return nil, fmt.Errorf("failed to get worktree ID: %w", err)
 	}
 
+	// Record the repo ID so the session can be re-bound if the repository moves
+	repoID, err := ensureRepoID()
+	if err != nil {
+		// Non-fatal: without an ID, re-binding relies on the worktree ID alone
+		logging.Debug(logging.WithComponent(context.Background(), "session"), "failed to assign repo ID",
+			slog.String("error", err.Error()))
+	}
+
 	// Capture untracked files at session start to preserve them during rewind
 	untrackedFiles, err := collectUntrackedFiles()
 	if err != nil {
@@ -217,12 +263,12 @@ func (s *ManualCommitStrategy) initializeSession(repo *git.Repository, sessionID
 	}
 
 	// Generate TurnID for the first turn
-	turnID, err := id.Generate()
+	turnID, err := s.generateID()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate turn ID: %w", err)
 	}
 
-	now := time.Now()
+	now := s.now()
 	headHash := head.Hash().String()
 	state := &SessionState{
 		SessionID:             sessionID,
@@ -231,6 +277,7 @@ func (s *ManualCommitStrategy) initializeSession(repo *git.Repository, sessionID
 		AttributionBaseCommit: headHash,
 		WorktreePath:          worktreePath,
 		WorktreeID:            worktreeID,
+		RepoID:                repoID,
 		StartedAt:             now,
 		LastInteractionTime:   &now,
 		TurnID:                turnID.String(),
@@ -253,3 +300,11 @@ func (s *ManualCommitStrategy) initializeSession(repo *git.Repository, sessionID
 func getShadowBranchNameForCommit(baseCommit, worktreeID string) string {
 	return checkpoint.ShadowBranchNameForCommit(baseCommit, worktreeID)
 }
+
+// commitSessionTx writes the session state changes made in tx, warning
+// instead of failing so hooks stay silent.
+func commitSessionTx(tx *session.StateTx) {
+	if err := tx.Commit(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
+	}
+}
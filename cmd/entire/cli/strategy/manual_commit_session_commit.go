@@ -0,0 +1,49 @@
+package strategy
+
+import (
+	"fmt"
+)
+
+// These helpers back `entire commit`, which commits a session's files with a
+// message and trailers prepared up front. Because the message already has an
+// Entire-Checkpoint trailer, prepare-commit-msg keeps it instead of asking
+// whether to link the commit, and post-commit condenses the session under it.
+
+// SessionLastPrompt returns the last prompt recorded on the session's shadow
+// branch, or "" if there is none.
+func (s *ManualCommitStrategy) SessionLastPrompt(sessionID string) (string, error) {
+	state, err := s.loadSessionState(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return "", fmt.Errorf("session %s not found", sessionID)
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+	return s.getLastPrompt(repo, state), nil
+}
+
+// AddSessionCommitTrailers adds an Entire-Checkpoint trailer with a new
+// checkpoint ID to message, followed by the session's Entire-Ticket trailers.
+func (s *ManualCommitStrategy) AddSessionCommitTrailers(sessionID, message string) (string, error) {
+	state, err := s.loadSessionState(sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return "", fmt.Errorf("session %s not found", sessionID)
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+	checkpointID, err := s.generateID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate checkpoint ID: %w", err)
+	}
+	message = addCheckpointTrailer(message, checkpointID)
+	return addTicketTrailers(message, sessionTickets(state, GetCurrentBranchName(repo))), nil
+}
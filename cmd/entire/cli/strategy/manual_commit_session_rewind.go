@@ -0,0 +1,235 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sessionRewindLimit caps how many of a session's checkpoints are listed.
+const sessionRewindLimit = 1000
+
+// Unlike Rewind, which restores every file in a checkpoint's tree, a session
+// rewind only undoes what the session itself changed after the checkpoint:
+// the files its later checkpoints changed are restored to their content at
+// the checkpoint, or deleted if they did not exist then. Every other file,
+// including local edits and other sessions' work, is left alone.
+
+// SessionRewindPoints returns the session's uncommitted checkpoints, oldest
+// first, so a checkpoint's position numbers the session's steps.
+func (s *ManualCommitStrategy) SessionRewindPoints(sessionID string) ([]RewindPoint, error) {
+	state, err := s.loadSessionState(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
+	}
+	checkpoints, err := store.ListTemporaryCheckpoints(context.Background(), state.BaseCommit, state.WorktreeID, sessionID, sessionRewindLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	points := make([]RewindPoint, 0, len(checkpoints))
+	for i := len(checkpoints) - 1; i >= 0; i-- {
+		cp := checkpoints[i]
+		points = append(points, RewindPoint{
+			ID:               cp.CommitHash.String(),
+			Message:          cp.Message,
+			MetadataDir:      cp.MetadataDir,
+			Date:             cp.Timestamp,
+			IsTaskCheckpoint: cp.IsTaskCheckpoint,
+			ToolUseID:        cp.ToolUseID,
+			SessionID:        cp.SessionID,
+			Agent:            state.AgentType,
+		})
+	}
+	return points, nil
+}
+
+// PreviewSessionRewind returns the files RewindSession would restore and
+// delete to undo the session's changes after the point at index target of
+// points, as returned by SessionRewindPoints.
+func (s *ManualCommitStrategy) PreviewSessionRewind(points []RewindPoint, target int) (*RewindPreview, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	preview, _, err := planSessionRewind(repo, points, target)
+	return preview, err
+}
+
+// RewindSession undoes the session's changes after the point at index target
+// of points, as returned by SessionRewindPoints, and returns what it changed.
+// The shadow branch is reset to the checkpoint when no other session has
+// checkpointed since, so the next checkpoint continues from it.
+func (s *ManualCommitStrategy) RewindSession(points []RewindPoint, target int) (*RewindPreview, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	preview, tree, err := planSessionRewind(repo, points, target)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repository root: %w", err)
+	}
+
+	for _, relPath := range preview.FilesToRestore {
+		f, err := tree.File(relPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from checkpoint: %w", relPath, err)
+		}
+		contents, err := f.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from checkpoint: %w", relPath, err)
+		}
+		absPath := filepath.Join(repoRoot, relPath)
+		//nolint:gosec // G301: Need 0o755 for user directories during rewind
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", relPath, err)
+		}
+		var perm os.FileMode = 0o644
+		if f.Mode == filemode.Executable {
+			perm = 0o755
+		}
+		if err := os.WriteFile(absPath, []byte(contents), perm); err != nil {
+			return nil, fmt.Errorf("failed to write file %s: %w", relPath, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(absPath, perm); err != nil {
+			return nil, fmt.Errorf("failed to set mode of %s: %w", relPath, err)
+		}
+		audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileWrite, Target: relPath})
+	}
+	for _, relPath := range preview.FilesToDelete {
+		if err := os.Remove(filepath.Join(repoRoot, relPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to delete %s: %w", relPath, err)
+		}
+		audit.RecordRepo(repo, audit.Event{Action: audit.ActionFileDelete, Target: relPath})
+	}
+
+	if err := s.resetShadowBranchForSessionRewind(repo, points, target); err != nil {
+		// File restoration is the primary operation
+		fmt.Fprintf(os.Stderr, "[entire] Warning: %v\n", err)
+	}
+	return preview, nil
+}
+
+// planSessionRewind lists the files the session changed in the checkpoints
+// after points[target], split by whether they exist in that checkpoint's
+// tree, which is returned for restoring them.
+func planSessionRewind(repo *git.Repository, points []RewindPoint, target int) (*RewindPreview, *object.Tree, error) {
+	if target < 0 || target >= len(points) {
+		return nil, nil, fmt.Errorf("checkpoint %d does not exist", target+1)
+	}
+	tree, err := commitTree(repo, points[target].ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changed := make(map[string]struct{})
+	for _, p := range points[target+1:] {
+		commit, err := repo.CommitObject(plumbing.NewHash(p.ID))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get checkpoint %s: %w", p.ID, err)
+		}
+		to, err := commit.Tree()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get checkpoint tree: %w", err)
+		}
+		var from *object.Tree // a root commit adds every file
+		if parent, err := commit.Parent(0); err == nil {
+			if from, err = parent.Tree(); err != nil {
+				return nil, nil, fmt.Errorf("failed to get checkpoint tree: %w", err)
+			}
+		}
+		files, err := changedFilesBetweenTrees(repo, from, to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to diff checkpoint %s: %w", p.ID, err)
+		}
+		for f := range files {
+			if !strings.HasPrefix(f, entireDir+"/") {
+				changed[f] = struct{}{}
+			}
+		}
+	}
+
+	preview := &RewindPreview{}
+	for f := range changed {
+		if _, err := tree.File(f); err == nil {
+			preview.FilesToRestore = append(preview.FilesToRestore, f)
+		} else {
+			preview.FilesToDelete = append(preview.FilesToDelete, f)
+		}
+	}
+	sort.Strings(preview.FilesToRestore)
+	sort.Strings(preview.FilesToDelete)
+	return preview, tree, nil
+}
+
+// resetShadowBranchForSessionRewind moves the shadow branch back to
+// points[target], unless another session has checkpoints after it that the
+// reset would drop.
+func (s *ManualCommitStrategy) resetShadowBranchForSessionRewind(repo *git.Repository, points []RewindPoint, target int) error {
+	if target == len(points)-1 {
+		return nil // Already the latest checkpoint
+	}
+	sessionID := points[target].SessionID
+	state, err := s.loadSessionState(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(getShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)), true)
+	if err != nil {
+		return fmt.Errorf("failed to find shadow branch: %w", err)
+	}
+
+	targetHash := plumbing.NewHash(points[target].ID)
+	commit, err := repo.CommitObject(ref.Hash())
+	for err == nil && commit.Hash != targetHash {
+		if id, ok := trailers.ParseSession(commit.Message); !ok || id != sessionID {
+			return errors.New("shadow branch not reset: another session has checkpoints after this one")
+		}
+		commit, err = commit.Parent(0)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to walk shadow branch: %w", err)
+	}
+	return s.resetShadowBranchToCheckpoint(repo, commit)
+}
+
+// commitTree returns the tree of the commit with the given hash.
+func commitTree(repo *git.Repository, hash string) (*object.Tree, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint tree: %w", err)
+	}
+	return tree, nil
+}
@@ -0,0 +1,120 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// saveSessionRewindStep writes files and records a checkpoint for them.
+func saveSessionRewindStep(t *testing.T, s *ManualCommitStrategy, dir, sessionID, message string, files map[string]string, newFiles []string) {
+	t.Helper()
+	var modified []string
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+		if !slices.Contains(newFiles, name) {
+			modified = append(modified, name)
+		}
+	}
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(metadataDirAbs, paths.TranscriptFileName),
+		[]byte(`{"type":"human","message":{"content":"`+message+`"}}`+"\n"), 0o644))
+
+	require.NoError(t, s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  modified,
+		NewFiles:       newFiles,
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: metadataDirAbs,
+		CommitMessage:  message,
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}))
+}
+
+func TestRewindSession_UndoesOnlyTheSessionsLaterChanges(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	s := &ManualCommitStrategy{}
+	sessionID := "test-session-rewind"
+
+	saveSessionRewindStep(t, s, dir, sessionID, "Step 1", map[string]string{"test.txt": "v1"}, nil)
+	saveSessionRewindStep(t, s, dir, sessionID, "Step 2", map[string]string{"test.txt": "v2", "new.txt": "new"}, []string{"new.txt"})
+	saveSessionRewindStep(t, s, dir, sessionID, "Step 3", map[string]string{"test.txt": "v3"}, nil)
+
+	// The user's own file, which the session never touched
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine"), 0o644))
+
+	points, err := s.SessionRewindPoints(sessionID)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, "Step 1", points[0].Message, "oldest first")
+	assert.Equal(t, "Step 3", points[2].Message)
+
+	preview, err := s.PreviewSessionRewind(points, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.txt"}, preview.FilesToRestore)
+	assert.Equal(t, []string{"new.txt"}, preview.FilesToDelete)
+
+	latest, err := s.PreviewSessionRewind(points, 2)
+	require.NoError(t, err)
+	assert.Empty(t, latest.FilesToRestore, "nothing to undo at the latest checkpoint")
+	assert.Empty(t, latest.FilesToDelete)
+
+	_, err = s.RewindSession(points, 0)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "test.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "new.txt"))
+	data, err = os.ReadFile(filepath.Join(dir, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "mine", string(data), "unrelated files are left alone")
+
+	// The shadow branch continues from the checkpoint
+	points, err = s.SessionRewindPoints(sessionID)
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.Equal(t, "Step 1", points[0].Message)
+
+	_, err = s.PreviewSessionRewind(points, 1)
+	require.Error(t, err)
+}
+
+func TestRewindSession_KeepsShadowBranchWithOtherSessions(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	s := &ManualCommitStrategy{}
+
+	saveSessionRewindStep(t, s, dir, "session-a", "A1", map[string]string{"a.txt": "a1"}, []string{"a.txt"})
+	saveSessionRewindStep(t, s, dir, "session-a", "A2", map[string]string{"a.txt": "a2"}, nil)
+	saveSessionRewindStep(t, s, dir, "session-b", "B1", map[string]string{"b.txt": "b1"}, []string{"b.txt"})
+
+	points, err := s.SessionRewindPoints("session-a")
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+
+	preview, err := s.RewindSession(points, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt"}, preview.FilesToRestore, "the other session's files are not touched")
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a1", string(data))
+	assert.FileExists(t, filepath.Join(dir, "b.txt"))
+
+	// Session B's checkpoint is still on the shadow branch
+	pointsB, err := s.SessionRewindPoints("session-b")
+	require.NoError(t, err)
+	assert.Len(t, pointsB, 1)
+}
@@ -0,0 +1,162 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+)
+
+// squashMsgFileName is the file in the git directory where `git merge --squash`
+// leaves the log of the squashed commits for the next commit message. Git
+// removes it once that commit is made, before post-commit runs.
+const squashMsgFileName = "SQUASH_MSG"
+
+// squashedCommitRegex matches the "commit <hash>" header git writes for each
+// squashed commit.
+var squashedCommitRegex = regexp.MustCompile(`(?m)^commit ([0-9a-f]{40})$`)
+
+// squashMerge is a pending `git merge --squash` about to be committed.
+type squashMerge struct {
+	// Commits are the squashed commits, as listed in SQUASH_MSG.
+	Commits []string
+	// Checkpoints are the checkpoints the squashed commits were linked to.
+	Checkpoints []id.CheckpointID
+}
+
+// detectSquashMerge returns the squash merge being committed, or nil if the
+// commit is not one. Besides the "squash" source git passes when it prepared
+// the message, a squash is detected by SQUASH_MSG, so `git commit -m` after
+// `git merge --squash` is covered too.
+func detectSquashMerge(commitMsgFile, source string) *squashMerge {
+	var squashMsg []byte
+	if gitDir, err := GetGitDir(); err == nil {
+		squashMsg, _ = os.ReadFile(filepath.Join(gitDir, squashMsgFileName)) //nolint:gosec // path is within the git directory
+	}
+	if squashMsg == nil {
+		if source != "squash" {
+			return nil
+		}
+		squashMsg, _ = os.ReadFile(commitMsgFile) //nolint:gosec // commitMsgFile is provided by git hook
+	}
+
+	squash := &squashMerge{Checkpoints: trailers.ParseAllCheckpoints(string(squashMsg))}
+	for _, match := range squashedCommitRegex.FindAllStringSubmatch(string(squashMsg), -1) {
+		squash.Commits = append(squash.Commits, match[1])
+	}
+	return squash
+}
+
+// prepareSquashCommitMsg prepares the message of a squash merge commit.
+//
+// The Entire-Checkpoint trailers of the squashed commits are carried over as
+// Entire-Squashed-Checkpoint trailers, so the squash commit stays linked to
+// their checkpoints without PostCommit condensing into them. Trailers that
+// git quoted in the squash log are rewritten in place.
+//
+// Sessions covering the squashed range, those whose shadow branch is based on
+// a squashed commit or on HEAD, are condensed like any other commit: if one
+// has content that is not yet condensed, a fresh Entire-Checkpoint trailer is
+// added for PostCommit, without the interactive prompt of a normal commit.
+func (s *ManualCommitStrategy) prepareSquashCommitMsg(logCtx context.Context, commitMsgFile, source string, squash *squashMerge) error {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	worktreePath, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	content, err := os.ReadFile(commitMsgFile) //nolint:gosec // commitMsgFile is provided by git hook
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	message := markSquashedCheckpoints(string(content), squash.Checkpoints)
+
+	var covered []*SessionState
+	if sessions, listErr := s.findSessionsForWorktree(worktreePath); listErr == nil {
+		var head string
+		if ref, headErr := repo.Head(); headErr == nil {
+			head = ref.Hash().String()
+		}
+		var inRange []*SessionState
+		for _, state := range sessions {
+			if state.BaseCommit == head || slices.Contains(squash.Commits, state.BaseCommit) {
+				inRange = append(inRange, state)
+			}
+		}
+		covered = s.filterSessionsWithNewContent(repo, inRange)
+	}
+
+	var checkpointID id.CheckpointID
+	if _, found := trailers.ParseCheckpoint(message); !found && len(covered) > 0 {
+		checkpointID, err = s.generateID()
+		if err != nil {
+			return fmt.Errorf("failed to generate checkpoint ID: %w", err)
+		}
+		message = addCheckpointTrailer(message, checkpointID)
+		message = addTicketTrailers(message, sessionsTickets(covered, GetCurrentBranchName(repo)))
+	}
+
+	logging.Info(logCtx, "prepare-commit-msg: squash merge",
+		slog.String("strategy", "manual-commit"),
+		slog.String("source", source),
+		slog.Int("squashed_commits", len(squash.Commits)),
+		slog.Int("squashed_checkpoints", len(squash.Checkpoints)),
+		slog.Int("covered_sessions", len(covered)),
+		slog.String("checkpoint_id", checkpointID.String()),
+	)
+
+	if err := os.WriteFile(commitMsgFile, []byte(message), 0o600); err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	return nil
+}
+
+// markSquashedCheckpoints turns the Entire-Checkpoint trailers of squashed
+// checkpoints into Entire-Squashed-Checkpoint trailers, keeping their
+// indentation, and appends one for each squashed checkpoint the message does
+// not mention yet.
+func markSquashedCheckpoints(message string, squashed []id.CheckpointID) string {
+	if len(squashed) == 0 {
+		return message
+	}
+
+	message = renameCheckpointTrailers(message, squashed, trailers.SquashedCheckpointTrailerKey)
+
+	present := trailers.ParseSquashedCheckpoints(message)
+	for _, cpID := range squashed {
+		if !slices.Contains(present, cpID) {
+			message = appendTrailer(message, trailers.SquashedCheckpointTrailerKey+": "+cpID.String())
+		}
+	}
+	return message
+}
+
+// renameCheckpointTrailers gives the Entire-Checkpoint trailers of the
+// checkpoints in cpIDs the trailer key key instead, keeping their indentation.
+func renameCheckpointTrailers(message string, cpIDs []id.CheckpointID, key string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		value, ok := strings.CutPrefix(trimmed, trailers.CheckpointTrailerKey()+":")
+		if !ok {
+			continue
+		}
+		if cpID, err := id.NewCheckpointID(strings.TrimSpace(value)); err == nil && slices.Contains(cpIDs, cpID) {
+			indent := line[:len(line)-len(trimmed)]
+			lines[i] = indent + key + ": " + cpID.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// squashMsgForTest mimics the log git writes to SQUASH_MSG for
+// `git merge --squash` of two commits linked to checkpoints.
+const squashMsgForTest = `Squashed commit of the following:
+
+commit 1111111111111111111111111111111111111111
+Author: Test User <test@test.com>
+Date:   Thu Apr 2 10:00:00 2026 +0000
+
+    Add b
+
+    Entire-Checkpoint: 0123456789ab
+
+commit 2222222222222222222222222222222222222222
+Author: Test User <test@test.com>
+Date:   Thu Apr 2 09:00:00 2026 +0000
+
+    Add a
+
+    Entire-Checkpoint: a1b2c3d4e5f6
+`
+
+func TestMarkSquashedCheckpoints(t *testing.T) {
+	t.Parallel()
+
+	squashed := []id.CheckpointID{id.MustCheckpointID("0123456789ab"), id.MustCheckpointID("a1b2c3d4e5f6")}
+
+	result := markSquashedCheckpoints(squashMsgForTest, squashed)
+	assert.Contains(t, result, "    Entire-Squashed-Checkpoint: 0123456789ab\n")
+	assert.Contains(t, result, "    Entire-Squashed-Checkpoint: a1b2c3d4e5f6\n")
+	_, found := trailers.ParseCheckpoint(result)
+	assert.False(t, found, "quoted checkpoints must not be taken for the commit's own")
+	assert.Equal(t, squashed, trailers.ParseSquashedCheckpoints(result))
+
+	result = markSquashedCheckpoints("Squash feature\n", squashed)
+	assert.Equal(t, "Squash feature\n\n"+
+		"Entire-Squashed-Checkpoint: 0123456789ab\n"+
+		"Entire-Squashed-Checkpoint: a1b2c3d4e5f6\n", result)
+
+	assert.Equal(t, "Unrelated\n", markSquashedCheckpoints("Unrelated\n", nil))
+}
+
+func TestPrepareCommitMsg_SquashMessageSource(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	// `git merge --squash` followed by `git commit -m`: the hook gets the
+	// "message" source, and only SQUASH_MSG tells it a squash is committed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", squashMsgFileName), []byte(squashMsgForTest), 0o644))
+	commitMsgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(commitMsgFile, []byte("Squash feature\n"), 0o644))
+
+	s := &ManualCommitStrategy{}
+	require.NoError(t, s.PrepareCommitMsg(commitMsgFile, "message"))
+
+	content, err := os.ReadFile(commitMsgFile)
+	require.NoError(t, err)
+	_, found := trailers.ParseCheckpoint(string(content))
+	assert.False(t, found, "no session covers the squash, so no new checkpoint")
+	assert.Equal(t, []id.CheckpointID{id.MustCheckpointID("0123456789ab"), id.MustCheckpointID("a1b2c3d4e5f6")},
+		trailers.ParseSquashedCheckpoints(string(content)))
+}
+
+func TestPrepareCommitMsg_SquashSourceRewritesQuotedTrailers(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	commitMsgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(commitMsgFile, []byte(squashMsgForTest), 0o644))
+
+	s := &ManualCommitStrategy{}
+	require.NoError(t, s.PrepareCommitMsg(commitMsgFile, "squash"))
+
+	content, err := os.ReadFile(commitMsgFile)
+	require.NoError(t, err)
+	_, found := trailers.ParseCheckpoint(string(content))
+	assert.False(t, found)
+	assert.Len(t, trailers.ParseAllCheckpoints(string(content)), 2)
+	assert.NotContains(t, string(content), "\n\nEntire-Squashed-Checkpoint", "quoted trailers are rewritten, not duplicated")
+}
@@ -0,0 +1,319 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// submoduleStep holds the files a step changed inside one submodule, relative
+// to the submodule's root.
+type submoduleStep struct {
+	modified []string
+	new      []string
+	deleted  []string
+}
+
+// submodulePaths returns the paths, relative to the worktree root, of the
+// repository's submodules that are checked out.
+func submodulePaths(repo *git.Repository) []string {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil
+	}
+	submodules, err := wt.Submodules()
+	if err != nil {
+		return nil
+	}
+	var result []string
+	for _, sm := range submodules {
+		path := filepath.ToSlash(filepath.Clean(sm.Config().Path))
+		if _, err := os.Stat(filepath.Join(wt.Filesystem.Root(), path, ".git")); err == nil {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// submoduleOf returns the submodule among submodules that file is in, and
+// file's path relative to it, or "" if file is in none of them. The path of a
+// submodule itself (its gitlink) has an empty relative path.
+func submoduleOf(submodules []string, file string) (string, string) {
+	var sub string
+	for _, path := range submodules {
+		if (file == path || strings.HasPrefix(file, path+"/")) && len(path) > len(sub) {
+			sub = path
+		}
+	}
+	if sub == "" {
+		return "", ""
+	}
+	return sub, strings.TrimPrefix(strings.TrimPrefix(file, sub), "/")
+}
+
+// splitSubmoduleFiles moves the files in ctx that are inside one of
+// submodules into per-submodule steps, keyed by submodule path. Submodule
+// paths themselves are dropped: what changed is inside them.
+func splitSubmoduleFiles(submodules []string, ctx *StepContext) map[string]*submoduleStep {
+	if len(submodules) == 0 {
+		return nil
+	}
+	steps := make(map[string]*submoduleStep)
+	split := func(files []string, add func(*submoduleStep, string)) []string {
+		var rest []string
+		for _, file := range files {
+			sub, rel := submoduleOf(submodules, file)
+			switch {
+			case sub == "":
+				rest = append(rest, file)
+			case rel != "":
+				if steps[sub] == nil {
+					steps[sub] = &submoduleStep{}
+				}
+				add(steps[sub], rel)
+			}
+		}
+		return rest
+	}
+	ctx.ModifiedFiles = split(ctx.ModifiedFiles, func(step *submoduleStep, file string) { step.modified = append(step.modified, file) })
+	ctx.NewFiles = split(ctx.NewFiles, func(step *submoduleStep, file string) { step.new = append(step.new, file) })
+	ctx.DeletedFiles = split(ctx.DeletedFiles, func(step *submoduleStep, file string) { step.deleted = append(step.deleted, file) })
+	return steps
+}
+
+// saveSubmoduleSteps checkpoints each submodule's files on a shadow branch in
+// the submodule's own repository and records it in state. A submodule that
+// cannot be checkpointed is logged and skipped, so it never stops the
+// superproject's checkpoint.
+func (s *ManualCommitStrategy) saveSubmoduleSteps(logCtx context.Context, state *SessionState, worktreeRoot string, steps map[string]*submoduleStep, ctx StepContext) {
+	for _, path := range slices.Sorted(maps.Keys(steps)) {
+		if err := s.saveSubmoduleStep(logCtx, state, worktreeRoot, path, steps[path], ctx); err != nil {
+			logging.Warn(logCtx, "failed to checkpoint submodule",
+				slog.String("session_id", state.SessionID),
+				slog.String("submodule", path),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
+
+func (s *ManualCommitStrategy) saveSubmoduleStep(logCtx context.Context, state *SessionState, worktreeRoot, path string, step *submoduleStep, ctx StepContext) error {
+	root := filepath.Join(worktreeRoot, filepath.FromSlash(path))
+	repo, err := git.PlainOpen(root)
+	if err != nil {
+		return fmt.Errorf("failed to open submodule: %w", err)
+	}
+
+	sub := state.Submodules[path]
+	if sub == nil {
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to get submodule HEAD: %w", err)
+		}
+		sub = &session.SubmoduleState{BaseCommit: head.Hash().String()}
+	}
+
+	maxFileBytes, skipBinary := loadSnapshotLimits()
+	result, err := s.newGitStore(repo).WriteTemporary(context.Background(), checkpoint.WriteTemporaryOptions{
+		SessionID:       state.SessionID,
+		BaseCommit:      sub.BaseCommit,
+		ModifiedFiles:   step.modified,
+		NewFiles:        step.new,
+		DeletedFiles:    step.deleted,
+		MetadataDir:     ctx.MetadataDir,
+		CommitMessage:   ctx.CommitMessage,
+		AuthorName:      ctx.AuthorName,
+		AuthorEmail:     ctx.AuthorEmail,
+		WorktreeRoot:    root,
+		MaxFileBytes:    maxFileBytes,
+		SkipBinaryFiles: skipBinary,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write temporary checkpoint: %w", err)
+	}
+	for i := range result.Omitted {
+		result.Omitted[i].Path = path + "/" + result.Omitted[i].Path
+	}
+	warnOmittedFiles(result.Omitted)
+	if state.Submodules == nil {
+		state.Submodules = make(map[string]*session.SubmoduleState)
+	}
+	state.Submodules[path] = sub
+	if result.Skipped {
+		return nil
+	}
+	sub.StepCount++
+	sub.FilesTouched = mergeFilesTouched(sub.FilesTouched, step.modified, step.new, step.deleted)
+
+	logging.Info(logCtx, "submodule checkpoint saved",
+		slog.String("session_id", state.SessionID),
+		slog.String("submodule", path),
+		slog.Int("checkpoint_count", sub.StepCount),
+		slog.String("shadow_branch", checkpoint.ShadowBranchNameForCommit(sub.BaseCommit, "")),
+	)
+	return nil
+}
+
+// condenseSubmoduleCommits condenses what the session's submodules committed
+// since their shadow branches were made (see condenseSubmodule). Commits made
+// inside a submodule run the submodule's hooks, not the superproject's, so
+// this runs from the superproject's post-commit hook, typically when the
+// submodule's new commit is recorded, and from SaveStep. Reports whether
+// state changed.
+func (s *ManualCommitStrategy) condenseSubmoduleCommits(logCtx context.Context, state *SessionState, worktreeRoot string) bool {
+	changed := false
+	for _, path := range slices.Sorted(maps.Keys(state.Submodules)) {
+		root := filepath.Join(worktreeRoot, filepath.FromSlash(path))
+		repo, err := git.PlainOpen(root)
+		if err != nil {
+			continue
+		}
+		if s.condenseSubmodule(logCtx, state, repo, root, path) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// condenseSubmodule checkpoints the session's work committed in the submodule
+// at path once its HEAD has moved off the shadow branch's base. The files the
+// session touched that the new commits changed are written as a committed
+// checkpoint to the submodule's own metadata branch, and its HEAD is stamped
+// with it (the submodule's commits are not rewritten to add a trailer). Files
+// not yet committed stay on the shadow branch, which moves to the new HEAD.
+// Reports whether state changed.
+func (s *ManualCommitStrategy) condenseSubmodule(logCtx context.Context, state *SessionState, repo *git.Repository, root, path string) bool {
+	sub := state.Submodules[path]
+	head, err := repo.Head()
+	if err != nil || head.Hash().String() == sub.BaseCommit {
+		return false
+	}
+	baseCommit, err := repo.CommitObject(plumbing.NewHash(sub.BaseCommit))
+	if err != nil {
+		return false
+	}
+	headCommit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return false
+	}
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return false
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return false
+	}
+	changedFiles, err := changedFilesBetweenTrees(repo, baseTree, headTree)
+	if err != nil {
+		return false
+	}
+	var committed, remaining []string
+	for _, file := range sub.FilesTouched {
+		if _, ok := changedFiles[file]; ok {
+			committed = append(committed, file)
+		} else {
+			remaining = append(remaining, file)
+		}
+	}
+
+	oldBranch := checkpoint.ShadowBranchNameForCommit(sub.BaseCommit, "")
+	if len(committed) > 0 {
+		cpID, err := s.generateID()
+		if err != nil {
+			return false
+		}
+		var branch string
+		if head.Name().IsBranch() {
+			branch = head.Name().Short()
+		}
+		authorName, authorEmail := GetGitAuthorFromRepo(repo)
+		store := s.newGitStore(repo)
+		if err := store.WriteCommitted(logCtx, checkpoint.WriteCommittedOptions{
+			CheckpointID:     cpID,
+			SessionID:        state.SessionID,
+			Strategy:         StrategyNameManualCommit,
+			Branch:           branch,
+			FilesTouched:     committed,
+			CheckpointsCount: sub.StepCount,
+			EphemeralBranch:  oldBranch,
+			AuthorName:       authorName,
+			AuthorEmail:      authorEmail,
+			Agent:            state.AgentType,
+		}); err != nil {
+			logging.Warn(logCtx, "failed to condense submodule checkpoint",
+				slog.String("session_id", state.SessionID),
+				slog.String("submodule", path),
+				slog.String("error", err.Error()),
+			)
+			return false
+		}
+		if _, err := store.StampCommit(logCtx, cpID, head.Hash()); err != nil {
+			logging.Warn(logCtx, "failed to link submodule commit to checkpoint",
+				slog.String("checkpoint_id", cpID.String()),
+				slog.String("submodule", path),
+				slog.String("error", err.Error()),
+			)
+		}
+		logging.Info(logCtx, "condensed submodule checkpoint",
+			slog.String("session_id", state.SessionID),
+			slog.String("submodule", path),
+			slog.String("checkpoint_id", cpID.String()),
+			slog.String("commit", TruncateHash(head.Hash().String())),
+			slog.Int("files", len(committed)),
+		)
+	}
+
+	if len(remaining) == 0 {
+		if err := deleteSubmoduleBranch(root, oldBranch); err != nil {
+			logging.Warn(logCtx, "failed to delete submodule shadow branch",
+				slog.String("submodule", path),
+				slog.String("shadow_branch", oldBranch),
+				slog.String("error", err.Error()),
+			)
+		}
+		delete(state.Submodules, path)
+		return true
+	}
+
+	newBranch := checkpoint.ShadowBranchNameForCommit(head.Hash().String(), "")
+	if oldRef, err := repo.Reference(plumbing.NewBranchReferenceName(oldBranch), true); err == nil {
+		if err := audit.SetReference(repo, plumbing.NewHashReference(plumbing.NewBranchReferenceName(newBranch), oldRef.Hash())); err != nil {
+			return false
+		}
+		if err := deleteSubmoduleBranch(root, oldBranch); err != nil {
+			logging.Warn(logCtx, "failed to delete submodule shadow branch",
+				slog.String("submodule", path),
+				slog.String("shadow_branch", oldBranch),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	sub.BaseCommit = head.Hash().String()
+	sub.FilesTouched = remaining
+	return true
+}
+
+// deleteSubmoduleBranch deletes branch from the repository checked out at
+// root, using the git CLI for the reasons given on DeleteBranchCLI.
+func deleteSubmoduleBranch(root, branch string) error {
+	cmd := exec.CommandContext(context.Background(), "git", "-C", root, "branch", "-D", "--", branch) //nolint:gosec // branch comes from internal shadow branch naming
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %s: %w", branch, strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
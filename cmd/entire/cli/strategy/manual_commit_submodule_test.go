@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSubmoduleFiles(t *testing.T) {
+	t.Parallel()
+
+	ctx := StepContext{
+		ModifiedFiles: []string{"main.go", "lib/a.go", "lib", "vendor/x/b.go"},
+		NewFiles:      []string{"lib/sub/c.go", "library.go"},
+		DeletedFiles:  []string{"vendor/x/d.go"},
+	}
+	steps := splitSubmoduleFiles([]string{"lib", "vendor/x"}, &ctx)
+
+	assert.Equal(t, []string{"main.go"}, ctx.ModifiedFiles)
+	assert.Equal(t, []string{"library.go"}, ctx.NewFiles)
+	assert.Empty(t, ctx.DeletedFiles)
+	require.Len(t, steps, 2)
+	assert.Equal(t, &submoduleStep{modified: []string{"a.go"}, new: []string{"sub/c.go"}}, steps["lib"])
+	assert.Equal(t, &submoduleStep{modified: []string{"b.go"}, deleted: []string{"d.go"}}, steps["vendor/x"])
+
+	assert.Nil(t, splitSubmoduleFiles(nil, &ctx))
+}
+
+// TestSaveStep_CheckpointsSubmoduleFiles verifies that files the agent
+// changes inside a submodule are checkpointed in the submodule's repository,
+// and condensed there once the submodule commits them.
+func TestSaveStep_CheckpointsSubmoduleFiles(t *testing.T) {
+	dir := setupGitRepo(t)
+	upstream := t.TempDir()
+	runGitInDir(t, upstream, "init", "-q")
+	require.NoError(t, os.WriteFile(filepath.Join(upstream, "a.txt"), []byte("a"), 0o644))
+	runGitInDir(t, upstream, "add", "a.txt")
+	runGitInDir(t, upstream, "commit", "-q", "-m", "Initial")
+	runGitInDir(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", "-q", upstream, "lib")
+	runGitInDir(t, dir, "commit", "-q", "-m", "Add lib")
+	t.Chdir(dir)
+
+	libDir := filepath.Join(dir, "lib")
+	libRepo, err := git.PlainOpen(libDir)
+	require.NoError(t, err)
+	libBase, err := libRepo.Head()
+	require.NoError(t, err)
+
+	s := &ManualCommitStrategy{}
+	sessionID := "2026-05-01-submodule"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(metadataDirAbs, "full.jsonl"), []byte("{}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(libDir, "a.txt"), []byte("agent change"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte("agent change"), 0o644))
+
+	require.NoError(t, s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  []string{"lib/a.txt", "test.txt"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: metadataDirAbs,
+		CommitMessage:  "Checkpoint 1",
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}))
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.txt"}, state.FilesTouched, "submodule files are not the superproject's")
+	require.Contains(t, state.Submodules, "lib")
+	assert.Equal(t, libBase.Hash().String(), state.Submodules["lib"].BaseCommit)
+	assert.Equal(t, 1, state.Submodules["lib"].StepCount)
+	assert.Equal(t, []string{"a.txt"}, state.Submodules["lib"].FilesTouched)
+
+	shadowBranch := checkpoint.ShadowBranchNameForCommit(libBase.Hash().String(), "")
+	ref, err := libRepo.Reference(plumbing.NewBranchReferenceName(shadowBranch), true)
+	require.NoError(t, err, "shadow branch is created in the submodule")
+	shadowCommit, err := libRepo.CommitObject(ref.Hash())
+	require.NoError(t, err)
+	file, err := shadowCommit.File("a.txt")
+	require.NoError(t, err)
+	content, err := file.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "agent change", content)
+
+	// The submodule commits the change and the superproject records it
+	runGitInDir(t, libDir, "commit", "-q", "-am", "Change a")
+	runGitInDir(t, dir, "add", "lib")
+	runGitInDir(t, dir, "commit", "-q", "-m", "Bump lib")
+	require.NoError(t, s.PostCommit())
+
+	libHead, err := libRepo.Head()
+	require.NoError(t, err)
+	committed, err := checkpoint.NewGitStore(libRepo).ListCommitted(context.Background())
+	require.NoError(t, err)
+	require.Len(t, committed, 1)
+	assert.Equal(t, sessionID, committed[0].SessionID)
+	assert.Equal(t, []string{libHead.Hash().String()}, committed[0].StampedCommits)
+
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	assert.Empty(t, state.Submodules, "everything the session changed in lib is committed")
+	_, err = libRepo.Reference(plumbing.NewBranchReferenceName(shadowBranch), true)
+	assert.Error(t, err, "submodule shadow branch is removed")
+}
@@ -789,7 +789,8 @@ func TestShadowStrategy_PrepareCommitMsg_NoActiveSession(t *testing.T) {
 }
 
 func TestShadowStrategy_PrepareCommitMsg_SkipSources(t *testing.T) {
-	// Tests that merge, squash, and commit sources are skipped
+	// Tests that merge and commit sources are skipped, and that a squash
+	// without checkpoints or sessions leaves the message alone
 	dir := t.TempDir()
 	_, err := git.PlainInit(dir, false)
 	if err != nil {
@@ -839,7 +840,7 @@ func TestAddCheckpointTrailer_NoComment(t *testing.T) {
 	result := addCheckpointTrailer(message, testTrailerCheckpointID)
 
 	// Should contain the trailer
-	if !strings.Contains(result, trailers.CheckpointTrailerKey+": "+testTrailerCheckpointID.String()) {
+	if !strings.Contains(result, trailers.CheckpointTrailerKey()+": "+testTrailerCheckpointID.String()) {
 		t.Errorf("addCheckpointTrailer() missing trailer, got: %q", result)
 	}
 
@@ -856,7 +857,7 @@ func TestAddCheckpointTrailerWithComment_HasComment(t *testing.T) {
 	result := addCheckpointTrailerWithComment(message, testTrailerCheckpointID, "Claude Code", "add password hashing")
 
 	// Should contain the trailer
-	if !strings.Contains(result, trailers.CheckpointTrailerKey+": "+testTrailerCheckpointID.String()) {
+	if !strings.Contains(result, trailers.CheckpointTrailerKey()+": "+testTrailerCheckpointID.String()) {
 		t.Errorf("addCheckpointTrailerWithComment() missing trailer, got: %q", result)
 	}
 
@@ -888,7 +889,7 @@ func TestAddCheckpointTrailerWithComment_NoPrompt(t *testing.T) {
 	result := addCheckpointTrailerWithComment(message, testTrailerCheckpointID, "Claude Code", "")
 
 	// Should contain the trailer
-	if !strings.Contains(result, trailers.CheckpointTrailerKey+": "+testTrailerCheckpointID.String()) {
+	if !strings.Contains(result, trailers.CheckpointTrailerKey()+": "+testTrailerCheckpointID.String()) {
 		t.Errorf("addCheckpointTrailerWithComment() missing trailer, got: %q", result)
 	}
 
@@ -937,7 +938,7 @@ func TestAddCheckpointTrailer_ConventionalCommitSubject(t *testing.T) {
 			result := addCheckpointTrailer(tt.message, testTrailerCheckpointID)
 
 			// The trailer must be separated from the subject by a blank line
-			if !strings.Contains(result, "\n\n"+trailers.CheckpointTrailerKey+":") {
+			if !strings.Contains(result, "\n\n"+trailers.CheckpointTrailerKey()+":") {
 				t.Errorf("addCheckpointTrailer() trailer not separated by blank line from subject.\ngot: %q", result)
 			}
 		})
@@ -953,7 +954,7 @@ func TestAddCheckpointTrailer_ExistingTrailers(t *testing.T) {
 	result := addCheckpointTrailer(message, testTrailerCheckpointID)
 
 	// Should NOT add a double blank line before our trailer
-	if strings.Contains(result, "\n\n"+trailers.CheckpointTrailerKey) {
+	if strings.Contains(result, "\n\n"+trailers.CheckpointTrailerKey()) {
 		t.Errorf("addCheckpointTrailer() added extra blank line before existing trailer block.\ngot: %q", result)
 	}
 
@@ -961,7 +962,7 @@ func TestAddCheckpointTrailer_ExistingTrailers(t *testing.T) {
 	if !strings.Contains(result, "Signed-off-by:") {
 		t.Errorf("addCheckpointTrailer() lost existing trailer.\ngot: %q", result)
 	}
-	if !strings.Contains(result, trailers.CheckpointTrailerKey+":") {
+	if !strings.Contains(result, trailers.CheckpointTrailerKey()+":") {
 		t.Errorf("addCheckpointTrailer() missing our trailer.\ngot: %q", result)
 	}
 }
@@ -1550,7 +1551,7 @@ func TestShadowStrategy_CondenseSession_EphemeralBranchTrailer(t *testing.T) {
 	}
 
 	// Get the sessions branch commit and verify the Ephemeral-branch trailer
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch reference: %v", err)
 	}
@@ -2001,6 +2002,41 @@ func TestExtractUserPrompts(t *testing.T) {
 			}`,
 			expected: []string{"Create a file", "Edit the file"},
 		},
+		{
+			name:      "Cursor JSONL with user query tags",
+			agentType: agent.AgentTypeCursor,
+			content: `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nAdd a README\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}
+{"role":"user","message":{"content":[{"type":"text","text":"<user_query>Add a license</user_query>"}]}}`,
+			expected: []string{"Add a README", "Add a license"},
+		},
+		{
+			name:      "Aider chat history",
+			agentType: agent.AgentTypeAider,
+			content: `# aider chat started at 2026-03-02 14:30:05
+
+#### add a hello function
+
+Done.
+
+> Applied edit to main.py
+
+#### thanks
+
+You're welcome!
+`,
+			expected: []string{"add a hello function", "thanks"},
+		},
+		{
+			name:      "Codex rollout",
+			agentType: agent.AgentTypeCodex,
+			content: `{"type":"session_meta","payload":{"id":"0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b","cwd":"/repo"}}
+{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>/repo</environment_context>"}]}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Add a README"}}
+{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Done"}]}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Add a license"}}`,
+			expected: []string{"Add a README", "Add a license"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2129,7 +2165,7 @@ func TestCondenseSession_IncludesInitialAttribution(t *testing.T) {
 	}
 
 	// Read metadata from entire/checkpoints/v1 branch and verify InitialAttribution
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -2298,7 +2334,7 @@ func TestCondenseSession_AttributionWithoutShadowBranch(t *testing.T) {
 	}
 
 	// Read metadata from entire/checkpoints/v1 branch
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -2464,7 +2500,7 @@ func TestCondenseSession_AttributionWithoutShadowBranch_MixedHumanAgent(t *testi
 	}
 
 	// Read metadata
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -2776,7 +2812,7 @@ func TestMultiCheckpoint_UserEditsBetweenCheckpoints(t *testing.T) {
 	}
 
 	// Read metadata and verify attribution
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -3420,7 +3456,7 @@ func TestCondenseSession_FilesTouchedFallback_EmptyState(t *testing.T) {
 	}
 
 	// Read metadata and verify files_touched contains the committed file (fallback worked)
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -3537,7 +3573,7 @@ func TestCondenseSession_FilesTouchedNoFallback_NoOverlap(t *testing.T) {
 	}
 
 	// Read metadata and verify files_touched is EMPTY (no fallback applied)
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	if err != nil {
 		t.Fatalf("failed to get sessions branch: %v", err)
 	}
@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+)
+
+// sessionTickets returns the tickets a session is linked to: those attached
+// with `entire session set-ticket`, followed by those the tickets.branch_patterns
+// setting finds in the branch name.
+func sessionTickets(state *SessionState, branch string) []string {
+	tickets := slices.Clone(state.Tickets)
+	s, err := settings.Load()
+	if err != nil {
+		return tickets
+	}
+	for _, ticket := range s.TicketsFromBranch(branch) {
+		if !slices.Contains(tickets, ticket) {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets
+}
+
+// sessionsTickets returns the tickets of several sessions, without duplicates.
+func sessionsTickets(states []*SessionState, branch string) []string {
+	var tickets []string
+	for _, state := range states {
+		for _, ticket := range sessionTickets(state, branch) {
+			if !slices.Contains(tickets, ticket) {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets
+}
+
+// addTicketTrailers adds an Entire-Ticket trailer for each ticket right after
+// the Entire-Checkpoint trailer, so they share its trailer block. Tickets the
+// message already references are skipped; without a checkpoint trailer the
+// message is returned unchanged.
+func addTicketTrailers(message string, tickets []string) string {
+	existing := trailers.ParseAllTickets(message)
+	var lines []string
+	for _, ticket := range tickets {
+		if !slices.Contains(existing, ticket) {
+			lines = append(lines, trailers.TicketTrailerKey+": "+ticket)
+		}
+	}
+	if len(lines) == 0 {
+		return message
+	}
+
+	msgLines := strings.Split(message, "\n")
+	for i, line := range msgLines {
+		if strings.HasPrefix(line, trailers.CheckpointTrailerKey()+":") {
+			result := make([]string, 0, len(msgLines)+len(lines))
+			result = append(result, msgLines[:i+1]...)
+			result = append(result, lines...)
+			result = append(result, msgLines[i+1:]...)
+			return strings.Join(result, "\n")
+		}
+	}
+	return message
+}
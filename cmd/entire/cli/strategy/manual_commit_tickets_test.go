@@ -0,0 +1,49 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+)
+
+func TestAddTicketTrailers(t *testing.T) {
+	t.Parallel()
+
+	message := addCheckpointTrailerWithComment("Fix login\n\n# Please enter the commit message\n", testTrailerCheckpointID, "Claude Code", "")
+	result := addTicketTrailers(message, []string{"ABC-123", "#42"})
+
+	want := trailers.CheckpointTrailerKey() + ": " + testTrailerCheckpointID.String() + "\n" +
+		trailers.TicketTrailerKey + ": ABC-123\n" +
+		trailers.TicketTrailerKey + ": #42\n# Remove the Entire-Checkpoint"
+	if !strings.Contains(result, want) {
+		t.Errorf("addTicketTrailers() should place tickets after the checkpoint trailer, got: %q", result)
+	}
+
+	// Tickets the message already has are not repeated.
+	again := addTicketTrailers(result, []string{"ABC-123", "ENG-7"})
+	if got := trailers.ParseAllTickets(again); len(got) != 3 || strings.Count(again, "ABC-123") != 1 {
+		t.Errorf("addTicketTrailers() repeated a ticket, got: %q", again)
+	}
+}
+
+func TestAddTicketTrailers_NoCheckpointTrailer(t *testing.T) {
+	t.Parallel()
+
+	message := "Fix login\n"
+	if got := addTicketTrailers(message, []string{"ABC-123"}); got != message {
+		t.Errorf("addTicketTrailers() without a checkpoint trailer = %q, want message unchanged", got)
+	}
+}
+
+func TestStripCheckpointTrailer_RemovesTickets(t *testing.T) {
+	t.Parallel()
+
+	message := addTicketTrailers(addCheckpointTrailer("", testTrailerCheckpointID), []string{"ABC-123"})
+	if hasUserContent(message) {
+		t.Errorf("hasUserContent() = true for a message with only trailers: %q", message)
+	}
+	if got := strings.TrimSpace(stripCheckpointTrailer(message)); got != "" {
+		t.Errorf("stripCheckpointTrailer() = %q, want empty message", got)
+	}
+}
@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
+)
+
+// filterTranscriptForStorage applies the transcript.keep_types,
+// transcript.drop_tool_output_over_bytes, and transcript.max_bytes (in
+// "truncate" mode) settings to a transcript about to be stored in a checkpoint. start is the checkpoint's transcript line offset;
+// the offset of the same position in the filtered transcript is returned with
+// it. Gemini and OpenCode transcripts are single JSON documents, Aider's is
+// markdown, Codex rollout lines have their own types, and generic transcripts
+// have no known format, so they are returned unchanged, as are all
+// transcripts when no rules are configured.
+func filterTranscriptForStorage(agentType agent.AgentType, data []byte, start int) ([]byte, int, *transcript.FilterStats) {
+	switch agentType {
+	case agent.AgentTypeGemini, agent.AgentTypeOpenCode, agent.AgentTypeAider, agent.AgentTypeCodex, agent.AgentTypeGeneric:
+		return data, start, nil
+	}
+	if len(data) == 0 {
+		return data, start, nil
+	}
+	s, err := settings.Load()
+	if err != nil {
+		return data, start, nil
+	}
+	rules := s.TranscriptFilter()
+	if rules == nil {
+		return data, start, nil
+	}
+
+	opts := transcript.FilterOptions{
+		KeepTypes:          rules.KeepTypes,
+		MaxToolOutputBytes: rules.DropToolOutputOverBytes,
+	}
+	if s.TranscriptOversize() == settings.TranscriptOversizeTruncate {
+		opts.MaxBytes = rules.MaxBytes
+	}
+	if !opts.Enabled() {
+		return data, start, nil
+	}
+	result := transcript.Filter(data, opts)
+	return result.Transcript, result.LineOffset(start), &result.Stats
+}
+
+// compressTranscriptForStorage reports whether a transcript of size bytes is
+// over transcript.max_bytes with transcript.oversize set to "gzip", so the
+// checkpoint store should compress it. Compression works for every agent's
+// format, unlike truncation.
+func compressTranscriptForStorage(size int) bool {
+	s, err := settings.Load()
+	if err != nil || s.Transcript == nil || s.Transcript.MaxBytes <= 0 {
+		return false
+	}
+	return s.TranscriptOversize() == settings.TranscriptOversizeGzip && size > s.Transcript.MaxBytes
+}
@@ -51,7 +51,8 @@ type CondenseResult struct {
 	SessionID            string
 	CheckpointsCount     int
 	FilesTouched         []string
-	TotalTranscriptLines int // Total lines in transcript after this condensation
+	TotalTranscriptLines int               // Total lines in transcript after this condensation
+	TokenUsage           *agent.TokenUsage // Token usage of the condensed portion
 }
 
 // ExtractedSessionData contains data extracted from a shadow branch.
@@ -0,0 +1,325 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// mergeMetadataCommits merges the remote tip of the metadata branch into the
+// local tip and returns the commit the local branch should point to: local
+// itself if remote has nothing new, remote if local has nothing new, or a new
+// merge commit with both as parents. Also returns the paths whose conflicting
+// changes were resolved (see mergeMetadataEntries).
+func mergeMetadataCommits(repo *git.Repository, local, remote *object.Commit) (plumbing.Hash, []string, error) {
+	if local.Hash == remote.Hash {
+		return local.Hash, nil, nil
+	}
+
+	// Branches created independently on two machines share no history,
+	// in which case everything is merged against an empty base.
+	baseEntries := make(map[string]object.TreeEntry)
+	bases, err := local.MergeBase(remote)
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("failed to find merge base: %w", err)
+	}
+	if len(bases) > 0 {
+		switch bases[0].Hash {
+		case remote.Hash:
+			return local.Hash, nil, nil
+		case local.Hash:
+			return remote.Hash, nil, nil
+		}
+		if err := flattenCommitTree(repo, bases[0], baseEntries); err != nil {
+			return plumbing.ZeroHash, nil, fmt.Errorf("failed to flatten merge base tree: %w", err)
+		}
+	}
+
+	localEntries := make(map[string]object.TreeEntry)
+	if err := flattenCommitTree(repo, local, localEntries); err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("failed to flatten local tree: %w", err)
+	}
+	remoteEntries := make(map[string]object.TreeEntry)
+	if err := flattenCommitTree(repo, remote, remoteEntries); err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("failed to flatten remote tree: %w", err)
+	}
+
+	merged, conflicts, err := mergeMetadataEntries(repo, baseEntries, localEntries, remoteEntries)
+	if err != nil {
+		return plumbing.ZeroHash, nil, err
+	}
+	if len(conflicts) > 0 {
+		logging.Warn(logging.WithComponent(context.Background(), "checkpoint"), "resolved conflicting session log changes",
+			slog.Any("paths", conflicts),
+			slog.String("local", local.Hash.String()),
+			slog.String("remote", remote.Hash.String()),
+		)
+	}
+
+	mergedTreeHash, err := checkpoint.BuildTreeFromEntries(repo, merged)
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("failed to build merged tree: %w", err)
+	}
+	mergeCommitHash, err := createMergeCommitCommon(repo, mergedTreeHash,
+		[]plumbing.Hash{local.Hash, remote.Hash},
+		"Merge remote session logs")
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("failed to create merge commit: %w", err)
+	}
+	return mergeCommitHash, conflicts, nil
+}
+
+func flattenCommitTree(repo *git.Repository, commit *object.Commit, entries map[string]object.TreeEntry) error {
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get tree: %w", err)
+	}
+	return checkpoint.FlattenTree(repo, tree, "", entries) //nolint:wrapcheck // callers add context
+}
+
+// mergeMetadataEntries three-way merges two flattened metadata branch trees
+// that diverged from base, as happens when several machines condense and push
+// concurrently. A path changed on one side only takes that side's version.
+// Paths changed differently on both sides are conflicts, resolved as follows:
+//
+//   - A revision both sides added under the same number keeps its number
+//     locally; the remote one is renumbered after the last revision.
+//   - A checkpoint's root metadata.json takes the local version plus any
+//     commits the remote stamped onto it.
+//   - Otherwise a deletion wins, so erased data isn't resurrected, and
+//     then the local version wins.
+//
+// Returns the merged entries and the conflicting paths, other than
+// renumbered revisions. Remote versions that lost a conflict stay reachable
+// through the merge commit's second parent.
+func mergeMetadataEntries(repo *git.Repository, base, local, remote map[string]object.TreeEntry) (map[string]object.TreeEntry, []string, error) {
+	merged := make(map[string]object.TreeEntry, len(local))
+	var conflicts []string
+	for p := range unionKeys(local, remote) {
+		l, inLocal := local[p]
+		r, inRemote := remote[p]
+		b, inBase := base[p]
+		switch {
+		case sameEntry(l, inLocal, r, inRemote), sameEntry(r, inRemote, b, inBase):
+			if inLocal {
+				merged[p] = l
+			}
+		case sameEntry(l, inLocal, b, inBase):
+			if inRemote {
+				merged[p] = r
+			}
+		default:
+			conflicts = append(conflicts, p)
+		}
+	}
+	slices.Sort(conflicts)
+
+	// Sessions that got a revision under the same number on both sides
+	renumbered := make(map[string]bool) // session path -> true
+	for _, p := range conflicts {
+		if sessionPath, _, ok := revisionOf(p); ok {
+			if _, inBase := base[p]; !inBase {
+				renumbered[sessionPath] = true
+			}
+		}
+	}
+	for _, sessionPath := range slices.Sorted(maps.Keys(renumbered)) {
+		renumberRemoteRevisions(sessionPath, base, local, remote, merged)
+	}
+
+	var unresolved []string
+	for _, p := range conflicts {
+		if sessionPath, _, ok := revisionOf(p); ok && renumbered[sessionPath] {
+			continue
+		}
+		unresolved = append(unresolved, p)
+
+		l, inLocal := local[p]
+		r, inRemote := remote[p]
+		switch {
+		case !inLocal || !inRemote:
+			delete(merged, p)
+		case isCheckpointSummaryPath(p):
+			entry, err := mergeCheckpointSummaries(repo, p, l, r)
+			if err != nil {
+				return nil, nil, err
+			}
+			merged[p] = entry
+		default:
+			merged[p] = l
+		}
+	}
+	return merged, unresolved, nil
+}
+
+// mergeCheckpointSummaries returns the local root metadata.json with the
+// remote's stamped commits added.
+func mergeCheckpointSummaries(repo *git.Repository, p string, local, remote object.TreeEntry) (object.TreeEntry, error) {
+	localSummary, err := readSummaryBlob(repo, local.Hash)
+	if err != nil {
+		return local, nil //nolint:nilerr // Unreadable summary: keep local as-is
+	}
+	remoteSummary, err := readSummaryBlob(repo, remote.Hash)
+	if err != nil {
+		return local, nil //nolint:nilerr // Unreadable summary: keep local as-is
+	}
+
+	added := false
+	for _, commit := range remoteSummary.StampedCommits {
+		if !slices.Contains(localSummary.StampedCommits, commit) {
+			localSummary.StampedCommits = append(localSummary.StampedCommits, commit)
+			added = true
+		}
+	}
+	if !added {
+		return local, nil
+	}
+
+	summaryJSON, err := jsonutil.MarshalCanonical(localSummary)
+	if err != nil {
+		return object.TreeEntry{}, fmt.Errorf("failed to marshal checkpoint summary: %w", err)
+	}
+	hash, err := checkpoint.CreateBlobFromContent(repo, summaryJSON)
+	if err != nil {
+		return object.TreeEntry{}, fmt.Errorf("failed to store checkpoint summary: %w", err)
+	}
+	return object.TreeEntry{Name: p, Mode: filemode.Regular, Hash: hash}, nil
+}
+
+func readSummaryBlob(repo *git.Repository, hash plumbing.Hash) (*checkpoint.CheckpointSummary, error) {
+	blob, err := repo.BlobObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	var summary checkpoint.CheckpointSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint summary: %w", err)
+	}
+	return &summary, nil
+}
+
+// isCheckpointSummaryPath reports whether p is a checkpoint's root
+// metadata.json (<id[:2]>/<id[2:]>/metadata.json), as opposed to a session's.
+func isCheckpointSummaryPath(p string) bool {
+	return path.Base(p) == paths.MetadataFileName && strings.Count(p, "/") == 2
+}
+
+// revisionOf returns the session path (ending in a slash) and revision
+// number of the revision directory containing p, if p is inside one.
+func revisionOf(p string) (string, int, bool) {
+	sessionPath, rest, ok := strings.Cut(p, "/"+paths.RevisionsDirName+"/")
+	if !ok {
+		return "", 0, false
+	}
+	dir, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(dir)
+	if err != nil {
+		return "", 0, false
+	}
+	return sessionPath + "/", n, true
+}
+
+// renumberRemoteRevisions replaces the revisions of the session at
+// sessionPath in merged with the local ones, followed by the revisions the
+// remote added since base, renumbered in their original order.
+func renumberRemoteRevisions(sessionPath string, base, local, remote, merged map[string]object.TreeEntry) {
+	prefix := sessionPath + paths.RevisionsDirName + "/"
+	for p := range merged {
+		if strings.HasPrefix(p, prefix) {
+			delete(merged, p)
+		}
+	}
+	for p, entry := range local {
+		if strings.HasPrefix(p, prefix) {
+			merged[p] = entry
+		}
+	}
+
+	baseRevisions := make(map[int]bool)
+	for p := range base {
+		if s, n, ok := revisionOf(p); ok && s == sessionPath {
+			baseRevisions[n] = true
+		}
+	}
+	remoteAdded := make(map[int]bool)
+	for p := range remote {
+		if s, n, ok := revisionOf(p); ok && s == sessionPath && !baseRevisions[n] {
+			remoteAdded[n] = true
+		}
+	}
+
+	next := lastRevision(sessionPath, local) + 1
+	for _, n := range slices.Sorted(maps.Keys(remoteAdded)) {
+		dir := fmt.Sprintf("%s%d/", prefix, n)
+		newDir := fmt.Sprintf("%s%d/", prefix, next)
+		next++
+		for p, entry := range remote {
+			if rest, ok := strings.CutPrefix(p, dir); ok {
+				entry.Name = newDir + rest
+				merged[newDir+rest] = entry
+			}
+		}
+	}
+}
+
+// lastRevision returns the highest revision number of the session at
+// sessionPath present in entries, or 0 if it has none.
+func lastRevision(sessionPath string, entries map[string]object.TreeEntry) int {
+	prefix := sessionPath + paths.RevisionsDirName + "/"
+	latest := 0
+	for p := range entries {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		dir, _, _ := strings.Cut(rest, "/")
+		if n, err := strconv.Atoi(dir); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return latest
+}
+
+func sameEntry(a object.TreeEntry, inA bool, b object.TreeEntry, inB bool) bool {
+	return inA == inB && (!inA || a.Hash == b.Hash)
+}
+
+func unionKeys(a, b map[string]object.TreeEntry) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
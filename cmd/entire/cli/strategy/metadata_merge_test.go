@@ -0,0 +1,209 @@
+package strategy
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metadataEntries stores each content as a blob and returns the entries.
+func metadataEntries(t *testing.T, repo *git.Repository, files map[string]string) map[string]object.TreeEntry {
+	t.Helper()
+	entries := make(map[string]object.TreeEntry, len(files))
+	for p, content := range files {
+		hash, err := checkpoint.CreateBlobFromContent(repo, []byte(content))
+		require.NoError(t, err)
+		entries[p] = object.TreeEntry{Name: p, Mode: filemode.Regular, Hash: hash}
+	}
+	return entries
+}
+
+// entryContents reads back the content of each entry.
+func entryContents(t *testing.T, repo *git.Repository, entries map[string]object.TreeEntry) map[string]string {
+	t.Helper()
+	contents := make(map[string]string, len(entries))
+	for p, entry := range entries {
+		blob, err := repo.BlobObject(entry.Hash)
+		require.NoError(t, err)
+		reader, err := blob.Reader()
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		contents[p] = string(data)
+	}
+	return contents
+}
+
+func TestMergeMetadataEntries(t *testing.T) {
+	t.Parallel()
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+
+	const rev = "ab/cdef012345/0/revisions/"
+	base := map[string]string{
+		"ab/cdef012345/0/full.jsonl":       "base transcript",
+		"ab/cdef012345/0/prompt.txt":       "base prompt",
+		"ab/cdef012345/0/context.md":       "base context",
+		"ab/cdef012345/0/summary.txt":      "base summary",
+		"ab/cdef012345/0/review.md":        "base review",
+		rev + "1/revision.json":            "base revision 1",
+		"cd/ef01234567/0/full.jsonl":       "erased transcript",
+		"ef/0123456789/0/full.jsonl":       "unchanged",
+		"01/23456789ab/0/full.jsonl":       "deleted remotely",
+		"01/23456789ab/0/metadata.json":    "deleted remotely",
+		"23/456789abcd/0/full.jsonl":       "deleted locally",
+		"45/6789abcdef/0/content_hash.txt": "base",
+		"67/89abcdef01/0/context.md":       "same change",
+	}
+	local := withChanges(base,
+		map[string]string{
+			"aa/aaaaaaaaaa/0/full.jsonl":  "local checkpoint",
+			"ab/cdef012345/0/prompt.txt":  "local prompt",
+			"ab/cdef012345/0/context.md":  "local context",
+			"ab/cdef012345/0/summary.txt": "local summary",
+			rev + "2/revision.json":       "local revision 2",
+			"67/89abcdef01/0/context.md":  "both changed alike",
+		},
+		"cd/ef01234567/0/full.jsonl", "23/456789abcd/0/full.jsonl",
+	)
+	remote := withChanges(base,
+		map[string]string{
+			"bb/bbbbbbbbbb/0/full.jsonl": "remote checkpoint",
+			"ab/cdef012345/0/full.jsonl": "remote transcript",
+			"ab/cdef012345/0/context.md": "remote context",
+			"ab/cdef012345/0/review.md":  "remote review",
+			"cd/ef01234567/0/full.jsonl": "remote change to erased transcript",
+			rev + "2/revision.json":      "remote revision 2",
+			rev + "2/full.jsonl":         "remote revision 2 transcript",
+			rev + "3/revision.json":      "remote revision 3",
+			"67/89abcdef01/0/context.md": "both changed alike",
+		},
+		"01/23456789ab/0/full.jsonl", "01/23456789ab/0/metadata.json",
+	)
+
+	merged, conflicts, err := mergeMetadataEntries(repo,
+		metadataEntries(t, repo, base), metadataEntries(t, repo, local), metadataEntries(t, repo, remote))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"aa/aaaaaaaaaa/0/full.jsonl":       "local checkpoint",
+		"bb/bbbbbbbbbb/0/full.jsonl":       "remote checkpoint",
+		"ab/cdef012345/0/full.jsonl":       "remote transcript",
+		"ab/cdef012345/0/prompt.txt":       "local prompt",
+		"ab/cdef012345/0/context.md":       "local context",
+		"ab/cdef012345/0/summary.txt":      "local summary",
+		"ab/cdef012345/0/review.md":        "remote review",
+		rev + "1/revision.json":            "base revision 1",
+		rev + "2/revision.json":            "local revision 2",
+		rev + "3/revision.json":            "remote revision 2",
+		rev + "3/full.jsonl":               "remote revision 2 transcript",
+		rev + "4/revision.json":            "remote revision 3",
+		"ef/0123456789/0/full.jsonl":       "unchanged",
+		"45/6789abcdef/0/content_hash.txt": "base",
+		"67/89abcdef01/0/context.md":       "both changed alike",
+	}, entryContents(t, repo, merged))
+	assert.Equal(t, []string{"ab/cdef012345/0/context.md", "cd/ef01234567/0/full.jsonl"}, conflicts)
+	for p, entry := range merged {
+		assert.Equal(t, p, entry.Name)
+	}
+}
+
+func TestMergeMetadataEntries_MergesStampedCommits(t *testing.T) {
+	t.Parallel()
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+
+	summary := func(stamped ...string) string {
+		data, err := jsonutil.MarshalCanonical(checkpoint.CheckpointSummary{CheckpointID: "abcdef012345", StampedCommits: stamped})
+		require.NoError(t, err)
+		return string(data)
+	}
+	const summaryPath = "ab/cdef012345/" + paths.MetadataFileName
+	base := metadataEntries(t, repo, map[string]string{summaryPath: summary("c1")})
+	local := metadataEntries(t, repo, map[string]string{summaryPath: summary("c1", "c2")})
+	remote := metadataEntries(t, repo, map[string]string{summaryPath: summary("c1", "c3")})
+
+	merged, conflicts, err := mergeMetadataEntries(repo, base, local, remote)
+	require.NoError(t, err)
+	assert.Equal(t, []string{summaryPath}, conflicts)
+
+	got, err := readSummaryBlob(repo, merged[summaryPath].Hash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, got.StampedCommits)
+}
+
+// withChanges returns a copy of base with set applied and remove deleted.
+func withChanges(base, set map[string]string, remove ...string) map[string]string {
+	result := make(map[string]string, len(base)+len(set))
+	for k, v := range base {
+		if !slices.Contains(remove, k) {
+			result[k] = v
+		}
+	}
+	for k, v := range set {
+		result[k] = v
+	}
+	return result
+}
+
+// commitMetadataFile commits a file to the metadata branch checked out in dir.
+func commitMetadataFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	runGitInDir(t, dir, "add", name)
+	runGitInDir(t, dir, "commit", "-q", "-m", "Checkpoint "+name)
+}
+
+func TestDoPushSessionsBranch_MergesConcurrentCondensation(t *testing.T) {
+	remote := t.TempDir()
+	runGitInDir(t, remote, "init", "-q", "--bare")
+
+	// Machine A creates the metadata branch and pushes it
+	machineA := t.TempDir()
+	runGitInDir(t, machineA, "init", "-q", "-b", "main")
+	runGitInDir(t, machineA, "remote", "add", "origin", remote)
+	runGitInDir(t, machineA, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGitInDir(t, machineA, "checkout", "-q", "--orphan", paths.MetadataBranchName())
+	commitMetadataFile(t, machineA, "aa/aaaaaaaaaa/0/full.jsonl", "first")
+	runGitInDir(t, machineA, "push", "-q", "origin", paths.MetadataBranchName())
+
+	// Machine B condenses and pushes in the meantime
+	machineB := t.TempDir()
+	runGitInDir(t, machineB, "clone", "-q", remote, ".")
+	runGitInDir(t, machineB, "checkout", "-q", paths.MetadataBranchName())
+	commitMetadataFile(t, machineB, "bb/bbbbbbbbbb/0/full.jsonl", "from B")
+	runGitInDir(t, machineB, "push", "-q", "origin", paths.MetadataBranchName())
+
+	// Machine A condenses too, so its push is rejected as non-fast-forward
+	commitMetadataFile(t, machineA, "cc/cccccccccc/0/full.jsonl", "from A")
+	runGitInDir(t, machineA, "checkout", "-q", "main")
+
+	t.Chdir(machineA)
+	paths.ClearWorktreeRootCache()
+	require.NoError(t, doPushSessionsBranch("origin", paths.MetadataBranchName(), hookPushPolicy))
+
+	out, err := exec.CommandContext(context.Background(), "git", "-C", remote, "ls-tree", "-r", "--name-only", paths.MetadataBranchName()).Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"aa/aaaaaaaaaa/0/full.jsonl",
+		"bb/bbbbbbbbbb/0/full.jsonl",
+		"cc/cccccccccc/0/full.jsonl",
+	}, strings.Fields(string(out)))
+}
@@ -0,0 +1,181 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrNoRemoteMetadataBranch is returned by SyncMetadataBranch when the remote
+// has no metadata branch yet. The returned error names the branch.
+var ErrNoRemoteMetadataBranch = errors.New("remote has no metadata branch")
+
+// SyncResult describes what SyncMetadataBranch changed locally.
+type SyncResult struct {
+	// NewCheckpoints are the checkpoints the local branch gained, sorted by ID.
+	NewCheckpoints []id.CheckpointID
+	// Conflicts are paths changed differently on both sides, resolved as
+	// described on mergeMetadataEntries.
+	Conflicts []string
+	// Merged is set when a merge commit was created because both sides had
+	// checkpoints the other lacked. The local branch must be pushed for the
+	// remote to get the local checkpoints.
+	Merged bool
+}
+
+// SyncMetadataBranch fetches entire/checkpoints/v1 from remote into
+// refs/remotes/<remote>/entire/checkpoints/v1 and merges it into the local
+// branch, creating the local branch if it doesn't exist. Nothing is pushed.
+func SyncMetadataBranch(remote string) (*SyncResult, error) {
+	remoteRefName := plumbing.NewRemoteReferenceName(remote, paths.MetadataBranchName())
+	if err := fetchMetadataBranch(remote, remoteRefName); err != nil {
+		return nil, err
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	remoteRef, err := repo.Reference(remoteRefName, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", remoteRefName.Short(), err)
+	}
+	remoteCommit, err := repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote commit: %w", err)
+	}
+
+	result := &SyncResult{}
+	branchRef := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
+	localRef, err := repo.Reference(branchRef, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		result.NewCheckpoints, err = checkpointIDsAdded(nil, remoteCommit)
+		if err != nil {
+			return nil, err
+		}
+		if err := audit.SetReference(repo, plumbing.NewHashReference(branchRef, remoteCommit.Hash)); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", paths.MetadataBranchName(), err)
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", paths.MetadataBranchName(), err)
+	}
+	localCommit, err := repo.CommitObject(localRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local commit: %w", err)
+	}
+
+	mergeHash, conflicts, err := mergeMetadataCommits(repo, localCommit, remoteCommit)
+	if err != nil {
+		return nil, err
+	}
+	if mergeHash == localCommit.Hash {
+		return result, nil
+	}
+	mergeCommit, err := repo.CommitObject(mergeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge commit: %w", err)
+	}
+	result.NewCheckpoints, err = checkpointIDsAdded(localCommit, mergeCommit)
+	if err != nil {
+		return nil, err
+	}
+	result.Conflicts = conflicts
+	result.Merged = mergeHash != remoteCommit.Hash
+
+	if err := audit.CheckAndSetReference(repo, plumbing.NewHashReference(branchRef, mergeHash), localRef); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", paths.MetadataBranchName(), err)
+	}
+	return result, nil
+}
+
+// fetchMetadataBranch fetches the remote's metadata branch into remoteRefName.
+// Returns ErrNoRemoteMetadataBranch if the remote doesn't have it.
+func fetchMetadataBranch(remote string, remoteRefName plumbing.ReferenceName) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
+	refspec := "+" + plumbing.NewBranchReferenceName(paths.MetadataBranchName()).String() + ":" + remoteRefName.String()
+	cmd := exec.CommandContext(ctx, "git", "fetch", "--no-tags", remote, refspec)
+	cmd.Stdin = nil
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "couldn't find remote ref") {
+			return fmt.Errorf("%w %s", ErrNoRemoteMetadataBranch, paths.MetadataBranchName())
+		}
+		return fmt.Errorf("fetch failed: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// checkpointIDsAdded returns the checkpoints in after's tree that are not in
+// before's, sorted by ID. A nil before counts as an empty tree.
+func checkpointIDsAdded(before, after *object.Commit) ([]id.CheckpointID, error) {
+	existing := make(map[id.CheckpointID]bool)
+	if before != nil {
+		ids, err := checkpointIDsInCommit(before)
+		if err != nil {
+			return nil, err
+		}
+		for _, cpID := range ids {
+			existing[cpID] = true
+		}
+	}
+	ids, err := checkpointIDsInCommit(after)
+	if err != nil {
+		return nil, err
+	}
+	added := []id.CheckpointID{}
+	for _, cpID := range ids {
+		if !existing[cpID] {
+			added = append(added, cpID)
+		}
+	}
+	slices.Sort(added)
+	return added, nil
+}
+
+// checkpointIDsInCommit lists the checkpoints in a metadata branch commit,
+// identified by their <id[:2]>/<id[2:]>/metadata.json files.
+func checkpointIDsInCommit(commit *object.Commit) ([]id.CheckpointID, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+	var ids []id.CheckpointID
+	for _, shard := range tree.Entries {
+		if shard.Mode.IsFile() {
+			continue
+		}
+		shardTree, err := tree.Tree(shard.Name)
+		if err != nil {
+			continue
+		}
+		for _, rest := range shardTree.Entries {
+			if rest.Mode.IsFile() {
+				continue
+			}
+			cpID, err := id.NewCheckpointID(shard.Name + rest.Name)
+			if err != nil {
+				continue
+			}
+			if _, err := shardTree.File(rest.Name + "/" + paths.MetadataFileName); err == nil {
+				ids = append(ids, cpID)
+			}
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,58 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncMetadataBranch(t *testing.T) {
+	remote := t.TempDir()
+	runGitInDir(t, remote, "init", "-q", "--bare")
+
+	local := t.TempDir()
+	runGitInDir(t, local, "init", "-q", "-b", "main")
+	runGitInDir(t, local, "remote", "add", "origin", remote)
+	runGitInDir(t, local, "commit", "-q", "--allow-empty", "-m", "initial")
+	t.Chdir(local)
+	paths.ClearWorktreeRootCache()
+
+	_, err := SyncMetadataBranch("origin")
+	require.ErrorIs(t, err, ErrNoRemoteMetadataBranch)
+	assert.Contains(t, err.Error(), paths.MetadataBranchName())
+
+	// A teammate pushes the first checkpoint
+	teammate := t.TempDir()
+	runGitInDir(t, teammate, "init", "-q", "-b", "main")
+	runGitInDir(t, teammate, "remote", "add", "origin", remote)
+	runGitInDir(t, teammate, "checkout", "-q", "--orphan", paths.MetadataBranchName())
+	commitMetadataFile(t, teammate, "aa/aaaaaaaaaa/metadata.json", "{}")
+	runGitInDir(t, teammate, "push", "-q", "origin", paths.MetadataBranchName())
+
+	result, err := SyncMetadataBranch("origin")
+	require.NoError(t, err)
+	assert.Equal(t, []id.CheckpointID{"aaaaaaaaaaaa"}, result.NewCheckpoints)
+	assert.False(t, result.Merged)
+
+	// Both sides condense before syncing again
+	commitMetadataFile(t, teammate, "bb/bbbbbbbbbb/metadata.json", "{}")
+	runGitInDir(t, teammate, "push", "-q", "origin", paths.MetadataBranchName())
+	runGitInDir(t, local, "checkout", "-q", paths.MetadataBranchName())
+	commitMetadataFile(t, local, "cc/cccccccccc/metadata.json", "{}")
+	runGitInDir(t, local, "checkout", "-q", "main")
+
+	result, err = SyncMetadataBranch("origin")
+	require.NoError(t, err)
+	assert.Equal(t, []id.CheckpointID{"bbbbbbbbbbbb"}, result.NewCheckpoints)
+	assert.True(t, result.Merged)
+	assert.Empty(t, result.Conflicts)
+
+	result, err = SyncMetadataBranch("origin")
+	require.NoError(t, err)
+	assert.Empty(t, result.NewCheckpoints)
+	assert.False(t, result.Merged)
+}
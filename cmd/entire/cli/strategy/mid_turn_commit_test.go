@@ -10,8 +10,10 @@ import (
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Register Claude Code agent for transcript analysis
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -246,3 +248,65 @@ func TestPostCommit_NoTrailer_UpdatesBaseCommit(t *testing.T) {
 	assert.Equal(t, session.PhaseActive, state.Phase,
 		"Phase should remain ACTIVE when commit has no trailer")
 }
+
+// TestPostCommit_MalformedTrailer_HandledGracefully verifies that hand-edited,
+// malformed Entire-Checkpoint trailers never make PostCommit fail or panic.
+// They are treated like a missing trailer: no condensation, shadow branch kept,
+// BaseCommit updated.
+func TestPostCommit_MalformedTrailer_HandledGracefully(t *testing.T) {
+	malformedValues := []string{
+		"abc123",           // too short
+		"a1b2c3d4e5f6789",  // too long
+		"A1B2C3D4E5F6",     // uppercase
+		"../../etc/passwd", // path traversal attempt
+		"",                 // empty
+	}
+
+	for _, value := range malformedValues {
+		t.Run(value, func(t *testing.T) {
+			dir := setupGitRepo(t)
+			t.Chdir(dir)
+
+			repo, err := git.PlainOpen(dir)
+			require.NoError(t, err)
+
+			s := &ManualCommitStrategy{}
+			sessionID := "test-postcommit-malformed"
+			setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+
+			state, err := s.loadSessionState(sessionID)
+			require.NoError(t, err)
+			state.Phase = session.PhaseActive
+			require.NoError(t, s.saveSessionState(state))
+			shadowBranch := getShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+
+			testFile := filepath.Join(dir, "test.txt")
+			require.NoError(t, os.WriteFile(testFile, []byte("agent modified content"), 0o644))
+			wt, err := repo.Worktree()
+			require.NoError(t, err)
+			_, err = wt.Add("test.txt")
+			require.NoError(t, err)
+			_, err = wt.Commit("hand-edited trailer\n\n"+trailers.CheckpointTrailerKey()+": "+value+"\n", &git.CommitOptions{
+				Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+			})
+			require.NoError(t, err)
+			head, err := repo.Head()
+			require.NoError(t, err)
+
+			require.NotPanics(t, func() {
+				require.NoError(t, s.PostCommit())
+			})
+
+			_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+			require.Error(t, err, "malformed trailer must not trigger condensation")
+
+			_, err = repo.Reference(plumbing.NewBranchReferenceName(shadowBranch), true)
+			require.NoError(t, err, "shadow branch should be preserved")
+
+			state, err = s.loadSessionState(sessionID)
+			require.NoError(t, err)
+			assert.Equal(t, head.Hash().String(), state.BaseCommit,
+				"BaseCommit should be updated as for a commit without a trailer")
+		})
+	}
+}
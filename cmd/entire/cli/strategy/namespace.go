@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BranchRename is one local branch moved by MigrateNamespace.
+type BranchRename struct {
+	From string
+	To   string
+	Hash plumbing.Hash
+}
+
+// NamespaceRenames lists the local branches that still use the branch names of
+// from and must move to the current namespace: the metadata branch and every
+// shadow branch. Empty fields of from mean the defaults. Commits keep their
+// trailers; the old trailer key is still recognized when parsing.
+func NamespaceRenames(from paths.Namespace) ([]BranchRename, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	return namespaceRenames(repo, from)
+}
+
+func namespaceRenames(repo *git.Repository, from paths.Namespace) ([]BranchRename, error) {
+	defaults := paths.DefaultNamespace()
+	if from.MetadataBranch == "" {
+		from.MetadataBranch = defaults.MetadataBranch
+	}
+	if from.ShadowBranchPrefix == "" {
+		from.ShadowBranchPrefix = defaults.ShadowBranchPrefix
+	}
+	if err := from.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid source namespace: %w", err)
+	}
+	current := paths.CurrentNamespace()
+
+	branches, err := repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	oldShadow := shadowBranchPattern(from.ShadowBranchPrefix)
+	var renames []BranchRename
+	err = branches.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		switch {
+		case name == from.MetadataBranch && from.MetadataBranch != current.MetadataBranch:
+			renames = append(renames, BranchRename{From: name, To: current.MetadataBranch, Hash: ref.Hash()})
+		case from.ShadowBranchPrefix != current.ShadowBranchPrefix && oldShadow.MatchString(name):
+			to := current.ShadowBranchPrefix + name[len(from.ShadowBranchPrefix):]
+			renames = append(renames, BranchRename{From: name, To: to, Hash: ref.Hash()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate branches: %w", err)
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+	return renames, nil
+}
+
+// MigrateNamespace moves the branches listed by NamespaceRenames to their new
+// names and returns the ones moved. A branch whose new name already exists at
+// a different commit is left in place and reported in the returned error; the
+// others are still moved.
+func MigrateNamespace(renames []BranchRename) ([]BranchRename, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	var moved []BranchRename
+	var errs []error
+	for _, rename := range renames {
+		if err := renameBranch(repo, rename); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		moved = append(moved, rename)
+	}
+	return moved, errors.Join(errs...)
+}
+
+func renameBranch(repo *git.Repository, rename BranchRename) error {
+	target := plumbing.NewBranchReferenceName(rename.To)
+	existing, err := repo.Storer.Reference(target)
+	switch {
+	case err == nil && existing.Hash() != rename.Hash:
+		return fmt.Errorf("cannot move %s: %s already exists at %s", rename.From, rename.To, TruncateHash(existing.Hash().String()))
+	case err == nil:
+		// Already copied by an earlier, interrupted migration
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		if err := audit.SetReference(repo, plumbing.NewHashReference(target, rename.Hash)); err != nil {
+			return fmt.Errorf("failed to create %s: %w", rename.To, err)
+		}
+	default:
+		return fmt.Errorf("failed to read %s: %w", rename.To, err)
+	}
+	return removeReference(repo, plumbing.NewHashReference(plumbing.NewBranchReferenceName(rename.From), rename.Hash))
+}
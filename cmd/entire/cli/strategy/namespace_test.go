@@ -0,0 +1,55 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateNamespace(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	t.Cleanup(func() { paths.SetNamespaceGetter(nil) })
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	for _, branch := range []string{"entire/checkpoints/v1", "entire/abcdef0-123456", "entire/feature"} {
+		require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())))
+	}
+
+	paths.SetNamespaceGetter(func() paths.Namespace {
+		return paths.Namespace{MetadataBranch: "acme/checkpoints", ShadowBranchPrefix: "acme/shadow/"}
+	})
+	assert.True(t, IsShadowBranch("acme/shadow/abcdef0-123456"))
+	assert.False(t, IsShadowBranch("entire/abcdef0-123456"))
+
+	renames, err := NamespaceRenames(paths.Namespace{})
+	require.NoError(t, err)
+	require.Equal(t, []BranchRename{
+		{From: "entire/abcdef0-123456", To: "acme/shadow/abcdef0-123456", Hash: head.Hash()},
+		{From: "entire/checkpoints/v1", To: "acme/checkpoints", Hash: head.Hash()},
+	}, renames)
+
+	moved, err := MigrateNamespace(renames)
+	require.NoError(t, err)
+	assert.Equal(t, renames, moved)
+	for _, branch := range []string{"acme/checkpoints", "acme/shadow/abcdef0-123456", "entire/feature"} {
+		_, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		require.NoError(t, err, branch)
+	}
+	for _, branch := range []string{"entire/checkpoints/v1", "entire/abcdef0-123456"} {
+		_, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+		require.Error(t, err, branch)
+	}
+
+	renames, err = NamespaceRenames(paths.Namespace{})
+	require.NoError(t, err)
+	assert.Empty(t, renames)
+}
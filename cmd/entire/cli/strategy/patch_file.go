@@ -0,0 +1,491 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// The patch-file strategy is for repositories where Entire may not create
+// refs, such as locked-down CI mirrors. Instead of committing each step to a
+// shadow branch, it writes the step as a patch in mbox format plus a metadata
+// JSON file under .entire/patches/<session-id>/. Each patch holds every file
+// the session touched so far, relative to the session's base commit, so any
+// one of them can be applied on its own with `git am` or `git apply`.
+//
+// Session state is shared with manual-commit. Nothing is condensed to the
+// metadata branch and no trailers are added, so commands that read
+// checkpoints from git (rewind, explain, resume) find nothing for these
+// sessions.
+
+// PatchMetadata describes one step written by the patch-file strategy.
+type PatchMetadata struct {
+	SessionID  string          `json:"session_id"`
+	Step       int             `json:"step"`
+	AgentType  agent.AgentType `json:"agent_type,omitempty"`
+	BaseCommit string          `json:"base_commit"`
+	Message    string          `json:"message"`
+	CreatedAt  time.Time       `json:"created_at"`
+	// Patch is the patch file name, relative to the session's directory.
+	Patch       string `json:"patch"`
+	PatchSHA256 string `json:"patch_sha256"`
+	// FilesTouched are all files the session touched so far; the patch
+	// contains the ones that differ from BaseCommit.
+	FilesTouched  []string `json:"files_touched"`
+	ModifiedFiles []string `json:"modified_files,omitempty"`
+	NewFiles      []string `json:"new_files,omitempty"`
+	DeletedFiles  []string `json:"deleted_files,omitempty"`
+	// ToolUseID is set for steps saved when a subagent task completes.
+	ToolUseID  string            `json:"tool_use_id,omitempty"`
+	TokenUsage *agent.TokenUsage `json:"token_usage,omitempty"`
+}
+
+// PatchFileStrategy writes checkpoints as patch files instead of refs. It
+// embeds ManualCommitStrategy for session state handling and overrides every
+// method that would write a ref.
+type PatchFileStrategy struct {
+	*ManualCommitStrategy
+}
+
+// NewPatchFileStrategy creates a new patch-file strategy instance.
+func NewPatchFileStrategy() Strategy {
+	return &PatchFileStrategy{ManualCommitStrategy: &ManualCommitStrategy{}}
+}
+
+// Name returns the strategy name.
+func (s *PatchFileStrategy) Name() string {
+	return StrategyNamePatchFile
+}
+
+// Description returns the strategy description.
+func (s *PatchFileStrategy) Description() string {
+	return "Checkpoints written as patch files under " + paths.EntirePatchesDir + ", without creating refs"
+}
+
+// SaveStep writes the session's changes as the next patch.
+func (s *PatchFileStrategy) SaveStep(ctx StepContext) error {
+	sessionID := filepath.Base(ctx.MetadataDir)
+	state, err := s.patchSessionState(sessionID, ctx.AgentType)
+	if err != nil {
+		return err
+	}
+
+	state.FilesTouched = mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	if ctx.TokenUsage != nil {
+		state.TokenUsage = accumulateTokenUsage(state.TokenUsage, ctx.TokenUsage)
+	}
+	meta := PatchMetadata{
+		SessionID:     sessionID,
+		AgentType:     state.AgentType,
+		BaseCommit:    state.BaseCommit,
+		Message:       ctx.CommitMessage,
+		FilesTouched:  state.FilesTouched,
+		ModifiedFiles: ctx.ModifiedFiles,
+		NewFiles:      ctx.NewFiles,
+		DeletedFiles:  ctx.DeletedFiles,
+		TokenUsage:    ctx.TokenUsage,
+	}
+	if err := s.writePatchStep(state, meta, ctx.AuthorName, ctx.AuthorEmail, ctx.TranscriptPath); err != nil {
+		return err
+	}
+	if err := s.saveSessionState(state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+	return nil
+}
+
+// SaveTaskStep writes a patch when a subagent task completes. Incremental
+// task checkpoints are skipped: the final one includes their changes.
+func (s *PatchFileStrategy) SaveTaskStep(ctx TaskStepContext) error {
+	if ctx.IsIncremental {
+		return nil
+	}
+	state, err := s.patchSessionState(ctx.SessionID, ctx.AgentType)
+	if err != nil {
+		return err
+	}
+
+	state.FilesTouched = mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	message := "Task completed"
+	if ctx.TaskDescription != "" {
+		message = "Task: " + ctx.TaskDescription
+	}
+	meta := PatchMetadata{
+		SessionID:     ctx.SessionID,
+		AgentType:     state.AgentType,
+		BaseCommit:    state.BaseCommit,
+		Message:       message,
+		FilesTouched:  state.FilesTouched,
+		ModifiedFiles: ctx.ModifiedFiles,
+		NewFiles:      ctx.NewFiles,
+		DeletedFiles:  ctx.DeletedFiles,
+		ToolUseID:     ctx.ToolUseID,
+	}
+	if err := s.writePatchStep(state, meta, ctx.AuthorName, ctx.AuthorEmail, ctx.TranscriptPath); err != nil {
+		return err
+	}
+	if err := s.saveSessionState(state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+	return nil
+}
+
+// patchSessionState loads the session's state, initializing it if needed, and
+// moves its base commit to HEAD if HEAD has changed. No shadow branch exists,
+// so the migration only updates the state.
+func (s *PatchFileStrategy) patchSessionState(sessionID string, agentType agent.AgentType) (*SessionState, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	state, err := s.loadSessionState(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil || state.BaseCommit == "" {
+		state, err = s.initializeSession(repo, sessionID, resolveAgentType(agentType, state), "", "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize session: %w", err)
+		}
+	}
+	if err := s.migrateAndPersistIfNeeded(repo, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// writePatchStep diffs the session's files against its base commit and
+// writes the next numbered patch and metadata file. A step with no changes,
+// or whose patch is identical to the previous one, is skipped.
+func (s *PatchFileStrategy) writePatchStep(state *SessionState, meta PatchMetadata, authorName, authorEmail, transcriptPath string) error {
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	diff, err := sessionDiff(repoRoot, state.BaseCommit, state.FilesTouched)
+	if err != nil {
+		return err
+	}
+	if len(diff) == 0 {
+		fmt.Fprintf(os.Stderr, "Skipped checkpoint (no changes since the base commit)\n")
+		return nil
+	}
+	sum := sha256.Sum256(diff)
+	meta.PatchSHA256 = hex.EncodeToString(sum[:])
+
+	dir := filepath.Join(repoRoot, paths.EntirePatchesDir, state.SessionID)
+	steps, err := ListPatchSteps(dir)
+	if err != nil {
+		return err
+	}
+	if len(steps) > 0 && steps[len(steps)-1].PatchSHA256 == meta.PatchSHA256 {
+		fmt.Fprintf(os.Stderr, "Skipped checkpoint (no changes since last checkpoint)\n")
+		return nil
+	}
+	meta.Step = 1
+	if len(steps) > 0 {
+		meta.Step = steps[len(steps)-1].Step + 1
+	}
+	meta.CreatedAt = s.now().UTC()
+	base := fmt.Sprintf("%03d", meta.Step)
+	meta.Patch = base + ".patch"
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create patch directory: %w", err)
+	}
+	var mbox bytes.Buffer
+	writeMboxHeader(&mbox, meta, authorName, authorEmail)
+	mbox.Write(diff)
+	if err := os.WriteFile(filepath.Join(dir, meta.Patch), mbox.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write patch: %w", err)
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal patch metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, base+".json"), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write patch metadata: %w", err)
+	}
+	if transcriptPath != "" {
+		if err := copyFile(transcriptPath, filepath.Join(dir, paths.TranscriptFileName)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to copy transcript: %v\n", err)
+		}
+	}
+
+	rel := filepath.ToSlash(filepath.Join(paths.EntirePatchesDir, state.SessionID, meta.Patch))
+	fmt.Fprintf(os.Stderr, "Wrote checkpoint patch %s\n", rel)
+	logging.Info(logging.WithComponent(context.Background(), "checkpoint"), "checkpoint saved",
+		slog.String("strategy", StrategyNamePatchFile),
+		slog.String("session_id", state.SessionID),
+		slog.Int("step", meta.Step),
+		slog.Int("files_touched", len(meta.FilesTouched)),
+	)
+	return nil
+}
+
+// writeMboxHeader writes the header `git am` expects before the diff.
+func writeMboxHeader(w io.Writer, meta PatchMetadata, authorName, authorEmail string) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(meta.Message), "\n")
+	if authorName == "" {
+		authorName = "Entire"
+	}
+	fmt.Fprintf(w, "From %s Mon Sep 17 00:00:00 2001\n", meta.BaseCommit)
+	fmt.Fprintf(w, "From: %s <%s>\n", authorName, authorEmail)
+	fmt.Fprintf(w, "Date: %s\n", meta.CreatedAt.Format(time.RFC1123Z))
+	fmt.Fprintf(w, "Subject: [PATCH] %s\n\n", subject)
+	if body = strings.TrimSpace(body); body != "" {
+		fmt.Fprintf(w, "%s\n\n", body)
+	}
+	fmt.Fprintf(w, "%s: %s\n", trailers.SessionTrailerKey, meta.SessionID)
+	fmt.Fprintf(w, "%s: %s\n", trailers.BaseCommitTrailerKey, meta.BaseCommit)
+	fmt.Fprintf(w, "%s: %s\n", trailers.StrategyTrailerKey, StrategyNamePatchFile)
+	fmt.Fprint(w, "---\n")
+}
+
+// sessionDiff returns a binary diff from baseCommit to the working tree for
+// files. It stages the files into a temporary index so new, deleted, and
+// binary files are included without touching the real index.
+func sessionDiff(repoRoot, baseCommit string, files []string) ([]byte, error) {
+	tmp, err := os.CreateTemp("", "entire-patch-index-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	indexFile := tmp.Name()
+	_ = tmp.Close()
+	_ = os.Remove(indexFile) // git refuses to read an empty file as an index
+	defer os.Remove(indexFile)
+
+	// Touched files are paths, never patterns: a file named "*.go" or
+	// ":(exclude)x" must not match other files.
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(context.Background(), "git", append([]string{"--literal-pathspecs"}, args...)...)
+		cmd.Dir = repoRoot
+		cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+indexFile)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+		}
+		return out, nil
+	}
+
+	if _, err := git("read-tree", baseCommit); err != nil {
+		return nil, err
+	}
+	// git add fails on paths that neither exist nor are tracked, e.g. a file
+	// created and deleted again within the session.
+	tracked, err := git(append([]string{"ls-files", "-z", "--"}, files...)...)
+	if err != nil {
+		return nil, err
+	}
+	inBase := make(map[string]bool)
+	for _, f := range strings.Split(string(tracked), "\x00") {
+		inBase[f] = true
+	}
+	var existing []string
+	for _, f := range files {
+		if _, statErr := os.Lstat(filepath.Join(repoRoot, f)); statErr == nil || inBase[f] {
+			existing = append(existing, f)
+		}
+	}
+	if len(existing) == 0 {
+		return nil, nil
+	}
+	if _, err := git(append([]string{"add", "-A", "--"}, existing...)...); err != nil {
+		return nil, err
+	}
+	return git(append([]string{"diff", "--cached", "--binary", "--full-index", "--no-color", "--no-ext-diff", baseCommit, "--"}, existing...)...)
+}
+
+// ListPatchSteps reads the metadata of the patches in a session's patch
+// directory, ordered by step. A missing directory has no steps.
+func ListPatchSteps(dir string) ([]PatchMetadata, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch directory: %w", err)
+	}
+	var steps []PatchMetadata
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		if _, err := strconv.Atoi(name); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name())) //nolint:gosec // path is within the patch directory
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		var meta PatchMetadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		steps = append(steps, meta)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Step < steps[j].Step })
+	return steps, nil
+}
+
+// hasPatchSteps reports whether the patch-file strategy wrote patches for
+// the session in its worktree.
+func hasPatchSteps(state *SessionState) bool {
+	if state.WorktreePath == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(state.WorktreePath, paths.EntirePatchesDir, state.SessionID))
+	return err == nil
+}
+
+// PostCommit moves the base commit of this worktree's sessions to the new
+// HEAD. Files the commits since the old base include no longer count as
+// touched, so the next patch only holds changes made after them; files left
+// out of the commit stay in the next patch.
+func (s *PatchFileStrategy) PostCommit() error {
+	ExpireIdleSessions(s.now())
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	worktreePath, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	sessions, err := s.findSessionsForWorktree(worktreePath)
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	newHead := head.Hash().String()
+	for _, state := range sessions {
+		if state.BaseCommit == "" || state.BaseCommit == newHead {
+			continue
+		}
+		state.FilesTouched = subtractFilesByName(state.FilesTouched, committedSinceBase(repo, state.BaseCommit, head.Hash()))
+		state.BaseCommit = newHead
+		state.AttributionBaseCommit = newHead
+		if err := s.saveSessionState(state); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session %s: %v\n", state.SessionID, err)
+		}
+	}
+	return nil
+}
+
+// committedSinceBase returns the files changed between baseCommit and head,
+// or, if baseCommit cannot be read, the files changed by head itself.
+func committedSinceBase(repo *git.Repository, baseCommit string, head plumbing.Hash) map[string]struct{} {
+	headCommit, err := repo.CommitObject(head)
+	if err != nil {
+		return map[string]struct{}{}
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return map[string]struct{}{}
+	}
+	base, err := repo.CommitObject(plumbing.NewHash(baseCommit))
+	if err != nil {
+		return filesChangedInCommit(repo, headCommit)
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return filesChangedInCommit(repo, headCommit)
+	}
+	changed, err := changedFilesBetweenTrees(repo, baseTree, headTree)
+	if err != nil {
+		return filesChangedInCommit(repo, headCommit)
+	}
+	return changed
+}
+
+// PrepareCommitMsg adds no trailer: patches aren't linked to commits.
+func (s *PatchFileStrategy) PrepareCommitMsg(_ string, _ string) error {
+	return nil
+}
+
+// CommitMsg does nothing for the patch-file strategy.
+func (s *PatchFileStrategy) CommitMsg(_ string) error {
+	return nil
+}
+
+// PrePush does nothing: there are no branches to push.
+func (s *PatchFileStrategy) PrePush(_ string) error {
+	return nil
+}
+
+// PostRewrite does nothing: patches aren't linked to commits.
+func (s *PatchFileStrategy) PostRewrite(_ string, _ []RewrittenCommit) error {
+	return nil
+}
+
+// HandleTurnEnd does nothing: patches are complete when written.
+func (s *PatchFileStrategy) HandleTurnEnd(_ *session.State) error {
+	return nil
+}
+
+// CondenseSessionByID is not supported: there is no metadata branch.
+func (s *PatchFileStrategy) CondenseSessionByID(_ string) error {
+	return errPatchFileUnsupported
+}
+
+// GetRewindPoints returns no points: patches are applied with git, not rewound.
+func (s *PatchFileStrategy) GetRewindPoints(_ int) ([]RewindPoint, error) {
+	return nil, nil
+}
+
+// CanRewind reports that rewinding is not supported.
+func (s *PatchFileStrategy) CanRewind() (bool, string, error) {
+	return false, "the patch-file strategy does not support rewind; apply a patch from " + paths.EntirePatchesDir + " with 'git apply' instead", nil
+}
+
+// Rewind is not supported.
+func (s *PatchFileStrategy) Rewind(_ RewindPoint) error {
+	return errPatchFileUnsupported
+}
+
+var errPatchFileUnsupported = errors.New("not supported by the patch-file strategy")
+
+// Compile-time check that PatchFileStrategy implements Strategy
+var _ Strategy = (*PatchFileStrategy)(nil)
+
+// copyFile copies src to dst, replacing dst.
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src) //nolint:gosec // src is the agent's transcript path
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.WriteFile(dst, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
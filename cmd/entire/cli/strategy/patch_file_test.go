@@ -0,0 +1,151 @@
+package strategy
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchFileStrategy_SaveStep(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	refsBefore := countReferences(t, repo)
+
+	s := &PatchFileStrategy{ManualCommitStrategy: &ManualCommitStrategy{}}
+	sessionID := "2026-03-01-patch"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	transcriptPath := filepath.Join(metadataDirAbs, paths.TranscriptFileName)
+	require.NoError(t, os.WriteFile(transcriptPath, []byte(`{"type":"human","message":{"content":"edit"}}
+`), 0o644))
+
+	require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), "changed content"))
+	require.NoError(t, writeTestFile(filepath.Join(dir, "new.txt"), "new file"))
+	step := StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  []string{"test.txt"},
+		NewFiles:       []string{"new.txt"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: metadataDirAbs,
+		CommitMessage:  "Checkpoint",
+		TranscriptPath: transcriptPath,
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}
+	require.NoError(t, s.SaveStep(step))
+	// Identical changes don't produce another patch
+	require.NoError(t, s.SaveStep(step))
+
+	patchDir := filepath.Join(dir, paths.EntirePatchesDir, sessionID)
+	steps, err := ListPatchSteps(patchDir)
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.Equal(t, 1, steps[0].Step)
+	assert.Equal(t, "001.patch", steps[0].Patch)
+	assert.ElementsMatch(t, []string{"test.txt", "new.txt"}, steps[0].FilesTouched)
+	assert.FileExists(t, filepath.Join(patchDir, paths.TranscriptFileName))
+	assert.Equal(t, refsBefore, countReferences(t, repo), "patch-file strategy must not create refs")
+
+	// The patch applies to a clean checkout of the base commit
+	patch := filepath.Join(patchDir, "001.patch")
+	clean := t.TempDir()
+	runGit(t, "", "clone", "--quiet", dir, clean)
+	runGit(t, clean, "apply", "--check", patch)
+
+	require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), "changed again"))
+	require.NoError(t, s.SaveStep(step))
+	steps, err = ListPatchSteps(patchDir)
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, 2, steps[1].Step)
+}
+
+func TestPatchFileStrategy_PostCommitMovesBase(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	s := &PatchFileStrategy{ManualCommitStrategy: &ManualCommitStrategy{}}
+	sessionID := "2026-03-01-patch-commit"
+	metadataDir := ".entire/metadata/" + sessionID
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, metadataDir), 0o755))
+	require.NoError(t, writeTestFile(filepath.Join(dir, "test.txt"), "changed content"))
+	require.NoError(t, writeTestFile(filepath.Join(dir, "later.txt"), "not committed yet"))
+	require.NoError(t, s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  []string{"test.txt"},
+		NewFiles:       []string{"later.txt"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: filepath.Join(dir, metadataDir),
+		CommitMessage:  "Checkpoint",
+	}))
+
+	runGit(t, dir, "commit", "--quiet", "-m", "user commit", "--", "test.txt")
+	require.NoError(t, s.PostCommit())
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, head.Hash().String(), state.BaseCommit)
+	assert.Equal(t, []string{"later.txt"}, state.FilesTouched, "only committed files are dropped")
+}
+
+func TestPatchFileStrategy_SaveStepLiteralPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	s := &PatchFileStrategy{ManualCommitStrategy: &ManualCommitStrategy{}}
+	sessionID := "2026-03-01-patch-literal"
+	metadataDir := ".entire/metadata/" + sessionID
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, metadataDir), 0o755))
+	// As a pathspec, "*.md" would also match the user's notes.md
+	require.NoError(t, writeTestFile(filepath.Join(dir, "*.md"), "odd name"))
+	require.NoError(t, writeTestFile(filepath.Join(dir, "notes.md"), "the user's notes"))
+	require.NoError(t, s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		NewFiles:       []string{"*.md"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: filepath.Join(dir, metadataDir),
+		CommitMessage:  "Checkpoint",
+	}))
+
+	patch, err := os.ReadFile(filepath.Join(dir, paths.EntirePatchesDir, sessionID, "001.patch"))
+	require.NoError(t, err)
+	assert.Contains(t, string(patch), "b/*.md")
+	assert.NotContains(t, string(patch), "notes.md")
+}
+
+func countReferences(t *testing.T, repo *git.Repository) int {
+	t.Helper()
+	refs, err := repo.References()
+	require.NoError(t, err)
+	count := 0
+	require.NoError(t, refs.ForEach(func(*plumbing.Reference) error {
+		count++
+		return nil
+	}))
+	return count
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(t.Context(), "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+}
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
@@ -60,7 +62,7 @@ func TestPostCommit_ActiveSession_CondensesImmediately(t *testing.T) {
 		"ACTIVE session should stay ACTIVE after immediate condensation on GitCommit")
 
 	// Verify condensation happened: the entire/checkpoints/v1 branch should exist
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after immediate condensation")
 	assert.NotNil(t, sessionsRef)
 
@@ -103,7 +105,7 @@ func TestPostCommit_IdleSession_Condenses(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify condensation happened: the entire/checkpoints/v1 branch should exist
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after condensation")
 	assert.NotNil(t, sessionsRef)
 
@@ -112,6 +114,20 @@ func TestPostCommit_IdleSession_Condenses(t *testing.T) {
 	_, err = repo.Reference(refName, true)
 	assert.Error(t, err,
 		"shadow branch should be deleted after condensation for IDLE session")
+
+	decisions := readCondensationLog(t, dir)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, audit.DecisionCondensed, decisions[0].Decision)
+	assert.Equal(t, sessionID, decisions[0].SessionID)
+	assert.Equal(t, "b2c3d4e5f6a1", decisions[0].CheckpointID)
+}
+
+// readCondensationLog returns the post-commit decisions recorded in dir.
+func readCondensationLog(t *testing.T, dir string) []audit.Condensation {
+	t.Helper()
+	decisions, err := audit.ReadCondensations(filepath.Join(dir, ".git"), time.Time{})
+	require.NoError(t, err)
+	return decisions
 }
 
 // TestPostCommit_RebaseDuringActive_SkipsTransition verifies that PostCommit
@@ -164,7 +180,7 @@ func TestPostCommit_RebaseDuringActive_SkipsTransition(t *testing.T) {
 		"StepCount should be unchanged - no condensation during rebase")
 
 	// Verify NO condensation happened (entire/checkpoints/v1 branch should not exist)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist - no condensation during rebase")
 
@@ -173,6 +189,11 @@ func TestPostCommit_RebaseDuringActive_SkipsTransition(t *testing.T) {
 	_, err = repo.Reference(refName, true)
 	assert.NoError(t, err,
 		"shadow branch should be preserved during rebase")
+
+	decisions := readCondensationLog(t, dir)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, audit.DecisionSkipped, decisions[0].Decision)
+	assert.Contains(t, decisions[0].Reason, "rebase")
 }
 
 // TestPostCommit_ActiveSessionAlwaysCondenses verifies that an ACTIVE session
@@ -243,7 +264,7 @@ func TestPostCommit_ActiveSessionAlwaysCondenses(t *testing.T) {
 	// Verify both sessions condensed (entire/checkpoints/v1 branch should exist)
 	idleState, err = s.loadSessionState(idleSessionID)
 	require.NoError(t, err)
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after condensation")
 	require.NotNil(t, sessionsRef)
 
@@ -307,7 +328,7 @@ func TestPostCommit_CondensationFailure_PreservesShadowBranch(t *testing.T) {
 		"StepCount should NOT be reset when condensation fails")
 
 	// Verify entire/checkpoints/v1 branch does NOT exist (condensation failed)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist when condensation fails")
 
@@ -316,6 +337,49 @@ func TestPostCommit_CondensationFailure_PreservesShadowBranch(t *testing.T) {
 		"phase should remain IDLE when condensation fails")
 }
 
+// TestPostCommit_InjectedFaults_StateRecoverable verifies that PostCommit never
+// fails the git commit when state saves and ref updates fail, and that the
+// session condenses normally when the hook runs again without faults.
+func TestPostCommit_InjectedFaults_StateRecoverable(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-postcommit-injected-faults"
+	setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	originalBaseCommit := state.BaseCommit
+
+	commitWithCheckpointTrailer(t, repo, dir, "f1a2b3c4d5e6")
+
+	t.Setenv(faultinject.EnvVar, "state-save,ref-update")
+	require.NoError(t, s.PostCommit(), "PostCommit should not return error when faults are injected")
+
+	// Neither the session state nor the metadata branch was updated.
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state, "session state should still load after a failed save")
+	assert.Equal(t, originalBaseCommit, state.BaseCommit)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	require.Error(t, err, "metadata branch should not exist when ref updates fail")
+
+	// With faults off, running the hook again condenses the session.
+	t.Setenv(faultinject.EnvVar, "")
+	require.NoError(t, s.PostCommit())
+
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
+	require.NoError(t, err, "metadata branch should exist after condensing without faults")
+	state, err = s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.NotEqual(t, originalBaseCommit, state.BaseCommit, "BaseCommit should advance after recovery")
+}
+
 // TestPostCommit_IdleSession_NoNewContent_PreservesBaseCommit verifies that when
 // an IDLE session has no new transcript content since last condensation,
 // PostCommit skips condensation and does NOT update BaseCommit.
@@ -368,7 +432,7 @@ func TestPostCommit_IdleSession_NoNewContent_PreservesBaseCommit(t *testing.T) {
 		"shadow branch should still exist when no condensation happened")
 
 	// entire/checkpoints/v1 branch should NOT exist (no condensation)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist when no condensation happened")
 
@@ -412,7 +476,7 @@ func TestPostCommit_EndedSession_FilesTouched_Condenses(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify entire/checkpoints/v1 branch exists (condensation happened)
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after condensation")
 	assert.NotNil(t, sessionsRef)
 
@@ -475,7 +539,7 @@ func TestPostCommit_EndedSession_FilesTouched_NoNewContent(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify entire/checkpoints/v1 branch does NOT exist (no condensation)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist when no new content")
 
@@ -494,6 +558,11 @@ func TestPostCommit_EndedSession_FilesTouched_NoNewContent(t *testing.T) {
 	// StepCount unchanged
 	assert.Equal(t, originalStepCount, state.StepCount,
 		"StepCount should be unchanged when no condensation happened")
+
+	decisions := readCondensationLog(t, dir)
+	require.Len(t, decisions, 1)
+	assert.Equal(t, audit.DecisionSkipped, decisions[0].Decision)
+	assert.Equal(t, "no new content since the last checkpoint", decisions[0].Reason)
 }
 
 // TestPostCommit_EndedSession_NoFilesTouched_Discards verifies that an ENDED
@@ -535,7 +604,7 @@ func TestPostCommit_EndedSession_NoFilesTouched_Discards(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify entire/checkpoints/v1 branch does NOT exist (no condensation for discard path)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist for discard path")
 
@@ -604,7 +673,7 @@ func TestPostCommit_CondensationFailure_EndedSession_PreservesShadowBranch(t *te
 		"StepCount should NOT be reset when condensation fails for ENDED session")
 
 	// Verify entire/checkpoints/v1 branch does NOT exist (condensation failed)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.Error(t, err,
 		"entire/checkpoints/v1 branch should NOT exist when condensation fails")
 
@@ -641,7 +710,7 @@ func TestTurnEnd_Active_NoActions(t *testing.T) {
 	result := session.Transition(state.Phase, session.EventTurnEnd, session.TransitionContext{})
 
 	// Apply transition with no-op handler (no strategy actions for ACTIVE → IDLE)
-	err = session.ApplyTransition(state, result, session.NoOpActionHandler{})
+	err = session.ApplyTransition(state, result, session.NoOpActionHandler{}, nil)
 	require.NoError(t, err)
 
 	// Call HandleTurnEnd — should be a no-op (no TurnCheckpointIDs)
@@ -723,7 +792,7 @@ func TestPostCommit_FilesTouched_ResetsAfterCondensation(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify condensation happened
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 should exist after first condensation")
 
 	// Verify first condensation contains A.txt and B.txt
@@ -878,7 +947,7 @@ func TestFilesChangedInCommit(t *testing.T) {
 	commit, err := repo.CommitObject(commitHash)
 	require.NoError(t, err)
 
-	changed := filesChangedInCommit(commit)
+	changed := filesChangedInCommit(repo, commit)
 	assert.Contains(t, changed, "file1.txt")
 	assert.Contains(t, changed, "file2.txt")
 	// test.txt was in the initial commit, not this one
@@ -915,7 +984,7 @@ func TestFilesChangedInCommit_InitialCommit(t *testing.T) {
 	commit, err := repo.CommitObject(commitHash)
 	require.NoError(t, err)
 
-	changed := filesChangedInCommit(commit)
+	changed := filesChangedInCommit(repo, commit)
 	assert.Contains(t, changed, "init.txt")
 	assert.Len(t, changed, 1)
 }
@@ -982,7 +1051,7 @@ func TestPostCommit_ActiveSession_CarryForward_PartialCommit(t *testing.T) {
 	require.NoError(t, err)
 
 	cpID := "cf1cf2cf3cf4"
-	commitMsg := "commit A and B\n\n" + trailers.CheckpointTrailerKey + ": " + cpID + "\n"
+	commitMsg := "commit A and B\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
 	})
@@ -1075,7 +1144,7 @@ func TestPostCommit_ActiveSession_CarryForward_AllCommitted(t *testing.T) {
 	require.NoError(t, err)
 
 	cpID := "cf5cf6cf7cf8"
-	commitMsg := "commit A and B\n\n" + trailers.CheckpointTrailerKey + ": " + cpID + "\n"
+	commitMsg := "commit A and B\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
 	})
@@ -1336,7 +1405,7 @@ func commitFilesWithTrailer(t *testing.T, repo *git.Repository, dir, checkpointI
 		require.NoError(t, err)
 	}
 
-	commitMsg := "test commit\n\n" + trailers.CheckpointTrailerKey + ": " + cpID.String() + "\n"
+	commitMsg := "test commit\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID.String() + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  "Test",
@@ -1593,7 +1662,7 @@ func TestPostCommit_EndedSessionCarryForward_NotCondensedIntoUnrelatedCommit(t *
 	require.NoError(t, err)
 
 	cpID := "ae1ae2ae3ae4"
-	commitMsg := "add new feature\n\n" + trailers.CheckpointTrailerKey + ": " + cpID + "\n"
+	commitMsg := "add new feature\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
 	})
@@ -1725,7 +1794,7 @@ func TestPostCommit_StaleActiveSession_NotCondensed(t *testing.T) {
 	require.NoError(t, err)
 
 	cpID := "de1de2de3de4"
-	commitMsg := "add new feature\n\n" + trailers.CheckpointTrailerKey + ": " + cpID + "\n"
+	commitMsg := "add new feature\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
 	})
@@ -1770,7 +1839,7 @@ func TestPostCommit_StaleActiveSession_NotCondensed(t *testing.T) {
 		"New ACTIVE session BaseCommit should be updated after condensation")
 
 	// Verify entire/checkpoints/v1 exists (new session was condensed)
-	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err,
 		"entire/checkpoints/v1 should exist (new session was condensed)")
 }
@@ -1814,7 +1883,7 @@ func TestPostCommit_IdleSessionEmptyFilesTouched_NotCondensed(t *testing.T) {
 	require.NoError(t, err)
 
 	cpID := "f1f2f3f4f5f6"
-	commitMsg := "other work\n\n" + trailers.CheckpointTrailerKey + ": " + cpID + "\n"
+	commitMsg := "other work\n\n" + trailers.CheckpointTrailerKey() + ": " + cpID + "\n"
 	_, err = wt.Commit(commitMsg, &git.CommitOptions{
 		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
 	})
@@ -1891,7 +1960,7 @@ func TestPostCommit_IdleSession_SkipsSentinelWait(t *testing.T) {
 		"IDLE session PostCommit should skip sentinel wait and complete in <2s, took %v", elapsed)
 
 	// Verify condensation still happened correctly
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after condensation")
 	assert.NotNil(t, sessionsRef)
 }
@@ -1944,7 +2013,7 @@ func TestPostCommit_EndedSession_SkipsSentinelWait(t *testing.T) {
 		"ENDED session PostCommit should skip sentinel wait and complete in <2s, took %v", elapsed)
 
 	// Verify condensation still happened correctly
-	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	sessionsRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName()), true)
 	require.NoError(t, err, "entire/checkpoints/v1 branch should exist after condensation")
 	assert.NotNil(t, sessionsRef)
 }
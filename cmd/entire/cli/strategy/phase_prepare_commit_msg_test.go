@@ -7,6 +7,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/stretchr/testify/assert"
@@ -127,3 +128,51 @@ func TestPrepareCommitMsg_AmendNoTrailerNoLastCheckpointID(t *testing.T) {
 	assert.Equal(t, newMsg, string(content),
 		"commit message should be unchanged when no trailer to restore")
 }
+
+// TestPrepareCommitMsg_FixupAndRevertGetNoTrailer verifies that `git commit
+// --fixup` and `--squash` commits, which are folded into an earlier commit,
+// and `git revert` commits are not given a checkpoint of their own.
+func TestPrepareCommitMsg_FixupAndRevertGetNoTrailer(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	t.Setenv("ENTIRE_TEST_TTY", "0")
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-session-fixup"
+	metadataDir := ".entire/metadata/" + sessionID
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, metadataDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, metadataDir, paths.TranscriptFileName), []byte("{}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte("changed"), 0o644))
+	require.NoError(t, s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		ModifiedFiles:  []string{"test.txt"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: filepath.Join(dir, metadataDir),
+		CommitMessage:  "Change test.txt",
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}))
+
+	for _, msg := range []string{
+		"fixup! initial commit\n",
+		"squash! initial commit\n\nMore detail\n",
+		"Revert \"initial commit\"\n\nThis reverts commit 1111111111111111111111111111111111111111.\n",
+	} {
+		commitMsgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+		require.NoError(t, os.WriteFile(commitMsgFile, []byte(msg), 0o644))
+		require.NoError(t, s.PrepareCommitMsg(commitMsgFile, "message"))
+
+		content, err := os.ReadFile(commitMsgFile)
+		require.NoError(t, err)
+		assert.Equal(t, msg, string(content))
+	}
+
+	// The same session content does get a trailer on an ordinary commit
+	commitMsgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(commitMsgFile, []byte("Change test.txt\n"), 0o644))
+	require.NoError(t, s.PrepareCommitMsg(commitMsgFile, "message"))
+	content, err := os.ReadFile(commitMsgFile)
+	require.NoError(t, err)
+	_, found := trailers.ParseCheckpoint(string(content))
+	assert.True(t, found)
+}
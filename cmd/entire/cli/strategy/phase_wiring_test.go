@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/clock"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 
 	"github.com/go-git/go-git/v5"
@@ -37,6 +39,29 @@ func TestInitializeSession_SetsPhaseActive(t *testing.T) {
 		"InitializeSession should set TurnID")
 }
 
+// TestInitializeSession_InjectedClockAndIDs verifies that session timestamps
+// and turn IDs come from the strategy's clock and ID generator.
+func TestInitializeSession_InjectedClockAndIDs(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s := &ManualCommitStrategy{}
+	s.SetClock(clock.NewFake(start))
+	s.SetIDGenerator(&id.SequenceGenerator{})
+
+	require.NoError(t, s.InitializeSession("test-session-injected", "Claude Code", "", ""))
+
+	state, err := s.loadSessionState("test-session-injected")
+	require.NoError(t, err)
+	require.NotNil(t, state)
+
+	assert.True(t, state.StartedAt.Equal(start), "StartedAt = %v, want %v", state.StartedAt, start)
+	require.NotNil(t, state.LastInteractionTime)
+	assert.True(t, state.LastInteractionTime.Equal(start), "LastInteractionTime = %v, want %v", *state.LastInteractionTime, start)
+	assert.Equal(t, "000000000001", state.TurnID)
+}
+
 // TestInitializeSession_IdleToActive verifies a second call (existing IDLE session)
 // transitions from IDLE to ACTIVE.
 func TestInitializeSession_IdleToActive(t *testing.T) {
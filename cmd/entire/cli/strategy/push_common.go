@@ -9,7 +9,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/audit"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
 
 	"github.com/go-git/go-git/v5"
@@ -47,7 +47,7 @@ func pushSessionsBranchCommon(remote, branchName string) error {
 		return nil
 	}
 
-	return doPushSessionsBranch(remote, branchName)
+	return doPushSessionsBranch(remote, branchName, hookPushPolicy)
 }
 
 // hasUnpushedSessionsCommon checks if the local branch differs from the remote.
@@ -76,34 +76,71 @@ func isPushSessionsDisabled() bool {
 	return s.IsPushSessionsDisabled()
 }
 
-// doPushSessionsBranch pushes the sessions branch to the remote.
-func doPushSessionsBranch(remote, branchName string) error {
-	fmt.Fprintf(os.Stderr, "[entire] Pushing session logs to %s...\n", remote)
+// pushPolicy limits how hard a sessions branch push tries.
+type pushPolicy struct {
+	// attempts is how many times the push is attempted before giving up,
+	// counting the push after merging a rejected one.
+	attempts int
+	// timeout bounds each git push and fetch.
+	timeout time.Duration
+	// retryDelay is the delay before retrying a push that failed for a reason
+	// other than non-fast-forward, such as a network error. Doubled per
+	// attempt. Zero gives up on such failures instead.
+	retryDelay time.Duration
+}
 
-	// Try pushing first
-	if err := tryPushSessionsCommon(remote, branchName); err == nil {
-		return nil
-	}
+// hookPushPolicy is for pushes the user waits on, from the pre-push hook: a
+// single push with a short timeout, and one more if it was rejected and the
+// remote branch was merged. Network errors are not retried.
+var hookPushPolicy = pushPolicy{attempts: 2, timeout: 30 * time.Second}
+
+// backgroundPushPolicy is for pushes nobody waits on, run by `entire worker
+// push` after condensation: network errors are retried with backoff.
+var backgroundPushPolicy = pushPolicy{attempts: 3, timeout: 2 * time.Minute, retryDelay: time.Second}
+
+// errNonFastForward is returned by tryPushSessionsCommon when the remote
+// branch has commits the local branch doesn't, e.g. because another machine
+// condensed and pushed in the meantime.
+var errNonFastForward = errors.New("non-fast-forward")
+
+// doPushSessionsBranch pushes the sessions branch to the remote. A rejected
+// non-fast-forward push is resolved by merging the remote branch into the
+// local one; other failures are retried as policy allows. Failures are
+// reported as warnings and never returned, so the user's own push or commit
+// proceeds.
+func doPushSessionsBranch(remote, branchName string, policy pushPolicy) error {
+	fmt.Fprintf(os.Stderr, "[entire] Pushing session logs to %s...\n", remote)
 
-	// Push failed - likely non-fast-forward. Try to fetch and merge.
-	fmt.Fprintf(os.Stderr, "[entire] Syncing with remote session logs...\n")
+	delay := policy.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := tryPushSessionsCommon(remote, branchName, policy.timeout)
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.attempts || (!errors.Is(err, errNonFastForward) && delay == 0) {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push sessions: %v\n", err)
+			return nil
+		}
 
-	if err := fetchAndMergeSessionsCommon(remote, branchName); err != nil {
-		fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync sessions: %v\n", err)
-		return nil // Don't fail the main push
-	}
+		if !errors.Is(err, errNonFastForward) {
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
 
-	// Try pushing again after merge
-	if err := tryPushSessionsCommon(remote, branchName); err != nil {
-		fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push sessions after sync: %v\n", err)
+		// Remote is ahead or diverged - fetch and merge, then push again
+		fmt.Fprintf(os.Stderr, "[entire] Syncing with remote session logs...\n")
+		if err := fetchAndMergeSessionsCommon(remote, branchName, policy.timeout); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync sessions: %v\n", err)
+			return nil // Don't fail the main push
+		}
 	}
-
-	return nil
 }
 
 // tryPushSessionsCommon attempts to push the sessions branch.
-func tryPushSessionsCommon(remote, branchName string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+// Returns errNonFastForward if the remote rejected the push.
+func tryPushSessionsCommon(remote, branchName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	// Use --no-verify to prevent recursive hook calls
@@ -115,17 +152,19 @@ func tryPushSessionsCommon(remote, branchName string) error {
 		// Check if it's a non-fast-forward error (we can try to recover)
 		if strings.Contains(string(output), "non-fast-forward") ||
 			strings.Contains(string(output), "rejected") {
-			return errors.New("non-fast-forward")
+			return errNonFastForward
 		}
 		return fmt.Errorf("push failed: %s", output)
 	}
 	return nil
 }
 
-// fetchAndMergeSessionsCommon fetches remote sessions and merges into local using go-git.
-// Since session logs are append-only (unique cond-* directories), we just combine trees.
-func fetchAndMergeSessionsCommon(remote, branchName string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+// fetchAndMergeSessionsCommon fetches the remote sessions branch and merges
+// it into the local one. If the local branch has nothing the remote lacks, it
+// is fast-forwarded; otherwise a merge commit is created from a three-way
+// merge of both trees (see mergeMetadataEntries).
+func fetchAndMergeSessionsCommon(remote, branchName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
@@ -149,10 +188,6 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get local commit: %w", err)
 	}
-	localTree, err := localCommit.Tree()
-	if err != nil {
-		return fmt.Errorf("failed to get local tree: %w", err)
-	}
 
 	// Get remote (FETCH_HEAD)
 	fetchHeadRef, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
@@ -163,38 +198,19 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to get remote commit: %w", err)
 	}
-	remoteTree, err := remoteCommit.Tree()
-	if err != nil {
-		return fmt.Errorf("failed to get remote tree: %w", err)
-	}
 
-	// Flatten both trees and combine entries
-	// Session logs have unique cond-* directories, so no conflicts expected
-	entries := make(map[string]object.TreeEntry)
-	if err := checkpoint.FlattenTree(repo, localTree, "", entries); err != nil {
-		return fmt.Errorf("failed to flatten local tree: %w", err)
-	}
-	if err := checkpoint.FlattenTree(repo, remoteTree, "", entries); err != nil {
-		return fmt.Errorf("failed to flatten remote tree: %w", err)
-	}
-
-	// Build merged tree
-	mergedTreeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
+	mergeHash, _, err := mergeMetadataCommits(repo, localCommit, remoteCommit)
 	if err != nil {
-		return fmt.Errorf("failed to build merged tree: %w", err)
+		return err
 	}
-
-	// Create merge commit with both parents
-	mergeCommitHash, err := createMergeCommitCommon(repo, mergedTreeHash,
-		[]plumbing.Hash{localRef.Hash(), fetchHeadRef.Hash()},
-		"Merge remote session logs")
-	if err != nil {
-		return fmt.Errorf("failed to create merge commit: %w", err)
+	if mergeHash == localRef.Hash() {
+		return nil
 	}
 
-	// Update branch ref
-	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), mergeCommitHash)
-	if err := repo.Storer.SetReference(newRef); err != nil {
+	// Only move the branch if no condensation moved it while we merged;
+	// otherwise the next push attempt fetches and merges again.
+	newRef := plumbing.NewHashReference(localRef.Name(), mergeHash)
+	if err := audit.CheckAndSetReference(repo, newRef, localRef); err != nil {
 		return fmt.Errorf("failed to update branch ref: %w", err)
 	}
 
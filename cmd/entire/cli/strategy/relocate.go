@@ -0,0 +1,183 @@
+package strategy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/google/uuid"
+)
+
+// Session states record the absolute path of their worktree. When the
+// repository (or a linked worktree) is moved or renamed, those paths go stale
+// and the sessions no longer match the worktree they belong to. Sessions are
+// re-bound by their repo-relative identity instead: the worktree ID (empty for
+// the main worktree, the .git/worktrees/<name> name otherwise) is stable across
+// moves, and the repo ID guards against claiming sessions from a different
+// repository. Sessions recorded before repo IDs existed are never re-bound.
+
+// repoIDFileName is the file in the main worktree's .entire directory that
+// holds the repository's ID. It is gitignored, so each clone has its own.
+const repoIDFileName = "repo-id"
+
+// Rebinding describes a session whose worktree path was updated after a move.
+type Rebinding struct {
+	SessionID string
+	OldPath   string
+	NewPath   string
+}
+
+// repoIDPath returns the path of the repo ID file. It lives in the main
+// worktree so that linked worktrees share the same ID.
+func repoIDPath() (string, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	commonDir, err = filepath.Abs(commonDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git common dir: %w", err)
+	}
+	if filepath.Base(commonDir) == ".git" {
+		return filepath.Join(filepath.Dir(commonDir), paths.EntireDir, repoIDFileName), nil
+	}
+	// Bare repository or unusual layout: fall back to the current worktree.
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	return filepath.Join(root, paths.EntireDir, repoIDFileName), nil
+}
+
+// readRepoID returns the repository's ID, or "" if none has been assigned.
+func readRepoID() string {
+	path, err := repoIDPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is inside the repository's .entire directory
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// ensureRepoID returns the repository's ID, assigning a new one if needed.
+func ensureRepoID() (string, error) {
+	if repoID := readRepoID(); repoID != "" {
+		return repoID, nil
+	}
+	path, err := repoIDPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", fmt.Errorf("failed to create .entire directory: %w", err)
+	}
+	repoID := uuid.NewString()
+	if err := os.WriteFile(path, []byte(repoID+"\n"), 0o644); err != nil { //nolint:gosec // not sensitive; gitignored
+		return "", fmt.Errorf("failed to write repo ID: %w", err)
+	}
+	return repoID, nil
+}
+
+// worktreePathsByID maps each worktree's ID to its current absolute path,
+// using `git worktree list`. The main worktree has the empty ID.
+func worktreePathsByID() (map[string]string, error) {
+	cmd := exec.CommandContext(context.Background(), "git", "worktree", "list", "--porcelain")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	byID := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		path, ok := strings.CutPrefix(scanner.Text(), "worktree ")
+		if !ok {
+			continue
+		}
+		worktreeID, idErr := paths.GetWorktreeID(path)
+		if idErr != nil {
+			continue // Missing or not yet repaired; can't rebind to it
+		}
+		byID[worktreeID] = path
+	}
+	return byID, nil
+}
+
+// RebindMovedSessions updates the worktree path of sessions whose recorded
+// worktree no longer exists, when the same worktree now lives elsewhere in
+// this repository. Returns the sessions that were re-bound.
+func RebindMovedSessions() ([]Rebinding, error) {
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state store: %w", err)
+	}
+	return rebindMovedSessions(context.Background(), store)
+}
+
+func rebindMovedSessions(ctx context.Context, store *session.StateStore) ([]Rebinding, error) {
+	recorded, err := store.WorktreePaths(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session worktrees: %w", err)
+	}
+
+	var missing []string
+	for _, path := range recorded {
+		if _, statErr := os.Stat(path); errors.Is(statErr, os.ErrNotExist) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	byID, err := worktreePathsByID()
+	if err != nil {
+		return nil, err
+	}
+	repoID := readRepoID()
+	if repoID == "" {
+		return nil, nil // No ID yet, so no session can prove it belongs here
+	}
+	logCtx := logging.WithComponent(ctx, "session")
+
+	var rebound []Rebinding
+	for _, oldPath := range missing {
+		states, listErr := store.ListMatching(ctx, session.Filter{WorktreePath: oldPath})
+		if listErr != nil {
+			return rebound, fmt.Errorf("failed to list sessions for %s: %w", oldPath, listErr)
+		}
+		for _, state := range states {
+			if state.RepoID != repoID {
+				continue // Another repository's session, or one too old to tell
+			}
+			newPath, ok := byID[state.WorktreeID]
+			if !ok || newPath == oldPath {
+				continue
+			}
+			state.WorktreePath = newPath
+			if saveErr := store.Save(ctx, state); saveErr != nil {
+				return rebound, fmt.Errorf("failed to save session %s: %w", state.SessionID, saveErr)
+			}
+			logging.Info(logCtx, "re-bound session to moved worktree",
+				slog.String("session_id", state.SessionID),
+				slog.String("old_path", oldPath),
+				slog.String("new_path", newPath))
+			rebound = append(rebound, Rebinding{SessionID: state.SessionID, OldPath: oldPath, NewPath: newPath})
+		}
+	}
+	return rebound, nil
+}
@@ -0,0 +1,84 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureRepoID(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	assert.Empty(t, readRepoID())
+
+	repoID, err := ensureRepoID()
+	require.NoError(t, err)
+	require.NotEmpty(t, repoID)
+
+	data, err := os.ReadFile(filepath.Join(dir, paths.EntireDir, repoIDFileName))
+	require.NoError(t, err)
+	assert.Equal(t, repoID, strings.TrimSpace(string(data)))
+
+	again, err := ensureRepoID()
+	require.NoError(t, err)
+	assert.Equal(t, repoID, again, "repo ID should be stable once assigned")
+}
+
+func TestRebindMovedSessions(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	paths.ClearWorktreeRootCache()
+
+	worktreeRoot, err := paths.WorktreeRoot()
+	require.NoError(t, err)
+	repoID, err := ensureRepoID()
+	require.NoError(t, err)
+
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	oldPath := filepath.Join(t.TempDir(), "old-location")
+	require.NoError(t, store.Save(ctx, &session.State{SessionID: "moved", WorktreePath: oldPath, RepoID: repoID, StartedAt: time.Now()}))
+	require.NoError(t, store.Save(ctx, &session.State{SessionID: "legacy", WorktreePath: oldPath, StartedAt: time.Now()}))
+	require.NoError(t, store.Save(ctx, &session.State{SessionID: "foreign", WorktreePath: oldPath, RepoID: "other-repo", StartedAt: time.Now()}))
+	require.NoError(t, store.Save(ctx, &session.State{SessionID: "linked", WorktreePath: oldPath, WorktreeID: "gone", RepoID: repoID, StartedAt: time.Now()}))
+
+	rebound, err := RebindMovedSessions()
+	require.NoError(t, err)
+
+	var ids []string
+	for _, rb := range rebound {
+		ids = append(ids, rb.SessionID)
+		assert.Equal(t, oldPath, rb.OldPath)
+		assert.Equal(t, worktreeRoot, rb.NewPath)
+	}
+	assert.Equal(t, []string{"moved"}, ids)
+
+	legacy, err := store.Load(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, oldPath, legacy.WorktreePath, "sessions without a repo ID are left alone")
+
+	foreign, err := store.Load(ctx, "foreign")
+	require.NoError(t, err)
+	assert.Equal(t, oldPath, foreign.WorktreePath, "sessions from another repository are left alone")
+
+	linked, err := store.Load(ctx, "linked")
+	require.NoError(t, err)
+	assert.Equal(t, oldPath, linked.WorktreePath, "sessions from an unknown worktree are left alone")
+
+	// Nothing left to do on a second pass.
+	rebound, err = RebindMovedSessions()
+	require.NoError(t, err)
+	assert.Empty(t, rebound)
+}
@@ -0,0 +1,339 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/audit"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// When condensation fails, the post-commit hook keeps the session's state and
+// shadow branch so nothing is lost, and the next commit tries again. If the
+// data itself is damaged, every later attempt fails the same way. Repair
+// checks look for the damage seen in practice and pair each finding with a
+// fix that `entire doctor --repair` can apply.
+
+// Repair issue kinds.
+const (
+	RepairCorruptedRef      = "corrupted_ref"
+	RepairZeroHash          = "zero_hash"
+	RepairOrphanedMetadata  = "orphaned_metadata"
+	RepairInconsistentState = "inconsistent_state"
+	RepairUnreadableState   = "unreadable_state"
+)
+
+// RepairIssue is one problem found by FindRepairIssues.
+type RepairIssue struct {
+	Kind string
+	// Target is the ref name, session ID, or repo-relative path affected.
+	Target  string
+	Problem string
+	// Fix describes what ApplyRepair does. Empty when there is no automated
+	// fix and the issue is only reported.
+	Fix   string
+	apply func() error
+}
+
+func (i RepairIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Target, i.Problem)
+}
+
+// Fixable reports whether ApplyRepair can fix the issue.
+func (i RepairIssue) Fixable() bool {
+	return i.apply != nil
+}
+
+// ApplyRepair applies the issue's fix.
+func ApplyRepair(issue RepairIssue) error {
+	if issue.apply == nil {
+		return fmt.Errorf("no automated fix for %s", issue.Target)
+	}
+	return issue.apply()
+}
+
+// FindRepairIssues checks Entire's refs, the current user's session states,
+// and the current worktree's session metadata directories for damage that
+// would make condensation fail or leave data behind.
+func FindRepairIssues() ([]RepairIssue, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	states, err := ListSessionStates()
+	if err != nil {
+		return nil, err
+	}
+
+	issues, broken, err := refRepairIssues(repo, states)
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		issue, ok := sessionRepairIssue(repo, state, broken)
+		if ok {
+			issues = append(issues, issue)
+		}
+	}
+	unreadable, err := UnreadableSessionStates()
+	if err != nil {
+		return nil, err
+	}
+	for _, sessionID := range slices.Sorted(maps.Keys(unreadable)) {
+		issues = append(issues, RepairIssue{
+			Kind:    RepairUnreadableState,
+			Target:  sessionID,
+			Problem: "state file cannot be read: " + unreadable[sessionID].Error(),
+			Fix:     "remove the state file",
+			apply:   func() error { return ClearSessionState(sessionID) },
+		})
+	}
+	orphaned, err := orphanedMetadataIssues()
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, orphaned...), nil
+}
+
+// refRepairIssues checks shadow branches and the metadata branch. It also
+// returns the shadow branches found broken, whose sessions are then left to
+// the ref's fix.
+func refRepairIssues(repo *git.Repository, states []*SessionState) ([]RepairIssue, map[string]bool, error) {
+	refs, err := repo.References()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get references: %w", err)
+	}
+	var issues []RepairIssue
+	broken := make(map[string]bool)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if !ref.Name().IsBranch() || ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		branch := ref.Name().Short()
+		isMetadata := branch == paths.MetadataBranchName()
+		if !isMetadata && !IsShadowBranch(branch) {
+			return nil
+		}
+
+		issue := RepairIssue{Kind: RepairCorruptedRef, Target: ref.Name().String()}
+		if ref.Hash().IsZero() {
+			issue.Kind = RepairZeroHash
+			issue.Problem = "points at the zero hash"
+		} else if problem := commitProblem(repo, ref.Hash()); problem != "" {
+			issue.Problem = problem
+		} else {
+			return nil
+		}
+
+		switch {
+		case !isMetadata:
+			broken[branch] = true
+			issue.Fix = "delete the branch and reset the step count of sessions using it"
+			issue.apply = func() error { return deleteBrokenShadowBranch(repo, ref, states) }
+		case issue.Kind == RepairZeroHash:
+			issue.Fix = "delete the ref so it can be fetched again with 'entire sync'"
+			issue.apply = func() error { return removeReference(repo, ref) }
+		default:
+			issue.Problem += "; fetch a good copy from the remote or restore it from a backup"
+		}
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+	return issues, broken, nil
+}
+
+// commitProblem returns why the commit at hash can't be read, or "" if its
+// commit and tree are intact.
+func commitProblem(repo *git.Repository, hash plumbing.Hash) string {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return fmt.Sprintf("commit %s cannot be read: %v", hash.String()[:7], err)
+	}
+	if _, err := commit.Tree(); err != nil {
+		return fmt.Sprintf("tree of commit %s cannot be read: %v", hash.String()[:7], err)
+	}
+	return ""
+}
+
+// deleteBrokenShadowBranch removes a shadow branch whose commits are gone.
+// Its steps can't be condensed anymore, so the sessions that recorded them
+// start counting again.
+func deleteBrokenShadowBranch(repo *git.Repository, ref *plumbing.Reference, states []*SessionState) error {
+	if err := removeReference(repo, ref); err != nil {
+		return err
+	}
+	branch := ref.Name().Short()
+	for _, state := range states {
+		if state.StepCount == 0 || checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID) != branch {
+			continue
+		}
+		state.StepCount = 0
+		if err := SaveSessionState(state); err != nil {
+			return fmt.Errorf("failed to save session %s: %w", state.SessionID, err)
+		}
+	}
+	return nil
+}
+
+// removeReference deletes ref without reading the objects it points at,
+// which `git branch -D` would refuse to do for a broken branch.
+func removeReference(repo *git.Repository, ref *plumbing.Reference) error {
+	if err := repo.Storer.RemoveReference(ref.Name()); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", ref.Name(), err)
+	}
+	if commonDir, err := GetGitCommonDir(); err == nil {
+		audit.Record(commonDir, audit.Event{Action: audit.ActionRefDelete, Target: ref.Name().String(), Old: ref.Hash().String()})
+	}
+	return nil
+}
+
+// sessionRepairIssue checks a session's base commits and step count. At most
+// one issue is returned per session: fixing it may resolve the others.
+func sessionRepairIssue(repo *git.Repository, state *SessionState, broken map[string]bool) (RepairIssue, bool) {
+	zero := plumbing.ZeroHash.String()
+	issue := RepairIssue{Target: state.SessionID}
+
+	switch {
+	case state.BaseCommit == zero:
+		issue.Kind = RepairZeroHash
+		issue.Problem = "base commit is the zero hash"
+		head, err := worktreeHead(state.WorktreePath)
+		if err != nil {
+			issue.Fix = "discard the session state"
+			issue.apply = func() error { return ClearSessionState(state.SessionID) }
+			return issue, true
+		}
+		issue.Fix = "reset the base commit to HEAD (" + head[:7] + ")"
+		issue.apply = func() error {
+			state.BaseCommit = head
+			state.AttributionBaseCommit = head
+			state.StepCount = 0
+			return SaveSessionState(state)
+		}
+		return issue, true
+
+	case state.AttributionBaseCommit == zero && state.BaseCommit != "":
+		issue.Kind = RepairZeroHash
+		issue.Problem = "attribution base commit is the zero hash"
+		issue.Fix = "reset it to the base commit (" + TruncateHash(state.BaseCommit) + ")"
+		issue.apply = func() error {
+			state.AttributionBaseCommit = state.BaseCommit
+			return SaveSessionState(state)
+		}
+		return issue, true
+
+	case state.BaseCommit == "" && state.StepCount > 0:
+		issue.Kind = RepairInconsistentState
+		issue.Problem = fmt.Sprintf("records %d step(s) but has no base commit", state.StepCount)
+		issue.Fix = "reset the step count to 0"
+		issue.apply = func() error {
+			state.StepCount = 0
+			return SaveSessionState(state)
+		}
+		return issue, true
+	}
+
+	branch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+	if state.StepCount == 0 || broken[branch] {
+		return RepairIssue{}, false
+	}
+	steps, err := sessionStepCommits(repo, state)
+	if err != nil || len(steps) == state.StepCount {
+		return RepairIssue{}, false
+	}
+	count := len(steps)
+	issue.Kind = RepairInconsistentState
+	if _, refErr := repo.Reference(plumbing.NewBranchReferenceName(branch), true); refErr != nil {
+		issue.Problem = fmt.Sprintf("records %d step(s) but shadow branch %s does not exist", state.StepCount, branch)
+	} else {
+		issue.Problem = fmt.Sprintf("records %d step(s) but shadow branch %s has %d", state.StepCount, branch, count)
+	}
+	issue.Fix = fmt.Sprintf("set the step count to %d", count)
+	issue.apply = func() error {
+		state.StepCount = count
+		return SaveSessionState(state)
+	}
+	return issue, true
+}
+
+// worktreeHead returns the HEAD commit of the worktree at path, or of the
+// current worktree when path is empty.
+func worktreeHead(path string) (string, error) {
+	args := []string{"rev-parse", "--verify", "HEAD"}
+	if path != "" {
+		args = append([]string{"-C", path}, args...)
+	}
+	output, err := exec.CommandContext(context.Background(), "git", args...).Output() //nolint:gosec // path comes from session state
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// orphanedMetadataIssues returns session metadata directories in the current
+// worktree that no session state of any user refers to.
+func orphanedMetadataIssues() ([]RepairIssue, error) {
+	root, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worktree root: %w", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(root, paths.EntireMetadataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata directory: %w", err)
+	}
+
+	stores, err := session.NewAllUsersStateStores()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state stores: %w", err)
+	}
+	states, err := session.ListAllUsers(context.Background(), stores, session.Filter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session states: %w", err)
+	}
+	known := make(map[string]bool, len(states))
+	for _, state := range states {
+		known[state.SessionID] = true
+	}
+
+	var issues []RepairIssue
+	for _, entry := range entries {
+		if !entry.IsDir() || known[entry.Name()] {
+			continue
+		}
+		rel := paths.SessionMetadataDirFromSessionID(entry.Name())
+		issues = append(issues, RepairIssue{
+			Kind:    RepairOrphanedMetadata,
+			Target:  rel,
+			Problem: "no session state refers to it",
+			Fix:     "remove the directory",
+			apply: func() error {
+				if err := os.RemoveAll(filepath.Join(root, rel)); err != nil {
+					return fmt.Errorf("failed to remove %s: %w", rel, err)
+				}
+				if commonDir, err := GetGitCommonDir(); err == nil {
+					audit.Record(commonDir, audit.Event{Action: audit.ActionFileDelete, Target: rel})
+				}
+				return nil
+			},
+		})
+	}
+	return issues, nil
+}
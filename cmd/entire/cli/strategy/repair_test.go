@@ -0,0 +1,101 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRepairIssues(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	headHash := head.Hash().String()
+
+	// A shadow branch pointing at an object that doesn't exist
+	brokenBase := "1234567890abcdef1234567890abcdef12345678"
+	brokenBranch := checkpoint.ShadowBranchNameForCommit(brokenBase, "")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName(brokenBranch),
+		plumbing.NewHash("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef"))))
+
+	for _, state := range []*SessionState{
+		{SessionID: "2026-03-01-broken", BaseCommit: brokenBase, StepCount: 2, Phase: session.PhaseIdle},
+		{SessionID: "2026-03-01-zero", BaseCommit: plumbing.ZeroHash.String(), WorktreePath: dir, Phase: session.PhaseActive},
+		{SessionID: "2026-03-01-nosteps", BaseCommit: headHash, StepCount: 3, Phase: session.PhaseIdle},
+		{SessionID: "2026-03-01-healthy", BaseCommit: headHash, Phase: session.PhaseIdle},
+	} {
+		require.NoError(t, SaveSessionState(state))
+	}
+	garbled, err := sessionStateFile("2026-03-01-garbled")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(garbled, []byte(`{"session_id":`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, paths.EntireMetadataDir, "2026-03-01-healthy"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, paths.EntireMetadataDir, "2026-02-01-orphan"), 0o755))
+
+	issues, err := FindRepairIssues()
+	require.NoError(t, err)
+	kinds := make(map[string]string)
+	for _, issue := range issues {
+		assert.True(t, issue.Fixable(), "issue %s should be fixable", issue)
+		kinds[issue.Target] = issue.Kind
+	}
+	assert.Equal(t, map[string]string{
+		"refs/heads/" + brokenBranch: RepairCorruptedRef,
+		"2026-03-01-zero":            RepairZeroHash,
+		"2026-03-01-nosteps":         RepairInconsistentState,
+		"2026-03-01-garbled":         RepairUnreadableState,
+		paths.SessionMetadataDirFromSessionID("2026-02-01-orphan"): RepairOrphanedMetadata,
+	}, kinds)
+
+	for _, issue := range issues {
+		require.NoError(t, ApplyRepair(issue), "repair %s", issue)
+	}
+
+	issues, err = FindRepairIssues()
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	_, err = repo.Reference(plumbing.NewBranchReferenceName(brokenBranch), true)
+	require.Error(t, err)
+	broken, err := LoadSessionState("2026-03-01-broken")
+	require.NoError(t, err)
+	assert.Equal(t, 0, broken.StepCount)
+	zero, err := LoadSessionState("2026-03-01-zero")
+	require.NoError(t, err)
+	assert.Equal(t, headHash, zero.BaseCommit)
+	assert.NoFileExists(t, garbled)
+	assert.NoDirExists(t, filepath.Join(dir, paths.EntireMetadataDir, "2026-02-01-orphan"))
+	assert.DirExists(t, filepath.Join(dir, paths.EntireMetadataDir, "2026-03-01-healthy"))
+}
+
+func TestFindRepairIssues_CorruptedMetadataBranchIsReportOnly(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(
+		plumbing.NewBranchReferenceName(paths.MetadataBranchName()),
+		plumbing.NewHash("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef"))))
+
+	issues, err := FindRepairIssues()
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RepairCorruptedRef, issues[0].Kind)
+	assert.False(t, issues[0].Fixable())
+	require.Error(t, ApplyRepair(issues[0]))
+}
@@ -0,0 +1,437 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Sparse-checkout and partial-clone support.
+//
+// go-git honors the skip-worktree bits in the index, but it does not read the
+// sparse-checkout settings (which git stores in config.worktree) and it knows
+// nothing about promisor remotes. In a sparse checkout, agent-reported paths can
+// fall outside the cone; in a partial clone, blobs referenced by HEAD may be
+// missing locally until git lazily fetches them. The helpers below detect these
+// configurations via the git CLI and let callers constrain tracking or fetch
+// blobs on demand.
+
+// RepoFeatures describes repository configurations that affect file tracking.
+type RepoFeatures struct {
+	// SparseCheckout is true when core.sparseCheckout is enabled.
+	SparseCheckout bool
+	// SparseCheckoutCone is true when the sparse checkout uses cone mode.
+	SparseCheckoutCone bool
+	// PartialClone is true when the repository has a promisor remote.
+	PartialClone bool
+	// PromisorRemote is the name of the remote missing objects are fetched from.
+	PromisorRemote string
+}
+
+// repoFeatures caches DetectRepoFeatures results by repository root.
+var repoFeatures sync.Map
+
+// ClearRepoFeaturesCache forgets the cached DetectRepoFeatures and
+// CheckRepoSupport results.
+func ClearRepoFeaturesCache() {
+	repoFeatures.Clear()
+	repoSupport.Clear()
+}
+
+func init() {
+	repocache.Register(ClearRepoFeaturesCache)
+}
+
+// DetectRepoFeatures inspects the git configuration of the repository at repoRoot.
+// Uses the git CLI because go-git does not read config.worktree, where
+// `git sparse-checkout` stores its settings. The result is cached for the life
+// of the process, so a hook runs the git subprocesses at most once. Fails open:
+// unknown settings are reported as disabled.
+func DetectRepoFeatures(ctx context.Context, repoRoot string) RepoFeatures {
+	if cached, ok := repoFeatures.Load(repoRoot); ok {
+		return cached.(RepoFeatures) //nolint:forcetypeassert // only RepoFeatures is stored
+	}
+	features := detectRepoFeatures(ctx, repoRoot)
+	repoFeatures.Store(repoRoot, features)
+	return features
+}
+
+func detectRepoFeatures(ctx context.Context, repoRoot string) RepoFeatures {
+	var features RepoFeatures
+	features.SparseCheckout = gitConfigBool(ctx, repoRoot, "core.sparseCheckout")
+	if features.SparseCheckout {
+		features.SparseCheckoutCone = gitConfigBool(ctx, repoRoot, "core.sparseCheckoutCone")
+	}
+
+	if remote := gitConfigGet(ctx, repoRoot, "extensions.partialClone"); remote != "" {
+		features.PartialClone = true
+		features.PromisorRemote = remote
+		return features
+	}
+
+	// Older clients only set remote.<name>.promisor
+	cmd := exec.CommandContext(ctx, "git", "config", "--get-regexp", `^remote\..*\.promisor$`)
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return features
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		key, value, found := strings.Cut(line, " ")
+		if !found || !strings.EqualFold(value, "true") {
+			continue
+		}
+		features.PartialClone = true
+		features.PromisorRemote = strings.TrimSuffix(strings.TrimPrefix(key, "remote."), ".promisor")
+		break
+	}
+	return features
+}
+
+// Repository format support.
+//
+// go-git opens repositories without checking their extensions, so a repository
+// using reftable ref storage, the SHA-256 object format, or another extension
+// go-git does not implement opens fine and then fails deep inside a strategy
+// with an unrelated-looking error. CheckRepoSupport probes the extensions once
+// per process so OpenRepository can fail early with a clear message instead.
+
+// ErrUnsupportedRepository is wrapped by errors for repositories that use
+// features Entire cannot read.
+var ErrUnsupportedRepository = errors.New("repository uses features Entire does not support")
+
+// UnsupportedRepoError lists the repository features Entire cannot read.
+type UnsupportedRepoError struct {
+	Features []string
+}
+
+func (e *UnsupportedRepoError) Error() string {
+	return fmt.Sprintf("this repository uses features Entire does not support yet: %s", strings.Join(e.Features, ", "))
+}
+
+func (e *UnsupportedRepoError) Unwrap() error {
+	return ErrUnsupportedRepository
+}
+
+// compatibleExtensions are repository extensions go-git either implements or
+// can safely ignore.
+var compatibleExtensions = map[string]bool{
+	"noop":            true,
+	"noop-v1":         true,
+	"partialclone":    true,
+	"preciousobjects": true,
+	"worktreeconfig":  true,
+}
+
+// repoSupport caches CheckRepoSupport results by repository root.
+var repoSupport sync.Map
+
+// CheckRepoSupport returns an *UnsupportedRepoError if the repository at
+// repoRoot uses features go-git cannot read. The result is cached for the life
+// of the process. Fails open: if the probe itself fails, the repository is
+// assumed to be supported.
+func CheckRepoSupport(repoRoot string) error {
+	if cached, ok := repoSupport.Load(repoRoot); ok {
+		return cached.(repoSupportResult).err //nolint:forcetypeassert // only repoSupportResult is stored
+	}
+	var result repoSupportResult
+	if features := UnsupportedRepoFeatures(context.Background(), repoRoot); len(features) > 0 {
+		result.err = &UnsupportedRepoError{Features: features}
+		logging.Debug(logging.WithComponent(context.Background(), "git"), "unsupported repository features",
+			slog.String("features", strings.Join(features, ", ")))
+	}
+	repoSupport.Store(repoRoot, result)
+	return result.err
+}
+
+type repoSupportResult struct {
+	err error
+}
+
+// UnsupportedRepoFeatures returns human-readable descriptions of the
+// repository's extensions that go-git cannot handle, sorted. Returns nil for
+// repositories without extensions or when git cannot be run.
+func UnsupportedRepoFeatures(ctx context.Context, repoRoot string) []string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--local", "--get-regexp", `^extensions\.`)
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		// git itself refuses to run in a repository with an extension it does not
+		// know; that extension is certainly beyond go-git too.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if msg := strings.TrimSpace(string(exitErr.Stderr)); strings.Contains(msg, "repository extension") {
+				return []string{strings.TrimPrefix(msg, "fatal: ")}
+			}
+		}
+		return nil
+	}
+	return parseUnsupportedExtensions(string(output))
+}
+
+// Git CLI fallbacks.
+//
+// In repositories CheckRepoSupport rejects, go-git cannot resolve HEAD or read
+// refs, but the system git can. The helpers below answer those questions with
+// the git CLI so commands that need nothing more keep working. Object IDs are
+// returned as strings because SHA-256 IDs do not fit in a plumbing.Hash.
+
+// HeadCLI returns the commit HEAD points to and the short name of the current
+// branch. branch is "" when HEAD is detached, and commit is "" on a branch
+// with no commits yet.
+func HeadCLI(ctx context.Context, repoRoot string) (commit, branch string, err error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = repoRoot
+	if output, symErr := cmd.Output(); symErr == nil {
+		branch = strings.TrimSpace(string(output))
+	}
+	commit, err = ReferenceCLI(ctx, repoRoot, "HEAD")
+	if err != nil && !(errors.Is(err, plumbing.ErrReferenceNotFound) && branch != "") {
+		return "", "", err
+	}
+	return commit, branch, nil
+}
+
+// ReferenceCLI returns the object ID ref resolves to, following symbolic refs.
+// Returns an error wrapping plumbing.ErrReferenceNotFound if ref does not exist.
+func ReferenceCLI(ctx context.Context, repoRoot, ref string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref) //nolint:gosec // ref is a ref name chosen by the caller
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", fmt.Errorf("%w: %s", plumbing.ErrReferenceNotFound, ref)
+		}
+		return "", fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SymbolicReferenceCLI returns the full name of the ref the symbolic ref ref
+// points to, e.g. "refs/remotes/origin/main" for "refs/remotes/origin/HEAD".
+// Returns "" if ref is missing or not symbolic.
+func SymbolicReferenceCLI(ctx context.Context, repoRoot, ref string) string {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", ref) //nolint:gosec // ref is a ref name chosen by the caller
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// parseUnsupportedExtensions interprets `git config --get-regexp ^extensions\.`
+// output, where keys are lowercased.
+func parseUnsupportedExtensions(output string) []string {
+	var features []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		name, ok := strings.CutPrefix(strings.ToLower(key), "extensions.")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch name {
+		case "refstorage":
+			if value != "" && value != "files" {
+				features = append(features, value+" ref storage (extensions.refStorage)")
+			}
+		case "objectformat":
+			if value != "" && value != "sha1" {
+				features = append(features, value+" object format (extensions.objectFormat)")
+			}
+		default:
+			if !compatibleExtensions[name] {
+				features = append(features, "extensions."+name)
+			}
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// gitConfigGet returns the value of a git config key, or "" if unset.
+func gitConfigGet(ctx context.Context, repoRoot, key string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--get", key) //nolint:gosec // key is a constant config key
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// gitConfigBool returns true if a git config key is set to a truthy value.
+func gitConfigBool(ctx context.Context, repoRoot, key string) bool {
+	cmd := exec.CommandContext(ctx, "git", "config", "--type=bool", "--get", key) //nolint:gosec // key is a constant config key
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// sparseConeDirs returns the directories of a cone-mode sparse checkout.
+func sparseConeDirs(ctx context.Context, repoRoot string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "sparse-checkout", "list")
+	cmd.Dir = repoRoot
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sparse-checkout cone: %w", err)
+	}
+	var dirs []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if dir := strings.Trim(strings.TrimSpace(line), "/"); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
+
+// inSparseCone reports whether a repo-relative path is inside a cone-mode
+// sparse checkout. Cone mode always includes files at the repository root and
+// files directly inside any ancestor of a cone directory.
+func inSparseCone(relPath string, coneDirs []string) bool {
+	dir := path.Dir(relPath)
+	if dir == "." {
+		return true
+	}
+	for _, cone := range coneDirs {
+		if strings.HasPrefix(relPath, cone+"/") {
+			return true
+		}
+		if strings.HasPrefix(cone, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterToSparseCone removes paths that lie outside the sparse-checkout cone of
+// the repository. A path is outside the cone if its index entry has the
+// skip-worktree bit set or, in cone mode, if it is not covered by a cone
+// directory. Returns files unchanged if the repository is not a sparse checkout.
+// Fails open: if the index or cone cannot be read, returns files unchanged.
+func FilterToSparseCone(repo *git.Repository, files []string) []string {
+	if len(files) == 0 {
+		return files
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return files
+	}
+	ctx := context.Background()
+	repoRoot := wt.Filesystem.Root()
+	features := DetectRepoFeatures(ctx, repoRoot)
+	if !features.SparseCheckout {
+		return files
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return files
+	}
+	skipped := make(map[string]bool)
+	for _, entry := range idx.Entries {
+		if entry.SkipWorktree {
+			skipped[entry.Name] = true
+		}
+	}
+
+	var coneDirs []string
+	if features.SparseCheckoutCone {
+		coneDirs, err = sparseConeDirs(ctx, repoRoot)
+		if err != nil {
+			features.SparseCheckoutCone = false
+		}
+	}
+
+	result := make([]string, 0, len(files))
+	var excluded int
+	for _, f := range files {
+		if skipped[f] || (features.SparseCheckoutCone && !paths.IsInfrastructurePath(f) && !inSparseCone(f, coneDirs)) {
+			excluded++
+			continue
+		}
+		result = append(result, f)
+	}
+
+	if excluded > 0 {
+		logging.Debug(logging.WithComponent(ctx, "checkpoint"), "excluded paths outside sparse-checkout cone",
+			slog.Int("excluded", excluded),
+			slog.Int("kept", len(result)),
+		)
+	}
+	return result
+}
+
+// treeEntryHash returns the blob hash of a file in a tree without reading the
+// blob itself. Unlike tree.File, this works in partial clones where the blob
+// has not been fetched yet.
+func treeEntryHash(tree *object.Tree, filePath string) (plumbing.Hash, bool) {
+	entry, err := tree.FindEntry(filePath)
+	if err != nil || entry.Mode == filemode.Dir || entry.Mode == filemode.Submodule {
+		return plumbing.ZeroHash, false
+	}
+	return entry.Hash, true
+}
+
+// readBlobContent returns the content of a blob. If the blob is missing from
+// the local object store (partial clone), it is fetched on demand through
+// `git cat-file`, which lazily downloads objects from the promisor remote.
+func readBlobContent(ctx context.Context, repo *git.Repository, hash plumbing.Hash) (string, error) {
+	blob, err := repo.BlobObject(hash)
+	if err == nil {
+		reader, readerErr := blob.Reader()
+		if readerErr != nil {
+			return "", fmt.Errorf("failed to open blob %s: %w", hash, readerErr)
+		}
+		content, readErr := io.ReadAll(reader)
+		_ = reader.Close() // Best effort close
+		if readErr != nil {
+			return "", fmt.Errorf("failed to read blob %s: %w", hash, readErr)
+		}
+		return string(content), nil
+	}
+	if !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return "", fmt.Errorf("failed to get blob %s: %w", hash, err)
+	}
+
+	wt, wtErr := repo.Worktree()
+	if wtErr != nil {
+		return "", fmt.Errorf("blob %s not found locally: %w", hash, err)
+	}
+	repoRoot := wt.Filesystem.Root()
+	if !DetectRepoFeatures(ctx, repoRoot).PartialClone {
+		return "", fmt.Errorf("blob %s not found locally: %w", hash, err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "blob", hash.String()) //nolint:gosec // hash is a validated plumbing.Hash
+	cmd.Dir = repoRoot
+	output, cmdErr := cmd.Output()
+	if cmdErr != nil {
+		return "", fmt.Errorf("failed to fetch missing blob %s from promisor remote: %w", hash, cmdErr)
+	}
+	logging.Debug(logging.WithComponent(ctx, "checkpoint"), "fetched missing blob on demand",
+		slog.String("hash", hash.String()),
+	)
+	return string(output), nil
+}
@@ -0,0 +1,264 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/repocache"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGitInDir runs a git command in dir and fails the test on error.
+func runGitInDir(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+// setupSparseFixture creates a repository with files in a/, b/ and the root,
+// and returns its path. The repository is not sparse.
+func setupSparseFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q")
+	for name, content := range map[string]string{
+		"a/x.txt":  "in cone\n",
+		"b/y.txt":  "outside cone\n",
+		"root.txt": "root\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	runGitInDir(t, dir, "add", ".")
+	runGitInDir(t, dir, "-c", "commit.gpgsign=false", "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func TestDetectRepoFeatures_PlainRepo(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+
+	features := DetectRepoFeatures(context.Background(), dir)
+	assert.Equal(t, RepoFeatures{}, features)
+}
+
+func TestDetectRepoFeatures_SparseCheckout(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+	runGitInDir(t, dir, "sparse-checkout", "set", "a")
+
+	features := DetectRepoFeatures(context.Background(), dir)
+	assert.True(t, features.SparseCheckout)
+	assert.True(t, features.SparseCheckoutCone)
+	assert.False(t, features.PartialClone)
+}
+
+func TestDetectRepoFeatures_Cached(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+	assert.False(t, DetectRepoFeatures(context.Background(), dir).SparseCheckout)
+
+	runGitInDir(t, dir, "sparse-checkout", "set", "a")
+	assert.False(t, DetectRepoFeatures(context.Background(), dir).SparseCheckout, "result is cached")
+
+	ClearRepoFeaturesCache()
+	assert.True(t, DetectRepoFeatures(context.Background(), dir).SparseCheckout)
+}
+
+func TestFilterToSparseCone(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+	runGitInDir(t, dir, "sparse-checkout", "set", "a")
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	files := []string{"a/x.txt", "a/sub/new.txt", "b/y.txt", "b/new.txt", "root.txt", ".entire/metadata/s/full.jsonl"}
+	got := FilterToSparseCone(repo, files)
+	assert.Equal(t, []string{"a/x.txt", "a/sub/new.txt", "root.txt", ".entire/metadata/s/full.jsonl"}, got)
+}
+
+func TestFilterToSparseCone_NotSparse(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	files := []string{"a/x.txt", "b/y.txt"}
+	assert.Equal(t, files, FilterToSparseCone(repo, files))
+}
+
+func TestInSparseCone(t *testing.T) {
+	t.Parallel()
+	coneDirs := []string{"services/api", "docs"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"README.md", true},
+		{"services/api/main.go", true},
+		{"services/api/internal/x.go", true},
+		{"services/go.mod", true}, // file directly in an ancestor of a cone dir
+		{"services/web/index.ts", false},
+		{"docs/guide.md", true},
+		{"tools/gen.go", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, inSparseCone(tt.path, coneDirs), tt.path)
+	}
+}
+
+func TestReadBlobContent_PartialCloneFetchesOnDemand(t *testing.T) {
+	t.Parallel()
+	src := setupSparseFixture(t)
+	runGitInDir(t, src, "config", "uploadpack.allowFilter", "true")
+	runGitInDir(t, src, "config", "uploadpack.allowAnySHA1InWant", "true")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGitInDir(t, filepath.Dir(clone), "clone", "-q", "--filter=blob:none", "--sparse", "file://"+src, clone)
+	runGitInDir(t, clone, "sparse-checkout", "set", "a")
+
+	features := DetectRepoFeatures(context.Background(), clone)
+	assert.True(t, features.PartialClone)
+	assert.Equal(t, "origin", features.PromisorRemote)
+
+	repo, err := git.PlainOpen(clone)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	tree, err := commit.Tree()
+	require.NoError(t, err)
+
+	// The tree entry is known even though the blob was filtered out
+	hash, ok := treeEntryHash(tree, "b/y.txt")
+	require.True(t, ok)
+	_, err = repo.BlobObject(hash)
+	require.ErrorIs(t, err, plumbing.ErrObjectNotFound, "fixture should be missing the blob")
+
+	content, err := readBlobContent(context.Background(), repo, hash)
+	require.NoError(t, err)
+	assert.Equal(t, "outside cone\n", content)
+}
+
+func TestReadBlobContent_MissingBlobWithoutPromisor(t *testing.T) {
+	t.Parallel()
+	dir := setupSparseFixture(t)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	_, err = readBlobContent(context.Background(), repo, plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"))
+	require.Error(t, err)
+}
+
+func TestParseUnsupportedExtensions(t *testing.T) {
+	t.Parallel()
+
+	output := "extensions.worktreeconfig true\n" +
+		"extensions.refstorage reftable\n" +
+		"extensions.objectformat sha256\n" +
+		"extensions.partialclone origin\n" +
+		"extensions.somethingnew true\n"
+	assert.Equal(t, []string{
+		"extensions.somethingnew",
+		"reftable ref storage (extensions.refStorage)",
+		"sha256 object format (extensions.objectFormat)",
+	}, parseUnsupportedExtensions(output))
+
+	assert.Empty(t, parseUnsupportedExtensions("extensions.objectformat sha1\nextensions.refstorage files\n"))
+	assert.Empty(t, parseUnsupportedExtensions(""))
+}
+
+func TestUnsupportedRepoFeatures_PlainRepo(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q")
+
+	assert.Empty(t, UnsupportedRepoFeatures(context.Background(), dir))
+	assert.NoError(t, CheckRepoSupport(dir))
+}
+
+func TestCheckRepoSupport_SHA256(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q", "--object-format=sha256")
+
+	err := CheckRepoSupport(dir)
+	require.ErrorIs(t, err, ErrUnsupportedRepository)
+	assert.Contains(t, err.Error(), "sha256 object format")
+}
+
+func TestHeadCLI_SHA256(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q", "--object-format=sha256", "-b", "main")
+
+	commit, branch, err := HeadCLI(ctx, dir)
+	require.NoError(t, err, "an unborn branch is not an error")
+	assert.Empty(t, commit)
+	assert.Equal(t, "main", branch)
+
+	runGitInDir(t, dir, "commit", "-q", "--allow-empty", "-m", "Initial")
+	commit, branch, err = HeadCLI(ctx, dir)
+	require.NoError(t, err)
+	assert.Len(t, commit, 64)
+	assert.Equal(t, "main", branch)
+
+	ref, err := ReferenceCLI(ctx, dir, "refs/heads/main")
+	require.NoError(t, err)
+	assert.Equal(t, commit, ref)
+	_, err = ReferenceCLI(ctx, dir, "refs/heads/missing")
+	require.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+	runGitInDir(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	assert.Equal(t, "refs/remotes/origin/main", SymbolicReferenceCLI(ctx, dir, "refs/remotes/origin/HEAD"))
+	assert.Empty(t, SymbolicReferenceCLI(ctx, dir, "refs/heads/main"))
+
+	runGitInDir(t, dir, "checkout", "-q", "--detach")
+	_, branch, err = HeadCLI(ctx, dir)
+	require.NoError(t, err)
+	assert.Empty(t, branch)
+}
+
+// Cannot use t.Parallel() because repocache.Clear resets caches other tests rely on
+func TestCheckRepoSupport_ClearedWithRepoCaches(t *testing.T) {
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q")
+	require.NoError(t, CheckRepoSupport(dir))
+
+	runGitInDir(t, dir, "config", "core.repositoryformatversion", "1")
+	runGitInDir(t, dir, "config", "extensions.unknownfeature", "true")
+	require.NoError(t, CheckRepoSupport(dir), "result is cached")
+
+	repocache.Clear()
+	require.ErrorIs(t, CheckRepoSupport(dir), ErrUnsupportedRepository)
+}
+
+// Cannot use t.Parallel() because we use t.Chdir()
+func TestOpenRepository_UnsupportedRepository(t *testing.T) {
+	dir := t.TempDir()
+	runGitInDir(t, dir, "init", "-q", "--object-format=sha256")
+	t.Chdir(dir)
+
+	_, err := OpenRepository()
+	require.ErrorIs(t, err, ErrUnsupportedRepository)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Register agent for ResolveAgentForRewind tests
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"  // Register agent for ResolveAgentForRewind tests
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
@@ -261,3 +262,69 @@ func TestResolveAgentForRewind(t *testing.T) {
 		}
 	})
 }
+
+// TestRewind_SmudgesLFSFiles verifies that rewinding writes the content of
+// LFS-tracked files, not the pointers their checkpoint stores, unless
+// GIT_LFS_SKIP_SMUDGE is set.
+func TestRewind_SmudgesLFSFiles(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("*.bin filter=lfs diff=lfs merge=lfs -text\n"), 0o644); err != nil {
+		t.Fatalf("failed to write .gitattributes: %v", err)
+	}
+	runGitInDir(t, dir, "add", ".gitattributes")
+	runGitInDir(t, dir, "commit", "-q", "-m", "Track bin files with LFS")
+
+	s := &ManualCommitStrategy{}
+	sessionID := "2026-05-04-lfs"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	if err := os.MkdirAll(metadataDirAbs, 0o755); err != nil {
+		t.Fatalf("failed to create metadata dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(metadataDirAbs, "full.jsonl"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	modelPath := filepath.Join(dir, "model.bin")
+	if err := os.WriteFile(modelPath, []byte("weights v1\x00"), 0o644); err != nil {
+		t.Fatalf("failed to write model.bin: %v", err)
+	}
+	if err := s.SaveStep(StepContext{
+		SessionID:      sessionID,
+		NewFiles:       []string{"model.bin"},
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: metadataDirAbs,
+		CommitMessage:  "Checkpoint 1",
+		AuthorName:     "Test",
+		AuthorEmail:    "test@test.com",
+	}); err != nil {
+		t.Fatalf("SaveStep() error = %v", err)
+	}
+	points, err := s.GetRewindPoints(10)
+	if err != nil || len(points) == 0 {
+		t.Fatalf("GetRewindPoints() = %v, %v", points, err)
+	}
+
+	for _, skipSmudge := range []bool{false, true} {
+		if skipSmudge {
+			t.Setenv("GIT_LFS_SKIP_SMUDGE", "1")
+		}
+		if err := os.WriteFile(modelPath, []byte("weights v2\x00"), 0o644); err != nil {
+			t.Fatalf("failed to modify model.bin: %v", err)
+		}
+		if err := s.Rewind(points[0]); err != nil {
+			t.Fatalf("Rewind() error = %v", err)
+		}
+		data, err := os.ReadFile(modelPath)
+		if err != nil {
+			t.Fatalf("failed to read model.bin: %v", err)
+		}
+		_, isPointer := checkpoint.ParseLFSPointer(data)
+		switch {
+		case !skipSmudge && string(data) != "weights v1\x00":
+			t.Errorf("model.bin after rewind = %q, want the checkpointed content", data)
+		case skipSmudge && !isPointer:
+			t.Errorf("model.bin after rewind with GIT_LFS_SKIP_SMUDGE = %q, want the LFS pointer", data)
+		}
+	}
+}
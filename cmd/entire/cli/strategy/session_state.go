@@ -4,27 +4,27 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
 	"path/filepath"
+	"time"
 
-	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
-	"github.com/entireio/cli/cmd/entire/cli/validation"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
 )
 
 // Session state management functions shared across all strategies.
 // SessionState is stored in .git/entire-sessions/{session_id}.json
 
-// getSessionStateDir returns the path to the session state directory.
+// getSessionStateDir returns the path to the current user's session state directory.
 // This is stored in the git common dir so it's shared across all worktrees.
 func getSessionStateDir() (string, error) {
 	commonDir, err := GetGitCommonDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(commonDir, session.SessionStateDirName), nil
+	return session.StateDir(commonDir), nil
 }
 
 // sessionStateFile returns the path to a session state file.
@@ -54,37 +54,28 @@ func LoadSessionState(sessionID string) (*SessionState, error) {
 
 // SaveSessionState saves the session state atomically.
 func SaveSessionState(state *SessionState) error {
-	// Validate session ID to prevent path traversal
-	if err := validation.ValidateSessionID(state.SessionID); err != nil {
-		return fmt.Errorf("invalid session ID: %w", err)
-	}
-
-	stateDir, err := getSessionStateDir()
+	store, err := session.NewStateStore()
 	if err != nil {
-		return fmt.Errorf("failed to get session state directory: %w", err)
+		return fmt.Errorf("failed to create state store: %w", err)
 	}
-
-	if err := os.MkdirAll(stateDir, 0o750); err != nil {
-		return fmt.Errorf("failed to create session state directory: %w", err)
-	}
-
-	data, err := jsonutil.MarshalIndentWithNewline(state, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal session state: %w", err)
+	if err := store.Save(context.Background(), state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
 	}
+	return nil
+}
 
-	stateFile, err := sessionStateFile(state.SessionID)
+// UpdateSessionState loads the session's state, applies fn, and saves it,
+// holding the session's lock throughout so that a hook running at the same
+// time, such as post-commit, cannot lose the change or have its own lost.
+// fn is not called when the session has no state; an error from fn skips
+// the save and is returned.
+func UpdateSessionState(sessionID string, fn func(*SessionState) error) error {
+	store, err := session.NewStateStore()
 	if err != nil {
-		return fmt.Errorf("failed to get session state file path: %w", err)
+		return fmt.Errorf("failed to create state store: %w", err)
 	}
-
-	// Atomic write: write to temp file, then rename
-	tmpFile := stateFile + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write session state: %w", err)
-	}
-	if err := os.Rename(tmpFile, stateFile); err != nil {
-		return fmt.Errorf("failed to rename session state file: %w", err)
+	if err := store.Update(context.Background(), sessionID, fn); err != nil {
+		return fmt.Errorf("failed to update session state: %w", err)
 	}
 	return nil
 }
@@ -97,6 +88,12 @@ func ListSessionStates() ([]*SessionState, error) {
 		return nil, fmt.Errorf("failed to create state store: %w", err)
 	}
 
+	// Re-bind sessions from a moved worktree so callers see current paths.
+	if _, err := rebindMovedSessions(context.Background(), store); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "session"), "failed to re-bind moved sessions",
+			slog.String("error", err.Error()))
+	}
+
 	states, err := store.List(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("failed to list session states: %w", err)
@@ -104,6 +101,53 @@ func ListSessionStates() ([]*SessionState, error) {
 	return states, nil
 }
 
+// UnreadableSessionStates returns the current user's session state files
+// that fail to load, keyed by session ID. ListSessionStates skips them.
+func UnreadableSessionStates() (map[string]error, error) {
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create state store: %w", err)
+	}
+	unreadable, err := store.Unreadable(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session states: %w", err)
+	}
+	return unreadable, nil
+}
+
+// otherUsersShadowBranches returns the shadow branches used by other users'
+// sessions that haven't ended. With state.per_user enabled, a user only sees
+// their own sessions, so this keeps post-commit and clean from deleting a
+// branch someone else is still checkpointing to. Returns nil when state is
+// shared. Best effort: unreadable stores are skipped.
+func otherUsersShadowBranches() map[string]bool {
+	if settings.StateUserNamespace() == "" {
+		return nil
+	}
+	current, err := session.NewStateStore()
+	if err != nil {
+		return nil
+	}
+	stores, err := session.NewAllUsersStateStores()
+	if err != nil {
+		return nil
+	}
+	inUse := make(map[string]bool)
+	for _, store := range stores {
+		if store.Dir() == current.Dir() {
+			continue
+		}
+		states, err := store.ListMatching(context.Background(), session.Filter{ExcludeEnded: true})
+		if err != nil {
+			continue
+		}
+		for _, state := range states {
+			inUse[checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)] = true
+		}
+	}
+	return inUse
+}
+
 // FindMostRecentSession returns the session ID of the most recently interacted session
 // (by LastInteractionTime) in the current worktree. Returns empty string if no sessions exist.
 // Scoping to the current worktree prevents cross-worktree pollution in log routing.
@@ -154,6 +198,87 @@ func FindMostRecentSession() string {
 	return ""
 }
 
+// ExpireIdleSessions ends the current user's ACTIVE sessions that have seen
+// no interaction for longer than state.session_timeout_minutes, as happens
+// when an agent is killed mid-turn without firing its stop hooks. Returns the
+// IDs of the sessions it ended. Does nothing if no timeout is configured.
+func ExpireIdleSessions(now time.Time) []string {
+	s, err := settings.Load()
+	if err != nil {
+		return nil
+	}
+	return expireIdleSessions(s.SessionTimeout(), now)
+}
+
+func expireIdleSessions(timeout time.Duration, now time.Time) []string {
+	if timeout <= 0 {
+		return nil
+	}
+	store, err := session.NewStateStore()
+	if err != nil {
+		return nil
+	}
+	active, err := store.ListMatching(context.Background(), idleSessionFilter)
+	if err != nil {
+		return nil
+	}
+	return expireIdleStates(active, timeout, now, SaveSessionState)
+}
+
+// idleSessionFilter matches the sessions expireIdleStates may end.
+var idleSessionFilter = session.Filter{Phases: []session.Phase{session.PhaseActive}}
+
+// expireIdleSessionsInTx is ExpireIdleSessions for a hook that holds a state
+// transaction: only the transaction's sessions are considered, and ended
+// sessions are saved with the rest of the transaction.
+func expireIdleSessionsInTx(tx *session.StateTx, now time.Time) []string {
+	s, err := settings.Load()
+	if err != nil || s.SessionTimeout() <= 0 {
+		return nil
+	}
+	return expireIdleStates(tx.List(idleSessionFilter), s.SessionTimeout(), now, func(state *SessionState) error {
+		tx.Save(state)
+		return nil
+	})
+}
+
+// expireIdleStates ends the ACTIVE states idle for longer than timeout,
+// saving each with save, and returns the IDs of the sessions it ended.
+func expireIdleStates(active []*SessionState, timeout time.Duration, now time.Time, save func(*SessionState) error) []string {
+	logCtx := logging.WithComponent(context.Background(), "session")
+	var ended []string
+	for _, state := range active {
+		lastInteraction := state.StartedAt
+		if state.LastInteractionTime != nil {
+			lastInteraction = *state.LastInteractionTime
+		}
+		if now.Sub(lastInteraction) <= timeout {
+			continue
+		}
+
+		if err := TransitionAndLog(state, session.EventIdleTimeout, session.TransitionContext{}, session.NoOpActionHandler{}); err != nil {
+			continue
+		}
+		endedAt := now
+		state.EndedAt = &endedAt
+		if err := save(state); err != nil {
+			logging.Warn(logCtx, "failed to save session ended after idle timeout",
+				slog.String("session_id", state.SessionID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		logging.Info(logCtx, "ended idle session",
+			slog.String("session_id", state.SessionID),
+			slog.String("reason", "no interaction for longer than state.session_timeout_minutes; the agent likely exited without firing its stop hooks"),
+			slog.Time("last_interaction", lastInteraction),
+			slog.Duration("timeout", timeout),
+		)
+		ended = append(ended, state.SessionID)
+	}
+	return ended
+}
+
 // TransitionAndLog runs a session phase transition, applies actions via the
 // handler, and logs the transition. Returns the first handler error from
 // ApplyTransition (if any) so callers can surface it. The error is also
@@ -165,7 +290,7 @@ func TransitionAndLog(state *SessionState, event session.Event, ctx session.Tran
 	result := session.Transition(oldPhase, event, ctx)
 	logCtx := logging.WithComponent(context.Background(), "session")
 
-	handlerErr := session.ApplyTransition(state, result, handler)
+	handlerErr := session.ApplyTransition(state, result, handler, ctx.Clock)
 	if handlerErr != nil {
 		logging.Error(logCtx, "action handler error during transition",
 			slog.String("session_id", state.SessionID),
@@ -198,21 +323,12 @@ func TransitionAndLog(state *SessionState, event session.Event, ctx session.Tran
 
 // ClearSessionState removes the session state file for the given session ID.
 func ClearSessionState(sessionID string) error {
-	// Validate session ID to prevent path traversal
-	if err := validation.ValidateSessionID(sessionID); err != nil {
-		return fmt.Errorf("invalid session ID: %w", err)
-	}
-
-	stateFile, err := sessionStateFile(sessionID)
+	store, err := session.NewStateStore()
 	if err != nil {
-		return fmt.Errorf("failed to get session state file path: %w", err)
+		return fmt.Errorf("failed to create state store: %w", err)
 	}
-
-	if err := os.Remove(stateFile); err != nil {
-		if os.IsNotExist(err) {
-			return nil // Already gone, not an error
-		}
-		return fmt.Errorf("failed to remove session state file: %w", err)
+	if err := store.Clear(context.Background(), sessionID); err != nil {
+		return fmt.Errorf("failed to clear session state: %w", err)
 	}
 	return nil
 }
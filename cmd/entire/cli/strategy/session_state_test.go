@@ -466,3 +466,57 @@ func TestLoadSessionState_DeletesStaleSession(t *testing.T) {
 		t.Error("stale session file should be deleted after LoadSessionState()")
 	}
 }
+
+func TestExpireIdleSessions(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	t.Chdir(dir)
+
+	now := time.Now()
+	longAgo := now.Add(-2 * time.Hour)
+	recently := now.Add(-time.Minute)
+	for _, state := range []*SessionState{
+		{SessionID: "active-idle", Phase: session.PhaseActive, StartedAt: longAgo, LastInteractionTime: &longAgo},
+		{SessionID: "active-recent", Phase: session.PhaseActive, StartedAt: longAgo, LastInteractionTime: &recently},
+		{SessionID: "idle-old", Phase: session.PhaseIdle, StartedAt: longAgo, LastInteractionTime: &longAgo},
+	} {
+		if err := SaveSessionState(state); err != nil {
+			t.Fatalf("SaveSessionState() error = %v", err)
+		}
+	}
+
+	if ended := expireIdleSessions(0, now); len(ended) != 0 {
+		t.Errorf("expireIdleSessions(0) = %v, want no sessions ended", ended)
+	}
+
+	ended := expireIdleSessions(time.Hour, now)
+	if len(ended) != 1 || ended[0] != "active-idle" {
+		t.Fatalf("expireIdleSessions() = %v, want [active-idle]", ended)
+	}
+
+	state, err := LoadSessionState("active-idle")
+	if err != nil {
+		t.Fatalf("LoadSessionState() error = %v", err)
+	}
+	if state.Phase != session.PhaseEnded {
+		t.Errorf("Phase = %s, want %s", state.Phase, session.PhaseEnded)
+	}
+	if state.EndedAt == nil || !state.EndedAt.Equal(now) {
+		t.Errorf("EndedAt = %v, want %v", state.EndedAt, now)
+	}
+	if state.LastInteractionTime == nil || !state.LastInteractionTime.Equal(longAgo) {
+		t.Errorf("LastInteractionTime = %v, want unchanged %v", state.LastInteractionTime, longAgo)
+	}
+
+	for id, want := range map[string]session.Phase{"active-recent": session.PhaseActive, "idle-old": session.PhaseIdle} {
+		state, err := LoadSessionState(id)
+		if err != nil {
+			t.Fatalf("LoadSessionState(%s) error = %v", id, err)
+		}
+		if state.Phase != want {
+			t.Errorf("%s Phase = %s, want %s", id, state.Phase, want)
+		}
+	}
+}
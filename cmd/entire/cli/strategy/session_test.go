@@ -476,7 +476,7 @@ func createTestMultiSessionCheckpoint(t *testing.T, repo *git.Repository, checkp
 	}
 
 	// Create branch reference
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref := plumbing.NewHashReference(refName, commitHash)
 	if err := repo.Storer.SetReference(ref); err != nil {
 		t.Fatalf("failed to create branch: %v", err)
@@ -592,7 +592,7 @@ func createTestMetadataBranchWithPrompt(t *testing.T, repo *git.Repository, sess
 	}
 
 	// Create branch reference
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName())
 	ref := plumbing.NewHashReference(refName, commitHash)
 	if err := repo.Storer.SetReference(ref); err != nil {
 		t.Fatalf("failed to create branch: %v", err)
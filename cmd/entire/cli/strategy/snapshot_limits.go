@@ -0,0 +1,61 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+// loadSnapshotLimits returns the snapshot.max_file_bytes and
+// snapshot.skip_binary settings. Settings that can't be loaded mean no limits.
+func loadSnapshotLimits() (maxFileBytes int64, skipBinary bool) {
+	s, err := settings.Load()
+	if err != nil {
+		return 0, false
+	}
+	return s.SnapshotLimits()
+}
+
+// warnOmittedFiles tells the user which files a checkpoint recorded by hash
+// only. Their content cannot be restored by rewinding to the checkpoint.
+func warnOmittedFiles(omitted []checkpoint.OmittedFile) {
+	for _, f := range omitted {
+		reason := "binary"
+		if f.Reason == checkpoint.OmittedTooLarge {
+			reason = "over snapshot.max_file_bytes"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s not stored in checkpoint (%s, %d bytes); recorded by hash only\n", f.Path, reason, f.Size)
+	}
+	if len(omitted) > 0 {
+		logging.Warn(logging.WithComponent(context.Background(), "checkpoint"), "checkpoint left out file content",
+			slog.Int("files", len(omitted)),
+		)
+	}
+}
+
+// mergeOmittedFiles adds the files in added to existing, replacing earlier
+// records for the same path, sorted by path.
+func mergeOmittedFiles(existing, added []checkpoint.OmittedFile) []checkpoint.OmittedFile {
+	if len(added) == 0 {
+		return existing
+	}
+	byPath := make(map[string]checkpoint.OmittedFile, len(existing)+len(added))
+	for _, f := range existing {
+		byPath[f.Path] = f
+	}
+	for _, f := range added {
+		byPath[f.Path] = f
+	}
+	merged := make([]checkpoint.OmittedFile, 0, len(byPath))
+	for _, f := range byPath {
+		merged = append(merged, f)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Path < merged[j].Path })
+	return merged
+}
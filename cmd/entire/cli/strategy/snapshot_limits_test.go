@@ -0,0 +1,78 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeOmittedFiles(t *testing.T) {
+	t.Parallel()
+
+	existing := []checkpoint.OmittedFile{{Path: "b.bin", Hash: "old"}, {Path: "c.bin"}}
+	merged := mergeOmittedFiles(existing, []checkpoint.OmittedFile{{Path: "b.bin", Hash: "new"}, {Path: "a.bin"}})
+	assert.Equal(t, []checkpoint.OmittedFile{{Path: "a.bin"}, {Path: "b.bin", Hash: "new"}, {Path: "c.bin"}}, merged)
+	assert.Equal(t, existing, mergeOmittedFiles(existing, nil))
+}
+
+// TestSaveStep_SkipBinary verifies that a binary file is recorded in the
+// session instead of stored, and that rewinding leaves it as it is on disk.
+func TestSaveStep_SkipBinary(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".entire"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".entire", "settings.json"),
+		[]byte(`{"enabled": true, "snapshot": {"skip_binary": true}}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644))
+
+	s := &ManualCommitStrategy{}
+	sessionID := "2026-05-03-skip-binary"
+	metadataDir := ".entire/metadata/" + sessionID
+	metadataDirAbs := filepath.Join(dir, metadataDir)
+	require.NoError(t, os.MkdirAll(metadataDirAbs, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(metadataDirAbs, "full.jsonl"), []byte("{}\n"), 0o644))
+
+	// The agent creates the binary file after the session started, so it is
+	// not one of the untracked files rewind always keeps
+	for i, files := range [][]string{{"main.go"}, {"shot.png"}} {
+		if i == 1 {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "shot.png"), []byte("PNG\x00first"), 0o644))
+		}
+		require.NoError(t, s.SaveStep(StepContext{
+			SessionID:      sessionID,
+			NewFiles:       files,
+			MetadataDir:    metadataDir,
+			MetadataDirAbs: metadataDirAbs,
+			CommitMessage:  "Checkpoint",
+			AuthorName:     "Test",
+			AuthorEmail:    "test@test.com",
+		}))
+	}
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.Len(t, state.OmittedFiles, 1)
+	assert.Equal(t, "shot.png", state.OmittedFiles[0].Path)
+	assert.Equal(t, checkpoint.OmittedBinary, state.OmittedFiles[0].Reason)
+
+	points, err := s.GetRewindPoints(10)
+	require.NoError(t, err)
+	require.NotEmpty(t, points)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main // edited\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shot.png"), []byte("PNG\x00second"), 0o644))
+	require.NoError(t, s.Rewind(points[0]))
+
+	mainGo, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(mainGo))
+	shot, err := os.ReadFile(filepath.Join(dir, "shot.png"))
+	require.NoError(t, err, "rewind must not delete a file it has no content for")
+	assert.Equal(t, "PNG\x00second", string(shot))
+}
@@ -387,6 +387,11 @@ type Strategy interface {
 	// The remote parameter is the name of the remote being pushed to.
 	// Should return nil on errors to not block pushes (log warnings to stderr).
 	PrePush(remote string) error
+	// PostRewrite is called by the git post-rewrite hook after commits were
+	// rewritten by a rebase (rewriteType "rebase") or amend ("amend"), with
+	// the rewritten commits git reports.
+	// Should return nil on errors to not block subsequent operations (log warnings to stderr).
+	PostRewrite(rewriteType string, rewritten []RewrittenCommit) error
 	// HandleTurnEnd performs strategy-specific cleanup at the end of a turn.
 	// Work items are read from state (e.g. TurnCheckpointIDs), not from the
 	// action list. The state has already been updated by ApplyTransition;
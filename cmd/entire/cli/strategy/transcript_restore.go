@@ -0,0 +1,107 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// RestoreSessionTranscript returns the path of the session's transcript copy in
+// its metadata directory (.entire/metadata/<session>/full.jsonl). If the copy
+// is missing, for example because the user deleted .entire, it is restored
+// from the session's newest checkpoint on entire/checkpoints/v1.
+//
+// Returns checkpoint.ErrNoTranscript if there is no local copy and no
+// checkpoint of the session has a transcript.
+func RestoreSessionTranscript(ctx context.Context, sessionID string) (string, error) {
+	state, err := LoadSessionState(sessionID)
+	if err != nil {
+		return "", err
+	}
+	if state == nil {
+		state = &SessionState{SessionID: sessionID}
+	}
+	return restoreSessionTranscript(ctx, state)
+}
+
+// restoreSessionTranscript is RestoreSessionTranscript for a loaded state.
+func restoreSessionTranscript(ctx context.Context, state *SessionState) (string, error) {
+	transcriptPath, err := paths.AbsPath(filepath.Join(paths.SessionMetadataDirFromSessionID(state.SessionID), paths.TranscriptFileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve metadata directory: %w", err)
+	}
+	if _, statErr := os.Stat(transcriptPath); statErr == nil {
+		return transcriptPath, nil
+	}
+
+	// Newest first: this turn's checkpoints, then the last condensed one.
+	candidates := slices.Clone(state.TurnCheckpointIDs)
+	slices.Reverse(candidates)
+	if !state.LastCheckpointID.IsEmpty() {
+		candidates = append(candidates, state.LastCheckpointID.String())
+	}
+	if len(candidates) == 0 {
+		return "", checkpoint.ErrNoTranscript
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+	store := checkpoint.NewGitStore(repo)
+
+	for _, cpIDStr := range candidates {
+		cpID, parseErr := id.NewCheckpointID(cpIDStr)
+		if parseErr != nil {
+			continue
+		}
+		content, readErr := store.ReadSessionContentByID(ctx, cpID, state.SessionID)
+		if readErr != nil || len(content.Transcript) == 0 {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(transcriptPath), 0o750); err != nil {
+			return "", fmt.Errorf("failed to create metadata directory: %w", err)
+		}
+		if err := os.WriteFile(transcriptPath, content.Transcript, 0o600); err != nil {
+			return "", fmt.Errorf("failed to restore transcript: %w", err)
+		}
+		logging.Info(logging.WithComponent(ctx, "checkpoint"), "restored missing session transcript from metadata branch",
+			slog.String("session_id", state.SessionID),
+			slog.String("checkpoint_id", cpIDStr),
+			slog.String("path", transcriptPath),
+		)
+		return transcriptPath, nil
+	}
+	return "", checkpoint.ErrNoTranscript
+}
+
+// readTranscriptWithRestore reads the session's live transcript, falling back
+// to its metadata directory copy (restored from entire/checkpoints/v1 if
+// needed) when the live transcript cannot be read.
+func readTranscriptWithRestore(ctx context.Context, state *SessionState) ([]byte, error) {
+	data, liveErr := readLiveTranscript(state.SessionID, state.TranscriptPath)
+	if liveErr == nil && len(data) > 0 {
+		return data, nil
+	}
+	transcriptPath, err := restoreSessionTranscript(ctx, state)
+	if err != nil {
+		if liveErr != nil {
+			return nil, errors.Join(liveErr, err)
+		}
+		return data, nil
+	}
+	data, err = os.ReadFile(transcriptPath) //nolint:gosec // path is inside the repository's metadata directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read restored transcript: %w", err)
+	}
+	return data, nil
+}
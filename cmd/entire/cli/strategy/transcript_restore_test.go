@@ -0,0 +1,63 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreSessionTranscript_AfterEntireDirDeleted(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	s := &ManualCommitStrategy{}
+	sessionID := "test-restore-transcript"
+	setupSessionWithCheckpoint(t, s, repo, dir, sessionID)
+	commitWithCheckpointTrailer(t, repo, dir, "a1b2c3d4e5f6")
+	require.NoError(t, s.PostCommit())
+
+	state, err := s.loadSessionState(sessionID)
+	require.NoError(t, err)
+	require.False(t, state.LastCheckpointID.IsEmpty(), "PostCommit should condense the session")
+	content, err := checkpoint.NewGitStore(repo).ReadSessionContentByID(context.Background(), state.LastCheckpointID, sessionID)
+	require.NoError(t, err)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, paths.EntireDir)))
+
+	restored, err := RestoreSessionTranscript(context.Background(), sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, paths.SessionMetadataDirFromSessionID(sessionID), paths.TranscriptFileName), restored)
+	data, err := os.ReadFile(restored)
+	require.NoError(t, err)
+	assert.Equal(t, content.Transcript, data)
+
+	// An existing local copy is returned as-is.
+	require.NoError(t, os.WriteFile(restored, []byte("local\n"), 0o600))
+	again, err := RestoreSessionTranscript(context.Background(), sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, restored, again)
+	data, err = os.ReadFile(again)
+	require.NoError(t, err)
+	assert.Equal(t, "local\n", string(data))
+}
+
+func TestRestoreSessionTranscript_NoCheckpoint(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	_, err := RestoreSessionTranscript(context.Background(), "test-restore-no-checkpoint")
+	require.ErrorIs(t, err, checkpoint.ErrNoTranscript)
+}
@@ -0,0 +1,233 @@
+package strategy
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// subtreeDiff is a pair of subtrees to compare. A zero hash is an absent
+// (empty) tree, so one-sided subtrees list every file under the other.
+type subtreeDiff struct {
+	path     string
+	from, to plumbing.Hash
+}
+
+// changedFilesBetweenTrees returns the paths of files added, modified,
+// deleted, or whose mode changed between from and to. A nil from is an empty
+// tree, as for a root commit. Both old and new paths of a rename are returned.
+//
+// Unlike object.Tree.Diff, no blobs are read and renames are not detected:
+// subtrees with equal hashes are skipped without being read, so the cost
+// follows the size of the change rather than of the repository. The
+// top-level subtrees that differ are compared concurrently, each worker with
+// its own object storage because go-git's filesystem storage is not safe for
+// concurrent use.
+func changedFilesBetweenTrees(repo *git.Repository, from, to *object.Tree) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+	emit := func(path string) { result[path] = struct{}{} }
+
+	var fromEntries []object.TreeEntry
+	if from != nil {
+		fromEntries = from.Entries
+	}
+	var subtrees []subtreeDiff
+	compareEntries("", fromEntries, to.Entries, emit, func(d subtreeDiff) { subtrees = append(subtrees, d) })
+
+	sequential := func() (map[string]struct{}, error) {
+		for _, d := range subtrees {
+			if err := diffSubtrees(repo.Storer, d, emit); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	newStorer := workerStorerFactory(repo)
+	workers := min(runtime.GOMAXPROCS(0), len(subtrees))
+	if workers <= 1 || newStorer == nil {
+		return sequential()
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	jobs := make(chan subtreeDiff)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st := newStorer()
+			var paths []string
+			collect := func(path string) { paths = append(paths, path) }
+			for d := range jobs {
+				if err := diffSubtrees(st, d, collect); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+			mu.Lock()
+			for _, path := range paths {
+				emit(path)
+			}
+			mu.Unlock()
+		}()
+	}
+	for _, d := range subtrees {
+		jobs <- d
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		// A worker storage may not see every object the repository's does
+		// (e.g. alternates), so retry through the repository's own storage.
+		return sequential()
+	}
+	return result, nil
+}
+
+// workerStorerFactory returns a function opening an independent object
+// storage on the repository's git directory, or nil if the repository is not
+// stored on disk.
+func workerStorerFactory(repo *git.Repository) func() storer.EncodedObjectStorer {
+	fsStorage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil
+	}
+	return func() storer.EncodedObjectStorer {
+		return filesystem.NewStorage(fsStorage.Filesystem(), cache.NewObjectLRUDefault())
+	}
+}
+
+// diffSubtrees compares the subtrees in d, recursing only into subtrees
+// whose hashes differ.
+func diffSubtrees(st storer.EncodedObjectStorer, d subtreeDiff, emit func(string)) error {
+	var fromEntries, toEntries []object.TreeEntry
+	if !d.from.IsZero() {
+		tree, err := object.GetTree(st, d.from)
+		if err != nil {
+			return fmt.Errorf("failed to read tree %s: %w", d.path, err)
+		}
+		fromEntries = tree.Entries
+	}
+	if !d.to.IsZero() {
+		tree, err := object.GetTree(st, d.to)
+		if err != nil {
+			return fmt.Errorf("failed to read tree %s: %w", d.path, err)
+		}
+		toEntries = tree.Entries
+	}
+
+	var subtrees []subtreeDiff
+	compareEntries(d.path+"/", fromEntries, toEntries, emit, func(sub subtreeDiff) { subtrees = append(subtrees, sub) })
+	for _, sub := range subtrees {
+		if err := diffSubtrees(st, sub, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareEntries emits the files that differ between two entry lists of the
+// same directory and reports the subtrees that still need comparing.
+func compareEntries(prefix string, from, to []object.TreeEntry, emit func(string), descend func(subtreeDiff)) {
+	fromByName := make(map[string]object.TreeEntry, len(from))
+	for _, e := range from {
+		fromByName[e.Name] = e
+	}
+
+	// side splits an entry into its subtree hash and whether it is a file
+	// (including submodules, which are compared by commit hash).
+	side := func(e object.TreeEntry, ok bool) (plumbing.Hash, bool) {
+		if !ok {
+			return plumbing.ZeroHash, false
+		}
+		if e.Mode == filemode.Dir {
+			return e.Hash, false
+		}
+		return plumbing.ZeroHash, true
+	}
+
+	visit := func(name string, f object.TreeEntry, inFrom bool, t object.TreeEntry, inTo bool) {
+		if inFrom && inTo && f.Hash == t.Hash && f.Mode == t.Mode {
+			return
+		}
+		path := prefix + name
+		fromTree, fromFile := side(f, inFrom)
+		toTree, toFile := side(t, inTo)
+		if fromFile || toFile {
+			emit(path)
+		}
+		if !fromTree.IsZero() || !toTree.IsZero() {
+			descend(subtreeDiff{path: path, from: fromTree, to: toTree})
+		}
+	}
+
+	for _, t := range to {
+		f, inFrom := fromByName[t.Name]
+		delete(fromByName, t.Name)
+		visit(t.Name, f, inFrom, t, true)
+	}
+	for _, f := range from {
+		if _, left := fromByName[f.Name]; left {
+			visit(f.Name, f, true, object.TreeEntry{}, false)
+		}
+	}
+}
+
+// filesChangedAtPaths returns the files among paths that commit changed
+// relative to its first parent, in the order given. Only those paths are
+// looked up, so the cost follows the number of paths rather than the size of
+// the commit. On error the commit is treated as changing none of them.
+func filesChangedAtPaths(commit *object.Commit, paths []string) []string {
+	commitTree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil
+		}
+	}
+
+	// fileEntry looks up a file, treating directories as absent.
+	fileEntry := func(tree *object.Tree, path string) (*object.TreeEntry, bool) {
+		if tree == nil {
+			return nil, false
+		}
+		entry, err := tree.FindEntry(path)
+		if err != nil || entry.Mode == filemode.Dir {
+			return nil, false
+		}
+		return entry, true
+	}
+
+	var changed []string
+	for _, path := range paths {
+		to, inTo := fileEntry(commitTree, path)
+		from, inFrom := fileEntry(parentTree, path)
+		if inTo != inFrom || (inTo && (to.Hash != from.Hash || to.Mode != from.Mode)) {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,124 @@
+package strategy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitWorktree stages every change in dir and commits it.
+func commitWorktree(t *testing.T, repo *git.Repository, message string) *object.Commit {
+	t.Helper()
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, wt.AddWithOptions(&git.AddOptions{All: true}))
+	hash, err := wt.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	commit, err := repo.CommitObject(hash)
+	require.NoError(t, err)
+	return commit
+}
+
+func TestChangedFilesBetweenTrees(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	write := func(path, content string) {
+		full := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+	// Enough top-level directories that the subtrees are diffed in parallel
+	for i := range 20 {
+		for j := range 5 {
+			write(fmt.Sprintf("pkg%02d/sub/file%d.go", i, j), "package sub\n")
+		}
+	}
+	write("script.sh", "echo hi\n")
+	write("becomes_dir", "file\n")
+	write("gone/only.txt", "bye\n")
+	base := commitWorktree(t, repo, "base")
+
+	write("pkg03/sub/file1.go", "package sub // changed\n")
+	write("pkg07/sub/deeper/new.go", "package deeper\n")
+	write("pkg15/new.go", "package pkg15\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "pkg11/sub/file4.go")))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "script.sh"), 0o755))
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Remove("becomes_dir")
+	require.NoError(t, err)
+	write("becomes_dir/inner.txt", "dir\n")
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "gone")))
+	head := commitWorktree(t, repo, "change")
+
+	baseTree, err := base.Tree()
+	require.NoError(t, err)
+	headTree, err := head.Tree()
+	require.NoError(t, err)
+
+	changed, err := changedFilesBetweenTrees(repo, baseTree, headTree)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{
+		"pkg03/sub/file1.go":      {},
+		"pkg07/sub/deeper/new.go": {},
+		"pkg15/new.go":            {},
+		"pkg11/sub/file4.go":      {},
+		"script.sh":               {},
+		"becomes_dir":             {},
+		"becomes_dir/inner.txt":   {},
+		"gone/only.txt":           {},
+	}, changed)
+
+	// Matches go-git's own diff
+	changes, err := baseTree.Diff(headTree)
+	require.NoError(t, err)
+	want := make(map[string]struct{})
+	for _, c := range changes {
+		for _, name := range []string{c.From.Name, c.To.Name} {
+			if name != "" {
+				want[name] = struct{}{}
+			}
+		}
+	}
+	assert.Equal(t, want, changed)
+
+	// A nil from tree lists every file, as for a root commit
+	all, err := changedFilesBetweenTrees(repo, nil, baseTree)
+	require.NoError(t, err)
+	assert.Len(t, all, 20*5+4)
+	assert.Contains(t, all, "test.txt")
+}
+
+func TestFilesChangedAtPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	t.Chdir(dir)
+
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "a.go"), []byte("package src\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unchanged.txt"), []byte("same\n"), 0o644))
+	commitWorktree(t, repo, "base")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "a.go"), []byte("package src // edited\n"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "test.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o644))
+	head := commitWorktree(t, repo, "change")
+
+	changed := filesChangedAtPaths(head, []string{"unchanged.txt", "src/a.go", "test.txt", "new.txt", "missing.txt", "src"})
+	assert.Equal(t, []string{"src/a.go", "test.txt", "new.txt"}, changed)
+}
@@ -0,0 +1,209 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// WorktreeInfo describes a git worktree and the Entire data keyed to it.
+// Shadow branches name a worktree only by the hash of its ID, so data left
+// behind by a worktree git no longer knows may be listed by Hash alone.
+type WorktreeInfo struct {
+	// ID is git's internal name for a linked worktree (.git/worktrees/<id>).
+	// Empty for the main worktree and for worktrees known only by Hash.
+	ID string
+	// Hash is the worktree ID hash used in shadow branch names.
+	Hash string
+	// Main is set for the main worktree.
+	Main bool
+	// Path is the worktree's directory, from git or from its sessions.
+	// Empty when unknown.
+	Path string
+	// Registered is set when git still lists the worktree.
+	Registered bool
+	// Missing is set when the worktree's directory no longer exists.
+	Missing bool
+	// Sessions are the session states started in the worktree.
+	Sessions []*SessionState
+	// ShadowBranches are the shadow branches written from the worktree.
+	ShadowBranches []string
+	// Mismatched are sessions recorded under this worktree whose path now
+	// belongs to a different one, e.g. after a worktree was removed and
+	// another one was added at its path.
+	Mismatched []*SessionState
+}
+
+// Stale reports whether the worktree is gone but has Entire data left.
+func (w *WorktreeInfo) Stale() bool {
+	if w.Main {
+		return false
+	}
+	return (!w.Registered || w.Missing) && (len(w.Sessions) > 0 || len(w.ShadowBranches) > 0)
+}
+
+// Label is the name the worktree is shown and selected by: its ID, "main",
+// or the hash of an ID git no longer knows.
+func (w *WorktreeInfo) Label() string {
+	switch {
+	case w.Main:
+		return "main"
+	case w.ID != "":
+		return w.ID
+	default:
+		return w.Hash
+	}
+}
+
+// ListWorktrees returns the main worktree, the linked worktrees git knows,
+// and any worktree that only sessions or shadow branches still refer to.
+// Sessions are the current user's.
+func ListWorktrees() ([]*WorktreeInfo, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return nil, err
+	}
+	commonDir, err = filepath.Abs(commonDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve git common dir: %w", err)
+	}
+
+	main := &WorktreeInfo{Main: true, Hash: checkpoint.HashWorktreeID(""), Path: filepath.Dir(commonDir), Registered: true}
+	byID := map[string]*WorktreeInfo{"": main}
+	worktrees := []*WorktreeInfo{main}
+	add := func(w *WorktreeInfo) *WorktreeInfo {
+		byID[w.ID] = w
+		worktrees = append(worktrees, w)
+		return w
+	}
+
+	linked, err := registeredWorktrees(commonDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range linked {
+		add(w)
+	}
+
+	states, err := ListSessionStates()
+	if err != nil {
+		return nil, err
+	}
+	for _, state := range states {
+		w, ok := byID[state.WorktreeID]
+		if !ok {
+			w = add(&WorktreeInfo{ID: state.WorktreeID, Hash: checkpoint.HashWorktreeID(state.WorktreeID), Path: state.WorktreePath})
+		}
+		w.Sessions = append(w.Sessions, state)
+		if w.Path == "" {
+			w.Path = state.WorktreePath
+		}
+		if state.WorktreePath == "" {
+			continue
+		}
+		if current, err := paths.GetWorktreeID(state.WorktreePath); err == nil && current != state.WorktreeID {
+			w.Mismatched = append(w.Mismatched, state)
+		}
+	}
+
+	branches, err := ListShadowBranches()
+	if err != nil {
+		return nil, err
+	}
+	byHash := make(map[string]*WorktreeInfo, len(worktrees))
+	for _, w := range worktrees {
+		byHash[w.Hash] = w
+	}
+	for _, branch := range branches {
+		_, hash, ok := checkpoint.ParseShadowBranchName(branch)
+		if !ok || hash == "" {
+			continue // legacy branch names don't record a worktree
+		}
+		w, ok := byHash[hash]
+		if !ok {
+			w = &WorktreeInfo{Hash: hash}
+			worktrees = append(worktrees, w)
+			byHash[hash] = w
+		}
+		w.ShadowBranches = append(w.ShadowBranches, branch)
+	}
+
+	for _, w := range worktrees {
+		if w.Path != "" {
+			if _, err := os.Stat(w.Path); errors.Is(err, os.ErrNotExist) {
+				w.Missing = true
+			}
+		}
+		slices.Sort(w.ShadowBranches)
+	}
+	slices.SortStableFunc(worktrees[1:], func(a, b *WorktreeInfo) int {
+		return strings.Compare(a.Label(), b.Label())
+	})
+	return worktrees, nil
+}
+
+// FindWorktree returns the worktree whose ID or hash is label. The main
+// worktree cannot be selected.
+func FindWorktree(worktrees []*WorktreeInfo, label string) (*WorktreeInfo, error) {
+	for _, w := range worktrees {
+		if w.Main {
+			continue
+		}
+		if w.ID == label || w.Hash == label {
+			return w, nil
+		}
+	}
+	for _, w := range worktrees {
+		if w.Main && (label == "main" || w.Hash == label) {
+			return nil, errors.New("the main worktree cannot be cleaned; use 'entire clean' or 'entire gc'")
+		}
+	}
+	return nil, fmt.Errorf("worktree not found: %s", label)
+}
+
+// WorktreeCleanupItems returns the session states and shadow branches of a
+// worktree, for DeleteAllCleanupItems.
+func WorktreeCleanupItems(w *WorktreeInfo) []CleanupItem {
+	reason := "worktree " + w.Label() + " was removed"
+	items := make([]CleanupItem, 0, len(w.Sessions)+len(w.ShadowBranches))
+	for _, state := range w.Sessions {
+		items = append(items, CleanupItem{Type: CleanupTypeSessionState, ID: state.SessionID, Reason: reason})
+	}
+	for _, branch := range w.ShadowBranches {
+		items = append(items, CleanupItem{Type: CleanupTypeShadowBranch, ID: branch, Reason: reason})
+	}
+	return items
+}
+
+// registeredWorktrees returns the linked worktrees recorded under
+// <commonDir>/worktrees/, with the paths git has for them.
+func registeredWorktrees(commonDir string) ([]*WorktreeInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(commonDir, "worktrees"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read worktrees: %w", err)
+	}
+
+	var worktrees []*WorktreeInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		w := &WorktreeInfo{ID: entry.Name(), Hash: checkpoint.HashWorktreeID(entry.Name()), Registered: true}
+		// gitdir holds the path of the worktree's .git file
+		gitdirFile := filepath.Join(commonDir, "worktrees", entry.Name(), "gitdir")
+		if data, err := os.ReadFile(gitdirFile); err == nil { //nolint:gosec // path is built from the git common dir
+			w.Path = filepath.Dir(strings.TrimSpace(string(data)))
+		}
+		worktrees = append(worktrees, w)
+	}
+	return worktrees, nil
+}
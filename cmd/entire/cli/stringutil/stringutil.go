@@ -6,6 +6,8 @@ import (
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
 )
 
 // whitespaceRegex matches one or more whitespace characters (including newlines)
@@ -45,3 +47,124 @@ func CapitalizeFirst(s string) string {
 	}
 	return string(unicode.ToUpper(r)) + s[size:]
 }
+
+// DisplayWidth returns the number of terminal cells s occupies.
+// East Asian wide characters and most emoji count as two cells.
+func DisplayWidth(s string) int {
+	return uniseg.StringWidth(s)
+}
+
+// TruncateWidth truncates s to at most maxWidth terminal cells, appending
+// suffix if truncated. It never splits a grapheme cluster (combining marks,
+// emoji sequences), and for space-separated text it backs up to the last
+// word boundary rather than cutting a word in half. Text without spaces,
+// such as Chinese or Japanese, is cut at the last whole character that fits.
+func TruncateWidth(s string, maxWidth int, suffix string) string {
+	if DisplayWidth(s) <= maxWidth {
+		return s
+	}
+	budget := maxWidth - DisplayWidth(suffix)
+	if budget < 0 {
+		budget = 0
+	}
+
+	width, cut := 0, 0
+	wordCut, wordWidth := 0, 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		w := g.Width()
+		if width+w > budget {
+			break
+		}
+		start, end := g.Positions()
+		if strings.TrimSpace(g.Str()) == "" {
+			wordCut, wordWidth = start, width
+		}
+		width += w
+		cut = end
+	}
+
+	// Only back up to a word boundary if it keeps at least half the budget;
+	// otherwise a single long word would leave almost nothing.
+	if cut < len(s) && wordCut > 0 && wordWidth >= budget/2 && !isWordBoundary(s, cut) {
+		cut = wordCut
+	}
+
+	return strings.TrimRightFunc(s[:cut], func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == ';' || r == ':'
+	}) + suffix
+}
+
+// isWordBoundary reports whether byte offset i of s falls between words.
+func isWordBoundary(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return unicode.IsSpace(r)
+}
+
+// abbreviations are words whose trailing period does not end a sentence.
+var abbreviations = map[string]bool{
+	"etc": true, "vs": true, "approx": true, "mr": true, "mrs": true, "ms": true, "dr": true,
+}
+
+// FirstSentence returns the first sentence of s. A sentence ends at a newline
+// (unless the line ends with a colon, as in "Fix the following:"), at ". ",
+// "! ", or "? ", or at a full-width terminator such as "。". Periods inside
+// words (file names, version numbers) and after common abbreviations do not
+// end a sentence. Returns s with whitespace collapsed if no sentence break is
+// found.
+func FirstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if line, _, ok := strings.Cut(s, "\n"); ok {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasSuffix(line, ":") {
+			s = line
+		}
+	}
+	s = CollapseWhitespace(s)
+
+	for i, r := range s {
+		switch r {
+		case '。', '！', '？':
+			return s[:i+utf8.RuneLen(r)]
+		case '.', '!', '?':
+			next := i + 1
+			if next < len(s) && s[next] == ' ' && !endsWithAbbreviation(s[:i]) {
+				return s[:next]
+			}
+		}
+	}
+	return s
+}
+
+// endsWithAbbreviation reports whether the last word of s is an abbreviation
+// such as "e.g" or "etc" (the trailing period already removed). Single letters
+// and dotted initialisms count; dotted words like "main.go" do not.
+func endsWithAbbreviation(s string) bool {
+	word := s[strings.LastIndex(s, " ")+1:]
+	initialism := true
+	for _, part := range strings.Split(word, ".") {
+		if utf8.RuneCountInString(part) > 1 {
+			initialism = false
+			break
+		}
+	}
+	return initialism || abbreviations[strings.ToLower(word)]
+}
+
+// SummaryOptions controls how Summarize shortens text.
+type SummaryOptions struct {
+	// MaxWidth is the maximum display width in terminal cells.
+	MaxWidth int
+	// FirstSentence keeps only the first sentence before truncating.
+	FirstSentence bool
+	// Suffix is appended when the text is truncated, e.g. "...".
+	Suffix string
+}
+
+// Summarize reduces multi-line text such as a user prompt to a single line
+// that fits opts.MaxWidth, using FirstSentence and TruncateWidth.
+func Summarize(s string, opts SummaryOptions) string {
+	if opts.FirstSentence {
+		s = FirstSentence(s)
+	}
+	return TruncateWidth(CollapseWhitespace(s), opts.MaxWidth, opts.Suffix)
+}
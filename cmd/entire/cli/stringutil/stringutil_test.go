@@ -218,3 +218,97 @@ func TestCapitalizeFirst(t *testing.T) {
 		})
 	}
 }
+
+func TestDisplayWidth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"hello", 5},
+		{"日本語", 6},
+		{"café", 4},
+		{"café", 4}, // e + combining acute accent
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := DisplayWidth(tt.input); got != tt.want {
+			t.Errorf("DisplayWidth(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		maxWidth int
+		suffix   string
+		want     string
+	}{
+		{name: "fits", input: "fix the bug", maxWidth: 20, suffix: "...", want: "fix the bug"},
+		{name: "breaks at word boundary", input: "refactor the authentication module", maxWidth: 20, suffix: "...", want: "refactor the..."},
+		{name: "exact word end", input: "fix the bug today", maxWidth: 11, suffix: "", want: "fix the bug"},
+		{name: "long single word is cut", input: "internationalization", maxWidth: 10, suffix: "...", want: "interna..."},
+		{name: "trims trailing comma", input: "first, second third", maxWidth: 12, suffix: "", want: "first"},
+		{name: "CJK by width", input: "日本語のテキスト", maxWidth: 7, suffix: "", want: "日本語"},
+		{name: "CJK with suffix", input: "日本語のテキスト", maxWidth: 9, suffix: "...", want: "日本語..."},
+		{name: "keeps combining marks", input: "cafés are nice", maxWidth: 5, suffix: "", want: "cafés"},
+		{name: "keeps emoji sequence whole", input: "👍🏽👍🏽👍🏽", maxWidth: 5, suffix: "", want: "👍🏽👍🏽"},
+		{name: "zero width", input: "hello", maxWidth: 0, suffix: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := TruncateWidth(tt.input, tt.maxWidth, tt.suffix)
+			if got != tt.want {
+				t.Errorf("TruncateWidth(%q, %d, %q) = %q, want %q", tt.input, tt.maxWidth, tt.suffix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFirstSentence(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "single sentence", input: "Fix the bug", want: "Fix the bug"},
+		{name: "two sentences", input: "Fix the bug. Then add tests.", want: "Fix the bug."},
+		{name: "question", input: "Why does login fail? It worked yesterday.", want: "Why does login fail?"},
+		{name: "first line", input: "Add retries\n\nThe client times out.", want: "Add retries"},
+		{name: "line ending in colon keeps going", input: "Fix the following:\n- login\n- logout", want: "Fix the following: - login - logout"},
+		{name: "file name", input: "Update main.go. Then run tests.", want: "Update main.go."},
+		{name: "abbreviation", input: "Use a map, e.g. a cache. Then test.", want: "Use a map, e.g. a cache."},
+		{name: "etc", input: "Rename foo, bar, etc. in the API. Done.", want: "Rename foo, bar, etc. in the API."},
+		{name: "version number", input: "Bump to 1.2.3 now", want: "Bump to 1.2.3 now"},
+		{name: "full-width terminator", input: "修复登录错误。密码为空时会崩溃。", want: "修复登录错误。"},
+		{name: "empty", input: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := FirstSentence(tt.input); got != tt.want {
+				t.Errorf("FirstSentence(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	prompt := "Refactor the session store to use atomic writes.\nIt currently corrupts state on crash."
+
+	got := Summarize(prompt, SummaryOptions{MaxWidth: 60, FirstSentence: true, Suffix: "..."})
+	if want := "Refactor the session store to use atomic writes."; got != want {
+		t.Errorf("Summarize(sentence) = %q, want %q", got, want)
+	}
+
+	got = Summarize(prompt, SummaryOptions{MaxWidth: 60, Suffix: "..."})
+	if want := "Refactor the session store to use atomic writes. It..."; got != want {
+		t.Errorf("Summarize(truncate) = %q, want %q", got, want)
+	}
+}
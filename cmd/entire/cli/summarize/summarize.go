@@ -9,6 +9,9 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/agent/opencode"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
@@ -119,6 +122,12 @@ func BuildCondensedTranscriptFromBytes(content []byte, agentType agent.AgentType
 		return buildCondensedTranscriptFromGemini(content)
 	case agent.AgentTypeOpenCode:
 		return buildCondensedTranscriptFromOpenCode(content)
+	case agent.AgentTypeCursor:
+		return buildCondensedTranscriptFromCursor(content), nil
+	case agent.AgentTypeAider:
+		return buildCondensedTranscriptFromAider(content), nil
+	case agent.AgentTypeCodex:
+		return buildCondensedTranscriptFromCodex(content), nil
 	case agent.AgentTypeClaudeCode, agent.AgentTypeUnknown:
 		// Claude format - fall through to shared logic below
 	}
@@ -224,8 +233,87 @@ func extractOpenCodeToolDetail(input map[string]interface{}) string {
 	return ""
 }
 
+// buildCondensedTranscriptFromCursor parses a Cursor JSONL transcript and extracts a condensed view.
+func buildCondensedTranscriptFromCursor(content []byte) []Entry {
+	var entries []Entry
+	for _, line := range cursor.ParseTranscript(content) {
+		switch line.Role {
+		case cursor.RoleUser:
+			if prompt := cursor.ExtractUserPrompt(line); prompt != "" {
+				entries = append(entries, Entry{
+					Type:    EntryTypeUser,
+					Content: prompt,
+				})
+			}
+		case cursor.RoleAssistant:
+			if text := cursor.MessageText(line); text != "" {
+				entries = append(entries, Entry{
+					Type:    EntryTypeAssistant,
+					Content: text,
+				})
+			}
+			for _, call := range cursor.ExtractToolCalls(line) {
+				entries = append(entries, Entry{
+					Type:       EntryTypeTool,
+					ToolName:   call.Name,
+					ToolDetail: extractGenericToolDetail(call.Input),
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// buildCondensedTranscriptFromAider parses an Aider markdown chat history and
+// extracts a condensed view. Aider's own output is left out; the files it
+// edited are listed separately in the summary prompt.
+func buildCondensedTranscriptFromAider(content []byte) []Entry {
+	var entries []Entry
+	for _, msg := range aider.ParseMessages(content, 0) {
+		switch msg.Role {
+		case aider.RoleUser:
+			entries = append(entries, Entry{
+				Type:    EntryTypeUser,
+				Content: msg.Text,
+			})
+		case aider.RoleAssistant:
+			entries = append(entries, Entry{
+				Type:    EntryTypeAssistant,
+				Content: msg.Text,
+			})
+		}
+	}
+	return entries
+}
+
+// buildCondensedTranscriptFromCodex parses a Codex JSONL rollout and extracts a condensed view.
+func buildCondensedTranscriptFromCodex(content []byte) []Entry {
+	var entries []Entry
+	for _, msg := range codex.ParseMessages(codex.ParseRollout(content)) {
+		switch msg.Role {
+		case codex.RoleUser:
+			entries = append(entries, Entry{
+				Type:    EntryTypeUser,
+				Content: msg.Text,
+			})
+		case codex.RoleAssistant:
+			entries = append(entries, Entry{
+				Type:    EntryTypeAssistant,
+				Content: msg.Text,
+			})
+		case codex.RoleTool:
+			entries = append(entries, Entry{
+				Type:       EntryTypeTool,
+				ToolName:   msg.ToolName,
+				ToolDetail: msg.ToolDetail,
+			})
+		}
+	}
+	return entries
+}
+
 // extractGenericToolDetail extracts an appropriate detail string from a tool's input/args map.
-// Checks common fields in order of preference. Used by Gemini condensation.
+// Checks common fields in order of preference. Used by Gemini and Cursor condensation.
 func extractGenericToolDetail(input map[string]interface{}) string {
 	for _, key := range []string{"description", "command", "file_path", "path", "pattern"} {
 		if v, ok := input[key].(string); ok && v != "" {
@@ -427,6 +427,81 @@ func TestBuildCondensedTranscript_SkipsEmptyContent(t *testing.T) {
 	}
 }
 
+func TestBuildCondensedTranscriptFromBytes_Cursor(t *testing.T) {
+	cursorJSONL := `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nAdd a README\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Creating it."},{"type":"tool_use","name":"Write","input":{"path":"README.md"}}]}}
+`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(cursorJSONL), agent.AgentTypeCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries (user + assistant + tool), got %d", len(entries))
+	}
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Add a README" {
+		t.Errorf("entry 0: expected user prompt without tags, got %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "Creating it." {
+		t.Errorf("entry 1: unexpected assistant entry %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeTool || entries[2].ToolName != "Write" || entries[2].ToolDetail != "README.md" {
+		t.Errorf("entry 2: unexpected tool entry %+v", entries[2])
+	}
+}
+
+func TestBuildCondensedTranscriptFromBytes_Aider(t *testing.T) {
+	history := "# aider chat started at 2026-03-02 14:30:05\n\n" +
+		"> aider --model sonnet\n\n" +
+		"#### add a hello function\n\n" +
+		"Here it is.\n\n" +
+		"> Tokens: 2.1k sent, 120 received.\n" +
+		"> Applied edit to main.py\n"
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(history), agent.AgentTypeAider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries (user + assistant, Aider output skipped), got %d", len(entries))
+	}
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "add a hello function" {
+		t.Errorf("entry 0: unexpected user entry %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "Here it is." {
+		t.Errorf("entry 1: unexpected assistant entry %+v", entries[1])
+	}
+}
+
+func TestBuildCondensedTranscriptFromBytes_Codex(t *testing.T) {
+	rollout := `{"type":"session_meta","payload":{"id":"0199a1b2-c3d4-7e5f-8a9b-0c1d2e3f4a5b","cwd":"/repo"}}
+{"type":"response_item","payload":{"type":"message","role":"user","content":[{"type":"input_text","text":"<environment_context>/repo</environment_context>"}]}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Add a README"}}
+{"type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Add File: README.md\n+# Project\n*** End Patch"}}
+{"type":"event_msg","payload":{"type":"agent_message","message":"Added it."}}
+{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Added it."}]}}`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(rollout), agent.AgentTypeCodex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries (user, tool, assistant), got %d", len(entries))
+	}
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Add a README" {
+		t.Errorf("entry 0: unexpected user entry %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeTool || entries[1].ToolName != "apply_patch" || entries[1].ToolDetail != "README.md" {
+		t.Errorf("entry 1: unexpected tool entry %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeAssistant || entries[2].Content != "Added it." {
+		t.Errorf("entry 2: unexpected assistant entry %+v", entries[2])
+	}
+}
+
 func TestBuildCondensedTranscriptFromBytes_GeminiUserAndAssistant(t *testing.T) {
 	geminiJSON := `{"messages":[
 		{"type":"user","content":"Help me write a Go function"},
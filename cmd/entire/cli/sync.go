@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newSyncCmd() *cobra.Command {
+	var remoteFlag string
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Fetch and merge teammates' checkpoints from a remote",
+		Long: `Fetch the ` + paths.MetadataBranchName() + ` branch from a remote (origin by default)
+and merge it into the local one, then list the checkpoints that became
+available, with who created them and which sessions they contain.
+
+Checkpoints are stored under their own IDs, so branches condensed on different
+machines merge without conflicts. If both sides changed the same file, the
+local version is kept, stamped commits are combined, and clashing revision
+numbers are renumbered; the remote version stays in the branch history.
+
+Nothing is pushed. Your own checkpoints reach the remote on your next git push,
+or after every commit with strategy_options.push_on_condense.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := openRepository(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			return runSync(cmd.OutOrStdout(), remoteFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&remoteFlag, "remote", "origin", "Remote to fetch checkpoints from")
+
+	return cmd
+}
+
+// syncedCheckpoint is a checkpoint that `entire sync` made available locally.
+type syncedCheckpoint struct {
+	info   checkpoint.CommittedInfo
+	author checkpoint.Author
+}
+
+func runSync(w io.Writer, remote string) error {
+	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
+	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
+	logging.SetLogLevelGetter(GetLogLevel)
+	logging.SetUserNamespaceGetter(settings.StateUserNamespace)
+	if err := logging.Init(""); err == nil {
+		defer logging.Close()
+	}
+
+	result, err := strategy.SyncMetadataBranch(remote)
+	if errors.Is(err, strategy.ErrNoRemoteMetadataBranch) {
+		fmt.Fprintf(w, "%s has no %s branch yet. Nothing to sync.\n", remote, paths.MetadataBranchName())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sync %s: %w", paths.MetadataBranchName(), err)
+	}
+
+	if len(result.NewCheckpoints) == 0 {
+		fmt.Fprintf(w, "No new checkpoints from %s.\n", remote)
+	} else {
+		synced, err := loadSyncedCheckpoints(result)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "New checkpoints from %s (%d):\n\n", remote, len(result.NewCheckpoints))
+		writeSyncedCheckpoints(w, synced)
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Fprintf(w, "\nKept the local version of files changed on both sides (%d):\n", len(result.Conflicts))
+		for _, p := range result.Conflicts {
+			fmt.Fprintf(w, "  %s\n", p)
+		}
+	}
+	if result.Merged {
+		fmt.Fprintf(w, "\nYour local checkpoints will reach %s on your next git push.\n", remote)
+	}
+	return nil
+}
+
+// loadSyncedCheckpoints reads the details of the checkpoints a sync added,
+// newest first.
+func loadSyncedCheckpoints(result *strategy.SyncResult) ([]syncedCheckpoint, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, err
+	}
+	store := checkpoint.NewGitStore(repo)
+	committed, err := store.ListCommitted(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var synced []syncedCheckpoint
+	for _, info := range committed {
+		if _, found := slices.BinarySearch(result.NewCheckpoints, info.CheckpointID); !found {
+			continue
+		}
+		author, err := store.GetCheckpointAuthor(context.Background(), info.CheckpointID)
+		if err != nil {
+			author = checkpoint.Author{}
+		}
+		synced = append(synced, syncedCheckpoint{info: info, author: author})
+	}
+	return synced, nil
+}
+
+func writeSyncedCheckpoints(w io.Writer, synced []syncedCheckpoint) {
+	authorWidth := len("AUTHOR")
+	agentWidth := len("AGENT")
+	for _, s := range synced {
+		authorWidth = max(authorWidth, len(syncedAuthorLabel(s.author)))
+		agentWidth = max(agentWidth, len(syncedAgentLabel(s.info.Agent)))
+	}
+
+	fmt.Fprintf(w, "%-12s  %-16s  %-*s  %-*s  %s\n", "CHECKPOINT", "CREATED", authorWidth, "AUTHOR", agentWidth, "AGENT", "SESSIONS")
+	for _, s := range synced {
+		fmt.Fprintf(w, "%-12s  %-16s  %-*s  %-*s  %s\n",
+			s.info.CheckpointID,
+			checkpointCreatedLabel(s.info.CreatedAt),
+			authorWidth, syncedAuthorLabel(s.author),
+			agentWidth, syncedAgentLabel(s.info.Agent),
+			checkpointSessionsLabel(s.info.SessionIDs))
+	}
+}
+
+func syncedAuthorLabel(author checkpoint.Author) string {
+	switch {
+	case author.Name != "":
+		return author.Name
+	case author.Email != "":
+		return author.Email
+	default:
+		return unknownPlaceholder
+	}
+}
+
+func syncedAgentLabel(agentType agent.AgentType) string {
+	if agentType == "" {
+		return "-"
+	}
+	return string(agentType)
+}
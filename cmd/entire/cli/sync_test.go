@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitForSyncTest(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, output)
+}
+
+// setupSyncRemote creates a bare remote and returns its path. If
+// withCheckpoint is set, a teammate has pushed a checkpoint to it.
+func setupSyncRemote(t *testing.T, withCheckpoint bool) string {
+	t.Helper()
+	remote := t.TempDir()
+	gitForSyncTest(t, remote, "init", "-q", "--bare")
+
+	teammate := t.TempDir()
+	gitForSyncTest(t, teammate, "init", "-q", "-b", "main")
+	gitForSyncTest(t, teammate, "remote", "add", "origin", remote)
+	gitForSyncTest(t, teammate, "commit", "-q", "--allow-empty", "-m", "initial")
+	gitForSyncTest(t, teammate, "push", "-q", "origin", "main")
+	if !withCheckpoint {
+		return remote
+	}
+
+	repo, err := git.PlainOpen(teammate)
+	require.NoError(t, err)
+	require.NoError(t, checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("a1b2c3d4e5f6"),
+		SessionID:    "2026-04-01-teammate",
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		FilesTouched: []string{"a.go"},
+		AuthorName:   "Alice",
+		AuthorEmail:  "alice@example.com",
+	}))
+	gitForSyncTest(t, teammate, "push", "-q", "origin", paths.MetadataBranchName())
+	return remote
+}
+
+func TestRunSync_NewCheckpoints(t *testing.T) {
+	remote := setupSyncRemote(t, true)
+	local := t.TempDir()
+	gitForSyncTest(t, local, "clone", "-q", remote, ".")
+	t.Chdir(local)
+	paths.ClearWorktreeRootCache()
+
+	var out bytes.Buffer
+	require.NoError(t, runSync(&out, "origin"))
+	assert.Contains(t, out.String(), "New checkpoints from origin (1):")
+	assert.Contains(t, out.String(), "a1b2c3d4e5f6")
+	assert.Contains(t, out.String(), "Alice")
+	assert.Contains(t, out.String(), string(agent.AgentTypeClaudeCode))
+	assert.Contains(t, out.String(), "2026-04-01-teammate")
+	assert.NotContains(t, out.String(), "next git push")
+
+	repo, err := git.PlainOpen(local)
+	require.NoError(t, err)
+	summary, err := checkpoint.NewGitStore(repo).ReadCommitted(context.Background(), id.MustCheckpointID("a1b2c3d4e5f6"))
+	require.NoError(t, err)
+	require.NotNil(t, summary)
+
+	out.Reset()
+	require.NoError(t, runSync(&out, "origin"))
+	assert.Equal(t, "No new checkpoints from origin.\n", out.String())
+}
+
+func TestRunSync_NoRemoteBranch(t *testing.T) {
+	remote := setupSyncRemote(t, false)
+	local := t.TempDir()
+	gitForSyncTest(t, local, "clone", "-q", remote, ".")
+	t.Chdir(local)
+	paths.ClearWorktreeRootCache()
+
+	var out bytes.Buffer
+	require.NoError(t, runSync(&out, "origin"))
+	assert.Equal(t, "origin has no "+paths.MetadataBranchName()+" branch yet. Nothing to sync.\n", out.String())
+}
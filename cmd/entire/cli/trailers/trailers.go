@@ -4,11 +4,15 @@
 package trailers
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	checkpointID "github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 )
 
 // Trailer key constants used in commit messages.
@@ -35,10 +39,18 @@ const (
 	// Format: "<branch>@<commit-hash>" e.g. "entire/metadata@abc123def456"
 	SourceRefTrailerKey = "Entire-Source-Ref"
 
-	// CheckpointTrailerKey links commits to their checkpoint metadata on entire/checkpoints/v1.
+	// SquashedCheckpointTrailerKey links a squash merge commit to the checkpoint
+	// of a commit it folded in. Unlike the checkpoint trailer, PostCommit never
+	// condenses sessions into it.
 	// Format: 12 hex characters e.g. "a3b2c4d5e6f7"
-	// This trailer survives git amend and rebase operations.
-	CheckpointTrailerKey = "Entire-Checkpoint"
+	SquashedCheckpointTrailerKey = "Entire-Squashed-Checkpoint"
+
+	// CherryPickedCheckpointTrailerKey links a cherry-picked commit to the
+	// checkpoint of the commit it was picked from, replacing the copied
+	// checkpoint trailer. Like the squashed trailer, PostCommit never
+	// condenses sessions into it.
+	// Format: 12 hex characters e.g. "a3b2c4d5e6f7"
+	CherryPickedCheckpointTrailerKey = "Entire-Cherry-Picked-Checkpoint"
 
 	// EphemeralBranchTrailerKey identifies the shadow branch that a checkpoint originated from.
 	// Used in manual-commit strategy checkpoint commits on entire/checkpoints/v1 branch.
@@ -48,6 +60,10 @@ const (
 	// AgentTrailerKey identifies the agent that created a checkpoint.
 	// Format: human-readable agent name e.g. "Claude Code", "Cursor"
 	AgentTrailerKey = "Entire-Agent"
+
+	// TicketTrailerKey links a commit to an issue or ticket the session was working on.
+	// Format: ticket ID e.g. "ABC-123" or "#42". A commit may carry several.
+	TicketTrailerKey = "Entire-Ticket"
 )
 
 // Pre-compiled regexes for trailer parsing.
@@ -59,9 +75,81 @@ var (
 	baseCommitTrailerRegex   = regexp.MustCompile(BaseCommitTrailerKey + `:\s*([a-f0-9]{40})`)
 	condensationTrailerRegex = regexp.MustCompile(CondensationTrailerKey + `:\s*(.+)`)
 	sessionTrailerRegex      = regexp.MustCompile(SessionTrailerKey + `:\s*(.+)`)
-	checkpointTrailerRegex   = regexp.MustCompile(CheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+	squashedTrailerRegex     = regexp.MustCompile(SquashedCheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+	cherryPickedTrailerRegex = regexp.MustCompile(CherryPickedCheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+	ticketTrailerRegex       = regexp.MustCompile(`(?m)^` + TicketTrailerKey + `:[ \t]*(\S+)[ \t]*$`)
 )
 
+// CheckpointTrailerKey returns the key of the trailer that links commits to
+// their checkpoint metadata on the metadata branch: Entire-Checkpoint unless
+// the "namespace" setting overrides it.
+// Format: 12 hex characters e.g. "a3b2c4d5e6f7"
+// This trailer survives git amend and rebase operations.
+func CheckpointTrailerKey() string {
+	return paths.CheckpointTrailerKey()
+}
+
+// checkpointRegexes are the checkpoint trailer regexes for one trailer key.
+type checkpointRegexes struct {
+	key string
+	// valid matches a trailer with a checkpoint ID under the configured or
+	// the default key, so commits made before the key changed still resolve.
+	valid *regexp.Regexp
+	// any matches a trailer line under the configured key with any value, so
+	// malformed values can be reported instead of silently ignored.
+	any *regexp.Regexp
+}
+
+var (
+	checkpointRegexMu     sync.Mutex
+	checkpointRegexCached *checkpointRegexes
+)
+
+// checkpointTrailerRegexes returns the regexes for the current trailer key,
+// compiling them when the key changes.
+func checkpointTrailerRegexes() *checkpointRegexes {
+	key := CheckpointTrailerKey()
+	checkpointRegexMu.Lock()
+	defer checkpointRegexMu.Unlock()
+	if checkpointRegexCached != nil && checkpointRegexCached.key == key {
+		return checkpointRegexCached
+	}
+	keys := regexp.QuoteMeta(key)
+	if key != paths.DefaultCheckpointTrailerKey {
+		keys += "|" + regexp.QuoteMeta(paths.DefaultCheckpointTrailerKey)
+	}
+	checkpointRegexCached = &checkpointRegexes{
+		key: key,
+		// The key must not be the tail of a longer key such as Entire-Squashed-Checkpoint.
+		valid: regexp.MustCompile(`(?m)(?:^|[^\w-])(?:` + keys + `):\s*(` + checkpointID.Pattern + `)(?:\s|$)`),
+		any:   regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(key) + `:[ \t]*(.*)$`),
+	}
+	return checkpointRegexCached
+}
+
+// ErrMalformedCheckpoint is returned when a commit message has a checkpoint
+// trailer whose value is not a valid checkpoint ID.
+var ErrMalformedCheckpoint = errors.New("malformed checkpoint trailer")
+
+// malformedCheckpointError names the trailer key in the message and matches
+// ErrMalformedCheckpoint with errors.Is.
+type malformedCheckpointError struct {
+	key string
+	err error
+}
+
+func (e *malformedCheckpointError) Error() string {
+	return fmt.Sprintf("malformed %s trailer: %v", e.key, e.err)
+}
+
+func (e *malformedCheckpointError) Is(target error) bool {
+	return target == ErrMalformedCheckpoint
+}
+
+func (e *malformedCheckpointError) Unwrap() error {
+	return e.err
+}
+
 // ParseStrategy extracts strategy from commit message.
 // Returns the strategy name and true if found, empty string and false otherwise.
 func ParseStrategy(commitMessage string) (string, bool) {
@@ -127,7 +215,7 @@ func ParseSession(commitMessage string) (string, bool) {
 // ParseCheckpoint extracts the checkpoint ID from a commit message.
 // Returns the CheckpointID and true if found, empty ID and false otherwise.
 func ParseCheckpoint(commitMessage string) (checkpointID.CheckpointID, bool) {
-	matches := checkpointTrailerRegex.FindStringSubmatch(commitMessage)
+	matches := checkpointTrailerRegexes().valid.FindStringSubmatch(commitMessage)
 	if len(matches) > 1 {
 		idStr := strings.TrimSpace(matches[1])
 		// Validate it's a proper checkpoint ID
@@ -138,6 +226,26 @@ func ParseCheckpoint(commitMessage string) (checkpointID.CheckpointID, bool) {
 	return checkpointID.EmptyCheckpointID, false
 }
 
+// ParseCheckpointID extracts the checkpoint ID from a commit message like
+// ParseCheckpoint, but distinguishes a missing trailer from a malformed one.
+// Returns (id, true, nil) for a valid trailer, (empty, false, nil) when there is
+// no trailer, and (empty, false, err) wrapping ErrMalformedCheckpoint when a
+// trailer is present but its value is not a valid checkpoint ID.
+func ParseCheckpointID(commitMessage string) (checkpointID.CheckpointID, bool, error) {
+	if cpID, found := ParseCheckpoint(commitMessage); found {
+		return cpID, true, nil
+	}
+	regexes := checkpointTrailerRegexes()
+	matches := regexes.any.FindStringSubmatch(commitMessage)
+	if len(matches) > 1 {
+		value := strings.TrimSpace(matches[1])
+		if err := checkpointID.Validate(value); err != nil {
+			return checkpointID.EmptyCheckpointID, false, &malformedCheckpointError{key: regexes.key, err: err}
+		}
+	}
+	return checkpointID.EmptyCheckpointID, false, nil
+}
+
 // ParseAllSessions extracts all session IDs from a commit message.
 // Returns a slice of session IDs (may be empty if none found).
 // Duplicate session IDs are deduplicated while preserving order.
@@ -162,6 +270,75 @@ func ParseAllSessions(commitMessage string) []string {
 	return sessionIDs
 }
 
+// ParseAllCheckpoints extracts all valid checkpoint IDs from a commit message,
+// deduplicated in order. Squash merges can carry one Entire-Checkpoint or
+// Entire-Squashed-Checkpoint trailer per squashed commit; squashed checkpoints
+// are listed after the commit's own.
+func ParseAllCheckpoints(commitMessage string) []checkpointID.CheckpointID {
+	matches := checkpointTrailerRegexes().valid.FindAllStringSubmatch(commitMessage, -1)
+	matches = append(matches, squashedTrailerRegex.FindAllStringSubmatch(commitMessage, -1)...)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[checkpointID.CheckpointID]bool)
+	ids := make([]checkpointID.CheckpointID, 0, len(matches))
+	for _, match := range matches {
+		cpID, err := checkpointID.NewCheckpointID(strings.TrimSpace(match[1]))
+		if err != nil || seen[cpID] {
+			continue
+		}
+		seen[cpID] = true
+		ids = append(ids, cpID)
+	}
+	return ids
+}
+
+// ParseSquashedCheckpoints extracts the checkpoint IDs from
+// Entire-Squashed-Checkpoint trailers, deduplicated in order.
+func ParseSquashedCheckpoints(commitMessage string) []checkpointID.CheckpointID {
+	var ids []checkpointID.CheckpointID
+	for _, match := range squashedTrailerRegex.FindAllStringSubmatch(commitMessage, -1) {
+		cpID, err := checkpointID.NewCheckpointID(strings.TrimSpace(match[1]))
+		if err == nil && !slices.Contains(ids, cpID) {
+			ids = append(ids, cpID)
+		}
+	}
+	return ids
+}
+
+// ParseCherryPickedCheckpoints extracts the checkpoint IDs from
+// Entire-Cherry-Picked-Checkpoint trailers, deduplicated in order.
+func ParseCherryPickedCheckpoints(commitMessage string) []checkpointID.CheckpointID {
+	var ids []checkpointID.CheckpointID
+	for _, match := range cherryPickedTrailerRegex.FindAllStringSubmatch(commitMessage, -1) {
+		cpID, err := checkpointID.NewCheckpointID(strings.TrimSpace(match[1]))
+		if err == nil && !slices.Contains(ids, cpID) {
+			ids = append(ids, cpID)
+		}
+	}
+	return ids
+}
+
+// ParseAllTickets extracts all ticket IDs from Entire-Ticket trailers in a
+// commit message, deduplicated in order.
+func ParseAllTickets(commitMessage string) []string {
+	matches := ticketTrailerRegex.FindAllStringSubmatch(commitMessage, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	tickets := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			tickets = append(tickets, match[1])
+		}
+	}
+	return tickets
+}
+
 // FormatStrategy creates a commit message with just the strategy trailer.
 func FormatStrategy(message, strategy string) string {
 	return fmt.Sprintf("%s\n\n%s: %s\n", message, StrategyTrailerKey, strategy)
@@ -224,5 +401,5 @@ func FormatShadowTaskCommit(message, taskMetadataDir, sessionID string) string {
 // FormatCheckpoint creates a commit message with a checkpoint trailer.
 // This links user commits to their checkpoint metadata on entire/checkpoints/v1 branch.
 func FormatCheckpoint(message string, cpID checkpointID.CheckpointID) string {
-	return fmt.Sprintf("%s\n\n%s: %s\n", message, CheckpointTrailerKey, cpID.String())
+	return fmt.Sprintf("%s\n\n%s: %s\n", message, CheckpointTrailerKey(), cpID.String())
 }
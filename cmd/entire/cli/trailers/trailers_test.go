@@ -1,7 +1,12 @@
 package trailers
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	checkpointID "github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
 )
 
 func TestFormatMetadata(t *testing.T) {
@@ -255,6 +260,56 @@ func TestParseAllSessions(t *testing.T) {
 	}
 }
 
+func TestParseAllTickets(t *testing.T) {
+	message := "Fix login\n\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Ticket: ABC-123\n" +
+		"Entire-Ticket:  #42 \nEntire-Ticket: two words\nEntire-Ticket: ABC-123\n"
+	got := ParseAllTickets(message)
+	want := []string{"ABC-123", "#42"}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAllTickets() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ParseAllTickets()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if got := ParseAllTickets("No tickets here"); got != nil {
+		t.Errorf("ParseAllTickets() = %v, want nil", got)
+	}
+}
+
+func TestParseAllCheckpoints(t *testing.T) {
+	message := "Squashed\n\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Checkpoint: not-an-id\n" +
+		"Entire-Checkpoint: 0123456789ab\nEntire-Checkpoint: a1b2c3d4e5f6\n"
+	got := ParseAllCheckpoints(message)
+	if len(got) != 2 || got[0].String() != "a1b2c3d4e5f6" || got[1].String() != "0123456789ab" {
+		t.Errorf("ParseAllCheckpoints() = %v, want [a1b2c3d4e5f6 0123456789ab]", got)
+	}
+
+	if got := ParseAllCheckpoints("No trailers"); got != nil {
+		t.Errorf("ParseAllCheckpoints() = %v, want nil", got)
+	}
+}
+
+func TestParseAllCheckpoints_Squashed(t *testing.T) {
+	message := "Squash feature\n\nEntire-Checkpoint: fedcba987654\n" +
+		"Entire-Squashed-Checkpoint: a1b2c3d4e5f6\nEntire-Squashed-Checkpoint: fedcba987654\n"
+	got := ParseAllCheckpoints(message)
+	if len(got) != 2 || got[0].String() != "fedcba987654" || got[1].String() != "a1b2c3d4e5f6" {
+		t.Errorf("ParseAllCheckpoints() = %v, want [fedcba987654 a1b2c3d4e5f6]", got)
+	}
+
+	squashed := ParseSquashedCheckpoints(message)
+	if len(squashed) != 2 || squashed[0].String() != "a1b2c3d4e5f6" {
+		t.Errorf("ParseSquashedCheckpoints() = %v, want [a1b2c3d4e5f6 fedcba987654]", squashed)
+	}
+
+	// A squashed checkpoint is never the commit's own checkpoint
+	if cpID, found := ParseCheckpoint("Squash\n\nEntire-Squashed-Checkpoint: a1b2c3d4e5f6\n"); found {
+		t.Errorf("ParseCheckpoint() = %s, want not found", cpID)
+	}
+}
+
 func TestParseCheckpoint(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -318,3 +373,104 @@ func TestParseCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCheckpointID(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       string
+		wantID        string
+		wantFound     bool
+		wantMalformed bool
+	}{
+		{
+			name:      "valid checkpoint trailer",
+			message:   "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			wantID:    "a1b2c3d4e5f6",
+			wantFound: true,
+		},
+		{
+			name:    "no trailer",
+			message: "Simple commit message",
+		},
+		{
+			name:          "too short",
+			message:       "Message\n\nEntire-Checkpoint: abc123\n",
+			wantMalformed: true,
+		},
+		{
+			name:          "empty value",
+			message:       "Message\n\nEntire-Checkpoint:\n",
+			wantMalformed: true,
+		},
+		{
+			name:          "path traversal attempt",
+			message:       "Message\n\nEntire-Checkpoint: ../../etc/passwd\n",
+			wantMalformed: true,
+		},
+		{
+			name:          "uppercase hex",
+			message:       "Message\n\nEntire-Checkpoint: A1B2C3D4E5F6\n",
+			wantMalformed: true,
+		},
+		{
+			name:    "key mentioned mid-line is not a trailer",
+			message: "Explain the Entire-Checkpoint: format in docs\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotID, gotFound, err := ParseCheckpointID(tt.message)
+			if gotFound != tt.wantFound {
+				t.Errorf("ParseCheckpointID() found = %v, want %v", gotFound, tt.wantFound)
+			}
+			if gotID.String() != tt.wantID {
+				t.Errorf("ParseCheckpointID() id = %v, want %v", gotID.String(), tt.wantID)
+			}
+			if gotMalformed := errors.Is(err, ErrMalformedCheckpoint); gotMalformed != tt.wantMalformed {
+				t.Errorf("ParseCheckpointID() err = %v, want malformed = %v", err, tt.wantMalformed)
+			}
+		})
+	}
+}
+
+func TestCheckpointTrailer_CustomKey(t *testing.T) {
+	t.Cleanup(func() { paths.SetNamespaceGetter(nil) })
+	paths.SetNamespaceGetter(func() paths.Namespace { return paths.Namespace{TrailerKey: "Checkpoint"} })
+
+	cpID := checkpointID.MustCheckpointID("a1b2c3d4e5f6")
+	if got, want := FormatCheckpoint("Fix bug", cpID), "Fix bug\n\nCheckpoint: a1b2c3d4e5f6\n"; got != want {
+		t.Errorf("FormatCheckpoint() = %q, want %q", got, want)
+	}
+	if got, found := ParseCheckpoint("Fix bug\n\nCheckpoint: a1b2c3d4e5f6\n"); !found || got != cpID {
+		t.Errorf("ParseCheckpoint() with the configured key = %v, %v", got, found)
+	}
+	if got, found := ParseCheckpoint("Fix bug\n\nEntire-Checkpoint: a1b2c3d4e5f6\n"); !found || got != cpID {
+		t.Errorf("ParseCheckpoint() with the default key = %v, %v; commits made before the change must still resolve", got, found)
+	}
+	if _, found := ParseCheckpoint("Squash\n\nEntire-Squashed-Checkpoint: a1b2c3d4e5f6\n"); found {
+		t.Error("ParseCheckpoint() matched the tail of Entire-Squashed-Checkpoint")
+	}
+
+	_, _, err := ParseCheckpointID("Fix bug\n\nCheckpoint: nope\n")
+	if !errors.Is(err, ErrMalformedCheckpoint) {
+		t.Fatalf("ParseCheckpointID() error = %v, want ErrMalformedCheckpoint", err)
+	}
+	if !strings.Contains(err.Error(), "malformed Checkpoint trailer") {
+		t.Errorf("ParseCheckpointID() error = %q, want it to name the configured key", err)
+	}
+}
+
+func TestParseCherryPickedCheckpoints(t *testing.T) {
+	message := "Add a\n\nEntire-Cherry-Picked-Checkpoint: a1b2c3d4e5f6\nEntire-Cherry-Picked-Checkpoint: a1b2c3d4e5f6\n"
+	got := ParseCherryPickedCheckpoints(message)
+	if len(got) != 1 || got[0].String() != "a1b2c3d4e5f6" {
+		t.Errorf("ParseCherryPickedCheckpoints() = %v, want [a1b2c3d4e5f6]", got)
+	}
+	if _, found := ParseCheckpoint(message); found {
+		t.Error("ParseCheckpoint() found a checkpoint in cherry-picked trailers")
+	}
+	if all := ParseAllCheckpoints(message); len(all) != 0 {
+		t.Errorf("ParseAllCheckpoints() = %v, want none: cherry-picked commits are not linked", all)
+	}
+}
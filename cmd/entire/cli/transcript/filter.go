@@ -0,0 +1,298 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// FilterOptions selects what Filter removes from a JSONL transcript.
+// The zero value keeps everything.
+type FilterOptions struct {
+	// KeepTypes, if set, keeps only lines whose "type" is listed. Lines
+	// without a type and lines that are not JSON are always kept.
+	KeepTypes []string
+	// MaxToolOutputBytes, if positive, truncates tool outputs larger than
+	// this many bytes.
+	MaxToolOutputBytes int
+	// MaxBytes, if positive, limits the size of the filtered transcript.
+	// Larger transcripts keep whole lines from the head and tail, about half
+	// the limit each, and replace the lines between with a marker line.
+	MaxBytes int
+}
+
+// Enabled reports whether the options filter anything.
+func (o FilterOptions) Enabled() bool {
+	return len(o.KeepTypes) > 0 || o.MaxToolOutputBytes > 0 || o.MaxBytes > 0
+}
+
+// FilterStats records what Filter removed, so the original size of a stored
+// transcript is known.
+type FilterStats struct {
+	// OriginalBytes is the size of the transcript before filtering.
+	OriginalBytes int `json:"original_bytes"`
+	// OriginalLines is the number of lines before filtering.
+	OriginalLines int `json:"original_lines"`
+	// DroppedLines is the number of lines removed by type.
+	DroppedLines int `json:"dropped_lines,omitempty"`
+	// TruncatedToolOutputs is the number of tool outputs truncated.
+	TruncatedToolOutputs int `json:"truncated_tool_outputs,omitempty"`
+	// OmittedLines is the number of lines between the head and tail
+	// replaced by the omission marker to stay under MaxBytes.
+	OmittedLines int `json:"omitted_lines,omitempty"`
+	// OmittedBytes is the size of the omitted lines.
+	OmittedBytes int `json:"omitted_bytes,omitempty"`
+}
+
+// FilterResult is a filtered transcript.
+type FilterResult struct {
+	Transcript []byte
+	Stats      FilterStats
+	// keptBefore[i] is the number of lines kept among the first i lines.
+	keptBefore []int
+}
+
+// LineOffset maps a line offset in the original transcript to the offset of
+// the same position in the filtered one.
+func (r *FilterResult) LineOffset(original int) int {
+	switch {
+	case original <= 0:
+		return 0
+	case original >= len(r.keptBefore):
+		return r.keptBefore[len(r.keptBefore)-1]
+	}
+	return r.keptBefore[original]
+}
+
+// OmittedLineType is the type of the marker line that replaces the middle of
+// a transcript larger than FilterOptions.MaxBytes.
+const OmittedLineType = "entire_omitted"
+
+// truncatedToolOutputKey is added to truncated tool_result blocks and
+// replaces oversized toolUseResult values, recording the original size.
+const truncatedToolOutputKey = "entire_original_bytes"
+
+// Filter applies opts to a JSONL transcript: lines of types not in KeepTypes
+// are dropped, tool outputs larger than MaxToolOutputBytes are cut down to
+// that size with a marker, and the middle of a transcript larger than
+// MaxBytes is omitted. Lines that need no change are kept byte for byte.
+func Filter(data []byte, opts FilterOptions) *FilterResult {
+	result := &FilterResult{
+		Stats:      FilterStats{OriginalBytes: len(data)},
+		keptBefore: []int{0},
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(data))
+	// lineEnds[i] is the end offset of kept line i in out.
+	var lineEnds []int
+	kept := 0
+	for len(data) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			line, data = data[:i+1], data[i+1:]
+		} else {
+			line, data = data, nil
+		}
+		result.Stats.OriginalLines++
+
+		if filtered, keep := filterLine(line, opts, &result.Stats); keep {
+			out.Write(filtered)
+			lineEnds = append(lineEnds, out.Len())
+			kept++
+		} else {
+			result.Stats.DroppedLines++
+		}
+		result.keptBefore = append(result.keptBefore, kept)
+	}
+
+	result.Transcript = out.Bytes()
+	if opts.MaxBytes > 0 && len(result.Transcript) > opts.MaxBytes {
+		result.omitMiddle(lineEnds, opts.MaxBytes)
+	}
+	return result
+}
+
+// omitMiddle replaces the lines between the head and tail of the filtered
+// transcript, each at most half of maxBytes, with an OmittedLineType marker.
+func (r *FilterResult) omitMiddle(lineEnds []int, maxBytes int) {
+	data := r.Transcript
+	lineStart := func(i int) int {
+		if i == 0 {
+			return 0
+		}
+		return lineEnds[i-1]
+	}
+	head := 0
+	for head < len(lineEnds) && lineEnds[head] <= maxBytes/2 {
+		head++
+	}
+	tail := 0
+	for tail < len(lineEnds)-head && len(data)-lineStart(len(lineEnds)-tail-1) <= maxBytes/2 {
+		tail++
+	}
+	omitted := len(lineEnds) - head - tail
+	if omitted == 0 {
+		return
+	}
+	omitStart, omitEnd := lineStart(head), lineStart(len(lineEnds)-tail)
+	r.Stats.OmittedLines = omitted
+	r.Stats.OmittedBytes = omitEnd - omitStart
+
+	marker := fmt.Sprintf(`{"type":%q,"omitted_lines":%d,"omitted_bytes":%d}`+"\n", OmittedLineType, omitted, omitEnd-omitStart)
+	out := make([]byte, 0, omitStart+len(marker)+len(data)-omitEnd)
+	out = append(out, data[:omitStart]...)
+	out = append(out, marker...)
+	out = append(out, data[omitEnd:]...)
+	r.Transcript = out
+
+	// Positions inside the omitted lines map to just after the marker.
+	for i, k := range r.keptBefore {
+		switch {
+		case k <= head:
+		case k >= len(lineEnds)-tail:
+			r.keptBefore[i] = k - omitted + 1
+		default:
+			r.keptBefore[i] = head + 1
+		}
+	}
+}
+
+// filterLine returns the line to keep, or false to drop it.
+func filterLine(line []byte, opts FilterOptions, stats *FilterStats) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return line, true
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return line, true
+	}
+
+	if len(opts.KeepTypes) > 0 {
+		var lineType string
+		if raw, ok := fields["type"]; ok {
+			_ = json.Unmarshal(raw, &lineType) //nolint:errcheck // non-string types are kept
+		}
+		if lineType != "" && !slices.Contains(opts.KeepTypes, lineType) {
+			return nil, false
+		}
+	}
+
+	if opts.MaxToolOutputBytes <= 0 || len(trimmed) <= opts.MaxToolOutputBytes {
+		return line, true
+	}
+	if !truncateToolOutputs(fields, opts.MaxToolOutputBytes, stats) {
+		return line, true
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return line, true
+	}
+	if bytes.HasSuffix(line, []byte("\n")) {
+		encoded = append(encoded, '\n')
+	}
+	return encoded, true
+}
+
+// truncateToolOutputs truncates the oversized tool outputs of a line in place
+// and reports whether it changed anything. Tool outputs are the tool_result
+// blocks of the message and Claude Code's toolUseResult copy of them.
+func truncateToolOutputs(fields map[string]json.RawMessage, maxBytes int, stats *FilterStats) bool {
+	changed := false
+
+	if raw, ok := fields["toolUseResult"]; ok && len(raw) > maxBytes {
+		if encoded, err := json.Marshal(map[string]int{truncatedToolOutputKey: len(raw)}); err == nil {
+			fields["toolUseResult"] = encoded
+			stats.TruncatedToolOutputs++
+			changed = true
+		}
+	}
+
+	rawMessage, ok := fields["message"]
+	if !ok {
+		return changed
+	}
+	var message map[string]json.RawMessage
+	if err := json.Unmarshal(rawMessage, &message); err != nil {
+		return changed
+	}
+	var blocks []map[string]json.RawMessage
+	if err := json.Unmarshal(message["content"], &blocks); err != nil {
+		return changed
+	}
+
+	blocksChanged := false
+	for _, block := range blocks {
+		var blockType string
+		_ = json.Unmarshal(block["type"], &blockType) //nolint:errcheck // blocks without a type are skipped
+		content := block["content"]
+		if blockType != "tool_result" || len(content) <= maxBytes {
+			continue
+		}
+		text := truncateUTF8(toolResultText(content), maxBytes)
+		text += fmt.Sprintf("\n[truncated by entire: %d of %d bytes kept]", len(text), len(content))
+		encodedText, err := json.Marshal(text)
+		if err != nil {
+			continue
+		}
+		block["content"] = encodedText
+		block[truncatedToolOutputKey] = json.RawMessage(fmt.Sprintf("%d", len(content)))
+		stats.TruncatedToolOutputs++
+		blocksChanged = true
+	}
+	if !blocksChanged {
+		return changed
+	}
+
+	encodedBlocks, err := json.Marshal(blocks)
+	if err != nil {
+		return changed
+	}
+	message["content"] = encodedBlocks
+	encodedMessage, err := json.Marshal(message)
+	if err != nil {
+		return changed
+	}
+	fields["message"] = encodedMessage
+	return true
+}
+
+// toolResultText returns the text of a tool_result content value, which is
+// either a string or a list of content blocks.
+func toolResultText(content json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(content, &s); err == nil {
+		return s
+	}
+	var blocks []ContentBlock
+	if err := json.Unmarshal(content, &blocks); err == nil {
+		var texts []string
+		for _, b := range blocks {
+			if b.Type == ContentTypeText {
+				texts = append(texts, b.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return string(content)
+}
+
+// truncateUTF8 returns the longest prefix of s of at most maxBytes bytes that
+// does not split a character.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	s = s[:maxBytes]
+	for len(s) > 0 {
+		if r, size := utf8.DecodeLastRuneInString(s); r != utf8.RuneError || size > 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,154 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFilter_KeepTypes(t *testing.T) {
+	content := []byte(`{"type":"user","uuid":"u1","message":{"content":"hello"}}
+{"type":"progress","uuid":"p1"}
+{"type":"assistant","uuid":"a1","message":{"content":[{"type":"text","text":"hi"}]}}
+{"type":"system","uuid":"s1"}
+{"uuid":"untyped"}
+`)
+
+	result := Filter(content, FilterOptions{KeepTypes: []string{"user", "assistant"}})
+
+	want := `{"type":"user","uuid":"u1","message":{"content":"hello"}}
+{"type":"assistant","uuid":"a1","message":{"content":[{"type":"text","text":"hi"}]}}
+{"uuid":"untyped"}
+`
+	if string(result.Transcript) != want {
+		t.Errorf("Filter() transcript =\n%s\nwant\n%s", result.Transcript, want)
+	}
+	if result.Stats.OriginalBytes != len(content) {
+		t.Errorf("OriginalBytes = %d, want %d", result.Stats.OriginalBytes, len(content))
+	}
+	if result.Stats.OriginalLines != 5 || result.Stats.DroppedLines != 2 {
+		t.Errorf("Stats = %+v, want 5 original lines and 2 dropped", result.Stats)
+	}
+
+	// Offsets past dropped lines shift down by the lines dropped before them.
+	for original, want := range map[int]int{0: 0, 1: 1, 2: 1, 3: 2, 4: 2, 5: 3, 9: 3} {
+		if got := result.LineOffset(original); got != want {
+			t.Errorf("LineOffset(%d) = %d, want %d", original, got, want)
+		}
+	}
+}
+
+func TestFilter_TruncatesToolOutputs(t *testing.T) {
+	big := strings.Repeat("x", 200)
+	line := map[string]any{
+		"type": "user",
+		"uuid": "u1",
+		"message": map[string]any{
+			"role": "user",
+			"content": []map[string]any{
+				{"type": "tool_result", "tool_use_id": "t1", "content": big},
+			},
+		},
+		"toolUseResult": map[string]any{"stdout": big},
+	}
+	encoded, err := json.Marshal(line)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	small := `{"type":"user","uuid":"u2","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}`
+	content := []byte(string(encoded) + "\n" + small + "\n")
+
+	result := Filter(content, FilterOptions{MaxToolOutputBytes: 50})
+
+	if result.Stats.TruncatedToolOutputs != 2 {
+		t.Errorf("TruncatedToolOutputs = %d, want 2", result.Stats.TruncatedToolOutputs)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(result.Transcript), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[1] != small {
+		t.Errorf("small tool output changed: %s", lines[1])
+	}
+
+	var got struct {
+		Message struct {
+			Content []struct {
+				Content       string `json:"content"`
+				OriginalBytes int    `json:"entire_original_bytes"`
+			} `json:"content"`
+		} `json:"message"`
+		ToolUseResult map[string]int `json:"toolUseResult"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("truncated line is not valid JSON: %v", err)
+	}
+	block := got.Message.Content[0]
+	if !strings.HasPrefix(block.Content, strings.Repeat("x", 50)+"\n[truncated by entire: 50 of 202 bytes kept]") {
+		t.Errorf("unexpected truncated content: %q", block.Content)
+	}
+	if block.OriginalBytes != 202 {
+		t.Errorf("entire_original_bytes = %d, want 202", block.OriginalBytes)
+	}
+	if got.ToolUseResult[truncatedToolOutputKey] == 0 {
+		t.Errorf("toolUseResult not replaced: %v", got.ToolUseResult)
+	}
+}
+
+func TestFilter_NoOptionsKeepsTranscript(t *testing.T) {
+	content := []byte("{\"type\":\"user\"}\nnot json\n{\"type\":\"assistant\"}")
+
+	result := Filter(content, FilterOptions{})
+
+	if string(result.Transcript) != string(content) {
+		t.Errorf("Filter() changed the transcript: %q", result.Transcript)
+	}
+	if result.Stats.OriginalLines != 3 || result.Stats.DroppedLines != 0 {
+		t.Errorf("Stats = %+v", result.Stats)
+	}
+}
+
+func TestFilter_MaxBytesOmitsMiddle(t *testing.T) {
+	var lines []string
+	for i := range 10 {
+		lines = append(lines, fmt.Sprintf(`{"type":"user","uuid":"u%d","message":{"content":"line %d"}}`, i, i))
+	}
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	lineSize := len(lines[0]) + 1
+
+	result := Filter(content, FilterOptions{MaxBytes: 4*lineSize + lineSize/2})
+
+	want := strings.Join(lines[:2], "\n") + "\n" +
+		fmt.Sprintf(`{"type":"entire_omitted","omitted_lines":6,"omitted_bytes":%d}`, 6*lineSize) + "\n" +
+		strings.Join(lines[8:], "\n") + "\n"
+	if string(result.Transcript) != want {
+		t.Errorf("Filter() transcript =\n%s\nwant\n%s", result.Transcript, want)
+	}
+	if result.Stats.OmittedLines != 6 || result.Stats.OmittedBytes != 6*lineSize {
+		t.Errorf("Stats = %+v, want 6 omitted lines of %d bytes", result.Stats, 6*lineSize)
+	}
+
+	// Offsets in the head are kept, offsets in the omitted lines point after
+	// the marker, and offsets in the tail shift by the omitted lines.
+	for original, want := range map[int]int{0: 0, 2: 2, 3: 3, 7: 3, 8: 3, 9: 4, 10: 5} {
+		if got := result.LineOffset(original); got != want {
+			t.Errorf("LineOffset(%d) = %d, want %d", original, got, want)
+		}
+	}
+
+	// Transcripts within the limit are unchanged
+	result = Filter(content, FilterOptions{MaxBytes: len(content)})
+	if string(result.Transcript) != string(content) || result.Stats.OmittedLines != 0 {
+		t.Errorf("Filter() changed a transcript within MaxBytes: %+v", result.Stats)
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	if got := truncateUTF8("héllo", 2); got != "h" {
+		t.Errorf("truncateUTF8() = %q, want %q", got, "h")
+	}
+	if got := truncateUTF8("héllo", 3); got != "hé" {
+		t.Errorf("truncateUTF8() = %q, want %q", got, "hé")
+	}
+}
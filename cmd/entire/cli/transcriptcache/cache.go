@@ -0,0 +1,331 @@
+// Package transcriptcache keeps local copies of agent transcripts.
+//
+// Agents write transcripts under the user's home directory (for example
+// ~/.claude/projects/...). When home is a network mount, reading it during a
+// hook can be slow or fail intermittently. The cache copies each transcript
+// into .entire/cache/transcripts/ with a SHA-256 checksum, so hooks read a
+// local file instead. Transcripts are append-only, so a refresh only reads
+// the bytes written since the last sync.
+//
+// Reads of the source are retried with a per-attempt timeout. If the source
+// stays unreachable, the last verified copy is used instead, so a slow home
+// directory degrades checkpoint freshness rather than failing the checkpoint.
+package transcriptcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/faultinject"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// DirName is the cache directory under paths.EntireCacheDir.
+const DirName = "transcripts"
+
+// Defaults for reading the source transcript.
+const (
+	defaultAttempts = 3
+	defaultTimeout  = 10 * time.Second
+	defaultBackoff  = 200 * time.Millisecond
+)
+
+// overlapSize is how many bytes at the end of the cached copy are compared
+// against the source before appending, to detect a rewritten transcript.
+const overlapSize = 4096
+
+// Cache stores local copies of transcripts, one per session.
+type Cache struct {
+	dir      string
+	attempts int
+	timeout  time.Duration
+	backoff  time.Duration
+}
+
+// New returns a cache rooted at dir.
+func New(dir string) *Cache {
+	return &Cache{dir: dir, attempts: defaultAttempts, timeout: defaultTimeout, backoff: defaultBackoff}
+}
+
+// Open returns the cache for the current repository.
+func Open() (*Cache, error) {
+	dir, err := paths.AbsPath(filepath.Join(paths.EntireCacheDir, DirName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve transcript cache directory: %w", err)
+	}
+	return New(dir), nil
+}
+
+// ForTranscript returns the current repository's cache if the transcript at
+// ref lives outside the repository (typically under the user's home
+// directory). Transcripts inside the repository are already local and are not
+// cached.
+func ForTranscript(ref string) (*Cache, bool) {
+	if ref == "" {
+		return nil, false
+	}
+	repoRoot, err := paths.WorktreeRoot()
+	if err != nil {
+		return nil, false
+	}
+	if paths.RepoRelative(repoRoot, ref) != "" {
+		return nil, false
+	}
+	cache, err := Open()
+	if err != nil {
+		return nil, false
+	}
+	return cache, true
+}
+
+// SetRetry overrides how many times and how long each read of the source is attempted.
+func (c *Cache) SetRetry(attempts int, timeout, backoff time.Duration) {
+	c.attempts = max(attempts, 1)
+	c.timeout = timeout
+	c.backoff = backoff
+}
+
+// entry is the sidecar metadata stored next to each cached transcript.
+type entry struct {
+	Source   string    `json:"source"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"mod_time"`
+	SHA256   string    `json:"sha256"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Path returns the cached transcript path for a session.
+func (c *Cache) Path(sessionID string) string {
+	return filepath.Join(c.dir, sessionID+".jsonl")
+}
+
+func (c *Cache) metaPath(sessionID string) string {
+	return filepath.Join(c.dir, sessionID+".json")
+}
+
+// Sync refreshes the cached copy of source for sessionID and returns the path
+// callers should read. If source cannot be read but a verified copy from an
+// earlier sync exists, that copy's path is returned; if the cache itself
+// cannot be written, source is returned. Returns an error only if neither is
+// usable.
+func (c *Cache) Sync(ctx context.Context, sessionID, source string) (string, error) {
+	logCtx := logging.WithComponent(ctx, "transcript-cache")
+	cached, cachedData, cachedOK := c.load(sessionID, source)
+
+	info, err := retry(ctx, c, func() (os.FileInfo, error) { return os.Stat(source) })
+	if err == nil && cachedOK && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return c.Path(sessionID), nil
+	}
+
+	var data []byte
+	if err == nil {
+		data, err = c.readSource(ctx, source, cached, cachedData, cachedOK)
+	}
+	if err != nil {
+		if cachedOK {
+			logging.Warn(logCtx, "transcript source unavailable, using cached copy",
+				slog.String("source", source),
+				slog.Time("synced_at", cached.SyncedAt),
+				slog.String("error", err.Error()),
+			)
+			return c.Path(sessionID), nil
+		}
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	next := entry{
+		Source:   source,
+		Size:     int64(len(data)),
+		ModTime:  info.ModTime(),
+		SHA256:   hex.EncodeToString(sum[:]),
+		SyncedAt: time.Now(),
+	}
+	if err := c.store(sessionID, data, next); err != nil {
+		// The source was readable just now, so read it directly rather than fail.
+		logging.Warn(logCtx, "failed to update transcript cache, using source",
+			slog.String("source", source),
+			slog.String("error", err.Error()),
+		)
+		return source, nil
+	}
+	return c.Path(sessionID), nil
+}
+
+// Read returns the contents of source, going through the cache (see Sync).
+func (c *Cache) Read(ctx context.Context, sessionID, source string) ([]byte, error) {
+	path, err := c.Sync(ctx, sessionID, source)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is within the cache directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached transcript: %w", err)
+	}
+	return data, nil
+}
+
+// Has reports whether a verified copy of source is cached for sessionID.
+func (c *Cache) Has(sessionID, source string) bool {
+	_, _, ok := c.load(sessionID, source)
+	return ok
+}
+
+// load returns the cached entry and data for sessionID if it was synced from
+// source and its checksum still matches.
+func (c *Cache) load(sessionID, source string) (entry, []byte, bool) {
+	metaData, err := os.ReadFile(c.metaPath(sessionID))
+	if err != nil {
+		return entry{}, nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(metaData, &e); err != nil || e.Source != source {
+		return entry{}, nil, false
+	}
+	data, err := os.ReadFile(c.Path(sessionID))
+	if err != nil || int64(len(data)) != e.Size {
+		return entry{}, nil, false
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != e.SHA256 {
+		return entry{}, nil, false
+	}
+	return e, data, true
+}
+
+// readSource returns the full contents of source. When a verified cached copy
+// is still a prefix of source, only the appended bytes are read.
+func (c *Cache) readSource(ctx context.Context, source string, cached entry, cachedData []byte, cachedOK bool) ([]byte, error) {
+	if err := faultinject.Check(faultinject.TranscriptRead); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	if cachedOK && cached.Size > 0 {
+		overlap := min(cached.Size, overlapSize)
+		tail, err := retry(ctx, c, func() ([]byte, error) { return readFrom(source, cached.Size-overlap) })
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(tail)) >= overlap && bytes.Equal(tail[:overlap], cachedData[cached.Size-overlap:]) {
+			return append(cachedData[:cached.Size:cached.Size], tail[overlap:]...), nil
+		}
+		// Source was truncated or rewritten; fall through to a full copy.
+	}
+	return retry(ctx, c, func() ([]byte, error) { return os.ReadFile(source) }) //nolint:gosec // source is the agent's transcript path
+}
+
+// store atomically writes the cached transcript and its metadata.
+func (c *Cache) store(sessionID string, data []byte, e entry) error {
+	if err := os.MkdirAll(c.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create transcript cache directory: %w", err)
+	}
+	if err := writeAtomic(c.Path(sessionID), data); err != nil {
+		return err
+	}
+	metaData, err := jsonutil.MarshalIndentWithNewline(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript cache entry: %w", err)
+	}
+	return writeAtomic(c.metaPath(sessionID), metaData)
+}
+
+// Remove deletes the cached copy for a session. Missing entries are not an error.
+func (c *Cache) Remove(sessionID string) error {
+	for _, p := range []string{c.Path(sessionID), c.metaPath(sessionID)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove cached transcript: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write transcript cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write transcript cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write transcript cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write transcript cache: %w", err)
+	}
+	return nil
+}
+
+// readFrom reads source from offset to the end.
+func readFrom(source string, offset int64) ([]byte, error) {
+	f, err := os.Open(source) //nolint:gosec // source is the agent's transcript path
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by retry callers
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by retry callers
+	}
+	return io.ReadAll(f) //nolint:wrapcheck // wrapped by retry callers
+}
+
+// retry runs op until it succeeds, up to c.attempts times, abandoning any
+// attempt that exceeds c.timeout. A missing file is not retried.
+func retry[T any](ctx context.Context, c *Cache, op func() (T, error)) (T, error) {
+	var zero T
+	var lastErr error
+	for attempt := range c.attempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return zero, fmt.Errorf("reading transcript: %w", ctx.Err())
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		type result struct {
+			value T
+			err   error
+		}
+		// Buffered so an abandoned attempt can still finish and exit.
+		done := make(chan result, 1)
+		go func() {
+			v, err := op()
+			done <- result{v, err}
+		}()
+
+		timer := time.NewTimer(c.timeout)
+		select {
+		case r := <-done:
+			timer.Stop()
+			if r.err == nil {
+				return r.value, nil
+			}
+			lastErr = r.err
+		case <-timer.C:
+			lastErr = fmt.Errorf("timed out after %s", c.timeout)
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, fmt.Errorf("reading transcript: %w", ctx.Err())
+		}
+		if errors.Is(lastErr, os.ErrNotExist) {
+			break
+		}
+	}
+	return zero, fmt.Errorf("reading transcript: %w", lastErr)
+}
@@ -0,0 +1,189 @@
+package transcriptcache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestSync_CopiesAndRefreshes(t *testing.T) {
+	t.Parallel()
+	source := filepath.Join(t.TempDir(), "session.jsonl")
+	cache := New(t.TempDir())
+	ctx := context.Background()
+
+	writeFile(t, source, "{\"type\":\"user\"}\n")
+	path, err := cache.Sync(ctx, "s1", source)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if path != cache.Path("s1") {
+		t.Errorf("Sync() path = %q, want cached path %q", path, cache.Path("s1"))
+	}
+	if got := readFile(t, path); got != "{\"type\":\"user\"}\n" {
+		t.Errorf("cached content = %q", got)
+	}
+
+	// Appended lines are picked up on the next sync.
+	f, err := os.OpenFile(source, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{\"type\":\"assistant\"}\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	data, err := cache.Read(ctx, "s1", source)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if want := "{\"type\":\"user\"}\n{\"type\":\"assistant\"}\n"; string(data) != want {
+		t.Errorf("Read() = %q, want %q", data, want)
+	}
+}
+
+func TestSync_DetectsRewrittenSource(t *testing.T) {
+	t.Parallel()
+	source := filepath.Join(t.TempDir(), "session.jsonl")
+	cache := New(t.TempDir())
+	ctx := context.Background()
+
+	writeFile(t, source, "original line\n")
+	if _, err := cache.Sync(ctx, "s1", source); err != nil {
+		t.Fatal(err)
+	}
+
+	// A longer file that does not start with the cached content must be
+	// copied in full rather than appended.
+	writeFile(t, source, "completely different and longer content\n")
+	data, err := cache.Read(ctx, "s1", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "completely different and longer content\n" {
+		t.Errorf("Read() = %q, want rewritten content", data)
+	}
+}
+
+func TestSync_FallsBackToCachedCopy(t *testing.T) {
+	t.Parallel()
+	source := filepath.Join(t.TempDir(), "session.jsonl")
+	cache := New(t.TempDir())
+	cache.SetRetry(2, time.Second, time.Millisecond)
+	ctx := context.Background()
+
+	writeFile(t, source, "line\n")
+	if _, err := cache.Sync(ctx, "s1", source); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(source); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cache.Has("s1", source) {
+		t.Fatal("Has() = false, want cached copy")
+	}
+	data, err := cache.Read(ctx, "s1", source)
+	if err != nil {
+		t.Fatalf("Read() error = %v, want cached copy", err)
+	}
+	if string(data) != "line\n" {
+		t.Errorf("Read() = %q, want cached content", data)
+	}
+}
+
+func TestSync_MissingSourceWithoutCache(t *testing.T) {
+	t.Parallel()
+	cache := New(t.TempDir())
+
+	_, err := cache.Sync(context.Background(), "s1", filepath.Join(t.TempDir(), "missing.jsonl"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Sync() error = %v, want ErrNotExist", err)
+	}
+}
+
+func TestSync_RejectsCorruptCache(t *testing.T) {
+	t.Parallel()
+	source := filepath.Join(t.TempDir(), "session.jsonl")
+	cache := New(t.TempDir())
+	ctx := context.Background()
+
+	writeFile(t, source, "good\n")
+	if _, err := cache.Sync(ctx, "s1", source); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, cache.Path("s1"), "bad!\n")
+
+	if cache.Has("s1", source) {
+		t.Error("Has() = true for a copy whose checksum no longer matches")
+	}
+	data, err := cache.Read(ctx, "s1", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "good\n" {
+		t.Errorf("Read() = %q, want content re-copied from source", data)
+	}
+}
+
+func TestRetry_TimesOut(t *testing.T) {
+	t.Parallel()
+	cache := New(t.TempDir())
+	cache.SetRetry(2, 10*time.Millisecond, time.Millisecond)
+
+	block := make(chan struct{})
+	defer close(block)
+	var calls atomic.Int32
+	_, err := retry(context.Background(), cache, func() ([]byte, error) {
+		calls.Add(1)
+		<-block
+		return nil, nil
+	})
+	if err == nil {
+		t.Fatal("retry() error = nil, want timeout")
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("retry() made %d attempts, want 2", n)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	t.Parallel()
+	source := filepath.Join(t.TempDir(), "session.jsonl")
+	cache := New(t.TempDir())
+
+	writeFile(t, source, "line\n")
+	if _, err := cache.Sync(context.Background(), "s1", source); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Remove("s1"); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Has("s1", source) {
+		t.Error("Has() = true after Remove()")
+	}
+	if err := cache.Remove("s1"); err != nil {
+		t.Errorf("Remove() of missing entry error = %v", err)
+	}
+}
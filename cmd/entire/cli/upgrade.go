@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/versioncheck"
+
+	"github.com/spf13/cobra"
+)
+
+func newUpgradeCmd() *cobra.Command {
+	var checkOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Upgrade the Entire CLI to the latest release",
+		Long: `Upgrade the Entire CLI to the latest release.
+
+Downloads the release archive for this platform, verifies it against the
+release's published SHA-256 checksums after checking their minisign
+signature, and atomically replaces the running binary. Afterwards, hooks in
+the current repository are refreshed by the new binary so hooks and binary
+stay in sync.
+
+Set ` + versioncheck.OfflineEnvVar + `=1 to disable all network access by the CLI's
+update checks.
+Homebrew installations should be upgraded with 'brew upgrade entire', and
+'go install' installations by re-running 'go install'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runUpgrade(context.Background(), cmd.OutOrStdout(), cmd.ErrOrStderr(), checkOnly)
+		},
+	}
+
+	cmd.Flags().BoolVar(&checkOnly, "check", false, "Only check whether a newer version is available")
+
+	return cmd
+}
+
+func runUpgrade(ctx context.Context, w, errW io.Writer, checkOnly bool) error {
+	currentVersion := buildinfo.Version
+	if currentVersion == "dev" || currentVersion == "" {
+		fmt.Fprintln(errW, "This is a development build of Entire CLI and cannot be upgraded in place.")
+		return NewSilentError(errors.New("development build"))
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the entire binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+		execPath = resolved
+	}
+	if versioncheck.IsHomebrewInstall(execPath) {
+		fmt.Fprintln(w, "Entire CLI was installed with Homebrew. Run 'brew upgrade entire' to upgrade.")
+		return nil
+	}
+	if versioncheck.IsGoInstall(execPath) {
+		fmt.Fprintln(w, "Entire CLI was installed with 'go install'. "+
+			"Run 'go install github.com/entireio/cli/cmd/entire@latest' to upgrade.")
+		return nil
+	}
+
+	upgrade, err := versioncheck.CheckForUpgrade(ctx, currentVersion)
+	if errors.Is(err, versioncheck.ErrOffline) {
+		fmt.Fprintf(errW, "Cannot check for upgrades: %v\n", err)
+		return NewSilentError(err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check for upgrades: %w", err)
+	}
+
+	if !upgrade.Available() {
+		fmt.Fprintf(w, "Entire CLI %s is up to date.\n", currentVersion)
+		return nil
+	}
+
+	if checkOnly {
+		fmt.Fprintf(w, "A newer version of Entire CLI is available: %s (current: %s)\n",
+			upgrade.LatestVersion, currentVersion)
+		fmt.Fprintln(w, "Run 'entire upgrade' to install it.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "Downloading Entire CLI %s (%s)...\n", upgrade.LatestVersion, upgrade.ArchiveName)
+	if err := versioncheck.InstallUpgrade(ctx, upgrade, execPath); err != nil {
+		return fmt.Errorf("upgrade failed: %w", err)
+	}
+	fmt.Fprintf(w, "✓ Upgraded Entire CLI from %s to %s\n", currentVersion, upgrade.LatestVersion)
+
+	// Let the new binary rewrite hooks so they match its version.
+	refresh := exec.CommandContext(ctx, execPath, "hooks", "refresh") //nolint:gosec // execPath is this binary's own path
+	refresh.Stdout = w
+	refresh.Stderr = errW
+	if err := refresh.Run(); err != nil {
+		fmt.Fprintf(errW, "Warning: failed to refresh hooks: %v\n"+
+			"Run 'entire enable --force' in each repository to reinstall them.\n", err)
+	}
+	return nil
+}
+
+// newHooksRefreshCmd creates the hidden command that reinstalls hooks in the
+// current repository. `entire upgrade` runs it with the new binary so that
+// installed hooks always match the binary that handles them.
+func newHooksRefreshCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "refresh",
+		Short: "Reinstall Entire hooks in the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runHooksRefresh(cmd.OutOrStdout())
+		},
+	}
+}
+
+func runHooksRefresh(w io.Writer) error {
+	// Nothing to refresh outside a repository or when Entire is not set up here
+	if _, err := paths.WorktreeRoot(); err != nil {
+		return nil //nolint:nilerr // not a repository, nothing to refresh
+	}
+	s, err := settings.Load()
+	if err != nil || !s.Enabled {
+		return nil //nolint:nilerr // Entire is not enabled in this repository
+	}
+
+	gitCount, err := strategy.InstallGitHook(true, s.LocalDev)
+	if err != nil {
+		return fmt.Errorf("failed to refresh git hooks: %w", err)
+	}
+
+	agentCount := 0
+	for _, name := range GetAgentsWithHooksInstalled() {
+		ag, err := agent.Get(name)
+		if err != nil {
+			continue
+		}
+		count, err := setupAgentHooks(ag, s.LocalDev, false)
+		if err != nil {
+			return err
+		}
+		agentCount += count
+	}
+
+	if gitCount+agentCount > 0 {
+		fmt.Fprintf(w, "✓ Refreshed %d hook(s) in this repository\n", gitCount+agentCount)
+	}
+	return nil
+}
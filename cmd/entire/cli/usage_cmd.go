@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/pricing"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+)
+
+// Values accepted by entire usage --by.
+const (
+	usageByAgent   = "agent"
+	usageBySession = "session"
+	usageByDay     = "day"
+	usageByCommit  = "commit"
+	usageByModel   = "model"
+)
+
+// usageNotCommitted is the --by commit key for checkpoints no local commit links to.
+const usageNotCommitted = "(no commit)"
+
+// usageRow is the token usage of one group of checkpoint sessions.
+type usageRow struct {
+	Key                 string  `json:"key"`
+	Checkpoints         int     `json:"checkpoints"`
+	InputTokens         int     `json:"input_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	APICallCount        int     `json:"api_call_count"`
+	Cost                float64 `json:"estimated_cost,omitempty"`
+	Currency            string  `json:"currency,omitempty"`
+
+	estimate *pricing.Estimate
+}
+
+// add counts usage, including subagent usage, into the row.
+func (r *usageRow) add(usage *agent.TokenUsage) {
+	for tu := usage; tu != nil; tu = tu.SubagentTokens {
+		r.InputTokens += tu.InputTokens
+		r.CacheCreationTokens += tu.CacheCreationTokens
+		r.CacheReadTokens += tu.CacheReadTokens
+		r.OutputTokens += tu.OutputTokens
+		r.APICallCount += tu.APICallCount
+	}
+}
+
+func (r *usageRow) addCost(est pricing.Estimate) {
+	if r.estimate == nil {
+		r.estimate = &pricing.Estimate{Currency: est.Currency}
+	}
+	r.estimate.Amount += est.Amount
+	r.Cost = r.estimate.Amount
+	r.Currency = est.Currency.Code
+}
+
+func newUsageCmd() *cobra.Command {
+	var sinceFlag string
+	var byFlag string
+	var jsonFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Report token usage across checkpoints",
+		Long: `Report the token usage recorded in committed checkpoints.
+
+Usage is grouped by agent, session, day, commit, or model (--by) and shows
+input, cache write, cache read, and output tokens, API calls, and an estimated
+cost. Costs are priced at the model named in the transcript, or the agent's
+default model, using the "pricing" setting. Subagent usage is included.
+
+With --by commit, each checkpoint counts toward the newest local commit that
+links to it.
+
+--since accepts a duration (90m, 24h, 7d), a date (2006-01-02), or an
+RFC 3339 timestamp.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			switch byFlag {
+			case usageByAgent, usageBySession, usageByDay, usageByCommit, usageByModel:
+			default:
+				return fmt.Errorf("invalid --by value %q: use agent, session, day, commit, or model", byFlag)
+			}
+			since, err := parseSince(sinceFlag, time.Now())
+			if err != nil {
+				return err
+			}
+			repo, err := openRepository()
+			if err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(err)
+			}
+			rows, total, err := collectUsage(context.Background(), repo, since, byFlag, loadCostEstimator())
+			if err != nil {
+				return err
+			}
+			w := cmd.OutOrStdout()
+			if jsonFlag {
+				return writeUsageJSON(w, rows, total)
+			}
+			writeUsageTable(w, byFlag, rows, total)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only count checkpoints created after this time (duration, date, or timestamp)")
+	cmd.Flags().StringVar(&byFlag, "by", usageByAgent, "Group usage by agent, session, day, commit, or model")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output usage as JSON")
+
+	return cmd
+}
+
+// collectUsage sums the token usage of every checkpoint session created at or
+// after since, grouped by the given key. Rows are sorted by key, which puts
+// days oldest first.
+func collectUsage(ctx context.Context, repo *git.Repository, since time.Time, by string, estimator *pricing.Estimator) ([]usageRow, usageRow, error) {
+	store := checkpoint.NewGitStore(repo)
+	infos, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, usageRow{}, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var commits map[id.CheckpointID][]checkpointCommit
+	if by == usageByCommit {
+		if commits, err = linkedCommits(repo, infos); err != nil {
+			return nil, usageRow{}, fmt.Errorf("failed to find linked commits: %w", err)
+		}
+	}
+
+	groups := make(map[string]*usageRow)
+	total := usageRow{Key: "Total"}
+	for _, info := range infos {
+		commit := usageNotCommitted
+		if linked := commits[info.CheckpointID]; len(linked) > 0 {
+			commit = strategy.TruncateHash(linked[0].Commit)
+		}
+		metadata, readErr := store.ReadCommittedMetadata(ctx, info.CheckpointID)
+		if readErr != nil {
+			continue
+		}
+		for _, m := range metadata {
+			if m.TokenUsage == nil || m.CreatedAt.Before(since) {
+				continue
+			}
+			key := usageKey(m, by, commit)
+			row, ok := groups[key]
+			if !ok {
+				row = &usageRow{Key: key}
+				groups[key] = row
+			}
+			row.Checkpoints++
+			row.add(m.TokenUsage)
+			total.Checkpoints++
+			total.add(m.TokenUsage)
+			if est, priced := estimator.Estimate(m.Agent, m.TokenUsage); priced {
+				row.addCost(est)
+				total.addCost(est)
+			}
+		}
+	}
+
+	rows := make([]usageRow, 0, len(groups))
+	for _, row := range groups {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows, total, nil
+}
+
+func usageKey(m checkpoint.CommittedMetadata, by, commit string) string {
+	switch by {
+	case usageByCommit:
+		return commit
+	case usageByModel:
+		if m.TokenUsage.Model == "" {
+			return "unknown"
+		}
+		return m.TokenUsage.Model
+	case usageBySession:
+		return m.SessionID
+	case usageByDay:
+		return m.CreatedAt.Local().Format("2006-01-02")
+	default:
+		if m.Agent == "" {
+			return string(agent.AgentTypeUnknown)
+		}
+		return string(m.Agent)
+	}
+}
+
+func writeUsageJSON(w io.Writer, rows []usageRow, total usageRow) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(struct {
+		Rows  []usageRow `json:"rows"`
+		Total usageRow   `json:"total"`
+	}{rows, total}); err != nil {
+		return fmt.Errorf("failed to encode usage: %w", err)
+	}
+	return nil
+}
+
+func writeUsageTable(w io.Writer, by string, rows []usageRow, total usageRow) {
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No token usage recorded.")
+		return
+	}
+
+	width := len(by)
+	for _, row := range rows {
+		width = max(width, len(row.Key))
+	}
+	line := func(key, checkpoints, input, cacheWrite, cacheRead, output, calls, cost string) {
+		fmt.Fprintf(w, "%-*s  %11s  %8s  %11s  %10s  %8s  %9s  %s\n",
+			width, key, checkpoints, input, cacheWrite, cacheRead, output, calls, cost)
+	}
+	row := func(r usageRow) {
+		cost := "-"
+		if r.estimate != nil {
+			cost = r.estimate.String()
+		}
+		line(r.Key, fmt.Sprint(r.Checkpoints), formatTokenCount(r.InputTokens),
+			formatTokenCount(r.CacheCreationTokens), formatTokenCount(r.CacheReadTokens),
+			formatTokenCount(r.OutputTokens), fmt.Sprint(r.APICallCount), cost)
+	}
+
+	line(by, "checkpoints", "input", "cache write", "cache read", "output", "API calls", "cost")
+	for _, r := range rows {
+		row(r)
+	}
+	row(total)
+}
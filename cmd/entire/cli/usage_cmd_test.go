@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runUsageForTest(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newUsageCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestUsage_AggregatesCheckpoints(t *testing.T) {
+	// Cannot use t.Parallel() because we use t.Chdir()
+	tmpDir := t.TempDir()
+	testutil.InitRepo(t, tmpDir)
+	t.Chdir(tmpDir)
+	paths.ClearWorktreeRootCache()
+	testutil.WriteFile(t, tmpDir, "a.go", "package a\n")
+	testutil.GitAdd(t, tmpDir, "a.go")
+	testutil.GitCommit(t, tmpDir, "Add a")
+
+	output, err := runUsageForTest(t)
+	require.NoError(t, err)
+	assert.Contains(t, output, "No token usage recorded.")
+
+	repo, err := git.PlainOpen(tmpDir)
+	require.NoError(t, err)
+	store := checkpoint.NewGitStore(repo)
+	for _, cp := range []struct {
+		checkpointID string
+		sessionID    string
+		agent        agent.AgentType
+		usage        *agent.TokenUsage
+	}{
+		{"a1a1a1a1a1a1", "session-one", agent.AgentTypeClaudeCode, &agent.TokenUsage{
+			Model: "claude-opus-4-1-20250805", InputTokens: 1000, OutputTokens: 200, CacheReadTokens: 5000, APICallCount: 3,
+			SubagentTokens: &agent.TokenUsage{InputTokens: 500, OutputTokens: 100, APICallCount: 1},
+		}},
+		{"b2b2b2b2b2b2", "session-one", agent.AgentTypeClaudeCode, &agent.TokenUsage{
+			InputTokens: 300, OutputTokens: 50, CacheCreationTokens: 700, APICallCount: 2,
+		}},
+		{"c3c3c3c3c3c3", "session-two", agent.AgentTypeGemini, &agent.TokenUsage{
+			InputTokens: 2000, OutputTokens: 400, APICallCount: 4,
+		}},
+	} {
+		require.NoError(t, store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+			CheckpointID: id.MustCheckpointID(cp.checkpointID),
+			SessionID:    cp.sessionID,
+			Strategy:     "manual-commit",
+			Agent:        cp.agent,
+			Transcript:   []byte(`{"type":"user","message":"hello"}` + "\n"),
+			FilesTouched: []string{"a.go"},
+			TokenUsage:   cp.usage,
+		}))
+	}
+
+	output, err = runUsageForTest(t, "--by", "session", "--json")
+	require.NoError(t, err)
+	var report struct {
+		Rows  []usageRow `json:"rows"`
+		Total usageRow   `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(output), &report))
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, "session-one", report.Rows[0].Key)
+	assert.Equal(t, 2, report.Rows[0].Checkpoints)
+	assert.Equal(t, 1800, report.Rows[0].InputTokens, "subagent usage is included")
+	assert.Equal(t, 350, report.Rows[0].OutputTokens)
+	assert.Equal(t, 700, report.Rows[0].CacheCreationTokens)
+	assert.Equal(t, 5000, report.Rows[0].CacheReadTokens)
+	assert.Equal(t, 6, report.Rows[0].APICallCount)
+	assert.Equal(t, "session-two", report.Rows[1].Key)
+	assert.Equal(t, 3, report.Total.Checkpoints)
+	assert.Equal(t, 3800, report.Total.InputTokens)
+	assert.Equal(t, 10, report.Total.APICallCount)
+
+	output, err = runUsageForTest(t, "--since", "7d")
+	require.NoError(t, err)
+	assert.Contains(t, output, string(agent.AgentTypeClaudeCode))
+	assert.Contains(t, output, string(agent.AgentTypeGemini))
+	assert.Contains(t, output, "Total")
+
+	output, err = runUsageForTest(t, "--by", "day")
+	require.NoError(t, err)
+	assert.Contains(t, output, time.Now().Format("2006-01-02"))
+
+	output, err = runUsageForTest(t, "--since", time.Now().AddDate(0, 0, 1).Format("2006-01-02"))
+	require.NoError(t, err)
+	assert.Contains(t, output, "No token usage recorded.")
+
+	output, err = runUsageForTest(t, "--by", "model")
+	require.NoError(t, err)
+	assert.Contains(t, output, "claude-opus-4-1-20250805")
+	assert.Contains(t, output, "unknown")
+
+	testutil.WriteFile(t, tmpDir, "b.go", "package b\n")
+	testutil.GitAdd(t, tmpDir, "b.go")
+	testutil.GitCommit(t, tmpDir, "Add b\n\nEntire-Checkpoint: a1a1a1a1a1a1\n")
+	output, err = runUsageForTest(t, "--by", "commit", "--json")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(output), &report))
+	require.Len(t, report.Rows, 2)
+	assert.Equal(t, usageNotCommitted, report.Rows[0].Key)
+	assert.Equal(t, 2, report.Rows[0].Checkpoints)
+	assert.Equal(t, 1, report.Rows[1].Checkpoints)
+	assert.Equal(t, 1500, report.Rows[1].InputTokens)
+	assert.Positive(t, report.Rows[1].Cost, "priced at the transcript's model")
+
+	_, err = runUsageForTest(t, "--by", "branch")
+	require.Error(t, err)
+}
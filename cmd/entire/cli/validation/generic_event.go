@@ -0,0 +1,110 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GenericEventVersion is the envelope version `entire hooks generic` accepts.
+const GenericEventVersion = 1
+
+// Generic hook events - the <event> of `entire hooks generic <event>`.
+const (
+	GenericEventSessionStart = "session-start"
+	GenericEventTurnStart    = "turn-start"
+	GenericEventTurnEnd      = "turn-end"
+	GenericEventCompaction   = "compaction"
+	GenericEventSessionEnd   = "session-end"
+)
+
+// GenericEventNames lists the generic hook events in lifecycle order.
+func GenericEventNames() []string {
+	return []string{
+		GenericEventSessionStart,
+		GenericEventTurnStart,
+		GenericEventTurnEnd,
+		GenericEventCompaction,
+		GenericEventSessionEnd,
+	}
+}
+
+// GenericEvent is the envelope a tool sends on stdin to `entire hooks generic
+// <event>` to drive the session lifecycle without a dedicated agent:
+//
+//	{
+//	  "version": 1,
+//	  "session_id": "run-42",
+//	  "transcript_path": "/home/me/.my-agent/run-42.jsonl",
+//	  "prompt": "Fix the login bug",
+//	  "previous_session_id": "run-41",
+//	  "timestamp": "2026-03-02T14:30:05Z",
+//	  "metadata": {"model": "my-model"}
+//	}
+//
+// session_id is required. transcript_path is required for turn-end and
+// compaction, which copy the transcript into the checkpoint, and must be
+// absolute. prompt is only accepted on turn-start. version defaults to 1,
+// timestamp to the time the event is received. Unknown fields are rejected
+// so typos don't silently drop data.
+type GenericEvent struct {
+	Version           int               `json:"version,omitempty"`
+	SessionID         string            `json:"session_id"`
+	TranscriptPath    string            `json:"transcript_path,omitempty"`
+	Prompt            string            `json:"prompt,omitempty"`
+	PreviousSessionID string            `json:"previous_session_id,omitempty"`
+	Timestamp         *time.Time        `json:"timestamp,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+// ParseGenericEvent decodes and validates the envelope for a generic hook event.
+func ParseGenericEvent(event string, data []byte) (*GenericEvent, error) {
+	if !slices.Contains(GenericEventNames(), event) {
+		return nil, fmt.Errorf("unknown generic event %q (supported: %s)", event, strings.Join(GenericEventNames(), ", "))
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("empty event envelope")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var envelope GenericEvent
+	if err := decoder.Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid event envelope: %w", err)
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("invalid event envelope: unexpected data after the JSON object")
+	}
+
+	if envelope.Version == 0 {
+		envelope.Version = GenericEventVersion
+	}
+	if envelope.Version != GenericEventVersion {
+		return nil, fmt.Errorf("unsupported event envelope version %d (supported: %d)", envelope.Version, GenericEventVersion)
+	}
+	if err := ValidateSessionID(envelope.SessionID); err != nil {
+		return nil, fmt.Errorf("invalid session_id: %w", err)
+	}
+	if envelope.PreviousSessionID != "" {
+		if err := ValidateSessionID(envelope.PreviousSessionID); err != nil {
+			return nil, fmt.Errorf("invalid previous_session_id: %w", err)
+		}
+	}
+
+	switch {
+	case envelope.TranscriptPath != "" && !filepath.IsAbs(envelope.TranscriptPath):
+		return nil, fmt.Errorf("invalid transcript_path %q: must be an absolute path", envelope.TranscriptPath)
+	case envelope.TranscriptPath == "" && (event == GenericEventTurnEnd || event == GenericEventCompaction):
+		return nil, fmt.Errorf("transcript_path is required for %s", event)
+	case envelope.Prompt != "" && event != GenericEventTurnStart:
+		return nil, fmt.Errorf("prompt is only accepted for %s", GenericEventTurnStart)
+	}
+
+	return &envelope, nil
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGenericEvent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		event   string
+		input   string
+		wantErr string
+	}{
+		{
+			name:  "session start",
+			event: GenericEventSessionStart,
+			input: `{"session_id": "run-42"}`,
+		},
+		{
+			name:  "turn start with prompt",
+			event: GenericEventTurnStart,
+			input: `{"version": 1, "session_id": "run-42", "transcript_path": "/tmp/run-42.jsonl", "prompt": "Fix the bug"}`,
+		},
+		{
+			name:  "turn end with all optional fields",
+			event: GenericEventTurnEnd,
+			input: `{"session_id": "run-42", "transcript_path": "/tmp/run-42.jsonl", "previous_session_id": "run-41", "timestamp": "2026-03-02T14:30:05Z", "metadata": {"model": "m1"}}`,
+		},
+		{
+			name:    "unknown event",
+			event:   "pre-tool",
+			input:   `{"session_id": "run-42"}`,
+			wantErr: `unknown generic event "pre-tool"`,
+		},
+		{
+			name:    "empty input",
+			event:   GenericEventSessionStart,
+			input:   "  \n",
+			wantErr: "empty event envelope",
+		},
+		{
+			name:    "not JSON",
+			event:   GenericEventSessionStart,
+			input:   "session-start",
+			wantErr: "invalid event envelope",
+		},
+		{
+			name:    "unknown field",
+			event:   GenericEventTurnStart,
+			input:   `{"session_id": "run-42", "promt": "typo"}`,
+			wantErr: `unknown field "promt"`,
+		},
+		{
+			name:    "trailing data",
+			event:   GenericEventSessionStart,
+			input:   `{"session_id": "run-42"} {"session_id": "run-43"}`,
+			wantErr: "unexpected data after the JSON object",
+		},
+		{
+			name:    "unsupported version",
+			event:   GenericEventSessionStart,
+			input:   `{"version": 2, "session_id": "run-42"}`,
+			wantErr: "unsupported event envelope version 2",
+		},
+		{
+			name:    "missing session ID",
+			event:   GenericEventSessionStart,
+			input:   `{"transcript_path": "/tmp/run-42.jsonl"}`,
+			wantErr: "invalid session_id",
+		},
+		{
+			name:    "session ID with path separator",
+			event:   GenericEventSessionStart,
+			input:   `{"session_id": "../run-42"}`,
+			wantErr: "invalid session_id",
+		},
+		{
+			name:    "invalid previous session ID",
+			event:   GenericEventSessionStart,
+			input:   `{"session_id": "run-42", "previous_session_id": "a/b"}`,
+			wantErr: "invalid previous_session_id",
+		},
+		{
+			name:    "turn end without transcript",
+			event:   GenericEventTurnEnd,
+			input:   `{"session_id": "run-42"}`,
+			wantErr: "transcript_path is required for turn-end",
+		},
+		{
+			name:    "relative transcript path",
+			event:   GenericEventCompaction,
+			input:   `{"session_id": "run-42", "transcript_path": "run-42.jsonl"}`,
+			wantErr: "must be an absolute path",
+		},
+		{
+			name:    "prompt outside turn start",
+			event:   GenericEventTurnEnd,
+			input:   `{"session_id": "run-42", "transcript_path": "/tmp/run-42.jsonl", "prompt": "Fix the bug"}`,
+			wantErr: "prompt is only accepted for turn-start",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			envelope, err := ParseGenericEvent(tt.event, []byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseGenericEvent() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseGenericEvent() error = %v", err)
+			}
+			if envelope.Version != GenericEventVersion {
+				t.Errorf("Version = %d, want %d", envelope.Version, GenericEventVersion)
+			}
+			if envelope.SessionID != "run-42" {
+				t.Errorf("SessionID = %q, want run-42", envelope.SessionID)
+			}
+		})
+	}
+}
+
+func TestParseGenericEvent_Fields(t *testing.T) {
+	t.Parallel()
+
+	envelope, err := ParseGenericEvent(GenericEventTurnEnd, []byte(`{"session_id": "run-42", "transcript_path": "/tmp/run-42.jsonl", "previous_session_id": "run-41", "timestamp": "2026-03-02T14:30:05Z", "metadata": {"model": "m1"}}`))
+	if err != nil {
+		t.Fatalf("ParseGenericEvent() error = %v", err)
+	}
+	if envelope.TranscriptPath != "/tmp/run-42.jsonl" || envelope.PreviousSessionID != "run-41" {
+		t.Errorf("envelope = %+v", envelope)
+	}
+	if want := time.Date(2026, 3, 2, 14, 30, 5, 0, time.UTC); envelope.Timestamp == nil || !envelope.Timestamp.Equal(want) {
+		t.Errorf("Timestamp = %v, want %v", envelope.Timestamp, want)
+	}
+	if envelope.Metadata["model"] != "m1" {
+		t.Errorf("Metadata = %v, want model m1", envelope.Metadata)
+	}
+}
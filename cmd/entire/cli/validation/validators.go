@@ -13,6 +13,13 @@ import (
 // Used to validate IDs that will be used in file paths.
 var pathSafeRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
+// ticketIDRegex matches issue and ticket references such as "ABC-123",
+// "#42" or "owner/repo#42".
+var ticketIDRegex = regexp.MustCompile(`^[a-zA-Z0-9#][a-zA-Z0-9_.#/:-]*$`)
+
+// maxTicketIDLength bounds ticket IDs, which are written to commit trailers.
+const maxTicketIDLength = 100
+
 // ValidateSessionID validates that a session ID doesn't contain path separators.
 // This prevents path traversal attacks when session IDs are used in file paths.
 func ValidateSessionID(id string) error {
@@ -60,3 +67,18 @@ func ValidateAgentSessionID(id string) error {
 	}
 	return nil
 }
+
+// ValidateTicketID validates a ticket or issue reference attached to a session.
+// Ticket IDs end up in commit trailers, so whitespace and line breaks are rejected.
+func ValidateTicketID(id string) error {
+	if id == "" {
+		return errors.New("ticket ID cannot be empty")
+	}
+	if len(id) > maxTicketIDLength {
+		return fmt.Errorf("invalid ticket ID %q: longer than %d characters", id, maxTicketIDLength)
+	}
+	if !ticketIDRegex.MatchString(id) {
+		return fmt.Errorf("invalid ticket ID %q: must be letters, digits, and _ . # / : - only", id)
+	}
+	return nil
+}
@@ -239,3 +239,30 @@ func TestValidateAgentSessionID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTicketID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "jira key", id: "ABC-123", wantErr: false},
+		{name: "github issue", id: "#42", wantErr: false},
+		{name: "qualified github issue", id: "entireio/cli#42", wantErr: false},
+		{name: "linear key", id: "ENG-7", wantErr: false},
+		{name: "empty rejected", id: "", wantErr: true},
+		{name: "space rejected", id: "ABC 123", wantErr: true},
+		{name: "newline rejected", id: "ABC-1\nEntire-Checkpoint: a1b2c3d4e5f6", wantErr: true},
+		{name: "leading dash rejected", id: "-ABC", wantErr: true},
+		{name: "too long rejected", id: strings.Repeat("A", 101), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTicketID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTicketID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			}
+		})
+	}
+}
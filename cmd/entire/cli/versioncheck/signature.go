@@ -0,0 +1,78 @@
+package versioncheck
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ReleasePublicKey is the minisign public key that signs the checksums of
+// every release. It is set at build time for production; without it,
+// `entire upgrade` refuses to install anything.
+var ReleasePublicKey = ""
+
+// minisign signature algorithms: "Ed" signs the message itself, "ED" signs
+// its BLAKE2b-512 hash (the default since minisign 0.10).
+const (
+	minisignAlgEd       = "Ed"
+	minisignAlgPrehash  = "ED"
+	minisignKeyIDLength = 8
+)
+
+// ErrNoReleaseKey is returned when the binary was built without a release
+// public key, so downloaded releases cannot be authenticated.
+var ErrNoReleaseKey = errors.New("this build has no release signing key; reinstall Entire CLI to upgrade")
+
+// verifyMinisign checks that sig is a valid minisign signature of message made
+// with the secret key for publicKey, including the signed trusted comment.
+func verifyMinisign(publicKey string, message, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != 2+minisignKeyIDLength+ed25519.PublicKeySize || string(key[:2]) != minisignAlgEd {
+		return errors.New("invalid release public key")
+	}
+	keyID, pub := key[2:2+minisignKeyIDLength], ed25519.PublicKey(key[2+minisignKeyIDLength:])
+
+	// A signature file has four lines: an untrusted comment, the signature,
+	// a trusted comment, and the global signature over signature+comment.
+	lines := strings.Split(strings.TrimRight(string(sig), "\r\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("malformed signature file")
+	}
+	sigBlob, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sigBlob) != 2+minisignKeyIDLength+ed25519.SignatureSize {
+		return errors.New("malformed signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return errors.New("malformed trusted comment signature")
+	}
+
+	alg, sigKeyID, signature := string(sigBlob[:2]), sigBlob[2:2+minisignKeyIDLength], sigBlob[2+minisignKeyIDLength:]
+	if !bytes.Equal(sigKeyID, keyID) {
+		return errors.New("signature was made with a different key")
+	}
+
+	signed := message
+	switch alg {
+	case minisignAlgEd:
+	case minisignAlgPrehash:
+		sum := blake2b.Sum512(message)
+		signed = sum[:]
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	if !ed25519.Verify(pub, signed, signature) {
+		return errors.New("signature verification failed")
+	}
+
+	trustedComment := strings.TrimSuffix(strings.TrimPrefix(lines[2], "trusted comment: "), "\r")
+	if !ed25519.Verify(pub, append(bytes.Clone(signature), trustedComment...), globalSig) {
+		return errors.New("trusted comment verification failed")
+	}
+	return nil
+}
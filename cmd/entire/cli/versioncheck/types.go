@@ -9,8 +9,15 @@ type VersionCache struct {
 
 // GitHubRelease represents the GitHub API response for a release.
 type GitHubRelease struct {
-	TagName    string `json:"tag_name"`
-	Prerelease bool   `json:"prerelease"`
+	TagName    string         `json:"tag_name"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset represents a downloadable file attached to a GitHub release.
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
 }
 
 // githubAPIURL is the GitHub API endpoint for fetching the latest release.
@@ -29,4 +36,26 @@ const (
 
 	// globalConfigDirName is the name of the global config directory in the user's home.
 	globalConfigDirName = ".config/entire"
+
+	// downloadTimeout is the timeout for downloading release assets during an upgrade.
+	downloadTimeout = 5 * time.Minute
+
+	// maxArchiveSize caps the size of a downloaded release archive.
+	maxArchiveSize = 200 << 20
+
+	// checksumsAssetName is the release asset listing SHA-256 checksums of all archives.
+	checksumsAssetName = "checksums.txt"
+
+	// signatureAssetName is the release asset holding the minisign signature of checksumsAssetName.
+	signatureAssetName = checksumsAssetName + ".minisig"
+
+	// goInstallCommand is the update instruction for binaries installed with `go install`.
+	goInstallCommand = "go install github.com/entireio/cli/cmd/entire@latest"
+
+	// binaryName is the name of the CLI binary inside release archives.
+	binaryName = "entire"
 )
+
+// OfflineEnvVar disables all network access by the CLI's update machinery
+// (version check notifications and `entire upgrade`) when set to a non-empty value.
+const OfflineEnvVar = "ENTIRE_OFFLINE"
@@ -0,0 +1,240 @@
+package versioncheck
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrOffline is returned when network access is disabled via OfflineEnvVar.
+var ErrOffline = errors.New("network access disabled (" + OfflineEnvVar + " is set)")
+
+// IsOffline reports whether the user disabled network access for update checks.
+func IsOffline() bool {
+	return os.Getenv(OfflineEnvVar) != ""
+}
+
+// Upgrade describes the latest release and the assets needed to install it on
+// the current platform.
+type Upgrade struct {
+	CurrentVersion string
+	LatestVersion  string
+	ArchiveName    string
+	ArchiveURL     string
+	ChecksumsURL   string
+	SignatureURL   string
+}
+
+// Available reports whether the latest release is newer than the running binary.
+func (u *Upgrade) Available() bool {
+	return isOutdated(u.CurrentVersion, u.LatestVersion)
+}
+
+// ArchiveName returns the release archive name for a platform, matching the
+// archive name_template in .goreleaser.yaml.
+func ArchiveName(goos, goarch string) string {
+	return fmt.Sprintf("%s_%s_%s.tar.gz", binaryName, goos, goarch)
+}
+
+// CheckForUpgrade looks up the latest release and the download URLs for the
+// current platform. Returns ErrOffline if network access is disabled.
+func CheckForUpgrade(ctx context.Context, currentVersion string) (*Upgrade, error) {
+	if IsOffline() {
+		return nil, ErrOffline
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	body, err := httpGet(ctx, githubAPIURL, "application/vnd.github+json", 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("fetching release info: %w", err)
+	}
+	release, err := decodeGitHubRelease(body)
+	if err != nil {
+		return nil, fmt.Errorf("parsing release: %w", err)
+	}
+
+	upgrade := &Upgrade{
+		CurrentVersion: currentVersion,
+		LatestVersion:  release.TagName,
+		ArchiveName:    ArchiveName(runtime.GOOS, runtime.GOARCH),
+	}
+	for _, asset := range release.Assets {
+		switch asset.Name {
+		case upgrade.ArchiveName:
+			upgrade.ArchiveURL = asset.BrowserDownloadURL
+		case checksumsAssetName:
+			upgrade.ChecksumsURL = asset.BrowserDownloadURL
+		case signatureAssetName:
+			upgrade.SignatureURL = asset.BrowserDownloadURL
+		}
+	}
+	if upgrade.ArchiveURL == "" {
+		return nil, fmt.Errorf("release %s has no archive for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	if upgrade.ChecksumsURL == "" {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, checksumsAssetName)
+	}
+	if upgrade.SignatureURL == "" {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, signatureAssetName)
+	}
+
+	return upgrade, nil
+}
+
+// InstallUpgrade downloads the release archive, verifies it against the
+// release's published SHA-256 checksums, whose minisign signature must match
+// ReleasePublicKey, and atomically replaces the binary at execPath with the one
+// from the archive.
+func InstallUpgrade(ctx context.Context, upgrade *Upgrade, execPath string) error {
+	if IsOffline() {
+		return ErrOffline
+	}
+	if ReleasePublicKey == "" {
+		return ErrNoReleaseKey
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	checksums, err := httpGet(ctx, upgrade.ChecksumsURL, "", 1<<20)
+	if err != nil {
+		return fmt.Errorf("downloading checksums: %w", err)
+	}
+	signature, err := httpGet(ctx, upgrade.SignatureURL, "", 1<<20)
+	if err != nil {
+		return fmt.Errorf("downloading checksums signature: %w", err)
+	}
+	if err := verifyMinisign(ReleasePublicKey, checksums, signature); err != nil {
+		return fmt.Errorf("verifying %s: %w", checksumsAssetName, err)
+	}
+	expected, err := findChecksum(checksums, upgrade.ArchiveName)
+	if err != nil {
+		return err
+	}
+
+	archive, err := httpGet(ctx, upgrade.ArchiveURL, "", maxArchiveSize)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", upgrade.ArchiveName, err)
+	}
+	sum := sha256.Sum256(archive)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", upgrade.ArchiveName, expected, actual)
+	}
+
+	binary, err := extractBinary(archive)
+	if err != nil {
+		return err
+	}
+
+	return replaceBinary(execPath, binary)
+}
+
+// IsHomebrewInstall reports whether the binary at execPath is managed by Homebrew,
+// in which case it must be upgraded with `brew upgrade` instead.
+func IsHomebrewInstall(execPath string) bool {
+	realPath, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		realPath = execPath
+	}
+	return isHomebrewPath(realPath)
+}
+
+// IsGoInstall reports whether the binary at execPath was installed with
+// `go install`, in which case it should be upgraded the same way.
+func IsGoInstall(execPath string) bool {
+	realPath, err := filepath.EvalSymlinks(execPath)
+	if err != nil {
+		realPath = execPath
+	}
+	return isGoInstallPath(realPath)
+}
+
+// findChecksum returns the SHA-256 checksum for name from a checksums.txt file
+// in `sha256sum` format ("<hex>  <name>" per line).
+func findChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading checksums: %w", err)
+	}
+	return "", fmt.Errorf("no checksum published for %s", name)
+}
+
+// extractBinary returns the CLI binary from a gzipped release tarball.
+func extractBinary(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("archive does not contain %s binary", binaryName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg || filepath.Base(header.Name) != binaryName {
+			continue
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxArchiveSize))
+		if err != nil {
+			return nil, fmt.Errorf("extracting %s: %w", binaryName, err)
+		}
+		return data, nil
+	}
+}
+
+// replaceBinary atomically replaces the file at execPath with binary.
+// The new binary is written to a temp file in the same directory and renamed
+// over the old one, so a failed upgrade never leaves a partial binary behind.
+func replaceBinary(execPath string, binary []byte) error {
+	dir := filepath.Dir(execPath)
+	tmpFile, err := os.CreateTemp(dir, ".entire-upgrade-")
+	if err != nil {
+		return fmt.Errorf("creating temp file next to %s: %w", execPath, err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(binary); err != nil {
+		_ = tmpFile.Close() // cleanup on error path
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close() // cleanup on error path
+		return fmt.Errorf("syncing new binary: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing temp file: %w", err)
+	}
+
+	//nolint:gosec // the CLI binary must be executable
+	if err := os.Chmod(tmpFile.Name(), 0o755); err != nil {
+		return fmt.Errorf("setting permissions on new binary: %w", err)
+	}
+	if err := os.Rename(tmpFile.Name(), execPath); err != nil {
+		return fmt.Errorf("replacing %s: %w", execPath, err)
+	}
+	return nil
+}
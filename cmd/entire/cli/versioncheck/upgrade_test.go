@@ -0,0 +1,294 @@
+package versioncheck
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// buildTestArchive returns a gzipped tarball containing an "entire" binary with the given content.
+func buildTestArchive(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string]string{"README.md": "readme", binaryName: content} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testSigningKey is a minisign key pair used to sign test releases.
+type testSigningKey struct {
+	keyID   []byte
+	private ed25519.PrivateKey
+}
+
+func newTestSigningKey(t *testing.T, keyID string) *testSigningKey {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testSigningKey{keyID: []byte(keyID), private: private}
+}
+
+// publicKey returns the key in minisign's base64 public key format.
+func (k *testSigningKey) publicKey() string {
+	public, _ := k.private.Public().(ed25519.PublicKey)
+	return base64.StdEncoding.EncodeToString(slices.Concat([]byte(minisignAlgEd), k.keyID, public))
+}
+
+// sign returns a prehashed minisign signature file for message.
+func (k *testSigningKey) sign(message []byte, trustedComment string) []byte {
+	hash := blake2b.Sum512(message)
+	signature := ed25519.Sign(k.private, hash[:])
+	globalSig := ed25519.Sign(k.private, slices.Concat(signature, []byte(trustedComment)))
+	return fmt.Appendf(nil, "untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(slices.Concat([]byte(minisignAlgPrehash), k.keyID, signature)),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig))
+}
+
+// setupReleaseServer serves a release with an archive for the current platform,
+// with checksums signed by a key that ReleasePublicKey is set to.
+// checksum overrides the published checksum when non-empty.
+func setupReleaseServer(t *testing.T, archive []byte, checksum string) {
+	t.Helper()
+	archiveName := ArchiveName(runtime.GOOS, runtime.GOARCH)
+	if checksum == "" {
+		sum := sha256.Sum256(archive)
+		checksum = hex.EncodeToString(sum[:])
+	}
+	checksums := fmt.Sprintf("%s  entire_other_arch.tar.gz\n%s  %s\n", strings.Repeat("0", 64), checksum, archiveName)
+
+	key := newTestSigningKey(t, "testkey1")
+	originalKey := ReleasePublicKey
+	ReleasePublicKey = key.publicKey()
+	t.Cleanup(func() { ReleasePublicKey = originalKey })
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/release":
+			fmt.Fprintf(w, `{"tag_name":"v2.0.0","prerelease":false,"assets":[
+				{"name":%q,"browser_download_url":%q},
+				{"name":"checksums.txt","browser_download_url":%q},
+				{"name":"checksums.txt.minisig","browser_download_url":%q}]}`,
+				archiveName, server.URL+"/archive", server.URL+"/checksums", server.URL+"/signature")
+		case "/archive":
+			_, _ = w.Write(archive)
+		case "/checksums":
+			_, _ = w.Write([]byte(checksums))
+		case "/signature":
+			_, _ = w.Write(key.sign([]byte(checksums), "timestamp:1700000000"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	original := githubAPIURL
+	githubAPIURL = server.URL + "/release"
+	t.Cleanup(func() { githubAPIURL = original })
+}
+
+func TestCheckForUpgrade(t *testing.T) {
+	setupReleaseServer(t, buildTestArchive(t, "new binary"), "")
+
+	upgrade, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckForUpgrade() error = %v", err)
+	}
+	if upgrade.LatestVersion != "v2.0.0" {
+		t.Errorf("LatestVersion = %q, want %q", upgrade.LatestVersion, "v2.0.0")
+	}
+	if !upgrade.Available() {
+		t.Error("Available() = false, want true")
+	}
+	if !strings.HasSuffix(upgrade.ArchiveURL, "/archive") || !strings.HasSuffix(upgrade.ChecksumsURL, "/checksums") {
+		t.Errorf("unexpected asset URLs: %+v", upgrade)
+	}
+}
+
+func TestInstallUpgrade_ReplacesBinary(t *testing.T) {
+	setupReleaseServer(t, buildTestArchive(t, "new binary"), "")
+
+	execPath := filepath.Join(t.TempDir(), "entire")
+	if err := os.WriteFile(execPath, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	upgrade, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckForUpgrade() error = %v", err)
+	}
+	if err := InstallUpgrade(context.Background(), upgrade, execPath); err != nil {
+		t.Fatalf("InstallUpgrade() error = %v", err)
+	}
+
+	data, err := os.ReadFile(execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new binary" {
+		t.Errorf("binary content = %q, want %q", data, "new binary")
+	}
+	info, err := os.Stat(execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("new binary is not executable: %v", info.Mode())
+	}
+
+	// No temp files left behind
+	entries, err := os.ReadDir(filepath.Dir(execPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the binary in the directory, got %d entries", len(entries))
+	}
+}
+
+func TestInstallUpgrade_ChecksumMismatch(t *testing.T) {
+	setupReleaseServer(t, buildTestArchive(t, "tampered binary"), strings.Repeat("a", 64))
+
+	execPath := filepath.Join(t.TempDir(), "entire")
+	if err := os.WriteFile(execPath, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	upgrade, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckForUpgrade() error = %v", err)
+	}
+	err = InstallUpgrade(context.Background(), upgrade, execPath)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("InstallUpgrade() error = %v, want checksum mismatch", err)
+	}
+
+	data, err := os.ReadFile(execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old binary" {
+		t.Errorf("binary was modified despite checksum mismatch: %q", data)
+	}
+}
+
+func TestInstallUpgrade_UntrustedSignature(t *testing.T) {
+	setupReleaseServer(t, buildTestArchive(t, "new binary"), "")
+	ReleasePublicKey = newTestSigningKey(t, "otherkey").publicKey()
+
+	execPath := filepath.Join(t.TempDir(), "entire")
+	if err := os.WriteFile(execPath, []byte("old binary"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	upgrade, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckForUpgrade() error = %v", err)
+	}
+	err = InstallUpgrade(context.Background(), upgrade, execPath)
+	if err == nil || !strings.Contains(err.Error(), "verifying checksums.txt") {
+		t.Fatalf("InstallUpgrade() error = %v, want signature verification error", err)
+	}
+
+	data, err := os.ReadFile(execPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "old binary" {
+		t.Errorf("binary was modified despite untrusted signature: %q", data)
+	}
+}
+
+func TestInstallUpgrade_NoReleaseKey(t *testing.T) {
+	setupReleaseServer(t, buildTestArchive(t, "new binary"), "")
+	ReleasePublicKey = ""
+
+	upgrade, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckForUpgrade() error = %v", err)
+	}
+	err = InstallUpgrade(context.Background(), upgrade, filepath.Join(t.TempDir(), "entire"))
+	if !errors.Is(err, ErrNoReleaseKey) {
+		t.Fatalf("InstallUpgrade() error = %v, want ErrNoReleaseKey", err)
+	}
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	t.Parallel()
+	key := newTestSigningKey(t, "testkey1")
+	message := []byte("abc123  entire_linux_amd64.tar.gz\n")
+	signature := key.sign(message, "timestamp:1700000000")
+
+	if err := verifyMinisign(key.publicKey(), message, signature); err != nil {
+		t.Fatalf("verifyMinisign() error = %v", err)
+	}
+	if err := verifyMinisign(key.publicKey(), []byte("def456  entire_linux_amd64.tar.gz\n"), signature); err == nil {
+		t.Error("verifyMinisign() accepted a tampered message")
+	}
+	tamperedComment := bytes.Replace(signature, []byte("timestamp:1700000000"), []byte("timestamp:1800000000"), 1)
+	if err := verifyMinisign(key.publicKey(), message, tamperedComment); err == nil {
+		t.Error("verifyMinisign() accepted a tampered trusted comment")
+	}
+	if err := verifyMinisign(newTestSigningKey(t, "testkey1").publicKey(), message, signature); err == nil {
+		t.Error("verifyMinisign() accepted a signature from another key")
+	}
+}
+
+func TestCheckForUpgrade_Offline(t *testing.T) {
+	t.Setenv(OfflineEnvVar, "1")
+
+	_, err := CheckForUpgrade(context.Background(), "1.0.0")
+	if !errors.Is(err, ErrOffline) {
+		t.Fatalf("CheckForUpgrade() error = %v, want ErrOffline", err)
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte("abc123  entire_linux_amd64.tar.gz\nDEF456 *entire_darwin_arm64.tar.gz\n")
+
+	got, err := findChecksum(checksums, "entire_darwin_arm64.tar.gz")
+	if err != nil {
+		t.Fatalf("findChecksum() error = %v", err)
+	}
+	if got != "def456" {
+		t.Errorf("findChecksum() = %q, want %q", got, "def456")
+	}
+
+	if _, err := findChecksum(checksums, "entire_windows_amd64.tar.gz"); err == nil {
+		t.Error("findChecksum() expected error for missing archive")
+	}
+}
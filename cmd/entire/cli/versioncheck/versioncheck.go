@@ -25,6 +25,11 @@ func CheckAndNotify(w io.Writer, currentVersion string) {
 		return
 	}
 
+	// Skip checks when the user asked for no network access
+	if IsOffline() {
+		return
+	}
+
 	// Ensure the global config directory exists
 	if err := ensureGlobalConfigDir(); err != nil {
 		// Silent failure - don't block CLI operations
@@ -164,60 +169,85 @@ func fetchLatestVersion() (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIURL, nil)
+	// Read response body (limit to 1MB to prevent memory exhaustion)
+	body, err := httpGet(ctx, githubAPIURL, "application/vnd.github+json", 1<<20)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return "", fmt.Errorf("fetching release info: %w", err)
+	}
+
+	// Parse GitHub release response
+	version, err := parseGitHubRelease(body)
+	if err != nil {
+		return "", fmt.Errorf("parsing release: %w", err)
+	}
+
+	return version, nil
+}
+
+// httpGet fetches url and returns the response body. Bodies larger than limit
+// bytes are rejected to prevent memory exhaustion.
+func httpGet(ctx context.Context, url, accept string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	// Set headers to identify as Entire CLI
-	req.Header.Set("Accept", "application/vnd.github+json")
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
 	req.Header.Set("User-Agent", "entire-cli")
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("fetching release info: %w", err)
+		return nil, fmt.Errorf("requesting %s: %w", url, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Read response body (limit to 1MB to prevent memory exhaustion)
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
-
-	// Parse GitHub release response
-	version, err := parseGitHubRelease(body)
-	if err != nil {
-		return "", fmt.Errorf("parsing release: %w", err)
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
 	}
 
-	return version, nil
+	return body, nil
 }
 
 // parseGitHubRelease parses the GitHub API response and extracts the latest stable version.
 // Filters out prerelease versions.
 func parseGitHubRelease(body []byte) (string, error) {
+	release, err := decodeGitHubRelease(body)
+	if err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// decodeGitHubRelease parses the GitHub API response for a stable release.
+func decodeGitHubRelease(body []byte) (*GitHubRelease, error) {
 	var release GitHubRelease
 	if err := json.Unmarshal(body, &release); err != nil {
-		return "", fmt.Errorf("parsing JSON: %w", err)
+		return nil, fmt.Errorf("parsing JSON: %w", err)
 	}
 
 	// Skip prerelease versions
 	if release.Prerelease {
-		return "", errors.New("only prerelease versions available")
+		return nil, errors.New("only prerelease versions available")
 	}
 
 	// Ensure we have a tag name
 	if release.TagName == "" {
-		return "", errors.New("empty tag name")
+		return nil, errors.New("empty tag name")
 	}
 
-	return release.TagName, nil
+	return &release, nil
 }
 
 // isOutdated compares current and latest versions using semantic versioning.
@@ -248,11 +278,48 @@ func updateCommand() string {
 		realPath = execPath
 	}
 
-	if strings.Contains(realPath, "/Cellar/") || strings.Contains(realPath, "/homebrew/") {
+	if isHomebrewPath(realPath) {
 		return "brew upgrade entire"
 	}
+	if isGoInstallPath(realPath) {
+		return goInstallCommand
+	}
+
+	return "entire upgrade"
+}
+
+// isHomebrewPath reports whether a resolved binary path belongs to a Homebrew installation.
+func isHomebrewPath(realPath string) bool {
+	return strings.Contains(realPath, "/Cellar/") || strings.Contains(realPath, "/homebrew/")
+}
 
-	return "curl -fsSL https://entire.io/install.sh | bash"
+// isGoInstallPath reports whether a resolved binary path is in a directory
+// `go install` writes to: $GOBIN, or bin/ under each $GOPATH entry (~/go by default).
+func isGoInstallPath(realPath string) bool {
+	var dirs []string
+	if gobin := os.Getenv("GOBIN"); gobin != "" {
+		dirs = append(dirs, gobin)
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			gopath = filepath.Join(home, "go")
+		}
+	}
+	for _, p := range filepath.SplitList(gopath) {
+		dirs = append(dirs, filepath.Join(p, "bin"))
+	}
+
+	dir := filepath.Dir(realPath)
+	for _, d := range dirs {
+		if resolved, err := filepath.EvalSymlinks(d); err == nil {
+			d = resolved
+		}
+		if dir == filepath.Clean(d) {
+			return true
+		}
+	}
+	return false
 }
 
 // printNotification prints the version update notification to the user.
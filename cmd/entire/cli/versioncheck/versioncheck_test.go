@@ -231,11 +231,13 @@ func TestParseGitHubRelease(t *testing.T) {
 }
 
 func TestUpdateCommand(t *testing.T) {
-	// updateCommand should return one of the two valid update commands
+	// updateCommand should return one of the valid update commands
 	cmd := updateCommand()
 
 	validCommands := map[string]bool{
-		"brew upgrade entire":                            true,
+		"brew upgrade entire": true,
+		"entire upgrade":      true,
+		goInstallCommand:      true,
 		"curl -fsSL https://entire.io/install.sh | bash": true,
 	}
 
@@ -298,6 +300,18 @@ func TestCheckAndNotify_SkipsEmptyVersion(t *testing.T) {
 	}
 }
 
+func TestCheckAndNotify_SkipsWhenOffline(t *testing.T) {
+	server := newVersionServer(t, "v9.9.9")
+	cmd, buf := setupCheckAndNotifyTest(t, server.URL)
+	t.Setenv(OfflineEnvVar, "1")
+
+	CheckAndNotify(cmd.OutOrStdout(), "1.0.0")
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no output when offline, got %q", buf.String())
+	}
+}
+
 func TestCheckAndNotify_SkipsWhenCacheIsFresh(t *testing.T) {
 	server := newVersionServer(t, "v9.9.9")
 	cmd, buf := setupCheckAndNotifyTest(t, server.URL)
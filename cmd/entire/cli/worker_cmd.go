@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorkerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Run work deferred by hooks",
+	}
+
+	cmd.AddCommand(newWorkerRunOnceCmd())
+	cmd.AddCommand(newWorkerPushCmd())
+
+	return cmd
+}
+
+func newWorkerRunOnceCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run-once",
+		Short: "Condense commits queued by the post-commit hook",
+		Long: `Condense every commit queued by the post-commit hook, oldest first, then exit.
+
+With "strategy_options": {"async_condensation": true}, the post-commit hook
+only queues the commit under .git/entire/queue instead of condensing session
+data into it. The queue is drained by the next prepare-commit-msg, pre-push,
+or agent hook, or by running this command (e.g. from a scheduler). A lock
+ensures only one worker condenses at a time: hooks skip the queue while it
+is held, and this command waits up to 30 seconds for it. Each job is removed
+once handled, so no commit is condensed twice.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := strategy.GetGitCommonDir(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			n, err := strategy.RunCondensationQueue()
+			if err != nil {
+				return fmt.Errorf("failed to condense queued commits: %w", err)
+			}
+			if n == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No queued commits.")
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Condensed %d queued commit(s).\n", n)
+			return nil
+		},
+	}
+}
+
+func newWorkerPushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "push",
+		Short: "Push condensed checkpoints to origin",
+		Long: `Push the ` + paths.MetadataBranchName() + ` branch to origin, merging checkpoints
+another machine pushed first and retrying network errors with backoff.
+
+With "strategy_options": {"push_on_condense": true}, the post-commit hook
+starts this command in the background after condensing, so the commit does
+not wait for the network.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := strategy.GetGitCommonDir(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			strategy.PushCondensedCheckpoints()
+			return nil
+		},
+	}
+}
+
+// drainCondensationQueue condenses commits queued by an async post-commit
+// hook before a hook does its own work, so sessions are condensed in commit
+// order. If another worker is already draining the queue it returns at once.
+// Failures are logged; hooks never fail or wait because of the queue.
+func drainCondensationQueue() {
+	s, err := LoadEntireSettings()
+	if err != nil || !s.IsAsyncCondensationEnabled() {
+		return
+	}
+	if _, err := strategy.TryCondensationQueue(); err != nil {
+		logging.Warn(logging.WithComponent(context.Background(), "hooks"), "failed to condense queued commits",
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorktreeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "worktree",
+		Aliases: []string{"worktrees"},
+		Short:   "Inspect and clean up Entire data per git worktree",
+	}
+
+	cmd.AddCommand(newWorktreeListCmd())
+	cmd.AddCommand(newWorktreeCleanCmd())
+
+	return cmd
+}
+
+func newWorktreeListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List worktrees with their sessions and shadow branches",
+		Long: `List the main worktree, the linked worktrees git knows about, and any removed
+worktree that still has sessions or shadow branches. Shadow branches record a
+worktree only by a hash of its name, so a worktree git has already pruned is
+listed by that hash.
+
+Status is "ok", "missing" when the worktree's directory is gone but git still
+lists it, or "removed" when git no longer lists it. Use
+'entire worktree clean <worktree>' to drop what a removed worktree left behind.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := openRepository(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			return runWorktreeList(cmd.OutOrStdout())
+		},
+	}
+}
+
+func newWorktreeCleanCmd() *cobra.Command {
+	var forceFlag bool
+
+	cmd := &cobra.Command{
+		Use:   "clean <worktree>",
+		Short: "Remove the sessions and shadow branches of a removed worktree",
+		Long: `Remove the session states and shadow branches a removed worktree left behind.
+<worktree> is a name or hash shown by 'entire worktree list'. Worktrees that
+still exist cannot be cleaned: remove them with 'git worktree remove' first.
+Checkpoints on entire/checkpoints/v1 are never removed.
+
+Default: shows a preview of items that would be deleted.
+With --force, actually deletes them.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := openRepository(); err != nil {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), "Not a git repository.")
+				return NewSilentError(errors.New("not a git repository"))
+			}
+			err := runWorktreeClean(cmd.OutOrStdout(), args[0], forceFlag)
+			var userErr *worktreeCleanError
+			if errors.As(err, &userErr) {
+				cmd.SilenceUsage = true
+				fmt.Fprintln(cmd.ErrOrStderr(), err)
+				return NewSilentError(err)
+			}
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Actually delete items (default: dry run)")
+
+	return cmd
+}
+
+// worktreeCleanError is a worktree that cannot be cleaned as asked.
+type worktreeCleanError struct {
+	err error
+}
+
+func (e *worktreeCleanError) Error() string { return e.err.Error() }
+func (e *worktreeCleanError) Unwrap() error { return e.err }
+
+func runWorktreeList(w io.Writer) error {
+	worktrees, err := strategy.ListWorktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	labelWidth := len("WORKTREE")
+	pathWidth := len("PATH")
+	for _, wt := range worktrees {
+		labelWidth = max(labelWidth, len(wt.Label()))
+		pathWidth = max(pathWidth, len(worktreePathLabel(wt)))
+	}
+
+	stale := false
+	fmt.Fprintf(w, "%-*s  %-*s  %-7s  %8s  %6s\n", labelWidth, "WORKTREE", pathWidth, "PATH", "STATUS", "SESSIONS", "SHADOW")
+	for _, wt := range worktrees {
+		stale = stale || wt.Stale()
+		fmt.Fprintf(w, "%-*s  %-*s  %-7s  %8d  %6d\n",
+			labelWidth, wt.Label(),
+			pathWidth, worktreePathLabel(wt),
+			worktreeStatusLabel(wt),
+			len(wt.Sessions),
+			len(wt.ShadowBranches))
+	}
+
+	for _, wt := range worktrees {
+		for _, state := range wt.Mismatched {
+			fmt.Fprintf(w, "\nWarning: session %s was started in worktree %s, but %s now belongs to another worktree.\n",
+				state.SessionID, wt.Label(), state.WorktreePath)
+		}
+	}
+	if stale {
+		fmt.Fprintln(w, "\nRun 'entire worktree clean <worktree>' to remove data left by removed worktrees.")
+	}
+	return nil
+}
+
+func runWorktreeClean(w io.Writer, label string, force bool) error {
+	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
+	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
+	logging.SetLogLevelGetter(GetLogLevel)
+	logging.SetUserNamespaceGetter(settings.StateUserNamespace)
+	if err := logging.Init(""); err == nil {
+		defer logging.Close()
+	}
+
+	worktrees, err := strategy.ListWorktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	wt, err := strategy.FindWorktree(worktrees, label)
+	if err != nil {
+		return &worktreeCleanError{err: err}
+	}
+	if wt.Registered && !wt.Missing {
+		return &worktreeCleanError{err: fmt.Errorf("worktree %s still exists at %s; remove it with 'git worktree remove' first", wt.Label(), wt.Path)}
+	}
+
+	items := strategy.WorktreeCleanupItems(wt)
+	if len(items) == 0 {
+		fmt.Fprintf(w, "Worktree %s has no sessions or shadow branches.\n", wt.Label())
+		return nil
+	}
+
+	if !force {
+		sessions := make([]string, 0, len(wt.Sessions))
+		for _, state := range wt.Sessions {
+			sessions = append(sessions, state.SessionID)
+		}
+		fmt.Fprintf(w, "Found %d items for worktree %s:\n\n", len(items), wt.Label())
+		writeGCSection(w, "Session states", sessions)
+		writeGCSection(w, "Shadow branches", wt.ShadowBranches)
+		fmt.Fprintln(w, "Run with --force to delete these items.")
+		return nil
+	}
+
+	result, err := strategy.DeleteAllCleanupItems(items)
+	if err != nil {
+		return fmt.Errorf("failed to delete items: %w", err)
+	}
+	if deleted := len(result.SessionStates) + len(result.ShadowBranches); deleted > 0 {
+		fmt.Fprintf(w, "Deleted %d items for worktree %s:\n\n", deleted, wt.Label())
+		writeGCSection(w, "Session states", result.SessionStates)
+		writeGCSection(w, "Shadow branches", result.ShadowBranches)
+	}
+	if failed := len(result.FailedStates) + len(result.FailedBranches); failed > 0 {
+		fmt.Fprintf(w, "Failed to delete %d items:\n\n", failed)
+		writeGCSection(w, "Session states", result.FailedStates)
+		writeGCSection(w, "Shadow branches", result.FailedBranches)
+		return fmt.Errorf("failed to delete %d items", failed)
+	}
+	if wt.Registered {
+		fmt.Fprintln(w, "Run 'git worktree prune' to let git forget the worktree too.")
+	}
+	return nil
+}
+
+func worktreePathLabel(wt *strategy.WorktreeInfo) string {
+	if wt.Path == "" {
+		return "-"
+	}
+	return wt.Path
+}
+
+func worktreeStatusLabel(wt *strategy.WorktreeInfo) string {
+	switch {
+	case !wt.Registered:
+		return "removed"
+	case wt.Missing:
+		return "missing"
+	default:
+		return "ok"
+	}
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupWorktreeTestRepo creates a repo with a linked worktree named "wt"
+// that has a session and a shadow branch. Returns the worktree's path and
+// its shadow branch.
+func setupWorktreeTestRepo(t *testing.T) (worktreePath, shadowBranch string) {
+	t.Helper()
+	repo, commitHash := setupCleanTestRepo(t)
+	repoDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	worktreePath = filepath.Join(t.TempDir(), "wt")
+	gitForSyncTest(t, repoDir, "worktree", "add", "-q", "-b", "wt", worktreePath)
+
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	require.NoError(t, store.Save(context.Background(), &session.State{
+		SessionID:    "2026-04-01-wt",
+		BaseCommit:   commitHash.String(),
+		WorktreePath: worktreePath,
+		WorktreeID:   "wt",
+		StartedAt:    time.Now(),
+	}))
+
+	shadowBranch = checkpoint.ShadowBranchNameForCommit(commitHash.String(), "wt")
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(shadowBranch), commitHash)))
+	return worktreePath, shadowBranch
+}
+
+func TestRunWorktreeList(t *testing.T) {
+	worktreePath, _ := setupWorktreeTestRepo(t)
+
+	var out bytes.Buffer
+	require.NoError(t, runWorktreeList(&out))
+	assert.Contains(t, out.String(), "main")
+	assert.Regexp(t, `wt\s+\S+\s+ok\s+1\s+1`, out.String())
+	assert.NotContains(t, out.String(), "entire worktree clean")
+
+	require.NoError(t, os.RemoveAll(worktreePath))
+	out.Reset()
+	require.NoError(t, runWorktreeList(&out))
+	assert.Regexp(t, `wt\s+\S+\s+missing\s+1\s+1`, out.String())
+	assert.Contains(t, out.String(), "entire worktree clean <worktree>")
+}
+
+func TestRunWorktreeClean(t *testing.T) {
+	worktreePath, shadowBranch := setupWorktreeTestRepo(t)
+
+	var out bytes.Buffer
+	err := runWorktreeClean(&out, "wt", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "still exists")
+
+	err = runWorktreeClean(&out, "main", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "main worktree")
+
+	require.NoError(t, os.RemoveAll(worktreePath))
+
+	require.NoError(t, runWorktreeClean(&out, "wt", false))
+	assert.Contains(t, out.String(), "Found 2 items for worktree wt")
+	assert.Contains(t, out.String(), shadowBranch)
+	assert.Contains(t, out.String(), "Run with --force")
+
+	out.Reset()
+	require.NoError(t, runWorktreeClean(&out, "wt", true))
+	assert.Contains(t, out.String(), "Deleted 2 items for worktree wt")
+	assert.Contains(t, out.String(), "git worktree prune")
+
+	branches, err := strategy.ListShadowBranches()
+	require.NoError(t, err)
+	assert.NotContains(t, branches, shadowBranch)
+	store, err := session.NewStateStore()
+	require.NoError(t, err)
+	state, err := store.Load(context.Background(), "2026-04-01-wt")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+
+	out.Reset()
+	require.NoError(t, runWorktreeClean(&out, "wt", true))
+	assert.Equal(t, "Worktree wt has no sessions or shadow branches.\n", out.String())
+}
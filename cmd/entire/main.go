@@ -14,6 +14,16 @@ import (
 )
 
 func main() {
+	// Run as a git hook when started through a hooks dispatcher link
+	if name := cli.DispatchedGitHookName(os.Args[0]); name != "" {
+		os.Exit(cli.RunDispatchedGitHook(name, os.Args[1:]))
+	}
+
+	// Hand hooks to a running hook daemon (entire daemon --serve-hooks)
+	if code, ok := cli.ForwardHookToDaemon(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	// Create context that cancels on interrupt
 	ctx, cancel := context.WithCancel(context.Background())
 
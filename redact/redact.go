@@ -40,14 +40,84 @@ func getDetector() *detect.Detector {
 	return gitleaksDetector
 }
 
-// region represents a byte range to redact.
-type region struct{ start, end int }
+// region represents a byte range to redact and the rule that found it.
+type region struct {
+	start, end int
+	rule       string
+}
+
+// Finding is a secret detected by Scan or ScanJSONL. It names the rule that
+// matched but not the secret itself, so findings are safe to print.
+type Finding struct {
+	// Rule is RuleEntropy, RuleEnvAssignment, "gitleaks:<rule id>", or the
+	// name of a custom rule.
+	Rule string
+	// Line is the 1-based line the secret starts on.
+	Line int
+}
 
 // String replaces secrets in s with "REDACTED" using layered detection:
 // 1. Entropy-based: high-entropy alphanumeric sequences (threshold 4.5)
-// 2. Pattern-based: gitleaks regex rules (180+ known secret formats)
-// A string is redacted if EITHER method flags it.
+// 2. Assignment-based: values of .env-style SECRET/TOKEN/PASSWORD variables
+// 3. Pattern-based: gitleaks regex rules (180+ known secret formats)
+// 4. Custom patterns from the configured rules (see SetRulesGetter)
+// A string is redacted if ANY method flags it, unless the secret matches an
+// allow pattern.
 func String(s string) string {
+	regions := findSecrets(s)
+	if len(regions) == 0 {
+		return s
+	}
+
+	// Merge overlapping regions and build result.
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].start < regions[j].start
+	})
+	merged := []region{regions[0]}
+	for _, r := range regions[1:] {
+		last := &merged[len(merged)-1]
+		if r.start <= last.end {
+			if r.end > last.end {
+				last.end = r.end
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, r := range merged {
+		b.WriteString(s[prev:r.start])
+		b.WriteString("REDACTED")
+		prev = r.end
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
+
+// Scan returns the secrets String would redact in s, in order. A secret
+// found by several rules is reported once per rule.
+func Scan(s string) []Finding {
+	regions := findSecrets(s)
+	sort.SliceStable(regions, func(i, j int) bool {
+		return regions[i].start < regions[j].start
+	})
+	findings := make([]Finding, 0, len(regions))
+	seen := make(map[region]bool)
+	for _, r := range regions {
+		if seen[r] {
+			continue
+		}
+		seen[r] = true
+		findings = append(findings, Finding{Rule: r.rule, Line: strings.Count(s[:r.start], "\n") + 1})
+	}
+	return findings
+}
+
+// findSecrets returns the regions of s flagged by any rule, unsorted.
+func findSecrets(s string) []region {
+	rules := currentRules()
 	var regions []region
 
 	// 1. Entropy-based detection.
@@ -70,12 +140,17 @@ func String(s string) string {
 			}
 		}
 
-		if shannonEntropy(s[start:end]) > entropyThreshold {
-			regions = append(regions, region{start, end})
+		if shannonEntropy(s[start:end]) > rules.entropyThreshold {
+			regions = append(regions, region{start, end, RuleEntropy})
 		}
 	}
 
-	// 2. Pattern-based detection via gitleaks.
+	// 2. Assignment-based detection.
+	for _, loc := range envAssignmentPattern.FindAllStringSubmatchIndex(s, -1) {
+		regions = append(regions, region{loc[2], loc[3], RuleEnvAssignment})
+	}
+
+	// 3. Pattern-based detection via gitleaks.
 	if d := getDetector(); d != nil {
 		for _, f := range d.DetectString(s) {
 			if f.Secret == "" {
@@ -88,41 +163,37 @@ func String(s string) string {
 					break
 				}
 				absIdx := searchFrom + idx
-				regions = append(regions, region{absIdx, absIdx + len(f.Secret)})
+				regions = append(regions, region{absIdx, absIdx + len(f.Secret), "gitleaks:" + f.RuleID})
 				searchFrom = absIdx + len(f.Secret)
 			}
 		}
 	}
 
-	if len(regions) == 0 {
-		return s
-	}
-
-	// Merge overlapping regions and build result.
-	sort.Slice(regions, func(i, j int) bool {
-		return regions[i].start < regions[j].start
-	})
-	merged := []region{regions[0]}
-	for _, r := range regions[1:] {
-		last := &merged[len(merged)-1]
-		if r.start <= last.end {
-			if r.end > last.end {
-				last.end = r.end
+	// 4. Custom patterns.
+	for _, rule := range rules.patterns {
+		for _, loc := range rule.re.FindAllStringSubmatchIndex(s, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 {
+				if loc[2] < 0 {
+					continue
+				}
+				start, end = loc[2], loc[3]
+			}
+			if start < end {
+				regions = append(regions, region{start, end, rule.name})
 			}
-		} else {
-			merged = append(merged, r)
 		}
 	}
 
-	var b strings.Builder
-	prev := 0
-	for _, r := range merged {
-		b.WriteString(s[prev:r.start])
-		b.WriteString("REDACTED")
-		prev = r.end
+	// Values redacted earlier, e.g. API_TOKEN=REDACTED in a stored
+	// checkpoint, and allowed values are not secrets.
+	kept := regions[:0]
+	for _, r := range regions {
+		if secret := s[r.start:r.end]; secret != "REDACTED" && !rules.allowed(secret) {
+			kept = append(kept, r)
+		}
 	}
-	b.WriteString(s[prev:])
-	return b.String()
+	return kept
 }
 
 // Bytes is a convenience wrapper around String for []byte content.
@@ -190,38 +261,67 @@ func JSONLContent(content string) (string, error) {
 	return b.String(), nil
 }
 
+// ScanJSONL returns the secrets JSONLContent would redact in content. Each
+// finding's Line is the JSONL line holding the secret.
+func ScanJSONL(content string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		var parsed any
+		if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+			for _, f := range Scan(line) {
+				findings = append(findings, Finding{Rule: f.Rule, Line: i + 1})
+			}
+			continue
+		}
+		walkJSONLStrings(parsed, func(val string) {
+			for _, f := range Scan(val) {
+				findings = append(findings, Finding{Rule: f.Rule, Line: i + 1})
+			}
+		})
+	}
+	return findings
+}
+
 // collectJSONLReplacements walks a parsed JSON value and collects unique
 // (original, redacted) string pairs for values that need redaction.
 func collectJSONLReplacements(v any) [][2]string {
 	seen := make(map[string]bool)
 	var repls [][2]string
-	var walk func(v any)
-	walk = func(v any) {
-		switch val := v.(type) {
-		case map[string]any:
-			if shouldSkipJSONLObject(val) {
-				return
-			}
-			for k, child := range val {
-				if shouldSkipJSONLField(k) {
-					continue
-				}
-				walk(child)
-			}
-		case []any:
-			for _, child := range val {
-				walk(child)
-			}
-		case string:
-			redacted := String(val)
-			if redacted != val && !seen[val] {
-				seen[val] = true
-				repls = append(repls, [2]string{val, redacted})
+	walkJSONLStrings(v, func(val string) {
+		redacted := String(val)
+		if redacted != val && !seen[val] {
+			seen[val] = true
+			repls = append(repls, [2]string{val, redacted})
+		}
+	})
+	return repls
+}
+
+// walkJSONLStrings calls fn for every string value in v that may hold a
+// secret, skipping ID, path, and signature fields and image objects.
+func walkJSONLStrings(v any, fn func(string)) {
+	switch val := v.(type) {
+	case map[string]any:
+		if shouldSkipJSONLObject(val) {
+			return
+		}
+		for k, child := range val {
+			if shouldSkipJSONLField(k) {
+				continue
 			}
+			walkJSONLStrings(child, fn)
+		}
+	case []any:
+		for _, child := range val {
+			walkJSONLStrings(child, fn)
 		}
+	case string:
+		fn(val)
 	}
-	walk(v)
-	return repls
 }
 
 // shouldSkipJSONLField returns true if a JSON key should be excluded from scanning/redaction.
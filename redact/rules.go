@@ -0,0 +1,144 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Rule names reported in findings for the built-in detectors. Findings from
+// gitleaks are reported as "gitleaks:<rule id>".
+const (
+	RuleEntropy       = "entropy"
+	RuleEnvAssignment = "env-assignment"
+)
+
+// envAssignmentPattern matches shell and .env assignments to variables whose
+// name marks them as secret, e.g. DB_PASSWORD=hunter2. The value (group 1) is
+// redacted even when its entropy is low. Values starting with $ or < refer to
+// other variables or are placeholders.
+var envAssignmentPattern = regexp.MustCompile(
+	`\b[A-Z][A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|ACCESS_?KEY|CREDENTIALS?)[A-Z0-9_]*\s*=\s*["']?([^\s"'\\$<][^\s"'\\]*)`)
+
+// Rule is a custom secret pattern. When Pattern has a capture group, only
+// the first group is redacted; otherwise the whole match is.
+type Rule struct {
+	Name    string
+	Pattern string
+}
+
+// Rules configures detection on top of the built-in entropy, .env
+// assignment, and gitleaks detectors.
+type Rules struct {
+	// EntropyThreshold replaces the default Shannon entropy threshold (4.5).
+	// 0 = default.
+	EntropyThreshold float64
+	// Patterns are additional secret patterns.
+	Patterns []Rule
+	// Allow lists regular expressions for values that are never redacted,
+	// such as well-known example keys.
+	Allow []string
+}
+
+// compiledRules is Rules with its regular expressions compiled.
+type compiledRules struct {
+	entropyThreshold float64
+	patterns         []compiledRule
+	allow            []*regexp.Regexp
+}
+
+type compiledRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Validate reports the first rule that can't be used.
+func (r Rules) Validate() error {
+	_, err := r.compile()
+	return err
+}
+
+func (r Rules) compile() (*compiledRules, error) {
+	if r.EntropyThreshold < 0 {
+		return nil, fmt.Errorf("entropy_threshold %v must not be negative", r.EntropyThreshold)
+	}
+	c := &compiledRules{entropyThreshold: entropyThreshold}
+	if r.EntropyThreshold > 0 {
+		c.entropyThreshold = r.EntropyThreshold
+	}
+	for i, rule := range r.Patterns {
+		if rule.Pattern == "" {
+			return nil, fmt.Errorf("rule %d has no pattern", i+1)
+		}
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("custom-%d", i+1)
+		}
+		c.patterns = append(c.patterns, compiledRule{name: name, re: re})
+	}
+	for _, pattern := range r.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("allow %q: %w", pattern, err)
+		}
+		c.allow = append(c.allow, re)
+	}
+	return c, nil
+}
+
+// allowed reports whether secret matches an allow pattern.
+func (c *compiledRules) allowed(secret string) bool {
+	for _, re := range c.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	rulesMu sync.Mutex
+	// Set by SetRulesGetter.
+	rulesGetter func() Rules
+
+	// rules caches the compiled result of rulesGetter.
+	rules *compiledRules
+)
+
+// SetRulesGetter sets a callback function to get the configured rules from
+// settings. This allows the redact package to read settings without a
+// circular dependency. The callback is called at most once.
+func SetRulesGetter(getter func() Rules) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rulesGetter = getter
+	rules = nil
+}
+
+// ClearRulesCache forgets the compiled rules, so the next redaction reads
+// settings again.
+func ClearRulesCache() {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	rules = nil
+}
+
+// currentRules returns the configured rules. Invalid rules fall back to the
+// built-in detectors only, so a typo in settings never disables redaction.
+func currentRules() *compiledRules {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	if rules == nil {
+		rules = &compiledRules{entropyThreshold: entropyThreshold}
+		if rulesGetter != nil {
+			if c, err := rulesGetter().compile(); err == nil {
+				rules = c
+			}
+		}
+	}
+	return rules
+}
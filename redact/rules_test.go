@@ -0,0 +1,113 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setRules installs rules for the rest of the test. Tests that call it
+// cannot use t.Parallel() because the rules are package state.
+func setRules(t *testing.T, r Rules) {
+	t.Helper()
+	SetRulesGetter(func() Rules { return r })
+	t.Cleanup(func() { SetRulesGetter(nil) })
+}
+
+func TestString_EnvAssignment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"DB_PASSWORD=hunter2", "DB_PASSWORD=REDACTED"},
+		{"export GITHUB_TOKEN=\"abc123\"", "export GITHUB_TOKEN=\"REDACTED\""},
+		{"STRIPE_API_KEY = short", "STRIPE_API_KEY = REDACTED"},
+		{"API_TOKEN=$OTHER_VAR", "API_TOKEN=$OTHER_VAR"},
+		{"DB_PASSWORD=<your password>", "DB_PASSWORD=<your password>"},
+		{"password=hunter2", "password=hunter2"},
+		{"PATH=/usr/bin", "PATH=/usr/bin"},
+	}
+	for _, tt := range tests {
+		if got := String(tt.input); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestString_CustomRules(t *testing.T) {
+	setRules(t, Rules{
+		Patterns: []Rule{
+			{Name: "acme-token", Pattern: `acme_[a-z]{8}`},
+			{Name: "internal-host", Pattern: `host=(\S+)`},
+		},
+		Allow: []string{`^acme_examples$`},
+	})
+
+	assert.Equal(t, "token REDACTED", String("token acme_abcdefgh"))
+	assert.Equal(t, "host=REDACTED port=1", String("host=db1.corp port=1"))
+	assert.Equal(t, "token acme_examples", String("token acme_examples"))
+}
+
+func TestString_EntropyThreshold(t *testing.T) {
+	input := "identifier abcdefghijklmnop"
+	assert.Equal(t, input, String(input))
+
+	setRules(t, Rules{EntropyThreshold: 3.5})
+	assert.Equal(t, "identifier REDACTED", String(input))
+}
+
+func TestString_InvalidRulesUseBuiltins(t *testing.T) {
+	setRules(t, Rules{Patterns: []Rule{{Name: "broken", Pattern: `(`}}})
+
+	assert.NotContains(t, String("key="+highEntropySecret), highEntropySecret)
+	assert.Equal(t, "DB_PASSWORD=REDACTED", String("DB_PASSWORD=hunter2"))
+}
+
+func TestRules_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, Rules{}.Validate())
+	require.NoError(t, Rules{Patterns: []Rule{{Name: "x", Pattern: `x_\d+`}}, Allow: []string{`^EXAMPLE`}}.Validate())
+	require.Error(t, Rules{EntropyThreshold: -1}.Validate())
+	require.Error(t, Rules{Patterns: []Rule{{Name: "empty"}}}.Validate())
+	require.Error(t, Rules{Patterns: []Rule{{Name: "broken", Pattern: `(`}}}.Validate())
+	require.Error(t, Rules{Allow: []string{`[`}}.Validate())
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	findings := Scan("first line\nkey=" + highEntropySecret + "\nDB_PASSWORD=hunter2 and DB_PASSWORD=REDACTED")
+	require.NotEmpty(t, findings)
+	assert.Equal(t, 2, findings[0].Line)
+	assert.Contains(t, findings, Finding{Rule: RuleEntropy, Line: 2})
+	assert.Contains(t, findings, Finding{Rule: RuleEnvAssignment, Line: 3})
+	assert.Len(t, filterRule(findings, RuleEnvAssignment), 1, "already redacted values are not findings")
+
+	assert.Empty(t, Scan("hello world, this is normal text"))
+}
+
+func TestScanJSONL(t *testing.T) {
+	t.Parallel()
+
+	content := `{"type":"user","message":{"content":"hello"}}
+{"type":"assistant","message":{"content":"DB_PASSWORD=hunter2"},"session_id":"` + highEntropySecret + `"}
+not json DB_PASSWORD=hunter2`
+	assert.Equal(t, []Finding{
+		{Rule: RuleEnvAssignment, Line: 2},
+		{Rule: RuleEnvAssignment, Line: 3},
+	}, ScanJSONL(content))
+}
+
+func filterRule(findings []Finding, rule string) []Finding {
+	var matched []Finding
+	for _, f := range findings {
+		if f.Rule == rule {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}